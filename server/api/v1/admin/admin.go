@@ -39,6 +39,25 @@ func GetAdminDashboard(c *gin.Context) {
 	common.ResponseSuccess(c, dashboard)
 }
 
+// GetProviderCapacity 获取集群容量概览
+// @Summary 获取集群容量概览
+// @Description 按Provider汇总CPU/内存/磁盘的总量与已用量、各状态实例数、最近的聚合流量速率和健康状态。数据全部来自数据库，不会触发SSH调用
+// @Tags 管理员管理
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} common.Response{data=admin.ProviderCapacityResponse} "获取成功"
+// @Failure 500 {object} common.Response "获取失败"
+// @Router /admin/capacity [get]
+func GetProviderCapacity(c *gin.Context) {
+	dashboardService := &resources.AdminDashboardService{}
+	capacity, err := dashboardService.GetProviderCapacity()
+	if err != nil {
+		common.ResponseWithError(c, err)
+		return
+	}
+	common.ResponseSuccess(c, capacity)
+}
+
 // GetInstanceList 获取实例列表
 // @Summary 获取实例列表
 // @Description 管理员获取系统中所有实例的列表，支持分页和过滤
@@ -53,6 +72,9 @@ func GetAdminDashboard(c *gin.Context) {
 // @Param providerName query string false "节点名称"
 // @Param ownerName query string false "所有者名称"
 // @Param instance_type query string false "实例类型"
+// @Param providerHealth query string false "Provider健康状态：online, offline, partial, unknown，多个以逗号分隔"
+// @Param lastSyncBefore query string false "流量最后同步时间早于该时间"
+// @Param hasTrafficError query bool false "是否存在流量采集错误"
 // @Success 200 {object} common.Response{data=object} "获取成功"
 // @Failure 400 {object} common.Response "请求参数错误"
 // @Failure 500 {object} common.Response "服务器内部错误"
@@ -73,6 +95,10 @@ func GetInstanceList(c *gin.Context) {
 	instanceService := instance.NewService(task.GetTaskService())
 	instances, total, err := instanceService.GetInstanceList(req)
 	if err != nil {
+		if appErr, ok := err.(*common.AppError); ok {
+			common.ResponseWithError(c, appErr)
+			return
+		}
 		c.JSON(http.StatusInternalServerError, common.Response{
 			Code: 500,
 			Msg:  "获取实例列表失败",
@@ -273,7 +299,7 @@ func UpdateAdminInstanceTypePermissions(c *gin.Context) {
 
 // AdminInstanceAction 管理员执行实例操作
 // @Summary 管理员执行实例操作
-// @Description 管理员对实例执行启动、停止、重启等操作
+// @Description 管理员对实例执行启动、停止、重启、重置、重建（recreate，按创建配置重建容器并保留数据卷，仅Docker/Podman）、删除等操作
 // @Tags 管理员管理
 // @Accept json
 // @Produce json
@@ -343,6 +369,345 @@ func AdminInstanceAction(c *gin.Context) {
 	common.ResponseSuccess(c, nil, "操作已提交")
 }
 
+// ResizeInstanceDisk 管理员调整实例磁盘大小
+// @Summary 管理员调整实例磁盘大小
+// @Description 调整LXD/Incus/Proxmox实例的根磁盘大小，不允许小于当前已用空间
+// @Tags 管理员管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "实例ID"
+// @Param request body admin.ResizeInstanceDiskRequest true "磁盘大小参数"
+// @Success 200 {object} common.Response "调整成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 404 {object} common.Response "实例不存在"
+// @Failure 500 {object} common.Response "调整失败"
+// @Router /admin/instances/{id}/resize-disk [put]
+func ResizeInstanceDisk(c *gin.Context) {
+	instanceIDStr := c.Param("id")
+	instanceID, err := strconv.ParseUint(instanceIDStr, 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的实例ID"))
+		return
+	}
+
+	var req admin.ResizeInstanceDiskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "磁盘大小必须为1-10240之间的整数(GB)"))
+		return
+	}
+
+	global.APP_LOG.Info("管理员调整实例磁盘大小",
+		zap.Uint64("instanceID", instanceID),
+		zap.Int("diskSizeGB", req.DiskSizeGB),
+		zap.String("admin_ip", c.ClientIP()))
+
+	instanceService := instance.NewService(task.GetTaskService())
+	if err := instanceService.ResizeInstanceDisk(uint(instanceID), req.DiskSizeGB); err != nil {
+		global.APP_LOG.Error("管理员调整实例磁盘大小失败",
+			zap.Uint64("instanceID", instanceID),
+			zap.Error(err))
+		if err.Error() == "实例不存在" {
+			common.ResponseWithError(c, common.NewError(common.CodeNotFound, err.Error()))
+			return
+		}
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, err.Error()))
+		return
+	}
+
+	common.ResponseSuccess(c, nil, "磁盘大小调整成功")
+}
+
+// SetInstanceBandwidth 管理员调整实例带宽限速
+// @Summary 管理员调整实例带宽限速
+// @Description 设置实例入站/出站带宽限速并持久化，运行中的实例立即生效，实例启动/重启后自动重新应用。Docker/Podman/Proxmox通过宿主机侧接口的tc规则限速，LXD/Incus使用网卡limits配置
+// @Tags 管理员管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "实例ID"
+// @Param request body admin.SetInstanceBandwidthRequest true "带宽限速参数，0表示不限速"
+// @Success 200 {object} common.Response "调整成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 404 {object} common.Response "实例不存在"
+// @Failure 500 {object} common.Response "调整失败"
+// @Router /admin/instances/{id}/bandwidth [put]
+func SetInstanceBandwidth(c *gin.Context) {
+	instanceID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的实例ID"))
+		return
+	}
+
+	var req admin.SetInstanceBandwidthRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "带宽必须为非负整数(Mbps)"))
+		return
+	}
+
+	instanceService := instance.NewService(task.GetTaskService())
+	if err := instanceService.SetInstanceBandwidth(uint(instanceID), req); err != nil {
+		global.APP_LOG.Error("管理员调整实例带宽限速失败",
+			zap.Uint64("instanceID", instanceID),
+			zap.Error(err))
+		common.ResponseWithError(c, err)
+		return
+	}
+
+	common.ResponseSuccess(c, nil, "带宽限速调整成功")
+}
+
+// ApplyInstanceProfile 管理员为实例应用profile
+// @Summary 管理员为实例应用profile
+// @Description 为LXD/Incus实例追加profile并清除实例自身与profile重复的配置项；运行中的虚拟机需要停止，allowRestart为true时自动停止并在应用后重新启动
+// @Tags 管理员管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "实例ID"
+// @Param request body admin.ApplyInstanceProfileRequest true "profile参数"
+// @Success 200 {object} common.Response "应用成功"
+// @Failure 400 {object} common.Response "参数错误或实例需要停止"
+// @Failure 404 {object} common.Response "实例不存在"
+// @Failure 500 {object} common.Response "应用失败"
+// @Router /admin/instances/{id}/profile [post]
+func ApplyInstanceProfile(c *gin.Context) {
+	instanceID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的实例ID"))
+		return
+	}
+
+	var req admin.ApplyInstanceProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误: "+err.Error()))
+		return
+	}
+
+	instanceService := instance.NewService(task.GetTaskService())
+	if err := instanceService.ApplyInstanceProfile(uint(instanceID), req); err != nil {
+		global.APP_LOG.Error("管理员为实例应用profile失败",
+			zap.Uint64("instanceID", instanceID),
+			zap.String("profile", req.Profile),
+			zap.Error(err))
+		common.ResponseWithError(c, err)
+		return
+	}
+
+	common.ResponseSuccess(c, nil, "profile应用成功")
+}
+
+// SyncInstanceTrafficNow 管理员立即同步实例流量
+// @Summary 管理员立即同步实例流量
+// @Description 同步执行指定实例的pmacct流量采集并返回当月最新流量统计，采集受Provider的SSH执行超时限制
+// @Tags 管理员管理
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "实例ID"
+// @Success 200 {object} common.Response{data=admin.InstanceTrafficSyncResponse} "同步成功"
+// @Failure 400 {object} common.Response "参数错误或实例未启用流量监控"
+// @Failure 404 {object} common.Response "实例不存在"
+// @Failure 500 {object} common.Response "采集失败或超时"
+// @Router /admin/instances/{id}/sync-traffic [post]
+func SyncInstanceTrafficNow(c *gin.Context) {
+	instanceID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的实例ID"))
+		return
+	}
+
+	instanceService := instance.NewService(task.GetTaskService())
+	result, err := instanceService.ForceSyncInstanceTraffic(uint(instanceID))
+	if err != nil {
+		common.ResponseWithError(c, err)
+		return
+	}
+
+	common.ResponseSuccess(c, result, "实例流量同步完成")
+}
+
+// GetInstanceMonitorInterfaces 管理员获取实例流量监控接口
+// @Summary 管理员获取实例流量监控接口
+// @Description 返回实例记录中的监控接口、是否为手动指定，以及pmacct当前实际监控的接口
+// @Tags 管理员管理
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "实例ID"
+// @Success 200 {object} common.Response{data=admin.MonitorInterfacesResponse} "获取成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 404 {object} common.Response "实例不存在"
+// @Router /admin/instances/{id}/monitor-interfaces [get]
+func GetInstanceMonitorInterfaces(c *gin.Context) {
+	instanceID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的实例ID"))
+		return
+	}
+
+	instanceService := instance.NewService(task.GetTaskService())
+	result, err := instanceService.GetMonitorInterfaces(uint(instanceID))
+	if err != nil {
+		common.ResponseWithError(c, err)
+		return
+	}
+
+	common.ResponseSuccess(c, result)
+}
+
+// SetInstanceMonitorInterfaces 管理员手动指定实例流量监控接口
+// @Summary 管理员手动指定实例流量监控接口
+// @Description 自动检测选错接口时手动指定宿主机上的监控接口，之后不再自动检测；auto为true时恢复自动检测。保存后立即重建该实例的流量监控
+// @Tags 管理员管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "实例ID"
+// @Param request body admin.SetMonitorInterfacesRequest true "监控接口"
+// @Success 200 {object} common.Response "设置成功"
+// @Failure 400 {object} common.Response "参数错误或接口不存在"
+// @Failure 404 {object} common.Response "实例不存在"
+// @Router /admin/instances/{id}/monitor-interfaces [put]
+func SetInstanceMonitorInterfaces(c *gin.Context) {
+	instanceID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的实例ID"))
+		return
+	}
+
+	var req admin.SetMonitorInterfacesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误: "+err.Error()))
+		return
+	}
+
+	instanceService := instance.NewService(task.GetTaskService())
+	if err := instanceService.SetMonitorInterfaces(uint(instanceID), req); err != nil {
+		global.APP_LOG.Error("管理员设置实例流量监控接口失败",
+			zap.Uint64("instanceID", instanceID),
+			zap.Error(err))
+		common.ResponseWithError(c, err)
+		return
+	}
+
+	common.ResponseSuccess(c, nil, "监控接口设置成功")
+}
+
+// SetInstanceMonitoring 管理员开启或关闭实例流量监控
+// @Summary 管理员开启或关闭实例流量监控
+// @Description 关闭后清理该实例在宿主机上的pmacct监控并停止采集，新建、重置等流程也不再初始化监控，已有流量历史保留；开启后立即重新初始化监控
+// @Tags 管理员管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "实例ID"
+// @Param request body admin.SetInstanceMonitoringRequest true "监控开关"
+// @Success 200 {object} common.Response "设置成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 404 {object} common.Response "实例不存在"
+// @Router /admin/instances/{id}/monitoring [put]
+func SetInstanceMonitoring(c *gin.Context) {
+	instanceID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的实例ID"))
+		return
+	}
+
+	var req admin.SetInstanceMonitoringRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误: "+err.Error()))
+		return
+	}
+
+	instanceService := instance.NewService(task.GetTaskService())
+	if err := instanceService.SetInstanceMonitoring(uint(instanceID), *req.Enabled); err != nil {
+		global.APP_LOG.Error("管理员设置实例流量监控开关失败",
+			zap.Uint64("instanceID", instanceID),
+			zap.Error(err))
+		common.ResponseWithError(c, err)
+		return
+	}
+
+	common.ResponseSuccess(c, nil, "流量监控设置成功")
+}
+
+// MigrateInstance 管理员迁移实例到其他节点
+// @Summary 管理员迁移实例到其他节点
+// @Description 创建迁移任务：停止实例，在同类型的目标节点创建同配置实例，将源实例导出的数据导入目标实例后切换实例记录并删除源实例。目前支持Docker和Incus，迁移期间实例停机，失败时删除目标节点上的实例并恢复源实例
+// @Tags 管理员管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "实例ID"
+// @Param request body admin.MigrateInstanceRequest true "目标节点"
+// @Success 200 {object} common.Response{data=admin.MigrateInstanceResponse} "任务创建成功"
+// @Failure 400 {object} common.Response "参数错误或不满足迁移条件"
+// @Failure 404 {object} common.Response "实例或目标节点不存在"
+// @Failure 409 {object} common.Response "实例有进行中的任务"
+// @Router /admin/instances/{id}/migrate [post]
+func MigrateInstance(c *gin.Context) {
+	instanceID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的实例ID"))
+		return
+	}
+
+	var req admin.MigrateInstanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误: "+err.Error()))
+		return
+	}
+
+	instanceService := instance.NewService(task.GetTaskService())
+	result, err := instanceService.MigrateInstance(uint(instanceID), req)
+	if err != nil {
+		global.APP_LOG.Warn("管理员创建实例迁移任务失败",
+			zap.Uint64("instanceID", instanceID),
+			zap.Uint("targetProviderID", req.TargetProviderID),
+			zap.Error(err))
+		common.ResponseWithError(c, err)
+		return
+	}
+
+	common.ResponseSuccess(c, result, "迁移任务创建成功")
+}
+
+// GetInstanceDeletePlan 管理员预览实例删除计划
+// @Summary 管理员预览实例删除计划
+// @Description 返回删除实例时Provider将按顺序执行的命令，仅预览不执行
+// @Tags 管理员管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "实例ID"
+// @Success 200 {object} common.Response{data=admin.InstanceDeletePlanResponse} "获取成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 404 {object} common.Response "实例不存在"
+// @Failure 500 {object} common.Response "获取失败"
+// @Router /admin/instances/{id}/delete-plan [get]
+func GetInstanceDeletePlan(c *gin.Context) {
+	instanceID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的实例ID"))
+		return
+	}
+
+	instanceService := instance.NewService(task.GetTaskService())
+	plan, err := instanceService.GetInstanceDeletePlan(uint(instanceID))
+	if err != nil {
+		global.APP_LOG.Error("获取实例删除计划失败",
+			zap.Uint64("instanceID", instanceID),
+			zap.Error(err))
+		if err.Error() == "实例不存在" {
+			common.ResponseWithError(c, common.NewError(common.CodeNotFound, err.Error()))
+			return
+		}
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, err.Error()))
+		return
+	}
+
+	common.ResponseSuccess(c, plan, "获取删除计划成功")
+}
+
 // ResetInstancePassword 管理员重置实例密码
 // @Summary 管理员重置实例密码
 // @Description 管理员重置指定实例的登录密码，创建异步任务执行密码重置操作
@@ -399,6 +764,39 @@ func ResetInstancePassword(c *gin.Context) {
 	common.ResponseSuccess(c, response, "密码重置任务创建成功")
 }
 
+// ResetInstancePasswordSync 管理员同步重置实例密码
+// @Summary 管理员同步重置实例密码
+// @Description 不创建异步任务，直接在Provider上设置新密码并返回，适合批量操作
+// @Tags 管理员管理
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "实例ID"
+// @Success 200 {object} common.Response{data=admin.ResetInstancePasswordSyncResponse} "重置成功，返回新密码"
+// @Failure 400 {object} common.Response "参数错误或实例未运行"
+// @Failure 404 {object} common.Response "实例不存在"
+// @Failure 500 {object} common.Response "重置失败"
+// @Router /admin/instances/{id}/reset-password-sync [post]
+func ResetInstancePasswordSync(c *gin.Context) {
+	instanceID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的实例ID"))
+		return
+	}
+
+	global.APP_LOG.Info("管理员同步重置实例密码",
+		zap.Uint64("instanceID", instanceID),
+		zap.String("admin_ip", c.ClientIP()))
+
+	instanceService := instance.NewService(task.GetTaskService())
+	result, err := instanceService.ResetInstancePasswordSync(uint(instanceID))
+	if err != nil {
+		common.ResponseWithError(c, err)
+		return
+	}
+
+	common.ResponseSuccess(c, result, "密码重置成功")
+}
+
 // GetInstanceNewPassword 管理员获取实例重置后的新密码
 // @Summary 管理员获取实例重置后的新密码
 // @Description 通过任务ID获取实例重置后的新密码
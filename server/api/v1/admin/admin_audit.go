@@ -0,0 +1,49 @@
+package admin
+
+import (
+	"oneclickvirt/model/admin"
+	"oneclickvirt/model/common"
+	auditLog "oneclickvirt/service/log"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetAuditLogs 获取操作审计日志
+// @Summary 获取操作审计日志
+// @Description 分页查询管理员与Provider变更类操作的审计日志，请求体中的密码、密钥字段已脱敏
+// @Tags 管理员管理
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码"
+// @Param pageSize query int false "每页数量"
+// @Param userId query int false "操作者用户ID"
+// @Param username query string false "操作者用户名"
+// @Param action query string false "操作（模糊匹配）"
+// @Param method query string false "HTTP方法"
+// @Param result query string false "结果：success/failure"
+// @Param startTime query string false "开始时间"
+// @Param endTime query string false "结束时间"
+// @Success 200 {object} common.Response{data=common.PageResult} "获取成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 500 {object} common.Response "获取失败"
+// @Router /admin/audit-logs [get]
+func GetAuditLogs(c *gin.Context) {
+	var req admin.AuditLogListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误: "+err.Error()))
+		return
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 || req.PageSize > 200 {
+		req.PageSize = 20
+	}
+
+	logs, total, err := auditLog.NewAuditService().List(req)
+	if err != nil {
+		common.ResponseWithError(c, err)
+		return
+	}
+	common.ResponseSuccessWithPagination(c, logs, total, req.Page, req.PageSize)
+}
@@ -0,0 +1,146 @@
+package admin
+
+import (
+	"strconv"
+
+	"oneclickvirt/global"
+	"oneclickvirt/model/admin"
+	"oneclickvirt/model/common"
+	"oneclickvirt/service/admin/instance"
+	"oneclickvirt/service/task"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// CreateInstanceBackup 管理员创建实例备份
+// @Summary 管理员创建实例备份
+// @Description 创建备份任务：在宿主机上导出实例（Docker export、LXD/Incus export、Proxmox vzdump）并流式上传到配置的S3兼容对象存储，完成后按保留策略清理旧备份
+// @Tags 管理员管理
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "实例ID"
+// @Success 200 {object} common.Response{data=admin.BackupTaskResponse} "任务创建成功"
+// @Failure 400 {object} common.Response "未配置对象存储或实例状态不允许备份"
+// @Failure 404 {object} common.Response "实例不存在"
+// @Failure 409 {object} common.Response "已有进行中的备份或恢复任务"
+// @Router /admin/instances/{id}/backups [post]
+func CreateInstanceBackup(c *gin.Context) {
+	instanceID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的实例ID"))
+		return
+	}
+
+	instanceService := instance.NewService(task.GetTaskService())
+	result, err := instanceService.CreateBackup(uint(instanceID))
+	if err != nil {
+		global.APP_LOG.Warn("管理员创建实例备份失败",
+			zap.Uint64("instanceID", instanceID),
+			zap.Error(err))
+		common.ResponseWithError(c, err)
+		return
+	}
+
+	common.ResponseSuccess(c, result, "备份任务创建成功")
+}
+
+// GetBackupList 管理员获取备份列表
+// @Summary 管理员获取备份列表
+// @Description 分页查询实例备份记录，可按实例和状态过滤
+// @Tags 管理员管理
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码"
+// @Param pageSize query int false "每页数量"
+// @Param keyword query string false "实例名称（模糊匹配）"
+// @Param instanceId query int false "实例ID"
+// @Param status query string false "状态：pending/running/completed/failed"
+// @Success 200 {object} common.Response{data=common.PageResult} "获取成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 500 {object} common.Response "获取失败"
+// @Router /admin/backups [get]
+func GetBackupList(c *gin.Context) {
+	var req admin.BackupListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误: "+err.Error()))
+		return
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 || req.PageSize > 100 {
+		req.PageSize = 20
+	}
+
+	instanceService := instance.NewService(task.GetTaskService())
+	backups, total, err := instanceService.GetBackupList(req)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, "获取备份列表失败"))
+		return
+	}
+	common.ResponseSuccessWithPagination(c, backups, total, req.Page, req.PageSize)
+}
+
+// RestoreInstanceBackup 管理员从备份恢复实例
+// @Summary 管理员从备份恢复实例
+// @Description 创建恢复任务，用备份覆盖原实例的数据。备份下载时校验SHA256，校验失败不会覆盖实例
+// @Tags 管理员管理
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "备份ID"
+// @Success 200 {object} common.Response{data=admin.BackupTaskResponse} "任务创建成功"
+// @Failure 400 {object} common.Response "备份未完成或实例状态不允许恢复"
+// @Failure 404 {object} common.Response "备份或实例不存在"
+// @Failure 409 {object} common.Response "已有进行中的备份或恢复任务"
+// @Router /admin/backups/{id}/restore [post]
+func RestoreInstanceBackup(c *gin.Context) {
+	backupID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的备份ID"))
+		return
+	}
+
+	instanceService := instance.NewService(task.GetTaskService())
+	result, err := instanceService.RestoreFromBackup(uint(backupID))
+	if err != nil {
+		global.APP_LOG.Warn("管理员创建实例恢复任务失败",
+			zap.Uint64("backupID", backupID),
+			zap.Error(err))
+		common.ResponseWithError(c, err)
+		return
+	}
+
+	common.ResponseSuccess(c, result, "恢复任务创建成功")
+}
+
+// DeleteBackup 管理员删除备份
+// @Summary 管理员删除备份
+// @Description 删除备份记录及其在对象存储中的文件
+// @Tags 管理员管理
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "备份ID"
+// @Success 200 {object} common.Response "删除成功"
+// @Failure 400 {object} common.Response "备份进行中"
+// @Failure 404 {object} common.Response "备份不存在"
+// @Failure 500 {object} common.Response "删除失败"
+// @Router /admin/backups/{id} [delete]
+func DeleteBackup(c *gin.Context) {
+	backupID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的备份ID"))
+		return
+	}
+
+	instanceService := instance.NewService(task.GetTaskService())
+	if err := instanceService.DeleteBackup(uint(backupID)); err != nil {
+		global.APP_LOG.Warn("管理员删除备份失败",
+			zap.Uint64("backupID", backupID),
+			zap.Error(err))
+		common.ResponseWithError(c, err)
+		return
+	}
+
+	common.ResponseSuccess(c, nil, "备份删除成功")
+}
@@ -13,6 +13,7 @@ import (
 	"oneclickvirt/model/admin"
 	"oneclickvirt/model/common"
 	adminProvider "oneclickvirt/service/admin/provider"
+	"oneclickvirt/service/resources"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -232,6 +233,48 @@ func UnfreezeProvider(c *gin.Context) {
 	})
 }
 
+// SetProviderMaintenance 切换Provider维护模式
+// @Summary 切换Provider维护模式
+// @Description 维护中的Provider不再出现在用户可用节点列表中，并拒绝新建、申领和克隆实例；已有实例的启停、重启等操作不受影响
+// @Tags 管理员管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Provider ID"
+// @Param request body admin.SetProviderMaintenanceRequest true "维护模式参数"
+// @Success 200 {object} common.Response "设置成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 404 {object} common.Response "Provider不存在"
+// @Router /admin/providers/{id}/maintenance [put]
+func SetProviderMaintenance(c *gin.Context) {
+	providerID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的Provider ID"))
+		return
+	}
+
+	var req admin.SetProviderMaintenanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误: "+err.Error()))
+		return
+	}
+
+	providerService := adminProvider.NewService()
+	if err := providerService.SetMaintenanceMode(uint(providerID), req); err != nil {
+		global.APP_LOG.Error("切换Provider维护模式失败",
+			zap.Uint64("providerId", providerID),
+			zap.Error(err))
+		common.ResponseWithError(c, err)
+		return
+	}
+
+	msg := "提供商已退出维护模式"
+	if req.Enabled {
+		msg = "提供商已进入维护模式"
+	}
+	common.ResponseSuccess(c, nil, msg)
+}
+
 // GenerateProviderCert 为Provider生成证书或配置
 // @Summary 为Provider生成证书或配置
 // @Description 为LXD/Incus Provider生成客户端证书和设置脚本，为Proxmox VE生成API Token配置脚本
@@ -473,6 +516,504 @@ func GetProviderStatus(c *gin.Context) {
 	})
 }
 
+// RecalculateProviderBudget 重算Provider资源预算
+// @Summary 重算Provider资源预算
+// @Description 根据当前活跃实例的资源限制重新计算Provider的已用和剩余CPU/内存/磁盘预算
+// @Tags 管理员管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Provider ID"
+// @Success 200 {object} common.Response{data=resource.ProviderBudgetResult} "重算成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 500 {object} common.Response "重算失败"
+// @Router /admin/providers/{id}/recalculate-budget [post]
+func RecalculateProviderBudget(c *gin.Context) {
+	providerIDStr := c.Param("id")
+	providerID, err := strconv.ParseUint(providerIDStr, 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的Provider ID"))
+		return
+	}
+
+	global.APP_LOG.Info("管理员重算Provider资源预算",
+		zap.Uint64("providerId", providerID),
+		zap.String("admin_ip", c.ClientIP()))
+
+	resourceService := &resources.ResourceService{}
+	result, err := resourceService.SyncProviderResources(uint(providerID))
+	if err != nil {
+		global.APP_LOG.Error("重算Provider资源预算失败",
+			zap.Uint64("providerId", providerID),
+			zap.Error(err))
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, "重算资源预算失败: "+err.Error()))
+		return
+	}
+
+	common.ResponseSuccess(c, result, "资源预算重算成功")
+}
+
+// ValidateProviderSSHAuth 校验Provider SSH认证
+// @Summary 校验Provider SSH认证
+// @Description 使用Provider已保存的凭据按私钥、ssh-agent、密码的顺序尝试SSH认证，不执行任何命令，返回认证成功的方式
+// @Tags 管理员管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Provider ID"
+// @Success 200 {object} common.Response{data=admin.ValidateSSHAuthResponse} "校验完成"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 404 {object} common.Response "Provider不存在"
+// @Router /admin/providers/{id}/validate-ssh-auth [post]
+func ValidateProviderSSHAuth(c *gin.Context) {
+	providerID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的Provider ID"))
+		return
+	}
+
+	global.APP_LOG.Info("管理员校验Provider SSH认证",
+		zap.Uint64("providerId", providerID),
+		zap.String("admin_ip", c.ClientIP()))
+
+	providerService := adminProvider.NewService()
+	result, err := providerService.ValidateProviderSSHAuth(uint(providerID))
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeNotFound, err.Error()))
+		return
+	}
+
+	if !result.Success {
+		common.ResponseSuccess(c, result, "SSH认证失败")
+		return
+	}
+	common.ResponseSuccess(c, result, "SSH认证成功")
+}
+
+// RepinProviderHostKey 重新固定Provider的SSH主机密钥
+// @Summary 重新固定Provider的SSH主机密钥
+// @Description 节点重装等导致主机密钥合法变更后，读取节点当前的主机密钥指纹并替换已固定的指纹，随后重新加载Provider连接。请先通过其他途径确认新指纹可信
+// @Tags 管理员管理
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Provider ID"
+// @Success 200 {object} common.Response{data=admin.RepinHostKeyResponse} "重新固定成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 404 {object} common.Response "Provider不存在"
+// @Failure 502 {object} common.Response "无法获取主机密钥"
+// @Router /admin/providers/{id}/repin-host-key [post]
+func RepinProviderHostKey(c *gin.Context) {
+	providerID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的Provider ID"))
+		return
+	}
+
+	global.APP_LOG.Info("管理员重新固定Provider SSH主机密钥",
+		zap.Uint64("providerId", providerID),
+		zap.String("admin_ip", c.ClientIP()))
+
+	providerService := adminProvider.NewService()
+	result, err := providerService.RepinHostKey(uint(providerID))
+	if err != nil {
+		common.ResponseWithError(c, err)
+		return
+	}
+	common.ResponseSuccess(c, result, "主机密钥已重新固定")
+}
+
+// RotateProviderCredentials 轮换Provider SSH凭据
+// @Summary 轮换Provider SSH凭据
+// @Description 替换Provider的SSH密码或私钥。新提供的每种凭据都会先单独完成SSH认证，任一失败则拒绝修改；保存后重新加载Provider并断开使用旧凭据的连接
+// @Tags 管理员管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Provider ID"
+// @Param request body admin.RotateProviderCredentialsRequest true "新凭据"
+// @Success 200 {object} common.Response{data=admin.RotateProviderCredentialsResponse} "轮换成功"
+// @Failure 400 {object} common.Response "参数错误或新凭据校验失败"
+// @Failure 404 {object} common.Response "Provider不存在"
+// @Router /admin/providers/{id}/credentials [put]
+func RotateProviderCredentials(c *gin.Context) {
+	providerID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的Provider ID"))
+		return
+	}
+
+	var req admin.RotateProviderCredentialsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误"))
+		return
+	}
+
+	global.APP_LOG.Info("管理员轮换Provider SSH凭据",
+		zap.Uint64("providerId", providerID),
+		zap.String("admin_ip", c.ClientIP()))
+
+	providerService := adminProvider.NewService()
+	result, err := providerService.RotateCredentials(uint(providerID), req)
+	if err != nil {
+		common.ResponseWithError(c, err)
+		return
+	}
+	common.ResponseSuccess(c, result, "凭据已更新")
+}
+
+// GetProviderStoragePools 获取Provider存储池列表
+// @Summary 获取Provider存储池列表
+// @Description 列出Provider节点上的存储池（LXD/Incus存储池、ProxmoxVE存储），用于选择创建实例时使用的存储
+// @Tags 管理员管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Provider ID"
+// @Success 200 {object} common.Response{data=admin.ProviderStoragePoolsResponse} "获取成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 500 {object} common.Response "获取失败"
+// @Router /admin/providers/{id}/storage-pools [get]
+func GetProviderStoragePools(c *gin.Context) {
+	providerID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的Provider ID"))
+		return
+	}
+
+	providerService := adminProvider.NewService()
+	result, err := providerService.ListProviderStoragePools(uint(providerID))
+	if err != nil {
+		global.APP_LOG.Error("获取Provider存储池列表失败",
+			zap.Uint64("providerId", providerID),
+			zap.Error(err))
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, err.Error()))
+		return
+	}
+
+	common.ResponseSuccess(c, result, "获取存储池列表成功")
+}
+
+// GetProviderGPUs 获取Provider宿主机GPU列表
+// @Summary 获取Provider宿主机GPU列表
+// @Description 列出Proxmox/Incus宿主机上可直通的GPU、IOMMU分组及直通占用情况
+// @Tags 管理员管理
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Provider ID"
+// @Success 200 {object} common.Response{data=admin.ProviderGPUsResponse} "获取成功"
+// @Failure 400 {object} common.Response "参数错误或Provider不支持GPU直通"
+// @Failure 500 {object} common.Response "获取失败"
+// @Router /admin/providers/{id}/gpus [get]
+func GetProviderGPUs(c *gin.Context) {
+	providerID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的Provider ID"))
+		return
+	}
+
+	providerService := adminProvider.NewService()
+	result, err := providerService.ListProviderGPUs(uint(providerID))
+	if err != nil {
+		global.APP_LOG.Error("获取Provider GPU列表失败",
+			zap.Uint64("providerId", providerID),
+			zap.Error(err))
+		common.ResponseWithError(c, err)
+		return
+	}
+
+	common.ResponseSuccess(c, result, "获取成功")
+}
+
+// GetProviderCommandLog 获取Provider最近执行的命令
+// @Summary 获取Provider最近执行的命令
+// @Description 返回该Provider最近通过SSH执行的命令（最多200条，按时间倒序），包括退出码、错误和截断后的输出，命令中的密码已脱敏。记录仅保存在内存中，服务重启后清空
+// @Tags 管理员管理
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Provider ID"
+// @Param limit query int false "返回条数，默认及最大200"
+// @Success 200 {object} common.Response{data=[]utils.CommandLogEntry} "获取成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 404 {object} common.Response "Provider不存在"
+// @Router /admin/providers/{id}/command-log [get]
+func GetProviderCommandLog(c *gin.Context) {
+	providerID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的Provider ID"))
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "0"))
+
+	providerService := adminProvider.NewService()
+	entries, err := providerService.GetProviderCommandLog(uint(providerID), limit)
+	if err != nil {
+		common.ResponseWithError(c, err)
+		return
+	}
+
+	common.ResponseSuccess(c, entries, "获取成功")
+}
+
+// GetSSHOperations 获取正在执行的SSH命令
+// @Summary 获取正在执行的SSH命令
+// @Description 列出所有Provider上正在通过SSH执行的命令（按开始时间升序，执行最久的在前），包括已执行时间、超时时间和节点熔断状态，命令中的密码已脱敏
+// @Tags 管理员管理
+// @Produce json
+// @Security BearerAuth
+// @Param providerId query int false "只返回指定Provider的命令"
+// @Success 200 {object} common.Response{data=[]admin.SSHOperationResponse} "获取成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Router /admin/operations [get]
+func GetSSHOperations(c *gin.Context) {
+	var providerID uint64
+	if value := c.Query("providerId"); value != "" {
+		var err error
+		providerID, err = strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的Provider ID"))
+			return
+		}
+	}
+
+	providerService := adminProvider.NewService()
+	operations, err := providerService.ListSSHOperations(uint(providerID))
+	if err != nil {
+		common.ResponseWithError(c, err)
+		return
+	}
+
+	common.ResponseSuccess(c, operations, "获取成功")
+}
+
+// CancelSSHOperation 取消正在执行的SSH命令
+// @Summary 取消正在执行的SSH命令
+// @Description 强制终止命令所在的SSH会话，等待该命令的调用方会收到取消错误。用于恢复被卡住的命令（如等待交互输入）阻塞的节点，取消不计入熔断统计
+// @Tags 管理员管理
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "操作ID"
+// @Success 200 {object} common.Response{data=admin.SSHOperationResponse} "已取消"
+// @Failure 404 {object} common.Response "操作不存在或已结束"
+// @Router /admin/operations/{id}/cancel [post]
+func CancelSSHOperation(c *gin.Context) {
+	providerService := adminProvider.NewService()
+	operation, err := providerService.CancelSSHOperation(c.Param("id"))
+	if err != nil {
+		common.ResponseWithError(c, err)
+		return
+	}
+
+	common.ResponseSuccess(c, operation, "已取消")
+}
+
+// GetProviderNetworks 获取Provider网络列表
+// @Summary 获取Provider网络列表
+// @Description 列出Docker/Podman节点上的网络及允许用户创建实例时选择的网络
+// @Tags 管理员管理
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Provider ID"
+// @Success 200 {object} common.Response{data=admin.ProviderNetworksResponse} "获取成功"
+// @Failure 400 {object} common.Response "参数错误或Provider不支持网络管理"
+// @Failure 500 {object} common.Response "获取失败"
+// @Router /admin/providers/{id}/networks [get]
+func GetProviderNetworks(c *gin.Context) {
+	providerID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的Provider ID"))
+		return
+	}
+
+	providerService := adminProvider.NewService()
+	result, err := providerService.ListProviderNetworks(uint(providerID))
+	if err != nil {
+		global.APP_LOG.Error("获取Provider网络列表失败",
+			zap.Uint64("providerId", providerID),
+			zap.Error(err))
+		common.ResponseWithError(c, err)
+		return
+	}
+
+	common.ResponseSuccess(c, result, "获取网络列表成功")
+}
+
+// CreateProviderNetwork 在Provider节点上创建网络
+// @Summary 在Provider节点上创建网络
+// @Description 在Docker/Podman节点上创建用户自定义bridge网络，用于隔离不同分组的容器；allowUsers为true时同时加入允许用户选择的网络列表
+// @Tags 管理员管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Provider ID"
+// @Param request body admin.CreateProviderNetworkRequest true "网络参数"
+// @Success 200 {object} common.Response "创建成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 500 {object} common.Response "创建失败"
+// @Router /admin/providers/{id}/networks [post]
+func CreateProviderNetwork(c *gin.Context) {
+	providerID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的Provider ID"))
+		return
+	}
+
+	var req admin.CreateProviderNetworkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误: "+err.Error()))
+		return
+	}
+
+	providerService := adminProvider.NewService()
+	if err := providerService.CreateProviderNetwork(uint(providerID), req); err != nil {
+		global.APP_LOG.Error("创建Provider网络失败",
+			zap.Uint64("providerId", providerID),
+			zap.String("network", req.Name),
+			zap.Error(err))
+		common.ResponseWithError(c, err)
+		return
+	}
+
+	common.ResponseSuccess(c, nil, "网络创建成功")
+}
+
+// GetProviderProfiles 获取Provider节点上的profile列表
+// @Summary 获取Provider节点上的profile列表
+// @Description 列出LXD/Incus节点上的profile及其配置项和设备
+// @Tags 管理员管理
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Provider ID"
+// @Success 200 {object} common.Response{data=admin.ProviderProfilesResponse} "获取成功"
+// @Failure 400 {object} common.Response "参数错误或Provider不支持profile管理"
+// @Failure 500 {object} common.Response "获取失败"
+// @Router /admin/providers/{id}/profiles [get]
+func GetProviderProfiles(c *gin.Context) {
+	providerID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的Provider ID"))
+		return
+	}
+
+	providerService := adminProvider.NewService()
+	result, err := providerService.ListProviderProfiles(uint(providerID))
+	if err != nil {
+		global.APP_LOG.Error("获取Provider profile列表失败",
+			zap.Uint64("providerId", providerID),
+			zap.Error(err))
+		common.ResponseWithError(c, err)
+		return
+	}
+
+	common.ResponseSuccess(c, result, "获取profile列表成功")
+}
+
+// CreateProviderProfile 在Provider节点上创建profile
+// @Summary 在Provider节点上创建profile
+// @Description 在LXD/Incus节点上创建包含CPU、内存、根磁盘等限制的profile，用于预先定义实例规格；只允许 limits.*、boot.*、snapshots.* 配置项
+// @Tags 管理员管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Provider ID"
+// @Param request body admin.CreateProviderProfileRequest true "profile参数"
+// @Success 200 {object} common.Response "创建成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 500 {object} common.Response "创建失败"
+// @Router /admin/providers/{id}/profiles [post]
+func CreateProviderProfile(c *gin.Context) {
+	providerID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的Provider ID"))
+		return
+	}
+
+	var req admin.CreateProviderProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误: "+err.Error()))
+		return
+	}
+
+	providerService := adminProvider.NewService()
+	if err := providerService.CreateProviderProfile(uint(providerID), req); err != nil {
+		global.APP_LOG.Error("创建Provider profile失败",
+			zap.Uint64("providerId", providerID),
+			zap.String("profile", req.Name),
+			zap.Error(err))
+		common.ResponseWithError(c, err)
+		return
+	}
+
+	common.ResponseSuccess(c, nil, "profile创建成功")
+}
+
+// PrewarmProviderImages 预热Provider镜像
+// @Summary 预热Provider镜像
+// @Description 在Provider节点上预先下载并导入指定的系统镜像，避免首次创建实例时等待镜像下载。预热在后台执行，通过GET同一路径查询每个镜像的状态
+// @Tags 管理员管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Provider ID"
+// @Param request body admin.PrewarmProviderImagesRequest true "预热的镜像名称列表"
+// @Success 200 {object} common.Response{data=admin.ProviderPrewarmStatusResponse} "预热任务已开始"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 500 {object} common.Response "预热失败"
+// @Router /admin/providers/{id}/prewarm [post]
+func PrewarmProviderImages(c *gin.Context) {
+	providerID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的Provider ID"))
+		return
+	}
+
+	var req admin.PrewarmProviderImagesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误"))
+		return
+	}
+
+	providerService := adminProvider.NewService()
+	result, err := providerService.PrewarmProviderImages(uint(providerID), req)
+	if err != nil {
+		global.APP_LOG.Error("预热Provider镜像失败",
+			zap.Uint64("providerId", providerID),
+			zap.Strings("images", req.Images),
+			zap.Error(err))
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, err.Error()))
+		return
+	}
+
+	common.ResponseSuccess(c, result, "镜像预热任务已开始")
+}
+
+// GetProviderPrewarmStatus 获取Provider镜像预热状态
+// @Summary 获取Provider镜像预热状态
+// @Description 查询Provider最近一次镜像预热的每个镜像状态（running、ready、failed）
+// @Tags 管理员管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Provider ID"
+// @Success 200 {object} common.Response{data=admin.ProviderPrewarmStatusResponse} "获取成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Router /admin/providers/{id}/prewarm [get]
+func GetProviderPrewarmStatus(c *gin.Context) {
+	providerID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的Provider ID"))
+		return
+	}
+
+	providerService := adminProvider.NewService()
+	result, err := providerService.GetProviderPrewarmStatus(uint(providerID))
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, err.Error()))
+		return
+	}
+
+	common.ResponseSuccess(c, result, "获取镜像预热状态成功")
+}
+
 // ExportProviderConfigs 导出所有Provider配置
 // @Summary 导出所有Provider配置
 // @Description 导出所有已配置的Provider认证信息到文件
@@ -506,6 +1047,85 @@ func ExportProviderConfigs(c *gin.Context) {
 	})
 }
 
+// ExportProviders 导出Provider定义
+// @Summary 导出Provider定义
+// @Description 导出全部Provider的连接、网络和资源配置，用于迁移到其他部署。通过X-Export-Passphrase请求头提供口令时加密密码、SSH密钥和Token，否则这些字段被清空
+// @Tags 管理员管理
+// @Produce json
+// @Security BearerAuth
+// @Param X-Export-Passphrase header string false "敏感字段加密口令"
+// @Success 200 {object} common.Response{data=admin.ProviderExportBundle} "导出成功"
+// @Failure 500 {object} common.Response "导出失败"
+// @Router /admin/providers/export [get]
+func ExportProviders(c *gin.Context) {
+	providerService := adminProvider.NewService()
+	bundle, err := providerService.ExportProviders(c.GetHeader("X-Export-Passphrase"))
+	if err != nil {
+		global.APP_LOG.Error("导出Provider定义失败", zap.Error(err))
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, err.Error()))
+		return
+	}
+
+	common.ResponseSuccess(c, bundle, "导出Provider定义成功")
+}
+
+// ImportProviders 导入Provider定义
+// @Summary 导入Provider定义
+// @Description 根据导出包重建Provider。导出包在写入前整体校验，名称冲突时按onConflict跳过或追加后缀，可选为LXD/Incus重新生成客户端证书
+// @Tags 管理员管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body admin.ImportProvidersRequest true "导出包及导入选项"
+// @Success 200 {object} common.Response{data=admin.ImportProvidersResponse} "导入完成"
+// @Failure 400 {object} common.Response "导出包无效"
+// @Router /admin/providers/import [post]
+func ImportProviders(c *gin.Context) {
+	var req admin.ImportProvidersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误: "+err.Error()))
+		return
+	}
+
+	providerService := adminProvider.NewService()
+	result, err := providerService.ImportProviders(req)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, err.Error()))
+		return
+	}
+
+	common.ResponseSuccess(c, result, "导入Provider定义完成")
+}
+
+// ImportProxmoxCluster 批量添加Proxmox集群节点
+// @Summary 批量添加Proxmox集群节点
+// @Description 使用集群中任一节点的凭据查询集群成员，将每个成员节点添加为Provider。成员节点沿用请求中的配置和SSH凭据，名称为 <name>-<节点名>；已添加的节点跳过，离线或无法连接的节点记为失败
+// @Tags 管理员管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body admin.ImportProxmoxClusterRequest true "种子节点配置"
+// @Success 200 {object} common.Response{data=admin.ImportProxmoxClusterResponse} "导入完成"
+// @Failure 400 {object} common.Response "参数错误或无法查询集群"
+// @Router /admin/providers/proxmox-cluster [post]
+func ImportProxmoxCluster(c *gin.Context) {
+	var req admin.ImportProxmoxClusterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误: "+err.Error()))
+		return
+	}
+
+	providerService := adminProvider.NewService()
+	result, err := providerService.ImportProxmoxCluster(req)
+	if err != nil {
+		global.APP_LOG.Warn("导入Proxmox集群失败", zap.Error(err))
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, err.Error()))
+		return
+	}
+
+	common.ResponseSuccess(c, result, "导入Proxmox集群节点完成")
+}
+
 // TestSSHConnection 测试SSH连接延迟
 // @Summary 测试SSH连接延迟
 // @Description 测试SSH连接延迟，执行多次测试并返回最小、最大、平均延迟及推荐超时时间
@@ -543,7 +1163,7 @@ func TestSSHConnection(c *gin.Context) {
 		zap.Int("testCount", req.TestCount))
 
 	// 验证认证方式：必须提供密码或SSH密钥其中一种
-	if req.Password == "" && req.SSHKey == "" {
+	if req.Password == "" && req.SSHKey == "" && !req.SSHUseAgent {
 		c.JSON(http.StatusBadRequest, common.Response{
 			Code: 400,
 			Msg:  "必须提供SSH密码或SSH密钥其中一种认证方式",
@@ -553,11 +1173,13 @@ func TestSSHConnection(c *gin.Context) {
 
 	// 导入 utils 包
 	sshConfig := utils.SSHConfig{
-		Host:       req.Host,
-		Port:       req.Port,
-		Username:   req.Username,
-		Password:   req.Password,
-		PrivateKey: req.SSHKey,
+		Host:                 req.Host,
+		Port:                 req.Port,
+		Username:             req.Username,
+		Password:             req.Password,
+		PrivateKey:           req.SSHKey,
+		PrivateKeyPassphrase: req.SSHKeyPassphrase,
+		UseAgent:             req.SSHUseAgent,
 	}
 
 	// 执行测试
@@ -141,6 +141,41 @@ func CancelUserTaskByAdmin(c *gin.Context) {
 	common.ResponseSuccess(c, nil, "任务已取消")
 }
 
+// UpdateTaskPriority 调整任务优先级
+// @Summary 调整任务优先级
+// @Description 管理员调整排队中任务的优先级，数值越大越先执行，仅pending状态的任务可以调整
+// @Tags 管理员管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param taskId path int true "任务ID"
+// @Param request body adminModel.UpdateTaskPriorityRequest true "调整任务优先级请求"
+// @Success 200 {object} common.Response "操作成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 401 {object} common.Response "权限不足"
+// @Router /admin/tasks/{taskId}/priority [put]
+func UpdateTaskPriority(c *gin.Context) {
+	taskID, err := strconv.ParseUint(c.Param("taskId"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的任务ID"))
+		return
+	}
+
+	var req adminModel.UpdateTaskPriorityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误"))
+		return
+	}
+
+	taskService := task.GetTaskService()
+	if err := taskService.SetTaskPriority(uint(taskID), req.Priority); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, err.Error()))
+		return
+	}
+
+	common.ResponseSuccess(c, nil, "任务优先级已调整")
+}
+
 // GetTaskOverallStats 获取任务总体统计信息
 // @Summary 获取任务总体统计信息
 // @Description 获取所有任务的总体统计信息，包括各种状态的任务数量
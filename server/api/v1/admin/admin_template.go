@@ -0,0 +1,158 @@
+package admin
+
+import (
+	"strconv"
+
+	"oneclickvirt/global"
+	"oneclickvirt/model/admin"
+	"oneclickvirt/model/common"
+	"oneclickvirt/service/admin/instance"
+	"oneclickvirt/service/task"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// CreateInstanceTemplate 管理员将实例标记为模板
+// @Summary 管理员将实例标记为模板
+// @Description 创建模板任务：在实例所在节点上复制出一份停止状态的模板副本，之后源实例的变化不影响模板。用户可从模板创建新实例并自选规格和密码
+// @Tags 管理员管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "实例ID"
+// @Param request body admin.CreateInstanceTemplateRequest true "模板名称和描述"
+// @Success 200 {object} common.Response{data=admin.InstanceTemplateTaskResponse} "任务创建成功"
+// @Failure 400 {object} common.Response "参数错误或实例状态不允许制作模板"
+// @Failure 404 {object} common.Response "实例不存在"
+// @Failure 409 {object} common.Response "模板名称已存在"
+// @Router /admin/instances/{id}/template [post]
+func CreateInstanceTemplate(c *gin.Context) {
+	instanceID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的实例ID"))
+		return
+	}
+
+	var req admin.CreateInstanceTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误: "+err.Error()))
+		return
+	}
+
+	instanceService := instance.NewService(task.GetTaskService())
+	result, err := instanceService.CreateTemplate(uint(instanceID), req)
+	if err != nil {
+		global.APP_LOG.Warn("管理员创建实例模板失败",
+			zap.Uint64("instanceID", instanceID),
+			zap.Error(err))
+		common.ResponseWithError(c, err)
+		return
+	}
+
+	common.ResponseSuccess(c, result, "模板创建任务已提交")
+}
+
+// GetInstanceTemplateList 管理员获取实例模板列表
+// @Summary 管理员获取实例模板列表
+// @Description 分页查询实例模板，可按节点和状态过滤
+// @Tags 管理员管理
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码"
+// @Param pageSize query int false "每页数量"
+// @Param keyword query string false "模板名称（模糊匹配）"
+// @Param providerId query int false "节点ID"
+// @Param status query string false "状态：creating/ready/failed"
+// @Success 200 {object} common.Response{data=common.PageResult} "获取成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 500 {object} common.Response "获取失败"
+// @Router /admin/templates [get]
+func GetInstanceTemplateList(c *gin.Context) {
+	var req admin.InstanceTemplateListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误: "+err.Error()))
+		return
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 || req.PageSize > 100 {
+		req.PageSize = 20
+	}
+
+	instanceService := instance.NewService(task.GetTaskService())
+	templates, total, err := instanceService.GetTemplateList(req)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, "获取模板列表失败"))
+		return
+	}
+	common.ResponseSuccessWithPagination(c, templates, total, req.Page, req.PageSize)
+}
+
+// UpdateInstanceTemplate 管理员更新实例模板
+// @Summary 管理员更新实例模板
+// @Description 修改模板名称、描述，或启用/停用模板。停用的模板不再对用户展示，已创建的实例不受影响
+// @Tags 管理员管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "模板ID"
+// @Param request body admin.UpdateInstanceTemplateRequest true "更新内容"
+// @Success 200 {object} common.Response{data=provider.InstanceTemplate} "更新成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 404 {object} common.Response "模板不存在"
+// @Failure 409 {object} common.Response "模板名称已存在"
+// @Router /admin/templates/{id} [put]
+func UpdateInstanceTemplate(c *gin.Context) {
+	templateID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的模板ID"))
+		return
+	}
+
+	var req admin.UpdateInstanceTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误: "+err.Error()))
+		return
+	}
+
+	instanceService := instance.NewService(task.GetTaskService())
+	template, err := instanceService.UpdateTemplate(uint(templateID), req)
+	if err != nil {
+		common.ResponseWithError(c, err)
+		return
+	}
+	common.ResponseSuccess(c, template, "模板更新成功")
+}
+
+// DeleteInstanceTemplate 管理员删除实例模板
+// @Summary 管理员删除实例模板
+// @Description 删除模板记录及节点上的模板副本，已从模板创建的实例不受影响
+// @Tags 管理员管理
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "模板ID"
+// @Success 200 {object} common.Response "删除成功"
+// @Failure 404 {object} common.Response "模板不存在"
+// @Failure 409 {object} common.Response "模板有进行中的任务"
+// @Failure 500 {object} common.Response "删除失败"
+// @Router /admin/templates/{id} [delete]
+func DeleteInstanceTemplate(c *gin.Context) {
+	templateID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的模板ID"))
+		return
+	}
+
+	instanceService := instance.NewService(task.GetTaskService())
+	if err := instanceService.DeleteTemplate(uint(templateID)); err != nil {
+		global.APP_LOG.Warn("管理员删除实例模板失败",
+			zap.Uint64("templateID", templateID),
+			zap.Error(err))
+		common.ResponseWithError(c, err)
+		return
+	}
+
+	common.ResponseSuccess(c, nil, "模板删除成功")
+}
@@ -0,0 +1,117 @@
+package admin
+
+import (
+	"strconv"
+
+	"oneclickvirt/model/admin"
+	"oneclickvirt/model/common"
+	"oneclickvirt/service/webhook"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetWebhooks 获取Webhook列表
+// @Summary 获取Webhook列表
+// @Description 获取所有实例生命周期与流量超限事件的Webhook配置，不返回签名密钥
+// @Tags 管理员管理
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} common.Response{data=object} "获取成功"
+// @Failure 500 {object} common.Response "获取失败"
+// @Router /admin/webhooks [get]
+func GetWebhooks(c *gin.Context) {
+	hooks, err := webhook.NewService().List()
+	if err != nil {
+		common.ResponseWithError(c, err)
+		return
+	}
+	common.ResponseSuccess(c, map[string]interface{}{
+		"list":   hooks,
+		"events": webhook.SupportedEvents,
+	}, "获取成功")
+}
+
+// CreateWebhook 创建Webhook
+// @Summary 创建Webhook
+// @Description 创建Webhook，推送内容使用密钥对请求体做HMAC-SHA256签名，密钥仅在创建时返回
+// @Tags 管理员管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body admin.CreateWebhookRequest true "创建Webhook请求参数"
+// @Success 200 {object} common.Response{data=admin.CreateWebhookResponse} "创建成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 500 {object} common.Response "创建失败"
+// @Router /admin/webhooks [post]
+func CreateWebhook(c *gin.Context) {
+	var req admin.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误: "+err.Error()))
+		return
+	}
+
+	result, err := webhook.NewService().Create(req)
+	if err != nil {
+		common.ResponseWithError(c, err)
+		return
+	}
+	common.ResponseSuccess(c, result, "创建Webhook成功")
+}
+
+// UpdateWebhook 更新Webhook
+// @Summary 更新Webhook
+// @Description 更新Webhook配置，未提供的字段保持不变
+// @Tags 管理员管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Webhook ID"
+// @Param request body admin.UpdateWebhookRequest true "更新Webhook请求参数"
+// @Success 200 {object} common.Response{data=admin.Webhook} "更新成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 404 {object} common.Response "Webhook不存在"
+// @Router /admin/webhooks/{id} [put]
+func UpdateWebhook(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的Webhook ID"))
+		return
+	}
+
+	var req admin.UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误: "+err.Error()))
+		return
+	}
+
+	hook, err := webhook.NewService().Update(uint(id), req)
+	if err != nil {
+		common.ResponseWithError(c, err)
+		return
+	}
+	common.ResponseSuccess(c, hook, "更新Webhook成功")
+}
+
+// DeleteWebhook 删除Webhook
+// @Summary 删除Webhook
+// @Description 删除指定Webhook
+// @Tags 管理员管理
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Webhook ID"
+// @Success 200 {object} common.Response "删除成功"
+// @Failure 404 {object} common.Response "Webhook不存在"
+// @Router /admin/webhooks/{id} [delete]
+func DeleteWebhook(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的Webhook ID"))
+		return
+	}
+
+	if err := webhook.NewService().Delete(uint(id)); err != nil {
+		common.ResponseWithError(c, err)
+		return
+	}
+	common.ResponseSuccess(c, nil, "删除Webhook成功")
+}
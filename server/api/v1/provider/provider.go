@@ -138,14 +138,16 @@ func (p *ProviderApi) GetProviderCapabilities(c *gin.Context) {
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "Provider ID"
+// @Param refresh query bool false "为true时跳过缓存，通过SSH实时获取网络信息"
 // @Success 200 {object} common.Response{data=[]object} "获取成功"
 // @Failure 404 {object} common.Response "Provider不存在"
 // @Failure 500 {object} common.Response "获取失败"
 // @Router /provider/{id}/instances [get]
 func (p *ProviderApi) ListInstances(c *gin.Context) {
 	providerID := c.Param("id")
+	refresh := c.Query("refresh") == "true"
 
-	instances, err := providerApiService.ListInstancesByProviderID(c.Request.Context(), providerID)
+	instances, err := providerApiService.ListInstancesByProviderID(c.Request.Context(), providerID, refresh)
 	if err != nil {
 		if err.Error() == "Provider不存在" {
 			c.JSON(http.StatusNotFound, gin.H{
@@ -220,8 +222,9 @@ func (p *ProviderApi) CreateInstance(c *gin.Context) {
 func (p *ProviderApi) GetInstance(c *gin.Context) {
 	providerID := c.Param("id")
 	instanceName := c.Param("name")
+	refresh := c.Query("refresh") == "true"
 
-	instance, err := providerApiService.GetInstanceByProviderID(c.Request.Context(), providerID, instanceName)
+	instance, err := providerApiService.GetInstanceByProviderID(c.Request.Context(), providerID, instanceName, refresh)
 	if err != nil {
 		if err.Error() == "Provider不存在" {
 			c.JSON(http.StatusNotFound, gin.H{
@@ -6,6 +6,8 @@ import (
 
 	"oneclickvirt/global"
 	"oneclickvirt/model/common"
+	providerModel "oneclickvirt/model/provider"
+	providerService "oneclickvirt/service/provider"
 	"oneclickvirt/utils"
 
 	"github.com/gin-gonic/gin"
@@ -65,6 +67,66 @@ func HealthCheck(c *gin.Context) {
 	})
 }
 
+// Liveness 存活探针
+// @Tags Health
+// @Summary 存活探针
+// @Description 只表示进程存活，不依赖数据库或Provider，供容器编排的liveness探测使用
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /healthz [get]
+func Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Readiness 就绪探针
+// @Tags Health
+// @Summary 就绪探针
+// @Description 检查配置已加载、数据库可连接，且存在启用的Provider时至少一个已连接。全部通过返回200，否则返回503
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 503 {object} map[string]interface{}
+// @Router /readyz [get]
+func Readiness(c *gin.Context) {
+	ready := true
+	checks := make(map[string]interface{})
+
+	configLoaded := global.APP_VP != nil
+	checks["config"] = gin.H{"ready": configLoaded}
+	ready = ready && configLoaded
+
+	dbReady := true
+	dbCheck := gin.H{"ready": true}
+	if err := utils.CheckDBHealth(); err != nil {
+		dbReady = false
+		dbCheck = gin.H{"ready": false, "error": err.Error()}
+	}
+	checks["database"] = dbCheck
+	ready = ready && dbReady
+
+	// 数据库不可用时无法确定启用的Provider数量，只返回内存中的连接状态
+	loaded, connected := providerService.GetProviderService().ConnectionSummary()
+	providerCheck := gin.H{"loaded": loaded, "connected": connected}
+	if dbReady {
+		var active int64
+		if err := global.APP_DB.Model(&providerModel.Provider{}).Where("status = ?", "active").Count(&active).Error; err == nil {
+			providerCheck["active"] = active
+			// 尚未添加Provider的新部署视为就绪，否则无法通过面板完成配置
+			providerReady := active == 0 || connected > 0
+			providerCheck["ready"] = providerReady
+			ready = ready && providerReady
+		}
+	}
+	checks["providers"] = providerCheck
+
+	status := "ready"
+	statusCode := http.StatusOK
+	if !ready {
+		status = "not_ready"
+		statusCode = http.StatusServiceUnavailable
+	}
+	c.JSON(statusCode, gin.H{"status": status, "checks": checks})
+}
+
 // DatabaseStatsAPI 数据库统计信息API
 // @Tags Health
 // @Summary 数据库统计信息
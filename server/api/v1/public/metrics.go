@@ -0,0 +1,65 @@
+package public
+
+import (
+	"crypto/subtle"
+	"net"
+	"net/http"
+	"strings"
+
+	"oneclickvirt/global"
+	"oneclickvirt/service/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+var metricsHandler = metrics.Handler()
+
+// Metrics Prometheus指标
+// @Tags Health
+// @Summary Prometheus指标
+// @Description 以Prometheus文本格式导出Provider实例数量、当月流量、任务队列深度和SSH命令耗时等指标。需携带system.metrics-token对应的Bearer Token，或来源IP在system.metrics-allow-ips中；两者都未配置时拒绝访问
+// @Produce plain
+// @Success 200 {string} string "Prometheus指标"
+// @Failure 401 {string} string "Token无效"
+// @Failure 403 {string} string "未配置访问凭据或来源IP不在允许列表中"
+// @Router /metrics [get]
+func Metrics(c *gin.Context) {
+	cfg := global.APP_CONFIG.System
+	if token := cfg.MetricsToken; token != "" {
+		provided := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) == 1 {
+			metricsHandler.ServeHTTP(c.Writer, c.Request)
+			return
+		}
+	}
+	// 按TCP连接地址匹配，不使用可被伪造的X-Forwarded-For
+	if metricsIPAllowed(c.RemoteIP(), cfg.MetricsAllowIPs) {
+		metricsHandler.ServeHTTP(c.Writer, c.Request)
+		return
+	}
+	if cfg.MetricsToken != "" {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	c.AbortWithStatus(http.StatusForbidden)
+}
+
+// metricsIPAllowed 判断来源IP是否在允许列表中，列表项可以是IP或CIDR
+func metricsIPAllowed(remoteIP string, allowed []string) bool {
+	ip := net.ParseIP(remoteIP)
+	if ip == nil {
+		return false
+	}
+	for _, item := range allowed {
+		if allowedIP := net.ParseIP(item); allowedIP != nil {
+			if allowedIP.Equal(ip) {
+				return true
+			}
+			continue
+		}
+		if _, ipNet, err := net.ParseCIDR(item); err == nil && ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
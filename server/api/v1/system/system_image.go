@@ -12,6 +12,7 @@ import (
 
 	"oneclickvirt/global"
 	systemModel "oneclickvirt/model/system"
+	"oneclickvirt/provider"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -25,10 +26,10 @@ type SystemImageResponse struct {
 // CreateSystemImageRequest 创建系统镜像请求
 type CreateSystemImageRequest struct {
 	Name         string `json:"name" binding:"required"`
-	ProviderType string `json:"providerType" binding:"required,oneof=proxmox lxd incus docker"`
+	ProviderType string `json:"providerType" binding:"required,oneof=proxmox lxd incus docker podman"`
 	InstanceType string `json:"instanceType" binding:"required,oneof=vm container"`
 	Architecture string `json:"architecture" binding:"required,oneof=amd64 arm64 s390x"`
-	URL          string `json:"url" binding:"required,url"`
+	URL          string `json:"url" binding:"omitempty,url"` // Docker/Podman容器镜像名称为仓库引用时可为空
 	Checksum     string `json:"checksum"`
 	Size         int64  `json:"size"`
 	Description  string `json:"description"`
@@ -43,7 +44,7 @@ type CreateSystemImageRequest struct {
 // UpdateSystemImageRequest 更新系统镜像请求
 type UpdateSystemImageRequest struct {
 	Name         string `json:"name"`
-	ProviderType string `json:"providerType" binding:"omitempty,oneof=proxmox lxd incus docker"`
+	ProviderType string `json:"providerType" binding:"omitempty,oneof=proxmox lxd incus docker podman"`
 	InstanceType string `json:"instanceType" binding:"omitempty,oneof=vm container"`
 	Architecture string `json:"architecture" binding:"omitempty,oneof=amd64 arm64 s390x"`
 	URL          string `json:"url" binding:"omitempty,url"`
@@ -176,7 +177,7 @@ func CreateSystemImage(c *gin.Context) {
 	}
 
 	// 验证文件扩展名
-	if err := validateImageURL(req.ProviderType, req.InstanceType, req.URL); err != nil {
+	if err := validateImageURL(req.ProviderType, req.InstanceType, req.Name, req.URL); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"code": 400,
 			"msg":  err.Error(),
@@ -299,7 +300,11 @@ func UpdateSystemImage(c *gin.Context) {
 		if instanceType == "" {
 			instanceType = image.InstanceType
 		}
-		if err := validateImageURL(providerType, instanceType, req.URL); err != nil {
+		name := req.Name
+		if name == "" {
+			name = image.Name
+		}
+		if err := validateImageURL(providerType, instanceType, name, req.URL); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"code": 400,
 				"msg":  err.Error(),
@@ -518,7 +523,14 @@ func GetAvailableSystemImages(c *gin.Context) {
 }
 
 // validateImageURL 验证镜像URL的文件扩展名
-func validateImageURL(providerType, instanceType, url string) error {
+// Docker/Podman容器镜像名称为仓库引用（如 nginx:1.25）时直接从仓库拉取，不使用URL
+func validateImageURL(providerType, instanceType, name, url string) error {
+	if (providerType == "docker" || providerType == "podman") && instanceType == "container" && provider.IsRegistryReference(name) {
+		return nil
+	}
+	if url == "" {
+		return fmt.Errorf("镜像地址不能为空")
+	}
 	switch providerType {
 	case "proxmox":
 		if instanceType == "vm" && !strings.HasSuffix(url, ".qcow2") {
@@ -531,9 +543,9 @@ func validateImageURL(providerType, instanceType, url string) error {
 		if !strings.HasSuffix(url, ".zip") {
 			return fmt.Errorf("LXD/Incus镜像地址必须是zip文件")
 		}
-	case "docker":
+	case "docker", "podman":
 		if instanceType == "container" && !strings.HasSuffix(url, ".tar.gz") {
-			return fmt.Errorf("Docker容器镜像地址必须是.tar.gz文件")
+			return fmt.Errorf("Docker/Podman容器镜像地址必须是.tar.gz文件")
 		}
 	}
 	return nil
@@ -0,0 +1,64 @@
+package traffic
+
+import (
+	"fmt"
+	"net/http"
+
+	"oneclickvirt/global"
+	"oneclickvirt/model/admin"
+	"oneclickvirt/model/common"
+	"oneclickvirt/service/billing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// GetBillingReport 生成流量计费报表
+// @Summary 生成流量计费报表
+// @Description 按用户或Provider生成指定自然月的流量计费报表，超出流量配额的用量按配置的单价计费。用量已处理pmacct重启导致的计数器重置，format=csv时以附件形式下载
+// @Tags 管理员流量
+// @Produce json
+// @Produce text/csv
+// @Security ApiKeyAuth
+// @Param scope query string true "统计维度：user/provider"
+// @Param period query string true "账期，格式YYYY-MM"
+// @Param id query int false "只统计指定的用户或Provider"
+// @Param format query string false "输出格式：json/csv，默认json"
+// @Success 200 {object} common.Response{data=billing.Report} "生成成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 500 {object} common.Response "生成失败"
+// @Router /admin/traffic/billing-report [get]
+func (api *AdminTrafficAPI) GetBillingReport(c *gin.Context) {
+	var req admin.BillingReportRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误: "+err.Error()))
+		return
+	}
+
+	billingService := billing.NewService()
+	report, err := billingService.GenerateReport(req.Scope, req.Period, req.ID)
+	if err != nil {
+		global.APP_LOG.Error("生成流量计费报表失败",
+			zap.String("scope", req.Scope),
+			zap.String("period", req.Period),
+			zap.Uint("id", req.ID),
+			zap.Error(err))
+		common.ResponseWithError(c, err)
+		return
+	}
+
+	if req.Format != "csv" {
+		common.ResponseSuccess(c, report, "生成计费报表成功")
+		return
+	}
+
+	data, err := billingService.ExportCSV(report)
+	if err != nil {
+		global.APP_LOG.Error("导出流量计费报表失败", zap.Error(err))
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, "导出计费报表失败"))
+		return
+	}
+	fileName := fmt.Sprintf("billing-%s-%s.csv", report.Scope, report.Period)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", data)
+}
@@ -1,7 +1,9 @@
 package traffic
 
 import (
+	"fmt"
 	"strconv"
+	"strings"
 
 	"oneclickvirt/global"
 	"oneclickvirt/model/common"
@@ -15,13 +17,13 @@ import (
 // GetInstanceTrafficHistory 获取实例流量历史数据
 // @Tags 流量管理
 // @Summary 获取实例流量历史
-// @Description 获取指定实例的历史流量数据，支持5分钟到24小时的灵活时间范围
+// @Description 获取指定实例的历史流量数据，支持5分钟到24小时的灵活时间范围，7d、30d按天返回日度聚合数据
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param instance_id path int true "实例ID"
-// @Param period query string false "时间范围: 5m, 10m, 15m, 30m, 45m, 1h, 6h, 12h, 24h" default(1h)
-// @Param interval query int false "数据点间隔（分钟），0表示自动选择，可选: 5, 15, 30, 60" default(0)
+// @Param period query string false "时间范围: 5m, 10m, 15m, 30m, 45m, 1h, 6h, 12h, 24h, 7d, 30d" default(1h)
+// @Param interval query int false "数据点间隔（分钟），0表示自动选择，可选: 5, 15, 30, 60；7d、30d固定每天一个点" default(0)
 // @Param includeArchived query bool false "是否包含已归档数据（重置前的历史记录）" default(false)
 // @Success 200 {object} common.Response{data=[]monitoring.InstanceTrafficHistory}
 // @Failure 400 {object} common.Response
@@ -51,10 +53,10 @@ func (api *UserTrafficAPI) GetInstanceTrafficHistory(c *gin.Context) {
 	// 验证period参数
 	validPeriods := map[string]bool{
 		"5m": true, "10m": true, "15m": true, "30m": true, "45m": true,
-		"1h": true, "6h": true, "12h": true, "24h": true,
+		"1h": true, "6h": true, "12h": true, "24h": true, "7d": true, "30d": true,
 	}
 	if !validPeriods[period] {
-		common.ResponseWithError(c, common.NewError(common.CodeInvalidParam, "period参数必须是5m, 10m, 15m, 30m, 45m, 1h, 6h, 12h, 24h之一"))
+		common.ResponseWithError(c, common.NewError(common.CodeInvalidParam, "period参数必须是5m, 10m, 15m, 30m, 45m, 1h, 6h, 12h, 24h, 7d, 30d之一"))
 		return
 	}
 
@@ -112,6 +114,123 @@ func (api *UserTrafficAPI) GetInstanceTrafficHistory(c *gin.Context) {
 	common.ResponseSuccess(c, histories, "获取流量历史成功")
 }
 
+// maxMultiInstanceHistoryIDs 批量查询流量历史时一次最多允许的实例数量
+const maxMultiInstanceHistoryIDs = 50
+
+// GetMultiInstanceTrafficHistory 批量获取多个实例的流量历史数据
+// @Tags 流量管理
+// @Summary 批量获取实例流量历史
+// @Description 一次获取多个实例的历史流量数据，用于绘制多实例折线图，返回以实例ID为键的数据序列
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param instanceIds query string true "实例ID列表，逗号分隔，最多50个"
+// @Param period query string false "时间范围: 5m, 10m, 15m, 30m, 45m, 1h, 6h, 12h, 24h" default(1h)
+// @Param interval query int false "数据点间隔（分钟），0表示自动选择，可选: 5, 15, 30, 60" default(0)
+// @Success 200 {object} common.Response{data=map[string][]monitoring.InstanceTrafficHistory}
+// @Failure 400 {object} common.Response
+// @Failure 401 {object} common.Response
+// @Failure 403 {object} common.Response
+// @Failure 500 {object} common.Response
+// @Router /v1/user/traffic/instances/history [get]
+func (api *UserTrafficAPI) GetMultiInstanceTrafficHistory(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		common.ResponseWithError(c, common.NewError(common.CodeUnauthorized, "用户未登录"))
+		return
+	}
+
+	// 解析实例ID列表并去重
+	var instanceIDs []uint
+	seen := make(map[uint]bool)
+	for _, part := range strings.Split(c.Query("instanceIds"), ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(part, 10, 32)
+		if err != nil {
+			common.ResponseWithError(c, common.NewError(common.CodeInvalidParam, "无效的实例ID: "+part))
+			return
+		}
+		if !seen[uint(id)] {
+			seen[uint(id)] = true
+			instanceIDs = append(instanceIDs, uint(id))
+		}
+	}
+	if len(instanceIDs) == 0 {
+		common.ResponseWithError(c, common.NewError(common.CodeInvalidParam, "instanceIds参数不能为空"))
+		return
+	}
+	if len(instanceIDs) > maxMultiInstanceHistoryIDs {
+		common.ResponseWithError(c, common.NewError(common.CodeInvalidParam, fmt.Sprintf("一次最多查询%d个实例", maxMultiInstanceHistoryIDs)))
+		return
+	}
+
+	// 获取查询参数
+	period := c.DefaultQuery("period", "1h")
+	interval, err := strconv.Atoi(c.DefaultQuery("interval", "0"))
+	if err != nil || interval < 0 {
+		interval = 0
+	}
+
+	// 验证period参数
+	validPeriods := map[string]bool{
+		"5m": true, "10m": true, "15m": true, "30m": true, "45m": true,
+		"1h": true, "6h": true, "12h": true, "24h": true,
+	}
+	if !validPeriods[period] {
+		common.ResponseWithError(c, common.NewError(common.CodeInvalidParam, "period参数必须是5m, 10m, 15m, 30m, 45m, 1h, 6h, 12h, 24h之一"))
+		return
+	}
+
+	// 验证interval参数
+	if interval != 0 && interval != 5 && interval != 15 && interval != 30 && interval != 60 {
+		common.ResponseWithError(c, common.NewError(common.CodeInvalidParam, "interval参数必须是0, 5, 15, 30, 60之一"))
+		return
+	}
+
+	// 先校验所有实例的归属，任一实例不存在或无权限时整体拒绝
+	userType, _ := c.Get("user_type")
+	isAdmin := userType == "admin"
+
+	var owners []struct {
+		ID     uint
+		UserID uint
+	}
+	if err := global.APP_DB.Table("instances").
+		Select("id, user_id").
+		Where("id IN ? AND deleted_at IS NULL", instanceIDs).
+		Scan(&owners).Error; err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, "查询实例失败"))
+		return
+	}
+	if len(owners) != len(instanceIDs) {
+		common.ResponseWithError(c, common.NewError(common.CodeForbidden, "实例不存在或无权限"))
+		return
+	}
+	if !isAdmin {
+		for _, owner := range owners {
+			if owner.UserID != userID.(uint) {
+				common.ResponseWithError(c, common.NewError(common.CodeForbidden, "无权限访问该实例"))
+				return
+			}
+		}
+	}
+
+	historyService := traffic.NewHistoryService()
+	histories, err := historyService.GetMultiInstanceTrafficHistory(instanceIDs, period, interval)
+	if err != nil {
+		global.APP_LOG.Error("批量获取实例流量历史失败",
+			zap.Uints("instanceIDs", instanceIDs),
+			zap.Error(err))
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, "获取流量历史失败"))
+		return
+	}
+
+	common.ResponseSuccess(c, histories, "获取流量历史成功")
+}
+
 // GetProviderTrafficHistory 获取Provider流量历史数据
 // @Tags 流量管理-管理员
 // @Summary 获取Provider流量历史
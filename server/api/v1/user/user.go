@@ -181,7 +181,7 @@ func GetUserInstances(c *gin.Context) {
 
 // InstanceAction 实例操作
 // @Summary 实例操作
-// @Description 对用户实例执行操作（启动、停止、重启等）
+// @Description 对用户实例执行操作（启动、停止、重启等）。配置了删除等待期时，删除操作先停机保留实例，等待期结束后由系统删除，等待期内再次删除则立即删除
 // @Tags 用户管理
 // @Accept json
 // @Produce json
@@ -611,7 +611,7 @@ func CancelUserTask(c *gin.Context) {
 
 // CreateUserInstance 创建实例
 // @Summary 创建实例
-// @Description 用户创建新的虚拟机或容器实例（异步处理）
+// @Description 用户创建新的虚拟机或容器实例（异步处理），未指定节点时按配置的策略自动选择节点并在响应中返回
 // @Tags 用户管理
 // @Accept json
 // @Produce json
@@ -649,6 +649,10 @@ func CreateUserInstance(c *gin.Context) {
 		"status":     task.Status,
 		"message":    "实例创建任务已提交，正在后台处理",
 		"created_at": task.CreatedAt,
+		"providerId": task.ProviderID,
+	}
+	if task.Provider != nil {
+		responseData["providerName"] = task.Provider.Name
 	}
 
 	common.ResponseSuccess(c, responseData, "实例创建任务已提交")
@@ -797,7 +801,7 @@ func GetActiveReservations(c *gin.Context) {
 
 // GetInstanceMonitoring 获取实例监控数据
 // @Summary 获取实例监控数据
-// @Description 获取用户实例的监控数据，包括流量统计信息
+// @Description 获取用户实例的监控数据，包括流量统计信息和根磁盘使用情况（运行中的实例，结果缓存5分钟）
 // @Tags 用户管理
 // @Accept json
 // @Produce json
@@ -901,6 +905,470 @@ func ResetInstancePassword(c *gin.Context) {
 	common.ResponseSuccess(c, response, "密码重置任务创建成功")
 }
 
+// CloneInstance 用户克隆实例
+// @Summary 用户克隆实例
+// @Description 按源实例规格克隆出一个新实例，克隆实例计入用户配额并重新分配SSH密码和端口，创建异步任务执行克隆操作
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "源实例ID"
+// @Param request body user.CloneInstanceRequest true "克隆实例请求参数（名称可为空，自动生成）"
+// @Success 200 {object} common.Response{data=user.CloneInstanceResponse} "任务创建成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 401 {object} common.Response "用户未登录"
+// @Failure 403 {object} common.Response "实例不存在或无权限"
+// @Failure 500 {object} common.Response "创建任务失败"
+// @Router /user/instances/{id}/clone [post]
+func CloneInstance(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeUnauthorized, err.Error()))
+		return
+	}
+
+	instanceID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的实例ID"))
+		return
+	}
+
+	var req user.CloneInstanceRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误"))
+			return
+		}
+	}
+
+	userInstanceService := userService.NewService()
+	response, err := userInstanceService.CloneInstance(userID, uint(instanceID), req)
+	if err != nil {
+		global.APP_LOG.Error("用户创建克隆实例任务失败",
+			zap.Uint("userID", userID),
+			zap.Uint64("instanceID", instanceID),
+			zap.Error(err))
+		if err.Error() == "无权限访问此实例" {
+			common.ResponseWithError(c, common.NewError(common.CodeForbidden, err.Error()))
+			return
+		}
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, err.Error()))
+		return
+	}
+
+	common.ResponseSuccess(c, response, "克隆任务创建成功")
+}
+
+// CreateInstanceFromTemplate 用户从模板创建实例
+// @Summary 用户从模板创建实例
+// @Description 在模板所在节点上复制模板副本创建新实例，按选择的规格调整资源并设置root密码，规格未选择时使用模板默认规格，密码为空时自动生成。可用模板通过实例配置接口获取
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "模板ID"
+// @Param request body user.CreateInstanceFromTemplateRequest true "从模板创建实例请求参数"
+// @Success 200 {object} common.Response{data=user.CreateInstanceFromTemplateResponse} "任务创建成功"
+// @Failure 400 {object} common.Response "参数错误或模板不可用"
+// @Failure 401 {object} common.Response "用户未登录"
+// @Failure 404 {object} common.Response "模板不存在"
+// @Failure 500 {object} common.Response "创建任务失败"
+// @Router /user/templates/{id}/instances [post]
+func CreateInstanceFromTemplate(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeUnauthorized, err.Error()))
+		return
+	}
+
+	templateID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的模板ID"))
+		return
+	}
+
+	var req user.CreateInstanceFromTemplateRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误"))
+			return
+		}
+	}
+
+	userInstanceService := userService.NewService()
+	response, err := userInstanceService.CreateInstanceFromTemplate(userID, uint(templateID), req)
+	if err != nil {
+		global.APP_LOG.Warn("用户从模板创建实例失败",
+			zap.Uint("userID", userID),
+			zap.Uint64("templateID", templateID),
+			zap.Error(err))
+		common.ResponseWithError(c, err)
+		return
+	}
+
+	common.ResponseSuccess(c, response, "实例创建任务已提交")
+}
+
+// UpdateInstanceTags 用户更新实例标签
+// @Summary 用户更新实例标签
+// @Description 整体替换实例标签，标签保存在数据库中，并尽力同步到LXD/Incus的user.*配置
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "实例ID"
+// @Param request body user.UpdateInstanceTagsRequest true "实例标签"
+// @Success 200 {object} common.Response{data=map[string]string} "更新成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 401 {object} common.Response "用户未登录"
+// @Failure 403 {object} common.Response "实例不存在或无权限"
+// @Failure 500 {object} common.Response "更新失败"
+// @Router /user/instances/{id}/tags [put]
+func UpdateInstanceTags(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeUnauthorized, err.Error()))
+		return
+	}
+
+	instanceID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的实例ID"))
+		return
+	}
+
+	var req user.UpdateInstanceTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误"))
+		return
+	}
+	if err := utils.ValidateInstanceTags(req.Tags); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, err.Error()))
+		return
+	}
+
+	userInstanceService := userService.NewService()
+	tags, err := userInstanceService.UpdateInstanceTags(userID, uint(instanceID), req.Tags)
+	if err != nil {
+		global.APP_LOG.Error("用户更新实例标签失败",
+			zap.Uint("userID", userID),
+			zap.Uint64("instanceID", instanceID),
+			zap.Error(err))
+		if err.Error() == "无权限访问此实例" {
+			common.ResponseWithError(c, common.NewError(common.CodeForbidden, err.Error()))
+			return
+		}
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, err.Error()))
+		return
+	}
+
+	common.ResponseSuccess(c, tags, "实例标签更新成功")
+}
+
+// ExecInstanceCommand 在实例内执行命令
+// @Summary 在实例内执行命令
+// @Description 在运行中的实例内执行一条命令并返回stdout、stderr和退出码。需要管理员为用户开启执行权限，命令受白名单/黑名单、超时和输出大小限制
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "实例ID"
+// @Param request body user.ExecInstanceCommandRequest true "执行命令请求"
+// @Success 200 {object} common.Response{data=user.ExecInstanceCommandResponse} "执行完成"
+// @Failure 400 {object} common.Response "参数错误或命令不被允许"
+// @Failure 401 {object} common.Response "用户未登录"
+// @Failure 403 {object} common.Response "无执行权限"
+// @Failure 500 {object} common.Response "执行失败"
+// @Router /user/instances/{id}/exec [post]
+func ExecInstanceCommand(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeUnauthorized, err.Error()))
+		return
+	}
+
+	instanceID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的实例ID"))
+		return
+	}
+
+	var req user.ExecInstanceCommandRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误"))
+		return
+	}
+
+	userInstanceService := userService.NewService()
+	result, err := userInstanceService.ExecInstanceCommand(userID, uint(instanceID), req.Command)
+	if err != nil {
+		global.APP_LOG.Warn("用户执行实例命令失败",
+			zap.Uint("userID", userID),
+			zap.Uint64("instanceID", instanceID),
+			zap.Error(err))
+		common.ResponseWithError(c, err)
+		return
+	}
+
+	common.ResponseSuccess(c, result)
+}
+
+// GetInstanceLogs 获取实例日志
+// @Summary 获取实例日志
+// @Description 获取实例的最近日志：Docker为容器日志，Incus/LXD为控制台日志，Proxmox为实例内的系统日志（虚拟机需要guest agent）。since过滤仅Docker和Proxmox支持，输出受大小限制
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "实例ID"
+// @Param lines query int false "日志行数" default(200)
+// @Param since query string false "只返回该时间之后的日志（RFC3339）"
+// @Success 200 {object} common.Response{data=user.InstanceLogsResponse} "获取成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 401 {object} common.Response "用户未登录"
+// @Failure 403 {object} common.Response "无权限访问此实例"
+// @Failure 500 {object} common.Response "获取失败"
+// @Router /user/instances/{id}/logs [get]
+func GetInstanceLogs(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeUnauthorized, err.Error()))
+		return
+	}
+
+	instanceID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的实例ID"))
+		return
+	}
+
+	var req user.InstanceLogsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误"))
+		return
+	}
+
+	userInstanceService := userService.NewService()
+	result, err := userInstanceService.GetInstanceLogs(userID, uint(instanceID), req)
+	if err != nil {
+		common.ResponseWithError(c, err)
+		return
+	}
+
+	common.ResponseSuccess(c, result)
+}
+
+// ExtendInstance 延长实例有效期
+// @Summary 延长实例有效期
+// @Description 延长实例的到期时间。单次续期时长和续期后的最长有效期受管理员策略限制，且不超过节点的到期时间，实例到期后会被自动删除
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "实例ID"
+// @Param request body user.ExtendInstanceRequest true "续期请求"
+// @Success 200 {object} common.Response{data=user.ExtendInstanceResponse} "续期成功"
+// @Failure 400 {object} common.Response "参数错误或超出续期限制"
+// @Failure 401 {object} common.Response "用户未登录"
+// @Failure 500 {object} common.Response "续期失败"
+// @Router /user/instances/{id}/extend [post]
+func ExtendInstance(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeUnauthorized, err.Error()))
+		return
+	}
+
+	instanceID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的实例ID"))
+		return
+	}
+
+	var req user.ExtendInstanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误"))
+		return
+	}
+
+	userInstanceService := userService.NewService()
+	result, err := userInstanceService.ExtendInstance(userID, uint(instanceID), req.Hours)
+	if err != nil {
+		common.ResponseWithError(c, err)
+		return
+	}
+
+	common.ResponseSuccess(c, result, "实例续期成功")
+}
+
+// UndeleteInstance 撤销实例删除
+// @Summary 撤销实例删除
+// @Description 在删除等待期内撤销删除，实例保持停机状态，需要手动启动
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "实例ID"
+// @Success 200 {object} common.Response{data=user.UndeleteInstanceResponse} "撤销成功"
+// @Failure 400 {object} common.Response "实例未计划删除或等待期已结束"
+// @Failure 401 {object} common.Response "用户未登录"
+// @Failure 403 {object} common.Response "无权限访问此实例"
+// @Router /user/instances/{id}/undelete [post]
+func UndeleteInstance(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeUnauthorized, err.Error()))
+		return
+	}
+
+	instanceID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的实例ID"))
+		return
+	}
+
+	result, err := userService.NewService().UndeleteInstance(userID, uint(instanceID))
+	if err != nil {
+		common.ResponseWithError(c, err)
+		return
+	}
+
+	common.ResponseSuccess(c, result, "已撤销删除")
+}
+
+// GetInstanceFirewallRules 获取实例防火墙规则
+// @Summary 获取实例防火墙规则
+// @Description 获取实例的防火墙规则列表，规则作用于转发到实例的新建连接
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "实例ID"
+// @Success 200 {object} common.Response{data=[]provider.InstanceFirewallRule} "获取成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 401 {object} common.Response "用户未登录"
+// @Failure 403 {object} common.Response "无权限访问此实例"
+// @Router /user/instances/{id}/firewall-rules [get]
+func GetInstanceFirewallRules(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeUnauthorized, err.Error()))
+		return
+	}
+
+	instanceID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的实例ID"))
+		return
+	}
+
+	userInstanceService := userService.NewService()
+	rules, err := userInstanceService.ListFirewallRules(userID, uint(instanceID))
+	if err != nil {
+		common.ResponseWithError(c, err)
+		return
+	}
+
+	common.ResponseSuccess(c, rules)
+}
+
+// AddInstanceFirewallRule 添加实例防火墙规则
+// @Summary 添加实例防火墙规则
+// @Description 为实例添加按协议、端口和来源地址匹配的放行或拒绝规则，端口为实例内部端口。allow规则优先于deny规则，实例运行中时立即生效
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "实例ID"
+// @Param request body user.AddFirewallRuleRequest true "防火墙规则"
+// @Success 200 {object} common.Response{data=provider.InstanceFirewallRule} "添加成功"
+// @Failure 400 {object} common.Response "参数错误或超出规则数量限制"
+// @Failure 401 {object} common.Response "用户未登录"
+// @Failure 403 {object} common.Response "无权限访问此实例"
+// @Failure 409 {object} common.Response "规则已存在"
+// @Failure 500 {object} common.Response "下发规则失败"
+// @Router /user/instances/{id}/firewall-rules [post]
+func AddInstanceFirewallRule(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeUnauthorized, err.Error()))
+		return
+	}
+
+	instanceID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的实例ID"))
+		return
+	}
+
+	var req user.AddFirewallRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误"))
+		return
+	}
+
+	userInstanceService := userService.NewService()
+	rule, err := userInstanceService.AddFirewallRule(userID, uint(instanceID), req)
+	if err != nil {
+		global.APP_LOG.Warn("用户添加实例防火墙规则失败",
+			zap.Uint("userID", userID),
+			zap.Uint64("instanceID", instanceID),
+			zap.Error(err))
+		common.ResponseWithError(c, err)
+		return
+	}
+
+	common.ResponseSuccess(c, rule, "防火墙规则添加成功")
+}
+
+// DeleteInstanceFirewallRule 删除实例防火墙规则
+// @Summary 删除实例防火墙规则
+// @Description 删除实例的一条防火墙规则，实例运行中时同时从宿主机移除
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "实例ID"
+// @Param ruleId path int true "规则ID"
+// @Success 200 {object} common.Response "删除成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 401 {object} common.Response "用户未登录"
+// @Failure 403 {object} common.Response "无权限访问此实例"
+// @Failure 404 {object} common.Response "规则不存在"
+// @Failure 500 {object} common.Response "移除规则失败"
+// @Router /user/instances/{id}/firewall-rules/{ruleId} [delete]
+func DeleteInstanceFirewallRule(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeUnauthorized, err.Error()))
+		return
+	}
+
+	instanceID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的实例ID"))
+		return
+	}
+	ruleID, err := strconv.ParseUint(c.Param("ruleId"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的规则ID"))
+		return
+	}
+
+	userInstanceService := userService.NewService()
+	if err := userInstanceService.DeleteFirewallRule(userID, uint(instanceID), uint(ruleID)); err != nil {
+		global.APP_LOG.Warn("用户删除实例防火墙规则失败",
+			zap.Uint("userID", userID),
+			zap.Uint64("instanceID", instanceID),
+			zap.Uint64("ruleID", ruleID),
+			zap.Error(err))
+		common.ResponseWithError(c, err)
+		return
+	}
+
+	common.ResponseSuccess(c, nil, "防火墙规则删除成功")
+}
+
 // GetInstanceNewPassword 获取实例重置后的新密码
 // @Summary 获取实例重置后的新密码
 // @Description 通过任务ID获取实例重置后的新密码
@@ -0,0 +1,68 @@
+package user
+
+import (
+	"strconv"
+
+	"oneclickvirt/global"
+	"oneclickvirt/model/common"
+	"oneclickvirt/model/user"
+	userService "oneclickvirt/service/user"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// UpdateInstanceLimits 调整实例CPU和内存限制
+// @Summary 调整实例CPU和内存限制
+// @Description 在不重建实例的情况下调整CPU和内存，调整前按用户等级配额和节点剩余资源重新校验。Docker、容器类实例即时生效；运行中的虚拟机需要重启才能生效，此时返回restartRequired，设置restart=true时会自动提交重启任务
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "实例ID"
+// @Param request body user.UpdateInstanceLimitsRequest true "资源调整请求"
+// @Success 200 {object} common.Response{data=user.UpdateInstanceLimitsResponse} "调整成功"
+// @Failure 400 {object} common.Response "参数错误或超出配额"
+// @Failure 401 {object} common.Response "用户未登录"
+// @Failure 403 {object} common.Response "无权限访问此实例"
+// @Failure 500 {object} common.Response "调整失败"
+// @Router /user/instances/{id}/limits [put]
+func UpdateInstanceLimits(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeUnauthorized, err.Error()))
+		return
+	}
+
+	instanceID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的实例ID"))
+		return
+	}
+
+	var req user.UpdateInstanceLimitsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误: "+err.Error()))
+		return
+	}
+
+	result, err := userService.NewService().UpdateInstanceLimits(userID, uint(instanceID), req)
+	if err != nil {
+		global.APP_LOG.Warn("调整实例资源限制失败",
+			zap.Uint("userID", userID),
+			zap.Uint64("instanceID", instanceID),
+			zap.Error(err))
+		common.ResponseWithError(c, err)
+		return
+	}
+
+	message := "实例资源已调整"
+	if result.RestartRequired {
+		if result.Restarted {
+			message = "实例资源已调整，已提交重启任务使配置生效"
+		} else {
+			message = "实例资源已调整，需要重启实例后生效"
+		}
+	}
+	common.ResponseSuccess(c, result, message)
+}
@@ -0,0 +1,128 @@
+package user
+
+import (
+	"strconv"
+
+	"oneclickvirt/global"
+	"oneclickvirt/model/common"
+	"oneclickvirt/model/user"
+	userService "oneclickvirt/service/user"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// GetInstanceSchedule 获取实例定时开关机计划
+// @Summary 获取实例定时开关机计划
+// @Description 获取实例的定时开关机计划及下次开关机时间，未设置时返回null
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "实例ID"
+// @Success 200 {object} common.Response{data=user.InstanceScheduleResponse} "获取成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 401 {object} common.Response "用户未登录"
+// @Failure 403 {object} common.Response "无权限访问此实例"
+// @Router /user/instances/{id}/schedule [get]
+func GetInstanceSchedule(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeUnauthorized, err.Error()))
+		return
+	}
+
+	instanceID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的实例ID"))
+		return
+	}
+
+	schedule, err := userService.NewService().GetInstanceSchedule(userID, uint(instanceID))
+	if err != nil {
+		common.ResponseWithError(c, err)
+		return
+	}
+
+	common.ResponseSuccess(c, schedule)
+}
+
+// SetInstanceSchedule 设置实例定时开关机计划
+// @Summary 设置实例定时开关机计划
+// @Description 按cron表达式（分 时 日 月 周）在指定时区定时开机、关机，同一表达式相邻两次触发需间隔至少1小时。定时操作会创建开关机任务并记录在任务历史中
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "实例ID"
+// @Param request body user.SetInstanceScheduleRequest true "定时开关机计划"
+// @Success 200 {object} common.Response{data=user.InstanceScheduleResponse} "设置成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 401 {object} common.Response "用户未登录"
+// @Failure 403 {object} common.Response "无权限访问此实例"
+// @Router /user/instances/{id}/schedule [put]
+func SetInstanceSchedule(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeUnauthorized, err.Error()))
+		return
+	}
+
+	instanceID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的实例ID"))
+		return
+	}
+
+	var req user.SetInstanceScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误: "+err.Error()))
+		return
+	}
+
+	schedule, err := userService.NewService().SetInstanceSchedule(userID, uint(instanceID), req)
+	if err != nil {
+		global.APP_LOG.Warn("设置实例定时开关机计划失败",
+			zap.Uint("userID", userID),
+			zap.Uint64("instanceID", instanceID),
+			zap.Error(err))
+		common.ResponseWithError(c, err)
+		return
+	}
+
+	common.ResponseSuccess(c, schedule, "定时开关机计划已保存")
+}
+
+// ClearInstanceSchedule 删除实例定时开关机计划
+// @Summary 删除实例定时开关机计划
+// @Description 删除实例的定时开关机计划，不影响实例当前状态
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "实例ID"
+// @Success 200 {object} common.Response "删除成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 401 {object} common.Response "用户未登录"
+// @Failure 403 {object} common.Response "无权限访问此实例"
+// @Router /user/instances/{id}/schedule [delete]
+func ClearInstanceSchedule(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeUnauthorized, err.Error()))
+		return
+	}
+
+	instanceID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的实例ID"))
+		return
+	}
+
+	if err := userService.NewService().ClearInstanceSchedule(userID, uint(instanceID)); err != nil {
+		common.ResponseWithError(c, err)
+		return
+	}
+
+	common.ResponseSuccess(c, nil, "定时开关机计划已删除")
+}
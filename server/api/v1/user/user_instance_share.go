@@ -0,0 +1,162 @@
+package user
+
+import (
+	"strconv"
+
+	"oneclickvirt/global"
+	"oneclickvirt/model/common"
+	"oneclickvirt/model/user"
+	userService "oneclickvirt/service/user"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// GetInstanceShares 获取实例共享授权列表
+// @Summary 获取实例共享授权列表
+// @Description 获取实例所有者授予其他用户的共享授权，仅实例所有者可查看
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "实例ID"
+// @Success 200 {object} common.Response{data=[]user.InstanceShareResponse} "获取成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 401 {object} common.Response "用户未登录"
+// @Failure 403 {object} common.Response "实例不存在或无权限"
+// @Router /user/instances/{id}/shares [get]
+func GetInstanceShares(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeUnauthorized, err.Error()))
+		return
+	}
+
+	instanceID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的实例ID"))
+		return
+	}
+
+	shares, err := userService.NewService().GetInstanceShares(userID, uint(instanceID))
+	if err != nil {
+		common.ResponseWithError(c, err)
+		return
+	}
+
+	common.ResponseSuccess(c, shares)
+}
+
+// GrantInstanceShare 共享实例给其他用户
+// @Summary 共享实例给其他用户
+// @Description 以viewer（只读，不含登录密码）或operator（可开机、关机、重启）角色将实例共享给其他用户，重复授权时更新角色和过期时间。删除、重置等操作始终仅所有者可执行
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "实例ID"
+// @Param request body user.GrantInstanceShareRequest true "共享授权"
+// @Success 200 {object} common.Response{data=user.InstanceShareResponse} "共享成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 401 {object} common.Response "用户未登录"
+// @Failure 403 {object} common.Response "实例不存在或无权限"
+// @Failure 404 {object} common.Response "用户不存在"
+// @Router /user/instances/{id}/shares [post]
+func GrantInstanceShare(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeUnauthorized, err.Error()))
+		return
+	}
+
+	instanceID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的实例ID"))
+		return
+	}
+
+	var req user.GrantInstanceShareRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误: "+err.Error()))
+		return
+	}
+
+	share, err := userService.NewService().GrantInstanceShare(userID, uint(instanceID), req)
+	if err != nil {
+		global.APP_LOG.Warn("共享实例失败",
+			zap.Uint("userID", userID),
+			zap.Uint64("instanceID", instanceID),
+			zap.Error(err))
+		common.ResponseWithError(c, err)
+		return
+	}
+
+	common.ResponseSuccess(c, share, "实例共享成功")
+}
+
+// RevokeInstanceShare 撤销实例共享授权
+// @Summary 撤销实例共享授权
+// @Description 撤销实例所有者授予其他用户的共享授权
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "实例ID"
+// @Param shareId path int true "共享授权ID"
+// @Success 200 {object} common.Response "撤销成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 401 {object} common.Response "用户未登录"
+// @Failure 403 {object} common.Response "实例不存在或无权限"
+// @Failure 404 {object} common.Response "共享授权不存在"
+// @Router /user/instances/{id}/shares/{shareId} [delete]
+func RevokeInstanceShare(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeUnauthorized, err.Error()))
+		return
+	}
+
+	instanceID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的实例ID"))
+		return
+	}
+	shareID, err := strconv.ParseUint(c.Param("shareId"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的共享授权ID"))
+		return
+	}
+
+	if err := userService.NewService().RevokeInstanceShare(userID, uint(instanceID), uint(shareID)); err != nil {
+		common.ResponseWithError(c, err)
+		return
+	}
+
+	common.ResponseSuccess(c, nil, "共享授权已撤销")
+}
+
+// GetSharedInstances 获取共享给我的实例
+// @Summary 获取共享给我的实例
+// @Description 获取其他用户共享给当前用户且仍在有效期内的实例
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} common.Response{data=[]user.SharedInstanceResponse} "获取成功"
+// @Failure 401 {object} common.Response "用户未登录"
+// @Router /user/shared-instances [get]
+func GetSharedInstances(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeUnauthorized, err.Error()))
+		return
+	}
+
+	instances, err := userService.NewService().GetSharedInstances(userID)
+	if err != nil {
+		common.ResponseWithError(c, err)
+		return
+	}
+
+	common.ResponseSuccess(c, instances)
+}
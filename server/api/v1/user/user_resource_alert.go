@@ -0,0 +1,121 @@
+package user
+
+import (
+	"oneclickvirt/global"
+	"oneclickvirt/model/common"
+	"oneclickvirt/model/user"
+	"oneclickvirt/service/alert"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// GetResourceAlerts 获取实例资源告警记录
+// @Summary 获取实例资源告警记录
+// @Description 分页获取当前用户实例的CPU、磁盘使用率告警历史
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码"
+// @Param pageSize query int false "每页数量"
+// @Param instanceId query int false "实例ID"
+// @Param alertType query string false "告警类型：cpu, disk"
+// @Success 200 {object} common.Response "获取成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 401 {object} common.Response "用户未登录"
+// @Failure 500 {object} common.Response "获取失败"
+// @Router /user/resource-alerts [get]
+func GetResourceAlerts(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeUnauthorized, err.Error()))
+		return
+	}
+
+	var req user.ResourceAlertListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误"))
+		return
+	}
+
+	// 设置默认值
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 || req.PageSize > 100 {
+		req.PageSize = 20
+	}
+
+	alerts, total, err := alert.NewService().ListUserAlerts(userID, req)
+	if err != nil {
+		global.APP_LOG.Error("获取资源告警记录失败", zap.Uint("userID", userID), zap.Error(err))
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, "获取资源告警记录失败"))
+		return
+	}
+
+	common.ResponseSuccessWithPagination(c, alerts, total, req.Page, req.PageSize)
+}
+
+// GetResourceAlertSettings 获取资源告警设置
+// @Summary 获取资源告警设置
+// @Description 获取当前用户生效的资源告警阈值（未单独设置的项使用系统默认值）
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} common.Response{data=user.ResourceAlertSettingsResponse} "获取成功"
+// @Failure 401 {object} common.Response "用户未登录"
+// @Failure 500 {object} common.Response "获取失败"
+// @Router /user/resource-alerts/settings [get]
+func GetResourceAlertSettings(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeUnauthorized, err.Error()))
+		return
+	}
+
+	settings, err := alert.NewService().GetUserSettings(userID)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, err.Error()))
+		return
+	}
+
+	common.ResponseSuccess(c, settings)
+}
+
+// UpdateResourceAlertSettings 更新资源告警设置
+// @Summary 更新资源告警设置
+// @Description 设置当前用户的资源告警开关和阈值，字段为空表示使用系统默认值
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body user.UpdateResourceAlertSettingsRequest true "资源告警设置"
+// @Success 200 {object} common.Response{data=user.ResourceAlertSettingsResponse} "更新成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 401 {object} common.Response "用户未登录"
+// @Failure 500 {object} common.Response "更新失败"
+// @Router /user/resource-alerts/settings [put]
+func UpdateResourceAlertSettings(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeUnauthorized, err.Error()))
+		return
+	}
+
+	var req user.UpdateResourceAlertSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误: "+err.Error()))
+		return
+	}
+
+	settings, err := alert.NewService().UpdateUserSettings(userID, req)
+	if err != nil {
+		global.APP_LOG.Error("更新资源告警设置失败", zap.Uint("userID", userID), zap.Error(err))
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, err.Error()))
+		return
+	}
+
+	common.ResponseSuccess(c, settings, "资源告警设置已更新")
+}
@@ -0,0 +1,126 @@
+package user
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"oneclickvirt/global"
+	adminModel "oneclickvirt/model/admin"
+	"oneclickvirt/model/common"
+	"oneclickvirt/service/task"
+	"oneclickvirt/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// taskEventsHeartbeat 事件流心跳间隔，同时用于检查被取消或超时等未经进度回调结束的任务
+const taskEventsHeartbeat = 15 * time.Second
+
+// StreamUserTaskEvents 任务进度事件流
+// @Summary 任务进度事件流
+// @Description 通过Server-Sent Events实时推送任务进度。连接后先推送一次当前进度，之后每次进度更新推送progress事件，任务结束时推送completed或failed事件并关闭连接。EventSource无法设置请求头时可通过token查询参数认证
+// @Tags 用户管理
+// @Produce text/event-stream
+// @Security BearerAuth
+// @Param taskId path int true "任务ID"
+// @Success 200 {object} utils.TaskEvent "事件流"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 401 {object} common.Response "用户未登录"
+// @Failure 404 {object} common.Response "任务不存在"
+// @Router /user/tasks/{taskId}/events [get]
+func StreamUserTaskEvents(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeUnauthorized, err.Error()))
+		return
+	}
+
+	taskID, err := strconv.ParseUint(c.Param("taskId"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的任务ID"))
+		return
+	}
+
+	// 先订阅再读取当前状态，避免两者之间的进度更新丢失
+	events, unsubscribe := utils.SubscribeTaskEvents(uint(taskID))
+	defer unsubscribe()
+
+	taskService := task.GetTaskService()
+	current, err := taskService.GetUserTaskProgress(uint(taskID), userID)
+	if err != nil {
+		common.ResponseWithError(c, err)
+		return
+	}
+
+	// 事件流持续时间超过服务器写超时，需单独取消写超时
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		global.APP_LOG.Debug("取消事件流写超时失败", zap.Uint64("taskId", taskID), zap.Error(err))
+	}
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	if finished, ok := finalTaskEvent(current); ok {
+		c.SSEvent(finished.Type, finished)
+		c.Writer.Flush()
+		return
+	}
+	c.SSEvent(utils.TaskEventProgress, utils.TaskEvent{
+		TaskID:   current.ID,
+		Type:     utils.TaskEventProgress,
+		Progress: current.Progress,
+		Message:  current.StatusMessage,
+		Time:     time.Now(),
+	})
+	c.Writer.Flush()
+
+	ticker := time.NewTicker(taskEventsHeartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case event := <-events:
+			c.SSEvent(event.Type, event)
+			c.Writer.Flush()
+			if event.Type != utils.TaskEventProgress {
+				return
+			}
+		case <-ticker.C:
+			latest, err := taskService.GetUserTaskProgress(uint(taskID), userID)
+			if err == nil {
+				if finished, ok := finalTaskEvent(latest); ok {
+					c.SSEvent(finished.Type, finished)
+					c.Writer.Flush()
+					return
+				}
+			}
+			if _, err := c.Writer.WriteString(": ping\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
+
+// finalTaskEvent 已结束任务对应的结束事件，完成以外的结束状态（取消、超时）均作为failed推送
+func finalTaskEvent(t *adminModel.Task) (utils.TaskEvent, bool) {
+	if !task.IsTaskFinished(t.Status) {
+		return utils.TaskEvent{}, false
+	}
+	event := utils.TaskEvent{TaskID: t.ID, Progress: t.Progress, Message: t.StatusMessage, Time: time.Now()}
+	if t.Status == adminModel.TaskStatusCompleted {
+		event.Type = utils.TaskEventCompleted
+		return event, true
+	}
+	event.Type = utils.TaskEventFailed
+	event.Message = t.ErrorMessage
+	if event.Message == "" {
+		event.Message = t.CancelReason
+	}
+	return event, true
+}
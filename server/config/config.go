@@ -14,6 +14,8 @@ type Server struct {
 	CDN        CDN        `mapstructure:"cdn" json:"cdn" yaml:"cdn"`
 	Task       Task       `mapstructure:"task" json:"task" yaml:"task"`
 	Upload     Upload     `mapstructure:"upload" json:"upload" yaml:"upload"`
+	Backup     Backup     `mapstructure:"backup" json:"backup" yaml:"backup"`
+	Billing    Billing    `mapstructure:"billing" json:"billing" yaml:"billing"`
 	Other      Other      `mapstructure:"other" json:"other" yaml:"other"`
 }
 
@@ -43,9 +45,41 @@ type Auth struct {
 }
 
 type Quota struct {
-	DefaultLevel            int                     `mapstructure:"default-level" json:"default-level" yaml:"default-level"`
-	LevelLimits             map[int]LevelLimitInfo  `mapstructure:"level-limits" json:"level-limits" yaml:"level-limits"`
-	InstanceTypePermissions InstanceTypePermissions `mapstructure:"instance-type-permissions" json:"instance-type-permissions" yaml:"instance-type-permissions"`
+	DefaultLevel                 int                     `mapstructure:"default-level" json:"default-level" yaml:"default-level"`
+	LevelLimits                  map[int]LevelLimitInfo  `mapstructure:"level-limits" json:"level-limits" yaml:"level-limits"`
+	InstanceTypePermissions      InstanceTypePermissions `mapstructure:"instance-type-permissions" json:"instance-type-permissions" yaml:"instance-type-permissions"`
+	TrafficWarningPercent        int                     `mapstructure:"traffic-warning-percent" json:"traffic-warning-percent" yaml:"traffic-warning-percent"`                         // 流量使用达到配额的百分比时发送预警通知，0表示使用默认值90
+	ResourceAlertEnabled         bool                    `mapstructure:"resource-alert-enabled" json:"resource-alert-enabled" yaml:"resource-alert-enabled"`                            // 是否启用实例资源使用告警（定期通过SSH采样实例CPU和磁盘）
+	ResourceAlertCPUPercent      int                     `mapstructure:"resource-alert-cpu-percent" json:"resource-alert-cpu-percent" yaml:"resource-alert-cpu-percent"`                // CPU使用率告警阈值（%），0表示使用默认值90
+	ResourceAlertCPUMinutes      int                     `mapstructure:"resource-alert-cpu-minutes" json:"resource-alert-cpu-minutes" yaml:"resource-alert-cpu-minutes"`                // CPU持续超过阈值多少分钟后告警，0表示使用默认值10
+	ResourceAlertDiskPercent     int                     `mapstructure:"resource-alert-disk-percent" json:"resource-alert-disk-percent" yaml:"resource-alert-disk-percent"`             // 磁盘使用率告警阈值（%），0表示使用默认值95
+	ResourceAlertCooldownMinutes int                     `mapstructure:"resource-alert-cooldown-minutes" json:"resource-alert-cooldown-minutes" yaml:"resource-alert-cooldown-minutes"` // 同一实例同类告警的最小间隔（分钟），0表示使用默认值60
+	TrafficSpikeAlertEnabled     bool                    `mapstructure:"traffic-spike-alert-enabled" json:"traffic-spike-alert-enabled" yaml:"traffic-spike-alert-enabled"`             // 是否启用实例流量突增告警（对比pmacct近期流量速率与历史基线）
+	TrafficSpikeMultiplier       int                     `mapstructure:"traffic-spike-multiplier" json:"traffic-spike-multiplier" yaml:"traffic-spike-multiplier"`                      // 近期速率达到基线的多少倍时告警，0表示使用默认值5
+	TrafficSpikeWindowMinutes    int                     `mapstructure:"traffic-spike-window-minutes" json:"traffic-spike-window-minutes" yaml:"traffic-spike-window-minutes"`          // 计算近期速率的时间窗口（分钟），0表示使用默认值15
+	TrafficSpikeBaselineHours    int                     `mapstructure:"traffic-spike-baseline-hours" json:"traffic-spike-baseline-hours" yaml:"traffic-spike-baseline-hours"`          // 计算基线速率的时间窗口（小时），0表示使用默认值24
+	TrafficSpikeMinMbps          int                     `mapstructure:"traffic-spike-min-mbps" json:"traffic-spike-min-mbps" yaml:"traffic-spike-min-mbps"`                            // 近期速率低于该值（Mbps）时不告警，避免低流量实例误报，0表示使用默认值10
+	TrafficSpikeCooldownMinutes  int                     `mapstructure:"traffic-spike-cooldown-minutes" json:"traffic-spike-cooldown-minutes" yaml:"traffic-spike-cooldown-minutes"`    // 同一实例流量突增告警的最小间隔（分钟），0表示使用默认值60
+	TrafficSpikeLimitMbps        int                     `mapstructure:"traffic-spike-limit-mbps" json:"traffic-spike-limit-mbps" yaml:"traffic-spike-limit-mbps"`                      // 流量突增时自动将实例限速到该值（Mbps），0表示不自动限速
+	InstanceExecAllowlist        []string                `mapstructure:"instance-exec-allowlist" json:"instance-exec-allowlist" yaml:"instance-exec-allowlist"`                         // 用户在实例内执行命令的程序白名单，为空表示不限制
+	InstanceExecDenylist         []string                `mapstructure:"instance-exec-denylist" json:"instance-exec-denylist" yaml:"instance-exec-denylist"`                            // 用户在实例内执行命令的程序黑名单
+	InstanceExecTimeout          int                     `mapstructure:"instance-exec-timeout" json:"instance-exec-timeout" yaml:"instance-exec-timeout"`                               // 实例内命令执行超时（秒），0表示使用默认值30
+	InstanceExecMaxOutput        int                     `mapstructure:"instance-exec-max-output" json:"instance-exec-max-output" yaml:"instance-exec-max-output"`                      // 实例内命令stdout/stderr各自的最大返回字节数，0表示使用默认值65536
+	InstanceMaxTTLHours          int                     `mapstructure:"instance-max-ttl-hours" json:"instance-max-ttl-hours" yaml:"instance-max-ttl-hours"`                            // 用户申请实例时可设置的最长有效期，以及续期后距当前的最长有效期（小时），0表示使用默认值720
+	InstanceExtendMaxHours       int                     `mapstructure:"instance-extend-max-hours" json:"instance-extend-max-hours" yaml:"instance-extend-max-hours"`                   // 单次续期的最长时长（小时），0表示使用默认值168
+	InstanceExpireNotifyHours    int                     `mapstructure:"instance-expire-notify-hours" json:"instance-expire-notify-hours" yaml:"instance-expire-notify-hours"`          // 实例到期前多少小时通知用户，0表示使用默认值24
+	InstanceDeleteGraceHours     int                     `mapstructure:"instance-delete-grace-hours" json:"instance-delete-grace-hours" yaml:"instance-delete-grace-hours"`             // 用户删除实例后的可撤销等待期（小时），等待期内实例停机保留并占用配额，0表示立即删除
+	PlacementStrategy            string                  `mapstructure:"placement-strategy" json:"placement-strategy" yaml:"placement-strategy"`                                        // 用户未指定节点时的自动选择策略：most-free、least-loaded、weighted-random，为空表示使用most-free
+	InstanceReadyProbe           string                  `mapstructure:"instance-ready-probe" json:"instance-ready-probe" yaml:"instance-ready-probe"`                                  // 实例创建完成前的就绪探测方式：tcp（连接SSH端口并读取banner）、exec（在实例内执行true）、none（不探测），为空表示使用tcp
+	InstanceReadyTimeout         int                     `mapstructure:"instance-ready-timeout" json:"instance-ready-timeout" yaml:"instance-ready-timeout"`                            // 就绪探测的最长等待时间（秒），超时后任务仍标记成功并提示稍后连接，0表示使用默认值120
+	GeneratedPasswordLength      int                     `mapstructure:"generated-password-length" json:"generated-password-length" yaml:"generated-password-length"`                   // 系统生成的实例密码和重置后账户密码的长度，0表示使用默认值12，否则为8-64
+	GeneratedPasswordUpperCase   bool                    `mapstructure:"generated-password-uppercase" json:"generated-password-uppercase" yaml:"generated-password-uppercase"`          // 生成的密码是否包含大写字母（小写字母和数字始终包含）
+	GeneratedPasswordSpecial     bool                    `mapstructure:"generated-password-special" json:"generated-password-special" yaml:"generated-password-special"`                // 生成的密码是否包含特殊字符（仅使用 @#%^*_+=,.:- 等无需转义的字符）
+	GeneratedPasswordNoAmbiguous bool                    `mapstructure:"generated-password-no-ambiguous" json:"generated-password-no-ambiguous" yaml:"generated-password-no-ambiguous"` // 生成的密码是否排除易混淆字符（0 O o 1 l I）
+	RateLimitCreate              int                     `mapstructure:"rate-limit-create" json:"rate-limit-create" yaml:"rate-limit-create"`                                           // 每个用户每分钟可调用创建实例、克隆、申领资源接口的次数，0表示使用默认值5，-1表示不限制，管理员不受限制
+	RateLimitExec                int                     `mapstructure:"rate-limit-exec" json:"rate-limit-exec" yaml:"rate-limit-exec"`                                                 // 每个用户每分钟可调用实例内执行命令、查看日志接口的次数，0表示使用默认值20，-1表示不限制
+	RateLimitWrite               int                     `mapstructure:"rate-limit-write" json:"rate-limit-write" yaml:"rate-limit-write"`                                              // 每个用户每分钟可调用其他修改类接口的次数，0表示使用默认值30，-1表示不限制
+	RateLimitRead                int                     `mapstructure:"rate-limit-read" json:"rate-limit-read" yaml:"rate-limit-read"`                                                 // 每个用户每分钟可调用查询类接口的次数，0表示使用默认值120，-1表示不限制
 }
 
 type InstanceTypePermissions struct {
@@ -75,6 +109,22 @@ type System struct {
 	FrontendURL             string `mapstructure:"frontend-url" json:"frontend-url" yaml:"frontend-url"`                                           // 前端URL，用于OAuth2回调跳转
 	ProviderInactiveHours   int    `mapstructure:"provider-inactive-hours" json:"provider-inactive-hours" yaml:"provider-inactive-hours"`          // Provider不活动阈值（小时），默认72小时
 	OAuth2StateTokenMinutes int    `mapstructure:"oauth2-state-token-minutes" json:"oauth2-state-token-minutes" yaml:"oauth2-state-token-minutes"` // OAuth2 State令牌有效期（分钟），默认15分钟
+
+	TrafficHistoryRetentionHours int `mapstructure:"traffic-history-retention-hours" json:"traffic-history-retention-hours" yaml:"traffic-history-retention-hours"` // 小时级流量历史保留时长（小时），默认72小时
+	TrafficDailyRetentionDays    int `mapstructure:"traffic-daily-retention-days" json:"traffic-daily-retention-days" yaml:"traffic-daily-retention-days"`          // 日级汇总流量历史保留时长（天），默认90天
+	TrafficCollectConcurrency    int `mapstructure:"traffic-collect-concurrency" json:"traffic-collect-concurrency" yaml:"traffic-collect-concurrency"`             // 同时采集流量的Provider数量上限，默认8
+	TrafficCollectTimeout        int `mapstructure:"traffic-collect-timeout" json:"traffic-collect-timeout" yaml:"traffic-collect-timeout"`                         // 单个Provider一轮流量采集的超时时间（秒），默认1800秒
+	TrafficCollectInterval       int `mapstructure:"traffic-collect-interval" json:"traffic-collect-interval" yaml:"traffic-collect-interval"`                      // Provider未设置采集间隔时的基础采集间隔（秒），默认300秒
+	TrafficCollectMaxJitter      int `mapstructure:"traffic-collect-max-jitter" json:"traffic-collect-max-jitter" yaml:"traffic-collect-max-jitter"`                // 每轮流量采集的随机延迟上限（秒），用于错开各Provider的采集，默认60秒，-1关闭
+
+	MetricsToken    string   `mapstructure:"metrics-token" json:"metrics-token" yaml:"metrics-token"`             // /metrics 端点的Bearer Token
+	MetricsAllowIPs []string `mapstructure:"metrics-allow-ips" json:"metrics-allow-ips" yaml:"metrics-allow-ips"` // 无需Token即可访问 /metrics 的来源IP或CIDR，按TCP连接地址匹配；与Token都未配置时 /metrics 拒绝所有请求
+
+	ImageNamePrefix string `mapstructure:"image-name-prefix" json:"image-name-prefix" yaml:"image-name-prefix"` // 导入节点的镜像名称前缀，为空时使用 oneclickvirt_，none 表示不添加前缀
+
+	SSHMaxOutputBytes int `mapstructure:"ssh-max-output-bytes" json:"ssh-max-output-bytes" yaml:"ssh-max-output-bytes"` // SSH命令返回输出的最大字节数，超出部分截断，默认16MB
+	SSHMaxConnections int `mapstructure:"ssh-max-connections" json:"ssh-max-connections" yaml:"ssh-max-connections"`    // 每个节点最多同时保持的SSH连接数，并发命令超出单连接会话上限时按需建立，空闲5分钟后关闭，默认4
+	SSHMaxSessions    int `mapstructure:"ssh-max-sessions" json:"ssh-max-sessions" yaml:"ssh-max-sessions"`             // 每个SSH连接最多同时执行的命令数，应不超过节点sshd的MaxSessions，默认8
 }
 
 type JWT struct {
@@ -131,8 +181,35 @@ type CDN struct {
 
 // Task 任务配置
 type Task struct {
-	DeleteRetryCount int `mapstructure:"delete-retry-count" json:"delete-retry-count" yaml:"delete-retry-count"` // 删除实例重试次数，默认3
-	DeleteRetryDelay int `mapstructure:"delete-retry-delay" json:"delete-retry-delay" yaml:"delete-retry-delay"` // 删除实例重试延迟（秒），默认2
+	DeleteRetryCount       int `mapstructure:"delete-retry-count" json:"delete-retry-count" yaml:"delete-retry-count"`                   // 删除实例重试次数，默认3
+	DeleteRetryDelay       int `mapstructure:"delete-retry-delay" json:"delete-retry-delay" yaml:"delete-retry-delay"`                   // 删除实例重试延迟（秒），默认2
+	DeleteStrategyRetries  int `mapstructure:"delete-strategy-retries" json:"delete-strategy-retries" yaml:"delete-strategy-retries"`    // Docker删除实例时每种删除策略的重试次数，默认3
+	DeleteStrategyDelay    int `mapstructure:"delete-strategy-delay" json:"delete-strategy-delay" yaml:"delete-strategy-delay"`          // Docker删除策略重试间隔（秒），默认2
+	CommandTimeoutQuick    int `mapstructure:"command-timeout-quick" json:"command-timeout-quick" yaml:"command-timeout-quick"`          // 快速查询类SSH命令超时（秒），0使用默认值30
+	CommandTimeoutNormal   int `mapstructure:"command-timeout-normal" json:"command-timeout-normal" yaml:"command-timeout-normal"`       // 普通SSH命令超时（秒），0使用节点配置的执行超时
+	CommandTimeoutInstall  int `mapstructure:"command-timeout-install" json:"command-timeout-install" yaml:"command-timeout-install"`    // 软件安装类SSH命令超时（秒），0使用默认值900
+	CommandTimeoutDownload int `mapstructure:"command-timeout-download" json:"command-timeout-download" yaml:"command-timeout-download"` // 镜像下载类SSH命令超时（秒），0使用默认值3600
+	CommandTimeoutBackup   int `mapstructure:"command-timeout-backup" json:"command-timeout-backup" yaml:"command-timeout-backup"`       // 备份导出导入类SSH命令超时（秒），0使用默认值7200
+}
+
+// Backup 实例备份配置，备份上传到S3兼容对象存储（使用path-style访问）
+type Backup struct {
+	Endpoint       string `mapstructure:"endpoint" json:"endpoint" yaml:"endpoint"`                      // 对象存储地址，含协议，如 https://s3.example.com
+	Region         string `mapstructure:"region" json:"region" yaml:"region"`                            // 区域，默认us-east-1
+	Bucket         string `mapstructure:"bucket" json:"bucket" yaml:"bucket"`                            // 存储桶
+	AccessKey      string `mapstructure:"access-key" json:"access-key" yaml:"access-key"`                // Access Key
+	SecretKey      string `mapstructure:"secret-key" json:"secret-key" yaml:"secret-key"`                // Secret Key
+	Prefix         string `mapstructure:"prefix" json:"prefix" yaml:"prefix"`                            // 对象键前缀
+	PartSize       int    `mapstructure:"part-size" json:"part-size" yaml:"part-size"`                   // 分片上传的分片大小（MB），默认16，最小5
+	RetentionCount int    `mapstructure:"retention-count" json:"retention-count" yaml:"retention-count"` // 每个实例保留的备份数量，0表示不限制
+	RetentionDays  int    `mapstructure:"retention-days" json:"retention-days" yaml:"retention-days"`    // 备份保留天数，0表示不限制
+}
+
+// Billing 流量计费报表配置，当月用量超出流量配额的部分按GB计费
+type Billing struct {
+	Currency                  string  `mapstructure:"currency" json:"currency" yaml:"currency"`                                                                // 货币单位，默认CNY
+	UserOveragePricePerGB     float64 `mapstructure:"user-overage-price-per-gb" json:"user-overage-price-per-gb" yaml:"user-overage-price-per-gb"`             // 用户超额流量单价（每GB）
+	ProviderOveragePricePerGB float64 `mapstructure:"provider-overage-price-per-gb" json:"provider-overage-price-per-gb" yaml:"provider-overage-price-per-gb"` // Provider超额流量单价（每GB）
 }
 
 // Upload 上传配置
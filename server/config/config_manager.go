@@ -3,7 +3,10 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"net"
 	"os"
+	"regexp"
+	"strings"
 	"sync"
 	"time"
 
@@ -25,17 +28,29 @@ const (
 // - 基础系统设置（如OSS类型、是否使用Redis等）
 var systemLevelConfigKeys = map[string]bool{
 	// System 配置（所有 system.* 都是系统级配置）
-	"system.addr":                       true,
-	"system.db-type":                    true,
-	"system.env":                        true,
-	"system.frontend-url":               true,
-	"system.iplimit-count":              true,
-	"system.iplimit-time":               true,
-	"system.oauth2-state-token-minutes": true,
-	"system.oss-type":                   true,
-	"system.provider-inactive-hours":    true,
-	"system.use-multipoint":             true,
-	"system.use-redis":                  true,
+	"system.addr":                            true,
+	"system.db-type":                         true,
+	"system.env":                             true,
+	"system.frontend-url":                    true,
+	"system.image-name-prefix":               true,
+	"system.iplimit-count":                   true,
+	"system.iplimit-time":                    true,
+	"system.metrics-token":                   true,
+	"system.metrics-allow-ips":               true,
+	"system.oauth2-state-token-minutes":      true,
+	"system.oss-type":                        true,
+	"system.provider-inactive-hours":         true,
+	"system.ssh-max-output-bytes":            true,
+	"system.ssh-max-connections":             true,
+	"system.ssh-max-sessions":                true,
+	"system.traffic-history-retention-hours": true,
+	"system.traffic-daily-retention-days":    true,
+	"system.traffic-collect-concurrency":     true,
+	"system.traffic-collect-timeout":         true,
+	"system.traffic-collect-interval":        true,
+	"system.traffic-collect-max-jitter":      true,
+	"system.use-multipoint":                  true,
+	"system.use-redis":                       true,
 
 	// MySQL 配置（数据库连接信息，必须在连接数据库前读取）
 	"mysql.path":           true,
@@ -228,6 +243,228 @@ func (cm *ConfigManager) initValidationRules() {
 		MaxValue: 5,
 	}
 
+	cm.validationRules["system.traffic-history-retention-hours"] = ConfigValidationRule{
+		Required: false,
+		Type:     "int",
+		MinValue: 1,
+		MaxValue: 24 * 365,
+	}
+	cm.validationRules["system.traffic-daily-retention-days"] = ConfigValidationRule{
+		Required: false,
+		Type:     "int",
+		MinValue: 1,
+		MaxValue: 3650,
+	}
+	cm.validationRules["system.traffic-collect-concurrency"] = ConfigValidationRule{
+		Required: false,
+		Type:     "int",
+		MinValue: 1,
+		MaxValue: 100,
+	}
+	cm.validationRules["system.traffic-collect-timeout"] = ConfigValidationRule{
+		Required: false,
+		Type:     "int",
+		MinValue: 60,
+		MaxValue: 7200,
+	}
+	cm.validationRules["system.traffic-collect-interval"] = ConfigValidationRule{
+		Required: false,
+		Type:     "int",
+		MinValue: 60,
+		MaxValue: 86400,
+	}
+	cm.validationRules["system.traffic-collect-max-jitter"] = ConfigValidationRule{
+		Required: false,
+		Type:     "int",
+		MinValue: -1,
+		MaxValue: 3600,
+	}
+	cm.validationRules["system.metrics-allow-ips"] = ConfigValidationRule{
+		Required:  false,
+		Type:      "array",
+		Validator: validateIPOrCIDRList,
+	}
+	cm.validationRules["system.image-name-prefix"] = ConfigValidationRule{
+		Required:  false,
+		Type:      "string",
+		Validator: validateImageNamePrefix,
+	}
+	cm.validationRules["system.ssh-max-output-bytes"] = ConfigValidationRule{
+		Required: false,
+		Type:     "int",
+		MinValue: 0,
+		MaxValue: 1 << 30,
+	}
+	cm.validationRules["system.ssh-max-connections"] = ConfigValidationRule{
+		Required: false,
+		Type:     "int",
+		MinValue: 0,
+		MaxValue: 32,
+	}
+	cm.validationRules["system.ssh-max-sessions"] = ConfigValidationRule{
+		Required: false,
+		Type:     "int",
+		MinValue: 0,
+		MaxValue: 64,
+	}
+	cm.validationRules["quota.traffic-warning-percent"] = ConfigValidationRule{
+		Required: false,
+		Type:     "int",
+		MinValue: 0,
+		MaxValue: 100,
+	}
+	cm.validationRules["quota.resource-alert-cpu-percent"] = ConfigValidationRule{
+		Required: false,
+		Type:     "int",
+		MinValue: 0,
+		MaxValue: 100,
+	}
+	cm.validationRules["quota.resource-alert-cpu-minutes"] = ConfigValidationRule{
+		Required: false,
+		Type:     "int",
+		MinValue: 0,
+		MaxValue: 1440,
+	}
+	cm.validationRules["quota.resource-alert-disk-percent"] = ConfigValidationRule{
+		Required: false,
+		Type:     "int",
+		MinValue: 0,
+		MaxValue: 100,
+	}
+	cm.validationRules["quota.resource-alert-cooldown-minutes"] = ConfigValidationRule{
+		Required: false,
+		Type:     "int",
+		MinValue: 0,
+		MaxValue: 10080,
+	}
+	cm.validationRules["quota.traffic-spike-multiplier"] = ConfigValidationRule{
+		Required: false,
+		Type:     "int",
+		MinValue: 0,
+		MaxValue: 1000,
+	}
+	cm.validationRules["quota.traffic-spike-window-minutes"] = ConfigValidationRule{
+		Required: false,
+		Type:     "int",
+		MinValue: 0,
+		MaxValue: 1440,
+	}
+	cm.validationRules["quota.traffic-spike-baseline-hours"] = ConfigValidationRule{
+		Required: false,
+		Type:     "int",
+		MinValue: 0,
+		MaxValue: 720,
+	}
+	cm.validationRules["quota.traffic-spike-min-mbps"] = ConfigValidationRule{
+		Required: false,
+		Type:     "int",
+		MinValue: 0,
+		MaxValue: 100000,
+	}
+	cm.validationRules["quota.traffic-spike-cooldown-minutes"] = ConfigValidationRule{
+		Required: false,
+		Type:     "int",
+		MinValue: 0,
+		MaxValue: 10080,
+	}
+	cm.validationRules["quota.traffic-spike-limit-mbps"] = ConfigValidationRule{
+		Required: false,
+		Type:     "int",
+		MinValue: 0,
+		MaxValue: 100000,
+	}
+	cm.validationRules["quota.instance-exec-allowlist"] = ConfigValidationRule{
+		Required:  false,
+		Type:      "array",
+		Validator: validateStringList,
+	}
+	cm.validationRules["quota.instance-exec-denylist"] = ConfigValidationRule{
+		Required:  false,
+		Type:      "array",
+		Validator: validateStringList,
+	}
+	cm.validationRules["quota.instance-exec-timeout"] = ConfigValidationRule{
+		Required: false,
+		Type:     "int",
+		MinValue: 0,
+		MaxValue: 300,
+	}
+	cm.validationRules["quota.instance-exec-max-output"] = ConfigValidationRule{
+		Required: false,
+		Type:     "int",
+		MinValue: 0,
+		MaxValue: 1048576,
+	}
+	cm.validationRules["quota.instance-max-ttl-hours"] = ConfigValidationRule{
+		Required: false,
+		Type:     "int",
+		MinValue: 0,
+		MaxValue: 87600,
+	}
+	cm.validationRules["quota.instance-extend-max-hours"] = ConfigValidationRule{
+		Required: false,
+		Type:     "int",
+		MinValue: 0,
+		MaxValue: 87600,
+	}
+	cm.validationRules["quota.instance-expire-notify-hours"] = ConfigValidationRule{
+		Required: false,
+		Type:     "int",
+		MinValue: 0,
+		MaxValue: 720,
+	}
+	cm.validationRules["quota.instance-delete-grace-hours"] = ConfigValidationRule{
+		Required: false,
+		Type:     "int",
+		MinValue: 0,
+		MaxValue: 720,
+	}
+	cm.validationRules["quota.placement-strategy"] = ConfigValidationRule{
+		Required:  false,
+		Type:      "string",
+		Validator: validatePlacementStrategy,
+	}
+	cm.validationRules["quota.instance-ready-probe"] = ConfigValidationRule{
+		Required:  false,
+		Type:      "string",
+		Validator: validateInstanceReadyProbe,
+	}
+	cm.validationRules["quota.instance-ready-timeout"] = ConfigValidationRule{
+		Required: false,
+		Type:     "int",
+		MinValue: 0,
+		MaxValue: 1800,
+	}
+	cm.validationRules["quota.generated-password-length"] = ConfigValidationRule{
+		Required:  false,
+		Type:      "int",
+		Validator: validateGeneratedPasswordLength,
+	}
+	cm.validationRules["quota.rate-limit-create"] = ConfigValidationRule{
+		Required: false,
+		Type:     "int",
+		MinValue: -1,
+		MaxValue: 600,
+	}
+	cm.validationRules["quota.rate-limit-exec"] = ConfigValidationRule{
+		Required: false,
+		Type:     "int",
+		MinValue: -1,
+		MaxValue: 600,
+	}
+	cm.validationRules["quota.rate-limit-write"] = ConfigValidationRule{
+		Required: false,
+		Type:     "int",
+		MinValue: -1,
+		MaxValue: 6000,
+	}
+	cm.validationRules["quota.rate-limit-read"] = ConfigValidationRule{
+		Required: false,
+		Type:     "int",
+		MinValue: -1,
+		MaxValue: 6000,
+	}
+
 	// 等级限制配置验证规则
 	cm.validationRules["quota.level-limits"] = ConfigValidationRule{
 		Required: false,
@@ -471,6 +708,124 @@ func (cm *ConfigManager) validateConfig(key string, value interface{}) error {
 	return nil
 }
 
+// imageNamePrefixPattern 镜像名称前缀格式，前缀会拼接到Docker镜像名中，只允许小写字母、数字和分隔符
+var imageNamePrefixPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{0,63}$`)
+
+// validateImageNamePrefix 验证镜像名称前缀，允许为空（使用默认前缀）或 none（不添加前缀）
+func validateImageNamePrefix(value interface{}) error {
+	prefix, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("镜像名称前缀必须是字符串")
+	}
+	if prefix == "" || prefix == "none" || imageNamePrefixPattern.MatchString(prefix) {
+		return nil
+	}
+	return fmt.Errorf("镜像名称前缀无效: %s，只能包含小写字母、数字、点、下划线和连字符", prefix)
+}
+
+// PlacementStrategies 支持的节点自动选择策略
+var PlacementStrategies = []string{"most-free", "least-loaded", "weighted-random"}
+
+// validatePlacementStrategy 验证节点自动选择策略配置，为空表示使用默认策略
+func validatePlacementStrategy(value interface{}) error {
+	strategy, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("节点选择策略必须是字符串")
+	}
+	if strategy == "" {
+		return nil
+	}
+	for _, item := range PlacementStrategies {
+		if strategy == item {
+			return nil
+		}
+	}
+	return fmt.Errorf("节点选择策略无效: %s，可选值: %s", strategy, strings.Join(PlacementStrategies, ", "))
+}
+
+// InstanceReadyProbes 支持的实例就绪探测方式
+var InstanceReadyProbes = []string{"tcp", "exec", "none"}
+
+// validateInstanceReadyProbe 验证实例就绪探测方式配置，为空表示使用tcp
+func validateInstanceReadyProbe(value interface{}) error {
+	probe, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("就绪探测方式必须是字符串")
+	}
+	if probe == "" {
+		return nil
+	}
+	for _, item := range InstanceReadyProbes {
+		if probe == item {
+			return nil
+		}
+	}
+	return fmt.Errorf("就绪探测方式无效: %s，可选值: %s", probe, strings.Join(InstanceReadyProbes, ", "))
+}
+
+// validateGeneratedPasswordLength 验证生成密码长度配置，0表示使用默认长度，否则必须在8-64之间
+func validateGeneratedPasswordLength(value interface{}) error {
+	var length int
+	switch v := value.(type) {
+	case int:
+		length = v
+	case int64:
+		length = int(v)
+	case float64:
+		if v != float64(int(v)) {
+			return fmt.Errorf("生成密码长度必须是整数")
+		}
+		length = int(v)
+	default:
+		return fmt.Errorf("生成密码长度必须是整数")
+	}
+	if length != 0 && (length < 8 || length > 64) {
+		return fmt.Errorf("生成密码长度无效: %d，必须为0（默认12位）或8-64之间", length)
+	}
+	return nil
+}
+
+// validateStringList 验证字符串数组配置
+func validateStringList(value interface{}) error {
+	switch v := value.(type) {
+	case nil, []string:
+		return nil
+	case []interface{}:
+		for _, item := range v {
+			if _, ok := item.(string); !ok {
+				return fmt.Errorf("列表项 %v 必须是字符串", item)
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("期望字符串数组")
+	}
+}
+
+// validateIPOrCIDRList 验证IP或CIDR数组配置
+func validateIPOrCIDRList(value interface{}) error {
+	if err := validateStringList(value); err != nil {
+		return err
+	}
+	var items []string
+	switch v := value.(type) {
+	case []string:
+		items = v
+	case []interface{}:
+		for _, item := range v {
+			items = append(items, item.(string))
+		}
+	}
+	for _, item := range items {
+		if net.ParseIP(item) == nil {
+			if _, _, err := net.ParseCIDR(item); err != nil {
+				return fmt.Errorf("%s 不是有效的IP或CIDR", item)
+			}
+		}
+	}
+	return nil
+}
+
 // validateLevelLimits 验证等级限制配置，并自动填充缺失的默认值
 func (cm *ConfigManager) validateLevelLimits(value interface{}) error {
 	levelLimitsMap, ok := value.(map[string]interface{})
@@ -1363,7 +1718,39 @@ func getDefaultConfigMap() map[string]interface{} {
 			"qq-app-key":                 "",
 		},
 		"quota": map[string]interface{}{
-			"default-level": 1,
+			"default-level":                   1,
+			"traffic-warning-percent":         90,
+			"resource-alert-enabled":          false,
+			"resource-alert-cpu-percent":      90,
+			"resource-alert-cpu-minutes":      10,
+			"resource-alert-disk-percent":     95,
+			"resource-alert-cooldown-minutes": 60,
+			"traffic-spike-alert-enabled":     false,
+			"traffic-spike-multiplier":        5,
+			"traffic-spike-window-minutes":    15,
+			"traffic-spike-baseline-hours":    24,
+			"traffic-spike-min-mbps":          10,
+			"traffic-spike-cooldown-minutes":  60,
+			"traffic-spike-limit-mbps":        0,
+			"instance-exec-allowlist":         []string{},
+			"instance-exec-denylist":          []string{"reboot", "shutdown", "poweroff", "halt", "init", "mkfs", "dd"},
+			"instance-exec-timeout":           30,
+			"instance-exec-max-output":        65536,
+			"instance-max-ttl-hours":          720,
+			"instance-extend-max-hours":       168,
+			"instance-expire-notify-hours":    24,
+			"instance-delete-grace-hours":     0,
+			"placement-strategy":              "most-free",
+			"instance-ready-probe":            "tcp",
+			"instance-ready-timeout":          120,
+			"generated-password-length":       12,
+			"generated-password-uppercase":    true,
+			"generated-password-special":      false,
+			"generated-password-no-ambiguous": false,
+			"rate-limit-create":               5,
+			"rate-limit-exec":                 20,
+			"rate-limit-write":                30,
+			"rate-limit-read":                 120,
 			"instance-type-permissions": map[string]interface{}{
 				"min-level-for-container":        1,
 				"min-level-for-vm":               2,
@@ -1436,17 +1823,29 @@ func getDefaultConfigMap() map[string]interface{} {
 			"whitelist": []string{"http://localhost:8080", "http://127.0.0.1:8080"},
 		},
 		"system": map[string]interface{}{
-			"env":                        "public",
-			"addr":                       8888,
-			"db-type":                    "mysql",
-			"oss-type":                   "local",
-			"use-multipoint":             false,
-			"use-redis":                  false,
-			"iplimit-count":              100,
-			"iplimit-time":               3600,
-			"frontend-url":               "",
-			"provider-inactive-hours":    72,
-			"oauth2-state-token-minutes": 15,
+			"env":                             "public",
+			"addr":                            8888,
+			"db-type":                         "mysql",
+			"oss-type":                        "local",
+			"use-multipoint":                  false,
+			"use-redis":                       false,
+			"iplimit-count":                   100,
+			"iplimit-time":                    3600,
+			"frontend-url":                    "",
+			"provider-inactive-hours":         72,
+			"oauth2-state-token-minutes":      15,
+			"traffic-history-retention-hours": 72,
+			"traffic-daily-retention-days":    90,
+			"traffic-collect-concurrency":     8,
+			"traffic-collect-timeout":         1800,
+			"traffic-collect-interval":        300,
+			"traffic-collect-max-jitter":      60,
+			"metrics-token":                   "",
+			"metrics-allow-ips":               []string{},
+			"image-name-prefix":               "oneclickvirt_",
+			"ssh-max-output-bytes":            16777216,
+			"ssh-max-connections":             4,
+			"ssh-max-sessions":                8,
 		},
 		"jwt": map[string]interface{}{
 			"signing-key":  "",
@@ -0,0 +1,115 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// EnvPrefix 配置环境变量前缀，配置键转为大写并将 . 和 - 替换为 _，如 mysql.db-name → ONECLICKVIRT_MYSQL_DB_NAME
+const EnvPrefix = "ONECLICKVIRT_"
+
+// envOverride 可通过环境变量提供的配置项
+type envOverride struct {
+	key   string
+	apply func(cfg *Server, value string) error
+}
+
+// envOverrides 支持环境变量的配置项：数据库连接、监听端口及各类密钥
+// 优先级：环境变量 > config.yaml（及后台保存到数据库的配置） > 默认值，未设置或为空的环境变量不生效
+var envOverrides = []envOverride{
+	{"system.env", func(cfg *Server, v string) error { cfg.System.Env = v; return nil }},
+	{"system.addr", func(cfg *Server, v string) error { return setEnvInt(&cfg.System.Addr, v, 1, 65535) }},
+	{"system.db-type", func(cfg *Server, v string) error {
+		if v != "mysql" && v != "mariadb" {
+			return fmt.Errorf("仅支持 mysql 或 mariadb")
+		}
+		cfg.System.DbType = v
+		return nil
+	}},
+	{"system.use-redis", func(cfg *Server, v string) error { return setEnvBool(&cfg.System.UseRedis, v) }},
+	{"system.frontend-url", func(cfg *Server, v string) error { cfg.System.FrontendURL = v; return nil }},
+	{"system.metrics-token", func(cfg *Server, v string) error { cfg.System.MetricsToken = v; return nil }},
+	{"mysql.path", func(cfg *Server, v string) error { cfg.Mysql.Path = v; return nil }},
+	{"mysql.port", func(cfg *Server, v string) error {
+		var port int
+		if err := setEnvInt(&port, v, 1, 65535); err != nil {
+			return err
+		}
+		cfg.Mysql.Port = v
+		return nil
+	}},
+	{"mysql.db-name", func(cfg *Server, v string) error { cfg.Mysql.Dbname = v; return nil }},
+	{"mysql.username", func(cfg *Server, v string) error { cfg.Mysql.Username = v; return nil }},
+	{"mysql.password", func(cfg *Server, v string) error { cfg.Mysql.Password = v; return nil }},
+	{"mysql.config", func(cfg *Server, v string) error { cfg.Mysql.Config = v; return nil }},
+	{"redis.addr", func(cfg *Server, v string) error { cfg.Redis.Addr = v; return nil }},
+	{"redis.password", func(cfg *Server, v string) error { cfg.Redis.Password = v; return nil }},
+	{"redis.db", func(cfg *Server, v string) error { return setEnvInt(&cfg.Redis.DB, v, 0, 15) }},
+	{"jwt.signing-key", func(cfg *Server, v string) error {
+		if len(v) < 32 {
+			return fmt.Errorf("长度不能少于32个字符")
+		}
+		cfg.JWT.SigningKey = v
+		return nil
+	}},
+}
+
+// requiredEnvKeys 未提供config.yaml时必须通过环境变量设置的配置项
+var requiredEnvKeys = []string{"mysql.path", "mysql.db-name", "mysql.username", "jwt.signing-key"}
+
+// EnvName 配置键对应的环境变量名
+func EnvName(key string) string {
+	return EnvPrefix + strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(key))
+}
+
+// ApplyEnvOverrides 用环境变量覆盖配置，返回已生效的配置键
+// 取值无效的环境变量不会生效，错误中列出全部无效项
+func ApplyEnvOverrides(cfg *Server) ([]string, error) {
+	var applied, invalid []string
+	for _, override := range envOverrides {
+		value := strings.TrimSpace(os.Getenv(EnvName(override.key)))
+		if value == "" {
+			continue
+		}
+		if err := override.apply(cfg, value); err != nil {
+			invalid = append(invalid, fmt.Sprintf("%s: %v", EnvName(override.key), err))
+			continue
+		}
+		applied = append(applied, override.key)
+	}
+	if len(invalid) > 0 {
+		return applied, fmt.Errorf("环境变量取值无效: %s", strings.Join(invalid, "; "))
+	}
+	return applied, nil
+}
+
+// MissingRequiredEnv 返回未提供config.yaml时缺失的必需环境变量名
+func MissingRequiredEnv() []string {
+	var missing []string
+	for _, key := range requiredEnvKeys {
+		if strings.TrimSpace(os.Getenv(EnvName(key))) == "" {
+			missing = append(missing, EnvName(key))
+		}
+	}
+	return missing
+}
+
+func setEnvInt(target *int, value string, min, max int) error {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < min || n > max {
+		return fmt.Errorf("需为%d-%d之间的整数", min, max)
+	}
+	*target = n
+	return nil
+}
+
+func setEnvBool(target *bool, value string) error {
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return fmt.Errorf("需为true或false")
+	}
+	*target = b
+	return nil
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestEnvName 测试配置键到环境变量名的转换
+func TestEnvName(t *testing.T) {
+	if got := EnvName("mysql.db-name"); got != "ONECLICKVIRT_MYSQL_DB_NAME" {
+		t.Fatalf("环境变量名错误: %s", got)
+	}
+	if got := EnvName("jwt.signing-key"); got != "ONECLICKVIRT_JWT_SIGNING_KEY" {
+		t.Fatalf("环境变量名错误: %s", got)
+	}
+}
+
+// TestApplyEnvOverrides 测试环境变量覆盖YAML配置，未设置的配置保持不变
+func TestApplyEnvOverrides(t *testing.T) {
+	t.Setenv("ONECLICKVIRT_SYSTEM_ADDR", "9000")
+	t.Setenv("ONECLICKVIRT_MYSQL_PATH", "db.internal")
+	t.Setenv("ONECLICKVIRT_MYSQL_PASSWORD", "s3cret")
+	t.Setenv("ONECLICKVIRT_SYSTEM_USE_REDIS", "true")
+	t.Setenv("ONECLICKVIRT_MYSQL_DB_NAME", "")
+
+	cfg := Server{
+		System: System{Addr: 8888},
+		Mysql:  Mysql{Path: "127.0.0.1", Dbname: "oneclickvirt"},
+	}
+	applied, err := ApplyEnvOverrides(&cfg)
+	if err != nil {
+		t.Fatalf("覆盖失败: %v", err)
+	}
+	if len(applied) != 4 {
+		t.Fatalf("生效的配置项数量错误: %v", applied)
+	}
+	if cfg.System.Addr != 9000 || cfg.Mysql.Path != "db.internal" || cfg.Mysql.Password != "s3cret" || !cfg.System.UseRedis {
+		t.Fatalf("环境变量未生效: %+v", cfg)
+	}
+	if cfg.Mysql.Dbname != "oneclickvirt" {
+		t.Fatalf("空环境变量不应覆盖配置: %s", cfg.Mysql.Dbname)
+	}
+}
+
+// TestApplyEnvOverridesInvalid 测试无效取值不生效并报告变量名
+func TestApplyEnvOverridesInvalid(t *testing.T) {
+	t.Setenv("ONECLICKVIRT_SYSTEM_ADDR", "http")
+	t.Setenv("ONECLICKVIRT_JWT_SIGNING_KEY", "short")
+
+	cfg := Server{System: System{Addr: 8888}}
+	_, err := ApplyEnvOverrides(&cfg)
+	if err == nil {
+		t.Fatalf("期望返回错误")
+	}
+	if !strings.Contains(err.Error(), "ONECLICKVIRT_SYSTEM_ADDR") || !strings.Contains(err.Error(), "ONECLICKVIRT_JWT_SIGNING_KEY") {
+		t.Fatalf("错误信息应包含无效变量名: %v", err)
+	}
+	if cfg.System.Addr != 8888 || cfg.JWT.SigningKey != "" {
+		t.Fatalf("无效取值不应生效: %+v", cfg)
+	}
+}
+
+// TestMissingRequiredEnv 测试列出缺失的必需环境变量
+func TestMissingRequiredEnv(t *testing.T) {
+	for _, key := range requiredEnvKeys {
+		t.Setenv(EnvName(key), "")
+	}
+	t.Setenv("ONECLICKVIRT_MYSQL_PATH", "db.internal")
+
+	missing := MissingRequiredEnv()
+	if len(missing) != len(requiredEnvKeys)-1 {
+		t.Fatalf("缺失列表错误: %v", missing)
+	}
+	for _, name := range missing {
+		if name == "ONECLICKVIRT_MYSQL_PATH" {
+			t.Fatalf("已设置的变量不应列为缺失")
+		}
+	}
+}
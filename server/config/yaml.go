@@ -68,6 +68,11 @@ func (cm *ConfigManager) handleYAMLFirst() error {
 func (cm *ConfigManager) writeConfigToYAML(updates map[string]interface{}) error {
 	// 读取现有配置文件
 	file, err := os.ReadFile("config.yaml")
+	if os.IsNotExist(err) {
+		// 仅使用环境变量运行时配置只保存在数据库中
+		cm.logger.Info("未使用配置文件，跳过写回YAML")
+		return nil
+	}
 	if err != nil {
 		cm.logger.Error("读取配置文件失败", zap.Error(err))
 		return err
@@ -550,6 +555,10 @@ func (cm *ConfigManager) syncYAMLConfigToDatabase() error {
 
 	// 读取YAML文件
 	file, err := os.ReadFile("config.yaml")
+	if os.IsNotExist(err) {
+		cm.logger.Info("未使用配置文件，跳过YAML同步")
+		return nil
+	}
 	if err != nil {
 		return fmt.Errorf("读取配置文件失败: %v", err)
 	}
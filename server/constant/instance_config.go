@@ -25,6 +25,7 @@ const (
 	ProviderTypeLXD     ProviderType = "lxd"
 	ProviderTypeIncus   ProviderType = "incus"
 	ProviderTypeProxmox ProviderType = "proxmox"
+	ProviderTypePodman  ProviderType = "podman"
 )
 
 // Architecture 架构类型
@@ -286,3 +287,36 @@ func GetBandwidthSpecByID(id string) (*BandwidthSpec, error) {
 	}
 	return nil, errors.New("带宽规格配置未找到")
 }
+
+// CPUSpecForCores 返回核心数不超过cores的最大CPU规格，没有匹配规格时返回nil
+func CPUSpecForCores(cores int) *CPUSpec {
+	var matched *CPUSpec
+	for i := range PredefinedCPUSpecs {
+		if PredefinedCPUSpecs[i].Cores <= cores && (matched == nil || PredefinedCPUSpecs[i].Cores > matched.Cores) {
+			matched = &PredefinedCPUSpecs[i]
+		}
+	}
+	return matched
+}
+
+// MemorySpecForSize 返回大小不超过sizeMB的最大内存规格，没有匹配规格时返回nil
+func MemorySpecForSize(sizeMB int64) *MemorySpec {
+	var matched *MemorySpec
+	for i := range PredefinedMemorySpecs {
+		if int64(PredefinedMemorySpecs[i].SizeMB) <= sizeMB && (matched == nil || PredefinedMemorySpecs[i].SizeMB > matched.SizeMB) {
+			matched = &PredefinedMemorySpecs[i]
+		}
+	}
+	return matched
+}
+
+// DiskSpecForSize 返回大小不超过sizeMB的最大磁盘规格，没有匹配规格时返回nil
+func DiskSpecForSize(sizeMB int64) *DiskSpec {
+	var matched *DiskSpec
+	for i := range PredefinedDiskSpecs {
+		if int64(PredefinedDiskSpecs[i].SizeMB) <= sizeMB && (matched == nil || PredefinedDiskSpecs[i].SizeMB > matched.SizeMB) {
+			matched = &PredefinedDiskSpecs[i]
+		}
+	}
+	return matched
+}
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"oneclickvirt/config"
 	"oneclickvirt/global"
 
 	"github.com/fsnotify/fsnotify"
@@ -29,8 +30,13 @@ func Viper(path ...string) *viper.Viper {
 
 	err := v.ReadInConfig()
 	if err != nil {
-		fmt.Printf("[VIPER] 配置文件读取错误: %s，使用默认配置\n", err)
+		fmt.Printf("[VIPER] 配置文件读取错误: %s，使用默认配置和环境变量\n", err)
 		// 不要panic，而是使用默认配置继续运行
+		setDefaults(v)
+		if err := v.Unmarshal(&global.APP_CONFIG); err != nil {
+			fmt.Printf("[VIPER] 默认配置解析失败: %v\n", err)
+		}
+		applyEnvOverrides()
 		return v
 	}
 
@@ -40,11 +46,13 @@ func Viper(path ...string) *viper.Viper {
 		if err := v.Unmarshal(&global.APP_CONFIG); err != nil {
 			fmt.Printf("[VIPER] 配置解析失败: %v\n", err)
 		}
+		applyEnvOverrides()
 	})
 
 	if err := v.Unmarshal(&global.APP_CONFIG); err != nil {
 		fmt.Printf("[VIPER] 配置解析失败: %v\n", err)
 	}
+	applyEnvOverrides()
 
 	// 设置默认值
 	setDefaults(v)
@@ -52,9 +60,20 @@ func Viper(path ...string) *viper.Viper {
 	return v
 }
 
+// applyEnvOverrides 用 ONECLICKVIRT_ 前缀的环境变量覆盖配置文件中的值
+func applyEnvOverrides() {
+	applied, err := config.ApplyEnvOverrides(&global.APP_CONFIG)
+	if err != nil {
+		fmt.Printf("[VIPER] %v\n", err)
+	}
+	if len(applied) > 0 {
+		fmt.Printf("[VIPER] 以下配置使用环境变量: %s\n", strings.Join(applied, ", "))
+	}
+}
+
 func setDefaults(v *viper.Viper) {
 	v.SetDefault("system.env", "public")
-	v.SetDefault("system.addr", 8080)
+	v.SetDefault("system.addr", 8888)
 	v.SetDefault("system.db-type", "mysql")
 	v.SetDefault("system.oss-type", "local")
 	v.SetDefault("system.use-multipoint", false)
@@ -73,6 +92,15 @@ func setDefaults(v *viper.Viper) {
 	}
 
 	v.SetDefault("jwt.signing-key", randomKey)
+	v.SetDefault("mysql.port", "3306")
+	v.SetDefault("mysql.config", "charset=utf8mb4&parseTime=True&loc=Local")
+	v.SetDefault("mysql.engine", "InnoDB")
+	v.SetDefault("mysql.log-mode", "error")
+	v.SetDefault("mysql.max-idle-conns", 50)
+	v.SetDefault("mysql.max-open-conns", 500)
+	v.SetDefault("mysql.max-lifetime", 900)
+	v.SetDefault("mysql.auto-create", true)
+
 	v.SetDefault("jwt.expires-time", "7d")
 	v.SetDefault("jwt.buffer-time", "1d")
 	v.SetDefault("jwt.issuer", "oneclickvirt")
@@ -80,6 +80,10 @@ func syncConfigToGlobal(key string, oldValue, newValue interface{}) error {
 			syncOtherConfig(otherConfig)
 		}
 	}
+	// 环境变量优先于数据库和YAML中的配置，同步后重新覆盖
+	if _, err := config.ApplyEnvOverrides(&global.APP_CONFIG); err != nil {
+		global.APP_LOG.Debug("环境变量配置覆盖失败", zap.Error(err))
+	}
 	return nil
 }
 
@@ -187,6 +187,21 @@ func RegisterTables(db *gorm.DB) {
 		&providerModel.Port{},     // 端口映射表
 		&adminModel.Task{},        // 用户任务表
 
+		// 实例防火墙规则表
+		&providerModel.InstanceFirewallRule{}, // 实例防火墙规则表
+
+		// 实例备份表
+		&providerModel.Backup{}, // 实例备份记录表
+
+		// 实例定时开关机计划表
+		&providerModel.InstanceSchedule{}, // 实例定时开关机计划表
+
+		// 实例模板表
+		&providerModel.InstanceTemplate{}, // 实例模板表
+
+		// 实例共享授权表
+		&providerModel.InstanceShare{}, // 实例共享授权表
+
 		// 资源管理表
 		&resourceModel.ResourceReservation{}, // 资源预留表
 
@@ -212,6 +227,9 @@ func RegisterTables(db *gorm.DB) {
 		&adminModel.AuditLog{},           // 操作审计日志表
 		&providerModel.PendingDeletion{}, // 待删除资源表
 
+		// Webhook通知表
+		&adminModel.Webhook{}, // Webhook通知配置表
+
 		// 管理员配置任务表
 		&adminModel.ConfigurationTask{},  // 管理员配置任务表
 		&adminModel.TrafficMonitorTask{}, // 流量监控操作任务表
@@ -223,6 +241,8 @@ func RegisterTables(db *gorm.DB) {
 		&monitoringModel.ProviderTrafficHistory{}, // Provider流量历史表
 		&monitoringModel.UserTrafficHistory{},     // 用户流量历史表
 		&monitoringModel.PerformanceMetric{},      // 性能指标历史表
+		&monitoringModel.ResourceAlert{},          // 实例资源告警记录表
+		&monitoringModel.ResourceAlertSetting{},   // 用户资源告警设置表
 	)
 	if err != nil {
 		global.APP_LOG.Error("register table failed", zap.Error(err))
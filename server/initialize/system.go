@@ -6,6 +6,7 @@ import (
 
 	"oneclickvirt/core"
 	"oneclickvirt/global"
+	"oneclickvirt/service/alert"
 	"oneclickvirt/service/auth"
 	"oneclickvirt/service/lifecycle"
 	"oneclickvirt/service/log"
@@ -15,6 +16,7 @@ import (
 	"oneclickvirt/service/storage"
 	"oneclickvirt/service/system"
 	"oneclickvirt/service/task"
+	userInstanceService "oneclickvirt/service/user/instance"
 	userProviderService "oneclickvirt/service/user/provider"
 	"oneclickvirt/utils"
 
@@ -266,7 +268,7 @@ func syncProvidersDataOnStartup() {
 
 	for _, prov := range providers {
 		// 1. 同步资源使用情况（基于数据库中的实例记录）
-		if err := resourceService.SyncProviderResources(prov.ID); err != nil {
+		if _, err := resourceService.SyncProviderResources(prov.ID); err != nil {
 			global.APP_LOG.Warn("同步Provider资源失败",
 				zap.Uint("providerID", prov.ID),
 				zap.String("providerName", prov.Name),
@@ -320,6 +322,26 @@ func initializeSchedulers() {
 	providerHealthSchedulerService.Start(global.APP_SHUTDOWN_CONTEXT)
 	lifecycleMgr.Register("ProviderHealthScheduler", providerHealthSchedulerService)
 
+	// 启动实例电源状态同步，按宿主机实际状态校正数据库中的running/stopped
+	powerStateSchedulerService := scheduler.NewPowerStateSchedulerService()
+	powerStateSchedulerService.Start(global.APP_SHUTDOWN_CONTEXT)
+	lifecycleMgr.Register("PowerStateScheduler", powerStateSchedulerService)
+
+	// 启动实例资源告警采样（是否采样由quota.resource-alert-enabled动态控制）
+	resourceAlertWorker := alert.GetWorker()
+	resourceAlertWorker.Start(global.APP_SHUTDOWN_CONTEXT)
+	lifecycleMgr.Register("ResourceAlertWorker", resourceAlertWorker)
+
+	// 启动实例流量突增检测（是否检测由quota.traffic-spike-alert-enabled动态控制）
+	trafficSpikeWorker := alert.GetTrafficSpikeWorker()
+	trafficSpikeWorker.Start(global.APP_SHUTDOWN_CONTEXT)
+	lifecycleMgr.Register("TrafficSpikeWorker", trafficSpikeWorker)
+
+	// 启动实例定时开关机执行器
+	scheduleWorker := userInstanceService.GetScheduleWorker()
+	scheduleWorker.Start(global.APP_SHUTDOWN_CONTEXT)
+	lifecycleMgr.Register("InstanceScheduleWorker", scheduleWorker)
+
 	// 注册pmacct批处理器
 	pmacctBatchProcessor := pmacct.GetBatchProcessor()
 	lifecycleMgr.Register("PmacctBatchProcessor", pmacctBatchProcessor)
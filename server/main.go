@@ -4,8 +4,10 @@ import (
 	"fmt"
 	_ "net/http/pprof"
 	"os"
+	"strings"
 
 	systemAPI "oneclickvirt/api/v1/system"
+	"oneclickvirt/config"
 	"oneclickvirt/global"
 	"oneclickvirt/initialize"
 
@@ -13,6 +15,7 @@ import (
 	_ "oneclickvirt/provider/docker"
 	_ "oneclickvirt/provider/incus"
 	_ "oneclickvirt/provider/lxd"
+	_ "oneclickvirt/provider/podman"
 	_ "oneclickvirt/provider/proxmox"
 
 	"go.uber.org/zap"
@@ -55,9 +58,14 @@ func main() {
 // ensureCorrectWorkingDirectory 确保从正确的工作目录启动
 func ensureCorrectWorkingDirectory() {
 	if _, err := os.Stat("config.yaml"); os.IsNotExist(err) {
-		fmt.Println("[ERROR] 未找到 config.yaml 文件")
-		fmt.Println("[HINT] 请确保从项目的 server 目录启动程序")
-		os.Exit(1)
+		// 没有配置文件时允许完全通过环境变量提供配置
+		if missing := config.MissingRequiredEnv(); len(missing) > 0 {
+			fmt.Println("[ERROR] 未找到 config.yaml 文件，且未通过环境变量提供必需配置")
+			fmt.Printf("[ERROR] 缺少环境变量: %s\n", strings.Join(missing, ", "))
+			fmt.Println("[HINT] 请从项目的 server 目录启动程序，或设置以上环境变量（前缀 " + config.EnvPrefix + "，详见 README）")
+			os.Exit(1)
+		}
+		fmt.Println("[SYSTEM] 未找到 config.yaml，使用环境变量配置")
 	}
 	if err := os.MkdirAll("storage", 0755); err != nil {
 		fmt.Printf("[ERROR] 无法创建 storage 目录: %v\n", err)
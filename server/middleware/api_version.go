@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DeprecationInfo 已弃用接口的迁移信息
+type DeprecationInfo struct {
+	Since       time.Time // 开始弃用的时间，为零值时 Deprecation 头为 true
+	Sunset      time.Time // 计划下线的时间，为零值时不返回 Sunset 头
+	Replacement string    // 替代接口路径，如 /api/v2/user/profile
+}
+
+var (
+	deprecatedRoutes   = make(map[string]DeprecationInfo)
+	deprecatedRoutesMu sync.RWMutex
+)
+
+// MarkDeprecated 将接口登记为已弃用，path 为注册路由时的完整路径（含 /api 前缀和 :param 占位符）
+func MarkDeprecated(method, path string, info DeprecationInfo) {
+	deprecatedRoutesMu.Lock()
+	defer deprecatedRoutesMu.Unlock()
+	deprecatedRoutes[strings.ToUpper(method)+" "+path] = info
+}
+
+// DeprecatedRoutes 返回所有已登记的弃用接口，键为 "METHOD path"
+func DeprecatedRoutes() map[string]DeprecationInfo {
+	deprecatedRoutesMu.RLock()
+	defer deprecatedRoutesMu.RUnlock()
+	routes := make(map[string]DeprecationInfo, len(deprecatedRoutes))
+	for key, info := range deprecatedRoutes {
+		routes[key] = info
+	}
+	return routes
+}
+
+// APIVersion 为API响应添加版本头，并为已弃用的接口添加 Deprecation、Sunset 和 Link 头
+// Deprecation 遵循 RFC 9745（@Unix时间戳），Sunset 遵循 RFC 8594，替代接口通过 rel="successor-version" 给出
+func APIVersion() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if version := apiVersionOf(c.Request.URL.Path); version != "" {
+			c.Header("API-Version", version)
+		}
+
+		deprecatedRoutesMu.RLock()
+		info, deprecated := deprecatedRoutes[c.Request.Method+" "+c.FullPath()]
+		deprecatedRoutesMu.RUnlock()
+		if deprecated {
+			if info.Since.IsZero() {
+				c.Header("Deprecation", "true")
+			} else {
+				c.Header("Deprecation", fmt.Sprintf("@%d", info.Since.Unix()))
+			}
+			if !info.Sunset.IsZero() {
+				c.Header("Sunset", info.Sunset.UTC().Format(http.TimeFormat))
+			}
+			if info.Replacement != "" {
+				c.Header("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", info.Replacement))
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// apiVersionOf 从 /api/vN/... 路径中提取版本号，非版本化路径返回空字符串
+func apiVersionOf(path string) string {
+	rest, ok := strings.CutPrefix(path, "/api/")
+	if !ok {
+		return ""
+	}
+	version, _, _ := strings.Cut(rest, "/")
+	if len(version) < 2 || version[0] != 'v' || strings.Trim(version[1:], "0123456789") != "" {
+		return ""
+	}
+	return version
+}
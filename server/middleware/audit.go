@@ -0,0 +1,138 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	adminModel "oneclickvirt/model/admin"
+	auditLog "oneclickvirt/service/log"
+	"oneclickvirt/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	auditMaxBodySize   = 64 << 10 // 审计只读取64KB以内的请求体
+	auditMaxStoredBody = 2000     // 落库的请求体最大长度
+	auditRedacted      = "******"
+)
+
+// auditSensitiveKeyParts 字段名（转小写并去掉 - 和 _ 后）包含这些片段时脱敏
+// 覆盖 password、sshKeyPassphrase 等请求字段以及 signing-key、metrics-token、secret-key 等配置项
+var auditSensitiveKeyParts = []string{"password", "passwd", "secret", "token", "key", "credential", "passphrase"}
+
+// AuditMiddleware 记录变更类请求（POST/PUT/PATCH/DELETE）的审计日志
+// 必须放在 RequireAuth 之后，以便取得操作者信息
+func AuditMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		method := c.Request.Method
+		if method != http.MethodPost && method != http.MethodPut &&
+			method != http.MethodPatch && method != http.MethodDelete {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		var body []byte
+		if c.Request.Body != nil && !strings.HasPrefix(c.ContentType(), "multipart/") &&
+			c.Request.ContentLength <= auditMaxBodySize {
+			body, _ = io.ReadAll(io.LimitReader(c.Request.Body, auditMaxBodySize))
+			c.Request.Body = io.NopCloser(bytes.NewBuffer(body))
+		}
+
+		c.Next()
+
+		status := c.Writer.Status()
+		entry := &adminModel.AuditLog{
+			Method:     method,
+			Path:       utils.TruncateString(c.Request.URL.Path, 255),
+			StatusCode: status,
+			Latency:    time.Since(start).Milliseconds(),
+			ClientIP:   c.ClientIP(),
+			UserAgent:  utils.TruncateString(c.Request.UserAgent(), 255),
+			Request:    redactAuditBody(body),
+			Action:     utils.TruncateString(method+" "+c.FullPath(), 128),
+			Target:     utils.TruncateString(auditTarget(c), 255),
+			Result:     "success",
+		}
+		if status >= http.StatusBadRequest || len(c.Errors) > 0 {
+			entry.Result = "failure"
+		}
+		if authCtx, ok := GetAuthContext(c); ok {
+			userID := authCtx.UserID
+			entry.UserID = &userID
+			entry.Username = authCtx.Username
+		}
+
+		auditLog.NewAuditService().Record(entry)
+	}
+}
+
+// auditTarget 使用路由参数描述操作对象，例如 "id=3,name=web-1"
+func auditTarget(c *gin.Context) string {
+	parts := make([]string, 0, len(c.Params))
+	for _, p := range c.Params {
+		parts = append(parts, p.Key+"="+p.Value)
+	}
+	return strings.Join(parts, ",")
+}
+
+// redactAuditBody 对JSON请求体中的密码、密钥等字段脱敏，非JSON请求体不落库
+func redactAuditBody(body []byte) string {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return ""
+	}
+	var data interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return "[非JSON请求体已省略]"
+	}
+	redacted, err := json.Marshal(redactAuditValue(data))
+	if err != nil {
+		return ""
+	}
+	return utils.TruncateString(string(redacted), auditMaxStoredBody)
+}
+
+func redactAuditValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, item := range val {
+			if isAuditSensitiveKey(k) {
+				switch item.(type) {
+				case map[string]interface{}:
+					// 如 jwt 配置对象，继续按字段脱敏
+				case bool, float64:
+					// 如 generated-password-length、oauth2-state-token-minutes 等开关和数值配置不含敏感信息
+					continue
+				default:
+					val[k] = auditRedacted
+					continue
+				}
+			}
+			val[k] = redactAuditValue(item)
+		}
+		return val
+	case []interface{}:
+		for i, item := range val {
+			val[i] = redactAuditValue(item)
+		}
+		return val
+	default:
+		return v
+	}
+}
+
+// isAuditSensitiveKey 判断字段名是否需要脱敏，忽略大小写以及 - 和 _ 分隔符
+func isAuditSensitiveKey(key string) bool {
+	normalized := strings.NewReplacer("-", "", "_", "").Replace(strings.ToLower(key))
+	for _, part := range auditSensitiveKeyParts {
+		if strings.Contains(normalized, part) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// TestRedactAuditBodyConfig 测试配置更新请求体中的各类密钥都被脱敏，普通配置项保留
+func TestRedactAuditBodyConfig(t *testing.T) {
+	body := `{
+		"scope": "admin",
+		"config": {
+			"jwt": {"signing-key": "jwt-signing-secret-value", "expires-time": "7d"},
+			"system": {"metrics-token": "metrics-secret", "oauth2-state-token-minutes": 15, "addr": 8888},
+			"upload": {"access-key": "AKIAEXAMPLE", "secret-key": "s3-secret", "bucket": "backups"},
+			"notification": {"telegram-bot-token": "123:abc", "qq-app-key": "qq-secret", "enabled": true},
+			"mysql": {"password": "db-secret", "username": "root"},
+			"quota": {"generated-password-length": 16},
+			"oauth2": [{"client_secret": "oauth-secret", "clientId": "app"}],
+			"sshKeyPassphrase": "ssh-secret",
+			"serviceCredential": "credential-secret"
+		}
+	}`
+
+	redacted := redactAuditBody([]byte(body))
+	for _, secret := range []string{
+		"jwt-signing-secret-value", "metrics-secret", "AKIAEXAMPLE", "s3-secret", "123:abc",
+		"qq-secret", "db-secret", "oauth-secret", "ssh-secret", "credential-secret",
+	} {
+		if strings.Contains(redacted, secret) {
+			t.Errorf("审计请求体中包含未脱敏的 %q: %s", secret, redacted)
+		}
+	}
+
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(redacted), &data); err != nil {
+		t.Fatalf("脱敏后的请求体不是JSON: %v", err)
+	}
+	config := data["config"].(map[string]interface{})
+	checks := map[string]interface{}{
+		"jwt.expires-time":                  "7d",
+		"system.addr":                       float64(8888),
+		"system.oauth2-state-token-minutes": float64(15),
+		"upload.bucket":                     "backups",
+		"notification.enabled":              true,
+		"mysql.username":                    "root",
+		"quota.generated-password-length":   float64(16),
+		"jwt.signing-key":                   auditRedacted,
+		"system.metrics-token":              auditRedacted,
+	}
+	for path, expect := range checks {
+		section, key, _ := strings.Cut(path, ".")
+		if got := config[section].(map[string]interface{})[key]; got != expect {
+			t.Errorf("%s = %v, 期望 %v", path, got, expect)
+		}
+	}
+}
+
+// TestIsAuditSensitiveKey 测试字段名忽略大小写和分隔符匹配
+func TestIsAuditSensitiveKey(t *testing.T) {
+	tests := map[string]bool{
+		"password":          true,
+		"newPassword":       true,
+		"signing-key":       true,
+		"SECRET_KEY":        true,
+		"accessToken":       true,
+		"sshKeyPassphrase":  true,
+		"credentials":       true,
+		"username":          false,
+		"expires-time":      false,
+		"image-name-prefix": false,
+	}
+	for key, expect := range tests {
+		if got := isAuditSensitiveKey(key); got != expect {
+			t.Errorf("isAuditSensitiveKey(%q) = %v, 期望 %v", key, got, expect)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// compressMinSize 响应体小于该值时不压缩，压缩小响应收益很低
+const compressMinSize = 1024
+
+// compressibleTypes 会被压缩的Content-Type前缀，图片、压缩包等已压缩的内容直接透传
+var compressibleTypes = []string{
+	"application/json",
+	"application/javascript",
+	"application/xml",
+	"image/svg+xml",
+	"text/",
+}
+
+var gzipWriterPool = sync.Pool{
+	New: func() interface{} {
+		w, _ := gzip.NewWriterLevel(nil, gzip.DefaultCompression)
+		return w
+	},
+}
+
+// Compression 根据Accept-Encoding对响应进行gzip压缩
+// 响应体先缓冲到compressMinSize再决定是否压缩；WebSocket升级请求、HEAD请求和已设置Content-Encoding的响应不处理。
+// 流式响应调用Flush时立即开始压缩输出
+func Compression() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method == http.MethodHead ||
+			!acceptsGzip(c.Request) ||
+			strings.EqualFold(c.Request.Header.Get("Upgrade"), "websocket") {
+			c.Next()
+			return
+		}
+
+		writer := &gzipResponseWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		defer func() {
+			writer.finish()
+			c.Writer = writer.ResponseWriter
+		}()
+
+		c.Next()
+	}
+}
+
+func acceptsGzip(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		encoding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if strings.EqualFold(strings.TrimSpace(encoding), "gzip") && strings.ReplaceAll(params, " ", "") != "q=0" {
+			return true
+		}
+	}
+	return false
+}
+
+// gzipResponseWriter 缓冲响应开头部分以决定是否压缩
+type gzipResponseWriter struct {
+	gin.ResponseWriter
+	status   int
+	buf      []byte
+	decided  bool
+	gzWriter *gzip.Writer
+}
+
+func (w *gzipResponseWriter) WriteHeader(code int) {
+	if !w.decided {
+		w.status = code
+		return
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *gzipResponseWriter) WriteHeaderNow() {
+	// 延迟到确定是否压缩后再写出响应头
+}
+
+func (w *gzipResponseWriter) Status() int {
+	if !w.decided && w.status != 0 {
+		return w.status
+	}
+	return w.ResponseWriter.Status()
+}
+
+func (w *gzipResponseWriter) Written() bool {
+	return w.decided || w.status != 0 || len(w.buf) > 0
+}
+
+func (w *gzipResponseWriter) Write(data []byte) (int, error) {
+	if !w.decided {
+		w.buf = append(w.buf, data...)
+		if len(w.buf) < compressMinSize {
+			return len(data), nil
+		}
+		if err := w.decide(true); err != nil {
+			return 0, err
+		}
+		return len(data), nil
+	}
+	if w.gzWriter != nil {
+		return w.gzWriter.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *gzipResponseWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *gzipResponseWriter) Flush() {
+	if !w.decided {
+		w.decide(true)
+	}
+	if w.gzWriter != nil {
+		w.gzWriter.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// Unwrap 供http.ResponseController访问底层连接（如流式响应取消写超时）
+func (w *gzipResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// decide 确定是否压缩并写出响应头和已缓冲的内容，wantCompress为false时原样输出
+func (w *gzipResponseWriter) decide(wantCompress bool) error {
+	w.decided = true
+	header := w.ResponseWriter.Header()
+	if wantCompress && w.shouldCompress(header) {
+		header.Set("Content-Encoding", "gzip")
+		header.Add("Vary", "Accept-Encoding")
+		header.Del("Content-Length")
+		w.gzWriter = gzipWriterPool.Get().(*gzip.Writer)
+		w.gzWriter.Reset(w.ResponseWriter)
+	}
+	if w.status != 0 {
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	var err error
+	if w.gzWriter != nil {
+		_, err = w.gzWriter.Write(buf)
+	} else {
+		_, err = w.ResponseWriter.Write(buf)
+	}
+	return err
+}
+
+func (w *gzipResponseWriter) shouldCompress(header http.Header) bool {
+	if header.Get("Content-Encoding") != "" {
+		return false
+	}
+	// 分段响应的Content-Range针对原始内容，压缩后会失配
+	if w.status == http.StatusPartialContent || w.status == http.StatusNoContent ||
+		w.status == http.StatusNotModified || (w.status >= 100 && w.status < 200) {
+		return false
+	}
+	contentType := strings.ToLower(header.Get("Content-Type"))
+	for _, prefix := range compressibleTypes {
+		if strings.HasPrefix(contentType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// finish 输出未达到压缩阈值的缓冲内容并关闭gzip流
+func (w *gzipResponseWriter) finish() {
+	if !w.decided {
+		w.decide(false)
+	}
+	if w.gzWriter != nil {
+		w.gzWriter.Close()
+		gzipWriterPool.Put(w.gzWriter)
+		w.gzWriter = nil
+	}
+}
@@ -0,0 +1,182 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"oneclickvirt/global"
+	"oneclickvirt/model/auth"
+	"oneclickvirt/model/common"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// 用户接口限流分类
+const (
+	RateLimitCategoryCreate = "create" // 创建实例、克隆、申领资源
+	RateLimitCategoryExec   = "exec"   // 需要在节点上执行命令的接口（实例内执行命令、查看日志）
+	RateLimitCategoryWrite  = "write"  // 其他修改类接口
+	RateLimitCategoryRead   = "read"   // 查询类接口
+)
+
+// 各分类未配置时每分钟允许的请求数
+var defaultRateLimits = map[string]int{
+	RateLimitCategoryCreate: 5,
+	RateLimitCategoryExec:   20,
+	RateLimitCategoryWrite:  30,
+	RateLimitCategoryRead:   120,
+}
+
+// rateLimitBucketIdle 令牌桶闲置超过该时间后回收（已回满，重建不影响限流结果）
+const rateLimitBucketIdle = 10 * time.Minute
+
+var (
+	rateLimitRoutes   = make(map[string]string)
+	rateLimitRoutesMu sync.RWMutex
+)
+
+// SetRateLimitCategory 指定接口的限流分类，path 为注册路由时的完整路径（含 /api 前缀和 :param 占位符）
+// 未指定的接口按请求方法归类：GET/HEAD 为 read，其余为 write
+func SetRateLimitCategory(method, path, category string) {
+	rateLimitRoutesMu.Lock()
+	defer rateLimitRoutesMu.Unlock()
+	rateLimitRoutes[method+" "+path] = category
+}
+
+// rateLimitCategoryOf 请求所属的限流分类
+func rateLimitCategoryOf(c *gin.Context) string {
+	rateLimitRoutesMu.RLock()
+	category, ok := rateLimitRoutes[c.Request.Method+" "+c.FullPath()]
+	rateLimitRoutesMu.RUnlock()
+	if ok {
+		return category
+	}
+	if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+		return RateLimitCategoryRead
+	}
+	return RateLimitCategoryWrite
+}
+
+// rateLimitPerMinute 分类每分钟允许的请求数，0表示不限制
+func rateLimitPerMinute(category string) int {
+	var configured int
+	quota := global.APP_CONFIG.Quota
+	switch category {
+	case RateLimitCategoryCreate:
+		configured = quota.RateLimitCreate
+	case RateLimitCategoryExec:
+		configured = quota.RateLimitExec
+	case RateLimitCategoryWrite:
+		configured = quota.RateLimitWrite
+	case RateLimitCategoryRead:
+		configured = quota.RateLimitRead
+	}
+	if configured < 0 {
+		return 0
+	}
+	if configured == 0 {
+		return defaultRateLimits[category]
+	}
+	return configured
+}
+
+// tokenBucket 令牌桶，容量为每分钟请求数，令牌按每分钟请求数匀速补充
+type tokenBucket struct {
+	tokens   float64
+	capacity float64
+	updated  time.Time
+}
+
+// take 尝试取出一个令牌，失败时返回需要等待的时间
+func (b *tokenBucket) take(now time.Time, perMinute int) (bool, time.Duration) {
+	capacity := float64(perMinute)
+	ratePerSecond := capacity / 60
+	if b.capacity != capacity {
+		// 限额调整后按新容量截断，避免调低限额后仍可突发旧容量的请求
+		b.tokens = math.Min(b.tokens, capacity)
+		b.capacity = capacity
+	}
+	b.tokens = math.Min(capacity, b.tokens+now.Sub(b.updated).Seconds()*ratePerSecond)
+	b.updated = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	return false, time.Duration((1 - b.tokens) / ratePerSecond * float64(time.Second))
+}
+
+// rateLimiter 按用户和分类维护令牌桶
+type rateLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+var userRateLimiter = &rateLimiter{buckets: make(map[string]*tokenBucket)}
+
+// allow 判断用户在该分类下的请求是否放行
+func (l *rateLimiter) allow(userID uint, category string, perMinute int, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) > time.Minute {
+		for key, bucket := range l.buckets {
+			if now.Sub(bucket.updated) > rateLimitBucketIdle {
+				delete(l.buckets, key)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	key := fmt.Sprintf("%d:%s", userID, category)
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: float64(perMinute), capacity: float64(perMinute), updated: now}
+		l.buckets[key] = bucket
+	}
+	return bucket.take(now, perMinute)
+}
+
+// UserRateLimit 按用户ID和接口分类限流，需放在 RequireAuth 之后
+// 超出限额时返回429和 Retry-After 头（秒），管理员不受限制
+func UserRateLimit() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authCtx, ok := GetAuthContext(c)
+		if !ok || hasRequiredLevel(authCtx, auth.AuthLevelAdmin) {
+			c.Next()
+			return
+		}
+
+		category := rateLimitCategoryOf(c)
+		perMinute := rateLimitPerMinute(category)
+		if perMinute == 0 {
+			c.Next()
+			return
+		}
+
+		allowed, wait := userRateLimiter.allow(authCtx.UserID, category, perMinute, time.Now())
+		if !allowed {
+			retryAfter := int(math.Ceil(wait.Seconds()))
+			if retryAfter < 1 {
+				retryAfter = 1
+			}
+			global.APP_LOG.Debug("用户请求过于频繁",
+				zap.Uint("userID", authCtx.UserID),
+				zap.String("category", category),
+				zap.Int("perMinute", perMinute),
+				zap.String("path", c.FullPath()))
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			common.ResponseWithError(c, common.NewError(common.CodeTooManyRequests,
+				fmt.Sprintf("请求过于频繁，请%d秒后重试", retryAfter)))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
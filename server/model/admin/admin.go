@@ -22,6 +22,7 @@ type Task struct {
 	TaskType string `json:"taskType" gorm:"not null;size:32"`                                                                               // 任务类型：create, start, stop, restart, reset, delete, reset-password
 	Status   string `json:"status" gorm:"default:pending;size:32;index:idx_status_created,priority:1;index:idx_provider_status,priority:2"` // 任务状态：pending, processing, running, completed, failed, cancelling, cancelled, timeout
 	Progress int    `json:"progress" gorm:"default:0"`                                                                                      // 任务执行进度百分比（0-100）
+	Priority int    `json:"priority" gorm:"default:0"`                                                                                      // 任务优先级，数值越大越先执行
 
 	// 错误和状态信息
 	ErrorMessage  string `json:"errorMessage" gorm:"type:text"` // 任务失败时的错误信息
@@ -54,6 +55,14 @@ type Task struct {
 	IsForceStoppable bool `json:"isForceStoppable" gorm:"default:true"` // 是否允许被强制停止
 }
 
+// 任务优先级：排队中的任务按优先级从高到低、同优先级按创建时间先后执行
+const (
+	TaskPriorityNormal = 0   // 用户发起的常规任务
+	TaskPrioritySystem = 30  // 系统发起的任务（如到期回收）
+	TaskPriorityAdmin  = 50  // 管理员发起的实例操作
+	TaskPriorityMax    = 100 // 允许设置的最高优先级
+)
+
 func (t *Task) BeforeCreate(tx *gorm.DB) error {
 	t.UUID = uuid.New().String()
 	return nil
@@ -75,6 +84,11 @@ type AuditLog struct {
 	UserAgent  string         `json:"userAgent" gorm:"size:255"`
 	Request    string         `json:"request" gorm:"type:text"`
 	Response   string         `json:"response" gorm:"type:text"`
+
+	// 审计字段
+	Action string `json:"action" gorm:"size:128;index"` // 操作，格式为 "方法 路由模板"
+	Target string `json:"target" gorm:"size:255"`       // 操作对象，由路由参数拼接
+	Result string `json:"result" gorm:"size:16;index"`  // success / failure
 }
 
 // SystemConfig 系统配置模型
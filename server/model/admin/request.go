@@ -1,6 +1,11 @@
 package admin
 
-import "oneclickvirt/model/common"
+import (
+	"time"
+
+	"oneclickvirt/model/common"
+	providerModel "oneclickvirt/model/provider"
+)
 
 type CreateUserRequest struct {
 	Username   string `json:"username" binding:"required"`
@@ -31,6 +36,8 @@ type UpdateUserRequest struct {
 	TotalQuota int    `json:"totalQuota"`
 	Status     int    `json:"status"`
 	RoleID     uint   `json:"roleId"`
+
+	AllowInstanceExec *bool `json:"allowInstanceExec"` // 是否允许在实例内执行命令，为空表示不修改
 }
 
 type UserListRequest struct {
@@ -40,6 +47,18 @@ type UserListRequest struct {
 	Status   *int   `json:"status" form:"status"`
 }
 
+// AuditLogListRequest 审计日志查询请求
+type AuditLogListRequest struct {
+	common.PageInfo
+	UserID    *uint  `json:"userId" form:"userId"`
+	Username  string `json:"username" form:"username"`
+	Action    string `json:"action" form:"action"`
+	Method    string `json:"method" form:"method"`
+	Result    string `json:"result" form:"result"`
+	StartTime string `json:"startTime" form:"startTime"` // RFC3339 或 2006-01-02 15:04:05
+	EndTime   string `json:"endTime" form:"endTime"`
+}
+
 type CreateProviderRequest struct {
 	Name                  string `json:"name" binding:"required"`
 	Type                  string `json:"type" binding:"required"`
@@ -48,7 +67,10 @@ type CreateProviderRequest struct {
 	SSHPort               int    `json:"sshPort"`
 	Username              string `json:"username"`
 	Password              string `json:"password"`
-	SSHKey                string `json:"sshKey"` // SSH私钥，优先于密码使用
+	SSHKey                string `json:"sshKey"`           // SSH私钥，优先于密码使用
+	SSHKeyPassphrase      string `json:"sshKeyPassphrase"` // 加密私钥的密码短语
+	SSHUseAgent           bool   `json:"sshUseAgent"`      // 是否使用ssh-agent认证
+	SSHVerifyHostKey      bool   `json:"sshVerifyHostKey"` // 是否校验SSH主机密钥（首次连接时固定指纹）
 	Token                 string `json:"token"`
 	Config                string `json:"config"`
 	Region                string `json:"region"`
@@ -68,7 +90,11 @@ type CreateProviderRequest struct {
 	MaxConcurrentTasks    int    `json:"maxConcurrentTasks"`    // 最大并发任务数，默认1
 	TaskPollInterval      int    `json:"taskPollInterval"`      // 任务轮询间隔（秒），默认60秒
 	EnableTaskPolling     bool   `json:"enableTaskPolling"`     // 是否启用任务轮询，默认true
-	// 存储配置（ProxmoxVE专用）
+	// 实例创建并发限制
+	MaxConcurrentCreations int `json:"maxConcurrentCreations"` // 同时执行的实例创建任务上限，0表示不单独限制
+	// GPU直通（仅Proxmox/Incus虚拟机）
+	GPUPassthroughEnabled bool `json:"gpuPassthroughEnabled"` // 是否允许用户创建实例时直通宿主机GPU
+	// 存储配置（ProxmoxVE、LXD、Incus）
 	StoragePool string `json:"storagePool"` // 存储池名称，用于存储虚拟机磁盘和容器
 	// 操作执行配置
 	ExecutionRule string `json:"executionRule" binding:"oneof=auto api_only ssh_only"` // 操作轮转规则：auto(自动切换), api_only(仅API), ssh_only(仅SSH)
@@ -110,18 +136,46 @@ type CreateProviderRequest struct {
 	VMLimitCpu    bool `json:"vmLimitCpu"`    // 虚拟机CPU是否计入总量预算
 	VMLimitMemory bool `json:"vmLimitMemory"` // 虚拟机内存是否计入总量预算
 	VMLimitDisk   bool `json:"vmLimitDisk"`   // 虚拟机硬盘是否计入总量预算
+	// 资源超分配比例，不小于1.0，0表示使用默认值1.0（不超分配）
+	CPUOvercommitRatio    float64 `json:"cpuOvercommitRatio"`    // CPU超分配比例，如4.0表示4:1
+	MemoryOvercommitRatio float64 `json:"memoryOvercommitRatio"` // 内存超分配比例，如1.2表示1.2:1
 	// 容器特殊配置选项（仅 LXD/Incus 容器）
 	ContainerPrivileged   bool   `json:"containerPrivileged"`   // 是否启用特权容器
 	ContainerAllowNesting bool   `json:"containerAllowNesting"` // 是否允许嵌套虚拟化
 	ContainerEnableLXCFS  bool   `json:"containerEnableLxcfs"`  // 是否启用LXCFS
+	ContainerLXCFSMounts  string `json:"containerLxcfsMounts"`  // LXCFS挂载文件列表（逗号分隔，仅Docker）
 	ContainerCPUAllowance string `json:"containerCpuAllowance"` // CPU使用率上限（如"100%"）
-	ContainerMemorySwap   bool   `json:"containerMemorySwap"`   // 是否允许使用swap
-	ContainerMaxProcesses int    `json:"containerMaxProcesses"` // 最大进程数限制（0表示不限制）
-	ContainerDiskIOLimit  string `json:"containerDiskIoLimit"`  // 磁盘IO限制（如"10MB"或"100iops"）
+	// 未指定CPU使用率上限时按核心数生成CPU限额的方式：percent(N*100%)、time(N*100ms/100ms)、none(不设置)，为空使用percent
+	ContainerCPUAllowanceMode string `json:"containerCpuAllowanceMode" binding:"omitempty,oneof=percent time none"`
+	ContainerMemorySwap       bool   `json:"containerMemorySwap"`   // 是否允许使用swap
+	ContainerMaxProcesses     int    `json:"containerMaxProcesses"` // 最大进程数限制（0表示不限制）
+	ContainerDiskIOLimit      string `json:"containerDiskIoLimit"`  // 磁盘IO限制（如"10MB"或"100iops"）
 
 	// 节点级别的等级限制配置
 	// 用于限制该节点上不同等级用户能创建的最大资源
 	LevelLimits map[int]map[string]interface{} `json:"levelLimits"` // 等级限制配置
+
+	// 允许用户附加额外网卡的宿主机网络（逗号分隔），为空表示不允许
+	ExtraNetworks string `json:"extraNetworks"`
+
+	// 允许用户挂载到实例内的宿主机目录（逗号分隔的绝对路径），为空表示不允许
+	VolumeAllowlist string `json:"volumeAllowlist"`
+
+	// Docker/Podman拉取仓库镜像的凭据，仓库地址为空表示Docker Hub，用户名为空时匿名拉取
+	RegistryServer   string `json:"registryServer"`
+	RegistryUsername string `json:"registryUsername"`
+	RegistryPassword string `json:"registryPassword"`
+
+	// 删除实例的其他策略都失败时是否允许 system prune 兜底清理（仅Docker/Podman），默认关闭
+	AllowSystemPrune bool `json:"allowSystemPrune"`
+
+	// 自动选择节点时的权重（1-100），0表示使用默认值1
+	PlacementWeight int `json:"placementWeight"`
+
+	// 实例默认资源限制，创建实例未指定对应资源时使用，0表示未设置
+	DefaultCPU    int   `json:"defaultCpu"`    // 默认CPU核心数
+	DefaultMemory int64 `json:"defaultMemory"` // 默认内存大小（MB）
+	DefaultDisk   int64 `json:"defaultDisk"`   // 默认磁盘大小（MB）
 }
 
 type UpdateProviderRequest struct {
@@ -132,8 +186,11 @@ type UpdateProviderRequest struct {
 	PortIP                string  `json:"portIP"` // 端口映射使用的公网IP
 	SSHPort               int     `json:"sshPort"`
 	Username              string  `json:"username"`
-	Password              *string `json:"password,omitempty"` // 使用指针以区分"未提供"和"空值"
-	SSHKey                *string `json:"sshKey,omitempty"`   // SSH私钥，使用指针以区分"未提供"和"空值"
+	Password              *string `json:"password,omitempty"`         // 使用指针以区分"未提供"和"空值"
+	SSHKey                *string `json:"sshKey,omitempty"`           // SSH私钥，使用指针以区分"未提供"和"空值"
+	SSHKeyPassphrase      *string `json:"sshKeyPassphrase,omitempty"` // 加密私钥的密码短语，使用指针以区分"未提供"和"空值"
+	SSHUseAgent           *bool   `json:"sshUseAgent,omitempty"`      // 是否使用ssh-agent认证，为空表示不修改
+	SSHVerifyHostKey      *bool   `json:"sshVerifyHostKey,omitempty"` // 是否校验SSH主机密钥（首次连接时固定指纹），为空表示不修改
 	Token                 string  `json:"token"`
 	Config                string  `json:"config"`
 	Region                string  `json:"region"`
@@ -153,7 +210,11 @@ type UpdateProviderRequest struct {
 	MaxConcurrentTasks    int     `json:"maxConcurrentTasks"`    // 最大并发任务数，默认1
 	TaskPollInterval      int     `json:"taskPollInterval"`      // 任务轮询间隔（秒），默认60秒
 	EnableTaskPolling     bool    `json:"enableTaskPolling"`     // 是否启用任务轮询，默认true
-	// 存储配置（ProxmoxVE专用）
+	// 实例创建并发限制
+	MaxConcurrentCreations int `json:"maxConcurrentCreations"` // 同时执行的实例创建任务上限，0表示不单独限制
+	// GPU直通（仅Proxmox/Incus虚拟机）
+	GPUPassthroughEnabled bool `json:"gpuPassthroughEnabled"` // 是否允许用户创建实例时直通宿主机GPU
+	// 存储配置（ProxmoxVE、LXD、Incus）
 	StoragePool string `json:"storagePool"` // 存储池名称，用于存储虚拟机磁盘和容器
 	// 操作执行配置
 	ExecutionRule string `json:"executionRule" binding:"oneof=auto api_only ssh_only"` // 操作轮转规则：auto(自动切换), api_only(仅API), ssh_only(仅SSH)
@@ -195,18 +256,54 @@ type UpdateProviderRequest struct {
 	VMLimitCpu    bool `json:"vmLimitCpu"`    // 虚拟机CPU是否计入总量预算
 	VMLimitMemory bool `json:"vmLimitMemory"` // 虚拟机内存是否计入总量预算
 	VMLimitDisk   bool `json:"vmLimitDisk"`   // 虚拟机硬盘是否计入总量预算
+	// 资源超分配比例，不小于1.0，0表示使用默认值1.0（不超分配）
+	CPUOvercommitRatio    float64 `json:"cpuOvercommitRatio"`    // CPU超分配比例，如4.0表示4:1
+	MemoryOvercommitRatio float64 `json:"memoryOvercommitRatio"` // 内存超分配比例，如1.2表示1.2:1
 	// 容器特殊配置选项（仅 LXD/Incus 容器）
 	ContainerPrivileged   bool   `json:"containerPrivileged"`   // 是否启用特权容器
 	ContainerAllowNesting bool   `json:"containerAllowNesting"` // 是否允许嵌套虚拟化
 	ContainerEnableLXCFS  bool   `json:"containerEnableLxcfs"`  // 是否启用LXCFS
+	ContainerLXCFSMounts  string `json:"containerLxcfsMounts"`  // LXCFS挂载文件列表（逗号分隔，仅Docker）
 	ContainerCPUAllowance string `json:"containerCpuAllowance"` // CPU使用率上限（如"100%"）
-	ContainerMemorySwap   bool   `json:"containerMemorySwap"`   // 是否允许使用swap
-	ContainerMaxProcesses int    `json:"containerMaxProcesses"` // 最大进程数限制（0表示不限制）
-	ContainerDiskIOLimit  string `json:"containerDiskIoLimit"`  // 磁盘IO限制（如"10MB"或"100iops"）
+	// 未指定CPU使用率上限时按核心数生成CPU限额的方式：percent(N*100%)、time(N*100ms/100ms)、none(不设置)，为空使用percent
+	ContainerCPUAllowanceMode string `json:"containerCpuAllowanceMode" binding:"omitempty,oneof=percent time none"`
+	ContainerMemorySwap       bool   `json:"containerMemorySwap"`   // 是否允许使用swap
+	ContainerMaxProcesses     int    `json:"containerMaxProcesses"` // 最大进程数限制（0表示不限制）
+	ContainerDiskIOLimit      string `json:"containerDiskIoLimit"`  // 磁盘IO限制（如"10MB"或"100iops"）
 
 	// 节点级别的等级限制配置
 	// 用于限制该节点上不同等级用户能创建的最大资源
 	LevelLimits map[int]map[string]interface{} `json:"levelLimits"` // 等级限制配置
+
+	// 允许用户附加额外网卡的宿主机网络（逗号分隔），为空表示不允许
+	ExtraNetworks string `json:"extraNetworks"`
+
+	// 允许用户挂载到实例内的宿主机目录（逗号分隔的绝对路径），为空表示不允许
+	VolumeAllowlist string `json:"volumeAllowlist"`
+
+	// Docker/Podman拉取仓库镜像的凭据，仓库地址为空表示Docker Hub，用户名为空时匿名拉取
+	RegistryServer   string  `json:"registryServer"`
+	RegistryUsername string  `json:"registryUsername"`
+	RegistryPassword *string `json:"registryPassword,omitempty"` // 使用指针以区分"未提供"和"空值"
+
+	// 删除实例的其他策略都失败时是否允许 system prune 兜底清理（仅Docker/Podman），默认关闭
+	AllowSystemPrune bool `json:"allowSystemPrune"`
+
+	// 自动选择节点时的权重（1-100），0表示使用默认值1
+	PlacementWeight int `json:"placementWeight"`
+
+	// 实例默认资源限制，创建实例未指定对应资源时使用，0表示未设置
+	DefaultCPU    int   `json:"defaultCpu"`    // 默认CPU核心数
+	DefaultMemory int64 `json:"defaultMemory"` // 默认内存大小（MB）
+	DefaultDisk   int64 `json:"defaultDisk"`   // 默认磁盘大小（MB）
+}
+
+// RotateProviderCredentialsRequest 轮换Provider SSH凭据请求
+// 字段为nil表示保持不变，空字符串表示清除；新凭据校验通过后才会保存
+type RotateProviderCredentialsRequest struct {
+	Password         *string `json:"password,omitempty"`         // 新SSH密码
+	SSHKey           *string `json:"sshKey,omitempty"`           // 新SSH私钥
+	SSHKeyPassphrase *string `json:"sshKeyPassphrase,omitempty"` // 新私钥的密码短语
 }
 
 type ProviderListRequest struct {
@@ -214,12 +311,20 @@ type ProviderListRequest struct {
 	Name   string `json:"name" form:"name"`
 	Type   string `json:"type" form:"type"`
 	Status string `json:"status" form:"status"`
+
+	Maintenance *bool `json:"maintenance" form:"maintenance"` // 按是否处于维护模式筛选
 }
 
 type FreezeProviderRequest struct {
 	ID uint `json:"id" binding:"required"`
 }
 
+// SetProviderMaintenanceRequest 切换Provider维护模式请求
+type SetProviderMaintenanceRequest struct {
+	Enabled bool   `json:"enabled"`
+	Reason  string `json:"reason" binding:"max=255"` // 维护原因，关闭维护模式时清空
+}
+
 type UnfreezeProviderRequest struct {
 	ID        uint   `json:"id" binding:"required"`
 	ExpiresAt string `json:"expiresAt"` // 新的过期时间，格式: "2006-01-02 15:04:05"
@@ -227,12 +332,14 @@ type UnfreezeProviderRequest struct {
 
 // TestSSHConnectionRequest 测试SSH连接请求
 type TestSSHConnectionRequest struct {
-	Host      string `json:"host" binding:"required"`     // SSH服务器地址
-	Port      int    `json:"port" binding:"required"`     // SSH端口
-	Username  string `json:"username" binding:"required"` // SSH用户名
-	Password  string `json:"password"`                    // SSH密码（使用密码认证时必填）
-	SSHKey    string `json:"sshKey"`                      // SSH私钥（使用密钥认证时必填）
-	TestCount int    `json:"testCount"`                   // 测试次数，默认3次
+	Host             string `json:"host" binding:"required"`     // SSH服务器地址
+	Port             int    `json:"port" binding:"required"`     // SSH端口
+	Username         string `json:"username" binding:"required"` // SSH用户名
+	Password         string `json:"password"`                    // SSH密码（使用密码认证时必填）
+	SSHKey           string `json:"sshKey"`                      // SSH私钥（使用密钥认证时必填）
+	SSHKeyPassphrase string `json:"sshKeyPassphrase"`            // 加密私钥的密码短语
+	SSHUseAgent      bool   `json:"sshUseAgent"`                 // 是否使用ssh-agent认证
+	TestCount        int    `json:"testCount"`                   // 测试次数，默认3次
 }
 
 type CreateInviteCodeRequest struct {
@@ -284,12 +391,69 @@ type InstanceListRequest struct {
 	Status       string `json:"status" form:"status"`
 	InstanceType string `json:"instance_type" form:"instance_type"`
 	UserID       uint   `json:"userId" form:"userId"`
+
+	// 故障排查筛选
+	ProviderHealth  string `json:"providerHealth" form:"providerHealth"`   // Provider健康状态：online, offline, partial, unknown，多个以逗号分隔
+	LastSyncBefore  string `json:"lastSyncBefore" form:"lastSyncBefore"`   // 流量最后同步时间早于该时间（RFC3339或2006-01-02 15:04:05），仅包含已启用流量监控的实例
+	HasTrafficError *bool  `json:"hasTrafficError" form:"hasTrafficError"` // 是否存在未恢复的流量采集错误
 }
 
 type InstanceActionRequest struct {
 	Action string `json:"action" binding:"required"`
 }
 
+// ResizeInstanceDiskRequest 管理员调整实例磁盘大小请求
+type ResizeInstanceDiskRequest struct {
+	DiskSizeGB int `json:"diskSizeGB" binding:"required,min=1,max=10240"` // 新磁盘大小（GB）
+}
+
+// SetInstanceBandwidthRequest 管理员调整实例带宽限速请求
+type SetInstanceBandwidthRequest struct {
+	IngressMbps int `json:"ingressMbps" binding:"min=0"` // 入站（实例下载）带宽（Mbps），0表示不限速
+	EgressMbps  int `json:"egressMbps" binding:"min=0"`  // 出站（实例上传）带宽（Mbps），0表示不限速
+}
+
+// CreateProviderNetworkRequest 在Provider节点上创建网络请求
+type CreateProviderNetworkRequest struct {
+	Name     string `json:"name" binding:"required"`
+	Subnet   string `json:"subnet"`   // IPv4子网（CIDR），为空时自动分配
+	Gateway  string `json:"gateway"`  // 网关地址，为空时自动选择
+	Internal bool   `json:"internal"` // 是否为内部网络，内部网络中的实例不能访问外网
+	// 创建后是否加入节点允许用户使用的网络列表
+	AllowUsers bool `json:"allowUsers"`
+}
+
+// CreateProviderProfileRequest 在LXD/Incus节点上创建profile请求
+type CreateProviderProfileRequest struct {
+	Name        string            `json:"name" binding:"required"`
+	Description string            `json:"description"`
+	CPU         string            `json:"cpu"`                                 // 核心数或核心范围
+	MemoryMB    int               `json:"memoryMB" binding:"omitempty,min=64"` // 内存限制（MB）
+	DiskGB      int               `json:"diskGB" binding:"omitempty,min=1,max=10240"`
+	StoragePool string            `json:"storagePool"` // 根磁盘所在存储池，为空时使用default profile的存储池
+	Config      map[string]string `json:"config"`      // 其他配置项，只允许 limits.*、boot.*、snapshots.*
+}
+
+// ApplyInstanceProfileRequest 为实例应用profile请求
+type ApplyInstanceProfileRequest struct {
+	Profile string `json:"profile" binding:"required"`
+	// 虚拟机运行中时是否允许自动停止并在应用后重新启动
+	AllowRestart bool `json:"allowRestart"`
+}
+
+// SetMonitorInterfacesRequest 管理员手动指定实例流量监控接口请求
+// Auto为true时清除手动配置并恢复自动检测，此时忽略接口名称
+type SetMonitorInterfacesRequest struct {
+	InterfaceV4 string `json:"interfaceV4"` // IPv4流量监控的宿主机接口
+	InterfaceV6 string `json:"interfaceV6"` // IPv6流量监控的宿主机接口，为空时与IPv4使用同一接口
+	Auto        bool   `json:"auto"`
+}
+
+// SetInstanceMonitoringRequest 管理员开启或关闭实例流量监控请求
+type SetInstanceMonitoringRequest struct {
+	Enabled *bool `json:"enabled" binding:"required"`
+}
+
 // ResetInstancePasswordRequest 管理员重置实例密码请求
 type ResetInstancePasswordRequest struct {
 	// 不需要传递任何参数，由后端自动生成新密码
@@ -430,16 +594,62 @@ type ProviderPortConfigRequest struct {
 	NetworkType      string `json:"networkType" binding:"oneof=nat_ipv4 nat_ipv4_ipv6 dedicated_ipv4 dedicated_ipv4_ipv6 ipv6_only"` // 网络配置类型
 }
 
+// PrewarmProviderImagesRequest Provider镜像预热请求
+type PrewarmProviderImagesRequest struct {
+	Images       []string `json:"images" binding:"required,min=1"` // 系统镜像名称列表
+	InstanceType string   `json:"instanceType"`                    // 实例类型：container、vm，为空时预热Provider支持的所有类型
+}
+
+// ProviderExportBundle Provider配置导出包
+// 敏感字段（密码、SSH私钥、私钥密码短语、Token）未加密时会被清空
+type ProviderExportBundle struct {
+	Version    int                     `json:"version"`        // 导出包格式版本
+	ExportedAt time.Time               `json:"exportedAt"`     // 导出时间
+	Encrypted  bool                    `json:"encrypted"`      // 敏感字段是否已加密
+	Salt       string                  `json:"salt,omitempty"` // 加密密钥派生盐值（base64）
+	Providers  []CreateProviderRequest `json:"providers"`      // Provider定义列表
+}
+
+// ImportProvidersRequest 导入Provider配置请求
+type ImportProvidersRequest struct {
+	Bundle          ProviderExportBundle `json:"bundle"`
+	Passphrase      string               `json:"passphrase"`                                       // 导出包加密口令，导出包已加密时必填
+	OnConflict      string               `json:"onConflict" binding:"omitempty,oneof=skip suffix"` // 名称冲突处理方式：skip(跳过，默认), suffix(追加后缀)
+	RegenerateCerts bool                 `json:"regenerateCerts"`                                  // 是否为LXD/Incus重新生成客户端证书并自动配置
+}
+
+// ImportProxmoxClusterRequest 通过一个Proxmox集群节点批量添加集群成员请求
+// Provider为种子节点的完整配置，全部成员节点沿用其中的配置和SSH凭据，名称为 <name>-<节点名>
+type ImportProxmoxClusterRequest struct {
+	Provider CreateProviderRequest `json:"provider"`
+}
+
 // CreateInstanceTaskRequest 创建实例任务数据结构
 type CreateInstanceTaskRequest struct {
-	ProviderId  uint   `json:"providerId"`
-	ImageId     uint   `json:"imageId"`
-	CPUId       string `json:"cpuId"`
-	MemoryId    string `json:"memoryId"`
-	DiskId      string `json:"diskId"`
-	BandwidthId string `json:"bandwidthId"`
-	Description string `json:"description"`
-	SessionId   string `json:"sessionId"` // 会话ID，用于新的资源预留机制
+	ProviderId    uint              `json:"providerId"`
+	ImageId       uint              `json:"imageId"`
+	CPUId         string            `json:"cpuId"`
+	MemoryId      string            `json:"memoryId"`
+	DiskId        string            `json:"diskId"`
+	BandwidthId   string            `json:"bandwidthId"`
+	Description   string            `json:"description"`
+	SessionId     string            `json:"sessionId"`               // 会话ID，用于新的资源预留机制
+	Tags          map[string]string `json:"tags,omitempty"`          // 实例标签
+	RequestedIPv4 string            `json:"requestedIPv4,omitempty"` // 指定的内网IPv4地址
+	RequestedIPv6 string            `json:"requestedIPv6,omitempty"` // 指定的内网IPv6地址
+	SSHPublicKeys []string          `json:"sshPublicKeys,omitempty"` // root用户SSH公钥
+	TTLHours      int               `json:"ttlHours,omitempty"`      // 实例有效期（小时），0表示使用默认到期时间
+
+	Network           string                                         `json:"network,omitempty"`           // 主网卡使用的网络，为空时使用节点默认网络
+	NetworkInterfaces []providerModel.ProviderNetworkInterfaceConfig `json:"networkInterfaces,omitempty"` // 额外网卡
+
+	GPUDevices []string `json:"gpuDevices,omitempty"` // 直通的宿主机GPU的PCI地址
+	DNSServers []string `json:"dnsServers,omitempty"` // 自定义DNS服务器
+	Hostname   string   `json:"hostname,omitempty"`   // 自定义主机名
+
+	MemorySwapMB *int64 `json:"memorySwapMB,omitempty"` // swap大小（MB），为空时使用节点默认行为
+
+	Volumes []providerModel.ProviderVolumeMount `json:"volumes,omitempty"` // 挂载的宿主机目录
 }
 
 // InstanceOperationTaskRequest 实例操作任务数据结构（启动、停止、重启、重置）
@@ -453,6 +663,64 @@ type DeleteInstanceTaskRequest struct {
 	InstanceId     uint `json:"instanceId"`
 	ProviderId     uint `json:"providerId"`
 	AdminOperation bool `json:"adminOperation,omitempty"` // 是否为管理员操作
+
+	// 到期回收
+	StopBeforeDelete bool `json:"stopBeforeDelete,omitempty"` // 删除前先停止实例
+}
+
+// CloneInstanceTaskRequest 克隆实例任务数据结构
+type CloneInstanceTaskRequest struct {
+	SourceInstanceId uint `json:"sourceInstanceId"`
+	InstanceId       uint `json:"instanceId"` // 预先创建的克隆实例记录ID
+	ProviderId       uint `json:"providerId"`
+}
+
+// CreateTemplateTaskRequest 创建实例模板任务数据结构
+type CreateTemplateTaskRequest struct {
+	TemplateId       uint `json:"templateId"`
+	SourceInstanceId uint `json:"sourceInstanceId"`
+	ProviderId       uint `json:"providerId"`
+}
+
+// TemplateInstanceTaskRequest 从模板创建实例任务数据结构
+type TemplateInstanceTaskRequest struct {
+	TemplateId uint `json:"templateId"`
+	InstanceId uint `json:"instanceId"` // 预先创建的实例记录ID，用户指定的密码保存在该记录中
+	ProviderId uint `json:"providerId"`
+}
+
+// BackupTaskRequest 备份/恢复任务数据结构
+type BackupTaskRequest struct {
+	BackupId   uint `json:"backupId"`
+	InstanceId uint `json:"instanceId"`
+	ProviderId uint `json:"providerId"`
+}
+
+// BackupListRequest 备份列表请求
+type BackupListRequest struct {
+	common.PageInfo
+	InstanceID uint   `form:"instanceId"`
+	Status     string `form:"status"`
+}
+
+// MigrateInstanceRequest 迁移实例到其他节点请求
+type MigrateInstanceRequest struct {
+	TargetProviderID uint `json:"targetProviderId" binding:"required"` // 目标节点ID，需与实例当前节点类型相同
+}
+
+// MigrateInstanceTaskRequest 迁移实例任务数据结构
+type MigrateInstanceTaskRequest struct {
+	InstanceId       uint `json:"instanceId"`
+	SourceProviderId uint `json:"sourceProviderId"`
+	TargetProviderId uint `json:"targetProviderId"`
+}
+
+// BillingReportRequest 流量计费报表请求
+type BillingReportRequest struct {
+	Scope  string `form:"scope" binding:"required,oneof=user provider"` // 统计维度：user/provider
+	Period string `form:"period" binding:"required"`                    // 账期，格式YYYY-MM
+	ID     uint   `form:"id"`                                           // 只统计指定的用户或Provider，0表示全部
+	Format string `form:"format" binding:"omitempty,oneof=json csv"`    // 输出格式，默认json
 }
 
 // ResetPasswordTaskRequest 重置密码任务数据结构
@@ -499,3 +767,43 @@ type CheckPortAvailabilityResponse struct {
 	PortRange        string `json:"portRange"`        // 端口范围描述（如 "10000-10009"）
 	Suggestion       string `json:"suggestion"`       // 建议（如果有冲突，提供替代方案）
 }
+
+// CreateWebhookRequest 创建Webhook请求
+type CreateWebhookRequest struct {
+	Name       string   `json:"name" binding:"required,max=64"`
+	URL        string   `json:"url" binding:"required,url,max=512"`
+	Secret     string   `json:"secret" binding:"omitempty,min=16,max=128"` // 为空时自动生成
+	Events     []string `json:"events"`                                    // 为空表示订阅全部事件
+	Enabled    *bool    `json:"enabled"`
+	MaxRetries *int     `json:"maxRetries" binding:"omitempty,min=0,max=10"`
+}
+
+// UpdateWebhookRequest 更新Webhook请求，未提供的字段保持不变
+type UpdateWebhookRequest struct {
+	Name       *string  `json:"name" binding:"omitempty,max=64"`
+	URL        *string  `json:"url" binding:"omitempty,url,max=512"`
+	Secret     *string  `json:"secret" binding:"omitempty,min=16,max=128"`
+	Events     []string `json:"events"`
+	Enabled    *bool    `json:"enabled"`
+	MaxRetries *int     `json:"maxRetries" binding:"omitempty,min=0,max=10"`
+}
+
+// CreateInstanceTemplateRequest 将实例标记为模板请求
+type CreateInstanceTemplateRequest struct {
+	Name        string `json:"name" binding:"required,max=64"`
+	Description string `json:"description" binding:"max=255"`
+}
+
+// UpdateInstanceTemplateRequest 更新实例模板请求，未提供的字段保持不变
+type UpdateInstanceTemplateRequest struct {
+	Name        *string `json:"name" binding:"omitempty,min=1,max=64"`
+	Description *string `json:"description" binding:"omitempty,max=255"`
+	Enabled     *bool   `json:"enabled"`
+}
+
+// InstanceTemplateListRequest 实例模板列表请求
+type InstanceTemplateListRequest struct {
+	common.PageInfo
+	ProviderID uint   `form:"providerId"`
+	Status     string `form:"status"`
+}
@@ -73,6 +73,11 @@ type InstanceManageResponse struct {
 	HealthStatus   string `json:"healthStatus"`
 	UsedTrafficIn  int64  `json:"usedTrafficIn"`  // 当月入站流量（MB）- 从历史记录查询
 	UsedTrafficOut int64  `json:"usedTrafficOut"` // 当月出站流量（MB）- 从历史记录查询
+
+	// 故障排查信息
+	ProviderHealth  string     `json:"providerHealth"`            // 所在Provider的综合健康状态：online, offline, partial, unknown
+	TrafficLastSync *time.Time `json:"trafficLastSync,omitempty"` // 流量最后同步时间，未启用流量监控时为空
+	TrafficError    string     `json:"trafficError,omitempty"`    // 最近一次未恢复的流量采集错误
 }
 
 type SystemConfigResponse struct {
@@ -103,6 +108,32 @@ type ProviderStatusResponse struct {
 	NodeDiskTotal    int64      `json:"nodeDiskTotal"`
 	ResourceSynced   bool       `json:"resourceSynced"`
 	ResourceSyncedAt *time.Time `json:"resourceSyncedAt"`
+	// SSH命令熔断状态
+	SSHCircuitBreaker SSHCircuitBreakerStatus `json:"sshCircuitBreaker"`
+}
+
+// SSHCircuitBreakerStatus 节点SSH命令熔断器状态
+type SSHCircuitBreakerStatus struct {
+	State               string     `json:"state"`               // closed正常、open熔断中、half_open探测中
+	ConsecutiveTimeouts int        `json:"consecutiveTimeouts"` // 当前连续超时次数
+	OpenedAt            *time.Time `json:"openedAt,omitempty"`  // 熔断开始时间
+	RetryAt             *time.Time `json:"retryAt,omitempty"`   // 冷却结束、放行探测命令的时间
+	TotalTrips          int        `json:"totalTrips"`          // 自服务启动以来的熔断次数
+}
+
+// SSHOperationResponse 正在执行的SSH命令
+type SSHOperationResponse struct {
+	ID           string    `json:"id"`
+	ProviderID   uint      `json:"providerId"`
+	ProviderName string    `json:"providerName"`
+	Address      string    `json:"address"`       // 节点SSH地址
+	Tag          string    `json:"tag,omitempty"` // 命令日志前缀
+	Command      string    `json:"command"`       // 已脱敏并截断的命令
+	StartedAt    time.Time `json:"startedAt"`
+	TimeoutSec   int64     `json:"timeoutSec"`   // 本次执行的超时时间
+	RunningSec   int64     `json:"runningSec"`   // 已执行的时间
+	Cancelled    bool      `json:"cancelled"`    // 已请求取消，等待会话退出
+	CircuitState string    `json:"circuitState"` // 节点SSH熔断器状态
 }
 
 // ConfigurationTaskResponse 配置任务响应
@@ -160,6 +191,13 @@ type ResetInstancePasswordResponse struct {
 	TaskID uint `json:"taskId"` // 异步任务ID
 }
 
+// ResetInstancePasswordSyncResponse 管理员同步重置实例密码响应
+type ResetInstancePasswordSyncResponse struct {
+	InstanceID  uint   `json:"instanceId"`
+	NewPassword string `json:"newPassword"`
+	ResetTime   int64  `json:"resetTime"`
+}
+
 // GetInstancePasswordResponse 获取实例新密码响应
 type GetInstancePasswordResponse struct {
 	NewPassword string `json:"newPassword"`
@@ -184,3 +222,219 @@ type TestSSHConnectionResponse struct {
 	TestCount          int    `json:"testCount"`              // 测试次数
 	ErrorMessage       string `json:"errorMessage,omitempty"` // 错误信息（如果失败）
 }
+
+// InstanceDeletePlanResponse 实例删除计划（预演）响应
+type InstanceDeletePlanResponse struct {
+	InstanceID   uint     `json:"instanceId"`
+	InstanceName string   `json:"instanceName"`
+	ProviderID   uint     `json:"providerId"`
+	ProviderType string   `json:"providerType"`
+	Commands     []string `json:"commands"` // 按执行顺序排列，以#开头的为说明性步骤
+}
+
+// ProviderStoragePoolsResponse Provider存储池列表响应
+type ProviderStoragePoolsResponse struct {
+	ProviderID   uint                           `json:"providerId"`
+	ProviderType string                         `json:"providerType"`
+	DefaultPool  string                         `json:"defaultPool"` // Provider当前配置的存储池，为空表示使用默认存储池
+	Pools        []provider.ProviderStoragePool `json:"pools"`
+}
+
+// ProviderNetworksResponse Provider网络列表响应
+type ProviderNetworksResponse struct {
+	ProviderID      uint                       `json:"providerId"`
+	ProviderType    string                     `json:"providerType"`
+	AllowedNetworks []string                   `json:"allowedNetworks"` // 允许用户创建实例时选择的网络
+	Networks        []provider.ProviderNetwork `json:"networks"`
+}
+
+// ProviderProfilesResponse Provider profile列表响应
+type ProviderProfilesResponse struct {
+	ProviderID   uint                       `json:"providerId"`
+	ProviderType string                     `json:"providerType"`
+	Profiles     []provider.ProviderProfile `json:"profiles"`
+}
+
+// ProviderGPUsResponse Provider宿主机GPU列表响应
+type ProviderGPUsResponse struct {
+	ProviderID            uint                         `json:"providerId"`
+	ProviderType          string                       `json:"providerType"`
+	GPUPassthroughEnabled bool                         `json:"gpuPassthroughEnabled"` // 是否允许用户直通GPU
+	IOMMUEnabled          bool                         `json:"iommuEnabled"`          // 宿主机GPU是否有IOMMU分组
+	GPUs                  []provider.ProviderGPUDevice `json:"gpus"`
+}
+
+// ProviderPrewarmStatusResponse Provider镜像预热状态
+type ProviderPrewarmStatusResponse struct {
+	ProviderID uint                             `json:"providerId"`
+	Running    bool                             `json:"running"`
+	StartedAt  *time.Time                       `json:"startedAt,omitempty"`
+	FinishedAt *time.Time                       `json:"finishedAt,omitempty"`
+	Images     []provider.ProviderPrewarmResult `json:"images"`
+}
+
+// ValidateSSHAuthResponse SSH认证校验响应
+type ValidateSSHAuthResponse struct {
+	Success      bool     `json:"success"`                // 认证是否成功
+	AuthMethod   string   `json:"authMethod,omitempty"`   // 认证成功的方式：privateKey、agent、password
+	TriedMethods []string `json:"triedMethods"`           // 按顺序配置的认证方式
+	Latency      int64    `json:"latency"`                // 握手耗时（毫秒）
+	ErrorMessage string   `json:"errorMessage,omitempty"` // 错误信息（如果失败）
+}
+
+// RotateProviderCredentialsResponse 轮换Provider SSH凭据响应
+type RotateProviderCredentialsResponse struct {
+	ValidatedMethods []string `json:"validatedMethods"` // 已逐一校验通过的认证方式：privateKey、password
+	ProviderReloaded bool     `json:"providerReloaded"` // 是否已使用新凭据重新连接Provider
+}
+
+// RepinHostKeyResponse 重新固定SSH主机密钥响应
+type RepinHostKeyResponse struct {
+	Fingerprint         string `json:"fingerprint"`                   // 新固定的主机密钥指纹
+	PreviousFingerprint string `json:"previousFingerprint,omitempty"` // 之前固定的指纹
+	VerifyEnabled       bool   `json:"verifyEnabled"`                 // 是否已启用主机密钥校验
+}
+
+// ProviderImportResult 单个Provider导入结果
+type ProviderImportResult struct {
+	Name            string `json:"name"`                   // 导出包中的名称
+	ImportedName    string `json:"importedName,omitempty"` // 实际创建的名称
+	ProviderID      uint   `json:"providerId,omitempty"`
+	Status          string `json:"status"`            // created, skipped, failed
+	Message         string `json:"message,omitempty"` // 跳过或失败原因
+	CertRegenerated bool   `json:"certRegenerated"`   // 是否已重新生成客户端证书
+}
+
+// ImportProvidersResponse 导入Provider配置响应
+type ImportProvidersResponse struct {
+	Created int                    `json:"created"`
+	Skipped int                    `json:"skipped"`
+	Failed  int                    `json:"failed"`
+	Results []ProviderImportResult `json:"results"`
+}
+
+// ProxmoxClusterNodeResult 单个Proxmox集群节点的添加结果
+type ProxmoxClusterNodeResult struct {
+	Node       string `json:"node"`                 // 集群中的节点名称
+	Endpoint   string `json:"endpoint"`             // 节点SSH地址
+	Name       string `json:"name,omitempty"`       // 创建的Provider名称
+	ProviderID uint   `json:"providerId,omitempty"` // 创建的Provider ID
+	Status     string `json:"status"`               // created, skipped, failed
+	Message    string `json:"message,omitempty"`    // 跳过或失败原因
+}
+
+// ImportProxmoxClusterResponse 批量添加Proxmox集群节点响应
+type ImportProxmoxClusterResponse struct {
+	ClusterName string                     `json:"clusterName"`
+	Created     int                        `json:"created"`
+	Skipped     int                        `json:"skipped"`
+	Failed      int                        `json:"failed"`
+	Results     []ProxmoxClusterNodeResult `json:"results"`
+}
+
+// InstanceTrafficSyncResponse 实例流量强制同步结果
+type InstanceTrafficSyncResponse struct {
+	InstanceID    uint       `json:"instanceId"`
+	Year          int        `json:"year"`
+	Month         int        `json:"month"`
+	RxBytes       int64      `json:"rxBytes"`            // 当月接收字节数
+	TxBytes       int64      `json:"txBytes"`            // 当月发送字节数
+	TotalBytes    int64      `json:"totalBytes"`         // 当月总字节数
+	ActualUsageMB float64    `json:"actualUsageMB"`      // 按Provider流量统计模式计算后的使用量（MB）
+	LastSync      *time.Time `json:"lastSync,omitempty"` // 最后一次成功写入流量记录的时间
+	DurationMs    int64      `json:"durationMs"`         // 采集耗时（毫秒）
+}
+
+// MonitorInterfacesResponse 实例流量监控接口
+type MonitorInterfacesResponse struct {
+	InstanceID  uint   `json:"instanceId"`
+	Manual      bool   `json:"manual"`      // 是否为管理员手动指定
+	InterfaceV4 string `json:"interfaceV4"` // 实例记录中的IPv4监控接口
+	InterfaceV6 string `json:"interfaceV6"` // 实例记录中的IPv6监控接口
+	// 当前pmacct监控实际使用的接口，未启用监控时为空
+	ActiveInterfaceV4 string `json:"activeInterfaceV4"`
+	ActiveInterfaceV6 string `json:"activeInterfaceV6"`
+}
+
+// CreateWebhookResponse 创建Webhook响应，签名密钥仅在创建时返回
+type CreateWebhookResponse struct {
+	Webhook
+	Secret string `json:"secret"`
+}
+
+// ProviderCapacityItem 单个Provider的容量概览
+type ProviderCapacityItem struct {
+	ProviderID     uint             `json:"providerId"`
+	Name           string           `json:"name"`
+	Type           string           `json:"type"`
+	Status         string           `json:"status"`      // Provider状态：active, inactive, partial
+	Health         string           `json:"health"`      // 综合健康状态：online, partial, offline, unknown
+	APIStatus      string           `json:"apiStatus"`   // 最近一次API健康检查结果
+	SSHStatus      string           `json:"sshStatus"`   // 最近一次SSH健康检查结果
+	LastCheckAt    *time.Time       `json:"lastCheckAt"` // 最近一次健康检查时间
+	IsFrozen       bool             `json:"isFrozen"`
+	Maintenance    bool             `json:"maintenanceMode"`
+	TrafficLimited bool             `json:"trafficLimited"`
+	CPU            CapacityUsage    `json:"cpu"`            // 核心数，按CPU超分配比例计算可分配量
+	Memory         CapacityUsage    `json:"memory"`         // MB，按内存超分配比例计算可分配量
+	Disk           CapacityUsage    `json:"disk"`           // MB
+	InstanceCounts map[string]int64 `json:"instanceCounts"` // 按实例状态统计
+	InstanceTotal  int64            `json:"instanceTotal"`
+	TrafficRate    TrafficRate      `json:"trafficRate"` // 基于最近pmacct采样计算的聚合速率
+
+	Creations CreationConcurrency `json:"creations"` // 实例创建并发情况
+}
+
+// CreationConcurrency 实例创建并发限制与当前占用
+type CreationConcurrency struct {
+	Limit   int `json:"limit"`   // 配置的创建并发上限，0表示不单独限制
+	Active  int `json:"active"`  // 正在执行的创建任务数
+	Waiting int `json:"waiting"` // 排队等待名额的创建任务数
+}
+
+// CapacityUsage 资源总量与已用量
+// Total和Percent基于节点物理总量，Percent超过100表示已超分配；
+// Allocatable和Available基于超分配比例，决定还能创建多少实例
+type CapacityUsage struct {
+	Total           int64   `json:"total"`           // 节点物理总量
+	Used            int64   `json:"used"`            // 已分配给实例的量
+	Percent         float64 `json:"percent"`         // 已分配量占物理总量的比例，总量未知时为0
+	OvercommitRatio float64 `json:"overcommitRatio"` // 超分配比例，1表示不超分配
+	Allocatable     int64   `json:"allocatable"`     // 按超分配比例计算的可分配总量
+	Available       int64   `json:"available"`       // 剩余可分配量，Allocatable - Used，最小为0
+}
+
+// TrafficRate 聚合流量速率
+type TrafficRate struct {
+	InBps   int64      `json:"inBps"`   // 入站速率（字节/秒）
+	OutBps  int64      `json:"outBps"`  // 出站速率（字节/秒）
+	Window  int        `json:"window"`  // 统计窗口（秒）
+	Samples int        `json:"samples"` // 参与计算的实例数
+	Latest  *time.Time `json:"latest"`  // 最新采样时间
+}
+
+// ProviderCapacityResponse 集群容量概览
+type ProviderCapacityResponse struct {
+	Providers   []ProviderCapacityItem `json:"providers"`
+	GeneratedAt time.Time              `json:"generatedAt"`
+}
+
+// MigrateInstanceResponse 迁移实例任务创建响应
+type MigrateInstanceResponse struct {
+	InstanceID       uint `json:"instanceId"`
+	SourceProviderID uint `json:"sourceProviderId"`
+	TargetProviderID uint `json:"targetProviderId"`
+	TaskID           uint `json:"taskId"`
+}
+
+// InstanceTemplateTaskResponse 实例模板创建响应
+type InstanceTemplateTaskResponse struct {
+	Template provider.InstanceTemplate `json:"template"`
+	TaskID   uint                      `json:"taskId"`
+}
+
+// BackupTaskResponse 备份/恢复任务创建响应
+type BackupTaskResponse struct {
+	Backup provider.Backup `json:"backup"`
+	TaskID uint            `json:"taskId"`
+}
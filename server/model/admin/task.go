@@ -22,6 +22,7 @@ type AdminTaskResponse struct {
 	TaskType         string     `json:"taskType"`
 	Status           string     `json:"status"`
 	Progress         int        `json:"progress"`
+	Priority         int        `json:"priority"` // 任务优先级
 	ErrorMessage     string     `json:"errorMessage"`
 	CancelReason     string     `json:"cancelReason"` // 取消原因
 	CreatedAt        time.Time  `json:"createdAt"`
@@ -66,6 +67,11 @@ type ForceStopTaskRequest struct {
 	Reason string `json:"reason"` // 强制停止原因
 }
 
+// UpdateTaskPriorityRequest 调整任务优先级请求
+type UpdateTaskPriorityRequest struct {
+	Priority int `json:"priority" binding:"min=0,max=100"` // 新的优先级（0-100），数值越大越先执行
+}
+
 // TaskStatsResponse 任务统计响应
 type TaskStatsResponse struct {
 	TotalTasks     int64 `json:"totalTasks"`
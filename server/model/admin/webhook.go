@@ -0,0 +1,29 @@
+package admin
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Webhook 外部Webhook通知配置
+type Webhook struct {
+	ID              uint           `json:"id" gorm:"primarykey"`
+	CreatedAt       time.Time      `json:"createdAt"`
+	UpdatedAt       time.Time      `json:"updatedAt"`
+	DeletedAt       gorm.DeletedAt `json:"-" gorm:"index"`
+	Name            string         `json:"name" gorm:"size:64;not null"` // 名称
+	URL             string         `json:"url" gorm:"size:512;not null"` // 接收地址
+	Secret          string         `json:"-" gorm:"size:128;not null"`   // HMAC签名密钥
+	Events          string         `json:"events" gorm:"size:255"`       // 订阅的事件，逗号分隔，为空表示全部事件
+	Enabled         bool           `json:"enabled" gorm:"default:true"`  // 是否启用
+	MaxRetries      int            `json:"maxRetries" gorm:"default:3"`  // 非2xx响应时的最大重试次数
+	LastStatusCode  int            `json:"lastStatusCode"`               // 最近一次投递的HTTP状态码
+	LastError       string         `json:"lastError" gorm:"size:512"`    // 最近一次投递失败的原因
+	LastTriggeredAt *time.Time     `json:"lastTriggeredAt"`              // 最近一次投递时间
+}
+
+// TableName 指定表名
+func (Webhook) TableName() string {
+	return "webhooks"
+}
@@ -54,6 +54,7 @@ const (
 	CodeCacheError       = 5003
 	CodeExternalAPIError = 5004
 	CodeRequestTooLarge  = 5005
+	CodeTooManyRequests  = 5006
 )
 
 // 错误信息映射
@@ -92,6 +93,7 @@ var ErrorMessages = map[int]string{
 	CodeCacheError:              "缓存错误",
 	CodeExternalAPIError:        "外部API调用失败",
 	CodeRequestTooLarge:         "请求数据过大",
+	CodeTooManyRequests:         "请求过于频繁",
 }
 
 // AppError 统一错误结构
@@ -188,6 +190,8 @@ func getHTTPCode(code int) int {
 		return http.StatusConflict
 	case CodeRequestTooLarge:
 		return http.StatusRequestEntityTooLarge
+	case CodeTooManyRequests:
+		return http.StatusTooManyRequests
 	default:
 		return http.StatusInternalServerError
 	}
@@ -51,7 +51,11 @@ type PmacctMonitor struct {
 	NetworkIfaceV4 string    `json:"network_iface_v4" gorm:"size:32"`         // IPv4流量监控的网络接口名称
 	NetworkIfaceV6 string    `json:"network_iface_v6" gorm:"size:32"`         // IPv6流量监控的网络接口名称
 	IsEnabled      bool      `json:"is_enabled" gorm:"default:true"`          // 是否启用监控
-	LastSync       time.Time `json:"last_sync"`                               // 最后同步时间
+	LastSync       time.Time `json:"last_sync" gorm:"index"`                  // 最后同步时间
+
+	// 采集错误，采集成功后清空
+	LastError   string     `json:"last_error" gorm:"size:255"` // 最近一次采集失败的原因
+	LastErrorAt *time.Time `json:"last_error_at" gorm:"index"` // 最近一次采集失败的时间
 
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
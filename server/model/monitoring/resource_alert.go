@@ -0,0 +1,43 @@
+package monitoring
+
+import (
+	"time"
+)
+
+// ResourceAlert 实例资源使用告警记录
+type ResourceAlert struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	UserID       uint      `json:"user_id" gorm:"index:idx_resource_alert_user;not null"`                                        // 用户ID
+	InstanceID   uint      `json:"instance_id" gorm:"index:idx_resource_alert_instance,priority:1;not null"`                     // 实例ID
+	InstanceName string    `json:"instance_name" gorm:"size:128"`                                                                // 告警时的实例名称
+	ProviderID   uint      `json:"provider_id" gorm:"index"`                                                                     // Provider ID
+	AlertType    string    `json:"alert_type" gorm:"size:16;index:idx_resource_alert_instance,priority:2;not null"`              // 告警类型：cpu, disk, traffic_spike
+	Value        float64   `json:"value"`                                                                                        // 触发时的使用率（%），流量突增告警为近期速率（Mbps）
+	Threshold    float64   `json:"threshold"`                                                                                    // 触发阈值（%），流量突增告警为基线速率乘以倍数（Mbps）
+	Message      string    `json:"message" gorm:"size:512"`                                                                      // 告警内容
+	Notified     bool      `json:"notified" gorm:"default:false"`                                                                // 是否已成功发送通知
+	CreatedAt    time.Time `json:"created_at" gorm:"index:idx_resource_alert_instance,priority:3;index:idx_resource_alert_user"` // 告警时间
+}
+
+// TableName 指定表名
+func (ResourceAlert) TableName() string {
+	return "resource_alerts"
+}
+
+// ResourceAlertSetting 用户级资源告警设置，未设置的字段使用系统默认值
+type ResourceAlertSetting struct {
+	ID              uint      `json:"id" gorm:"primaryKey"`
+	UserID          uint      `json:"user_id" gorm:"uniqueIndex;not null"` // 用户ID
+	Enabled         *bool     `json:"enabled"`                             // 是否接收资源告警
+	CPUPercent      *int      `json:"cpu_percent"`                         // CPU使用率阈值（%）
+	CPUMinutes      *int      `json:"cpu_minutes"`                         // CPU持续超过阈值的分钟数
+	DiskPercent     *int      `json:"disk_percent"`                        // 磁盘使用率阈值（%）
+	CooldownMinutes *int      `json:"cooldown_minutes"`                    // 同一实例同类告警的最小间隔（分钟）
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (ResourceAlertSetting) TableName() string {
+	return "resource_alert_settings"
+}
@@ -0,0 +1,26 @@
+package provider
+
+import "time"
+
+// InstanceSchedule 实例定时开关机计划，cron表达式按Timezone指定的时区解析
+type InstanceSchedule struct {
+	ID        uint      `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	InstanceID uint       `json:"instanceId" gorm:"uniqueIndex;not null"` // 关联的实例ID，每个实例只有一个计划
+	UserID     uint       `json:"userId" gorm:"index;not null"`           // 设置计划的用户ID，定时操作以该用户身份执行
+	StartCron  string     `json:"startCron" gorm:"size:64"`               // 开机cron表达式，为空表示不定时开机
+	StopCron   string     `json:"stopCron" gorm:"size:64"`                // 关机cron表达式，为空表示不定时关机
+	Timezone   string     `json:"timezone" gorm:"size:64;not null"`       // IANA时区名，如 Asia/Shanghai
+	Enabled    bool       `json:"enabled" gorm:"default:true"`            // 是否启用
+	LastAction string     `json:"lastAction" gorm:"size:16"`              // 最近一次定时操作：start, stop
+	LastStatus string     `json:"lastStatus" gorm:"size:16"`              // 最近一次定时操作结果：submitted, skipped, failed
+	LastError  string     `json:"lastError" gorm:"size:255"`              // 最近一次定时操作跳过或失败的原因
+	LastRunAt  *time.Time `json:"lastRunAt"`                              // 最近一次定时操作的触发时间
+}
+
+// TableName 指定表名
+func (InstanceSchedule) TableName() string {
+	return "instance_schedules"
+}
@@ -0,0 +1,45 @@
+package provider
+
+import "time"
+
+// 实例共享角色
+const (
+	ShareRoleViewer   = "viewer"   // 只读：查看实例详情，不含登录密码
+	ShareRoleOperator = "operator" // 操作：在只读基础上可开机、关机、重启
+)
+
+// InstanceShare 实例共享授权，实例所有者将实例以指定角色授权给其他用户
+// 删除、重置等破坏性操作始终只允许所有者执行
+type InstanceShare struct {
+	ID        uint      `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	InstanceID   uint       `json:"instanceId" gorm:"uniqueIndex:idx_instance_share_user;not null"`         // 共享的实例ID
+	OwnerID      uint       `json:"ownerId" gorm:"index;not null"`                                          // 授权时的实例所有者ID
+	SharedUserID uint       `json:"sharedUserId" gorm:"uniqueIndex:idx_instance_share_user;index;not null"` // 被授权的用户ID
+	Role         string     `json:"role" gorm:"size:16;not null"`                                           // 共享角色：viewer, operator
+	ExpiresAt    *time.Time `json:"expiresAt"`                                                              // 授权过期时间，为空表示长期有效
+}
+
+// TableName 指定表名
+func (InstanceShare) TableName() string {
+	return "instance_shares"
+}
+
+// IsValidShareRole 检查共享角色是否有效
+func IsValidShareRole(role string) bool {
+	return role == ShareRoleViewer || role == ShareRoleOperator
+}
+
+// ShareRoleAllows 检查已授予的角色是否满足所需角色，operator包含viewer的权限
+func ShareRoleAllows(granted, required string) bool {
+	switch required {
+	case ShareRoleViewer:
+		return granted == ShareRoleViewer || granted == ShareRoleOperator
+	case ShareRoleOperator:
+		return granted == ShareRoleOperator
+	default:
+		return false
+	}
+}
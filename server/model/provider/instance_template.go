@@ -0,0 +1,43 @@
+package provider
+
+import "time"
+
+// 实例模板状态
+const (
+	TemplateStatusCreating = "creating" // 正在从源实例复制
+	TemplateStatusReady    = "ready"    // 可用于创建实例
+	TemplateStatusFailed   = "failed"   // 复制失败
+)
+
+// InstanceTemplate 实例模板（黄金镜像）
+// 管理员将现有实例标记为模板时，在同一节点上复制出一份停止状态的实例作为模板副本，
+// 之后源实例的变化不会影响模板；用户从模板创建实例时克隆该副本
+type InstanceTemplate struct {
+	ID        uint      `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	Name         string `json:"name" gorm:"uniqueIndex;not null;size:64"` // 模板名称
+	Description  string `json:"description" gorm:"size:255"`              // 模板描述
+	ProviderID   uint   `json:"providerId" gorm:"index;not null"`         // 模板副本所在节点，从模板创建的实例落在同一节点
+	InstanceName string `json:"instanceName" gorm:"size:128;not null"`    // 节点上模板副本的实例名称
+
+	// 标记为模板时源实例的信息
+	SourceInstanceID uint   `json:"sourceInstanceId" gorm:"index"` // 源实例ID
+	InstanceType     string `json:"instanceType" gorm:"size:16"`   // 实例类型：container, vm
+	Image            string `json:"image" gorm:"size:128"`         // 源实例使用的镜像
+	OSType           string `json:"osType" gorm:"size:64"`         // 操作系统类型
+	CPU              int    `json:"cpu"`                           // 源实例CPU核心数，作为默认规格
+	Memory           int64  `json:"memory"`                        // 源实例内存（MB），作为默认规格
+	Disk             int64  `json:"disk"`                          // 模板磁盘大小（MB），从模板创建的实例磁盘不能小于该值
+	Bandwidth        int    `json:"bandwidth"`                     // 源实例带宽（Mbps），作为默认规格
+
+	Status       string `json:"status" gorm:"size:16;default:creating"` // 状态：creating, ready, failed
+	ErrorMessage string `json:"errorMessage" gorm:"type:text"`          // 复制失败原因
+	Enabled      bool   `json:"enabled" gorm:"default:true"`            // 是否对用户开放
+}
+
+// TableName 指定表名
+func (InstanceTemplate) TableName() string {
+	return "instance_templates"
+}
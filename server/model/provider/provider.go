@@ -83,16 +83,22 @@ type Provider struct {
 	UpdatedAt time.Time `json:"updatedAt"`                                // 更新时间
 
 	// 基本信息
-	Name     string `json:"name" gorm:"uniqueIndex;not null;size:64"` // Provider名称（唯一）
-	Type     string `json:"type" gorm:"not null;size:32"`             // Provider类型：docker, lxd, incus, proxmox
-	Endpoint string `json:"endpoint" gorm:"size:255"`                 // SSH连接端点地址
-	PortIP   string `json:"portIP" gorm:"size:255"`                   // 端口映射使用的公网IP（非必填，若为空则使用Endpoint）
-	SSHPort  int    `json:"sshPort" gorm:"default:22"`                // SSH连接端口
-	Username string `json:"username" gorm:"size:128"`                 // SSH连接用户名
-	Password string `json:"-" gorm:"size:255"`                        // SSH连接密码（不返回给前端）
-	SSHKey   string `json:"-" gorm:"type:text"`                       // SSH私钥（不返回给前端，优先于密码使用）
-	Token    string `json:"-" gorm:"size:255"`                        // API访问令牌（不返回给前端）
-	Config   string `json:"config" gorm:"type:text"`                  // 额外配置信息（JSON格式）
+	Name             string `json:"name" gorm:"uniqueIndex;not null;size:64"` // Provider名称（唯一）
+	Type             string `json:"type" gorm:"not null;size:32"`             // Provider类型：docker, lxd, incus, proxmox
+	Endpoint         string `json:"endpoint" gorm:"size:255"`                 // SSH连接端点地址
+	PortIP           string `json:"portIP" gorm:"size:255"`                   // 端口映射使用的公网IP（非必填，若为空则使用Endpoint）
+	SSHPort          int    `json:"sshPort" gorm:"default:22"`                // SSH连接端口
+	Username         string `json:"username" gorm:"size:128"`                 // SSH连接用户名
+	Password         string `json:"-" gorm:"size:255"`                        // SSH连接密码（不返回给前端）
+	SSHKey           string `json:"-" gorm:"type:text"`                       // SSH私钥（不返回给前端，优先于密码使用）
+	SSHKeyPassphrase string `json:"-" gorm:"size:255"`                        // 加密SSH私钥的密码短语（不返回给前端）
+	SSHUseAgent      bool   `json:"sshUseAgent" gorm:"default:false"`         // 是否使用ssh-agent认证（私钥之后、密码之前尝试）
+	Token            string `json:"-" gorm:"size:255"`                        // API访问令牌（不返回给前端）
+	Config           string `json:"config" gorm:"type:text"`                  // 额外配置信息（JSON格式）
+
+	// SSH主机密钥校验（TOFU：启用后首次连接固定指纹，之后指纹变化时拒绝连接）
+	SSHVerifyHostKey      bool   `json:"sshVerifyHostKey" gorm:"default:false"` // 是否校验SSH主机密钥，默认不校验
+	SSHHostKeyFingerprint string `json:"sshHostKeyFingerprint" gorm:"size:128"` // 已固定的主机密钥指纹（SHA256格式）
 
 	// 状态和地理信息
 	Status      string `json:"status" gorm:"default:active;size:16"` // Provider状态：active, inactive
@@ -118,8 +124,12 @@ type Provider struct {
 	ExpiresAt    *time.Time `json:"expiresAt" gorm:"index;column:expires_at"`  // Provider过期时间
 	IsFrozen     bool       `json:"isFrozen" gorm:"default:false"`             // 是否被冻结（冻结后无法使用）
 
+	// 维护模式：不再接受新实例，已有实例的操作不受影响
+	MaintenanceMode   bool   `json:"maintenanceMode" gorm:"default:false"`
+	MaintenanceReason string `json:"maintenanceReason" gorm:"size:255"` // 维护原因，展示给管理员
+
 	// 存储配置（ProxmoxVE专用）
-	StoragePool string `json:"storagePool" gorm:"size:64;default:local"` // 存储池名称，用于存储虚拟机磁盘和容器
+	StoragePool string `json:"storagePool" gorm:"size:64;default:local"` // 存储池名称，用于存储虚拟机磁盘和容器（ProxmoxVE、LXD、Incus）
 
 	// 证书相关字段（用于TLS连接）
 	CertPath        string     `json:"certPath" gorm:"size:512"`                 // 客户端证书文件路径
@@ -146,10 +156,20 @@ type Provider struct {
 	NodeMemoryTotal int64 `json:"nodeMemoryTotal" gorm:"default:0"` // 节点总内存大小（MB）
 	NodeDiskTotal   int64 `json:"nodeDiskTotal" gorm:"default:0"`   // 节点总磁盘空间（MB）
 
+	// 资源超分配比例：可分配量 = 节点总量 × 比例，默认1.0表示不超分配
+	CPUOvercommitRatio    float64 `json:"cpuOvercommitRatio" gorm:"default:1"`    // CPU超分配比例，如4.0表示4:1
+	MemoryOvercommitRatio float64 `json:"memoryOvercommitRatio" gorm:"default:1"` // 内存超分配比例，如1.2表示1.2:1
+
 	// 并发控制配置
 	AllowConcurrentTasks bool `json:"allowConcurrentTasks" gorm:"default:false"` // 是否允许并发执行任务
 	MaxConcurrentTasks   int  `json:"maxConcurrentTasks" gorm:"default:1"`       // 最大并发任务数量
 
+	// 实例创建并发限制：创建任务在工作池之外再排队，避免大量创建同时压到节点上
+	MaxConcurrentCreations int `json:"maxConcurrentCreations" gorm:"default:0"` // 同时执行的实例创建任务上限，0表示不单独限制
+
+	// GPU直通（仅Proxmox/Incus虚拟机），宿主机需开启IOMMU
+	GPUPassthroughEnabled bool `json:"gpuPassthroughEnabled" gorm:"default:false"` // 是否允许用户创建实例时直通宿主机GPU
+
 	// SSH连接配置
 	SSHConnectTimeout int `json:"sshConnectTimeout" gorm:"default:30"`  // SSH连接超时时间（秒），默认30秒
 	SSHExecuteTimeout int `json:"sshExecuteTimeout" gorm:"default:300"` // SSH命令执行超时时间（秒），默认300秒
@@ -222,6 +242,7 @@ type Provider struct {
 	// 可用资源统计（动态计算得出）
 	AvailableCPUCores int   `json:"availableCpuCores" gorm:"default:0"` // 可用的CPU核心数（NodeCPUCores - UsedCPUCores）
 	AvailableMemory   int64 `json:"availableMemory" gorm:"default:0"`   // 可用的内存大小（NodeMemoryTotal - UsedMemory）
+	AvailableDisk     int64 `json:"availableDisk" gorm:"default:0"`     // 可用的磁盘空间（NodeDiskTotal - UsedDisk）
 	UsedInstances     int   `json:"usedInstances" gorm:"default:0"`     // 已使用的实例总数（ContainerCount + VMCount）
 
 	// 节点级别的等级限制配置（JSON格式存储）
@@ -232,14 +253,41 @@ type Provider struct {
 	// 节点标识信息（用于区分多个hostname相同的节点）
 	HostName string `json:"hostName" gorm:"size:128"` // 节点主机名（hostname），由健康检查自动更新
 
+	// 允许用户为实例附加额外网卡的宿主机网络（逗号分隔的Docker网络、LXD/Incus网络或Proxmox网桥），为空表示不允许
+	// Docker/Podman节点上这些网络同样可以作为实例的主网络
+	ExtraNetworks string `json:"extraNetworks" gorm:"size:512"`
+
+	// 允许用户挂载到实例内的宿主机目录（逗号分隔的绝对路径，含其子目录），为空表示不允许挂载
+	VolumeAllowlist string `json:"volumeAllowlist" gorm:"size:1024"`
+
+	// Docker/Podman从镜像仓库拉取镜像时使用的凭据，仓库地址为空表示Docker Hub，用户名为空时匿名拉取
+	RegistryServer   string `json:"registryServer" gorm:"size:255"`
+	RegistryUsername string `json:"registryUsername" gorm:"size:128"`
+	RegistryPassword string `json:"-" gorm:"size:512"` // 仓库密码或访问令牌（不返回给前端）
+
+	// 删除Docker/Podman实例的其他策略都失败时，是否允许执行 system prune -f --volumes 兜底清理
+	// prune会清理宿主机上所有未使用的容器、网络和卷，共享宿主机上应保持关闭
+	AllowSystemPrune bool `json:"allowSystemPrune" gorm:"default:false"`
+
+	// 自动选择节点时的权重（1-100），权重越大被选中的概率越高，0按1处理
+	PlacementWeight int `json:"placementWeight" gorm:"default:1"`
+
+	// 实例默认资源限制：创建实例未指定CPU、内存或磁盘时使用，0表示未设置
+	DefaultCPU    int   `json:"defaultCpu" gorm:"default:0"`    // 默认CPU核心数
+	DefaultMemory int64 `json:"defaultMemory" gorm:"default:0"` // 默认内存大小（MB）
+	DefaultDisk   int64 `json:"defaultDisk" gorm:"default:0"`   // 默认磁盘大小（MB）
+
 	// 容器特殊配置选项（仅适用于 LXD 和 Incus 的容器实例）
 	ContainerPrivileged   bool   `json:"containerPrivileged" gorm:"default:false"`          // 容器特权模式：允许容器访问宿主机资源
 	ContainerAllowNesting bool   `json:"containerAllowNesting" gorm:"default:false"`        // 容器嵌套：允许在容器内运行容器
 	ContainerEnableLXCFS  bool   `json:"containerEnableLxcfs" gorm:"default:true"`          // LXCFS资源视图：显示真实资源限制
+	ContainerLXCFSMounts  string `json:"containerLxcfsMounts" gorm:"size:1024"`             // LXCFS挂载文件列表（逗号分隔，仅Docker），为空使用默认六项
 	ContainerCPUAllowance string `json:"containerCpuAllowance" gorm:"default:100%;size:16"` // CPU限制：例如 "100%" 或 "50%"
-	ContainerMemorySwap   bool   `json:"containerMemorySwap" gorm:"default:true"`           // 内存交换：允许使用swap空间
-	ContainerMaxProcesses int    `json:"containerMaxProcesses" gorm:"default:0"`            // 最大进程数：0表示不限制
-	ContainerDiskIOLimit  string `json:"containerDiskIoLimit" gorm:"size:32"`               // 磁盘IO限制：例如 "10MB" 或 "100iops"
+	// 未指定CPU限制（或为100%）时按实例核心数生成 limits.cpu.allowance 的方式：percent(N*100%)、time(N*100ms/100ms)、none(不设置)
+	ContainerCPUAllowanceMode string `json:"containerCpuAllowanceMode" gorm:"size:16;default:percent"`
+	ContainerMemorySwap       bool   `json:"containerMemorySwap" gorm:"default:true"` // 内存交换：允许使用swap空间
+	ContainerMaxProcesses     int    `json:"containerMaxProcesses" gorm:"default:0"`  // 最大进程数：0表示不限制
+	ContainerDiskIOLimit      string `json:"containerDiskIoLimit" gorm:"size:32"`     // 磁盘IO限制：例如 "10MB" 或 "100iops"
 }
 
 func (p *Provider) BeforeCreate(tx *gorm.DB) error {
@@ -298,6 +346,57 @@ func (p *Provider) GetAuthMethod() string {
 	return "password"
 }
 
+// InstanceStoragePool 返回创建实例时使用的存储池
+// 字段默认值local是ProxmoxVE的默认存储名，LXD/Incus未显式配置时返回空以使用其默认存储池
+func (p *Provider) InstanceStoragePool() string {
+	if (p.Type == "lxd" || p.Type == "incus") && p.StoragePool == "local" {
+		return ""
+	}
+	return p.StoragePool
+}
+
+// AllocatableCPUCores 按CPU超分配比例计算可分配的核心数，比例未设置或小于1时按1:1计算
+func (p *Provider) AllocatableCPUCores() int {
+	return int(float64(p.NodeCPUCores) * effectiveOvercommitRatio(p.CPUOvercommitRatio))
+}
+
+// AllocatableMemory 按内存超分配比例计算可分配的内存（MB），比例未设置或小于1时按1:1计算
+func (p *Provider) AllocatableMemory() int64 {
+	return int64(float64(p.NodeMemoryTotal) * effectiveOvercommitRatio(p.MemoryOvercommitRatio))
+}
+
+func effectiveOvercommitRatio(ratio float64) float64 {
+	if ratio < 1 {
+		return 1
+	}
+	return ratio
+}
+
+// Provider综合健康状态
+const (
+	ProviderHealthOnline  = "online"  // API或SSH可用，且没有检查失败
+	ProviderHealthOffline = "offline" // API或SSH检查失败，且没有可用连接
+	ProviderHealthPartial = "partial" // API与SSH一个可用、一个失败
+	ProviderHealthUnknown = "unknown" // 尚未完成健康检查
+)
+
+// Health 根据API与SSH健康检查结果得出综合健康状态
+// 仅使用SSH的Provider其API状态为unknown，不影响判断
+func (p *Provider) Health() string {
+	online := p.APIStatus == "online" || p.SSHStatus == "online"
+	offline := p.APIStatus == "offline" || p.SSHStatus == "offline"
+	switch {
+	case online && offline:
+		return ProviderHealthPartial
+	case online:
+		return ProviderHealthOnline
+	case offline:
+		return ProviderHealthOffline
+	default:
+		return ProviderHealthUnknown
+	}
+}
+
 // Instance 实例模型
 type Instance struct {
 	// 基础字段
@@ -322,14 +421,20 @@ type Instance struct {
 	Bandwidth int   `json:"bandwidth" gorm:"default:10"` // 网络带宽（Mbps）
 
 	// 网络配置
-	Network        string `json:"network" gorm:"size:64"`      // 网络名称或配置
-	PrivateIP      string `json:"privateIP" gorm:"size:64"`    // 内网/私有IPv4地址
-	PublicIP       string `json:"publicIP" gorm:"size:64"`     // 公网IPv4地址
-	IPv6Address    string `json:"ipv6Address" gorm:"size:128"` // 内网IPv6地址
-	PublicIPv6     string `json:"publicIPv6" gorm:"size:128"`  // 公网IPv6地址
-	SSHPort        int    `json:"sshPort" gorm:"default:22"`   // SSH访问端口
-	PortRangeStart int    `json:"portRangeStart"`              // 端口映射范围起始
-	PortRangeEnd   int    `json:"portRangeEnd"`                // 端口映射范围结束
+	Network        string `json:"network" gorm:"size:64"`       // 网络名称或配置
+	PrivateIP      string `json:"privateIP" gorm:"size:64"`     // 内网/私有IPv4地址
+	PublicIP       string `json:"publicIP" gorm:"size:64"`      // 公网IPv4地址
+	IPv6Address    string `json:"ipv6Address" gorm:"size:128"`  // 内网IPv6地址
+	PublicIPv6     string `json:"publicIPv6" gorm:"size:128"`   // 公网IPv6地址
+	ReservedIPv4   string `json:"reservedIPv4" gorm:"size:64"`  // 创建时指定的内网IPv4地址
+	ReservedIPv6   string `json:"reservedIPv6" gorm:"size:128"` // 创建时指定的内网IPv6地址
+	SSHPort        int    `json:"sshPort" gorm:"default:22"`    // SSH访问端口
+	PortRangeStart int    `json:"portRangeStart"`               // 端口映射范围起始
+	PortRangeEnd   int    `json:"portRangeEnd"`                 // 端口映射范围结束
+
+	// 网络信息缓存，列表和详情优先使用，避免每次通过SSH查询
+	VethInterface   string     `json:"vethInterface" gorm:"size:64"` // 宿主机侧veth接口
+	NetworkSyncedAt *time.Time `json:"networkSyncedAt"`              // 内网IP/IPv6/veth最近一次从Provider同步的时间
 
 	// 访问凭据
 	Username string `json:"username" gorm:"size:64"`  // 登录用户名
@@ -339,6 +444,9 @@ type Instance struct {
 	OSType string `json:"osType" gorm:"size:64"` // 操作系统类型：ubuntu, centos, debian等
 	Region string `json:"region" gorm:"size:64"` // 所在地区
 
+	// 标签（数据库为筛选依据，支持原生标签的Provider会同步到实例上）
+	Tags map[string]string `json:"tags" gorm:"type:text;serializer:json"`
+
 	// 流量统计（实例层面）
 	MaxTraffic         int64  `json:"maxTraffic" gorm:"default:0"`                  // 实例流量限制（MB），0表示不限制，从用户等级继承
 	TrafficLimited     bool   `json:"trafficLimited" gorm:"default:false"`          // 是否因流量超限被停机
@@ -346,9 +454,49 @@ type Instance struct {
 	PmacctInterfaceV4  string `json:"pmacctInterfaceV4" gorm:"size:32"`             // pmacct 监控的IPv4网络接口名称
 	PmacctInterfaceV6  string `json:"pmacctInterfaceV6" gorm:"size:32"`             // pmacct 监控的IPv6网络接口名称
 
+	// 管理员手动指定监控接口后不再自动检测和覆盖上面的接口名称
+	PmacctInterfaceManual bool `json:"pmacctInterfaceManual" gorm:"default:false"`
+
+	// 是否对该实例进行流量监控，关闭后不初始化pmacct监控也不采集流量，仅在Provider启用流量统计时生效
+	EnableMonitoring bool `json:"enableMonitoring" gorm:"default:true"`
+
 	// 生命周期
 	ExpiredAt time.Time `json:"expiredAt" gorm:"column:expired_at"` // 实例到期时间
 
+	// 到期提醒
+	ExpireNotifiedAt *time.Time `json:"expireNotifiedAt"` // 最近一次发送到期提醒的时间，续期后清空
+
+	// 延迟删除：用户删除后在等待期内可撤销，到期后由系统删除
+	DeleteScheduledAt *time.Time `json:"deleteScheduledAt" gorm:"index"` // 计划删除时间，为空表示未计划删除
+
+	// 实例级带宽限速（Mbps），0表示该方向未单独限速，实例启动/重启后重新应用
+	IngressMbps int `json:"ingressMbps" gorm:"default:0"` // 入站（实例下载）带宽
+	EgressMbps  int `json:"egressMbps" gorm:"default:0"`  // 出站（实例上传）带宽
+
+	// 创建时附加的额外网卡，重置系统时按相同配置重建
+	NetworkInterfaces []ProviderNetworkInterfaceConfig `json:"networkInterfaces" gorm:"type:text;serializer:json"`
+
+	// 直通的宿主机GPU的PCI地址，重置系统时按相同配置重建
+	GPUDevices []string `json:"gpuDevices" gorm:"type:text;serializer:json"`
+
+	// 自定义DNS服务器，为空时使用Provider默认DNS，重置系统时按相同配置重建
+	DNSServers []string `json:"dnsServers" gorm:"type:text;serializer:json"`
+
+	// 自定义主机名或FQDN，为空时使用实例名称，重置系统时按相同配置重建
+	Hostname string `json:"hostname" gorm:"size:253"`
+
+	// swap大小（MB），为空时使用Provider默认行为，0表示禁用swap，重置系统时按相同配置重建
+	MemorySwapMB *int64 `json:"memorySwapMB"`
+
+	// 挂载的宿主机目录，重置系统时按相同配置重新挂载
+	Volumes []ProviderVolumeMount `json:"volumes" gorm:"type:text;serializer:json"`
+
+	// 创建来源模板ID，为空表示不是从模板创建
+	TemplateID *uint `json:"templateId" gorm:"index"`
+
+	// 创建时下发给Provider的实例配置，重建实例时按原配置重新应用，为空表示无法重建
+	CreateConfig *ProviderInstanceConfig `json:"-" gorm:"type:text;serializer:json"`
+
 	// 关联关系
 	UserID uint `json:"userId" gorm:"index:idx_user_status,priority:1"` // 所属用户ID
 }
@@ -387,6 +535,50 @@ type Port struct {
 	MappingMethod string `json:"mappingMethod" gorm:"size:32;default:native"` // 映射方法：native, iptables, firewall
 }
 
+// InstanceFirewallRule 实例防火墙规则，实例启动时重新应用，删除实例时清理
+type InstanceFirewallRule struct {
+	ID        uint      `json:"id" gorm:"primarykey"` // 规则主键ID
+	CreatedAt time.Time `json:"createdAt"`            // 创建时间
+	UpdatedAt time.Time `json:"updatedAt"`            // 更新时间
+
+	InstanceID  uint   `json:"instanceId" gorm:"index;not null"` // 关联的实例ID
+	Protocol    string `json:"protocol" gorm:"size:8;not null"`  // 协议：tcp, udp
+	Port        int    `json:"port" gorm:"not null"`             // 实例内部端口（起始端口）
+	EndPort     int    `json:"endPort" gorm:"default:0"`         // 端口范围结束（0表示单端口）
+	Source      string `json:"source" gorm:"size:64"`            // 来源地址或CIDR，为空表示任意来源
+	Action      string `json:"action" gorm:"size:8;not null"`    // 动作：allow, deny
+	Description string `json:"description" gorm:"size:128"`      // 规则描述
+}
+
+// ToProviderRule 转换为下发到Provider的规则
+func (r InstanceFirewallRule) ToProviderRule() ProviderFirewallRule {
+	return ProviderFirewallRule{
+		Protocol: r.Protocol,
+		Port:     r.Port,
+		EndPort:  r.EndPort,
+		Source:   r.Source,
+		Action:   r.Action,
+	}
+}
+
+// Backup 实例备份记录，备份文件保存在对象存储中，实例删除后备份仍保留到过期
+type Backup struct {
+	ID        uint      `json:"id" gorm:"primarykey"` // 备份主键ID
+	CreatedAt time.Time `json:"createdAt"`            // 创建时间
+	UpdatedAt time.Time `json:"updatedAt"`            // 更新时间
+
+	InstanceID   uint       `json:"instanceId" gorm:"index;not null"`      // 关联的实例ID
+	InstanceName string     `json:"instanceName" gorm:"size:128"`          // 备份时的实例名称
+	ProviderID   uint       `json:"providerId" gorm:"index"`               // 备份时实例所在的Provider
+	Status       string     `json:"status" gorm:"size:16;default:pending"` // 状态：pending, running, completed, failed
+	ObjectKey    string     `json:"objectKey" gorm:"size:512"`             // 对象存储中的键
+	FileName     string     `json:"fileName" gorm:"size:255"`              // Provider导出的归档文件名，恢复时用于识别格式
+	Size         int64      `json:"size" gorm:"default:0"`                 // 备份大小（字节）
+	Checksum     string     `json:"checksum" gorm:"size:64"`               // 备份内容的SHA256
+	ErrorMessage string     `json:"errorMessage" gorm:"type:text"`         // 失败原因
+	CompletedAt  *time.Time `json:"completedAt"`                           // 完成时间
+}
+
 // PendingDeletion 待删除资源模型
 type PendingDeletion struct {
 	ID           uint      `json:"id" gorm:"primarykey"`
@@ -431,19 +623,47 @@ type ProviderImage struct {
 
 // ProviderInstanceConfig 实例配置
 type ProviderInstanceConfig struct {
-	Name         string            `json:"name"`
-	Image        string            `json:"image"`
-	ImageURL     string            `json:"image_url"`  // 镜像下载URL
-	ImagePath    string            `json:"image_path"` // 镜像文件路径
-	UseCDN       bool              `json:"use_cdn"`    // 是否使用CDN加速下载镜像
-	CPU          string            `json:"cpu"`
-	Memory       string            `json:"memory"`
-	Disk         string            `json:"disk"`
-	Network      string            `json:"network"`
-	Ports        []string          `json:"ports"`
-	Env          map[string]string `json:"env"`
-	Metadata     map[string]string `json:"metadata"`
-	InstanceType string            `json:"instance_type"` // container 或 vm
+	Name          string            `json:"name"`
+	Image         string            `json:"image"`
+	ImageURL      string            `json:"image_url"`    // 镜像下载URL
+	ImagePath     string            `json:"image_path"`   // 镜像文件路径
+	UseCDN        bool              `json:"use_cdn"`      // 是否使用CDN加速下载镜像
+	ImageSHA256   string            `json:"image_sha256"` // 镜像文件sha256，非空时下载后校验，不匹配则删除文件并失败
+	CPU           string            `json:"cpu"`
+	Memory        string            `json:"memory"`
+	Disk          string            `json:"disk"`
+	Network       string            `json:"network"`
+	Ports         []string          `json:"ports"`
+	Env           map[string]string `json:"env"`
+	Metadata      map[string]string `json:"metadata"`
+	InstanceType  string            `json:"instance_type"`  // container 或 vm
+	StoragePool   string            `json:"storage_pool"`   // 存储池名称，为空时使用Provider默认存储
+	Labels        map[string]string `json:"labels"`         // 实例标签，Docker写入--label，LXD/Incus写入user.tag.*配置
+	RequestedIPv4 string            `json:"requested_ipv4"` // 指定的内网IPv4地址，为空时由Provider自动分配
+	RequestedIPv6 string            `json:"requested_ipv6"` // 指定的内网IPv6地址，为空时由Provider自动分配
+
+	// cloud-init配置（目前用于Proxmox虚拟机）
+	SSHPublicKeys []string `json:"ssh_public_keys"` // 写入root用户authorized_keys的SSH公钥
+	UserData      string   `json:"user_data"`       // 自定义cloud-init配置，作为vendor-data与生成的用户数据合并
+
+	// 带宽限制（Mbps），大于0时覆盖Provider默认带宽和用户等级带宽
+	IngressMbps int `json:"ingress_mbps"` // 入站（实例下载）带宽
+	EgressMbps  int `json:"egress_mbps"`  // 出站（实例上传）带宽
+
+	// 额外网卡，按顺序附加在主网卡之后
+	NetworkInterfaces []ProviderNetworkInterfaceConfig `json:"network_interfaces"`
+
+	// 直通的宿主机GPU的PCI地址（仅Proxmox/Incus虚拟机）
+	GPUDevices []string `json:"gpu_devices"`
+
+	// 自定义DNS服务器，为空时使用Provider默认DNS
+	DNSServers []string `json:"dns_servers"`
+
+	// 自定义主机名或FQDN，为空时使用实例名称
+	Hostname string `json:"hostname"`
+
+	// 挂载到实例内的宿主机目录
+	Volumes []ProviderVolumeMount `json:"volumes"`
 
 	// 容器特殊配置选项（仅适用于 LXD 和 Incus 的容器实例）
 	Privileged   *bool   `json:"privileged,omitempty"`   // 容器特权模式，使用指针以区分 false 和未设置
@@ -451,10 +671,178 @@ type ProviderInstanceConfig struct {
 	EnableLXCFS  *bool   `json:"enableLxcfs,omitempty"`  // LXCFS资源视图
 	CPUAllowance *string `json:"cpuAllowance,omitempty"` // CPU限制
 	MemorySwap   *bool   `json:"memorySwap,omitempty"`   // 内存交换
+	MemorySwapMB *int64  `json:"memorySwapMB,omitempty"` // swap大小（MB），nil时沿用Provider默认行为，0表示禁用swap
 	MaxProcesses *int    `json:"maxProcesses,omitempty"` // 最大进程数
 	DiskIOLimit  *string `json:"diskIoLimit,omitempty"`  // 磁盘IO限制
 }
 
+// ProviderFirewallRule 下发到Provider的实例防火墙规则，端口为实例内部端口
+type ProviderFirewallRule struct {
+	Protocol string `json:"protocol"`          // tcp, udp
+	Port     int    `json:"port"`              // 起始端口
+	EndPort  int    `json:"endPort,omitempty"` // 端口范围结束，0表示单端口
+	Source   string `json:"source,omitempty"`  // 来源地址或CIDR，为空表示任意来源
+	Action   string `json:"action"`            // allow放行（优先于deny），deny拒绝新建连接
+	Remove   bool   `json:"remove,omitempty"`  // 为true时删除该规则
+}
+
+// ProviderBackupArchive Provider导出的备份归档信息
+type ProviderBackupArchive struct {
+	FileName string `json:"fileName"` // 归档文件名，包含格式对应的扩展名
+	Size     int64  `json:"size"`     // 归档大小（字节）
+}
+
+// ProviderVolumeMount 挂载到实例内的宿主机目录
+type ProviderVolumeMount struct {
+	Source   string `json:"source"`             // 宿主机上的绝对路径，必须位于节点允许挂载的目录下
+	Target   string `json:"target"`             // 实例内的挂载路径
+	ReadOnly bool   `json:"readOnly,omitempty"` // 是否只读挂载
+}
+
+// ProviderNetworkInterfaceConfig 实例额外网卡配置
+type ProviderNetworkInterfaceConfig struct {
+	Network    string `json:"network"`              // 宿主机上的网络名称：Docker网络、LXD/Incus网络或网桥、Proxmox网桥
+	IPAddress  string `json:"ipAddress,omitempty"`  // 静态IP，可带前缀长度，如 192.168.100.10/24，为空时由网络自动分配
+	MACAddress string `json:"macAddress,omitempty"` // MAC地址，为空时自动生成
+}
+
+// ProviderNetwork Provider节点上的网络（目前为Docker/Podman网络）
+type ProviderNetwork struct {
+	Name     string   `json:"name"`
+	ID       string   `json:"id"`
+	Driver   string   `json:"driver"`   // 网络驱动：bridge、macvlan、host等
+	Scope    string   `json:"scope"`    // 作用域：local、swarm等
+	Subnets  []string `json:"subnets"`  // 子网（CIDR）
+	IPv6     bool     `json:"ipv6"`     // 是否启用IPv6
+	Internal bool     `json:"internal"` // 是否为内部网络，内部网络中的实例不能访问外网
+}
+
+// ProviderGPUDevice Provider宿主机上的GPU设备
+type ProviderGPUDevice struct {
+	Address    string `json:"address"`              // PCI地址，如 0000:01:00.0
+	VendorID   string `json:"vendorId"`             // 厂商ID，如 10de
+	DeviceID   string `json:"deviceId"`             // 设备ID
+	Model      string `json:"model,omitempty"`      // 型号描述（宿主机安装了lspci时提供）
+	IOMMUGroup string `json:"iommuGroup,omitempty"` // IOMMU分组，为空表示未开启IOMMU
+	Driver     string `json:"driver,omitempty"`     // 当前绑定的驱动
+	AssignedTo string `json:"assignedTo,omitempty"` // 已直通给的实例，为空表示空闲
+}
+
+// ProviderNetworkCreateConfig 在Provider节点上创建网络的参数
+type ProviderNetworkCreateConfig struct {
+	Name     string `json:"name"`
+	Subnet   string `json:"subnet,omitempty"`   // IPv4子网（CIDR），为空时自动分配
+	Gateway  string `json:"gateway,omitempty"`  // 网关地址，必须位于子网内，为空时自动选择
+	Internal bool   `json:"internal,omitempty"` // 是否创建为内部网络
+}
+
+// ProviderProfile LXD/Incus节点上的profile
+type ProviderProfile struct {
+	Name        string                       `json:"name"`
+	Description string                       `json:"description"`
+	Config      map[string]string            `json:"config"`  // 配置项，如 limits.cpu、limits.memory
+	Devices     map[string]map[string]string `json:"devices"` // 设备，如根磁盘、网卡
+	UsedBy      int                          `json:"usedBy"`  // 使用该profile的实例数量
+}
+
+// ProviderProfileCreateConfig 在LXD/Incus节点上创建profile的参数
+type ProviderProfileCreateConfig struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	CPU         string            `json:"cpu,omitempty"`         // limits.cpu，核心数或核心范围
+	MemoryMB    int               `json:"memoryMB,omitempty"`    // limits.memory（MB）
+	DiskGB      int               `json:"diskGB,omitempty"`      // 根磁盘大小（GB），大于0时在profile中添加root设备
+	StoragePool string            `json:"storagePool,omitempty"` // 根磁盘所在存储池，为空时使用default profile的存储池
+	Config      map[string]string `json:"config,omitempty"`      // 其他配置项，只允许 limits.*、boot.*、snapshots.*
+}
+
+// ProviderStoragePool Provider存储池信息
+type ProviderStoragePool struct {
+	Name        string `json:"name"`
+	Driver      string `json:"driver"`              // 存储驱动：dir、zfs、btrfs、lvm、lvmthin等
+	Status      string `json:"status"`              // 存储状态
+	Description string `json:"description"`         // 描述
+	UsedBy      int    `json:"usedBy"`              // 使用该存储池的对象数量（LXD/Incus）
+	Total       int64  `json:"total,omitempty"`     // 总容量（字节），仅ProxmoxVE提供
+	Used        int64  `json:"used,omitempty"`      // 已用容量（字节），仅ProxmoxVE提供
+	Available   int64  `json:"available,omitempty"` // 可用容量（字节），仅ProxmoxVE提供
+	Content     string `json:"content,omitempty"`   // 存储内容类型，仅ProxmoxVE提供
+}
+
+// ProviderInstanceUsage 实例实时资源使用情况
+type ProviderInstanceUsage struct {
+	CPUPercent  float64 `json:"cpuPercent"`  // CPU使用率，按实例分配的核心数归一化到0-100
+	DiskPercent float64 `json:"diskPercent"` // 根文件系统使用率
+	DiskUsedMB  int64   `json:"diskUsedMB"`  // 根文件系统已用空间（MB）
+	DiskTotalMB int64   `json:"diskTotalMB"` // 根文件系统总空间（MB），为0表示无法获取磁盘使用情况
+}
+
+// ProviderInstanceDiskUsage Provider返回的实例根磁盘使用量
+type ProviderInstanceDiskUsage struct {
+	UsedBytes  int64 `json:"usedBytes"`  // 已用空间（字节）
+	TotalBytes int64 `json:"totalBytes"` // 根磁盘配额或文件系统总量（字节），为0表示无法获取，按实例配置的磁盘大小计算
+}
+
+// InstanceDiskUsage 实例根磁盘使用情况，按实例配置的磁盘大小计算使用率
+type InstanceDiskUsage struct {
+	UsedBytes  int64     `json:"usedBytes"`  // 已用空间（字节）
+	TotalBytes int64     `json:"totalBytes"` // 可用总量（字节），取Provider返回的总量与实例配置磁盘大小中的较小值
+	Percent    float64   `json:"percent"`    // 使用率（%）
+	SampledAt  time.Time `json:"sampledAt"`  // 采样时间，结果会缓存一段时间
+}
+
+// ProviderCapabilities Provider节点实际具备的能力，由连接后的探测结果得出
+type ProviderCapabilities struct {
+	SupportsVM        bool      `json:"supportsVm"`              // 是否可以创建虚拟机（LXD/Incus/Proxmox需要宿主机支持KVM）
+	SupportsContainer bool      `json:"supportsContainer"`       // 是否可以创建容器
+	SupportsIPv6      bool      `json:"supportsIpv6"`            // 是否具备为实例分配IPv6的环境
+	SupportsDiskLimit bool      `json:"supportsDiskLimit"`       // 是否支持限制实例硬盘大小
+	SupportsSnapshots bool      `json:"supportsSnapshots"`       // 是否支持实例快照
+	Architectures     []string  `json:"architectures"`           // 支持的CPU架构，如amd64、arm64
+	StorageDriver     string    `json:"storageDriver,omitempty"` // 探测到的存储驱动
+	ProbedAt          time.Time `json:"probedAt"`                // 探测时间
+}
+
+// SupportsInstanceType 判断能力信息是否支持指定的实例类型
+func (c *ProviderCapabilities) SupportsInstanceType(instanceType string) bool {
+	switch instanceType {
+	case "vm":
+		return c.SupportsVM
+	case "container", "":
+		return c.SupportsContainer
+	}
+	return false
+}
+
+// SupportedInstanceTypes 返回能力信息中支持的实例类型列表
+func (c *ProviderCapabilities) SupportedInstanceTypes() []string {
+	var types []string
+	if c.SupportsContainer {
+		types = append(types, "container")
+	}
+	if c.SupportsVM {
+		types = append(types, "vm")
+	}
+	return types
+}
+
+// ProviderPrewarmImage 镜像预热请求项
+type ProviderPrewarmImage struct {
+	Name         string `json:"name"`         // 系统镜像名称
+	InstanceType string `json:"instanceType"` // 实例类型：container、vm
+	URL          string `json:"url"`          // 镜像下载地址
+	UseCDN       bool   `json:"useCdn"`       // 是否使用CDN加速下载
+	SHA256       string `json:"sha256"`       // 镜像文件sha256，非空时下载后校验
+}
+
+// ProviderPrewarmResult 单个镜像的预热结果
+type ProviderPrewarmResult struct {
+	Name         string `json:"name"`
+	InstanceType string `json:"instanceType"`
+	Status       string `json:"status"`          // running, ready, failed
+	Error        string `json:"error,omitempty"` // 失败原因
+}
+
 // ProviderNodeConfig 节点配置
 type ProviderNodeConfig struct {
 	ID                    uint     `json:"id"` // Provider ID，用于资源清理
@@ -465,9 +853,11 @@ type ProviderNodeConfig struct {
 	Port                  int      `json:"port"`
 	Username              string   `json:"username"`
 	Password              string   `json:"password"`
-	PrivateKey            string   `json:"private_key"` // SSH私钥内容，优先于密码使用
-	Token                 string   `json:"token"`       // API Token Secret，用于ProxmoxVE等
-	TokenID               string   `json:"token_id"`    // API Token ID，用于ProxmoxVE等 (USER@REALM!TOKENID)
+	PrivateKey            string   `json:"private_key"`   // SSH私钥内容，优先于密码使用
+	PrivateKeyPassphrase  string   `json:"-"`             // 加密私钥的密码短语
+	UseSSHAgent           bool     `json:"use_ssh_agent"` // 是否尝试ssh-agent认证
+	Token                 string   `json:"token"`         // API Token Secret，用于ProxmoxVE等
+	TokenID               string   `json:"token_id"`      // API Token ID，用于ProxmoxVE等 (USER@REALM!TOKENID)
 	CertPath              string   `json:"cert_path"`
 	KeyPath               string   `json:"key_path"`
 	Country               string   `json:"country"`             // Provider所在国家，用于CDN选择
@@ -495,14 +885,28 @@ type ProviderNodeConfig struct {
 	// 节点标识（用于区分多个相同hostname的节点）
 	HostName string `json:"host_name"` // 节点主机名（hostname），用于Proxmox等需要节点名的Provider
 
+	// SSH主机密钥校验
+	SSHVerifyHostKey      bool                     `json:"ssh_verify_host_key"`      // 是否校验SSH主机密钥
+	SSHHostKeyFingerprint string                   `json:"ssh_host_key_fingerprint"` // 已固定的主机密钥指纹
+	OnHostKeyPinned       func(fingerprint string) `json:"-"`                        // 首次连接固定指纹时回调，用于保存到数据库
+
 	// 容器特殊配置选项（仅适用于 LXD 和 Incus 的容器实例）
 	ContainerPrivileged   bool   `json:"containerPrivileged"`   // 容器特权模式
 	ContainerAllowNesting bool   `json:"containerAllowNesting"` // 容器嵌套
 	ContainerEnableLXCFS  bool   `json:"containerEnableLxcfs"`  // LXCFS资源视图
+	ContainerLXCFSMounts  string `json:"containerLxcfsMounts"`  // LXCFS挂载文件列表
 	ContainerCPUAllowance string `json:"containerCpuAllowance"` // CPU限制
 	ContainerMemorySwap   bool   `json:"containerMemorySwap"`   // 内存交换
-	ContainerMaxProcesses int    `json:"containerMaxProcesses"` // 最大进程数
-	ContainerDiskIOLimit  string `json:"containerDiskIoLimit"`  // 磁盘IO限制
+	// 未指定CPU限制时按核心数生成CPU限额的方式：percent、time、none
+	ContainerCPUAllowanceMode string `json:"containerCpuAllowanceMode"`
+	ContainerMaxProcesses     int    `json:"containerMaxProcesses"` // 最大进程数
+	ContainerDiskIOLimit      string `json:"containerDiskIoLimit"`  // 磁盘IO限制
+
+	VolumeAllowlist  string `json:"volumeAllowlist"`  // 允许挂载到实例内的宿主机目录
+	RegistryServer   string `json:"registryServer"`   // 镜像仓库地址，为空表示Docker Hub
+	RegistryUsername string `json:"registryUsername"` // 镜像仓库用户名，为空时匿名拉取
+	RegistryPassword string `json:"-"`                // 镜像仓库密码或访问令牌
+	AllowSystemPrune bool   `json:"allowSystemPrune"` // 删除实例失败时允许system prune兜底清理（仅Docker/Podman）
 }
 
 // ProviderResponse 用于返回给前端的Provider响应结构
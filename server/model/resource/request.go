@@ -40,3 +40,22 @@ type ReserveResourcesResult struct {
 	ReservationID uint
 	ExpiresAt     time.Time
 }
+
+// ProviderBudgetResult Provider资源预算重算结果
+type ProviderBudgetResult struct {
+	ProviderID  uint  `json:"providerId"`
+	TotalCPU    int   `json:"totalCpu"`
+	TotalMemory int64 `json:"totalMemory"` // MB
+	TotalDisk   int64 `json:"totalDisk"`   // MB
+	UsedCPU     int   `json:"usedCpu"`
+	UsedMemory  int64 `json:"usedMemory"` // MB
+	UsedDisk    int64 `json:"usedDisk"`   // MB
+	// 按超分配比例计算的可分配量，Available = Allocatable - Used
+	AllocatableCPU    int   `json:"allocatableCpu"`
+	AllocatableMemory int64 `json:"allocatableMemory"` // MB
+	AvailableCPU      int   `json:"availableCpu"`
+	AvailableMemory   int64 `json:"availableMemory"` // MB
+	AvailableDisk     int64 `json:"availableDisk"`   // MB
+	ContainerCount    int   `json:"containerCount"`
+	VMCount           int   `json:"vmCount"`
+}
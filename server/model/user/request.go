@@ -1,6 +1,11 @@
 package user
 
-import "oneclickvirt/model/common"
+import (
+	"time"
+
+	"oneclickvirt/model/common"
+	providerModel "oneclickvirt/model/provider"
+)
 
 type ClaimResourceRequest struct {
 	ProviderID   uint   `json:"providerId" binding:"required"`
@@ -24,6 +29,8 @@ type UserInstanceListRequest struct {
 	InstanceType string `json:"instanceType" form:"instanceType"`
 	Type         string `json:"type" form:"type"`                 // 实例类型筛选（和instanceType一样，兼容前端）
 	ProviderName string `json:"providerName" form:"providerName"` // 节点名称搜索
+	TagKey       string `json:"tagKey" form:"tagKey"`             // 按标签键筛选
+	TagValue     string `json:"tagValue" form:"tagValue"`         // 按标签值筛选，需同时指定tagKey
 }
 
 type AvailableResourcesRequest struct {
@@ -54,6 +61,40 @@ type ResetInstancePasswordRequest struct {
 	// 不需要传递任何参数，由后端自动生成新密码
 }
 
+// UpdateInstanceTagsRequest 更新实例标签请求，整体替换原有标签
+type UpdateInstanceTagsRequest struct {
+	Tags map[string]string `json:"tags"`
+}
+
+// CloneInstanceRequest 用户克隆实例请求
+type CloneInstanceRequest struct {
+	Name string `json:"name" binding:"omitempty,max=63"` // 克隆实例名称，为空时自动生成
+}
+
+// CreateInstanceFromTemplateRequest 从模板创建实例请求，规格ID为空时使用模板的默认规格
+type CreateInstanceFromTemplateRequest struct {
+	Name        string `json:"name" binding:"omitempty,max=63"`           // 实例名称，为空时自动生成
+	CPUId       string `json:"cpuId"`                                     // CPU规格ID
+	MemoryId    string `json:"memoryId"`                                  // 内存规格ID
+	DiskId      string `json:"diskId"`                                    // 磁盘规格ID，不能小于模板磁盘
+	BandwidthId string `json:"bandwidthId"`                               // 带宽规格ID
+	Password    string `json:"password" binding:"omitempty,min=8,max=64"` // root密码，为空时自动生成
+}
+
+// GrantInstanceShareRequest 共享实例给其他用户请求，同一用户重复授权时更新角色和过期时间
+type GrantInstanceShareRequest struct {
+	Username  string     `json:"username" binding:"required,max=64"`            // 被授权用户的用户名
+	Role      string     `json:"role" binding:"required,oneof=viewer operator"` // 共享角色：viewer只读，operator可开关机和重启
+	ExpiresAt *time.Time `json:"expiresAt"`                                     // 授权过期时间，为空表示长期有效
+}
+
+// UpdateInstanceLimitsRequest 用户调整实例CPU和内存请求
+type UpdateInstanceLimitsRequest struct {
+	CPUId    string `json:"cpuId" binding:"required"`    // CPU规格ID
+	MemoryId string `json:"memoryId" binding:"required"` // 内存规格ID
+	Restart  bool   `json:"restart"`                     // 需要重启才能生效时是否自动重启实例
+}
+
 // UserTasksRequest 用户任务列表请求
 type UserTasksRequest struct {
 	common.PageInfo
@@ -74,13 +115,72 @@ type SystemImagesRequest struct {
 // 安全设计：所有参数都是从后端预定义配置中选择的ID，不允许自定义输入
 // 实例名称由后端根据provider名称自动生成
 type CreateInstanceRequest struct {
-	ProviderId  uint   `json:"providerId" binding:"required"`  // 节点ID
-	ImageId     uint   `json:"imageId" binding:"required"`     // 镜像ID（从数据库获取）
-	CPUId       string `json:"cpuId" binding:"required"`       // CPU规格ID
-	MemoryId    string `json:"memoryId" binding:"required"`    // 内存规格ID
-	DiskId      string `json:"diskId" binding:"required"`      // 磁盘规格ID
-	BandwidthId string `json:"bandwidthId" binding:"required"` // 带宽规格ID
-	Description string `json:"description"`                    // 描述信息
+	ProviderId    uint              `json:"providerId"`                             // 节点ID，为0时按配置的策略自动选择节点
+	ImageId       uint              `json:"imageId" binding:"required"`             // 镜像ID（从数据库获取）
+	CPUId         string            `json:"cpuId"`                                  // CPU规格ID，为空时使用节点默认规格
+	MemoryId      string            `json:"memoryId"`                               // 内存规格ID，为空时使用节点默认规格
+	DiskId        string            `json:"diskId"`                                 // 磁盘规格ID，为空时使用节点默认规格
+	BandwidthId   string            `json:"bandwidthId" binding:"required"`         // 带宽规格ID
+	Description   string            `json:"description"`                            // 描述信息
+	Tags          map[string]string `json:"tags"`                                   // 实例标签
+	RequestedIPv4 string            `json:"requestedIPv4" binding:"omitempty,ipv4"` // 指定内网IPv4地址（仅LXD/Incus/Proxmox支持）
+	RequestedIPv6 string            `json:"requestedIPv6" binding:"omitempty,ipv6"` // 指定内网IPv6地址（仅LXD/Incus支持）
+	SSHPublicKeys []string          `json:"sshPublicKeys"`                          // root用户SSH公钥（仅Proxmox虚拟机通过cloud-init写入）
+	TTLHours      int               `json:"ttlHours"`                               // 实例有效期（小时），0表示不指定，到期后自动回收
+
+	// 主网卡使用的网络（仅Docker/Podman支持），为空时使用节点默认网络；额外网卡同样只能引用节点允许的网络
+	Network           string                                         `json:"network"`
+	NetworkInterfaces []providerModel.ProviderNetworkInterfaceConfig `json:"networkInterfaces"`
+
+	// 直通的宿主机GPU的PCI地址（仅开启了GPU直通的Proxmox/Incus节点上的虚拟机）
+	GPUDevices []string `json:"gpuDevices"`
+
+	// 自定义DNS服务器（IPv4或IPv6地址，最多3个），为空时使用节点默认DNS
+	DNSServers []string `json:"dnsServers"`
+
+	// 自定义主机名或FQDN（如 web1.example.com），为空时使用实例名称
+	Hostname string `json:"hostname"`
+
+	// swap大小（MB），不填时使用节点默认行为，0表示禁用swap（Proxmox虚拟机不支持）
+	MemorySwapMB *int64 `json:"memorySwapMB"`
+
+	// 挂载到实例内的宿主机目录，只能挂载节点允许列表中的目录（Proxmox虚拟机不支持）
+	Volumes []providerModel.ProviderVolumeMount `json:"volumes"`
+}
+
+// ExtendInstanceRequest 实例续期请求
+type ExtendInstanceRequest struct {
+	Hours int `json:"hours" binding:"required,min=1"` // 续期时长（小时）
+}
+
+// SetInstanceScheduleRequest 设置实例定时开关机计划请求
+// cron表达式为标准5字段格式（分 时 日 月 周），开机和关机至少设置一项
+type SetInstanceScheduleRequest struct {
+	StartCron string `json:"startCron" binding:"max=64"`         // 开机cron表达式，如 0 9 * * 1-5
+	StopCron  string `json:"stopCron" binding:"max=64"`          // 关机cron表达式，如 0 18 * * 1-5
+	Timezone  string `json:"timezone" binding:"required,max=64"` // IANA时区名，如 Asia/Shanghai
+	Enabled   *bool  `json:"enabled"`                            // 是否启用，默认启用
+}
+
+// ExecInstanceCommandRequest 实例内执行命令请求
+type ExecInstanceCommandRequest struct {
+	Command string `json:"command" binding:"required,max=4096"` // 在实例内通过sh -c执行的命令
+}
+
+// InstanceLogsRequest 获取实例日志请求
+type InstanceLogsRequest struct {
+	Lines int       `json:"lines" form:"lines" binding:"omitempty,min=1,max=1000"`      // 返回的日志行数，默认200
+	Since time.Time `json:"since" form:"since" time_format:"2006-01-02T15:04:05Z07:00"` // 只返回该时间之后的日志（RFC3339），控制台日志不支持
+}
+
+// AddFirewallRuleRequest 添加实例防火墙规则请求，端口为实例内部端口
+type AddFirewallRuleRequest struct {
+	Protocol    string `json:"protocol" binding:"required,oneof=tcp udp"`   // 协议
+	Port        int    `json:"port" binding:"required,min=1,max=65535"`     // 起始端口
+	EndPort     int    `json:"endPort" binding:"omitempty,min=1,max=65535"` // 端口范围结束，为空表示单端口
+	Source      string `json:"source" binding:"omitempty,max=64"`           // 来源地址或CIDR，为空表示任意来源
+	Action      string `json:"action" binding:"required,oneof=allow deny"`  // allow放行，deny拒绝
+	Description string `json:"description" binding:"omitempty,max=128"`     // 规则描述
 }
 
 // QuotaCheckRequest 配额检查请求
@@ -92,3 +192,19 @@ type QuotaCheckRequest struct {
 	Disk         int    `json:"disk"`
 	Bandwidth    int    `json:"bandwidth"`
 }
+
+// ResourceAlertListRequest 资源告警记录列表请求
+type ResourceAlertListRequest struct {
+	common.PageInfo
+	InstanceID uint   `json:"instanceId" form:"instanceId"`
+	AlertType  string `json:"alertType" form:"alertType"` // cpu, disk, traffic_spike
+}
+
+// UpdateResourceAlertSettingsRequest 更新资源告警设置请求，字段为空表示使用系统默认值
+type UpdateResourceAlertSettingsRequest struct {
+	Enabled         *bool `json:"enabled"`
+	CPUPercent      *int  `json:"cpuPercent" binding:"omitempty,min=1,max=100"`
+	CPUMinutes      *int  `json:"cpuMinutes" binding:"omitempty,min=1,max=1440"`
+	DiskPercent     *int  `json:"diskPercent" binding:"omitempty,min=1,max=100"`
+	CooldownMinutes *int  `json:"cooldownMinutes" binding:"omitempty,min=1,max=10080"`
+}
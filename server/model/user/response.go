@@ -42,6 +42,8 @@ type AvailableResourceResponse struct {
 	City                  string `json:"city"`
 	ContainerEnabled      bool   `json:"containerEnabled"`
 	VirtualMachineEnabled bool   `json:"vmEnabled"`
+	SupportsVM            bool   `json:"supportsVm"`                    // 是否可以申领虚拟机（已开放且宿主机支持KVM，未探测时按开放状态）
+	VMUnavailableReason   string `json:"vmUnavailableReason,omitempty"` // 无法申领虚拟机的原因
 	AvailableQuota        int    `json:"availableQuota"`
 	Status                string `json:"status"`
 }
@@ -73,6 +75,9 @@ type UserLimitsResponse struct {
 	UsedBandwidth int   `json:"usedBandwidth"` // 已使用带宽(Mbps)
 	MaxTraffic    int64 `json:"maxTraffic"`    // 最大流量(MB)
 	UsedTraffic   int64 `json:"usedTraffic"`   // 已使用流量(MB)
+
+	TrafficUsagePercent float64 `json:"trafficUsagePercent"` // 当月流量使用百分比
+	TrafficLimited      bool    `json:"trafficLimited"`      // 是否因流量超限被限制
 }
 
 // UserTaskResponse 用户任务响应
@@ -82,6 +87,7 @@ type UserTaskResponse struct {
 	TaskType         string     `json:"taskType"`
 	Status           string     `json:"status"`
 	Progress         int        `json:"progress"`
+	Priority         int        `json:"priority"` // 任务优先级，数值越大越先执行
 	ErrorMessage     string     `json:"errorMessage"`
 	CancelReason     string     `json:"cancelReason"` // 取消原因
 	CreatedAt        time.Time  `json:"createdAt"`
@@ -113,31 +119,36 @@ type TaskResponse = UserTaskResponse
 
 // UserInstanceDetailResponse 用户实例详情响应
 type UserInstanceDetailResponse struct {
-	ID              uint      `json:"id"`
-	Name            string    `json:"name"`
-	Type            string    `json:"type"`
-	Status          string    `json:"status"`
-	CPU             int       `json:"cpu"`
-	Memory          int       `json:"memory"`
-	Disk            int       `json:"disk"`
-	Bandwidth       int       `json:"bandwidth"`
-	OsType          string    `json:"osType"`
-	PrivateIP       string    `json:"privateIP"`   // 内网IPv4地址
-	PublicIP        string    `json:"publicIP"`    // 公网IPv4地址
-	IPv6Address     string    `json:"ipv6Address"` // 内网IPv6地址
-	PublicIPv6      string    `json:"publicIPv6"`  // 公网IPv6地址
-	SSHPort         int       `json:"sshPort"`
-	Username        string    `json:"username"`
-	Password        string    `json:"password"`
-	ProviderName    string    `json:"providerName"`
-	ProviderType    string    `json:"providerType"`    // Provider虚拟化类型：docker, lxd, incus, proxmox
-	ProviderStatus  string    `json:"providerStatus"`  // Provider状态：active, inactive, partial
-	PortRangeStart  int       `json:"portRangeStart"`  // 端口范围起始
-	PortRangeEnd    int       `json:"portRangeEnd"`    // 端口范围结束
-	IPv4MappingType string    `json:"ipv4MappingType"` // IPv4映射类型：nat(NAT共享IP), dedicated(独立IPv4地址) (已弃用，保留向后兼容)
-	NetworkType     string    `json:"networkType"`     // 网络配置类型：nat_ipv4, nat_ipv4_ipv6, dedicated_ipv4, dedicated_ipv4_ipv6, ipv6_only
-	CreatedAt       time.Time `json:"createdAt"`
-	ExpiredAt       time.Time `json:"expiredAt"`
+	ID                uint              `json:"id"`
+	Name              string            `json:"name"`
+	Type              string            `json:"type"`
+	Status            string            `json:"status"`
+	CPU               int               `json:"cpu"`
+	Memory            int               `json:"memory"`
+	Disk              int               `json:"disk"`
+	Bandwidth         int               `json:"bandwidth"`
+	OsType            string            `json:"osType"`
+	PrivateIP         string            `json:"privateIP"`    // 内网IPv4地址
+	PublicIP          string            `json:"publicIP"`     // 公网IPv4地址
+	IPv6Address       string            `json:"ipv6Address"`  // 内网IPv6地址
+	PublicIPv6        string            `json:"publicIPv6"`   // 公网IPv6地址
+	ReservedIPv4      string            `json:"reservedIPv4"` // 创建时指定的内网IPv4地址
+	ReservedIPv6      string            `json:"reservedIPv6"` // 创建时指定的内网IPv6地址
+	SSHPort           int               `json:"sshPort"`
+	Username          string            `json:"username"`
+	Password          string            `json:"password"`
+	ProviderName      string            `json:"providerName"`
+	ProviderType      string            `json:"providerType"`    // Provider虚拟化类型：docker, lxd, incus, proxmox
+	ProviderStatus    string            `json:"providerStatus"`  // Provider状态：active, inactive, partial
+	PortRangeStart    int               `json:"portRangeStart"`  // 端口范围起始
+	PortRangeEnd      int               `json:"portRangeEnd"`    // 端口范围结束
+	IPv4MappingType   string            `json:"ipv4MappingType"` // IPv4映射类型：nat(NAT共享IP), dedicated(独立IPv4地址) (已弃用，保留向后兼容)
+	NetworkType       string            `json:"networkType"`     // 网络配置类型：nat_ipv4, nat_ipv4_ipv6, dedicated_ipv4, dedicated_ipv4_ipv6, ipv6_only
+	Tags              map[string]string `json:"tags"`
+	CreatedAt         time.Time         `json:"createdAt"`
+	ExpiredAt         time.Time         `json:"expiredAt"`
+	DeleteScheduledAt *time.Time        `json:"deleteScheduledAt"`    // 计划删除时间，等待期内可撤销
+	SharedRole        string            `json:"sharedRole,omitempty"` // 通过共享访问时的角色，所有者访问时为空
 	// 关联任务信息
 	RelatedTask *UserTaskResponse `json:"relatedTask,omitempty"` // 关联的最新任务（如果有）
 }
@@ -147,7 +158,8 @@ type InstanceMonitoringResponse struct {
 	// CPUUsage    float64     `json:"cpuUsage"`    // 已移除：硬件资源使用率监控
 	// MemoryUsage float64     `json:"memoryUsage"` // 已移除：硬件资源使用率监控
 	// DiskUsage   float64     `json:"diskUsage"`   // 已移除：硬件资源使用率监控
-	TrafficData TrafficData `json:"trafficData"` // 流量详细数据（基于pmacct）
+	TrafficData TrafficData                      `json:"trafficData"`         // 流量详细数据（基于pmacct）
+	DiskUsage   *providerModel.InstanceDiskUsage `json:"diskUsage,omitempty"` // 根磁盘使用情况，实例未运行或无法采集时为空
 }
 
 // TrafficData 流量数据结构
@@ -219,11 +231,23 @@ type SystemImageResponse struct {
 
 // InstanceConfigResponse 实例配置响应
 type InstanceConfigResponse struct {
-	Images         []SystemImageResponse   `json:"images"`         // 可用镜像列表（从数据库获取）
-	CPUSpecs       []CPUSpecResponse       `json:"cpuSpecs"`       // 可用CPU规格列表
-	MemorySpecs    []MemorySpecResponse    `json:"memorySpecs"`    // 可用内存规格列表
-	DiskSpecs      []DiskSpecResponse      `json:"diskSpecs"`      // 可用磁盘规格列表
-	BandwidthSpecs []BandwidthSpecResponse `json:"bandwidthSpecs"` // 可用带宽规格列表
+	Images         []SystemImageResponse      `json:"images"`         // 可用镜像列表（从数据库获取）
+	CPUSpecs       []CPUSpecResponse          `json:"cpuSpecs"`       // 可用CPU规格列表
+	MemorySpecs    []MemorySpecResponse       `json:"memorySpecs"`    // 可用内存规格列表
+	DiskSpecs      []DiskSpecResponse         `json:"diskSpecs"`      // 可用磁盘规格列表
+	BandwidthSpecs []BandwidthSpecResponse    `json:"bandwidthSpecs"` // 可用带宽规格列表
+	Defaults       InstanceConfigDefaults     `json:"defaults"`       // 节点默认规格，用于预填表单
+	Templates      []InstanceTemplateResponse `json:"templates"`      // 可用的实例模板，指定节点时只返回该节点上的模板
+}
+
+// InstanceConfigDefaults 节点默认资源限制及对应的可选规格ID，规格ID为空表示未设置或超出用户可用范围
+type InstanceConfigDefaults struct {
+	CPU      int    `json:"cpu"`      // 默认CPU核心数
+	MemoryMB int64  `json:"memoryMB"` // 默认内存大小（MB）
+	DiskMB   int64  `json:"diskMB"`   // 默认磁盘大小（MB）
+	CPUId    string `json:"cpuId"`
+	MemoryId string `json:"memoryId"`
+	DiskId   string `json:"diskId"`
 }
 
 // 规格响应结构
@@ -271,8 +295,110 @@ type ResetInstancePasswordResponse struct {
 	TaskID uint `json:"taskId"`
 }
 
+// CloneInstanceResponse 用户克隆实例响应
+type CloneInstanceResponse struct {
+	TaskID     uint   `json:"taskId"`
+	InstanceID uint   `json:"instanceId"`
+	Name       string `json:"name"`
+}
+
+// CreateInstanceFromTemplateResponse 从模板创建实例响应
+type CreateInstanceFromTemplateResponse struct {
+	TaskID     uint   `json:"taskId"`
+	InstanceID uint   `json:"instanceId"`
+	Name       string `json:"name"`
+}
+
+// InstanceTemplateResponse 用户可用的实例模板
+type InstanceTemplateResponse struct {
+	ID           uint   `json:"id"`
+	Name         string `json:"name"`
+	Description  string `json:"description"`
+	ProviderID   uint   `json:"providerId"`
+	InstanceType string `json:"instanceType"`
+	Image        string `json:"image"`
+	OSType       string `json:"osType"`
+	CPU          int    `json:"cpu"`       // 默认CPU核心数
+	Memory       int64  `json:"memory"`    // 默认内存（MB）
+	MinDisk      int64  `json:"minDisk"`   // 最小磁盘（MB），即模板磁盘大小
+	Bandwidth    int    `json:"bandwidth"` // 默认带宽（Mbps）
+}
+
+// UndeleteInstanceResponse 撤销实例删除响应
+type UndeleteInstanceResponse struct {
+	ID     uint   `json:"id"`
+	Status string `json:"status"` // 实例当前状态，撤销删除后不会自动启动
+}
+
+// UpdateInstanceLimitsResponse 用户调整实例CPU和内存响应
+type UpdateInstanceLimitsResponse struct {
+	CPU             int   `json:"cpu"`             // 调整后的CPU核心数
+	Memory          int64 `json:"memory"`          // 调整后的内存大小（MB）
+	RestartRequired bool  `json:"restartRequired"` // 是否需要重启实例后生效
+	Restarted       bool  `json:"restarted"`       // 是否已提交重启任务
+}
+
 // GetInstancePasswordResponse 获取实例新密码响应
 type GetInstancePasswordResponse struct {
 	NewPassword string `json:"newPassword"`
 	ResetTime   int64  `json:"resetTime"`
 }
+
+// ResourceAlertSettingsResponse 资源告警设置（已合并系统默认值）
+type ResourceAlertSettingsResponse struct {
+	SystemEnabled   bool `json:"systemEnabled"` // 系统是否启用资源告警
+	Enabled         bool `json:"enabled"`
+	CPUPercent      int  `json:"cpuPercent"`
+	CPUMinutes      int  `json:"cpuMinutes"`
+	DiskPercent     int  `json:"diskPercent"`
+	CooldownMinutes int  `json:"cooldownMinutes"`
+}
+
+// ExecInstanceCommandResponse 实例内执行命令结果
+type ExecInstanceCommandResponse struct {
+	Stdout    string `json:"stdout"`
+	Stderr    string `json:"stderr"`
+	ExitCode  int    `json:"exitCode"`  // 命令退出码，超时时为-1或137
+	Truncated bool   `json:"truncated"` // 输出是否因超过大小限制被截断
+	TimedOut  bool   `json:"timedOut"`  // 是否因超时被终止
+	Duration  int64  `json:"duration"`  // 执行耗时（毫秒）
+}
+
+// InstanceLogsResponse 实例日志
+type InstanceLogsResponse struct {
+	Logs      string `json:"logs"`
+	Lines     int    `json:"lines"`     // 请求的日志行数
+	Truncated bool   `json:"truncated"` // 日志是否因超过大小限制被截断
+}
+
+// InstanceScheduleResponse 实例定时开关机计划
+type InstanceScheduleResponse struct {
+	providerModel.InstanceSchedule
+	NextStartAt *time.Time `json:"nextStartAt"` // 下次定时开机时间
+	NextStopAt  *time.Time `json:"nextStopAt"`  // 下次定时关机时间
+}
+
+// InstanceShareResponse 实例共享授权
+type InstanceShareResponse struct {
+	providerModel.InstanceShare
+	Username string `json:"username"` // 被授权用户的用户名
+}
+
+// SharedInstanceResponse 其他用户共享给当前用户的实例
+type SharedInstanceResponse struct {
+	ID           uint       `json:"id"`
+	Name         string     `json:"name"`
+	Type         string     `json:"type"`
+	Status       string     `json:"status"`
+	ProviderName string     `json:"providerName"`
+	OwnerName    string     `json:"ownerName"` // 实例所有者的用户名
+	Role         string     `json:"role"`      // 共享角色：viewer, operator
+	ExpiresAt    *time.Time `json:"expiresAt"` // 授权过期时间
+}
+
+// ExtendInstanceResponse 实例续期响应
+type ExtendInstanceResponse struct {
+	ExpiredAt      time.Time `json:"expiredAt"`      // 续期后的到期时间
+	MaxExpiredAt   time.Time `json:"maxExpiredAt"`   // 当前策略下可续期到的最晚时间
+	ExtendMaxHours int       `json:"extendMaxHours"` // 单次续期的最长时长（小时）
+}
@@ -30,6 +30,8 @@ type User struct {
 	Level    int    `json:"level" gorm:"default:1"`               // 用户等级，用于权限控制
 	UserType string `json:"userType" gorm:"default:user;size:16"` // 用户类型：user, admin, super_admin等
 
+	AllowInstanceExec bool `json:"allowInstanceExec" gorm:"default:false"` // 是否允许在实例内直接执行命令
+
 	// 配额管理（传统系统兼容字段）
 	UsedQuota  int `json:"usedQuota" gorm:"default:0"`  // 已使用配额
 	TotalQuota int `json:"totalQuota" gorm:"default:0"` // 总配额限制
@@ -38,6 +40,7 @@ type User struct {
 	TotalTraffic   int64      `json:"totalTraffic" gorm:"default:0"`       // 当月流量配额（MB），根据用户等级自动设置
 	TrafficResetAt *time.Time `json:"trafficResetAt"`                      // 流量重置时间
 	TrafficLimited bool       `json:"trafficLimited" gorm:"default:false"` // 是否因流量超限被限制
+	TrafficWarnAt  *time.Time `json:"trafficWarnAt"`                       // 最近一次发送流量预警通知的时间（每月最多发送一次）
 
 	// 资源限制（根据用户等级自动设置，避免每次查询配置）
 	MaxInstances int `json:"maxInstances" gorm:"default:1"`   // 最大实例数
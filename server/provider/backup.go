@@ -0,0 +1,80 @@
+package provider
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"oneclickvirt/global"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+)
+
+// BackupTempDir 宿主机上存放备份临时文件的目录，使用/var/tmp避免占用tmpfs内存
+const BackupTempDir = "/var/tmp/oneclickvirt-backup"
+
+// PrepareBackupWorkDir 在宿主机上创建本次备份/恢复使用的临时目录
+func PrepareBackupWorkDir(client *utils.SSHClient, instanceName string) (string, error) {
+	if err := ValidateInstanceName(instanceName); err != nil {
+		return "", err
+	}
+	dir := fmt.Sprintf("%s/%s-%d", BackupTempDir, instanceName, time.Now().UnixNano())
+	if output, err := client.Execute(fmt.Sprintf("mkdir -p %s", dir)); err != nil {
+		return "", fmt.Errorf("创建备份临时目录失败: %s: %w", strings.TrimSpace(output), err)
+	}
+	return dir, nil
+}
+
+// RemoveBackupWorkDir 删除宿主机上的备份临时目录，失败只记录日志
+func RemoveBackupWorkDir(client *utils.SSHClient, dir string) {
+	if !strings.HasPrefix(dir, BackupTempDir+"/") {
+		return
+	}
+	if output, err := client.Execute(fmt.Sprintf("rm -rf %s", dir)); err != nil {
+		global.APP_LOG.Warn("删除备份临时目录失败",
+			zap.String("dir", dir),
+			zap.String("output", strings.TrimSpace(output)),
+			zap.Error(err))
+	}
+}
+
+// RunBackupCommand 执行导出/导入命令，命令以非零状态退出时返回stderr
+// 大实例的导出可能持续很久，使用备份类超时
+func RunBackupCommand(client *utils.SSHClient, command string) error {
+	result, err := client.ExecuteCapture(command, utils.SSHOperationTimeout(utils.SSHOperationBackup), 16*1024)
+	if err != nil {
+		return err
+	}
+	if result.ExitCode != 0 {
+		output := strings.TrimSpace(result.Stderr)
+		if output == "" {
+			output = strings.TrimSpace(result.Stdout)
+		}
+		return fmt.Errorf("命令退出码 %d: %s", result.ExitCode, utils.TruncateString(output, 512))
+	}
+	return nil
+}
+
+// SendBackupArchive 将宿主机上的归档文件流式写入dest
+func SendBackupArchive(client *utils.SSHClient, archivePath string, dest io.Writer) (*BackupArchive, error) {
+	size, err := client.DownloadFile(archivePath, dest)
+	if err != nil {
+		return nil, fmt.Errorf("传输备份文件失败: %w", err)
+	}
+	return &BackupArchive{FileName: path.Base(archivePath), Size: size}, nil
+}
+
+// ReceiveBackupArchive 将src中的归档写入宿主机临时目录，返回归档在宿主机上的路径
+func ReceiveBackupArchive(client *utils.SSHClient, dir, fileName string, src io.Reader) (string, error) {
+	if fileName == "" || fileName != path.Base(fileName) || strings.HasPrefix(fileName, ".") {
+		return "", fmt.Errorf("备份文件名无效: %s", fileName)
+	}
+	archivePath := dir + "/" + fileName
+	if _, err := client.UploadFile(src, archivePath); err != nil {
+		return "", fmt.Errorf("传输备份文件失败: %w", err)
+	}
+	return archivePath, nil
+}
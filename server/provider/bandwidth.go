@@ -0,0 +1,57 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// MaxBandwidthMbps 单个实例允许设置的带宽上限（Mbps）
+const MaxBandwidthMbps = 100000
+
+// minBandwidthBurstBytes tc令牌桶的最小突发量，过小会导致低速率下无法发送完整报文
+const minBandwidthBurstBytes = 16 * 1024
+
+var hostInterfacePattern = regexp.MustCompile(`^[A-Za-z0-9_.\-]{1,15}$`)
+
+// BuildBandwidthLimitCommand 生成在宿主机侧veth/tap接口上设置tc限速的命令
+// 宿主机侧接口的出方向即实例的下载方向，使用tbf整形；入方向即实例的上传方向，使用ingress police限速。
+// uploadMbps/downloadMbps小于等于0表示该方向不限速，两者均为0时仅清理已有规则
+func BuildBandwidthLimitCommand(iface string, uploadMbps, downloadMbps int) (string, error) {
+	iface = strings.TrimSpace(iface)
+	if !hostInterfacePattern.MatchString(iface) {
+		return "", fmt.Errorf("无效的网络接口名称: %s", iface)
+	}
+	if uploadMbps > MaxBandwidthMbps || downloadMbps > MaxBandwidthMbps {
+		return "", fmt.Errorf("带宽限制不能超过%dMbps", MaxBandwidthMbps)
+	}
+
+	// 先清理已有规则，规则不存在时删除失败不影响后续设置
+	command := fmt.Sprintf("tc qdisc del dev %s root 2>/dev/null; tc qdisc del dev %s ingress 2>/dev/null; true", iface, iface)
+
+	var commands []string
+	if downloadMbps > 0 {
+		commands = append(commands, fmt.Sprintf(
+			"tc qdisc add dev %s root tbf rate %dmbit burst %d latency 400ms",
+			iface, downloadMbps, bandwidthBurstBytes(downloadMbps)))
+	}
+	if uploadMbps > 0 {
+		commands = append(commands,
+			fmt.Sprintf("tc qdisc add dev %s handle ffff: ingress", iface),
+			fmt.Sprintf("tc filter add dev %s parent ffff: protocol all prio 1 u32 match u32 0 0 police rate %dmbit burst %d drop flowid :1",
+				iface, uploadMbps, bandwidthBurstBytes(uploadMbps)))
+	}
+	if len(commands) > 0 {
+		command += " && " + strings.Join(commands, " && ")
+	}
+	return command, nil
+}
+
+// bandwidthBurstBytes 按10ms的数据量计算突发大小
+func bandwidthBurstBytes(mbps int) int {
+	burst := mbps * 1000000 / 8 / 100
+	if burst < minBandwidthBurstBytes {
+		return minBandwidthBurstBytes
+	}
+	return burst
+}
@@ -0,0 +1,73 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestBuildBandwidthLimitCommand 测试tc限速命令的生成与参数校验
+func TestBuildBandwidthLimitCommand(t *testing.T) {
+	tests := []struct {
+		name        string
+		iface       string
+		upload      int
+		download    int
+		contains    []string
+		excludes    []string
+		expectError bool
+	}{
+		{
+			name:     "双向限速",
+			iface:    "veth1a2b3c",
+			upload:   50,
+			download: 100,
+			contains: []string{
+				"tc qdisc del dev veth1a2b3c root",
+				"tc qdisc add dev veth1a2b3c root tbf rate 100mbit burst 125000",
+				"police rate 50mbit burst 62500",
+			},
+		},
+		{
+			name:     "仅下载限速",
+			iface:    "tap100i0",
+			download: 10,
+			contains: []string{"tbf rate 10mbit burst 16384"},
+			excludes: []string{"ingress &&", "police"},
+		},
+		{
+			name:     "均为0时只清理规则",
+			iface:    "veth100i0",
+			contains: []string{"tc qdisc del dev veth100i0 ingress"},
+			excludes: []string{"tc qdisc add", "&&"},
+		},
+		{name: "接口名包含非法字符", iface: "eth0; reboot", upload: 10, download: 10, expectError: true},
+		{name: "接口名过长", iface: "veth0123456789abcdef", upload: 10, download: 10, expectError: true},
+		{name: "接口名为空", iface: "", upload: 10, download: 10, expectError: true},
+		{name: "超过带宽上限", iface: "veth0", upload: MaxBandwidthMbps + 1, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := BuildBandwidthLimitCommand(tt.iface, tt.upload, tt.download)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("BuildBandwidthLimitCommand(%q) 期望返回错误，实际返回 %q", tt.iface, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("BuildBandwidthLimitCommand(%q) 返回错误: %v", tt.iface, err)
+			}
+			for _, s := range tt.contains {
+				if !strings.Contains(got, s) {
+					t.Errorf("命令 %q 应包含 %q", got, s)
+				}
+			}
+			for _, s := range tt.excludes {
+				if strings.Contains(got, s) {
+					t.Errorf("命令 %q 不应包含 %q", got, s)
+				}
+			}
+		})
+	}
+}
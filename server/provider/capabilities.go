@@ -0,0 +1,209 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"oneclickvirt/utils"
+)
+
+// CapabilitiesCacheTTL Provider能力探测结果的缓存时间，过期后下次查询重新探测
+const CapabilitiesCacheTTL = 30 * time.Minute
+
+// ErrIPv6Unavailable 节点没有可用的IPv6网段，无法为实例分配IPv6
+var ErrIPv6Unavailable = errors.New("该节点未配置IPv6环境，无法创建带IPv6的实例")
+
+// CapabilitiesCache 缓存单个Provider的能力探测结果，避免每次查询都执行SSH探测
+// 零值可直接使用，重新连接Provider时应调用Invalidate
+type CapabilitiesCache struct {
+	mu        sync.Mutex
+	caps      *Capabilities
+	expiresAt time.Time
+}
+
+// Get 返回缓存的能力信息，缓存不存在或已过期时调用probe重新探测
+// 探测失败时不缓存结果，返回的副本可由调用方自由修改
+func (c *CapabilitiesCache) Get(probe func() (*Capabilities, error)) (*Capabilities, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.caps != nil && time.Now().Before(c.expiresAt) {
+		return copyCapabilities(c.caps), nil
+	}
+
+	caps, err := probe()
+	if err != nil {
+		return nil, err
+	}
+	if caps.ProbedAt.IsZero() {
+		caps.ProbedAt = time.Now()
+	}
+	c.caps = caps
+	c.expiresAt = time.Now().Add(CapabilitiesCacheTTL)
+	return copyCapabilities(caps), nil
+}
+
+// Peek 返回缓存中未过期的能力信息，不会触发探测，缓存不存在或已过期时返回nil
+func (c *CapabilitiesCache) Peek() *Capabilities {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.caps == nil || !time.Now().Before(c.expiresAt) {
+		return nil
+	}
+	return copyCapabilities(c.caps)
+}
+
+// Invalidate 清除缓存的能力信息
+func (c *CapabilitiesCache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.caps = nil
+}
+
+func copyCapabilities(caps *Capabilities) *Capabilities {
+	cp := *caps
+	cp.Architectures = append([]string(nil), caps.Architectures...)
+	return &cp
+}
+
+// NormalizeArchitecture 将 uname -m 的输出转换为系统中使用的架构名称（amd64、arm64等）
+func NormalizeArchitecture(machine string) string {
+	machine = strings.ToLower(strings.TrimSpace(machine))
+	switch machine {
+	case "x86_64", "amd64":
+		return "amd64"
+	case "aarch64", "arm64", "armv8", "armv8l":
+		return "arm64"
+	case "s390x":
+		return "s390x"
+	case "ppc64le":
+		return "ppc64le"
+	case "riscv64":
+		return "riscv64"
+	}
+	return machine
+}
+
+// hostProbeCommand 一次SSH调用探测宿主机架构、KVM设备和全局IPv6地址
+const hostProbeCommand = `echo "arch=$(uname -m)"; ` +
+	`[ -c /dev/kvm ] && echo "kvm=1" || echo "kvm=0"; ` +
+	`ip -6 addr show scope global 2>/dev/null | grep -q inet6 && echo "ipv6=1" || echo "ipv6=0"`
+
+// HostProbe 宿主机基础环境探测结果
+type HostProbe struct {
+	Architecture string // 规范化后的CPU架构
+	KVM          bool   // /dev/kvm 是否存在
+	GlobalIPv6   bool   // 宿主机是否有全局IPv6地址
+}
+
+// ProbeHost 通过SSH探测宿主机的基础环境
+func ProbeHost(client *utils.SSHClient) (HostProbe, error) {
+	if client == nil {
+		return HostProbe{}, fmt.Errorf("SSH client not connected")
+	}
+	output, err := client.Execute(hostProbeCommand)
+	if err != nil {
+		return HostProbe{}, fmt.Errorf("探测宿主机环境失败: %w", err)
+	}
+	return ParseHostProbe(output), nil
+}
+
+// ParseHostProbe 解析hostProbeCommand的输出
+func ParseHostProbe(output string) HostProbe {
+	var probe HostProbe
+	for _, line := range strings.Split(output, "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "arch":
+			probe.Architecture = NormalizeArchitecture(value)
+		case "kvm":
+			probe.KVM = value == "1"
+		case "ipv6":
+			probe.GlobalIPv6 = value == "1"
+		}
+	}
+	return probe
+}
+
+// Architectures 返回探测到的架构列表，未探测到时返回nil
+func (h HostProbe) Architectures() []string {
+	if h.Architecture == "" {
+		return nil
+	}
+	return []string{h.Architecture}
+}
+
+// VMUnsupportedMessage 节点宿主机无法运行虚拟机时展示给用户的提示
+const VMUnsupportedMessage = "该节点不支持创建虚拟机（宿主机未启用KVM虚拟化）"
+
+// VMUnavailableReason 返回节点无法创建虚拟机的原因，可以创建时返回空字符串
+// vmEnabled为节点是否开放虚拟机，caps为nil表示尚未探测到能力信息，此时只按开放状态判断
+func VMUnavailableReason(vmEnabled bool, caps *Capabilities) string {
+	if !vmEnabled {
+		return "该节点未开放虚拟机"
+	}
+	if caps != nil && !caps.SupportsVM {
+		return VMUnsupportedMessage
+	}
+	return ""
+}
+
+// CheckInstanceCapabilities 校验实例配置是否与Provider的能力匹配，不匹配时返回可展示给用户的错误
+// architecture为空或能力信息中未探测到架构时不校验架构；硬盘大小限制不支持时Provider会忽略该限制，因此不作为拒绝条件
+func CheckInstanceCapabilities(caps *Capabilities, instanceType, architecture string, needIPv6 bool) error {
+	if caps == nil {
+		return nil
+	}
+	if !caps.SupportsInstanceType(instanceType) {
+		if instanceType == "vm" {
+			return errors.New(VMUnsupportedMessage)
+		}
+		return fmt.Errorf("该节点不支持创建%s类型的实例", instanceType)
+	}
+	if architecture != "" && len(caps.Architectures) > 0 {
+		arch := NormalizeArchitecture(architecture)
+		supported := false
+		for _, a := range caps.Architectures {
+			if a == arch {
+				supported = true
+				break
+			}
+		}
+		if !supported {
+			return fmt.Errorf("该节点不支持%s架构的镜像，节点架构为%s", arch, strings.Join(caps.Architectures, ", "))
+		}
+	}
+	if needIPv6 && !caps.SupportsIPv6 {
+		return ErrIPv6Unavailable
+	}
+	return nil
+}
+
+// NetworkTypeRequiresIPv6 判断网络类型是否需要为实例分配IPv6（纯IPv6或IPv4/IPv6双栈）
+func NetworkTypeRequiresIPv6(networkType string) bool {
+	return strings.Contains(networkType, "ipv6")
+}
+
+// IPv6UnavailableMessage 生成节点缺少可用IPv6网段时展示给用户的提示，compatible为可改选的其他节点名称
+func IPv6UnavailableMessage(networkType string, compatible []string) string {
+	var msg string
+	switch {
+	case networkType == "ipv6_only":
+		msg = "该节点没有可用的IPv6网段，无法创建纯IPv6实例"
+	case NetworkTypeRequiresIPv6(networkType):
+		msg = "该节点没有可用的IPv6网段，无法创建IPv4/IPv6双栈实例"
+	default:
+		msg = ErrIPv6Unavailable.Error()
+	}
+	if len(compatible) == 0 {
+		return msg + "，当前没有其他可用的IPv6节点"
+	}
+	return msg + "，可改选以下支持IPv6的节点：" + strings.Join(compatible, "、")
+}
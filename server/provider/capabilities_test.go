@@ -0,0 +1,131 @@
+package provider
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestParseHostProbe 测试宿主机探测输出的解析
+func TestParseHostProbe(t *testing.T) {
+	probe := ParseHostProbe("arch=aarch64\nkvm=1\nipv6=0\n")
+	if probe.Architecture != "arm64" || !probe.KVM || probe.GlobalIPv6 {
+		t.Fatalf("解析结果错误: %+v", probe)
+	}
+
+	if got := ParseHostProbe("").Architectures(); got != nil {
+		t.Fatalf("未探测到架构时应返回nil，实际 %v", got)
+	}
+}
+
+// TestCheckInstanceCapabilities 测试实例配置与节点能力的匹配校验
+func TestCheckInstanceCapabilities(t *testing.T) {
+	caps := &Capabilities{
+		SupportsContainer: true,
+		Architectures:     []string{"amd64"},
+	}
+
+	tests := []struct {
+		name         string
+		instanceType string
+		arch         string
+		needIPv6     bool
+		expectError  bool
+	}{
+		{name: "容器", instanceType: "container", arch: "x86_64"},
+		{name: "未指定架构", instanceType: "container"},
+		{name: "不支持虚拟机", instanceType: "vm", expectError: true},
+		{name: "架构不匹配", instanceType: "container", arch: "arm64", expectError: true},
+		{name: "不支持IPv6", instanceType: "container", needIPv6: true, expectError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckInstanceCapabilities(caps, tt.instanceType, tt.arch, tt.needIPv6)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("期望错误=%v，实际 %v", tt.expectError, err)
+			}
+		})
+	}
+
+	if err := CheckInstanceCapabilities(caps, "container", "", true); !errors.Is(err, ErrIPv6Unavailable) {
+		t.Fatalf("缺少IPv6时应返回ErrIPv6Unavailable，实际 %v", err)
+	}
+	if err := CheckInstanceCapabilities(nil, "vm", "arm64", true); err != nil {
+		t.Fatalf("能力信息为空时不应校验: %v", err)
+	}
+}
+
+// TestCapabilitiesCache 测试能力缓存只探测一次且探测失败时不缓存
+func TestCapabilitiesCache(t *testing.T) {
+	var cache CapabilitiesCache
+	probes := 0
+	probe := func() (*Capabilities, error) {
+		probes++
+		return &Capabilities{SupportsContainer: true, Architectures: []string{"amd64"}}, nil
+	}
+
+	first, err := cache.Get(probe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first.Architectures[0] = "modified"
+	second, _ := cache.Get(probe)
+	if probes != 1 {
+		t.Fatalf("缓存有效期内应只探测一次，实际 %d 次", probes)
+	}
+	if second.Architectures[0] != "amd64" {
+		t.Fatal("修改返回值不应影响缓存")
+	}
+	if second.ProbedAt.IsZero() {
+		t.Fatal("应记录探测时间")
+	}
+
+	if peeked := cache.Peek(); peeked == nil || probes != 1 {
+		t.Fatal("Peek应返回缓存的能力信息且不触发探测")
+	}
+
+	cache.Invalidate()
+	if cache.Peek() != nil {
+		t.Fatal("失效后Peek应返回nil")
+	}
+	if _, err := cache.Get(func() (*Capabilities, error) { return nil, errors.New("probe failed") }); err == nil {
+		t.Fatal("探测失败应返回错误")
+	}
+	cache.Get(probe)
+	if probes != 2 {
+		t.Fatalf("失效后应重新探测，实际探测 %d 次", probes)
+	}
+}
+
+// TestIPv6UnavailableMessage 测试缺少IPv6时按网络类型生成提示并附带可选节点
+func TestIPv6UnavailableMessage(t *testing.T) {
+	tests := []struct {
+		networkType string
+		compatible  []string
+		expect      string
+	}{
+		{"ipv6_only", nil, "该节点没有可用的IPv6网段，无法创建纯IPv6实例，当前没有其他可用的IPv6节点"},
+		{"nat_ipv4_ipv6", []string{"hk-1", "jp-2"}, "该节点没有可用的IPv6网段，无法创建IPv4/IPv6双栈实例，可改选以下支持IPv6的节点：hk-1、jp-2"},
+		{"nat_ipv4", []string{"hk-1"}, "该节点未配置IPv6环境，无法创建带IPv6的实例，可改选以下支持IPv6的节点：hk-1"},
+	}
+	for _, tt := range tests {
+		if got := IPv6UnavailableMessage(tt.networkType, tt.compatible); got != tt.expect {
+			t.Fatalf("IPv6UnavailableMessage(%q) = %q，期望 %q", tt.networkType, got, tt.expect)
+		}
+	}
+}
+
+// TestVMUnavailableReason 测试节点无法创建虚拟机的原因
+func TestVMUnavailableReason(t *testing.T) {
+	if reason := VMUnavailableReason(false, nil); reason == "" {
+		t.Fatalf("未开放虚拟机时应返回原因")
+	}
+	if reason := VMUnavailableReason(true, nil); reason != "" {
+		t.Fatalf("未探测能力时不应拒绝: %s", reason)
+	}
+	if reason := VMUnavailableReason(true, &Capabilities{SupportsContainer: true}); reason != VMUnsupportedMessage {
+		t.Fatalf("宿主机不支持KVM时原因错误: %s", reason)
+	}
+	if reason := VMUnavailableReason(true, &Capabilities{SupportsVM: true}); reason != "" {
+		t.Fatalf("支持虚拟机时不应返回原因: %s", reason)
+	}
+}
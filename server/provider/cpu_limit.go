@@ -0,0 +1,132 @@
+package provider
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ParseCPULimit 解析实例的CPU参数，返回可直接写入LXD/Incus limits.cpu 的值
+// 支持两种格式：
+//   - 核心数，如 "2"，由内核调度到任意核心
+//   - 核心绑定集合，如 "0-3"、"1,3"、"0-1,4"，固定绑定到指定核心
+//
+// 百分比（"50%"）和时间片（"25ms/100ms"）属于 limits.cpu.allowance 的格式，不能作为核心数使用。
+// nodeCores大于0时校验核心数不超过节点总核心数、绑定的核心编号在节点范围内
+func ParseCPULimit(value string, nodeCores int) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", nil
+	}
+	if strings.HasSuffix(value, "%") || strings.Contains(value, "/") {
+		return "", fmt.Errorf("CPU参数 %s 是CPU限额格式，核心数请使用整数或核心范围", value)
+	}
+
+	if !strings.ContainsAny(value, "-,") {
+		count, err := strconv.Atoi(value)
+		if err != nil || count < 1 {
+			return "", fmt.Errorf("无效的CPU核心数: %s", value)
+		}
+		if nodeCores > 0 && count > nodeCores {
+			return "", fmt.Errorf("CPU核心数 %d 超过节点总核心数 %d", count, nodeCores)
+		}
+		return strconv.Itoa(count), nil
+	}
+
+	cores := make(map[int]bool)
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			return "", fmt.Errorf("无效的CPU核心范围: %s", value)
+		}
+		start, end := part, part
+		if idx := strings.Index(part, "-"); idx >= 0 {
+			start, end = part[:idx], part[idx+1:]
+		}
+		from, err1 := strconv.Atoi(strings.TrimSpace(start))
+		to, err2 := strconv.Atoi(strings.TrimSpace(end))
+		if err1 != nil || err2 != nil || from < 0 || to < from {
+			return "", fmt.Errorf("无效的CPU核心范围: %s", part)
+		}
+		if nodeCores > 0 && to >= nodeCores {
+			return "", fmt.Errorf("CPU核心编号 %d 超出节点核心范围 0-%d", to, nodeCores-1)
+		}
+		for c := from; c <= to; c++ {
+			cores[c] = true
+		}
+	}
+
+	return formatCPUSet(cores), nil
+}
+
+// 容器 limits.cpu.allowance 的生成方式，未指定CPU限额时按实例核心数生成
+const (
+	CPUAllowanceModePercent = "percent" // N核对应 N*100%，按比例分配CPU时间的软限制（默认）
+	CPUAllowanceModeTime    = "time"    // N核对应 N*100ms/100ms，按时间片限制的硬限制
+	CPUAllowanceModeNone    = "none"    // 不设置 limits.cpu.allowance，仅由 limits.cpu 限制核心数
+)
+
+// CPUAllowance 根据实例的CPU参数生成容器的 limits.cpu.allowance 值
+// explicit为节点配置的CPU限额（如 "50%"、"25ms/100ms"），不为空且不为 "100%" 时直接使用；
+// 否则按mode由核心数生成，核心绑定集合按绑定的核心数量计算，返回空字符串表示不设置
+func CPUAllowance(cpu, explicit, mode string) string {
+	explicit = strings.TrimSpace(explicit)
+	if explicit != "" && explicit != "100%" {
+		return explicit
+	}
+	if mode == CPUAllowanceModeNone {
+		return ""
+	}
+
+	limit, err := ParseCPULimit(cpu, 0)
+	if err != nil || limit == "" {
+		return ""
+	}
+	cores, err := strconv.Atoi(limit)
+	if err != nil {
+		cores = countCPUSet(limit)
+	}
+
+	if mode == CPUAllowanceModeTime {
+		return fmt.Sprintf("%dms/100ms", cores*100)
+	}
+	return fmt.Sprintf("%d%%", cores*100)
+}
+
+// ContainerCPUAllowance 返回LXD/Incus容器的 limits.cpu.allowance 值，返回空字符串表示不设置
+func ContainerCPUAllowance(config InstanceConfig, mode string) string {
+	explicit := ""
+	if config.CPUAllowance != nil {
+		explicit = *config.CPUAllowance
+	}
+	return CPUAllowance(config.CPU, explicit, mode)
+}
+
+// formatCPUSet 将核心集合格式化为紧凑的范围表示，如 0-2,5
+func formatCPUSet(cores map[int]bool) string {
+	list := make([]int, 0, len(cores))
+	for c := range cores {
+		list = append(list, c)
+	}
+	sort.Ints(list)
+
+	var parts []string
+	for i := 0; i < len(list); {
+		j := i
+		for j+1 < len(list) && list[j+1] == list[j]+1 {
+			j++
+		}
+		if i == j {
+			parts = append(parts, strconv.Itoa(list[i]))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d-%d", list[i], list[j]))
+		}
+		i = j + 1
+	}
+	// 单个核心写成范围形式，避免被LXD/Incus当作核心数解析
+	if len(list) == 1 {
+		return fmt.Sprintf("%d-%d", list[0], list[0])
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,86 @@
+package provider
+
+import "testing"
+
+// TestParseCPULimit 测试核心数、核心绑定范围与CPU限额格式的区分
+func TestParseCPULimit(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		nodeCores   int
+		expected    string
+		expectError bool
+	}{
+		{name: "空值不设置", value: "", nodeCores: 4, expected: ""},
+		{name: "核心数", value: "2", nodeCores: 4, expected: "2"},
+		{name: "核心数等于节点核心数", value: "4", nodeCores: 4, expected: "4"},
+		{name: "核心数超过节点核心数", value: "8", nodeCores: 4, expectError: true},
+		{name: "未知节点核心数时不校验上限", value: "8", nodeCores: 0, expected: "8"},
+		{name: "核心数为0", value: "0", nodeCores: 4, expectError: true},
+		{name: "负数核心数", value: "-1", nodeCores: 4, expectError: true},
+		{name: "核心范围", value: "0-3", nodeCores: 4, expected: "0-3"},
+		{name: "核心列表", value: "1,3", nodeCores: 4, expected: "1,3"},
+		{name: "混合范围并合并相邻核心", value: "2,0-1,5", nodeCores: 8, expected: "0-2,5"},
+		{name: "单个核心绑定", value: "2-2", nodeCores: 4, expected: "2-2"},
+		{name: "单个核心列表", value: "3,", nodeCores: 4, expectError: true},
+		{name: "核心编号超出节点范围", value: "2-4", nodeCores: 4, expectError: true},
+		{name: "范围倒置", value: "3-1", nodeCores: 4, expectError: true},
+		{name: "百分比限额", value: "50%", nodeCores: 4, expectError: true},
+		{name: "时间片限额", value: "25ms/100ms", nodeCores: 4, expectError: true},
+		{name: "非数字", value: "two", nodeCores: 4, expectError: true},
+		{name: "带空白", value: " 2 ", nodeCores: 4, expected: "2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCPULimit(tt.value, tt.nodeCores)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("ParseCPULimit(%q, %d) 期望返回错误，实际返回 %q", tt.value, tt.nodeCores, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseCPULimit(%q, %d) 返回错误: %v", tt.value, tt.nodeCores, err)
+			}
+			if got != tt.expected {
+				t.Errorf("ParseCPULimit(%q, %d) = %q, 期望 %q", tt.value, tt.nodeCores, got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestCPUAllowance 测试由实例CPU参数生成 limits.cpu.allowance 的结果
+func TestCPUAllowance(t *testing.T) {
+	tests := []struct {
+		name     string
+		cpu      string
+		explicit string
+		mode     string
+		expected string
+	}{
+		{name: "默认按百分比", cpu: "1", mode: "", expected: "100%"},
+		{name: "百分比多核", cpu: "2", mode: CPUAllowanceModePercent, expected: "200%"},
+		{name: "时间片单核", cpu: "1", mode: CPUAllowanceModeTime, expected: "100ms/100ms"},
+		{name: "时间片多核", cpu: "4", mode: CPUAllowanceModeTime, expected: "400ms/100ms"},
+		{name: "核心绑定范围按核心数量计算", cpu: "0-3", mode: CPUAllowanceModePercent, expected: "400%"},
+		{name: "核心绑定列表按核心数量计算", cpu: "1,3", mode: CPUAllowanceModeTime, expected: "200ms/100ms"},
+		{name: "不设置限额", cpu: "2", mode: CPUAllowanceModeNone, expected: ""},
+		{name: "未知模式按百分比", cpu: "2", mode: "share", expected: "200%"},
+		{name: "节点指定限额优先", cpu: "2", explicit: "50%", mode: CPUAllowanceModeTime, expected: "50%"},
+		{name: "节点指定时间片限额优先", cpu: "2", explicit: "25ms/100ms", mode: CPUAllowanceModePercent, expected: "25ms/100ms"},
+		{name: "节点指定限额优先于none", cpu: "2", explicit: "50%", mode: CPUAllowanceModeNone, expected: "50%"},
+		{name: "节点限额为100%时按核心数生成", cpu: "2", explicit: "100%", mode: CPUAllowanceModePercent, expected: "200%"},
+		{name: "未指定CPU", cpu: "", mode: CPUAllowanceModePercent, expected: ""},
+		{name: "无效CPU", cpu: "two", mode: CPUAllowanceModePercent, expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CPUAllowance(tt.cpu, tt.explicit, tt.mode)
+			if got != tt.expected {
+				t.Errorf("CPUAllowance(%q, %q, %q) = %q, 期望 %q", tt.cpu, tt.explicit, tt.mode, got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultLimits Provider级别的实例默认资源限制，0表示未设置
+type DefaultLimits struct {
+	CPU      int   // CPU核心数
+	MemoryMB int64 // 内存大小（MB）
+	DiskMB   int64 // 磁盘大小（MB）
+}
+
+// IsZero 是否未设置任何默认限制
+func (d DefaultLimits) IsZero() bool {
+	return d.CPU == 0 && d.MemoryMB == 0 && d.DiskMB == 0
+}
+
+// ValidateDefaultLimits 校验默认限制不为负数且不超过节点容量，节点容量为0（尚未同步）时跳过容量检查
+func ValidateDefaultLimits(limits DefaultLimits, nodeCPUCores int, nodeMemoryMB, nodeDiskMB int64) error {
+	for _, item := range []struct {
+		name     string
+		value    int64
+		capacity int64
+		unit     string
+	}{
+		{"CPU", int64(limits.CPU), int64(nodeCPUCores), "核"},
+		{"内存", limits.MemoryMB, nodeMemoryMB, "MB"},
+		{"磁盘", limits.DiskMB, nodeDiskMB, "MB"},
+	} {
+		if item.value < 0 {
+			return fmt.Errorf("默认%s限制不能为负数", item.name)
+		}
+		if item.capacity > 0 && item.value > item.capacity {
+			return fmt.Errorf("默认%s限制 %d%s 超过节点容量 %d%s", item.name, item.value, item.unit, item.capacity, item.unit)
+		}
+	}
+	return nil
+}
+
+// ApplyDefaultLimits 用Provider默认限制补齐实例配置中未指定的CPU、内存和磁盘，已指定的值保持不变
+func ApplyDefaultLimits(config *InstanceConfig, limits DefaultLimits) {
+	if strings.TrimSpace(config.CPU) == "" && limits.CPU > 0 {
+		config.CPU = fmt.Sprintf("%d", limits.CPU)
+	}
+	if strings.TrimSpace(config.Memory) == "" && limits.MemoryMB > 0 {
+		config.Memory = fmt.Sprintf("%dm", limits.MemoryMB)
+	}
+	if strings.TrimSpace(config.Disk) == "" && limits.DiskMB > 0 {
+		config.Disk = fmt.Sprintf("%dm", limits.DiskMB)
+	}
+}
@@ -0,0 +1,38 @@
+package provider
+
+import "testing"
+
+// TestValidateDefaultLimits 测试默认限制不能超过节点容量
+func TestValidateDefaultLimits(t *testing.T) {
+	limits := DefaultLimits{CPU: 2, MemoryMB: 1024, DiskMB: 10240}
+	if err := ValidateDefaultLimits(limits, 4, 8192, 102400); err != nil {
+		t.Fatalf("容量内的默认限制不应报错: %v", err)
+	}
+	if err := ValidateDefaultLimits(limits, 0, 0, 0); err != nil {
+		t.Fatalf("节点容量未同步时应跳过容量检查: %v", err)
+	}
+	if err := ValidateDefaultLimits(DefaultLimits{CPU: 8}, 4, 8192, 102400); err == nil {
+		t.Fatal("默认CPU超过节点核心数时应报错")
+	}
+	if err := ValidateDefaultLimits(DefaultLimits{DiskMB: 204800}, 4, 8192, 102400); err == nil {
+		t.Fatal("默认磁盘超过节点容量时应报错")
+	}
+	if err := ValidateDefaultLimits(DefaultLimits{MemoryMB: -1}, 0, 0, 0); err == nil {
+		t.Fatal("负数默认限制应报错")
+	}
+}
+
+// TestApplyDefaultLimits 测试只补齐未指定的资源配置
+func TestApplyDefaultLimits(t *testing.T) {
+	config := InstanceConfig{Memory: "2048m"}
+	ApplyDefaultLimits(&config, DefaultLimits{CPU: 1, MemoryMB: 512, DiskMB: 10240})
+	if config.CPU != "1" || config.Memory != "2048m" || config.Disk != "10240m" {
+		t.Fatalf("默认限制补齐结果错误: cpu=%s memory=%s disk=%s", config.CPU, config.Memory, config.Disk)
+	}
+
+	config = InstanceConfig{}
+	ApplyDefaultLimits(&config, DefaultLimits{})
+	if config.CPU != "" || config.Memory != "" || config.Disk != "" {
+		t.Fatal("未设置默认限制时不应修改配置")
+	}
+}
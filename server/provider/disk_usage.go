@@ -0,0 +1,56 @@
+package provider
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DiskUsageScript 在实例内以字节为单位输出根文件系统df数据行的脚本
+const DiskUsageScript = "df -PB1 / | tail -n1"
+
+// ParseDiskUsage 解析DiskUsageScript的输出
+func ParseDiskUsage(output string) (*DiskUsage, error) {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	used, total, ok := parseDfLine(strings.TrimSpace(lines[len(lines)-1]))
+	if !ok {
+		return nil, fmt.Errorf("无法解析磁盘使用量输出: %s", strings.TrimSpace(output))
+	}
+	return &DiskUsage{UsedBytes: used, TotalBytes: total}, nil
+}
+
+// byteSizeUnits 容量单位，B结尾的十进制单位按1000换算，iB结尾的二进制单位按1024换算
+var byteSizeUnits = []struct {
+	suffix     string
+	multiplier int64
+}{
+	{"KiB", 1 << 10},
+	{"MiB", 1 << 20},
+	{"GiB", 1 << 30},
+	{"TiB", 1 << 40},
+	{"kB", 1000},
+	{"KB", 1000},
+	{"MB", 1000 * 1000},
+	{"GB", 1000 * 1000 * 1000},
+	{"TB", 1000 * 1000 * 1000 * 1000},
+	{"B", 1},
+}
+
+// ParseByteSize 解析LXD/Incus磁盘设备的size配置（如 10GB、10GiB、10737418240）为字节数
+func ParseByteSize(size string) (int64, error) {
+	size = strings.TrimSpace(size)
+	multiplier := int64(1)
+	number := size
+	for _, unit := range byteSizeUnits {
+		if strings.HasSuffix(size, unit.suffix) {
+			number = strings.TrimSpace(strings.TrimSuffix(size, unit.suffix))
+			multiplier = unit.multiplier
+			break
+		}
+	}
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("无效的容量: %q", size)
+	}
+	return int64(value * float64(multiplier)), nil
+}
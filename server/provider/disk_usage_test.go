@@ -0,0 +1,45 @@
+package provider
+
+import "testing"
+
+// TestParseDiskUsage 测试解析以字节为单位的df输出
+func TestParseDiskUsage(t *testing.T) {
+	usage, err := ParseDiskUsage("Filesystem 1-blocks Used Available Capacity Mounted on\n/dev/sda1 10737418240 2147483648 8589934592 20% /\n")
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	if usage.UsedBytes != 2147483648 || usage.TotalBytes != 10737418240 {
+		t.Fatalf("解析结果错误: %+v", usage)
+	}
+
+	if _, err := ParseDiskUsage("df: /: No such file or directory"); err == nil {
+		t.Fatalf("期望解析失败")
+	}
+}
+
+// TestParseByteSize 测试LXD/Incus容量配置的单位换算
+func TestParseByteSize(t *testing.T) {
+	cases := map[string]int64{
+		"10GB":        10 * 1000 * 1000 * 1000,
+		"10GiB":       10 << 30,
+		"512MiB":      512 << 20,
+		"1.5TB":       1500 * 1000 * 1000 * 1000,
+		"10737418240": 10737418240,
+		"100B":        100,
+	}
+	for input, expected := range cases {
+		got, err := ParseByteSize(input)
+		if err != nil {
+			t.Fatalf("解析 %q 失败: %v", input, err)
+		}
+		if got != expected {
+			t.Fatalf("解析 %q 结果错误: %d", input, got)
+		}
+	}
+
+	for _, input := range []string{"", "GB", "ten", "-1GB"} {
+		if _, err := ParseByteSize(input); err == nil {
+			t.Fatalf("期望解析失败: %q", input)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package provider
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// MaxDNSServers 单个实例允许配置的DNS服务器数量上限，与resolv.conf生效的nameserver数量一致
+const MaxDNSServers = 3
+
+// ValidateDNSServers 校验自定义DNS服务器必须是合法的IPv4或IPv6地址，返回规范化并去重后的列表
+func ValidateDNSServers(servers []string) ([]string, error) {
+	if len(servers) > MaxDNSServers {
+		return nil, fmt.Errorf("DNS服务器数量不能超过%d个", MaxDNSServers)
+	}
+	normalized := make([]string, 0, len(servers))
+	seen := make(map[string]bool)
+	for _, server := range servers {
+		ip := net.ParseIP(strings.TrimSpace(server))
+		if ip == nil {
+			return nil, fmt.Errorf("DNS服务器地址无效: %s", server)
+		}
+		if ip.IsUnspecified() || ip.IsMulticast() {
+			return nil, fmt.Errorf("DNS服务器地址不可用: %s", server)
+		}
+		addr := ip.String()
+		if seen[addr] {
+			continue
+		}
+		seen[addr] = true
+		normalized = append(normalized, addr)
+	}
+	return normalized, nil
+}
+
+// ResolvConfCommand 构建在实例内写入/etc/resolv.conf的命令，servers必须已通过ValidateDNSServers校验
+// 先删除文件以解除指向systemd-resolved等的符号链接，避免写入的配置被覆盖
+func ResolvConfCommand(servers []string) string {
+	var content strings.Builder
+	for _, server := range servers {
+		content.WriteString("nameserver " + server + `\n`)
+	}
+	return fmt.Sprintf("rm -f /etc/resolv.conf && printf '%s' > /etc/resolv.conf", content.String())
+}
@@ -0,0 +1,38 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestValidateDNSServers 测试DNS服务器地址校验、规范化和去重
+func TestValidateDNSServers(t *testing.T) {
+	servers, err := ValidateDNSServers([]string{" 1.1.1.1", "2001:4860:4860:0::8888", "1.1.1.1"})
+	if err != nil {
+		t.Fatalf("合法地址不应报错: %v", err)
+	}
+	if !reflect.DeepEqual(servers, []string{"1.1.1.1", "2001:4860:4860::8888"}) {
+		t.Fatalf("规范化结果错误: %v", servers)
+	}
+
+	invalid := [][]string{
+		{"dns.google"},
+		{"1.1.1.1; reboot"},
+		{"0.0.0.0"},
+		{"1.1.1.1", "8.8.8.8", "8.8.4.4", "9.9.9.9"},
+	}
+	for _, input := range invalid {
+		if _, err := ValidateDNSServers(input); err == nil {
+			t.Fatalf("期望校验失败: %v", input)
+		}
+	}
+}
+
+// TestResolvConfCommand 测试生成的resolv.conf写入命令
+func TestResolvConfCommand(t *testing.T) {
+	cmd := ResolvConfCommand([]string{"1.1.1.1", "2606:4700:4700::1111"})
+	expected := `rm -f /etc/resolv.conf && printf 'nameserver 1.1.1.1\nnameserver 2606:4700:4700::1111\n' > /etc/resolv.conf`
+	if cmd != expected {
+		t.Fatalf("命令错误: %s", cmd)
+	}
+}
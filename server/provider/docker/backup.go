@@ -0,0 +1,365 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"oneclickvirt/global"
+	"oneclickvirt/provider"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+)
+
+// BackupInstance 使用 export 导出容器文件系统并流式写入dest，宿主机上的临时文件在结束后删除
+// 导出内容不包含镜像层之外的卷数据和容器配置（资源限制、端口映射由数据库记录）
+func (d *DockerProvider) BackupInstance(ctx context.Context, instanceName string, dest io.Writer) (*provider.BackupArchive, error) {
+	if !d.connected || d.sshClient == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	if _, err := d.sshClient.Execute(d.cliCommand("inspect %s >/dev/null 2>&1", instanceName)); err != nil {
+		return nil, fmt.Errorf("容器 %s 不存在", instanceName)
+	}
+
+	dir, err := provider.PrepareBackupWorkDir(d.sshClient, instanceName)
+	if err != nil {
+		return nil, err
+	}
+	defer provider.RemoveBackupWorkDir(d.sshClient, dir)
+
+	archivePath := fmt.Sprintf("%s/%s.tar", dir, instanceName)
+	if err := provider.RunBackupCommand(d.sshClient, d.cliCommand("export -o %s %s", archivePath, instanceName)); err != nil {
+		return nil, fmt.Errorf("导出容器失败: %w", err)
+	}
+
+	archive, err := provider.SendBackupArchive(d.sshClient, archivePath, dest)
+	if err != nil {
+		return nil, err
+	}
+	global.APP_LOG.Info("容器备份导出完成",
+		zap.String("instance", instanceName),
+		zap.Int64("size", archive.Size))
+	return archive, nil
+}
+
+// RestoreFromBackup 用备份的文件系统重建容器
+// 归档先 import 为镜像，再按原容器的运行配置（资源限制、磁盘大小、网络、DNS、端口、挂载、环境变量、标签、启动命令）创建新容器，
+// 使容器文件系统与备份完全一致；原容器先停止并改名保留，新容器创建失败时恢复原容器，成功后才删除。
+// 数据卷不在导出内容中，新容器继续挂载原容器的数据卷和宿主机目录，其内容保持恢复前的状态。
+// 新容器的veth是新建的，限速和防火墙规则由调用方按数据库记录重新应用
+func (d *DockerProvider) RestoreFromBackup(ctx context.Context, instanceName, fileName string, src io.Reader) error {
+	if !d.connected || d.sshClient == nil {
+		return fmt.Errorf("not connected")
+	}
+	statusOutput, err := d.sshClient.Execute(d.cliCommand("inspect %s --format '{{.State.Status}}'", instanceName))
+	if err != nil {
+		return fmt.Errorf("容器 %s 不存在", instanceName)
+	}
+	wasRunning := strings.Contains(strings.ToLower(statusOutput), "running")
+
+	inspectOutput, err := d.sshClient.Execute(d.cliCommand("inspect --format '{{json .}}' %s", instanceName))
+	if err != nil {
+		return fmt.Errorf("读取容器配置失败: %w", err)
+	}
+	spec, err := parseRestoreSpec(inspectOutput)
+	if err != nil {
+		return err
+	}
+
+	dir, err := provider.PrepareBackupWorkDir(d.sshClient, instanceName)
+	if err != nil {
+		return err
+	}
+	defer provider.RemoveBackupWorkDir(d.sshClient, dir)
+
+	archivePath, err := provider.ReceiveBackupArchive(d.sshClient, dir, fileName, src)
+	if err != nil {
+		return err
+	}
+
+	suffix := time.Now().Unix()
+	restoreImage := fmt.Sprintf("oneclickvirt-restore/%s:%d", strings.ToLower(instanceName), suffix)
+	importCmd := d.cliCommand("import")
+	for _, change := range spec.importChanges() {
+		importCmd += " --change " + utils.ShellQuote(change)
+	}
+	importCmd += fmt.Sprintf(" %s %s", archivePath, restoreImage)
+	if err := provider.RunBackupCommand(d.sshClient, importCmd); err != nil {
+		return fmt.Errorf("导入备份镜像失败: %w", err)
+	}
+
+	if wasRunning {
+		if output, err := d.sshClient.Execute(d.cliCommand("stop %s", instanceName)); err != nil {
+			d.sshClient.Execute(d.cliCommand("rmi %s", restoreImage))
+			return fmt.Errorf("停止容器失败: %s: %w", strings.TrimSpace(output), err)
+		}
+	}
+	previousName := fmt.Sprintf("%s-pre-restore-%d", instanceName, suffix)
+	if output, err := d.sshClient.Execute(d.cliCommand("rename %s %s", instanceName, previousName)); err != nil {
+		if wasRunning {
+			d.sshClient.Execute(d.cliCommand("start %s", instanceName))
+		}
+		d.sshClient.Execute(d.cliCommand("rmi %s", restoreImage))
+		return fmt.Errorf("重命名现有容器失败: %s: %w", strings.TrimSpace(output), err)
+	}
+
+	runCmd := d.cliCommand("run -d --name %s", instanceName) + spec.runArgs() + " " + restoreImage
+	global.APP_LOG.Info("开始从备份重建容器",
+		zap.String("instance", utils.TruncateString(instanceName, 32)),
+		zap.String("previous", previousName),
+		zap.String("command", utils.TruncateString(runCmd, 200)))
+
+	if output, err := d.sshClient.Execute(runCmd); err != nil {
+		d.sshClient.Execute(d.cliCommand("rm -f %s", instanceName))
+		if renameOutput, renameErr := d.sshClient.Execute(d.cliCommand("rename %s %s", previousName, instanceName)); renameErr != nil {
+			global.APP_LOG.Error("恢复失败后还原原容器失败",
+				zap.String("instance", utils.TruncateString(instanceName, 32)),
+				zap.String("previous", previousName),
+				zap.String("output", utils.TruncateString(renameOutput, 200)),
+				zap.Error(renameErr))
+			return fmt.Errorf("从备份创建容器失败: %s: %w，且还原原容器失败，原容器保留为 %s", utils.TruncateString(output, 500), err, previousName)
+		}
+		if wasRunning {
+			d.sshClient.Execute(d.cliCommand("start %s", instanceName))
+		}
+		d.sshClient.Execute(d.cliCommand("rmi %s", restoreImage))
+		return fmt.Errorf("从备份创建容器失败，已还原原容器: %s: %w", utils.TruncateString(output, 500), err)
+	}
+
+	for _, network := range spec.extraNetworks() {
+		if output, err := d.sshClient.Execute(d.cliCommand("network connect %s %s", network, instanceName)); err != nil {
+			global.APP_LOG.Warn("恢复后连接附加网络失败",
+				zap.String("instance", instanceName),
+				zap.String("network", network),
+				zap.String("output", strings.TrimSpace(output)),
+				zap.Error(err))
+		}
+	}
+	if !wasRunning {
+		d.sshClient.Execute(d.cliCommand("stop %s", instanceName))
+	}
+
+	// 不带-v删除，数据卷已由新容器使用
+	if output, err := d.sshClient.Execute(d.cliCommand("rm -f %s", previousName)); err != nil {
+		global.APP_LOG.Warn("删除恢复前的原容器失败",
+			zap.String("previous", previousName),
+			zap.String("output", strings.TrimSpace(output)),
+			zap.Error(err))
+	}
+
+	global.APP_LOG.Info("容器备份恢复完成",
+		zap.String("instance", instanceName),
+		zap.String("image", restoreImage))
+	return nil
+}
+
+// restoreSpec 从备份重建容器时沿用的原容器配置，字段为 docker/podman inspect 输出的子集
+type restoreSpec struct {
+	Config struct {
+		Hostname   string            `json:"Hostname"`
+		Env        []string          `json:"Env"`
+		Cmd        json.RawMessage   `json:"Cmd"`
+		Entrypoint json.RawMessage   `json:"Entrypoint"` // Docker为数组，旧版Podman为字符串
+		WorkingDir string            `json:"WorkingDir"`
+		User       string            `json:"User"`
+		Labels     map[string]string `json:"Labels"`
+	} `json:"Config"`
+	HostConfig struct {
+		NanoCpus     int64             `json:"NanoCpus"`
+		Memory       int64             `json:"Memory"`
+		MemorySwap   int64             `json:"MemorySwap"`
+		StorageOpt   map[string]string `json:"StorageOpt"`
+		DNS          []string          `json:"Dns"`
+		NetworkMode  string            `json:"NetworkMode"`
+		PortBindings map[string][]struct {
+			HostIP   string `json:"HostIp"`
+			HostPort string `json:"HostPort"`
+		} `json:"PortBindings"`
+		CapAdd        []string `json:"CapAdd"`
+		RestartPolicy struct {
+			Name string `json:"Name"`
+		} `json:"RestartPolicy"`
+	} `json:"HostConfig"`
+	Mounts []struct {
+		Type        string `json:"Type"`
+		Name        string `json:"Name"`
+		Source      string `json:"Source"`
+		Destination string `json:"Destination"`
+		RW          bool   `json:"RW"`
+	} `json:"Mounts"`
+	NetworkSettings struct {
+		Networks map[string]json.RawMessage `json:"Networks"`
+	} `json:"NetworkSettings"`
+}
+
+// parseRestoreSpec 解析 inspect --format '{{json .}}' 的输出
+func parseRestoreSpec(output string) (*restoreSpec, error) {
+	var spec restoreSpec
+	if err := json.Unmarshal([]byte(strings.TrimSpace(output)), &spec); err != nil {
+		return nil, fmt.Errorf("解析容器配置失败: %w", err)
+	}
+	return &spec, nil
+}
+
+// importChanges import 时写入镜像的启动配置，export 出的归档不包含这些信息
+func (s *restoreSpec) importChanges() []string {
+	var changes []string
+	if entrypoint := inspectStringList(s.Config.Entrypoint); len(entrypoint) > 0 {
+		data, _ := json.Marshal(entrypoint)
+		changes = append(changes, "ENTRYPOINT "+string(data))
+	}
+	if cmd := inspectStringList(s.Config.Cmd); len(cmd) > 0 {
+		data, _ := json.Marshal(cmd)
+		changes = append(changes, "CMD "+string(data))
+	}
+	if s.Config.WorkingDir != "" {
+		changes = append(changes, "WORKDIR "+s.Config.WorkingDir)
+	}
+	if s.Config.User != "" {
+		changes = append(changes, "USER "+s.Config.User)
+	}
+	return changes
+}
+
+// runArgs 按原容器配置生成 run 参数（不含容器名和镜像）
+func (s *restoreSpec) runArgs() string {
+	var args strings.Builder
+	if s.Config.Hostname != "" {
+		args.WriteString(" --hostname " + utils.ShellQuote(s.Config.Hostname))
+	}
+	s.writeLimitArgs(&args)
+	s.writeNetworkArgs(&args)
+
+	ports := make([]string, 0, len(s.HostConfig.PortBindings))
+	for port := range s.HostConfig.PortBindings {
+		ports = append(ports, port)
+	}
+	sort.Strings(ports)
+	for _, port := range ports {
+		for _, binding := range s.HostConfig.PortBindings[port] {
+			if binding.HostPort == "" {
+				continue
+			}
+			if hostIP := binding.HostIP; hostIP != "" {
+				if strings.Contains(hostIP, ":") {
+					hostIP = "[" + hostIP + "]"
+				}
+				args.WriteString(fmt.Sprintf(" -p %s:%s:%s", hostIP, binding.HostPort, port))
+			} else {
+				args.WriteString(fmt.Sprintf(" -p %s:%s", binding.HostPort, port))
+			}
+		}
+	}
+
+	for _, mount := range s.Mounts {
+		source := mount.Source
+		switch mount.Type {
+		case "volume":
+			source = mount.Name
+		case "bind":
+		default:
+			continue
+		}
+		if source == "" || mount.Destination == "" {
+			continue
+		}
+		args.WriteString(" " + provider.DockerVolumeArg(provider.VolumeMount{
+			Source:   source,
+			Target:   mount.Destination,
+			ReadOnly: !mount.RW,
+		}))
+	}
+
+	s.writeDNSArgs(&args)
+
+	for _, env := range s.Config.Env {
+		args.WriteString(" -e " + utils.ShellQuote(env))
+	}
+
+	// 只沿用实例标签，Config.Labels 中还混有原镜像自带的label，不需要写到新容器上
+	labels := make([]string, 0, len(s.Config.Labels))
+	for key, value := range s.Config.Labels {
+		if strings.HasPrefix(key, dockerTagLabelPrefix) {
+			labels = append(labels, key+"="+value)
+		}
+	}
+	sort.Strings(labels)
+	for _, label := range labels {
+		args.WriteString(" --label " + utils.ShellQuote(label))
+	}
+	for _, capability := range s.HostConfig.CapAdd {
+		args.WriteString(" --cap-add=" + capability)
+	}
+	if policy := s.HostConfig.RestartPolicy.Name; policy != "" && policy != "no" {
+		args.WriteString(" --restart=" + policy)
+	}
+	return args.String()
+}
+
+// writeLimitArgs 写入CPU、内存、swap和磁盘大小限制
+func (s *restoreSpec) writeLimitArgs(args *strings.Builder) {
+	if s.HostConfig.NanoCpus > 0 {
+		args.WriteString(" --cpus=" + strconv.FormatFloat(float64(s.HostConfig.NanoCpus)/1e9, 'f', -1, 64))
+	}
+	if s.HostConfig.Memory > 0 {
+		args.WriteString(fmt.Sprintf(" --memory=%db", s.HostConfig.Memory))
+		// -1 表示不限制swap，0 表示未设置（沿用Docker默认的两倍内存）
+		if swap := s.HostConfig.MemorySwap; swap > 0 {
+			args.WriteString(fmt.Sprintf(" --memory-swap=%db", swap))
+		} else if swap < 0 {
+			args.WriteString(" --memory-swap=-1")
+		}
+	}
+	if size := s.HostConfig.StorageOpt["size"]; size != "" {
+		args.WriteString(" --storage-opt size=" + utils.ShellQuote(size))
+	}
+}
+
+// writeNetworkArgs 写入主网络，默认网络不需要指定
+func (s *restoreSpec) writeNetworkArgs(args *strings.Builder) {
+	if mode := s.HostConfig.NetworkMode; mode != "" && mode != "default" && mode != "bridge" {
+		args.WriteString(" --network=" + mode)
+	}
+}
+
+// writeDNSArgs 写入自定义DNS
+func (s *restoreSpec) writeDNSArgs(args *strings.Builder) {
+	for _, server := range s.HostConfig.DNS {
+		args.WriteString(" --dns=" + utils.ShellQuote(server))
+	}
+}
+
+// extraNetworks 主网络之外需要在容器创建后连接的网络
+func (s *restoreSpec) extraNetworks() []string {
+	mode := s.HostConfig.NetworkMode
+	defaultMode := mode == "" || mode == "default" || mode == "bridge"
+	var networks []string
+	for name := range s.NetworkSettings.Networks {
+		// Docker默认网络名为bridge，Podman为podman
+		if name == mode || (defaultMode && (name == "bridge" || name == "podman")) {
+			continue
+		}
+		networks = append(networks, name)
+	}
+	sort.Strings(networks)
+	return networks
+}
+
+// inspectStringList 解析既可能是字符串数组也可能是字符串的inspect字段
+func inspectStringList(raw json.RawMessage) []string {
+	var list []string
+	if err := json.Unmarshal(raw, &list); err == nil {
+		return list
+	}
+	var value string
+	if err := json.Unmarshal(raw, &value); err == nil {
+		return strings.Fields(value)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package docker
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestRestoreSpec 测试从inspect输出生成重建容器的import和run参数
+func TestRestoreSpec(t *testing.T) {
+	output := `{
+		"Config": {
+			"Hostname": "web-1",
+			"Env": ["PATH=/usr/bin:/bin", "GREETING=it's ok"],
+			"Cmd": ["/sbin/init"],
+			"Entrypoint": null,
+			"WorkingDir": "/root",
+			"User": "",
+			"Labels": {"oneclickvirt.tag.env": "prod", "oneclickvirt.tag.owner": "ops team", "maintainer": "debian"}
+		},
+		"HostConfig": {
+			"NanoCpus": 1500000000,
+			"Memory": 536870912,
+			"MemorySwap": 1073741824,
+			"StorageOpt": {"size": "10240m"},
+			"Dns": ["1.1.1.1", "2606:4700:4700::1111"],
+			"NetworkMode": "ocv-net",
+			"PortBindings": {
+				"80/tcp": [{"HostIp": "0.0.0.0", "HostPort": "10080"}],
+				"22/tcp": [{"HostIp": "", "HostPort": "10022"}, {"HostIp": "::", "HostPort": "10022"}],
+				"53/udp": [{"HostIp": "", "HostPort": ""}]
+			},
+			"CapAdd": ["MKNOD"],
+			"RestartPolicy": {"Name": "no"}
+		},
+		"Mounts": [
+			{"Type": "volume", "Name": "data", "Source": "/var/lib/docker/volumes/data/_data", "Destination": "/data", "RW": true},
+			{"Type": "bind", "Source": "/var/lib/lxcfs/proc/meminfo", "Destination": "/proc/meminfo", "RW": false},
+			{"Type": "tmpfs", "Destination": "/run", "RW": true}
+		],
+		"NetworkSettings": {"Networks": {"ocv-net": {}, "extra-b": {}, "extra-a": {}}}
+	}`
+
+	spec, err := parseRestoreSpec(output)
+	if err != nil {
+		t.Fatalf("parseRestoreSpec() 失败: %v", err)
+	}
+
+	expectedChanges := []string{`CMD ["/sbin/init"]`, "WORKDIR /root"}
+	if changes := spec.importChanges(); !reflect.DeepEqual(changes, expectedChanges) {
+		t.Errorf("importChanges() = %q, 期望 %q", changes, expectedChanges)
+	}
+
+	expectedArgs := " --hostname 'web-1' --cpus=1.5 --memory=536870912b --memory-swap=1073741824b" +
+		" --storage-opt size='10240m' --network=ocv-net" +
+		" -p 10022:22/tcp -p [::]:10022:22/tcp -p 0.0.0.0:10080:80/tcp" +
+		" --volume data:/data:rw --volume /var/lib/lxcfs/proc/meminfo:/proc/meminfo:ro" +
+		" --dns='1.1.1.1' --dns='2606:4700:4700::1111'" +
+		" -e 'PATH=/usr/bin:/bin' -e 'GREETING=it'\\''s ok'" +
+		" --label 'oneclickvirt.tag.env=prod' --label 'oneclickvirt.tag.owner=ops team' --cap-add=MKNOD"
+	args := spec.runArgs()
+	if args != expectedArgs {
+		t.Errorf("runArgs() =\n%s\n期望\n%s", args, expectedArgs)
+	}
+	// 重建的容器必须保留磁盘大小和swap限制，否则恢复后实例的资源限制会失效
+	for _, flag := range []string{" --storage-opt size=", " --memory-swap="} {
+		if !strings.Contains(args, flag) {
+			t.Errorf("runArgs() 缺少 %q", flag)
+		}
+	}
+
+	if networks := spec.extraNetworks(); !reflect.DeepEqual(networks, []string{"extra-a", "extra-b"}) {
+		t.Errorf("extraNetworks() = %v, 期望 [extra-a extra-b]", networks)
+	}
+}
+
+// TestRestoreSpecPodman 测试Podman的字符串Entrypoint和默认网络
+func TestRestoreSpecPodman(t *testing.T) {
+	output := `{
+		"Config": {"Entrypoint": "/bin/sh -c", "Cmd": ["sleep", "infinity"]},
+		"HostConfig": {"NetworkMode": "bridge", "RestartPolicy": {"Name": "always"}},
+		"NetworkSettings": {"Networks": {"podman": {}}}
+	}`
+
+	spec, err := parseRestoreSpec(output)
+	if err != nil {
+		t.Fatalf("parseRestoreSpec() 失败: %v", err)
+	}
+	expectedChanges := []string{`ENTRYPOINT ["/bin/sh","-c"]`, `CMD ["sleep","infinity"]`}
+	if changes := spec.importChanges(); !reflect.DeepEqual(changes, expectedChanges) {
+		t.Errorf("importChanges() = %q, 期望 %q", changes, expectedChanges)
+	}
+	if args := spec.runArgs(); args != " --restart=always" {
+		t.Errorf("runArgs() = %q, 期望 \" --restart=always\"", args)
+	}
+	if networks := spec.extraNetworks(); len(networks) != 0 {
+		t.Errorf("extraNetworks() = %v, 默认网络不应作为附加网络", networks)
+	}
+
+	if _, err := parseRestoreSpec("Error: no such container"); err == nil {
+		t.Error("parseRestoreSpec() 非JSON输出期望返回错误")
+	}
+}
@@ -0,0 +1,38 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+
+	"oneclickvirt/global"
+	"oneclickvirt/provider"
+
+	"go.uber.org/zap"
+)
+
+// SetBandwidthLimit 在容器的宿主机侧veth接口上设置tc限速，uploadMbps/downloadMbps小于等于0表示该方向不限速
+// 容器每次启动都会重建veth，tc规则随之丢失，因此启动/重启后需要重新调用
+func (d *DockerProvider) SetBandwidthLimit(ctx context.Context, instanceName string, uploadMbps, downloadMbps int) error {
+	if !d.connected || d.sshClient == nil {
+		return fmt.Errorf("not connected")
+	}
+
+	veth, err := d.GetVethInterfaceName(ctx, instanceName)
+	if err != nil {
+		return err
+	}
+	cmd, err := provider.BuildBandwidthLimitCommand(veth, uploadMbps, downloadMbps)
+	if err != nil {
+		return err
+	}
+	if output, err := d.sshClient.Execute(cmd); err != nil {
+		return fmt.Errorf("设置tc限速失败: %s: %w", output, err)
+	}
+
+	global.APP_LOG.Info("容器限速已更新",
+		zap.String("instance", instanceName),
+		zap.String("veth", veth),
+		zap.Int("uploadMbps", uploadMbps),
+		zap.Int("downloadMbps", downloadMbps))
+	return nil
+}
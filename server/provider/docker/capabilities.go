@@ -0,0 +1,51 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+
+	"oneclickvirt/global"
+	"oneclickvirt/provider"
+
+	"go.uber.org/zap"
+)
+
+// Capabilities 返回Docker节点的能力信息
+// Docker只能运行容器，硬盘限制取决于存储驱动，IPv6取决于ipv6_net网络和ndpresponder是否就绪
+func (d *DockerProvider) Capabilities(ctx context.Context) (*provider.Capabilities, error) {
+	return d.capabilities.Get(func() (*provider.Capabilities, error) {
+		if !d.connected || d.sshClient == nil {
+			return nil, fmt.Errorf("not connected")
+		}
+
+		caps := &provider.Capabilities{
+			SupportsContainer: true,
+			SupportsIPv6:      d.checkIPv6NetworkAvailable(),
+		}
+
+		supportsDiskLimit, storageDriver, err := d.checkStorageDriver()
+		if err != nil {
+			global.APP_LOG.Warn("探测存储驱动失败，按不支持硬盘限制处理",
+				zap.String("provider", d.config.Name),
+				zap.Error(err))
+		}
+		caps.SupportsDiskLimit = supportsDiskLimit
+		caps.StorageDriver = storageDriver
+
+		if host, err := provider.ProbeHost(d.sshClient); err == nil {
+			caps.Architectures = host.Architectures()
+		}
+
+		global.APP_LOG.Info("Docker节点能力探测完成",
+			zap.String("provider", d.config.Name),
+			zap.Bool("ipv6", caps.SupportsIPv6),
+			zap.Bool("diskLimit", caps.SupportsDiskLimit),
+			zap.Strings("architectures", caps.Architectures))
+		return caps, nil
+	})
+}
+
+// CachedCapabilities 返回缓存中的能力信息，不会触发SSH探测，缓存不存在或已过期时返回nil
+func (d *DockerProvider) CachedCapabilities() *provider.Capabilities {
+	return d.capabilities.Peek()
+}
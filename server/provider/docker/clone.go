@@ -0,0 +1,110 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"oneclickvirt/global"
+	"oneclickvirt/provider"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+)
+
+// CloneInstance 通过commit源容器生成镜像并以相同的资源限制、磁盘大小、网络、DNS和LXCFS挂载运行新容器
+// 端口映射不会复制（宿主机端口会冲突），由服务层为克隆实例重新分配；数据卷不共享，克隆容器只包含源容器文件系统
+func (d *DockerProvider) CloneInstance(ctx context.Context, sourceName, newName string) error {
+	if !d.connected || d.sshClient == nil {
+		return fmt.Errorf("not connected")
+	}
+	if sourceName == "" || newName == "" {
+		return fmt.Errorf("实例名称不能为空")
+	}
+
+	if _, err := d.sshClient.Execute(d.cliCommand("inspect %s >/dev/null 2>&1", sourceName)); err != nil {
+		return fmt.Errorf("源容器 %s 不存在", sourceName)
+	}
+	if _, err := d.sshClient.Execute(d.cliCommand("inspect %s >/dev/null 2>&1", newName)); err == nil {
+		return fmt.Errorf("目标容器 %s 已存在", newName)
+	}
+
+	// 读取源容器的运行配置
+	inspectOutput, err := d.sshClient.Execute(d.cliCommand("inspect --format '{{json .}}' %s", sourceName))
+	if err != nil {
+		return fmt.Errorf("读取源容器配置失败: %w", err)
+	}
+	spec, err := parseRestoreSpec(inspectOutput)
+	if err != nil {
+		return err
+	}
+
+	cloneImage := fmt.Sprintf("oneclickvirt-clone/%s:latest", strings.ToLower(newName))
+	if output, err := d.sshClient.Execute(d.cliCommand("commit %s %s", sourceName, cloneImage)); err != nil {
+		return fmt.Errorf("提交源容器镜像失败: %s: %w", strings.TrimSpace(output), err)
+	}
+
+	cmd := d.cliCommand("run -d --name %s --hostname %s", newName, newName) + spec.cloneArgs() + " " + cloneImage
+
+	global.APP_LOG.Info("开始运行克隆容器",
+		zap.String("source", utils.TruncateString(sourceName, 32)),
+		zap.String("instance", utils.TruncateString(newName, 32)),
+		zap.String("command", utils.TruncateString(cmd, 200)))
+
+	if output, err := d.sshClient.Execute(cmd); err != nil {
+		_, _ = d.sshClient.Execute(d.cliCommand("rm -f %s", newName))
+		_, _ = d.sshClient.Execute(d.cliCommand("rmi %s", cloneImage))
+		return fmt.Errorf("运行克隆容器失败: %s: %w", utils.TruncateString(output, 500), err)
+	}
+
+	// 源容器附加的网络（如IPv6网络）在创建后连接
+	for _, network := range spec.extraNetworks() {
+		if output, err := d.sshClient.Execute(d.cliCommand("network connect %s %s", network, newName)); err != nil {
+			global.APP_LOG.Warn("克隆容器连接附加网络失败",
+				zap.String("instance", newName),
+				zap.String("network", network),
+				zap.String("output", strings.TrimSpace(output)),
+				zap.Error(err))
+		}
+	}
+
+	// 克隆容器已持有镜像层，只需去掉中间镜像的标签避免残留；podman rmi -f 会连带删除使用该镜像的容器，改用untag
+	untagCmd := d.cliCommand("rmi -f %s", cloneImage)
+	if d.cliBinary() == "podman" {
+		untagCmd = d.cliCommand("untag %s", cloneImage)
+	}
+	if output, err := d.sshClient.Execute(untagCmd); err != nil {
+		global.APP_LOG.Warn("删除克隆中间镜像失败",
+			zap.String("image", cloneImage),
+			zap.String("output", strings.TrimSpace(output)),
+			zap.Error(err))
+	}
+
+	global.APP_LOG.Info("Docker容器克隆成功",
+		zap.String("source", utils.TruncateString(sourceName, 32)),
+		zap.String("instance", utils.TruncateString(newName, 32)))
+	return nil
+}
+
+// cloneArgs 按源容器配置生成克隆容器的 run 参数（不含容器名、主机名和镜像）
+// 沿用资源限制、网络、DNS、LXCFS挂载和能力；环境变量和启动命令已随commit写入镜像，端口映射和数据卷不复制
+func (s *restoreSpec) cloneArgs() string {
+	var args strings.Builder
+	s.writeLimitArgs(&args)
+	s.writeNetworkArgs(&args)
+	for _, mount := range s.Mounts {
+		if mount.Type != "bind" || !strings.HasPrefix(mount.Source, provider.LXCFSRoot) || mount.Destination == "" {
+			continue
+		}
+		args.WriteString(" " + provider.DockerVolumeArg(provider.VolumeMount{
+			Source:   mount.Source,
+			Target:   mount.Destination,
+			ReadOnly: !mount.RW,
+		}))
+	}
+	s.writeDNSArgs(&args)
+	for _, capability := range s.HostConfig.CapAdd {
+		args.WriteString(" --cap-add=" + capability)
+	}
+	return args.String()
+}
@@ -0,0 +1,39 @@
+package docker
+
+import "testing"
+
+// TestRestoreSpecCloneArgs 测试克隆容器沿用源容器的资源限制、网络和LXCFS挂载，不复制端口和数据卷
+func TestRestoreSpecCloneArgs(t *testing.T) {
+	output := `{
+		"Config": {"Env": ["PATH=/usr/bin:/bin"]},
+		"HostConfig": {
+			"NanoCpus": 1000000000,
+			"Memory": 536870912,
+			"MemorySwap": -1,
+			"StorageOpt": {"size": "5g"},
+			"Dns": ["8.8.8.8"],
+			"NetworkMode": "ipv6_net",
+			"PortBindings": {"22/tcp": [{"HostIp": "0.0.0.0", "HostPort": "10022"}]},
+			"CapAdd": ["MKNOD"]
+		},
+		"Mounts": [
+			{"Type": "volume", "Name": "data", "Destination": "/data", "RW": true},
+			{"Type": "bind", "Source": "/srv/shared", "Destination": "/shared", "RW": true},
+			{"Type": "bind", "Source": "/var/lib/lxcfs/proc/cpuinfo", "Destination": "/proc/cpuinfo", "RW": false}
+		],
+		"NetworkSettings": {"Networks": {"ipv6_net": {}}}
+	}`
+
+	spec, err := parseRestoreSpec(output)
+	if err != nil {
+		t.Fatalf("parseRestoreSpec() 失败: %v", err)
+	}
+	expected := " --cpus=1 --memory=536870912b --memory-swap=-1 --storage-opt size='5g' --network=ipv6_net" +
+		" --volume /var/lib/lxcfs/proc/cpuinfo:/proc/cpuinfo:ro --dns='8.8.8.8' --cap-add=MKNOD"
+	if args := spec.cloneArgs(); args != expected {
+		t.Errorf("cloneArgs() =\n%s\n期望\n%s", args, expected)
+	}
+	if networks := spec.extraNetworks(); len(networks) != 0 {
+		t.Errorf("extraNetworks() = %v, 主网络不应作为附加网络", networks)
+	}
+}
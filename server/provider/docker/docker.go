@@ -10,21 +10,33 @@ import (
 	"oneclickvirt/global"
 	"oneclickvirt/provider"
 	"oneclickvirt/provider/health"
+	"oneclickvirt/service/metrics"
 	"oneclickvirt/utils"
 
 	"go.uber.org/zap"
 )
 
+// dockerTagLabelPrefix 实例标签写入容器label时使用的前缀
+const dockerTagLabelPrefix = "oneclickvirt.tag."
+
 type DockerProvider struct {
+	cli           string // 容器运行时命令，docker 或 podman
 	config        provider.NodeConfig
 	sshClient     *utils.SSHClient
 	connected     bool
 	healthChecker health.HealthChecker
 	mu            sync.RWMutex // 保护并发访问
+
+	capabilities provider.CapabilitiesCache // 能力探测结果缓存
 }
 
 func NewDockerProvider() provider.Provider {
-	return &DockerProvider{}
+	return &DockerProvider{cli: "docker"}
+}
+
+// NewCompatibleProvider 创建使用指定兼容命令行（如podman）的Provider，供兼容Docker CLI的运行时复用
+func NewCompatibleProvider(cli string) *DockerProvider {
+	return &DockerProvider{cli: cli}
 }
 
 func (d *DockerProvider) GetType() string {
@@ -41,6 +53,7 @@ func (d *DockerProvider) GetSupportedInstanceTypes() []string {
 
 func (d *DockerProvider) Connect(ctx context.Context, config provider.NodeConfig) error {
 	d.config = config
+	d.capabilities.Invalidate()
 	global.APP_LOG.Info("Docker provider开始连接",
 		zap.String("host", utils.TruncateString(config.Host, 32)),
 		zap.Int("port", config.Port))
@@ -56,13 +69,19 @@ func (d *DockerProvider) Connect(ctx context.Context, config provider.NodeConfig
 	}
 
 	sshConfig := utils.SSHConfig{
-		Host:           config.Host,
-		Port:           config.Port,
-		Username:       config.Username,
-		Password:       config.Password,
-		PrivateKey:     config.PrivateKey,
-		ConnectTimeout: time.Duration(sshConnectTimeout) * time.Second,
-		ExecuteTimeout: time.Duration(sshExecuteTimeout) * time.Second,
+		Host:                 config.Host,
+		Port:                 config.Port,
+		Username:             config.Username,
+		Password:             config.Password,
+		PrivateKey:           config.PrivateKey,
+		PrivateKeyPassphrase: config.PrivateKeyPassphrase,
+		UseAgent:             config.UseSSHAgent,
+		VerifyHostKey:        config.SSHVerifyHostKey,
+		HostKeyFingerprint:   config.SSHHostKeyFingerprint,
+		OnHostKeyPinned:      config.OnHostKeyPinned,
+		ConnectTimeout:       time.Duration(sshConnectTimeout) * time.Second,
+		ExecuteTimeout:       time.Duration(sshExecuteTimeout) * time.Second,
+		CommandLogID:         config.ID,
 	}
 	client, err := utils.NewSSHClient(sshConfig)
 	if err != nil {
@@ -74,15 +93,19 @@ func (d *DockerProvider) Connect(ctx context.Context, config provider.NodeConfig
 
 	// 初始化健康检查器，使用Provider的SSH连接，避免创建独立连接导致节点混淆
 	healthConfig := health.HealthConfig{
-		Host:          config.Host,
-		Port:          config.Port,
-		Username:      config.Username,
-		Password:      config.Password,
-		PrivateKey:    config.PrivateKey,
-		APIEnabled:    false, // Docker Provider 不使用 API
-		SSHEnabled:    true,
-		Timeout:       30 * time.Second,
-		ServiceChecks: []string{"docker"},
+		Host:                 config.Host,
+		Port:                 config.Port,
+		Username:             config.Username,
+		Password:             config.Password,
+		PrivateKey:           config.PrivateKey,
+		PrivateKeyPassphrase: config.PrivateKeyPassphrase,
+		UseAgent:             config.UseSSHAgent,
+		VerifyHostKey:        config.SSHVerifyHostKey,
+		HostKeyFingerprint:   config.SSHHostKeyFingerprint,
+		APIEnabled:           false, // Docker Provider 不使用 API
+		SSHEnabled:           true,
+		Timeout:              30 * time.Second,
+		ServiceChecks:        []string{d.cliBinary()},
 	}
 
 	// 创建一个简单的zap logger实例给健康检查器使用
@@ -92,7 +115,8 @@ func (d *DockerProvider) Connect(ctx context.Context, config provider.NodeConfig
 
 	global.APP_LOG.Info("Docker provider连接成功",
 		zap.String("host", utils.TruncateString(config.Host, 32)),
-		zap.Int("port", config.Port))
+		zap.Int("port", config.Port),
+		zap.String("authMethod", client.AuthMethod()))
 
 	return nil
 }
@@ -153,6 +177,10 @@ func (d *DockerProvider) ListInstances(ctx context.Context) ([]provider.Instance
 }
 
 func (d *DockerProvider) CreateInstance(ctx context.Context, config provider.InstanceConfig) error {
+	if err := provider.ValidateInstanceName(config.Name); err != nil {
+		return err
+	}
+
 	if !d.connected {
 		return fmt.Errorf("not connected")
 	}
@@ -166,6 +194,10 @@ func (d *DockerProvider) CreateInstance(ctx context.Context, config provider.Ins
 }
 
 func (d *DockerProvider) CreateInstanceWithProgress(ctx context.Context, config provider.InstanceConfig, progressCallback provider.ProgressCallback) error {
+	if err := provider.ValidateInstanceName(config.Name); err != nil {
+		return err
+	}
+
 	global.APP_LOG.Info("Docker.CreateInstanceWithProgress被调用",
 		zap.String("instanceName", config.Name),
 		zap.Bool("connected", d.connected))
@@ -188,6 +220,10 @@ func (d *DockerProvider) CreateInstanceWithProgress(ctx context.Context, config
 }
 
 func (d *DockerProvider) StartInstance(ctx context.Context, id string) error {
+	if err := provider.ValidateInstanceName(id); err != nil {
+		return err
+	}
+
 	if !d.connected {
 		return fmt.Errorf("not connected")
 	}
@@ -201,6 +237,10 @@ func (d *DockerProvider) StartInstance(ctx context.Context, id string) error {
 }
 
 func (d *DockerProvider) StopInstance(ctx context.Context, id string) error {
+	if err := provider.ValidateInstanceName(id); err != nil {
+		return err
+	}
+
 	if !d.connected {
 		return fmt.Errorf("not connected")
 	}
@@ -214,6 +254,10 @@ func (d *DockerProvider) StopInstance(ctx context.Context, id string) error {
 }
 
 func (d *DockerProvider) RestartInstance(ctx context.Context, id string) error {
+	if err := provider.ValidateInstanceName(id); err != nil {
+		return err
+	}
+
 	if !d.connected {
 		return fmt.Errorf("not connected")
 	}
@@ -227,6 +271,10 @@ func (d *DockerProvider) RestartInstance(ctx context.Context, id string) error {
 }
 
 func (d *DockerProvider) DeleteInstance(ctx context.Context, id string) error {
+	if err := provider.ValidateInstanceName(id); err != nil {
+		return err
+	}
+
 	// Docker provider只支持SSH，检查执行规则
 	if d.config.ExecutionRule == "api_only" {
 		return fmt.Errorf("Docker provider不支持API调用，无法使用api_only执行规则")
@@ -342,7 +390,7 @@ func (d *DockerProvider) GetInstance(ctx context.Context, id string) (*provider.
 	}
 
 	// 使用简单的分隔符格式获取信息，避免table格式的解析问题
-	output, err := d.sshClient.ExecuteWithLogging(fmt.Sprintf("docker inspect %s --format '{{.Name}}|{{.State.Status}}|{{.Config.Image}}|{{.Id}}|{{.Created}}'", id), "DOCKER_INSPECT")
+	output, err := d.sshClient.ExecuteWithLogging(d.cliCommand("inspect %s --format '{{.Name}}|{{.State.Status}}|{{.Config.Image}}|{{.Id}}|{{.Created}}'", id), "DOCKER_INSPECT")
 	if err != nil {
 		global.APP_LOG.Debug("Docker inspect命令执行失败",
 			zap.String("id", utils.TruncateString(id, 32)),
@@ -401,7 +449,7 @@ func (d *DockerProvider) GetInstance(ctx context.Context, id string) (*provider.
 // enrichInstanceWithNetworkInfo 补充单个实例的网络信息
 func (d *DockerProvider) enrichInstanceWithNetworkInfo(instance *provider.Instance) {
 	// 1. 获取容器的内网IP地址
-	cmd := fmt.Sprintf("docker inspect %s --format '{{range $net, $config := .NetworkSettings.Networks}}{{$config.IPAddress}}{{end}}'", instance.Name)
+	cmd := d.cliCommand("inspect %s --format '{{range $net, $config := .NetworkSettings.Networks}}{{$config.IPAddress}}{{end}}'", instance.Name)
 	output, err := d.sshClient.Execute(cmd)
 	if err == nil {
 		ipAddress := strings.TrimSpace(output)
@@ -415,21 +463,7 @@ func (d *DockerProvider) enrichInstanceWithNetworkInfo(instance *provider.Instan
 	}
 
 	// 2. 获取容器对应的宿主机veth接口
-	vethCmd := fmt.Sprintf(`
-CONTAINER_NAME='%s'
-CONTAINER_PID=$(docker inspect -f '{{.State.Pid}}' "$CONTAINER_NAME" 2>/dev/null)
-if [ -z "$CONTAINER_PID" ] || [ "$CONTAINER_PID" = "0" ]; then
-    exit 1
-fi
-HOST_VETH_IFINDEX=$(nsenter -t $CONTAINER_PID -n ip link show eth0 2>/dev/null | head -n1 | sed -n 's/.*@if\([0-9]\+\).*/\1/p')
-if [ -z "$HOST_VETH_IFINDEX" ]; then
-    exit 1
-fi
-VETH_NAME=$(ip -o link show 2>/dev/null | awk -v idx="$HOST_VETH_IFINDEX" -F': ' '$1 == idx {print $2}' | cut -d'@' -f1)
-if [ -n "$VETH_NAME" ]; then
-    echo "$VETH_NAME"
-fi
-`, instance.Name)
+	vethCmd := d.vethLookupCommand(instance.Name)
 
 	vethOutput, err := d.sshClient.Execute(vethCmd)
 	if err == nil {
@@ -447,7 +481,7 @@ fi
 
 	// 如果没有获取到PrivateIP，尝试使用旧方法获取
 	if instance.PrivateIP == "" {
-		cmd := fmt.Sprintf("docker inspect %s --format '{{.NetworkSettings.IPAddress}}'", instance.Name)
+		cmd := d.cliCommand("inspect %s --format '{{.NetworkSettings.IPAddress}}'", instance.Name)
 		output, err := d.sshClient.Execute(cmd)
 		if err == nil {
 			ipAddress := strings.TrimSpace(output)
@@ -461,21 +495,16 @@ fi
 		}
 	}
 
-	// 3. 检查容器是否连接到ipv6_net网络，如果是则获取IPv6地址
-	checkIPv6Cmd := fmt.Sprintf("docker inspect %s --format '{{range $net, $config := .NetworkSettings.Networks}}{{$net}}{{println}}{{end}}'", instance.Name)
-	networksOutput, err := d.sshClient.Execute(checkIPv6Cmd)
-	if err == nil && strings.Contains(networksOutput, "ipv6_net") {
-		// 容器连接到了ipv6_net，获取IPv6地址
-		cmd = fmt.Sprintf("docker inspect %s --format '{{range $net, $config := .NetworkSettings.Networks}}{{if $config.GlobalIPv6Address}}{{$config.GlobalIPv6Address}}{{end}}{{end}}'", instance.Name)
-		output, err = d.sshClient.Execute(cmd)
-		if err == nil {
-			ipv6Address := strings.TrimSpace(output)
-			if ipv6Address != "" && ipv6Address != "<no value>" {
-				instance.IPv6Address = ipv6Address
-				global.APP_LOG.Debug("获取到Docker实例IPv6地址",
-					zap.String("instance", instance.Name),
-					zap.String("ipv6", ipv6Address))
-			}
+	// 3. 获取IPv6地址，容器可能连接在ipv6_net或任意启用了IPv6的自定义网络上
+	cmd = d.cliCommand("inspect %s --format '{{range $net, $config := .NetworkSettings.Networks}}{{if $config.GlobalIPv6Address}}{{$config.GlobalIPv6Address}}{{println}}{{end}}{{end}}'", instance.Name)
+	output, err = d.sshClient.Execute(cmd)
+	if err == nil {
+		ipv6Address := firstLine(output)
+		if ipv6Address != "" && ipv6Address != "<no value>" {
+			instance.IPv6Address = ipv6Address
+			global.APP_LOG.Debug("获取到Docker实例IPv6地址",
+				zap.String("instance", instance.Name),
+				zap.String("ipv6", ipv6Address))
 		}
 	}
 }
@@ -487,7 +516,7 @@ func (d *DockerProvider) checkIPv6NetworkAvailable() bool {
 	}
 
 	// 检查 ipv6_net 网络是否存在
-	_, err := d.sshClient.Execute("docker network inspect ipv6_net")
+	_, err := d.sshClient.Execute(d.cliCommand("network inspect ipv6_net"))
 	if err != nil {
 		global.APP_LOG.Debug("IPv6网络检查失败: ipv6_net网络不存在",
 			zap.String("provider", d.config.Name),
@@ -496,7 +525,7 @@ func (d *DockerProvider) checkIPv6NetworkAvailable() bool {
 	}
 
 	// 检查 ndpresponder 容器是否存在且正在运行
-	ndpresponderCmd := "docker inspect -f '{{.State.Status}}' ndpresponder 2>/dev/null"
+	ndpresponderCmd := d.cliCommand("inspect -f '{{.State.Status}}' ndpresponder 2>/dev/null")
 	ndpresponderOutput, err := d.sshClient.Execute(ndpresponderCmd)
 	if err != nil {
 		global.APP_LOG.Debug("IPv6网络检查: ndpresponder容器不存在",
@@ -526,7 +555,7 @@ func (d *DockerProvider) checkIPv6NetworkAvailable() bool {
 	return true
 }
 
-// ExecuteSSHCommand 执行SSH命令
+// ExecuteSSHCommand 执行SSH命令，超时按ctx中的操作类型确定
 func (d *DockerProvider) ExecuteSSHCommand(ctx context.Context, command string) (string, error) {
 	if !d.connected || d.sshClient == nil {
 		return "", fmt.Errorf("Docker provider not connected")
@@ -535,7 +564,9 @@ func (d *DockerProvider) ExecuteSSHCommand(ctx context.Context, command string)
 	global.APP_LOG.Debug("执行SSH命令",
 		zap.String("command", utils.TruncateString(command, 200)))
 
-	output, err := d.sshClient.Execute(command)
+	start := time.Now()
+	output, err := d.sshClient.ExecuteWithTimeout(command, utils.SSHOperationTimeout(utils.SSHOperationFromContext(ctx)), utils.SSHOutputLimitFromContext(ctx))
+	metrics.ObserveSSHCommand(d.config.Name, start, err)
 	if err != nil {
 		global.APP_LOG.Error("SSH命令执行失败",
 			zap.String("command", utils.TruncateString(command, 200)),
@@ -547,6 +578,19 @@ func (d *DockerProvider) ExecuteSSHCommand(ctx context.Context, command string)
 	return output, nil
 }
 
+// GetVethInterfaceName 通过容器PID获取对应的宿主机veth接口名称
+func (d *DockerProvider) GetVethInterfaceName(ctx context.Context, instanceName string) (string, error) {
+	output, err := d.ExecuteSSHCommand(ctx, d.vethLookupCommand(instanceName))
+	if err != nil {
+		return "", fmt.Errorf("获取veth接口失败: %w", err)
+	}
+	vethName := strings.TrimSpace(output)
+	if vethName == "" {
+		return "", fmt.Errorf("未找到容器 %s 的veth接口", instanceName)
+	}
+	return vethName, nil
+}
+
 // SSH 实现方法
 
 func init() {
@@ -7,13 +7,15 @@ import (
 	"strings"
 
 	"oneclickvirt/global"
+	"oneclickvirt/provider"
 	"oneclickvirt/utils"
 
 	"go.uber.org/zap"
 )
 
 // downloadImageToRemote 在远程服务器上下载镜像
-func (d *DockerProvider) downloadImageToRemote(imageURL, imageName, providerCountry, architecture string, useCDN bool) (string, error) {
+// expectedSHA256非空时校验已缓存和新下载的文件，缓存文件不匹配时重新下载，新下载的文件不匹配时删除并返回错误
+func (d *DockerProvider) downloadImageToRemote(imageURL, imageName, providerCountry, architecture, expectedSHA256 string, useCDN bool) (string, error) {
 	// 根据provider类型确定远程下载目录
 	downloadDir := "/usr/local/bin/docker_ct_images"
 
@@ -30,10 +32,18 @@ func (d *DockerProvider) downloadImageToRemote(imageURL, imageName, providerCoun
 
 	// 检查远程文件是否已存在
 	if d.isRemoteFileValid(remotePath) {
-		global.APP_LOG.Info("远程镜像文件已存在且完整，跳过下载",
-			zap.String("imageName", imageName),
-			zap.String("remotePath", remotePath))
-		return remotePath, nil
+		if err := provider.VerifyRemoteSHA256(d.sshClient, remotePath, expectedSHA256); err != nil {
+			global.APP_LOG.Warn("远程缓存镜像文件校验失败，重新下载",
+				zap.String("imageName", imageName),
+				zap.String("remotePath", remotePath),
+				zap.Error(err))
+			d.removeRemoteFile(remotePath)
+		} else {
+			global.APP_LOG.Info("远程镜像文件已存在且完整，跳过下载",
+				zap.String("imageName", imageName),
+				zap.String("remotePath", remotePath))
+			return remotePath, nil
+		}
 	}
 
 	// 确定下载URL，传递 useCDN 参数
@@ -47,11 +57,20 @@ func (d *DockerProvider) downloadImageToRemote(imageURL, imageName, providerCoun
 
 	// 在远程服务器上下载文件
 	if err := d.downloadFileToRemote(downloadURL, remotePath); err != nil {
-		// 下载失败，删除不完整的文件
+		// 下载失败，部分文件保留在.tmp中供下次续传
 		d.removeRemoteFile(remotePath)
 		return "", fmt.Errorf("远程下载镜像失败: %w", err)
 	}
 
+	if err := provider.VerifyRemoteSHA256(d.sshClient, remotePath, expectedSHA256); err != nil {
+		d.removeRemoteFile(remotePath)
+		global.APP_LOG.Error("远程镜像文件校验失败",
+			zap.String("imageName", imageName),
+			zap.String("remotePath", remotePath),
+			zap.Error(err))
+		return "", err
+	}
+
 	global.APP_LOG.Info("远程镜像下载完成",
 		zap.String("imageName", imageName),
 		zap.String("remotePath", remotePath))
@@ -100,49 +119,11 @@ func (d *DockerProvider) removeRemoteFile(remotePath string) error {
 	return err
 }
 
-// downloadFileToRemote 在远程服务器上下载文件
+// downloadFileToRemote 在远程服务器上下载文件，中断后保留部分文件以便下次断点续传
 func (d *DockerProvider) downloadFileToRemote(url, remotePath string) error {
-	// 使用curl在远程服务器上下载文件
-	tmpPath := remotePath + ".tmp"
-
-	// 下载文件，支持断点续传
-	curlCmd := fmt.Sprintf(
-		"curl -4 -L -C - --connect-timeout 30 --retry 5 --retry-delay 10 --retry-max-time 0 -o %s '%s'",
-		tmpPath, url,
-	)
-
 	global.APP_LOG.Info("执行远程下载命令",
 		zap.String("url", utils.TruncateString(url, 100)))
-
-	output, err := d.sshClient.Execute(curlCmd)
-	if err != nil {
-		// 清理临时文件
-		d.sshClient.Execute(fmt.Sprintf("rm -f %s", tmpPath))
-
-		global.APP_LOG.Error("远程下载失败",
-			zap.String("url", utils.TruncateString(url, 100)),
-			zap.String("remotePath", remotePath),
-			zap.String("output", utils.TruncateString(output, 500)),
-			zap.Error(err))
-		return fmt.Errorf("远程下载失败: %w", err)
-	}
-
-	// 移动文件到最终位置
-	mvCmd := fmt.Sprintf("mv %s %s", tmpPath, remotePath)
-	_, err = d.sshClient.Execute(mvCmd)
-	if err != nil {
-		global.APP_LOG.Error("移动文件失败",
-			zap.String("tmpPath", tmpPath),
-			zap.String("remotePath", remotePath),
-			zap.Error(err))
-		return fmt.Errorf("移动文件失败: %w", err)
-	}
-
-	global.APP_LOG.Info("远程下载成功",
-		zap.String("url", utils.TruncateString(url, 100)),
-		zap.String("remotePath", remotePath))
-
-	return nil
+	return provider.DownloadRemoteFile(d.sshClient, url, remotePath)
 }
 
 // ensureSSHScriptsAvailable 确保SSH脚本文件在远程服务器上可用
@@ -0,0 +1,23 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"oneclickvirt/provider"
+	"oneclickvirt/utils"
+)
+
+// ExecInInstance 在容器内执行命令
+func (d *DockerProvider) ExecInInstance(ctx context.Context, instanceName, command string, timeout time.Duration, maxOutput int) (*utils.CommandResult, error) {
+	if err := provider.ValidateInstanceName(instanceName); err != nil {
+		return nil, err
+	}
+	if !d.connected || d.sshClient == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+
+	cmd := provider.WithExecTimeout(d.cliCommand("exec %s sh -c %s", instanceName, utils.ShellQuote(command)), timeout)
+	return d.sshClient.ExecuteCapture(cmd, timeout+provider.ExecTimeoutGrace, maxOutput)
+}
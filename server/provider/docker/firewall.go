@@ -0,0 +1,68 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"oneclickvirt/global"
+	"oneclickvirt/provider"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+)
+
+// dockerFirewallHooks Docker会在FORWARD链首重建自己的规则，入口链挂在DOCKER-USER上；
+// ip6tables未启用DOCKER-USER时退回FORWARD
+var dockerFirewallHooks = []string{"DOCKER-USER", "FORWARD"}
+
+// ManageFirewallRule 在宿主机上添加或删除容器的防火墙规则，按容器IP匹配转发到容器的流量
+func (d *DockerProvider) ManageFirewallRule(ctx context.Context, instanceName string, rule provider.FirewallRule) error {
+	if !d.connected || d.sshClient == nil {
+		return fmt.Errorf("not connected")
+	}
+
+	ips, err := d.firewallTargetIPs(instanceName)
+	if err != nil {
+		return err
+	}
+	cmd, err := provider.BuildFirewallRuleCommand(instanceName, ips, dockerFirewallHooks, rule)
+	if err != nil {
+		return err
+	}
+	if output, err := d.sshClient.Execute(cmd); err != nil {
+		return fmt.Errorf("应用防火墙规则失败: %s: %w", utils.TruncateString(strings.TrimSpace(output), 200), err)
+	}
+
+	global.APP_LOG.Info("容器防火墙规则已更新",
+		zap.String("instance", instanceName),
+		zap.String("protocol", rule.Protocol),
+		zap.Int("port", rule.Port),
+		zap.String("action", rule.Action),
+		zap.Bool("remove", rule.Remove))
+	return nil
+}
+
+// ClearFirewallRules 删除容器的全部防火墙规则
+func (d *DockerProvider) ClearFirewallRules(ctx context.Context, instanceName string) error {
+	if !d.connected || d.sshClient == nil {
+		return fmt.Errorf("not connected")
+	}
+	_, err := d.sshClient.Execute(provider.BuildFirewallCleanupCommand(instanceName))
+	return err
+}
+
+// firewallTargetIPs 返回容器的内网IPv4和IPv6地址，IPv6获取失败时只返回IPv4
+func (d *DockerProvider) firewallTargetIPs(containerName string) ([]string, error) {
+	ipv4, err := d.getContainerPrivateIP(containerName)
+	if err != nil {
+		return nil, err
+	}
+	ips := []string{ipv4}
+
+	output, err := d.sshClient.Execute(d.cliCommand("inspect %s --format '{{range $net, $config := .NetworkSettings.Networks}}{{$config.GlobalIPv6Address}} {{end}}'", containerName))
+	if err == nil {
+		ips = append(ips, strings.Fields(output)...)
+	}
+	return ips, nil
+}
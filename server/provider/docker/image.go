@@ -14,7 +14,7 @@ import (
 
 // sshListImages 列出所有镜像
 func (d *DockerProvider) sshListImages(ctx context.Context) ([]provider.Image, error) {
-	output, err := d.sshClient.ExecuteWithLogging("docker images --format 'table {{.Repository}}\\t{{.Tag}}\\t{{.ID}}\\t{{.Size}}\\t{{.CreatedAt}}'", "DOCKER_IMAGES")
+	output, err := d.sshClient.ExecuteWithLogging(d.cliCommand("images --format 'table {{.Repository}}\\t{{.Tag}}\\t{{.ID}}\\t{{.Size}}\\t{{.CreatedAt}}'"), "DOCKER_IMAGES")
 	if err != nil {
 		return nil, err
 	}
@@ -46,7 +46,7 @@ func (d *DockerProvider) sshListImages(ctx context.Context) ([]provider.Image, e
 
 // sshPullImage 拉取镜像
 func (d *DockerProvider) sshPullImage(ctx context.Context, image string) error {
-	pullCmd := fmt.Sprintf("docker pull %s", image)
+	pullCmd := d.cliCommand("pull %s", image)
 	global.APP_LOG.Info("开始拉取Docker镜像",
 		zap.String("image", utils.TruncateString(image, 64)),
 		zap.String("command", pullCmd))
@@ -67,7 +67,7 @@ func (d *DockerProvider) sshPullImage(ctx context.Context, image string) error {
 
 // sshDeleteImage 删除镜像
 func (d *DockerProvider) sshDeleteImage(ctx context.Context, id string) error {
-	_, err := d.sshClient.Execute(fmt.Sprintf("docker rmi -f %s", id))
+	_, err := d.sshClient.Execute(d.cliCommand("rmi -f %s", id))
 	if err != nil {
 		return fmt.Errorf("failed to delete image: %w", err)
 	}
@@ -78,14 +78,14 @@ func (d *DockerProvider) sshDeleteImage(ctx context.Context, id string) error {
 
 // loadImageToDocker 加载镜像到Docker
 func (d *DockerProvider) loadImageToDocker(imagePath, targetImageName string) error {
-	loadCmd := fmt.Sprintf("docker load -i %s", imagePath)
+	loadCmd := d.cliCommand("load -i %s", imagePath)
 
 	global.APP_LOG.Info("开始加载Docker镜像",
 		zap.String("imagePath", utils.TruncateString(imagePath, 64)),
 		zap.String("targetImageName", utils.TruncateString(targetImageName, 64)),
 		zap.String("command", utils.TruncateString(loadCmd, 200)))
 
-	output, err := d.sshClient.Execute(loadCmd)
+	output, err := d.sshClient.ExecuteOperation(utils.SSHOperationDownload, loadCmd)
 	if err != nil {
 		global.APP_LOG.Error("Docker镜像加载失败",
 			zap.String("imagePath", utils.TruncateString(imagePath, 64)),
@@ -109,7 +109,7 @@ func (d *DockerProvider) loadImageToDocker(imagePath, targetImageName string) er
 	}
 	// 如果找到了加载的镜像名称且与目标名称不同，则重新标记
 	if loadedImageName != "" && loadedImageName != targetImageName {
-		tagCmd := fmt.Sprintf("docker tag %s %s", loadedImageName, targetImageName)
+		tagCmd := d.cliCommand("tag %s %s", loadedImageName, targetImageName)
 		global.APP_LOG.Info("重新标记Docker镜像",
 			zap.String("sourceImage", utils.TruncateString(loadedImageName, 64)),
 			zap.String("targetImage", utils.TruncateString(targetImageName, 64)),
@@ -137,15 +137,15 @@ func (d *DockerProvider) loadImageToDocker(imagePath, targetImageName string) er
 // cleanupDockerImage 清理Docker镜像
 func (d *DockerProvider) cleanupDockerImage(imageName string) {
 	// 删除损坏的Docker镜像（忽略错误）
-	d.sshClient.Execute(fmt.Sprintf("docker rmi -f %s", imageName))
+	d.sshClient.Execute(d.cliCommand("rmi -f %s", imageName))
 	// 清理未使用的镜像
-	d.sshClient.Execute("docker image prune -f")
+	d.sshClient.Execute(d.cliCommand("image prune -f"))
 	global.APP_LOG.Info("清理Docker镜像", zap.String("imageName", utils.TruncateString(imageName, 64)))
 }
 
 // imageExists 检查Docker镜像是否已存在
 func (d *DockerProvider) imageExists(imageName string) bool {
-	output, err := d.sshClient.Execute(fmt.Sprintf("docker images --format '{{.Repository}}:{{.Tag}}' | grep -E '^%s($|:)'", imageName))
+	output, err := d.sshClient.Execute(d.cliCommand("images --format '{{.Repository}}:{{.Tag}}' | grep -E '^%s($|:)'", imageName))
 	if err != nil {
 		global.APP_LOG.Debug("检查Docker镜像存在性失败",
 			zap.String("imageName", utils.TruncateString(imageName, 64)),
@@ -159,3 +159,73 @@ func (d *DockerProvider) imageExists(imageName string) bool {
 		zap.Bool("exists", exists))
 	return exists
 }
+
+// ensureImageLoaded 确保镜像已加载到Docker，不存在时下载并导入
+// 镜像仓库引用直接拉取，不经过tar包下载和名称前缀
+func (d *DockerProvider) ensureImageLoaded(imageName, imageURL, imageSHA256 string, useCDN bool, updateProgress func(int, string)) error {
+	if provider.IsRegistryReference(imageName) {
+		return d.ensureRegistryImage(imageName, updateProgress)
+	}
+
+	// 为镜像名称添加前缀
+	imageNameWithPrefix := provider.ImageNameWithPrefix(imageName)
+
+	// 首先检查镜像是否存在
+	imageExistsResult := d.imageExists(imageNameWithPrefix)
+	global.APP_LOG.Debug("imageExists调用完成",
+		zap.String("imageNameWithPrefix", imageNameWithPrefix),
+		zap.Bool("exists", imageExistsResult))
+
+	if !imageExistsResult {
+		// 如果镜像不存在且有镜像URL，先在远程服务器下载镜像
+		if imageURL != "" {
+			updateProgress(30, "下载镜像到远程服务器...")
+			// 在远程服务器上下载镜像
+			remotePath, err := d.downloadImageToRemote(imageURL, imageName, d.config.Country, d.config.Architecture, imageSHA256, useCDN)
+			if err != nil {
+				return fmt.Errorf("下载镜像失败: %w", err)
+			}
+
+			updateProgress(50, "加载镜像到Docker...")
+			// 在远程服务器上加载镜像到Docker
+			if err := d.loadImageToDocker(remotePath, imageNameWithPrefix); err != nil {
+				// 加载失败，清理下载的文件并重试
+				global.APP_LOG.Warn("Docker镜像加载失败，尝试重新下载",
+					zap.String("image", utils.TruncateString(imageNameWithPrefix, 64)),
+					zap.Error(err))
+
+				// 清理损坏的镜像文件和Docker镜像
+				d.cleanupRemoteImage(imageName, imageURL, d.config.Architecture)
+				d.cleanupDockerImage(imageNameWithPrefix)
+
+				updateProgress(40, "重新下载镜像...")
+				// 重新下载
+				remotePath, err = d.downloadImageToRemote(imageURL, imageName, d.config.Country, d.config.Architecture, imageSHA256, useCDN)
+				if err != nil {
+					return fmt.Errorf("重新下载镜像失败: %w", err)
+				}
+
+				updateProgress(55, "重新加载镜像到Docker...")
+				// 重新加载
+				if err := d.loadImageToDocker(remotePath, imageNameWithPrefix); err != nil {
+					return fmt.Errorf("重新加载镜像失败: %w", err)
+				}
+			}
+
+			updateProgress(60, "清理临时文件...")
+			// 导入成功后删除文件
+			d.cleanupRemoteImage(imageName, imageURL, d.config.Architecture)
+		} else {
+			// 镜像不存在且没有URL，返回错误
+			global.APP_LOG.Error("Docker镜像不存在且没有下载URL",
+				zap.String("image", utils.TruncateString(imageNameWithPrefix, 64)))
+			return fmt.Errorf("镜像 %s 不存在，且没有提供下载URL", imageNameWithPrefix)
+		}
+	} else {
+		updateProgress(60, "Docker镜像已存在，跳过下载...")
+		global.APP_LOG.Info("Docker镜像已存在，跳过下载",
+			zap.String("image", utils.TruncateString(imageNameWithPrefix, 64)))
+	}
+
+	return nil
+}
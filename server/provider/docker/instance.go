@@ -20,7 +20,7 @@ import (
 
 // sshListInstances 列出所有实例
 func (d *DockerProvider) sshListInstances(ctx context.Context) ([]provider.Instance, error) {
-	output, err := d.sshClient.ExecuteWithLogging("docker ps -a --format 'table {{.Names}}\\t{{.Status}}\\t{{.Image}}\\t{{.ID}}\\t{{.CreatedAt}}'", "DOCKER_LIST")
+	output, err := d.sshClient.ExecuteWithLogging(d.cliCommand("ps -a --format 'table {{.Names}}\\t{{.Status}}\\t{{.Image}}\\t{{.ID}}\\t{{.CreatedAt}}'"), "DOCKER_LIST")
 	if err != nil {
 		return nil, err
 	}
@@ -71,7 +71,7 @@ func (d *DockerProvider) enrichInstancesWithNetworkInfo(instances *[]provider.In
 		}
 
 		// 1. 获取容器的内网IP地址
-		cmd := fmt.Sprintf("docker inspect %s --format '{{range $net, $config := .NetworkSettings.Networks}}{{$config.IPAddress}}{{end}}'", instance.Name)
+		cmd := d.cliCommand("inspect %s --format '{{range $net, $config := .NetworkSettings.Networks}}{{$config.IPAddress}}{{end}}'", instance.Name)
 		output, err := d.sshClient.Execute(cmd)
 		if err == nil {
 			ipAddress := strings.TrimSpace(output)
@@ -85,21 +85,7 @@ func (d *DockerProvider) enrichInstancesWithNetworkInfo(instances *[]provider.In
 		}
 
 		// 2. 获取容器对应的宿主机veth接口
-		vethCmd := fmt.Sprintf(`
-CONTAINER_NAME='%s'
-CONTAINER_PID=$(docker inspect -f '{{.State.Pid}}' "$CONTAINER_NAME" 2>/dev/null)
-if [ -z "$CONTAINER_PID" ] || [ "$CONTAINER_PID" = "0" ]; then
-    exit 1
-fi
-HOST_VETH_IFINDEX=$(nsenter -t $CONTAINER_PID -n ip link show eth0 2>/dev/null | head -n1 | sed -n 's/.*@if\([0-9]\+\).*/\1/p')
-if [ -z "$HOST_VETH_IFINDEX" ]; then
-    exit 1
-fi
-VETH_NAME=$(ip -o link show 2>/dev/null | awk -v idx="$HOST_VETH_IFINDEX" -F': ' '$1 == idx {print $2}' | cut -d'@' -f1)
-if [ -n "$VETH_NAME" ]; then
-    echo "$VETH_NAME"
-fi
-`, instance.Name)
+		vethCmd := d.vethLookupCommand(instance.Name)
 
 		vethOutput, err := d.sshClient.Execute(vethCmd)
 		if err == nil {
@@ -117,7 +103,7 @@ fi
 
 		// 如果没有获取到PrivateIP，尝试使用旧方法获取
 		if instance.PrivateIP == "" {
-			cmd := fmt.Sprintf("docker inspect %s --format '{{.NetworkSettings.IPAddress}}'", instance.Name)
+			cmd := d.cliCommand("inspect %s --format '{{.NetworkSettings.IPAddress}}'", instance.Name)
 			output, err := d.sshClient.Execute(cmd)
 			if err == nil {
 				ipAddress := strings.TrimSpace(output)
@@ -131,21 +117,16 @@ fi
 			}
 		}
 
-		// 3. 检查容器是否连接到ipv6_net网络，如果是则获取IPv6地址
-		checkIPv6Cmd := fmt.Sprintf("docker inspect %s --format '{{range $net, $config := .NetworkSettings.Networks}}{{$net}}{{println}}{{end}}'", instance.Name)
-		networksOutput, err := d.sshClient.Execute(checkIPv6Cmd)
-		if err == nil && strings.Contains(networksOutput, "ipv6_net") {
-			// 容器连接到了ipv6_net，获取IPv6地址
-			cmd = fmt.Sprintf("docker inspect %s --format '{{range $net, $config := .NetworkSettings.Networks}}{{if $config.GlobalIPv6Address}}{{$config.GlobalIPv6Address}}{{end}}{{end}}'", instance.Name)
-			output, err = d.sshClient.Execute(cmd)
-			if err == nil {
-				ipv6Address := strings.TrimSpace(output)
-				if ipv6Address != "" && ipv6Address != "<no value>" {
-					instance.IPv6Address = ipv6Address
-					global.APP_LOG.Debug("获取到Docker实例IPv6地址",
-						zap.String("instance", instance.Name),
-						zap.String("ipv6", ipv6Address))
-				}
+		// 3. 获取IPv6地址，容器可能连接在ipv6_net或任意启用了IPv6的自定义网络上
+		cmd = d.cliCommand("inspect %s --format '{{range $net, $config := .NetworkSettings.Networks}}{{if $config.GlobalIPv6Address}}{{$config.GlobalIPv6Address}}{{println}}{{end}}{{end}}'", instance.Name)
+		output, err = d.sshClient.Execute(cmd)
+		if err == nil {
+			ipv6Address := firstLine(output)
+			if ipv6Address != "" && ipv6Address != "<no value>" {
+				instance.IPv6Address = ipv6Address
+				global.APP_LOG.Debug("获取到Docker实例IPv6地址",
+					zap.String("instance", instance.Name),
+					zap.String("ipv6", ipv6Address))
 			}
 		}
 	}
@@ -158,6 +139,12 @@ func (d *DockerProvider) sshCreateInstance(ctx context.Context, config provider.
 
 // sshCreateInstanceWithProgress 创建实例并报告进度
 func (d *DockerProvider) sshCreateInstanceWithProgress(ctx context.Context, config provider.InstanceConfig, progressCallback provider.ProgressCallback) error {
+	return d.runInstance(ctx, config, progressCallback, nil)
+}
+
+// runInstance 按实例配置运行容器并完成后续配置
+// preserved为重建实例时从原容器保留的数据卷，与配置中的挂载一起挂载到新容器
+func (d *DockerProvider) runInstance(ctx context.Context, config provider.InstanceConfig, progressCallback provider.ProgressCallback, preserved []provider.VolumeMount) error {
 	// 进度更新辅助函数
 	updateProgress := func(percentage int, message string) {
 		if progressCallback != nil {
@@ -171,6 +158,46 @@ func (d *DockerProvider) sshCreateInstanceWithProgress(ctx context.Context, conf
 
 	updateProgress(10, "开始创建Docker实例...")
 
+	// Docker默认网桥无法保证固定地址，指定IP时直接失败而不是退回自动分配
+	if config.RequestedIPv4 != "" || config.RequestedIPv6 != "" {
+		return fmt.Errorf("Docker节点不支持指定IP地址")
+	}
+
+	if config.Network != "" {
+		if err := d.validatePrimaryNetwork(config.Network); err != nil {
+			return err
+		}
+	}
+
+	if len(config.NetworkInterfaces) > 0 {
+		if err := provider.ValidateNetworkInterfaces(config.NetworkInterfaces); err != nil {
+			return err
+		}
+		if err := d.validateExtraNetworks(config.NetworkInterfaces); err != nil {
+			return err
+		}
+	}
+
+	dnsServers, err := provider.ValidateDNSServers(config.DNSServers)
+	if err != nil {
+		return err
+	}
+
+	if config.Hostname, err = provider.ValidateHostname(config.Hostname); err != nil {
+		return err
+	}
+
+	// 挂载源按解析后的真实路径挂载，避免符号链接指向允许列表外的目录
+	volumes, err := provider.ResolveVolumeSources(d.sshClient.Execute, config.Volumes, d.config.VolumeAllowlist)
+	if err != nil {
+		return err
+	}
+
+	// 在下载镜像之前检查宿主机端口占用，避免run阶段才因端口冲突失败
+	if err := d.checkHostPortConflicts(config); err != nil {
+		return err
+	}
+
 	global.APP_LOG.Debug("开始创建Docker实例",
 		zap.String("instance", config.Name),
 		zap.String("image", config.Image),
@@ -193,75 +220,21 @@ func (d *DockerProvider) sshCreateInstanceWithProgress(ctx context.Context, conf
 		zap.String("instance", config.Name))
 
 	updateProgress(20, "处理Docker镜像...")
-	// 为镜像名称添加前缀
-	imageNameWithPrefix := "oneclickvirt_" + config.Image
+	// 本地别名添加前缀，仓库引用原样使用
+	runImage := runtimeImageName(config.Image)
 
 	global.APP_LOG.Debug("准备检查镜像是否存在",
 		zap.String("instance", config.Name),
-		zap.String("imageNameWithPrefix", imageNameWithPrefix))
+		zap.String("image", runImage))
 
-	// 首先检查镜像是否存在
-	imageExistsResult := d.imageExists(imageNameWithPrefix)
-	global.APP_LOG.Debug("imageExists调用完成",
-		zap.String("instance", config.Name),
-		zap.String("imageNameWithPrefix", imageNameWithPrefix),
-		zap.Bool("exists", imageExistsResult))
-
-	if !imageExistsResult {
-		// 如果镜像不存在且有镜像URL，先在远程服务器下载镜像
-		if config.ImageURL != "" {
-			updateProgress(30, "下载镜像到远程服务器...")
-			// 在远程服务器上下载镜像
-			remotePath, err := d.downloadImageToRemote(config.ImageURL, config.Image, d.config.Country, d.config.Architecture, config.UseCDN)
-			if err != nil {
-				return fmt.Errorf("下载镜像失败: %w", err)
-			}
-
-			updateProgress(50, "加载镜像到Docker...")
-			// 在远程服务器上加载镜像到Docker
-			if err := d.loadImageToDocker(remotePath, imageNameWithPrefix); err != nil {
-				// 加载失败，清理下载的文件并重试
-				global.APP_LOG.Warn("Docker镜像加载失败，尝试重新下载",
-					zap.String("image", utils.TruncateString(imageNameWithPrefix, 64)),
-					zap.Error(err))
-
-				// 清理损坏的镜像文件和Docker镜像
-				d.cleanupRemoteImage(config.Image, config.ImageURL, d.config.Architecture)
-				d.cleanupDockerImage(imageNameWithPrefix)
-
-				updateProgress(40, "重新下载镜像...")
-				// 重新下载
-				remotePath, err = d.downloadImageToRemote(config.ImageURL, config.Image, d.config.Country, d.config.Architecture, config.UseCDN)
-				if err != nil {
-					return fmt.Errorf("重新下载镜像失败: %w", err)
-				}
-
-				updateProgress(55, "重新加载镜像到Docker...")
-				// 重新加载
-				if err := d.loadImageToDocker(remotePath, imageNameWithPrefix); err != nil {
-					return fmt.Errorf("重新加载镜像失败: %w", err)
-				}
-			}
-
-			updateProgress(60, "清理临时文件...")
-			// 导入成功后删除文件
-			d.cleanupRemoteImage(config.Image, config.ImageURL, d.config.Architecture)
-		} else {
-			// 镜像不存在且没有URL，返回错误
-			global.APP_LOG.Error("Docker镜像不存在且没有下载URL",
-				zap.String("image", utils.TruncateString(imageNameWithPrefix, 64)))
-			return fmt.Errorf("镜像 %s 不存在，且没有提供下载URL", imageNameWithPrefix)
-		}
-	} else {
-		updateProgress(60, "Docker镜像已存在，跳过下载...")
-		global.APP_LOG.Info("Docker镜像已存在，跳过下载",
-			zap.String("image", utils.TruncateString(imageNameWithPrefix, 64)))
+	if err := d.ensureImageLoaded(config.Image, config.ImageURL, config.ImageSHA256, config.UseCDN, updateProgress); err != nil {
+		return err
 	}
 
 	updateProgress(70, "清理同名残留容器...")
 	// 预先清理任何同名的残留容器（包括停止、失败或创建失败的容器）
 	// 这可以避免端口冲突和容器名称冲突
-	cleanupCmd := fmt.Sprintf("docker ps -a --filter name=^%s$ -q | xargs -r docker rm -f", config.Name)
+	cleanupCmd := d.cliCommand("ps -a --filter name=^%s$ -q | xargs -r %s rm -f", config.Name, d.cliBinary())
 	global.APP_LOG.Debug("创建前清理同名容器",
 		zap.String("instance", utils.TruncateString(config.Name, 32)),
 		zap.String("command", cleanupCmd))
@@ -280,7 +253,7 @@ func (d *DockerProvider) sshCreateInstanceWithProgress(ctx context.Context, conf
 
 	updateProgress(72, "构建Docker run命令...")
 	// 构建docker run命令
-	cmd := fmt.Sprintf("docker run -d --name %s", config.Name)
+	cmd := d.cliCommand("run -d --name %s --hostname %s", config.Name, provider.InstanceHostname(config))
 
 	// 检查是否启用IPv6网络（支持标准的网络类型值）
 	networkType := d.config.NetworkType
@@ -295,7 +268,13 @@ func (d *DockerProvider) sshCreateInstanceWithProgress(ctx context.Context, conf
 	}
 
 	hasIPv6 := networkType == "nat_ipv4_ipv6" || networkType == "dedicated_ipv4_ipv6" || networkType == "ipv6_only"
-	if hasIPv6 && d.checkIPv6NetworkAvailable() {
+	if config.Network != "" {
+		// 指定了网络时直接使用，IPv6取决于该网络自身是否启用IPv6
+		cmd += fmt.Sprintf(" --network=%s", utils.ShellQuote(config.Network))
+		global.APP_LOG.Info("使用指定的网络",
+			zap.String("name", utils.TruncateString(config.Name, 32)),
+			zap.String("network", config.Network))
+	} else if hasIPv6 && d.checkIPv6NetworkAvailable() {
 		cmd += " --network=ipv6_net"
 		global.APP_LOG.Info("启用IPv6网络",
 			zap.String("name", utils.TruncateString(config.Name, 32)),
@@ -308,6 +287,11 @@ func (d *DockerProvider) sshCreateInstanceWithProgress(ctx context.Context, conf
 		}
 	}
 
+	// 自定义DNS，未指定时沿用Docker守护进程的DNS配置
+	for _, server := range dnsServers {
+		cmd += fmt.Sprintf(" --dns=%s", server)
+	}
+
 	// 始终应用CPU限制参数（资源限制配置只影响Provider层面的资源预算计算）
 	if config.CPU != "" {
 		cmd += fmt.Sprintf(" --cpus=%s", config.CPU)
@@ -321,6 +305,15 @@ func (d *DockerProvider) sshCreateInstanceWithProgress(ctx context.Context, conf
 		// Reference: https://docs.docker.com/config/containers/resource_constraints/#limit-a-containers-access-to-memory
 		// Note: Docker accepts both binary and decimal units, but typically uses 1024-based calculations
 		cmd += fmt.Sprintf(" --memory=%s", config.Memory)
+
+		// --memory-swap 为内存与swap之和，未指定swap大小时沿用Docker默认行为（swap为内存的两倍）
+		if config.MemorySwapMB != nil {
+			memorySwap, err := provider.DockerMemorySwapLimit(config.Memory, *config.MemorySwapMB)
+			if err != nil {
+				return fmt.Errorf("swap配置无效: %w", err)
+			}
+			cmd += fmt.Sprintf(" --memory-swap=%s", memorySwap)
+		}
 	}
 
 	updateProgress(75, "配置存储限制...")
@@ -333,44 +326,9 @@ func (d *DockerProvider) sshCreateInstanceWithProgress(ctx context.Context, conf
 				zap.String("name", utils.TruncateString(config.Name, 32)),
 				zap.String("disk", config.Disk),
 				zap.Error(err))
+			updateProgress(75, "无法检测存储驱动，硬盘大小限制不会生效")
 		} else if supportsDiskLimit {
-			// 处理磁盘大小单位转换
-			// config.Disk格式可能是："1024MB", "2GB", "512" 等
-			diskSize := strings.ToLower(config.Disk)
-			var finalDiskSize string
-
-			if strings.HasSuffix(diskSize, "mb") {
-				// 如果是MB单位，需要转换为GB（Docker storage-opt一般使用GB）
-				mbValue := strings.TrimSuffix(diskSize, "mb")
-				if mb, err := strconv.Atoi(mbValue); err == nil {
-					// 转换MB到GB，向上取整
-					gb := (mb + 1023) / 1024 // 向上取整
-					if gb < 1 {
-						gb = 1 // 最小1GB
-					}
-					finalDiskSize = fmt.Sprintf("%dG", gb)
-				} else {
-					finalDiskSize = "1G" // 解析失败，默认1GB
-				}
-			} else if strings.HasSuffix(diskSize, "gb") || strings.HasSuffix(diskSize, "g") {
-				// 已经是GB单位，直接使用
-				finalDiskSize = config.Disk
-				if !strings.HasSuffix(diskSize, "g") {
-					finalDiskSize = strings.TrimSuffix(config.Disk, "b") // 移除"b"，保留"g"
-				}
-			} else {
-				// 没有单位，假设是MB
-				if mb, err := strconv.Atoi(config.Disk); err == nil {
-					gb := (mb + 1023) / 1024 // 向上取整
-					if gb < 1 {
-						gb = 1
-					}
-					finalDiskSize = fmt.Sprintf("%dG", gb)
-				} else {
-					finalDiskSize = "1G"
-				}
-			}
-
+			finalDiskSize := dockerDiskSize(config.Disk)
 			cmd += fmt.Sprintf(" --storage-opt size=%s", finalDiskSize)
 			global.APP_LOG.Info("已启用硬盘大小限制",
 				zap.String("name", utils.TruncateString(config.Name, 32)),
@@ -382,6 +340,7 @@ func (d *DockerProvider) sshCreateInstanceWithProgress(ctx context.Context, conf
 				zap.String("name", utils.TruncateString(config.Name, 32)),
 				zap.String("storage_driver", storageDriver),
 				zap.String("disk", config.Disk))
+			updateProgress(75, fmt.Sprintf("节点存储驱动 %s 不支持硬盘大小限制，硬盘大小不会生效", storageDriver))
 		}
 	}
 
@@ -435,7 +394,14 @@ func (d *DockerProvider) sshCreateInstanceWithProgress(ctx context.Context, conf
 		}
 	}
 
-	updateProgress(85, "配置LXCFS卷挂载...")
+	updateProgress(85, "配置卷挂载...")
+	for _, volume := range volumes {
+		cmd += " " + provider.DockerVolumeArg(volume)
+	}
+	for _, volume := range preserved {
+		cmd += " " + provider.DockerVolumeArg(volume)
+	}
+
 	// 检查并添加LXCFS卷挂载
 	lxcfsAvailable, lxcfsVolumes, lxcfsReason, err := d.checkLXCFS()
 	if err != nil {
@@ -445,7 +411,7 @@ func (d *DockerProvider) sshCreateInstanceWithProgress(ctx context.Context, conf
 	} else if lxcfsAvailable && len(lxcfsVolumes) > 0 {
 		// 检测到的LXCFS卷挂载
 		for _, volume := range lxcfsVolumes {
-			cmd += " " + volume
+			cmd += " " + provider.DockerVolumeArg(volume)
 		}
 		global.APP_LOG.Info("已启用LXCFS卷挂载，提供真实的容器内资源视图",
 			zap.String("name", utils.TruncateString(config.Name, 32)),
@@ -465,12 +431,17 @@ func (d *DockerProvider) sshCreateInstanceWithProgress(ctx context.Context, conf
 		cmd += fmt.Sprintf(" -e %s=%s", key, value)
 	}
 
-	cmd += fmt.Sprintf(" %s", imageNameWithPrefix)
+	// 实例标签写入容器label，容器创建后label不可修改，后续以数据库为准
+	for key, value := range config.Labels {
+		cmd += fmt.Sprintf(" --label '%s%s=%s'", dockerTagLabelPrefix, key, value)
+	}
+
+	cmd += fmt.Sprintf(" %s", utils.ShellQuote(runImage))
 
 	updateProgress(95, "执行Docker创建命令...")
 	global.APP_LOG.Info("开始执行Docker创建命令",
 		zap.String("name", utils.TruncateString(config.Name, 32)),
-		zap.String("image", utils.TruncateString(imageNameWithPrefix, 64)),
+		zap.String("image", utils.TruncateString(runImage, 64)),
 		zap.String("command", utils.TruncateString(cmd, 200)))
 
 	output, err := d.sshClient.Execute(cmd)
@@ -501,7 +472,7 @@ func (d *DockerProvider) sshCreateInstanceWithProgress(ctx context.Context, conf
 		time.Sleep(checkInterval)
 
 		// 检查容器状态
-		statusOutput, err := d.sshClient.Execute(fmt.Sprintf("docker inspect %s --format '{{.State.Status}}'", config.Name))
+		statusOutput, err := d.sshClient.Execute(d.cliCommand("inspect %s --format '{{.State.Status}}'", config.Name))
 		if err == nil {
 			status := strings.ToLower(strings.TrimSpace(statusOutput))
 			if status == "running" {
@@ -523,6 +494,13 @@ func (d *DockerProvider) sshCreateInstanceWithProgress(ctx context.Context, conf
 			zap.String("name", utils.TruncateString(config.Name, 32)))
 	}
 
+	if len(config.NetworkInterfaces) > 0 {
+		updateProgress(96, "附加额外网卡...")
+		if err := d.attachExtraNetworks(config.Name, config.NetworkInterfaces); err != nil {
+			return fmt.Errorf("附加额外网卡失败: %w", err)
+		}
+	}
+
 	// 配置SSH密码
 	updateProgress(97, "配置SSH密码...")
 	if err := d.configureInstanceSSHPassword(ctx, config); err != nil {
@@ -530,6 +508,14 @@ func (d *DockerProvider) sshCreateInstanceWithProgress(ctx context.Context, conf
 		global.APP_LOG.Warn("配置SSH密码失败", zap.Error(err))
 	}
 
+	// 配置带宽限速
+	if config.IngressMbps > 0 || config.EgressMbps > 0 {
+		updateProgress(97, "配置带宽限速...")
+		if err := d.SetBandwidthLimit(ctx, config.Name, config.EgressMbps, config.IngressMbps); err != nil {
+			global.APP_LOG.Warn("配置带宽限速失败", zap.String("name", config.Name), zap.Error(err))
+		}
+	}
+
 	// 获取并更新实例的PrivateIP（确保pmacct配置使用正确的内网IP）
 	updateProgress(97, "获取实例内网IP...")
 	if privateIP, err := d.getContainerPrivateIP(config.Name); err == nil && privateIP != "" {
@@ -566,7 +552,7 @@ func (d *DockerProvider) sshCreateInstanceWithProgress(ctx context.Context, conf
 // sshStartInstance 启动实例
 func (d *DockerProvider) sshStartInstance(ctx context.Context, id string) error {
 	// 先检查容器状态，如果是Exited状态则使用restart命令
-	statusOutput, err := d.sshClient.Execute(fmt.Sprintf("docker inspect %s --format '{{.State.Status}}'", id))
+	statusOutput, err := d.sshClient.Execute(d.cliCommand("inspect %s --format '{{.State.Status}}'", id))
 	if err != nil {
 		global.APP_LOG.Error("检查Docker容器状态失败",
 			zap.String("id", utils.TruncateString(id, 32)),
@@ -576,7 +562,7 @@ func (d *DockerProvider) sshStartInstance(ctx context.Context, id string) error
 
 	status := strings.ToLower(strings.TrimSpace(statusOutput))
 	var startCmd string
-	startCmd = fmt.Sprintf("docker restart %s", id)
+	startCmd = d.cliCommand("restart %s", id)
 	if strings.Contains(status, "exited") {
 		global.APP_LOG.Info("检测到容器为Exited状态，使用restart命令",
 			zap.String("id", utils.TruncateString(id, 32)),
@@ -615,7 +601,7 @@ func (d *DockerProvider) sshStartInstance(ctx context.Context, id string) error
 		time.Sleep(checkInterval)
 
 		// 检查容器状态
-		statusOutput, err := d.sshClient.Execute(fmt.Sprintf("docker inspect %s --format '{{.State.Status}}'", id))
+		statusOutput, err := d.sshClient.Execute(d.cliCommand("inspect %s --format '{{.State.Status}}'", id))
 		if err == nil {
 			currentStatus := strings.ToLower(strings.TrimSpace(statusOutput))
 			if currentStatus == "running" {
@@ -636,7 +622,7 @@ func (d *DockerProvider) sshStartInstance(ctx context.Context, id string) error
 
 // sshStopInstance 停止实例
 func (d *DockerProvider) sshStopInstance(ctx context.Context, id string) error {
-	stopCmd := fmt.Sprintf("docker stop %s", id)
+	stopCmd := d.cliCommand("stop %s", id)
 	global.APP_LOG.Info("开始停止Docker实例",
 		zap.String("id", utils.TruncateString(id, 32)),
 		zap.String("command", stopCmd))
@@ -654,7 +640,7 @@ func (d *DockerProvider) sshStopInstance(ctx context.Context, id string) error {
 	maxRetries := 10
 	retryInterval := 1 * time.Second
 	for i := 0; i < maxRetries; i++ {
-		statusOutput, err := d.sshClient.Execute(fmt.Sprintf("docker inspect %s --format '{{.State.Status}}'", id))
+		statusOutput, err := d.sshClient.Execute(d.cliCommand("inspect %s --format '{{.State.Status}}'", id))
 		if err != nil {
 			global.APP_LOG.Warn("检查Docker容器停止状态失败",
 				zap.String("id", utils.TruncateString(id, 32)),
@@ -686,7 +672,7 @@ func (d *DockerProvider) sshStopInstance(ctx context.Context, id string) error {
 
 // sshRestartInstance 重启实例
 func (d *DockerProvider) sshRestartInstance(ctx context.Context, id string) error {
-	restartCmd := fmt.Sprintf("docker restart %s", id)
+	restartCmd := d.cliCommand("restart %s", id)
 	global.APP_LOG.Info("开始重启Docker实例",
 		zap.String("id", utils.TruncateString(id, 32)),
 		zap.String("command", restartCmd))
@@ -711,7 +697,7 @@ func (d *DockerProvider) sshDeleteInstance(ctx context.Context, id string) error
 		zap.String("id", utils.TruncateString(id, 32)))
 
 	// 预清理：先尝试删除所有同名的已停止容器（Exited状态）
-	cleanupCmd := fmt.Sprintf("docker ps -a --filter name=^%s$ --filter status=exited -q | xargs -r docker rm -f", id)
+	cleanupCmd := d.preDeleteCleanupCommand(id)
 	global.APP_LOG.Debug("清理已停止的同名容器",
 		zap.String("id", utils.TruncateString(id, 32)),
 		zap.String("command", cleanupCmd))
@@ -729,46 +715,9 @@ func (d *DockerProvider) sshDeleteInstance(ctx context.Context, id string) error
 	}
 
 	// 定义多种删除策略，按优先级顺序执行
-	deleteStrategies := []struct {
-		name        string
-		commands    []string
-		description string
-	}{
-		{
-			name: "graceful_stop_and_remove",
-			commands: []string{
-				fmt.Sprintf("docker stop %s", id),
-				fmt.Sprintf("docker rm %s", id),
-			},
-			description: "优雅停止并删除容器",
-		},
-		{
-			name: "force_remove_running",
-			commands: []string{
-				fmt.Sprintf("docker rm -f %s", id),
-			},
-			description: "强制删除正在运行的容器",
-		},
-		{
-			name: "kill_and_remove",
-			commands: []string{
-				fmt.Sprintf("docker kill %s", id),
-				fmt.Sprintf("docker rm %s", id),
-			},
-			description: "强制杀死进程并删除容器",
-		},
-		{
-			name: "system_prune_targeted",
-			commands: []string{
-				fmt.Sprintf("docker rm -f %s", id),
-				"docker system prune -f --volumes",
-			},
-			description: "删除容器并清理系统资源",
-		},
-	}
+	deleteStrategies := d.deleteStrategies(id)
 
-	maxRetries := 3
-	retryDelay := 2 * time.Second
+	maxRetries, retryDelay := deleteStrategyRetry()
 
 	// 尝试每种删除策略
 	for strategyIndex, strategy := range deleteStrategies {
@@ -882,7 +831,7 @@ func (d *DockerProvider) sshDeleteInstance(ctx context.Context, id string) error
 	global.APP_LOG.Info("执行最终清理，删除所有同名已停止容器",
 		zap.String("id", utils.TruncateString(id, 32)))
 
-	finalCleanupCmd := fmt.Sprintf("docker ps -a --filter name=^%s$ -q | xargs -r docker rm -f", id)
+	finalCleanupCmd := d.finalDeleteCleanupCommand(id)
 	finalOutput, finalErr := d.sshClient.Execute(finalCleanupCmd)
 	if finalErr != nil {
 		global.APP_LOG.Debug("最终清理失败（可忽略）",
@@ -907,6 +856,92 @@ func (d *DockerProvider) sshDeleteInstance(ctx context.Context, id string) error
 	return fmt.Errorf("failed to delete container after trying all strategies: %s", id)
 }
 
+// deleteStrategy 删除策略
+type deleteStrategy struct {
+	name        string
+	commands    []string
+	description string
+}
+
+// deleteStrategyRetry 返回每种删除策略的重试次数和重试间隔，未配置时为3次、2秒
+func deleteStrategyRetry() (int, time.Duration) {
+	maxRetries := global.APP_CONFIG.Task.DeleteStrategyRetries
+	if maxRetries <= 0 {
+		maxRetries = 3
+	}
+	retryDelay := time.Duration(global.APP_CONFIG.Task.DeleteStrategyDelay) * time.Second
+	if retryDelay <= 0 {
+		retryDelay = 2 * time.Second
+	}
+	return maxRetries, retryDelay
+}
+
+// deleteStrategies 返回按优先级排列的删除策略
+// system_prune_targeted 会清理宿主机上所有未使用的资源，仅在节点开启 AllowSystemPrune 时使用
+func (d *DockerProvider) deleteStrategies(id string) []deleteStrategy {
+	strategies := []deleteStrategy{
+		{
+			name: "graceful_stop_and_remove",
+			commands: []string{
+				d.cliCommand("stop %s", id),
+				d.cliCommand("rm %s", id),
+			},
+			description: "优雅停止并删除容器",
+		},
+		{
+			name: "force_remove_running",
+			commands: []string{
+				d.cliCommand("rm -f %s", id),
+			},
+			description: "强制删除正在运行的容器",
+		},
+		{
+			name: "kill_and_remove",
+			commands: []string{
+				d.cliCommand("kill %s", id),
+				d.cliCommand("rm %s", id),
+			},
+			description: "强制杀死进程并删除容器",
+		},
+	}
+	if d.config.AllowSystemPrune {
+		strategies = append(strategies, deleteStrategy{
+			name: "system_prune_targeted",
+			commands: []string{
+				d.cliCommand("rm -f %s", id),
+				d.cliCommand("system prune -f --volumes"),
+			},
+			description: "删除容器并清理系统资源",
+		})
+	}
+	return strategies
+}
+
+// preDeleteCleanupCommand 删除前清理同名已停止容器的命令
+func (d *DockerProvider) preDeleteCleanupCommand(id string) string {
+	return d.cliCommand("ps -a --filter name=^%s$ --filter status=exited -q | xargs -r %s rm -f", id, d.cliBinary())
+}
+
+// finalDeleteCleanupCommand 所有策略执行后清理同名容器的命令
+func (d *DockerProvider) finalDeleteCleanupCommand(id string) string {
+	return d.cliCommand("ps -a --filter name=^%s$ -q | xargs -r %s rm -f", id, d.cliBinary())
+}
+
+// PlanDeleteInstance 返回删除实例时将按顺序执行的命令（仅预览，不执行）
+// 后续策略仅在前一策略失败时才会执行
+func (d *DockerProvider) PlanDeleteInstance(ctx context.Context, id string) ([]string, error) {
+	if id == "" {
+		return nil, fmt.Errorf("实例名称不能为空")
+	}
+	commands := []string{d.preDeleteCleanupCommand(id)}
+	for _, strategy := range d.deleteStrategies(id) {
+		commands = append(commands, fmt.Sprintf("# 策略 %s: %s", strategy.name, strategy.description))
+		commands = append(commands, strategy.commands...)
+	}
+	commands = append(commands, d.finalDeleteCleanupCommand(id))
+	return commands, nil
+}
+
 // isAcceptableError 检查是否是可以接受的错误（例如容器已经不存在）
 func (d *DockerProvider) isAcceptableError(err error, output string) bool {
 	errorStr := strings.ToLower(err.Error())
@@ -932,7 +967,7 @@ func (d *DockerProvider) isAcceptableError(err error, output string) bool {
 // verifyContainerDeleted 验证容器是否真的被删除（包括已停止的容器）
 func (d *DockerProvider) verifyContainerDeleted(ctx context.Context, id string) bool {
 	// 方法1：检查运行中的容器
-	checkCmd := fmt.Sprintf("docker inspect %s --format '{{.State.Status}}'", id)
+	checkCmd := d.cliCommand("inspect %s --format '{{.State.Status}}'", id)
 	output, err := d.sshClient.Execute(checkCmd)
 
 	if err != nil {
@@ -961,7 +996,7 @@ func (d *DockerProvider) verifyContainerDeleted(ctx context.Context, id string)
 
 	// 方法2：通过docker ps -a检查所有状态的容器（包括已停止的）
 	// 使用精确匹配的name filter
-	listByNameCmd := fmt.Sprintf("docker ps -a --filter name=^%s$ --format '{{.Names}}:{{.Status}}'", id)
+	listByNameCmd := d.cliCommand("ps -a --filter name=^%s$ --format '{{.Names}}:{{.Status}}'", id)
 	listByNameOutput, listByNameErr := d.sshClient.Execute(listByNameCmd)
 
 	if listByNameErr == nil {
@@ -976,7 +1011,7 @@ func (d *DockerProvider) verifyContainerDeleted(ctx context.Context, id string)
 	}
 
 	// 方法3：用ID进行filter检查
-	listCmd := fmt.Sprintf("docker ps -a --filter id=%s --format '{{.ID}}'", id)
+	listCmd := d.cliCommand("ps -a --filter id=%s --format '{{.ID}}'", id)
 	listOutput, listErr := d.sshClient.Execute(listCmd)
 
 	if listErr == nil && strings.TrimSpace(listOutput) != "" {
@@ -1000,7 +1035,11 @@ func (d *DockerProvider) checkStorageDriver() (bool, string, error) {
 
 	// 如果缓存文件不存在或为空，则通过docker info命令获取
 	if storageDriver == "" {
-		infoCmd := "docker info --format '{{.Driver}}' 2>/dev/null || docker info | grep 'Storage Driver:' | awk '{print $3}'"
+		infoCmd := d.cliCommand("info --format '{{.Driver}}' 2>/dev/null || docker info | grep 'Storage Driver:' | awk '{print $3}'")
+		if d.cliBinary() == "podman" {
+			// podman info 的存储驱动字段路径与docker不同
+			infoCmd = d.cliCommand("info --format '{{.Store.GraphDriverName}}'")
+		}
 		output, err := d.sshClient.Execute(infoCmd)
 		if err != nil {
 			global.APP_LOG.Error("获取Docker存储驱动信息失败",
@@ -1017,8 +1056,18 @@ func (d *DockerProvider) checkStorageDriver() (bool, string, error) {
 	}
 
 	// 检查是否支持硬盘大小限制
-	// 目前只有btrfs存储驱动支持--storage-opt size参数
-	supportsDiskLimit := storageDriver == "btrfs"
+	// btrfs 原生支持 --storage-opt size；overlay2（podman为overlay）仅在Docker根目录所在
+	// 文件系统为xfs且以prjquota/pquota挂载时支持，常见做法是将xfs镜像loop挂载为Docker根目录
+	supportsDiskLimit := false
+	switch storageDriver {
+	case "btrfs":
+		supportsDiskLimit = true
+	case "overlay2", "overlay":
+		supportsDiskLimit = d.checkProjectQuota()
+		if supportsDiskLimit {
+			storageDriver += "+xfs-pquota"
+		}
+	}
 
 	global.APP_LOG.Info("Docker存储驱动检测结果",
 		zap.String("provider", d.config.Name),
@@ -1028,8 +1077,83 @@ func (d *DockerProvider) checkStorageDriver() (bool, string, error) {
 	return supportsDiskLimit, storageDriver, nil
 }
 
-// checkLXCFS 检查LXCFS服务是否可用并返回可用的挂载路径
-func (d *DockerProvider) checkLXCFS() (bool, []string, string, error) {
+// checkProjectQuota 检查Docker根目录是否位于启用项目配额的xfs文件系统上
+// overlay2 依赖 xfs 项目配额实现 --storage-opt size，未启用时容器创建会直接失败
+func (d *DockerProvider) checkProjectQuota() bool {
+	rootCmd := d.cliCommand("info --format '{{.DockerRootDir}}' 2>/dev/null")
+	if d.cliBinary() == "podman" {
+		rootCmd = d.cliCommand("info --format '{{.Store.GraphRoot}}' 2>/dev/null")
+	}
+	rootOutput, err := d.sshClient.Execute(rootCmd)
+	rootDir := strings.TrimSpace(rootOutput)
+	if err != nil || rootDir == "" {
+		rootDir = "/var/lib/docker"
+	}
+
+	mountOutput, err := d.sshClient.Execute(fmt.Sprintf("findmnt -n -o FSTYPE,OPTIONS --target %s 2>/dev/null", utils.ShellQuote(rootDir)))
+	if err != nil {
+		global.APP_LOG.Debug("获取Docker根目录挂载信息失败",
+			zap.String("provider", d.config.Name),
+			zap.String("rootDir", rootDir),
+			zap.Error(err))
+		return false
+	}
+
+	supported := hasXFSProjectQuota(mountOutput)
+	global.APP_LOG.Debug("Docker根目录项目配额检测结果",
+		zap.String("provider", d.config.Name),
+		zap.String("rootDir", rootDir),
+		zap.String("mount", utils.TruncateString(strings.TrimSpace(mountOutput), 200)),
+		zap.Bool("supported", supported))
+	return supported
+}
+
+// hasXFSProjectQuota 解析 findmnt -o FSTYPE,OPTIONS 的输出，判断是否为启用项目配额的xfs
+// 挂载时写 pquota 或 prjquota，内核在挂载选项中统一显示为 prjquota
+func hasXFSProjectQuota(findmntOutput string) bool {
+	fields := strings.Fields(strings.TrimSpace(findmntOutput))
+	if len(fields) < 2 || fields[0] != "xfs" {
+		return false
+	}
+	for _, opt := range strings.Split(fields[1], ",") {
+		if opt == "prjquota" || opt == "pquota" {
+			return true
+		}
+	}
+	return false
+}
+
+// dockerDiskSize 将实例硬盘大小转换为 --storage-opt size 使用的GB值
+// 输入可能是 "1024MB"、"2GB"、"2g" 或不带单位的MB数，MB向上取整到GB，最小1G，无法解析时为1G
+func dockerDiskSize(disk string) string {
+	diskSize := strings.ToLower(strings.TrimSpace(disk))
+
+	mbToGB := func(value string) string {
+		mb, err := strconv.Atoi(value)
+		if err != nil {
+			return "1G"
+		}
+		gb := (mb + 1023) / 1024
+		if gb < 1 {
+			gb = 1
+		}
+		return fmt.Sprintf("%dG", gb)
+	}
+
+	switch {
+	case strings.HasSuffix(diskSize, "mb"):
+		return mbToGB(strings.TrimSuffix(diskSize, "mb"))
+	case strings.HasSuffix(diskSize, "gb"):
+		return strings.TrimSuffix(diskSize, "b")
+	case strings.HasSuffix(diskSize, "g"):
+		return diskSize
+	default:
+		return mbToGB(diskSize)
+	}
+}
+
+// checkLXCFS 检查LXCFS服务是否可用并返回可用的挂载
+func (d *DockerProvider) checkLXCFS() (bool, []provider.VolumeMount, string, error) {
 	// 检查lxcfs服务是否活跃
 	statusCmd := "systemctl is-active lxcfs 2>/dev/null"
 	statusOutput, err := d.sshClient.Execute(statusCmd)
@@ -1049,26 +1173,25 @@ func (d *DockerProvider) checkLXCFS() (bool, []string, string, error) {
 		return false, nil, "LXCFS proc目录不存在", nil
 	}
 
-	// 定义所有可能的LXCFS挂载文件
-	potentialMounts := map[string]string{
-		"/var/lib/lxcfs/proc/cpuinfo":   "/proc/cpuinfo",
-		"/var/lib/lxcfs/proc/diskstats": "/proc/diskstats",
-		"/var/lib/lxcfs/proc/meminfo":   "/proc/meminfo",
-		"/var/lib/lxcfs/proc/stat":      "/proc/stat",
-		"/var/lib/lxcfs/proc/swaps":     "/proc/swaps",
-		"/var/lib/lxcfs/proc/uptime":    "/proc/uptime",
+	// 按Provider配置的挂载列表逐个检查，未配置时使用默认列表
+	mounts, err := provider.ParseLXCFSMounts(d.config.ContainerLXCFSMounts)
+	if err != nil {
+		global.APP_LOG.Warn("LXCFS挂载列表配置无效，使用默认列表",
+			zap.String("provider", d.config.Name),
+			zap.Error(err))
+		mounts = provider.DefaultLXCFSMounts
 	}
 
 	// 逐个检查文件是否存在，只收集存在的文件
-	var availableVolumes []string
+	var availableVolumes []provider.VolumeMount
 	var availableFiles []string
 
-	for hostPath, containerPath := range potentialMounts {
+	for _, containerPath := range mounts {
+		hostPath := provider.LXCFSRoot + containerPath
 		checkCmd := fmt.Sprintf("[ -f '%s' ] && echo 'exists' || echo 'not_exists'", hostPath)
 		output, err := d.sshClient.Execute(checkCmd)
 		if err == nil && strings.TrimSpace(output) == "exists" {
-			volumeMount := fmt.Sprintf("--volume %s:%s:rw", hostPath, containerPath)
-			availableVolumes = append(availableVolumes, volumeMount)
+			availableVolumes = append(availableVolumes, provider.VolumeMount{Source: hostPath, Target: containerPath})
 			availableFiles = append(availableFiles, hostPath)
 		} else {
 			global.APP_LOG.Debug("LXCFS文件不存在，跳过挂载",
@@ -1102,7 +1225,7 @@ func (d *DockerProvider) configureInstanceSSHPassword(ctx context.Context, confi
 	// 根据系统类型选择脚本
 	var scriptName string
 	// 检测系统类型
-	output, err := d.sshClient.Execute(fmt.Sprintf("docker exec %s cat /etc/os-release 2>/dev/null | grep ^ID= | cut -d= -f2 | tr -d '\"'", config.Name))
+	output, err := d.sshClient.Execute(d.cliCommand("exec %s cat /etc/os-release 2>/dev/null | grep ^ID= | cut -d= -f2 | tr -d '\"'", config.Name))
 	if err == nil {
 		osType := strings.TrimSpace(strings.ToLower(output))
 		if osType == "alpine" || osType == "openwrt" {
@@ -1124,7 +1247,7 @@ func (d *DockerProvider) configureInstanceSSHPassword(ctx context.Context, confi
 	} else {
 		time.Sleep(3 * time.Second)
 		// 复制脚本到容器
-		copyCmd := fmt.Sprintf("docker cp %s %s:/root/", scriptPath, config.Name)
+		copyCmd := d.cliCommand("cp %s %s:/root/", scriptPath, config.Name)
 		_, err = d.sshClient.Execute(copyCmd)
 		if err != nil {
 			global.APP_LOG.Warn("复制SSH脚本到容器失败",
@@ -1133,12 +1256,12 @@ func (d *DockerProvider) configureInstanceSSHPassword(ctx context.Context, confi
 				zap.Error(err))
 		} else {
 			// 设置脚本权限
-			_, err = d.sshClient.Execute(fmt.Sprintf("docker exec %s chmod +x /root/%s", config.Name, scriptName))
+			_, err = d.sshClient.Execute(d.cliCommand("exec %s chmod +x /root/%s", config.Name, scriptName))
 			if err != nil {
 				global.APP_LOG.Warn("设置脚本权限失败", zap.Error(err))
 			} else {
 				// 执行脚本配置SSH和密码
-				execCmd := fmt.Sprintf("docker exec %s /root/%s %s", config.Name, scriptName, password)
+				execCmd := d.cliCommand("exec %s /root/%s %s", config.Name, scriptName, password)
 				_, execErr := d.sshClient.Execute(execCmd)
 				if execErr != nil {
 					global.APP_LOG.Warn("执行SSH配置脚本失败，将使用直接设置密码",
@@ -1152,7 +1275,7 @@ func (d *DockerProvider) configureInstanceSSHPassword(ctx context.Context, confi
 	}
 
 	// 直接使用docker exec设置密码
-	directPasswordCmd := fmt.Sprintf("docker exec %s bash -c 'echo \"root:%s\" | chpasswd'", config.Name, password)
+	directPasswordCmd := d.cliCommand("exec %s bash -c 'echo \"root:%s\" | chpasswd'", config.Name, password)
 	_, err = d.sshClient.Execute(directPasswordCmd)
 	if err != nil {
 		global.APP_LOG.Error("设置容器密码失败",
@@ -1182,7 +1305,7 @@ func (d *DockerProvider) configureInstanceSSHPassword(ctx context.Context, confi
 
 // getContainerPrivateIP 获取容器的内网IP地址
 func (d *DockerProvider) getContainerPrivateIP(containerName string) (string, error) {
-	cmd := fmt.Sprintf("docker inspect %s --format '{{range $net, $config := .NetworkSettings.Networks}}{{$config.IPAddress}}{{end}}'", containerName)
+	cmd := d.cliCommand("inspect %s --format '{{range $net, $config := .NetworkSettings.Networks}}{{$config.IPAddress}}{{end}}'", containerName)
 	output, err := d.sshClient.Execute(cmd)
 	if err != nil {
 		return "", fmt.Errorf("failed to get container IP: %w", err)
@@ -1191,7 +1314,7 @@ func (d *DockerProvider) getContainerPrivateIP(containerName string) (string, er
 	ipAddress := strings.TrimSpace(output)
 	if ipAddress == "" || ipAddress == "<no value>" {
 		// 尝试使用默认网络
-		cmd = fmt.Sprintf("docker inspect %s --format '{{.NetworkSettings.IPAddress}}'", containerName)
+		cmd = d.cliCommand("inspect %s --format '{{.NetworkSettings.IPAddress}}'", containerName)
 		output, err = d.sshClient.Execute(cmd)
 		if err != nil {
 			return "", fmt.Errorf("failed to get container IP from default network: %w", err)
@@ -1237,6 +1360,12 @@ func (d *DockerProvider) initializePmacctMonitoring(ctx context.Context, config
 			zap.Uint("instanceId", instanceID))
 		return nil
 	}
+	if !instance.EnableMonitoring {
+		global.APP_LOG.Debug("实例已关闭流量监控，跳过Docker容器pmacct监控初始化",
+			zap.String("instanceName", config.Name),
+			zap.Uint("instanceId", instanceID))
+		return nil
+	}
 
 	global.APP_LOG.Info("开始初始化Docker容器pmacct监控",
 		zap.String("instanceName", config.Name))
@@ -0,0 +1,79 @@
+package docker
+
+import "testing"
+
+// TestDockerDiskSize 测试硬盘大小到 --storage-opt size 的单位转换
+func TestDockerDiskSize(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "MB整数倍", input: "2048MB", expected: "2G"},
+		{name: "MB向上取整", input: "1025MB", expected: "2G"},
+		{name: "MB小于1GB", input: "512mb", expected: "1G"},
+		{name: "MB为0", input: "0MB", expected: "1G"},
+		{name: "GB单位", input: "10GB", expected: "10g"},
+		{name: "G单位", input: "5g", expected: "5g"},
+		{name: "无单位按MB处理", input: "3072", expected: "3G"},
+		{name: "无单位向上取整", input: "1", expected: "1G"},
+		{name: "带空白", input: " 2048MB ", expected: "2G"},
+		{name: "无法解析", input: "abcMB", expected: "1G"},
+		{name: "无法解析无单位", input: "large", expected: "1G"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := dockerDiskSize(tt.input); got != tt.expected {
+				t.Errorf("dockerDiskSize(%q) = %q, 期望 %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestHasXFSProjectQuota 测试 findmnt 输出的项目配额检测
+func TestHasXFSProjectQuota(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected bool
+	}{
+		{name: "xfs启用prjquota", input: "xfs    rw,relatime,attr2,inode64,logbufs=8,logbsize=32k,prjquota\n", expected: true},
+		{name: "xfs启用pquota", input: "xfs rw,pquota", expected: true},
+		{name: "xfs未启用项目配额", input: "xfs rw,relatime,attr2,inode64,noquota", expected: false},
+		{name: "xfs仅用户配额", input: "xfs rw,usrquota", expected: false},
+		{name: "ext4", input: "ext4 rw,relatime,prjquota", expected: false},
+		{name: "空输出", input: "", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hasXFSProjectQuota(tt.input); got != tt.expected {
+				t.Errorf("hasXFSProjectQuota(%q) = %v, 期望 %v", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestDeleteStrategiesSystemPrune 测试 system prune 删除策略仅在节点开启时使用
+func TestDeleteStrategiesSystemPrune(t *testing.T) {
+	hasPrune := func(strategies []deleteStrategy) bool {
+		for _, strategy := range strategies {
+			if strategy.name == "system_prune_targeted" {
+				return true
+			}
+		}
+		return false
+	}
+
+	d := &DockerProvider{}
+	if strategies := d.deleteStrategies("test"); hasPrune(strategies) || len(strategies) != 3 {
+		t.Errorf("未开启AllowSystemPrune时不应包含prune策略，实际策略数 %d", len(strategies))
+	}
+
+	d.config.AllowSystemPrune = true
+	strategies := d.deleteStrategies("test")
+	if !hasPrune(strategies) || strategies[len(strategies)-1].name != "system_prune_targeted" {
+		t.Errorf("开启AllowSystemPrune后prune策略应作为最后一种策略")
+	}
+}
@@ -0,0 +1,54 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"oneclickvirt/global"
+	"oneclickvirt/provider"
+
+	"go.uber.org/zap"
+)
+
+// UpdateInstanceLimits 在线调整容器的CPU和内存限制，docker update 对运行中的容器立即生效
+func (d *DockerProvider) UpdateInstanceLimits(ctx context.Context, instanceName string, cpu int, memoryMB int64) error {
+	if !d.connected || d.sshClient == nil {
+		return fmt.Errorf("not connected")
+	}
+	if err := provider.ValidateInstanceLimits(cpu, memoryMB); err != nil {
+		return err
+	}
+
+	// --memory-swap 为内存与swap之和，已设置时必须随内存一起调整，否则Docker会拒绝超过原swap上限的内存
+	inspectCmd := d.cliCommand("inspect %s --format '{{.HostConfig.Memory}} {{.HostConfig.MemorySwap}}'", instanceName)
+	output, err := d.sshClient.Execute(inspectCmd)
+	if err != nil {
+		return fmt.Errorf("获取容器资源配置失败: %s: %w", strings.TrimSpace(output), err)
+	}
+	fields := strings.Fields(output)
+	if len(fields) != 2 {
+		return fmt.Errorf("解析容器资源配置失败: %s", strings.TrimSpace(output))
+	}
+	currentMemory, err1 := strconv.ParseInt(fields[0], 10, 64)
+	currentSwap, err2 := strconv.ParseInt(fields[1], 10, 64)
+	if err1 != nil || err2 != nil {
+		return fmt.Errorf("解析容器资源配置失败: %s", strings.TrimSpace(output))
+	}
+
+	cmd := d.cliCommand("update --cpus=%d --memory=%dm", cpu, memoryMB)
+	if swap := provider.DockerMemorySwapForUpdate(currentMemory, currentSwap, memoryMB); swap != "" {
+		cmd += fmt.Sprintf(" --memory-swap=%s", swap)
+	}
+	cmd += " " + instanceName
+	if output, err := d.sshClient.Execute(cmd); err != nil {
+		return fmt.Errorf("调整容器资源限制失败: %s: %w", strings.TrimSpace(output), err)
+	}
+
+	global.APP_LOG.Info("容器资源限制已更新",
+		zap.String("instance", instanceName),
+		zap.Int("cpu", cpu),
+		zap.Int64("memoryMB", memoryMB))
+	return nil
+}
@@ -0,0 +1,27 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"oneclickvirt/provider"
+)
+
+// GetInstanceLogs 读取容器的最近日志，since非零时只返回该时间之后的日志
+func (d *DockerProvider) GetInstanceLogs(ctx context.Context, instanceName string, lines int, since time.Time) (string, error) {
+	if !d.connected || d.sshClient == nil {
+		return "", fmt.Errorf("not connected")
+	}
+
+	args := fmt.Sprintf("logs --tail %d", provider.ClampLogLines(lines))
+	if !since.IsZero() {
+		args += fmt.Sprintf(" --since %d", since.Unix())
+	}
+	cmd := d.cliCommand("%s %s 2>&1", args, instanceName)
+	result, err := d.sshClient.ExecuteCapture(cmd, provider.InstanceLogTimeout, provider.InstanceLogMaxOutput)
+	if err != nil {
+		return "", err
+	}
+	return provider.InstanceLogOutput(result)
+}
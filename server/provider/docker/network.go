@@ -0,0 +1,191 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"oneclickvirt/global"
+	"oneclickvirt/provider"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+)
+
+// validateExtraNetworks 创建容器前检查额外网卡引用的网络是否存在
+// docker network connect 不支持指定MAC地址，配置了MAC时直接失败
+func (d *DockerProvider) validateExtraNetworks(nics []provider.NetworkInterfaceConfig) error {
+	for _, nic := range nics {
+		if nic.MACAddress != "" {
+			return fmt.Errorf("%s节点的额外网卡不支持指定MAC地址", d.cliBinary())
+		}
+		if _, err := d.sshClient.Execute(d.cliCommand("network inspect %s", utils.ShellQuote(nic.Network))); err != nil {
+			return fmt.Errorf("%s网络 %s 不存在", d.cliBinary(), nic.Network)
+		}
+	}
+	return nil
+}
+
+// attachExtraNetworks 将容器连接到额外网络，容器内依次出现为eth1、eth2...
+func (d *DockerProvider) attachExtraNetworks(containerName string, nics []provider.NetworkInterfaceConfig) error {
+	for idx, nic := range nics {
+		cmd := d.cliCommand("network connect")
+		if nic.IPAddress != "" {
+			ip, _, err := provider.SplitInterfaceAddress(nic.IPAddress)
+			if err != nil {
+				return err
+			}
+			if ip.To4() != nil {
+				cmd += fmt.Sprintf(" --ip %s", ip)
+			} else {
+				cmd += fmt.Sprintf(" --ip6 %s", ip)
+			}
+		}
+		cmd += fmt.Sprintf(" %s %s", utils.ShellQuote(nic.Network), containerName)
+
+		output, err := d.sshClient.Execute(cmd)
+		if err != nil {
+			return fmt.Errorf("连接网络 %s 失败: %s", nic.Network, utils.TruncateString(strings.TrimSpace(output), 200))
+		}
+		global.APP_LOG.Info("已为容器附加额外网卡",
+			zap.String("name", utils.TruncateString(containerName, 32)),
+			zap.String("network", nic.Network),
+			zap.Int("index", idx+1))
+	}
+	return nil
+}
+
+// ExtraInterfaceNames 返回容器额外网卡（eth1起）在宿主机侧的veth接口名称
+func (d *DockerProvider) ExtraInterfaceNames(ctx context.Context, instanceName string, count int) ([]string, error) {
+	var names []string
+	for idx := 1; idx <= count; idx++ {
+		output, err := d.ExecuteSSHCommand(ctx, d.interfaceVethLookupCommand(instanceName, fmt.Sprintf("eth%d", idx)))
+		if err != nil {
+			return nil, fmt.Errorf("获取eth%d的veth接口失败: %w", idx, err)
+		}
+		if name := strings.TrimSpace(output); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// cliNetwork network inspect 的输出结构，同时兼容Docker和Podman的字段名
+// （encoding/json匹配字段名时不区分大小写）
+type cliNetwork struct {
+	Name        string `json:"Name"`
+	ID          string `json:"Id"`
+	Driver      string `json:"Driver"`
+	Scope       string `json:"Scope"`
+	Internal    bool   `json:"Internal"`
+	EnableIPv6  bool   `json:"EnableIPv6"`   // Docker
+	IPv6Enabled bool   `json:"ipv6_enabled"` // Podman
+	IPAM        struct {
+		Config []struct {
+			Subnet string `json:"Subnet"`
+		} `json:"Config"`
+	} `json:"IPAM"` // Docker
+	Subnets []struct {
+		Subnet string `json:"subnet"`
+	} `json:"subnets"` // Podman
+}
+
+// ListNetworks 列出节点上的网络
+func (d *DockerProvider) ListNetworks(ctx context.Context) ([]provider.Network, error) {
+	if !d.connected || d.sshClient == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+
+	output, err := d.sshClient.Execute(d.cliCommand("network ls -q | xargs -r %s network inspect", d.cliBinary()))
+	if err != nil {
+		return nil, fmt.Errorf("获取网络列表失败: %s", utils.TruncateString(strings.TrimSpace(output), 200))
+	}
+	output = strings.TrimSpace(output)
+	if output == "" {
+		return []provider.Network{}, nil
+	}
+
+	var networks []cliNetwork
+	if err := json.Unmarshal([]byte(output), &networks); err != nil {
+		return nil, fmt.Errorf("解析网络列表失败: %w", err)
+	}
+
+	result := make([]provider.Network, 0, len(networks))
+	for _, n := range networks {
+		item := provider.Network{
+			Name:     n.Name,
+			ID:       utils.TruncateString(n.ID, 12),
+			Driver:   n.Driver,
+			Scope:    n.Scope,
+			Subnets:  []string{},
+			IPv6:     n.EnableIPv6 || n.IPv6Enabled,
+			Internal: n.Internal,
+		}
+		for _, cfg := range n.IPAM.Config {
+			if cfg.Subnet != "" {
+				item.Subnets = append(item.Subnets, cfg.Subnet)
+			}
+		}
+		for _, subnet := range n.Subnets {
+			if subnet.Subnet != "" {
+				item.Subnets = append(item.Subnets, subnet.Subnet)
+			}
+		}
+		result = append(result, item)
+	}
+	return result, nil
+}
+
+// CreateNetwork 创建用户自定义的bridge网络，可用于隔离不同分组的容器
+func (d *DockerProvider) CreateNetwork(ctx context.Context, cfg provider.NetworkCreateConfig) error {
+	if !d.connected || d.sshClient == nil {
+		return fmt.Errorf("not connected")
+	}
+	if err := provider.ValidateNetworkCreateConfig(cfg); err != nil {
+		return err
+	}
+	if d.networkExists(cfg.Name) {
+		return fmt.Errorf("网络 %s 已存在", cfg.Name)
+	}
+
+	cmd := d.cliCommand("network create --driver bridge --label oneclickvirt.managed=true")
+	if cfg.Subnet != "" {
+		cmd += fmt.Sprintf(" --subnet %s", cfg.Subnet)
+	}
+	if cfg.Gateway != "" {
+		cmd += fmt.Sprintf(" --gateway %s", cfg.Gateway)
+	}
+	if cfg.Internal {
+		cmd += " --internal"
+	}
+	cmd += " " + utils.ShellQuote(cfg.Name)
+
+	output, err := d.sshClient.Execute(cmd)
+	if err != nil {
+		return fmt.Errorf("创建网络失败: %s", utils.TruncateString(strings.TrimSpace(output), 200))
+	}
+	global.APP_LOG.Info("已创建容器网络",
+		zap.String("provider", d.config.Name),
+		zap.String("network", cfg.Name),
+		zap.String("subnet", cfg.Subnet),
+		zap.Bool("internal", cfg.Internal))
+	return nil
+}
+
+// networkExists 检查网络是否存在
+func (d *DockerProvider) networkExists(name string) bool {
+	_, err := d.sshClient.Execute(d.cliCommand("network inspect %s", utils.ShellQuote(name)))
+	return err == nil
+}
+
+// validatePrimaryNetwork 创建容器前检查主网卡指定的网络是否存在
+func (d *DockerProvider) validatePrimaryNetwork(name string) error {
+	if err := provider.ValidateNetworkName(name); err != nil {
+		return err
+	}
+	if !d.networkExists(name) {
+		return fmt.Errorf("%s网络 %s 不存在", d.cliBinary(), name)
+	}
+	return nil
+}
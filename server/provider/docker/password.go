@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"oneclickvirt/global"
+	"oneclickvirt/provider"
 	"oneclickvirt/utils"
 
 	"go.uber.org/zap"
@@ -14,6 +15,10 @@ import (
 
 // SetInstancePassword 设置实例密码
 func (d *DockerProvider) SetInstancePassword(ctx context.Context, instanceID, password string) error {
+	if err := provider.ValidateInstanceName(instanceID); err != nil {
+		return err
+	}
+
 	if !d.connected {
 		return fmt.Errorf("provider not connected")
 	}
@@ -24,6 +29,10 @@ func (d *DockerProvider) SetInstancePassword(ctx context.Context, instanceID, pa
 
 // ResetInstancePassword 重置实例密码
 func (d *DockerProvider) ResetInstancePassword(ctx context.Context, instanceID string) (string, error) {
+	if err := provider.ValidateInstanceName(instanceID); err != nil {
+		return "", err
+	}
+
 	if !d.connected {
 		return "", fmt.Errorf("provider not connected")
 	}
@@ -54,7 +63,7 @@ func (d *DockerProvider) sshSetInstancePassword(ctx context.Context, instanceID,
 	var containerStatus string
 	maxRetries := 3
 	for i := 0; i < maxRetries; i++ {
-		checkCmd := fmt.Sprintf("docker inspect %s --format '{{.State.Status}}'", instanceID)
+		checkCmd := d.cliCommand("inspect %s --format '{{.State.Status}}'", instanceID)
 		output, err := d.sshClient.Execute(checkCmd)
 		if err != nil {
 			global.APP_LOG.Error("检查容器状态失败",
@@ -100,7 +109,7 @@ func (d *DockerProvider) sshSetInstancePassword(ctx context.Context, instanceID,
 	}
 
 	// 额外检查容器是否真正可用（测试基础命令）
-	healthCheckCmd := fmt.Sprintf("docker exec %s echo 'container_ready' 2>/dev/null", instanceID)
+	healthCheckCmd := d.cliCommand("exec %s echo 'container_ready' 2>/dev/null", instanceID)
 	healthOutput, err := d.sshClient.Execute(healthCheckCmd)
 	if err != nil || !strings.Contains(healthOutput, "container_ready") {
 		global.APP_LOG.Warn("容器健康检查失败，再等待一段时间",
@@ -120,7 +129,7 @@ func (d *DockerProvider) sshSetInstancePassword(ctx context.Context, instanceID,
 	}
 
 	// 检查SSH相关进程和服务是否可用（更具体的就绪检查）
-	sshReadinessCmd := fmt.Sprintf("docker exec %s sh -c 'command -v passwd >/dev/null 2>&1 && echo ssh_ready' 2>/dev/null", instanceID)
+	sshReadinessCmd := d.cliCommand("exec %s sh -c 'command -v passwd >/dev/null 2>&1 && echo ssh_ready' 2>/dev/null", instanceID)
 	sshOutput, err := d.sshClient.Execute(sshReadinessCmd)
 	if err != nil || !strings.Contains(sshOutput, "ssh_ready") {
 		global.APP_LOG.Warn("SSH服务未就绪，等待初始化",
@@ -158,7 +167,7 @@ func (d *DockerProvider) sshSetInstancePassword(ctx context.Context, instanceID,
 		zap.String("instanceID", utils.TruncateString(instanceID, 12)))
 
 	// 检测容器操作系统类型
-	osCmd := fmt.Sprintf("docker exec %s cat /etc/os-release 2>/dev/null | grep -E '^ID=' | cut -d '=' -f 2 | tr -d '\"'", instanceID)
+	osCmd := d.cliCommand("exec %s cat /etc/os-release 2>/dev/null | grep -E '^ID=' | cut -d '=' -f 2 | tr -d '\"'", instanceID)
 	osOutput, err := d.sshClient.Execute(osCmd)
 	osType := strings.TrimSpace(osOutput)
 	if err != nil || osType == "" {
@@ -197,7 +206,7 @@ func (d *DockerProvider) sshSetInstancePassword(ctx context.Context, instanceID,
 	}
 
 	// 检查容器内是否已存在SSH脚本
-	checkScriptCmd := fmt.Sprintf("docker exec %s %s -c '[ -f /%s ]'", instanceID, shellType, scriptName)
+	checkScriptCmd := d.cliCommand("exec %s %s -c '[ -f /%s ]'", instanceID, shellType, scriptName)
 	_, err = d.sshClient.Execute(checkScriptCmd)
 
 	if err != nil {
@@ -207,7 +216,7 @@ func (d *DockerProvider) sshSetInstancePassword(ctx context.Context, instanceID,
 			zap.String("scriptName", scriptName))
 
 		// 复制脚本到容器内
-		copyCmd := fmt.Sprintf("docker cp \"%s\" \"%s:/%s\"", hostScriptPath, instanceID, scriptName)
+		copyCmd := d.cliCommand("cp \"%s\" \"%s:/%s\"", hostScriptPath, instanceID, scriptName)
 		_, err = d.sshClient.Execute(copyCmd)
 		if err != nil {
 			global.APP_LOG.Error("复制SSH脚本到容器失败",
@@ -218,7 +227,7 @@ func (d *DockerProvider) sshSetInstancePassword(ctx context.Context, instanceID,
 		}
 
 		// 给脚本添加执行权限
-		chmodCmd := fmt.Sprintf("docker exec %s %s -c 'chmod +x /%s'", instanceID, shellType, scriptName)
+		chmodCmd := d.cliCommand("exec %s %s -c 'chmod +x /%s'", instanceID, shellType, scriptName)
 		_, err = d.sshClient.Execute(chmodCmd)
 		if err != nil {
 			global.APP_LOG.Error("设置SSH脚本执行权限失败",
@@ -237,11 +246,11 @@ func (d *DockerProvider) sshSetInstancePassword(ctx context.Context, instanceID,
 	}
 
 	// 设置interactionless环境变量，避免交互式操作
-	envCmd := fmt.Sprintf("docker exec %s %s -c 'export interactionless=true'", instanceID, shellType)
+	envCmd := d.cliCommand("exec %s %s -c 'export interactionless=true'", instanceID, shellType)
 	d.sshClient.Execute(envCmd)
 
 	// 执行SSH配置脚本
-	executeScriptCmd := fmt.Sprintf("docker exec %s %s -c 'interactionless=true %s /%s %s'", instanceID, shellType, shellType, scriptName, password)
+	executeScriptCmd := d.cliCommand("exec %s %s -c 'interactionless=true %s /%s %s'", instanceID, shellType, shellType, scriptName, password)
 	scriptOutput, err := d.sshClient.Execute(executeScriptCmd)
 	if err != nil {
 		global.APP_LOG.Error("执行SSH配置脚本失败",
@@ -253,7 +262,7 @@ func (d *DockerProvider) sshSetInstancePassword(ctx context.Context, instanceID,
 	}
 
 	// 额外使用chpasswd命令确保密码设置
-	setPasswordCmd := fmt.Sprintf("docker exec %s %s -c 'echo \"root:%s\" | chpasswd'", instanceID, shellType, password)
+	setPasswordCmd := d.cliCommand("exec %s %s -c 'echo \"root:%s\" | chpasswd'", instanceID, shellType, password)
 	_, err = d.sshClient.Execute(setPasswordCmd)
 	if err != nil {
 		global.APP_LOG.Error("使用chpasswd设置密码失败",
@@ -270,7 +279,7 @@ func (d *DockerProvider) sshSetInstancePassword(ctx context.Context, instanceID,
 	return nil
 }
 
-// generateRandomPassword 生成随机密码（仅包含数字和大小写英文字母，长度不低于8位）
+// generateRandomPassword 按配置的生成密码策略生成随机密码
 func (d *DockerProvider) generateRandomPassword() string {
 	return utils.GenerateInstancePassword()
 }
@@ -0,0 +1,201 @@
+package docker
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"oneclickvirt/global"
+	"oneclickvirt/provider"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+)
+
+// 列出宿主机上监听中的TCP/UDP端口及所属进程
+const listeningPortsCommand = "ss -Htulnp 2>/dev/null"
+
+// 容器运行时自身的端口代理进程，所属容器已由 ps 的输出覆盖
+var runtimeProxyProcesses = map[string]bool{
+	"docker-proxy": true,
+	"rootlessport": true,
+	"conmon":       true,
+}
+
+// hostPortBinding 实例需要占用的宿主机端口
+type hostPortBinding struct {
+	Port     int
+	Protocol string // tcp 或 udp
+}
+
+func (b hostPortBinding) key() string {
+	return fmt.Sprintf("%d/%s", b.Port, b.Protocol)
+}
+
+// parseHostPortBindings 从端口映射配置中解析需要占用的宿主机端口
+// 支持 0.0.0.0:宿主机端口:容器端口/协议、宿主机端口:容器端口 和单个端口三种格式，both拆分为tcp和udp
+func parseHostPortBindings(ports []string) []hostPortBinding {
+	var bindings []hostPortBinding
+	for _, mapping := range ports {
+		base, protocol, _ := strings.Cut(mapping, "/")
+		parts := strings.Split(base, ":")
+		hostPart := parts[0]
+		if len(parts) >= 2 {
+			hostPart = parts[len(parts)-2]
+		}
+		port, err := strconv.Atoi(hostPart)
+		if err != nil || port <= 0 {
+			continue
+		}
+
+		switch protocol {
+		case "both":
+			bindings = append(bindings, hostPortBinding{port, "tcp"}, hostPortBinding{port, "udp"})
+		case "udp":
+			bindings = append(bindings, hostPortBinding{port, "udp"})
+		default:
+			bindings = append(bindings, hostPortBinding{port, "tcp"})
+		}
+	}
+	return bindings
+}
+
+// parsePublishedPorts 解析 ps --format '{{.Names}}|{{.Ports}}' 的输出，返回"端口/协议"到容器名称的映射
+// 端口格式如 0.0.0.0:10000->22/tcp、:::10001-10003->10001-10003/udp，未发布到宿主机的端口不计入
+func parsePublishedPorts(output string) map[string]string {
+	owners := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		name, portsField, ok := strings.Cut(strings.TrimSpace(line), "|")
+		if !ok || portsField == "" {
+			continue
+		}
+		for _, entry := range strings.Split(portsField, ",") {
+			hostSide, containerSide, ok := strings.Cut(strings.TrimSpace(entry), "->")
+			if !ok {
+				continue
+			}
+			protocol := "tcp"
+			if _, proto, found := strings.Cut(containerSide, "/"); found {
+				protocol = proto
+			}
+			portRange := hostSide[strings.LastIndex(hostSide, ":")+1:]
+			start, end := parsePortRange(portRange)
+			for port := start; port > 0 && port <= end; port++ {
+				owners[fmt.Sprintf("%d/%s", port, protocol)] = name
+			}
+		}
+	}
+	return owners
+}
+
+// parseListeningPorts 解析 ss -Htulnp 的输出，返回"端口/协议"到进程名称的映射
+// 容器运行时的代理进程会被跳过，无法获取进程名称时记为unknown
+func parseListeningPorts(output string) map[string]string {
+	owners := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 5 {
+			continue
+		}
+		protocol := fields[0]
+		if protocol != "tcp" && protocol != "udp" {
+			continue
+		}
+		local := fields[4]
+		port, err := strconv.Atoi(local[strings.LastIndex(local, ":")+1:])
+		if err != nil {
+			continue
+		}
+
+		process := "unknown"
+		if len(fields) > 6 {
+			if _, rest, found := strings.Cut(fields[6], `(("`); found {
+				if name, _, found := strings.Cut(rest, `"`); found {
+					process = name
+				}
+			}
+		}
+		if runtimeProxyProcesses[process] {
+			continue
+		}
+		owners[fmt.Sprintf("%d/%s", port, protocol)] = process
+	}
+	return owners
+}
+
+func parsePortRange(s string) (int, int) {
+	startStr, endStr, isRange := strings.Cut(s, "-")
+	start, err := strconv.Atoi(startStr)
+	if err != nil {
+		return 0, 0
+	}
+	if !isRange {
+		return start, start
+	}
+	end, err := strconv.Atoi(endStr)
+	if err != nil || end < start {
+		return start, start
+	}
+	return start, end
+}
+
+// findPortConflicts 返回已被占用的端口及占用说明，按端口排序
+// 同名容器会在创建前被清理，其占用的端口不视为冲突
+func findPortConflicts(bindings []hostPortBinding, containerOwners, processOwners map[string]string, instanceName string) []string {
+	var conflicts []string
+	seen := make(map[string]bool)
+	sort.Slice(bindings, func(i, j int) bool {
+		if bindings[i].Port != bindings[j].Port {
+			return bindings[i].Port < bindings[j].Port
+		}
+		return bindings[i].Protocol < bindings[j].Protocol
+	})
+	for _, binding := range bindings {
+		key := binding.key()
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if owner, ok := containerOwners[key]; ok {
+			if owner != instanceName {
+				conflicts = append(conflicts, fmt.Sprintf("端口 %s 已被容器 %s 占用", key, owner))
+			}
+			continue
+		}
+		if owner, ok := processOwners[key]; ok {
+			conflicts = append(conflicts, fmt.Sprintf("端口 %s 已被进程 %s 占用", key, owner))
+		}
+	}
+	return conflicts
+}
+
+// checkHostPortConflicts 创建容器前检查端口映射需要的宿主机端口是否已被占用
+// 查询失败时只记录日志，不阻止创建，由 run 命令自身报错
+func (d *DockerProvider) checkHostPortConflicts(config provider.InstanceConfig) error {
+	bindings := parseHostPortBindings(config.Ports)
+	if len(bindings) == 0 {
+		return nil
+	}
+
+	psOutput, err := d.sshClient.Execute(d.cliCommand("ps --format '{{.Names}}|{{.Ports}}'"))
+	if err != nil {
+		global.APP_LOG.Warn("查询容器端口占用失败，跳过端口冲突预检",
+			zap.String("instance", config.Name),
+			zap.String("output", utils.TruncateString(psOutput, 200)),
+			zap.Error(err))
+		return nil
+	}
+	// 未安装ss或权限不足时输出为空，只按容器检查
+	ssOutput, _ := d.sshClient.Execute(listeningPortsCommand)
+
+	conflicts := findPortConflicts(bindings, parsePublishedPorts(psOutput), parseListeningPorts(ssOutput), config.Name)
+	if len(conflicts) == 0 {
+		return nil
+	}
+
+	global.APP_LOG.Warn("端口映射与宿主机已占用端口冲突",
+		zap.String("instance", config.Name),
+		zap.Strings("conflicts", conflicts))
+	return fmt.Errorf("端口冲突，无法创建容器: %s", strings.Join(conflicts, "; "))
+}
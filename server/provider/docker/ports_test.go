@@ -0,0 +1,80 @@
+package docker
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestParseHostPortBindings 测试从端口映射配置解析宿主机端口
+func TestParseHostPortBindings(t *testing.T) {
+	bindings := parseHostPortBindings([]string{
+		"0.0.0.0:10000:22/tcp",
+		"0.0.0.0:10001:53/both",
+		"10002:80/udp",
+		"8080",
+		"invalid",
+	})
+	expected := []hostPortBinding{
+		{10000, "tcp"},
+		{10001, "tcp"},
+		{10001, "udp"},
+		{10002, "udp"},
+		{8080, "tcp"},
+	}
+	if !reflect.DeepEqual(bindings, expected) {
+		t.Fatalf("解析结果错误: %+v", bindings)
+	}
+}
+
+// TestParsePublishedPorts 测试解析容器已发布的端口，包括端口段和未发布的端口
+func TestParsePublishedPorts(t *testing.T) {
+	output := "web|0.0.0.0:10000->22/tcp, :::10000->22/tcp, 0.0.0.0:10001-10003->10001-10003/udp\n" +
+		"db|3306/tcp\n" +
+		"idle|\n"
+	owners := parsePublishedPorts(output)
+	for _, key := range []string{"10000/tcp", "10001/udp", "10002/udp", "10003/udp"} {
+		if owners[key] != "web" {
+			t.Fatalf("端口 %s 应属于容器web: %v", key, owners)
+		}
+	}
+	if _, ok := owners["3306/tcp"]; ok {
+		t.Fatalf("未发布的端口不应计入: %v", owners)
+	}
+}
+
+// TestParseListeningPorts 测试解析宿主机监听端口并跳过容器运行时代理
+func TestParseListeningPorts(t *testing.T) {
+	output := `tcp   LISTEN 0      4096         0.0.0.0:22        0.0.0.0:*    users:(("sshd",pid=812,fd=3))
+tcp   LISTEN 0      4096         0.0.0.0:10000     0.0.0.0:*    users:(("docker-proxy",pid=901,fd=4))
+udp   UNCONN 0      0            [::]:5353         [::]:*
+`
+	owners := parseListeningPorts(output)
+	if owners["22/tcp"] != "sshd" {
+		t.Fatalf("22/tcp 应属于sshd: %v", owners)
+	}
+	if _, ok := owners["10000/tcp"]; ok {
+		t.Fatalf("docker-proxy 不应计入: %v", owners)
+	}
+	if owners["5353/udp"] != "unknown" {
+		t.Fatalf("无进程信息时应记为unknown: %v", owners)
+	}
+}
+
+// TestFindPortConflicts 测试端口冲突判断，同名容器不视为冲突
+func TestFindPortConflicts(t *testing.T) {
+	bindings := []hostPortBinding{{10001, "tcp"}, {22, "tcp"}, {10000, "tcp"}, {10002, "tcp"}}
+	containers := map[string]string{"10000/tcp": "other", "10001/tcp": "self"}
+	processes := map[string]string{"22/tcp": "sshd"}
+
+	conflicts := findPortConflicts(bindings, containers, processes, "self")
+	if len(conflicts) != 2 {
+		t.Fatalf("期望2个冲突，实际: %v", conflicts)
+	}
+	if !strings.Contains(conflicts[0], "22/tcp") || !strings.Contains(conflicts[0], "sshd") {
+		t.Fatalf("冲突应按端口排序并包含进程名: %v", conflicts)
+	}
+	if !strings.Contains(conflicts[1], "other") {
+		t.Fatalf("冲突应包含容器名: %v", conflicts)
+	}
+}
@@ -0,0 +1,30 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+
+	"oneclickvirt/global"
+	"oneclickvirt/provider"
+
+	"go.uber.org/zap"
+)
+
+// PrewarmImages 预先下载并加载镜像，避免首次创建实例时等待镜像下载
+func (d *DockerProvider) PrewarmImages(ctx context.Context, images []provider.PrewarmImage) ([]provider.PrewarmResult, error) {
+	if !d.connected || d.sshClient == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+
+	if err := d.ensureSSHScriptsAvailable(d.config.Country); err != nil {
+		global.APP_LOG.Warn("确保SSH脚本可用失败，继续预热镜像", zap.Error(err))
+	}
+
+	noProgress := func(int, string) {}
+	return provider.PrewarmEach(ctx, images, func(image provider.PrewarmImage) error {
+		if image.InstanceType == "vm" {
+			return fmt.Errorf("Docker不支持虚拟机镜像")
+		}
+		return d.ensureImageLoaded(image.Name, image.URL, image.SHA256, image.UseCDN, noProgress)
+	}), nil
+}
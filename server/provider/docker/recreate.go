@@ -0,0 +1,103 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"oneclickvirt/global"
+	"oneclickvirt/provider"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+)
+
+// recreateMountsFormat 列出容器的数据卷挂载（不含绑定挂载），每行为 卷名|挂载路径|是否可写
+const recreateMountsFormat = `{{range .Mounts}}{{if eq .Type "volume"}}{{.Name}}|{{.Destination}}|{{.RW}}{{"\n"}}{{end}}{{end}}`
+
+// RecreateInstance 按创建时的配置重建容器，保留原容器的数据卷
+// 原容器先停止并改名，新容器挂载原容器的数据卷（包括镜像声明的匿名卷）和配置中的宿主机目录；
+// 新容器创建失败时删除新容器并恢复原容器，成功后删除原容器但不删除其数据卷。
+// 容器可写层中不在数据卷内的数据不会保留
+func (d *DockerProvider) RecreateInstance(ctx context.Context, config provider.InstanceConfig) error {
+	if !d.connected || d.sshClient == nil {
+		return fmt.Errorf("not connected")
+	}
+	if config.Name == "" {
+		return fmt.Errorf("实例名称不能为空")
+	}
+
+	var preserved []provider.VolumeMount
+	backupName := ""
+	if _, err := d.sshClient.Execute(d.cliCommand("inspect %s >/dev/null 2>&1", config.Name)); err == nil {
+		output, err := d.sshClient.Execute(d.cliCommand("inspect -f '%s' %s", recreateMountsFormat, config.Name))
+		if err != nil {
+			return fmt.Errorf("读取原容器数据卷失败: %w", err)
+		}
+		preserved = preservedVolumes(output, config.Volumes)
+
+		backupName = fmt.Sprintf("%s-recreate-%d", config.Name, time.Now().Unix())
+		d.sshClient.Execute(d.cliCommand("stop %s", config.Name))
+		if output, err := d.sshClient.Execute(d.cliCommand("rename %s %s", config.Name, backupName)); err != nil {
+			return fmt.Errorf("重命名原容器失败: %s: %w", strings.TrimSpace(output), err)
+		}
+	}
+
+	global.APP_LOG.Info("开始重建Docker实例",
+		zap.String("name", utils.TruncateString(config.Name, 32)),
+		zap.String("backupName", backupName),
+		zap.Int("preservedVolumes", len(preserved)))
+
+	if err := d.runInstance(ctx, config, nil, preserved); err != nil {
+		if backupName != "" {
+			d.sshClient.Execute(d.cliCommand("rm -f %s", config.Name))
+			if output, renameErr := d.sshClient.Execute(d.cliCommand("rename %s %s", backupName, config.Name)); renameErr != nil {
+				global.APP_LOG.Error("重建失败后恢复原容器失败",
+					zap.String("name", utils.TruncateString(config.Name, 32)),
+					zap.String("backupName", backupName),
+					zap.String("output", utils.TruncateString(output, 200)),
+					zap.Error(renameErr))
+				return fmt.Errorf("重建容器失败: %w，且恢复原容器失败，原容器保留为 %s", err, backupName)
+			}
+			d.sshClient.Execute(d.cliCommand("start %s", config.Name))
+		}
+		return fmt.Errorf("重建容器失败，已恢复原容器: %w", err)
+	}
+
+	if backupName != "" {
+		// 不带-v删除，数据卷已由新容器使用
+		if output, err := d.sshClient.Execute(d.cliCommand("rm -f %s", backupName)); err != nil {
+			global.APP_LOG.Warn("删除重建前的原容器失败",
+				zap.String("backupName", backupName),
+				zap.String("output", utils.TruncateString(output, 200)),
+				zap.Error(err))
+		}
+	}
+
+	global.APP_LOG.Info("Docker实例重建成功", zap.String("name", utils.TruncateString(config.Name, 32)))
+	return nil
+}
+
+// preservedVolumes 解析原容器的数据卷挂载，跳过配置中已挂载的路径
+func preservedVolumes(output string, configured []provider.VolumeMount) []provider.VolumeMount {
+	targets := make(map[string]bool, len(configured))
+	for _, volume := range configured {
+		targets[volume.Target] = true
+	}
+
+	var volumes []provider.VolumeMount
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.Split(strings.TrimSpace(line), "|")
+		if len(parts) != 3 || parts[0] == "" || parts[1] == "" || targets[parts[1]] {
+			continue
+		}
+		targets[parts[1]] = true
+		volumes = append(volumes, provider.VolumeMount{
+			Source:   parts[0],
+			Target:   parts[1],
+			ReadOnly: parts[2] == "false",
+		})
+	}
+	return volumes
+}
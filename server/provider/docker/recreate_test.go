@@ -0,0 +1,33 @@
+package docker
+
+import (
+	"reflect"
+	"testing"
+
+	"oneclickvirt/provider"
+)
+
+// TestPreservedVolumes 测试解析重建时需要保留的数据卷，配置中已挂载的路径不重复挂载
+func TestPreservedVolumes(t *testing.T) {
+	output := "data|/var/lib/mysql|true\n" +
+		"3f2a9c|/data|true\n" +
+		"conf|/etc/app|false\n" +
+		"shared|/srv/shared|true\n" +
+		"invalid line\n" +
+		"\n"
+	configured := []provider.VolumeMount{{Source: "/opt/shared", Target: "/srv/shared"}}
+
+	volumes := preservedVolumes(output, configured)
+	expected := []provider.VolumeMount{
+		{Source: "data", Target: "/var/lib/mysql"},
+		{Source: "3f2a9c", Target: "/data"},
+		{Source: "conf", Target: "/etc/app", ReadOnly: true},
+	}
+	if !reflect.DeepEqual(volumes, expected) {
+		t.Fatalf("解析结果错误: %+v", volumes)
+	}
+
+	if volumes := preservedVolumes("", nil); len(volumes) != 0 {
+		t.Fatalf("没有数据卷时不应返回挂载: %+v", volumes)
+	}
+}
@@ -0,0 +1,120 @@
+package docker
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"oneclickvirt/global"
+	"oneclickvirt/provider"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+)
+
+// dockerHubAuthKeys 未指定仓库地址时凭据写入的键，Docker使用index地址，Podman使用docker.io
+var dockerHubAuthKeys = []string{"https://index.docker.io/v1/", "docker.io"}
+
+// registryReferencePattern 镜像仓库引用允许的字符，引用会拼接到拉取命令中
+var registryReferencePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._/:@+-]*$`)
+
+// validateRegistryReference 校验镜像仓库引用格式
+func validateRegistryReference(ref string) error {
+	if len(ref) > 255 || !registryReferencePattern.MatchString(ref) || strings.Contains(ref, "//") {
+		return fmt.Errorf("无效的镜像引用: %s", ref)
+	}
+	return nil
+}
+
+// registryAuthConfig 生成仓库凭据配置文件内容，Docker的config.json与Podman的authfile格式相同
+func registryAuthConfig(server, username, password string) (string, error) {
+	auth := map[string]string{"auth": base64.StdEncoding.EncodeToString([]byte(username + ":" + password))}
+	auths := make(map[string]map[string]string)
+	if server == "" {
+		for _, key := range dockerHubAuthKeys {
+			auths[key] = auth
+		}
+	} else {
+		auths[server] = auth
+	}
+	data, err := json.Marshal(map[string]interface{}{"auths": auths})
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// runtimeImageName 返回创建实例时使用的镜像名称，仓库引用原样使用，本地别名添加导入前缀
+func runtimeImageName(image string) string {
+	if provider.IsRegistryReference(image) {
+		return image
+	}
+	return provider.ImageNameWithPrefix(image)
+}
+
+// ensureRegistryImage 确保仓库镜像已存在，不存在时通过pull拉取
+func (d *DockerProvider) ensureRegistryImage(ref string, updateProgress func(int, string)) error {
+	if err := validateRegistryReference(ref); err != nil {
+		return err
+	}
+	if _, err := d.sshClient.Execute(d.cliCommand("image inspect %s >/dev/null 2>&1", utils.ShellQuote(ref))); err == nil {
+		updateProgress(60, "镜像已存在，跳过拉取...")
+		global.APP_LOG.Info("仓库镜像已存在，跳过拉取", zap.String("image", utils.TruncateString(ref, 64)))
+		return nil
+	}
+
+	updateProgress(30, "从镜像仓库拉取镜像...")
+	if err := d.pullRegistryImage(ref); err != nil {
+		return fmt.Errorf("拉取镜像失败: %w", err)
+	}
+	updateProgress(60, "镜像拉取完成...")
+	return nil
+}
+
+// pullRegistryImage 从镜像仓库拉取镜像
+// 配置了仓库凭据时写入临时凭据文件并在拉取后删除，凭据不会出现在命令行和命令记录中
+func (d *DockerProvider) pullRegistryImage(ref string) error {
+	pullCmd := d.cliCommand("pull %s", utils.ShellQuote(ref))
+
+	if d.config.RegistryUsername != "" {
+		authConfig, err := registryAuthConfig(d.config.RegistryServer, d.config.RegistryUsername, d.config.RegistryPassword)
+		if err != nil {
+			return fmt.Errorf("生成仓库凭据失败: %w", err)
+		}
+		output, err := d.sshClient.Execute("mktemp -d /tmp/oneclickvirt-registry-XXXXXX")
+		if err != nil {
+			return fmt.Errorf("创建临时凭据目录失败: %w", err)
+		}
+		authDir := strings.TrimSpace(output)
+		defer d.sshClient.Execute(fmt.Sprintf("rm -rf %s", utils.ShellQuote(authDir)))
+
+		authFile := path.Join(authDir, "config.json")
+		if err := d.sshClient.UploadContent(authConfig, authFile, 0600); err != nil {
+			return fmt.Errorf("写入仓库凭据失败: %w", err)
+		}
+		if d.cliBinary() == "podman" {
+			pullCmd = d.cliCommand("pull --authfile %s %s", utils.ShellQuote(authFile), utils.ShellQuote(ref))
+		} else {
+			pullCmd = d.cliCommand("--config %s pull %s", utils.ShellQuote(authDir), utils.ShellQuote(ref))
+		}
+	}
+
+	global.APP_LOG.Info("开始从镜像仓库拉取镜像",
+		zap.String("image", utils.TruncateString(ref, 64)),
+		zap.Bool("withAuth", d.config.RegistryUsername != ""))
+
+	output, err := d.sshClient.ExecuteOperation(utils.SSHOperationDownload, pullCmd)
+	if err != nil {
+		global.APP_LOG.Error("从镜像仓库拉取镜像失败",
+			zap.String("image", utils.TruncateString(ref, 64)),
+			zap.String("output", utils.TruncateString(output, 500)),
+			zap.Error(err))
+		return err
+	}
+
+	global.APP_LOG.Info("从镜像仓库拉取镜像成功", zap.String("image", utils.TruncateString(ref, 64)))
+	return nil
+}
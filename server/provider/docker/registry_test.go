@@ -0,0 +1,57 @@
+package docker
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+)
+
+// TestValidateRegistryReference 测试仓库引用格式校验
+func TestValidateRegistryReference(t *testing.T) {
+	valid := []string{"nginx:1.25", "ghcr.io/org/app:v1", "localhost:5000/app", "alpine@sha256:abc123"}
+	for _, ref := range valid {
+		if err := validateRegistryReference(ref); err != nil {
+			t.Errorf("validateRegistryReference(%q) 返回错误: %v", ref, err)
+		}
+	}
+	invalid := []string{"nginx:1.25; rm -rf /", "-rm/app", "a//b", "app $(id)", "app:'x'"}
+	for _, ref := range invalid {
+		if err := validateRegistryReference(ref); err == nil {
+			t.Errorf("validateRegistryReference(%q) 期望返回错误", ref)
+		}
+	}
+}
+
+// TestRegistryAuthConfig 测试仓库凭据文件内容
+func TestRegistryAuthConfig(t *testing.T) {
+	parse := func(content string) map[string]map[string]string {
+		var config struct {
+			Auths map[string]map[string]string `json:"auths"`
+		}
+		if err := json.Unmarshal([]byte(content), &config); err != nil {
+			t.Fatalf("解析凭据文件失败: %v", err)
+		}
+		return config.Auths
+	}
+	expectAuth := base64.StdEncoding.EncodeToString([]byte("user:p@ss:word"))
+
+	content, err := registryAuthConfig("registry.example.com", "user", "p@ss:word")
+	if err != nil {
+		t.Fatalf("registryAuthConfig() 返回错误: %v", err)
+	}
+	auths := parse(content)
+	if len(auths) != 1 || auths["registry.example.com"]["auth"] != expectAuth {
+		t.Errorf("registryAuthConfig() = %s, 期望仅包含 registry.example.com", content)
+	}
+
+	content, err = registryAuthConfig("", "user", "p@ss:word")
+	if err != nil {
+		t.Fatalf("registryAuthConfig() 返回错误: %v", err)
+	}
+	auths = parse(content)
+	for _, key := range dockerHubAuthKeys {
+		if auths[key]["auth"] != expectAuth {
+			t.Errorf("registryAuthConfig() Docker Hub凭据缺少 %s", key)
+		}
+	}
+}
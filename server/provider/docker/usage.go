@@ -0,0 +1,70 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"oneclickvirt/provider"
+)
+
+// GetInstanceUsage 获取容器实时CPU和磁盘使用率
+// 容器内/proc/stat反映的是宿主机数据，CPU使用率取自stats并按--cpus限制归一化
+func (d *DockerProvider) GetInstanceUsage(ctx context.Context, instanceName string) (*provider.InstanceUsage, error) {
+	if !d.connected || d.sshClient == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+
+	statsOutput, err := d.sshClient.Execute(d.cliCommand("stats --no-stream --format '{{.CPUPerc}}' %s", instanceName))
+	if err != nil {
+		return nil, fmt.Errorf("获取容器CPU使用率失败: %s: %w", strings.TrimSpace(statsOutput), err)
+	}
+	cpuPercent, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(statsOutput), "%"), 64)
+	if err != nil {
+		return nil, fmt.Errorf("解析容器CPU使用率失败: %s", strings.TrimSpace(statsOutput))
+	}
+
+	// stats的CPU百分比以单核为100%，按容器CPU限制换算
+	if nanoOutput, err := d.sshClient.Execute(d.cliCommand("inspect -f '{{.HostConfig.NanoCpus}}' %s", instanceName)); err == nil {
+		if nanoCPUs, err := strconv.ParseInt(strings.TrimSpace(nanoOutput), 10, 64); err == nil && nanoCPUs > 0 {
+			cpuPercent = cpuPercent * 1e9 / float64(nanoCPUs)
+		}
+	}
+
+	usage := &provider.InstanceUsage{CPUPercent: cpuPercent}
+	dfOutput, err := d.sshClient.Execute(d.cliCommand("exec %s sh -c 'df -Pm / | tail -n1'", instanceName))
+	if err == nil {
+		fields := strings.Fields(strings.TrimSpace(dfOutput))
+		if len(fields) >= 6 {
+			total, totalErr := strconv.ParseInt(fields[1], 10, 64)
+			used, usedErr := strconv.ParseInt(fields[2], 10, 64)
+			if totalErr == nil && usedErr == nil && total > 0 {
+				usage.DiskTotalMB = total
+				usage.DiskUsedMB = used
+				usage.DiskPercent = float64(used) * 100 / float64(total)
+			}
+		}
+	}
+
+	return usage, nil
+}
+
+// GetInstanceDiskUsage 获取容器占用的磁盘空间
+// 通过inspect --size只统计该容器的可写层，比 system df -v 遍历所有容器和镜像开销小；
+// --storage-opt size 限制的也是可写层，因此总量由调用方按实例配置的磁盘大小计算
+func (d *DockerProvider) GetInstanceDiskUsage(ctx context.Context, instanceName string) (*provider.DiskUsage, error) {
+	if !d.connected || d.sshClient == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+
+	output, err := d.sshClient.Execute(d.cliCommand("inspect --size -f '{{.SizeRw}}' %s", instanceName))
+	if err != nil {
+		return nil, fmt.Errorf("获取容器磁盘使用量失败: %s: %w", strings.TrimSpace(output), err)
+	}
+	used, err := strconv.ParseInt(strings.TrimSpace(output), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("解析容器磁盘使用量失败: %s", strings.TrimSpace(output))
+	}
+	return &provider.DiskUsage{UsedBytes: used}, nil
+}
@@ -1,6 +1,9 @@
 package docker
 
 import (
+	"fmt"
+	"strings"
+
 	"oneclickvirt/global"
 	"oneclickvirt/utils"
 
@@ -22,3 +25,50 @@ func (d *DockerProvider) getDownloadURL(originalURL, providerCountry string, use
 	}
 	return originalURL
 }
+
+// cliBinary 返回容器运行时命令名称，未设置时默认为docker
+func (d *DockerProvider) cliBinary() string {
+	if d.cli == "" {
+		return "docker"
+	}
+	return d.cli
+}
+
+// cliCommand 构建容器运行时命令，Docker与Podman共用同一套命令参数
+func (d *DockerProvider) cliCommand(format string, args ...interface{}) string {
+	return d.cliBinary() + " " + fmt.Sprintf(format, args...)
+}
+
+// vethLookupCommand 构建通过容器PID查找宿主机veth接口的脚本
+func (d *DockerProvider) vethLookupCommand(containerName string) string {
+	return d.interfaceVethLookupCommand(containerName, "eth0")
+}
+
+// interfaceVethLookupCommand 构建查找容器内指定网卡对应宿主机veth接口的脚本
+func (d *DockerProvider) interfaceVethLookupCommand(containerName, ifname string) string {
+	return fmt.Sprintf(`
+CONTAINER_NAME='%s'
+CONTAINER_PID=$(%s inspect -f '{{.State.Pid}}' "$CONTAINER_NAME" 2>/dev/null)
+if [ -z "$CONTAINER_PID" ] || [ "$CONTAINER_PID" = "0" ]; then
+    exit 1
+fi
+HOST_VETH_IFINDEX=$(nsenter -t $CONTAINER_PID -n ip link show %s 2>/dev/null | head -n1 | sed -n 's/.*@if\([0-9]\+\).*/\1/p')
+if [ -z "$HOST_VETH_IFINDEX" ]; then
+    exit 1
+fi
+VETH_NAME=$(ip -o link show 2>/dev/null | awk -v idx="$HOST_VETH_IFINDEX" -F': ' '$1 == idx {print $2}' | cut -d'@' -f1)
+if [ -n "$VETH_NAME" ]; then
+    echo "$VETH_NAME"
+fi
+`, containerName, d.cliBinary(), ifname)
+}
+
+// firstLine 返回输出中第一个非空行，容器连接多个网络时inspect会按网络逐行输出
+func firstLine(output string) string {
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
@@ -0,0 +1,20 @@
+package provider
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// ExecTimeoutGrace SSH会话等待时间在实例内命令超时基础上额外预留的时长
+const ExecTimeoutGrace = 5 * time.Second
+
+// WithExecTimeout 为宿主机上执行的实例内命令加上timeout限制
+// SSH会话被中断时宿主机上的exec进程不一定会退出，由timeout保证超时后强制终止
+func WithExecTimeout(hostCommand string, timeout time.Duration) string {
+	seconds := int(math.Ceil(timeout.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	return fmt.Sprintf("timeout -s KILL %d %s", seconds, hostCommand)
+}
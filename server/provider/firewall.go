@@ -0,0 +1,177 @@
+package provider
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// FirewallBaseChain 所有实例防火墙规则的入口链，挂载在Provider指定的钩子链上
+const FirewallBaseChain = "OCV-FIREWALL"
+
+// MaxFirewallRulesPerInstance 单个实例允许的防火墙规则数量上限
+const MaxFirewallRulesPerInstance = 50
+
+// FirewallInstanceChain 返回实例专属的规则链名称，链名长度受iptables限制，使用实例名哈希
+func FirewallInstanceChain(instanceName string) string {
+	sum := sha1.Sum([]byte(instanceName))
+	return "OCVFW-" + hex.EncodeToString(sum[:])[:12]
+}
+
+// ValidateFirewallRule 校验防火墙规则
+func ValidateFirewallRule(rule FirewallRule) error {
+	if rule.Protocol != "tcp" && rule.Protocol != "udp" {
+		return fmt.Errorf("协议只能是tcp或udp")
+	}
+	if rule.Action != "allow" && rule.Action != "deny" {
+		return fmt.Errorf("动作只能是allow或deny")
+	}
+	if rule.Port < 1 || rule.Port > 65535 {
+		return fmt.Errorf("端口必须在1-65535之间")
+	}
+	if rule.EndPort != 0 && (rule.EndPort <= rule.Port || rule.EndPort > 65535) {
+		return fmt.Errorf("端口范围结束必须大于起始端口且不超过65535")
+	}
+	if rule.Source != "" {
+		if _, _, err := parseFirewallSource(rule.Source); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseFirewallSource 解析来源地址，返回规范化的地址和是否为IPv6
+func parseFirewallSource(source string) (string, bool, error) {
+	if ip := net.ParseIP(source); ip != nil {
+		return ip.String(), ip.To4() == nil, nil
+	}
+	ip, ipNet, err := net.ParseCIDR(source)
+	if err != nil {
+		return "", false, fmt.Errorf("来源地址无效: %s", source)
+	}
+	return ipNet.String(), ip.To4() == nil, nil
+}
+
+// BuildFirewallRuleCommand 生成在宿主机上添加或删除实例防火墙规则的命令
+// 规则按实例IP匹配经过宿主机转发的流量，只作用于新建连接，不影响实例主动发起连接的回包。
+// allow规则插入链首并RETURN，优先于追加在链尾的deny规则；每次调用都会确保入口链已挂载，
+// 并将实例链的跳转更新为当前IP，实例IP变化后重新应用即可生效
+func BuildFirewallRuleCommand(instanceName string, instanceIPs []string, hooks []string, rule FirewallRule) (string, error) {
+	if err := ValidateInstanceName(instanceName); err != nil {
+		return "", err
+	}
+	if err := ValidateFirewallRule(rule); err != nil {
+		return "", err
+	}
+
+	var ipv4, ipv6 []string
+	for _, addr := range instanceIPs {
+		ip := net.ParseIP(strings.TrimSpace(addr))
+		if ip == nil {
+			continue
+		}
+		if ip.To4() != nil {
+			ipv4 = append(ipv4, ip.String())
+		} else {
+			ipv6 = append(ipv6, ip.String())
+		}
+	}
+	if len(ipv4) == 0 && len(ipv6) == 0 {
+		return "", fmt.Errorf("未获取到实例IP地址")
+	}
+
+	source, sourceIPv6 := "", false
+	if rule.Source != "" {
+		source, sourceIPv6, _ = parseFirewallSource(rule.Source)
+	}
+
+	chain := FirewallInstanceChain(instanceName)
+	var commands []string
+	for _, family := range []struct {
+		tool string
+		ips  []string
+		ipv6 bool
+	}{
+		{tool: "iptables", ips: ipv4},
+		{tool: "ip6tables", ips: ipv6, ipv6: true},
+	} {
+		if len(family.ips) == 0 || (source != "" && sourceIPv6 != family.ipv6) {
+			continue
+		}
+		commands = append(commands, firewallChainSetup(family.tool, chain, family.ips, hooks)...)
+		commands = append(commands, firewallRuleCommand(family.tool, chain, source, rule))
+	}
+	if len(commands) == 0 {
+		return "", fmt.Errorf("来源地址与实例IP地址族不匹配")
+	}
+	return strings.Join(commands, "; "), nil
+}
+
+// firewallChainSetup 确保入口链和实例链存在，并把入口链中跳转到实例链的规则替换为当前IP
+func firewallChainSetup(tool, chain string, ips []string, hooks []string) []string {
+	commands := []string{
+		fmt.Sprintf("%s -N %s 2>/dev/null", tool, FirewallBaseChain),
+	}
+	for _, hook := range hooks {
+		commands = append(commands, fmt.Sprintf(
+			"if %[1]s -L %[2]s -n >/dev/null 2>&1; then %[1]s -C %[2]s -j %[3]s 2>/dev/null || %[1]s -I %[2]s 1 -j %[3]s; fi",
+			tool, hook, FirewallBaseChain))
+	}
+	commands = append(commands,
+		fmt.Sprintf("%s -N %s 2>/dev/null", tool, chain),
+		firewallRemoveJumps(tool, chain))
+	for _, ip := range ips {
+		commands = append(commands, fmt.Sprintf("%s -A %s -d %s -j %s", tool, FirewallBaseChain, ip, chain))
+	}
+	return commands
+}
+
+// firewallRemoveJumps 删除入口链中所有跳转到实例链的规则
+func firewallRemoveJumps(tool, chain string) string {
+	return fmt.Sprintf(
+		"%[1]s -S %[2]s 2>/dev/null | grep -- '-j %[3]s$' | sed 's/^-A /-D /' | while read -r r; do %[1]s $r; done",
+		tool, FirewallBaseChain, chain)
+}
+
+func firewallRuleCommand(tool, chain, source string, rule FirewallRule) string {
+	match := fmt.Sprintf("-p %s", rule.Protocol)
+	if source != "" {
+		match += " -s " + source
+	}
+	if rule.EndPort > 0 {
+		match += fmt.Sprintf(" --dport %d:%d", rule.Port, rule.EndPort)
+	} else {
+		match += fmt.Sprintf(" --dport %d", rule.Port)
+	}
+	match += " -m conntrack --ctstate NEW"
+
+	target := "DROP"
+	if rule.Action == "allow" {
+		target = "RETURN"
+	}
+
+	if rule.Remove {
+		return fmt.Sprintf("while %[1]s -D %[2]s %[3]s -j %[4]s 2>/dev/null; do :; done", tool, chain, match, target)
+	}
+	insert := fmt.Sprintf("-A %s", chain)
+	if rule.Action == "allow" {
+		insert = fmt.Sprintf("-I %s 1", chain)
+	}
+	return fmt.Sprintf("%[1]s -C %[2]s %[3]s -j %[4]s 2>/dev/null || %[1]s %[5]s %[3]s -j %[4]s",
+		tool, chain, match, target, insert)
+}
+
+// BuildFirewallCleanupCommand 生成删除实例全部防火墙规则的命令，规则不存在时也返回成功
+func BuildFirewallCleanupCommand(instanceName string) string {
+	chain := FirewallInstanceChain(instanceName)
+	var commands []string
+	for _, tool := range []string{"iptables", "ip6tables"} {
+		commands = append(commands,
+			firewallRemoveJumps(tool, chain),
+			fmt.Sprintf("%s -F %s 2>/dev/null", tool, chain),
+			fmt.Sprintf("%s -X %s 2>/dev/null", tool, chain))
+	}
+	return strings.Join(commands, "; ") + "; true"
+}
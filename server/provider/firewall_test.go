@@ -0,0 +1,69 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestValidateFirewallRule 测试防火墙规则校验
+func TestValidateFirewallRule(t *testing.T) {
+	tests := []struct {
+		name        string
+		rule        FirewallRule
+		expectError bool
+	}{
+		{name: "单端口", rule: FirewallRule{Protocol: "tcp", Port: 22, Action: "allow"}},
+		{name: "端口范围", rule: FirewallRule{Protocol: "udp", Port: 1000, EndPort: 2000, Action: "deny"}},
+		{name: "来源CIDR", rule: FirewallRule{Protocol: "tcp", Port: 80, Source: "10.0.0.0/8", Action: "allow"}},
+		{name: "协议无效", rule: FirewallRule{Protocol: "icmp", Port: 1, Action: "allow"}, expectError: true},
+		{name: "动作无效", rule: FirewallRule{Protocol: "tcp", Port: 1, Action: "reject"}, expectError: true},
+		{name: "范围倒置", rule: FirewallRule{Protocol: "tcp", Port: 100, EndPort: 50, Action: "deny"}, expectError: true},
+		{name: "来源无效", rule: FirewallRule{Protocol: "tcp", Port: 80, Source: "example.com", Action: "deny"}, expectError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateFirewallRule(tt.rule)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("期望错误=%v，实际 %v", tt.expectError, err)
+			}
+		})
+	}
+}
+
+// TestBuildFirewallRuleCommand 测试规则命令按地址族生成且allow规则插入链首
+func TestBuildFirewallRuleCommand(t *testing.T) {
+	chain := FirewallInstanceChain("test-instance")
+	if len(chain) > 28 {
+		t.Fatalf("链名超过iptables长度限制: %s", chain)
+	}
+
+	ips := []string{"172.17.0.2", "fd00::2"}
+	cmd, err := BuildFirewallRuleCommand("test-instance", ips, []string{"FORWARD"}, FirewallRule{Protocol: "tcp", Port: 22, Action: "allow"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(cmd, "iptables -I "+chain+" 1 -p tcp --dport 22") ||
+		!strings.Contains(cmd, "ip6tables -I "+chain+" 1 -p tcp --dport 22") {
+		t.Fatalf("allow规则应在IPv4和IPv6链首插入: %s", cmd)
+	}
+
+	cmd, err = BuildFirewallRuleCommand("test-instance", ips, []string{"FORWARD"}, FirewallRule{Protocol: "udp", Port: 53, Source: "10.0.0.0/8", Action: "deny"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(cmd, "ip6tables") {
+		t.Fatalf("IPv4来源的规则不应下发到ip6tables: %s", cmd)
+	}
+	if !strings.Contains(cmd, "-A "+chain+" -p udp -s 10.0.0.0/8 --dport 53") {
+		t.Fatalf("deny规则应追加到链尾: %s", cmd)
+	}
+
+	cmd, _ = BuildFirewallRuleCommand("test-instance", ips, nil, FirewallRule{Protocol: "tcp", Port: 80, Action: "deny", Remove: true})
+	if !strings.Contains(cmd, "-D "+chain) {
+		t.Fatalf("删除规则应生成-D命令: %s", cmd)
+	}
+
+	if _, err := BuildFirewallRuleCommand("test-instance", []string{"fd00::2"}, nil, FirewallRule{Protocol: "tcp", Port: 80, Source: "1.2.3.4", Action: "deny"}); err == nil {
+		t.Fatal("来源地址与实例地址族不匹配时应返回错误")
+	}
+}
@@ -0,0 +1,104 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// MaxGPUDevices 单个实例允许直通的GPU数量上限
+const MaxGPUDevices = 4
+
+var pciAddressPattern = regexp.MustCompile(`^([0-9a-f]{4}:)?[0-9a-f]{2}:[0-9a-f]{2}\.[0-7]$`)
+
+// GPUListCommand 通过sysfs列出宿主机上的显示控制器（PCI类0x03），不依赖pciutils
+// 每行输出：PCI地址|厂商ID|设备ID|IOMMU分组|驱动|型号，宿主机安装了lspci时才有型号
+const GPUListCommand = `for d in /sys/bus/pci/devices/*; do
+case "$(cat "$d/class" 2>/dev/null)" in 0x03*) ;; *) continue ;; esac
+addr=$(basename "$d"); group=""; driver=""; name=""
+[ -L "$d/iommu_group" ] && group=$(basename "$(readlink "$d/iommu_group")")
+[ -L "$d/driver" ] && driver=$(basename "$(readlink "$d/driver")")
+command -v lspci >/dev/null 2>&1 && name=$(lspci -s "$addr" 2>/dev/null | cut -d' ' -f2- | head -n1)
+echo "$addr|$(cat "$d/vendor")|$(cat "$d/device")|$group|$driver|$name"
+done`
+
+// NormalizePCIAddress 校验PCI地址并补全为 0000:01:00.0 的完整形式
+func NormalizePCIAddress(addr string) (string, error) {
+	normalized := strings.ToLower(strings.TrimSpace(addr))
+	if !pciAddressPattern.MatchString(normalized) {
+		return "", fmt.Errorf("PCI地址无效: %s", addr)
+	}
+	if strings.Count(normalized, ":") == 1 {
+		normalized = "0000:" + normalized
+	}
+	return normalized, nil
+}
+
+// ValidateGPUDevices 校验GPU直通列表的格式，返回规范化后的PCI地址，不检查设备是否存在
+func ValidateGPUDevices(devices []string) ([]string, error) {
+	if len(devices) > MaxGPUDevices {
+		return nil, fmt.Errorf("直通GPU数量不能超过%d个", MaxGPUDevices)
+	}
+	normalized := make([]string, 0, len(devices))
+	seen := make(map[string]bool)
+	for _, device := range devices {
+		addr, err := NormalizePCIAddress(device)
+		if err != nil {
+			return nil, err
+		}
+		if seen[addr] {
+			return nil, fmt.Errorf("直通GPU重复: %s", addr)
+		}
+		seen[addr] = true
+		normalized = append(normalized, addr)
+	}
+	return normalized, nil
+}
+
+// ParseGPUList 解析GPUListCommand的输出
+func ParseGPUList(output string) []GPUDevice {
+	var gpus []GPUDevice
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.SplitN(strings.TrimSpace(line), "|", 6)
+		if len(fields) < 6 {
+			continue
+		}
+		addr, err := NormalizePCIAddress(fields[0])
+		if err != nil {
+			continue
+		}
+		gpus = append(gpus, GPUDevice{
+			Address:    addr,
+			VendorID:   strings.TrimPrefix(fields[1], "0x"),
+			DeviceID:   strings.TrimPrefix(fields[2], "0x"),
+			IOMMUGroup: fields[3],
+			Driver:     fields[4],
+			Model:      strings.TrimSpace(fields[5]),
+		})
+	}
+	return gpus
+}
+
+// CheckGPUsAssignable 校验请求直通的GPU都存在于宿主机、已开启IOMMU且未直通给其他实例
+// instanceName为重建同名实例时的实例名称，其自身占用的GPU不视为冲突
+func CheckGPUsAssignable(devices []string, gpus []GPUDevice, instanceName string) error {
+	for _, addr := range devices {
+		var found *GPUDevice
+		for idx := range gpus {
+			if gpus[idx].Address == addr {
+				found = &gpus[idx]
+				break
+			}
+		}
+		if found == nil {
+			return fmt.Errorf("宿主机上不存在GPU %s", addr)
+		}
+		if found.IOMMUGroup == "" {
+			return fmt.Errorf("GPU %s 没有IOMMU分组，请在宿主机BIOS和内核参数中开启IOMMU（intel_iommu=on 或 amd_iommu=on）后重试", addr)
+		}
+		if found.AssignedTo != "" && found.AssignedTo != instanceName {
+			return fmt.Errorf("GPU %s 已直通给实例 %s", addr, found.AssignedTo)
+		}
+	}
+	return nil
+}
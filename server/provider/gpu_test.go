@@ -0,0 +1,73 @@
+package provider
+
+import "testing"
+
+// TestValidateGPUDevices 测试GPU直通列表的格式校验和地址规范化
+func TestValidateGPUDevices(t *testing.T) {
+	devices, err := ValidateGPUDevices([]string{"01:00.0", "0000:02:00.1"})
+	if err != nil {
+		t.Fatalf("合法地址不应报错: %v", err)
+	}
+	if devices[0] != "0000:01:00.0" || devices[1] != "0000:02:00.1" {
+		t.Fatalf("规范化结果错误: %v", devices)
+	}
+
+	invalid := [][]string{
+		{"01:00"},
+		{"01:00.8"},
+		{"0000:01:00.0; reboot"},
+		{"01:00.0", "0000:01:00.0"},
+		make([]string, MaxGPUDevices+1),
+	}
+	for _, input := range invalid {
+		if _, err := ValidateGPUDevices(input); err == nil {
+			t.Fatalf("期望校验失败: %v", input)
+		}
+	}
+}
+
+// TestParseGPUList 测试解析sysfs GPU列表输出
+func TestParseGPUList(t *testing.T) {
+	output := "0000:01:00.0|0x10de|0x2204|14|vfio-pci|VGA compatible controller: NVIDIA Corporation GA102\n" +
+		"0000:00:02.0|0x8086|0x3e92||i915|\n" +
+		"garbage line\n"
+	gpus := ParseGPUList(output)
+	if len(gpus) != 2 {
+		t.Fatalf("期望2个GPU，实际 %d", len(gpus))
+	}
+	if gpus[0].VendorID != "10de" || gpus[0].IOMMUGroup != "14" || gpus[0].Driver != "vfio-pci" || gpus[0].Model == "" {
+		t.Fatalf("解析结果错误: %+v", gpus[0])
+	}
+	if gpus[1].IOMMUGroup != "" || gpus[1].Model != "" {
+		t.Fatalf("解析结果错误: %+v", gpus[1])
+	}
+}
+
+// TestCheckGPUsAssignable 测试GPU存在性、IOMMU和占用校验
+func TestCheckGPUsAssignable(t *testing.T) {
+	gpus := []GPUDevice{
+		{Address: "0000:01:00.0", IOMMUGroup: "14"},
+		{Address: "0000:02:00.0", IOMMUGroup: "15", AssignedTo: "vm-a"},
+		{Address: "0000:00:02.0"},
+	}
+	cases := []struct {
+		name        string
+		devices     []string
+		instance    string
+		expectError bool
+	}{
+		{name: "空闲GPU", devices: []string{"0000:01:00.0"}, instance: "vm-b"},
+		{name: "设备不存在", devices: []string{"0000:03:00.0"}, instance: "vm-b", expectError: true},
+		{name: "未开启IOMMU", devices: []string{"0000:00:02.0"}, instance: "vm-b", expectError: true},
+		{name: "已被其他实例占用", devices: []string{"0000:02:00.0"}, instance: "vm-b", expectError: true},
+		{name: "同名实例重建", devices: []string{"0000:02:00.0"}, instance: "vm-a"},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckGPUsAssignable(tt.devices, gpus, tt.instance)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("期望错误=%v，实际 %v", tt.expectError, err)
+			}
+		})
+	}
+}
@@ -156,45 +156,12 @@ func (d *DockerHealthChecker) checkSSH(ctx context.Context) error {
 		d.sshClient = nil
 	}
 
-	// 构建认证方法：优先使用SSH密钥，否则使用密码
-	// 构建认证方法：支持密钥和密码，SSH客户端会按顺序尝试
-	var authMethods []ssh.AuthMethod
-
-	// 如果提供了SSH私钥，添加密钥认证
-	if d.config.PrivateKey != "" {
-		signer, err := ssh.ParsePrivateKey([]byte(d.config.PrivateKey))
-		if err == nil {
-			authMethods = append(authMethods, ssh.PublicKeys(signer))
-			if d.logger != nil {
-				d.logger.Debug("已添加SSH密钥认证方法", zap.String("host", d.config.Host))
-			}
-		} else if d.logger != nil {
-			d.logger.Warn("SSH私钥解析失败，将尝试使用密码认证",
-				zap.String("host", d.config.Host),
-				zap.Error(err))
-		}
-	}
-
-	// 如果提供了密码，添加密码认证（无论是否有密钥，都添加作为备用方案）
-	if d.config.Password != "" {
-		authMethods = append(authMethods, ssh.Password(d.config.Password))
-		if d.logger != nil {
-			d.logger.Debug("已添加SSH密码认证方法", zap.String("host", d.config.Host))
-		}
-	}
-
-	// 如果既没有密钥也没有密码，返回错误
-	if len(authMethods) == 0 {
-		return fmt.Errorf("no authentication method available: neither SSH key nor password provided")
-	}
-
-	// 建立新连接
-	config := &ssh.ClientConfig{
-		User:            d.config.Username,
-		Auth:            authMethods,
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
-		Timeout:         d.config.Timeout,
+	// 建立新连接，认证链（私钥、ssh-agent、密码）和主机密钥校验与utils.NewSSHClient一致
+	config, closeAuth, err := utils.NewSSHClientConfig(d.config.sshConfig())
+	if err != nil {
+		return err
 	}
+	defer closeAuth()
 
 	address := fmt.Sprintf("%s:%d", d.config.Host, d.config.Port)
 	// 保存预期的host用于后续验证（避免并发修改）
@@ -311,14 +278,25 @@ func (d *DockerHealthChecker) checkDockerService(ctx context.Context) error {
 		return fmt.Errorf("请求PTY失败: %w", err)
 	}
 
+	// Podman与Docker共用此检查器，通过ServiceChecks区分命令
+	binary := "docker"
+	if d.config.ServiceChecks[0] == "podman" {
+		binary = "podman"
+	}
+
 	// 设置环境变量来确保PATH正确加载，避免bash -l -c的转义问题
-	envCommand := "source /etc/profile 2>/dev/null || true; source ~/.bashrc 2>/dev/null || true; source ~/.bash_profile 2>/dev/null || true; export PATH=$PATH:/usr/local/bin:/snap/bin:/usr/sbin:/sbin; docker version"
+	envCommand := "source /etc/profile 2>/dev/null || true; source ~/.bashrc 2>/dev/null || true; source ~/.bash_profile 2>/dev/null || true; export PATH=$PATH:/usr/local/bin:/snap/bin:/usr/sbin:/sbin; " + binary + " version"
 	output, err := session.CombinedOutput(envCommand)
 	if err != nil {
-		return fmt.Errorf("Docker服务不可用: %w", err)
+		return fmt.Errorf("%s服务不可用: %w", binary, err)
 	}
 
-	if !strings.Contains(string(output), "Server:") {
+	// Podman没有守护进程，只要客户端能正常输出版本信息即可
+	if binary == "podman" {
+		if !strings.Contains(string(output), "Version:") {
+			return fmt.Errorf("Podman不可用")
+		}
+	} else if !strings.Contains(string(output), "Server:") {
 		return fmt.Errorf("Docker守护进程未运行")
 	}
 
@@ -68,6 +68,12 @@ type HealthConfig struct {
 	Password   string `json:"password"`
 	PrivateKey string `json:"private_key"` // SSH私钥，优先于密码使用
 
+	// SSH认证和主机密钥校验配置，与utils.SSHConfig保持一致
+	PrivateKeyPassphrase string `json:"-"`                    // SSH私钥密码短语
+	UseAgent             bool   `json:"use_agent"`            // 是否尝试ssh-agent认证
+	VerifyHostKey        bool   `json:"verify_host_key"`      // 是否校验主机密钥
+	HostKeyFingerprint   string `json:"host_key_fingerprint"` // 已固定的主机密钥指纹
+
 	// API配置
 	APIEnabled    bool   `json:"api_enabled"`
 	APIPort       int    `json:"api_port"`
@@ -97,27 +103,31 @@ func (c HealthConfig) DeepCopy() HealthConfig {
 	copy(customCommands, c.CustomCommands)
 
 	return HealthConfig{
-		ProviderID:     c.ProviderID,
-		ProviderName:   c.ProviderName,
-		Host:           c.Host,
-		Port:           c.Port,
-		Username:       c.Username,
-		Password:       c.Password,
-		PrivateKey:     c.PrivateKey,
-		APIEnabled:     c.APIEnabled,
-		APIPort:        c.APIPort,
-		APIScheme:      c.APIScheme,
-		SkipTLSVerify:  c.SkipTLSVerify,
-		Token:          c.Token,
-		TokenID:        c.TokenID,
-		CertPath:       c.CertPath,
-		KeyPath:        c.KeyPath,
-		CertContent:    c.CertContent,
-		KeyContent:     c.KeyContent,
-		Timeout:        c.Timeout,
-		SSHEnabled:     c.SSHEnabled,
-		ServiceChecks:  serviceChecks,
-		CustomCommands: customCommands,
+		ProviderID:           c.ProviderID,
+		ProviderName:         c.ProviderName,
+		Host:                 c.Host,
+		Port:                 c.Port,
+		Username:             c.Username,
+		Password:             c.Password,
+		PrivateKey:           c.PrivateKey,
+		PrivateKeyPassphrase: c.PrivateKeyPassphrase,
+		UseAgent:             c.UseAgent,
+		VerifyHostKey:        c.VerifyHostKey,
+		HostKeyFingerprint:   c.HostKeyFingerprint,
+		APIEnabled:           c.APIEnabled,
+		APIPort:              c.APIPort,
+		APIScheme:            c.APIScheme,
+		SkipTLSVerify:        c.SkipTLSVerify,
+		Token:                c.Token,
+		TokenID:              c.TokenID,
+		CertPath:             c.CertPath,
+		KeyPath:              c.KeyPath,
+		CertContent:          c.CertContent,
+		KeyContent:           c.KeyContent,
+		Timeout:              c.Timeout,
+		SSHEnabled:           c.SSHEnabled,
+		ServiceChecks:        serviceChecks,
+		CustomCommands:       customCommands,
 	}
 }
 
@@ -16,6 +16,7 @@ const (
 	ProviderTypeLXD     ProviderType = "lxd"
 	ProviderTypeIncus   ProviderType = "incus"
 	ProviderTypeProxmox ProviderType = "proxmox"
+	ProviderTypePodman  ProviderType = "podman"
 )
 
 // HealthManager 健康检查管理器
@@ -64,7 +65,8 @@ func (hm *HealthManager) CreateChecker(providerType ProviderType, config HealthC
 	var checkerTypeName string
 
 	switch providerType {
-	case ProviderTypeDocker:
+	case ProviderTypeDocker, ProviderTypePodman:
+		// Podman命令行与Docker兼容，复用Docker健康检查器
 		if configCopy.APIScheme == "" {
 			configCopy.APIScheme = "http"
 		}
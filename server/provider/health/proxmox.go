@@ -135,45 +135,12 @@ func (p *ProxmoxHealthChecker) checkSSH(ctx context.Context) error {
 		p.sshClient = nil
 	}
 
-	// 构建认证方法：优先使用SSH密钥，否则使用密码
-	// 构建认证方法：支持密钥和密码，SSH客户端会按顺序尝试
-	var authMethods []ssh.AuthMethod
-
-	// 如果提供了SSH私钥，添加密钥认证
-	if p.config.PrivateKey != "" {
-		signer, err := ssh.ParsePrivateKey([]byte(p.config.PrivateKey))
-		if err == nil {
-			authMethods = append(authMethods, ssh.PublicKeys(signer))
-			if p.logger != nil {
-				p.logger.Debug("已添加SSH密钥认证方法", zap.String("host", p.config.Host))
-			}
-		} else if p.logger != nil {
-			p.logger.Warn("SSH私钥解析失败，将尝试使用密码认证",
-				zap.String("host", p.config.Host),
-				zap.Error(err))
-		}
-	}
-
-	// 如果提供了密码，添加密码认证（无论是否有密钥，都添加作为备用方案）
-	if p.config.Password != "" {
-		authMethods = append(authMethods, ssh.Password(p.config.Password))
-		if p.logger != nil {
-			p.logger.Debug("已添加SSH密码认证方法", zap.String("host", p.config.Host))
-		}
-	}
-
-	// 如果既没有密钥也没有密码，返回错误
-	if len(authMethods) == 0 {
-		return fmt.Errorf("no authentication method available: neither SSH key nor password provided")
-	}
-
-	// 建立新连接
-	config := &ssh.ClientConfig{
-		User:            p.config.Username,
-		Auth:            authMethods,
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
-		Timeout:         p.config.Timeout,
+	// 建立新连接，认证链（私钥、ssh-agent、密码）和主机密钥校验与utils.NewSSHClient一致
+	config, closeAuth, err := utils.NewSSHClientConfig(p.config.sshConfig())
+	if err != nil {
+		return err
 	}
+	defer closeAuth()
 
 	address := fmt.Sprintf("%s:%d", p.config.Host, p.config.Port)
 	client, err := ssh.Dial("tcp", address, config)
@@ -50,18 +50,45 @@ type TokenInfo interface {
 	GetTokenSecret() string
 }
 
+// setSSHConfig 使用SSH连接配置填充健康检查配置的连接和认证字段
+func (c *HealthConfig) setSSHConfig(sshConfig utils.SSHConfig) {
+	c.Host = sshConfig.Host
+	c.Port = sshConfig.Port
+	c.Username = sshConfig.Username
+	c.Password = sshConfig.Password
+	c.PrivateKey = sshConfig.PrivateKey
+	c.PrivateKeyPassphrase = sshConfig.PrivateKeyPassphrase
+	c.UseAgent = sshConfig.UseAgent
+	c.VerifyHostKey = sshConfig.VerifyHostKey
+	c.HostKeyFingerprint = sshConfig.HostKeyFingerprint
+}
+
+// sshConfig 转换为utils.SSHConfig，用于构建与utils.NewSSHClient一致的认证链和主机密钥校验
+func (c HealthConfig) sshConfig() utils.SSHConfig {
+	return utils.SSHConfig{
+		Host:                 c.Host,
+		Port:                 c.Port,
+		Username:             c.Username,
+		Password:             c.Password,
+		PrivateKey:           c.PrivateKey,
+		PrivateKeyPassphrase: c.PrivateKeyPassphrase,
+		UseAgent:             c.UseAgent,
+		VerifyHostKey:        c.VerifyHostKey,
+		HostKeyFingerprint:   c.HostKeyFingerprint,
+		ConnectTimeout:       c.Timeout,
+	}
+}
+
 // CheckProviderHealthWithAuthConfig 根据认证配置执行健康检查
 // 返回: sshStatus, apiStatus, hostName, error
-func (phc *ProviderHealthChecker) CheckProviderHealthWithAuthConfig(ctx context.Context, providerID uint, providerName, providerType, host, username, password, privateKey string, port int, authConfig ProviderAuthConfig) (string, string, string, error) {
+func (phc *ProviderHealthChecker) CheckProviderHealthWithAuthConfig(ctx context.Context, providerID uint, providerName, providerType string, sshConfig utils.SSHConfig, authConfig ProviderAuthConfig) (string, string, string, error) {
 	// 复制副本避免共享状态，立即创建所有参数的本地副本
 	localProviderID := providerID
 	localProviderName := providerName
 	localProviderType := providerType
-	localHost := host
-	localUsername := username
-	localPassword := password
-	localPrivateKey := privateKey
-	localPort := port
+	localHost := sshConfig.Host
+	localUsername := sshConfig.Username
+	localPort := sshConfig.Port
 
 	// 添加入口日志，追踪参数
 	if phc.logger != nil {
@@ -77,16 +104,12 @@ func (phc *ProviderHealthChecker) CheckProviderHealthWithAuthConfig(ctx context.
 	config := HealthConfig{
 		ProviderID:    localProviderID,
 		ProviderName:  localProviderName,
-		Host:          localHost,
-		Port:          localPort,
-		Username:      localUsername,
-		Password:      localPassword,
-		PrivateKey:    localPrivateKey,
 		SSHEnabled:    true,
 		APIEnabled:    true,
 		SkipTLSVerify: true,
 		Timeout:       30 * time.Second,
 	}
+	config.setSSHConfig(sshConfig)
 
 	// 根据认证配置设置具体的认证信息
 	switch localProviderType {
@@ -115,6 +138,9 @@ func (phc *ProviderHealthChecker) CheckProviderHealthWithAuthConfig(ctx context.
 		config.APIPort = 2375
 		config.APIScheme = "http"
 		config.ServiceChecks = []string{"docker"}
+	case "podman":
+		config.APIEnabled = false // podman无守护进程API
+		config.ServiceChecks = []string{"podman"}
 	}
 
 	// 创建checker前再次记录配置，确保config.Host正确
@@ -185,6 +211,9 @@ func (phc *ProviderHealthChecker) CheckProviderHealthFromConfig(ctx context.Cont
 		config.APIPort = 2375
 		config.APIScheme = "http"
 		config.ServiceChecks = []string{"docker"}
+	case "podman":
+		config.APIEnabled = false // podman无守护进程API
+		config.ServiceChecks = []string{"podman"}
 	case "lxd":
 		config.APIPort = 8443
 		config.APIScheme = "https"
@@ -228,28 +257,15 @@ func (phc *ProviderHealthChecker) CheckProviderHealthFromConfig(ctx context.Cont
 }
 
 // CheckSSHConnection 单独检查SSH连接
-func (phc *ProviderHealthChecker) CheckSSHConnection(ctx context.Context, providerID uint, providerName, host, username, password, privateKey string, port int) error {
-	// 复制副本避免共享状态，立即创建所有参数的本地副本
-	localProviderID := providerID
-	localProviderName := providerName
-	localHost := host
-	localUsername := username
-	localPassword := password
-	localPrivateKey := privateKey
-	localPort := port
-
+func (phc *ProviderHealthChecker) CheckSSHConnection(ctx context.Context, providerID uint, providerName string, sshConfig utils.SSHConfig) error {
 	config := HealthConfig{
-		ProviderID:   localProviderID,
-		ProviderName: localProviderName,
-		Host:         localHost,
-		Port:         localPort,
-		Username:     localUsername,
-		Password:     localPassword,
-		PrivateKey:   localPrivateKey,
+		ProviderID:   providerID,
+		ProviderName: providerName,
 		SSHEnabled:   true,
 		APIEnabled:   false,
 		Timeout:      30 * time.Second,
 	}
+	config.setSSHConfig(sshConfig)
 	checker := NewDockerHealthChecker(config, phc.logger)
 	defer checker.Close()
 	result, err := checker.CheckHealth(ctx)
@@ -276,7 +292,7 @@ func (phc *ProviderHealthChecker) CheckAPIConnection(ctx context.Context, provid
 		Timeout:       30 * time.Second,
 	}
 	switch providerType {
-	case "docker":
+	case "docker", "podman":
 		config.APIScheme = "http"
 	case "lxd", "incus":
 		config.APIScheme = "https"
@@ -331,19 +347,22 @@ func (phc *ProviderHealthChecker) CheckAPIConnection(ctx context.Context, provid
 
 // GetSystemResourceInfo 通过SSH获取系统资源信息
 func (phc *ProviderHealthChecker) GetSystemResourceInfo(ctx context.Context, providerID uint, providerName, host, username, password string, port int) (*ResourceInfo, error) {
-	return phc.GetSystemResourceInfoWithKey(ctx, providerID, providerName, host, username, password, "", port)
+	return phc.GetSystemResourceInfoWithKey(ctx, providerID, providerName, utils.SSHConfig{
+		Host:     host,
+		Port:     port,
+		Username: username,
+		Password: password,
+	})
 }
 
-// GetSystemResourceInfoWithKey 通过SSH获取系统资源信息（支持SSH密钥）
-func (phc *ProviderHealthChecker) GetSystemResourceInfoWithKey(ctx context.Context, providerID uint, providerName, host, username, password, privateKey string, port int) (*ResourceInfo, error) {
+// GetSystemResourceInfoWithKey 通过SSH获取系统资源信息（支持SSH密钥、ssh-agent和主机密钥校验）
+func (phc *ProviderHealthChecker) GetSystemResourceInfoWithKey(ctx context.Context, providerID uint, providerName string, sshConfig utils.SSHConfig) (*ResourceInfo, error) {
 	// 复制副本避免共享状态，立即创建所有参数的本地副本
 	localProviderID := providerID
 	localProviderName := providerName
-	localHost := host
-	localUsername := username
-	localPassword := password
-	localPrivateKey := privateKey
-	localPort := port
+	localHost := sshConfig.Host
+	localUsername := sshConfig.Username
+	localPort := sshConfig.Port
 
 	// 添加入口日志
 	if phc.logger != nil {
@@ -355,43 +374,13 @@ func (phc *ProviderHealthChecker) GetSystemResourceInfoWithKey(ctx context.Conte
 			zap.String("username", localUsername))
 	}
 
-	// 构建认证方法：优先使用SSH密钥，否则使用密码
-	var authMethods []ssh.AuthMethod
-
-	// 如果提供了SSH私钥，添加密钥认证
-	if localPrivateKey != "" {
-		signer, err := ssh.ParsePrivateKey([]byte(localPrivateKey))
-		if err == nil {
-			authMethods = append(authMethods, ssh.PublicKeys(signer))
-			if phc.logger != nil {
-				phc.logger.Debug("已添加SSH密钥认证方法获取资源信息", zap.String("host", localHost))
-			}
-		} else if phc.logger != nil {
-			phc.logger.Warn("SSH私钥解析失败，将尝试使用密码认证",
-				zap.String("host", localHost),
-				zap.Error(err))
-		}
-	}
-
-	// 如果提供了密码，添加密码认证（无论是否有密钥，都添加作为备用方案）
-	if localPassword != "" {
-		authMethods = append(authMethods, ssh.Password(localPassword))
-		if phc.logger != nil {
-			phc.logger.Debug("已添加SSH密码认证方法获取资源信息", zap.String("host", localHost))
-		}
-	}
-
-	// 如果既没有密钥也没有密码，返回错误
-	if len(authMethods) == 0 {
-		return nil, fmt.Errorf("no authentication method available: neither SSH key nor password provided")
-	}
-
-	config := &ssh.ClientConfig{
-		User:            localUsername,
-		Auth:            authMethods,
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
-		Timeout:         30 * time.Second,
+	// 认证链（私钥、ssh-agent、密码）和主机密钥校验与utils.NewSSHClient一致
+	sshConfig.ConnectTimeout = 30 * time.Second
+	config, closeAuth, err := utils.NewSSHClientConfig(sshConfig)
+	if err != nil {
+		return nil, err
 	}
+	defer closeAuth()
 
 	// 连接SSH
 	addr := fmt.Sprintf("%s:%d", localHost, localPort)
@@ -0,0 +1,69 @@
+package provider
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// MaxHostnameLength 主机名（FQDN）总长度上限，单个标签不超过63个字符
+const MaxHostnameLength = 253
+
+// ValidateHostname 校验自定义主机名或FQDN，返回去掉末尾点号并转为小写的主机名，空字符串表示未指定
+// 每个标签只能包含字母、数字和连字符，且不能以连字符开头或结尾（RFC 1123）
+func ValidateHostname(hostname string) (string, error) {
+	hostname = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(hostname), "."))
+	if hostname == "" {
+		return "", nil
+	}
+	if len(hostname) > MaxHostnameLength {
+		return "", fmt.Errorf("主机名长度不能超过%d个字符", MaxHostnameLength)
+	}
+	if net.ParseIP(hostname) != nil {
+		return "", fmt.Errorf("主机名不能是IP地址: %s", hostname)
+	}
+	for _, label := range strings.Split(hostname, ".") {
+		if label == "" || len(label) > 63 {
+			return "", fmt.Errorf("主机名无效: %s，每段长度需为1-63个字符", hostname)
+		}
+		if label[0] == '-' || label[len(label)-1] == '-' {
+			return "", fmt.Errorf("主机名无效: %s，每段不能以连字符开头或结尾", hostname)
+		}
+		for _, ch := range label {
+			if (ch < 'a' || ch > 'z') && (ch < '0' || ch > '9') && ch != '-' {
+				return "", fmt.Errorf("主机名无效: %s，只能包含字母、数字、连字符和点", hostname)
+			}
+		}
+	}
+	return hostname, nil
+}
+
+// InstanceHostname 实例使用的主机名，未指定时使用实例名称
+func InstanceHostname(config InstanceConfig) string {
+	if config.Hostname != "" {
+		return config.Hostname
+	}
+	return config.Name
+}
+
+// SetHostnameCommand 构建在实例内设置主机名的命令，hostname必须已通过ValidateHostname校验
+// 没有systemd的容器回退为直接写入/etc/hostname；同时更新/etc/hosts，
+// 并让cloud-init保留主机名，避免重启后被实例名称覆盖
+func SetHostnameCommand(hostname string) string {
+	short, _, _ := strings.Cut(hostname, ".")
+	hostsEntry := hostname
+	if short != hostname {
+		hostsEntry += " " + short
+	}
+	return fmt.Sprintf("{ hostnamectl set-hostname '%[1]s' 2>/dev/null || { echo '%[1]s' > /etc/hostname && hostname '%[1]s'; }; } && "+
+		"{ sed -i '/^127\\.0\\.1\\.1[[:space:]]/d' /etc/hosts 2>/dev/null; echo '127.0.1.1 %[2]s' >> /etc/hosts; } && "+
+		"{ [ ! -d /etc/cloud/cloud.cfg.d ] || echo 'preserve_hostname: true' > /etc/cloud/cloud.cfg.d/99-oneclickvirt-hostname.cfg; }",
+		hostname, hostsEntry)
+}
+
+// HostnameCloudConfig 生成设置主机名的cloud-init配置，用于通过vendor-data设置虚拟机主机名
+// 平台生成的用户数据以实例名称作为hostname，需保留主机名并在首次启动时改为自定义主机名
+func HostnameCloudConfig(hostname string) string {
+	return fmt.Sprintf("#cloud-config\npreserve_hostname: true\nruncmd:\n  - [sh, -c, %s]\n", strconv.Quote(SetHostnameCommand(hostname)))
+}
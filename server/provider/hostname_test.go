@@ -0,0 +1,72 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestValidateHostname 测试主机名校验和规范化
+func TestValidateHostname(t *testing.T) {
+	valid := map[string]string{
+		"":                  "",
+		"web1":              "web1",
+		" Web1.Example.COM": "web1.example.com",
+		"db-01.internal.":   "db-01.internal",
+		"1host":             "1host",
+	}
+	for input, expected := range valid {
+		got, err := ValidateHostname(input)
+		if err != nil {
+			t.Fatalf("合法主机名 %q 不应报错: %v", input, err)
+		}
+		if got != expected {
+			t.Fatalf("主机名 %q 规范化结果错误: %q", input, got)
+		}
+	}
+
+	invalid := []string{
+		"-web",
+		"web-",
+		"web_1",
+		"web..example.com",
+		"web;reboot",
+		"web 1",
+		"10.0.0.1",
+		strings.Repeat("a", 64),
+		strings.Repeat("a.", 127) + "a",
+	}
+	for _, input := range invalid {
+		if _, err := ValidateHostname(input); err == nil {
+			t.Fatalf("期望校验失败: %q", input)
+		}
+	}
+}
+
+// TestInstanceHostname 测试未指定主机名时使用实例名称
+func TestInstanceHostname(t *testing.T) {
+	if got := InstanceHostname(InstanceConfig{Name: "ct-1"}); got != "ct-1" {
+		t.Fatalf("未指定主机名时应使用实例名称: %s", got)
+	}
+	if got := InstanceHostname(InstanceConfig{Name: "ct-1", Hostname: "web.example.com"}); got != "web.example.com" {
+		t.Fatalf("应使用自定义主机名: %s", got)
+	}
+}
+
+// TestSetHostnameCommand 测试FQDN在/etc/hosts中同时写入短名称
+func TestSetHostnameCommand(t *testing.T) {
+	cmd := SetHostnameCommand("web.example.com")
+	if !strings.Contains(cmd, "hostnamectl set-hostname 'web.example.com'") {
+		t.Fatalf("命令缺少hostnamectl: %s", cmd)
+	}
+	if !strings.Contains(cmd, "echo '127.0.1.1 web.example.com web' >> /etc/hosts") {
+		t.Fatalf("命令缺少hosts条目: %s", cmd)
+	}
+	if strings.Contains(SetHostnameCommand("web"), "web web") {
+		t.Fatalf("短主机名不应重复写入hosts")
+	}
+
+	config := HostnameCloudConfig("web.example.com")
+	if !strings.HasPrefix(config, "#cloud-config\npreserve_hostname: true\n") {
+		t.Fatalf("cloud-init配置错误: %s", config)
+	}
+}
@@ -0,0 +1,46 @@
+package provider
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"oneclickvirt/utils"
+)
+
+// NormalizeSHA256 规范化sha256校验值，支持 "sha256:" 前缀
+// 不是合法sha256时返回空字符串，表示不做校验（系统镜像的校验和字段也可能保存其他算法的值）
+func NormalizeSHA256(checksum string) string {
+	value := strings.ToLower(strings.TrimSpace(checksum))
+	value = strings.TrimPrefix(value, "sha256:")
+	if len(value) != 64 {
+		return ""
+	}
+	if _, err := hex.DecodeString(value); err != nil {
+		return ""
+	}
+	return value
+}
+
+// VerifyRemoteSHA256 在远程服务器上计算文件sha256并与期望值比较
+// expected为空时不做校验
+func VerifyRemoteSHA256(client *utils.SSHClient, remotePath, expected string) error {
+	expected = NormalizeSHA256(expected)
+	if expected == "" {
+		return nil
+	}
+
+	output, err := client.Execute(fmt.Sprintf("sha256sum %s", utils.ShellQuote(remotePath)))
+	if err != nil {
+		return fmt.Errorf("计算镜像文件sha256失败: %w", err)
+	}
+	fields := strings.Fields(output)
+	if len(fields) == 0 {
+		return fmt.Errorf("计算镜像文件sha256失败: 输出为空")
+	}
+	actual := strings.ToLower(fields[0])
+	if actual != expected {
+		return fmt.Errorf("镜像文件sha256不匹配，期望 %s，实际 %s", expected, actual)
+	}
+	return nil
+}
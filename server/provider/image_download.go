@@ -0,0 +1,167 @@
+package provider
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"oneclickvirt/global"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+)
+
+// maxDownloadAttempts 单次下载调用内curl的最大执行次数，每次执行内curl自身还会重试
+const maxDownloadAttempts = 3
+
+// curlExitRangeError curl无法续传（服务器不支持Range）时的退出码
+const curlExitRangeError = 33
+
+// DownloadRemoteFile 在远程服务器上下载文件到remotePath，支持断点续传
+// 下载过程写入 remotePath+".tmp"，失败时保留该部分文件，下次调用从断点继续。
+// 服务器通过HEAD返回Content-Length时校验最终大小：部分文件超过预期大小或下载完成后大小不一致，
+// 视为部分文件已损坏，删除后从头下载（每次调用最多重新开始一次，避免无休止地续传损坏文件）
+func DownloadRemoteFile(client *utils.SSHClient, url, remotePath string) error {
+	tmpPath := remotePath + ".tmp"
+	expectedSize := remoteContentLength(client, url)
+
+	restarted := false
+	restart := func(reason string) bool {
+		if restarted {
+			return false
+		}
+		restarted = true
+		global.APP_LOG.Warn("部分下载文件不可续传，删除后重新下载",
+			zap.String("tmpPath", tmpPath),
+			zap.String("reason", reason))
+		client.Execute(fmt.Sprintf("rm -f %s", utils.ShellQuote(tmpPath)))
+		return true
+	}
+
+	if partialSize := remoteFileSize(client, tmpPath); partialSize > 0 {
+		if expectedSize > 0 && partialSize > expectedSize {
+			restart(fmt.Sprintf("部分文件大小%d超过预期大小%d", partialSize, expectedSize))
+		} else {
+			global.APP_LOG.Info("发现未完成的下载，继续断点续传",
+				zap.String("tmpPath", tmpPath),
+				zap.Int64("partialSize", partialSize),
+				zap.Int64("expectedSize", expectedSize))
+		}
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= maxDownloadAttempts; attempt++ {
+		// 部分文件已是完整大小时服务器会拒绝Range请求，直接进入校验
+		if expectedSize <= 0 || remoteFileSize(client, tmpPath) != expectedSize {
+			exitCode, output := runResumableCurl(client, url, tmpPath)
+			if exitCode != 0 {
+				lastErr = fmt.Errorf("curl退出码 %d: %s", exitCode, utils.TruncateString(strings.TrimSpace(output), 300))
+				global.APP_LOG.Warn("远程下载中断",
+					zap.String("url", utils.TruncateString(url, 100)),
+					zap.Int("attempt", attempt),
+					zap.Int("exitCode", exitCode),
+					zap.String("output", utils.TruncateString(output, 500)))
+				if exitCode == curlExitRangeError {
+					restart("服务器不支持断点续传")
+				}
+				continue
+			}
+		}
+
+		if expectedSize > 0 {
+			if actual := remoteFileSize(client, tmpPath); actual != expectedSize {
+				lastErr = fmt.Errorf("下载文件大小不匹配，期望 %d，实际 %d", expectedSize, actual)
+				if !restart(lastErr.Error()) {
+					break
+				}
+				continue
+			}
+		}
+
+		if output, err := client.Execute(fmt.Sprintf("mv -f %s %s", utils.ShellQuote(tmpPath), utils.ShellQuote(remotePath))); err != nil {
+			return fmt.Errorf("移动文件失败: %s: %w", strings.TrimSpace(output), err)
+		}
+		global.APP_LOG.Info("远程下载成功",
+			zap.String("url", utils.TruncateString(url, 100)),
+			zap.String("remotePath", remotePath),
+			zap.Int64("size", expectedSize))
+		return nil
+	}
+
+	global.APP_LOG.Error("远程下载失败，保留部分文件以便下次续传",
+		zap.String("url", utils.TruncateString(url, 100)),
+		zap.String("tmpPath", tmpPath),
+		zap.Error(lastErr))
+	return fmt.Errorf("远程下载失败: %w", lastErr)
+}
+
+// runResumableCurl 执行可续传的curl下载，返回curl退出码和输出
+// -f 避免把HTTP错误页写入部分文件，否则后续续传会拼接出损坏的文件；大镜像下载耗时较长，使用下载类超时
+func runResumableCurl(client *utils.SSHClient, url, tmpPath string) (int, string) {
+	cmd := fmt.Sprintf(
+		"curl -4 -fsSL -C - --connect-timeout 30 --retry 5 --retry-delay 10 --retry-max-time 0 -o %s %s; echo \"__exit=$?\"",
+		utils.ShellQuote(tmpPath), utils.ShellQuote(url),
+	)
+	output, err := client.ExecuteOperation(utils.SSHOperationDownload, cmd)
+	if err != nil {
+		return -1, output + err.Error()
+	}
+	idx := strings.LastIndex(output, "__exit=")
+	if idx < 0 {
+		return -1, output
+	}
+	code, convErr := strconv.Atoi(strings.TrimSpace(output[idx+len("__exit="):]))
+	if convErr != nil {
+		return -1, output
+	}
+	return code, output[:idx]
+}
+
+// remoteContentLength 通过HEAD请求获取文件大小，获取失败返回0表示不校验大小
+func remoteContentLength(client *utils.SSHClient, url string) int64 {
+	output, err := client.Execute(fmt.Sprintf("curl -4 -sIL --connect-timeout 30 --max-time 60 %s", utils.ShellQuote(url)))
+	if err != nil {
+		return 0
+	}
+	return ParseContentLength(output)
+}
+
+// ParseContentLength 解析curl -I -L的输出，返回最终响应的Content-Length
+// 跟随重定向时会输出多段响应头，只取最后一段；最终响应不是2xx或没有Content-Length时返回0
+func ParseContentLength(headers string) int64 {
+	var length int64
+	success := false
+	for _, line := range strings.Split(headers, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "HTTP/") {
+			fields := strings.Fields(line)
+			success = len(fields) >= 2 && strings.HasPrefix(fields[1], "2")
+			length = 0
+			continue
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if !ok || !strings.EqualFold(strings.TrimSpace(name), "content-length") {
+			continue
+		}
+		if n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64); err == nil && n > 0 {
+			length = n
+		}
+	}
+	if !success {
+		return 0
+	}
+	return length
+}
+
+// remoteFileSize 返回远程文件大小，文件不存在时返回0
+func remoteFileSize(client *utils.SSHClient, path string) int64 {
+	output, err := client.Execute(fmt.Sprintf("stat -c %%s %s 2>/dev/null || echo 0", utils.ShellQuote(path)))
+	if err != nil {
+		return 0
+	}
+	size, err := strconv.ParseInt(strings.TrimSpace(output), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return size
+}
@@ -0,0 +1,44 @@
+package provider
+
+import "testing"
+
+// TestParseContentLength 测试从HEAD响应头中解析最终的Content-Length
+func TestParseContentLength(t *testing.T) {
+	tests := []struct {
+		name     string
+		headers  string
+		expected int64
+	}{
+		{
+			name:     "单次响应",
+			headers:  "HTTP/1.1 200 OK\r\nContent-Type: application/octet-stream\r\nContent-Length: 1048576\r\n\r\n",
+			expected: 1048576,
+		},
+		{
+			name: "跟随重定向取最后一段",
+			headers: "HTTP/1.1 302 Found\r\nLocation: https://cdn.example.com/a.tar\r\nContent-Length: 154\r\n\r\n" +
+				"HTTP/2 200\r\ncontent-length: 2147483648\r\n\r\n",
+			expected: 2147483648,
+		},
+		{
+			name:     "最终响应没有Content-Length",
+			headers:  "HTTP/1.1 301 Moved\r\nContent-Length: 10\r\n\r\nHTTP/1.1 200 OK\r\nTransfer-Encoding: chunked\r\n\r\n",
+			expected: 0,
+		},
+		{
+			name:     "最终响应失败",
+			headers:  "HTTP/1.1 404 Not Found\r\nContent-Length: 512\r\n\r\n",
+			expected: 0,
+		},
+		{name: "空输出", headers: "", expected: 0},
+		{name: "非法长度", headers: "HTTP/1.1 200 OK\r\nContent-Length: abc\r\n\r\n", expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseContentLength(tt.headers); got != tt.expected {
+				t.Errorf("ParseContentLength() = %d, 期望 %d", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package provider
+
+import (
+	"strings"
+
+	"oneclickvirt/global"
+)
+
+// DefaultImageNamePrefix 导入到节点的镜像默认添加的名称前缀
+const DefaultImageNamePrefix = "oneclickvirt_"
+
+// ImageNamePrefixNone 配置为该值时导入的镜像不添加前缀
+const ImageNamePrefixNone = "none"
+
+// ResolveImageNamePrefix 将配置值转换为实际使用的前缀：空值使用默认前缀，none 表示不添加前缀
+func ResolveImageNamePrefix(configured string) string {
+	switch configured {
+	case "":
+		return DefaultImageNamePrefix
+	case ImageNamePrefixNone:
+		return ""
+	}
+	return configured
+}
+
+// ImageNameWithPrefix 返回镜像导入节点后使用的名称，导入、检查、清理和创建实例时都应通过该函数生成，保证名称一致
+func ImageNameWithPrefix(image string) string {
+	return ResolveImageNamePrefix(global.APP_CONFIG.System.ImageNamePrefix) + image
+}
+
+// IsRegistryReference 判断Docker/Podman镜像名称是否为镜像仓库引用（如 nginx:1.25、library/debian、ghcr.io/org/app@sha256:...）
+// 不含 '/'、':'、'@' 的名称视为从tar包导入的本地镜像别名，仍走下载导入流程并添加名称前缀
+func IsRegistryReference(image string) bool {
+	return strings.ContainsAny(image, "/:@")
+}
@@ -0,0 +1,58 @@
+package provider
+
+import (
+	"testing"
+
+	"oneclickvirt/global"
+)
+
+// TestResolveImageNamePrefix 测试镜像名称前缀配置的解析
+func TestResolveImageNamePrefix(t *testing.T) {
+	tests := map[string]string{
+		"":        DefaultImageNamePrefix,
+		"none":    "",
+		"ocv-":    "ocv-",
+		"team.a_": "team.a_",
+	}
+	for configured, expect := range tests {
+		if got := ResolveImageNamePrefix(configured); got != expect {
+			t.Fatalf("ResolveImageNamePrefix(%q) = %q，期望 %q", configured, got, expect)
+		}
+	}
+}
+
+// TestIsRegistryReference 测试仓库引用与本地镜像别名的区分
+func TestIsRegistryReference(t *testing.T) {
+	tests := map[string]bool{
+		"spiritlhl-debian":                 false,
+		"ubuntu":                           false,
+		"nginx:1.25":                       true,
+		"library/debian":                   true,
+		"ghcr.io/org/app:v1":               true,
+		"localhost:5000/app":               true,
+		"alpine@sha256:0123456789abcdef00": true,
+	}
+	for image, expect := range tests {
+		if got := IsRegistryReference(image); got != expect {
+			t.Errorf("IsRegistryReference(%q) = %v, 期望 %v", image, got, expect)
+		}
+	}
+}
+
+// TestImageNameWithPrefix 测试导入节点的镜像名称按当前配置添加前缀
+func TestImageNameWithPrefix(t *testing.T) {
+	original := global.APP_CONFIG.System.ImageNamePrefix
+	defer func() { global.APP_CONFIG.System.ImageNamePrefix = original }()
+
+	tests := map[string]string{
+		"":     "oneclickvirt_debian",
+		"none": "debian",
+		"ocv-": "ocv-debian",
+	}
+	for configured, expect := range tests {
+		global.APP_CONFIG.System.ImageNamePrefix = configured
+		if got := ImageNameWithPrefix("debian"); got != expect {
+			t.Errorf("配置前缀 %q 时 ImageNameWithPrefix(\"debian\") = %q, 期望 %q", configured, got, expect)
+		}
+	}
+}
@@ -209,6 +209,31 @@ func (i *IncusProvider) apiCreateInstanceWithProgress(ctx context.Context, confi
 
 	updateProgress(10, "开始Incus API创建实例...")
 
+	bridgeNetwork, err := i.validateRequestedIPs(config)
+	if err != nil {
+		return fmt.Errorf("指定IP校验失败: %w", err)
+	}
+	extraNICs, err := i.prepareExtraNICs(config)
+	if err != nil {
+		return fmt.Errorf("额外网卡校验失败: %w", err)
+	}
+	volumes, err := i.prepareVolumes(config)
+	if err != nil {
+		return fmt.Errorf("挂载目录校验失败: %w", err)
+	}
+	if err := i.validateGPUs(ctx, &config); err != nil {
+		return fmt.Errorf("直通GPU校验失败: %w", err)
+	}
+	if err := i.resolveCPULimit(&config); err != nil {
+		return fmt.Errorf("CPU限制校验失败: %w", err)
+	}
+	if config.DNSServers, err = provider.ValidateDNSServers(config.DNSServers); err != nil {
+		return fmt.Errorf("DNS服务器校验失败: %w", err)
+	}
+	if config.Hostname, err = provider.ValidateHostname(config.Hostname); err != nil {
+		return fmt.Errorf("主机名校验失败: %w", err)
+	}
+
 	// 在API创建之前，处理镜像下载和导入
 	updateProgress(30, "处理镜像下载和导入...")
 	if err := i.handleImageDownloadAndImport(ctx, &config); err != nil {
@@ -243,12 +268,42 @@ func (i *IncusProvider) apiCreateInstanceWithProgress(ctx context.Context, confi
 	if config.Memory != "" {
 		instanceConfig["config"].(map[string]interface{})["limits.memory"] = config.Memory
 	}
-	if config.Disk != "" {
-		instanceConfig["devices"].(map[string]interface{})["root"] = map[string]interface{}{
+	for k, v := range config.Labels {
+		instanceConfig["config"].(map[string]interface{})[tagConfigPrefix+k] = v
+	}
+	if config.Disk != "" || config.StoragePool != "" {
+		storagePool := config.StoragePool
+		if storagePool == "" {
+			storagePool = "default"
+		}
+		rootDevice := map[string]interface{}{
 			"type": "disk",
 			"path": "/",
-			"pool": "default",
-			"size": config.Disk,
+			"pool": storagePool,
+		}
+		if config.Disk != "" {
+			rootDevice["size"] = config.Disk
+		}
+		instanceConfig["devices"].(map[string]interface{})["root"] = rootDevice
+	}
+	if config.RequestedIPv4 != "" || config.RequestedIPv6 != "" {
+		instanceConfig["devices"].(map[string]interface{})["eth0"] = requestedIPDevice(bridgeNetwork, config)
+	}
+	for _, nic := range extraNICs {
+		device := make(map[string]interface{}, len(nic.Config))
+		for key, value := range nic.Config {
+			device[key] = value
+		}
+		instanceConfig["devices"].(map[string]interface{})[nic.Name] = device
+	}
+	for name, device := range volumeDevices(volumes) {
+		instanceConfig["devices"].(map[string]interface{})[name] = device
+	}
+	for idx, addr := range config.GPUDevices {
+		instanceConfig["devices"].(map[string]interface{})[gpuDeviceName(idx)] = map[string]interface{}{
+			"type":    "gpu",
+			"gputype": "physical",
+			"pci":     addr,
 		}
 	}
 
@@ -301,6 +356,9 @@ func (i *IncusProvider) apiCreateInstanceWithProgress(ctx context.Context, confi
 		}
 	}
 
+	i.configureInstanceDNS(config)
+	i.configureInstanceHostname(config)
+
 	updateProgress(100, "Incus API实例创建完成")
 	global.APP_LOG.Info("Incus API实例创建成功", zap.String("name", config.Name))
 	return nil
@@ -0,0 +1,121 @@
+package incus
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"oneclickvirt/global"
+
+	"go.uber.org/zap"
+)
+
+// CloneInstance 通过快照复制实例
+// 先对源实例创建临时快照再复制，避免直接复制运行中的虚拟机失败；
+// 克隆出的实例会移除从源实例继承的proxy设备和静态IP绑定，防止与源实例的端口映射和地址冲突
+func (i *IncusProvider) CloneInstance(ctx context.Context, sourceName, newName string) error {
+	if !i.connected || i.sshClient == nil {
+		return fmt.Errorf("not connected")
+	}
+	if sourceName == "" || newName == "" {
+		return fmt.Errorf("实例名称不能为空")
+	}
+
+	if !i.hasInstance(sourceName) {
+		return fmt.Errorf("源实例 %s 不存在", sourceName)
+	}
+	if i.hasInstance(newName) {
+		return fmt.Errorf("目标实例 %s 已存在", newName)
+	}
+
+	snapshotName := fmt.Sprintf("clone-%d", time.Now().Unix())
+	if output, err := i.sshClient.Execute(fmt.Sprintf("incus snapshot create %s %s", sourceName, snapshotName)); err != nil {
+		return fmt.Errorf("创建源实例快照失败: %s: %w", strings.TrimSpace(output), err)
+	}
+	defer func() {
+		if output, err := i.sshClient.Execute(fmt.Sprintf("incus snapshot delete %s %s", sourceName, snapshotName)); err != nil {
+			global.APP_LOG.Warn("删除克隆临时快照失败",
+				zap.String("source", sourceName),
+				zap.String("snapshot", snapshotName),
+				zap.String("output", strings.TrimSpace(output)),
+				zap.Error(err))
+		}
+	}()
+
+	copyCmd := fmt.Sprintf("incus copy %s/%s %s", sourceName, snapshotName, newName)
+	if output, err := i.sshClient.Execute(copyCmd); err != nil {
+		return fmt.Errorf("复制实例失败: %s: %w", strings.TrimSpace(output), err)
+	}
+
+	// 移除继承的proxy设备，端口映射由服务层重新分配
+	removeProxyCmd := fmt.Sprintf(`for d in $(incus config device list %[1]s); do [ "$(incus config device get %[1]s $d type 2>/dev/null)" = "proxy" ] && incus config device remove %[1]s $d; done; true`, newName)
+	if output, err := i.sshClient.Execute(removeProxyCmd); err != nil {
+		global.APP_LOG.Warn("移除克隆实例的proxy设备失败",
+			zap.String("instance", newName),
+			zap.String("output", strings.TrimSpace(output)),
+			zap.Error(err))
+	}
+
+	// 复制会保留源实例网卡上绑定的静态IP，启动前清除，否则克隆实例会与源实例使用相同的地址
+	unsetIPCmd := fmt.Sprintf(`for d in eth0 enp5s0; do incus config device unset %[1]s $d ipv4.address 2>/dev/null; incus config device unset %[1]s $d ipv6.address 2>/dev/null; done; true`, newName)
+	if output, err := i.sshClient.Execute(unsetIPCmd); err != nil {
+		global.APP_LOG.Warn("清除克隆实例继承的IP绑定失败",
+			zap.String("instance", newName),
+			zap.String("output", strings.TrimSpace(output)),
+			zap.Error(err))
+	}
+
+	if output, err := i.sshClient.Execute(fmt.Sprintf("incus start %s", newName)); err != nil {
+		_, _ = i.sshClient.Execute(i.deleteInstanceCommand(newName))
+		return fmt.Errorf("启动克隆实例失败: %s: %w", strings.TrimSpace(output), err)
+	}
+
+	if err := i.bindClonedInstanceIP(ctx, newName); err != nil {
+		_, _ = i.sshClient.Execute(i.deleteInstanceCommand(newName))
+		return err
+	}
+
+	global.APP_LOG.Info("Incus实例克隆成功",
+		zap.String("source", sourceName),
+		zap.String("instance", newName))
+	return nil
+}
+
+// bindClonedInstanceIP 等待克隆实例通过DHCP获取新地址后重新绑定静态IP，与创建实例时的网络配置保持一致
+func (i *IncusProvider) bindClonedInstanceIP(ctx context.Context, instanceName string) error {
+	instanceType, _ := i.getInstanceType(instanceName)
+	var err error
+	if instanceType == "virtual-machine" {
+		err = i.waitForVMNetworkReady(instanceName)
+	} else {
+		err = i.waitForContainerNetworkReady(instanceName)
+	}
+	if err != nil {
+		return fmt.Errorf("等待克隆实例获取IP地址失败: %w", err)
+	}
+
+	instanceIP, err := i.getInstanceIP(instanceName)
+	if err != nil {
+		return fmt.Errorf("获取克隆实例IP地址失败: %w", err)
+	}
+
+	if err := i.stopInstanceForConfig(instanceName); err != nil {
+		return fmt.Errorf("停止克隆实例进行配置失败: %w", err)
+	}
+	if err := i.setIPAddressBinding(instanceName, instanceIP); err != nil {
+		global.APP_LOG.Warn("设置克隆实例IP地址绑定失败",
+			zap.String("instance", instanceName),
+			zap.Error(err))
+	}
+	if err := i.StartInstance(ctx, instanceName); err != nil {
+		return fmt.Errorf("启动克隆实例失败: %w", err)
+	}
+	return nil
+}
+
+// hasInstance 通过info精确检查实例是否存在（list按前缀过滤，不适合精确判断）
+func (i *IncusProvider) hasInstance(name string) bool {
+	_, err := i.sshClient.Execute(fmt.Sprintf("incus info %s >/dev/null 2>&1", name))
+	return err == nil
+}
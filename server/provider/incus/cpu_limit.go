@@ -0,0 +1,40 @@
+package incus
+
+import (
+	"strconv"
+	"strings"
+
+	"oneclickvirt/global"
+	"oneclickvirt/provider"
+
+	"go.uber.org/zap"
+)
+
+// nodeCPUCores 获取节点CPU总核心数，获取失败时返回0
+func (i *IncusProvider) nodeCPUCores() int {
+	output, err := i.sshClient.Execute("nproc")
+	if err != nil {
+		global.APP_LOG.Warn("获取节点CPU核心数失败，跳过核心数上限校验", zap.Error(err))
+		return 0
+	}
+	cores, err := strconv.Atoi(strings.TrimSpace(output))
+	if err != nil || cores < 1 {
+		global.APP_LOG.Warn("解析节点CPU核心数失败，跳过核心数上限校验", zap.String("output", strings.TrimSpace(output)))
+		return 0
+	}
+	return cores
+}
+
+// resolveCPULimit 校验实例CPU参数并规范化为 limits.cpu 的值
+// 核心数不能超过节点总核心数，核心绑定范围必须位于节点核心编号内
+func (i *IncusProvider) resolveCPULimit(config *provider.InstanceConfig) error {
+	if strings.TrimSpace(config.CPU) == "" {
+		return nil
+	}
+	limit, err := provider.ParseCPULimit(config.CPU, i.nodeCPUCores())
+	if err != nil {
+		return err
+	}
+	config.CPU = limit
+	return nil
+}
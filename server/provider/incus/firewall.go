@@ -0,0 +1,66 @@
+package incus
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"oneclickvirt/global"
+	"oneclickvirt/provider"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+)
+
+// firewallHooks 端口映射的NAT转发经过FORWARD，proxy设备由宿主机进程转发经过OUTPUT
+var firewallHooks = []string{"FORWARD", "OUTPUT"}
+
+// ManageFirewallRule 在宿主机上添加或删除实例的防火墙规则，按实例IP匹配发往实例的流量
+// 网络ACL只适用于受管网络且需要改写实例网卡设备，这里统一使用宿主机iptables
+func (i *IncusProvider) ManageFirewallRule(ctx context.Context, instanceName string, rule provider.FirewallRule) error {
+	if !i.connected || i.sshClient == nil {
+		return fmt.Errorf("not connected")
+	}
+
+	ips, err := i.firewallTargetIPs(ctx, instanceName)
+	if err != nil {
+		return err
+	}
+	cmd, err := provider.BuildFirewallRuleCommand(instanceName, ips, firewallHooks, rule)
+	if err != nil {
+		return err
+	}
+	if output, err := i.sshClient.Execute(cmd); err != nil {
+		return fmt.Errorf("应用防火墙规则失败: %s: %w", utils.TruncateString(strings.TrimSpace(output), 200), err)
+	}
+
+	global.APP_LOG.Info("实例防火墙规则已更新",
+		zap.String("instance", instanceName),
+		zap.String("protocol", rule.Protocol),
+		zap.Int("port", rule.Port),
+		zap.String("action", rule.Action),
+		zap.Bool("remove", rule.Remove))
+	return nil
+}
+
+// ClearFirewallRules 删除实例的全部防火墙规则
+func (i *IncusProvider) ClearFirewallRules(ctx context.Context, instanceName string) error {
+	if !i.connected || i.sshClient == nil {
+		return fmt.Errorf("not connected")
+	}
+	_, err := i.sshClient.Execute(provider.BuildFirewallCleanupCommand(instanceName))
+	return err
+}
+
+// firewallTargetIPs 返回实例的内网IPv4和IPv6地址，IPv6获取失败时只返回IPv4
+func (i *IncusProvider) firewallTargetIPs(ctx context.Context, instanceName string) ([]string, error) {
+	ipv4, err := i.getInstanceIP(instanceName)
+	if err != nil {
+		return nil, fmt.Errorf("获取实例IP失败: %w", err)
+	}
+	ips := []string{ipv4}
+	if ipv6, err := i.GetInstanceIPv6(ctx, instanceName); err == nil && ipv6 != "" {
+		ips = append(ips, ipv6)
+	}
+	return ips, nil
+}
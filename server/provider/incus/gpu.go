@@ -0,0 +1,121 @@
+package incus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"oneclickvirt/global"
+	"oneclickvirt/provider"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+)
+
+// gpuDeviceName 第idx块直通GPU的设备名称
+func gpuDeviceName(idx int) string {
+	return fmt.Sprintf("gpu%d", idx)
+}
+
+// ListGPUs 列出宿主机上的GPU及其直通占用情况
+func (i *IncusProvider) ListGPUs(ctx context.Context) ([]provider.GPUDevice, error) {
+	if i.sshClient == nil {
+		return nil, fmt.Errorf("查询GPU需要SSH连接")
+	}
+	output, err := i.sshClient.Execute(provider.GPUListCommand)
+	if err != nil {
+		return nil, fmt.Errorf("查询宿主机GPU失败: %w", err)
+	}
+	gpus := provider.ParseGPUList(output)
+	if len(gpus) == 0 {
+		return gpus, nil
+	}
+
+	listOutput, err := i.sshClient.Execute("incus list --format json")
+	if err != nil {
+		return nil, fmt.Errorf("查询实例设备失败: %w", err)
+	}
+	owners, err := parseGPUAssignments(listOutput)
+	if err != nil {
+		return nil, err
+	}
+	for idx := range gpus {
+		gpus[idx].AssignedTo = owners[gpus[idx].Address]
+	}
+	return gpus, nil
+}
+
+// parseGPUAssignments 解析incus list的JSON输出，返回PCI地址到实例名称的映射
+// 统计按PCI地址直通的gpu设备和pci设备，按厂商或ID共享的gpu设备不独占宿主机GPU
+func parseGPUAssignments(output string) (map[string]string, error) {
+	var instances []struct {
+		Name            string                       `json:"name"`
+		ExpandedDevices map[string]map[string]string `json:"expanded_devices"`
+	}
+	if err := json.Unmarshal([]byte(output), &instances); err != nil {
+		return nil, fmt.Errorf("解析实例列表失败: %w", err)
+	}
+
+	owners := make(map[string]string)
+	for _, inst := range instances {
+		for _, device := range inst.ExpandedDevices {
+			var addr string
+			switch device["type"] {
+			case "gpu":
+				addr = device["pci"]
+			case "pci":
+				addr = device["address"]
+			}
+			if normalized, err := provider.NormalizePCIAddress(addr); err == nil {
+				owners[normalized] = inst.Name
+			}
+		}
+	}
+	return owners, nil
+}
+
+// validateGPUs 校验直通GPU配置：仅支持虚拟机，设备必须存在、已开启IOMMU且未被其他实例占用
+func (i *IncusProvider) validateGPUs(ctx context.Context, config *provider.InstanceConfig) error {
+	if len(config.GPUDevices) == 0 {
+		return nil
+	}
+	if config.InstanceType != "vm" {
+		return fmt.Errorf("GPU直通仅支持虚拟机")
+	}
+	devices, err := provider.ValidateGPUDevices(config.GPUDevices)
+	if err != nil {
+		return err
+	}
+	gpus, err := i.ListGPUs(ctx)
+	if err != nil {
+		return err
+	}
+	if err := provider.CheckGPUsAssignable(devices, gpus, config.Name); err != nil {
+		return err
+	}
+	config.GPUDevices = devices
+	return nil
+}
+
+// attachGPUs 在实例首次启动前添加直通GPU设备，失败时删除已创建的实例
+func (i *IncusProvider) attachGPUs(instanceName string, devices []string) error {
+	for idx, addr := range devices {
+		cmd := fmt.Sprintf("incus config device add %s %s gpu gputype=physical pci=%s", instanceName, gpuDeviceName(idx), addr)
+		if output, err := i.sshClient.Execute(cmd); err != nil {
+			global.APP_LOG.Error("添加直通GPU失败，删除已创建的实例",
+				zap.String("instance", instanceName),
+				zap.String("pci", addr),
+				zap.String("output", utils.TruncateString(output, 200)),
+				zap.Error(err))
+			i.sshClient.Execute(fmt.Sprintf("incus delete %s --force", instanceName))
+			return fmt.Errorf("添加直通GPU %s 失败: %w", addr, err)
+		}
+	}
+
+	if len(devices) > 0 {
+		global.APP_LOG.Info("已为实例添加直通GPU",
+			zap.String("instance", instanceName),
+			zap.Strings("devices", devices))
+	}
+	return nil
+}
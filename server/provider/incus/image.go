@@ -27,7 +27,7 @@ func (i *IncusProvider) handleImageDownloadAndImport(ctx context.Context, config
 
 	// 为镜像名称添加前缀
 	originalImageName := config.Image
-	imageNameWithPrefix := "oneclickvirt_" + config.Image
+	imageNameWithPrefix := provider.ImageNameWithPrefix(config.Image)
 
 	// 根据实例类型确定镜像类型
 	var imageTypeStr string
@@ -45,7 +45,7 @@ func (i *IncusProvider) handleImageDownloadAndImport(ctx context.Context, config
 			zap.Bool("useCDN", config.UseCDN))
 
 		// 直接在远程服务器上下载镜像
-		imagePath, err := i.downloadImageToRemote(config.ImageURL, originalImageName, i.config.Architecture, config.InstanceType, config.UseCDN)
+		imagePath, err := i.downloadImageToRemote(config.ImageURL, originalImageName, i.config.Architecture, config.InstanceType, config.ImageSHA256, config.UseCDN)
 		if err != nil {
 			return fmt.Errorf("下载%s镜像失败: %w", imageTypeStr, err)
 		}
@@ -143,7 +143,7 @@ func (i *IncusProvider) handleImageDownloadAndImport(ctx context.Context, config
 				}
 			}
 
-			_, err := i.sshClient.Execute(importCmd)
+			_, err := i.sshClient.ExecuteOperation(utils.SSHOperationDownload, importCmd)
 			if err != nil {
 				return fmt.Errorf("Incus%s镜像导入失败: %w", imageTypeStr, err)
 			}
@@ -217,6 +217,9 @@ func (i *IncusProvider) queryAndSetSystemImage(ctx context.Context, config *prov
 	if systemImage.URL != "" {
 		config.ImageURL = systemImage.URL
 		config.UseCDN = systemImage.UseCDN // 传递UseCDN配置给后续流程
+		if config.ImageSHA256 == "" {
+			config.ImageSHA256 = provider.NormalizeSHA256(systemImage.Checksum)
+		}
 		global.APP_LOG.Info("从数据库获取到系统镜像配置",
 			zap.String("imageName", systemImage.Name),
 			zap.String("originalURL", utils.TruncateString(systemImage.URL, 100)),
@@ -259,7 +262,8 @@ func (i *IncusProvider) isRemoteFileValid(remotePath string) bool {
 }
 
 // downloadImageToRemote 在远程服务器上下载镜像
-func (i *IncusProvider) downloadImageToRemote(imageURL, imageName, architecture, instanceType string, useCDN bool) (string, error) {
+// expectedSHA256非空时校验已缓存和新下载的文件，缓存文件不匹配时重新下载，新下载的文件不匹配时删除并返回错误
+func (i *IncusProvider) downloadImageToRemote(imageURL, imageName, architecture, instanceType, expectedSHA256 string, useCDN bool) (string, error) {
 	// 根据实例类型确定远程下载目录
 	var downloadDir string
 	if instanceType == "vm" {
@@ -281,10 +285,18 @@ func (i *IncusProvider) downloadImageToRemote(imageURL, imageName, architecture,
 
 	// 检查远程文件是否已存在
 	if i.isRemoteFileValid(remotePath) {
-		global.APP_LOG.Info("远程镜像文件已存在且完整，跳过下载",
-			zap.String("imageName", imageName),
-			zap.String("remotePath", remotePath))
-		return remotePath, nil
+		if err := provider.VerifyRemoteSHA256(i.sshClient, remotePath, expectedSHA256); err != nil {
+			global.APP_LOG.Warn("远程缓存镜像文件校验失败，重新下载",
+				zap.String("imageName", imageName),
+				zap.String("remotePath", remotePath),
+				zap.Error(err))
+			i.removeRemoteFile(remotePath)
+		} else {
+			global.APP_LOG.Info("远程镜像文件已存在且完整，跳过下载",
+				zap.String("imageName", imageName),
+				zap.String("remotePath", remotePath))
+			return remotePath, nil
+		}
 	}
 
 	// 如果文件存在但无效，先删除它
@@ -301,11 +313,20 @@ func (i *IncusProvider) downloadImageToRemote(imageURL, imageName, architecture,
 
 	// 在远程服务器上下载文件
 	if err := i.downloadFileToRemote(downloadURL, remotePath); err != nil {
-		// 下载失败，删除不完整的文件
+		// 下载失败，部分文件保留在.tmp中供下次续传
 		i.removeRemoteFile(remotePath)
 		return "", fmt.Errorf("远程下载镜像失败: %w", err)
 	}
 
+	if err := provider.VerifyRemoteSHA256(i.sshClient, remotePath, expectedSHA256); err != nil {
+		i.removeRemoteFile(remotePath)
+		global.APP_LOG.Error("远程镜像文件校验失败",
+			zap.String("imageName", imageName),
+			zap.String("remotePath", remotePath),
+			zap.Error(err))
+		return "", err
+	}
+
 	global.APP_LOG.Info("远程镜像下载完成",
 		zap.String("imageName", imageName),
 		zap.String("remotePath", remotePath))
@@ -339,49 +360,11 @@ func (i *IncusProvider) removeRemoteFile(remotePath string) error {
 	return err
 }
 
-// downloadFileToRemote 在远程服务器上下载文件
+// downloadFileToRemote 在远程服务器上下载文件，中断后保留部分文件以便下次断点续传
 func (i *IncusProvider) downloadFileToRemote(url, remotePath string) error {
-	// 使用curl在远程服务器上下载文件
-	tmpPath := remotePath + ".tmp"
-
-	// 下载文件，支持断点续传
-	curlCmd := fmt.Sprintf(
-		"curl -4 -L -C - --connect-timeout 30 --retry 5 --retry-delay 10 --retry-max-time 0 -o %s '%s'",
-		tmpPath, url,
-	)
-
 	global.APP_LOG.Info("执行远程下载命令",
 		zap.String("url", utils.TruncateString(url, 100)))
-
-	output, err := i.sshClient.Execute(curlCmd)
-	if err != nil {
-		// 清理临时文件
-		i.sshClient.Execute(fmt.Sprintf("rm -f %s", tmpPath))
-
-		global.APP_LOG.Error("远程下载失败",
-			zap.String("url", utils.TruncateString(url, 100)),
-			zap.String("remotePath", remotePath),
-			zap.String("output", utils.TruncateString(output, 500)),
-			zap.Error(err))
-		return fmt.Errorf("远程下载失败: %w", err)
-	}
-
-	// 移动文件到最终位置
-	mvCmd := fmt.Sprintf("mv %s %s", tmpPath, remotePath)
-	_, err = i.sshClient.Execute(mvCmd)
-	if err != nil {
-		global.APP_LOG.Error("移动文件失败",
-			zap.String("tmpPath", tmpPath),
-			zap.String("remotePath", remotePath),
-			zap.Error(err))
-		return fmt.Errorf("移动文件失败: %w", err)
-	}
-
-	global.APP_LOG.Info("远程下载成功",
-		zap.String("url", utils.TruncateString(url, 100)),
-		zap.String("remotePath", remotePath))
-
-	return nil
+	return provider.DownloadRemoteFile(i.sshClient, url, remotePath)
 }
 
 // cleanupRemoteImage 清理远程镜像文件
@@ -12,6 +12,7 @@ import (
 	"oneclickvirt/global"
 	"oneclickvirt/provider"
 	"oneclickvirt/provider/health"
+	"oneclickvirt/service/metrics"
 	"oneclickvirt/utils"
 
 	"go.uber.org/zap"
@@ -26,6 +27,8 @@ type IncusProvider struct {
 	connected     bool
 	healthChecker health.HealthChecker
 	mu            sync.RWMutex // 保护并发访问
+
+	capabilities provider.CapabilitiesCache // 能力探测结果缓存
 }
 
 func NewIncusProvider() provider.Provider {
@@ -60,6 +63,7 @@ func (i *IncusProvider) GetSupportedInstanceTypes() []string {
 
 func (i *IncusProvider) Connect(ctx context.Context, config provider.NodeConfig) error {
 	i.config = config
+	i.capabilities.Invalidate()
 	i.providerID = config.ID // 存储providerID
 
 	// Transport 已在 NewIncusProvider 中创建，现在关联providerID
@@ -102,13 +106,19 @@ func (i *IncusProvider) Connect(ctx context.Context, config provider.NodeConfig)
 	}
 
 	sshConfig := utils.SSHConfig{
-		Host:           config.Host,
-		Port:           config.Port,
-		Username:       config.Username,
-		Password:       config.Password,
-		PrivateKey:     config.PrivateKey,
-		ConnectTimeout: time.Duration(sshConnectTimeout) * time.Second,
-		ExecuteTimeout: time.Duration(sshExecuteTimeout) * time.Second,
+		Host:                 config.Host,
+		Port:                 config.Port,
+		Username:             config.Username,
+		Password:             config.Password,
+		PrivateKey:           config.PrivateKey,
+		PrivateKeyPassphrase: config.PrivateKeyPassphrase,
+		UseAgent:             config.UseSSHAgent,
+		VerifyHostKey:        config.SSHVerifyHostKey,
+		HostKeyFingerprint:   config.SSHHostKeyFingerprint,
+		OnHostKeyPinned:      config.OnHostKeyPinned,
+		ConnectTimeout:       time.Duration(sshConnectTimeout) * time.Second,
+		ExecuteTimeout:       time.Duration(sshExecuteTimeout) * time.Second,
+		CommandLogID:         config.ID,
 	}
 	client, err := utils.NewSSHClient(sshConfig)
 	if err != nil {
@@ -119,19 +129,23 @@ func (i *IncusProvider) Connect(ctx context.Context, config provider.NodeConfig)
 
 	// 初始化健康检查器，使用Provider的SSH连接，避免创建独立连接导致节点混淆
 	healthConfig := health.HealthConfig{
-		Host:          config.Host,
-		Port:          config.Port,
-		Username:      config.Username,
-		Password:      config.Password,
-		PrivateKey:    config.PrivateKey,
-		APIEnabled:    config.CertPath != "" && config.KeyPath != "",
-		APIPort:       8443,
-		APIScheme:     "https",
-		SSHEnabled:    true,
-		Timeout:       30 * time.Second,
-		ServiceChecks: []string{"incus"},
-		CertPath:      config.CertPath,
-		KeyPath:       config.KeyPath,
+		Host:                 config.Host,
+		Port:                 config.Port,
+		Username:             config.Username,
+		Password:             config.Password,
+		PrivateKey:           config.PrivateKey,
+		PrivateKeyPassphrase: config.PrivateKeyPassphrase,
+		UseAgent:             config.UseSSHAgent,
+		VerifyHostKey:        config.SSHVerifyHostKey,
+		HostKeyFingerprint:   config.SSHHostKeyFingerprint,
+		APIEnabled:           config.CertPath != "" && config.KeyPath != "",
+		APIPort:              8443,
+		APIScheme:            "https",
+		SSHEnabled:           true,
+		Timeout:              30 * time.Second,
+		ServiceChecks:        []string{"incus"},
+		CertPath:             config.CertPath,
+		KeyPath:              config.KeyPath,
 	}
 
 	zapLogger, _ := zap.NewProduction()
@@ -140,7 +154,8 @@ func (i *IncusProvider) Connect(ctx context.Context, config provider.NodeConfig)
 
 	global.APP_LOG.Info("Incus provider SSH连接成功",
 		zap.String("host", utils.TruncateString(config.Host, 32)),
-		zap.Int("port", config.Port))
+		zap.Int("port", config.Port),
+		zap.String("authMethod", client.AuthMethod()))
 	return nil
 }
 
@@ -234,10 +249,18 @@ func (i *IncusProvider) ListInstances(ctx context.Context) ([]provider.Instance,
 }
 
 func (i *IncusProvider) CreateInstance(ctx context.Context, config provider.InstanceConfig) error {
+	if err := provider.ValidateInstanceName(config.Name); err != nil {
+		return err
+	}
+
 	if !i.connected {
 		return fmt.Errorf("not connected")
 	}
 
+	if err := i.validateStoragePool(ctx, config.StoragePool); err != nil {
+		return err
+	}
+
 	// 根据执行规则判断使用哪种方式
 	if i.shouldUseAPI() {
 		if err := i.apiCreateInstance(ctx, config); err == nil {
@@ -264,10 +287,18 @@ func (i *IncusProvider) CreateInstance(ctx context.Context, config provider.Inst
 }
 
 func (i *IncusProvider) CreateInstanceWithProgress(ctx context.Context, config provider.InstanceConfig, progressCallback provider.ProgressCallback) error {
+	if err := provider.ValidateInstanceName(config.Name); err != nil {
+		return err
+	}
+
 	if !i.connected {
 		return fmt.Errorf("not connected")
 	}
 
+	if err := i.validateStoragePool(ctx, config.StoragePool); err != nil {
+		return err
+	}
+
 	// 根据执行规则判断使用哪种方式
 	if i.shouldUseAPI() {
 		if err := i.apiCreateInstanceWithProgress(ctx, config, progressCallback); err == nil {
@@ -293,6 +324,10 @@ func (i *IncusProvider) CreateInstanceWithProgress(ctx context.Context, config p
 }
 
 func (i *IncusProvider) StartInstance(ctx context.Context, id string) error {
+	if err := provider.ValidateInstanceName(id); err != nil {
+		return err
+	}
+
 	if !i.connected {
 		return fmt.Errorf("not connected")
 	}
@@ -322,6 +357,10 @@ func (i *IncusProvider) StartInstance(ctx context.Context, id string) error {
 }
 
 func (i *IncusProvider) StopInstance(ctx context.Context, id string) error {
+	if err := provider.ValidateInstanceName(id); err != nil {
+		return err
+	}
+
 	if !i.connected {
 		return fmt.Errorf("not connected")
 	}
@@ -351,6 +390,10 @@ func (i *IncusProvider) StopInstance(ctx context.Context, id string) error {
 }
 
 func (i *IncusProvider) RestartInstance(ctx context.Context, id string) error {
+	if err := provider.ValidateInstanceName(id); err != nil {
+		return err
+	}
+
 	if !i.connected {
 		return fmt.Errorf("not connected")
 	}
@@ -380,6 +423,10 @@ func (i *IncusProvider) RestartInstance(ctx context.Context, id string) error {
 }
 
 func (i *IncusProvider) DeleteInstance(ctx context.Context, id string) error {
+	if err := provider.ValidateInstanceName(id); err != nil {
+		return err
+	}
+
 	if !i.connected {
 		return fmt.Errorf("not connected")
 	}
@@ -408,6 +455,37 @@ func (i *IncusProvider) DeleteInstance(ctx context.Context, id string) error {
 	return i.sshDeleteInstance(id)
 }
 
+// deleteInstanceCommand 删除实例的SSH命令
+func (i *IncusProvider) deleteInstanceCommand(id string) string {
+	return fmt.Sprintf("incus delete %s --force", id)
+}
+
+// PlanDeleteInstance 返回删除实例时将按顺序执行的操作（仅预览，不执行）
+func (i *IncusProvider) PlanDeleteInstance(ctx context.Context, id string) ([]string, error) {
+	if !i.connected {
+		return nil, fmt.Errorf("not connected")
+	}
+	if id == "" {
+		return nil, fmt.Errorf("实例名称不能为空")
+	}
+
+	var commands []string
+	if i.shouldUseAPI() {
+		commands = append(commands, fmt.Sprintf("# API: DELETE https://%s:8443/1.0/instances/%s", i.config.Host, id))
+		if !i.shouldFallbackToSSH() {
+			return commands, nil
+		}
+		commands = append(commands, "# API失败时回退到SSH")
+	}
+	if i.shouldUseSSH() {
+		commands = append(commands, i.deleteInstanceCommand(id))
+	}
+	if len(commands) == 0 {
+		return nil, fmt.Errorf("执行规则不允许使用SSH")
+	}
+	return commands, nil
+}
+
 func (i *IncusProvider) GetInstance(ctx context.Context, id string) (*provider.Instance, error) {
 	instances, err := i.ListInstances(ctx)
 	if err != nil {
@@ -541,7 +619,7 @@ func (i *IncusProvider) createTLSConfig(certPath, keyPath string) (*tls.Config,
 	return tlsConfig, nil
 }
 
-// ExecuteSSHCommand 执行SSH命令
+// ExecuteSSHCommand 执行SSH命令，超时按ctx中的操作类型确定
 func (i *IncusProvider) ExecuteSSHCommand(ctx context.Context, command string) (string, error) {
 	if !i.connected || i.sshClient == nil {
 		return "", fmt.Errorf("Incus provider not connected")
@@ -550,7 +628,9 @@ func (i *IncusProvider) ExecuteSSHCommand(ctx context.Context, command string) (
 	global.APP_LOG.Debug("执行SSH命令",
 		zap.String("command", utils.TruncateString(command, 200)))
 
-	output, err := i.sshClient.Execute(command)
+	start := time.Now()
+	output, err := i.sshClient.ExecuteWithTimeout(command, utils.SSHOperationTimeout(utils.SSHOperationFromContext(ctx)), utils.SSHOutputLimitFromContext(ctx))
+	metrics.ObserveSSHCommand(i.config.Name, start, err)
 	if err != nil {
 		global.APP_LOG.Error("SSH命令执行失败",
 			zap.String("command", utils.TruncateString(command, 200)),
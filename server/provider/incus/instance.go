@@ -108,27 +108,18 @@ func (i *IncusProvider) buildCreateCommand(config provider.InstanceConfig) (stri
 		}
 
 		// 3. CPU限制配置（CPU Allowance vs limits.cpu）
-		if config.CPUAllowance != nil && *config.CPUAllowance != "" && *config.CPUAllowance != "100%" {
-			configParams = append(configParams, fmt.Sprintf("limits.cpu.allowance=%s", *config.CPUAllowance))
-			configParams = append(configParams, "limits.cpu.priority=0")
-		} else {
-			configParams = append(configParams, "limits.cpu.priority=0")
-			configParams = append(configParams, "limits.cpu.allowance=50%")
-			configParams = append(configParams, "limits.cpu.allowance=25ms/100ms")
+		// 节点指定了限额时直接使用，否则按实例核心数和节点配置的限额方式生成
+		configParams = append(configParams, "limits.cpu.priority=0")
+		if allowance := provider.ContainerCPUAllowance(config, i.config.ContainerCPUAllowanceMode); allowance != "" {
+			configParams = append(configParams, fmt.Sprintf("limits.cpu.allowance=%s", allowance))
 		}
 
-		// 4. 内存交换配置（Memory Swap）
-		if config.MemorySwap != nil {
-			if *config.MemorySwap {
-				configParams = append(configParams, "limits.memory.swap=true")
-				configParams = append(configParams, "limits.memory.swap.priority=1")
-			} else {
-				configParams = append(configParams, "limits.memory.swap=false")
-			}
-		} else {
-			// 默认启用swap（保持原有行为）
+		// 4. 内存交换配置（Memory Swap），LXC只能开关swap，无法限制swap大小
+		if provider.LXCMemorySwapEnabled(config) {
 			configParams = append(configParams, "limits.memory.swap=true")
 			configParams = append(configParams, "limits.memory.swap.priority=1")
+		} else {
+			configParams = append(configParams, "limits.memory.swap=false")
 		}
 
 		// 5. 最大进程数配置（Max Processes）
@@ -145,6 +136,14 @@ func (i *IncusProvider) buildCreateCommand(config provider.InstanceConfig) (stri
 		}
 	}
 
+	// 实例标签同步为user.tag.*配置项
+	configParams = append(configParams, tagConfigParams(config.Labels)...)
+
+	// 指定存储池
+	if config.StoragePool != "" {
+		cmd += fmt.Sprintf(" --storage %s", config.StoragePool)
+	}
+
 	// 配置参数到命令
 	for _, param := range configParams {
 		cmd += fmt.Sprintf(" -c %s", param)
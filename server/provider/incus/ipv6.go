@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"oneclickvirt/global"
+	"oneclickvirt/provider"
+	"oneclickvirt/provider/netfilter"
 
 	"go.uber.org/zap"
 )
@@ -310,14 +312,11 @@ func (i *IncusProvider) installSipcalcRHEL(ctx context.Context) error {
 
 	filename := "sipcalc-1.1.6-17.el8." + arch + ".rpm"
 
-	for _, mirror := range mirrors {
-		global.APP_LOG.Info("尝试从镜像下载sipcalc", zap.String("mirror", mirror))
-		downloadCmd := fmt.Sprintf("curl -fLO '%s'", mirror)
-		_, err := i.sshClient.Execute(downloadCmd)
-		if err == nil {
-			break
-		}
+	mirror, err := provider.DownloadFromMirrors(i.sshClient, mirrors, filename, provider.DownloadKindRPM)
+	if err != nil {
+		return fmt.Errorf("下载sipcalc失败: %w", err)
 	}
+	global.APP_LOG.Info("已从镜像下载sipcalc", zap.String("mirror", mirror))
 
 	// 安装rpm包
 	installCmd := fmt.Sprintf("rpm -ivh %s", filename)
@@ -654,29 +653,18 @@ func (i *IncusProvider) setupIptablesIPv6(ctx context.Context, config IPv6Config
 	global.APP_LOG.Info("开始配置iptables IPv6映射",
 		zap.String("container", config.ContainerName))
 
-	// 检测操作系统类型
-	osType, err := i.detectOS(ctx)
+	// 检测操作系统类型和防火墙后端
+	osType, err := netfilter.DetectOS(i.sshClient)
 	if err != nil {
-		return "", fmt.Errorf("检测操作系统失败: %w", err)
-	}
-
-	// 检查是否使用firewalld
-	useFirewalld := false
-	if osType == "centos" || osType == "almalinux" || osType == "rocky" {
-		_, err := i.sshClient.Execute("command -v dnf")
-		if err == nil {
-			useFirewalld = true
-		}
-		_, err = i.sshClient.Execute("command -v yum")
-		if err == nil {
-			useFirewalld = true
-		}
+		return "", err
 	}
+	backend := netfilter.Detect(i.sshClient, osType)
 
-	// 安装必要的包
-	err = i.installNetfilterPackages(ctx, osType, useFirewalld)
-	if err != nil {
-		global.APP_LOG.Warn("安装网络过滤包失败", zap.Error(err))
+	// 安装必要的包并初始化规则表
+	if err := backend.Prepare(); err != nil {
+		global.APP_LOG.Warn("初始化防火墙后端失败",
+			zap.String("backend", backend.Name()),
+			zap.Error(err))
 	}
 
 	// 获取容器的内网IPv6地址
@@ -725,53 +713,9 @@ func (i *IncusProvider) setupIptablesIPv6(ctx context.Context, config IPv6Config
 		zap.String("containerIPv6", containerIPv6))
 
 	// 查找可用的IPv6地址
-	var mappedIPv6 string
-	for idx := 3; idx <= 65535; idx++ {
-		testIPv6 := fmt.Sprintf("%s%d", subnetPrefix, idx)
-
-		// 跳过容器本身的地址
-		if testIPv6 == containerIPv6 {
-			continue
-		}
-
-		// 检查地址是否已被使用
-		checkAddrCmd := fmt.Sprintf("ip -6 addr show dev %s | grep -qw %s", interfaceName, testIPv6)
-		_, err := i.sshClient.Execute(checkAddrCmd)
-		if err == nil {
-			// 地址已被使用，继续下一个
-			continue
-		}
-
-		// 检查地址是否可以ping通
-		pingCmd := fmt.Sprintf("ping6 -c1 -w1 -q %s", testIPv6)
-		_, err = i.sshClient.Execute(pingCmd)
-		if err == nil {
-			// 地址能ping通，说明已被占用
-			global.APP_LOG.Debug("IPv6地址已被占用", zap.String("ipv6", testIPv6))
-			continue
-		}
-
-		// 检查firewall或iptables规则
-		var checkRuleCmd string
-		if useFirewalld {
-			checkRuleCmd = fmt.Sprintf("firewall-cmd --direct --query-rule ipv6 nat PREROUTING 0 -d %s -j DNAT --to-destination %s", testIPv6, containerIPv6)
-		} else {
-			checkRuleCmd = fmt.Sprintf("ip6tables -t nat -C PREROUTING -d %s -j DNAT --to-destination %s 2>/dev/null", testIPv6, containerIPv6)
-		}
-		_, err = i.sshClient.Execute(checkRuleCmd)
-		if err == nil {
-			// 规则已存在
-			continue
-		}
-
-		// 找到可用地址
-		mappedIPv6 = testIPv6
-		global.APP_LOG.Info("找到可用IPv6地址", zap.String("ipv6", mappedIPv6))
-		break
-	}
-
-	if mappedIPv6 == "" {
-		return "", fmt.Errorf("无可用IPv6地址，不进行自动映射")
+	mappedIPv6, err := netfilter.FindFreeIPv6(i.sshClient, backend, subnetPrefix, interfaceName, containerIPv6)
+	if err != nil {
+		return "", err
 	}
 
 	// IPv6地址到接口
@@ -781,31 +725,9 @@ func (i *IncusProvider) setupIptablesIPv6(ctx context.Context, config IPv6Config
 		return "", fmt.Errorf("添加IPv6地址失败: %w", err)
 	}
 
-	// 防火墙/iptables规则
-	if useFirewalld {
-		// 启用firewalld
-		i.sshClient.Execute("systemctl enable --now firewalld")
-		time.Sleep(3 * time.Second)
-
-		// firewalld直接规则
-		natRuleCmd := fmt.Sprintf("firewall-cmd --permanent --direct --add-rule ipv6 nat PREROUTING 0 -d %s -j DNAT --to-destination %s", mappedIPv6, containerIPv6)
-		_, err = i.sshClient.Execute(natRuleCmd)
-		if err != nil {
-			return "", fmt.Errorf("添加firewalld NAT规则失败: %w", err)
-		}
-
-		// 重新加载firewalld
-		_, err = i.sshClient.Execute("firewall-cmd --reload")
-		if err != nil {
-			return "", fmt.Errorf("重新加载firewalld失败: %w", err)
-		}
-	} else {
-		// ip6tables NAT规则
-		natRuleCmd := fmt.Sprintf("ip6tables -t nat -A PREROUTING -d %s -j DNAT --to-destination %s", mappedIPv6, containerIPv6)
-		_, err = i.sshClient.Execute(natRuleCmd)
-		if err != nil {
-			return "", fmt.Errorf("添加ip6tables NAT规则失败: %w", err)
-		}
+	// 添加DNAT规则
+	if err := backend.AddIPv6DNAT(mappedIPv6, containerIPv6); err != nil {
+		return "", err
 	}
 
 	// 设置持久化服务和脚本
@@ -815,9 +737,10 @@ func (i *IncusProvider) setupIptablesIPv6(ctx context.Context, config IPv6Config
 	}
 
 	// 保存规则
-	err = i.saveNetfilterRules(ctx, useFirewalld)
-	if err != nil {
-		global.APP_LOG.Warn("保存防火墙规则失败", zap.Error(err))
+	if err := backend.Persist(); err != nil {
+		global.APP_LOG.Warn("保存防火墙规则失败",
+			zap.String("backend", backend.Name()),
+			zap.Error(err))
 	}
 
 	// 测试连通性
@@ -829,67 +752,6 @@ func (i *IncusProvider) setupIptablesIPv6(ctx context.Context, config IPv6Config
 	return mappedIPv6, nil
 }
 
-// detectOS 检测操作系统类型
-func (i *IncusProvider) detectOS(ctx context.Context) (string, error) {
-	cmd := "cat /etc/os-release | grep ^ID= | cut -d= -f2 | tr -d '\"'"
-	output, err := i.sshClient.Execute(cmd)
-	if err != nil {
-		return "", fmt.Errorf("检测操作系统失败: %w", err)
-	}
-
-	osType := strings.TrimSpace(output)
-	global.APP_LOG.Info("检测到操作系统类型", zap.String("os", osType))
-
-	// 标准化操作系统名称
-	switch osType {
-	case "ubuntu", "pop", "neon", "zorin":
-		return "ubuntu", nil
-	case "debian":
-		return "debian", nil
-	case "kali":
-		return "debian", nil
-	case "centos", "almalinux", "rocky":
-		return osType, nil
-	case "arch", "archarm", "endeavouros", "blendos", "garuda":
-		return "arch", nil
-	case "manjaro", "manjaro-arm":
-		return "manjaro", nil
-	default:
-		return osType, nil
-	}
-}
-
-// installNetfilterPackages 安装网络过滤相关包
-func (i *IncusProvider) installNetfilterPackages(ctx context.Context, osType string, useFirewalld bool) error {
-	switch osType {
-	case "ubuntu", "debian":
-		updateCmd := "apt update -y"
-		i.sshClient.Execute(updateCmd)
-		if !useFirewalld {
-			installCmd := "apt install -y netfilter-persistent iptables-persistent"
-			_, err := i.sshClient.Execute(installCmd)
-			return err
-		}
-	case "centos", "almalinux", "rocky":
-		if useFirewalld {
-			installCmd := "yum install -y firewalld"
-			_, err := i.sshClient.Execute(installCmd)
-			return err
-		} else {
-			installCmd := "yum install -y iptables-services"
-			_, err := i.sshClient.Execute(installCmd)
-			return err
-		}
-	case "arch", "manjaro":
-		if !useFirewalld {
-			installCmd := "pacman -S --noconfirm --needed iptables"
-			_, err := i.sshClient.Execute(installCmd)
-			return err
-		}
-	}
-	return nil
-}
-
 // setupPersistenceServiceIncus 设置持久化服务 (Incus版本)
 func (i *IncusProvider) setupPersistenceServiceIncus(ctx context.Context) error {
 	// 检查CDN可用性并下载脚本
@@ -915,12 +777,11 @@ func (i *IncusProvider) setupPersistenceServiceIncus(ctx context.Context) error
 
 	// 下载add-ipv6.sh脚本 (Incus版本)
 	scriptPath := "/usr/local/bin/add-ipv6.sh"
-	checkScriptCmd := fmt.Sprintf("[ -f %s ]", scriptPath)
-	_, err := i.sshClient.Execute(checkScriptCmd)
+	// 已存在的文件也要校验，之前从镜像下载到的错误页面会被重新下载覆盖
+	_, err := i.sshClient.Execute(provider.ValidDownloadCommand(scriptPath, provider.DownloadKindScript))
 	if err != nil {
-		scriptUrl := cdnSuccessUrl + "https://raw.githubusercontent.com/oneclickvirt/incus/main/scripts/add-ipv6.sh"
-		downloadCmd := fmt.Sprintf("wget '%s' -O %s", scriptUrl, scriptPath)
-		_, err := i.sshClient.Execute(downloadCmd)
+		scriptUrls := provider.MirrorURLs(cdnUrls, cdnSuccessUrl, "https://raw.githubusercontent.com/oneclickvirt/incus/main/scripts/add-ipv6.sh")
+		_, err := provider.DownloadFromMirrors(i.sshClient, scriptUrls, scriptPath, provider.DownloadKindScript)
 		if err != nil {
 			global.APP_LOG.Warn("下载add-ipv6.sh脚本失败", zap.Error(err))
 		} else {
@@ -930,12 +791,10 @@ func (i *IncusProvider) setupPersistenceServiceIncus(ctx context.Context) error
 
 	// 下载add-ipv6.service服务文件 (Incus版本)
 	servicePath := "/etc/systemd/system/add-ipv6.service"
-	checkServiceCmd := fmt.Sprintf("[ -f %s ]", servicePath)
-	_, err = i.sshClient.Execute(checkServiceCmd)
+	_, err = i.sshClient.Execute(provider.ValidDownloadCommand(servicePath, provider.DownloadKindSystemdUnit))
 	if err != nil {
-		serviceUrl := cdnSuccessUrl + "https://raw.githubusercontent.com/oneclickvirt/incus/main/scripts/add-ipv6.service"
-		downloadCmd := fmt.Sprintf("wget '%s' -O %s", serviceUrl, servicePath)
-		_, err := i.sshClient.Execute(downloadCmd)
+		serviceUrls := provider.MirrorURLs(cdnUrls, cdnSuccessUrl, "https://raw.githubusercontent.com/oneclickvirt/incus/main/scripts/add-ipv6.service")
+		_, err := provider.DownloadFromMirrors(i.sshClient, serviceUrls, servicePath, provider.DownloadKindSystemdUnit)
 		if err != nil {
 			global.APP_LOG.Warn("下载add-ipv6.service服务文件失败", zap.Error(err))
 		} else {
@@ -948,48 +807,68 @@ func (i *IncusProvider) setupPersistenceServiceIncus(ctx context.Context) error
 	return nil
 }
 
-// saveNetfilterRules 保存网络过滤规则
-func (i *IncusProvider) saveNetfilterRules(ctx context.Context, useFirewalld bool) error {
-	if useFirewalld {
-		// firewalld会自动持久化规则
-		_, err := i.sshClient.Execute("systemctl restart firewalld")
-		return err
-	} else {
-		// 保存iptables规则
-		i.sshClient.Execute("mkdir -p /etc/iptables")
-		_, err := i.sshClient.Execute("ip6tables-save > /etc/iptables/rules.v6")
-		if err != nil {
-			return fmt.Errorf("保存ip6tables规则失败: %w", err)
-		}
-
-		// 检查netfilter-persistent是否可用
-		_, err = i.sshClient.Execute("command -v netfilter-persistent")
-		if err == nil {
-			i.sshClient.Execute("netfilter-persistent save")
-			i.sshClient.Execute("netfilter-persistent reload")
-			i.sshClient.Execute("service netfilter-persistent restart")
-		}
-	}
-
-	return nil
-}
-
 // testIPv6Connectivity 测试IPv6连通性
+// 添加地址后NDP/路由传播需要时间，因此在判定失败前会多次重试
 func (i *IncusProvider) testIPv6Connectivity(ctx context.Context, ipv6Addr, containerName string) error {
+	const (
+		maxAttempts   = 5
+		retryInterval = 2 * time.Second
+	)
+
 	global.APP_LOG.Info("测试IPv6连通性", zap.String("ipv6", ipv6Addr))
 
 	testCmd := fmt.Sprintf("ping6 -c 3 %s", ipv6Addr)
-	_, err := i.sshClient.Execute(testCmd)
-	if err != nil {
-		global.APP_LOG.Error("IPv6映射失败",
+	var lastOutput string
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		output, err := i.sshClient.Execute(testCmd)
+		if err == nil {
+			global.APP_LOG.Info("IPv6映射成功",
+				zap.String("container", containerName),
+				zap.String("ipv6", ipv6Addr),
+				zap.Int("attempt", attempt))
+			return nil
+		}
+		lastOutput = strings.TrimSpace(output)
+
+		// 地址本身无效属于配置错误，重试没有意义
+		if isInvalidIPv6ConfigOutput(lastOutput) {
+			global.APP_LOG.Error("IPv6地址配置无效",
+				zap.String("container", containerName),
+				zap.String("ipv6", ipv6Addr),
+				zap.String("output", lastOutput))
+			return fmt.Errorf("IPv6配置无效: %s", lastOutput)
+		}
+
+		global.APP_LOG.Warn("IPv6地址暂不可达，等待NDP/路由传播后重试",
 			zap.String("container", containerName),
-			zap.String("ipv6", ipv6Addr))
-		return fmt.Errorf("映射失败")
+			zap.String("ipv6", ipv6Addr),
+			zap.Int("attempt", attempt),
+			zap.Int("maxAttempts", maxAttempts),
+			zap.String("output", lastOutput))
+
+		if attempt < maxAttempts {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(retryInterval):
+			}
+		}
 	}
 
-	global.APP_LOG.Info("IPv6映射成功",
+	global.APP_LOG.Error("IPv6映射失败",
 		zap.String("container", containerName),
-		zap.String("ipv6", ipv6Addr))
+		zap.String("ipv6", ipv6Addr),
+		zap.Int("attempts", maxAttempts))
+	return fmt.Errorf("映射失败: %d次尝试后IPv6地址仍不可达", maxAttempts)
+}
 
-	return nil
+// isInvalidIPv6ConfigOutput 判断ping6输出是否表示地址或配置本身无效
+func isInvalidIPv6ConfigOutput(output string) bool {
+	lower := strings.ToLower(output)
+	for _, keyword := range []string{"unknown host", "name or service not known", "bad address", "invalid argument", "address family for hostname not supported"} {
+		if strings.Contains(lower, keyword) {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,47 @@
+package incus
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"oneclickvirt/global"
+	"oneclickvirt/provider"
+
+	"go.uber.org/zap"
+)
+
+// UpdateInstanceLimits 调整实例的CPU和内存限制
+// 容器的cgroup限制立即生效；运行中的虚拟机配置会被保存，但需要重启后生效，此时返回 provider.ErrLimitsRequireRestart
+func (i *IncusProvider) UpdateInstanceLimits(ctx context.Context, instanceName string, cpu int, memoryMB int64) error {
+	if !i.connected || i.sshClient == nil {
+		return fmt.Errorf("not connected")
+	}
+	if err := provider.ValidateInstanceLimits(cpu, memoryMB); err != nil {
+		return err
+	}
+
+	instanceType, err := i.getInstanceType(instanceName)
+	if err != nil {
+		return fmt.Errorf("获取实例类型失败: %w", err)
+	}
+
+	cmd := fmt.Sprintf("incus config set %s limits.cpu=%d limits.memory=%dMiB", instanceName, cpu, memoryMB)
+	if output, err := i.sshClient.Execute(cmd); err != nil {
+		return fmt.Errorf("调整实例资源限制失败: %s: %w", strings.TrimSpace(output), err)
+	}
+
+	global.APP_LOG.Info("Incus实例资源限制已更新",
+		zap.String("instance", instanceName),
+		zap.String("type", instanceType),
+		zap.Int("cpu", cpu),
+		zap.Int64("memoryMB", memoryMB))
+
+	if instanceType == "virtual-machine" {
+		status, err := i.sshClient.Execute(fmt.Sprintf("incus info %s | grep \"Status:\" | awk '{print $2}'", instanceName))
+		if err != nil || strings.EqualFold(strings.TrimSpace(status), "running") {
+			return provider.ErrLimitsRequireRestart
+		}
+	}
+	return nil
+}
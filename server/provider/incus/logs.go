@@ -0,0 +1,30 @@
+package incus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"oneclickvirt/provider"
+	"oneclickvirt/utils"
+)
+
+// GetInstanceLogs 读取实例控制台日志的最后若干行
+// 控制台日志不带时间戳，不支持since过滤
+func (i *IncusProvider) GetInstanceLogs(ctx context.Context, instanceName string, lines int, since time.Time) (string, error) {
+	if !i.connected || i.sshClient == nil {
+		return "", fmt.Errorf("not connected")
+	}
+
+	cmd := fmt.Sprintf("incus console %s --show-log 2>&1", instanceName)
+	result, err := i.sshClient.ExecuteCapture(cmd, provider.InstanceLogTimeout, provider.InstanceLogMaxOutput)
+	if err != nil {
+		return "", err
+	}
+	output, err := provider.InstanceLogOutput(result)
+	if err != nil && !errors.Is(err, utils.ErrOutputTruncated) {
+		return "", err
+	}
+	return provider.TailLines(output, provider.ClampLogLines(lines)), err
+}
@@ -0,0 +1,61 @@
+package incus
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"oneclickvirt/global"
+	"oneclickvirt/provider"
+
+	"go.uber.org/zap"
+)
+
+// PrepareImportedInstance 清理从其他节点导入的实例上的proxy设备
+// 导入的proxy设备监听的是源节点的地址和端口，保留会导致实例在本节点无法启动；端口映射在迁移完成后按本节点的分配重新配置
+func (i *IncusProvider) PrepareImportedInstance(ctx context.Context, instanceName string) error {
+	if !i.connected || i.sshClient == nil {
+		return fmt.Errorf("not connected")
+	}
+	output, err := i.sshClient.Execute(fmt.Sprintf("incus config device list %s", instanceName))
+	if err != nil {
+		return fmt.Errorf("获取实例设备列表失败: %w", err)
+	}
+	for _, line := range strings.Split(output, "\n") {
+		device := strings.TrimSpace(line)
+		if !strings.HasPrefix(device, "proxy-") {
+			continue
+		}
+		if output, err := i.sshClient.Execute(fmt.Sprintf("incus config device remove %s %s", instanceName, device)); err != nil {
+			return fmt.Errorf("删除proxy设备 %s 失败: %s: %w", device, strings.TrimSpace(output), err)
+		}
+	}
+	return nil
+}
+
+// RebindPortMappings 按数据库中本节点分配的端口重新配置实例的端口映射，实例需处于运行状态
+func (i *IncusProvider) RebindPortMappings(ctx context.Context, instanceName string) error {
+	if !i.connected || i.sshClient == nil {
+		return fmt.Errorf("not connected")
+	}
+	networkConfig := i.parseNetworkConfigFromInstanceConfig(provider.InstanceConfig{Name: instanceName})
+
+	var instanceIP string
+	if networkConfig.NetworkType != "ipv6_only" {
+		ip, err := i.GetInstanceIPv4(ctx, instanceName)
+		if err != nil {
+			return fmt.Errorf("获取实例IP失败: %w", err)
+		}
+		instanceIP = ip
+	}
+
+	if err := i.configurePortMappingsWithIP(ctx, instanceName, networkConfig, instanceIP); err != nil {
+		return err
+	}
+	if err := i.configureFirewallPorts(instanceName); err != nil {
+		global.APP_LOG.Warn("配置防火墙端口失败",
+			zap.String("instance", instanceName),
+			zap.Error(err))
+	}
+	return nil
+}
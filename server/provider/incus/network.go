@@ -190,6 +190,14 @@ func (i *IncusProvider) parseNetworkConfigFromInstanceConfig(config provider.Ins
 		}
 	}
 
+	// 实例配置中显式指定的带宽优先级最高
+	if config.IngressMbps > 0 {
+		networkConfig.InSpeed = config.IngressMbps
+	}
+	if config.EgressMbps > 0 {
+		networkConfig.OutSpeed = config.EgressMbps
+	}
+
 	return networkConfig
 }
 
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"oneclickvirt/global"
+	"oneclickvirt/provider"
 	"oneclickvirt/utils"
 
 	"go.uber.org/zap"
@@ -13,6 +14,10 @@ import (
 
 // SetInstancePassword 设置实例密码
 func (i *IncusProvider) SetInstancePassword(ctx context.Context, instanceID, password string) error {
+	if err := provider.ValidateInstanceName(instanceID); err != nil {
+		return err
+	}
+
 	if !i.connected {
 		return fmt.Errorf("provider not connected")
 	}
@@ -46,6 +51,10 @@ func (i *IncusProvider) SetInstancePassword(ctx context.Context, instanceID, pas
 
 // ResetInstancePassword 重置实例密码
 func (i *IncusProvider) ResetInstancePassword(ctx context.Context, instanceID string) (string, error) {
+	if err := provider.ValidateInstanceName(instanceID); err != nil {
+		return "", err
+	}
+
 	if !i.connected {
 		return "", fmt.Errorf("provider not connected")
 	}
@@ -62,7 +71,7 @@ func (i *IncusProvider) ResetInstancePassword(ctx context.Context, instanceID st
 	return newPassword, nil
 }
 
-// generateRandomPassword 生成随机密码（仅包含数字和大小写英文字母，长度不低于8位）
+// generateRandomPassword 按配置的生成密码策略生成随机密码
 func (i *IncusProvider) generateRandomPassword() string {
 	return utils.GenerateInstancePassword()
 }
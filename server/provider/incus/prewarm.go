@@ -0,0 +1,30 @@
+package incus
+
+import (
+	"context"
+	"fmt"
+
+	"oneclickvirt/global"
+	"oneclickvirt/provider"
+
+	"go.uber.org/zap"
+)
+
+// PrewarmImages 预先下载并导入镜像，导入的别名与创建实例时使用的别名一致
+func (i *IncusProvider) PrewarmImages(ctx context.Context, images []provider.PrewarmImage) ([]provider.PrewarmResult, error) {
+	if !i.connected || i.sshClient == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+
+	if err := i.ensureSSHScriptsAvailable(i.config.Country); err != nil {
+		global.APP_LOG.Warn("确保SSH脚本可用失败，继续预热镜像", zap.Error(err))
+	}
+
+	return provider.PrewarmEach(ctx, images, func(image provider.PrewarmImage) error {
+		config := provider.InstanceConfig{
+			Image:        image.Name,
+			InstanceType: image.InstanceType,
+		}
+		return i.handleImageDownloadAndImport(ctx, &config)
+	}), nil
+}
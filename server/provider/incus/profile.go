@@ -0,0 +1,201 @@
+package incus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"oneclickvirt/global"
+	"oneclickvirt/provider"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+)
+
+// incusProfile incus profile list --format json 的输出结构
+type incusProfile struct {
+	Name        string                       `json:"name"`
+	Description string                       `json:"description"`
+	Config      map[string]string            `json:"config"`
+	Devices     map[string]map[string]string `json:"devices"`
+	UsedBy      []string                     `json:"used_by"`
+}
+
+// incusInstanceProfileState incus query /1.0/instances/<name> 输出中应用profile需要的字段
+type incusInstanceProfileState struct {
+	Status   string            `json:"status"`
+	Type     string            `json:"type"`
+	Profiles []string          `json:"profiles"`
+	Config   map[string]string `json:"config"`
+}
+
+// ListProfiles 列出Incus节点上的profile
+func (i *IncusProvider) ListProfiles(ctx context.Context) ([]provider.Profile, error) {
+	if !i.connected || i.sshClient == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+
+	output, err := i.sshClient.Execute("incus profile list --format json")
+	if err != nil {
+		return nil, fmt.Errorf("获取profile列表失败: %w", err)
+	}
+
+	var profiles []incusProfile
+	if err := json.Unmarshal([]byte(strings.TrimSpace(output)), &profiles); err != nil {
+		return nil, fmt.Errorf("解析profile列表失败: %w", err)
+	}
+
+	result := make([]provider.Profile, 0, len(profiles))
+	for _, p := range profiles {
+		result = append(result, provider.Profile{
+			Name:        p.Name,
+			Description: p.Description,
+			Config:      p.Config,
+			Devices:     p.Devices,
+			UsedBy:      len(p.UsedBy),
+		})
+	}
+	return result, nil
+}
+
+// findProfile 按名称查找profile，不存在时返回错误
+func (i *IncusProvider) findProfile(ctx context.Context, name string) (*provider.Profile, error) {
+	profiles, err := i.ListProfiles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for idx := range profiles {
+		if profiles[idx].Name == name {
+			return &profiles[idx], nil
+		}
+	}
+	return nil, fmt.Errorf("profile %s 不存在", name)
+}
+
+// CreateProfile 创建包含资源限制的profile，任一步骤失败时删除已创建的profile
+func (i *IncusProvider) CreateProfile(ctx context.Context, cfg provider.ProfileCreateConfig) error {
+	if !i.connected || i.sshClient == nil {
+		return fmt.Errorf("not connected")
+	}
+	items, err := provider.ProfileConfigItems(cfg)
+	if err != nil {
+		return err
+	}
+	if _, err := i.findProfile(ctx, cfg.Name); err == nil {
+		return fmt.Errorf("profile %s 已存在", cfg.Name)
+	}
+
+	pool := cfg.StoragePool
+	if cfg.DiskGB > 0 {
+		if pool == "" {
+			output, err := i.sshClient.Execute("incus profile device get default root pool")
+			if err != nil || strings.TrimSpace(output) == "" {
+				return fmt.Errorf("获取默认存储池失败，请指定存储池")
+			}
+			pool = strings.TrimSpace(output)
+		} else if err := i.validateStoragePool(ctx, pool); err != nil {
+			return err
+		}
+	}
+
+	if output, err := i.sshClient.Execute(fmt.Sprintf("incus profile create %s", cfg.Name)); err != nil {
+		return fmt.Errorf("创建profile失败: %s", utils.TruncateString(strings.TrimSpace(output), 200))
+	}
+
+	commands := make([]string, 0, 3)
+	if cfg.Description != "" {
+		commands = append(commands, fmt.Sprintf("incus profile set %s --property %s", cfg.Name, utils.ShellQuote("description="+cfg.Description)))
+	}
+	if len(items) > 0 {
+		commands = append(commands, fmt.Sprintf("incus profile set %s %s", cfg.Name, provider.ProfileSetArgs(items)))
+	}
+	if cfg.DiskGB > 0 {
+		commands = append(commands, fmt.Sprintf("incus profile device add %s root disk path=/ pool=%s size=%dGB", cfg.Name, pool, cfg.DiskGB))
+	}
+	for _, cmd := range commands {
+		if output, err := i.sshClient.Execute(cmd); err != nil {
+			if _, delErr := i.sshClient.Execute(fmt.Sprintf("incus profile delete %s", cfg.Name)); delErr != nil {
+				global.APP_LOG.Warn("清理创建失败的profile失败",
+					zap.String("profile", cfg.Name),
+					zap.Error(delErr))
+			}
+			return fmt.Errorf("配置profile失败: %s", utils.TruncateString(strings.TrimSpace(output), 200))
+		}
+	}
+
+	global.APP_LOG.Info("Incus profile创建成功",
+		zap.String("profile", cfg.Name),
+		zap.Any("config", items),
+		zap.Int("diskGB", cfg.DiskGB))
+	return nil
+}
+
+// ApplyProfile 为实例追加profile，并清除实例自身与profile重复的配置项，使profile中的限制生效
+// 运行中的虚拟机不能在线变更资源限制：allowRestart为false时返回 provider.ErrProfileRequiresStop，
+// 为true时先停止实例，应用后重新启动。实例级的root设备仍优先于profile中的root设备
+func (i *IncusProvider) ApplyProfile(ctx context.Context, instanceName, profileName string, allowRestart bool) (*provider.Profile, error) {
+	if !i.connected || i.sshClient == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	if err := provider.ValidateProfileName(profileName); err != nil {
+		return nil, err
+	}
+	profile, err := i.findProfile(ctx, profileName)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := i.sshClient.Execute(fmt.Sprintf("incus query /1.0/instances/%s", instanceName))
+	if err != nil {
+		return nil, fmt.Errorf("获取实例状态失败: %s", utils.TruncateString(strings.TrimSpace(output), 200))
+	}
+	var state incusInstanceProfileState
+	if err := json.Unmarshal([]byte(strings.TrimSpace(output)), &state); err != nil {
+		return nil, fmt.Errorf("解析实例状态失败: %w", err)
+	}
+
+	needStop := state.Type == "virtual-machine" && state.Status == "Running"
+	if needStop {
+		if !allowRestart {
+			return nil, provider.ErrProfileRequiresStop
+		}
+		if err := i.StopInstance(ctx, instanceName); err != nil {
+			return nil, fmt.Errorf("停止实例失败: %w", err)
+		}
+		defer func() {
+			if err := i.StartInstance(ctx, instanceName); err != nil {
+				global.APP_LOG.Error("应用profile后启动实例失败",
+					zap.String("instance", instanceName),
+					zap.Error(err))
+			}
+		}()
+	}
+
+	added := false
+	if !slices.Contains(state.Profiles, profileName) {
+		if output, err := i.sshClient.Execute(fmt.Sprintf("incus profile add %s %s", instanceName, profileName)); err != nil {
+			return nil, fmt.Errorf("应用profile失败: %s", utils.TruncateString(strings.TrimSpace(output), 200))
+		}
+		added = true
+	}
+
+	for key := range profile.Config {
+		if _, local := state.Config[key]; !local {
+			continue
+		}
+		if output, err := i.sshClient.Execute(fmt.Sprintf("incus config unset %s %s", instanceName, key)); err != nil {
+			if added {
+				i.sshClient.Execute(fmt.Sprintf("incus profile remove %s %s", instanceName, profileName))
+			}
+			return nil, fmt.Errorf("清除实例配置 %s 失败: %s", key, utils.TruncateString(strings.TrimSpace(output), 200))
+		}
+	}
+
+	global.APP_LOG.Info("Incus实例应用profile成功",
+		zap.String("instance", instanceName),
+		zap.String("profile", profileName),
+		zap.Bool("restarted", needStop))
+	return profile, nil
+}
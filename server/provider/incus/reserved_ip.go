@@ -0,0 +1,127 @@
+package incus
+
+import (
+	"fmt"
+	"strings"
+
+	"oneclickvirt/global"
+	"oneclickvirt/provider"
+
+	"go.uber.org/zap"
+)
+
+// defaultBridgeNetwork 默认profile中未能读取到eth0所属网络时使用的网桥名称
+const defaultBridgeNetwork = "incusbr0"
+
+// resolveBridgeNetwork 获取默认profile中eth0所连接的网络名称
+func (i *IncusProvider) resolveBridgeNetwork() string {
+	for _, key := range []string{"network", "parent"} {
+		output, err := i.sshClient.Execute(fmt.Sprintf("incus profile device get default eth0 %s 2>/dev/null", key))
+		if err == nil && strings.TrimSpace(output) != "" {
+			return strings.TrimSpace(output)
+		}
+	}
+	return defaultBridgeNetwork
+}
+
+// validateRequestedIPs 校验指定的内网IP是否位于节点网桥子网内
+// 返回eth0所连接的网络名称，供后续设置设备配置使用
+func (i *IncusProvider) validateRequestedIPs(config provider.InstanceConfig) (string, error) {
+	if config.RequestedIPv4 == "" && config.RequestedIPv6 == "" {
+		return "", nil
+	}
+
+	network := i.resolveBridgeNetwork()
+	checks := []struct {
+		requested string
+		key       string
+	}{
+		{config.RequestedIPv4, "ipv4.address"},
+		{config.RequestedIPv6, "ipv6.address"},
+	}
+	for _, check := range checks {
+		if check.requested == "" {
+			continue
+		}
+		output, err := i.sshClient.Execute(fmt.Sprintf("incus network get %s %s", network, check.key))
+		if err != nil {
+			return "", fmt.Errorf("获取网络 %s 的 %s 配置失败: %w", network, check.key, err)
+		}
+		subnet := strings.TrimSpace(output)
+		if subnet == "" || subnet == "none" || subnet == "auto" {
+			return "", fmt.Errorf("网络 %s 未配置静态 %s 子网，无法指定IP %s", network, check.key, check.requested)
+		}
+		if err := provider.ValidateRequestedIP(check.requested, subnet); err != nil {
+			return "", err
+		}
+	}
+
+	instances, err := i.sshListInstances()
+	if err != nil {
+		return "", fmt.Errorf("检查IP地址占用失败: %w", err)
+	}
+	for _, inst := range instances {
+		if config.RequestedIPv4 != "" && inst.PrivateIP == config.RequestedIPv4 {
+			return "", fmt.Errorf("IP地址 %s 已被实例 %s 使用", config.RequestedIPv4, inst.Name)
+		}
+		if config.RequestedIPv6 != "" && inst.IPv6Address == config.RequestedIPv6 {
+			return "", fmt.Errorf("IP地址 %s 已被实例 %s 使用", config.RequestedIPv6, inst.Name)
+		}
+	}
+
+	return network, nil
+}
+
+// requestedIPDeviceParams 生成eth0设备的静态地址配置项
+func requestedIPDeviceParams(config provider.InstanceConfig) []string {
+	var params []string
+	if config.RequestedIPv4 != "" {
+		params = append(params, fmt.Sprintf("ipv4.address=%s", config.RequestedIPv4))
+	}
+	if config.RequestedIPv6 != "" {
+		params = append(params, fmt.Sprintf("ipv6.address=%s", config.RequestedIPv6))
+	}
+	return params
+}
+
+// applyRequestedIPs 在实例首次启动前为eth0设置静态地址，失败时删除已创建的实例
+func (i *IncusProvider) applyRequestedIPs(config provider.InstanceConfig) error {
+	params := requestedIPDeviceParams(config)
+	if len(params) == 0 {
+		return nil
+	}
+
+	cmd := fmt.Sprintf("incus config device override %s eth0 %s", config.Name, strings.Join(params, " "))
+	if _, err := i.sshClient.Execute(cmd); err != nil {
+		global.APP_LOG.Error("设置指定IP失败，删除已创建的实例",
+			zap.String("instance", config.Name),
+			zap.String("ipv4", config.RequestedIPv4),
+			zap.String("ipv6", config.RequestedIPv6),
+			zap.Error(err))
+		i.sshClient.Execute(fmt.Sprintf("incus delete %s --force", config.Name))
+		return fmt.Errorf("无法为实例设置指定IP: %w", err)
+	}
+
+	global.APP_LOG.Info("已为实例设置指定IP",
+		zap.String("instance", config.Name),
+		zap.String("ipv4", config.RequestedIPv4),
+		zap.String("ipv6", config.RequestedIPv6))
+	return nil
+}
+
+// requestedIPDevice 生成API创建时覆盖默认profile的eth0设备
+func requestedIPDevice(network string, config provider.InstanceConfig) map[string]interface{} {
+	device := map[string]interface{}{
+		"type":    "nic",
+		"nictype": "bridged",
+		"parent":  network,
+		"name":    "eth0",
+	}
+	if config.RequestedIPv4 != "" {
+		device["ipv4.address"] = config.RequestedIPv4
+	}
+	if config.RequestedIPv6 != "" {
+		device["ipv6.address"] = config.RequestedIPv6
+	}
+	return device
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -220,6 +221,29 @@ func (i *IncusProvider) sshCreateInstanceWithProgress(ctx context.Context, confi
 	if err := i.validateInstanceConfig(config); err != nil {
 		return fmt.Errorf("实例配置验证失败: %w", err)
 	}
+	if _, err := i.validateRequestedIPs(config); err != nil {
+		return fmt.Errorf("指定IP校验失败: %w", err)
+	}
+	extraNICs, err := i.prepareExtraNICs(config)
+	if err != nil {
+		return fmt.Errorf("额外网卡校验失败: %w", err)
+	}
+	volumes, err := i.prepareVolumes(config)
+	if err != nil {
+		return fmt.Errorf("挂载目录校验失败: %w", err)
+	}
+	if err := i.validateGPUs(ctx, &config); err != nil {
+		return fmt.Errorf("直通GPU校验失败: %w", err)
+	}
+	if err := i.resolveCPULimit(&config); err != nil {
+		return fmt.Errorf("CPU限制校验失败: %w", err)
+	}
+	if config.DNSServers, err = provider.ValidateDNSServers(config.DNSServers); err != nil {
+		return fmt.Errorf("DNS服务器校验失败: %w", err)
+	}
+	if config.Hostname, err = provider.ValidateHostname(config.Hostname); err != nil {
+		return fmt.Errorf("主机名校验失败: %w", err)
+	}
 
 	// 如果是虚拟机，先检查VM支持
 	if config.InstanceType == "vm" {
@@ -258,6 +282,19 @@ func (i *IncusProvider) sshCreateInstanceWithProgress(ctx context.Context, confi
 		return fmt.Errorf("执行创建命令失败: %w", err)
 	}
 
+	if err := i.applyRequestedIPs(config); err != nil {
+		return err
+	}
+	if err := i.attachExtraNICs(config.Name, extraNICs); err != nil {
+		return err
+	}
+	if err := i.attachGPUs(config.Name, config.GPUDevices); err != nil {
+		return err
+	}
+	if err := i.attachVolumes(config.Name, volumes); err != nil {
+		return err
+	}
+
 	// 如果是虚拟机，需要额外的配置
 	if config.InstanceType == "vm" {
 		updateProgress(40, "配置虚拟机设置...")
@@ -424,6 +461,8 @@ func (i *IncusProvider) sshCreateInstanceWithProgress(ctx context.Context, confi
 		// SSH密码设置失败也不应该阻止实例创建，记录错误即可
 		global.APP_LOG.Warn("配置SSH密码失败", zap.Error(err))
 	}
+	i.configureInstanceDNS(config)
+	i.configureInstanceHostname(config)
 	updateProgress(100, "Incus实例创建完成")
 	instanceTypeText := "容器"
 	if config.InstanceType == "vm" {
@@ -439,15 +478,19 @@ func (i *IncusProvider) sshCreateInstanceWithProgress(ctx context.Context, confi
 func (i *IncusProvider) configureInstanceLimits(ctx context.Context, config provider.InstanceConfig) error {
 	var errors []string
 
-	// 配置CPU优先级
+	// 配置CPU核心数/核心绑定及优先级
 	if config.CPU != "" {
+		if err := i.setInstanceConfig(ctx, config.Name, "limits.cpu", config.CPU); err != nil {
+			errors = append(errors, fmt.Sprintf("设置CPU核心限制失败: %v", err))
+		}
 		if err := i.setInstanceConfig(ctx, config.Name, "limits.cpu.priority", "0"); err != nil {
 			errors = append(errors, fmt.Sprintf("设置CPU优先级失败: %v", err))
 		}
 	}
 
 	// 配置内存交换
-	if err := i.setInstanceConfig(ctx, config.Name, "limits.memory.swap", "true"); err != nil {
+	swapEnabled := config.InstanceType == "vm" || provider.LXCMemorySwapEnabled(config)
+	if err := i.setInstanceConfig(ctx, config.Name, "limits.memory.swap", strconv.FormatBool(swapEnabled)); err != nil {
 		errors = append(errors, fmt.Sprintf("设置内存交换失败: %v", err))
 	}
 
@@ -469,20 +512,12 @@ func (i *IncusProvider) configureInstanceLimits(ctx context.Context, config prov
 			}
 		}
 
-		// 配置CPU限制
-		cpuConfigs := []struct {
-			key   string
-			value string
-		}{
-			{"limits.cpu.allowance", "50%"},
-			{"limits.cpu.allowance", "25ms/100ms"},
-		}
-
-		for _, cpuConfig := range cpuConfigs {
-			if err := i.setInstanceConfig(ctx, config.Name, cpuConfig.key, cpuConfig.value); err != nil {
+		// 配置CPU限额，按实例核心数和节点配置的限额方式生成
+		if allowance := provider.ContainerCPUAllowance(config, i.config.ContainerCPUAllowanceMode); allowance != "" {
+			if err := i.setInstanceConfig(ctx, config.Name, "limits.cpu.allowance", allowance); err != nil {
 				global.APP_LOG.Debug("CPU限制配置失败，继续执行",
-					zap.String("key", cpuConfig.key),
-					zap.String("value", cpuConfig.value),
+					zap.String("key", "limits.cpu.allowance"),
+					zap.String("value", allowance),
 					zap.Error(err))
 			}
 		}
@@ -627,7 +662,7 @@ func (i *IncusProvider) sshDeleteInstance(id string) error {
 		zap.String("host", utils.TruncateString(i.config.Host, 32)),
 		zap.String("instance_id", id))
 
-	output, err := i.sshClient.Execute(fmt.Sprintf("incus delete %s --force", id))
+	output, err := i.sshClient.Execute(i.deleteInstanceCommand(id))
 	if err != nil {
 		// 检查是否是实例不存在的错误
 		if strings.Contains(output, "Instance not found") || strings.Contains(output, "not found") {
@@ -726,12 +761,16 @@ func (i *IncusProvider) configureInstanceSecurity(ctx context.Context, config pr
 		}
 
 		// 内存交换配置
-		if err := i.setInstanceConfig(ctx, config.Name, "limits.memory.swap", "true"); err != nil {
-			global.APP_LOG.Warn("设置内存交换失败", zap.Error(err))
-		}
+		if provider.LXCMemorySwapEnabled(config) {
+			if err := i.setInstanceConfig(ctx, config.Name, "limits.memory.swap", "true"); err != nil {
+				global.APP_LOG.Warn("设置内存交换失败", zap.Error(err))
+			}
 
-		if err := i.setInstanceConfig(ctx, config.Name, "limits.memory.swap.priority", "1"); err != nil {
-			global.APP_LOG.Warn("设置内存交换优先级失败", zap.Error(err))
+			if err := i.setInstanceConfig(ctx, config.Name, "limits.memory.swap.priority", "1"); err != nil {
+				global.APP_LOG.Warn("设置内存交换优先级失败", zap.Error(err))
+			}
+		} else if err := i.setInstanceConfig(ctx, config.Name, "limits.memory.swap", "false"); err != nil {
+			global.APP_LOG.Warn("设置内存交换失败", zap.Error(err))
 		}
 	}
 
@@ -0,0 +1,66 @@
+package incus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"oneclickvirt/provider"
+)
+
+// incusStoragePool incus storage list --format json 的输出结构
+type incusStoragePool struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Driver      string   `json:"driver"`
+	Status      string   `json:"status"`
+	UsedBy      []string `json:"used_by"`
+}
+
+// ListStoragePools 列出Incus存储池
+func (i *IncusProvider) ListStoragePools(ctx context.Context) ([]provider.StoragePool, error) {
+	if !i.connected || i.sshClient == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+
+	output, err := i.sshClient.Execute("incus storage list --format json")
+	if err != nil {
+		return nil, fmt.Errorf("获取存储池列表失败: %w", err)
+	}
+
+	var pools []incusStoragePool
+	if err := json.Unmarshal([]byte(strings.TrimSpace(output)), &pools); err != nil {
+		return nil, fmt.Errorf("解析存储池列表失败: %w", err)
+	}
+
+	result := make([]provider.StoragePool, 0, len(pools))
+	for _, pool := range pools {
+		result = append(result, provider.StoragePool{
+			Name:        pool.Name,
+			Driver:      pool.Driver,
+			Status:      pool.Status,
+			Description: pool.Description,
+			UsedBy:      len(pool.UsedBy),
+		})
+	}
+	return result, nil
+}
+
+// validateStoragePool 创建实例前校验指定的存储池是否存在，未指定时使用默认存储池
+func (i *IncusProvider) validateStoragePool(ctx context.Context, pool string) error {
+	if pool == "" {
+		return nil
+	}
+
+	pools, err := i.ListStoragePools(ctx)
+	if err != nil {
+		return err
+	}
+	for _, p := range pools {
+		if p.Name == pool {
+			return nil
+		}
+	}
+	return fmt.Errorf("存储池 %s 不存在", pool)
+}
@@ -0,0 +1,58 @@
+package incus
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"oneclickvirt/global"
+
+	"go.uber.org/zap"
+)
+
+// tagConfigPrefix 实例标签在Incus中以user.*配置项保存
+const tagConfigPrefix = "user.tag."
+
+// tagConfigParams 将实例标签转换为创建命令的-c参数，按键排序保证命令稳定
+// 标签值已在服务层校验，不包含单引号
+func tagConfigParams(labels map[string]string) []string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	params := make([]string, 0, len(keys))
+	for _, k := range keys {
+		params = append(params, fmt.Sprintf("'%s%s=%s'", tagConfigPrefix, k, labels[k]))
+	}
+	return params
+}
+
+// SetInstanceTags 同步实例标签到Incus配置
+// 数据库中的标签为准，这里仅做尽力同步
+func (i *IncusProvider) SetInstanceTags(ctx context.Context, instanceName string, set map[string]string, unset []string) error {
+	if !i.connected || i.sshClient == nil {
+		return fmt.Errorf("not connected")
+	}
+
+	for _, k := range unset {
+		cmd := fmt.Sprintf("incus config unset %s %s%s", instanceName, tagConfigPrefix, k)
+		if _, err := i.sshClient.Execute(cmd); err != nil {
+			return fmt.Errorf("删除实例标签 %s 失败: %w", k, err)
+		}
+	}
+
+	for k, v := range set {
+		cmd := fmt.Sprintf("incus config set %s %s%s '%s'", instanceName, tagConfigPrefix, k, v)
+		if _, err := i.sshClient.Execute(cmd); err != nil {
+			return fmt.Errorf("设置实例标签 %s 失败: %w", k, err)
+		}
+	}
+
+	global.APP_LOG.Debug("Incus实例标签同步完成",
+		zap.String("instance", instanceName),
+		zap.Int("set", len(set)),
+		zap.Int("unset", len(unset)))
+	return nil
+}
@@ -0,0 +1,49 @@
+package incus
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"oneclickvirt/provider"
+)
+
+// GetInstanceUsage 获取实例实时CPU和磁盘使用率
+// 虚拟机需要安装incus-agent才能执行采样
+func (i *IncusProvider) GetInstanceUsage(ctx context.Context, instanceName string) (*provider.InstanceUsage, error) {
+	if !i.connected || i.sshClient == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+
+	cmd := fmt.Sprintf("incus exec %s -- sh -c '%s'", instanceName, provider.UsageSampleScript)
+	output, err := i.sshClient.Execute(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("采集实例资源使用情况失败: %s: %w", strings.TrimSpace(output), err)
+	}
+	return provider.ParseUsageSample(output)
+}
+
+// GetInstanceDiskUsage 获取实例根文件系统使用量
+// 已用空间取自实例内df；根磁盘设置了size时以其作为总量，dir等不支持配额的存储上df总量为宿主机分区大小
+func (i *IncusProvider) GetInstanceDiskUsage(ctx context.Context, instanceName string) (*provider.DiskUsage, error) {
+	if !i.connected || i.sshClient == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+
+	output, err := i.sshClient.Execute(fmt.Sprintf("incus exec %s -- sh -c '%s'", instanceName, provider.DiskUsageScript))
+	if err != nil {
+		return nil, fmt.Errorf("获取实例磁盘使用量失败: %s: %w", strings.TrimSpace(output), err)
+	}
+	usage, err := provider.ParseDiskUsage(output)
+	if err != nil {
+		return nil, err
+	}
+
+	// root设备继承自profile时该命令失败，保留df总量
+	if sizeOutput, err := i.sshClient.Execute(fmt.Sprintf("incus config device get %s root size", instanceName)); err == nil {
+		if size, err := provider.ParseByteSize(sizeOutput); err == nil && size > 0 && (usage.TotalBytes == 0 || size < usage.TotalBytes) {
+			usage.TotalBytes = size
+		}
+	}
+	return usage, nil
+}
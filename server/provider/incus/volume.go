@@ -0,0 +1,68 @@
+package incus
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"oneclickvirt/global"
+	"oneclickvirt/provider"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+)
+
+// prepareVolumes 校验挂载配置，并在宿主机上确认挂载源存在且位于允许列表内
+func (i *IncusProvider) prepareVolumes(config provider.InstanceConfig) ([]provider.VolumeMount, error) {
+	if len(config.Volumes) == 0 {
+		return nil, nil
+	}
+	if i.sshClient == nil {
+		return nil, fmt.Errorf("挂载宿主机目录需要SSH连接")
+	}
+	return provider.ResolveVolumeSources(i.sshClient.Execute, config.Volumes, i.config.VolumeAllowlist)
+}
+
+// volumeDevices 生成挂载对应的disk设备，供API创建请求使用
+func volumeDevices(volumes []provider.VolumeMount) map[string]map[string]string {
+	devices := make(map[string]map[string]string, len(volumes))
+	for idx, volume := range volumes {
+		devices[provider.VolumeDeviceName(idx)] = provider.LXCVolumeDeviceConfig(volume)
+	}
+	return devices
+}
+
+// attachVolumes 在实例首次启动前添加挂载宿主机目录的disk设备，失败时删除已创建的实例
+func (i *IncusProvider) attachVolumes(instanceName string, volumes []provider.VolumeMount) error {
+	for name, device := range volumeDevices(volumes) {
+		keys := make([]string, 0, len(device))
+		for key := range device {
+			if key != "type" {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		params := make([]string, 0, len(keys))
+		for _, key := range keys {
+			params = append(params, fmt.Sprintf("%s=%s", key, utils.ShellQuote(device[key])))
+		}
+
+		cmd := fmt.Sprintf("incus config device add %s %s disk %s", instanceName, name, strings.Join(params, " "))
+		if output, err := i.sshClient.Execute(cmd); err != nil {
+			global.APP_LOG.Error("挂载宿主机目录失败，删除已创建的实例",
+				zap.String("instance", instanceName),
+				zap.String("device", name),
+				zap.String("output", utils.TruncateString(output, 200)),
+				zap.Error(err))
+			i.sshClient.Execute(fmt.Sprintf("incus delete %s --force", instanceName))
+			return fmt.Errorf("挂载宿主机目录 %s 失败: %w", device["source"], err)
+		}
+	}
+
+	if len(volumes) > 0 {
+		global.APP_LOG.Info("已为实例挂载宿主机目录",
+			zap.String("instance", instanceName),
+			zap.Int("count", len(volumes)))
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrLimitsRequireRestart 新的CPU和内存限制已写入实例配置，但需要重启实例后才能生效
+var ErrLimitsRequireRestart = errors.New("资源限制已保存，需要重启实例后生效")
+
+// minInstanceMemoryMB 调整后实例内存的下限，与预定义的最小内存规格一致
+const minInstanceMemoryMB = 64
+
+// ValidateInstanceLimits 校验调整后的CPU核心数和内存大小（MB）
+func ValidateInstanceLimits(cpu int, memoryMB int64) error {
+	if cpu < 1 {
+		return fmt.Errorf("无效的CPU核心数: %d", cpu)
+	}
+	if memoryMB < minInstanceMemoryMB {
+		return fmt.Errorf("内存不能小于%dMB，当前值: %dMB", minInstanceMemoryMB, memoryMB)
+	}
+	return nil
+}
+
+// DockerMemorySwapForUpdate 计算 docker update 调整内存时的 --memory-swap 参数，保持原有swap大小不变
+// currentMemory、currentSwap 为 docker inspect 中 HostConfig 的字节数；
+// currentSwap 为-1表示swap不受限，为0表示未设置内存限制，此时返回空字符串表示不传该参数
+func DockerMemorySwapForUpdate(currentMemory, currentSwap, newMemoryMB int64) string {
+	if currentSwap < 0 {
+		return "-1"
+	}
+	if currentSwap == 0 || currentSwap < currentMemory {
+		return ""
+	}
+	return fmt.Sprintf("%db", newMemoryMB*1024*1024+currentSwap-currentMemory)
+}
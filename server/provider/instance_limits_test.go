@@ -0,0 +1,31 @@
+package provider
+
+import "testing"
+
+// TestValidateInstanceLimits 测试调整后的CPU和内存下限
+func TestValidateInstanceLimits(t *testing.T) {
+	if err := ValidateInstanceLimits(2, 1024); err != nil {
+		t.Fatalf("合法的资源限制不应报错: %v", err)
+	}
+	if err := ValidateInstanceLimits(0, 1024); err == nil {
+		t.Fatal("CPU核心数为0时应报错")
+	}
+	if err := ValidateInstanceLimits(1, 32); err == nil {
+		t.Fatal("内存低于下限时应报错")
+	}
+}
+
+// TestDockerMemorySwapForUpdate 测试调整内存时保持原有swap大小
+func TestDockerMemorySwapForUpdate(t *testing.T) {
+	const mb = 1024 * 1024
+	// 512MB内存 + 128MB swap，调整到1024MB后swap仍为128MB
+	if got := DockerMemorySwapForUpdate(512*mb, 640*mb, 1024); got != "1207959552b" {
+		t.Fatalf("swap参数错误: %s", got)
+	}
+	if got := DockerMemorySwapForUpdate(512*mb, -1, 1024); got != "-1" {
+		t.Fatalf("不受限的swap应保持-1，实际 %s", got)
+	}
+	if got := DockerMemorySwapForUpdate(0, 0, 1024); got != "" {
+		t.Fatalf("未设置内存限制时不应传swap参数，实际 %s", got)
+	}
+}
@@ -0,0 +1,31 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// MaxInstanceNameLength 实例名称长度上限
+const MaxInstanceNameLength = 128
+
+// 实例名称会直接拼接到SSH执行的命令中，只允许字母、数字、下划线、点和连字符，且必须以字母或数字开头（避免被解析为命令参数）
+var instanceNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// ErrUnsafeInstanceName 实例名称包含shell元字符等不安全字符
+var ErrUnsafeInstanceName = errors.New("实例名称包含不安全的字符")
+
+// ValidateInstanceName 校验实例名称，拒绝包含空白、引号、分号、管道、$、反引号等shell元字符的名称
+// 在服务层创建实例前以及Provider构建远程命令前调用
+func ValidateInstanceName(name string) error {
+	if name == "" {
+		return fmt.Errorf("实例名称不能为空")
+	}
+	if len(name) > MaxInstanceNameLength {
+		return fmt.Errorf("实例名称长度不能超过%d个字符", MaxInstanceNameLength)
+	}
+	if !instanceNamePattern.MatchString(name) {
+		return fmt.Errorf("%w: %q，只能包含字母、数字、下划线、点和连字符，且必须以字母或数字开头", ErrUnsafeInstanceName, name)
+	}
+	return nil
+}
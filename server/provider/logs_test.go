@@ -0,0 +1,49 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestClampLogLines 测试日志行数的默认值和上限
+func TestClampLogLines(t *testing.T) {
+	cases := map[int]int{
+		0:    DefaultInstanceLogLines,
+		-5:   DefaultInstanceLogLines,
+		50:   50,
+		5000: MaxInstanceLogLines,
+	}
+	for input, expected := range cases {
+		if got := ClampLogLines(input); got != expected {
+			t.Fatalf("ClampLogLines(%d) = %d，期望 %d", input, got, expected)
+		}
+	}
+}
+
+// TestTailLines 测试截取输出的最后若干行
+func TestTailLines(t *testing.T) {
+	output := "a\nb\nc\nd\n"
+	if got := TailLines(output, 2); got != "c\nd\n" {
+		t.Fatalf("截取结果错误: %q", got)
+	}
+	if got := TailLines(output, 10); got != output {
+		t.Fatalf("行数不足时应返回全部输出: %q", got)
+	}
+	if got := TailLines("\n\n", 3); got != "" {
+		t.Fatalf("空输出应返回空字符串: %q", got)
+	}
+}
+
+// TestJournalLogCommand 测试日志命令的行数和since参数
+func TestJournalLogCommand(t *testing.T) {
+	cmd := JournalLogCommand(100, time.Time{})
+	if !strings.Contains(cmd, "journalctl --no-pager -n 100") || strings.Contains(cmd, "--since") {
+		t.Fatalf("命令错误: %s", cmd)
+	}
+
+	cmd = JournalLogCommand(100, time.Unix(1700000000, 0))
+	if !strings.Contains(cmd, "--since @1700000000") {
+		t.Fatalf("缺少since参数: %s", cmd)
+	}
+}
@@ -0,0 +1,65 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// LXCFSRoot LXCFS在宿主机上的挂载根目录
+const LXCFSRoot = "/var/lib/lxcfs"
+
+// maxLXCFSMounts 单个Provider允许配置的LXCFS挂载文件数量上限
+const maxLXCFSMounts = 32
+
+// DefaultLXCFSMounts 未配置时默认尝试挂载的LXCFS文件，与早期版本的固定列表保持一致
+var DefaultLXCFSMounts = []string{
+	"/proc/cpuinfo",
+	"/proc/diskstats",
+	"/proc/meminfo",
+	"/proc/stat",
+	"/proc/swaps",
+	"/proc/uptime",
+}
+
+var lxcfsPathPattern = regexp.MustCompile(`^/(proc|sys)(/[A-Za-z0-9_.\-]+)+$`)
+
+// ParseLXCFSMounts 解析以逗号或换行分隔的LXCFS挂载列表，返回容器内路径
+// 每项为容器内的 /proc 或 /sys 路径（可省略开头的"/"），如 "/proc/slabinfo"、"sys/devices/system/cpu/online"，
+// 对应宿主机上 LXCFSRoot 下的同名文件。为空时返回默认列表
+func ParseLXCFSMounts(value string) ([]string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return append([]string(nil), DefaultLXCFSMounts...), nil
+	}
+
+	seen := make(map[string]bool)
+	var mounts []string
+	for _, item := range strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == '\n' || r == '\r'
+	}) {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if !strings.HasPrefix(item, "/") {
+			item = "/" + item
+		}
+		if !lxcfsPathPattern.MatchString(item) || strings.Contains(item, "/..") || strings.Contains(item, "/./") {
+			return nil, fmt.Errorf("无效的LXCFS挂载路径: %s，仅支持 /proc 或 /sys 下的文件", item)
+		}
+		if seen[item] {
+			continue
+		}
+		seen[item] = true
+		mounts = append(mounts, item)
+	}
+
+	if len(mounts) == 0 {
+		return append([]string(nil), DefaultLXCFSMounts...), nil
+	}
+	if len(mounts) > maxLXCFSMounts {
+		return nil, fmt.Errorf("LXCFS挂载路径不能超过%d个", maxLXCFSMounts)
+	}
+	return mounts, nil
+}
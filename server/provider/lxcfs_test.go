@@ -0,0 +1,44 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestParseLXCFSMounts 测试LXCFS挂载列表的默认值、规范化与路径校验
+func TestParseLXCFSMounts(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		expected    string
+		expectError bool
+	}{
+		{name: "空值使用默认列表", value: "", expected: strings.Join(DefaultLXCFSMounts, ",")},
+		{name: "仅分隔符使用默认列表", value: " , ", expected: strings.Join(DefaultLXCFSMounts, ",")},
+		{name: "自定义列表", value: "/proc/meminfo,/proc/slabinfo", expected: "/proc/meminfo,/proc/slabinfo"},
+		{name: "补全开头斜杠", value: "sys/devices/system/cpu/online", expected: "/sys/devices/system/cpu/online"},
+		{name: "换行分隔并去重", value: "/proc/stat\n/proc/stat\n/proc/uptime", expected: "/proc/stat,/proc/uptime"},
+		{name: "非proc或sys路径", value: "/etc/passwd", expectError: true},
+		{name: "路径穿越", value: "/proc/../etc/shadow", expectError: true},
+		{name: "包含shell字符", value: "/proc/meminfo;reboot", expectError: true},
+		{name: "仅目录前缀", value: "/proc", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLXCFSMounts(tt.value)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("ParseLXCFSMounts(%q) 期望返回错误，实际返回 %v", tt.value, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseLXCFSMounts(%q) 返回错误: %v", tt.value, err)
+			}
+			if joined := strings.Join(got, ","); joined != tt.expected {
+				t.Errorf("ParseLXCFSMounts(%q) = %q, 期望 %q", tt.value, joined, tt.expected)
+			}
+		})
+	}
+}
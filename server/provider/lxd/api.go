@@ -68,6 +68,28 @@ func (l *LXDProvider) apiCreateInstanceWithProgress(ctx context.Context, config
 
 	updateProgress(10, "开始LXD API创建实例...")
 
+	bridgeNetwork, err := l.validateRequestedIPs(ctx, config)
+	if err != nil {
+		return fmt.Errorf("指定IP校验失败: %w", err)
+	}
+	extraNICs, err := l.prepareExtraNICs(config)
+	if err != nil {
+		return fmt.Errorf("额外网卡校验失败: %w", err)
+	}
+	volumes, err := l.prepareVolumes(config)
+	if err != nil {
+		return fmt.Errorf("挂载目录校验失败: %w", err)
+	}
+	if err := l.resolveCPULimit(&config); err != nil {
+		return fmt.Errorf("CPU限制校验失败: %w", err)
+	}
+	if config.DNSServers, err = provider.ValidateDNSServers(config.DNSServers); err != nil {
+		return fmt.Errorf("DNS服务器校验失败: %w", err)
+	}
+	if config.Hostname, err = provider.ValidateHostname(config.Hostname); err != nil {
+		return fmt.Errorf("主机名校验失败: %w", err)
+	}
+
 	// 在API创建之前，处理镜像下载和导入
 	updateProgress(30, "处理镜像下载和导入...")
 	if err := l.handleImageDownloadAndImport(ctx, &config); err != nil {
@@ -102,13 +124,36 @@ func (l *LXDProvider) apiCreateInstanceWithProgress(ctx context.Context, config
 	if config.Memory != "" {
 		instanceConfig["config"].(map[string]interface{})["limits.memory"] = config.Memory
 	}
-	if config.Disk != "" {
-		instanceConfig["devices"].(map[string]interface{})["root"] = map[string]interface{}{
+	for k, v := range config.Labels {
+		instanceConfig["config"].(map[string]interface{})[tagConfigPrefix+k] = v
+	}
+	if config.Disk != "" || config.StoragePool != "" {
+		storagePool := config.StoragePool
+		if storagePool == "" {
+			storagePool = "default"
+		}
+		rootDevice := map[string]interface{}{
 			"type": "disk",
 			"path": "/",
-			"pool": "default",
-			"size": config.Disk,
+			"pool": storagePool,
 		}
+		if config.Disk != "" {
+			rootDevice["size"] = config.Disk
+		}
+		instanceConfig["devices"].(map[string]interface{})["root"] = rootDevice
+	}
+	if config.RequestedIPv4 != "" || config.RequestedIPv6 != "" {
+		instanceConfig["devices"].(map[string]interface{})["eth0"] = requestedIPDevice(bridgeNetwork, config)
+	}
+	for _, nic := range extraNICs {
+		device := make(map[string]interface{}, len(nic.Config))
+		for key, value := range nic.Config {
+			device[key] = value
+		}
+		instanceConfig["devices"].(map[string]interface{})[nic.Name] = device
+	}
+	for name, device := range volumeDevices(volumes) {
+		instanceConfig["devices"].(map[string]interface{})[name] = device
 	}
 
 	// 序列化请求体
@@ -160,6 +205,9 @@ func (l *LXDProvider) apiCreateInstanceWithProgress(ctx context.Context, config
 		}
 	}
 
+	l.configureInstanceDNS(config)
+	l.configureInstanceHostname(config)
+
 	updateProgress(100, "LXD API实例创建完成")
 	global.APP_LOG.Info("LXD API实例创建成功", zap.String("name", config.Name))
 	return nil
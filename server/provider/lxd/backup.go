@@ -0,0 +1,111 @@
+package lxd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"oneclickvirt/global"
+	"oneclickvirt/provider"
+
+	"go.uber.org/zap"
+)
+
+// BackupInstance 使用 lxc export 导出实例（不含快照）并流式写入dest，宿主机上的临时文件在结束后删除
+func (l *LXDProvider) BackupInstance(ctx context.Context, instanceName string, dest io.Writer) (*provider.BackupArchive, error) {
+	if !l.connected || l.sshClient == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	if !l.hasInstance(instanceName) {
+		return nil, fmt.Errorf("实例 %s 不存在", instanceName)
+	}
+
+	dir, err := provider.PrepareBackupWorkDir(l.sshClient, instanceName)
+	if err != nil {
+		return nil, err
+	}
+	defer provider.RemoveBackupWorkDir(l.sshClient, dir)
+
+	archivePath := fmt.Sprintf("%s/%s.tar.gz", dir, instanceName)
+	if err := provider.RunBackupCommand(l.sshClient, fmt.Sprintf("lxc export %s %s --instance-only", instanceName, archivePath)); err != nil {
+		return nil, fmt.Errorf("导出实例失败: %w", err)
+	}
+
+	archive, err := provider.SendBackupArchive(l.sshClient, archivePath, dest)
+	if err != nil {
+		return nil, err
+	}
+	global.APP_LOG.Info("LXD实例备份导出完成",
+		zap.String("instance", instanceName),
+		zap.Int64("size", archive.Size))
+	return archive, nil
+}
+
+// RestoreFromBackup 用备份替换现有实例
+// 现有实例先停止并改名保留，导入成功后才删除；导入失败时改回原名，保证实例不会丢失
+func (l *LXDProvider) RestoreFromBackup(ctx context.Context, instanceName, fileName string, src io.Reader) error {
+	if !l.connected || l.sshClient == nil {
+		return fmt.Errorf("not connected")
+	}
+	if !l.hasInstance(instanceName) {
+		return fmt.Errorf("实例 %s 不存在", instanceName)
+	}
+
+	dir, err := provider.PrepareBackupWorkDir(l.sshClient, instanceName)
+	if err != nil {
+		return err
+	}
+	defer provider.RemoveBackupWorkDir(l.sshClient, dir)
+
+	archivePath, err := provider.ReceiveBackupArchive(l.sshClient, dir, fileName, src)
+	if err != nil {
+		return err
+	}
+
+	statusOutput, _ := l.sshClient.Execute(fmt.Sprintf("lxc info %s | grep '^Status:'", instanceName))
+	wasRunning := strings.Contains(strings.ToUpper(statusOutput), "RUNNING")
+	if wasRunning {
+		if output, err := l.sshClient.Execute(fmt.Sprintf("lxc stop %s --force", instanceName)); err != nil {
+			return fmt.Errorf("停止实例失败: %s: %w", strings.TrimSpace(output), err)
+		}
+	}
+
+	previousName := fmt.Sprintf("%s-pre-restore-%d", instanceName, time.Now().Unix())
+	if output, err := l.sshClient.Execute(fmt.Sprintf("lxc move %s %s", instanceName, previousName)); err != nil {
+		if wasRunning {
+			l.sshClient.Execute(fmt.Sprintf("lxc start %s", instanceName))
+		}
+		return fmt.Errorf("重命名现有实例失败: %s: %w", strings.TrimSpace(output), err)
+	}
+
+	if err := provider.RunBackupCommand(l.sshClient, fmt.Sprintf("lxc import %s", archivePath)); err != nil {
+		l.sshClient.Execute(fmt.Sprintf("lxc delete %s --force 2>/dev/null", instanceName))
+		if output, moveErr := l.sshClient.Execute(fmt.Sprintf("lxc move %s %s", previousName, instanceName)); moveErr != nil {
+			global.APP_LOG.Error("恢复失败后还原实例名称失败",
+				zap.String("instance", instanceName),
+				zap.String("previous", previousName),
+				zap.String("output", strings.TrimSpace(output)),
+				zap.Error(moveErr))
+		} else if wasRunning {
+			l.sshClient.Execute(fmt.Sprintf("lxc start %s", instanceName))
+		}
+		return fmt.Errorf("导入备份失败: %w", err)
+	}
+
+	if output, err := l.sshClient.Execute(fmt.Sprintf("lxc delete %s --force", previousName)); err != nil {
+		global.APP_LOG.Warn("删除恢复前的实例失败",
+			zap.String("instance", previousName),
+			zap.String("output", strings.TrimSpace(output)),
+			zap.Error(err))
+	}
+	if wasRunning {
+		if output, err := l.sshClient.Execute(fmt.Sprintf("lxc start %s", instanceName)); err != nil {
+			return fmt.Errorf("启动恢复后的实例失败: %s: %w", strings.TrimSpace(output), err)
+		}
+	}
+
+	global.APP_LOG.Info("LXD实例备份恢复完成", zap.String("instance", instanceName))
+	return nil
+}
@@ -0,0 +1,53 @@
+package lxd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"oneclickvirt/global"
+
+	"go.uber.org/zap"
+)
+
+// SetBandwidthLimit 调整实例网卡的限速，uploadMbps/downloadMbps小于等于0表示该方向不限速
+// LXD的limits.ingress/limits.egress由LXD在宿主机侧接口上维护tc规则，实例重启后自动生效
+func (l *LXDProvider) SetBandwidthLimit(ctx context.Context, instanceName string, uploadMbps, downloadMbps int) error {
+	if !l.connected || l.sshClient == nil {
+		return fmt.Errorf("not connected")
+	}
+
+	iface := "eth0"
+	if output, err := l.sshClient.Execute(fmt.Sprintf("lxc config device list %s", instanceName)); err == nil {
+		for _, line := range strings.Split(output, "\n") {
+			if strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(line), ":")) == "enp5s0" {
+				iface = "enp5s0"
+				break
+			}
+		}
+	}
+
+	// limits.max会同时覆盖入站和出站，需清空后单独设置两个方向
+	limits := fmt.Sprintf("limits.egress=%s limits.ingress=%s limits.max=",
+		lxdBandwidthValue(uploadMbps), lxdBandwidthValue(downloadMbps))
+	if _, err := l.sshClient.Execute(fmt.Sprintf("lxc config device set %s %s %s", instanceName, iface, limits)); err != nil {
+		// 网卡仍继承自profile时需要先override到实例上
+		if _, overrideErr := l.sshClient.Execute(fmt.Sprintf("lxc config device override %s %s %s", instanceName, iface, limits)); overrideErr != nil {
+			return fmt.Errorf("设置网络限速失败: %w", overrideErr)
+		}
+	}
+
+	global.APP_LOG.Info("LXD实例限速已更新",
+		zap.String("instance", instanceName),
+		zap.String("interface", iface),
+		zap.Int("uploadMbps", uploadMbps),
+		zap.Int("downloadMbps", downloadMbps))
+	return nil
+}
+
+func lxdBandwidthValue(mbps int) string {
+	if mbps <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%dMbit", mbps)
+}
@@ -0,0 +1,83 @@
+package lxd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"oneclickvirt/global"
+	"oneclickvirt/provider"
+
+	"go.uber.org/zap"
+)
+
+// Capabilities 返回LXD节点的能力信息
+// 虚拟机需要宿主机存在/dev/kvm且LXD启用了qemu驱动，硬盘限制取决于默认存储池驱动（dir驱动不支持）
+func (l *LXDProvider) Capabilities(ctx context.Context) (*provider.Capabilities, error) {
+	return l.capabilities.Get(func() (*provider.Capabilities, error) {
+		if l.sshClient == nil {
+			return nil, fmt.Errorf("SSH client not connected")
+		}
+
+		host, err := provider.ProbeHost(l.sshClient)
+		if err != nil {
+			return nil, err
+		}
+
+		caps := &provider.Capabilities{
+			SupportsContainer: true,
+			SupportsVM:        host.KVM && l.hasQemuDriver(),
+			SupportsSnapshots: true,
+			Architectures:     host.Architectures(),
+		}
+		// 宿主机存在全局IPv6地址时再确认是否为可分配的公网IPv6，避免把ULA等私有地址误判为可用
+		caps.SupportsIPv6 = host.GlobalIPv6 && l.hasUsableIPv6(ctx)
+		caps.StorageDriver = l.defaultStorageDriver()
+		caps.SupportsDiskLimit = caps.StorageDriver != "" && caps.StorageDriver != "dir"
+
+		global.APP_LOG.Info("LXD节点能力探测完成",
+			zap.String("provider", l.config.Name),
+			zap.Bool("vm", caps.SupportsVM),
+			zap.Bool("ipv6", caps.SupportsIPv6),
+			zap.String("storageDriver", caps.StorageDriver),
+			zap.Strings("architectures", caps.Architectures))
+		return caps, nil
+	})
+}
+
+// CachedCapabilities 返回缓存中的能力信息，不会触发SSH探测，缓存不存在或已过期时返回nil
+func (l *LXDProvider) CachedCapabilities() *provider.Capabilities {
+	return l.capabilities.Peek()
+}
+
+// hasQemuDriver 检查LXD是否启用了qemu驱动，旧版本lxc info不输出驱动信息时视为启用
+func (l *LXDProvider) hasQemuDriver() bool {
+	output, err := l.sshClient.Execute("lxc info 2>/dev/null | grep -m1 -E '^[[:space:]]*driver:'")
+	if err != nil || strings.TrimSpace(output) == "" {
+		return true
+	}
+	return strings.Contains(output, "qemu")
+}
+
+// defaultStorageDriver 获取默认profile根磁盘所在存储池的驱动
+func (l *LXDProvider) defaultStorageDriver() string {
+	output, err := l.sshClient.Execute("lxc storage show \"$(lxc profile device get default root pool 2>/dev/null)\" 2>/dev/null | awk '/^driver:/{print $2}'")
+	if err != nil {
+		global.APP_LOG.Debug("获取LXD默认存储池驱动失败",
+			zap.String("provider", l.config.Name),
+			zap.Error(err))
+		return ""
+	}
+	return strings.TrimSpace(output)
+}
+
+// hasUsableIPv6 通过checkIPv6确认宿主机具备可用于实例的公网IPv6
+func (l *LXDProvider) hasUsableIPv6(ctx context.Context) bool {
+	if _, err := l.checkIPv6(ctx); err != nil {
+		global.APP_LOG.Debug("LXD节点没有可用的IPv6",
+			zap.String("provider", l.config.Name),
+			zap.Error(err))
+		return false
+	}
+	return true
+}
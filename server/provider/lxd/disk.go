@@ -0,0 +1,78 @@
+package lxd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"oneclickvirt/global"
+
+	"go.uber.org/zap"
+)
+
+// ResizeInstanceDisk 调整实例根磁盘大小（单位GB）
+func (l *LXDProvider) ResizeInstanceDisk(ctx context.Context, instanceName string, newSizeGB int) error {
+	if !l.connected || l.sshClient == nil {
+		return fmt.Errorf("not connected")
+	}
+	if newSizeGB <= 0 {
+		return fmt.Errorf("无效的磁盘大小: %d", newSizeGB)
+	}
+
+	instanceType, err := l.getInstanceType(instanceName)
+	if err != nil {
+		return fmt.Errorf("获取实例类型失败: %w", err)
+	}
+
+	// 检查当前磁盘使用量，防止缩小到已用空间以下
+	usedMB, err := l.getInstanceDiskUsageMB(instanceName)
+	if err != nil {
+		global.APP_LOG.Warn("获取实例磁盘使用量失败，跳过使用量检查",
+			zap.String("instance", instanceName),
+			zap.Error(err))
+	} else if int64(newSizeGB)*1024 <= usedMB {
+		return fmt.Errorf("新磁盘大小 %dGB 不能小于当前已用空间 %dMB", newSizeGB, usedMB)
+	}
+
+	size := fmt.Sprintf("%dGB", newSizeGB)
+	setCmd := fmt.Sprintf("lxc config device set %s root size=%s", instanceName, size)
+	if _, err := l.sshClient.Execute(setCmd); err != nil {
+		// root设备继承自profile时需要先override到实例上
+		overrideCmd := fmt.Sprintf("lxc config device override %s root size=%s", instanceName, size)
+		if output, overrideErr := l.sshClient.Execute(overrideCmd); overrideErr != nil {
+			return fmt.Errorf("设置根磁盘大小失败: %s: %w", strings.TrimSpace(output), overrideErr)
+		}
+	}
+
+	// 虚拟机需要在系统内扩展分区和文件系统，容器的根磁盘配额直接生效
+	if instanceType == "virtual-machine" {
+		growCmd := fmt.Sprintf("lxc exec %s -- sh -c 'ROOT=$(findmnt -n -o SOURCE /); DISK=/dev/$(lsblk -no PKNAME $ROOT); PART=$(echo $ROOT | grep -o \"[0-9]*$\"); (command -v growpart >/dev/null && growpart $DISK $PART || true); FSTYPE=$(findmnt -n -o FSTYPE /); if [ \"$FSTYPE\" = \"xfs\" ]; then xfs_growfs /; else resize2fs $ROOT; fi'", instanceName)
+		if output, err := l.sshClient.Execute(growCmd); err != nil {
+			global.APP_LOG.Warn("虚拟机内扩展文件系统失败，需要手动扩展",
+				zap.String("instance", instanceName),
+				zap.String("output", strings.TrimSpace(output)),
+				zap.Error(err))
+		}
+	}
+
+	global.APP_LOG.Info("LXD实例磁盘大小调整成功",
+		zap.String("instance", instanceName),
+		zap.String("type", instanceType),
+		zap.Int("newSizeGB", newSizeGB))
+	return nil
+}
+
+// getInstanceDiskUsageMB 获取实例根文件系统已用空间（MB）
+func (l *LXDProvider) getInstanceDiskUsageMB(instanceName string) (int64, error) {
+	cmd := fmt.Sprintf("lxc exec %s -- df -m / | awk 'NR==2 {print $3}'", instanceName)
+	output, err := l.sshClient.Execute(cmd)
+	if err != nil {
+		return 0, err
+	}
+	used, err := strconv.ParseInt(strings.TrimSpace(output), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("解析磁盘使用量失败: %s", strings.TrimSpace(output))
+	}
+	return used, nil
+}
@@ -0,0 +1,33 @@
+package lxd
+
+import (
+	"fmt"
+
+	"oneclickvirt/global"
+	"oneclickvirt/provider"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+)
+
+// configureInstanceDNS 在实例内写入自定义DNS服务器，未指定时保留镜像和网络的默认配置
+// 虚拟机需要agent已启动；写入失败不影响实例创建，只记录日志
+func (l *LXDProvider) configureInstanceDNS(config provider.InstanceConfig) {
+	if len(config.DNSServers) == 0 || l.sshClient == nil {
+		return
+	}
+
+	cmd := fmt.Sprintf("lxc exec %s -- sh -c %s", config.Name, utils.ShellQuote(provider.ResolvConfCommand(config.DNSServers)))
+	if output, err := l.sshClient.Execute(cmd); err != nil {
+		global.APP_LOG.Warn("配置实例DNS失败",
+			zap.String("instance", config.Name),
+			zap.Strings("dnsServers", config.DNSServers),
+			zap.String("output", utils.TruncateString(output, 200)),
+			zap.Error(err))
+		return
+	}
+
+	global.APP_LOG.Info("已配置实例DNS",
+		zap.String("instance", config.Name),
+		zap.Strings("dnsServers", config.DNSServers))
+}
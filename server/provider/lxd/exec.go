@@ -0,0 +1,23 @@
+package lxd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"oneclickvirt/provider"
+	"oneclickvirt/utils"
+)
+
+// ExecInInstance 在实例内执行命令，虚拟机需要安装lxd-agent
+func (l *LXDProvider) ExecInInstance(ctx context.Context, instanceName, command string, timeout time.Duration, maxOutput int) (*utils.CommandResult, error) {
+	if err := provider.ValidateInstanceName(instanceName); err != nil {
+		return nil, err
+	}
+	if !l.connected || l.sshClient == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+
+	cmd := provider.WithExecTimeout(fmt.Sprintf("lxc exec %s -- sh -c %s", instanceName, utils.ShellQuote(command)), timeout)
+	return l.sshClient.ExecuteCapture(cmd, timeout+provider.ExecTimeoutGrace, maxOutput)
+}
@@ -0,0 +1,128 @@
+package lxd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"oneclickvirt/global"
+	"oneclickvirt/provider"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+)
+
+// extraNICDevice 额外网卡对应的nic设备
+type extraNICDevice struct {
+	Name   string
+	Config map[string]string
+}
+
+// extraNICDeviceName 第idx块额外网卡的设备名称，eth1保留给独立IPv6网卡
+func extraNICDeviceName(idx int) string {
+	return fmt.Sprintf("eth%d", provider.FirstExtraInterfaceIndex+idx)
+}
+
+// prepareExtraNICs 检查额外网卡引用的网络是否存在并生成设备配置
+// 受管网络通过network=连接，可指定静态IP；未受管的网桥通过nictype=bridged连接，不支持静态IP
+func (l *LXDProvider) prepareExtraNICs(config provider.InstanceConfig) ([]extraNICDevice, error) {
+	if len(config.NetworkInterfaces) == 0 {
+		return nil, nil
+	}
+	if err := provider.ValidateNetworkInterfaces(config.NetworkInterfaces); err != nil {
+		return nil, err
+	}
+	if l.sshClient == nil {
+		return nil, fmt.Errorf("附加额外网卡需要SSH连接")
+	}
+
+	devices := make([]extraNICDevice, 0, len(config.NetworkInterfaces))
+	for idx, nic := range config.NetworkInterfaces {
+		output, err := l.sshClient.Execute(fmt.Sprintf("lxc network show %s 2>/dev/null", utils.ShellQuote(nic.Network)))
+		if err != nil || strings.TrimSpace(output) == "" {
+			return nil, fmt.Errorf("网络或网桥 %s 不存在", nic.Network)
+		}
+		managed := strings.Contains(output, "managed: true")
+
+		device := extraNICDevice{Name: extraNICDeviceName(idx), Config: map[string]string{"type": "nic"}}
+		if config.InstanceType != "vm" {
+			device.Config["name"] = device.Name
+		}
+		if managed {
+			device.Config["network"] = nic.Network
+		} else {
+			device.Config["nictype"] = "bridged"
+			device.Config["parent"] = nic.Network
+		}
+		if nic.IPAddress != "" {
+			if !managed {
+				return nil, fmt.Errorf("网桥 %s 不是LXD受管网络，无法指定静态IP", nic.Network)
+			}
+			ip, _, err := provider.SplitInterfaceAddress(nic.IPAddress)
+			if err != nil {
+				return nil, err
+			}
+			if ip.To4() != nil {
+				device.Config["ipv4.address"] = ip.String()
+			} else {
+				device.Config["ipv6.address"] = ip.String()
+			}
+		}
+		if nic.MACAddress != "" {
+			device.Config["hwaddr"] = strings.ToLower(nic.MACAddress)
+		}
+		devices = append(devices, device)
+	}
+	return devices, nil
+}
+
+// attachExtraNICs 在实例首次启动前添加额外网卡设备，失败时删除已创建的实例
+func (l *LXDProvider) attachExtraNICs(instanceName string, devices []extraNICDevice) error {
+	for _, device := range devices {
+		keys := make([]string, 0, len(device.Config))
+		for key := range device.Config {
+			if key != "type" {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		params := make([]string, 0, len(keys))
+		for _, key := range keys {
+			params = append(params, fmt.Sprintf("%s=%s", key, utils.ShellQuote(device.Config[key])))
+		}
+
+		cmd := fmt.Sprintf("lxc config device add %s %s nic %s", instanceName, device.Name, strings.Join(params, " "))
+		if output, err := l.sshClient.Execute(cmd); err != nil {
+			global.APP_LOG.Error("添加额外网卡失败，删除已创建的实例",
+				zap.String("instance", instanceName),
+				zap.String("device", device.Name),
+				zap.String("output", utils.TruncateString(output, 200)),
+				zap.Error(err))
+			l.sshClient.Execute(fmt.Sprintf("lxc delete %s --force", instanceName))
+			return fmt.Errorf("添加额外网卡 %s 失败: %w", device.Name, err)
+		}
+	}
+
+	if len(devices) > 0 {
+		global.APP_LOG.Info("已为实例添加额外网卡",
+			zap.String("instance", instanceName),
+			zap.Int("count", len(devices)))
+	}
+	return nil
+}
+
+// ExtraInterfaceNames 返回实例额外网卡在宿主机侧的接口名称
+func (l *LXDProvider) ExtraInterfaceNames(ctx context.Context, instanceName string, count int) ([]string, error) {
+	var names []string
+	for idx := 0; idx < count; idx++ {
+		output, err := l.sshClient.Execute(fmt.Sprintf("lxc config get %s volatile.%s.host_name", instanceName, extraNICDeviceName(idx)))
+		if err != nil {
+			return nil, fmt.Errorf("获取额外网卡 %s 的宿主机接口失败: %w", extraNICDeviceName(idx), err)
+		}
+		if name := strings.TrimSpace(output); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
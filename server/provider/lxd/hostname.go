@@ -0,0 +1,33 @@
+package lxd
+
+import (
+	"fmt"
+
+	"oneclickvirt/global"
+	"oneclickvirt/provider"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+)
+
+// configureInstanceHostname 在实例内设置自定义主机名，未指定时保留默认的实例名称
+// 虚拟机需要agent已启动；设置失败不影响实例创建，只记录日志
+func (l *LXDProvider) configureInstanceHostname(config provider.InstanceConfig) {
+	if config.Hostname == "" || l.sshClient == nil {
+		return
+	}
+
+	cmd := fmt.Sprintf("lxc exec %s -- sh -c %s", config.Name, utils.ShellQuote(provider.SetHostnameCommand(config.Hostname)))
+	if output, err := l.sshClient.Execute(cmd); err != nil {
+		global.APP_LOG.Warn("设置实例主机名失败",
+			zap.String("instance", config.Name),
+			zap.String("hostname", config.Hostname),
+			zap.String("output", utils.TruncateString(output, 200)),
+			zap.Error(err))
+		return
+	}
+
+	global.APP_LOG.Info("已设置实例主机名",
+		zap.String("instance", config.Name),
+		zap.String("hostname", config.Hostname))
+}
@@ -27,7 +27,7 @@ func (l *LXDProvider) handleImageDownloadAndImport(ctx context.Context, config *
 
 	// 为镜像名称添加前缀
 	originalImageName := config.Image
-	imageNameWithPrefix := "oneclickvirt_" + config.Image
+	imageNameWithPrefix := provider.ImageNameWithPrefix(config.Image)
 
 	// 根据实例类型确定镜像类型
 	var imageTypeStr string
@@ -45,7 +45,7 @@ func (l *LXDProvider) handleImageDownloadAndImport(ctx context.Context, config *
 			zap.Bool("useCDN", config.UseCDN))
 
 		// 直接在远程服务器上下载镜像
-		imagePath, err := l.downloadImageToRemote(config.ImageURL, originalImageName, l.config.Country, l.config.Architecture, config.InstanceType, config.UseCDN)
+		imagePath, err := l.downloadImageToRemote(config.ImageURL, originalImageName, l.config.Country, l.config.Architecture, config.InstanceType, config.ImageSHA256, config.UseCDN)
 		if err != nil {
 			return fmt.Errorf("下载%s镜像失败: %w", imageTypeStr, err)
 		}
@@ -142,7 +142,7 @@ func (l *LXDProvider) handleImageDownloadAndImport(ctx context.Context, config *
 				}
 			}
 
-			_, err := l.sshClient.Execute(importCmd)
+			_, err := l.sshClient.ExecuteOperation(utils.SSHOperationDownload, importCmd)
 			if err != nil {
 				return fmt.Errorf("LXD%s镜像导入失败: %w", imageTypeStr, err)
 			}
@@ -216,6 +216,9 @@ func (l *LXDProvider) queryAndSetSystemImage(ctx context.Context, config *provid
 	if systemImage.URL != "" {
 		config.ImageURL = systemImage.URL
 		config.UseCDN = systemImage.UseCDN // 传递UseCDN配置给后续流程
+		if config.ImageSHA256 == "" {
+			config.ImageSHA256 = provider.NormalizeSHA256(systemImage.Checksum)
+		}
 		global.APP_LOG.Info("从数据库获取到系统镜像配置",
 			zap.String("imageName", systemImage.Name),
 			zap.String("originalURL", utils.TruncateString(systemImage.URL, 100)),
@@ -248,7 +251,8 @@ func (l *LXDProvider) imageExists(alias string) bool {
 }
 
 // downloadImageToRemote 在远程服务器上下载LXD镜像
-func (l *LXDProvider) downloadImageToRemote(imageURL, imageName, providerCountry, architecture, instanceType string, useCDN bool) (string, error) {
+// expectedSHA256非空时校验已缓存和新下载的文件，缓存文件不匹配时重新下载，新下载的文件不匹配时删除并返回错误
+func (l *LXDProvider) downloadImageToRemote(imageURL, imageName, providerCountry, architecture, instanceType, expectedSHA256 string, useCDN bool) (string, error) {
 	// 根据实例类型确定远程下载目录
 	var downloadDir string
 	if instanceType == "vm" {
@@ -270,11 +274,19 @@ func (l *LXDProvider) downloadImageToRemote(imageURL, imageName, providerCountry
 
 	// 检查远程文件是否已存在
 	if l.isRemoteFileValid(remotePath) {
-		global.APP_LOG.Info("远程LXD镜像文件已存在且完整，跳过下载",
-			zap.String("imageName", imageName),
-			zap.String("remotePath", remotePath),
-			zap.String("instanceType", instanceType))
-		return remotePath, nil
+		if err := provider.VerifyRemoteSHA256(l.sshClient, remotePath, expectedSHA256); err != nil {
+			global.APP_LOG.Warn("远程缓存LXD镜像文件校验失败，重新下载",
+				zap.String("imageName", imageName),
+				zap.String("remotePath", remotePath),
+				zap.Error(err))
+			l.removeRemoteFile(remotePath)
+		} else {
+			global.APP_LOG.Info("远程LXD镜像文件已存在且完整，跳过下载",
+				zap.String("imageName", imageName),
+				zap.String("remotePath", remotePath),
+				zap.String("instanceType", instanceType))
+			return remotePath, nil
+		}
 	}
 
 	// 确定下载URL，传递 useCDN 参数
@@ -289,11 +301,20 @@ func (l *LXDProvider) downloadImageToRemote(imageURL, imageName, providerCountry
 
 	// 在远程服务器上下载文件
 	if err := l.downloadFileToRemote(downloadURL, remotePath); err != nil {
-		// 下载失败，删除不完整的文件
+		// 下载失败，部分文件保留在.tmp中供下次续传
 		l.removeRemoteFile(remotePath)
 		return "", fmt.Errorf("远程下载LXD镜像失败: %w", err)
 	}
 
+	if err := provider.VerifyRemoteSHA256(l.sshClient, remotePath, expectedSHA256); err != nil {
+		l.removeRemoteFile(remotePath)
+		global.APP_LOG.Error("远程LXD镜像文件校验失败",
+			zap.String("imageName", imageName),
+			zap.String("remotePath", remotePath),
+			zap.Error(err))
+		return "", err
+	}
+
 	global.APP_LOG.Info("远程LXD镜像下载完成",
 		zap.String("imageName", imageName),
 		zap.String("remotePath", remotePath),
@@ -361,49 +382,11 @@ func (l *LXDProvider) removeRemoteFile(remotePath string) error {
 }
 
 // downloadFileToRemote 在远程服务器上下载文件
-// downloadFileToRemote 在远程服务器上下载文件
+// downloadFileToRemote 在远程服务器上下载文件，中断后保留部分文件以便下次断点续传
 func (l *LXDProvider) downloadFileToRemote(url, remotePath string) error {
-	// 使用curl在远程服务器上下载文件
-	tmpPath := remotePath + ".tmp"
-
-	// 下载文件，支持断点续传
-	curlCmd := fmt.Sprintf(
-		"curl -4 -L -C - --connect-timeout 30 --retry 5 --retry-delay 10 --retry-max-time 0 -o %s '%s'",
-		tmpPath, url,
-	)
-
 	global.APP_LOG.Info("执行远程下载命令",
 		zap.String("url", utils.TruncateString(url, 100)))
-
-	output, err := l.sshClient.Execute(curlCmd)
-	if err != nil {
-		// 清理临时文件
-		l.sshClient.Execute(fmt.Sprintf("rm -f %s", tmpPath))
-
-		global.APP_LOG.Error("远程下载失败",
-			zap.String("url", utils.TruncateString(url, 100)),
-			zap.String("remotePath", remotePath),
-			zap.String("output", utils.TruncateString(output, 500)),
-			zap.Error(err))
-		return fmt.Errorf("远程下载失败: %w", err)
-	}
-
-	// 移动文件到最终位置
-	mvCmd := fmt.Sprintf("mv %s %s", tmpPath, remotePath)
-	_, err = l.sshClient.Execute(mvCmd)
-	if err != nil {
-		global.APP_LOG.Error("移动文件失败",
-			zap.String("tmpPath", tmpPath),
-			zap.String("remotePath", remotePath),
-			zap.Error(err))
-		return fmt.Errorf("移动文件失败: %w", err)
-	}
-
-	global.APP_LOG.Info("远程下载成功",
-		zap.String("url", utils.TruncateString(url, 100)),
-		zap.String("remotePath", remotePath))
-
-	return nil
+	return provider.DownloadRemoteFile(l.sshClient, url, remotePath)
 }
 
 // ensureSSHScriptsAvailable 确保SSH脚本文件在远程服务器上可用
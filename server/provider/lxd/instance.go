@@ -59,12 +59,16 @@ func (l *LXDProvider) configureInstanceSecurity(ctx context.Context, config prov
 		}
 
 		// 内存交换配置
-		if err := l.setInstanceConfig(ctx, config.Name, "limits.memory.swap", "true"); err != nil {
-			global.APP_LOG.Warn("设置内存交换失败", zap.Error(err))
-		}
+		if provider.LXCMemorySwapEnabled(config) {
+			if err := l.setInstanceConfig(ctx, config.Name, "limits.memory.swap", "true"); err != nil {
+				global.APP_LOG.Warn("设置内存交换失败", zap.Error(err))
+			}
 
-		if err := l.setInstanceConfig(ctx, config.Name, "limits.memory.swap.priority", "1"); err != nil {
-			global.APP_LOG.Warn("设置内存交换优先级失败", zap.Error(err))
+			if err := l.setInstanceConfig(ctx, config.Name, "limits.memory.swap.priority", "1"); err != nil {
+				global.APP_LOG.Warn("设置内存交换优先级失败", zap.Error(err))
+			}
+		} else if err := l.setInstanceConfig(ctx, config.Name, "limits.memory.swap", "false"); err != nil {
+			global.APP_LOG.Warn("设置内存交换失败", zap.Error(err))
 		}
 	}
 
@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"oneclickvirt/global"
+	"oneclickvirt/provider"
+	"oneclickvirt/provider/netfilter"
 
 	"go.uber.org/zap"
 )
@@ -413,14 +415,11 @@ func (l *LXDProvider) installSipcalcRHEL(ctx context.Context) error {
 
 	filename := "sipcalc-1.1.6-17.el8." + arch + ".rpm"
 
-	for _, mirror := range mirrors {
-		global.APP_LOG.Info("尝试从镜像下载sipcalc", zap.String("mirror", mirror))
-		downloadCmd := fmt.Sprintf("curl -fLO '%s'", mirror)
-		_, err := l.sshClient.Execute(downloadCmd)
-		if err == nil {
-			break
-		}
+	mirror, err := provider.DownloadFromMirrors(l.sshClient, mirrors, filename, provider.DownloadKindRPM)
+	if err != nil {
+		return fmt.Errorf("下载sipcalc失败: %w", err)
 	}
+	global.APP_LOG.Info("已从镜像下载sipcalc", zap.String("mirror", mirror))
 
 	// 安装rpm包
 	installCmd := fmt.Sprintf("rpm -ivh %s", filename)
@@ -763,9 +762,19 @@ func (l *LXDProvider) setupIptablesIPv6(ctx context.Context, config IPv6Config)
 	global.APP_LOG.Info("开始配置iptables IPv6映射",
 		zap.String("container", config.ContainerName))
 
-	// 安装必要的包
-	l.sshClient.Execute("apt update -y 2>/dev/null || yum update -y 2>/dev/null || true")
-	l.sshClient.Execute("apt install -y netfilter-persistent 2>/dev/null || yum install -y iptables-services 2>/dev/null || true")
+	// 检测防火墙后端，操作系统检测失败时仍按通用方式安装软件包
+	osType, err := netfilter.DetectOS(l.sshClient)
+	if err != nil {
+		global.APP_LOG.Warn("检测操作系统失败", zap.Error(err))
+	}
+	backend := netfilter.Detect(l.sshClient, osType)
+
+	// 安装必要的包并初始化规则表
+	if err := backend.Prepare(); err != nil {
+		global.APP_LOG.Warn("初始化防火墙后端失败",
+			zap.String("backend", backend.Name()),
+			zap.Error(err))
+	}
 
 	// 获取容器的内网IPv6地址
 	containerIPv6, err := l.getContainerIPv6(ctx, config.ContainerName)
@@ -813,48 +822,9 @@ func (l *LXDProvider) setupIptablesIPv6(ctx context.Context, config IPv6Config)
 		zap.String("containerIPv6", containerIPv6))
 
 	// 查找可用的IPv6地址
-	var mappedIPv6 string
-	for i := 3; i <= 65535; i++ {
-		testIPv6 := fmt.Sprintf("%s%d", subnetPrefix, i)
-
-		// 跳过容器本身的地址
-		if testIPv6 == containerIPv6 {
-			continue
-		}
-
-		// 检查地址是否已被使用
-		checkAddrCmd := fmt.Sprintf("ip -6 addr show dev %s | grep -q %s", interfaceName, testIPv6)
-		_, err := l.sshClient.Execute(checkAddrCmd)
-		if err == nil {
-			// 地址已被使用，继续下一个
-			continue
-		}
-
-		// 检查地址是否可以ping通
-		pingCmd := fmt.Sprintf("ping6 -c1 -w1 -q %s", testIPv6)
-		_, err = l.sshClient.Execute(pingCmd)
-		if err == nil {
-			// 地址能ping通，说明已被占用
-			global.APP_LOG.Debug("IPv6地址已被占用", zap.String("ipv6", testIPv6))
-			continue
-		}
-
-		// 检查是否已存在iptables规则
-		checkRuleCmd := fmt.Sprintf("ip6tables -t nat -C PREROUTING -d %s -j DNAT --to-destination %s 2>/dev/null", testIPv6, containerIPv6)
-		_, err = l.sshClient.Execute(checkRuleCmd)
-		if err == nil {
-			// 规则已存在
-			continue
-		}
-
-		// 找到可用地址
-		mappedIPv6 = testIPv6
-		global.APP_LOG.Info("找到可用IPv6地址", zap.String("ipv6", mappedIPv6))
-		break
-	}
-
-	if mappedIPv6 == "" {
-		return "", fmt.Errorf("无可用IPv6地址，不进行自动映射")
+	mappedIPv6, err := netfilter.FindFreeIPv6(l.sshClient, backend, subnetPrefix, interfaceName, containerIPv6)
+	if err != nil {
+		return "", err
 	}
 
 	// IPv6地址到接口
@@ -864,11 +834,9 @@ func (l *LXDProvider) setupIptablesIPv6(ctx context.Context, config IPv6Config)
 		return "", fmt.Errorf("添加IPv6地址失败: %w", err)
 	}
 
-	// iptables NAT规则
-	natRuleCmd := fmt.Sprintf("ip6tables -t nat -A PREROUTING -d %s -j DNAT --to-destination %s", mappedIPv6, containerIPv6)
-	_, err = l.sshClient.Execute(natRuleCmd)
-	if err != nil {
-		return "", fmt.Errorf("添加ip6tables NAT规则失败: %w", err)
+	// 添加DNAT规则
+	if err := backend.AddIPv6DNAT(mappedIPv6, containerIPv6); err != nil {
+		return "", err
 	}
 
 	// 设置持久化服务和脚本
@@ -877,10 +845,11 @@ func (l *LXDProvider) setupIptablesIPv6(ctx context.Context, config IPv6Config)
 		global.APP_LOG.Warn("设置持久化服务失败", zap.Error(err))
 	}
 
-	// 保存iptables规则
-	err = l.saveIp6tablesRules(ctx)
-	if err != nil {
-		global.APP_LOG.Warn("保存ip6tables规则失败", zap.Error(err))
+	// 保存规则
+	if err := backend.Persist(); err != nil {
+		global.APP_LOG.Warn("保存防火墙规则失败",
+			zap.String("backend", backend.Name()),
+			zap.Error(err))
 	}
 
 	// 测试连通性
@@ -917,12 +886,11 @@ func (l *LXDProvider) setupPersistenceService(ctx context.Context) error {
 
 	// 下载add-ipv6.sh脚本
 	scriptPath := "/usr/local/bin/add-ipv6.sh"
-	checkScriptCmd := fmt.Sprintf("[ -f %s ]", scriptPath)
-	_, err := l.sshClient.Execute(checkScriptCmd)
+	// 已存在的文件也要校验，之前从镜像下载到的错误页面会被重新下载覆盖
+	_, err := l.sshClient.Execute(provider.ValidDownloadCommand(scriptPath, provider.DownloadKindScript))
 	if err != nil {
-		scriptUrl := cdnSuccessUrl + "https://raw.githubusercontent.com/oneclickvirt/lxd/main/scripts/add-ipv6.sh"
-		downloadCmd := fmt.Sprintf("wget '%s' -O %s", scriptUrl, scriptPath)
-		_, err := l.sshClient.Execute(downloadCmd)
+		scriptUrls := provider.MirrorURLs(cdnUrls, cdnSuccessUrl, "https://raw.githubusercontent.com/oneclickvirt/lxd/main/scripts/add-ipv6.sh")
+		_, err := provider.DownloadFromMirrors(l.sshClient, scriptUrls, scriptPath, provider.DownloadKindScript)
 		if err != nil {
 			global.APP_LOG.Warn("下载add-ipv6.sh脚本失败", zap.Error(err))
 		} else {
@@ -932,12 +900,10 @@ func (l *LXDProvider) setupPersistenceService(ctx context.Context) error {
 
 	// 下载add-ipv6.service服务文件
 	servicePath := "/etc/systemd/system/add-ipv6.service"
-	checkServiceCmd := fmt.Sprintf("[ -f %s ]", servicePath)
-	_, err = l.sshClient.Execute(checkServiceCmd)
+	_, err = l.sshClient.Execute(provider.ValidDownloadCommand(servicePath, provider.DownloadKindSystemdUnit))
 	if err != nil {
-		serviceUrl := cdnSuccessUrl + "https://raw.githubusercontent.com/oneclickvirt/lxd/main/scripts/add-ipv6.service"
-		downloadCmd := fmt.Sprintf("wget '%s' -O %s", serviceUrl, servicePath)
-		_, err := l.sshClient.Execute(downloadCmd)
+		serviceUrls := provider.MirrorURLs(cdnUrls, cdnSuccessUrl, "https://raw.githubusercontent.com/oneclickvirt/lxd/main/scripts/add-ipv6.service")
+		_, err := provider.DownloadFromMirrors(l.sshClient, serviceUrls, servicePath, provider.DownloadKindSystemdUnit)
 		if err != nil {
 			global.APP_LOG.Warn("下载add-ipv6.service服务文件失败", zap.Error(err))
 		} else {
@@ -951,47 +917,68 @@ func (l *LXDProvider) setupPersistenceService(ctx context.Context) error {
 	return nil
 }
 
-// saveIp6tablesRules 保存ip6tables规则
-func (l *LXDProvider) saveIp6tablesRules(ctx context.Context) error {
-	// 创建iptables目录
-	l.sshClient.Execute("mkdir -p /etc/iptables")
-
-	// 创建规则文件
-	l.sshClient.Execute("touch /etc/iptables/rules.v6")
-
-	// 保存当前规则
-	_, err := l.sshClient.Execute("ip6tables-save > /etc/iptables/rules.v6")
-	if err != nil {
-		return fmt.Errorf("保存ip6tables规则失败: %w", err)
-	}
-
-	// 检查netfilter-persistent是否可用
-	_, err = l.sshClient.Execute("command -v netfilter-persistent")
-	if err == nil {
-		l.sshClient.Execute("netfilter-persistent save")
-		l.sshClient.Execute("netfilter-persistent reload")
-		l.sshClient.Execute("service netfilter-persistent restart")
-	}
-
-	return nil
-}
-
 // testIPv6Connectivity 测试IPv6连通性
+// 添加地址后NDP/路由传播需要时间，因此在判定失败前会多次重试
 func (l *LXDProvider) testIPv6Connectivity(ctx context.Context, ipv6Addr, containerName string) error {
+	const (
+		maxAttempts   = 5
+		retryInterval = 2 * time.Second
+	)
+
 	global.APP_LOG.Info("测试IPv6连通性", zap.String("ipv6", ipv6Addr))
 
 	testCmd := fmt.Sprintf("ping6 -c 3 %s", ipv6Addr)
-	_, err := l.sshClient.Execute(testCmd)
-	if err != nil {
-		global.APP_LOG.Error("IPv6映射失败",
+	var lastOutput string
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		output, err := l.sshClient.Execute(testCmd)
+		if err == nil {
+			global.APP_LOG.Info("IPv6映射成功",
+				zap.String("container", containerName),
+				zap.String("ipv6", ipv6Addr),
+				zap.Int("attempt", attempt))
+			return nil
+		}
+		lastOutput = strings.TrimSpace(output)
+
+		// 地址本身无效属于配置错误，重试没有意义
+		if isInvalidIPv6ConfigOutput(lastOutput) {
+			global.APP_LOG.Error("IPv6地址配置无效",
+				zap.String("container", containerName),
+				zap.String("ipv6", ipv6Addr),
+				zap.String("output", lastOutput))
+			return fmt.Errorf("IPv6配置无效: %s", lastOutput)
+		}
+
+		global.APP_LOG.Warn("IPv6地址暂不可达，等待NDP/路由传播后重试",
 			zap.String("container", containerName),
-			zap.String("ipv6", ipv6Addr))
-		return fmt.Errorf("映射失败")
+			zap.String("ipv6", ipv6Addr),
+			zap.Int("attempt", attempt),
+			zap.Int("maxAttempts", maxAttempts),
+			zap.String("output", lastOutput))
+
+		if attempt < maxAttempts {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(retryInterval):
+			}
+		}
 	}
 
-	global.APP_LOG.Info("IPv6映射成功",
+	global.APP_LOG.Error("IPv6映射失败",
 		zap.String("container", containerName),
-		zap.String("ipv6", ipv6Addr))
+		zap.String("ipv6", ipv6Addr),
+		zap.Int("attempts", maxAttempts))
+	return fmt.Errorf("映射失败: %d次尝试后IPv6地址仍不可达", maxAttempts)
+}
 
-	return nil
+// isInvalidIPv6ConfigOutput 判断ping6输出是否表示地址或配置本身无效
+func isInvalidIPv6ConfigOutput(output string) bool {
+	lower := strings.ToLower(output)
+	for _, keyword := range []string{"unknown host", "name or service not known", "bad address", "invalid argument", "address family for hostname not supported"} {
+		if strings.Contains(lower, keyword) {
+			return true
+		}
+	}
+	return false
 }
@@ -12,6 +12,7 @@ import (
 	"oneclickvirt/global"
 	"oneclickvirt/provider"
 	"oneclickvirt/provider/health"
+	"oneclickvirt/service/metrics"
 	"oneclickvirt/utils"
 
 	"go.uber.org/zap"
@@ -26,6 +27,8 @@ type LXDProvider struct {
 	connected     bool
 	healthChecker health.HealthChecker
 	mu            sync.RWMutex // 保护并发访问
+
+	capabilities provider.CapabilitiesCache // 能力探测结果缓存
 }
 
 func NewLXDProvider() provider.Provider {
@@ -60,6 +63,7 @@ func (l *LXDProvider) GetSupportedInstanceTypes() []string {
 
 func (l *LXDProvider) Connect(ctx context.Context, config provider.NodeConfig) error {
 	l.config = config
+	l.capabilities.Invalidate()
 	l.providerID = config.ID // 存储providerID
 
 	// Transport 已在 NewLXDProvider 中创建，现在关联providerID
@@ -103,13 +107,19 @@ func (l *LXDProvider) Connect(ctx context.Context, config provider.NodeConfig) e
 
 	// 尝试 SSH 连接
 	sshConfig := utils.SSHConfig{
-		Host:           config.Host,
-		Port:           config.Port,
-		Username:       config.Username,
-		Password:       config.Password,
-		PrivateKey:     config.PrivateKey,
-		ConnectTimeout: time.Duration(sshConnectTimeout) * time.Second,
-		ExecuteTimeout: time.Duration(sshExecuteTimeout) * time.Second,
+		Host:                 config.Host,
+		Port:                 config.Port,
+		Username:             config.Username,
+		Password:             config.Password,
+		PrivateKey:           config.PrivateKey,
+		PrivateKeyPassphrase: config.PrivateKeyPassphrase,
+		UseAgent:             config.UseSSHAgent,
+		VerifyHostKey:        config.SSHVerifyHostKey,
+		HostKeyFingerprint:   config.SSHHostKeyFingerprint,
+		OnHostKeyPinned:      config.OnHostKeyPinned,
+		ConnectTimeout:       time.Duration(sshConnectTimeout) * time.Second,
+		ExecuteTimeout:       time.Duration(sshExecuteTimeout) * time.Second,
+		CommandLogID:         config.ID,
 	}
 
 	client, err := utils.NewSSHClient(sshConfig)
@@ -122,19 +132,23 @@ func (l *LXDProvider) Connect(ctx context.Context, config provider.NodeConfig) e
 
 	// 初始化健康检查器，使用Provider的SSH连接，避免创建独立连接导致节点混淆
 	healthConfig := health.HealthConfig{
-		Host:          config.Host,
-		Port:          config.Port,
-		Username:      config.Username,
-		Password:      config.Password,
-		PrivateKey:    config.PrivateKey,
-		APIEnabled:    config.CertPath != "" && config.KeyPath != "",
-		APIPort:       8443,
-		APIScheme:     "https",
-		SSHEnabled:    true,
-		Timeout:       30 * time.Second,
-		ServiceChecks: []string{"lxd"},
-		CertPath:      config.CertPath,
-		KeyPath:       config.KeyPath,
+		Host:                 config.Host,
+		Port:                 config.Port,
+		Username:             config.Username,
+		Password:             config.Password,
+		PrivateKey:           config.PrivateKey,
+		PrivateKeyPassphrase: config.PrivateKeyPassphrase,
+		UseAgent:             config.UseSSHAgent,
+		VerifyHostKey:        config.SSHVerifyHostKey,
+		HostKeyFingerprint:   config.SSHHostKeyFingerprint,
+		APIEnabled:           config.CertPath != "" && config.KeyPath != "",
+		APIPort:              8443,
+		APIScheme:            "https",
+		SSHEnabled:           true,
+		Timeout:              30 * time.Second,
+		ServiceChecks:        []string{"lxd"},
+		CertPath:             config.CertPath,
+		KeyPath:              config.KeyPath,
 	}
 
 	zapLogger, _ := zap.NewProduction()
@@ -143,7 +157,8 @@ func (l *LXDProvider) Connect(ctx context.Context, config provider.NodeConfig) e
 
 	global.APP_LOG.Info("LXD provider SSH连接成功",
 		zap.String("host", utils.TruncateString(config.Host, 50)),
-		zap.Int("port", config.Port))
+		zap.Int("port", config.Port),
+		zap.String("authMethod", client.AuthMethod()))
 
 	return nil
 }
@@ -238,10 +253,18 @@ func (l *LXDProvider) ListInstances(ctx context.Context) ([]provider.Instance, e
 }
 
 func (l *LXDProvider) CreateInstance(ctx context.Context, config provider.InstanceConfig) error {
+	if err := provider.ValidateInstanceName(config.Name); err != nil {
+		return err
+	}
+
 	if !l.connected {
 		return fmt.Errorf("not connected")
 	}
 
+	if err := l.validateStoragePool(ctx, config.StoragePool); err != nil {
+		return err
+	}
+
 	// 根据执行规则判断使用哪种方式
 	if l.shouldUseAPI() {
 		if err := l.apiCreateInstance(ctx, config); err == nil {
@@ -268,10 +291,18 @@ func (l *LXDProvider) CreateInstance(ctx context.Context, config provider.Instan
 }
 
 func (l *LXDProvider) CreateInstanceWithProgress(ctx context.Context, config provider.InstanceConfig, progressCallback provider.ProgressCallback) error {
+	if err := provider.ValidateInstanceName(config.Name); err != nil {
+		return err
+	}
+
 	if !l.connected {
 		return fmt.Errorf("not connected")
 	}
 
+	if err := l.validateStoragePool(ctx, config.StoragePool); err != nil {
+		return err
+	}
+
 	// 根据执行规则判断使用哪种方式
 	if l.shouldUseAPI() {
 		if err := l.apiCreateInstanceWithProgress(ctx, config, progressCallback); err == nil {
@@ -298,6 +329,10 @@ func (l *LXDProvider) CreateInstanceWithProgress(ctx context.Context, config pro
 }
 
 func (l *LXDProvider) StartInstance(ctx context.Context, id string) error {
+	if err := provider.ValidateInstanceName(id); err != nil {
+		return err
+	}
+
 	if !l.connected {
 		return fmt.Errorf("not connected")
 	}
@@ -328,6 +363,10 @@ func (l *LXDProvider) StartInstance(ctx context.Context, id string) error {
 }
 
 func (l *LXDProvider) StopInstance(ctx context.Context, id string) error {
+	if err := provider.ValidateInstanceName(id); err != nil {
+		return err
+	}
+
 	if !l.connected {
 		return fmt.Errorf("not connected")
 	}
@@ -358,6 +397,10 @@ func (l *LXDProvider) StopInstance(ctx context.Context, id string) error {
 }
 
 func (l *LXDProvider) RestartInstance(ctx context.Context, id string) error {
+	if err := provider.ValidateInstanceName(id); err != nil {
+		return err
+	}
+
 	if !l.connected {
 		return fmt.Errorf("not connected")
 	}
@@ -388,6 +431,10 @@ func (l *LXDProvider) RestartInstance(ctx context.Context, id string) error {
 }
 
 func (l *LXDProvider) DeleteInstance(ctx context.Context, id string) error {
+	if err := provider.ValidateInstanceName(id); err != nil {
+		return err
+	}
+
 	if !l.connected {
 		return fmt.Errorf("not connected")
 	}
@@ -417,6 +464,37 @@ func (l *LXDProvider) DeleteInstance(ctx context.Context, id string) error {
 	return l.sshDeleteInstance(ctx, id)
 }
 
+// deleteInstanceCommand 删除实例的SSH命令
+func (l *LXDProvider) deleteInstanceCommand(id string) string {
+	return fmt.Sprintf("lxc delete %s --force", id)
+}
+
+// PlanDeleteInstance 返回删除实例时将按顺序执行的操作（仅预览，不执行）
+func (l *LXDProvider) PlanDeleteInstance(ctx context.Context, id string) ([]string, error) {
+	if !l.connected {
+		return nil, fmt.Errorf("not connected")
+	}
+	if id == "" {
+		return nil, fmt.Errorf("实例名称不能为空")
+	}
+
+	var commands []string
+	if l.shouldUseAPI() {
+		commands = append(commands, fmt.Sprintf("# API: DELETE https://%s:8443/1.0/instances/%s", l.config.Host, id))
+		if !l.shouldFallbackToSSH() {
+			return commands, nil
+		}
+		commands = append(commands, "# API失败时回退到SSH")
+	}
+	if l.shouldUseSSH() {
+		commands = append(commands, l.deleteInstanceCommand(id))
+	}
+	if len(commands) == 0 {
+		return nil, fmt.Errorf("执行规则不允许使用SSH")
+	}
+	return commands, nil
+}
+
 func (l *LXDProvider) GetInstance(ctx context.Context, id string) (*provider.Instance, error) {
 	instances, err := l.ListInstances(ctx)
 	if err != nil {
@@ -553,7 +631,7 @@ func (l *LXDProvider) createTLSConfig(certPath, keyPath string) (*tls.Config, er
 	return tlsConfig, nil
 }
 
-// ExecuteSSHCommand 执行SSH命令
+// ExecuteSSHCommand 执行SSH命令，超时按ctx中的操作类型确定
 func (l *LXDProvider) ExecuteSSHCommand(ctx context.Context, command string) (string, error) {
 	if !l.connected || l.sshClient == nil {
 		return "", fmt.Errorf("LXD provider not connected")
@@ -562,7 +640,9 @@ func (l *LXDProvider) ExecuteSSHCommand(ctx context.Context, command string) (st
 	global.APP_LOG.Debug("执行SSH命令",
 		zap.String("command", utils.TruncateString(command, 200)))
 
-	output, err := l.sshClient.Execute(command)
+	start := time.Now()
+	output, err := l.sshClient.ExecuteWithTimeout(command, utils.SSHOperationTimeout(utils.SSHOperationFromContext(ctx)), utils.SSHOutputLimitFromContext(ctx))
+	metrics.ObserveSSHCommand(l.config.Name, start, err)
 	if err != nil {
 		global.APP_LOG.Error("SSH命令执行失败",
 			zap.String("command", utils.TruncateString(command, 200)),
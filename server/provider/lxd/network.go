@@ -953,6 +953,14 @@ func (l *LXDProvider) parseNetworkConfigFromInstanceConfig(config provider.Insta
 			zap.String("instanceName", config.Name))
 	}
 
+	// 实例配置中显式指定的带宽优先级最高
+	if config.IngressMbps > 0 {
+		networkConfig.InSpeed = config.IngressMbps
+	}
+	if config.EgressMbps > 0 {
+		networkConfig.OutSpeed = config.EgressMbps
+	}
+
 	// 输出最终的网络配置结果
 	global.APP_LOG.Info("LXD网络配置解析完成",
 		zap.String("instanceName", config.Name),
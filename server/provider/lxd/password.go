@@ -4,11 +4,16 @@ import (
 	"context"
 	"fmt"
 
+	"oneclickvirt/provider"
 	"oneclickvirt/utils"
 )
 
 // SetInstancePassword 设置实例密码
 func (l *LXDProvider) SetInstancePassword(ctx context.Context, instanceID, password string) error {
+	if err := provider.ValidateInstanceName(instanceID); err != nil {
+		return err
+	}
+
 	if !l.connected {
 		return fmt.Errorf("provider not connected")
 	}
@@ -38,6 +43,10 @@ func (l *LXDProvider) SetInstancePassword(ctx context.Context, instanceID, passw
 
 // ResetInstancePassword 重置实例密码
 func (l *LXDProvider) ResetInstancePassword(ctx context.Context, instanceID string) (string, error) {
+	if err := provider.ValidateInstanceName(instanceID); err != nil {
+		return "", err
+	}
+
 	if !l.connected {
 		return "", fmt.Errorf("provider not connected")
 	}
@@ -60,7 +69,7 @@ func (l *LXDProvider) ResetInstancePassword(ctx context.Context, instanceID stri
 	return newPassword, nil
 }
 
-// generateRandomPassword 生成随机密码（仅包含数字和大小写英文字母，长度不低于8位）
+// generateRandomPassword 按配置的生成密码策略生成随机密码
 func (l *LXDProvider) generateRandomPassword() string {
 	return utils.GenerateInstancePassword()
 }
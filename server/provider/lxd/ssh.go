@@ -239,6 +239,27 @@ func (l *LXDProvider) sshCreateInstanceWithProgress(ctx context.Context, config
 
 	updateProgress(5, "开始创建LXD实例...")
 
+	if _, err := l.validateRequestedIPs(ctx, config); err != nil {
+		return fmt.Errorf("指定IP校验失败: %w", err)
+	}
+	extraNICs, err := l.prepareExtraNICs(config)
+	if err != nil {
+		return fmt.Errorf("额外网卡校验失败: %w", err)
+	}
+	volumes, err := l.prepareVolumes(config)
+	if err != nil {
+		return fmt.Errorf("挂载目录校验失败: %w", err)
+	}
+	if err := l.resolveCPULimit(&config); err != nil {
+		return fmt.Errorf("CPU限制校验失败: %w", err)
+	}
+	if config.DNSServers, err = provider.ValidateDNSServers(config.DNSServers); err != nil {
+		return fmt.Errorf("DNS服务器校验失败: %w", err)
+	}
+	if config.Hostname, err = provider.ValidateHostname(config.Hostname); err != nil {
+		return fmt.Errorf("主机名校验失败: %w", err)
+	}
+
 	// 如果是虚拟机，先检查VM支持
 	if config.InstanceType == "vm" {
 		updateProgress(10, "检查虚拟机支持...")
@@ -320,31 +341,19 @@ func (l *LXDProvider) sshCreateInstanceWithProgress(ctx context.Context, config
 		}
 
 		// 3. CPU限制配置（CPU Allowance vs limits.cpu）
-		// limits.cpu.allowance 与 limits.cpu 互斥，优先使用 allowance
-		if config.CPUAllowance != nil && *config.CPUAllowance != "" && *config.CPUAllowance != "100%" {
-			// CPU限制格式：20% 或 50%，100%等同于不限制
-			configParams = append(configParams, fmt.Sprintf("limits.cpu.allowance=%s", *config.CPUAllowance))
-			configParams = append(configParams, "limits.cpu.priority=0")
-		} else {
-			// 使用标准的CPU核心数限制（已在上面设置）
-			configParams = append(configParams, "limits.cpu.priority=0")
-			// 设置默认的CPU调度策略（参考官方脚本）
-			configParams = append(configParams, "limits.cpu.allowance=50%")
-			configParams = append(configParams, "limits.cpu.allowance=25ms/100ms")
+		// 节点指定了限额（如 20%、25ms/100ms）时直接使用，100%等同于未指定；
+		// 否则按实例核心数和节点配置的限额方式生成，只设置一个值
+		configParams = append(configParams, "limits.cpu.priority=0")
+		if allowance := provider.ContainerCPUAllowance(config, l.config.ContainerCPUAllowanceMode); allowance != "" {
+			configParams = append(configParams, fmt.Sprintf("limits.cpu.allowance=%s", allowance))
 		}
 
-		// 4. 内存交换配置（Memory Swap）
-		if config.MemorySwap != nil {
-			if *config.MemorySwap {
-				configParams = append(configParams, "limits.memory.swap=true")
-				configParams = append(configParams, "limits.memory.swap.priority=1")
-			} else {
-				configParams = append(configParams, "limits.memory.swap=false")
-			}
-		} else {
-			// 默认启用swap（保持原有行为）
+		// 4. 内存交换配置（Memory Swap），LXC只能开关swap，无法限制swap大小
+		if provider.LXCMemorySwapEnabled(config) {
 			configParams = append(configParams, "limits.memory.swap=true")
 			configParams = append(configParams, "limits.memory.swap.priority=1")
+		} else {
+			configParams = append(configParams, "limits.memory.swap=false")
 		}
 
 		// 5. 最大进程数配置（Max Processes）
@@ -355,6 +364,14 @@ func (l *LXDProvider) sshCreateInstanceWithProgress(ctx context.Context, config
 		// LXCFS和磁盘IO在init阶段不设置，在实例启动后通过lxc config device命令设置
 	}
 
+	// 实例标签同步为user.tag.*配置项
+	configParams = append(configParams, tagConfigParams(config.Labels)...)
+
+	// 指定存储池
+	if config.StoragePool != "" {
+		cmd += fmt.Sprintf(" --storage %s", config.StoragePool)
+	}
+
 	// 添加所有配置参数到命令
 	for _, param := range configParams {
 		cmd += fmt.Sprintf(" -c %s", param)
@@ -368,11 +385,21 @@ func (l *LXDProvider) sshCreateInstanceWithProgress(ctx context.Context, config
 
 	// 创建实例
 	global.APP_LOG.Debug("执行LXD实例创建命令", zap.String("command", cmd))
-	_, err := l.sshClient.Execute(cmd)
+	_, err = l.sshClient.Execute(cmd)
 	if err != nil {
 		return fmt.Errorf("failed to create instance: %w", err)
 	}
 
+	if err := l.applyRequestedIPs(config); err != nil {
+		return err
+	}
+	if err := l.attachExtraNICs(config.Name, extraNICs); err != nil {
+		return err
+	}
+	if err := l.attachVolumes(config.Name, volumes); err != nil {
+		return err
+	}
+
 	// 如果是虚拟机，需要额外的配置
 	if config.InstanceType == "vm" {
 		updateProgress(40, "配置虚拟机设置...")
@@ -545,6 +572,8 @@ func (l *LXDProvider) sshCreateInstanceWithProgress(ctx context.Context, config
 		// SSH密码设置失败也不应该阻止实例创建，记录错误即可
 		global.APP_LOG.Warn("配置SSH密码失败", zap.Error(err))
 	}
+	l.configureInstanceDNS(config)
+	l.configureInstanceHostname(config)
 
 	updateProgress(100, "LXD实例创建完成")
 	global.APP_LOG.Info("LXD实例创建成功", zap.String("name", config.Name))
@@ -621,7 +650,7 @@ func (l *LXDProvider) sshRestartInstance(ctx context.Context, id string) error {
 }
 
 func (l *LXDProvider) sshDeleteInstance(ctx context.Context, id string) error {
-	output, err := l.sshClient.Execute(fmt.Sprintf("lxc delete %s --force", id))
+	output, err := l.sshClient.Execute(l.deleteInstanceCommand(id))
 	if err != nil {
 		// 检查是否是实例不存在的错误
 		if strings.Contains(output, "Instance not found") || strings.Contains(output, "not found") {
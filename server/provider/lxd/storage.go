@@ -0,0 +1,66 @@
+package lxd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"oneclickvirt/provider"
+)
+
+// lxdStoragePool lxc storage list --format json 的输出结构
+type lxdStoragePool struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Driver      string   `json:"driver"`
+	Status      string   `json:"status"`
+	UsedBy      []string `json:"used_by"`
+}
+
+// ListStoragePools 列出LXD存储池
+func (l *LXDProvider) ListStoragePools(ctx context.Context) ([]provider.StoragePool, error) {
+	if !l.connected || l.sshClient == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+
+	output, err := l.sshClient.Execute("lxc storage list --format json")
+	if err != nil {
+		return nil, fmt.Errorf("获取存储池列表失败: %w", err)
+	}
+
+	var pools []lxdStoragePool
+	if err := json.Unmarshal([]byte(strings.TrimSpace(output)), &pools); err != nil {
+		return nil, fmt.Errorf("解析存储池列表失败: %w", err)
+	}
+
+	result := make([]provider.StoragePool, 0, len(pools))
+	for _, pool := range pools {
+		result = append(result, provider.StoragePool{
+			Name:        pool.Name,
+			Driver:      pool.Driver,
+			Status:      pool.Status,
+			Description: pool.Description,
+			UsedBy:      len(pool.UsedBy),
+		})
+	}
+	return result, nil
+}
+
+// validateStoragePool 创建实例前校验指定的存储池是否存在，未指定时使用默认存储池
+func (l *LXDProvider) validateStoragePool(ctx context.Context, pool string) error {
+	if pool == "" {
+		return nil
+	}
+
+	pools, err := l.ListStoragePools(ctx)
+	if err != nil {
+		return err
+	}
+	for _, p := range pools {
+		if p.Name == pool {
+			return nil
+		}
+	}
+	return fmt.Errorf("存储池 %s 不存在", pool)
+}
@@ -0,0 +1,83 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// MaxMemorySwapMB 单个实例允许配置的swap大小上限（MB）
+const MaxMemorySwapMB = 64 * 1024
+
+var memorySizePattern = regexp.MustCompile(`^(\d+)\s*([kKmMgG]?)[iI]?[bB]?$`)
+
+// ValidateMemorySwap 校验实例的swap大小（MB），nil表示沿用Provider默认行为，0表示禁用swap
+func ValidateMemorySwap(swapMB *int64) error {
+	if swapMB == nil {
+		return nil
+	}
+	if *swapMB < 0 {
+		return fmt.Errorf("swap大小不能为负数")
+	}
+	if *swapMB > MaxMemorySwapMB {
+		return fmt.Errorf("swap大小不能超过%dMB", MaxMemorySwapMB)
+	}
+	return nil
+}
+
+// ParseMemoryMB 将 512m、1g、1024MB 等内存大小解析为MB，不带单位时按MB处理
+func ParseMemoryMB(memory string) (int64, error) {
+	match := memorySizePattern.FindStringSubmatch(strings.TrimSpace(memory))
+	if match == nil {
+		return 0, fmt.Errorf("内存大小格式无效: %s", memory)
+	}
+	size, err := strconv.ParseInt(match[1], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("内存大小格式无效: %s", memory)
+	}
+	switch strings.ToLower(match[2]) {
+	case "k":
+		return size / 1024, nil
+	case "g":
+		return size * 1024, nil
+	default:
+		return size, nil
+	}
+}
+
+// DockerMemorySwapLimit 计算 docker --memory-swap 参数，该参数为内存与swap之和，Docker要求其不小于 --memory
+// swapMB 为0时与内存相同，即禁用swap
+func DockerMemorySwapLimit(memory string, swapMB int64) (string, error) {
+	if err := ValidateMemorySwap(&swapMB); err != nil {
+		return "", err
+	}
+	memoryMB, err := ParseMemoryMB(memory)
+	if err != nil {
+		return "", err
+	}
+	if memoryMB <= 0 {
+		return "", fmt.Errorf("设置swap需要同时设置内存限制")
+	}
+	return fmt.Sprintf("%dm", memoryMB+swapMB), nil
+}
+
+// LXCMemorySwapEnabled 返回LXD/Incus容器是否启用 limits.memory.swap
+// LXC只能开关swap而不能限制swap大小，指定了swap大小时大于0即启用；未指定时沿用Provider的内存交换配置，默认启用
+func LXCMemorySwapEnabled(config InstanceConfig) bool {
+	if config.MemorySwapMB != nil {
+		return *config.MemorySwapMB > 0
+	}
+	if config.MemorySwap != nil {
+		return *config.MemorySwap
+	}
+	return true
+}
+
+// ProxmoxContainerSwapMB 返回Proxmox容器的swap大小（MB），未指定时使用默认的128MB
+func ProxmoxContainerSwapMB(config InstanceConfig) int64 {
+	if config.MemorySwapMB != nil {
+		return *config.MemorySwapMB
+	}
+	return 128
+}
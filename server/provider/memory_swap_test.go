@@ -0,0 +1,72 @@
+package provider
+
+import "testing"
+
+func int64Ptr(v int64) *int64 {
+	return &v
+}
+
+// TestDockerMemorySwapLimit 测试 --memory-swap 按内存与swap之和计算
+func TestDockerMemorySwapLimit(t *testing.T) {
+	cases := []struct {
+		memory   string
+		swapMB   int64
+		expected string
+	}{
+		{"512m", 256, "768m"},
+		{"1024MB", 0, "1024m"},
+		{"1g", 1024, "2048m"},
+		{"2GiB", 512, "2560m"},
+	}
+	for _, c := range cases {
+		got, err := DockerMemorySwapLimit(c.memory, c.swapMB)
+		if err != nil {
+			t.Fatalf("%s + %dMB 不应报错: %v", c.memory, c.swapMB, err)
+		}
+		if got != c.expected {
+			t.Fatalf("%s + %dMB 期望 %s，实际 %s", c.memory, c.swapMB, c.expected, got)
+		}
+	}
+
+	invalid := []struct {
+		memory string
+		swapMB int64
+	}{
+		{"", 256},
+		{"abc", 256},
+		{"512m", -1},
+		{"512m", MaxMemorySwapMB + 1},
+	}
+	for _, c := range invalid {
+		if _, err := DockerMemorySwapLimit(c.memory, c.swapMB); err == nil {
+			t.Fatalf("期望校验失败: %s + %dMB", c.memory, c.swapMB)
+		}
+	}
+}
+
+// TestLXCMemorySwapEnabled 测试swap大小优先于Provider的内存交换开关
+func TestLXCMemorySwapEnabled(t *testing.T) {
+	disabled := false
+	if !LXCMemorySwapEnabled(InstanceConfig{}) {
+		t.Fatal("未配置时应默认启用swap")
+	}
+	if LXCMemorySwapEnabled(InstanceConfig{MemorySwap: &disabled}) {
+		t.Fatal("Provider禁用swap时不应启用")
+	}
+	if !LXCMemorySwapEnabled(InstanceConfig{MemorySwap: &disabled, MemorySwapMB: int64Ptr(512)}) {
+		t.Fatal("指定了swap大小时应启用swap")
+	}
+	if LXCMemorySwapEnabled(InstanceConfig{MemorySwapMB: int64Ptr(0)}) {
+		t.Fatal("swap大小为0时应禁用swap")
+	}
+}
+
+// TestProxmoxContainerSwapMB 测试Proxmox容器swap大小的默认值
+func TestProxmoxContainerSwapMB(t *testing.T) {
+	if got := ProxmoxContainerSwapMB(InstanceConfig{}); got != 128 {
+		t.Fatalf("默认swap应为128MB，实际 %d", got)
+	}
+	if got := ProxmoxContainerSwapMB(InstanceConfig{MemorySwapMB: int64Ptr(0)}); got != 0 {
+		t.Fatalf("swap应为0，实际 %d", got)
+	}
+}
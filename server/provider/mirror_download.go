@@ -0,0 +1,76 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+
+	"oneclickvirt/global"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+)
+
+// 从镜像下载的文件类型，用于判断镜像返回的内容是否可用
+const (
+	DownloadKindRPM         = "rpm"
+	DownloadKindScript      = "script"
+	DownloadKindSystemdUnit = "systemd-unit"
+)
+
+// ValidDownloadCommand 生成校验已下载文件的命令，文件有效时退出码为0
+// 镜像可能以200状态返回HTML错误页，curl成功并不代表文件可用：rpm检查魔数，脚本检查shebang，服务文件检查[Unit]段
+func ValidDownloadCommand(path, kind string) string {
+	quoted := utils.ShellQuote(path)
+	check := fmt.Sprintf("[ -s %s ]", quoted)
+	switch kind {
+	case DownloadKindRPM:
+		check += fmt.Sprintf(` && [ "$(head -c 4 %s | od -An -tx1 | tr -d ' \n')" = "edabeedb" ]`, quoted)
+	case DownloadKindScript:
+		check += fmt.Sprintf(" && head -n 1 %s | grep -q '^#!'", quoted)
+	case DownloadKindSystemdUnit:
+		check += fmt.Sprintf(` && grep -q '^\[Unit\]' %s`, quoted)
+	}
+	return check
+}
+
+// MirrorURLs 为目标地址拼接CDN前缀，preferred排在最前，最后附加不经CDN的原始地址
+func MirrorURLs(prefixes []string, preferred, target string) []string {
+	urls := make([]string, 0, len(prefixes)+1)
+	if preferred != "" {
+		urls = append(urls, preferred+target)
+	}
+	for _, prefix := range prefixes {
+		if prefix != preferred {
+			urls = append(urls, prefix+target)
+		}
+	}
+	return append(urls, target)
+}
+
+// DownloadFromMirrors 依次从镜像下载文件到远程路径，下载失败或内容校验不通过时换下一个镜像
+// 返回实际使用的地址，全部镜像都失败时返回错误且不保留无效文件
+func DownloadFromMirrors(client *utils.SSHClient, urls []string, remotePath, kind string) (string, error) {
+	if len(urls) == 0 {
+		return "", errors.New("没有可用的下载地址")
+	}
+	quotedPath := utils.ShellQuote(remotePath)
+	for _, url := range urls {
+		downloadCmd := fmt.Sprintf("curl -fsSL --connect-timeout 10 --max-time 120 -o %s %s", quotedPath, utils.ShellQuote(url))
+		if _, err := client.Execute(downloadCmd); err != nil {
+			global.APP_LOG.Warn("从镜像下载文件失败，尝试下一个镜像",
+				zap.String("url", url),
+				zap.Error(err))
+			continue
+		}
+		if _, err := client.Execute(ValidDownloadCommand(remotePath, kind)); err != nil {
+			global.APP_LOG.Warn("镜像返回的文件无效，尝试下一个镜像",
+				zap.String("url", url),
+				zap.String("kind", kind))
+			client.Execute(fmt.Sprintf("rm -f %s", quotedPath))
+			continue
+		}
+		return url, nil
+	}
+	client.Execute(fmt.Sprintf("rm -f %s", quotedPath))
+	return "", fmt.Errorf("所有镜像均未能下载有效的文件: %s", remotePath)
+}
@@ -0,0 +1,65 @@
+package provider
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestValidDownloadCommand 测试下载文件校验命令能区分有效文件和镜像返回的错误页面
+func TestValidDownloadCommand(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("缺少sh，跳过")
+	}
+
+	htmlPage := "<html><body>404 Not Found</body></html>\n"
+	tests := []struct {
+		name    string
+		kind    string
+		content string
+		valid   bool
+	}{
+		{name: "rpm魔数", kind: DownloadKindRPM, content: "\xed\xab\xee\xdb\x03\x00rest", valid: true},
+		{name: "rpm错误页面", kind: DownloadKindRPM, content: htmlPage, valid: false},
+		{name: "rpm空文件", kind: DownloadKindRPM, content: "", valid: false},
+		{name: "脚本shebang", kind: DownloadKindScript, content: "#!/bin/bash\necho ok\n", valid: true},
+		{name: "脚本错误页面", kind: DownloadKindScript, content: htmlPage, valid: false},
+		{name: "服务文件", kind: DownloadKindSystemdUnit, content: "[Unit]\nDescription=x\n\n[Service]\nExecStart=/bin/true\n", valid: true},
+		{name: "服务文件错误页面", kind: DownloadKindSystemdUnit, content: htmlPage, valid: false},
+		{name: "未知类型只检查非空", kind: "", content: "data", valid: true},
+	}
+
+	dir := t.TempDir()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, "it's download")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatalf("写入测试文件失败: %v", err)
+			}
+			err := exec.Command("sh", "-c", ValidDownloadCommand(path, tt.kind)).Run()
+			if got := err == nil; got != tt.valid {
+				t.Errorf("ValidDownloadCommand() 校验结果 = %v, 期望 %v", got, tt.valid)
+			}
+		})
+	}
+}
+
+// TestMirrorURLs 测试CDN地址排序
+func TestMirrorURLs(t *testing.T) {
+	prefixes := []string{"https://cdn0/", "http://cdn1/", "http://cdn2/"}
+	target := "https://raw.example.com/a.sh"
+
+	got := MirrorURLs(prefixes, "http://cdn1/", target)
+	expected := []string{"http://cdn1/" + target, "https://cdn0/" + target, "http://cdn2/" + target, target}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("MirrorURLs() = %v, 期望 %v", got, expected)
+	}
+
+	got = MirrorURLs(prefixes, "", target)
+	expected = []string{"https://cdn0/" + target, "http://cdn1/" + target, "http://cdn2/" + target, target}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("MirrorURLs() 无可用CDN = %v, 期望 %v", got, expected)
+	}
+}
@@ -0,0 +1,184 @@
+package netfilter
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"oneclickvirt/global"
+
+	"go.uber.org/zap"
+)
+
+// iptablesBackend 使用ip6tables，规则通过netfilter-persistent或iptables-services持久化
+type iptablesBackend struct {
+	exec   Executor
+	osType string
+}
+
+func (b *iptablesBackend) Name() string { return BackendIptables }
+
+func (b *iptablesBackend) Prepare() error {
+	switch b.osType {
+	case "ubuntu", "debian":
+		b.exec.Execute("apt update -y")
+		_, err := b.exec.Execute("apt install -y netfilter-persistent iptables-persistent")
+		return err
+	case "centos", "almalinux", "rocky":
+		_, err := b.exec.Execute("yum install -y iptables-services")
+		return err
+	case "arch", "manjaro":
+		_, err := b.exec.Execute("pacman -S --noconfirm --needed iptables")
+		return err
+	default:
+		_, err := b.exec.Execute("apt install -y netfilter-persistent 2>/dev/null || yum install -y iptables-services 2>/dev/null || true")
+		return err
+	}
+}
+
+func (b *iptablesBackend) HasIPv6DNAT(destination, target string) bool {
+	_, err := b.exec.Execute(fmt.Sprintf("ip6tables -t nat -C PREROUTING -d %s -j DNAT --to-destination %s 2>/dev/null", destination, target))
+	return err == nil
+}
+
+func (b *iptablesBackend) AddIPv6DNAT(destination, target string) error {
+	if _, err := b.exec.Execute(fmt.Sprintf("ip6tables -t nat -A PREROUTING -d %s -j DNAT --to-destination %s", destination, target)); err != nil {
+		return fmt.Errorf("添加ip6tables NAT规则失败: %w", err)
+	}
+	return nil
+}
+
+func (b *iptablesBackend) Persist() error {
+	b.exec.Execute("mkdir -p /etc/iptables")
+	if _, err := b.exec.Execute("ip6tables-save > /etc/iptables/rules.v6"); err != nil {
+		return fmt.Errorf("保存ip6tables规则失败: %w", err)
+	}
+
+	// 检查netfilter-persistent是否可用
+	if _, err := b.exec.Execute("command -v netfilter-persistent"); err == nil {
+		b.exec.Execute("netfilter-persistent save")
+		b.exec.Execute("netfilter-persistent reload")
+		b.exec.Execute("service netfilter-persistent restart")
+	}
+	return nil
+}
+
+// firewalldBackend 使用firewalld直接规则，--permanent规则由firewalld自身持久化
+type firewalldBackend struct {
+	exec Executor
+}
+
+func (b *firewalldBackend) Name() string { return BackendFirewalld }
+
+func (b *firewalldBackend) Prepare() error {
+	if _, err := b.exec.Execute("command -v firewall-cmd"); err != nil {
+		if _, err := b.exec.Execute("yum install -y firewalld"); err != nil {
+			return err
+		}
+	}
+	b.exec.Execute("systemctl enable --now firewalld")
+	time.Sleep(3 * time.Second)
+	return nil
+}
+
+func (b *firewalldBackend) HasIPv6DNAT(destination, target string) bool {
+	_, err := b.exec.Execute(fmt.Sprintf("firewall-cmd --direct --query-rule ipv6 nat PREROUTING 0 -d %s -j DNAT --to-destination %s", destination, target))
+	return err == nil
+}
+
+func (b *firewalldBackend) AddIPv6DNAT(destination, target string) error {
+	if _, err := b.exec.Execute(fmt.Sprintf("firewall-cmd --permanent --direct --add-rule ipv6 nat PREROUTING 0 -d %s -j DNAT --to-destination %s", destination, target)); err != nil {
+		return fmt.Errorf("添加firewalld NAT规则失败: %w", err)
+	}
+	if _, err := b.exec.Execute("firewall-cmd --reload"); err != nil {
+		return fmt.Errorf("重新加载firewalld失败: %w", err)
+	}
+	return nil
+}
+
+func (b *firewalldBackend) Persist() error {
+	_, err := b.exec.Execute("systemctl restart firewalld")
+	return err
+}
+
+// nftablesBackend 直接使用nft
+// 规则写入独立的表，持久化时只导出该表，避免与LXD/Incus/Docker自行管理的表互相覆盖
+type nftablesBackend struct {
+	exec Executor
+}
+
+const (
+	nftTable       = "oneclickvirt_nat"
+	nftChain       = "prerouting"
+	nftRulesFile   = "/etc/nftables.d/oneclickvirt-ipv6-nat.nft"
+	nftIncludeLine = `include "` + nftRulesFile + `"`
+)
+
+func (b *nftablesBackend) Name() string { return BackendNftables }
+
+func (b *nftablesBackend) Prepare() error {
+	if _, err := b.exec.Execute(fmt.Sprintf("nft add table ip6 %s", nftTable)); err != nil {
+		return fmt.Errorf("创建nftables表失败: %w", err)
+	}
+	cmd := fmt.Sprintf("nft add chain ip6 %s %s '{ type nat hook prerouting priority dstnat; policy accept; }'", nftTable, nftChain)
+	if _, err := b.exec.Execute(cmd); err != nil {
+		// 旧版本nft不支持dstnat别名，回退为数值优先级
+		cmd = fmt.Sprintf("nft add chain ip6 %s %s '{ type nat hook prerouting priority -100; policy accept; }'", nftTable, nftChain)
+		if _, err := b.exec.Execute(cmd); err != nil {
+			return fmt.Errorf("创建nftables链失败: %w", err)
+		}
+	}
+	return nil
+}
+
+func (b *nftablesBackend) HasIPv6DNAT(destination, target string) bool {
+	cmd := fmt.Sprintf("nft list chain ip6 %s %s 2>/dev/null | grep -qw 'ip6 daddr %s dnat to %s'", nftTable, nftChain, destination, target)
+	_, err := b.exec.Execute(cmd)
+	return err == nil
+}
+
+func (b *nftablesBackend) AddIPv6DNAT(destination, target string) error {
+	cmd := fmt.Sprintf("nft add rule ip6 %s %s ip6 daddr %s dnat to %s", nftTable, nftChain, destination, target)
+	if _, err := b.exec.Execute(cmd); err != nil {
+		return fmt.Errorf("添加nftables NAT规则失败: %w", err)
+	}
+	return nil
+}
+
+func (b *nftablesBackend) Persist() error {
+	// Debian系使用/etc/nftables.conf，RHEL系使用/etc/sysconfig/nftables.conf
+	confFile := "/etc/nftables.conf"
+	if _, err := b.exec.Execute("[ -f /etc/sysconfig/nftables.conf ]"); err == nil {
+		confFile = "/etc/sysconfig/nftables.conf"
+	}
+
+	b.exec.Execute("mkdir -p /etc/nftables.d")
+	// 先删除再重建表，保证重复加载配置时规则不会重复
+	saveCmd := fmt.Sprintf("{ echo 'table ip6 %s'; echo 'delete table ip6 %s'; nft list table ip6 %s; } > %s",
+		nftTable, nftTable, nftTable, nftRulesFile)
+	if _, err := b.exec.Execute(saveCmd); err != nil {
+		return fmt.Errorf("保存nftables规则失败: %w", err)
+	}
+
+	includeCmd := fmt.Sprintf("touch %s && (grep -qF '%s' %s || echo '%s' >> %s)",
+		confFile, nftIncludeLine, confFile, nftIncludeLine, confFile)
+	if _, err := b.exec.Execute(includeCmd); err != nil {
+		return fmt.Errorf("写入nftables配置失败: %w", err)
+	}
+
+	if _, err := b.exec.Execute("systemctl enable nftables"); err != nil {
+		global.APP_LOG.Warn("启用nftables服务失败，规则可能在重启后丢失",
+			zap.String("config", confFile),
+			zap.Error(err))
+	}
+	return nil
+}
+
+// isNftWrapper 判断iptables是否为iptables-nft兼容层
+func isNftWrapper(exec Executor) bool {
+	output, err := exec.Execute("iptables -V 2>/dev/null")
+	if err != nil {
+		return false
+	}
+	return strings.Contains(output, "nf_tables")
+}
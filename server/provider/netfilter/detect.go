@@ -0,0 +1,106 @@
+package netfilter
+
+import (
+	"fmt"
+	"strings"
+
+	"oneclickvirt/global"
+
+	"go.uber.org/zap"
+)
+
+// DetectOS 检测宿主机操作系统类型并标准化名称
+func DetectOS(exec Executor) (string, error) {
+	output, err := exec.Execute("cat /etc/os-release | grep ^ID= | cut -d= -f2 | tr -d '\"'")
+	if err != nil {
+		return "", fmt.Errorf("检测操作系统失败: %w", err)
+	}
+
+	osType := strings.TrimSpace(output)
+	global.APP_LOG.Info("检测到操作系统类型", zap.String("os", osType))
+
+	switch osType {
+	case "ubuntu", "pop", "neon", "zorin":
+		return "ubuntu", nil
+	case "debian", "kali":
+		return "debian", nil
+	case "centos", "almalinux", "rocky":
+		return osType, nil
+	case "arch", "archarm", "endeavouros", "blendos", "garuda":
+		return "arch", nil
+	case "manjaro", "manjaro-arm":
+		return "manjaro", nil
+	default:
+		return osType, nil
+	}
+}
+
+// isRHELFamily 判断是否为默认使用firewalld的RHEL系发行版
+func isRHELFamily(osType string) bool {
+	return osType == "centos" || osType == "almalinux" || osType == "rocky"
+}
+
+// Detect 检测宿主机当前生效的防火墙后端
+// 优先级：正在运行的firewalld > RHEL系（默认firewalld）> nft（iptables为nft兼容层或不存在时）> iptables
+func Detect(exec Executor, osType string) Backend {
+	backend := detect(exec, osType)
+	global.APP_LOG.Info("检测到防火墙后端",
+		zap.String("os", osType),
+		zap.String("backend", backend.Name()))
+	return backend
+}
+
+func detect(exec Executor, osType string) Backend {
+	if _, err := exec.Execute("firewall-cmd --state 2>/dev/null"); err == nil {
+		return &firewalldBackend{exec: exec}
+	}
+
+	if isRHELFamily(osType) {
+		if _, err := exec.Execute("command -v dnf || command -v yum"); err == nil {
+			return &firewalldBackend{exec: exec}
+		}
+	}
+
+	if _, err := exec.Execute("command -v nft"); err == nil {
+		if _, err := exec.Execute("command -v ip6tables"); err != nil || isNftWrapper(exec) {
+			return &nftablesBackend{exec: exec}
+		}
+	}
+
+	return &iptablesBackend{exec: exec, osType: osType}
+}
+
+// FindFreeIPv6 在宿主机IPv6子网中查找可用于映射的地址
+// 跳过容器自身地址、已绑定到网卡的地址、可ping通的地址以及已存在DNAT规则的地址
+func FindFreeIPv6(exec Executor, backend Backend, subnetPrefix, interfaceName, containerIPv6 string) (string, error) {
+	for idx := 3; idx <= 65535; idx++ {
+		testIPv6 := fmt.Sprintf("%s%d", subnetPrefix, idx)
+
+		// 跳过容器本身的地址
+		if testIPv6 == containerIPv6 {
+			continue
+		}
+
+		// 检查地址是否已被使用
+		if _, err := exec.Execute(fmt.Sprintf("ip -6 addr show dev %s | grep -qw %s", interfaceName, testIPv6)); err == nil {
+			continue
+		}
+
+		// 检查地址是否可以ping通，能ping通说明已被占用
+		if _, err := exec.Execute(fmt.Sprintf("ping6 -c1 -w1 -q %s", testIPv6)); err == nil {
+			global.APP_LOG.Debug("IPv6地址已被占用", zap.String("ipv6", testIPv6))
+			continue
+		}
+
+		if backend.HasIPv6DNAT(testIPv6, containerIPv6) {
+			continue
+		}
+
+		global.APP_LOG.Info("找到可用IPv6地址",
+			zap.String("ipv6", testIPv6),
+			zap.String("backend", backend.Name()))
+		return testIPv6, nil
+	}
+
+	return "", fmt.Errorf("无可用IPv6地址，不进行自动映射")
+}
@@ -0,0 +1,27 @@
+package netfilter
+
+// Executor 在宿主机上执行命令，utils.SSHClient实现了该接口
+type Executor interface {
+	Execute(command string) (string, error)
+}
+
+// Backend IPv6 DNAT规则的防火墙后端
+// LXD和Incus在iptables映射模式下通过该接口添加、检查并持久化NAT规则
+type Backend interface {
+	// Name 后端名称：iptables、firewalld、nftables
+	Name() string
+	// Prepare 安装所需软件包并初始化表和链
+	Prepare() error
+	// HasIPv6DNAT 检查目标地址到容器地址的DNAT规则是否已存在
+	HasIPv6DNAT(destination, target string) bool
+	// AddIPv6DNAT 添加目标地址到容器地址的DNAT规则
+	AddIPv6DNAT(destination, target string) error
+	// Persist 持久化当前规则，保证重启后仍然生效
+	Persist() error
+}
+
+const (
+	BackendIptables  = "iptables"
+	BackendFirewalld = "firewalld"
+	BackendNftables  = "nftables"
+)
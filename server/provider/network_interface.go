@@ -0,0 +1,193 @@
+package provider
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// MaxExtraNetworkInterfaces 单个实例允许附加的额外网卡数量上限
+const MaxExtraNetworkInterfaces = 4
+
+// FirstExtraInterfaceIndex LXD/Incus/Proxmox额外网卡的起始编号，eth1保留给独立IPv6网卡
+const FirstExtraInterfaceIndex = 2
+
+var networkNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.\-]{0,63}$`)
+
+// ValidateNetworkInterfaces 校验额外网卡配置的格式，不检查网络是否存在
+func ValidateNetworkInterfaces(nics []NetworkInterfaceConfig) error {
+	if len(nics) > MaxExtraNetworkInterfaces {
+		return fmt.Errorf("额外网卡数量不能超过%d个", MaxExtraNetworkInterfaces)
+	}
+
+	seenIPs := make(map[string]bool)
+	seenMACs := make(map[string]bool)
+	for idx, nic := range nics {
+		if !networkNamePattern.MatchString(nic.Network) {
+			return fmt.Errorf("第%d块额外网卡的网络名称无效: %s", idx+1, nic.Network)
+		}
+		if nic.IPAddress != "" {
+			ip, _, err := SplitInterfaceAddress(nic.IPAddress)
+			if err != nil {
+				return fmt.Errorf("第%d块额外网卡%v", idx+1, err)
+			}
+			if seenIPs[ip.String()] {
+				return fmt.Errorf("额外网卡IP地址重复: %s", ip)
+			}
+			seenIPs[ip.String()] = true
+		}
+		if nic.MACAddress != "" {
+			mac, err := net.ParseMAC(nic.MACAddress)
+			if err != nil || len(mac) != 6 {
+				return fmt.Errorf("第%d块额外网卡的MAC地址无效: %s", idx+1, nic.MACAddress)
+			}
+			if mac[0]&1 == 1 {
+				return fmt.Errorf("第%d块额外网卡的MAC地址不能是组播地址: %s", idx+1, nic.MACAddress)
+			}
+			if seenMACs[mac.String()] {
+				return fmt.Errorf("额外网卡MAC地址重复: %s", mac)
+			}
+			seenMACs[mac.String()] = true
+		}
+	}
+	return nil
+}
+
+// SplitInterfaceAddress 解析 "IP" 或 "IP/前缀长度" 格式的地址，未指定前缀长度时prefix为-1
+func SplitInterfaceAddress(addr string) (net.IP, int, error) {
+	addr = strings.TrimSpace(addr)
+	ipPart, prefixPart, hasPrefix := strings.Cut(addr, "/")
+	ip := net.ParseIP(ipPart)
+	if ip == nil || ip.IsUnspecified() || ip.IsLoopback() || ip.IsMulticast() {
+		return nil, 0, fmt.Errorf("IP地址无效: %s", addr)
+	}
+	if !hasPrefix {
+		return ip, -1, nil
+	}
+
+	maxPrefix := 32
+	if ip.To4() == nil {
+		maxPrefix = 128
+	}
+	prefix, err := strconv.Atoi(prefixPart)
+	if err != nil || prefix < 1 || prefix > maxPrefix {
+		return nil, 0, fmt.Errorf("IP地址前缀长度无效: %s", addr)
+	}
+	return ip, prefix, nil
+}
+
+// InterfaceAddressCIDR 返回带前缀长度的地址，未指定前缀长度时IPv4默认/24、IPv6默认/64
+func InterfaceAddressCIDR(addr string) (string, error) {
+	ip, prefix, err := SplitInterfaceAddress(addr)
+	if err != nil {
+		return "", err
+	}
+	if prefix < 0 {
+		prefix = 24
+		if ip.To4() == nil {
+			prefix = 64
+		}
+	}
+	return fmt.Sprintf("%s/%d", ip, prefix), nil
+}
+
+// ValidateNetworkName 校验网络名称格式，网络名称会拼接到命令行中
+func ValidateNetworkName(name string) error {
+	if !networkNamePattern.MatchString(name) {
+		return fmt.Errorf("网络名称无效: %s", name)
+	}
+	return nil
+}
+
+// ValidateNetworkCreateConfig 校验创建网络的参数，子网只支持IPv4
+func ValidateNetworkCreateConfig(cfg NetworkCreateConfig) error {
+	if err := ValidateNetworkName(cfg.Name); err != nil {
+		return err
+	}
+	if cfg.Subnet == "" {
+		if cfg.Gateway != "" {
+			return fmt.Errorf("指定网关时必须同时指定子网")
+		}
+		return nil
+	}
+	_, subnet, err := net.ParseCIDR(cfg.Subnet)
+	if err != nil || subnet.IP.To4() == nil {
+		return fmt.Errorf("子网无效: %s", cfg.Subnet)
+	}
+	if ones, _ := subnet.Mask.Size(); ones > 30 {
+		return fmt.Errorf("子网过小: %s", cfg.Subnet)
+	}
+	if cfg.Gateway != "" {
+		gateway := net.ParseIP(cfg.Gateway)
+		if gateway == nil || !subnet.Contains(gateway) || gateway.Equal(subnet.IP) {
+			return fmt.Errorf("网关 %s 不在子网 %s 内", cfg.Gateway, cfg.Subnet)
+		}
+	}
+	return nil
+}
+
+// ParseAllowedNetworks 解析以逗号或换行分隔的允许附加的网络列表
+func ParseAllowedNetworks(value string) []string {
+	var networks []string
+	for _, item := range strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == '\n' || r == '\r'
+	}) {
+		if item = strings.TrimSpace(item); item != "" {
+			networks = append(networks, item)
+		}
+	}
+	return networks
+}
+
+// NormalizeAllowedNetworks 校验并规范化允许附加的网络列表，返回逗号分隔的形式
+func NormalizeAllowedNetworks(value string) (string, error) {
+	networks := ParseAllowedNetworks(value)
+	for _, name := range networks {
+		if !networkNamePattern.MatchString(name) {
+			return "", fmt.Errorf("网络名称无效: %s", name)
+		}
+	}
+	return strings.Join(networks, ","), nil
+}
+
+// CheckPrimaryNetworkAllowed 校验实例主网卡使用的网络在允许列表中，未指定网络时使用节点默认网络
+func CheckPrimaryNetworkAllowed(network, allowed string) error {
+	if network == "" {
+		return nil
+	}
+	if err := ValidateNetworkName(network); err != nil {
+		return err
+	}
+	for _, name := range ParseAllowedNetworks(allowed) {
+		if name == network {
+			return nil
+		}
+	}
+	return fmt.Errorf("该节点不允许使用网络 %s", network)
+}
+
+// CheckNetworksAllowed 校验额外网卡引用的网络都在允许列表中，允许列表为空时不允许附加额外网卡
+func CheckNetworksAllowed(nics []NetworkInterfaceConfig, allowed string) error {
+	if len(nics) == 0 {
+		return nil
+	}
+	allowedNetworks := ParseAllowedNetworks(allowed)
+	if len(allowedNetworks) == 0 {
+		return fmt.Errorf("该节点未开放额外网卡")
+	}
+	for _, nic := range nics {
+		found := false
+		for _, name := range allowedNetworks {
+			if name == nic.Network {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("该节点不允许附加网络 %s", nic.Network)
+		}
+	}
+	return nil
+}
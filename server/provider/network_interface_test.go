@@ -0,0 +1,107 @@
+package provider
+
+import "testing"
+
+// TestValidateNetworkInterfaces 测试额外网卡配置的格式校验
+func TestValidateNetworkInterfaces(t *testing.T) {
+	tests := []struct {
+		name        string
+		nics        []NetworkInterfaceConfig
+		expectError bool
+	}{
+		{name: "空列表"},
+		{name: "仅网络名称", nics: []NetworkInterfaceConfig{{Network: "vmbr2"}}},
+		{name: "静态IP和MAC", nics: []NetworkInterfaceConfig{{Network: "lan", IPAddress: "192.168.100.10/24", MACAddress: "02:00:00:aa:bb:cc"}}},
+		{name: "网络名称含空格", nics: []NetworkInterfaceConfig{{Network: "br0; reboot"}}, expectError: true},
+		{name: "IP无效", nics: []NetworkInterfaceConfig{{Network: "lan", IPAddress: "300.1.1.1"}}, expectError: true},
+		{name: "前缀长度无效", nics: []NetworkInterfaceConfig{{Network: "lan", IPAddress: "10.0.0.2/33"}}, expectError: true},
+		{name: "组播MAC", nics: []NetworkInterfaceConfig{{Network: "lan", MACAddress: "01:00:5e:00:00:01"}}, expectError: true},
+		{name: "IP重复", nics: []NetworkInterfaceConfig{{Network: "a", IPAddress: "10.0.0.2"}, {Network: "b", IPAddress: "10.0.0.2/24"}}, expectError: true},
+		{name: "数量超限", nics: make([]NetworkInterfaceConfig, MaxExtraNetworkInterfaces+1), expectError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateNetworkInterfaces(tt.nics)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("期望错误=%v，实际 %v", tt.expectError, err)
+			}
+		})
+	}
+}
+
+// TestInterfaceAddressCIDR 测试未指定前缀长度时的默认值
+func TestInterfaceAddressCIDR(t *testing.T) {
+	cases := map[string]string{
+		"192.168.1.5":    "192.168.1.5/24",
+		"192.168.1.5/16": "192.168.1.5/16",
+		"fd00::5":        "fd00::5/64",
+	}
+	for input, want := range cases {
+		got, err := InterfaceAddressCIDR(input)
+		if err != nil || got != want {
+			t.Fatalf("InterfaceAddressCIDR(%q) = %q, %v，期望 %q", input, got, err, want)
+		}
+	}
+}
+
+// TestCheckNetworksAllowed 测试额外网卡网络的允许列表校验
+func TestCheckNetworksAllowed(t *testing.T) {
+	nics := []NetworkInterfaceConfig{{Network: "lan"}}
+	if err := CheckNetworksAllowed(nil, ""); err != nil {
+		t.Fatalf("未附加额外网卡时不应校验: %v", err)
+	}
+	if err := CheckNetworksAllowed(nics, ""); err == nil {
+		t.Fatal("允许列表为空时应拒绝")
+	}
+	if err := CheckNetworksAllowed(nics, "wan, lan"); err != nil {
+		t.Fatalf("网络在允许列表中: %v", err)
+	}
+	if err := CheckNetworksAllowed(nics, "wan"); err == nil {
+		t.Fatal("网络不在允许列表中应拒绝")
+	}
+
+	normalized, err := NormalizeAllowedNetworks(" lan ,\nvmbr2,")
+	if err != nil || normalized != "lan,vmbr2" {
+		t.Fatalf("规范化结果错误: %q, %v", normalized, err)
+	}
+}
+
+// TestValidateNetworkCreateConfig 测试创建网络参数的校验
+func TestValidateNetworkCreateConfig(t *testing.T) {
+	cases := []struct {
+		name        string
+		cfg         NetworkCreateConfig
+		expectError bool
+	}{
+		{name: "仅名称", cfg: NetworkCreateConfig{Name: "team-a"}},
+		{name: "子网和网关", cfg: NetworkCreateConfig{Name: "team-a", Subnet: "172.30.0.0/24", Gateway: "172.30.0.1"}},
+		{name: "名称含特殊字符", cfg: NetworkCreateConfig{Name: "a;rm -rf /"}, expectError: true},
+		{name: "IPv6子网", cfg: NetworkCreateConfig{Name: "v6", Subnet: "fd00::/64"}, expectError: true},
+		{name: "子网过小", cfg: NetworkCreateConfig{Name: "tiny", Subnet: "172.30.0.0/31"}, expectError: true},
+		{name: "网关不在子网内", cfg: NetworkCreateConfig{Name: "gw", Subnet: "172.30.0.0/24", Gateway: "172.31.0.1"}, expectError: true},
+		{name: "网关为网络地址", cfg: NetworkCreateConfig{Name: "gw", Subnet: "172.30.0.0/24", Gateway: "172.30.0.0"}, expectError: true},
+		{name: "只有网关", cfg: NetworkCreateConfig{Name: "gw", Gateway: "172.30.0.1"}, expectError: true},
+	}
+	for _, tc := range cases {
+		err := ValidateNetworkCreateConfig(tc.cfg)
+		if tc.expectError && err == nil {
+			t.Errorf("%s: 期望返回错误", tc.name)
+		}
+		if !tc.expectError && err != nil {
+			t.Errorf("%s: 不期望错误，得到 %v", tc.name, err)
+		}
+	}
+}
+
+// TestCheckPrimaryNetworkAllowed 测试主网卡网络的允许列表校验
+func TestCheckPrimaryNetworkAllowed(t *testing.T) {
+	if err := CheckPrimaryNetworkAllowed("", ""); err != nil {
+		t.Fatalf("未指定网络时不应校验: %v", err)
+	}
+	if err := CheckPrimaryNetworkAllowed("team-a", "team-a,lan"); err != nil {
+		t.Fatalf("网络在允许列表中: %v", err)
+	}
+	if err := CheckPrimaryNetworkAllowed("team-b", "team-a"); err == nil {
+		t.Fatal("网络不在允许列表中应拒绝")
+	}
+}
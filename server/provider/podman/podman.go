@@ -0,0 +1,71 @@
+package podman
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"oneclickvirt/global"
+	"oneclickvirt/provider"
+	"oneclickvirt/provider/docker"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+)
+
+// PodmanProvider Podman容器Provider
+// Podman命令行与Docker基本兼容，实例管理逻辑复用Docker Provider，只处理两者的差异部分
+type PodmanProvider struct {
+	*docker.DockerProvider
+	rootless bool // 是否为rootless模式（rootless模式下容器没有宿主机veth接口）
+}
+
+func NewPodmanProvider() provider.Provider {
+	return &PodmanProvider{
+		DockerProvider: docker.NewCompatibleProvider("podman"),
+	}
+}
+
+func (p *PodmanProvider) GetType() string {
+	return "podman"
+}
+
+func (p *PodmanProvider) Connect(ctx context.Context, config provider.NodeConfig) error {
+	if err := p.DockerProvider.Connect(ctx, config); err != nil {
+		return err
+	}
+
+	// 检测是否为rootless模式，rootless网络（slirp4netns/pasta）不会分配桥接IP和veth接口
+	output, err := p.ExecuteSSHCommand(ctx, "podman info --format '{{.Host.Security.Rootless}}'")
+	if err != nil {
+		global.APP_LOG.Warn("检测Podman运行模式失败，按rootful模式处理",
+			zap.String("host", utils.TruncateString(config.Host, 32)),
+			zap.Error(err))
+		return nil
+	}
+	p.rootless = strings.TrimSpace(output) == "true"
+
+	global.APP_LOG.Info("Podman provider连接成功",
+		zap.String("host", utils.TruncateString(config.Host, 32)),
+		zap.Bool("rootless", p.rootless))
+
+	return nil
+}
+
+// IsRootless 是否为rootless模式
+func (p *PodmanProvider) IsRootless() bool {
+	return p.rootless
+}
+
+// GetVethInterfaceName 获取容器对应的宿主机veth接口名称
+func (p *PodmanProvider) GetVethInterfaceName(ctx context.Context, instanceName string) (string, error) {
+	if p.rootless {
+		return "", fmt.Errorf("rootless模式下容器 %s 没有宿主机veth接口", instanceName)
+	}
+
+	return p.DockerProvider.GetVethInterfaceName(ctx, instanceName)
+}
+
+func init() {
+	provider.RegisterProvider("podman", NewPodmanProvider)
+}
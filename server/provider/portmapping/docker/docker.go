@@ -283,6 +283,14 @@ func (d *DockerPortMapping) getPublicIP(providerInfo *provider.Provider) string
 	return endpoint
 }
 
+// containerCLI 返回节点使用的容器运行时命令，Podman节点与Docker共用本实现
+func containerCLI(providerInfo *provider.Provider) string {
+	if providerInfo != nil && providerInfo.Type == "podman" {
+		return "podman"
+	}
+	return "docker"
+}
+
 // createDockerPortMapping 创建Docker原生端口映射
 func (d *DockerPortMapping) createDockerPortMapping(ctx context.Context, instance *provider.Instance, hostPort, guestPort int, protocol string, providerInfo *provider.Provider) error {
 	global.APP_LOG.Info("Creating Docker native port mapping",
@@ -309,7 +317,8 @@ func (d *DockerPortMapping) createDockerPortMapping(ctx context.Context, instanc
 		zap.String("providerName", providerInfo.Name))
 
 	// 检查容器是否存在
-	checkCmd := fmt.Sprintf("docker inspect %s --format '{{.State.Status}}'", instance.Name)
+	cli := containerCLI(providerInfo)
+	checkCmd := fmt.Sprintf("%s inspect %s --format '{{.State.Status}}'", cli, instance.Name)
 	status, err := providerInstance.ExecuteSSHCommand(ctx, checkCmd)
 	if err != nil {
 		return fmt.Errorf("failed to check container status: %v", err)
@@ -320,31 +329,31 @@ func (d *DockerPortMapping) createDockerPortMapping(ctx context.Context, instanc
 	// Docker不支持动态端口映射，需要重新创建容器
 	if strings.Contains(status, "running") || strings.Contains(status, "exited") {
 		// 获取现有容器的配置
-		inspectCmd := fmt.Sprintf("docker inspect %s --format '{{.Config.Image}} {{.Config.Cmd}} {{.HostConfig.Memory}} {{.HostConfig.NanoCpus}}'", instance.Name)
+		inspectCmd := fmt.Sprintf("%s inspect %s --format '{{.Config.Image}} {{.Config.Cmd}} {{.HostConfig.Memory}} {{.HostConfig.NanoCpus}}'", cli, instance.Name)
 		configInfo, err := providerInstance.ExecuteSSHCommand(ctx, inspectCmd)
 		if err != nil {
 			return fmt.Errorf("failed to get container config: %v", err)
 		}
 
 		// 获取现有的端口映射
-		portsCmd := fmt.Sprintf("docker port %s", instance.Name)
+		portsCmd := fmt.Sprintf("%s port %s", cli, instance.Name)
 		existingPorts, _ := providerInstance.ExecuteSSHCommand(ctx, portsCmd)
 
 		// 停止并删除现有容器
-		stopCmd := fmt.Sprintf("docker stop %s", instance.Name)
+		stopCmd := fmt.Sprintf("%s stop %s", cli, instance.Name)
 		_, err = providerInstance.ExecuteSSHCommand(ctx, stopCmd)
 		if err != nil {
 			global.APP_LOG.Warn("Failed to stop container", zap.Error(err))
 		}
 
-		removeCmd := fmt.Sprintf("docker rm %s", instance.Name)
+		removeCmd := fmt.Sprintf("%s rm %s", cli, instance.Name)
 		_, err = providerInstance.ExecuteSSHCommand(ctx, removeCmd)
 		if err != nil {
 			return fmt.Errorf("failed to remove container: %v", err)
 		}
 
 		// 重新创建容器，包含新的端口映射
-		recreateCmd := d.buildDockerRunCommand(instance, configInfo, existingPorts, hostPort, guestPort, protocol)
+		recreateCmd := d.buildDockerRunCommand(cli, instance, configInfo, existingPorts, hostPort, guestPort, protocol)
 		_, err = providerInstance.ExecuteSSHCommand(ctx, recreateCmd)
 		if err != nil {
 			return fmt.Errorf("failed to recreate container with port mapping: %v", err)
@@ -375,7 +384,8 @@ func (d *DockerPortMapping) createDockerPortMappingWithTempSSH(ctx context.Conte
 	defer sshClient.Close()
 
 	// 检查容器是否存在
-	checkCmd := fmt.Sprintf("docker inspect %s --format '{{.State.Status}}'", instance.Name)
+	cli := containerCLI(providerInfo)
+	checkCmd := fmt.Sprintf("%s inspect %s --format '{{.State.Status}}'", cli, instance.Name)
 	status, err := sshClient.Execute(checkCmd)
 	if err != nil {
 		return fmt.Errorf("failed to check container status: %v", err)
@@ -386,31 +396,31 @@ func (d *DockerPortMapping) createDockerPortMappingWithTempSSH(ctx context.Conte
 	// Docker不支持动态端口映射，需要重新创建容器
 	if strings.Contains(status, "running") || strings.Contains(status, "exited") {
 		// 获取现有容器的配置
-		inspectCmd := fmt.Sprintf("docker inspect %s --format '{{.Config.Image}} {{.Config.Cmd}} {{.HostConfig.Memory}} {{.HostConfig.NanoCpus}}'", instance.Name)
+		inspectCmd := fmt.Sprintf("%s inspect %s --format '{{.Config.Image}} {{.Config.Cmd}} {{.HostConfig.Memory}} {{.HostConfig.NanoCpus}}'", cli, instance.Name)
 		configInfo, err := sshClient.Execute(inspectCmd)
 		if err != nil {
 			return fmt.Errorf("failed to get container config: %v", err)
 		}
 
 		// 获取现有的端口映射
-		portsCmd := fmt.Sprintf("docker port %s", instance.Name)
+		portsCmd := fmt.Sprintf("%s port %s", cli, instance.Name)
 		existingPorts, _ := sshClient.Execute(portsCmd)
 
 		// 停止并删除现有容器
-		stopCmd := fmt.Sprintf("docker stop %s", instance.Name)
+		stopCmd := fmt.Sprintf("%s stop %s", cli, instance.Name)
 		_, err = sshClient.Execute(stopCmd)
 		if err != nil {
 			global.APP_LOG.Warn("Failed to stop container", zap.Error(err))
 		}
 
-		removeCmd := fmt.Sprintf("docker rm %s", instance.Name)
+		removeCmd := fmt.Sprintf("%s rm %s", cli, instance.Name)
 		_, err = sshClient.Execute(removeCmd)
 		if err != nil {
 			return fmt.Errorf("failed to remove container: %v", err)
 		}
 
 		// 重新创建容器，包含新的端口映射
-		recreateCmd := d.buildDockerRunCommand(instance, configInfo, existingPorts, hostPort, guestPort, protocol)
+		recreateCmd := d.buildDockerRunCommand(cli, instance, configInfo, existingPorts, hostPort, guestPort, protocol)
 		_, err = sshClient.Execute(recreateCmd)
 		if err != nil {
 			return fmt.Errorf("failed to recreate container with port mapping: %v", err)
@@ -429,36 +439,23 @@ func (d *DockerPortMapping) createDockerPortMappingWithTempSSH(ctx context.Conte
 
 // getSSHClient 获取SSH客户端
 func (d *DockerPortMapping) getSSHClient(providerInfo *provider.Provider) (*utils.SSHClient, error) {
-	// 解析认证配置
-	var authConfig provider.ProviderAuthConfig
+	// 使用Provider的基础配置（包含密钥密码短语、ssh-agent和主机密钥校验设置）
+	config := utils.ProviderSSHConfig(providerInfo, 0, 0)
+
+	// 认证配置中的SSH连接信息优先
 	if providerInfo.AuthConfig != "" {
+		var authConfig provider.ProviderAuthConfig
 		if err := json.Unmarshal([]byte(providerInfo.AuthConfig), &authConfig); err != nil {
 			return nil, fmt.Errorf("failed to parse auth config: %v", err)
 		}
-	} else {
-		// 使用基础配置
-		authConfig = provider.ProviderAuthConfig{
-			SSH: &provider.SSHConfig{
-				Host:       strings.Split(providerInfo.Endpoint, ":")[0], // 从endpoint提取host
-				Port:       providerInfo.SSHPort,
-				Username:   providerInfo.Username,
-				Password:   providerInfo.Password,
-				KeyContent: providerInfo.SSHKey,
-			},
+		if authConfig.SSH == nil {
+			return nil, fmt.Errorf("SSH configuration not found")
 		}
-	}
-
-	if authConfig.SSH == nil {
-		return nil, fmt.Errorf("SSH configuration not found")
-	}
-
-	// 创建SSH配置
-	config := utils.SSHConfig{
-		Host:       authConfig.SSH.Host,
-		Port:       authConfig.SSH.Port,
-		Username:   authConfig.SSH.Username,
-		Password:   authConfig.SSH.Password,
-		PrivateKey: authConfig.SSH.KeyContent,
+		config.Host = authConfig.SSH.Host
+		config.Port = authConfig.SSH.Port
+		config.Username = authConfig.SSH.Username
+		config.Password = authConfig.SSH.Password
+		config.PrivateKey = authConfig.SSH.KeyContent
 	}
 
 	// 创建SSH客户端
@@ -471,7 +468,7 @@ func (d *DockerPortMapping) getSSHClient(providerInfo *provider.Provider) (*util
 }
 
 // buildDockerRunCommand 构建Docker运行命令
-func (d *DockerPortMapping) buildDockerRunCommand(instance *provider.Instance, configInfo, existingPorts string, newHostPort, newGuestPort int, protocol string) string {
+func (d *DockerPortMapping) buildDockerRunCommand(cli string, instance *provider.Instance, configInfo, existingPorts string, newHostPort, newGuestPort int, protocol string) string {
 	// 解析配置信息
 	configParts := strings.Fields(strings.TrimSpace(configInfo))
 	if len(configParts) < 1 {
@@ -481,7 +478,7 @@ func (d *DockerPortMapping) buildDockerRunCommand(instance *provider.Instance, c
 	image := configParts[0]
 
 	// 构建基础命令
-	cmd := fmt.Sprintf("docker run -d --name %s", instance.Name)
+	cmd := fmt.Sprintf("%s run -d --name %s", cli, instance.Name)
 
 	// 资源限制（如果有的话）
 	if len(configParts) >= 3 && configParts[2] != "0" {
@@ -556,35 +553,36 @@ func (d *DockerPortMapping) removeDockerPortMapping(ctx context.Context, instanc
 	defer sshClient.Close()
 
 	// Docker不支持动态移除端口映射，需要重新创建容器（不包含该端口映射）
+	cli := containerCLI(providerInfo)
 	// 获取现有容器的配置
-	inspectCmd := fmt.Sprintf("docker inspect %s --format '{{.Config.Image}} {{.Config.Cmd}} {{.HostConfig.Memory}} {{.HostConfig.NanoCpus}}'", instance.Name)
+	inspectCmd := fmt.Sprintf("%s inspect %s --format '{{.Config.Image}} {{.Config.Cmd}} {{.HostConfig.Memory}} {{.HostConfig.NanoCpus}}'", cli, instance.Name)
 	configInfo, err := sshClient.Execute(inspectCmd)
 	if err != nil {
 		return fmt.Errorf("failed to get container config: %v", err)
 	}
 
 	// 获取现有的端口映射（排除要删除的）
-	portsCmd := fmt.Sprintf("docker port %s", instance.Name)
+	portsCmd := fmt.Sprintf("%s port %s", cli, instance.Name)
 	existingPorts, _ := sshClient.Execute(portsCmd)
 
 	// 过滤掉要删除的端口映射
 	filteredPorts := d.filterPortMappings(existingPorts, hostPort, guestPort, protocol)
 
 	// 停止并删除现有容器
-	stopCmd := fmt.Sprintf("docker stop %s", instance.Name)
+	stopCmd := fmt.Sprintf("%s stop %s", cli, instance.Name)
 	_, err = sshClient.Execute(stopCmd)
 	if err != nil {
 		global.APP_LOG.Warn("Failed to stop container", zap.Error(err))
 	}
 
-	removeCmd := fmt.Sprintf("docker rm %s", instance.Name)
+	removeCmd := fmt.Sprintf("%s rm %s", cli, instance.Name)
 	_, err = sshClient.Execute(removeCmd)
 	if err != nil {
 		return fmt.Errorf("failed to remove container: %v", err)
 	}
 
 	// 重新创建容器（不包含被删除的端口映射）
-	recreateCmd := d.buildDockerRunCommandWithFilteredPorts(instance, configInfo, filteredPorts)
+	recreateCmd := d.buildDockerRunCommandWithFilteredPorts(cli, instance, configInfo, filteredPorts)
 	_, err = sshClient.Execute(recreateCmd)
 	if err != nil {
 		return fmt.Errorf("failed to recreate container: %v", err)
@@ -647,7 +645,7 @@ func (d *DockerPortMapping) filterPortMappings(existingPorts string, excludeHost
 }
 
 // buildDockerRunCommandWithFilteredPorts 使用过滤后的端口映射构建Docker运行命令
-func (d *DockerPortMapping) buildDockerRunCommandWithFilteredPorts(instance *provider.Instance, configInfo string, filteredPorts []string) string {
+func (d *DockerPortMapping) buildDockerRunCommandWithFilteredPorts(cli string, instance *provider.Instance, configInfo string, filteredPorts []string) string {
 	// 解析配置信息
 	configParts := strings.Fields(strings.TrimSpace(configInfo))
 	if len(configParts) < 1 {
@@ -657,7 +655,7 @@ func (d *DockerPortMapping) buildDockerRunCommandWithFilteredPorts(instance *pro
 	image := configParts[0]
 
 	// 构建基础命令
-	cmd := fmt.Sprintf("docker run -d --name %s", instance.Name)
+	cmd := fmt.Sprintf("%s run -d --name %s", cli, instance.Name)
 
 	// 资源限制
 	if len(configParts) >= 3 && configParts[2] != "0" {
@@ -502,27 +502,11 @@ func (i *IptablesPortMapping) cleanupIptablesRule(ctx context.Context, instance
 
 // createSSHClient 创建SSH客户端连接到provider主机
 func (i *IptablesPortMapping) createSSHClient(providerInfo *provider.Provider) (*utils.SSHClient, error) {
-	// 解析endpoint获取host和port
-	host, port := i.parseEndpoint(providerInfo.Endpoint)
-
-	sshConfig := utils.SSHConfig{
-		Host:           host,
-		Port:           port,
-		Username:       providerInfo.Username,
-		Password:       providerInfo.Password,
-		PrivateKey:     providerInfo.SSHKey,
-		ConnectTimeout: 10 * time.Second,
-		ExecuteTimeout: 60 * time.Second,
-	}
+	sshConfig := utils.ProviderSSHConfig(providerInfo, 10*time.Second, 60*time.Second)
 
 	return utils.NewSSHClient(sshConfig)
 }
 
-// parseEndpoint 解析endpoint获取host和port（使用全局函数）
-func (i *IptablesPortMapping) parseEndpoint(endpoint string) (host string, port int) {
-	return utils.ParseEndpoint(endpoint, 22)
-}
-
 // init 注册iptables端口映射Provider
 func init() {
 	portmapping.RegisterProvider("iptables", func(config *portmapping.ManagerConfig) portmapping.PortMappingProvider {
@@ -0,0 +1,141 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"oneclickvirt/utils"
+)
+
+var (
+	profileNamePattern      = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.\-]{0,62}$`)
+	profileConfigKeyPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.\-]*$`)
+	profileMemoryPattern    = regexp.MustCompile(`^(\d+)\s*(MB|MiB|GB|GiB)$`)
+)
+
+// ErrProfileRequiresStop 运行中的虚拟机不能在线变更profile中的资源限制，需要先停止实例
+var ErrProfileRequiresStop = errors.New("虚拟机运行中，应用profile需要先停止实例")
+
+// 允许写入profile的配置项前缀，不开放 raw.*、security.* 等可能影响宿主机安全的配置
+var allowedProfileConfigPrefixes = []string{"limits.", "boot.", "snapshots."}
+
+// ValidateProfileName 校验profile名称格式，名称会拼接到命令行中
+func ValidateProfileName(name string) error {
+	if !profileNamePattern.MatchString(name) {
+		return fmt.Errorf("profile名称无效: %s", name)
+	}
+	return nil
+}
+
+// ProfileConfigItems 校验创建profile的参数，返回需要写入profile的配置项
+// default profile由节点初始化时创建，不允许通过此接口覆盖
+func ProfileConfigItems(cfg ProfileCreateConfig) (map[string]string, error) {
+	if err := ValidateProfileName(cfg.Name); err != nil {
+		return nil, err
+	}
+	if cfg.Name == "default" {
+		return nil, fmt.Errorf("不能创建名为default的profile")
+	}
+	if strings.ContainsAny(cfg.Description, "\r\n") {
+		return nil, fmt.Errorf("profile描述不能包含换行")
+	}
+	if cfg.StoragePool != "" && !profileNamePattern.MatchString(cfg.StoragePool) {
+		return nil, fmt.Errorf("存储池名称无效: %s", cfg.StoragePool)
+	}
+	if cfg.MemoryMB < 0 || cfg.DiskGB < 0 {
+		return nil, fmt.Errorf("内存和磁盘大小不能为负数")
+	}
+
+	items := make(map[string]string)
+	for key, value := range cfg.Config {
+		if !profileConfigKeyPattern.MatchString(key) || !hasAllowedProfilePrefix(key) {
+			return nil, fmt.Errorf("不支持的profile配置项: %s", key)
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return nil, fmt.Errorf("配置项 %s 的值不能包含换行", key)
+		}
+		items[key] = value
+	}
+	if cfg.CPU != "" {
+		limit, err := ParseCPULimit(cfg.CPU, 0)
+		if err != nil {
+			return nil, err
+		}
+		items["limits.cpu"] = limit
+	}
+	if cfg.MemoryMB > 0 {
+		items["limits.memory"] = fmt.Sprintf("%dMB", cfg.MemoryMB)
+	}
+
+	if len(items) == 0 && cfg.DiskGB == 0 {
+		return nil, fmt.Errorf("profile至少需要包含一项资源限制或配置")
+	}
+	return items, nil
+}
+
+func hasAllowedProfilePrefix(key string) bool {
+	for _, prefix := range allowedProfileConfigPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// ProfileSetArgs 将配置项格式化为 profile set 的参数，按键名排序并逐项转义
+func ProfileSetArgs(items map[string]string) string {
+	keys := make([]string, 0, len(items))
+	for key := range items {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	args := make([]string, 0, len(keys))
+	for _, key := range keys {
+		args = append(args, utils.ShellQuote(key+"="+items[key]))
+	}
+	return strings.Join(args, " ")
+}
+
+// ProfileLimits 从profile配置中解析CPU核心数和内存大小（MB），无法解析的项返回0
+// 核心绑定集合按绑定的核心数量计算
+func ProfileLimits(config map[string]string) (cpu int, memoryMB int64) {
+	if value := config["limits.cpu"]; value != "" {
+		if limit, err := ParseCPULimit(value, 0); err == nil {
+			if count, err := strconv.Atoi(limit); err == nil {
+				cpu = count
+			} else {
+				cpu = countCPUSet(limit)
+			}
+		}
+	}
+	if match := profileMemoryPattern.FindStringSubmatch(strings.TrimSpace(config["limits.memory"])); match != nil {
+		size, _ := strconv.ParseInt(match[1], 10, 64)
+		switch match[2] {
+		case "GB", "GiB":
+			memoryMB = size * 1024
+		default:
+			memoryMB = size
+		}
+	}
+	return cpu, memoryMB
+}
+
+// countCPUSet 统计规范化后的核心绑定集合（如 0-2,5）包含的核心数量
+func countCPUSet(set string) int {
+	count := 0
+	for _, part := range strings.Split(set, ",") {
+		from, to, isRange := strings.Cut(part, "-")
+		start, _ := strconv.Atoi(from)
+		end := start
+		if isRange {
+			end, _ = strconv.Atoi(to)
+		}
+		count += end - start + 1
+	}
+	return count
+}
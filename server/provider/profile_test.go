@@ -0,0 +1,69 @@
+package provider
+
+import (
+	"testing"
+)
+
+// TestProfileConfigItems 测试profile参数校验和配置项生成
+func TestProfileConfigItems(t *testing.T) {
+	items, err := ProfileConfigItems(ProfileCreateConfig{
+		Name:     "small",
+		CPU:      "2",
+		MemoryMB: 1024,
+		Config:   map[string]string{"limits.processes": "500"},
+	})
+	if err != nil {
+		t.Fatalf("合法参数校验失败: %v", err)
+	}
+	if items["limits.cpu"] != "2" || items["limits.memory"] != "1024MB" || items["limits.processes"] != "500" {
+		t.Fatalf("配置项错误: %v", items)
+	}
+
+	if _, err := ProfileConfigItems(ProfileCreateConfig{Name: "disk-only", DiskGB: 10}); err != nil {
+		t.Fatalf("只包含根磁盘的profile应允许创建: %v", err)
+	}
+
+	invalid := []ProfileCreateConfig{
+		{Name: "default", CPU: "1"},
+		{Name: "bad name", CPU: "1"},
+		{Name: "empty"},
+		{Name: "raw", Config: map[string]string{"raw.lxc": "lxc.apparmor.profile=unconfined"}},
+		{Name: "privileged", Config: map[string]string{"security.privileged": "true"}},
+		{Name: "newline", Config: map[string]string{"limits.processes": "1\n2"}},
+		{Name: "allowance", CPU: "50%"},
+		{Name: "negative", MemoryMB: -1},
+	}
+	for _, cfg := range invalid {
+		if _, err := ProfileConfigItems(cfg); err == nil {
+			t.Fatalf("非法参数应校验失败: %+v", cfg)
+		}
+	}
+}
+
+// TestProfileSetArgs 测试profile set参数按键名排序并转义
+func TestProfileSetArgs(t *testing.T) {
+	got := ProfileSetArgs(map[string]string{"limits.memory": "1024MB", "limits.cpu": "2"})
+	if got != "'limits.cpu=2' 'limits.memory=1024MB'" {
+		t.Fatalf("参数错误: %s", got)
+	}
+}
+
+// TestProfileLimits 测试从profile配置解析CPU和内存
+func TestProfileLimits(t *testing.T) {
+	cases := []struct {
+		config map[string]string
+		cpu    int
+		memory int64
+	}{
+		{map[string]string{"limits.cpu": "2", "limits.memory": "1024MB"}, 2, 1024},
+		{map[string]string{"limits.cpu": "0-2,5", "limits.memory": "2GiB"}, 4, 2048},
+		{map[string]string{"limits.memory": "50%"}, 0, 0},
+		{map[string]string{}, 0, 0},
+	}
+	for _, c := range cases {
+		cpu, memory := ProfileLimits(c.config)
+		if cpu != c.cpu || memory != c.memory {
+			t.Fatalf("ProfileLimits(%v) = %d, %d，期望 %d, %d", c.config, cpu, memory, c.cpu, c.memory)
+		}
+	}
+}
@@ -15,6 +15,21 @@ type Instance = provider.ProviderInstance
 type Image = provider.ProviderImage
 type InstanceConfig = provider.ProviderInstanceConfig
 type NodeConfig = provider.ProviderNodeConfig
+type StoragePool = provider.ProviderStoragePool
+type PrewarmImage = provider.ProviderPrewarmImage
+type PrewarmResult = provider.ProviderPrewarmResult
+type InstanceUsage = provider.ProviderInstanceUsage
+type DiskUsage = provider.ProviderInstanceDiskUsage
+type Capabilities = provider.ProviderCapabilities
+type NetworkInterfaceConfig = provider.ProviderNetworkInterfaceConfig
+type VolumeMount = provider.ProviderVolumeMount
+type FirewallRule = provider.ProviderFirewallRule
+type BackupArchive = provider.ProviderBackupArchive
+type Network = provider.ProviderNetwork
+type NetworkCreateConfig = provider.ProviderNetworkCreateConfig
+type GPUDevice = provider.ProviderGPUDevice
+type Profile = provider.ProviderProfile
+type ProfileCreateConfig = provider.ProviderProfileCreateConfig
 
 // ProgressCallback 进度回调函数类型
 type ProgressCallback func(percentage int, message string)
@@ -25,6 +40,8 @@ type Provider interface {
 	GetType() string
 	GetName() string
 	GetSupportedInstanceTypes() []string // 获取支持的实例类型
+	// Capabilities 返回节点实际具备的能力，结果经探测得出并按Provider缓存
+	Capabilities(ctx context.Context) (*Capabilities, error)
 
 	// 实例管理
 	ListInstances(ctx context.Context) ([]Instance, error)
@@ -127,3 +144,20 @@ func GetAllProviders() map[string]func() Provider {
 	}
 	return result
 }
+
+// PrewarmEach 依次预热镜像并汇总每个镜像的结果，单个镜像失败不影响其余镜像
+func PrewarmEach(ctx context.Context, images []PrewarmImage, prepare func(image PrewarmImage) error) []PrewarmResult {
+	results := make([]PrewarmResult, 0, len(images))
+	for _, image := range images {
+		result := PrewarmResult{Name: image.Name, InstanceType: image.InstanceType, Status: "ready"}
+		if err := ctx.Err(); err != nil {
+			result.Status = "failed"
+			result.Error = err.Error()
+		} else if err := prepare(image); err != nil {
+			result.Status = "failed"
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+	return results
+}
@@ -13,6 +13,7 @@ import (
 	"oneclickvirt/global"
 	providerModel "oneclickvirt/model/provider"
 	"oneclickvirt/provider"
+	"oneclickvirt/utils"
 
 	"go.uber.org/zap"
 )
@@ -140,8 +141,35 @@ func (p *ProxmoxProvider) apiCreateInstanceWithProgress(ctx context.Context, con
 
 	updateProgress(10, "开始Proxmox API创建实例...")
 
+	// 校验额外网卡引用的网桥
+	if err := p.validateExtraNICs(config); err != nil {
+		return err
+	}
+
+	// 校验直通GPU
+	if err := p.validateGPUs(ctx, &config); err != nil {
+		return err
+	}
+
+	// 校验挂载的宿主机目录
+	if err := p.prepareVolumes(&config); err != nil {
+		return err
+	}
+
+	// 校验自定义DNS
+	dnsServers, err := provider.ValidateDNSServers(config.DNSServers)
+	if err != nil {
+		return err
+	}
+	config.DNSServers = dnsServers
+
+	// 校验自定义主机名
+	if config.Hostname, err = provider.ValidateHostname(config.Hostname); err != nil {
+		return err
+	}
+
 	// 获取下一个可用的VMID
-	vmid, err := p.getNextVMID(ctx, config.InstanceType)
+	vmid, err := p.allocateVMID(ctx, config)
 	if err != nil {
 		return fmt.Errorf("获取VMID失败: %w", err)
 	}
@@ -164,6 +192,28 @@ func (p *ProxmoxProvider) apiCreateInstanceWithProgress(ctx context.Context, con
 		if err := p.apiCreateVM(ctx, vmid, config, updateProgress); err != nil {
 			return fmt.Errorf("API创建虚拟机失败: %w", err)
 		}
+		password := config.Metadata["password"]
+		if password == "" {
+			password = p.generateRandomPassword()
+		}
+		if err := p.applyCloudInit(vmid, config, password); err != nil {
+			global.APP_LOG.Warn("写入cloud-init配置失败", zap.Int("vmid", vmid), zap.Error(err))
+		}
+	}
+
+	// 添加额外网卡
+	if err := p.attachExtraNICs(vmid, config); err != nil {
+		return err
+	}
+
+	// 添加直通GPU
+	if err := p.attachGPUs(vmid, config); err != nil {
+		return err
+	}
+
+	// 挂载宿主机目录
+	if err := p.attachVolumes(vmid, config); err != nil {
+		return err
 	}
 
 	updateProgress(90, "配置网络和启动...")
@@ -172,6 +222,7 @@ func (p *ProxmoxProvider) apiCreateInstanceWithProgress(ctx context.Context, con
 	if err := p.configureInstanceNetwork(ctx, vmid, config); err != nil {
 		global.APP_LOG.Warn("网络配置失败", zap.Int("vmid", vmid), zap.Error(err))
 	}
+	p.applyNameservers(vmid, config)
 
 	// 启动实例
 	if err := p.apiStartInstance(ctx, fmt.Sprintf("%d", vmid)); err != nil {
@@ -184,12 +235,24 @@ func (p *ProxmoxProvider) apiCreateInstanceWithProgress(ctx context.Context, con
 		global.APP_LOG.Warn("配置端口映射失败", zap.Error(err))
 	}
 
-	// 配置SSH密码
+	// 配置带宽限速
+	if config.IngressMbps > 0 || config.EgressMbps > 0 {
+		if err := p.applyBandwidthLimit(fmt.Sprintf("%d", vmid), config.InstanceType, config.EgressMbps, config.IngressMbps); err != nil {
+			global.APP_LOG.Warn("配置带宽限速失败", zap.Int("vmid", vmid), zap.Error(err))
+		}
+	}
+
+	// 配置SSH密码，已通过cloud-init配置的虚拟机跳过
 	updateProgress(92, "配置SSH密码...")
-	if err := p.configureInstanceSSHPasswordByVMID(ctx, vmid, config); err != nil {
+	if config.InstanceType != "container" && p.hasCloudInitDrive(vmid) {
+		global.APP_LOG.Info("虚拟机已通过cloud-init配置登录凭据，跳过启动后设置密码", zap.Int("vmid", vmid))
+	} else if err := p.configureInstanceSSHPasswordByVMID(ctx, vmid, config); err != nil {
 		global.APP_LOG.Warn("配置SSH密码失败", zap.Error(err))
 	}
 
+	p.writeContainerResolvConf(vmid, config)
+	p.writeContainerHostname(vmid, config)
+
 	// 初始化pmacct流量监控
 	updateProgress(95, "初始化pmacct流量监控...")
 	if err := p.initializePmacctMonitoring(ctx, vmid, config.Name); err != nil {
@@ -510,37 +573,8 @@ func (p *ProxmoxProvider) performPostDeletionCleanup(ctx context.Context, vmctid
 		zap.String("type", instanceType),
 		zap.String("ip", ipAddress))
 
-	// 清理IPv6 NAT映射规则
-	if err := p.cleanupIPv6NATRules(ctx, vmctid); err != nil {
-		global.APP_LOG.Warn("清理IPv6 NAT规则失败", zap.String("vmctid", vmctid), zap.Error(err))
-	}
-
-	// 清理文件
-	if instanceType == "vm" {
-		if err := p.cleanupVMFiles(ctx, vmctid); err != nil {
-			global.APP_LOG.Warn("清理VM文件失败", zap.String("vmid", vmctid), zap.Error(err))
-		}
-	} else {
-		if err := p.cleanupCTFiles(ctx, vmctid); err != nil {
-			global.APP_LOG.Warn("清理CT文件失败", zap.String("ctid", vmctid), zap.Error(err))
-		}
-	}
-
-	// 更新iptables规则
-	if ipAddress != "" {
-		if err := p.updateIPTablesRules(ctx, ipAddress); err != nil {
-			global.APP_LOG.Warn("更新iptables规则失败", zap.String("ip", ipAddress), zap.Error(err))
-		}
-	}
-
-	// 重建iptables规则
-	if err := p.rebuildIPTablesRules(ctx); err != nil {
-		global.APP_LOG.Warn("重建iptables规则失败", zap.Error(err))
-	}
-
-	// 重启ndpresponder服务
-	if err := p.restartNDPResponder(ctx); err != nil {
-		global.APP_LOG.Warn("重启ndpresponder服务失败", zap.Error(err))
+	if err := runDeleteSteps(p.postDeleteSteps(ctx, vmctid, instanceType, ipAddress), vmctid); err != nil {
+		return err
 	}
 
 	global.APP_LOG.Info("通过API成功删除Proxmox实例",
@@ -806,7 +840,7 @@ func (p *ProxmoxProvider) apiCreateContainer(ctx context.Context, vmid int, conf
 		}
 
 		downloadCmd := fmt.Sprintf("curl -L -o %s %s", localImagePath, systemConfig.ImageURL)
-		_, err = p.sshClient.Execute(downloadCmd)
+		_, err = p.sshClient.ExecuteOperation(utils.SSHOperationDownload, downloadCmd)
 		if err != nil {
 			return fmt.Errorf("下载镜像失败: %v", err)
 		}
@@ -819,6 +853,9 @@ func (p *ProxmoxProvider) apiCreateContainer(ctx context.Context, vmid int, conf
 	}
 
 	storage := providerRecord.StoragePool
+	if config.StoragePool != "" {
+		storage = config.StoragePool
+	}
 	if storage == "" {
 		storage = "local"
 	}
@@ -836,7 +873,7 @@ func (p *ProxmoxProvider) apiCreateContainer(ctx context.Context, vmid int, conf
 		"ostemplate":   localImagePath,
 		"cores":        cpuFormatted,
 		"memory":       memoryFormatted,
-		"swap":         "128",
+		"swap":         fmt.Sprintf("%d", provider.ProxmoxContainerSwapMB(config)),
 		"rootfs":       fmt.Sprintf("%s:%s", storage, diskFormatted),
 		"onboot":       "1",
 		"features":     "nesting=1",
@@ -933,7 +970,7 @@ func (p *ProxmoxProvider) apiCreateVM(ctx context.Context, vmid int, config prov
 		}
 
 		downloadCmd := fmt.Sprintf("curl -L -o %s %s", localImagePath, systemConfig.ImageURL)
-		_, err = p.sshClient.Execute(downloadCmd)
+		_, err = p.sshClient.ExecuteOperation(utils.SSHOperationDownload, downloadCmd)
 		if err != nil {
 			return fmt.Errorf("下载镜像失败: %v", err)
 		}
@@ -967,6 +1004,9 @@ func (p *ProxmoxProvider) apiCreateVM(ctx context.Context, vmid int, config prov
 	}
 
 	storage := providerRecord.StoragePool
+	if config.StoragePool != "" {
+		storage = config.StoragePool
+	}
 	if storage == "" {
 		storage = "local"
 	}
@@ -1043,7 +1083,7 @@ func (p *ProxmoxProvider) apiCreateVM(ctx context.Context, vmid int, config prov
 	}
 
 	importCmd := fmt.Sprintf("qm importdisk %d %s %s", vmid, localImagePath, storage)
-	_, err = p.sshClient.Execute(importCmd)
+	_, err = p.sshClient.ExecuteOperation(utils.SSHOperationDownload, importCmd)
 	if err != nil {
 		return fmt.Errorf("导入磁盘失败: %w", err)
 	}
@@ -0,0 +1,108 @@
+package proxmox
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"oneclickvirt/global"
+	"oneclickvirt/provider"
+
+	"go.uber.org/zap"
+)
+
+// BackupInstance 使用 vzdump 以快照模式导出实例并流式写入dest，宿主机上的临时文件在结束后删除
+func (p *ProxmoxProvider) BackupInstance(ctx context.Context, instanceName string, dest io.Writer) (*provider.BackupArchive, error) {
+	if !p.connected || p.sshClient == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	vmid, _, err := p.findVMIDByNameOrID(ctx, instanceName)
+	if err != nil {
+		return nil, fmt.Errorf("查找实例失败: %w", err)
+	}
+
+	dir, err := provider.PrepareBackupWorkDir(p.sshClient, instanceName)
+	if err != nil {
+		return nil, err
+	}
+	defer provider.RemoveBackupWorkDir(p.sshClient, dir)
+
+	dumpCmd := fmt.Sprintf("vzdump %s --dumpdir %s --mode snapshot --compress zstd", vmid, dir)
+	if err := provider.RunBackupCommand(p.sshClient, dumpCmd); err != nil {
+		return nil, fmt.Errorf("vzdump导出实例失败: %w", err)
+	}
+
+	// vzdump按类型和时间生成文件名，同目录下还有一份日志
+	output, err := p.sshClient.Execute(fmt.Sprintf("find %s -maxdepth 1 -name 'vzdump-*' ! -name '*.log' | head -n 1", dir))
+	archivePath := strings.TrimSpace(output)
+	if err != nil || archivePath == "" {
+		return nil, fmt.Errorf("未找到vzdump生成的备份文件")
+	}
+
+	archive, err := provider.SendBackupArchive(p.sshClient, archivePath, dest)
+	if err != nil {
+		return nil, err
+	}
+	global.APP_LOG.Info("Proxmox实例备份导出完成",
+		zap.String("instance", instanceName),
+		zap.String("vmid", vmid),
+		zap.Int64("size", archive.Size))
+	return archive, nil
+}
+
+// RestoreFromBackup 使用 qmrestore/pct restore 以原VMID覆盖恢复实例
+// 恢复前先停止实例，磁盘恢复到备份中记录的原存储；恢复前原本运行中的实例在恢复后重新启动
+func (p *ProxmoxProvider) RestoreFromBackup(ctx context.Context, instanceName, fileName string, src io.Reader) error {
+	if !p.connected || p.sshClient == nil {
+		return fmt.Errorf("not connected")
+	}
+	vmid, instanceType, err := p.findVMIDByNameOrID(ctx, instanceName)
+	if err != nil {
+		return fmt.Errorf("查找实例失败: %w", err)
+	}
+
+	tool := "qm"
+	restoreFormat := "qmrestore %[2]s %[1]s --force 1"
+	if instanceType == "container" {
+		tool = "pct"
+		restoreFormat = "pct restore %[1]s %[2]s --force 1"
+	}
+	if (tool == "qm") != strings.HasPrefix(fileName, "vzdump-qemu-") {
+		return fmt.Errorf("备份文件 %s 与实例类型不匹配", fileName)
+	}
+
+	dir, err := provider.PrepareBackupWorkDir(p.sshClient, instanceName)
+	if err != nil {
+		return err
+	}
+	defer provider.RemoveBackupWorkDir(p.sshClient, dir)
+
+	archivePath, err := provider.ReceiveBackupArchive(p.sshClient, dir, fileName, src)
+	if err != nil {
+		return err
+	}
+
+	statusOutput, _ := p.sshClient.Execute(fmt.Sprintf("%s status %s", tool, vmid))
+	wasRunning := strings.Contains(statusOutput, "running")
+	if wasRunning {
+		if output, err := p.sshClient.Execute(fmt.Sprintf("%s stop %s", tool, vmid)); err != nil {
+			return fmt.Errorf("停止实例失败: %s: %w", strings.TrimSpace(output), err)
+		}
+	}
+
+	if err := provider.RunBackupCommand(p.sshClient, fmt.Sprintf(restoreFormat, vmid, archivePath)); err != nil {
+		return fmt.Errorf("恢复实例失败: %w", err)
+	}
+
+	if wasRunning {
+		if output, err := p.sshClient.Execute(fmt.Sprintf("%s start %s", tool, vmid)); err != nil {
+			return fmt.Errorf("启动恢复后的实例失败: %s: %w", strings.TrimSpace(output), err)
+		}
+	}
+
+	global.APP_LOG.Info("Proxmox实例备份恢复完成",
+		zap.String("instance", instanceName),
+		zap.String("vmid", vmid))
+	return nil
+}
@@ -0,0 +1,56 @@
+package proxmox
+
+import (
+	"context"
+	"fmt"
+
+	"oneclickvirt/global"
+	"oneclickvirt/provider"
+
+	"go.uber.org/zap"
+)
+
+// hostInterfaceName 返回实例net0在宿主机侧的接口名称，容器为veth<vmid>i0，虚拟机为tap<vmid>i0
+func hostInterfaceName(vmid string, instanceType string) string {
+	return hostInterfaceNameAt(vmid, instanceType, 0)
+}
+
+// hostInterfaceNameAt 返回实例netN在宿主机侧的接口名称
+func hostInterfaceNameAt(vmid string, instanceType string, index int) string {
+	if instanceType == "container" {
+		return fmt.Sprintf("veth%si%d", vmid, index)
+	}
+	return fmt.Sprintf("tap%si%d", vmid, index)
+}
+
+// SetBandwidthLimit 在实例的宿主机侧veth/tap接口上设置tc限速，uploadMbps/downloadMbps小于等于0表示该方向不限速
+// 接口在实例每次启动时重建，启动/重启后需要重新调用
+func (p *ProxmoxProvider) SetBandwidthLimit(ctx context.Context, instanceName string, uploadMbps, downloadMbps int) error {
+	if !p.connected || p.sshClient == nil {
+		return fmt.Errorf("not connected")
+	}
+
+	vmid, instanceType, err := p.findVMIDByNameOrID(ctx, instanceName)
+	if err != nil {
+		return err
+	}
+	return p.applyBandwidthLimit(vmid, instanceType, uploadMbps, downloadMbps)
+}
+
+func (p *ProxmoxProvider) applyBandwidthLimit(vmid string, instanceType string, uploadMbps, downloadMbps int) error {
+	iface := hostInterfaceName(vmid, instanceType)
+	cmd, err := provider.BuildBandwidthLimitCommand(iface, uploadMbps, downloadMbps)
+	if err != nil {
+		return err
+	}
+	if output, err := p.sshClient.Execute(cmd); err != nil {
+		return fmt.Errorf("设置tc限速失败: %s: %w", output, err)
+	}
+
+	global.APP_LOG.Info("Proxmox实例限速已更新",
+		zap.String("vmid", vmid),
+		zap.String("interface", iface),
+		zap.Int("uploadMbps", uploadMbps),
+		zap.Int("downloadMbps", downloadMbps))
+	return nil
+}
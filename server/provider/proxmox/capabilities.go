@@ -0,0 +1,47 @@
+package proxmox
+
+import (
+	"context"
+	"fmt"
+
+	"oneclickvirt/global"
+	"oneclickvirt/provider"
+
+	"go.uber.org/zap"
+)
+
+// Capabilities 返回Proxmox节点的能力信息
+// 宿主机没有KVM时虚拟机会以软件模拟方式（kvm 0）创建，因此虚拟机始终可用；IPv6取决于ndpresponder等独立IPv6环境是否就绪
+func (p *ProxmoxProvider) Capabilities(ctx context.Context) (*provider.Capabilities, error) {
+	return p.capabilities.Get(func() (*provider.Capabilities, error) {
+		if p.sshClient == nil {
+			return nil, fmt.Errorf("SSH client not connected")
+		}
+
+		host, err := provider.ProbeHost(p.sshClient)
+		if err != nil {
+			return nil, err
+		}
+
+		caps := &provider.Capabilities{
+			SupportsContainer: true,
+			SupportsVM:        true,
+			SupportsIPv6:      p.checkIPv6Environment(ctx) == nil,
+			SupportsDiskLimit: true,
+			SupportsSnapshots: true,
+			Architectures:     host.Architectures(),
+		}
+
+		global.APP_LOG.Info("Proxmox节点能力探测完成",
+			zap.String("provider", p.config.Name),
+			zap.Bool("kvm", host.KVM),
+			zap.Bool("ipv6", caps.SupportsIPv6),
+			zap.Strings("architectures", caps.Architectures))
+		return caps, nil
+	})
+}
+
+// CachedCapabilities 返回缓存中的能力信息，不会触发SSH探测，缓存不存在或已过期时返回nil
+func (p *ProxmoxProvider) CachedCapabilities() *provider.Capabilities {
+	return p.capabilities.Peek()
+}
@@ -0,0 +1,81 @@
+package proxmox
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"oneclickvirt/global"
+
+	"go.uber.org/zap"
+)
+
+// CloneInstance 使用 qm/pct clone 完整克隆实例
+// 新实例分配新的VMID，NAT网络下内网IP按VMID重新设置，避免与源实例冲突
+func (p *ProxmoxProvider) CloneInstance(ctx context.Context, sourceName, newName string) error {
+	if !p.connected || p.sshClient == nil {
+		return fmt.Errorf("not connected")
+	}
+	if sourceName == "" || newName == "" {
+		return fmt.Errorf("实例名称不能为空")
+	}
+
+	sourceVMID, instanceType, err := p.findVMIDByNameOrID(ctx, sourceName)
+	if err != nil {
+		return fmt.Errorf("查找源实例失败: %w", err)
+	}
+	if _, _, err := p.findVMIDByNameOrID(ctx, newName); err == nil {
+		return fmt.Errorf("目标实例 %s 已存在", newName)
+	}
+
+	vmidType := "vm"
+	tool := "qm"
+	nameFlag := "--name"
+	if instanceType == "container" {
+		vmidType = "container"
+		tool = "pct"
+		nameFlag = "--hostname"
+	}
+
+	newVMID, err := p.getNextVMID(ctx, vmidType)
+	if err != nil {
+		return fmt.Errorf("分配VMID失败: %w", err)
+	}
+
+	cloneCmd := fmt.Sprintf("%s clone %s %d %s %s --full", tool, sourceVMID, newVMID, nameFlag, newName)
+	global.APP_LOG.Info("开始克隆Proxmox实例",
+		zap.String("source", sourceName),
+		zap.String("sourceVMID", sourceVMID),
+		zap.Int("newVMID", newVMID),
+		zap.String("command", cloneCmd))
+	if output, err := p.sshClient.Execute(cloneCmd); err != nil {
+		return fmt.Errorf("克隆实例失败: %s: %w", strings.TrimSpace(output), err)
+	}
+
+	// NAT网络的内网IP与VMID绑定，克隆后需要改为新VMID对应的IP
+	userIP := fmt.Sprintf("172.16.1.%d", newVMID)
+	if tool == "pct" {
+		if netConfig, err := p.sshClient.Execute(fmt.Sprintf("pct config %d | grep '^net0:'", newVMID)); err == nil && strings.Contains(netConfig, "172.16.1.") {
+			if _, err := p.sshClient.Execute(fmt.Sprintf("pct set %d --net0 name=eth0,ip=%s/24,bridge=vmbr1,gw=172.16.1.1", newVMID, userIP)); err != nil {
+				global.APP_LOG.Warn("设置克隆容器网络失败", zap.Int("vmid", newVMID), zap.Error(err))
+			}
+		}
+	} else {
+		if ipConfig, err := p.sshClient.Execute(fmt.Sprintf("qm config %d | grep '^ipconfig0:'", newVMID)); err == nil && strings.Contains(ipConfig, "172.16.1.") {
+			if _, err := p.sshClient.Execute(fmt.Sprintf("qm set %d --ipconfig0 ip=%s/24,gw=172.16.1.1", newVMID, userIP)); err != nil {
+				global.APP_LOG.Warn("设置克隆虚拟机网络失败", zap.Int("vmid", newVMID), zap.Error(err))
+			}
+		}
+	}
+
+	if output, err := p.sshClient.Execute(fmt.Sprintf("%s start %d", tool, newVMID)); err != nil {
+		_, _ = p.sshClient.Execute(fmt.Sprintf("%s destroy %d", tool, newVMID))
+		return fmt.Errorf("启动克隆实例失败: %s: %w", strings.TrimSpace(output), err)
+	}
+
+	global.APP_LOG.Info("Proxmox实例克隆成功",
+		zap.String("source", sourceName),
+		zap.String("instance", newName),
+		zap.Int("vmid", newVMID))
+	return nil
+}
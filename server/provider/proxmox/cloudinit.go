@@ -0,0 +1,117 @@
+package proxmox
+
+import (
+	"fmt"
+	"strings"
+
+	"oneclickvirt/global"
+	"oneclickvirt/provider"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+)
+
+// cloudInitSnippetName 实例自定义vendor-data片段的文件名
+func cloudInitSnippetName(vmid string) string {
+	return fmt.Sprintf("oneclickvirt-%s-vendor.yaml", vmid)
+}
+
+// hasCloudInitDrive 检查虚拟机是否挂载了cloud-init驱动器
+func (p *ProxmoxProvider) hasCloudInitDrive(vmid int) bool {
+	output, err := p.sshClient.Execute(fmt.Sprintf("qm config %d", vmid))
+	if err != nil {
+		return false
+	}
+	return strings.Contains(output, ":cloudinit")
+}
+
+// findSnippetStorage 查找启用了snippets内容类型的可用存储
+func (p *ProxmoxProvider) findSnippetStorage() (string, error) {
+	output, err := p.sshClient.Execute("pvesm status --content snippets 2>/dev/null | awk 'NR > 1 && $3 == \"active\" {print $1}'")
+	if err != nil {
+		return "", fmt.Errorf("查询snippets存储失败: %w", err)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		if storage := strings.TrimSpace(line); storage != "" {
+			return storage, nil
+		}
+	}
+	return "", fmt.Errorf("节点上没有启用snippets内容类型的存储，请在存储配置中为local等存储开启snippets")
+}
+
+// applyCloudInit 在虚拟机首次启动前写入cloud-init配置：用户、密码、SSH公钥、网络、主机名及自定义vendor-data
+// 密码由Proxmox以哈希形式写入cloud-init用户数据，自定义UserData作为vendor-data与生成的用户数据合并生效
+func (p *ProxmoxProvider) applyCloudInit(vmid int, config provider.InstanceConfig, password string) error {
+	vmidStr := fmt.Sprintf("%d", vmid)
+
+	if _, err := p.sshClient.Execute(fmt.Sprintf("qm set %d --ciuser root --cipassword %s", vmid, utils.ShellQuote(password))); err != nil {
+		return fmt.Errorf("设置cloud-init用户密码失败: %w", err)
+	}
+
+	if len(config.SSHPublicKeys) > 0 {
+		keyFile := fmt.Sprintf("/tmp/oneclickvirt-%s-sshkeys.pub", vmidStr)
+		if err := p.sshClient.UploadContent(strings.Join(config.SSHPublicKeys, "\n")+"\n", keyFile, 0600); err != nil {
+			return fmt.Errorf("上传SSH公钥失败: %w", err)
+		}
+		_, err := p.sshClient.Execute(fmt.Sprintf("qm set %d --sshkeys %s", vmid, keyFile))
+		p.sshClient.Execute(fmt.Sprintf("rm -f %s", keyFile))
+		if err != nil {
+			return fmt.Errorf("设置cloud-init SSH公钥失败: %w", err)
+		}
+	}
+
+	// 自定义主机名通过vendor-data设置；已有自定义配置时不再合并，以用户提供的配置为准
+	vendorData := config.UserData
+	if config.Hostname != "" {
+		if strings.TrimSpace(vendorData) == "" {
+			vendorData = provider.HostnameCloudConfig(config.Hostname)
+		} else {
+			global.APP_LOG.Warn("已提供自定义cloud-init配置，跳过设置主机名",
+				zap.Int("vmid", vmid),
+				zap.String("hostname", config.Hostname))
+		}
+	}
+
+	if strings.TrimSpace(vendorData) != "" {
+		storage, err := p.findSnippetStorage()
+		if err != nil {
+			return err
+		}
+		volume := fmt.Sprintf("%s:snippets/%s", storage, cloudInitSnippetName(vmidStr))
+		pathOutput, err := p.sshClient.Execute(fmt.Sprintf("pvesm path %s", volume))
+		if err != nil || strings.TrimSpace(pathOutput) == "" {
+			return fmt.Errorf("解析snippets路径失败: %v", err)
+		}
+		if err := p.sshClient.UploadContent(vendorData, strings.TrimSpace(pathOutput), 0644); err != nil {
+			return fmt.Errorf("上传cloud-init vendor-data失败: %w", err)
+		}
+		if _, err := p.sshClient.Execute(fmt.Sprintf("qm set %d --cicustom vendor=%s", vmid, volume)); err != nil {
+			return fmt.Errorf("设置cloud-init自定义配置失败: %w", err)
+		}
+	}
+
+	// 重新生成cloud-init镜像；较旧的Proxmox版本不支持该命令，会在启动时自动生成
+	if _, err := p.sshClient.Execute(fmt.Sprintf("qm cloudinit update %d", vmid)); err != nil {
+		global.APP_LOG.Debug("qm cloudinit update执行失败，将在启动时生成cloud-init配置",
+			zap.Int("vmid", vmid),
+			zap.Error(err))
+	}
+
+	global.APP_LOG.Info("cloud-init配置已写入",
+		zap.Int("vmid", vmid),
+		zap.Int("sshKeys", len(config.SSHPublicKeys)),
+		zap.Bool("vendorData", strings.TrimSpace(vendorData) != ""))
+	return nil
+}
+
+// removeCloudInitSnippet 删除虚拟机的自定义vendor-data片段
+func (p *ProxmoxProvider) removeCloudInitSnippet(vmid string) {
+	storage, err := p.findSnippetStorage()
+	if err != nil {
+		return
+	}
+	volume := fmt.Sprintf("%s:snippets/%s", storage, cloudInitSnippetName(vmid))
+	if pathOutput, err := p.sshClient.Execute(fmt.Sprintf("pvesm path %s", volume)); err == nil && strings.TrimSpace(pathOutput) != "" {
+		p.sshClient.Execute(fmt.Sprintf("rm -f %s", utils.ShellQuote(strings.TrimSpace(pathOutput))))
+	}
+}
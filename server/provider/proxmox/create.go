@@ -38,8 +38,35 @@ func (p *ProxmoxProvider) sshCreateInstanceWithProgress(ctx context.Context, con
 
 	updateProgress(10, "开始创建Proxmox实例...")
 
+	// 校验额外网卡引用的网桥
+	if err := p.validateExtraNICs(config); err != nil {
+		return err
+	}
+
+	// 校验直通GPU
+	if err := p.validateGPUs(ctx, &config); err != nil {
+		return err
+	}
+
+	// 校验挂载的宿主机目录
+	if err := p.prepareVolumes(&config); err != nil {
+		return err
+	}
+
+	// 校验自定义DNS
+	dnsServers, err := provider.ValidateDNSServers(config.DNSServers)
+	if err != nil {
+		return err
+	}
+	config.DNSServers = dnsServers
+
+	// 校验自定义主机名
+	if config.Hostname, err = provider.ValidateHostname(config.Hostname); err != nil {
+		return err
+	}
+
 	// 获取下一个可用的VMID
-	vmid, err := p.getNextVMID(ctx, config.InstanceType)
+	vmid, err := p.allocateVMID(ctx, config)
 	if err != nil {
 		return fmt.Errorf("获取VMID失败: %w", err)
 	}
@@ -64,12 +91,23 @@ func (p *ProxmoxProvider) sshCreateInstanceWithProgress(ctx context.Context, con
 		}
 	}
 
+	// 添加额外网卡
+	if err := p.attachExtraNICs(vmid, config); err != nil {
+		return err
+	}
+
+	// 添加直通GPU
+	if err := p.attachGPUs(vmid, config); err != nil {
+		return err
+	}
+
 	updateProgress(90, "配置网络和启动...")
 
 	// 配置网络
 	if err := p.configureInstanceNetwork(ctx, vmid, config); err != nil {
 		global.APP_LOG.Warn("网络配置失败", zap.Int("vmid", vmid), zap.Error(err))
 	}
+	p.applyNameservers(vmid, config)
 
 	// 启动实例
 	if err := p.sshStartInstance(ctx, fmt.Sprintf("%d", vmid)); err != nil {
@@ -82,13 +120,26 @@ func (p *ProxmoxProvider) sshCreateInstanceWithProgress(ctx context.Context, con
 		global.APP_LOG.Warn("配置端口映射失败", zap.Error(err))
 	}
 
+	// 配置带宽限速
+	if config.IngressMbps > 0 || config.EgressMbps > 0 {
+		if err := p.applyBandwidthLimit(fmt.Sprintf("%d", vmid), config.InstanceType, config.EgressMbps, config.IngressMbps); err != nil {
+			global.APP_LOG.Warn("配置带宽限速失败", zap.Int("vmid", vmid), zap.Error(err))
+		}
+	}
+
 	// 配置SSH密码 - 在实例启动后，使用vmid而不是实例名称
+	// 虚拟机挂载了cloud-init驱动器时，密码已在首次启动前写入，无需再进入系统设置
 	updateProgress(92, "配置SSH密码...")
-	if err := p.configureInstanceSSHPasswordByVMID(ctx, vmid, config); err != nil {
+	if config.InstanceType != "container" && p.hasCloudInitDrive(vmid) {
+		global.APP_LOG.Info("虚拟机已通过cloud-init配置登录凭据，跳过启动后设置密码", zap.Int("vmid", vmid))
+	} else if err := p.configureInstanceSSHPasswordByVMID(ctx, vmid, config); err != nil {
 		// SSH密码设置失败也不应该阻止实例创建，记录错误即可
 		global.APP_LOG.Warn("配置SSH密码失败", zap.Error(err))
 	}
 
+	p.writeContainerResolvConf(vmid, config)
+	p.writeContainerHostname(vmid, config)
+
 	// 初始化pmacct流量监控
 	updateProgress(95, "初始化pmacct流量监控...")
 	if err := p.initializePmacctMonitoring(ctx, vmid, config.Name); err != nil {
@@ -159,7 +210,7 @@ func (p *ProxmoxProvider) createContainer(ctx context.Context, vmid int, config
 
 		// 下载镜像文件
 		downloadCmd := fmt.Sprintf("curl -L -o %s %s", localImagePath, downloadURL)
-		_, err = p.sshClient.Execute(downloadCmd)
+		_, err = p.sshClient.ExecuteOperation(utils.SSHOperationDownload, downloadCmd)
 		if err != nil {
 			return fmt.Errorf("下载镜像失败: %v", err)
 		}
@@ -177,6 +228,9 @@ func (p *ProxmoxProvider) createContainer(ctx context.Context, vmid int, config
 	}
 
 	storage := providerRecord.StoragePool
+	if config.StoragePool != "" {
+		storage = config.StoragePool
+	}
 	if storage == "" {
 		storage = "local" // 默认存储
 	}
@@ -193,15 +247,20 @@ func (p *ProxmoxProvider) createContainer(ctx context.Context, vmid int, config
 
 	// 构建容器创建命令
 	createCmd := fmt.Sprintf(
-		"pct create %d %s -cores %s -memory %s -swap 128 -rootfs %s:%s -onboot 1 -features nesting=1 -hostname %s",
+		"pct create %d %s -cores %s -memory %s -swap %d -rootfs %s:%s -onboot 1 -features nesting=1 -hostname %s",
 		vmid,
 		localImagePath,
 		cpuFormatted,
 		memoryFormatted,
+		provider.ProxmoxContainerSwapMB(config),
 		storage,
 		diskFormatted,
 		config.Name,
 	)
+	// 绑定挂载点在创建时一并设置，容器随后会立即启动
+	if len(config.Volumes) > 0 {
+		createCmd += " " + strings.Join(volumeOptions(config.Volumes), " ")
+	}
 
 	global.APP_LOG.Info("执行容器创建命令", zap.String("command", createCmd))
 
@@ -281,7 +340,7 @@ func (p *ProxmoxProvider) createVM(ctx context.Context, vmid int, config provide
 
 		// 下载镜像文件
 		downloadCmd := fmt.Sprintf("curl -L -o %s %s", localImagePath, downloadURL)
-		_, err = p.sshClient.Execute(downloadCmd)
+		_, err = p.sshClient.ExecuteOperation(utils.SSHOperationDownload, downloadCmd)
 		if err != nil {
 			return fmt.Errorf("下载镜像失败: %v", err)
 		}
@@ -338,6 +397,9 @@ func (p *ProxmoxProvider) createVM(ctx context.Context, vmid int, config provide
 	}
 
 	storage := providerRecord.StoragePool
+	if config.StoragePool != "" {
+		storage = config.StoragePool
+	}
 	if storage == "" {
 		storage = "local" // 默认存储
 	}
@@ -396,7 +458,7 @@ func (p *ProxmoxProvider) createVM(ctx context.Context, vmid int, config provide
 		importCmd = fmt.Sprintf("qm importdisk %d %s %s", vmid, localImagePath, storage)
 	}
 
-	_, err = p.sshClient.Execute(importCmd)
+	_, err = p.sshClient.ExecuteOperation(utils.SSHOperationDownload, importCmd)
 	if err != nil {
 		return fmt.Errorf("导入磁盘镜像失败: %v", err)
 	}
@@ -566,9 +628,8 @@ func (p *ProxmoxProvider) createVM(ctx context.Context, vmid int, config provide
 		password = utils.GenerateInstancePassword()
 	}
 
-	_, err = p.sshClient.Execute(fmt.Sprintf("qm set %d --cipassword %s --ciuser root", vmid, password))
-	if err != nil {
-		global.APP_LOG.Warn("设置用户密码失败", zap.Int("vmid", vmid), zap.Error(err))
+	if err := p.applyCloudInit(vmid, config, password); err != nil {
+		global.APP_LOG.Warn("写入cloud-init配置失败", zap.Int("vmid", vmid), zap.Error(err))
 	}
 
 	// 设置虚拟机名称，以便后续能够通过名称查找
@@ -48,144 +48,195 @@ func (p *ProxmoxProvider) sshDeleteInstance(ctx context.Context, id string) erro
 	}
 
 	// 执行完整的删除流程
-	if instanceType == "container" {
-		return p.handleCTDeletion(ctx, vmid, ipAddress)
-	} else {
-		return p.handleVMDeletion(ctx, vmid, ipAddress)
-	}
+	return p.handleDeletion(ctx, vmid, instanceType, ipAddress)
 }
 
-// handleVMDeletion 处理VM删除
-func (p *ProxmoxProvider) handleVMDeletion(ctx context.Context, vmid string, ipAddress string) error {
-	global.APP_LOG.Info("开始VM删除流程",
-		zap.String("vmid", vmid),
-		zap.String("ip", ipAddress))
-
-	// 1. 解锁VM
-	global.APP_LOG.Info("解锁VM", zap.String("vmid", vmid))
-	_, err := p.sshClient.Execute(fmt.Sprintf("qm unlock %s 2>/dev/null || true", vmid))
-	if err != nil {
-		global.APP_LOG.Warn("解锁VM失败", zap.String("vmid", vmid), zap.Error(err))
-	}
-
-	// 2. 清理端口映射 - 在停止VM之前清理，确保能获取到实例名称
-	if err := p.cleanupInstancePortMappings(ctx, vmid, "vm"); err != nil {
-		global.APP_LOG.Warn("清理VM端口映射失败", zap.String("vmid", vmid), zap.Error(err))
-		// 端口映射清理失败不应该阻止VM删除，继续执行
-	}
-
-	// 3. 停止VM
-	global.APP_LOG.Info("停止VM", zap.String("vmid", vmid))
-	_, err = p.sshClient.Execute(fmt.Sprintf("qm stop %s 2>/dev/null || true", vmid))
-	if err != nil {
-		global.APP_LOG.Warn("停止VM失败", zap.String("vmid", vmid), zap.Error(err))
-	}
-
-	// 4. 检查VM是否完全停止
-	if err := p.checkVMCTStatus(ctx, vmid, "vm"); err != nil {
-		global.APP_LOG.Warn("VM未完全停止", zap.String("vmid", vmid), zap.Error(err))
-		// 继续执行删除，但记录警告
-	}
+// deleteStep 删除实例流程中的一个步骤，执行删除和预览删除计划共用同一份步骤列表
+type deleteStep struct {
+	name  string       // 步骤名称，用于日志
+	plan  string       // 预览时展示的命令或说明
+	run   func() error // 执行该步骤
+	fatal bool         // 失败时是否中止删除流程，否则仅记录警告后继续
+}
 
-	// 5. 删除VM
-	global.APP_LOG.Info("销毁VM", zap.String("vmid", vmid))
-	_, err = p.sshClient.Execute(fmt.Sprintf("qm destroy %s", vmid))
-	if err != nil {
-		global.APP_LOG.Error("销毁VM失败", zap.String("vmid", vmid), zap.Error(err))
-		return fmt.Errorf("销毁VM失败 (VMID: %s): %w", vmid, err)
-	}
+// sshDeleteSteps 通过SSH删除实例的完整步骤
+func (p *ProxmoxProvider) sshDeleteSteps(ctx context.Context, vmid, instanceType, ipAddress string) []deleteStep {
+	tool, label := "qm", "VM"
+	if instanceType == "container" {
+		tool, label = "pct", "CT"
+	}
+
+	var steps []deleteStep
+	if tool == "qm" {
+		unlockCmd := fmt.Sprintf("qm unlock %s 2>/dev/null || true", vmid)
+		steps = append(steps, deleteStep{
+			name: "解锁VM",
+			plan: unlockCmd,
+			run: func() error {
+				_, err := p.sshClient.Execute(unlockCmd)
+				return err
+			},
+		})
+	}
+
+	stopCmd := fmt.Sprintf("%s stop %s 2>/dev/null || true", tool, vmid)
+	destroyCmd := fmt.Sprintf("%s destroy %s", tool, vmid)
+	steps = append(steps,
+		// 在停止实例之前清理端口映射，确保能获取到实例名称；失败不阻止删除
+		deleteStep{
+			name: "清理" + label + "端口映射",
+			plan: "# 清理实例端口映射",
+			run:  func() error { return p.cleanupInstancePortMappings(ctx, vmid, instanceType) },
+		},
+		deleteStep{
+			name: "停止" + label,
+			plan: stopCmd,
+			run: func() error {
+				_, err := p.sshClient.Execute(stopCmd)
+				return err
+			},
+		},
+		deleteStep{
+			name: "等待" + label + "完全停止",
+			plan: fmt.Sprintf("%s status %s 2>/dev/null | grep -w 'status:' | awk '{print $2}'", tool, vmid),
+			run:  func() error { return p.checkVMCTStatus(ctx, vmid, instanceType) },
+		},
+		deleteStep{
+			name: "销毁" + label,
+			plan: destroyCmd,
+			run: func() error {
+				_, err := p.sshClient.Execute(destroyCmd)
+				return err
+			},
+			fatal: true,
+		},
+	)
+	return append(steps, p.postDeleteSteps(ctx, vmid, instanceType, ipAddress)...)
+}
 
-	// 6. 清理IPv6 NAT映射规则
-	if err := p.cleanupIPv6NATRules(ctx, vmid); err != nil {
-		global.APP_LOG.Warn("清理IPv6 NAT规则失败", zap.String("vmid", vmid), zap.Error(err))
+// postDeleteSteps 实例销毁后的清理步骤，SSH和API删除共用
+func (p *ProxmoxProvider) postDeleteSteps(ctx context.Context, vmid, instanceType, ipAddress string) []deleteStep {
+	cleanupFiles, dirPrefix := p.cleanupVMFiles, "vm"
+	if instanceType == "container" {
+		cleanupFiles, dirPrefix = p.cleanupCTFiles, "ct"
 	}
 
-	// 7. 清理VM相关文件
-	if err := p.cleanupVMFiles(ctx, vmid); err != nil {
-		global.APP_LOG.Warn("清理VM文件失败", zap.String("vmid", vmid), zap.Error(err))
+	steps := []deleteStep{
+		{
+			name: "清理IPv6 NAT规则",
+			plan: "# 清理IPv6 NAT映射规则",
+			run:  func() error { return p.cleanupIPv6NATRules(ctx, vmid) },
+		},
+		{
+			name: "清理实例文件",
+			plan: fmt.Sprintf("pvesm status | awk 'NR > 1 {print $1}' # 逐个存储清理 VMID %s 相关卷，并删除 /root/%s%s", vmid, dirPrefix, vmid),
+			run:  func() error { return cleanupFiles(ctx, vmid) },
+		},
 	}
-
-	// 8. 更新iptables规则
 	if ipAddress != "" {
-		if err := p.updateIPTablesRules(ctx, ipAddress); err != nil {
-			global.APP_LOG.Warn("更新iptables规则失败", zap.String("ip", ipAddress), zap.Error(err))
-		}
-	}
-
-	// 9. 重建iptables规则
-	if err := p.rebuildIPTablesRules(ctx); err != nil {
-		global.APP_LOG.Warn("重建iptables规则失败", zap.Error(err))
-	}
+		steps = append(steps, deleteStep{
+			name: "更新iptables规则",
+			plan: fmt.Sprintf("sed -i '/%s:/d' '/etc/iptables/rules.v4'", ipAddress),
+			run:  func() error { return p.updateIPTablesRules(ctx, ipAddress) },
+		})
+	}
+	return append(steps,
+		deleteStep{
+			name: "重建iptables规则",
+			plan: "cat '/etc/iptables/rules.v4' | iptables-restore",
+			run:  func() error { return p.rebuildIPTablesRules(ctx) },
+		},
+		deleteStep{
+			name: "重启ndpresponder服务",
+			plan: "systemctl restart ndpresponder.service",
+			run:  func() error { return p.restartNDPResponder(ctx) },
+		},
+	)
+}
 
-	// 10. 重启ndpresponder服务
-	if err := p.restartNDPResponder(ctx); err != nil {
-		global.APP_LOG.Warn("重启ndpresponder服务失败", zap.Error(err))
+// runDeleteSteps 按顺序执行删除步骤，非关键步骤失败只记录警告
+func runDeleteSteps(steps []deleteStep, vmid string) error {
+	for _, step := range steps {
+		global.APP_LOG.Info(step.name, zap.String("vmid", vmid))
+		if err := step.run(); err != nil {
+			if step.fatal {
+				global.APP_LOG.Error(step.name+"失败", zap.String("vmid", vmid), zap.Error(err))
+				return fmt.Errorf("%s失败 (VMID: %s): %w", step.name, vmid, err)
+			}
+			global.APP_LOG.Warn(step.name+"失败", zap.String("vmid", vmid), zap.Error(err))
+		}
 	}
-
-	global.APP_LOG.Info("通过SSH成功删除Proxmox虚拟机", zap.String("vmid", vmid))
 	return nil
 }
 
-// handleCTDeletion 处理CT删除
-func (p *ProxmoxProvider) handleCTDeletion(ctx context.Context, ctid string, ipAddress string) error {
-	global.APP_LOG.Info("开始CT删除流程",
-		zap.String("ctid", ctid),
+// handleDeletion 通过SSH执行VM/CT删除流程
+func (p *ProxmoxProvider) handleDeletion(ctx context.Context, vmid, instanceType, ipAddress string) error {
+	global.APP_LOG.Info("开始实例删除流程",
+		zap.String("vmid", vmid),
+		zap.String("type", instanceType),
 		zap.String("ip", ipAddress))
 
-	// 1. 清理端口映射 - 在停止CT之前清理，确保能获取到实例名称
-	if err := p.cleanupInstancePortMappings(ctx, ctid, "container"); err != nil {
-		global.APP_LOG.Warn("清理CT端口映射失败", zap.String("ctid", ctid), zap.Error(err))
-		// 端口映射清理失败不应该阻止CT删除，继续执行
+	if err := runDeleteSteps(p.sshDeleteSteps(ctx, vmid, instanceType, ipAddress), vmid); err != nil {
+		return err
 	}
 
-	// 2. 停止容器
-	global.APP_LOG.Info("停止CT", zap.String("ctid", ctid))
-	_, err := p.sshClient.Execute(fmt.Sprintf("pct stop %s 2>/dev/null || true", ctid))
-	if err != nil {
-		global.APP_LOG.Warn("停止CT失败", zap.String("ctid", ctid), zap.Error(err))
-	}
+	global.APP_LOG.Info("通过SSH成功删除Proxmox实例",
+		zap.String("vmid", vmid),
+		zap.String("type", instanceType))
+	return nil
+}
 
-	// 3. 检查容器是否完全停止
-	if err := p.checkVMCTStatus(ctx, ctid, "container"); err != nil {
-		global.APP_LOG.Warn("CT未完全停止", zap.String("ctid", ctid), zap.Error(err))
-		// 继续执行删除，但记录警告
+// PlanDeleteInstance 返回删除实例时将按顺序执行的操作（仅预览，不执行）
+// 会先解析实例对应的VMID，确认删除目标
+func (p *ProxmoxProvider) PlanDeleteInstance(ctx context.Context, id string) ([]string, error) {
+	if !p.connected {
+		return nil, fmt.Errorf("not connected")
+	}
+	if id == "" {
+		return nil, fmt.Errorf("实例名称不能为空")
 	}
 
-	// 4. 删除容器
-	global.APP_LOG.Info("销毁CT", zap.String("ctid", ctid))
-	_, err = p.sshClient.Execute(fmt.Sprintf("pct destroy %s", ctid))
+	vmid, instanceType, err := p.findVMIDByNameOrID(ctx, id)
 	if err != nil {
-		global.APP_LOG.Error("销毁CT失败", zap.String("ctid", ctid), zap.Error(err))
-		return fmt.Errorf("销毁CT失败 (CTID: %s): %w", ctid, err)
+		return nil, fmt.Errorf("无法找到实例 %s 对应的VMID: %w", id, err)
 	}
 
-	// 5. 清理CT相关文件
-	if err := p.cleanupCTFiles(ctx, ctid); err != nil {
-		global.APP_LOG.Warn("清理CT文件失败", zap.String("ctid", ctid), zap.Error(err))
+	// 与实际删除一样先获取实例IP，决定是否需要更新iptables规则
+	ipAddress, err := p.getInstanceIPAddress(ctx, vmid, instanceType)
+	if err != nil {
+		ipAddress = ""
 	}
 
-	// 6. 清理IPv6 NAT映射规则
-	if err := p.cleanupIPv6NATRules(ctx, ctid); err != nil {
-		global.APP_LOG.Warn("清理IPv6 NAT规则失败", zap.String("ctid", ctid), zap.Error(err))
+	commands := []string{
+		fmt.Sprintf("# 目标实例: %s (VMID: %s, 类型: %s)", id, vmid, instanceType),
+		"# 清理pmacct监控数据",
 	}
 
-	// 7. 更新iptables规则
-	if ipAddress != "" {
-		if err := p.updateIPTablesRules(ctx, ipAddress); err != nil {
-			global.APP_LOG.Warn("更新iptables规则失败", zap.String("ip", ipAddress), zap.Error(err))
+	if p.shouldUseAPI() {
+		endpoint := "qemu"
+		if instanceType == "container" {
+			endpoint = "lxc"
+		} else {
+			commands = append(commands, fmt.Sprintf("qm unlock %s 2>/dev/null || true", vmid))
 		}
+		commands = append(commands,
+			fmt.Sprintf("# API: POST https://%s:8006/api2/json/nodes/%s/%s/%s/status/stop", p.config.Host, p.node, endpoint, vmid),
+			fmt.Sprintf("# API: DELETE https://%s:8006/api2/json/nodes/%s/%s/%s", p.config.Host, p.node, endpoint, vmid),
+		)
+		for _, step := range p.postDeleteSteps(ctx, vmid, instanceType, ipAddress) {
+			commands = append(commands, step.plan)
+		}
+		// 与DeleteInstance一致：不允许回退或不允许使用SSH时，只会执行API删除
+		if !p.shouldFallbackToSSH() || !p.shouldUseSSH() {
+			return commands, nil
+		}
+		commands = append(commands, "# API失败时回退到SSH")
+	} else if !p.shouldUseSSH() {
+		return nil, fmt.Errorf("执行规则不允许使用SSH")
 	}
 
-	// 8. 重建iptables规则
-	if err := p.rebuildIPTablesRules(ctx); err != nil {
-		global.APP_LOG.Warn("重建iptables规则失败", zap.Error(err))
-	}
-
-	// 9. 重启ndpresponder服务
-	if err := p.restartNDPResponder(ctx); err != nil {
-		global.APP_LOG.Warn("重启ndpresponder服务失败", zap.Error(err))
+	for _, step := range p.sshDeleteSteps(ctx, vmid, instanceType, ipAddress) {
+		commands = append(commands, step.plan)
 	}
-
-	global.APP_LOG.Info("通过SSH成功删除Proxmox容器", zap.String("ctid", ctid))
-	return nil
+	return commands, nil
 }
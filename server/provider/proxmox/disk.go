@@ -0,0 +1,81 @@
+package proxmox
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"oneclickvirt/global"
+
+	"go.uber.org/zap"
+)
+
+// ResizeInstanceDisk 调整实例根磁盘大小（单位GB）
+// Proxmox 不支持缩小磁盘，新大小必须大于当前大小
+func (p *ProxmoxProvider) ResizeInstanceDisk(ctx context.Context, instanceName string, newSizeGB int) error {
+	if !p.connected || p.sshClient == nil {
+		return fmt.Errorf("not connected")
+	}
+	if newSizeGB <= 0 {
+		return fmt.Errorf("无效的磁盘大小: %d", newSizeGB)
+	}
+
+	vmid, instanceType, err := p.findVMIDByNameOrID(ctx, instanceName)
+	if err != nil {
+		return fmt.Errorf("查找实例失败: %w", err)
+	}
+
+	// 容器使用rootfs，虚拟机使用scsi0
+	disk := "scsi0"
+	configCmd := fmt.Sprintf("qm config %s | grep '^scsi0:' | awk -F'size=' '{print $2}' | awk -F',' '{print $1}'", vmid)
+	resizeTool := "qm"
+	if instanceType == "container" {
+		disk = "rootfs"
+		configCmd = fmt.Sprintf("pct config %s | grep '^rootfs:' | awk -F'size=' '{print $2}' | awk -F',' '{print $1}'", vmid)
+		resizeTool = "pct"
+	}
+
+	// 检查当前磁盘大小，防止缩小
+	currentOutput, err := p.sshClient.Execute(configCmd)
+	if err == nil {
+		if currentMB := parseProxmoxSizeMB(strings.TrimSpace(currentOutput)); currentMB > 0 && int64(newSizeGB)*1024 <= currentMB {
+			return fmt.Errorf("Proxmox 不支持缩小磁盘，当前大小 %s，目标大小 %dGB", strings.TrimSpace(currentOutput), newSizeGB)
+		}
+	}
+
+	resizeCmd := fmt.Sprintf("%s resize %s %s %dG", resizeTool, vmid, disk, newSizeGB)
+	if output, err := p.sshClient.Execute(resizeCmd); err != nil {
+		return fmt.Errorf("调整磁盘大小失败: %s: %w", strings.TrimSpace(output), err)
+	}
+
+	global.APP_LOG.Info("Proxmox实例磁盘大小调整成功",
+		zap.String("instance", instanceName),
+		zap.String("vmid", vmid),
+		zap.String("type", instanceType),
+		zap.Int("newSizeGB", newSizeGB))
+	return nil
+}
+
+// parseProxmoxSizeMB 解析 Proxmox 磁盘大小字符串（如 10G、512M、1T）为MB
+func parseProxmoxSizeMB(size string) int64 {
+	if size == "" {
+		return 0
+	}
+	unit := size[len(size)-1]
+	num, err := strconv.ParseFloat(size[:len(size)-1], 64)
+	if err != nil {
+		return 0
+	}
+	switch unit {
+	case 'T':
+		return int64(num * 1024 * 1024)
+	case 'G':
+		return int64(num * 1024)
+	case 'M':
+		return int64(num)
+	case 'K':
+		return int64(num / 1024)
+	}
+	return 0
+}
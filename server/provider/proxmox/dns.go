@@ -0,0 +1,48 @@
+package proxmox
+
+import (
+	"fmt"
+	"strings"
+
+	"oneclickvirt/global"
+	"oneclickvirt/provider"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+)
+
+// applyNameservers 在实例配置中写入自定义DNS服务器，覆盖创建过程中设置的默认DNS
+// 容器在下次启动时由Proxmox写入resolv.conf，虚拟机通过cloud-init生效
+func (p *ProxmoxProvider) applyNameservers(vmid int, config provider.InstanceConfig) {
+	if len(config.DNSServers) == 0 {
+		return
+	}
+
+	tool := "qm"
+	if config.InstanceType == "container" {
+		tool = "pct"
+	}
+	cmd := fmt.Sprintf("%s set %d --nameserver '%s'", tool, vmid, strings.Join(config.DNSServers, " "))
+	if output, err := p.sshClient.Execute(cmd); err != nil {
+		global.APP_LOG.Warn("设置自定义DNS失败",
+			zap.Int("vmid", vmid),
+			zap.Strings("dnsServers", config.DNSServers),
+			zap.String("output", utils.TruncateString(output, 200)),
+			zap.Error(err))
+	}
+}
+
+// writeContainerResolvConf 容器在创建过程中已经启动过，直接改写运行中容器的resolv.conf使自定义DNS立即生效
+func (p *ProxmoxProvider) writeContainerResolvConf(vmid int, config provider.InstanceConfig) {
+	if len(config.DNSServers) == 0 || config.InstanceType != "container" {
+		return
+	}
+
+	cmd := fmt.Sprintf("pct exec %d -- sh -c %s", vmid, utils.ShellQuote(provider.ResolvConfCommand(config.DNSServers)))
+	if output, err := p.sshClient.Execute(cmd); err != nil {
+		global.APP_LOG.Warn("写入容器resolv.conf失败",
+			zap.Int("vmid", vmid),
+			zap.String("output", utils.TruncateString(output, 200)),
+			zap.Error(err))
+	}
+}
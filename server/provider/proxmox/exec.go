@@ -0,0 +1,68 @@
+package proxmox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"oneclickvirt/provider"
+	"oneclickvirt/utils"
+)
+
+// qmGuestExecResult qm guest exec 的输出结构
+type qmGuestExecResult struct {
+	ExitCode int    `json:"exitcode"`
+	Exited   int    `json:"exited"`
+	OutData  string `json:"out-data"`
+	ErrData  string `json:"err-data"`
+}
+
+// ExecInInstance 在实例内执行命令
+// 容器使用pct exec，虚拟机通过qemu-guest-agent执行
+func (p *ProxmoxProvider) ExecInInstance(ctx context.Context, instanceName, command string, timeout time.Duration, maxOutput int) (*utils.CommandResult, error) {
+	if err := provider.ValidateInstanceName(instanceName); err != nil {
+		return nil, err
+	}
+	if !p.connected || p.sshClient == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+
+	vmid, instanceType, err := p.findVMIDByNameOrID(ctx, instanceName)
+	if err != nil {
+		return nil, fmt.Errorf("查找实例失败: %w", err)
+	}
+
+	if instanceType == "container" {
+		cmd := provider.WithExecTimeout(fmt.Sprintf("pct exec %s -- sh -c %s", vmid, utils.ShellQuote(command)), timeout)
+		return p.sshClient.ExecuteCapture(cmd, timeout+provider.ExecTimeoutGrace, maxOutput)
+	}
+
+	// guest exec 的输出先经过JSON编码，宿主机侧的输出上限需要留出转义余量
+	seconds := int(math.Ceil(timeout.Seconds()))
+	cmd := fmt.Sprintf("qm guest exec %s --timeout %d -- sh -c %s", vmid, seconds, utils.ShellQuote(command))
+	raw, err := p.sshClient.ExecuteCapture(cmd, timeout+provider.ExecTimeoutGrace, maxOutput*4)
+	if err != nil {
+		return nil, err
+	}
+	if raw.ExitCode != 0 {
+		return nil, fmt.Errorf("qemu-guest-agent执行失败: %s", strings.TrimSpace(raw.Stderr+raw.Stdout))
+	}
+
+	var guest qmGuestExecResult
+	if err := json.Unmarshal([]byte(raw.Stdout), &guest); err != nil {
+		return nil, fmt.Errorf("解析guest exec输出失败: %w", err)
+	}
+	if guest.Exited == 0 {
+		return nil, fmt.Errorf("command execution timeout after %v", timeout)
+	}
+
+	return &utils.CommandResult{
+		Stdout:    utils.TruncateString(guest.OutData, maxOutput),
+		Stderr:    utils.TruncateString(guest.ErrData, maxOutput),
+		ExitCode:  guest.ExitCode,
+		Truncated: raw.Truncated || len(guest.OutData) > maxOutput || len(guest.ErrData) > maxOutput,
+	}, nil
+}
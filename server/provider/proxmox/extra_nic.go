@@ -0,0 +1,123 @@
+package proxmox
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"oneclickvirt/global"
+	"oneclickvirt/provider"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+)
+
+// validateExtraNICs 校验额外网卡配置并检查引用的网桥在宿主机上存在
+func (p *ProxmoxProvider) validateExtraNICs(config provider.InstanceConfig) error {
+	if len(config.NetworkInterfaces) == 0 {
+		return nil
+	}
+	if err := provider.ValidateNetworkInterfaces(config.NetworkInterfaces); err != nil {
+		return err
+	}
+	if p.sshClient == nil {
+		return fmt.Errorf("附加额外网卡需要SSH连接")
+	}
+	for _, nic := range config.NetworkInterfaces {
+		if _, err := p.sshClient.Execute(fmt.Sprintf("[ -d /sys/class/net/%s/bridge ]", nic.Network)); err != nil {
+			return fmt.Errorf("网桥 %s 不存在", nic.Network)
+		}
+	}
+	return nil
+}
+
+// extraNICOptions 生成pct/qm set的额外网卡参数，net0/net1保留给默认的IPv4/IPv6网卡
+func extraNICOptions(config provider.InstanceConfig) ([]string, error) {
+	var options []string
+	for idx, nic := range config.NetworkInterfaces {
+		index := provider.FirstExtraInterfaceIndex + idx
+		mac := strings.ToUpper(nic.MACAddress)
+
+		if config.InstanceType == "container" {
+			netValue := fmt.Sprintf("name=eth%d,bridge=%s", index, nic.Network)
+			if nic.IPAddress != "" {
+				cidr, err := provider.InterfaceAddressCIDR(nic.IPAddress)
+				if err != nil {
+					return nil, err
+				}
+				if strings.Contains(cidr, ":") {
+					netValue += ",ip6=" + cidr
+				} else {
+					netValue += ",ip=" + cidr
+				}
+			}
+			if mac != "" {
+				netValue += ",hwaddr=" + mac
+			}
+			options = append(options, fmt.Sprintf("--net%d %s", index, netValue))
+			continue
+		}
+
+		model := "virtio"
+		if mac != "" {
+			model = "virtio=" + mac
+		}
+		options = append(options, fmt.Sprintf("--net%d %s,bridge=%s,firewall=0", index, model, nic.Network))
+		if nic.IPAddress != "" {
+			cidr, err := provider.InterfaceAddressCIDR(nic.IPAddress)
+			if err != nil {
+				return nil, err
+			}
+			key := "ip"
+			if strings.Contains(cidr, ":") {
+				key = "ip6"
+			}
+			// 仅在挂载了cloud-init驱动器时生效
+			options = append(options, fmt.Sprintf("--ipconfig%d %s=%s", index, key, cidr))
+		}
+	}
+	return options, nil
+}
+
+// attachExtraNICs 在实例首次启动前添加额外网卡，失败时销毁已创建的实例
+func (p *ProxmoxProvider) attachExtraNICs(vmid int, config provider.InstanceConfig) error {
+	if len(config.NetworkInterfaces) == 0 {
+		return nil
+	}
+	options, err := extraNICOptions(config)
+	if err != nil {
+		return err
+	}
+
+	tool := "qm"
+	if config.InstanceType == "container" {
+		tool = "pct"
+	}
+	cmd := fmt.Sprintf("%s set %d %s", tool, vmid, strings.Join(options, " "))
+	if output, err := p.sshClient.Execute(cmd); err != nil {
+		global.APP_LOG.Error("添加额外网卡失败，销毁已创建的实例",
+			zap.Int("vmid", vmid),
+			zap.String("output", utils.TruncateString(output, 200)),
+			zap.Error(err))
+		p.sshClient.Execute(fmt.Sprintf("%s destroy %d", tool, vmid))
+		return fmt.Errorf("添加额外网卡失败: %w", err)
+	}
+
+	global.APP_LOG.Info("已为实例添加额外网卡",
+		zap.Int("vmid", vmid),
+		zap.Int("count", len(config.NetworkInterfaces)))
+	return nil
+}
+
+// ExtraInterfaceNames 返回实例额外网卡在宿主机侧的接口名称
+func (p *ProxmoxProvider) ExtraInterfaceNames(ctx context.Context, instanceName string, count int) ([]string, error) {
+	vmid, instanceType, err := p.findVMIDByNameOrID(ctx, instanceName)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, count)
+	for idx := 0; idx < count; idx++ {
+		names = append(names, hostInterfaceNameAt(vmid, instanceType, provider.FirstExtraInterfaceIndex+idx))
+	}
+	return names, nil
+}
@@ -0,0 +1,66 @@
+package proxmox
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"oneclickvirt/global"
+	"oneclickvirt/provider"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+)
+
+// firewallHooks 实例流量经vmbr网桥路由转发，入口链挂在FORWARD上
+var firewallHooks = []string{"FORWARD"}
+
+// ManageFirewallRule 在宿主机上添加或删除实例的防火墙规则，按实例IP匹配发往实例的流量
+// PVE防火墙需要在数据中心和网卡上分别启用，这里统一使用宿主机iptables，与端口映射的实现方式一致
+func (p *ProxmoxProvider) ManageFirewallRule(ctx context.Context, instanceName string, rule provider.FirewallRule) error {
+	if !p.connected || p.sshClient == nil {
+		return fmt.Errorf("not connected")
+	}
+
+	ips, err := p.firewallTargetIPs(ctx, instanceName)
+	if err != nil {
+		return err
+	}
+	cmd, err := provider.BuildFirewallRuleCommand(instanceName, ips, firewallHooks, rule)
+	if err != nil {
+		return err
+	}
+	if output, err := p.sshClient.Execute(cmd); err != nil {
+		return fmt.Errorf("应用防火墙规则失败: %s: %w", utils.TruncateString(strings.TrimSpace(output), 200), err)
+	}
+
+	global.APP_LOG.Info("实例防火墙规则已更新",
+		zap.String("instance", instanceName),
+		zap.String("protocol", rule.Protocol),
+		zap.Int("port", rule.Port),
+		zap.String("action", rule.Action),
+		zap.Bool("remove", rule.Remove))
+	return nil
+}
+
+// ClearFirewallRules 删除实例的全部防火墙规则
+func (p *ProxmoxProvider) ClearFirewallRules(ctx context.Context, instanceName string) error {
+	if !p.connected || p.sshClient == nil {
+		return fmt.Errorf("not connected")
+	}
+	_, err := p.sshClient.Execute(provider.BuildFirewallCleanupCommand(instanceName))
+	return err
+}
+
+// firewallTargetIPs 返回实例的内网IPv4和IPv6地址，IPv6获取失败时只返回IPv4
+func (p *ProxmoxProvider) firewallTargetIPs(ctx context.Context, instanceName string) ([]string, error) {
+	ipv4, err := p.GetInstanceIPv4(ctx, instanceName)
+	if err != nil {
+		return nil, fmt.Errorf("获取实例IP失败: %w", err)
+	}
+	ips := []string{ipv4}
+	if ipv6, err := p.GetInstanceIPv6(ctx, instanceName); err == nil && ipv6 != "" {
+		ips = append(ips, ipv6)
+	}
+	return ips, nil
+}
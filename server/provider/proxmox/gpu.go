@@ -0,0 +1,149 @@
+package proxmox
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"oneclickvirt/global"
+	"oneclickvirt/provider"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+)
+
+// 只读取虚拟机配置的当前部分，快照段（以[开头）中的直通设备不计入占用
+const hostPCIAssignmentCommand = `awk 'FNR==1{skip=0} /^\[/{skip=1} !skip && /^(name|hostpci[0-9]+):/{print FILENAME"|"$0}' /etc/pve/qemu-server/*.conf 2>/dev/null`
+
+// ListGPUs 列出宿主机上的GPU及其直通占用情况
+func (p *ProxmoxProvider) ListGPUs(ctx context.Context) ([]provider.GPUDevice, error) {
+	if p.sshClient == nil {
+		return nil, fmt.Errorf("查询GPU需要SSH连接")
+	}
+	output, err := p.sshClient.Execute(provider.GPUListCommand)
+	if err != nil {
+		return nil, fmt.Errorf("查询宿主机GPU失败: %w", err)
+	}
+	gpus := provider.ParseGPUList(output)
+	if len(gpus) == 0 {
+		return gpus, nil
+	}
+
+	// 没有任何虚拟机时通配符不匹配，awk会报错，忽略错误按无占用处理
+	assignments, _ := p.sshClient.Execute(hostPCIAssignmentCommand)
+	owners := parseHostPCIAssignments(assignments)
+	for idx := range gpus {
+		gpus[idx].AssignedTo = hostPCIOwner(owners, gpus[idx].Address)
+	}
+	return gpus, nil
+}
+
+// parseHostPCIAssignments 解析虚拟机配置中的hostpci条目，返回PCI地址到虚拟机名称的映射
+// 未指定功能号的地址（如01:00）表示直通该设备的所有功能，映射的键不带功能号
+func parseHostPCIAssignments(output string) map[string]string {
+	names := make(map[string]string)
+	devices := make(map[string][]string)
+	for _, line := range strings.Split(output, "\n") {
+		file, entry, ok := strings.Cut(strings.TrimSpace(line), "|")
+		if !ok {
+			continue
+		}
+		key, value, ok := strings.Cut(entry, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if key == "name" {
+			names[file] = value
+			continue
+		}
+		// 形如 host=0000:01:00.0;0000:02:00.0,pcie=1 或 01:00,x-vga=1
+		hostValue := strings.SplitN(value, ",", 2)[0]
+		hostValue = strings.TrimPrefix(hostValue, "host=")
+		if strings.HasPrefix(hostValue, "mapping=") {
+			continue
+		}
+		for _, addr := range strings.Split(hostValue, ";") {
+			addr = strings.ToLower(strings.TrimSpace(addr))
+			if addr == "" {
+				continue
+			}
+			if strings.Count(addr, ":") == 1 {
+				addr = "0000:" + addr
+			}
+			devices[file] = append(devices[file], addr)
+		}
+	}
+
+	owners := make(map[string]string)
+	for file, addrs := range devices {
+		owner := names[file]
+		if owner == "" {
+			owner = "VMID " + strings.TrimSuffix(filepath.Base(file), ".conf")
+		}
+		for _, addr := range addrs {
+			owners[addr] = owner
+		}
+	}
+	return owners
+}
+
+func hostPCIOwner(owners map[string]string, addr string) string {
+	if owner, ok := owners[addr]; ok {
+		return owner
+	}
+	if dot := strings.LastIndex(addr, "."); dot > 0 {
+		return owners[addr[:dot]]
+	}
+	return ""
+}
+
+// validateGPUs 校验直通GPU配置：仅支持虚拟机，设备必须存在、已开启IOMMU且未被其他虚拟机占用
+func (p *ProxmoxProvider) validateGPUs(ctx context.Context, config *provider.InstanceConfig) error {
+	if len(config.GPUDevices) == 0 {
+		return nil
+	}
+	if config.InstanceType != "vm" {
+		return fmt.Errorf("GPU直通仅支持虚拟机")
+	}
+	devices, err := provider.ValidateGPUDevices(config.GPUDevices)
+	if err != nil {
+		return err
+	}
+	gpus, err := p.ListGPUs(ctx)
+	if err != nil {
+		return err
+	}
+	if err := provider.CheckGPUsAssignable(devices, gpus, config.Name); err != nil {
+		return err
+	}
+	config.GPUDevices = devices
+	return nil
+}
+
+// attachGPUs 在虚拟机首次启动前添加直通GPU，失败时销毁已创建的虚拟机
+func (p *ProxmoxProvider) attachGPUs(vmid int, config provider.InstanceConfig) error {
+	if len(config.GPUDevices) == 0 {
+		return nil
+	}
+	options := make([]string, 0, len(config.GPUDevices))
+	for idx, addr := range config.GPUDevices {
+		options = append(options, fmt.Sprintf("--hostpci%d %s", idx, addr))
+	}
+
+	cmd := fmt.Sprintf("qm set %d %s", vmid, strings.Join(options, " "))
+	if output, err := p.sshClient.Execute(cmd); err != nil {
+		global.APP_LOG.Error("添加直通GPU失败，销毁已创建的虚拟机",
+			zap.Int("vmid", vmid),
+			zap.String("output", utils.TruncateString(output, 200)),
+			zap.Error(err))
+		p.sshClient.Execute(fmt.Sprintf("qm destroy %d", vmid))
+		return fmt.Errorf("添加直通GPU失败: %w", err)
+	}
+
+	global.APP_LOG.Info("已为虚拟机添加直通GPU",
+		zap.Int("vmid", vmid),
+		zap.Strings("devices", config.GPUDevices))
+	return nil
+}
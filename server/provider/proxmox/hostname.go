@@ -0,0 +1,31 @@
+package proxmox
+
+import (
+	"fmt"
+
+	"oneclickvirt/global"
+	"oneclickvirt/provider"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+)
+
+// writeContainerHostname 在容器内设置自定义主机名
+// 容器配置中的hostname仍保持为实例名称，因为按名称查找容器依赖pct list中的该字段；
+// 同时创建/etc/.pve-ignore.hostname和/etc/.pve-ignore.hosts，防止Proxmox在启动时按配置改写
+// 虚拟机的主机名通过cloud-init vendor-data设置，见applyCloudInit
+func (p *ProxmoxProvider) writeContainerHostname(vmid int, config provider.InstanceConfig) {
+	if config.Hostname == "" || config.InstanceType != "container" {
+		return
+	}
+
+	script := "touch /etc/.pve-ignore.hostname /etc/.pve-ignore.hosts && " + provider.SetHostnameCommand(config.Hostname)
+	cmd := fmt.Sprintf("pct exec %d -- sh -c %s", vmid, utils.ShellQuote(script))
+	if output, err := p.sshClient.Execute(cmd); err != nil {
+		global.APP_LOG.Warn("设置容器主机名失败",
+			zap.Int("vmid", vmid),
+			zap.String("hostname", config.Hostname),
+			zap.String("output", utils.TruncateString(output, 200)),
+			zap.Error(err))
+	}
+}
@@ -178,50 +178,11 @@ func (p *ProxmoxProvider) downloadImageToRemote(ctx context.Context, imageURL, i
 	return remotePath, nil
 }
 
-// downloadFileToRemote 在远程服务器上下载文件
+// downloadFileToRemote 在远程服务器上下载文件，中断后保留部分文件以便下次断点续传
 func (p *ProxmoxProvider) downloadFileToRemote(url, remotePath string) error {
-	tmpPath := remotePath + ".tmp"
-
-	// 下载文件，支持断点续传，优先使用wget，失败则使用curl
-	downloadCmds := []string{
-		fmt.Sprintf("wget --no-check-certificate -c -O %s '%s'", tmpPath, url),
-		fmt.Sprintf("curl -4 -L -C - --connect-timeout 30 --retry 5 --retry-delay 10 --retry-max-time 0 -o %s '%s'", tmpPath, url),
-	}
-
-	var lastErr error
-	for _, cmd := range downloadCmds {
-		global.APP_LOG.Info("执行下载命令",
-			zap.String("url", utils.TruncateString(url, 100)))
-
-		output, err := p.sshClient.Execute(cmd)
-		if err == nil {
-			// 下载成功，移动文件到最终位置
-			mvCmd := fmt.Sprintf("mv %s %s", tmpPath, remotePath)
-			_, err = p.sshClient.Execute(mvCmd)
-			if err != nil {
-				global.APP_LOG.Error("移动文件失败",
-					zap.String("tmpPath", tmpPath),
-					zap.String("remotePath", remotePath),
-					zap.Error(err))
-				return fmt.Errorf("移动文件失败: %w", err)
-			}
-
-			global.APP_LOG.Info("下载成功",
-				zap.String("url", utils.TruncateString(url, 100)),
-				zap.String("remotePath", remotePath))
-			return nil
-		}
-
-		lastErr = err
-		global.APP_LOG.Warn("下载命令失败，尝试下一个",
-			zap.String("output", utils.TruncateString(output, 500)),
-			zap.Error(err))
-
-		// 清理临时文件
-		p.sshClient.Execute(fmt.Sprintf("rm -f %s", tmpPath))
-	}
-
-	return fmt.Errorf("所有下载方式都失败: %w", lastErr)
+	global.APP_LOG.Info("执行下载命令",
+		zap.String("url", utils.TruncateString(url, 100)))
+	return provider.DownloadRemoteFile(p.sshClient, url, remotePath)
 }
 
 func (p *ProxmoxProvider) DeleteImage(ctx context.Context, id string) error {
@@ -367,7 +328,7 @@ func (p *ProxmoxProvider) downloadImageFromURL(ctx context.Context, imageURL, im
 
 	// 在远程服务器上下载文件
 	if err := p.downloadFileToRemote(imageURL, remotePath); err != nil {
-		// 下载失败，删除不完整的文件
+		// 下载失败，部分文件保留在.tmp中供下次续传
 		p.removeRemoteFile(remotePath)
 		return fmt.Errorf("远程下载镜像失败: %w", err)
 	}
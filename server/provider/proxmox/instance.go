@@ -41,10 +41,18 @@ func (p *ProxmoxProvider) ListInstances(ctx context.Context) ([]provider.Instanc
 }
 
 func (p *ProxmoxProvider) CreateInstance(ctx context.Context, config provider.InstanceConfig) error {
+	if err := provider.ValidateInstanceName(config.Name); err != nil {
+		return err
+	}
+
 	if !p.connected {
 		return fmt.Errorf("not connected")
 	}
 
+	if err := p.validateStoragePool(ctx, config.StoragePool); err != nil {
+		return err
+	}
+
 	// 根据执行规则判断使用哪种方式
 	if p.shouldUseAPI() {
 		err := p.apiCreateInstance(ctx, config)
@@ -70,10 +78,18 @@ func (p *ProxmoxProvider) CreateInstance(ctx context.Context, config provider.In
 }
 
 func (p *ProxmoxProvider) CreateInstanceWithProgress(ctx context.Context, config provider.InstanceConfig, progressCallback provider.ProgressCallback) error {
+	if err := provider.ValidateInstanceName(config.Name); err != nil {
+		return err
+	}
+
 	if !p.connected {
 		return fmt.Errorf("not connected")
 	}
 
+	if err := p.validateStoragePool(ctx, config.StoragePool); err != nil {
+		return err
+	}
+
 	// 根据执行规则判断使用哪种方式
 	if p.shouldUseAPI() {
 		err := p.apiCreateInstanceWithProgress(ctx, config, progressCallback)
@@ -99,6 +115,10 @@ func (p *ProxmoxProvider) CreateInstanceWithProgress(ctx context.Context, config
 }
 
 func (p *ProxmoxProvider) StartInstance(ctx context.Context, id string) error {
+	if err := provider.ValidateInstanceName(id); err != nil {
+		return err
+	}
+
 	if !p.connected {
 		return fmt.Errorf("not connected")
 	}
@@ -128,6 +148,10 @@ func (p *ProxmoxProvider) StartInstance(ctx context.Context, id string) error {
 }
 
 func (p *ProxmoxProvider) StopInstance(ctx context.Context, id string) error {
+	if err := provider.ValidateInstanceName(id); err != nil {
+		return err
+	}
+
 	if !p.connected {
 		return fmt.Errorf("not connected")
 	}
@@ -157,6 +181,10 @@ func (p *ProxmoxProvider) StopInstance(ctx context.Context, id string) error {
 }
 
 func (p *ProxmoxProvider) RestartInstance(ctx context.Context, id string) error {
+	if err := provider.ValidateInstanceName(id); err != nil {
+		return err
+	}
+
 	if !p.connected {
 		return fmt.Errorf("not connected")
 	}
@@ -186,6 +214,10 @@ func (p *ProxmoxProvider) RestartInstance(ctx context.Context, id string) error
 }
 
 func (p *ProxmoxProvider) DeleteInstance(ctx context.Context, id string) error {
+	if err := provider.ValidateInstanceName(id); err != nil {
+		return err
+	}
+
 	if !p.connected {
 		return fmt.Errorf("not connected")
 	}
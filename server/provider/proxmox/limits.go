@@ -0,0 +1,53 @@
+package proxmox
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"oneclickvirt/global"
+	"oneclickvirt/provider"
+
+	"go.uber.org/zap"
+)
+
+// UpdateInstanceLimits 调整实例的CPU核心数和内存大小
+// 容器通过 pct set 立即生效；虚拟机通过 qm set 写入配置，运行中时作为待生效配置，需要重启后生效，
+// 此时返回 provider.ErrLimitsRequireRestart
+func (p *ProxmoxProvider) UpdateInstanceLimits(ctx context.Context, instanceName string, cpu int, memoryMB int64) error {
+	if !p.connected || p.sshClient == nil {
+		return fmt.Errorf("not connected")
+	}
+	if err := provider.ValidateInstanceLimits(cpu, memoryMB); err != nil {
+		return err
+	}
+
+	vmid, instanceType, err := p.findVMIDByNameOrID(ctx, instanceName)
+	if err != nil {
+		return fmt.Errorf("查找实例失败: %w", err)
+	}
+
+	tool := "qm"
+	if instanceType == "container" {
+		tool = "pct"
+	}
+	cmd := fmt.Sprintf("%s set %s --cores %d --memory %d", tool, vmid, cpu, memoryMB)
+	if output, err := p.sshClient.Execute(cmd); err != nil {
+		return fmt.Errorf("调整实例资源限制失败: %s: %w", strings.TrimSpace(output), err)
+	}
+
+	global.APP_LOG.Info("Proxmox实例资源限制已更新",
+		zap.String("instance", instanceName),
+		zap.String("vmid", vmid),
+		zap.String("type", instanceType),
+		zap.Int("cpu", cpu),
+		zap.Int64("memoryMB", memoryMB))
+
+	if instanceType != "container" {
+		status, err := p.sshClient.Execute(fmt.Sprintf("qm status %s", vmid))
+		if err != nil || strings.Contains(status, "running") {
+			return provider.ErrLimitsRequireRestart
+		}
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+package proxmox
+
+import (
+	"context"
+	"time"
+
+	"oneclickvirt/provider"
+)
+
+// GetInstanceLogs 读取实例内的系统日志
+// 容器通过pct exec、虚拟机通过qemu-guest-agent执行journalctl，虚拟机需要安装并启用guest agent
+func (p *ProxmoxProvider) GetInstanceLogs(ctx context.Context, instanceName string, lines int, since time.Time) (string, error) {
+	cmd := provider.JournalLogCommand(provider.ClampLogLines(lines), since)
+	result, err := p.ExecInInstance(ctx, instanceName, cmd, provider.InstanceLogTimeout, provider.InstanceLogMaxOutput)
+	if err != nil {
+		return "", err
+	}
+	return provider.InstanceLogOutput(result)
+}
@@ -179,6 +179,14 @@ func (p *ProxmoxProvider) parseNetworkConfigFromInstanceConfig(config provider.I
 		}
 	}
 
+	// 实例配置中显式指定的带宽优先级最高
+	if config.IngressMbps > 0 {
+		networkConfig.InSpeed = config.IngressMbps
+	}
+	if config.EgressMbps > 0 {
+		networkConfig.OutSpeed = config.EgressMbps
+	}
+
 	// 输出最终的网络配置结果
 	hasIPv6 := networkConfig.NetworkType == "nat_ipv4_ipv6" || networkConfig.NetworkType == "dedicated_ipv4_ipv6" || networkConfig.NetworkType == "ipv6_only"
 	global.APP_LOG.Info("Proxmox网络配置解析完成",
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"oneclickvirt/global"
+	"oneclickvirt/provider"
 	"oneclickvirt/utils"
 
 	"go.uber.org/zap"
@@ -12,6 +13,10 @@ import (
 
 // SetInstancePassword 设置实例密码
 func (p *ProxmoxProvider) SetInstancePassword(ctx context.Context, instanceID, password string) error {
+	if err := provider.ValidateInstanceName(instanceID); err != nil {
+		return err
+	}
+
 	if !p.connected {
 		return fmt.Errorf("provider not connected")
 	}
@@ -45,6 +50,10 @@ func (p *ProxmoxProvider) SetInstancePassword(ctx context.Context, instanceID, p
 
 // ResetInstancePassword 重置实例密码
 func (p *ProxmoxProvider) ResetInstancePassword(ctx context.Context, instanceID string) (string, error) {
+	if err := provider.ValidateInstanceName(instanceID); err != nil {
+		return "", err
+	}
+
 	if !p.connected {
 		return "", fmt.Errorf("provider not connected")
 	}
@@ -61,7 +70,7 @@ func (p *ProxmoxProvider) ResetInstancePassword(ctx context.Context, instanceID
 	return newPassword, nil
 }
 
-// generateRandomPassword 生成随机密码（仅包含数字和大小写英文字母，长度不低于8位）
+// generateRandomPassword 按配置的生成密码策略生成随机密码
 func (p *ProxmoxProvider) generateRandomPassword() string {
 	return utils.GenerateInstancePassword()
 }
@@ -0,0 +1,19 @@
+package proxmox
+
+import (
+	"context"
+	"fmt"
+
+	"oneclickvirt/provider"
+)
+
+// PrewarmImages 预先下载容器模板和虚拟机镜像到ProxmoxVE存储目录
+func (p *ProxmoxProvider) PrewarmImages(ctx context.Context, images []provider.PrewarmImage) ([]provider.PrewarmResult, error) {
+	if !p.connected || p.sshClient == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+
+	return provider.PrewarmEach(ctx, images, func(image provider.PrewarmImage) error {
+		return p.prepareImage(ctx, image.Name, image.InstanceType)
+	}), nil
+}
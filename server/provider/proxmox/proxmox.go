@@ -11,6 +11,7 @@ import (
 	"oneclickvirt/global"
 	"oneclickvirt/provider"
 	"oneclickvirt/provider/health"
+	"oneclickvirt/service/metrics"
 	"oneclickvirt/utils"
 
 	"go.uber.org/zap"
@@ -27,6 +28,8 @@ type ProxmoxProvider struct {
 	providerUUID  string // Provider UUID，用于查询数据库中的配置
 	healthChecker health.HealthChecker
 	mu            sync.RWMutex // 保护并发访问
+
+	capabilities provider.CapabilitiesCache // 能力探测结果缓存
 }
 
 func NewProxmoxProvider() provider.Provider {
@@ -62,6 +65,7 @@ func (p *ProxmoxProvider) GetSupportedInstanceTypes() []string {
 
 func (p *ProxmoxProvider) Connect(ctx context.Context, config provider.NodeConfig) error {
 	p.config = config
+	p.capabilities.Invalidate()
 	p.providerUUID = config.UUID // 存储Provider UUID
 	p.providerID = config.ID     // 存储providerID
 
@@ -94,13 +98,19 @@ func (p *ProxmoxProvider) Connect(ctx context.Context, config provider.NodeConfi
 
 	// 尝试 SSH 连接
 	sshConfig := utils.SSHConfig{
-		Host:           config.Host,
-		Port:           config.Port,
-		Username:       config.Username,
-		Password:       config.Password,
-		PrivateKey:     config.PrivateKey,
-		ConnectTimeout: time.Duration(sshConnectTimeout) * time.Second,
-		ExecuteTimeout: time.Duration(sshExecuteTimeout) * time.Second,
+		Host:                 config.Host,
+		Port:                 config.Port,
+		Username:             config.Username,
+		Password:             config.Password,
+		PrivateKey:           config.PrivateKey,
+		PrivateKeyPassphrase: config.PrivateKeyPassphrase,
+		UseAgent:             config.UseSSHAgent,
+		VerifyHostKey:        config.SSHVerifyHostKey,
+		HostKeyFingerprint:   config.SSHHostKeyFingerprint,
+		OnHostKeyPinned:      config.OnHostKeyPinned,
+		ConnectTimeout:       time.Duration(sshConnectTimeout) * time.Second,
+		ExecuteTimeout:       time.Duration(sshExecuteTimeout) * time.Second,
+		CommandLogID:         config.ID,
 	}
 
 	client, err := utils.NewSSHClient(sshConfig)
@@ -135,20 +145,24 @@ func (p *ProxmoxProvider) Connect(ctx context.Context, config provider.NodeConfi
 
 	// 初始化健康检查器，使用Provider的SSH连接，避免创建独立连接导致节点混淆
 	healthConfig := health.HealthConfig{
-		Host:          config.Host,
-		Port:          config.Port,
-		Username:      config.Username,
-		Password:      config.Password,
-		PrivateKey:    config.PrivateKey,
-		APIEnabled:    p.hasAPIAccess(),
-		APIPort:       8006,
-		APIScheme:     "https",
-		SSHEnabled:    true,
-		SkipTLSVerify: true, // Proxmox通常使用自签名证书，需要跳过TLS验证
-		Timeout:       30 * time.Second,
-		ServiceChecks: []string{"pvestatd", "pvedaemon", "pveproxy"},
-		Token:         config.Token,
-		TokenID:       config.TokenID,
+		Host:                 config.Host,
+		Port:                 config.Port,
+		Username:             config.Username,
+		Password:             config.Password,
+		PrivateKey:           config.PrivateKey,
+		PrivateKeyPassphrase: config.PrivateKeyPassphrase,
+		UseAgent:             config.UseSSHAgent,
+		VerifyHostKey:        config.SSHVerifyHostKey,
+		HostKeyFingerprint:   config.SSHHostKeyFingerprint,
+		APIEnabled:           p.hasAPIAccess(),
+		APIPort:              8006,
+		APIScheme:            "https",
+		SSHEnabled:           true,
+		SkipTLSVerify:        true, // Proxmox通常使用自签名证书，需要跳过TLS验证
+		Timeout:              30 * time.Second,
+		ServiceChecks:        []string{"pvestatd", "pvedaemon", "pveproxy"},
+		Token:                config.Token,
+		TokenID:              config.TokenID,
 	}
 
 	zapLogger, _ := zap.NewProduction()
@@ -158,6 +172,7 @@ func (p *ProxmoxProvider) Connect(ctx context.Context, config provider.NodeConfi
 	global.APP_LOG.Info("Proxmox provider SSH连接成功",
 		zap.String("host", utils.TruncateString(config.Host, 32)),
 		zap.Int("port", config.Port),
+		zap.String("authMethod", client.AuthMethod()),
 		zap.String("node", utils.TruncateString(p.node, 32)),
 		zap.Bool("hasToken", p.hasAPIAccess()))
 
@@ -233,7 +248,7 @@ func (p *ProxmoxProvider) getNodeName(ctx context.Context) error {
 	return nil
 }
 
-// ExecuteSSHCommand 执行SSH命令
+// ExecuteSSHCommand 执行SSH命令，超时按ctx中的操作类型确定
 func (p *ProxmoxProvider) ExecuteSSHCommand(ctx context.Context, command string) (string, error) {
 	if !p.connected || p.sshClient == nil {
 		return "", fmt.Errorf("Proxmox provider not connected")
@@ -242,7 +257,9 @@ func (p *ProxmoxProvider) ExecuteSSHCommand(ctx context.Context, command string)
 	global.APP_LOG.Debug("执行SSH命令",
 		zap.String("command", utils.TruncateString(command, 200)))
 
-	output, err := p.sshClient.Execute(command)
+	start := time.Now()
+	output, err := p.sshClient.ExecuteWithTimeout(command, utils.SSHOperationTimeout(utils.SSHOperationFromContext(ctx)), utils.SSHOutputLimitFromContext(ctx))
+	metrics.ObserveSSHCommand(p.config.Name, start, err)
 	if err != nil {
 		global.APP_LOG.Error("SSH命令执行失败",
 			zap.String("command", utils.TruncateString(command, 200)),
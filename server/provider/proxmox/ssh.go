@@ -3,6 +3,7 @@ package proxmox
 import (
 	"context"
 	"fmt"
+	"net"
 	"strconv"
 	"strings"
 	"time"
@@ -513,16 +514,9 @@ func (p *ProxmoxProvider) sshPullImageToPath(ctx context.Context, imageURL, imag
 		return remotePath, nil
 	}
 
-	// 下载镜像
-	downloadCmd := fmt.Sprintf("wget --no-check-certificate -O %s %s", remotePath, imageURL)
-	_, err = p.sshClient.Execute(downloadCmd)
-	if err != nil {
-		// 尝试使用curl下载
-		downloadCmd = fmt.Sprintf("curl -L -k -o %s %s", remotePath, imageURL)
-		_, err = p.sshClient.Execute(downloadCmd)
-		if err != nil {
-			return "", fmt.Errorf("下载镜像失败: %w", err)
-		}
+	// 下载镜像，先写入临时文件，完成后才出现在最终路径，中断后可续传
+	if err := provider.DownloadRemoteFile(p.sshClient, imageURL, remotePath); err != nil {
+		return "", fmt.Errorf("下载镜像失败: %w", err)
 	}
 
 	global.APP_LOG.Info("Proxmox镜像下载完成", zap.String("remotePath", remotePath))
@@ -570,32 +564,27 @@ func (p *ProxmoxProvider) sshDeleteImage(ctx context.Context, id string) error {
 	return nil
 }
 
-// 获取下一个可用的 VMID
-func (p *ProxmoxProvider) getNextVMID(ctx context.Context, instanceType string) (int, error) {
-	// 根据实例类型确定VMID范围
-	var minVMID, maxVMID int
-	if instanceType == "vm" {
-		minVMID = 100
-		maxVMID = 177 // 虚拟机使用 100-177 (78个ID)
-	} else if instanceType == "container" {
-		minVMID = 178
-		maxVMID = 255 // 容器使用 178-255 (78个ID)
-	} else {
-		return 0, fmt.Errorf("不支持的实例类型: %s", instanceType)
+// vmidRange 返回实例类型对应的VMID范围，内网IP按 172.16.1.{VMID} 分配
+func vmidRange(instanceType string) (int, int, error) {
+	switch instanceType {
+	case "vm":
+		return 100, 177, nil // 虚拟机使用 100-177 (78个ID)
+	case "container":
+		return 178, 255, nil // 容器使用 178-255 (78个ID)
+	default:
+		return 0, 0, fmt.Errorf("不支持的实例类型: %s", instanceType)
 	}
+}
 
-	global.APP_LOG.Info("开始分配VMID",
-		zap.String("instanceType", instanceType),
-		zap.Int("minVMID", minVMID),
-		zap.Int("maxVMID", maxVMID))
-
-	// 获取已使用的VMID列表
+// listUsedVMIDs 获取节点上已使用的VMID
+func (p *ProxmoxProvider) listUsedVMIDs() map[int]bool {
 	usedVMIDs := make(map[int]bool)
-
-	// 获取虚拟机列表
-	vmOutput, err := p.sshClient.Execute("qm list")
-	if err == nil {
-		lines := strings.Split(strings.TrimSpace(vmOutput), "\n")
+	for _, listCmd := range []string{"qm list", "pct list"} {
+		output, err := p.sshClient.Execute(listCmd)
+		if err != nil {
+			continue
+		}
+		lines := strings.Split(strings.TrimSpace(output), "\n")
 		for _, line := range lines[1:] { // 跳过标题行
 			fields := strings.Fields(line)
 			if len(fields) >= 1 {
@@ -605,21 +594,25 @@ func (p *ProxmoxProvider) getNextVMID(ctx context.Context, instanceType string)
 			}
 		}
 	}
+	return usedVMIDs
+}
 
-	// 获取容器列表
-	ctOutput, err := p.sshClient.Execute("pct list")
-	if err == nil {
-		lines := strings.Split(strings.TrimSpace(ctOutput), "\n")
-		for _, line := range lines[1:] { // 跳过标题行
-			fields := strings.Fields(line)
-			if len(fields) >= 1 {
-				if vmid, parseErr := strconv.Atoi(fields[0]); parseErr == nil {
-					usedVMIDs[vmid] = true
-				}
-			}
-		}
+// 获取下一个可用的 VMID
+func (p *ProxmoxProvider) getNextVMID(ctx context.Context, instanceType string) (int, error) {
+	// 根据实例类型确定VMID范围
+	minVMID, maxVMID, err := vmidRange(instanceType)
+	if err != nil {
+		return 0, err
 	}
 
+	global.APP_LOG.Info("开始分配VMID",
+		zap.String("instanceType", instanceType),
+		zap.Int("minVMID", minVMID),
+		zap.Int("maxVMID", maxVMID))
+
+	// 获取已使用的VMID列表
+	usedVMIDs := p.listUsedVMIDs()
+
 	// 在指定范围内寻找最小的可用VMID
 	for vmid := minVMID; vmid <= maxVMID; vmid++ {
 		if !usedVMIDs[vmid] {
@@ -635,6 +628,38 @@ func (p *ProxmoxProvider) getNextVMID(ctx context.Context, instanceType string)
 	return 0, fmt.Errorf("在范围 %d-%d 内没有可用的VMID，实例类型: %s", minVMID, maxVMID, instanceType)
 }
 
+// allocateVMID 为新实例分配VMID
+// 内网IPv4固定为 172.16.1.{VMID}，指定IP时直接使用IP末位作为VMID
+func (p *ProxmoxProvider) allocateVMID(ctx context.Context, config provider.InstanceConfig) (int, error) {
+	if config.RequestedIPv6 != "" {
+		return 0, fmt.Errorf("Proxmox不支持指定IPv6地址")
+	}
+	if config.RequestedIPv4 == "" {
+		return p.getNextVMID(ctx, config.InstanceType)
+	}
+
+	if err := provider.ValidateRequestedIP(config.RequestedIPv4, "172.16.1.1/24"); err != nil {
+		return 0, err
+	}
+	minVMID, maxVMID, err := vmidRange(config.InstanceType)
+	if err != nil {
+		return 0, err
+	}
+	vmid := int(net.ParseIP(config.RequestedIPv4).To4()[3])
+	if vmid < minVMID || vmid > maxVMID {
+		return 0, fmt.Errorf("IP地址 %s 不可用于%s实例，可选范围为 172.16.1.%d-172.16.1.%d",
+			config.RequestedIPv4, config.InstanceType, minVMID, maxVMID)
+	}
+	if p.listUsedVMIDs()[vmid] {
+		return 0, fmt.Errorf("IP地址 %s 对应的VMID %d 已被占用", config.RequestedIPv4, vmid)
+	}
+
+	global.APP_LOG.Info("按指定IP分配VMID",
+		zap.String("requestedIPv4", config.RequestedIPv4),
+		zap.Int("vmid", vmid))
+	return vmid, nil
+}
+
 // sshSetInstancePassword 通过SSH设置实例密码
 func (p *ProxmoxProvider) sshSetInstancePassword(ctx context.Context, instanceID, password string) error {
 	// 先查找实例的VMID和类型
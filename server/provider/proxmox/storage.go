@@ -0,0 +1,81 @@
+package proxmox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"oneclickvirt/provider"
+)
+
+// pveStorage pvesh get /nodes/{node}/storage 的输出结构
+type pveStorage struct {
+	Storage string `json:"storage"`
+	Type    string `json:"type"`
+	Content string `json:"content"`
+	Active  int    `json:"active"`
+	Enabled int    `json:"enabled"`
+	Total   int64  `json:"total"`
+	Used    int64  `json:"used"`
+	Avail   int64  `json:"avail"`
+}
+
+// ListStoragePools 列出当前节点的pvesm存储
+func (p *ProxmoxProvider) ListStoragePools(ctx context.Context) ([]provider.StoragePool, error) {
+	if !p.connected || p.sshClient == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+
+	output, err := p.sshClient.Execute(fmt.Sprintf("pvesh get /nodes/%s/storage --output-format json", p.node))
+	if err != nil {
+		return nil, fmt.Errorf("获取存储列表失败: %w", err)
+	}
+
+	var storages []pveStorage
+	if err := json.Unmarshal([]byte(strings.TrimSpace(output)), &storages); err != nil {
+		return nil, fmt.Errorf("解析存储列表失败: %w", err)
+	}
+
+	result := make([]provider.StoragePool, 0, len(storages))
+	for _, s := range storages {
+		status := "inactive"
+		if s.Active == 1 {
+			status = "active"
+		}
+		if s.Enabled == 0 {
+			status = "disabled"
+		}
+		result = append(result, provider.StoragePool{
+			Name:      s.Storage,
+			Driver:    s.Type,
+			Status:    status,
+			Total:     s.Total,
+			Used:      s.Used,
+			Available: s.Avail,
+			Content:   s.Content,
+		})
+	}
+	return result, nil
+}
+
+// validateStoragePool 创建实例前校验指定的存储是否存在且可用，未指定时使用Provider配置的默认存储
+func (p *ProxmoxProvider) validateStoragePool(ctx context.Context, pool string) error {
+	if pool == "" {
+		return nil
+	}
+
+	pools, err := p.ListStoragePools(ctx)
+	if err != nil {
+		return err
+	}
+	for _, s := range pools {
+		if s.Name == pool {
+			if s.Status == "disabled" {
+				return fmt.Errorf("存储 %s 已禁用", pool)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("存储 %s 不存在", pool)
+}
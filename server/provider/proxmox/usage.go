@@ -0,0 +1,75 @@
+package proxmox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"oneclickvirt/provider"
+)
+
+// pveStatusCurrent pvesh get .../status/current 的输出结构
+type pveStatusCurrent struct {
+	CPU     float64 `json:"cpu"` // 占分配核心数的比例（0-1）
+	Disk    int64   `json:"disk"`
+	MaxDisk int64   `json:"maxdisk"`
+}
+
+// GetInstanceUsage 获取实例实时CPU和磁盘使用率
+// 虚拟机的磁盘使用量需要guest agent才能获取，未提供时DiskTotalMB为0
+func (p *ProxmoxProvider) GetInstanceUsage(ctx context.Context, instanceName string) (*provider.InstanceUsage, error) {
+	status, err := p.getStatusCurrent(ctx, instanceName)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := &provider.InstanceUsage{CPUPercent: status.CPU * 100}
+	if status.MaxDisk > 0 && status.Disk > 0 {
+		usage.DiskUsedMB = status.Disk / 1024 / 1024
+		usage.DiskTotalMB = status.MaxDisk / 1024 / 1024
+		usage.DiskPercent = float64(status.Disk) * 100 / float64(status.MaxDisk)
+	}
+	return usage, nil
+}
+
+// GetInstanceDiskUsage 获取实例根磁盘使用量
+// 虚拟机未运行guest agent时Proxmox返回的已用空间为0，此时返回错误而不是误报为未使用
+func (p *ProxmoxProvider) GetInstanceDiskUsage(ctx context.Context, instanceName string) (*provider.DiskUsage, error) {
+	status, err := p.getStatusCurrent(ctx, instanceName)
+	if err != nil {
+		return nil, err
+	}
+	if status.Disk <= 0 {
+		return nil, fmt.Errorf("无法获取实例磁盘使用量，虚拟机需要安装并启用qemu-guest-agent")
+	}
+	return &provider.DiskUsage{UsedBytes: status.Disk, TotalBytes: status.MaxDisk}, nil
+}
+
+// getStatusCurrent 读取实例的实时状态
+func (p *ProxmoxProvider) getStatusCurrent(ctx context.Context, instanceName string) (*pveStatusCurrent, error) {
+	if !p.connected || p.sshClient == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+
+	vmid, instanceType, err := p.findVMIDByNameOrID(ctx, instanceName)
+	if err != nil {
+		return nil, fmt.Errorf("查找实例失败: %w", err)
+	}
+
+	kind := "qemu"
+	if instanceType == "container" {
+		kind = "lxc"
+	}
+
+	output, err := p.sshClient.Execute(fmt.Sprintf("pvesh get /nodes/%s/%s/%s/status/current --output-format json", p.node, kind, vmid))
+	if err != nil {
+		return nil, fmt.Errorf("获取实例状态失败: %s: %w", strings.TrimSpace(output), err)
+	}
+
+	var status pveStatusCurrent
+	if err := json.Unmarshal([]byte(strings.TrimSpace(output)), &status); err != nil {
+		return nil, fmt.Errorf("解析实例状态失败: %w", err)
+	}
+	return &status, nil
+}
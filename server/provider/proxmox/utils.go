@@ -262,6 +262,8 @@ func (p *ProxmoxProvider) cleanupIPv6NATRules(ctx context.Context, vmctid string
 func (p *ProxmoxProvider) cleanupVMFiles(ctx context.Context, vmid string) error {
 	global.APP_LOG.Info("清理VM文件", zap.String("vmid", vmid))
 
+	p.removeCloudInitSnippet(vmid)
+
 	// 获取所有存储名称并清理相关卷
 	storageListCmd := "pvesm status | awk 'NR > 1 {print $1}'"
 	storageOutput, err := p.sshClient.Execute(storageListCmd)
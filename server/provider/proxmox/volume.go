@@ -0,0 +1,63 @@
+package proxmox
+
+import (
+	"fmt"
+	"strings"
+
+	"oneclickvirt/global"
+	"oneclickvirt/provider"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+)
+
+// prepareVolumes 校验挂载配置并在宿主机上解析挂载源，仅容器支持绑定挂载宿主机目录
+func (p *ProxmoxProvider) prepareVolumes(config *provider.InstanceConfig) error {
+	if len(config.Volumes) == 0 {
+		return nil
+	}
+	if config.InstanceType != "container" {
+		return fmt.Errorf("Proxmox虚拟机不支持挂载宿主机目录")
+	}
+	if p.sshClient == nil {
+		return fmt.Errorf("挂载宿主机目录需要SSH连接")
+	}
+	volumes, err := provider.ResolveVolumeSources(p.sshClient.Execute, config.Volumes, p.config.VolumeAllowlist)
+	if err != nil {
+		return err
+	}
+	config.Volumes = volumes
+	return nil
+}
+
+// volumeOptions 生成pct create/set的绑定挂载点参数
+func volumeOptions(volumes []provider.VolumeMount) []string {
+	options := make([]string, 0, len(volumes))
+	for idx, volume := range volumes {
+		options = append(options, provider.ProxmoxMountPointOption(idx, volume))
+	}
+	return options
+}
+
+// attachVolumes 在容器首次启动前添加绑定挂载点，失败时销毁已创建的容器
+// 绑定挂载点只能由root@pam设置，API令牌无权限，因此始终通过SSH执行
+func (p *ProxmoxProvider) attachVolumes(vmid int, config provider.InstanceConfig) error {
+	if len(config.Volumes) == 0 {
+		return nil
+	}
+
+	cmd := fmt.Sprintf("pct set %d %s", vmid, strings.Join(volumeOptions(config.Volumes), " "))
+	if output, err := p.sshClient.Execute(cmd); err != nil {
+		global.APP_LOG.Error("挂载宿主机目录失败，销毁已创建的容器",
+			zap.Int("vmid", vmid),
+			zap.String("output", utils.TruncateString(output, 200)),
+			zap.Error(err))
+		p.sshClient.Execute(fmt.Sprintf("pct destroy %d", vmid))
+		return fmt.Errorf("挂载宿主机目录失败: %w", err)
+	}
+
+	global.APP_LOG.Info("已为容器挂载宿主机目录",
+		zap.Int("vmid", vmid),
+		zap.Int("count", len(config.Volumes)))
+	return nil
+}
@@ -0,0 +1,44 @@
+package provider
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// ValidateRequestedIP 校验指定的IP地址位于子网内且不是网络地址、网关或广播地址
+// subnet为网关CIDR格式，例如 10.0.0.1/24（即LXD/Incus网络的ipv4.address配置）
+func ValidateRequestedIP(requested, subnet string) error {
+	ip := net.ParseIP(strings.TrimSpace(requested))
+	if ip == nil {
+		return fmt.Errorf("无效的IP地址: %s", requested)
+	}
+
+	gateway, ipNet, err := net.ParseCIDR(strings.TrimSpace(subnet))
+	if err != nil {
+		return fmt.Errorf("无法解析节点子网 %s: %w", subnet, err)
+	}
+	if (ip.To4() == nil) != (gateway.To4() == nil) {
+		return fmt.Errorf("IP地址 %s 与节点子网 %s 的协议版本不一致", requested, subnet)
+	}
+	if !ipNet.Contains(ip) {
+		return fmt.Errorf("IP地址 %s 不在节点子网 %s 内", requested, ipNet.String())
+	}
+	if ip.Equal(gateway) {
+		return fmt.Errorf("IP地址 %s 是节点网关地址", requested)
+	}
+	if ip.Equal(ipNet.IP) {
+		return fmt.Errorf("IP地址 %s 是子网的网络地址", requested)
+	}
+
+	if ip4 := ip.To4(); ip4 != nil {
+		broadcast := make(net.IP, len(ip4))
+		for i := range ip4 {
+			broadcast[i] = ipNet.IP.To4()[i] | ^ipNet.Mask[i]
+		}
+		if ip4.Equal(broadcast) {
+			return fmt.Errorf("IP地址 %s 是子网的广播地址", requested)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package provider
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// UsageSampleScript 在实例内采样CPU和根文件系统使用情况的脚本
+// 间隔1秒读取两次/proc/stat计算CPU使用率，随后输出df的根分区行
+const UsageSampleScript = "head -n1 /proc/stat; sleep 1; head -n1 /proc/stat; df -Pm / | tail -n1"
+
+// ParseUsageSample 解析UsageSampleScript的输出
+func ParseUsageSample(output string) (*InstanceUsage, error) {
+	var cpuLines []string
+	var dfLine string
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "cpu ") {
+			cpuLines = append(cpuLines, line)
+		} else if line != "" {
+			dfLine = line
+		}
+	}
+	if len(cpuLines) != 2 {
+		return nil, fmt.Errorf("无法解析CPU采样输出: %s", output)
+	}
+
+	idle1, total1, err := parseProcStatCPU(cpuLines[0])
+	if err != nil {
+		return nil, err
+	}
+	idle2, total2, err := parseProcStatCPU(cpuLines[1])
+	if err != nil {
+		return nil, err
+	}
+
+	usage := &InstanceUsage{}
+	if total2 > total1 {
+		usage.CPUPercent = float64((total2-total1)-(idle2-idle1)) * 100 / float64(total2-total1)
+	}
+
+	if used, total, ok := parseDfLine(dfLine); ok {
+		usage.DiskUsedMB = used
+		usage.DiskTotalMB = total
+		usage.DiskPercent = float64(used) * 100 / float64(total)
+	}
+
+	return usage, nil
+}
+
+// parseProcStatCPU 解析/proc/stat的cpu汇总行，返回空闲时间（idle+iowait）和总时间
+func parseProcStatCPU(line string) (idle, total uint64, err error) {
+	fields := strings.Fields(line)
+	if len(fields) < 5 {
+		return 0, 0, fmt.Errorf("无效的/proc/stat行: %s", line)
+	}
+	for i, field := range fields[1:] {
+		v, err := strconv.ParseUint(field, 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("无效的/proc/stat行: %s", line)
+		}
+		total += v
+		// 第4、5列为idle和iowait
+		if i == 3 || i == 4 {
+			idle += v
+		}
+	}
+	return idle, total, nil
+}
+
+// parseDfLine 解析df -Pm的数据行：文件系统 总量 已用 可用 使用率 挂载点
+func parseDfLine(line string) (used, total int64, ok bool) {
+	fields := strings.Fields(line)
+	if len(fields) < 6 {
+		return 0, 0, false
+	}
+	total, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil || total <= 0 {
+		return 0, 0, false
+	}
+	used, err = strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return used, total, true
+}
@@ -0,0 +1,208 @@
+package provider
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"oneclickvirt/utils"
+)
+
+// MaxVolumeMounts 单个实例允许挂载的宿主机目录数量上限
+const MaxVolumeMounts = 8
+
+// volumePathPattern 挂载路径只允许常见文件名字符，排除会破坏 docker -v、pct mpN 参数格式的冒号和逗号
+var volumePathPattern = regexp.MustCompile(`^/[A-Za-z0-9_./@+-]*$`)
+
+// reservedVolumeTargets 不允许被挂载覆盖的实例内系统目录，覆盖后实例无法正常启动
+var reservedVolumeTargets = []string{"/", "/bin", "/boot", "/etc", "/lib", "/lib32", "/lib64", "/root", "/run", "/sbin", "/usr", "/var"}
+
+// virtualFSTargets 由内核或容器运行时管理的虚拟文件系统，其下任何路径都不允许挂载
+var virtualFSTargets = []string{"/dev", "/proc", "/sys"}
+
+// cleanVolumePath 校验并规范化挂载路径，要求为绝对路径且不包含 .. 等相对路径成分
+func cleanVolumePath(p string) (string, error) {
+	p = strings.TrimSpace(p)
+	if !volumePathPattern.MatchString(p) {
+		return "", fmt.Errorf("路径无效: %s，必须是只包含字母、数字和 _ . / @ + - 的绝对路径", p)
+	}
+	for _, segment := range strings.Split(p, "/") {
+		if segment == ".." {
+			return "", fmt.Errorf("路径不能包含 ..: %s", p)
+		}
+	}
+	return path.Clean(p), nil
+}
+
+// ValidateVolumeMounts 校验挂载配置的格式，返回规范化后的列表，不检查宿主机路径是否存在
+func ValidateVolumeMounts(volumes []VolumeMount) ([]VolumeMount, error) {
+	if len(volumes) == 0 {
+		return nil, nil
+	}
+	if len(volumes) > MaxVolumeMounts {
+		return nil, fmt.Errorf("挂载目录数量不能超过%d个", MaxVolumeMounts)
+	}
+
+	result := make([]VolumeMount, 0, len(volumes))
+	seenTargets := make(map[string]bool)
+	for idx, volume := range volumes {
+		source, err := cleanVolumePath(volume.Source)
+		if err != nil {
+			return nil, fmt.Errorf("第%d个挂载的宿主机%v", idx+1, err)
+		}
+		if source == "/" {
+			return nil, fmt.Errorf("第%d个挂载不能挂载宿主机根目录", idx+1)
+		}
+		target, err := cleanVolumePath(volume.Target)
+		if err != nil {
+			return nil, fmt.Errorf("第%d个挂载的实例内%v", idx+1, err)
+		}
+		if isReservedVolumeTarget(target) {
+			return nil, fmt.Errorf("第%d个挂载的实例内路径 %s 为系统目录，不允许挂载", idx+1, target)
+		}
+		if seenTargets[target] {
+			return nil, fmt.Errorf("实例内挂载路径重复: %s", target)
+		}
+		seenTargets[target] = true
+		result = append(result, VolumeMount{Source: source, Target: target, ReadOnly: volume.ReadOnly})
+	}
+	return result, nil
+}
+
+func isReservedVolumeTarget(target string) bool {
+	for _, reserved := range reservedVolumeTargets {
+		if target == reserved {
+			return true
+		}
+	}
+	for _, dir := range virtualFSTargets {
+		if target == dir || strings.HasPrefix(target, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseVolumeAllowlist 解析以逗号或换行分隔的允许挂载的宿主机目录列表
+func ParseVolumeAllowlist(value string) []string {
+	var dirs []string
+	for _, item := range strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == '\n' || r == '\r'
+	}) {
+		if item = strings.TrimSpace(item); item != "" {
+			dirs = append(dirs, item)
+		}
+	}
+	return dirs
+}
+
+// NormalizeVolumeAllowlist 校验并规范化允许挂载的宿主机目录列表，返回逗号分隔的形式
+func NormalizeVolumeAllowlist(value string) (string, error) {
+	var dirs []string
+	seen := make(map[string]bool)
+	for _, item := range ParseVolumeAllowlist(value) {
+		dir, err := cleanVolumePath(item)
+		if err != nil {
+			return "", fmt.Errorf("允许挂载的目录%v", err)
+		}
+		if dir == "/" {
+			return "", fmt.Errorf("不能允许挂载宿主机根目录")
+		}
+		if !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+	return strings.Join(dirs, ","), nil
+}
+
+// CheckVolumesAllowed 校验挂载源都位于允许列表中的目录或其子目录下，允许列表为空时不允许挂载
+func CheckVolumesAllowed(volumes []VolumeMount, allowed string) error {
+	if len(volumes) == 0 {
+		return nil
+	}
+	dirs := ParseVolumeAllowlist(allowed)
+	if len(dirs) == 0 {
+		return fmt.Errorf("该节点未开放宿主机目录挂载")
+	}
+	for _, volume := range volumes {
+		if !volumeSourceAllowed(volume.Source, dirs) {
+			return fmt.Errorf("该节点不允许挂载宿主机目录 %s", volume.Source)
+		}
+	}
+	return nil
+}
+
+func volumeSourceAllowed(source string, dirs []string) bool {
+	for _, dir := range dirs {
+		if source == dir || strings.HasPrefix(source, strings.TrimSuffix(dir, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveVolumeSources 在宿主机上解析挂载源的真实路径并再次校验允许列表，防止通过符号链接挂载列表外的目录
+// 返回的挂载源替换为解析后的真实路径；路径不存在时返回错误
+func ResolveVolumeSources(execute func(cmd string) (string, error), volumes []VolumeMount, allowed string) ([]VolumeMount, error) {
+	volumes, err := ValidateVolumeMounts(volumes)
+	if err != nil || len(volumes) == 0 {
+		return volumes, err
+	}
+	if err := CheckVolumesAllowed(volumes, allowed); err != nil {
+		return nil, err
+	}
+
+	for idx, volume := range volumes {
+		output, err := execute(fmt.Sprintf("readlink -e -- %s", utils.ShellQuote(volume.Source)))
+		resolved := strings.TrimSpace(output)
+		if err != nil || resolved == "" {
+			return nil, fmt.Errorf("宿主机挂载路径不存在: %s", volume.Source)
+		}
+		if resolved, err = cleanVolumePath(resolved); err != nil {
+			return nil, fmt.Errorf("宿主机挂载路径 %s 解析后%v", volume.Source, err)
+		}
+		if !volumeSourceAllowed(resolved, ParseVolumeAllowlist(allowed)) {
+			return nil, fmt.Errorf("宿主机挂载路径 %s 指向允许列表外的 %s", volume.Source, resolved)
+		}
+		volumes[idx].Source = resolved
+	}
+	return volumes, nil
+}
+
+// DockerVolumeArg 生成 docker run 的 --volume 参数
+func DockerVolumeArg(volume VolumeMount) string {
+	mode := "rw"
+	if volume.ReadOnly {
+		mode = "ro"
+	}
+	return fmt.Sprintf("--volume %s:%s:%s", volume.Source, volume.Target, mode)
+}
+
+// VolumeDeviceName 第idx个挂载在LXD/Incus中的disk设备名称
+func VolumeDeviceName(idx int) string {
+	return fmt.Sprintf("vol%d", idx)
+}
+
+// LXCVolumeDeviceConfig 生成LXD/Incus disk设备的配置项
+func LXCVolumeDeviceConfig(volume VolumeMount) map[string]string {
+	config := map[string]string{
+		"type":   "disk",
+		"source": volume.Source,
+		"path":   volume.Target,
+	}
+	if volume.ReadOnly {
+		config["readonly"] = "true"
+	}
+	return config
+}
+
+// ProxmoxMountPointOption 生成Proxmox容器第idx个绑定挂载点的 --mpN 参数
+func ProxmoxMountPointOption(idx int, volume VolumeMount) string {
+	option := fmt.Sprintf("--mp%d %s,mp=%s", idx, volume.Source, volume.Target)
+	if volume.ReadOnly {
+		option += ",ro=1"
+	}
+	return option
+}
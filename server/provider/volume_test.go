@@ -0,0 +1,118 @@
+package provider
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestValidateVolumeMounts 测试挂载路径的规范化和系统目录限制
+func TestValidateVolumeMounts(t *testing.T) {
+	volumes, err := ValidateVolumeMounts([]VolumeMount{
+		{Source: " /data/app/ ", Target: "/srv/app/", ReadOnly: true},
+		{Source: "/data/logs", Target: "/var/log/app"},
+	})
+	if err != nil {
+		t.Fatalf("合法的挂载不应报错: %v", err)
+	}
+	if volumes[0].Source != "/data/app" || volumes[0].Target != "/srv/app" || !volumes[0].ReadOnly {
+		t.Fatalf("挂载路径应被规范化，实际 %+v", volumes[0])
+	}
+
+	invalid := [][]VolumeMount{
+		{{Source: "data", Target: "/srv"}},
+		{{Source: "/data/../etc", Target: "/srv"}},
+		{{Source: "/data:/etc", Target: "/srv"}},
+		{{Source: "/data,ro=0", Target: "/srv"}},
+		{{Source: "/", Target: "/srv"}},
+		{{Source: "/data", Target: "/"}},
+		{{Source: "/data", Target: "/etc"}},
+		{{Source: "/data", Target: "/proc/sys"}},
+		{{Source: "/data", Target: "/dev/shm"}},
+		{{Source: "/data/a", Target: "/srv"}, {Source: "/data/b", Target: "/srv/"}},
+	}
+	for _, c := range invalid {
+		if _, err := ValidateVolumeMounts(c); err == nil {
+			t.Fatalf("期望校验失败: %+v", c)
+		}
+	}
+
+	tooMany := make([]VolumeMount, MaxVolumeMounts+1)
+	for idx := range tooMany {
+		tooMany[idx] = VolumeMount{Source: "/data", Target: "/srv/" + string(rune('a'+idx))}
+	}
+	if _, err := ValidateVolumeMounts(tooMany); err == nil {
+		t.Fatal("挂载数量超过上限时应校验失败")
+	}
+}
+
+// TestCheckVolumesAllowed 测试挂载源必须位于允许列表的目录下
+func TestCheckVolumesAllowed(t *testing.T) {
+	allowed, err := NormalizeVolumeAllowlist("/data/, /srv/shared\n/data")
+	if err != nil {
+		t.Fatalf("合法的允许列表不应报错: %v", err)
+	}
+	if allowed != "/data,/srv/shared" {
+		t.Fatalf("允许列表应被规范化并去重，实际 %s", allowed)
+	}
+	if _, err := NormalizeVolumeAllowlist("/"); err == nil {
+		t.Fatal("不应允许挂载宿主机根目录")
+	}
+
+	if err := CheckVolumesAllowed([]VolumeMount{{Source: "/data"}, {Source: "/srv/shared/a"}}, allowed); err != nil {
+		t.Fatalf("允许列表内的目录不应报错: %v", err)
+	}
+	if err := CheckVolumesAllowed([]VolumeMount{{Source: "/data2"}}, allowed); err == nil {
+		t.Fatal("前缀相同但不在允许目录下的路径应被拒绝")
+	}
+	if err := CheckVolumesAllowed([]VolumeMount{{Source: "/data"}}, ""); err == nil {
+		t.Fatal("允许列表为空时应拒绝挂载")
+	}
+}
+
+// TestResolveVolumeSources 测试按真实路径校验挂载源，防止符号链接指向允许列表外
+func TestResolveVolumeSources(t *testing.T) {
+	links := map[string]string{
+		"/data/app":  "/data/app",
+		"/data/link": "/etc",
+		"/data/alt":  "/mnt/data/alt",
+	}
+	execute := func(cmd string) (string, error) {
+		for source, target := range links {
+			if strings.HasSuffix(cmd, "'"+source+"'") {
+				return target + "\n", nil
+			}
+		}
+		return "", errors.New("exit status 1")
+	}
+
+	volumes, err := ResolveVolumeSources(execute, []VolumeMount{{Source: "/data/app", Target: "/srv"}}, "/data")
+	if err != nil || volumes[0].Source != "/data/app" {
+		t.Fatalf("存在的挂载源应解析成功: %+v, %v", volumes, err)
+	}
+	volumes, err = ResolveVolumeSources(execute, []VolumeMount{{Source: "/data/alt", Target: "/srv"}}, "/data,/mnt/data")
+	if err != nil || volumes[0].Source != "/mnt/data/alt" {
+		t.Fatalf("挂载源应替换为解析后的真实路径: %+v, %v", volumes, err)
+	}
+	if _, err := ResolveVolumeSources(execute, []VolumeMount{{Source: "/data/link", Target: "/srv"}}, "/data"); err == nil {
+		t.Fatal("指向允许列表外的符号链接应被拒绝")
+	}
+	if _, err := ResolveVolumeSources(execute, []VolumeMount{{Source: "/data/missing", Target: "/srv"}}, "/data"); err == nil {
+		t.Fatal("不存在的挂载源应报错")
+	}
+}
+
+// TestVolumeArgs 测试各Provider挂载参数的格式
+func TestVolumeArgs(t *testing.T) {
+	volume := VolumeMount{Source: "/data/app", Target: "/srv/app", ReadOnly: true}
+	if got := DockerVolumeArg(volume); got != "--volume /data/app:/srv/app:ro" {
+		t.Fatalf("Docker挂载参数错误: %s", got)
+	}
+	if got := ProxmoxMountPointOption(1, volume); got != "--mp1 /data/app,mp=/srv/app,ro=1" {
+		t.Fatalf("Proxmox挂载参数错误: %s", got)
+	}
+	config := LXCVolumeDeviceConfig(VolumeMount{Source: "/data/app", Target: "/srv/app"})
+	if config["type"] != "disk" || config["source"] != "/data/app" || config["path"] != "/srv/app" || config["readonly"] != "" {
+		t.Fatalf("LXD/Incus disk设备配置错误: %v", config)
+	}
+}
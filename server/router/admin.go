@@ -15,9 +15,11 @@ import (
 func InitAdminRouter(Router *gin.RouterGroup) {
 	AdminGroup := Router.Group("/v1/admin")
 	AdminGroup.Use(middleware.RequireAuth(authModel.AuthLevelAdmin))
+	AdminGroup.Use(middleware.AuditMiddleware())
 	{
 		// 仪表盘
 		AdminGroup.GET("/dashboard", admin.GetAdminDashboard)
+		AdminGroup.GET("/capacity", admin.GetProviderCapacity)
 
 		// 系统配置（管理员专用）
 		AdminGroup.GET("/config", config.GetUnifiedConfig)
@@ -42,6 +44,24 @@ func InitAdminRouter(Router *gin.RouterGroup) {
 		AdminGroup.DELETE("/instances/:id", admin.DeleteInstance)
 		AdminGroup.POST("/instances/:id/action", admin.AdminInstanceAction)
 		AdminGroup.PUT("/instances/:id/reset-password", admin.ResetInstancePassword)
+		AdminGroup.POST("/instances/:id/reset-password-sync", admin.ResetInstancePasswordSync)
+		AdminGroup.PUT("/instances/:id/resize-disk", admin.ResizeInstanceDisk)
+		AdminGroup.PUT("/instances/:id/bandwidth", admin.SetInstanceBandwidth)
+		AdminGroup.POST("/instances/:id/profile", admin.ApplyInstanceProfile)
+		AdminGroup.GET("/instances/:id/delete-plan", admin.GetInstanceDeletePlan)
+		AdminGroup.POST("/instances/:id/sync-traffic", admin.SyncInstanceTrafficNow)
+		AdminGroup.GET("/instances/:id/monitor-interfaces", admin.GetInstanceMonitorInterfaces)
+		AdminGroup.PUT("/instances/:id/monitor-interfaces", admin.SetInstanceMonitorInterfaces)
+		AdminGroup.PUT("/instances/:id/monitoring", admin.SetInstanceMonitoring)
+		AdminGroup.POST("/instances/:id/backups", admin.CreateInstanceBackup)
+		AdminGroup.POST("/instances/:id/migrate", admin.MigrateInstance)
+		AdminGroup.GET("/backups", admin.GetBackupList)
+		AdminGroup.POST("/backups/:id/restore", admin.RestoreInstanceBackup)
+		AdminGroup.DELETE("/backups/:id", admin.DeleteBackup)
+		AdminGroup.POST("/instances/:id/template", admin.CreateInstanceTemplate)
+		AdminGroup.GET("/templates", admin.GetInstanceTemplateList)
+		AdminGroup.PUT("/templates/:id", admin.UpdateInstanceTemplate)
+		AdminGroup.DELETE("/templates/:id", admin.DeleteInstanceTemplate)
 		AdminGroup.GET("/instances/:id/password/:taskId", admin.GetInstanceNewPassword)
 		AdminGroup.GET("/instance-type-permissions", admin.GetAdminInstanceTypePermissions)
 		AdminGroup.PUT("/instance-type-permissions", admin.UpdateAdminInstanceTypePermissions)
@@ -55,6 +75,12 @@ func InitAdminRouter(Router *gin.RouterGroup) {
 		AdminGroup.PUT("/announcements/batch-status", admin.BatchUpdateAnnouncementStatus)
 		AdminGroup.POST("/announcements/batch-delete", admin.BatchDeleteAnnouncements)
 
+		// Webhook管理
+		AdminGroup.GET("/webhooks", admin.GetWebhooks)
+		AdminGroup.POST("/webhooks", admin.CreateWebhook)
+		AdminGroup.PUT("/webhooks/:id", admin.UpdateWebhook)
+		AdminGroup.DELETE("/webhooks/:id", admin.DeleteWebhook)
+
 		// 邀请码管理
 		AdminGroup.GET("/invite-codes", admin.GetInviteCodeList)
 		AdminGroup.POST("/invite-codes", admin.CreateInviteCode)
@@ -66,6 +92,7 @@ func InitAdminRouter(Router *gin.RouterGroup) {
 		// 系统监控
 		AdminGroup.GET("/monitoring/system", admin.GetAdminDashboard)
 		AdminGroup.GET("/monitoring/audit-logs", system.GetOperationLogs)
+		AdminGroup.GET("/audit-logs", admin.GetAuditLogs)
 
 		// 性能监控
 		AdminGroup.GET("/performance/metrics", system.GetPerformanceMetrics)
@@ -87,6 +114,7 @@ func InitAdminRouter(Router *gin.RouterGroup) {
 		AdminGroup.DELETE("/providers/:id", admin.DeleteProvider)
 		AdminGroup.POST("/providers/freeze", admin.FreezeProvider)
 		AdminGroup.POST("/providers/unfreeze", admin.UnfreezeProvider)
+		AdminGroup.PUT("/providers/:id/maintenance", admin.SetProviderMaintenance)
 		AdminGroup.POST("/providers/test-ssh-connection", admin.TestSSHConnection)
 		// Provider验证接口（用于前端实时验证）
 		AdminGroup.GET("/providers/check-name", admin.CheckProviderName)
@@ -97,9 +125,27 @@ func InitAdminRouter(Router *gin.RouterGroup) {
 		AdminGroup.POST("/providers/:id/auto-configure-stream", admin.AutoConfigureProviderStream)
 		AdminGroup.POST("/providers/:id/health-check", admin.CheckProviderHealth)
 		AdminGroup.GET("/providers/:id/status", admin.GetProviderStatus)
+		AdminGroup.POST("/providers/:id/recalculate-budget", admin.RecalculateProviderBudget)
+		AdminGroup.POST("/providers/:id/validate-ssh-auth", admin.ValidateProviderSSHAuth)
+		AdminGroup.POST("/providers/:id/repin-host-key", admin.RepinProviderHostKey)
+		AdminGroup.PUT("/providers/:id/credentials", admin.RotateProviderCredentials)
+		AdminGroup.GET("/providers/:id/storage-pools", admin.GetProviderStoragePools)
+		AdminGroup.GET("/providers/:id/networks", admin.GetProviderNetworks)
+		AdminGroup.POST("/providers/:id/networks", admin.CreateProviderNetwork)
+		AdminGroup.GET("/providers/:id/profiles", admin.GetProviderProfiles)
+		AdminGroup.POST("/providers/:id/profiles", admin.CreateProviderProfile)
+		AdminGroup.GET("/providers/:id/gpus", admin.GetProviderGPUs)
+		AdminGroup.GET("/providers/:id/command-log", admin.GetProviderCommandLog)
+		AdminGroup.GET("/operations", admin.GetSSHOperations)
+		AdminGroup.POST("/operations/:id/cancel", admin.CancelSSHOperation)
+		AdminGroup.POST("/providers/:id/prewarm", admin.PrewarmProviderImages)
+		AdminGroup.GET("/providers/:id/prewarm", admin.GetProviderPrewarmStatus)
 
 		// 配置导出
 		AdminGroup.POST("/providers/export-configs", admin.ExportProviderConfigs)
+		AdminGroup.GET("/providers/export", admin.ExportProviders)
+		AdminGroup.POST("/providers/import", admin.ImportProviders)
+		AdminGroup.POST("/providers/proxmox-cluster", admin.ImportProxmoxCluster)
 
 		// 配置任务管理
 		AdminGroup.POST("/providers/auto-configure", config.AutoConfigureProvider)
@@ -114,6 +160,7 @@ func InitAdminRouter(Router *gin.RouterGroup) {
 		AdminGroup.GET("/tasks/stats", admin.GetTaskStats)
 		AdminGroup.GET("/tasks/overall-stats", admin.GetTaskOverallStats)
 		AdminGroup.POST("/tasks/:taskId/cancel", admin.CancelUserTaskByAdmin)
+		AdminGroup.PUT("/tasks/:taskId/priority", admin.UpdateTaskPriority)
 
 		// 系统镜像管理
 		AdminGroup.GET("/system-images", system.GetSystemImageList)
@@ -143,6 +190,7 @@ func InitAdminRouter(Router *gin.RouterGroup) {
 		AdminGroup.POST("/traffic/batch-manage", adminTrafficAPI.BatchManageTrafficLimits)
 		AdminGroup.POST("/traffic/batch-sync", adminTrafficAPI.BatchSyncUserTraffic)
 		AdminGroup.DELETE("/traffic/user/:userId/clear", adminTrafficAPI.ClearUserTrafficRecords)
+		AdminGroup.GET("/traffic/billing-report", adminTrafficAPI.GetBillingReport)
 
 		// 流量历史API
 		AdminGroup.GET("/providers/:id/traffic/history", traffic.GetProviderTrafficHistory)
@@ -28,6 +28,7 @@ func InitResourceRouter(Router *gin.RouterGroup) {
 func InitProviderRouter(Router *gin.RouterGroup) {
 	ProviderGroup := Router.Group("/v1/providers")
 	ProviderGroup.Use(middleware.RequireAuth(authModel.AuthLevelUser))
+	ProviderGroup.Use(middleware.AuditMiddleware())
 	{
 		providerApi := &provider.ProviderApi{}
 		ProviderGroup.GET("/", providerApi.GetProviders)
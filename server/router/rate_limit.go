@@ -0,0 +1,32 @@
+package router
+
+import (
+	"net/http"
+
+	"oneclickvirt/middleware"
+)
+
+// rateLimitRoute 指定限流分类的接口
+type rateLimitRoute struct {
+	method   string
+	path     string
+	category string
+}
+
+// rateLimitRouteList 需要单独限流的高开销用户接口，其余接口按请求方法归为 read 或 write
+var rateLimitRouteList = []rateLimitRoute{
+	{http.MethodPost, "/api/v1/user/instances", middleware.RateLimitCategoryCreate},
+	{http.MethodPost, "/api/v1/instances", middleware.RateLimitCategoryCreate}, // 兼容路由，同样调用CreateUserInstance
+	{http.MethodPost, "/api/v1/user/instances/:id/clone", middleware.RateLimitCategoryCreate},
+	{http.MethodPost, "/api/v1/user/templates/:id/instances", middleware.RateLimitCategoryCreate},
+	{http.MethodPost, "/api/v1/user/resources/claim", middleware.RateLimitCategoryCreate},
+	{http.MethodPost, "/api/v1/user/instances/:id/exec", middleware.RateLimitCategoryExec},
+	{http.MethodGet, "/api/v1/user/instances/:id/logs", middleware.RateLimitCategoryExec},
+}
+
+// registerRateLimitCategories 将接口限流分类登记到中间件
+func registerRateLimitCategories() {
+	for _, route := range rateLimitRouteList {
+		middleware.SetRateLimitCategory(route.method, route.path, route.category)
+	}
+}
@@ -18,7 +18,10 @@ import (
 func isAPIPath(path string) bool {
 	return strings.HasPrefix(path, "/api/") ||
 		strings.HasPrefix(path, "/swagger/") ||
-		path == "/health"
+		path == "/health" ||
+		path == "/healthz" ||
+		path == "/readyz" ||
+		path == "/metrics"
 }
 
 // SetupRouter 统一的路由设置入口
@@ -35,17 +38,29 @@ func SetupRouter() *gin.Engine {
 		AllowOrigins:     []string{"*"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
 		AllowHeaders:     []string{"*"},
-		ExposeHeaders:    []string{"Content-Length", "Authorization"},
+		ExposeHeaders:    []string{"Content-Length", "Authorization", "API-Version", "Deprecation", "Sunset", "Link", "Retry-After"},
 		AllowCredentials: true,
 	}))
 
 	// 全局中间件
 	Router.Use(middleware.ErrorHandler())
 	Router.Use(middleware.InputValidator())
+	// 响应压缩（WebSocket连接和小于1KB的响应不压缩）
+	Router.Use(middleware.Compression())
+	// API版本头和弃用接口提示
+	registerDeprecatedRoutes()
+	Router.Use(middleware.APIVersion())
 
 	// 健康检查 - 使用public包中的标准健康检查
 	Router.GET("/health", public.HealthCheck)
 
+	// 容器编排探针：/healthz 只表示进程存活，/readyz 检查数据库和Provider连接
+	Router.GET("/healthz", public.Liveness)
+	Router.GET("/readyz", public.Readiness)
+
+	// Prometheus指标
+	Router.GET("/metrics", public.Metrics)
+
 	// Swagger文档路由
 	Router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
@@ -81,6 +96,9 @@ func SetupRouter() *gin.Engine {
 		// 资源和Provider路由
 		InitResourceRouter(ApiGroup)
 		InitProviderRouter(ApiGroup)
+
+		// v2路由，与v1并存
+		InitV2Router(ApiGroup)
 	}
 
 	// 设置静态文件路由（如果启用了嵌入模式）
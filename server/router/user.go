@@ -16,6 +16,9 @@ import (
 func InitUserRouter(Router *gin.RouterGroup) {
 	UserGroup := Router.Group("/v1")
 	UserGroup.Use(middleware.RequireAuth(authModel.AuthLevelUser))
+	UserGroup.Use(middleware.AuditMiddleware()) // 记录实例创建、删除、重置密码等变更操作
+	registerRateLimitCategories()
+	UserGroup.Use(middleware.UserRateLimit()) // 按用户和接口分类限流
 	{
 		// 用户管理
 		UserGroup.GET("/user/profile", user.GetUserInfo)
@@ -34,7 +37,25 @@ func InitUserRouter(Router *gin.RouterGroup) {
 		UserGroup.GET("/user/instances/:id/pmacct/query", user.QueryInstancePmacctData)
 		UserGroup.PUT("/user/instances/:id/reset-password", user.ResetInstancePassword)
 		UserGroup.GET("/user/instances/:id/password/:taskId", user.GetInstanceNewPassword)
+		UserGroup.POST("/user/instances/:id/clone", user.CloneInstance)
+		UserGroup.POST("/user/templates/:id/instances", user.CreateInstanceFromTemplate)
+		UserGroup.PUT("/user/instances/:id/tags", user.UpdateInstanceTags)
+		UserGroup.POST("/user/instances/:id/exec", user.ExecInstanceCommand)
+		UserGroup.GET("/user/instances/:id/logs", user.GetInstanceLogs)
+		UserGroup.POST("/user/instances/:id/extend", user.ExtendInstance)
+		UserGroup.POST("/user/instances/:id/undelete", user.UndeleteInstance)
+		UserGroup.PUT("/user/instances/:id/limits", user.UpdateInstanceLimits)
+		UserGroup.GET("/user/instances/:id/schedule", user.GetInstanceSchedule)
+		UserGroup.PUT("/user/instances/:id/schedule", user.SetInstanceSchedule)
+		UserGroup.DELETE("/user/instances/:id/schedule", user.ClearInstanceSchedule)
+		UserGroup.GET("/user/instances/:id/shares", user.GetInstanceShares)
+		UserGroup.POST("/user/instances/:id/shares", user.GrantInstanceShare)
+		UserGroup.DELETE("/user/instances/:id/shares/:shareId", user.RevokeInstanceShare)
+		UserGroup.GET("/user/shared-instances", user.GetSharedInstances)
 		UserGroup.GET("/user/instances/:id/ports", user.GetInstancePorts)
+		UserGroup.GET("/user/instances/:id/firewall-rules", user.GetInstanceFirewallRules)
+		UserGroup.POST("/user/instances/:id/firewall-rules", user.AddInstanceFirewallRule)
+		UserGroup.DELETE("/user/instances/:id/firewall-rules/:ruleId", user.DeleteInstanceFirewallRule)
 		UserGroup.GET("/user/instances/:id/ssh", user.SSHWebSocket) // WebSocket SSH连接
 		UserGroup.POST("/user/instances/action", user.InstanceAction)
 
@@ -54,6 +75,7 @@ func InitUserRouter(Router *gin.RouterGroup) {
 		// 任务管理
 		UserGroup.GET("/user/tasks", user.GetUserTasks)
 		UserGroup.POST("/user/tasks/:taskId/cancel", user.CancelUserTask)
+		UserGroup.GET("/user/tasks/:taskId/events", user.StreamUserTaskEvents)
 
 		// 流量统计API
 		trafficAPI := &traffic.UserTrafficAPI{}
@@ -63,8 +85,14 @@ func InitUserRouter(Router *gin.RouterGroup) {
 		UserGroup.GET("/user/traffic/limit-status", trafficAPI.GetTrafficLimitStatus)
 		UserGroup.GET("/user/traffic/pmacct/:instanceId", trafficAPI.GetPmacctData)
 		UserGroup.GET("/user/traffic/history", trafficAPI.GetUserTrafficHistory)
+		UserGroup.GET("/user/traffic/instances/history", trafficAPI.GetMultiInstanceTrafficHistory)
 		UserGroup.GET("/user/instances/:id/traffic/history", trafficAPI.GetInstanceTrafficHistory)
 
+		// 资源告警
+		UserGroup.GET("/user/resource-alerts", user.GetResourceAlerts)
+		UserGroup.GET("/user/resource-alerts/settings", user.GetResourceAlertSettings)
+		UserGroup.PUT("/user/resource-alerts/settings", user.UpdateResourceAlertSettings)
+
 		// 文件上传
 		uploadGroup := UserGroup.Group("/upload")
 		uploadGroup.Use(middleware.AvatarUploadLimit()) // 上传大小限制
@@ -0,0 +1,40 @@
+package router
+
+import (
+	"net/http"
+
+	"oneclickvirt/middleware"
+	authModel "oneclickvirt/model/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+// deprecatedRoute 已弃用接口登记项
+type deprecatedRoute struct {
+	method string
+	path   string
+	info   middleware.DeprecationInfo
+}
+
+// deprecatedRouteList 已弃用的接口，path 为完整路由路径，如
+// {http.MethodGet, "/api/v1/user/info", middleware.DeprecationInfo{Replacement: "/api/v2/user/profile"}}
+// 登记后接口照常工作，响应中附带 Deprecation、Sunset 和 Link 头提示客户端迁移
+var deprecatedRouteList = []deprecatedRoute{}
+
+// registerDeprecatedRoutes 将弃用接口登记到中间件
+func registerDeprecatedRoutes() {
+	for _, route := range deprecatedRouteList {
+		middleware.MarkDeprecated(route.method, route.path, route.info)
+	}
+}
+
+// InitV2Router v2 API 路由，与 v1 并存，新版本接口在此注册
+func InitV2Router(Router *gin.RouterGroup) {
+	PublicGroup := Router.Group("/v2")
+	PublicGroup.Use(middleware.RequireAuth(authModel.AuthLevelPublic))
+	{
+		PublicGroup.GET("/ping", func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{"message": "pong"})
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package instance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"oneclickvirt/global"
+	adminModel "oneclickvirt/model/admin"
+	"oneclickvirt/model/common"
+	providerModel "oneclickvirt/model/provider"
+	"oneclickvirt/service/backup"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// CreateBackup 为实例创建备份任务，备份在任务中导出并上传到对象存储
+func (s *Service) CreateBackup(instanceID uint) (*adminModel.BackupTaskResponse, error) {
+	if _, err := backup.NewObjectStorage(); err != nil {
+		return nil, common.NewError(common.CodeValidationError, err.Error())
+	}
+
+	instance, err := s.getBackupInstance(instanceID)
+	if err != nil {
+		return nil, err
+	}
+
+	record := providerModel.Backup{
+		InstanceID:   instance.ID,
+		InstanceName: instance.Name,
+		ProviderID:   instance.ProviderID,
+		Status:       backup.StatusPending,
+	}
+	if err := global.APP_DB.Create(&record).Error; err != nil {
+		return nil, fmt.Errorf("创建备份记录失败: %v", err)
+	}
+	record.ObjectKey = backup.ObjectKey(record)
+	global.APP_DB.Model(&record).Update("object_key", record.ObjectKey)
+
+	task, err := s.createBackupTask(instance, record.ID, "backup")
+	if err != nil {
+		global.APP_DB.Delete(&record)
+		return nil, err
+	}
+
+	global.APP_LOG.Info("管理员创建实例备份任务",
+		zap.Uint("instanceID", instance.ID),
+		zap.Uint("backupID", record.ID),
+		zap.Uint("taskID", task.ID))
+	return &adminModel.BackupTaskResponse{Backup: record, TaskID: task.ID}, nil
+}
+
+// RestoreFromBackup 创建从备份恢复实例的任务，恢复会覆盖实例当前的数据
+func (s *Service) RestoreFromBackup(backupID uint) (*adminModel.BackupTaskResponse, error) {
+	if _, err := backup.NewObjectStorage(); err != nil {
+		return nil, common.NewError(common.CodeValidationError, err.Error())
+	}
+
+	var record providerModel.Backup
+	if err := global.APP_DB.First(&record, backupID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, common.NewError(common.CodeNotFound, "备份不存在")
+		}
+		return nil, fmt.Errorf("获取备份失败: %v", err)
+	}
+	if record.Status != backup.StatusCompleted {
+		return nil, common.NewError(common.CodeValidationError, "只能从已完成的备份恢复")
+	}
+
+	instance, err := s.getBackupInstance(record.InstanceID)
+	if err != nil {
+		return nil, err
+	}
+	if instance.ProviderID != record.ProviderID {
+		return nil, common.NewError(common.CodeValidationError, "实例已迁移到其他节点，无法从该备份恢复")
+	}
+
+	task, err := s.createBackupTask(instance, record.ID, "restore-backup")
+	if err != nil {
+		return nil, err
+	}
+
+	global.APP_LOG.Info("管理员创建实例恢复任务",
+		zap.Uint("instanceID", instance.ID),
+		zap.Uint("backupID", record.ID),
+		zap.Uint("taskID", task.ID))
+	return &adminModel.BackupTaskResponse{Backup: record, TaskID: task.ID}, nil
+}
+
+// GetBackupList 获取备份列表
+func (s *Service) GetBackupList(req adminModel.BackupListRequest) ([]providerModel.Backup, int64, error) {
+	query := global.APP_DB.Model(&providerModel.Backup{})
+	if req.InstanceID > 0 {
+		query = query.Where("instance_id = ?", req.InstanceID)
+	}
+	if req.Status != "" {
+		query = query.Where("status = ?", req.Status)
+	}
+	if req.Keyword != "" {
+		query = query.Where("instance_name LIKE ?", "%"+req.Keyword+"%")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var backups []providerModel.Backup
+	if err := query.Order("created_at DESC").
+		Offset((req.Page - 1) * req.PageSize).Limit(req.PageSize).
+		Find(&backups).Error; err != nil {
+		return nil, 0, err
+	}
+	return backups, total, nil
+}
+
+// DeleteBackup 删除备份及其在对象存储中的文件
+func (s *Service) DeleteBackup(backupID uint) error {
+	var record providerModel.Backup
+	if err := global.APP_DB.First(&record, backupID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return common.NewError(common.CodeNotFound, "备份不存在")
+		}
+		return fmt.Errorf("获取备份失败: %v", err)
+	}
+	// 任务被取消或超时时记录可能停留在进行中状态，只在确有进行中的备份任务时拒绝删除
+	if record.Status == backup.StatusPending || record.Status == backup.StatusRunning {
+		var activeTasks int64
+		global.APP_DB.Model(&adminModel.Task{}).
+			Where("instance_id = ? AND task_type = 'backup' AND status IN ('pending', 'running')", record.InstanceID).
+			Count(&activeTasks)
+		if activeTasks > 0 {
+			return common.NewError(common.CodeValidationError, "备份进行中，无法删除")
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	return backup.Delete(ctx, record)
+}
+
+func (s *Service) getBackupInstance(instanceID uint) (*providerModel.Instance, error) {
+	var instance providerModel.Instance
+	if err := global.APP_DB.First(&instance, instanceID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, common.NewError(common.CodeNotFound, "实例不存在")
+		}
+		return nil, fmt.Errorf("获取实例信息失败: %v", err)
+	}
+	if instance.Status != "running" && instance.Status != "stopped" {
+		return nil, common.NewError(common.CodeValidationError, fmt.Sprintf("实例当前状态为 %s，无法备份或恢复", instance.Status))
+	}
+
+	var existingTask adminModel.Task
+	if err := global.APP_DB.Where("instance_id = ? AND task_type IN ('backup', 'restore-backup') AND status IN ('pending', 'running')", instance.ID).
+		First(&existingTask).Error; err == nil {
+		return nil, common.NewError(common.CodeConflict, "该实例已有进行中的备份或恢复任务")
+	}
+	return &instance, nil
+}
+
+func (s *Service) createBackupTask(instance *providerModel.Instance, backupID uint, taskType string) (*adminModel.Task, error) {
+	taskData, err := json.Marshal(adminModel.BackupTaskRequest{
+		BackupId:   backupID,
+		InstanceId: instance.ID,
+		ProviderId: instance.ProviderID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("序列化任务数据失败: %v", err)
+	}
+
+	// 管理员任务使用实例的用户ID
+	task, err := s.taskService.CreateTask(instance.UserID, &instance.ProviderID, &instance.ID, taskType, string(taskData), utils.GetDefaultTaskTimeout(taskType))
+	if err != nil {
+		return nil, fmt.Errorf("创建任务失败: %v", err)
+	}
+	s.prioritizeAdminTask(task)
+	return task, nil
+}
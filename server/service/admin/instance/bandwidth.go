@@ -0,0 +1,84 @@
+package instance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"oneclickvirt/global"
+	adminModel "oneclickvirt/model/admin"
+	"oneclickvirt/model/common"
+	providerModel "oneclickvirt/model/provider"
+	"oneclickvirt/provider"
+	providerService "oneclickvirt/service/provider"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// SetInstanceBandwidth 调整实例级带宽限速并持久化
+// 运行中的实例立即生效；已停止的实例仅保存配置，下次启动时应用
+func (s *Service) SetInstanceBandwidth(instanceID uint, req adminModel.SetInstanceBandwidthRequest) error {
+	var instance providerModel.Instance
+	if err := global.APP_DB.First(&instance, instanceID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return common.NewError(common.CodeNotFound, "实例不存在")
+		}
+		return fmt.Errorf("获取实例信息失败: %v", err)
+	}
+
+	switch instance.Status {
+	case "creating", "deleting", "resetting", "failed":
+		return common.NewError(common.CodeValidationError, fmt.Sprintf("实例当前状态为 %s，无法调整带宽", instance.Status))
+	}
+
+	var dbProvider providerModel.Provider
+	if err := global.APP_DB.First(&dbProvider, instance.ProviderID).Error; err != nil {
+		return common.NewError(common.CodeNotFound, "实例所属Provider不存在")
+	}
+	if err := validateBandwidthLimit("入站", req.IngressMbps, dbProvider.MaxInboundBandwidth); err != nil {
+		return err
+	}
+	if err := validateBandwidthLimit("出站", req.EgressMbps, dbProvider.MaxOutboundBandwidth); err != nil {
+		return err
+	}
+
+	if instance.Status == "running" {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		defer cancel()
+
+		providerApiService := &providerService.ProviderApiService{}
+		if err := providerApiService.SetBandwidthLimitByProviderID(ctx, instance.ProviderID, instance.Name, req.EgressMbps, req.IngressMbps); err != nil {
+			return err
+		}
+	}
+
+	if err := global.APP_DB.Model(&instance).Updates(map[string]interface{}{
+		"ingress_mbps": req.IngressMbps,
+		"egress_mbps":  req.EgressMbps,
+	}).Error; err != nil {
+		return fmt.Errorf("更新实例带宽配置失败: %v", err)
+	}
+
+	global.APP_LOG.Info("管理员调整实例带宽限速成功",
+		zap.Uint("instanceID", instanceID),
+		zap.String("instanceName", instance.Name),
+		zap.Int("ingressMbps", req.IngressMbps),
+		zap.Int("egressMbps", req.EgressMbps),
+		zap.Bool("applied", instance.Status == "running"))
+	return nil
+}
+
+func validateBandwidthLimit(direction string, mbps, maxMbps int) error {
+	if mbps < 0 {
+		return common.NewError(common.CodeValidationError, fmt.Sprintf("%s带宽不能为负数", direction))
+	}
+	if maxMbps <= 0 || maxMbps > provider.MaxBandwidthMbps {
+		maxMbps = provider.MaxBandwidthMbps
+	}
+	if mbps > maxMbps {
+		return common.NewError(common.CodeValidationError, fmt.Sprintf("%s带宽不能超过Provider上限%dMbps", direction, maxMbps))
+	}
+	return nil
+}
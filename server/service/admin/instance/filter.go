@@ -0,0 +1,79 @@
+package instance
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"oneclickvirt/global"
+	"oneclickvirt/model/admin"
+	"oneclickvirt/model/common"
+	monitoringModel "oneclickvirt/model/monitoring"
+	providerModel "oneclickvirt/model/provider"
+
+	"gorm.io/gorm"
+)
+
+// providerHealthConditions 各健康状态对应的providers表查询条件，与 Provider.Health 的判断保持一致
+var providerHealthConditions = map[string]string{
+	providerModel.ProviderHealthOnline:  "(api_status = 'online' OR ssh_status = 'online') AND api_status <> 'offline' AND ssh_status <> 'offline'",
+	providerModel.ProviderHealthOffline: "(api_status = 'offline' OR ssh_status = 'offline') AND api_status <> 'online' AND ssh_status <> 'online'",
+	providerModel.ProviderHealthPartial: "(api_status = 'online' OR ssh_status = 'online') AND (api_status = 'offline' OR ssh_status = 'offline')",
+	providerModel.ProviderHealthUnknown: "api_status NOT IN ('online', 'offline') AND ssh_status NOT IN ('online', 'offline')",
+}
+
+// applyTriageFilters 应用Provider健康状态、流量同步时间和采集错误筛选
+// 全部通过子查询完成，实例表只按provider_id/id过滤，可使用(provider_id, status)索引
+func applyTriageFilters(query *gorm.DB, req admin.InstanceListRequest) (*gorm.DB, error) {
+	if req.ProviderHealth != "" {
+		var conditions []string
+		for _, health := range strings.Split(req.ProviderHealth, ",") {
+			health = strings.TrimSpace(health)
+			if health == "" {
+				continue
+			}
+			condition, ok := providerHealthConditions[health]
+			if !ok {
+				return nil, common.NewError(common.CodeValidationError, fmt.Sprintf("无效的Provider健康状态: %s", health))
+			}
+			conditions = append(conditions, "("+condition+")")
+		}
+		if len(conditions) > 0 {
+			providerIDs := global.APP_DB.Model(&providerModel.Provider{}).
+				Select("id").
+				Where(strings.Join(conditions, " OR "))
+			query = query.Where("instances.provider_id IN (?)", providerIDs)
+		}
+	}
+
+	if req.LastSyncBefore != "" {
+		before, err := parseFilterTime(req.LastSyncBefore)
+		if err != nil {
+			return nil, common.NewError(common.CodeValidationError, "lastSyncBefore时间格式错误")
+		}
+		instanceIDs := global.APP_DB.Model(&monitoringModel.PmacctMonitor{}).
+			Select("instance_id").
+			Where("is_enabled = ? AND last_sync < ?", true, before)
+		query = query.Where("instances.id IN (?)", instanceIDs)
+	}
+
+	if req.HasTrafficError != nil {
+		instanceIDs := global.APP_DB.Model(&monitoringModel.PmacctMonitor{}).
+			Select("instance_id").
+			Where("last_error_at IS NOT NULL")
+		if *req.HasTrafficError {
+			query = query.Where("instances.id IN (?)", instanceIDs)
+		} else {
+			query = query.Where("instances.id NOT IN (?)", instanceIDs)
+		}
+	}
+
+	return query, nil
+}
+
+func parseFilterTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02 15:04:05", value, time.Local)
+}
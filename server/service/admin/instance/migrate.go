@@ -0,0 +1,79 @@
+package instance
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"oneclickvirt/global"
+	adminModel "oneclickvirt/model/admin"
+	"oneclickvirt/model/common"
+	providerModel "oneclickvirt/model/provider"
+	providerService "oneclickvirt/service/provider"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// MigrateInstance 创建将实例迁移到同类型其他节点的任务
+// 迁移期间实例停机，源实例在数据切换到目标节点成功后才会删除
+func (s *Service) MigrateInstance(instanceID uint, req adminModel.MigrateInstanceRequest) (*adminModel.MigrateInstanceResponse, error) {
+	var instance providerModel.Instance
+	if err := global.APP_DB.First(&instance, instanceID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, common.NewError(common.CodeNotFound, "实例不存在")
+		}
+		return nil, fmt.Errorf("获取实例信息失败: %v", err)
+	}
+
+	var source, target providerModel.Provider
+	if err := global.APP_DB.First(&source, instance.ProviderID).Error; err != nil {
+		return nil, fmt.Errorf("获取实例所在节点失败: %v", err)
+	}
+	if err := global.APP_DB.First(&target, req.TargetProviderID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, common.NewError(common.CodeNotFound, "目标节点不存在")
+		}
+		return nil, fmt.Errorf("获取目标节点失败: %v", err)
+	}
+	if _, err := providerService.ValidateMigration(&instance, &source, &target); err != nil {
+		return nil, common.NewError(common.CodeValidationError, err.Error())
+	}
+
+	var activeTasks int64
+	global.APP_DB.Model(&adminModel.Task{}).
+		Where("instance_id = ? AND status IN ('pending', 'running')", instance.ID).
+		Count(&activeTasks)
+	if activeTasks > 0 {
+		return nil, common.NewError(common.CodeConflict, "该实例有进行中的任务，请稍后再迁移")
+	}
+
+	taskData, err := json.Marshal(adminModel.MigrateInstanceTaskRequest{
+		InstanceId:       instance.ID,
+		SourceProviderId: source.ID,
+		TargetProviderId: target.ID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("序列化任务数据失败: %v", err)
+	}
+
+	// 任务挂在源节点上，与该节点上的其他实例操作串行
+	task, err := s.taskService.CreateTask(instance.UserID, &source.ID, &instance.ID, "migrate", string(taskData), utils.GetDefaultTaskTimeout("migrate"))
+	if err != nil {
+		return nil, fmt.Errorf("创建任务失败: %v", err)
+	}
+	s.prioritizeAdminTask(task)
+
+	global.APP_LOG.Info("管理员创建实例迁移任务",
+		zap.Uint("instanceID", instance.ID),
+		zap.Uint("sourceProviderID", source.ID),
+		zap.Uint("targetProviderID", target.ID),
+		zap.Uint("taskID", task.ID))
+	return &adminModel.MigrateInstanceResponse{
+		InstanceID:       instance.ID,
+		SourceProviderID: source.ID,
+		TargetProviderID: target.ID,
+		TaskID:           task.ID,
+	}, nil
+}
@@ -0,0 +1,173 @@
+package instance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"oneclickvirt/global"
+	adminModel "oneclickvirt/model/admin"
+	"oneclickvirt/model/common"
+	monitoringModel "oneclickvirt/model/monitoring"
+	providerModel "oneclickvirt/model/provider"
+	"oneclickvirt/service/pmacct"
+	providerService "oneclickvirt/service/provider"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Linux接口名最长15个字符，只允许常见字符，避免拼接到SSH命令中产生注入
+var hostInterfacePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.@-]{0,14}$`)
+
+// GetMonitorInterfaces 获取实例的流量监控接口配置及pmacct当前实际使用的接口
+func (s *Service) GetMonitorInterfaces(instanceID uint) (*adminModel.MonitorInterfacesResponse, error) {
+	instance, err := s.getMonitorInstance(instanceID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &adminModel.MonitorInterfacesResponse{
+		InstanceID:  instance.ID,
+		Manual:      instance.PmacctInterfaceManual,
+		InterfaceV4: instance.PmacctInterfaceV4,
+		InterfaceV6: instance.PmacctInterfaceV6,
+	}
+	var monitor monitoringModel.PmacctMonitor
+	if err := global.APP_DB.Where("instance_id = ? AND is_enabled = ?", instance.ID, true).First(&monitor).Error; err == nil {
+		resp.ActiveInterfaceV4 = monitor.NetworkIfaceV4
+		resp.ActiveInterfaceV6 = monitor.NetworkIfaceV6
+	}
+	return resp, nil
+}
+
+// SetMonitorInterfaces 手动指定实例的流量监控接口，或恢复自动检测
+// 指定的接口必须存在于宿主机上；保存后重建该实例的pmacct监控使配置立即生效，流量历史保留
+func (s *Service) SetMonitorInterfaces(instanceID uint, req adminModel.SetMonitorInterfacesRequest) error {
+	instance, err := s.getMonitorInstance(instanceID)
+	if err != nil {
+		return err
+	}
+
+	updates := map[string]interface{}{
+		"pmacct_interface_manual": false,
+		"pmacct_interface_v4":     "",
+		"pmacct_interface_v6":     "",
+	}
+	if !req.Auto {
+		v4 := strings.TrimSpace(req.InterfaceV4)
+		v6 := strings.TrimSpace(req.InterfaceV6)
+		if v4 == "" {
+			return common.NewError(common.CodeValidationError, "必须指定IPv4监控接口")
+		}
+		if v6 == "" {
+			v6 = v4
+		}
+		if err := validateHostInterfaces(instance.ProviderID, v4, v6); err != nil {
+			return err
+		}
+		updates["pmacct_interface_manual"] = true
+		updates["pmacct_interface_v4"] = v4
+		updates["pmacct_interface_v6"] = v6
+	}
+
+	if err := global.APP_DB.Model(instance).Updates(updates).Error; err != nil {
+		return fmt.Errorf("保存监控接口失败: %w", err)
+	}
+
+	global.APP_LOG.Info("管理员更新实例流量监控接口",
+		zap.Uint("instanceID", instance.ID),
+		zap.Bool("auto", req.Auto),
+		zap.Any("interfaceV4", updates["pmacct_interface_v4"]),
+		zap.Any("interfaceV6", updates["pmacct_interface_v6"]))
+
+	// 已有监控时重建，未启用监控的实例在下次初始化时使用新配置
+	var monitorCount int64
+	global.APP_DB.Model(&monitoringModel.PmacctMonitor{}).Where("instance_id = ?", instance.ID).Count(&monitorCount)
+	if monitorCount == 0 {
+		return nil
+	}
+	pmacctService := pmacct.NewService()
+	if err := pmacctService.CleanupPmacctData(instance.ID); err != nil {
+		return fmt.Errorf("清理旧流量监控失败: %w", err)
+	}
+	if err := pmacctService.InitializePmacctForInstance(instance.ID); err != nil {
+		return common.NewError(common.CodeExternalAPIError, fmt.Sprintf("接口已保存，但重建流量监控失败: %v", err))
+	}
+	return nil
+}
+
+// SetInstanceMonitoring 开启或关闭实例的流量监控
+// 开启时立即初始化pmacct监控（Provider未启用流量统计时仅保存开关），关闭时清理宿主机上的监控并停止采集，流量历史保留
+func (s *Service) SetInstanceMonitoring(instanceID uint, enabled bool) error {
+	instance, err := s.getMonitorInstance(instanceID)
+	if err != nil {
+		return err
+	}
+	if err := global.APP_DB.Model(instance).Update("enable_monitoring", enabled).Error; err != nil {
+		return fmt.Errorf("保存监控开关失败: %w", err)
+	}
+
+	global.APP_LOG.Info("管理员设置实例流量监控开关",
+		zap.Uint("instanceID", instance.ID),
+		zap.Bool("enabled", enabled))
+
+	pmacctService := pmacct.NewService()
+	if enabled {
+		if err := pmacctService.InitializePmacctForInstance(instance.ID); err != nil {
+			return common.NewError(common.CodeExternalAPIError, fmt.Sprintf("已开启监控，但初始化流量监控失败: %v", err))
+		}
+		return nil
+	}
+
+	var monitorCount int64
+	global.APP_DB.Model(&monitoringModel.PmacctMonitor{}).Where("instance_id = ?", instance.ID).Count(&monitorCount)
+	if monitorCount == 0 {
+		return nil
+	}
+	if err := pmacctService.CleanupPmacctData(instance.ID); err != nil {
+		return common.NewError(common.CodeExternalAPIError, fmt.Sprintf("已关闭监控，但清理流量监控失败: %v", err))
+	}
+	return nil
+}
+
+func (s *Service) getMonitorInstance(instanceID uint) (*providerModel.Instance, error) {
+	var instance providerModel.Instance
+	if err := global.APP_DB.First(&instance, instanceID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, common.NewError(common.CodeNotFound, "实例不存在")
+		}
+		return nil, fmt.Errorf("查询实例失败: %w", err)
+	}
+	return &instance, nil
+}
+
+// validateHostInterfaces 校验接口名称格式并确认接口存在于宿主机上
+func validateHostInterfaces(providerID uint, names ...string) error {
+	for _, name := range names {
+		if !hostInterfacePattern.MatchString(name) {
+			return common.NewError(common.CodeValidationError, fmt.Sprintf("无效的接口名称: %s", name))
+		}
+	}
+
+	prov, exists := providerService.GetProviderService().GetProviderByID(providerID)
+	if !exists || !prov.IsConnected() {
+		return common.NewError(common.CodeExternalAPIError, "实例所属Provider未连接，无法校验接口")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	for _, name := range names {
+		output, err := prov.ExecuteSSHCommand(ctx, fmt.Sprintf("ip link show dev %s >/dev/null 2>&1 && echo exists || echo missing", name))
+		if err != nil {
+			return common.NewError(common.CodeExternalAPIError, fmt.Sprintf("校验接口失败: %v", err))
+		}
+		if strings.TrimSpace(output) != "exists" {
+			return common.NewError(common.CodeValidationError, fmt.Sprintf("宿主机上不存在接口 %s", name))
+		}
+	}
+	return nil
+}
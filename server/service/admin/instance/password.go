@@ -0,0 +1,83 @@
+package instance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"oneclickvirt/global"
+	adminModel "oneclickvirt/model/admin"
+	"oneclickvirt/model/common"
+	providerModel "oneclickvirt/model/provider"
+	providerService "oneclickvirt/service/provider"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ResetInstancePasswordSync 同步重置实例密码并直接返回新密码，不创建异步任务
+// 新密码由Provider的ResetInstancePassword生成，与实例创建时使用相同的密码规则
+func (s *Service) ResetInstancePasswordSync(instanceID uint) (*adminModel.ResetInstancePasswordSyncResponse, error) {
+	var instance providerModel.Instance
+	if err := global.APP_DB.First(&instance, instanceID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, common.NewError(common.CodeNotFound, "实例不存在")
+		}
+		return nil, fmt.Errorf("查询实例失败: %w", err)
+	}
+
+	if instance.Status != "running" {
+		return nil, common.NewError(common.CodeValidationError, "只有运行中的实例才能重置密码")
+	}
+
+	var runningTasks int64
+	if err := global.APP_DB.Model(&adminModel.Task{}).
+		Where("instance_id = ? AND task_type = 'reset-password' AND status IN ('pending', 'running')", instance.ID).
+		Count(&runningTasks).Error; err != nil {
+		return nil, fmt.Errorf("查询密码重置任务失败: %w", err)
+	}
+	if runningTasks > 0 {
+		return nil, common.NewError(common.CodeConflict, "该实例已有进行中的密码重置任务，请稍后重试")
+	}
+
+	var dbProvider providerModel.Provider
+	if err := global.APP_DB.First(&dbProvider, instance.ProviderID).Error; err != nil {
+		return nil, common.NewError(common.CodeNotFound, "实例所属Provider不存在")
+	}
+
+	timeout := time.Duration(dbProvider.SSHExecuteTimeout) * time.Second
+	if timeout <= 0 {
+		timeout = 300 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	newPassword, err := providerService.GetProviderService().ResetInstancePassword(ctx, instance.ProviderID, instance.Name)
+	if err != nil {
+		global.APP_LOG.Error("同步重置实例密码失败",
+			zap.Uint("instanceID", instance.ID),
+			zap.String("instanceName", instance.Name),
+			zap.Error(err))
+		return nil, common.NewError(common.CodeExternalAPIError, fmt.Sprintf("重置密码失败: %v", err))
+	}
+
+	resetTime := time.Now()
+	// 实例内密码已经修改，数据库更新失败时仍返回新密码，避免管理员丢失登录凭据
+	if err := global.APP_DB.Model(&instance).Update("password", newPassword).Error; err != nil {
+		global.APP_LOG.Error("同步重置密码后更新数据库失败",
+			zap.Uint("instanceID", instance.ID),
+			zap.Error(err))
+	}
+
+	global.APP_LOG.Info("管理员同步重置实例密码成功",
+		zap.Uint("instanceID", instance.ID),
+		zap.String("instanceName", instance.Name),
+		zap.Uint("userID", instance.UserID))
+
+	return &adminModel.ResetInstancePasswordSyncResponse{
+		InstanceID:  instance.ID,
+		NewPassword: newPassword,
+		ResetTime:   resetTime.Unix(),
+	}, nil
+}
@@ -0,0 +1,82 @@
+package instance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"oneclickvirt/global"
+	adminModel "oneclickvirt/model/admin"
+	"oneclickvirt/model/common"
+	providerModel "oneclickvirt/model/provider"
+	"oneclickvirt/provider"
+	providerService "oneclickvirt/service/provider"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ApplyInstanceProfile 为LXD/Incus实例应用profile
+// profile中包含可识别的CPU核心数或内存限制时，同步更新数据库中的实例规格
+func (s *Service) ApplyInstanceProfile(instanceID uint, req adminModel.ApplyInstanceProfileRequest) error {
+	if err := provider.ValidateProfileName(req.Profile); err != nil {
+		return common.NewError(common.CodeValidationError, err.Error())
+	}
+
+	var instance providerModel.Instance
+	if err := global.APP_DB.First(&instance, instanceID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return common.NewError(common.CodeNotFound, "实例不存在")
+		}
+		return fmt.Errorf("获取实例信息失败: %v", err)
+	}
+
+	switch instance.Status {
+	case "creating", "deleting", "resetting", "failed":
+		return common.NewError(common.CodeValidationError, fmt.Sprintf("实例当前状态为 %s，无法应用profile", instance.Status))
+	}
+
+	prov, err := providerService.GetProviderInstanceByID(instance.ProviderID)
+	if err != nil {
+		return fmt.Errorf("获取Provider失败: %v", err)
+	}
+	applier, ok := prov.(interface {
+		ApplyProfile(ctx context.Context, instanceName, profileName string, allowRestart bool) (*provider.Profile, error)
+	})
+	if !ok {
+		return common.NewError(common.CodeValidationError, fmt.Sprintf("Provider类型 %s 不支持profile管理", prov.GetType()))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	profile, err := applier.ApplyProfile(ctx, instance.Name, req.Profile, req.AllowRestart)
+	if err != nil {
+		if errors.Is(err, provider.ErrProfileRequiresStop) {
+			return common.NewError(common.CodeValidationError, "虚拟机运行中，请先停止实例或允许自动重启后再应用profile")
+		}
+		return common.NewError(common.CodeExternalAPIError, err.Error())
+	}
+
+	updates := map[string]interface{}{}
+	cpu, memoryMB := provider.ProfileLimits(profile.Config)
+	if cpu > 0 {
+		updates["cpu"] = cpu
+	}
+	if memoryMB > 0 {
+		updates["memory"] = memoryMB
+	}
+	if len(updates) > 0 {
+		if err := global.APP_DB.Model(&instance).Updates(updates).Error; err != nil {
+			return fmt.Errorf("profile已应用，但更新实例规格失败: %v", err)
+		}
+	}
+
+	global.APP_LOG.Info("管理员为实例应用profile成功",
+		zap.Uint("instanceID", instanceID),
+		zap.String("instanceName", instance.Name),
+		zap.String("profile", req.Profile),
+		zap.Any("updates", updates))
+	return nil
+}
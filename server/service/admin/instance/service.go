@@ -5,8 +5,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"oneclickvirt/service/cache"
 	"oneclickvirt/service/database"
 	"oneclickvirt/service/interfaces"
+	providerService "oneclickvirt/service/provider"
 	"oneclickvirt/service/resources"
 	"oneclickvirt/service/traffic"
 	"time"
@@ -14,8 +16,11 @@ import (
 	"oneclickvirt/global"
 	"oneclickvirt/model/admin"
 	adminModel "oneclickvirt/model/admin"
+	"oneclickvirt/model/common"
+	monitoringModel "oneclickvirt/model/monitoring"
 	providerModel "oneclickvirt/model/provider"
 	userModel "oneclickvirt/model/user"
+	providerPkg "oneclickvirt/provider"
 
 	"go.uber.org/zap"
 	"gorm.io/gorm"
@@ -75,6 +80,11 @@ func (s *Service) GetInstanceList(req admin.InstanceListRequest) ([]admin.Instan
 		query = query.Where("user_id = ?", req.UserID)
 	}
 
+	query, err := applyTriageFilters(query, req)
+	if err != nil {
+		return nil, 0, err
+	}
+
 	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
@@ -120,7 +130,7 @@ func (s *Service) GetInstanceList(req admin.InstanceListRequest) ([]admin.Instan
 
 	var providers []providerModel.Provider
 	if len(providerIDs) > 0 {
-		global.APP_DB.Select("id, name, type, region, status").
+		global.APP_DB.Select("id, name, type, region, status, api_status, ssh_status").
 			Where("id IN ?", providerIDs).
 			Limit(1000).
 			Find(&providers)
@@ -152,6 +162,18 @@ func (s *Service) GetInstanceList(req admin.InstanceListRequest) ([]admin.Instan
 		sshPortMap[port.InstanceID] = port
 	}
 
+	// 批量查询流量监控的同步状态
+	var monitors []monitoringModel.PmacctMonitor
+	if len(instanceIDs) > 0 {
+		global.APP_DB.Select("instance_id, last_sync, last_error, last_error_at").
+			Where("instance_id IN ?", instanceIDs).
+			Find(&monitors)
+	}
+	monitorMap := make(map[uint]monitoringModel.PmacctMonitor, len(monitors))
+	for _, monitor := range monitors {
+		monitorMap[monitor.InstanceID] = monitor
+	}
+
 	// 批量查询实例当月流量历史数据 - 使用统一的流量查询服务
 	now := time.Now()
 	year := now.Year()
@@ -222,6 +244,17 @@ func (s *Service) GetInstanceList(req admin.InstanceListRequest) ([]admin.Instan
 		if instance.ProviderID > 0 {
 			if prov, ok := providerMap[instance.ProviderID]; ok {
 				instanceResponse.ProviderType = prov.Type
+				instanceResponse.ProviderHealth = prov.Health()
+			}
+		}
+
+		if monitor, ok := monitorMap[instance.ID]; ok {
+			if !monitor.LastSync.IsZero() {
+				lastSync := monitor.LastSync
+				instanceResponse.TrafficLastSync = &lastSync
+			}
+			if monitor.LastErrorAt != nil {
+				instanceResponse.TrafficError = monitor.LastError
 			}
 		}
 		instanceResponses = append(instanceResponses, instanceResponse)
@@ -232,6 +265,11 @@ func (s *Service) GetInstanceList(req admin.InstanceListRequest) ([]admin.Instan
 
 // CreateInstance 创建实例
 func (s *Service) CreateInstance(req admin.CreateInstanceRequest) error {
+	// 实例名称会拼接到Provider的远程命令中，必须先校验
+	if err := providerPkg.ValidateInstanceName(req.Name); err != nil {
+		return common.NewError(common.CodeValidationError, err.Error())
+	}
+
 	// 使用新的配额验证服务，即使是管理员也需要检查用户配额
 	quotaService := resources.NewQuotaService()
 
@@ -265,6 +303,11 @@ func (s *Service) CreateInstance(req admin.CreateInstanceRequest) error {
 		return fmt.Errorf("提供商 %s 已被冻结，无法创建实例", req.Provider)
 	}
 
+	// 维护中的节点不接受新实例，需要先关闭维护模式
+	if provider.MaintenanceMode {
+		return fmt.Errorf("提供商 %s 正在维护，无法创建实例", req.Provider)
+	}
+
 	// 检查提供商是否过期
 	if provider.ExpiresAt != nil && provider.ExpiresAt.Before(time.Now()) {
 		return fmt.Errorf("提供商 %s 已过期，无法创建实例", req.Provider)
@@ -337,6 +380,12 @@ func (s *Service) UpdateInstance(req admin.UpdateInstanceRequest) error {
 		return err
 	}
 
+	if req.Name != instance.Name {
+		if err := providerPkg.ValidateInstanceName(req.Name); err != nil {
+			return common.NewError(common.CodeValidationError, err.Error())
+		}
+	}
+
 	instance.Name = req.Name
 	instance.CPU = req.CPU
 	instance.Memory = req.Memory
@@ -389,8 +438,11 @@ func (s *Service) DeleteInstance(instanceID uint) error {
 		return fmt.Errorf("创建删除任务失败: %v", err)
 	}
 
-	// 标记任务为管理员操作，不允许用户取消
-	if err := global.APP_DB.Model(task).Update("is_force_stoppable", false).Error; err != nil {
+	// 标记任务为管理员操作，不允许用户取消，并优先于用户的常规任务执行
+	if err := global.APP_DB.Model(task).Updates(map[string]interface{}{
+		"is_force_stoppable": false,
+		"priority":           adminModel.TaskPriorityAdmin,
+	}).Error; err != nil {
 		global.APP_LOG.Warn("更新任务可取消状态失败", zap.Uint("taskId", task.ID), zap.Error(err))
 	}
 
@@ -407,6 +459,13 @@ func (s *Service) DeleteInstance(instanceID uint) error {
 	return nil
 }
 
+// prioritizeAdminTask 提升管理员发起任务的优先级，使其排在用户的常规任务之前
+func (s *Service) prioritizeAdminTask(task *adminModel.Task) {
+	if err := s.taskService.SetTaskPriority(task.ID, adminModel.TaskPriorityAdmin); err != nil {
+		global.APP_LOG.Warn("提升管理员任务优先级失败", zap.Uint("taskId", task.ID), zap.Error(err))
+	}
+}
+
 // InstanceAction 管理员执行实例操作
 func (s *Service) InstanceAction(instanceID uint, req admin.InstanceActionRequest) error {
 	// 获取实例信息
@@ -420,7 +479,11 @@ func (s *Service) InstanceAction(instanceID uint, req admin.InstanceActionReques
 
 	// 根据操作类型执行相应的操作
 	switch req.Action {
-	case "start", "stop", "restart", "reset":
+	case "start", "stop", "restart", "reset", "recreate":
+		// 重建需要按创建时保存的配置进行，早期创建的实例没有保存配置
+		if req.Action == "recreate" && instance.CreateConfig == nil {
+			return errors.New("实例没有保存创建配置，无法重建")
+		}
 		// 创建异步任务
 		taskData := map[string]interface{}{
 			"instanceId": instanceID,
@@ -433,17 +496,19 @@ func (s *Service) InstanceAction(instanceID uint, req admin.InstanceActionReques
 			return fmt.Errorf("序列化任务数据失败: %v", err)
 		}
 
-		_, err = s.taskService.CreateTask(instance.UserID, &instance.ProviderID, &instanceID, req.Action, string(taskDataJSON), 1800)
+		task, err := s.taskService.CreateTask(instance.UserID, &instance.ProviderID, &instanceID, req.Action, string(taskDataJSON), 1800)
 		if err != nil {
 			return fmt.Errorf("创建任务失败: %v", err)
 		}
+		s.prioritizeAdminTask(task)
 
 		// 更新实例状态
 		statusMap := map[string]string{
-			"start":   "starting",
-			"stop":    "stopping",
-			"restart": "restarting",
-			"reset":   "resetting",
+			"start":    "starting",
+			"stop":     "stopping",
+			"restart":  "restarting",
+			"reset":    "resetting",
+			"recreate": "recreating",
 		}
 		if newStatus, exists := statusMap[req.Action]; exists {
 			instance.Status = newStatus
@@ -472,8 +537,11 @@ func (s *Service) InstanceAction(instanceID uint, req admin.InstanceActionReques
 			return fmt.Errorf("创建删除任务失败: %v", err)
 		}
 
-		// 标记任务为管理员操作，不允许用户取消
-		if err := global.APP_DB.Model(task).Update("is_force_stoppable", false).Error; err != nil {
+		// 标记任务为管理员操作，不允许用户取消，并优先于用户的常规任务执行
+		if err := global.APP_DB.Model(task).Updates(map[string]interface{}{
+			"is_force_stoppable": false,
+			"priority":           adminModel.TaskPriorityAdmin,
+		}).Error; err != nil {
 			return fmt.Errorf("更新任务权限失败: %v", err)
 		}
 
@@ -490,6 +558,108 @@ func (s *Service) InstanceAction(instanceID uint, req admin.InstanceActionReques
 	return nil
 }
 
+// ResizeInstanceDisk 管理员调整实例磁盘大小，成功后同步更新数据库中的磁盘大小
+func (s *Service) ResizeInstanceDisk(instanceID uint, newSizeGB int) error {
+	if newSizeGB <= 0 {
+		return errors.New("磁盘大小必须为正整数")
+	}
+
+	var instance providerModel.Instance
+	if err := global.APP_DB.First(&instance, instanceID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("实例不存在")
+		}
+		return fmt.Errorf("获取实例信息失败: %v", err)
+	}
+
+	switch instance.Status {
+	case "creating", "deleting", "resetting", "failed":
+		return fmt.Errorf("实例当前状态为 %s，无法调整磁盘大小", instance.Status)
+	}
+
+	prov, err := providerService.GetProviderInstanceByID(instance.ProviderID)
+	if err != nil {
+		return fmt.Errorf("获取Provider失败: %v", err)
+	}
+
+	resizer, ok := prov.(interface {
+		ResizeInstanceDisk(ctx context.Context, instanceName string, newSizeGB int) error
+	})
+	if !ok {
+		return fmt.Errorf("Provider类型 %s 不支持调整磁盘大小", prov.GetType())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	if err := resizer.ResizeInstanceDisk(ctx, instance.Name, newSizeGB); err != nil {
+		return fmt.Errorf("调整磁盘大小失败: %v", err)
+	}
+
+	// 数据库中磁盘大小以MB存储
+	if err := global.APP_DB.Model(&instance).Update("disk", int64(newSizeGB)*1024).Error; err != nil {
+		return fmt.Errorf("更新实例磁盘大小失败: %v", err)
+	}
+
+	// 磁盘变化后重算用户配额占用和节点已用磁盘
+	if err := resources.NewQuotaService().RecalculateUserQuota(instance.UserID); err != nil {
+		global.APP_LOG.Warn("调整磁盘大小后重算用户配额失败",
+			zap.Uint("userID", instance.UserID),
+			zap.Error(err))
+	}
+	(&resources.ResourceService{}).SyncProviderResourcesAsync(instance.ProviderID)
+	cacheService := cache.GetUserCacheService()
+	cacheService.InvalidateUserCache(instance.UserID)
+	cacheService.InvalidateInstanceCache(instanceID)
+
+	global.APP_LOG.Info("管理员调整实例磁盘大小成功",
+		zap.Uint("instanceID", instanceID),
+		zap.String("instanceName", instance.Name),
+		zap.Int64("oldDiskMB", instance.Disk),
+		zap.Int("newSizeGB", newSizeGB))
+
+	return nil
+}
+
+// GetInstanceDeletePlan 获取实例删除计划，仅返回Provider将执行的命令，不实际执行
+func (s *Service) GetInstanceDeletePlan(instanceID uint) (*adminModel.InstanceDeletePlanResponse, error) {
+	var instance providerModel.Instance
+	if err := global.APP_DB.First(&instance, instanceID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("实例不存在")
+		}
+		return nil, fmt.Errorf("获取实例信息失败: %v", err)
+	}
+
+	prov, err := providerService.GetProviderInstanceByID(instance.ProviderID)
+	if err != nil {
+		return nil, fmt.Errorf("获取Provider失败: %v", err)
+	}
+
+	planner, ok := prov.(interface {
+		PlanDeleteInstance(ctx context.Context, instanceName string) ([]string, error)
+	})
+	if !ok {
+		return nil, fmt.Errorf("Provider类型 %s 不支持删除预演", prov.GetType())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	commands, err := planner.PlanDeleteInstance(ctx, instance.Name)
+	if err != nil {
+		return nil, fmt.Errorf("生成删除计划失败: %v", err)
+	}
+
+	return &adminModel.InstanceDeletePlanResponse{
+		InstanceID:   instance.ID,
+		InstanceName: instance.Name,
+		ProviderID:   instance.ProviderID,
+		ProviderType: prov.GetType(),
+		Commands:     commands,
+	}, nil
+}
+
 // ResetInstancePassword 管理员重置实例密码（异步任务）
 func (s *Service) ResetInstancePassword(instanceID uint) (uint, error) {
 	// 获取实例信息
@@ -531,6 +701,7 @@ func (s *Service) ResetInstancePassword(instanceID uint) (uint, error) {
 			zap.Error(err))
 		return 0, fmt.Errorf("创建密码重置任务失败: %v", err)
 	}
+	s.prioritizeAdminTask(task)
 
 	global.APP_LOG.Info("管理员创建密码重置任务成功",
 		zap.Uint("instanceID", instanceID),
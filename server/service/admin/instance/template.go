@@ -0,0 +1,247 @@
+package instance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"oneclickvirt/global"
+	adminModel "oneclickvirt/model/admin"
+	"oneclickvirt/model/common"
+	providerModel "oneclickvirt/model/provider"
+	providerService "oneclickvirt/service/provider"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// CreateTemplate 将实例标记为模板
+// 模板副本由异步任务在实例所在节点上复制生成，复制完成前模板处于creating状态
+func (s *Service) CreateTemplate(instanceID uint, req adminModel.CreateInstanceTemplateRequest) (*adminModel.InstanceTemplateTaskResponse, error) {
+	var instance providerModel.Instance
+	if err := global.APP_DB.First(&instance, instanceID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, common.NewError(common.CodeNotFound, "实例不存在")
+		}
+		return nil, fmt.Errorf("获取实例信息失败: %v", err)
+	}
+	if instance.Status != "running" && instance.Status != "stopped" {
+		return nil, common.NewError(common.CodeValidationError, fmt.Sprintf("实例当前状态为 %s，无法制作模板", instance.Status))
+	}
+
+	prov, err := providerService.GetProviderInstanceByID(instance.ProviderID)
+	if err != nil {
+		return nil, fmt.Errorf("获取Provider实例失败: %v", err)
+	}
+	if _, ok := prov.(interface {
+		CloneInstance(ctx context.Context, sourceName, newName string) error
+	}); !ok {
+		return nil, common.NewError(common.CodeValidationError, fmt.Sprintf("Provider类型 %s 不支持克隆实例，无法制作模板", prov.GetType()))
+	}
+
+	template := providerModel.InstanceTemplate{
+		Name:             req.Name,
+		Description:      req.Description,
+		ProviderID:       instance.ProviderID,
+		SourceInstanceID: instance.ID,
+		InstanceType:     instance.InstanceType,
+		Image:            instance.Image,
+		OSType:           instance.OSType,
+		CPU:              instance.CPU,
+		Memory:           instance.Memory,
+		Disk:             instance.Disk,
+		Bandwidth:        instance.Bandwidth,
+		Status:           providerModel.TemplateStatusCreating,
+		Enabled:          true,
+	}
+	err = global.APP_DB.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&providerModel.InstanceTemplate{}).Where("name = ?", req.Name).Count(&count).Error; err != nil {
+			return fmt.Errorf("检查模板名称失败: %v", err)
+		}
+		if count > 0 {
+			return common.NewError(common.CodeConflict, "模板名称已存在")
+		}
+
+		name, err := resolveTemplateInstanceName(tx, instance.ProviderID)
+		if err != nil {
+			return err
+		}
+		template.InstanceName = name
+		if err := tx.Create(&template).Error; err != nil {
+			return fmt.Errorf("创建模板记录失败: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	taskData, err := json.Marshal(adminModel.CreateTemplateTaskRequest{
+		TemplateId:       template.ID,
+		SourceInstanceId: instance.ID,
+		ProviderId:       instance.ProviderID,
+	})
+	if err != nil {
+		global.APP_DB.Delete(&template)
+		return nil, fmt.Errorf("序列化任务数据失败: %v", err)
+	}
+
+	// 管理员任务使用实例的用户ID，任务占用源实例，复制期间源实例不会被其他操作修改
+	task, err := s.taskService.CreateTask(instance.UserID, &instance.ProviderID, &instance.ID, "create-template", string(taskData), utils.GetDefaultTaskTimeout("create-template"))
+	if err != nil {
+		global.APP_DB.Delete(&template)
+		return nil, fmt.Errorf("创建任务失败: %v", err)
+	}
+	s.prioritizeAdminTask(task)
+
+	global.APP_LOG.Info("管理员创建实例模板任务",
+		zap.Uint("instanceID", instance.ID),
+		zap.Uint("templateID", template.ID),
+		zap.String("templateName", template.Name),
+		zap.Uint("taskID", task.ID))
+	return &adminModel.InstanceTemplateTaskResponse{Template: template, TaskID: task.ID}, nil
+}
+
+// resolveTemplateInstanceName 生成节点上模板副本的实例名称，需与节点上的实例（含软删除记录）和其他模板副本都不冲突
+func resolveTemplateInstanceName(tx *gorm.DB, providerID uint) (string, error) {
+	for attempt := 0; attempt < 10; attempt++ {
+		name := utils.GenerateInstanceName("tpl")
+		var instances, templates int64
+		if err := tx.Unscoped().Model(&providerModel.Instance{}).
+			Where("name = ? AND provider_id = ?", name, providerID).
+			Count(&instances).Error; err != nil {
+			return "", fmt.Errorf("检查实例名称失败: %v", err)
+		}
+		if err := tx.Model(&providerModel.InstanceTemplate{}).
+			Where("instance_name = ? AND provider_id = ?", name, providerID).
+			Count(&templates).Error; err != nil {
+			return "", fmt.Errorf("检查模板副本名称失败: %v", err)
+		}
+		if instances == 0 && templates == 0 {
+			return name, nil
+		}
+	}
+	return "", errors.New("生成模板副本名称失败，请稍后重试")
+}
+
+// GetTemplateList 分页查询实例模板
+func (s *Service) GetTemplateList(req adminModel.InstanceTemplateListRequest) ([]providerModel.InstanceTemplate, int64, error) {
+	query := global.APP_DB.Model(&providerModel.InstanceTemplate{})
+	if req.ProviderID > 0 {
+		query = query.Where("provider_id = ?", req.ProviderID)
+	}
+	if req.Status != "" {
+		query = query.Where("status = ?", req.Status)
+	}
+	if req.Keyword != "" {
+		query = query.Where("name LIKE ?", "%"+req.Keyword+"%")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var templates []providerModel.InstanceTemplate
+	if err := query.Order("created_at DESC").
+		Offset((req.Page - 1) * req.PageSize).Limit(req.PageSize).
+		Find(&templates).Error; err != nil {
+		return nil, 0, err
+	}
+	return templates, total, nil
+}
+
+// UpdateTemplate 更新模板名称、描述和启用状态
+func (s *Service) UpdateTemplate(templateID uint, req adminModel.UpdateInstanceTemplateRequest) (*providerModel.InstanceTemplate, error) {
+	template, err := getTemplate(templateID)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{}
+	if req.Name != nil && *req.Name != template.Name {
+		var count int64
+		global.APP_DB.Model(&providerModel.InstanceTemplate{}).Where("name = ? AND id <> ?", *req.Name, templateID).Count(&count)
+		if count > 0 {
+			return nil, common.NewError(common.CodeConflict, "模板名称已存在")
+		}
+		updates["name"] = *req.Name
+	}
+	if req.Description != nil {
+		updates["description"] = *req.Description
+	}
+	if req.Enabled != nil {
+		updates["enabled"] = *req.Enabled
+	}
+	if len(updates) > 0 {
+		if err := global.APP_DB.Model(template).Updates(updates).Error; err != nil {
+			return nil, fmt.Errorf("更新模板失败: %v", err)
+		}
+	}
+	return getTemplate(templateID)
+}
+
+// DeleteTemplate 删除模板及节点上的模板副本
+// 已从模板创建的实例是独立的副本，不受影响；仍有进行中的复制或创建任务时拒绝删除
+func (s *Service) DeleteTemplate(templateID uint) error {
+	template, err := getTemplate(templateID)
+	if err != nil {
+		return err
+	}
+
+	var activeTasks int64
+	global.APP_DB.Model(&adminModel.Task{}).
+		Where("task_type IN ('create-template', 'create-from-template') AND status IN ('pending', 'running')").
+		Where("task_data LIKE ?", fmt.Sprintf(`%%"templateId":%d,%%`, template.ID)).
+		Count(&activeTasks)
+	if activeTasks > 0 {
+		return common.NewError(common.CodeConflict, "模板有进行中的任务，请稍后再删除")
+	}
+
+	if err := deleteTemplateInstance(template); err != nil {
+		return err
+	}
+	if err := global.APP_DB.Delete(template).Error; err != nil {
+		return fmt.Errorf("删除模板记录失败: %v", err)
+	}
+
+	global.APP_LOG.Info("管理员删除实例模板",
+		zap.Uint("templateID", template.ID),
+		zap.String("templateName", template.Name),
+		zap.String("instanceName", template.InstanceName))
+	return nil
+}
+
+// deleteTemplateInstance 删除节点上的模板副本，副本不存在（如复制失败）时直接返回
+func deleteTemplateInstance(template *providerModel.InstanceTemplate) error {
+	prov, err := providerService.GetProviderInstanceByID(template.ProviderID)
+	if err != nil {
+		return fmt.Errorf("获取Provider实例失败: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	if info, err := prov.GetInstance(ctx, template.InstanceName); err != nil || info == nil {
+		return nil
+	}
+	if err := prov.DeleteInstance(ctx, template.InstanceName); err != nil {
+		return fmt.Errorf("删除节点上的模板副本失败: %v", err)
+	}
+	return nil
+}
+
+func getTemplate(templateID uint) (*providerModel.InstanceTemplate, error) {
+	var template providerModel.InstanceTemplate
+	if err := global.APP_DB.First(&template, templateID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, common.NewError(common.CodeNotFound, "模板不存在")
+		}
+		return nil, fmt.Errorf("获取模板失败: %v", err)
+	}
+	return &template, nil
+}
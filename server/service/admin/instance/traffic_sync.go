@@ -0,0 +1,113 @@
+package instance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"oneclickvirt/global"
+	adminModel "oneclickvirt/model/admin"
+	"oneclickvirt/model/common"
+	monitoringModel "oneclickvirt/model/monitoring"
+	providerModel "oneclickvirt/model/provider"
+	"oneclickvirt/service/pmacct"
+	providerService "oneclickvirt/service/provider"
+	"oneclickvirt/service/traffic"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ForceSyncInstanceTraffic 立即采集单个实例的pmacct流量数据并返回当月最新统计
+// 采集在Provider的SSH执行超时内完成，超时后直接返回，不等待采集结束
+func (s *Service) ForceSyncInstanceTraffic(instanceID uint) (*adminModel.InstanceTrafficSyncResponse, error) {
+	var instance providerModel.Instance
+	if err := global.APP_DB.First(&instance, instanceID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, common.NewError(common.CodeNotFound, "实例不存在")
+		}
+		return nil, fmt.Errorf("查询实例失败: %w", err)
+	}
+
+	var dbProvider providerModel.Provider
+	if err := global.APP_DB.First(&dbProvider, instance.ProviderID).Error; err != nil {
+		return nil, common.NewError(common.CodeNotFound, "实例所属Provider不存在")
+	}
+	if !dbProvider.EnableTrafficControl {
+		return nil, common.NewError(common.CodeValidationError, "实例所属Provider未启用流量统计")
+	}
+
+	var monitor monitoringModel.PmacctMonitor
+	if err := global.APP_DB.Where("instance_id = ? AND is_enabled = ?", instanceID, true).First(&monitor).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, common.NewError(common.CodeValidationError, "实例未启用流量监控")
+		}
+		return nil, fmt.Errorf("查询流量监控失败: %w", err)
+	}
+
+	if prov, exists := providerService.GetProviderService().GetProviderByID(dbProvider.ID); exists && !prov.IsConnected() {
+		return nil, common.NewError(common.CodeExternalAPIError, fmt.Sprintf("Provider %s 未连接，无法采集流量", dbProvider.Name))
+	}
+
+	timeout := time.Duration(dbProvider.SSHExecuteTimeout) * time.Second
+	if timeout <= 0 {
+		timeout = 300 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("采集流量时发生异常: %v", r)
+			}
+		}()
+		done <- pmacct.NewServiceWithContext(ctx).CollectTrafficFromSQLite(&instance, &monitor)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			global.APP_LOG.Warn("管理员强制同步实例流量失败",
+				zap.Uint("instanceID", instanceID),
+				zap.Error(err))
+			return nil, common.NewError(common.CodeExternalAPIError, fmt.Sprintf("采集流量失败: %v", err))
+		}
+	case <-ctx.Done():
+		global.APP_LOG.Warn("管理员强制同步实例流量超时",
+			zap.Uint("instanceID", instanceID),
+			zap.Duration("timeout", timeout))
+		return nil, common.NewError(common.CodeExternalAPIError, fmt.Sprintf("采集流量超时（%s）", timeout))
+	}
+
+	now := time.Now()
+	stats, err := traffic.NewQueryService().GetInstanceMonthlyTraffic(instanceID, now.Year(), int(now.Month()))
+	if err != nil {
+		return nil, fmt.Errorf("查询实例流量统计失败: %w", err)
+	}
+
+	resp := &adminModel.InstanceTrafficSyncResponse{
+		InstanceID:    instanceID,
+		Year:          now.Year(),
+		Month:         int(now.Month()),
+		RxBytes:       stats.RxBytes,
+		TxBytes:       stats.TxBytes,
+		TotalBytes:    stats.TotalBytes,
+		ActualUsageMB: stats.ActualUsageMB,
+		DurationMs:    time.Since(start).Milliseconds(),
+	}
+	var refreshed monitoringModel.PmacctMonitor
+	if err := global.APP_DB.Select("last_sync").First(&refreshed, monitor.ID).Error; err == nil && !refreshed.LastSync.IsZero() {
+		resp.LastSync = &refreshed.LastSync
+	}
+
+	global.APP_LOG.Info("管理员强制同步实例流量完成",
+		zap.Uint("instanceID", instanceID),
+		zap.Int64("totalBytes", resp.TotalBytes),
+		zap.Int64("durationMs", resp.DurationMs))
+
+	return resp, nil
+}
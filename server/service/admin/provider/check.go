@@ -9,7 +9,7 @@ import (
 	"oneclickvirt/service/database"
 	"oneclickvirt/service/images"
 	provider2 "oneclickvirt/service/provider"
-	"strings"
+	"oneclickvirt/utils"
 	"time"
 
 	"go.uber.org/zap"
@@ -45,21 +45,16 @@ func (s *Service) CheckProviderHealthWithOptions(providerID uint, forceRefresh b
 	localProviderName := provider.Name
 	localProviderType := provider.Type
 	localEndpoint := provider.Endpoint
-	localUsername := provider.Username
-	localPassword := provider.Password
-	localSSHKey := provider.SSHKey
-	localSSHPort := provider.SSHPort
-	if localSSHPort == 0 {
-		localSSHPort = 22 // 如果数据库中没有设置SSH端口，使用默认值22
-	}
+	// SSH连接配置（包含私钥密码短语、ssh-agent和主机密钥校验设置）
+	sshConfig := utils.ProviderSSHConfig(&provider, 30*time.Second, 0)
+	localSSHPort := sshConfig.Port
 	localAutoConfigured := provider.AutoConfigured
 	localAuthConfig := provider.AuthConfig
 
 	now := time.Now()
 	ctx := context.Background()
 
-	// 解析endpoint获取主机
-	host := strings.Split(localEndpoint, ":")[0]
+	host := sshConfig.Host
 
 	global.APP_LOG.Info("开始检查Provider健康状态",
 		zap.Uint("providerId", localProviderID),
@@ -91,14 +86,14 @@ func (s *Service) CheckProviderHealthWithOptions(providerID uint, forceRefresh b
 
 			// 使用认证配置执行完整健康检查（包含API检查），并获取主机名
 			sshStatus, apiStatus, hostName, err = images.CheckProviderHealthWithConfig(
-				ctx, localProviderID, localProviderName, localProviderType, host, localUsername, localPassword, localSSHKey, localSSHPort, authConfig)
+				ctx, localProviderID, localProviderName, localProviderType, sshConfig, authConfig)
 		} else {
 			// 配置加载失败，只进行SSH检查
 			global.APP_LOG.Warn("加载Provider配置失败，仅进行SSH检查",
 				zap.String("provider", localProviderName),
 				zap.Error(configErr))
 
-			if sshErr := healthChecker.CheckSSHConnection(ctx, localProviderID, localProviderName, host, localUsername, localPassword, localSSHKey, localSSHPort); sshErr != nil {
+			if sshErr := healthChecker.CheckSSHConnection(ctx, localProviderID, localProviderName, sshConfig); sshErr != nil {
 				sshStatus = "offline"
 			} else {
 				sshStatus = "online"
@@ -107,7 +102,7 @@ func (s *Service) CheckProviderHealthWithOptions(providerID uint, forceRefresh b
 		}
 	} else {
 		// 未自动配置的Provider，只进行SSH检查
-		if sshErr := healthChecker.CheckSSHConnection(ctx, localProviderID, localProviderName, host, localUsername, localPassword, localSSHKey, localSSHPort); sshErr != nil {
+		if sshErr := healthChecker.CheckSSHConnection(ctx, localProviderID, localProviderName, sshConfig); sshErr != nil {
 			sshStatus = "offline"
 		} else {
 			sshStatus = "online"
@@ -143,7 +138,7 @@ func (s *Service) CheckProviderHealthWithOptions(providerID uint, forceRefresh b
 			zap.Int("sshPort", localSSHPort),
 			zap.Bool("forceRefresh", forceRefresh))
 
-		resourceInfo, resourceErr := healthChecker.GetSystemResourceInfoWithKey(ctx, localProviderID, localProviderName, host, localUsername, localPassword, localSSHKey, localSSHPort)
+		resourceInfo, resourceErr := healthChecker.GetSystemResourceInfoWithKey(ctx, localProviderID, localProviderName, sshConfig)
 		if resourceErr != nil {
 			global.APP_LOG.Warn("获取系统资源信息失败",
 				zap.String("provider", localProviderName),
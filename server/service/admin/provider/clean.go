@@ -12,6 +12,7 @@ import (
 	"oneclickvirt/service/pmacct"
 	providerService "oneclickvirt/service/provider"
 	"oneclickvirt/service/task"
+	"oneclickvirt/utils"
 
 	"go.uber.org/zap"
 	"gorm.io/gorm"
@@ -171,6 +172,9 @@ func (s *Service) cleanupAllProviderResources(providerID uint) {
 	provider.GetTransportCleanupManager().CleanupProvider(providerID)
 	global.APP_LOG.Debug("HTTP Transport已清理", zap.Uint("providerID", providerID))
 
+	// 6. 清理最近命令记录
+	utils.ClearCommandLog(providerID)
+
 	global.APP_LOG.Info("所有Provider内存资源清理完成", zap.Uint("providerID", providerID))
 }
 
@@ -0,0 +1,203 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"oneclickvirt/global"
+	"oneclickvirt/model/admin"
+	providerModel "oneclickvirt/model/provider"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+)
+
+// proxmoxClusterStatusCommand 查询集群成员及其地址，pvecm nodes 不输出节点地址，因此使用API的集群状态
+const proxmoxClusterStatusCommand = "pvesh get /cluster/status --output-format json"
+
+// proxmoxClusterMember pvesh get /cluster/status 返回的条目，type为cluster时是集群本身，为node时是成员节点
+type proxmoxClusterMember struct {
+	Type   string `json:"type"`
+	Name   string `json:"name"`
+	IP     string `json:"ip"`
+	Local  int    `json:"local"`
+	Online int    `json:"online"`
+}
+
+// ImportProxmoxCluster 通过一个Proxmox集群节点发现全部集群成员并逐个添加为Provider
+// 成员节点沿用请求中的配置和凭据，SSH地址替换为集群中登记的节点地址；已添加的节点跳过，无法连接的节点记为失败
+func (s *Service) ImportProxmoxCluster(req admin.ImportProxmoxClusterRequest) (*admin.ImportProxmoxClusterResponse, error) {
+	seed := req.Provider
+	if seed.Type != "proxmox" {
+		return nil, fmt.Errorf("仅支持Proxmox集群")
+	}
+	if seed.Endpoint == "" {
+		return nil, fmt.Errorf("请提供集群中任一节点的SSH地址")
+	}
+	if seed.Password == "" && seed.SSHKey == "" && !seed.SSHUseAgent {
+		return nil, fmt.Errorf("必须提供SSH密码或SSH密钥其中一种认证方式")
+	}
+	if seed.SSHPort == 0 {
+		seed.SSHPort = 22
+	}
+
+	clusterName, members, err := discoverProxmoxCluster(seed)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &admin.ImportProxmoxClusterResponse{
+		ClusterName: clusterName,
+		Results:     make([]admin.ProxmoxClusterNodeResult, 0, len(members)),
+	}
+	for _, member := range members {
+		result := s.importProxmoxClusterNode(seed, member)
+		switch result.Status {
+		case "created":
+			resp.Created++
+		case "skipped":
+			resp.Skipped++
+		default:
+			resp.Failed++
+		}
+		resp.Results = append(resp.Results, result)
+	}
+
+	global.APP_LOG.Info("导入Proxmox集群节点完成",
+		zap.String("cluster", clusterName),
+		zap.Int("created", resp.Created),
+		zap.Int("skipped", resp.Skipped),
+		zap.Int("failed", resp.Failed))
+
+	return resp, nil
+}
+
+// discoverProxmoxCluster 登录种子节点查询集群名称和成员节点
+func discoverProxmoxCluster(seed admin.CreateProviderRequest) (string, []proxmoxClusterMember, error) {
+	sshClient, err := utils.NewSSHClient(clusterSSHConfig(seed, utils.ExtractHost(seed.Endpoint)))
+	if err != nil {
+		return "", nil, fmt.Errorf("连接种子节点失败: %w", err)
+	}
+	defer sshClient.Close()
+
+	output, err := sshClient.Execute(proxmoxClusterStatusCommand)
+	if err != nil {
+		return "", nil, fmt.Errorf("查询集群状态失败: %w", err)
+	}
+
+	var entries []proxmoxClusterMember
+	if err := json.Unmarshal([]byte(strings.TrimSpace(output)), &entries); err != nil {
+		return "", nil, fmt.Errorf("解析集群状态失败: %w", err)
+	}
+
+	clusterName := ""
+	members := make([]proxmoxClusterMember, 0, len(entries))
+	for _, entry := range entries {
+		switch entry.Type {
+		case "cluster":
+			clusterName = entry.Name
+		case "node":
+			members = append(members, entry)
+		}
+	}
+	if clusterName == "" {
+		return "", nil, fmt.Errorf("该节点未加入Proxmox集群，请直接添加Provider")
+	}
+	return clusterName, members, nil
+}
+
+// importProxmoxClusterNode 添加单个集群成员节点
+func (s *Service) importProxmoxClusterNode(seed admin.CreateProviderRequest, member proxmoxClusterMember) admin.ProxmoxClusterNodeResult {
+	item := seed
+	// 种子节点使用管理员提供的地址，其他节点使用集群中登记的地址（通常是集群内网地址）
+	if member.Local != 1 {
+		item.Endpoint = member.IP
+		item.PortIP = ""
+	}
+	result := admin.ProxmoxClusterNodeResult{
+		Node:     member.Name,
+		Endpoint: item.Endpoint,
+	}
+	if item.Endpoint == "" {
+		result.Status = "failed"
+		result.Message = "集群状态中没有该节点的地址"
+		return result
+	}
+
+	var count int64
+	if err := global.APP_DB.Model(&providerModel.Provider{}).
+		Where("endpoint = ? AND ssh_port = ?", item.Endpoint, item.SSHPort).
+		Count(&count).Error; err != nil {
+		result.Status = "failed"
+		result.Message = fmt.Sprintf("检查Provider SSH地址失败: %v", err)
+		return result
+	}
+	if count > 0 {
+		result.Status = "skipped"
+		result.Message = fmt.Sprintf("SSH地址 '%s:%d' 已被其他Provider使用", item.Endpoint, item.SSHPort)
+		return result
+	}
+
+	if member.Online != 1 {
+		result.Status = "failed"
+		result.Message = "节点在集群中处于离线状态"
+		return result
+	}
+	if _, _, err := utils.ValidateSSHAuth(clusterSSHConfig(item, item.Endpoint)); err != nil {
+		result.Status = "failed"
+		result.Message = fmt.Sprintf("无法使用共享凭据连接节点: %v", err)
+		return result
+	}
+
+	name, _, err := resolveImportName(fmt.Sprintf("%s-%s", seed.Name, member.Name), "suffix")
+	if err != nil {
+		result.Status = "failed"
+		result.Message = err.Error()
+		return result
+	}
+	item.Name = name
+
+	if err := s.CreateProvider(item); err != nil {
+		result.Status = "failed"
+		result.Message = err.Error()
+		return result
+	}
+
+	var created providerModel.Provider
+	if err := global.APP_DB.Where("name = ?", item.Name).First(&created).Error; err != nil {
+		result.Status = "failed"
+		result.Message = fmt.Sprintf("查询已创建的Provider失败: %v", err)
+		return result
+	}
+	result.Status = "created"
+	result.Name = created.Name
+	result.ProviderID = created.ID
+
+	global.APP_LOG.Info("添加Proxmox集群节点",
+		zap.String("node", member.Name),
+		zap.String("endpoint", utils.TruncateString(item.Endpoint, 64)),
+		zap.Uint("providerID", created.ID))
+
+	return result
+}
+
+// clusterSSHConfig 使用请求中的凭据构造连接指定主机的SSH配置
+func clusterSSHConfig(item admin.CreateProviderRequest, host string) utils.SSHConfig {
+	connectTimeout := item.SSHConnectTimeout
+	if connectTimeout <= 0 {
+		connectTimeout = 30
+	}
+	return utils.SSHConfig{
+		Host:                 host,
+		Port:                 item.SSHPort,
+		Username:             item.Username,
+		Password:             item.Password,
+		PrivateKey:           item.SSHKey,
+		PrivateKeyPassphrase: item.SSHKeyPassphrase,
+		UseAgent:             item.SSHUseAgent,
+		ConnectTimeout:       time.Duration(connectTimeout) * time.Second,
+		ExecuteTimeout:       60 * time.Second,
+	}
+}
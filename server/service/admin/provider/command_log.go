@@ -0,0 +1,27 @@
+package provider
+
+import (
+	"oneclickvirt/global"
+	"oneclickvirt/model/common"
+	providerModel "oneclickvirt/model/provider"
+	"oneclickvirt/utils"
+)
+
+// maxCommandLogLimit 单次查询最近命令记录的最大条数
+const maxCommandLogLimit = 200
+
+// GetProviderCommandLog 获取Provider最近通过SSH执行的命令，按时间倒序
+// 记录仅保存在内存中，Provider重新连接后仍保留，服务重启后清空
+func (s *Service) GetProviderCommandLog(providerID uint, limit int) ([]utils.CommandLogEntry, error) {
+	var count int64
+	if err := global.APP_DB.Model(&providerModel.Provider{}).Where("id = ?", providerID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, common.NewError(common.CodeNotFound, "Provider不存在")
+	}
+	if limit <= 0 || limit > maxCommandLogLimit {
+		limit = maxCommandLogLimit
+	}
+	return utils.GetCommandLog(providerID, limit), nil
+}
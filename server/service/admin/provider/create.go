@@ -9,6 +9,7 @@ import (
 	providerModel "oneclickvirt/model/provider"
 	"oneclickvirt/service/database"
 	"oneclickvirt/utils"
+	"strings"
 	"time"
 
 	"go.uber.org/zap"
@@ -55,6 +56,35 @@ func (s *Service) CreateProvider(req admin.CreateProviderRequest) error {
 		}
 	}
 
+	// 3. 校验LXCFS挂载列表
+	lxcfsMounts, err := normalizeLXCFSMounts(req.ContainerLXCFSMounts)
+	if err != nil {
+		return err
+	}
+	req.ContainerLXCFSMounts = lxcfsMounts
+	if err := validateOvercommitRatios(req.CPUOvercommitRatio, req.MemoryOvercommitRatio); err != nil {
+		return err
+	}
+	extraNetworks, err := normalizeExtraNetworks(req.ExtraNetworks)
+	if err != nil {
+		return err
+	}
+	req.ExtraNetworks = extraNetworks
+	volumeAllowlist, err := normalizeVolumeAllowlist(req.VolumeAllowlist)
+	if err != nil {
+		return err
+	}
+	req.VolumeAllowlist = volumeAllowlist
+	placementWeight, err := normalizePlacementWeight(req.PlacementWeight)
+	if err != nil {
+		return err
+	}
+	req.PlacementWeight = placementWeight
+	// 新建节点尚未同步硬件信息，此时只能校验取值范围，容量在更新时校验
+	if err := validateDefaultLimits(req.DefaultCPU, req.DefaultMemory, req.DefaultDisk, providerModel.Provider{}); err != nil {
+		return err
+	}
+
 	// 解析过期时间
 	var expiresAt *time.Time
 	if req.ExpiresAt != "" {
@@ -85,8 +115,8 @@ func (s *Service) CreateProvider(req admin.CreateProviderRequest) error {
 		expiresAt = &defaultExpiry
 	}
 
-	// 验证：必须提供密码或SSH密钥其中一种
-	if req.Password == "" && req.SSHKey == "" {
+	// 验证：必须提供密码或SSH密钥其中一种（或启用ssh-agent）
+	if req.Password == "" && req.SSHKey == "" && !req.SSHUseAgent {
 		global.APP_LOG.Warn("Provider创建失败：未提供SSH认证方式",
 			zap.String("name", utils.TruncateString(req.Name, 32)))
 		return fmt.Errorf("必须提供SSH密码或SSH密钥其中一种认证方式")
@@ -101,6 +131,9 @@ func (s *Service) CreateProvider(req admin.CreateProviderRequest) error {
 		Username:              req.Username,
 		Password:              req.Password,
 		SSHKey:                req.SSHKey,
+		SSHKeyPassphrase:      req.SSHKeyPassphrase,
+		SSHUseAgent:           req.SSHUseAgent,
+		SSHVerifyHostKey:      req.SSHVerifyHostKey,
 		Token:                 req.Token,
 		Config:                req.Config,
 		Region:                req.Region,
@@ -153,14 +186,29 @@ func (s *Service) CreateProvider(req admin.CreateProviderRequest) error {
 		VMLimitCPU:    req.VMLimitCpu,
 		VMLimitMemory: req.VMLimitMemory,
 		VMLimitDisk:   req.VMLimitDisk,
+		// 资源超分配比例
+		CPUOvercommitRatio:    req.CPUOvercommitRatio,
+		MemoryOvercommitRatio: req.MemoryOvercommitRatio,
 		// 容器特殊配置选项（仅 LXD/Incus 容器）
-		ContainerPrivileged:   req.ContainerPrivileged,
-		ContainerAllowNesting: req.ContainerAllowNesting,
-		ContainerEnableLXCFS:  req.ContainerEnableLXCFS,
-		ContainerCPUAllowance: req.ContainerCPUAllowance,
-		ContainerMemorySwap:   req.ContainerMemorySwap,
-		ContainerMaxProcesses: req.ContainerMaxProcesses,
-		ContainerDiskIOLimit:  req.ContainerDiskIOLimit,
+		ContainerPrivileged:       req.ContainerPrivileged,
+		ContainerAllowNesting:     req.ContainerAllowNesting,
+		ContainerEnableLXCFS:      req.ContainerEnableLXCFS,
+		ContainerLXCFSMounts:      req.ContainerLXCFSMounts,
+		ContainerCPUAllowance:     req.ContainerCPUAllowance,
+		ContainerCPUAllowanceMode: req.ContainerCPUAllowanceMode,
+		ContainerMemorySwap:       req.ContainerMemorySwap,
+		ContainerMaxProcesses:     req.ContainerMaxProcesses,
+		ContainerDiskIOLimit:      req.ContainerDiskIOLimit,
+		ExtraNetworks:             req.ExtraNetworks,
+		VolumeAllowlist:           req.VolumeAllowlist,
+		RegistryServer:            strings.TrimSpace(req.RegistryServer),
+		RegistryUsername:          strings.TrimSpace(req.RegistryUsername),
+		RegistryPassword:          req.RegistryPassword,
+		AllowSystemPrune:          req.AllowSystemPrune,
+		PlacementWeight:           req.PlacementWeight,
+		DefaultCPU:                req.DefaultCPU,
+		DefaultMemory:             req.DefaultMemory,
+		DefaultDisk:               req.DefaultDisk,
 	}
 
 	// 节点级别等级限制配置
@@ -228,6 +276,12 @@ func (s *Service) CreateProvider(req admin.CreateProviderRequest) error {
 	if provider.MaxConcurrentTasks <= 0 {
 		provider.MaxConcurrentTasks = 1
 	}
+	// 实例创建并发限制：0表示不单独限制，仅受任务并发数约束
+	provider.MaxConcurrentCreations = req.MaxConcurrentCreations
+	if provider.MaxConcurrentCreations < 0 {
+		provider.MaxConcurrentCreations = 0
+	}
+	provider.GPUPassthroughEnabled = req.GPUPassthroughEnabled
 	if provider.TaskPollInterval <= 0 {
 		provider.TaskPollInterval = 60
 	}
@@ -275,13 +329,20 @@ func (s *Service) CreateProvider(req admin.CreateProviderRequest) error {
 	if provider.TrafficMultiplier == 0 {
 		provider.TrafficMultiplier = 1.0 // 默认1.0倍
 	}
+	// 资源超分配比例默认值：不超分配
+	if provider.CPUOvercommitRatio == 0 {
+		provider.CPUOvercommitRatio = 1.0
+	}
+	if provider.MemoryOvercommitRatio == 0 {
+		provider.MemoryOvercommitRatio = 1.0
+	}
 	// 流量采集间隔验证：最大不超过5分钟（300秒），因为数据聚合精度为5分钟
 	if req.TrafficCollectInterval > 300 {
 		return fmt.Errorf("流量采集间隔不能超过300秒（5分钟），当前值: %d秒", req.TrafficCollectInterval)
 	}
 	// 端口映射方式默认值
-	// Docker 类型固定使用 native
-	if provider.Type == "docker" {
+	// Docker/Podman 类型固定使用 native
+	if provider.Type == "docker" || provider.Type == "podman" {
 		provider.IPv4PortMappingMethod = "native"
 		provider.IPv6PortMappingMethod = "native"
 	} else {
@@ -303,6 +364,9 @@ func (s *Service) CreateProvider(req admin.CreateProviderRequest) error {
 	if provider.ContainerCPUAllowance == "" {
 		provider.ContainerCPUAllowance = "100%" // 默认100% CPU使用率
 	}
+	if provider.ContainerCPUAllowanceMode == "" {
+		provider.ContainerCPUAllowanceMode = "percent" // 默认按核心数生成百分比限额
+	}
 	provider.NextAvailablePort = provider.PortRangeStart
 
 	// 初始化流量重置时间为下个月的1号
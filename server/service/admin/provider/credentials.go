@@ -0,0 +1,128 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"oneclickvirt/global"
+	"oneclickvirt/model/admin"
+	"oneclickvirt/model/common"
+	providerModel "oneclickvirt/model/provider"
+	provider2 "oneclickvirt/service/provider"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// RotateCredentials 轮换Provider的SSH密码或私钥
+// 新提供的每种凭据都会单独完成一次SSH认证，全部通过后才保存，避免输错导致节点无法连接；
+// 保存后重新加载Provider，断开仍在使用旧凭据的连接和连接池
+func (s *Service) RotateCredentials(providerID uint, req admin.RotateProviderCredentialsRequest) (*admin.RotateProviderCredentialsResponse, error) {
+	if req.Password == nil && req.SSHKey == nil && req.SSHKeyPassphrase == nil {
+		return nil, common.NewError(common.CodeValidationError, "请提供新的密码或SSH私钥")
+	}
+
+	var provider providerModel.Provider
+	if err := global.APP_DB.First(&provider, providerID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, common.NewError(common.CodeNotFound, "Provider不存在")
+		}
+		return nil, fmt.Errorf("查询Provider失败: %v", err)
+	}
+
+	newPassword := provider.Password
+	if req.Password != nil {
+		newPassword = *req.Password
+	}
+	newSSHKey := provider.SSHKey
+	if req.SSHKey != nil {
+		newSSHKey = *req.SSHKey
+	}
+	newPassphrase := provider.SSHKeyPassphrase
+	if req.SSHKeyPassphrase != nil {
+		newPassphrase = *req.SSHKeyPassphrase
+	}
+	if newPassword == "" && newSSHKey == "" && !provider.SSHUseAgent {
+		return nil, common.NewError(common.CodeValidationError, "必须保留至少一种SSH认证方式（密码或密钥）")
+	}
+
+	sshPort := provider.SSHPort
+	if sshPort == 0 {
+		sshPort = 22
+	}
+	connectTimeout := provider.SSHConnectTimeout
+	if connectTimeout <= 0 {
+		connectTimeout = 30
+	}
+	baseConfig := utils.SSHConfig{
+		Host:               utils.ExtractHost(provider.Endpoint),
+		Port:               sshPort,
+		Username:           provider.Username,
+		VerifyHostKey:      provider.SSHVerifyHostKey,
+		HostKeyFingerprint: provider.SSHHostKeyFingerprint,
+		ConnectTimeout:     time.Duration(connectTimeout) * time.Second,
+	}
+
+	// 私钥和密码分别校验，不启用ssh-agent，确保通过认证的是本次提交的凭据
+	var validated []string
+	if newSSHKey != "" && (req.SSHKey != nil || req.SSHKeyPassphrase != nil) {
+		keyConfig := baseConfig
+		keyConfig.PrivateKey = newSSHKey
+		keyConfig.PrivateKeyPassphrase = newPassphrase
+		if _, _, err := utils.ValidateSSHAuth(keyConfig); err != nil {
+			global.APP_LOG.Warn("Provider新SSH私钥校验失败，未保存",
+				zap.Uint("providerID", providerID),
+				zap.String("name", provider.Name),
+				zap.Error(err))
+			return nil, common.NewError(common.CodeValidationError, "新SSH私钥校验失败，凭据未修改: "+err.Error())
+		}
+		validated = append(validated, "privateKey")
+	}
+	if newPassword != "" && req.Password != nil {
+		passwordConfig := baseConfig
+		passwordConfig.Password = newPassword
+		if _, _, err := utils.ValidateSSHAuth(passwordConfig); err != nil {
+			global.APP_LOG.Warn("Provider新SSH密码校验失败，未保存",
+				zap.Uint("providerID", providerID),
+				zap.String("name", provider.Name),
+				zap.Error(err))
+			return nil, common.NewError(common.CodeValidationError, "新SSH密码校验失败，凭据未修改: "+err.Error())
+		}
+		validated = append(validated, "password")
+	}
+	if validated == nil {
+		validated = []string{}
+	}
+
+	if err := global.APP_DB.Model(&provider).Updates(map[string]interface{}{
+		"password":           newPassword,
+		"ssh_key":            newSSHKey,
+		"ssh_key_passphrase": newPassphrase,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("保存Provider凭据失败: %v", err)
+	}
+
+	global.APP_LOG.Info("Provider SSH凭据已轮换",
+		zap.Uint("providerID", providerID),
+		zap.String("name", provider.Name),
+		zap.Strings("validatedMethods", validated),
+		zap.Bool("passwordChanged", req.Password != nil),
+		zap.Bool("sshKeyChanged", req.SSHKey != nil))
+
+	// 重新加载会断开已加载的连接，并清理连接池中使用旧凭据的SSH连接
+	if pool, ok := global.APP_SSH_POOL.(interface{ RemoveProvider(uint) }); ok {
+		pool.RemoveProvider(providerID)
+	}
+	result := &admin.RotateProviderCredentialsResponse{ValidatedMethods: validated}
+	if err := provider2.GetProviderService().ReloadProvider(providerID); err != nil {
+		global.APP_LOG.Warn("轮换凭据后重新加载Provider失败",
+			zap.Uint("providerID", providerID),
+			zap.Error(err))
+	} else {
+		result.ProviderReloaded = true
+	}
+
+	return result, nil
+}
@@ -0,0 +1,28 @@
+package provider
+
+import (
+	"fmt"
+
+	providerModel "oneclickvirt/model/provider"
+	"oneclickvirt/provider"
+)
+
+// maxPlacementWeight 自动选择节点权重上限
+const maxPlacementWeight = 100
+
+// validateDefaultLimits 校验实例默认资源限制，节点容量为0时只校验取值范围
+func validateDefaultLimits(cpu int, memoryMB, diskMB int64, node providerModel.Provider) error {
+	return provider.ValidateDefaultLimits(provider.DefaultLimits{CPU: cpu, MemoryMB: memoryMB, DiskMB: diskMB},
+		node.NodeCPUCores, node.NodeMemoryTotal, node.NodeDiskTotal)
+}
+
+// normalizePlacementWeight 校验自动选择节点的权重，0表示使用默认值1
+func normalizePlacementWeight(weight int) (int, error) {
+	if weight == 0 {
+		return 1, nil
+	}
+	if weight < 1 || weight > maxPlacementWeight {
+		return 0, fmt.Errorf("节点选择权重必须在1到%d之间，当前值: %d", maxPlacementWeight, weight)
+	}
+	return weight, nil
+}
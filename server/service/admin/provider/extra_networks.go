@@ -0,0 +1,13 @@
+package provider
+
+import "oneclickvirt/provider"
+
+// normalizeExtraNetworks 校验并规范化允许附加额外网卡的网络列表，为空表示不允许附加
+func normalizeExtraNetworks(value string) (string, error) {
+	return provider.NormalizeAllowedNetworks(value)
+}
+
+// normalizeVolumeAllowlist 校验并规范化允许用户挂载的宿主机目录列表，为空表示不允许挂载
+func normalizeVolumeAllowlist(value string) (string, error) {
+	return provider.NormalizeVolumeAllowlist(value)
+}
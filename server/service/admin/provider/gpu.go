@@ -0,0 +1,60 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"oneclickvirt/global"
+	"oneclickvirt/model/admin"
+	"oneclickvirt/model/common"
+	providerModel "oneclickvirt/model/provider"
+	"oneclickvirt/provider"
+	providerService "oneclickvirt/service/provider"
+)
+
+// gpuLister 支持GPU直通的Provider实现的可选接口（Proxmox/Incus）
+type gpuLister interface {
+	ListGPUs(ctx context.Context) ([]provider.GPUDevice, error)
+}
+
+// ListProviderGPUs 列出Provider宿主机上可直通的GPU及其占用情况
+func (s *Service) ListProviderGPUs(providerID uint) (*admin.ProviderGPUsResponse, error) {
+	var dbProvider providerModel.Provider
+	if err := global.APP_DB.First(&dbProvider, providerID).Error; err != nil {
+		return nil, common.NewError(common.CodeNotFound, "Provider不存在")
+	}
+
+	prov, err := providerService.GetProviderInstanceByID(providerID)
+	if err != nil {
+		return nil, fmt.Errorf("获取Provider失败: %v", err)
+	}
+	lister, ok := prov.(gpuLister)
+	if !ok {
+		return nil, common.NewError(common.CodeValidationError, fmt.Sprintf("Provider类型 %s 不支持GPU直通", prov.GetType()))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	gpus, err := lister.ListGPUs(ctx)
+	if err != nil {
+		return nil, common.NewError(common.CodeExternalAPIError, err.Error())
+	}
+	if gpus == nil {
+		gpus = []provider.GPUDevice{}
+	}
+
+	resp := &admin.ProviderGPUsResponse{
+		ProviderID:            dbProvider.ID,
+		ProviderType:          dbProvider.Type,
+		GPUPassthroughEnabled: dbProvider.GPUPassthroughEnabled,
+		GPUs:                  gpus,
+	}
+	for _, gpu := range gpus {
+		if gpu.IOMMUGroup != "" {
+			resp.IOMMUEnabled = true
+			break
+		}
+	}
+	return resp, nil
+}
@@ -0,0 +1,65 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"oneclickvirt/global"
+	"oneclickvirt/model/admin"
+	"oneclickvirt/model/common"
+	providerModel "oneclickvirt/model/provider"
+	provider2 "oneclickvirt/service/provider"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// RepinHostKey 读取节点当前的SSH主机密钥指纹并替换已固定的指纹
+// 用于节点重装等合法的密钥变更，替换后重新加载Provider使新指纹立即生效
+func (s *Service) RepinHostKey(providerID uint) (*admin.RepinHostKeyResponse, error) {
+	var provider providerModel.Provider
+	if err := global.APP_DB.First(&provider, providerID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, common.NewError(common.CodeNotFound, "Provider不存在")
+		}
+		return nil, fmt.Errorf("查询Provider失败: %v", err)
+	}
+
+	sshPort := provider.SSHPort
+	if sshPort == 0 {
+		sshPort = 22
+	}
+	connectTimeout := provider.SSHConnectTimeout
+	if connectTimeout <= 0 {
+		connectTimeout = 30
+	}
+	fingerprint, err := utils.FetchHostKeyFingerprint(utils.ExtractHost(provider.Endpoint), sshPort, time.Duration(connectTimeout)*time.Second)
+	if err != nil {
+		return nil, common.NewError(common.CodeExternalAPIError, err.Error())
+	}
+
+	if err := global.APP_DB.Model(&provider).Update("ssh_host_key_fingerprint", fingerprint).Error; err != nil {
+		return nil, fmt.Errorf("保存主机密钥指纹失败: %v", err)
+	}
+
+	global.APP_LOG.Info("Provider SSH主机密钥已重新固定",
+		zap.Uint("providerID", provider.ID),
+		zap.String("name", provider.Name),
+		zap.String("previous", provider.SSHHostKeyFingerprint),
+		zap.String("fingerprint", fingerprint))
+
+	// 已加载的连接仍使用旧指纹，重新加载后才会按新指纹校验
+	if err := provider2.GetProviderService().ReloadProvider(provider.ID); err != nil {
+		global.APP_LOG.Warn("重新固定主机密钥后重新加载Provider失败",
+			zap.Uint("providerID", provider.ID),
+			zap.Error(err))
+	}
+
+	return &admin.RepinHostKeyResponse{
+		Fingerprint:         fingerprint,
+		PreviousFingerprint: provider.SSHHostKeyFingerprint,
+		VerifyEnabled:       provider.SSHVerifyHostKey,
+	}, nil
+}
@@ -0,0 +1,19 @@
+package provider
+
+import (
+	"strings"
+
+	"oneclickvirt/provider"
+)
+
+// normalizeLXCFSMounts 校验并规范化LXCFS挂载列表，为空时保持为空以便节点使用默认列表
+func normalizeLXCFSMounts(value string) (string, error) {
+	if strings.TrimSpace(value) == "" {
+		return "", nil
+	}
+	mounts, err := provider.ParseLXCFSMounts(value)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(mounts, ","), nil
+}
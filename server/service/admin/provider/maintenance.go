@@ -0,0 +1,45 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"oneclickvirt/global"
+	"oneclickvirt/model/admin"
+	"oneclickvirt/model/common"
+	providerModel "oneclickvirt/model/provider"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// SetMaintenanceMode 切换Provider维护模式
+// 维护中的Provider不出现在可用节点列表中并拒绝新实例，已有实例的操作不受影响
+func (s *Service) SetMaintenanceMode(providerID uint, req admin.SetProviderMaintenanceRequest) error {
+	var provider providerModel.Provider
+	if err := global.APP_DB.First(&provider, providerID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return common.NewError(common.CodeNotFound, "Provider不存在")
+		}
+		return fmt.Errorf("查询Provider失败: %v", err)
+	}
+
+	reason := strings.TrimSpace(req.Reason)
+	if !req.Enabled {
+		reason = ""
+	}
+	if err := global.APP_DB.Model(&provider).Updates(map[string]interface{}{
+		"maintenance_mode":   req.Enabled,
+		"maintenance_reason": reason,
+	}).Error; err != nil {
+		return fmt.Errorf("更新维护模式失败: %v", err)
+	}
+
+	global.APP_LOG.Info("Provider维护模式已切换",
+		zap.Uint("providerID", provider.ID),
+		zap.String("providerName", provider.Name),
+		zap.Bool("enabled", req.Enabled),
+		zap.String("reason", reason))
+	return nil
+}
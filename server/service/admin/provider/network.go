@@ -0,0 +1,103 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"oneclickvirt/global"
+	"oneclickvirt/model/admin"
+	"oneclickvirt/model/common"
+	providerModel "oneclickvirt/model/provider"
+	"oneclickvirt/provider"
+	providerService "oneclickvirt/service/provider"
+
+	"go.uber.org/zap"
+)
+
+// networkManager 支持网络管理的Provider实现的可选接口（Docker/Podman）
+type networkManager interface {
+	ListNetworks(ctx context.Context) ([]provider.Network, error)
+	CreateNetwork(ctx context.Context, cfg provider.NetworkCreateConfig) error
+}
+
+// ListProviderNetworks 列出Provider节点上的网络
+func (s *Service) ListProviderNetworks(providerID uint) (*admin.ProviderNetworksResponse, error) {
+	dbProvider, manager, err := s.getNetworkManager(providerID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	networks, err := manager.ListNetworks(ctx)
+	if err != nil {
+		return nil, common.NewError(common.CodeExternalAPIError, err.Error())
+	}
+
+	return &admin.ProviderNetworksResponse{
+		ProviderID:      dbProvider.ID,
+		ProviderType:    dbProvider.Type,
+		AllowedNetworks: provider.ParseAllowedNetworks(dbProvider.ExtraNetworks),
+		Networks:        networks,
+	}, nil
+}
+
+// CreateProviderNetwork 在Provider节点上创建网络，可选择同时加入允许用户使用的网络列表
+func (s *Service) CreateProviderNetwork(providerID uint, req admin.CreateProviderNetworkRequest) error {
+	dbProvider, manager, err := s.getNetworkManager(providerID)
+	if err != nil {
+		return err
+	}
+
+	cfg := provider.NetworkCreateConfig{
+		Name:     req.Name,
+		Subnet:   req.Subnet,
+		Gateway:  req.Gateway,
+		Internal: req.Internal,
+	}
+	if err := provider.ValidateNetworkCreateConfig(cfg); err != nil {
+		return common.NewError(common.CodeValidationError, err.Error())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	if err := manager.CreateNetwork(ctx, cfg); err != nil {
+		return common.NewError(common.CodeExternalAPIError, err.Error())
+	}
+
+	if req.AllowUsers && provider.CheckPrimaryNetworkAllowed(req.Name, dbProvider.ExtraNetworks) != nil {
+		allowed, err := provider.NormalizeAllowedNetworks(dbProvider.ExtraNetworks + "," + req.Name)
+		if err != nil {
+			return err
+		}
+		if err := global.APP_DB.Model(dbProvider).Update("extra_networks", allowed).Error; err != nil {
+			return fmt.Errorf("网络已创建，但更新允许使用的网络列表失败: %v", err)
+		}
+	}
+
+	global.APP_LOG.Info("管理员创建Provider网络",
+		zap.Uint("providerId", providerID),
+		zap.String("network", req.Name),
+		zap.Bool("allowUsers", req.AllowUsers))
+	return nil
+}
+
+func (s *Service) getNetworkManager(providerID uint) (*providerModel.Provider, networkManager, error) {
+	var dbProvider providerModel.Provider
+	if err := global.APP_DB.First(&dbProvider, providerID).Error; err != nil {
+		return nil, nil, common.NewError(common.CodeNotFound, "Provider不存在")
+	}
+
+	prov, err := providerService.GetProviderInstanceByID(providerID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("获取Provider失败: %v", err)
+	}
+
+	manager, ok := prov.(networkManager)
+	if !ok {
+		return nil, nil, common.NewError(common.CodeValidationError, fmt.Sprintf("Provider类型 %s 不支持网络管理", prov.GetType()))
+	}
+	return &dbProvider, manager, nil
+}
@@ -0,0 +1,27 @@
+package provider
+
+import (
+	"fmt"
+)
+
+// maxOvercommitRatio 超分配比例上限，防止误填导致节点严重超售
+const maxOvercommitRatio = 32.0
+
+// validateOvercommitRatios 校验CPU和内存超分配比例，0表示未设置
+func validateOvercommitRatios(cpuRatio, memoryRatio float64) error {
+	for _, item := range []struct {
+		name  string
+		ratio float64
+	}{
+		{"CPU", cpuRatio},
+		{"内存", memoryRatio},
+	} {
+		if item.ratio == 0 {
+			continue
+		}
+		if item.ratio < 1 || item.ratio > maxOvercommitRatio {
+			return fmt.Errorf("%s超分配比例必须在1.0到%.0f之间，当前值: %g", item.name, maxOvercommitRatio, item.ratio)
+		}
+	}
+	return nil
+}
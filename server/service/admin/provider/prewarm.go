@@ -0,0 +1,211 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"oneclickvirt/global"
+	"oneclickvirt/model/admin"
+	providerModel "oneclickvirt/model/provider"
+	systemModel "oneclickvirt/model/system"
+	"oneclickvirt/provider"
+	providerService "oneclickvirt/service/provider"
+
+	"go.uber.org/zap"
+)
+
+// prewarmTimeout 单次预热任务的最长执行时间，镜像较大时下载耗时较长
+const prewarmTimeout = 2 * time.Hour
+
+// prewarmJobs 记录每个Provider最近一次镜像预热的状态，仅保存在内存中
+var prewarmJobs sync.Map // map[uint]*prewarmJob
+
+type prewarmJob struct {
+	mu     sync.Mutex
+	status admin.ProviderPrewarmStatusResponse
+}
+
+func (j *prewarmJob) snapshot() *admin.ProviderPrewarmStatusResponse {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	status := j.status
+	status.Images = append([]providerModel.ProviderPrewarmResult(nil), j.status.Images...)
+	return &status
+}
+
+// PrewarmProviderImages 在Provider节点上预先下载并导入镜像
+// 预热在后台执行，通过GetProviderPrewarmStatus查询每个镜像的结果
+func (s *Service) PrewarmProviderImages(providerID uint, req admin.PrewarmProviderImagesRequest) (*admin.ProviderPrewarmStatusResponse, error) {
+	var dbProvider providerModel.Provider
+	if err := global.APP_DB.First(&dbProvider, providerID).Error; err != nil {
+		return nil, fmt.Errorf("Provider不存在")
+	}
+
+	if req.InstanceType != "" && req.InstanceType != "container" && req.InstanceType != "vm" {
+		return nil, fmt.Errorf("无效的实例类型: %s", req.InstanceType)
+	}
+
+	prov, err := providerService.GetProviderInstanceByID(providerID)
+	if err != nil {
+		return nil, fmt.Errorf("获取Provider失败: %v", err)
+	}
+
+	prewarmer, ok := prov.(interface {
+		PrewarmImages(ctx context.Context, images []provider.PrewarmImage) ([]provider.PrewarmResult, error)
+	})
+	if !ok {
+		return nil, fmt.Errorf("Provider类型 %s 不支持镜像预热", prov.GetType())
+	}
+
+	images, missing, err := s.resolvePrewarmImages(dbProvider, req)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	job := &prewarmJob{status: admin.ProviderPrewarmStatusResponse{
+		ProviderID: providerID,
+		Running:    true,
+		StartedAt:  &now,
+	}}
+	for _, image := range images {
+		job.status.Images = append(job.status.Images, provider.PrewarmResult{
+			Name:         image.Name,
+			InstanceType: image.InstanceType,
+			Status:       "running",
+		})
+	}
+	job.status.Images = append(job.status.Images, missing...)
+
+	if existing, loaded := prewarmJobs.LoadOrStore(providerID, job); loaded {
+		existingJob := existing.(*prewarmJob)
+		if existingJob.snapshot().Running {
+			return nil, errors.New("该Provider已有正在进行的镜像预热任务")
+		}
+		if !prewarmJobs.CompareAndSwap(providerID, existing, job) {
+			return nil, errors.New("该Provider已有正在进行的镜像预热任务")
+		}
+	}
+
+	if len(images) == 0 {
+		job.mu.Lock()
+		job.status.Running = false
+		job.status.FinishedAt = &now
+		job.mu.Unlock()
+		return job.snapshot(), nil
+	}
+
+	go s.runPrewarm(job, prewarmer.PrewarmImages, images, missing)
+
+	return job.snapshot(), nil
+}
+
+// GetProviderPrewarmStatus 获取Provider最近一次镜像预热的状态
+func (s *Service) GetProviderPrewarmStatus(providerID uint) (*admin.ProviderPrewarmStatusResponse, error) {
+	if job, ok := prewarmJobs.Load(providerID); ok {
+		return job.(*prewarmJob).snapshot(), nil
+	}
+	return &admin.ProviderPrewarmStatusResponse{
+		ProviderID: providerID,
+		Images:     []providerModel.ProviderPrewarmResult{},
+	}, nil
+}
+
+// resolvePrewarmImages 按名称查找与Provider类型和架构匹配的已启用系统镜像
+// 未找到的镜像名称直接标记为失败
+func (s *Service) resolvePrewarmImages(dbProvider providerModel.Provider, req admin.PrewarmProviderImagesRequest) ([]provider.PrewarmImage, []provider.PrewarmResult, error) {
+	architecture := dbProvider.Architecture
+	if architecture == "" {
+		architecture = "amd64"
+	}
+
+	query := global.APP_DB.Where("provider_type = ? AND architecture = ? AND status = ? AND name IN ?",
+		dbProvider.Type, architecture, "active", req.Images)
+	if req.InstanceType != "" {
+		query = query.Where("instance_type = ?", req.InstanceType)
+	}
+
+	var systemImages []systemModel.SystemImage
+	if err := query.Find(&systemImages).Error; err != nil {
+		return nil, nil, fmt.Errorf("查询系统镜像失败: %v", err)
+	}
+
+	found := make(map[string]bool, len(systemImages))
+	var images []provider.PrewarmImage
+	for _, img := range systemImages {
+		if img.InstanceType == "vm" && !dbProvider.VirtualMachineEnabled {
+			continue
+		}
+		if img.InstanceType == "container" && !dbProvider.ContainerEnabled {
+			continue
+		}
+		found[img.Name] = true
+		images = append(images, provider.PrewarmImage{
+			Name:         img.Name,
+			InstanceType: img.InstanceType,
+			URL:          img.URL,
+			UseCDN:       img.UseCDN,
+			SHA256:       provider.NormalizeSHA256(img.Checksum),
+		})
+	}
+
+	var missing []provider.PrewarmResult
+	for _, name := range req.Images {
+		if found[name] {
+			continue
+		}
+		found[name] = true
+		missing = append(missing, provider.PrewarmResult{
+			Name:         name,
+			InstanceType: req.InstanceType,
+			Status:       "failed",
+			Error:        "未找到与Provider类型和架构匹配的可用镜像",
+		})
+	}
+
+	return images, missing, nil
+}
+
+// runPrewarm 后台执行镜像预热并记录结果
+func (s *Service) runPrewarm(job *prewarmJob,
+	prewarm func(ctx context.Context, images []provider.PrewarmImage) ([]provider.PrewarmResult, error),
+	images []provider.PrewarmImage, missing []provider.PrewarmResult) {
+	ctx, cancel := context.WithTimeout(context.Background(), prewarmTimeout)
+	defer cancel()
+
+	results, err := prewarm(ctx, images)
+	if err != nil {
+		results = make([]provider.PrewarmResult, 0, len(images))
+		for _, image := range images {
+			results = append(results, provider.PrewarmResult{
+				Name:         image.Name,
+				InstanceType: image.InstanceType,
+				Status:       "failed",
+				Error:        err.Error(),
+			})
+		}
+	}
+
+	failed := 0
+	for _, r := range results {
+		if r.Status == "failed" {
+			failed++
+		}
+	}
+
+	finished := time.Now()
+	job.mu.Lock()
+	job.status.Images = append(results, missing...)
+	job.status.Running = false
+	job.status.FinishedAt = &finished
+	job.mu.Unlock()
+
+	global.APP_LOG.Info("Provider镜像预热完成",
+		zap.Uint("providerId", job.status.ProviderID),
+		zap.Int("images", len(results)),
+		zap.Int("failed", failed),
+		zap.Duration("duration", finished.Sub(*job.status.StartedAt)))
+}
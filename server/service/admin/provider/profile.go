@@ -0,0 +1,94 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"oneclickvirt/global"
+	"oneclickvirt/model/admin"
+	"oneclickvirt/model/common"
+	providerModel "oneclickvirt/model/provider"
+	"oneclickvirt/provider"
+	providerService "oneclickvirt/service/provider"
+
+	"go.uber.org/zap"
+)
+
+// profileManager 支持profile管理的Provider实现的可选接口（LXD/Incus）
+type profileManager interface {
+	ListProfiles(ctx context.Context) ([]provider.Profile, error)
+	CreateProfile(ctx context.Context, cfg provider.ProfileCreateConfig) error
+}
+
+// ListProviderProfiles 列出Provider节点上的profile
+func (s *Service) ListProviderProfiles(providerID uint) (*admin.ProviderProfilesResponse, error) {
+	dbProvider, manager, err := s.getProfileManager(providerID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	profiles, err := manager.ListProfiles(ctx)
+	if err != nil {
+		return nil, common.NewError(common.CodeExternalAPIError, err.Error())
+	}
+
+	return &admin.ProviderProfilesResponse{
+		ProviderID:   dbProvider.ID,
+		ProviderType: dbProvider.Type,
+		Profiles:     profiles,
+	}, nil
+}
+
+// CreateProviderProfile 在Provider节点上创建profile，用于预先定义 small/medium/large 等实例规格
+func (s *Service) CreateProviderProfile(providerID uint, req admin.CreateProviderProfileRequest) error {
+	_, manager, err := s.getProfileManager(providerID)
+	if err != nil {
+		return err
+	}
+
+	cfg := provider.ProfileCreateConfig{
+		Name:        req.Name,
+		Description: req.Description,
+		CPU:         req.CPU,
+		MemoryMB:    req.MemoryMB,
+		DiskGB:      req.DiskGB,
+		StoragePool: req.StoragePool,
+		Config:      req.Config,
+	}
+	if _, err := provider.ProfileConfigItems(cfg); err != nil {
+		return common.NewError(common.CodeValidationError, err.Error())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	if err := manager.CreateProfile(ctx, cfg); err != nil {
+		return common.NewError(common.CodeExternalAPIError, err.Error())
+	}
+
+	global.APP_LOG.Info("管理员创建Provider profile",
+		zap.Uint("providerId", providerID),
+		zap.String("profile", req.Name))
+	return nil
+}
+
+func (s *Service) getProfileManager(providerID uint) (*providerModel.Provider, profileManager, error) {
+	var dbProvider providerModel.Provider
+	if err := global.APP_DB.First(&dbProvider, providerID).Error; err != nil {
+		return nil, nil, common.NewError(common.CodeNotFound, "Provider不存在")
+	}
+
+	prov, err := providerService.GetProviderInstanceByID(providerID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("获取Provider失败: %v", err)
+	}
+
+	manager, ok := prov.(profileManager)
+	if !ok {
+		return nil, nil, common.NewError(common.CodeValidationError, fmt.Sprintf("Provider类型 %s 不支持profile管理", prov.GetType()))
+	}
+	return &dbProvider, manager, nil
+}
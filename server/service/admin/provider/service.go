@@ -51,6 +51,9 @@ func (s *Service) GetProviderList(req admin.ProviderListRequest) ([]admin.Provid
 	if req.Status != "" {
 		query = query.Where("status = ?", req.Status)
 	}
+	if req.Maintenance != nil {
+		query = query.Where("maintenance_mode = ?", *req.Maintenance)
+	}
 
 	if err := query.Count(&total).Error; err != nil {
 		global.APP_LOG.Error("查询Provider总数失败", zap.Error(err))
@@ -204,8 +207,8 @@ func (s *Service) GetProviderList(req admin.ProviderListRequest) ([]admin.Provid
 		runningTasksCount := taskCountMap[provider.ID]
 		usedTraffic := trafficUsageMap[provider.ID]
 
-		// Docker 类型固定使用 native 端口映射方式
-		if provider.Type == "docker" {
+		// Docker/Podman 类型固定使用 native 端口映射方式
+		if provider.Type == "docker" || provider.Type == "podman" {
 			provider.IPv4PortMappingMethod = "native"
 			provider.IPv6PortMappingMethod = "native"
 		}
@@ -331,6 +334,14 @@ func (s *Service) UpdateProvider(req admin.UpdateProviderRequest) error {
 		provider.ExpiresAt = &defaultExpiry
 	}
 
+	// SSH地址变化后原指纹不再适用，清除后在下次连接时重新固定
+	if provider.Endpoint != req.Endpoint || provider.SSHPort != req.SSHPort {
+		provider.SSHHostKeyFingerprint = ""
+	}
+	if req.SSHVerifyHostKey != nil {
+		provider.SSHVerifyHostKey = *req.SSHVerifyHostKey
+	}
+
 	provider.Name = req.Name
 	provider.Type = req.Type
 	provider.Endpoint = req.Endpoint
@@ -367,9 +378,17 @@ func (s *Service) UpdateProvider(req admin.UpdateProviderRequest) error {
 			zap.Bool("isEmpty", *req.SSHKey == ""))
 	}
 
+	// 是否修改了ssh-agent认证开关
+	newUseAgent := provider.SSHUseAgent
+	useAgentChanged := false
+	if req.SSHUseAgent != nil {
+		newUseAgent = *req.SSHUseAgent
+		useAgentChanged = true
+	}
+
 	// 验证：更新后必须至少保留一种认证方式
 	// 只有在实际修改了认证字段时才进行验证
-	if (passwordChanged || sshKeyChanged) && newPassword == "" && newSSHKey == "" {
+	if (passwordChanged || sshKeyChanged || useAgentChanged) && newPassword == "" && newSSHKey == "" && !newUseAgent {
 		global.APP_LOG.Warn("Provider更新失败：尝试清空所有认证方式",
 			zap.Uint("providerID", req.ID))
 		return fmt.Errorf("必须保留至少一种SSH认证方式（密码或密钥）")
@@ -382,6 +401,12 @@ func (s *Service) UpdateProvider(req admin.UpdateProviderRequest) error {
 	if sshKeyChanged {
 		provider.SSHKey = newSSHKey
 	}
+	if req.SSHKeyPassphrase != nil {
+		provider.SSHKeyPassphrase = *req.SSHKeyPassphrase
+	}
+	if useAgentChanged {
+		provider.SSHUseAgent = newUseAgent
+	}
 	provider.Token = req.Token
 	provider.Config = req.Config
 	provider.Region = req.Region
@@ -398,6 +423,8 @@ func (s *Service) UpdateProvider(req admin.UpdateProviderRequest) error {
 	provider.MaxVMInstances = req.MaxVMInstances
 	provider.AllowConcurrentTasks = req.AllowConcurrentTasks
 	provider.MaxConcurrentTasks = req.MaxConcurrentTasks
+	provider.MaxConcurrentCreations = req.MaxConcurrentCreations
+	provider.GPUPassthroughEnabled = req.GPUPassthroughEnabled
 	provider.TaskPollInterval = req.TaskPollInterval
 	provider.EnableTaskPolling = req.EnableTaskPolling
 	// 存储配置（ProxmoxVE专用）
@@ -494,8 +521,8 @@ func (s *Service) UpdateProvider(req admin.UpdateProviderRequest) error {
 			zap.Float64("newValue", req.TrafficMultiplier))
 	}
 	// 端口映射方式更新
-	// Docker 类型固定使用 native，忽略前端传入的值
-	if provider.Type == "docker" {
+	// Docker/Podman 类型固定使用 native，忽略前端传入的值
+	if provider.Type == "docker" || provider.Type == "podman" {
 		provider.IPv4PortMappingMethod = "native"
 		provider.IPv6PortMappingMethod = "native"
 	} else {
@@ -521,13 +548,60 @@ func (s *Service) UpdateProvider(req admin.UpdateProviderRequest) error {
 	provider.VMLimitCPU = req.VMLimitCpu
 	provider.VMLimitMemory = req.VMLimitMemory
 	provider.VMLimitDisk = req.VMLimitDisk
+	// 资源超分配比例更新，0表示保持原值
+	if err := validateOvercommitRatios(req.CPUOvercommitRatio, req.MemoryOvercommitRatio); err != nil {
+		return err
+	}
+	if req.CPUOvercommitRatio > 0 {
+		provider.CPUOvercommitRatio = req.CPUOvercommitRatio
+	}
+	if req.MemoryOvercommitRatio > 0 {
+		provider.MemoryOvercommitRatio = req.MemoryOvercommitRatio
+	}
 	// 容器特殊配置选项更新（仅 LXD/Incus 容器）
 	provider.ContainerPrivileged = req.ContainerPrivileged
 	provider.ContainerAllowNesting = req.ContainerAllowNesting
 	provider.ContainerEnableLXCFS = req.ContainerEnableLXCFS
+	lxcfsMounts, err := normalizeLXCFSMounts(req.ContainerLXCFSMounts)
+	if err != nil {
+		return err
+	}
+	provider.ContainerLXCFSMounts = lxcfsMounts
+	extraNetworks, err := normalizeExtraNetworks(req.ExtraNetworks)
+	if err != nil {
+		return err
+	}
+	provider.ExtraNetworks = extraNetworks
+	volumeAllowlist, err := normalizeVolumeAllowlist(req.VolumeAllowlist)
+	if err != nil {
+		return err
+	}
+	provider.VolumeAllowlist = volumeAllowlist
+	provider.RegistryServer = strings.TrimSpace(req.RegistryServer)
+	provider.RegistryUsername = strings.TrimSpace(req.RegistryUsername)
+	if req.RegistryPassword != nil {
+		provider.RegistryPassword = *req.RegistryPassword
+	}
+	provider.AllowSystemPrune = req.AllowSystemPrune
+	if req.PlacementWeight != 0 {
+		placementWeight, err := normalizePlacementWeight(req.PlacementWeight)
+		if err != nil {
+			return err
+		}
+		provider.PlacementWeight = placementWeight
+	}
+	if err := validateDefaultLimits(req.DefaultCPU, req.DefaultMemory, req.DefaultDisk, provider); err != nil {
+		return err
+	}
+	provider.DefaultCPU = req.DefaultCPU
+	provider.DefaultMemory = req.DefaultMemory
+	provider.DefaultDisk = req.DefaultDisk
 	if req.ContainerCPUAllowance != "" {
 		provider.ContainerCPUAllowance = req.ContainerCPUAllowance
 	}
+	if req.ContainerCPUAllowanceMode != "" {
+		provider.ContainerCPUAllowanceMode = req.ContainerCPUAllowanceMode
+	}
 	provider.ContainerMemorySwap = req.ContainerMemorySwap
 	provider.ContainerMaxProcesses = req.ContainerMaxProcesses
 	provider.ContainerDiskIOLimit = req.ContainerDiskIOLimit
@@ -573,6 +647,9 @@ func (s *Service) UpdateProvider(req admin.UpdateProviderRequest) error {
 	if provider.MaxConcurrentTasks <= 0 {
 		provider.MaxConcurrentTasks = 1
 	}
+	if provider.MaxConcurrentCreations < 0 {
+		provider.MaxConcurrentCreations = 0
+	}
 	if provider.TaskPollInterval <= 0 {
 		provider.TaskPollInterval = 60
 	}
@@ -896,5 +973,18 @@ func (s *Service) GetProviderStatus(providerID uint) (*admin.ProviderStatusRespo
 		ResourceSyncedAt: provider.ResourceSyncedAt,
 	}
 
+	sshPort := provider.SSHPort
+	if sshPort == 0 {
+		sshPort = 22
+	}
+	breaker := utils.GetSSHCircuitBreakerStatus(utils.ExtractHost(provider.Endpoint), sshPort)
+	response.SSHCircuitBreaker = admin.SSHCircuitBreakerStatus{
+		State:               breaker.State,
+		ConsecutiveTimeouts: breaker.ConsecutiveTimeouts,
+		OpenedAt:            breaker.OpenedAt,
+		RetryAt:             breaker.RetryAt,
+		TotalTrips:          breaker.TotalTrips,
+	}
+
 	return response, nil
 }
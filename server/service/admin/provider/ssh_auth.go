@@ -0,0 +1,56 @@
+package provider
+
+import (
+	"fmt"
+	"time"
+
+	"oneclickvirt/global"
+	"oneclickvirt/model/admin"
+	providerModel "oneclickvirt/model/provider"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+)
+
+// ValidateProviderSSHAuth 校验Provider已保存的SSH凭据
+// 仅完成握手和认证，不执行任何命令，返回实际认证成功的方式
+func (s *Service) ValidateProviderSSHAuth(providerID uint) (*admin.ValidateSSHAuthResponse, error) {
+	var provider providerModel.Provider
+	if err := global.APP_DB.First(&provider, providerID).Error; err != nil {
+		return nil, fmt.Errorf("Provider不存在")
+	}
+
+	connectTimeout := provider.SSHConnectTimeout
+	if connectTimeout <= 0 {
+		connectTimeout = 30
+	}
+
+	startTime := time.Now()
+	authMethod, triedMethods, err := utils.ValidateSSHAuth(utils.ProviderSSHConfig(&provider, time.Duration(connectTimeout)*time.Second, 0))
+	if triedMethods == nil {
+		triedMethods = []string{}
+	}
+
+	result := &admin.ValidateSSHAuthResponse{
+		TriedMethods: triedMethods,
+		Latency:      time.Since(startTime).Milliseconds(),
+	}
+	if err != nil {
+		global.APP_LOG.Warn("Provider SSH认证校验失败",
+			zap.Uint("providerID", providerID),
+			zap.String("name", provider.Name),
+			zap.Strings("triedMethods", triedMethods),
+			zap.Error(err))
+		result.ErrorMessage = err.Error()
+		return result, nil
+	}
+
+	global.APP_LOG.Info("Provider SSH认证校验成功",
+		zap.Uint("providerID", providerID),
+		zap.String("name", provider.Name),
+		zap.String("authMethod", authMethod))
+
+	result.Success = true
+	result.AuthMethod = authMethod
+	return result, nil
+}
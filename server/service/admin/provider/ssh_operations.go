@@ -0,0 +1,86 @@
+package provider
+
+import (
+	"oneclickvirt/global"
+	"oneclickvirt/model/admin"
+	"oneclickvirt/model/common"
+	providerModel "oneclickvirt/model/provider"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+)
+
+// ListSSHOperations 列出正在执行的SSH命令，按开始时间升序，providerID为0时返回全部Provider
+// 用于排查长时间无响应的命令（如等待交互输入的apt install）占用节点的情况
+func (s *Service) ListSSHOperations(providerID uint) ([]admin.SSHOperationResponse, error) {
+	commands := utils.ListInflightSSHCommands(providerID)
+	result := make([]admin.SSHOperationResponse, 0, len(commands))
+	if len(commands) == 0 {
+		return result, nil
+	}
+
+	ids := make([]uint, 0, len(commands))
+	for _, cmd := range commands {
+		if cmd.ProviderID != 0 {
+			ids = append(ids, cmd.ProviderID)
+		}
+	}
+	names := make(map[uint]string, len(ids))
+	if len(ids) > 0 {
+		var providers []providerModel.Provider
+		if err := global.APP_DB.Select("id, name").Where("id IN ?", ids).Find(&providers).Error; err != nil {
+			return nil, err
+		}
+		for _, p := range providers {
+			names[p.ID] = p.Name
+		}
+	}
+
+	for _, cmd := range commands {
+		result = append(result, toSSHOperationResponse(cmd, names[cmd.ProviderID]))
+	}
+	return result, nil
+}
+
+// CancelSSHOperation 取消正在执行的SSH命令，命令所在会话被强制终止，调用方收到取消错误
+// 取消不计入节点熔断统计
+func (s *Service) CancelSSHOperation(id string) (*admin.SSHOperationResponse, error) {
+	cmd, ok := utils.CancelInflightSSHCommand(id)
+	if !ok {
+		return nil, common.NewError(common.CodeNotFound, "操作不存在或已结束")
+	}
+
+	var name string
+	if cmd.ProviderID != 0 {
+		var provider providerModel.Provider
+		if err := global.APP_DB.Select("id, name").First(&provider, cmd.ProviderID).Error; err == nil {
+			name = provider.Name
+		}
+	}
+
+	global.APP_LOG.Warn("管理员取消SSH命令",
+		zap.String("operationId", cmd.ID),
+		zap.Uint("providerId", cmd.ProviderID),
+		zap.String("address", cmd.Address),
+		zap.String("command", utils.TruncateString(cmd.Command, 200)),
+		zap.Int64("runningSec", cmd.RunningSec))
+
+	response := toSSHOperationResponse(cmd, name)
+	return &response, nil
+}
+
+func toSSHOperationResponse(cmd utils.InflightSSHCommand, providerName string) admin.SSHOperationResponse {
+	return admin.SSHOperationResponse{
+		ID:           cmd.ID,
+		ProviderID:   cmd.ProviderID,
+		ProviderName: providerName,
+		Address:      cmd.Address,
+		Tag:          cmd.Tag,
+		Command:      cmd.Command,
+		StartedAt:    cmd.StartedAt,
+		TimeoutSec:   cmd.TimeoutSec,
+		RunningSec:   cmd.RunningSec,
+		Cancelled:    cmd.Cancelled,
+		CircuitState: utils.GetCircuitBreaker(cmd.Address).Status().State,
+	}
+}
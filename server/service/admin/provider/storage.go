@@ -0,0 +1,48 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"oneclickvirt/global"
+	"oneclickvirt/model/admin"
+	providerModel "oneclickvirt/model/provider"
+	"oneclickvirt/provider"
+	providerService "oneclickvirt/service/provider"
+)
+
+// ListProviderStoragePools 列出Provider节点上可用于创建实例的存储池
+func (s *Service) ListProviderStoragePools(providerID uint) (*admin.ProviderStoragePoolsResponse, error) {
+	var dbProvider providerModel.Provider
+	if err := global.APP_DB.First(&dbProvider, providerID).Error; err != nil {
+		return nil, fmt.Errorf("Provider不存在")
+	}
+
+	prov, err := providerService.GetProviderInstanceByID(providerID)
+	if err != nil {
+		return nil, fmt.Errorf("获取Provider失败: %v", err)
+	}
+
+	lister, ok := prov.(interface {
+		ListStoragePools(ctx context.Context) ([]provider.StoragePool, error)
+	})
+	if !ok {
+		return nil, fmt.Errorf("Provider类型 %s 不支持存储池管理", prov.GetType())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	pools, err := lister.ListStoragePools(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取存储池列表失败: %v", err)
+	}
+
+	return &admin.ProviderStoragePoolsResponse{
+		ProviderID:   dbProvider.ID,
+		ProviderType: dbProvider.Type,
+		DefaultPool:  dbProvider.InstanceStoragePool(),
+		Pools:        pools,
+	}, nil
+}
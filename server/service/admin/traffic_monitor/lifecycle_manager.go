@@ -140,13 +140,14 @@ func (m *LifecycleManager) BatchEnableMonitoring(ctx context.Context, providerID
 
 	// 查询所有活跃实例（使用精简字段查询，避免加载不必要数据）
 	var instances []struct {
-		ID         uint
-		Name       string
-		ProviderID uint
-		Status     string
+		ID               uint
+		Name             string
+		ProviderID       uint
+		Status           string
+		EnableMonitoring bool
 	}
 	if err := global.APP_DB.Model(&providerModel.Instance{}).
-		Select("id, name, provider_id, status").
+		Select("id, name, provider_id, status, enable_monitoring").
 		Where("provider_id = ? AND status NOT IN (?)", providerID, []string{"deleted", "deleting"}).
 		Find(&instances).Error; err != nil {
 		m.updateTaskStatus(taskID, "failed", 0, "查询实例失败", nil, &now)
@@ -188,6 +189,11 @@ func (m *LifecycleManager) BatchEnableMonitoring(ctx context.Context, providerID
 			successCount++
 			continue
 		}
+		if !inst.EnableMonitoring {
+			outputBuilder.WriteString("  - 实例已关闭流量监控，跳过\n\n")
+			successCount++
+			continue
+		}
 
 		// 初始化监控
 		if err := pmacctService.InitializePmacctForInstance(inst.ID); err != nil {
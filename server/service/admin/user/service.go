@@ -243,6 +243,9 @@ func (s *Service) UpdateUser(req admin.UpdateUserRequest, currentUserID uint) er
 	if req.Status >= 0 {
 		user.Status = req.Status
 	}
+	if req.AllowInstanceExec != nil {
+		user.AllowInstanceExec = *req.AllowInstanceExec
+	}
 
 	// 处理角色相关的用户类型更新
 	if req.RoleID > 0 {
@@ -631,24 +634,8 @@ func (s *Service) ResetUserPassword(userID uint) (string, error) {
 		return "", err
 	}
 
-	// 生成强密码（12位）
-	newPassword := utils.GenerateStrongPassword(12)
-
-	// 管理员重置密码使用放宽的策略（不要求特殊字符，因为生成的密码仅包含字母和数字）
-	adminResetPolicy := utils.PasswordStrengthConfig{
-		MinLength:        8,     // 最小8位
-		RequireUpperCase: true,  // 要求大写字母
-		RequireLowerCase: true,  // 要求小写字母
-		RequireDigit:     true,  // 要求数字
-		RequireSpecial:   false, // 不要求特殊字符（管理员生成的密码）
-		ForbidCommon:     true,  // 禁止常见弱密码
-		ForbidPersonal:   true,  // 禁止包含个人信息
-	}
-
-	// 密码强度验证（确保生成的密码符合策略）
-	if err := utils.ValidatePasswordStrength(newPassword, adminResetPolicy, user.Username); err != nil {
-		return "", common.NewError(common.CodeValidationError, err.Error())
-	}
+	// 按配置的生成密码策略生成新密码
+	newPassword := utils.GeneratePassword(utils.CurrentPasswordPolicy())
 
 	// 加密新密码
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
@@ -681,13 +668,8 @@ func (s *Service) ResetUserPasswordAndNotify(userID uint) error {
 		return err
 	}
 
-	// 生成强密码（12位）
-	newPassword := utils.GenerateStrongPassword(12)
-
-	// 密码强度验证（确保生成的密码符合策略）
-	if err := utils.ValidatePasswordStrength(newPassword, utils.DefaultPasswordPolicy, user.Username); err != nil {
-		return common.NewError(common.CodeValidationError, err.Error())
-	}
+	// 按配置的生成密码策略生成新密码
+	newPassword := utils.GeneratePassword(utils.CurrentPasswordPolicy())
 
 	// 加密新密码
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
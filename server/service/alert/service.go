@@ -0,0 +1,166 @@
+package alert
+
+import (
+	"errors"
+	"fmt"
+
+	"oneclickvirt/global"
+	monitoringModel "oneclickvirt/model/monitoring"
+	userModel "oneclickvirt/model/user"
+
+	"gorm.io/gorm"
+)
+
+const (
+	AlertTypeCPU          = "cpu"
+	AlertTypeDisk         = "disk"
+	AlertTypeTrafficSpike = "traffic_spike"
+
+	defaultCPUPercent      = 90
+	defaultCPUMinutes      = 10
+	defaultDiskPercent     = 95
+	defaultCooldownMinutes = 60
+)
+
+// alertSubjects 各类告警的通知标题
+var alertSubjects = map[string]string{
+	AlertTypeCPU:          "实例CPU使用率告警",
+	AlertTypeDisk:         "实例磁盘使用率告警",
+	AlertTypeTrafficSpike: "实例流量突增告警",
+}
+
+// Thresholds 生效的资源告警阈值
+type Thresholds struct {
+	Enabled         bool
+	CPUPercent      int
+	CPUMinutes      int
+	DiskPercent     int
+	CooldownMinutes int
+}
+
+// Service 资源告警设置与历史查询
+type Service struct{}
+
+// NewService 创建资源告警服务
+func NewService() *Service {
+	return &Service{}
+}
+
+// systemThresholds 读取系统级默认阈值
+func systemThresholds() Thresholds {
+	quota := global.APP_CONFIG.Quota
+	t := Thresholds{
+		Enabled:         true,
+		CPUPercent:      quota.ResourceAlertCPUPercent,
+		CPUMinutes:      quota.ResourceAlertCPUMinutes,
+		DiskPercent:     quota.ResourceAlertDiskPercent,
+		CooldownMinutes: quota.ResourceAlertCooldownMinutes,
+	}
+	if t.CPUPercent <= 0 {
+		t.CPUPercent = defaultCPUPercent
+	}
+	if t.CPUMinutes <= 0 {
+		t.CPUMinutes = defaultCPUMinutes
+	}
+	if t.DiskPercent <= 0 {
+		t.DiskPercent = defaultDiskPercent
+	}
+	if t.CooldownMinutes <= 0 {
+		t.CooldownMinutes = defaultCooldownMinutes
+	}
+	return t
+}
+
+// mergeSetting 用户设置覆盖系统默认值
+func mergeSetting(t Thresholds, setting *monitoringModel.ResourceAlertSetting) Thresholds {
+	if setting == nil {
+		return t
+	}
+	if setting.Enabled != nil {
+		t.Enabled = *setting.Enabled
+	}
+	if setting.CPUPercent != nil && *setting.CPUPercent > 0 {
+		t.CPUPercent = *setting.CPUPercent
+	}
+	if setting.CPUMinutes != nil && *setting.CPUMinutes > 0 {
+		t.CPUMinutes = *setting.CPUMinutes
+	}
+	if setting.DiskPercent != nil && *setting.DiskPercent > 0 {
+		t.DiskPercent = *setting.DiskPercent
+	}
+	if setting.CooldownMinutes != nil && *setting.CooldownMinutes > 0 {
+		t.CooldownMinutes = *setting.CooldownMinutes
+	}
+	return t
+}
+
+// GetUserSettings 获取用户的资源告警设置
+func (s *Service) GetUserSettings(userID uint) (*userModel.ResourceAlertSettingsResponse, error) {
+	var setting monitoringModel.ResourceAlertSetting
+	err := global.APP_DB.Where("user_id = ?", userID).First(&setting).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("获取资源告警设置失败: %w", err)
+	}
+
+	var t Thresholds
+	if err == nil {
+		t = mergeSetting(systemThresholds(), &setting)
+	} else {
+		t = systemThresholds()
+	}
+
+	return &userModel.ResourceAlertSettingsResponse{
+		SystemEnabled:   global.APP_CONFIG.Quota.ResourceAlertEnabled,
+		Enabled:         t.Enabled,
+		CPUPercent:      t.CPUPercent,
+		CPUMinutes:      t.CPUMinutes,
+		DiskPercent:     t.DiskPercent,
+		CooldownMinutes: t.CooldownMinutes,
+	}, nil
+}
+
+// UpdateUserSettings 更新用户的资源告警设置
+func (s *Service) UpdateUserSettings(userID uint, req userModel.UpdateResourceAlertSettingsRequest) (*userModel.ResourceAlertSettingsResponse, error) {
+	setting := monitoringModel.ResourceAlertSetting{UserID: userID}
+	if err := global.APP_DB.Where("user_id = ?", userID).FirstOrCreate(&setting).Error; err != nil {
+		return nil, fmt.Errorf("获取资源告警设置失败: %w", err)
+	}
+
+	updates := map[string]interface{}{
+		"enabled":          req.Enabled,
+		"cpu_percent":      req.CPUPercent,
+		"cpu_minutes":      req.CPUMinutes,
+		"disk_percent":     req.DiskPercent,
+		"cooldown_minutes": req.CooldownMinutes,
+	}
+	if err := global.APP_DB.Model(&setting).Updates(updates).Error; err != nil {
+		return nil, fmt.Errorf("更新资源告警设置失败: %w", err)
+	}
+
+	return s.GetUserSettings(userID)
+}
+
+// ListUserAlerts 获取用户的资源告警记录
+func (s *Service) ListUserAlerts(userID uint, req userModel.ResourceAlertListRequest) ([]monitoringModel.ResourceAlert, int64, error) {
+	query := global.APP_DB.Model(&monitoringModel.ResourceAlert{}).Where("user_id = ?", userID)
+	if req.InstanceID > 0 {
+		query = query.Where("instance_id = ?", req.InstanceID)
+	}
+	if req.AlertType != "" {
+		query = query.Where("alert_type = ?", req.AlertType)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var alerts []monitoringModel.ResourceAlert
+	if err := query.Order("created_at DESC").
+		Offset((req.Page - 1) * req.PageSize).
+		Limit(req.PageSize).
+		Find(&alerts).Error; err != nil {
+		return nil, 0, err
+	}
+	return alerts, total, nil
+}
@@ -0,0 +1,286 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"oneclickvirt/global"
+	monitoringModel "oneclickvirt/model/monitoring"
+	providerModel "oneclickvirt/model/provider"
+	providerService "oneclickvirt/service/provider"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// trafficSpikeInterval 流量突增检测周期，与pmacct数据点的5分钟精度一致
+	trafficSpikeInterval = 5 * time.Minute
+	// minBaselineSamples 基线至少需要的数据点间隔数，新建实例数据不足时不检测
+	minBaselineSamples = 6
+
+	defaultSpikeMultiplier      = 5
+	defaultSpikeWindowMinutes   = 15
+	defaultSpikeBaselineHours   = 24
+	defaultSpikeMinMbps         = 10
+	defaultSpikeCooldownMinutes = 60
+)
+
+// SpikeSettings 生效的流量突增检测参数
+type SpikeSettings struct {
+	Multiplier      int
+	WindowMinutes   int
+	BaselineHours   int
+	MinMbps         int
+	CooldownMinutes int
+	LimitMbps       int
+}
+
+// trafficSpikeSettings 读取系统级流量突增检测参数
+func trafficSpikeSettings() SpikeSettings {
+	quota := global.APP_CONFIG.Quota
+	s := SpikeSettings{
+		Multiplier:      quota.TrafficSpikeMultiplier,
+		WindowMinutes:   quota.TrafficSpikeWindowMinutes,
+		BaselineHours:   quota.TrafficSpikeBaselineHours,
+		MinMbps:         quota.TrafficSpikeMinMbps,
+		CooldownMinutes: quota.TrafficSpikeCooldownMinutes,
+		LimitMbps:       quota.TrafficSpikeLimitMbps,
+	}
+	if s.Multiplier <= 1 {
+		s.Multiplier = defaultSpikeMultiplier
+	}
+	if s.WindowMinutes <= 0 {
+		s.WindowMinutes = defaultSpikeWindowMinutes
+	}
+	if s.BaselineHours <= 0 {
+		s.BaselineHours = defaultSpikeBaselineHours
+	}
+	if s.MinMbps <= 0 {
+		s.MinMbps = defaultSpikeMinMbps
+	}
+	if s.CooldownMinutes <= 0 {
+		s.CooldownMinutes = defaultSpikeCooldownMinutes
+	}
+	return s
+}
+
+// TrafficSpikeWorker 定期对比实例近期流量速率与历史基线，速率突增时告警并可选自动限速
+type TrafficSpikeWorker struct {
+	stopChan chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+var (
+	spikeWorker     *TrafficSpikeWorker
+	spikeWorkerOnce sync.Once
+)
+
+// GetTrafficSpikeWorker 获取流量突增检测器单例
+func GetTrafficSpikeWorker() *TrafficSpikeWorker {
+	spikeWorkerOnce.Do(func() {
+		spikeWorker = &TrafficSpikeWorker{
+			stopChan: make(chan struct{}),
+		}
+	})
+	return spikeWorker
+}
+
+// Start 启动流量突增检测
+func (w *TrafficSpikeWorker) Start(ctx context.Context) {
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		defer func() {
+			if r := recover(); r != nil {
+				global.APP_LOG.Error("流量突增检测任务panic", zap.Any("panic", r), zap.Stack("stack"))
+			}
+		}()
+
+		ticker := time.NewTicker(trafficSpikeInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-w.stopChan:
+				return
+			case <-ticker.C:
+				if global.APP_DB == nil || !global.APP_CONFIG.Quota.TrafficSpikeAlertEnabled {
+					continue
+				}
+				w.runOnce(ctx)
+			}
+		}
+	}()
+}
+
+// Stop 停止流量突增检测
+func (w *TrafficSpikeWorker) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stopChan)
+	})
+	w.wg.Wait()
+}
+
+// runOnce 执行一轮检测
+func (w *TrafficSpikeWorker) runOnce(ctx context.Context) {
+	settings := trafficSpikeSettings()
+
+	var instances []providerModel.Instance
+	if err := global.APP_DB.
+		Select("id, name, user_id, provider_id, ingress_mbps, egress_mbps").
+		Where("status = ?", "running").
+		Find(&instances).Error; err != nil {
+		global.APP_LOG.Warn("查询流量突增检测实例失败", zap.Error(err))
+		return
+	}
+
+	now := time.Now()
+	windowStart := now.Add(-time.Duration(settings.WindowMinutes) * time.Minute)
+	// 多取一个数据点作为基线第一个间隔的起点
+	since := windowStart.Add(-time.Duration(settings.BaselineHours)*time.Hour - trafficSpikeInterval)
+
+	for _, inst := range instances {
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.stopChan:
+			return
+		default:
+		}
+
+		var records []monitoringModel.PmacctTrafficRecord
+		if err := global.APP_DB.
+			Select("timestamp, total_bytes").
+			Where("instance_id = ? AND timestamp >= ?", inst.ID, since).
+			Order("timestamp ASC").
+			Find(&records).Error; err != nil {
+			global.APP_LOG.Debug("查询实例流量记录失败",
+				zap.Uint("instanceID", inst.ID),
+				zap.Error(err))
+			continue
+		}
+
+		recentMbps, baselineMbps, ok := trafficRates(records, windowStart)
+		if !ok {
+			continue
+		}
+		if recentMbps < float64(settings.MinMbps) || recentMbps < baselineMbps*float64(settings.Multiplier) {
+			continue
+		}
+
+		w.handleSpike(ctx, inst, recentMbps, baselineMbps, settings)
+	}
+}
+
+// trafficRates 由pmacct累积计数器相邻数据点的增量计算近期和基线的平均速率（Mbps）
+// windowStart 之后结束的间隔计入近期速率，其余计入基线；数据点不足时返回false
+func trafficRates(records []monitoringModel.PmacctTrafficRecord, windowStart time.Time) (recentMbps, baselineMbps float64, ok bool) {
+	var recentBytes, baselineBytes int64
+	var recentSeconds, baselineSeconds float64
+	baselineSamples := 0
+
+	for i := 1; i < len(records); i++ {
+		seconds := records[i].Timestamp.Sub(records[i-1].Timestamp).Seconds()
+		if seconds <= 0 {
+			continue
+		}
+		delta := counterDelta(records[i].TotalBytes, records[i-1].TotalBytes)
+		if records[i].Timestamp.After(windowStart) {
+			recentBytes += delta
+			recentSeconds += seconds
+		} else {
+			baselineBytes += delta
+			baselineSeconds += seconds
+			baselineSamples++
+		}
+	}
+
+	if recentSeconds == 0 || baselineSamples < minBaselineSamples {
+		return 0, 0, false
+	}
+	return bytesToMbps(recentBytes, recentSeconds), bytesToMbps(baselineBytes, baselineSeconds), true
+}
+
+// counterDelta 计算累积计数器相邻两点的增量，当前值小于上一个值时视为pmacct重启，直接使用当前值
+func counterDelta(current, previous int64) int64 {
+	if current < previous {
+		return current
+	}
+	return current - previous
+}
+
+func bytesToMbps(bytes int64, seconds float64) float64 {
+	return float64(bytes) * 8 / seconds / 1000 / 1000
+}
+
+// handleSpike 记录流量突增告警并按配置自动限速
+func (w *TrafficSpikeWorker) handleSpike(ctx context.Context, inst providerModel.Instance, recentMbps, baselineMbps float64, settings SpikeSettings) {
+	// 冷却期内不重复告警和限速，避免速率在阈值附近波动时反复触发
+	if inCooldown(inst.ID, AlertTypeTrafficSpike, settings.CooldownMinutes) {
+		return
+	}
+
+	limited := false
+	if settings.LimitMbps > 0 && (bandwidthAbove(inst.IngressMbps, settings.LimitMbps) || bandwidthAbove(inst.EgressMbps, settings.LimitMbps)) {
+		limited = w.applyLimit(ctx, inst, settings.LimitMbps)
+	}
+
+	message := fmt.Sprintf("实例 %s 最近 %d 分钟的平均流量速率为 %.1fMbps，是过去 %d 小时平均速率 %.1fMbps 的 %d 倍以上，可能存在滥用或被入侵的情况，请及时检查。",
+		inst.Name, settings.WindowMinutes, recentMbps, settings.BaselineHours, baselineMbps, settings.Multiplier)
+	if limited {
+		message += fmt.Sprintf("实例已被自动限速至 %dMbps，如需解除请联系管理员。", settings.LimitMbps)
+	}
+
+	if raiseAlert(inst, AlertTypeTrafficSpike, recentMbps, baselineMbps*float64(settings.Multiplier), settings.CooldownMinutes, message) {
+		global.APP_LOG.Warn("检测到实例流量突增",
+			zap.Uint("instanceID", inst.ID),
+			zap.String("instanceName", inst.Name),
+			zap.Uint("userID", inst.UserID),
+			zap.Float64("recentMbps", recentMbps),
+			zap.Float64("baselineMbps", baselineMbps),
+			zap.Bool("limited", limited))
+	}
+}
+
+// bandwidthAbove 判断当前限速是否高于目标值，0表示不限速
+func bandwidthAbove(currentMbps, limitMbps int) bool {
+	return currentMbps <= 0 || currentMbps > limitMbps
+}
+
+// applyLimit 将实例双向带宽限制到不超过 limitMbps 并持久化，管理员可通过实例带宽接口解除
+func (w *TrafficSpikeWorker) applyLimit(ctx context.Context, inst providerModel.Instance, limitMbps int) bool {
+	ingress, egress := inst.IngressMbps, inst.EgressMbps
+	if bandwidthAbove(ingress, limitMbps) {
+		ingress = limitMbps
+	}
+	if bandwidthAbove(egress, limitMbps) {
+		egress = limitMbps
+	}
+
+	limitCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+
+	providerApiService := &providerService.ProviderApiService{}
+	if err := providerApiService.SetBandwidthLimitByProviderID(limitCtx, inst.ProviderID, inst.Name, egress, ingress); err != nil {
+		global.APP_LOG.Warn("流量突增自动限速失败",
+			zap.Uint("instanceID", inst.ID),
+			zap.String("instanceName", inst.Name),
+			zap.Error(err))
+		return false
+	}
+
+	if err := global.APP_DB.Model(&providerModel.Instance{}).Where("id = ?", inst.ID).Updates(map[string]interface{}{
+		"ingress_mbps": ingress,
+		"egress_mbps":  egress,
+	}).Error; err != nil {
+		global.APP_LOG.Warn("保存流量突增自动限速配置失败",
+			zap.Uint("instanceID", inst.ID),
+			zap.Error(err))
+	}
+	return true
+}
@@ -0,0 +1,309 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"oneclickvirt/global"
+	monitoringModel "oneclickvirt/model/monitoring"
+	providerModel "oneclickvirt/model/provider"
+	userModel "oneclickvirt/model/user"
+	"oneclickvirt/provider"
+	providerService "oneclickvirt/service/provider"
+	"oneclickvirt/service/user/notification"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// sampleInterval 资源采样周期
+	sampleInterval = 2 * time.Minute
+	// maxConcurrentProviders 同时采样的Provider数量上限，同一Provider内按实例顺序采样
+	maxConcurrentProviders = 4
+)
+
+type usageGetter interface {
+	GetInstanceUsage(ctx context.Context, instanceName string) (*provider.InstanceUsage, error)
+}
+
+// Worker 定期采样运行中实例的CPU和磁盘使用率，持续超阈值时通过用户通信渠道发送告警
+type Worker struct {
+	stopChan chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+
+	mu          sync.Mutex
+	cpuHighFrom map[uint]time.Time // 实例CPU持续超过阈值的起始时间
+}
+
+var (
+	worker     *Worker
+	workerOnce sync.Once
+)
+
+// GetWorker 获取资源告警采样器单例
+func GetWorker() *Worker {
+	workerOnce.Do(func() {
+		worker = &Worker{
+			stopChan:    make(chan struct{}),
+			cpuHighFrom: make(map[uint]time.Time),
+		}
+	})
+	return worker
+}
+
+// Start 启动资源告警采样
+func (w *Worker) Start(ctx context.Context) {
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		defer func() {
+			if r := recover(); r != nil {
+				global.APP_LOG.Error("资源告警采样任务panic", zap.Any("panic", r), zap.Stack("stack"))
+			}
+		}()
+
+		ticker := time.NewTicker(sampleInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-w.stopChan:
+				return
+			case <-ticker.C:
+				if global.APP_DB == nil || !global.APP_CONFIG.Quota.ResourceAlertEnabled {
+					continue
+				}
+				w.runOnce(ctx)
+			}
+		}
+	}()
+}
+
+// Stop 停止资源告警采样
+func (w *Worker) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stopChan)
+	})
+	w.wg.Wait()
+}
+
+// runOnce 执行一轮采样
+func (w *Worker) runOnce(ctx context.Context) {
+	var instances []providerModel.Instance
+	if err := global.APP_DB.
+		Select("instances.id, instances.name, instances.user_id, instances.provider_id, instances.status, instances.disk").
+		Joins("JOIN providers ON providers.id = instances.provider_id").
+		Where("instances.status = ? AND providers.ssh_status = ?", "running", "online").
+		Find(&instances).Error; err != nil {
+		global.APP_LOG.Warn("查询资源告警采样实例失败", zap.Error(err))
+		return
+	}
+
+	byProvider := make(map[uint][]providerModel.Instance)
+	seen := make(map[uint]bool, len(instances))
+	for _, inst := range instances {
+		byProvider[inst.ProviderID] = append(byProvider[inst.ProviderID], inst)
+		seen[inst.ID] = true
+	}
+
+	// 清理已停止或删除实例的CPU状态
+	w.mu.Lock()
+	for id := range w.cpuHighFrom {
+		if !seen[id] {
+			delete(w.cpuHighFrom, id)
+		}
+	}
+	w.mu.Unlock()
+
+	thresholds := newThresholdCache()
+	sem := make(chan struct{}, maxConcurrentProviders)
+	var wg sync.WaitGroup
+	for providerID, list := range byProvider {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(providerID uint, list []providerModel.Instance) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			w.sampleProvider(ctx, providerID, list, thresholds)
+		}(providerID, list)
+	}
+	wg.Wait()
+}
+
+// sampleProvider 采样同一Provider上的实例
+func (w *Worker) sampleProvider(ctx context.Context, providerID uint, instances []providerModel.Instance, thresholds *thresholdCache) {
+	prov, err := providerService.GetProviderInstanceByID(providerID)
+	if err != nil {
+		global.APP_LOG.Debug("获取Provider实例失败，跳过资源采样",
+			zap.Uint("providerID", providerID),
+			zap.Error(err))
+		return
+	}
+
+	getter, ok := prov.(usageGetter)
+	if !ok {
+		return
+	}
+
+	for _, inst := range instances {
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.stopChan:
+			return
+		default:
+		}
+
+		t := thresholds.get(inst.UserID)
+		if !t.Enabled {
+			continue
+		}
+
+		sampleCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		usage, err := getter.GetInstanceUsage(sampleCtx, inst.Name)
+		cancel()
+		if err != nil {
+			global.APP_LOG.Debug("采样实例资源使用情况失败",
+				zap.Uint("instanceID", inst.ID),
+				zap.String("instanceName", inst.Name),
+				zap.Error(err))
+			continue
+		}
+
+		// 磁盘使用率按实例配置的磁盘大小计算，避免容器内df显示的是宿主机分区而无法及时告警
+		if diskUsage, err := providerService.GetInstanceDiskUsage(inst); err == nil && diskUsage.TotalBytes > 0 {
+			usage.DiskUsedMB = diskUsage.UsedBytes / 1024 / 1024
+			usage.DiskTotalMB = diskUsage.TotalBytes / 1024 / 1024
+			usage.DiskPercent = diskUsage.Percent
+		}
+
+		w.evaluate(inst, usage, t)
+	}
+}
+
+// evaluate 根据采样结果判断是否需要告警
+func (w *Worker) evaluate(inst providerModel.Instance, usage *provider.InstanceUsage, t Thresholds) {
+	now := time.Now()
+
+	if usage.CPUPercent >= float64(t.CPUPercent) {
+		w.mu.Lock()
+		since, ok := w.cpuHighFrom[inst.ID]
+		if !ok {
+			since = now
+			w.cpuHighFrom[inst.ID] = now
+		}
+		w.mu.Unlock()
+
+		if now.Sub(since) >= time.Duration(t.CPUMinutes)*time.Minute {
+			message := fmt.Sprintf("实例 %s 的CPU使用率已连续 %d 分钟以上超过 %d%%，当前为 %.1f%%。",
+				inst.Name, int(now.Sub(since).Minutes()), t.CPUPercent, usage.CPUPercent)
+			raiseAlert(inst, AlertTypeCPU, usage.CPUPercent, float64(t.CPUPercent), t.CooldownMinutes, message)
+		}
+	} else {
+		w.mu.Lock()
+		delete(w.cpuHighFrom, inst.ID)
+		w.mu.Unlock()
+	}
+
+	if usage.DiskTotalMB > 0 && usage.DiskPercent >= float64(t.DiskPercent) {
+		message := fmt.Sprintf("实例 %s 的磁盘使用率为 %.1f%%（%dMB/%dMB），已超过 %d%%，磁盘写满可能导致服务异常。",
+			inst.Name, usage.DiskPercent, usage.DiskUsedMB, usage.DiskTotalMB, t.DiskPercent)
+		raiseAlert(inst, AlertTypeDisk, usage.DiskPercent, float64(t.DiskPercent), t.CooldownMinutes, message)
+	}
+}
+
+// raiseAlert 记录告警并通知用户，同一实例同类告警在冷却期内只发送一次，返回是否记录了新告警
+func raiseAlert(inst providerModel.Instance, alertType string, value, threshold float64, cooldownMinutes int, message string) bool {
+	if inCooldown(inst.ID, alertType, cooldownMinutes) {
+		return false
+	}
+
+	// 先记录告警，避免通知渠道异常时每轮采样重复发送
+	record := monitoringModel.ResourceAlert{
+		UserID:       inst.UserID,
+		InstanceID:   inst.ID,
+		InstanceName: inst.Name,
+		ProviderID:   inst.ProviderID,
+		AlertType:    alertType,
+		Value:        value,
+		Threshold:    threshold,
+		Message:      message,
+	}
+	if err := global.APP_DB.Create(&record).Error; err != nil {
+		global.APP_LOG.Warn("保存资源告警记录失败", zap.Uint("instanceID", inst.ID), zap.Error(err))
+		return false
+	}
+
+	var u userModel.User
+	if err := global.APP_DB.First(&u, inst.UserID).Error; err != nil {
+		global.APP_LOG.Warn("获取告警实例所属用户失败", zap.Uint("userID", inst.UserID), zap.Error(err))
+		return true
+	}
+
+	if err := notification.NewService().NotifyUser(&u, alertSubjects[alertType], message); err != nil {
+		global.APP_LOG.Warn("发送资源告警通知失败",
+			zap.Uint("userID", u.ID),
+			zap.Uint("instanceID", inst.ID),
+			zap.String("alertType", alertType),
+			zap.Error(err))
+		return true
+	}
+
+	global.APP_DB.Model(&record).Update("notified", true)
+
+	global.APP_LOG.Info("已发送实例资源告警",
+		zap.Uint("userID", u.ID),
+		zap.Uint("instanceID", inst.ID),
+		zap.String("alertType", alertType),
+		zap.Float64("value", value))
+	return true
+}
+
+// inCooldown 判断实例同类告警是否仍在冷却期内，查询失败时按冷却中处理以免重复告警
+func inCooldown(instanceID uint, alertType string, cooldownMinutes int) bool {
+	var recent int64
+	if err := global.APP_DB.Model(&monitoringModel.ResourceAlert{}).
+		Where("instance_id = ? AND alert_type = ? AND created_at > ?",
+			instanceID, alertType, time.Now().Add(-time.Duration(cooldownMinutes)*time.Minute)).
+		Count(&recent).Error; err != nil {
+		global.APP_LOG.Warn("查询资源告警记录失败", zap.Uint("instanceID", instanceID), zap.Error(err))
+		return true
+	}
+	return recent > 0
+}
+
+// thresholdCache 单轮采样内缓存用户阈值，避免重复查询
+type thresholdCache struct {
+	mu       sync.Mutex
+	system   Thresholds
+	settings map[uint]Thresholds
+}
+
+func newThresholdCache() *thresholdCache {
+	return &thresholdCache{
+		system:   systemThresholds(),
+		settings: make(map[uint]Thresholds),
+	}
+}
+
+func (c *thresholdCache) get(userID uint) Thresholds {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if t, ok := c.settings[userID]; ok {
+		return t
+	}
+
+	t := c.system
+	var setting monitoringModel.ResourceAlertSetting
+	if err := global.APP_DB.Where("user_id = ?", userID).First(&setting).Error; err == nil {
+		t = mergeSetting(c.system, &setting)
+	}
+	c.settings[userID] = t
+	return t
+}
@@ -560,13 +560,8 @@ func (s *AuthService) ResetPasswordWithToken(token string) error {
 		return err
 	}
 
-	// 生成强密码（12位）
-	newPassword := utils.GenerateStrongPassword(12)
-
-	// 密码强度验证（确保生成的密码符合策略）
-	if err := utils.ValidatePasswordStrength(newPassword, utils.DefaultPasswordPolicy, user.Username); err != nil {
-		return err
-	}
+	// 按配置的生成密码策略生成新密码
+	newPassword := utils.GeneratePassword(utils.CurrentPasswordPolicy())
 
 	// 加密新密码
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
@@ -0,0 +1,135 @@
+package backup
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+	"time"
+
+	"oneclickvirt/global"
+	providerModel "oneclickvirt/model/provider"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+)
+
+// 备份状态
+const (
+	StatusPending   = "pending"
+	StatusRunning   = "running"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+)
+
+// ErrStorageNotConfigured 未配置对象存储时返回的错误
+var ErrStorageNotConfigured = errors.New("未配置备份对象存储")
+
+// NewObjectStorage 根据配置创建对象存储客户端
+func NewObjectStorage() (*utils.S3Client, error) {
+	cfg := global.APP_CONFIG.Backup
+	if cfg.Endpoint == "" || cfg.Bucket == "" {
+		return nil, ErrStorageNotConfigured
+	}
+	return utils.NewS3Client(utils.S3Config{
+		Endpoint:  cfg.Endpoint,
+		Region:    cfg.Region,
+		Bucket:    cfg.Bucket,
+		AccessKey: cfg.AccessKey,
+		SecretKey: cfg.SecretKey,
+		PartSize:  int64(cfg.PartSize) << 20,
+	})
+}
+
+// ObjectKey 生成备份在对象存储中的键：<前缀>/<实例名>/<备份ID>
+// 归档文件名要在导出完成后才能确定，因此不放进键中，恢复时从备份记录读取
+func ObjectKey(backup providerModel.Backup) string {
+	key := fmt.Sprintf("%s/%d", backup.InstanceName, backup.ID)
+	if prefix := strings.Trim(global.APP_CONFIG.Backup.Prefix, "/"); prefix != "" {
+		key = prefix + "/" + key
+	}
+	return key
+}
+
+// Delete 删除备份对象和备份记录，对象删除失败时保留记录以便重试
+func Delete(ctx context.Context, backup providerModel.Backup) error {
+	if backup.ObjectKey != "" {
+		storage, err := NewObjectStorage()
+		if err != nil {
+			return err
+		}
+		if err := storage.DeleteObject(ctx, backup.ObjectKey); err != nil {
+			return fmt.Errorf("删除备份文件失败: %w", err)
+		}
+	}
+	if err := global.APP_DB.Delete(&backup).Error; err != nil {
+		return fmt.Errorf("删除备份记录失败: %w", err)
+	}
+	return nil
+}
+
+// ApplyRetention 按保留策略清理实例的旧备份：超过保留数量的最旧备份和超过保留天数的备份
+// 只清理已完成的备份，单个备份清理失败不影响其余备份
+func ApplyRetention(ctx context.Context, instanceID uint) {
+	cfg := global.APP_CONFIG.Backup
+	if cfg.RetentionCount <= 0 && cfg.RetentionDays <= 0 {
+		return
+	}
+
+	var backups []providerModel.Backup
+	if err := global.APP_DB.Where("instance_id = ? AND status = ?", instanceID, StatusCompleted).
+		Order("created_at DESC").Find(&backups).Error; err != nil {
+		global.APP_LOG.Warn("查询实例备份失败，跳过保留策略", zap.Uint("instanceId", instanceID), zap.Error(err))
+		return
+	}
+
+	cutoff := time.Now().AddDate(0, 0, -cfg.RetentionDays)
+	for idx, backup := range backups {
+		expired := cfg.RetentionDays > 0 && backup.CreatedAt.Before(cutoff)
+		overflow := cfg.RetentionCount > 0 && idx >= cfg.RetentionCount
+		if !expired && !overflow {
+			continue
+		}
+		if err := Delete(ctx, backup); err != nil {
+			global.APP_LOG.Warn("按保留策略删除备份失败",
+				zap.Uint("backupId", backup.ID),
+				zap.Error(err))
+			continue
+		}
+		global.APP_LOG.Info("按保留策略删除备份",
+			zap.Uint("backupId", backup.ID),
+			zap.Uint("instanceId", instanceID),
+			zap.Bool("expired", expired))
+	}
+}
+
+// ChecksumReader 读取时计算SHA256，读到末尾时与期望值比对，不一致时返回错误而不是EOF，
+// 使下游在导入前就能发现损坏的备份
+type ChecksumReader struct {
+	reader   io.Reader
+	hash     hash.Hash
+	expected string
+}
+
+// NewChecksumReader 创建校验读取器，expected为空时只计算不校验
+func NewChecksumReader(r io.Reader, expected string) *ChecksumReader {
+	return &ChecksumReader{reader: r, hash: sha256.New(), expected: strings.ToLower(expected)}
+}
+
+func (r *ChecksumReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.hash.Write(p[:n])
+	if err == io.EOF && r.expected != "" && r.Sum() != r.expected {
+		return n, fmt.Errorf("备份校验失败，SHA256不一致")
+	}
+	return n, err
+}
+
+// Sum 返回已读取内容的SHA256
+func (r *ChecksumReader) Sum() string {
+	return hex.EncodeToString(r.hash.Sum(nil))
+}
@@ -0,0 +1,306 @@
+package billing
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"oneclickvirt/global"
+	"oneclickvirt/model/common"
+	providerModel "oneclickvirt/model/provider"
+	userModel "oneclickvirt/model/user"
+	"oneclickvirt/service/traffic"
+)
+
+// 报表统计维度
+const (
+	ScopeUser     = "user"
+	ScopeProvider = "provider"
+)
+
+// 单次批量查询的实例数量，避免IN列表过长
+const instanceBatchSize = 200
+
+// Report 月度流量计费报表
+type Report struct {
+	Scope       string       `json:"scope"`        // 统计维度：user/provider
+	Period      string       `json:"period"`       // 账期，格式YYYY-MM
+	StartTime   time.Time    `json:"start_time"`   // 账期开始时间
+	EndTime     time.Time    `json:"end_time"`     // 账期结束时间（不含）
+	Partial     bool         `json:"partial"`      // 账期尚未结束，数据不完整
+	Currency    string       `json:"currency"`     // 货币单位
+	PricePerGB  float64      `json:"price_per_gb"` // 超额流量单价（每GB）
+	GeneratedAt time.Time    `json:"generated_at"` // 生成时间
+	Items       []ReportItem `json:"items"`
+	Summary     ReportItem   `json:"summary"` // 合计，ID和Name为空
+}
+
+// ReportItem 报表中单个用户或Provider的流量与费用
+type ReportItem struct {
+	ID            uint    `json:"id"`
+	Name          string  `json:"name"`
+	InstanceCount int     `json:"instance_count"` // 当月产生流量的实例数量
+	RxBytes       int64   `json:"rx_bytes"`       // 入站流量
+	TxBytes       int64   `json:"tx_bytes"`       // 出站流量
+	UsageMB       float64 `json:"usage_mb"`       // 计费用量（MB，已应用Provider流量计算模式和倍率）
+	IncludedMB    int64   `json:"included_mb"`    // 流量配额（MB），0表示不限制
+	OverageMB     float64 `json:"overage_mb"`     // 超出配额的用量（MB）
+	Amount        float64 `json:"amount"`         // 超额费用
+}
+
+// Service 流量计费报表服务
+type Service struct {
+	queryService *traffic.QueryService
+}
+
+// NewService 创建流量计费报表服务
+func NewService() *Service {
+	return &Service{queryService: traffic.NewQueryService()}
+}
+
+// ParsePeriod 解析YYYY-MM格式的账期，返回账期起止时间（本地时区，结束时间不含）
+func ParsePeriod(period string) (time.Time, time.Time, error) {
+	start, err := time.ParseInLocation("2006-01", period, time.Local)
+	if err != nil {
+		return time.Time{}, time.Time{}, common.NewError(common.CodeValidationError, "账期格式错误，应为YYYY-MM")
+	}
+	return start, start.AddDate(0, 1, 0), nil
+}
+
+// GenerateReport 生成指定账期的流量计费报表
+// 月度用量取自pmacct流量记录并按pmacct重启分段累加，计数器重置不会导致重复计算；
+// 账期按记录的年月划分，跨月的累积值不会计入相邻月份。entityID>0时只统计该用户或Provider
+func (s *Service) GenerateReport(scope, period string, entityID uint) (*Report, error) {
+	if scope != ScopeUser && scope != ScopeProvider {
+		return nil, common.NewError(common.CodeValidationError, "统计维度只能是user或provider")
+	}
+	start, end, err := ParsePeriod(period)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if start.After(now) {
+		return nil, common.NewError(common.CodeValidationError, "不能生成未来账期的报表")
+	}
+
+	cfg := global.APP_CONFIG.Billing
+	report := &Report{
+		Scope:       scope,
+		Period:      start.Format("2006-01"),
+		StartTime:   start,
+		EndTime:     end,
+		Partial:     now.Before(end),
+		Currency:    cfg.Currency,
+		PricePerGB:  cfg.UserOveragePricePerGB,
+		GeneratedAt: now,
+	}
+	if report.Currency == "" {
+		report.Currency = "CNY"
+	}
+	if scope == ScopeProvider {
+		report.PricePerGB = cfg.ProviderOveragePricePerGB
+	}
+
+	instances, err := s.billedInstances(start.Year(), int(start.Month()), scope, entityID)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make(map[uint]*ReportItem)
+	if entityID > 0 {
+		// 指定对象时即使当月无流量也输出一行
+		items[entityID] = &ReportItem{ID: entityID}
+	}
+	for i := 0; i < len(instances); i += instanceBatchSize {
+		batch := instances[i:min(i+instanceBatchSize, len(instances))]
+		ids := make([]uint, len(batch))
+		for j, inst := range batch {
+			ids[j] = inst.ID
+		}
+		stats, err := s.queryService.BatchGetInstancesMonthlyTraffic(ids, start.Year(), int(start.Month()))
+		if err != nil {
+			return nil, common.NewError(common.CodeDatabaseError, err.Error())
+		}
+		for _, inst := range batch {
+			key := inst.UserID
+			if scope == ScopeProvider {
+				key = inst.ProviderID
+			}
+			item, ok := items[key]
+			if !ok {
+				item = &ReportItem{ID: key}
+				items[key] = item
+			}
+			if stat := stats[inst.ID]; stat != nil && stat.TotalBytes > 0 {
+				item.InstanceCount++
+				item.RxBytes += stat.RxBytes
+				item.TxBytes += stat.TxBytes
+				item.UsageMB += stat.ActualUsageMB
+			}
+		}
+	}
+
+	if err := s.fillEntityInfo(scope, items); err != nil {
+		return nil, err
+	}
+
+	report.Items = make([]ReportItem, 0, len(items))
+	for _, item := range items {
+		item.UsageMB = round2(item.UsageMB)
+		if item.IncludedMB > 0 && item.UsageMB > float64(item.IncludedMB) {
+			item.OverageMB = round2(item.UsageMB - float64(item.IncludedMB))
+		}
+		item.Amount = round2(item.OverageMB / 1024 * report.PricePerGB)
+
+		report.Summary.InstanceCount += item.InstanceCount
+		report.Summary.RxBytes += item.RxBytes
+		report.Summary.TxBytes += item.TxBytes
+		report.Summary.UsageMB += item.UsageMB
+		report.Summary.OverageMB += item.OverageMB
+		report.Summary.Amount += item.Amount
+		report.Items = append(report.Items, *item)
+	}
+	report.Summary.UsageMB = round2(report.Summary.UsageMB)
+	report.Summary.OverageMB = round2(report.Summary.OverageMB)
+	report.Summary.Amount = round2(report.Summary.Amount)
+
+	// 费用高的排在前面，便于核对
+	sort.Slice(report.Items, func(i, j int) bool {
+		if report.Items[i].Amount != report.Items[j].Amount {
+			return report.Items[i].Amount > report.Items[j].Amount
+		}
+		if report.Items[i].UsageMB != report.Items[j].UsageMB {
+			return report.Items[i].UsageMB > report.Items[j].UsageMB
+		}
+		return report.Items[i].ID < report.Items[j].ID
+	})
+	return report, nil
+}
+
+// billedInstances 查询账期内有流量记录的实例，包含已删除的实例，以免删除实例后少计流量
+func (s *Service) billedInstances(year, month int, scope string, entityID uint) ([]providerModel.Instance, error) {
+	query := global.APP_DB.Unscoped().Model(&providerModel.Instance{}).
+		Select("id, user_id, provider_id").
+		Where("id IN (SELECT DISTINCT instance_id FROM pmacct_traffic_records WHERE year = ? AND month = ?)", year, month)
+	if entityID > 0 {
+		query = query.Where(scope+"_id = ?", entityID)
+	}
+
+	var instances []providerModel.Instance
+	if err := query.Order("id").Find(&instances).Error; err != nil {
+		return nil, common.NewError(common.CodeDatabaseError, fmt.Sprintf("查询计费实例失败: %v", err))
+	}
+	return instances, nil
+}
+
+// fillEntityInfo 填充用户或Provider的名称和流量配额
+func (s *Service) fillEntityInfo(scope string, items map[uint]*ReportItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+	ids := make([]uint, 0, len(items))
+	for id := range items {
+		ids = append(ids, id)
+	}
+
+	if scope == ScopeUser {
+		var users []userModel.User
+		if err := global.APP_DB.Unscoped().Select("id, username, level, total_traffic").
+			Where("id IN ?", ids).Find(&users).Error; err != nil {
+			return common.NewError(common.CodeDatabaseError, fmt.Sprintf("查询用户信息失败: %v", err))
+		}
+		for _, u := range users {
+			item := items[u.ID]
+			item.Name = u.Username
+			item.IncludedMB = u.TotalTraffic
+			// 与流量限制保持一致：未单独设置时使用等级默认配额
+			if item.IncludedMB == 0 {
+				if limits, ok := global.APP_CONFIG.Quota.LevelLimits[u.Level]; ok {
+					item.IncludedMB = limits.MaxTraffic
+				}
+			}
+		}
+		return nil
+	}
+
+	var providers []providerModel.Provider
+	if err := global.APP_DB.Unscoped().Select("id, name, enable_traffic_control, max_traffic").
+		Where("id IN ?", ids).Find(&providers).Error; err != nil {
+		return common.NewError(common.CodeDatabaseError, fmt.Sprintf("查询Provider信息失败: %v", err))
+	}
+	for _, p := range providers {
+		item := items[p.ID]
+		item.Name = p.Name
+		// 未启用流量控制的Provider没有配额，只统计用量不计超额费用
+		if p.EnableTrafficControl {
+			item.IncludedMB = p.MaxTraffic
+		}
+	}
+	return nil
+}
+
+// ExportCSV 将报表导出为CSV，最后一行为合计
+func (s *Service) ExportCSV(report *Report) ([]byte, error) {
+	var buf bytes.Buffer
+	// 写入BOM，避免Excel打开中文表头乱码
+	buf.WriteString("\xEF\xBB\xBF")
+	writer := csv.NewWriter(&buf)
+
+	idHeader, nameHeader := "用户ID", "用户名"
+	if report.Scope == ScopeProvider {
+		idHeader, nameHeader = "Provider ID", "Provider名称"
+	}
+	headers := []string{idHeader, nameHeader, "实例数", "入站流量(MB)", "出站流量(MB)", "计费用量(MB)",
+		"流量配额(MB)", "超额用量(MB)", "超额费用(" + report.Currency + ")"}
+	if err := writer.Write(headers); err != nil {
+		return nil, err
+	}
+
+	row := func(id, name string, item ReportItem) []string {
+		included := "不限"
+		if item.IncludedMB > 0 {
+			included = strconv.FormatInt(item.IncludedMB, 10)
+		}
+		return []string{
+			id,
+			name,
+			strconv.Itoa(item.InstanceCount),
+			formatMB(item.RxBytes),
+			formatMB(item.TxBytes),
+			strconv.FormatFloat(item.UsageMB, 'f', 2, 64),
+			included,
+			strconv.FormatFloat(item.OverageMB, 'f', 2, 64),
+			strconv.FormatFloat(item.Amount, 'f', 2, 64),
+		}
+	}
+	for _, item := range report.Items {
+		if err := writer.Write(row(strconv.FormatUint(uint64(item.ID), 10), item.Name, item)); err != nil {
+			return nil, err
+		}
+	}
+	summary := report.Summary
+	summary.IncludedMB = 0
+	total := row("合计", "", summary)
+	total[6] = ""
+	if err := writer.Write(total); err != nil {
+		return nil, err
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func formatMB(bytes int64) string {
+	return strconv.FormatFloat(float64(bytes)/1048576.0, 'f', 2, 64)
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -165,6 +165,10 @@ const (
 	// 实例流量详情缓存 - 2分钟
 	KeyInstanceTrafficDetail = "instance:traffic:detail:%d" // instanceID
 	TTLInstanceTrafficDetail = 2 * time.Minute
+
+	// 实例磁盘使用量缓存 - 5分钟（需要SSH到节点采集）
+	KeyInstanceDiskUsage = "instance:disk:usage:%d" // instanceID
+	TTLInstanceDiskUsage = 5 * time.Minute
 )
 
 // MakeUserDashboardKey 生成用户Dashboard缓存键
@@ -187,6 +191,11 @@ func MakeInstanceTrafficDetailKey(instanceID uint) string {
 	return fmt.Sprintf(KeyInstanceTrafficDetail, instanceID)
 }
 
+// MakeInstanceDiskUsageKey 生成实例磁盘使用量缓存键
+func MakeInstanceDiskUsageKey(instanceID uint) string {
+	return fmt.Sprintf(KeyInstanceDiskUsage, instanceID)
+}
+
 // InvalidateUserCache 使用户所有缓存失效
 func (s *UserCacheService) InvalidateUserCache(userID uint) {
 	// 删除Dashboard缓存
@@ -200,6 +209,7 @@ func (s *UserCacheService) InvalidateUserCache(userID uint) {
 // InvalidateInstanceCache 使实例缓存失效
 func (s *UserCacheService) InvalidateInstanceCache(instanceID uint) {
 	s.Delete(MakeInstanceTrafficDetailKey(instanceID))
+	s.Delete(MakeInstanceDiskUsageKey(instanceID))
 }
 
 // GetOrSet 获取缓存或执行函数并缓存结果
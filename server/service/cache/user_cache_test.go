@@ -163,4 +163,9 @@ func TestCacheKeys(t *testing.T) {
 	if instanceKey != "instance:traffic:detail:456" {
 		t.Errorf("实例流量键生成错误: %s", instanceKey)
 	}
+
+	diskKey := MakeInstanceDiskUsageKey(instanceID)
+	if diskKey != "instance:disk:usage:456" {
+		t.Errorf("实例磁盘使用量键生成错误: %s", diskKey)
+	}
 }
@@ -78,7 +78,7 @@ func (s *ImageDownloadService) DownloadImageForProvider(imageURL, imageName, pro
 func (s *ImageDownloadService) getDownloadDir(providerType string) string {
 	baseDir := "/usr/local/bin"
 	switch providerType {
-	case "docker":
+	case "docker", "podman":
 		return filepath.Join(baseDir, "docker_ct_images")
 	case "lxd":
 		return filepath.Join(baseDir, "lxd_images")
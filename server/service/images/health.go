@@ -6,6 +6,7 @@ import (
 	"oneclickvirt/global"
 	"oneclickvirt/model/provider"
 	"oneclickvirt/provider/health"
+	"oneclickvirt/utils"
 
 	"go.uber.org/zap"
 )
@@ -85,7 +86,7 @@ func (t *TokenConfigAdapter) GetTokenSecret() string {
 
 // CheckProviderHealthWithConfig 使用配置进行健康检查
 // 返回: sshStatus, apiStatus, hostName, error
-func CheckProviderHealthWithConfig(ctx context.Context, providerID uint, providerName, providerType, host, username, password, sshKey string, port int, authConfig *provider.ProviderAuthConfig) (string, string, string, error) {
+func CheckProviderHealthWithConfig(ctx context.Context, providerID uint, providerName, providerType string, sshConfig utils.SSHConfig, authConfig *provider.ProviderAuthConfig) (string, string, string, error) {
 	// 使用全局logger，如果没有则传nil
 	var logger *zap.Logger
 	if global.APP_LOG != nil {
@@ -94,5 +95,5 @@ func CheckProviderHealthWithConfig(ctx context.Context, providerID uint, provide
 
 	healthChecker := health.NewProviderHealthChecker(logger)
 	adapter := NewHealthConfigAdapter(authConfig)
-	return healthChecker.CheckProviderHealthWithAuthConfig(ctx, providerID, providerName, providerType, host, username, password, sshKey, port, adapter)
+	return healthChecker.CheckProviderHealthWithAuthConfig(ctx, providerID, providerName, providerType, sshConfig, adapter)
 }
@@ -135,7 +135,7 @@ func (s *ImageService) GetImageDownloadPath(providerType, instanceType string) s
 			return filepath.Join(baseDir, "incus_vm_images")
 		}
 		return filepath.Join(baseDir, "incus_container_images")
-	case "docker":
+	case "docker", "podman":
 		return filepath.Join(baseDir, "docker_images")
 	default:
 		return filepath.Join(baseDir, "images")
@@ -0,0 +1,152 @@
+package log
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"oneclickvirt/global"
+	adminModel "oneclickvirt/model/admin"
+	"oneclickvirt/model/common"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+)
+
+const (
+	auditQueueSize     = 1000            // 待写入审计日志队列长度，超出后丢弃
+	auditBatchSize     = 100             // 单次批量写入条数
+	auditFlushInterval = 1 * time.Second // 批量写入间隔
+)
+
+// AuditService 操作审计日志服务
+type AuditService struct{}
+
+var (
+	auditQueue     = make(chan *adminModel.AuditLog, auditQueueSize)
+	auditStartOnce sync.Once
+)
+
+// NewAuditService 创建审计日志服务
+func NewAuditService() *AuditService {
+	return &AuditService{}
+}
+
+// Record 异步记录一条审计日志，队列满时直接丢弃，避免阻塞请求
+func (s *AuditService) Record(entry *adminModel.AuditLog) {
+	auditStartOnce.Do(func() {
+		go runAuditWriter()
+	})
+
+	select {
+	case auditQueue <- entry:
+	default:
+		if global.APP_LOG != nil {
+			global.APP_LOG.Warn("审计日志队列已满，丢弃记录",
+				zap.String("action", entry.Action),
+				zap.String("target", entry.Target))
+		}
+	}
+}
+
+// runAuditWriter 按固定间隔批量写入审计日志，限制数据库写入频率
+func runAuditWriter() {
+	defer func() {
+		if r := recover(); r != nil && global.APP_LOG != nil {
+			global.APP_LOG.Error("审计日志写入协程panic", zap.Any("panic", r))
+		}
+	}()
+
+	ticker := time.NewTicker(auditFlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*adminModel.AuditLog, 0, auditBatchSize)
+	for {
+		select {
+		case entry := <-auditQueue:
+			batch = append(batch, entry)
+			if len(batch) >= auditBatchSize {
+				flushAuditLogs(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				flushAuditLogs(batch)
+				batch = batch[:0]
+			}
+		}
+	}
+}
+
+func flushAuditLogs(batch []*adminModel.AuditLog) {
+	if global.APP_DB == nil {
+		return
+	}
+	if err := global.APP_DB.CreateInBatches(batch, auditBatchSize).Error; err != nil && global.APP_LOG != nil {
+		global.APP_LOG.Error("写入审计日志失败",
+			zap.Int("count", len(batch)),
+			zap.String("error", utils.TruncateString(err.Error(), 200)))
+	}
+}
+
+// List 分页查询审计日志
+func (s *AuditService) List(req adminModel.AuditLogListRequest) ([]adminModel.AuditLog, int64, error) {
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 || req.PageSize > 200 {
+		req.PageSize = 20
+	}
+
+	db := global.APP_DB.Model(&adminModel.AuditLog{})
+	if req.UserID != nil {
+		db = db.Where("user_id = ?", *req.UserID)
+	}
+	if req.Username != "" {
+		db = db.Where("username LIKE ?", "%"+req.Username+"%")
+	}
+	if req.Action != "" {
+		db = db.Where("action LIKE ?", "%"+req.Action+"%")
+	}
+	if req.Method != "" {
+		db = db.Where("method = ?", strings.ToUpper(req.Method))
+	}
+	if req.Result != "" {
+		db = db.Where("result = ?", req.Result)
+	}
+	if req.StartTime != "" {
+		start, err := parseAuditTime(req.StartTime)
+		if err != nil {
+			return nil, 0, common.NewError(common.CodeValidationError, "开始时间格式错误")
+		}
+		db = db.Where("created_at >= ?", start)
+	}
+	if req.EndTime != "" {
+		end, err := parseAuditTime(req.EndTime)
+		if err != nil {
+			return nil, 0, common.NewError(common.CodeValidationError, "结束时间格式错误")
+		}
+		db = db.Where("created_at <= ?", end)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, common.NewError(common.CodeDatabaseError, "查询审计日志失败")
+	}
+
+	var logs []adminModel.AuditLog
+	if err := db.Order("id DESC").
+		Offset((req.Page - 1) * req.PageSize).
+		Limit(req.PageSize).
+		Find(&logs).Error; err != nil {
+		return nil, 0, common.NewError(common.CodeDatabaseError, "查询审计日志失败")
+	}
+	return logs, total, nil
+}
+
+func parseAuditTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02 15:04:05", value, time.Local)
+}
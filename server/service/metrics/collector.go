@@ -0,0 +1,154 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"oneclickvirt/global"
+	adminModel "oneclickvirt/model/admin"
+	providerModel "oneclickvirt/model/provider"
+	"oneclickvirt/service/traffic"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// trafficCacheTTL pmacct每5分钟采集一次，流量汇总查询较重，采集周期内复用结果
+const trafficCacheTTL = 5 * time.Minute
+
+var (
+	providerUpDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "provider", "up"),
+		"Whether the provider node is reachable over SSH (1) or not (0).",
+		[]string{"provider", "type"}, nil)
+	instancesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "provider", "instances"),
+		"Number of instances per provider by status.",
+		[]string{"provider", "status"}, nil)
+	trafficDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "provider", "traffic_month_bytes"),
+		"Traffic of all instances on the provider in the current month, aggregated from pmacct records.",
+		[]string{"provider", "direction"}, nil)
+	taskQueueDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "task", "queue_depth"),
+		"Number of unfinished tasks by status.",
+		[]string{"status"}, nil)
+)
+
+type providerTraffic struct {
+	rx int64
+	tx int64
+}
+
+// stateCollector 在每次抓取时从数据库读取实例、任务和流量状态
+// 所有指标按Provider聚合，不按实例打标签
+type stateCollector struct {
+	mu            sync.Mutex
+	trafficCache  map[string]providerTraffic
+	trafficCached time.Time
+}
+
+func newStateCollector() *stateCollector {
+	return &stateCollector{}
+}
+
+func (c *stateCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- providerUpDesc
+	ch <- instancesDesc
+	ch <- trafficDesc
+	ch <- taskQueueDesc
+}
+
+func (c *stateCollector) Collect(ch chan<- prometheus.Metric) {
+	if global.APP_DB == nil {
+		return
+	}
+
+	var providers []providerModel.Provider
+	if err := global.APP_DB.Select("id, name, type, ssh_status, enable_traffic_control").Find(&providers).Error; err != nil {
+		global.APP_LOG.Warn("采集Provider指标失败", zap.Error(err))
+		return
+	}
+
+	providerNames := make(map[uint]string, len(providers))
+	for _, p := range providers {
+		providerNames[p.ID] = p.Name
+		up := 0.0
+		if p.SSHStatus == "online" {
+			up = 1
+		}
+		ch <- prometheus.MustNewConstMetric(providerUpDesc, prometheus.GaugeValue, up, p.Name, p.Type)
+	}
+
+	var instanceCounts []struct {
+		ProviderID uint
+		Status     string
+		Count      int64
+	}
+	if err := global.APP_DB.Model(&providerModel.Instance{}).
+		Select("provider_id, status, COUNT(*) as count").
+		Group("provider_id, status").
+		Scan(&instanceCounts).Error; err != nil {
+		global.APP_LOG.Warn("采集实例指标失败", zap.Error(err))
+	} else {
+		for _, row := range instanceCounts {
+			name, ok := providerNames[row.ProviderID]
+			if !ok {
+				continue
+			}
+			ch <- prometheus.MustNewConstMetric(instancesDesc, prometheus.GaugeValue, float64(row.Count), name, row.Status)
+		}
+	}
+
+	var taskCounts []struct {
+		Status string
+		Count  int64
+	}
+	if err := global.APP_DB.Model(&adminModel.Task{}).
+		Select("status, COUNT(*) as count").
+		Where("status IN ?", []string{"pending", "processing", "running", "cancelling"}).
+		Group("status").
+		Scan(&taskCounts).Error; err != nil {
+		global.APP_LOG.Warn("采集任务指标失败", zap.Error(err))
+	} else {
+		for _, row := range taskCounts {
+			ch <- prometheus.MustNewConstMetric(taskQueueDesc, prometheus.GaugeValue, float64(row.Count), row.Status)
+		}
+	}
+
+	for name, t := range c.providerTraffic(providers) {
+		ch <- prometheus.MustNewConstMetric(trafficDesc, prometheus.GaugeValue, float64(t.rx), name, "rx")
+		ch <- prometheus.MustNewConstMetric(trafficDesc, prometheus.GaugeValue, float64(t.tx), name, "tx")
+	}
+}
+
+// providerTraffic 返回启用流量统计的Provider当月流量，结果缓存trafficCacheTTL
+func (c *stateCollector) providerTraffic(providers []providerModel.Provider) map[string]providerTraffic {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.trafficCache != nil && time.Since(c.trafficCached) < trafficCacheTTL {
+		return c.trafficCache
+	}
+
+	now := time.Now()
+	queryService := traffic.NewQueryService()
+	result := make(map[string]providerTraffic)
+	for _, p := range providers {
+		if !p.EnableTrafficControl {
+			continue
+		}
+		stats, err := queryService.GetProviderMonthlyTraffic(p.ID, now.Year(), int(now.Month()))
+		if err != nil {
+			global.APP_LOG.Warn("采集Provider流量指标失败",
+				zap.Uint("providerID", p.ID),
+				zap.Error(err))
+			continue
+		}
+		result[p.Name] = providerTraffic{rx: stats.RxBytes, tx: stats.TxBytes}
+	}
+
+	c.trafficCache = result
+	c.trafficCached = now
+	return result
+}
@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "oneclickvirt"
+
+var (
+	registry     *prometheus.Registry
+	registryOnce sync.Once
+
+	// sshCommandDuration SSH命令执行耗时，按Provider聚合，不按命令或实例打标签以控制基数
+	sshCommandDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "ssh_command_duration_seconds",
+		Help:      "Duration of SSH commands executed on provider nodes.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120},
+	}, []string{"provider", "result"})
+)
+
+// getRegistry 返回独立的指标注册表，避免与其他库注册到默认注册表的指标混在一起
+func getRegistry() *prometheus.Registry {
+	registryOnce.Do(func() {
+		registry = prometheus.NewRegistry()
+		registry.MustRegister(
+			collectors.NewGoCollector(),
+			collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+			sshCommandDuration,
+			newStateCollector(),
+		)
+	})
+	return registry
+}
+
+// Handler 返回Prometheus指标HTTP处理器
+func Handler() http.Handler {
+	return promhttp.HandlerFor(getRegistry(), promhttp.HandlerOpts{})
+}
+
+// ObserveSSHCommand 记录一次SSH命令的执行耗时
+func ObserveSSHCommand(providerName string, start time.Time, err error) {
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	sshCommandDuration.WithLabelValues(providerName, result).Observe(time.Since(start).Seconds())
+}
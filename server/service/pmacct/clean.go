@@ -53,6 +53,7 @@ func (s *Service) CleanupPmacctDataWithContext(ctx context.Context, instanceID u
 			return err
 		}
 
+		pushedTraffic.forget(instanceID)
 		global.APP_LOG.Info("pmacct监控配置清理完成（流量历史数据已保留）",
 			zap.Uint("instanceID", instanceID))
 
@@ -230,19 +231,8 @@ echo "=== pmacct 资源清理完成: %s ==="
 	// 通过SFTP上传清理脚本
 	scriptPath := fmt.Sprintf("/tmp/cleanup_pmacct_%s.sh", instanceName)
 
-	// 解析endpoint获取host和port
-	host, port := utils.ParseEndpoint(providerRecord.Endpoint, providerRecord.SSHPort)
-
 	// 从连接池获取SSH客户端
-	sshConfig := utils.SSHConfig{
-		Host:           host,
-		Port:           port,
-		Username:       providerRecord.Username,
-		Password:       providerRecord.Password,
-		PrivateKey:     providerRecord.SSHKey,
-		ConnectTimeout: 30 * time.Second,
-		ExecuteTimeout: 60 * time.Second,
-	}
+	sshConfig := utils.ProviderSSHConfig(&providerRecord, 30*time.Second, 60*time.Second)
 
 	sshClient, err := s.sshPool.GetOrCreate(providerID, sshConfig)
 	if err != nil {
@@ -415,6 +405,9 @@ func (s *Service) ResetPmacctDaemon(instanceID uint) error {
 		zap.Uint("instanceID", instanceID),
 		zap.String("instanceName", instance.Name))
 
+	// SQLite重置后累积值从0开始，下次采集全量写入
+	pushedTraffic.forget(instanceID)
+
 	// 步骤1: 停止pmacct守护进程
 	stopCmd := fmt.Sprintf(`
 # 停止pmacct守护进程
@@ -7,6 +7,7 @@ import (
 	monitoringModel "oneclickvirt/model/monitoring"
 	providerModel "oneclickvirt/model/provider"
 	providerService "oneclickvirt/service/provider"
+	"oneclickvirt/utils"
 	"strconv"
 	"strings"
 	"time"
@@ -15,11 +16,48 @@ import (
 	"gorm.io/gorm"
 )
 
+// sqliteQueryMaxOutput 流量累积值查询的SSH输出上限
+const sqliteQueryMaxOutput = 64 << 20
+
 // CollectTrafficFromSQLite 从远程 pmacct SQLite 数据库采集流量数据并导入系统数据库
 // 架构：Memory(1min) -> SQLite(local) -> MySQL(remote, dynamic interval)
 // 参数：预加载的instance和monitor数据
 // 策略：固定查询最近30分钟，MySQL自动去重累加
 func (s *Service) CollectTrafficFromSQLite(instance *providerModel.Instance, monitor *monitoringModel.PmacctMonitor) error {
+	err := s.collectTrafficFromSQLite(instance, monitor)
+	recordCollectResult(monitor, err)
+	return err
+}
+
+// recordCollectResult 记录采集结果，供管理员按流量采集错误筛选实例
+// 成功时仅在之前存在错误时才清空，避免每次采集都写库
+func recordCollectResult(monitor *monitoringModel.PmacctMonitor, collectErr error) {
+	if collectErr == nil {
+		if monitor.LastErrorAt == nil {
+			return
+		}
+		if err := global.APP_DB.Model(&monitoringModel.PmacctMonitor{}).Where("id = ?", monitor.ID).
+			Updates(map[string]interface{}{"last_error": "", "last_error_at": nil}).Error; err != nil {
+			global.APP_LOG.Warn("清除流量采集错误失败", zap.Uint("instanceID", monitor.InstanceID), zap.Error(err))
+			return
+		}
+		monitor.LastError = ""
+		monitor.LastErrorAt = nil
+		return
+	}
+
+	now := time.Now()
+	message := utils.TruncateString(collectErr.Error(), 255)
+	if err := global.APP_DB.Model(&monitoringModel.PmacctMonitor{}).Where("id = ?", monitor.ID).
+		Updates(map[string]interface{}{"last_error": message, "last_error_at": now}).Error; err != nil {
+		global.APP_LOG.Warn("记录流量采集错误失败", zap.Uint("instanceID", monitor.InstanceID), zap.Error(err))
+		return
+	}
+	monitor.LastError = message
+	monitor.LastErrorAt = &now
+}
+
+func (s *Service) collectTrafficFromSQLite(instance *providerModel.Instance, monitor *monitoringModel.PmacctMonitor) error {
 	instanceID := instance.ID
 
 	// 获取provider记录（用于验证和缓存刷新）
@@ -108,6 +146,13 @@ func (s *Service) CollectTrafficFromSQLite(instance *providerModel.Instance, mon
 	if queryIPv6 != "" {
 		ipList = append(ipList, queryIPv6)
 	}
+	// 额外网卡的静态IP流量同样计入实例
+	extraIPv4, extraIPv6 := extraInterfaceIPs(instance)
+	for _, ip := range append(extraIPv4, extraIPv6...) {
+		if ip != queryIPv4 && ip != queryIPv6 {
+			ipList = append(ipList, ip)
+		}
+	}
 
 	// 构建SQL IN子句
 	ipInClause := "'" + strings.Join(ipList, "','") + "'"
@@ -174,9 +219,10 @@ ORDER BY timestamp;
 		ipInClause, ipInClause,
 		ipInClause, ipInClause)
 
+	// 长时间未重置的数据库结果行较多，放宽SSH输出上限，避免截断导致累积值错误
 	ctx, cancel := context.WithTimeout(s.ctx, 30*time.Second)
 	defer cancel()
-	output, err := providerInstance.ExecuteSSHCommand(ctx, query)
+	output, err := providerInstance.ExecuteSSHCommand(utils.WithSSHOutputLimit(ctx, sqliteQueryMaxOutput), query)
 	if err != nil {
 		global.APP_LOG.Error("SQLite查询失败",
 			zap.Uint("instanceID", instanceID),
@@ -472,13 +518,15 @@ ORDER BY timestamp;
 	}
 
 	// 第二步：批量插入新采集的数据（每批独立事务，避免长时间锁表）
+	// 只写入累积值与上次采集不同的时间点，未变化的时间点upsert结果不变，跳过以减少数据库写入
+	recordsToWrite := pushedTraffic.changedRecords(instanceID, recordsToCreate)
 	batchSize := 50
-	for i := 0; i < len(recordsToCreate); i += batchSize {
+	for i := 0; i < len(recordsToWrite); i += batchSize {
 		end := i + batchSize
-		if end > len(recordsToCreate) {
-			end = len(recordsToCreate)
+		if end > len(recordsToWrite) {
+			end = len(recordsToWrite)
 		}
-		batch := recordsToCreate[i:end]
+		batch := recordsToWrite[i:end]
 
 		// 每批使用独立的短事务
 		err := global.APP_DB.Transaction(func(tx *gorm.DB) error {
@@ -547,6 +595,8 @@ ORDER BY timestamp;
 		}
 		imported += len(batch)
 	}
+	// 全部写入成功后才记录，失败时未写入的时间点下次仍会重试
+	pushedTraffic.remember(instanceID, recordsToCreate)
 
 	// 第三步：更新最后同步时间（独立小事务）
 	if err := global.APP_DB.Exec(
@@ -663,6 +713,7 @@ ORDER BY timestamp;
 	global.APP_LOG.Info("SQLite 流量数据采集完成",
 		zap.Uint("instanceID", instanceID),
 		zap.Int("records", imported),
+		zap.Int("unchanged", len(recordsToCreate)-len(recordsToWrite)),
 		zap.String("deduplication", "MySQL自动去重累加"),
 		zap.Time("lastSync", lastSync),
 		zap.String("currentSync", providerCurrentTimeStr))
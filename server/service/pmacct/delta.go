@@ -0,0 +1,63 @@
+package pmacct
+
+import (
+	"sync"
+	"time"
+
+	monitoringModel "oneclickvirt/model/monitoring"
+)
+
+// trafficPoint 某个时间点已写入数据库的累积值
+type trafficPoint struct {
+	rxBytes int64
+	txBytes int64
+}
+
+// pushedTrafficCache 记录每个实例上次采集写入的各时间点累积值
+// SQLite每次返回自上次重置以来的全部时间点，其中绝大多数与上次相同，
+// 只写入值有变化的时间点即可，避免每轮采集重复upsert整天的数据
+type pushedTrafficCache struct {
+	mu        sync.Mutex
+	instances map[uint]map[time.Time]trafficPoint
+}
+
+var pushedTraffic = &pushedTrafficCache{instances: make(map[uint]map[time.Time]trafficPoint)}
+
+// changedRecords 返回与上次写入值不同（或上次未写入）的记录
+// 按时间点逐一比较，计数器重置后时间点或累积值变化，对应记录仍会写入
+func (c *pushedTrafficCache) changedRecords(instanceID uint, records []monitoringModel.PmacctTrafficRecord) []monitoringModel.PmacctTrafficRecord {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	last := c.instances[instanceID]
+	if len(last) == 0 {
+		return records
+	}
+	changed := make([]monitoringModel.PmacctTrafficRecord, 0, len(records))
+	for _, record := range records {
+		point, ok := last[record.Timestamp]
+		if !ok || point.rxBytes != record.RxBytes || point.txBytes != record.TxBytes {
+			changed = append(changed, record)
+		}
+	}
+	return changed
+}
+
+// remember 记录本轮采集结果，只保留本轮返回的时间点，重置后消失的时间点随之清除
+func (c *pushedTrafficCache) remember(instanceID uint, records []monitoringModel.PmacctTrafficRecord) {
+	points := make(map[time.Time]trafficPoint, len(records))
+	for _, record := range records {
+		points[record.Timestamp] = trafficPoint{rxBytes: record.RxBytes, txBytes: record.TxBytes}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.instances[instanceID] = points
+}
+
+// forget 清除实例的记录，下次采集时全量写入
+func (c *pushedTrafficCache) forget(instanceID uint) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.instances, instanceID)
+}
@@ -152,18 +152,34 @@ func (s *Service) detectVethInterface(providerInstance provider.Provider, instan
 				zap.String("instance", instanceName),
 				zap.Error(err))
 		}
+	} else if providerType == "podman" {
+		// rootless模式下Provider会直接返回错误，由后续逻辑回退到主网络接口
+		if podmanProv, ok := providerInstance.(interface {
+			GetVethInterfaceName(context.Context, string) (string, error)
+		}); ok {
+			ctx, cancel := context.WithTimeout(s.ctx, 10*time.Second)
+			defer cancel()
+			vethName, err := podmanProv.GetVethInterfaceName(ctx, instanceName)
+			if err == nil && vethName != "" {
+				global.APP_LOG.Info("通过Podman Provider方法成功获取veth接口",
+					zap.String("instance", instanceName),
+					zap.String("veth", vethName))
+				return vethName, nil
+			}
+			return "", fmt.Errorf("Podman Provider获取veth接口失败: %w", err)
+		}
 	}
 
 	// 备用方法：通过进程和网络命名空间检测（适用于所有虚拟化类型）
 	var detectCmd string
-	if providerType == "docker" {
-		// Docker容器veth接口检测
+	if providerType == "docker" || providerType == "podman" {
+		// Docker/Podman容器veth接口检测
 		detectCmd = fmt.Sprintf(`
-# 检测Docker容器对应的veth接口
+# 检测Docker/Podman容器对应的veth接口
 CONTAINER_NAME='%s'
 
 # 1. 获取容器PID
-CONTAINER_PID=$(docker inspect -f '{{.State.Pid}}' "$CONTAINER_NAME" 2>/dev/null)
+CONTAINER_PID=$(%s inspect -f '{{.State.Pid}}' "$CONTAINER_NAME" 2>/dev/null)
 if [ -z "$CONTAINER_PID" ] || [ "$CONTAINER_PID" = "0" ]; then
     echo "ERROR: 容器未运行或PID为0" >&2
     exit 1
@@ -190,7 +206,7 @@ fi
 
 echo "ERROR: 无法找到有效的veth接口" >&2
 exit 1
-`, instanceName)
+`, instanceName, providerType)
 	} else if providerType == "lxd" || providerType == "incus" {
 		// LXD/Incus容器veth接口检测（备用方法）
 		cmd := "lxc"
@@ -449,6 +465,35 @@ exit 1
 type NetworkInterfaceInfo struct {
 	IPv4Interface string // IPv4流量监控的网络接口
 	IPv6Interface string // IPv6流量监控的网络接口（可能与IPv4相同或不同）
+
+	ExtraInterfaces []string // 额外网卡在宿主机侧的接口，与主接口一起监听
+}
+
+// extraInterfaceFinder 支持额外网卡的Provider实现的可选接口
+type extraInterfaceFinder interface {
+	ExtraInterfaceNames(ctx context.Context, instanceName string, count int) ([]string, error)
+}
+
+// detectExtraInterfaces 检测实例额外网卡在宿主机侧的接口，检测失败时只监控主接口
+func (s *Service) detectExtraInterfaces(providerInstance provider.Provider, instanceName string, instance *providerModel.Instance) []string {
+	if len(instance.NetworkInterfaces) == 0 {
+		return nil
+	}
+	finder, ok := providerInstance.(extraInterfaceFinder)
+	if !ok {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(s.ctx, 30*time.Second)
+	defer cancel()
+	names, err := finder.ExtraInterfaceNames(ctx, instanceName, len(instance.NetworkInterfaces))
+	if err != nil {
+		global.APP_LOG.Warn("检测额外网卡接口失败，仅监控主网络接口",
+			zap.String("instance", instanceName),
+			zap.Error(err))
+		return nil
+	}
+	return names
 }
 
 // detectNetworkInterfaces 检测支持IPv4和IPv6的网络接口
@@ -473,6 +518,17 @@ func (s *Service) detectNetworkInterfaces(providerInstance provider.Provider, in
 			zap.String("interfaceV6", info.IPv6Interface))
 	}
 
+	// 额外网卡接口在实例每次启动时可能变化，不缓存到数据库
+	info.ExtraInterfaces = s.detectExtraInterfaces(providerInstance, instanceName, instance)
+
+	// 管理员手动指定了接口时不再自动检测，未指定IPv6接口时与IPv4共用
+	if instance.PmacctInterfaceManual && info.IPv4Interface != "" {
+		if hasIPv6 && info.IPv6Interface == "" {
+			info.IPv6Interface = info.IPv4Interface
+		}
+		return info, nil
+	}
+
 	// 如果数据库中已有完整的接口信息，直接返回
 	if info.IPv4Interface != "" && (!hasIPv6 || info.IPv6Interface != "") {
 		return info, nil
@@ -484,7 +540,7 @@ func (s *Service) detectNetworkInterfaces(providerInstance provider.Provider, in
 		zap.Bool("hasIPv6", hasIPv6))
 
 	// Docker/LXD/Incus 容器: 优先检测veth接口
-	if providerType == "docker" || providerType == "lxd" || providerType == "incus" {
+	if providerType == "docker" || providerType == "podman" || providerType == "lxd" || providerType == "incus" {
 		// 尝试检测veth接口
 		vethInterface, err := s.detectVethInterface(providerInstance, instanceName)
 		if err != nil {
@@ -77,6 +77,13 @@ func (s *Service) InitializePmacctForInstance(instanceID uint) error {
 		return nil
 	}
 
+	if !instance.EnableMonitoring {
+		global.APP_LOG.Debug("实例已关闭流量监控，跳过pmacct监控初始化",
+			zap.Uint("instanceID", instanceID),
+			zap.String("instanceName", instance.Name))
+		return nil
+	}
+
 	// 获取provider实例
 	providerInstance, exists := providerService.GetProviderService().GetProviderByID(instance.ProviderID)
 	if !exists {
@@ -351,7 +358,7 @@ systemctl stop pmacct 2>/dev/null || service pmacct stop 2>/dev/null || rc-servi
 systemctl disable pmacct 2>/dev/null || chkconfig pmacct off 2>/dev/null || rc-update del pmacct 2>/dev/null || true
 `
 
-	installCtx, installCancel := context.WithTimeout(s.ctx, 5*time.Minute)
+	installCtx, installCancel := context.WithTimeout(utils.WithSSHOperation(s.ctx, utils.SSHOperationInstall), utils.SSHOperationTimeout(utils.SSHOperationInstall))
 	defer installCancel()
 
 	output, err = providerInstance.ExecuteSSHCommand(installCtx, installCmd)
@@ -481,6 +488,17 @@ func (s *Service) configurePmacctForIPs(providerInstance provider.Provider, inst
 	configFile := fmt.Sprintf("%s/pmacctd.conf", configDir)
 	dataFile := fmt.Sprintf("%s/traffic.db", configDir)
 
+	// 有额外网卡时通过接口映射文件同时监听所有接口
+	interfaceDirective := fmt.Sprintf("pcap_interface: %s", networkInterface)
+	var interfacesMap string
+	if len(networkInterfaces.ExtraInterfaces) > 0 {
+		interfacesMap = buildPcapInterfacesMap(append([]string{networkInterface}, networkInterfaces.ExtraInterfaces...))
+		interfaceDirective = fmt.Sprintf("pcap_interfaces_map: %s/interfaces.map", configDir)
+	}
+
+	// 额外网卡的静态IP一并加入BPF过滤器
+	extraIPv4, extraIPv6 := extraInterfaceIPs(&instance)
+
 	// 构建监控信息
 	monitorInfo := ""
 	if publicIPv4 != "" && publicIPv6 != "" {
@@ -505,14 +523,16 @@ func (s *Service) configurePmacctForIPs(providerInstance provider.Provider, inst
 		"(dst host 255.255.255.255) or " +
 		"(src net 169.254.0.0/16 or dst net 169.254.0.0/16))"
 
-	if bpfIPv4 != "" && bpfIPv6 != "" {
+	hostIPv4 := bpfHostExpr(bpfIPv4, extraIPv4)
+	hostIPv6 := bpfHostExpr(bpfIPv6, extraIPv6)
+	if hostIPv4 != "" && hostIPv6 != "" {
 		bpfFilter = fmt.Sprintf(
-			"(host %s and %s) or (host %s)",
-			bpfIPv4, internalNetFilter, bpfIPv6)
-	} else if bpfIPv4 != "" {
-		bpfFilter = fmt.Sprintf("host %s and %s", bpfIPv4, internalNetFilter)
-	} else if bpfIPv6 != "" {
-		bpfFilter = fmt.Sprintf("host %s", bpfIPv6)
+			"(%s and %s) or (%s)",
+			hostIPv4, internalNetFilter, hostIPv6)
+	} else if hostIPv4 != "" {
+		bpfFilter = fmt.Sprintf("%s and %s", hostIPv4, internalNetFilter)
+	} else if hostIPv6 != "" {
+		bpfFilter = hostIPv6
 	} else {
 		bpfFilter = internalNetFilter
 		global.APP_LOG.Warn("BPF过滤器未指定监控IP，将捕获所有非内网流量",
@@ -531,7 +551,7 @@ pidfile: %s/pmacctd.pid
 syslog: daemon
 
 # 监听的网络接口
-pcap_interface: %s
+%s
 
 # BPF过滤器：捕获外部流量，排除内网通信（10.x, 172.16-31.x, 192.168.x, 224.x多播, 255.255.255.255广播）
 pcap_filter: %s
@@ -563,7 +583,7 @@ sql_cache_entries[sqlite]: %d
 plugin_buffer_size[sqlite]: %d
 # 插件管道大小（字节）
 plugin_pipe_size[sqlite]: %d
-`, instanceName, monitorInfo, instance.Bandwidth, configDir, networkInterface,
+`, instanceName, monitorInfo, instance.Bandwidth, configDir, interfaceDirective,
 		bpfFilter,
 		dataFile,
 		sqlCacheEntries, pluginBufferSize, pluginPipeSize)
@@ -599,6 +619,11 @@ WantedBy=multi-user.target
 	if err := s.uploadFileViaSFTP(providerInstance, config, configFile, 0644); err != nil {
 		return fmt.Errorf("failed to upload pmacct config file: %w", err)
 	}
+	if interfacesMap != "" {
+		if err := s.uploadFileViaSFTP(providerInstance, interfacesMap, configDir+"/interfaces.map", 0644); err != nil {
+			return fmt.Errorf("failed to upload pmacct interfaces map: %w", err)
+		}
+	}
 
 	// 步骤3: 初始化SQLite数据库表结构
 	// pmacct不会自动创建表，需要手动创建acct_v9表
@@ -1062,7 +1087,10 @@ func (s *Service) updateInstanceNetworkInterfaces(instanceName, ipv4Interface, i
 	}
 
 	if len(updateData) > 0 {
-		if err := global.APP_DB.Model(&providerModel.Instance{}).Where("name = ?", instanceName).Updates(updateData).Error; err != nil {
+		// 手动指定的接口不被检测结果覆盖
+		if err := global.APP_DB.Model(&providerModel.Instance{}).
+			Where("name = ? AND pmacct_interface_manual = ?", instanceName, false).
+			Updates(updateData).Error; err != nil {
 			global.APP_LOG.Warn("更新实例网络接口信息失败",
 				zap.String("instance", instanceName),
 				zap.Error(err))
@@ -68,19 +68,8 @@ func (s *Service) uploadFileViaSFTP(providerInstance provider.Provider, content,
 		return fmt.Errorf("failed to find provider: %w", err)
 	}
 
-	// 解析endpoint获取host和port
-	host, port := utils.ParseEndpoint(providerRecord.Endpoint, providerRecord.SSHPort)
-
 	// 从连接池获取或创建SSH客户端
-	sshConfig := utils.SSHConfig{
-		Host:           host,
-		Port:           port,
-		Username:       providerRecord.Username,
-		Password:       providerRecord.Password,
-		PrivateKey:     providerRecord.SSHKey,
-		ConnectTimeout: 30 * time.Second,
-		ExecuteTimeout: 60 * time.Second,
-	}
+	sshConfig := utils.ProviderSSHConfig(&providerRecord, 30*time.Second, 60*time.Second)
 
 	sshClient, err := s.sshPool.GetOrCreate(s.providerID, sshConfig)
 	if err != nil {
@@ -549,3 +538,52 @@ func (s *Service) getAggregatedHistory(instanceID uint, days int) []*monitoringM
 	global.APP_DB.Raw(query, instanceID, instanceID, instanceID, instanceID, days).Scan(&records)
 	return records
 }
+
+// buildPcapInterfacesMap 生成pcap_interfaces_map文件内容，ifindex从1开始按顺序编号
+func buildPcapInterfacesMap(interfaces []string) string {
+	var sb strings.Builder
+	for idx, name := range interfaces {
+		sb.WriteString(fmt.Sprintf("ifindex=%d ifname=%s\n", idx+1, name))
+	}
+	return sb.String()
+}
+
+// extraInterfaceIPs 返回实例额外网卡上指定的静态IPv4/IPv6地址（不含前缀长度）
+// 由网络自动分配的地址无法预先得知，不在此列
+func extraInterfaceIPs(instance *providerModel.Instance) (ipv4 []string, ipv6 []string) {
+	for _, nic := range instance.NetworkInterfaces {
+		if nic.IPAddress == "" {
+			continue
+		}
+		ip, _, err := provider.SplitInterfaceAddress(nic.IPAddress)
+		if err != nil {
+			continue
+		}
+		if ip.To4() != nil {
+			ipv4 = append(ipv4, ip.String())
+		} else {
+			ipv6 = append(ipv6, ip.String())
+		}
+	}
+	return ipv4, ipv6
+}
+
+// bpfHostExpr 生成匹配主IP及额外IP的BPF表达式，多个地址时用括号包裹
+func bpfHostExpr(primary string, extra []string) string {
+	var hosts []string
+	if primary != "" {
+		hosts = append(hosts, "host "+primary)
+	}
+	for _, ip := range extra {
+		if ip != primary {
+			hosts = append(hosts, "host "+ip)
+		}
+	}
+	switch len(hosts) {
+	case 0:
+		return ""
+	case 1:
+		return hosts[0]
+	}
+	return "(" + strings.Join(hosts, " or ") + ")"
+}
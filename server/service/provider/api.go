@@ -3,6 +3,7 @@ package provider
 import (
 	"context"
 	"fmt"
+	"io"
 	"oneclickvirt/service/images"
 	"oneclickvirt/service/resources"
 
@@ -144,6 +145,12 @@ func (s *ProviderApiService) CreateInstanceByProviderID(ctx context.Context, pro
 	}
 
 	config := req.InstanceConfig
+	// 未指定的CPU、内存、磁盘使用节点默认限制，避免创建不受限制的实例
+	provider.ApplyDefaultLimits(&config, provider.DefaultLimits{
+		CPU:      dbProvider.DefaultCPU,
+		MemoryMB: dbProvider.DefaultMemory,
+		DiskMB:   dbProvider.DefaultDisk,
+	})
 
 	// 验证Provider类型和实例类型兼容性
 	resourceService := &resources.ResourceService{}
@@ -259,6 +266,150 @@ func (s *ProviderApiService) RestartInstanceByProviderID(ctx context.Context, pr
 	return nil
 }
 
+// SetBandwidthLimitByProviderID 根据Provider ID设置实例带宽限速，uploadMbps/downloadMbps小于等于0表示该方向不限速
+func (s *ProviderApiService) SetBandwidthLimitByProviderID(ctx context.Context, providerID uint, instanceID string, uploadMbps, downloadMbps int) error {
+	prov, _, err := s.GetProviderByID(providerID)
+	if err != nil {
+		return err
+	}
+
+	if err := CheckProviderConnection(prov); err != nil {
+		return err
+	}
+
+	limiter, ok := prov.(interface {
+		SetBandwidthLimit(ctx context.Context, instanceName string, uploadMbps, downloadMbps int) error
+	})
+	if !ok {
+		return fmt.Errorf("Provider类型 %s 不支持设置带宽限速", prov.GetType())
+	}
+
+	if err := limiter.SetBandwidthLimit(ctx, instanceID, uploadMbps, downloadMbps); err != nil {
+		global.APP_LOG.Error("设置实例带宽限速失败",
+			zap.Uint("providerId", providerID),
+			zap.String("instanceId", instanceID),
+			zap.Error(err))
+		return fmt.Errorf("设置带宽限速失败: %v", err)
+	}
+	return nil
+}
+
+// ManageFirewallRuleByProviderID 根据Provider ID添加或删除实例防火墙规则
+func (s *ProviderApiService) ManageFirewallRuleByProviderID(ctx context.Context, providerID uint, instanceID string, rule provider.FirewallRule) error {
+	manager, err := s.getFirewallManager(providerID)
+	if err != nil {
+		return err
+	}
+
+	if err := manager.ManageFirewallRule(ctx, instanceID, rule); err != nil {
+		global.APP_LOG.Error("更新实例防火墙规则失败",
+			zap.Uint("providerId", providerID),
+			zap.String("instanceId", instanceID),
+			zap.Error(err))
+		return fmt.Errorf("更新防火墙规则失败: %v", err)
+	}
+	return nil
+}
+
+// ClearFirewallRulesByProviderID 根据Provider ID删除实例的全部防火墙规则
+func (s *ProviderApiService) ClearFirewallRulesByProviderID(ctx context.Context, providerID uint, instanceID string) error {
+	manager, err := s.getFirewallManager(providerID)
+	if err != nil {
+		return err
+	}
+	return manager.ClearFirewallRules(ctx, instanceID)
+}
+
+type firewallManager interface {
+	ManageFirewallRule(ctx context.Context, instanceName string, rule provider.FirewallRule) error
+	ClearFirewallRules(ctx context.Context, instanceName string) error
+}
+
+func (s *ProviderApiService) getFirewallManager(providerID uint) (firewallManager, error) {
+	prov, _, err := s.GetProviderByID(providerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := CheckProviderConnection(prov); err != nil {
+		return nil, err
+	}
+
+	manager, ok := prov.(firewallManager)
+	if !ok {
+		return nil, fmt.Errorf("Provider类型 %s 不支持防火墙规则", prov.GetType())
+	}
+	return manager, nil
+}
+
+// BackupInstanceByProviderID 根据Provider ID导出实例备份并写入dest
+func (s *ProviderApiService) BackupInstanceByProviderID(ctx context.Context, providerID uint, instanceID string, dest io.Writer) (*provider.BackupArchive, error) {
+	manager, err := s.getBackupManager(providerID)
+	if err != nil {
+		return nil, err
+	}
+	return manager.BackupInstance(ctx, instanceID, dest)
+}
+
+// RestoreFromBackupByProviderID 根据Provider ID使用src中的备份恢复实例
+func (s *ProviderApiService) RestoreFromBackupByProviderID(ctx context.Context, providerID uint, instanceID, fileName string, src io.Reader) error {
+	manager, err := s.getBackupManager(providerID)
+	if err != nil {
+		return err
+	}
+	return manager.RestoreFromBackup(ctx, instanceID, fileName, src)
+}
+
+type backupManager interface {
+	BackupInstance(ctx context.Context, instanceName string, dest io.Writer) (*provider.BackupArchive, error)
+	RestoreFromBackup(ctx context.Context, instanceName, fileName string, src io.Reader) error
+}
+
+func (s *ProviderApiService) getBackupManager(providerID uint) (backupManager, error) {
+	prov, _, err := s.GetProviderByID(providerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := CheckProviderConnection(prov); err != nil {
+		return nil, err
+	}
+
+	manager, ok := prov.(backupManager)
+	if !ok {
+		return nil, fmt.Errorf("Provider类型 %s 不支持实例备份", prov.GetType())
+	}
+	return manager, nil
+}
+
+// RecreateInstanceByProviderID 根据Provider ID按创建配置重建实例，保留实例的数据卷
+func (s *ProviderApiService) RecreateInstanceByProviderID(ctx context.Context, providerID uint, config provider.InstanceConfig) error {
+	prov, _, err := s.GetProviderByID(providerID)
+	if err != nil {
+		return err
+	}
+
+	if err := CheckProviderConnection(prov); err != nil {
+		return err
+	}
+
+	recreator, ok := prov.(interface {
+		RecreateInstance(ctx context.Context, config provider.InstanceConfig) error
+	})
+	if !ok {
+		return fmt.Errorf("Provider类型 %s 不支持重建实例", prov.GetType())
+	}
+
+	if err := recreator.RecreateInstance(ctx, config); err != nil {
+		global.APP_LOG.Error("重建实例失败",
+			zap.Uint("providerId", providerID),
+			zap.String("instanceName", config.Name),
+			zap.Error(err))
+		return fmt.Errorf("重建实例失败: %v", err)
+	}
+	return nil
+}
+
 // DeleteInstanceByProviderID 根据Provider ID删除实例（确保使用正确的Provider）
 func (s *ProviderApiService) DeleteInstanceByProviderID(ctx context.Context, providerID uint, instanceID string) error {
 	// 使用新的GetProviderByID方法
@@ -86,7 +86,7 @@ func (s *ProviderApiService) GetProviderStatusByID(providerIDStr string) (map[st
 	// Docker 类型固定使用 native 端口映射方式
 	ipv4Method := dbProvider.IPv4PortMappingMethod
 	ipv6Method := dbProvider.IPv6PortMappingMethod
-	if dbProvider.Type == "docker" {
+	if dbProvider.Type == "docker" || dbProvider.Type == "podman" {
 		ipv4Method = "native"
 		ipv6Method = "native"
 	}
@@ -163,7 +163,7 @@ func (s *ProviderApiService) GetProviderCapabilitiesByID(providerIDStr string) (
 	// Docker 类型固定使用 native 端口映射方式
 	ipv4Method := dbProvider.IPv4PortMappingMethod
 	ipv6Method := dbProvider.IPv6PortMappingMethod
-	if dbProvider.Type == "docker" {
+	if dbProvider.Type == "docker" || dbProvider.Type == "podman" {
 		ipv4Method = "native"
 		ipv6Method = "native"
 	}
@@ -172,7 +172,10 @@ func (s *ProviderApiService) GetProviderCapabilitiesByID(providerIDStr string) (
 	providerService := GetProviderService()
 	var supportedTypes []string
 
-	if prov, exists := providerService.GetProviderByID(dbProvider.ID); exists && prov.IsConnected() {
+	caps := providerService.GetProviderCapabilities(context.Background(), dbProvider.ID)
+	if caps != nil {
+		supportedTypes = caps.SupportedInstanceTypes()
+	} else if prov, exists := providerService.GetProviderByID(dbProvider.ID); exists && prov.IsConnected() {
 		supportedTypes = prov.GetSupportedInstanceTypes()
 	} else {
 		// 根据配置返回支持的实例类型
@@ -209,32 +212,36 @@ func (s *ProviderApiService) GetProviderCapabilitiesByID(providerIDStr string) (
 		"maxTraffic":        dbProvider.MaxTraffic,
 		"trafficCountMode":  dbProvider.TrafficCountMode,
 		"trafficMultiplier": dbProvider.TrafficMultiplier,
+		// 探测到的节点能力，Provider未连接时为null
+		"capabilities": caps,
 	}
 
 	return capabilities, nil
 }
 
 // ListInstancesByProviderID 根据Provider ID获取实例列表
-func (s *ProviderApiService) ListInstancesByProviderID(ctx context.Context, providerIDStr string) ([]provider.Instance, error) {
+// 默认使用数据库中缓存的网络信息，缓存缺失、过期或refresh为true时通过Provider实时查询
+func (s *ProviderApiService) ListInstancesByProviderID(ctx context.Context, providerIDStr string, refresh bool) ([]provider.Instance, error) {
 	providerID, err := parseProviderID(providerIDStr)
 	if err != nil {
 		return nil, err
 	}
 
-	prov, _, err := s.GetProviderByID(providerID)
-	if err != nil {
-		return nil, err
-	}
-
-	instances, err := prov.ListInstances(ctx)
-	if err != nil {
-		global.APP_LOG.Error("获取实例列表失败",
-			zap.Uint("providerId", providerID),
-			zap.Error(err))
-		return nil, fmt.Errorf("获取实例列表失败: %v", err)
+	if !refresh {
+		var dbProvider providerModel.Provider
+		if err := global.APP_DB.Select("id").First(&dbProvider, providerID).Error; err != nil {
+			return nil, fmt.Errorf("Provider不存在")
+		}
+		instances, fresh, err := listCachedInstances(providerID)
+		if err != nil {
+			return nil, err
+		}
+		if fresh {
+			return instances, nil
+		}
 	}
 
-	return instances, nil
+	return s.liveListInstances(ctx, providerID)
 }
 
 // CreateInstanceByProviderIDFromString 根据字符串Provider ID创建实例
@@ -248,12 +255,19 @@ func (s *ProviderApiService) CreateInstanceByProviderIDFromString(ctx context.Co
 }
 
 // GetInstanceByProviderID 根据Provider ID获取实例详情
-func (s *ProviderApiService) GetInstanceByProviderID(ctx context.Context, providerIDStr string, instanceName string) (interface{}, error) {
+// 默认使用数据库中缓存的网络信息，缓存缺失、过期或refresh为true时通过Provider实时查询
+func (s *ProviderApiService) GetInstanceByProviderID(ctx context.Context, providerIDStr string, instanceName string, refresh bool) (interface{}, error) {
 	providerID, err := parseProviderID(providerIDStr)
 	if err != nil {
 		return nil, err
 	}
 
+	if !refresh {
+		if instance, fresh := getCachedInstance(providerID, instanceName); fresh {
+			return instance, nil
+		}
+	}
+
 	prov, _, err := s.GetProviderByID(providerID)
 	if err != nil {
 		return nil, err
@@ -272,6 +286,7 @@ func (s *ProviderApiService) GetInstanceByProviderID(ctx context.Context, provid
 		return nil, fmt.Errorf("实例不存在")
 	}
 
+	saveNetworkInfo(providerID, []provider.Instance{*instance})
 	return instance, nil
 }
 
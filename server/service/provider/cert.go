@@ -457,16 +457,7 @@ func (cs *CertService) autoConfigureProxmoxWithStream(provider *provider.Provide
 }
 
 func (cs *CertService) executeScriptViaSFTP(provider *provider.Provider, script, filename string) error {
-	host, port := utils.ParseEndpoint(provider.Endpoint, provider.SSHPort)
-	sshConfig := utils.SSHConfig{
-		Host:           host,
-		Port:           port,
-		Username:       provider.Username,
-		Password:       provider.Password,
-		PrivateKey:     provider.SSHKey,
-		ConnectTimeout: 10 * time.Second,
-		ExecuteTimeout: 300 * time.Second,
-	}
+	sshConfig := utils.ProviderSSHConfig(provider, 10*time.Second, 300*time.Second)
 
 	sshClient, err := utils.NewSSHClient(sshConfig)
 	if err != nil {
@@ -493,16 +484,7 @@ func (cs *CertService) executeScriptViaSFTP(provider *provider.Provider, script,
 }
 
 func (cs *CertService) executeScriptViaSFTPWithStream(provider *provider.Provider, script, filename string, outputChan chan<- string) error {
-	host, port := utils.ParseEndpoint(provider.Endpoint, provider.SSHPort)
-	sshConfig := utils.SSHConfig{
-		Host:           host,
-		Port:           port,
-		Username:       provider.Username,
-		Password:       provider.Password,
-		PrivateKey:     provider.SSHKey,
-		ConnectTimeout: 10 * time.Second,
-		ExecuteTimeout: 300 * time.Second,
-	}
+	sshConfig := utils.ProviderSSHConfig(provider, 10*time.Second, 300*time.Second)
 
 	sshClient, err := utils.NewSSHClient(sshConfig)
 	if err != nil {
@@ -863,16 +845,7 @@ echo "=== Proxmox VE 配置完成 ==="
 }
 
 func (cs *CertService) getProxmoxTokenFromRemote(provider *provider.Provider, username, tokenId string) (*TokenInfo, error) {
-	host, port := utils.ParseEndpoint(provider.Endpoint, provider.SSHPort)
-	sshConfig := utils.SSHConfig{
-		Host:           host,
-		Port:           port,
-		Username:       provider.Username,
-		Password:       provider.Password,
-		PrivateKey:     provider.SSHKey,
-		ConnectTimeout: 12 * time.Second,
-		ExecuteTimeout: 60 * time.Second,
-	}
+	sshConfig := utils.ProviderSSHConfig(provider, 12*time.Second, 60*time.Second)
 
 	sshClient, err := utils.NewSSHClient(sshConfig)
 	if err != nil {
@@ -0,0 +1,64 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	providerModel "oneclickvirt/model/provider"
+	"oneclickvirt/provider"
+	"oneclickvirt/service/cache"
+)
+
+// diskUsageTimeout 单次采集实例磁盘使用量的超时时间
+const diskUsageTimeout = 30 * time.Second
+
+// GetInstanceDiskUsage 获取实例根磁盘使用情况，结果缓存 cache.TTLInstanceDiskUsage
+// 使用率按Provider返回的总量与实例配置磁盘大小中的较小值计算，Docker等无法获取配额的节点以实例配置为准
+func GetInstanceDiskUsage(instance providerModel.Instance) (*providerModel.InstanceDiskUsage, error) {
+	data, err := cache.GetUserCacheService().GetOrSet(cache.MakeInstanceDiskUsageKey(instance.ID), cache.TTLInstanceDiskUsage, func() (interface{}, error) {
+		return sampleInstanceDiskUsage(instance)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return data.(*providerModel.InstanceDiskUsage), nil
+}
+
+// sampleInstanceDiskUsage 通过Provider采集实例磁盘使用量
+func sampleInstanceDiskUsage(instance providerModel.Instance) (*providerModel.InstanceDiskUsage, error) {
+	if instance.Status != "running" {
+		return nil, fmt.Errorf("实例未运行，无法获取磁盘使用量")
+	}
+	prov, err := GetProviderInstanceByID(instance.ProviderID)
+	if err != nil {
+		return nil, fmt.Errorf("获取Provider实例失败: %w", err)
+	}
+	getter, ok := prov.(interface {
+		GetInstanceDiskUsage(ctx context.Context, instanceName string) (*provider.DiskUsage, error)
+	})
+	if !ok {
+		return nil, fmt.Errorf("Provider类型 %s 不支持获取磁盘使用量", prov.GetType())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), diskUsageTimeout)
+	defer cancel()
+	raw, err := getter.GetInstanceDiskUsage(ctx, instance.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	total := raw.TotalBytes
+	if limit := instance.Disk * 1024 * 1024; limit > 0 && (total == 0 || limit < total) {
+		total = limit
+	}
+	usage := &providerModel.InstanceDiskUsage{
+		UsedBytes:  raw.UsedBytes,
+		TotalBytes: total,
+		SampledAt:  time.Now(),
+	}
+	if total > 0 {
+		usage.Percent = float64(raw.UsedBytes) * 100 / float64(total)
+	}
+	return usage, nil
+}
@@ -0,0 +1,138 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"oneclickvirt/global"
+	providerModel "oneclickvirt/model/provider"
+	"oneclickvirt/provider"
+
+	"go.uber.org/zap"
+)
+
+// networkInfoCacheTTL 实例网络信息缓存有效期，超过后列表和详情回退到SSH实时查询
+const networkInfoCacheTTL = 30 * time.Minute
+
+// networkInfoFresh 判断数据库中缓存的实例网络信息是否可直接使用
+// 非运行状态的实例不需要网络信息，视为有效
+func networkInfoFresh(instance providerModel.Instance, now time.Time) bool {
+	if instance.Status != "running" {
+		return true
+	}
+	if instance.PrivateIP == "" || instance.NetworkSyncedAt == nil {
+		return false
+	}
+	return now.Sub(*instance.NetworkSyncedAt) < networkInfoCacheTTL
+}
+
+// cachedInstance 将数据库中的实例记录转换为Provider实例格式
+func cachedInstance(instance providerModel.Instance) provider.Instance {
+	result := provider.Instance{
+		ID:          instance.Name,
+		Name:        instance.Name,
+		Status:      instance.Status,
+		Type:        instance.InstanceType,
+		Image:       instance.Image,
+		IP:          instance.PrivateIP,
+		PrivateIP:   instance.PrivateIP,
+		PublicIP:    instance.PublicIP,
+		IPv6Address: instance.IPv6Address,
+		CPU:         fmt.Sprintf("%d", instance.CPU),
+		Memory:      fmt.Sprintf("%dMB", instance.Memory),
+		Disk:        fmt.Sprintf("%dMB", instance.Disk),
+		Created:     instance.CreatedAt,
+		Metadata:    map[string]string{"source": "cache"},
+	}
+	if instance.VethInterface != "" {
+		result.Metadata["network_interface"] = instance.VethInterface
+	}
+	if instance.NetworkSyncedAt != nil {
+		result.Metadata["network_synced_at"] = instance.NetworkSyncedAt.Format(time.RFC3339)
+	}
+	return result
+}
+
+// listCachedInstances 从数据库读取Provider下的实例，任一运行中实例的网络信息缺失或过期时返回false
+func listCachedInstances(providerID uint) ([]provider.Instance, bool, error) {
+	var instances []providerModel.Instance
+	if err := global.APP_DB.Where("provider_id = ?", providerID).Order("id ASC").Find(&instances).Error; err != nil {
+		return nil, false, fmt.Errorf("查询实例缓存失败: %v", err)
+	}
+
+	now := time.Now()
+	result := make([]provider.Instance, 0, len(instances))
+	for _, instance := range instances {
+		if !networkInfoFresh(instance, now) {
+			return nil, false, nil
+		}
+		result = append(result, cachedInstance(instance))
+	}
+	return result, true, nil
+}
+
+// getCachedInstance 从数据库读取单个实例，网络信息缺失或过期时返回false
+func getCachedInstance(providerID uint, instanceName string) (*provider.Instance, bool) {
+	var instance providerModel.Instance
+	if err := global.APP_DB.Where("provider_id = ? AND name = ?", providerID, instanceName).First(&instance).Error; err != nil {
+		return nil, false
+	}
+	if !networkInfoFresh(instance, time.Now()) {
+		return nil, false
+	}
+	result := cachedInstance(instance)
+	return &result, true
+}
+
+// saveNetworkInfo 将实时查询到的实例网络信息写回数据库
+func saveNetworkInfo(providerID uint, instances []provider.Instance) {
+	now := time.Now()
+	for _, instance := range instances {
+		updates := map[string]interface{}{"network_synced_at": &now}
+		privateIP := instance.PrivateIP
+		if privateIP == "" {
+			privateIP = instance.IP
+		}
+		if privateIP != "" {
+			updates["private_ip"] = privateIP
+		}
+		if instance.IPv6Address != "" {
+			updates["ipv6_address"] = instance.IPv6Address
+		}
+		if veth := instance.Metadata["network_interface"]; veth != "" {
+			updates["veth_interface"] = veth
+		}
+		// 没有取到任何网络信息时不刷新同步时间，下次继续实时查询
+		if len(updates) == 1 {
+			continue
+		}
+		if err := global.APP_DB.Model(&providerModel.Instance{}).
+			Where("provider_id = ? AND name = ?", providerID, instance.Name).
+			Updates(updates).Error; err != nil {
+			global.APP_LOG.Warn("写入实例网络信息缓存失败",
+				zap.Uint("providerId", providerID),
+				zap.String("instance", instance.Name),
+				zap.Error(err))
+		}
+	}
+}
+
+// liveListInstances 通过SSH/API实时获取实例列表并刷新网络信息缓存
+func (s *ProviderApiService) liveListInstances(ctx context.Context, providerID uint) ([]provider.Instance, error) {
+	prov, _, err := s.GetProviderByID(providerID)
+	if err != nil {
+		return nil, err
+	}
+
+	instances, err := prov.ListInstances(ctx)
+	if err != nil {
+		global.APP_LOG.Error("获取实例列表失败",
+			zap.Uint("providerId", providerID),
+			zap.Error(err))
+		return nil, fmt.Errorf("获取实例列表失败: %v", err)
+	}
+
+	saveNetworkInfo(providerID, instances)
+	return instances, nil
+}
@@ -0,0 +1,98 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"oneclickvirt/global"
+	providerModel "oneclickvirt/model/provider"
+	"oneclickvirt/model/resource"
+	systemModel "oneclickvirt/model/system"
+	"oneclickvirt/service/resources"
+)
+
+// 支持跨节点迁移实例的Provider类型，暂只支持同类型节点之间迁移
+var migratableProviderTypes = map[string]bool{
+	"docker": true,
+	"incus":  true,
+}
+
+// CheckMigrationTarget 校验实例能否从source节点迁移到target节点
+func CheckMigrationTarget(source, target *providerModel.Provider) error {
+	if source.ID == target.ID {
+		return errors.New("目标节点与实例当前所在节点相同")
+	}
+	if !migratableProviderTypes[source.Type] {
+		return fmt.Errorf("暂不支持迁移 %s 节点上的实例", source.Type)
+	}
+	if target.Type != source.Type {
+		return fmt.Errorf("只能迁移到同类型的节点（源节点为%s，目标节点为%s）", source.Type, target.Type)
+	}
+	if target.Architecture != source.Architecture {
+		return fmt.Errorf("目标节点架构 %s 与源节点架构 %s 不一致", target.Architecture, source.Architecture)
+	}
+	if target.IsFrozen {
+		return errors.New("目标节点已冻结")
+	}
+	if target.MaintenanceMode {
+		return errors.New("目标节点正在维护，暂不接受新实例")
+	}
+	if target.TrafficLimited {
+		return errors.New("目标节点因流量超限被限制")
+	}
+	if target.ExpiresAt != nil && target.ExpiresAt.Before(time.Now()) {
+		return errors.New("目标节点已过期")
+	}
+	return nil
+}
+
+// ValidateMigration 校验实例迁移的前置条件，返回目标节点上用于创建实例的系统镜像
+// 包括节点类型、实例状态、目标节点上的同名实例、镜像和资源余量，不检查节点连接状态
+func ValidateMigration(instance *providerModel.Instance, source, target *providerModel.Provider) (*systemModel.SystemImage, error) {
+	if err := CheckMigrationTarget(source, target); err != nil {
+		return nil, err
+	}
+	if instance.Status != "running" && instance.Status != "stopped" {
+		return nil, fmt.Errorf("实例当前状态为 %s，无法迁移", instance.Status)
+	}
+	if len(instance.GPUDevices) > 0 {
+		return nil, errors.New("直通了GPU的实例不支持迁移")
+	}
+	if len(instance.Volumes) > 0 {
+		return nil, errors.New("挂载了宿主机目录的实例不支持迁移")
+	}
+
+	var count int64
+	if err := global.APP_DB.Model(&providerModel.Instance{}).
+		Where("provider_id = ? AND name = ?", target.ID, instance.Name).
+		Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("检查目标节点实例失败: %v", err)
+	}
+	if count > 0 {
+		return nil, fmt.Errorf("目标节点上已存在同名实例 %s", instance.Name)
+	}
+
+	var systemImage systemModel.SystemImage
+	if err := global.APP_DB.Where("name = ? AND provider_type = ? AND instance_type = ? AND architecture = ? AND status = ?",
+		instance.Image, target.Type, instance.InstanceType, target.Architecture, "active").
+		First(&systemImage).Error; err != nil {
+		return nil, fmt.Errorf("目标节点没有可用的镜像 %s", instance.Image)
+	}
+
+	resourceService := &resources.ResourceService{}
+	result, err := resourceService.CheckProviderResources(resource.ResourceCheckRequest{
+		ProviderID:   target.ID,
+		InstanceType: instance.InstanceType,
+		CPU:          instance.CPU,
+		Memory:       instance.Memory,
+		Disk:         instance.Disk,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("检查目标节点资源失败: %v", err)
+	}
+	if !result.Allowed {
+		return nil, fmt.Errorf("目标节点资源不足: %s", result.Reason)
+	}
+	return &systemImage, nil
+}
@@ -121,6 +121,8 @@ func (ps *ProviderService) LoadProvider(dbProvider providerModel.Provider) error
 		Username:              dbProvider.Username,
 		Password:              dbProvider.Password,
 		PrivateKey:            dbProvider.SSHKey,
+		PrivateKeyPassphrase:  dbProvider.SSHKeyPassphrase,
+		UseSSHAgent:           dbProvider.SSHUseAgent,
 		Token:                 dbProvider.Token,
 		UUID:                  dbProvider.UUID,
 		Country:               dbProvider.Country,
@@ -141,13 +143,24 @@ func (ps *ProviderService) LoadProvider(dbProvider providerModel.Provider) error
 		VMLimitMemory:        dbProvider.VMLimitMemory,
 		VMLimitDisk:          dbProvider.VMLimitDisk,
 		// 容器特殊配置选项（仅 LXD/Incus 容器）
-		ContainerPrivileged:   dbProvider.ContainerPrivileged,
-		ContainerAllowNesting: dbProvider.ContainerAllowNesting,
-		ContainerEnableLXCFS:  dbProvider.ContainerEnableLXCFS,
-		ContainerCPUAllowance: dbProvider.ContainerCPUAllowance,
-		ContainerMemorySwap:   dbProvider.ContainerMemorySwap,
-		ContainerMaxProcesses: dbProvider.ContainerMaxProcesses,
-		ContainerDiskIOLimit:  dbProvider.ContainerDiskIOLimit,
+		ContainerPrivileged:       dbProvider.ContainerPrivileged,
+		ContainerAllowNesting:     dbProvider.ContainerAllowNesting,
+		ContainerEnableLXCFS:      dbProvider.ContainerEnableLXCFS,
+		ContainerLXCFSMounts:      dbProvider.ContainerLXCFSMounts,
+		ContainerCPUAllowance:     dbProvider.ContainerCPUAllowance,
+		ContainerCPUAllowanceMode: dbProvider.ContainerCPUAllowanceMode,
+		ContainerMemorySwap:       dbProvider.ContainerMemorySwap,
+		ContainerMaxProcesses:     dbProvider.ContainerMaxProcesses,
+		ContainerDiskIOLimit:      dbProvider.ContainerDiskIOLimit,
+		VolumeAllowlist:           dbProvider.VolumeAllowlist,
+		RegistryServer:            dbProvider.RegistryServer,
+		RegistryUsername:          dbProvider.RegistryUsername,
+		RegistryPassword:          dbProvider.RegistryPassword,
+		AllowSystemPrune:          dbProvider.AllowSystemPrune,
+		// SSH主机密钥校验
+		SSHVerifyHostKey:      dbProvider.SSHVerifyHostKey,
+		SSHHostKeyFingerprint: dbProvider.SSHHostKeyFingerprint,
+		OnHostKeyPinned:       saveHostKeyFingerprint(dbProvider.ID),
 	}
 
 	// 如果Provider已自动配置，尝试加载完整配置
@@ -228,6 +241,45 @@ func (ps *ProviderService) GetProviderByID(id uint) (provider.Provider, bool) {
 	return prov, exists
 }
 
+// capabilitiesProbeTimeout 能力探测的超时时间，探测结果会缓存，超时只影响缓存失效后的首次查询
+const capabilitiesProbeTimeout = 30 * time.Second
+
+// GetProviderCapabilities 获取已连接Provider探测到的能力信息
+// Provider未加载、未连接或探测失败时返回nil，不会主动建立连接，调用方应退回到数据库中的配置
+func (ps *ProviderService) GetProviderCapabilities(ctx context.Context, providerID uint) *provider.Capabilities {
+	prov, exists := ps.GetProviderByID(providerID)
+	if !exists || !prov.IsConnected() {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, capabilitiesProbeTimeout)
+	defer cancel()
+	caps, err := prov.Capabilities(ctx)
+	if err != nil {
+		global.APP_LOG.Warn("获取Provider能力信息失败",
+			zap.Uint("providerID", providerID),
+			zap.Error(err))
+		return nil
+	}
+	return caps
+}
+
+// GetCachedProviderCapabilities 获取Provider已缓存的能力信息，不会触发SSH探测
+// Provider未加载、未探测过或缓存已过期时返回nil
+func (ps *ProviderService) GetCachedProviderCapabilities(providerID uint) *provider.Capabilities {
+	prov, exists := ps.GetProviderByID(providerID)
+	if !exists {
+		return nil
+	}
+	cached, ok := prov.(interface {
+		CachedCapabilities() *provider.Capabilities
+	})
+	if !ok {
+		return nil
+	}
+	return cached.CachedCapabilities()
+}
+
 // GetProvider 根据名称获取已加载的Provider（通过遍历查找）
 // 由于需要遍历，性能不如 GetProviderByID，推荐优先使用 GetProviderByID
 func (ps *ProviderService) GetProvider(name string) (provider.Provider, bool) {
@@ -300,6 +352,20 @@ func (ps *ProviderService) ListProviders() []uint {
 	return ids
 }
 
+// ConnectionSummary 返回已加载的Provider数量和其中处于连接状态的数量
+func (ps *ProviderService) ConnectionSummary() (loaded, connected int) {
+	ps.mutex.RLock()
+	defer ps.mutex.RUnlock()
+
+	for _, prov := range ps.providers {
+		loaded++
+		if prov.IsConnected() {
+			connected++
+		}
+	}
+	return loaded, connected
+}
+
 // SetInstancePassword 设置实例密码
 func (ps *ProviderService) SetInstancePassword(ctx context.Context, providerID uint, instanceName, password string) error {
 	// 获取Provider信息
@@ -379,3 +445,18 @@ func (ps *ProviderService) ResetInstancePassword(ctx context.Context, providerID
 	// 调用Provider的密码重置方法
 	return prov.ResetInstancePassword(ctx, instanceName)
 }
+
+// saveHostKeyFingerprint 返回保存首次连接时固定的SSH主机密钥指纹的回调
+func saveHostKeyFingerprint(providerID uint) func(string) {
+	return func(fingerprint string) {
+		// 只在尚未固定时写入，避免覆盖管理员重新固定的指纹
+		err := global.APP_DB.Model(&providerModel.Provider{}).
+			Where("id = ? AND (ssh_host_key_fingerprint = '' OR ssh_host_key_fingerprint IS NULL)", providerID).
+			Update("ssh_host_key_fingerprint", fingerprint).Error
+		if err != nil {
+			global.APP_LOG.Warn("保存SSH主机密钥指纹失败",
+				zap.Uint("providerID", providerID),
+				zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package resources
+
+import (
+	"time"
+
+	"oneclickvirt/global"
+	"oneclickvirt/model/admin"
+	"oneclickvirt/model/common"
+	monitoringModel "oneclickvirt/model/monitoring"
+	providerModel "oneclickvirt/model/provider"
+
+	"go.uber.org/zap"
+)
+
+// capacityTrafficWindow 计算流量速率使用的pmacct采样窗口（采样为5分钟对齐，窗口内至少包含两个采样点）
+const capacityTrafficWindow = 15 * time.Minute
+
+// GetProviderCapacity 汇总所有Provider的容量、实例状态、流量速率和健康状态
+// 仅查询数据库中已有的数据，不发起任何SSH/API调用，适合仪表盘轮询
+func (s *AdminDashboardService) GetProviderCapacity() (*admin.ProviderCapacityResponse, error) {
+	var providers []providerModel.Provider
+	if err := global.APP_DB.Select("id, name, type, status, api_status, ssh_status, last_api_check, last_ssh_check, " +
+		"is_frozen, maintenance_mode, traffic_limited, max_concurrent_creations, node_cpu_cores, node_memory_total, node_disk_total, used_cpu_cores, used_memory, used_disk, " +
+		"cpu_overcommit_ratio, memory_overcommit_ratio").
+		Order("id ASC").Find(&providers).Error; err != nil {
+		global.APP_LOG.Error("查询Provider容量信息失败", zap.Error(err))
+		return nil, common.NewError(common.CodeDatabaseError, "查询Provider失败")
+	}
+
+	// 按Provider和状态统计实例数量
+	var statusRows []struct {
+		ProviderID uint
+		Status     string
+		Count      int64
+	}
+	if err := global.APP_DB.Model(&providerModel.Instance{}).
+		Select("provider_id, status, COUNT(*) AS count").
+		Group("provider_id, status").
+		Scan(&statusRows).Error; err != nil {
+		global.APP_LOG.Error("统计实例状态失败", zap.Error(err))
+		return nil, common.NewError(common.CodeDatabaseError, "统计实例状态失败")
+	}
+	instanceCounts := make(map[uint]map[string]int64)
+	for _, row := range statusRows {
+		if instanceCounts[row.ProviderID] == nil {
+			instanceCounts[row.ProviderID] = make(map[string]int64)
+		}
+		instanceCounts[row.ProviderID][row.Status] += row.Count
+	}
+
+	rates := recentProviderTrafficRates()
+
+	resp := &admin.ProviderCapacityResponse{
+		Providers:   make([]admin.ProviderCapacityItem, 0, len(providers)),
+		GeneratedAt: time.Now(),
+	}
+	for _, p := range providers {
+		item := admin.ProviderCapacityItem{
+			ProviderID:     p.ID,
+			Name:           p.Name,
+			Type:           p.Type,
+			Status:         p.Status,
+			Health:         p.Health(),
+			APIStatus:      p.APIStatus,
+			SSHStatus:      p.SSHStatus,
+			LastCheckAt:    latestTime(p.LastAPICheck, p.LastSSHCheck),
+			IsFrozen:       p.IsFrozen,
+			Maintenance:    p.MaintenanceMode,
+			TrafficLimited: p.TrafficLimited,
+			CPU:            capacityUsage(int64(p.NodeCPUCores), int64(p.UsedCPUCores), int64(p.AllocatableCPUCores()), p.CPUOvercommitRatio),
+			Memory:         capacityUsage(p.NodeMemoryTotal, p.UsedMemory, p.AllocatableMemory(), p.MemoryOvercommitRatio),
+			Disk:           capacityUsage(p.NodeDiskTotal, p.UsedDisk, p.NodeDiskTotal, 1),
+			InstanceCounts: instanceCounts[p.ID],
+			TrafficRate:    admin.TrafficRate{Window: int(capacityTrafficWindow.Seconds())},
+		}
+		if item.InstanceCounts == nil {
+			item.InstanceCounts = map[string]int64{}
+		}
+		for _, count := range item.InstanceCounts {
+			item.InstanceTotal += count
+		}
+		if rate, ok := rates[p.ID]; ok {
+			item.TrafficRate = rate
+		}
+		item.Creations.Limit = p.MaxConcurrentCreations
+		item.Creations.Active, item.Creations.Waiting = GetCreationLimiter().Stats(p.ID)
+		resp.Providers = append(resp.Providers, item)
+	}
+
+	return resp, nil
+}
+
+// recentProviderTrafficRates 根据最近窗口内的pmacct累计值计算每个Provider的聚合速率
+// 逐实例累加相邻采样点的增量，遇到计数器重置时以重置后的值作为增量
+func recentProviderTrafficRates() map[uint]admin.TrafficRate {
+	var records []monitoringModel.PmacctTrafficRecord
+	if err := global.APP_DB.Select("instance_id, provider_id, rx_bytes, tx_bytes, timestamp").
+		Where("timestamp >= ?", time.Now().Add(-capacityTrafficWindow)).
+		Order("instance_id ASC, timestamp ASC").
+		Find(&records).Error; err != nil {
+		global.APP_LOG.Warn("查询最近流量采样失败", zap.Error(err))
+		return nil
+	}
+
+	type accumulator struct {
+		rateIn, rateOut float64
+		samples         int
+		latest          time.Time
+	}
+	acc := make(map[uint]*accumulator)
+
+	for i := 0; i < len(records); {
+		j := i
+		var deltaIn, deltaOut int64
+		for j+1 < len(records) && records[j+1].InstanceID == records[i].InstanceID {
+			prev, cur := records[j], records[j+1]
+			deltaIn += counterDelta(prev.RxBytes, cur.RxBytes)
+			deltaOut += counterDelta(prev.TxBytes, cur.TxBytes)
+			j++
+		}
+
+		first, last := records[i], records[j]
+		a := acc[first.ProviderID]
+		if a == nil {
+			a = &accumulator{}
+			acc[first.ProviderID] = a
+		}
+		if last.Timestamp.After(a.latest) {
+			a.latest = last.Timestamp
+		}
+		if span := last.Timestamp.Sub(first.Timestamp).Seconds(); span > 0 {
+			a.rateIn += float64(deltaIn) / span
+			a.rateOut += float64(deltaOut) / span
+			a.samples++
+		}
+		i = j + 1
+	}
+
+	rates := make(map[uint]admin.TrafficRate, len(acc))
+	for providerID, a := range acc {
+		latest := a.latest
+		rates[providerID] = admin.TrafficRate{
+			InBps:   int64(a.rateIn),
+			OutBps:  int64(a.rateOut),
+			Window:  int(capacityTrafficWindow.Seconds()),
+			Samples: a.samples,
+			Latest:  &latest,
+		}
+	}
+	return rates
+}
+
+// counterDelta 计算累计计数器的增量，计数器变小视为pmacct重启
+func counterDelta(prev, cur int64) int64 {
+	if cur >= prev {
+		return cur - prev
+	}
+	return cur
+}
+
+// capacityUsage 汇总资源用量，allocatable为按超分配比例ratio计算的可分配总量
+func capacityUsage(total, used, allocatable int64, ratio float64) admin.CapacityUsage {
+	usage := admin.CapacityUsage{
+		Total:           total,
+		Used:            used,
+		OvercommitRatio: max(ratio, 1),
+		Allocatable:     allocatable,
+		Available:       max(allocatable-used, 0),
+	}
+	if total > 0 {
+		usage.Percent = float64(used*10000/total) / 100
+	}
+	return usage
+}
+
+func latestTime(times ...*time.Time) *time.Time {
+	var latest *time.Time
+	for _, t := range times {
+		if t != nil && (latest == nil || t.After(*latest)) {
+			latest = t
+		}
+	}
+	return latest
+}
@@ -0,0 +1,104 @@
+package resources
+
+import (
+	"context"
+	"sync"
+)
+
+// CreationLimiter 按Provider限制同时执行的实例创建数量
+// 上限在每次获取名额时传入，修改Provider配置后立即生效，无需重建
+type CreationLimiter struct {
+	mu        sync.Mutex
+	providers map[uint]*creationSlots
+}
+
+type creationSlots struct {
+	active  int
+	waiting int
+	// wake 在释放名额时关闭并替换，唤醒所有等待者重新争抢
+	wake chan struct{}
+}
+
+var (
+	creationLimiter     *CreationLimiter
+	creationLimiterOnce sync.Once
+)
+
+// GetCreationLimiter 获取实例创建并发限制器单例
+func GetCreationLimiter() *CreationLimiter {
+	creationLimiterOnce.Do(func() {
+		creationLimiter = &CreationLimiter{providers: make(map[uint]*creationSlots)}
+	})
+	return creationLimiter
+}
+
+func (l *CreationLimiter) slots(providerID uint) *creationSlots {
+	slots, ok := l.providers[providerID]
+	if !ok {
+		slots = &creationSlots{wake: make(chan struct{})}
+		l.providers[providerID] = slots
+	}
+	return slots
+}
+
+// Acquire 获取一个创建名额，名额不足时阻塞直到有名额释放或ctx结束
+// limit<=0 表示不限制。onWait 在首次需要排队时调用一次，可为nil
+// 返回的release必须调用且只能调用一次
+func (l *CreationLimiter) Acquire(ctx context.Context, providerID uint, limit int, onWait func(active int)) (func(), error) {
+	notified := false
+	for {
+		l.mu.Lock()
+		slots := l.slots(providerID)
+		if limit <= 0 || slots.active < limit {
+			slots.active++
+			l.mu.Unlock()
+			var once sync.Once
+			return func() { once.Do(func() { l.release(providerID) }) }, nil
+		}
+		slots.waiting++
+		wake := slots.wake
+		active := slots.active
+		l.mu.Unlock()
+
+		if !notified && onWait != nil {
+			onWait(active)
+			notified = true
+		}
+
+		select {
+		case <-ctx.Done():
+			l.mu.Lock()
+			slots.waiting--
+			l.mu.Unlock()
+			return nil, ctx.Err()
+		case <-wake:
+			l.mu.Lock()
+			slots.waiting--
+			l.mu.Unlock()
+		}
+	}
+}
+
+func (l *CreationLimiter) release(providerID uint) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	slots := l.slots(providerID)
+	if slots.active > 0 {
+		slots.active--
+	}
+	close(slots.wake)
+	slots.wake = make(chan struct{})
+	if slots.active == 0 && slots.waiting == 0 {
+		delete(l.providers, providerID)
+	}
+}
+
+// Stats 返回Provider当前正在执行和排队等待的创建数量
+func (l *CreationLimiter) Stats(providerID uint) (active, waiting int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if slots, ok := l.providers[providerID]; ok {
+		return slots.active, slots.waiting
+	}
+	return 0, 0
+}
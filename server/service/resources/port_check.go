@@ -6,7 +6,6 @@ import (
 	"oneclickvirt/model/admin"
 	"oneclickvirt/model/provider"
 	"oneclickvirt/utils"
-	"strconv"
 	"strings"
 
 	"go.uber.org/zap"
@@ -375,34 +374,11 @@ func (s *PortMappingService) batchCheckPortsAvailability(providerInfo *provider.
 
 // createSSHClientForProvider 为Provider创建SSH客户端
 func (s *PortMappingService) createSSHClientForProvider(providerInfo *provider.Provider) (*utils.SSHClient, error) {
-	// 获取SSH连接信息
-	host := providerInfo.Endpoint
-	if host == "" {
+	if providerInfo.Endpoint == "" {
 		return nil, fmt.Errorf("Provider的Endpoint为空")
 	}
 
-	// 解析主机地址（去除可能的端口号）
-	hostParts := strings.Split(host, ":")
-	hostname := hostParts[0]
-
-	sshConfig := utils.SSHConfig{
-		Host:     hostname,
-		Port:     providerInfo.SSHPort,
-		Username: providerInfo.Username,
-		Password: providerInfo.Password,
-	}
-
-	// 如果有SSH密钥，优先使用密钥
-	if providerInfo.SSHKey != "" {
-		sshConfig.PrivateKey = providerInfo.SSHKey
-	}
-
-	// 如果Endpoint包含端口，使用指定的端口
-	if len(hostParts) > 1 {
-		if port, err := strconv.Atoi(hostParts[1]); err == nil {
-			sshConfig.Port = port
-		}
-	}
+	sshConfig := utils.ProviderSSHConfig(providerInfo, 0, 0)
 
 	// 创建SSH客户端
 	sshClient, err := utils.NewSSHClient(sshConfig)
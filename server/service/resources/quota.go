@@ -476,6 +476,54 @@ func (s *QuotaService) ValidateAdminInstanceCreation(req ResourceRequest) (*Quot
 	return s.ValidateInstanceCreation(req)
 }
 
+// ValidateInstanceResize 验证将实例调整为新的CPU和内存后，用户资源总量不超过等级限制（合并节点等级限制）
+// 只校验调整带来的增量，缩小资源总是允许；节点未限制对应资源时跳过该项检查
+func (s *QuotaService) ValidateInstanceResize(userID uint, instance provider.Instance, cpu int, memory int64) error {
+	return global.APP_DB.Transaction(func(tx *gorm.DB) error {
+		var u user.User
+		if err := tx.Set("gorm:query_option", "FOR UPDATE").First(&u, userID).Error; err != nil {
+			return fmt.Errorf("用户不存在: %v", err)
+		}
+		if u.Status != 1 {
+			return errors.New("用户账户已被禁用")
+		}
+
+		levelLimits, exists := global.APP_CONFIG.Quota.LevelLimits[u.Level]
+		if !exists {
+			return fmt.Errorf("用户等级 %d 没有配置资源限制", u.Level)
+		}
+		var prov provider.Provider
+		if err := tx.First(&prov, instance.ProviderID).Error; err != nil {
+			return fmt.Errorf("Provider 不存在: %v", err)
+		}
+		providerLevelLimits, err := s.getProviderLevelLimits(tx, instance.ProviderID, u.Level)
+		if err != nil {
+			return fmt.Errorf("获取 Provider 等级限制失败: %v", err)
+		}
+		if providerLevelLimits != nil {
+			levelLimits = s.mergeLevelLimitsWithOvercommit(levelLimits, *providerLevelLimits, &prov, instance.InstanceType)
+		}
+
+		_, current, err := s.getCurrentResourceUsage(tx, userID)
+		if err != nil {
+			return fmt.Errorf("获取当前资源使用情况失败: %v", err)
+		}
+		maxResources := s.GetLevelMaxResources(levelLimits)
+
+		checkCPU, checkMemory := prov.ContainerLimitCPU, prov.ContainerLimitMemory
+		if instance.InstanceType == "vm" {
+			checkCPU, checkMemory = prov.VMLimitCPU, prov.VMLimitMemory
+		}
+		if deltaCPU := cpu - instance.CPU; checkCPU && deltaCPU > 0 && current.CPU+deltaCPU > maxResources.CPU {
+			return fmt.Errorf("CPU资源不足：需要增加 %d，当前使用 %d，最大允许 %d", deltaCPU, current.CPU, maxResources.CPU)
+		}
+		if deltaMemory := memory - instance.Memory; checkMemory && deltaMemory > 0 && current.Memory+deltaMemory > maxResources.Memory {
+			return fmt.Errorf("内存资源不足：需要增加 %dMB，当前使用 %dMB，最大允许 %dMB", deltaMemory, current.Memory, maxResources.Memory)
+		}
+		return nil
+	})
+}
+
 // RecalculateUserQuota 重新计算用户配额
 // 由于系统会重新初始化数据库，这个功能主要用于运行时的配额同步
 func (s *QuotaService) RecalculateUserQuota(userID uint) error {
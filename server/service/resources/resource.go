@@ -8,7 +8,6 @@ import (
 	"time"
 
 	"oneclickvirt/global"
-	dashboardModel "oneclickvirt/model/dashboard"
 	providerModel "oneclickvirt/model/provider"
 	"oneclickvirt/model/resource"
 	"oneclickvirt/utils"
@@ -22,12 +21,12 @@ type ResourceService struct {
 	// 移除mutex，完全依赖数据库悲观锁
 }
 
-// SyncProviderResourcesAsync 异步同步Provider资源
+// SyncProviderResourcesAsync 异步同步Provider资源（实例创建/删除/调整配置后调用）
 func (s *ResourceService) SyncProviderResourcesAsync(providerID uint) {
 	global.APP_LOG.Debug("启动异步资源同步", zap.Uint("providerId", providerID))
 
 	go func() {
-		if err := s.SyncProviderResources(providerID); err != nil {
+		if _, err := s.SyncProviderResources(providerID); err != nil {
 			global.APP_LOG.Warn("异步资源同步失败",
 				zap.Uint("providerID", providerID),
 				zap.String("error", utils.TruncateString(err.Error(), 200)))
@@ -134,10 +133,10 @@ func (s *ResourceService) checkProviderResourceAvailability(provider *providerMo
 		return result
 	}
 
-	// 计算可用资源（考虑Provider的资源限制配置）
+	// 计算可用资源（考虑Provider的资源限制配置和超分配比例）
 	// 如果资源类型配置为不限制（false），则不计入总量，允许超分配
-	availableCPU := provider.NodeCPUCores - provider.UsedCPUCores
-	availableMemory := provider.NodeMemoryTotal - provider.UsedMemory
+	availableCPU := provider.AllocatableCPUCores() - provider.UsedCPUCores
+	availableMemory := provider.AllocatableMemory() - provider.UsedMemory
 	availableDisk := provider.NodeDiskTotal - provider.UsedDisk
 
 	result.AvailableCPU = availableCPU
@@ -439,94 +438,112 @@ func (s *ResourceService) ReleaseResources(providerID uint, instanceType string,
 }
 
 // SyncProviderResources 同步Provider资源使用情况（基于实际实例计算）
-func (s *ResourceService) SyncProviderResources(providerID uint) error {
+// 只有开启了对应资源限制配置（ContainerLimitXXX / VMLimitXXX）的资源才计入已用量，与资源分配时的扣减规则一致
+func (s *ResourceService) SyncProviderResources(providerID uint) (*resource.ProviderBudgetResult, error) {
+	var result *resource.ProviderBudgetResult
+
 	dbService := database.GetDatabaseService()
-	return dbService.ExecuteTransaction(context.Background(), func(tx *gorm.DB) error {
+	err := dbService.ExecuteTransaction(context.Background(), func(tx *gorm.DB) error {
 		var provider providerModel.Provider
-		if err := tx.First(&provider, providerID).Error; err != nil {
+		// 使用悲观锁，避免与实例创建/删除时的资源分配并发冲突
+		if err := tx.Set("gorm:query_option", "FOR UPDATE").First(&provider, providerID).Error; err != nil {
 			return fmt.Errorf("Provider不存在: %v", err)
 		}
 
-		// 统计当前实例资源使用
-		var stats dashboardModel.ResourceUsageStats
-
-		// 统计虚拟机资源
-		err := tx.Model(&providerModel.Instance{}).
-			Where("provider_id = ? AND instance_type = ? AND status NOT IN (?)",
-				providerID, "vm", []string{"deleted", "deleting"}).
-			Select("COUNT(*) as vm_count, COALESCE(SUM(cpu), 0) as used_cpu_cores, COALESCE(SUM(memory), 0) as used_memory, COALESCE(SUM(disk), 0) as used_disk").
-			Scan(&stats).Error
-		if err != nil {
-			return fmt.Errorf("统计虚拟机资源失败: %v", err)
+		// 按实例类型统计当前实例资源使用
+		var rows []instanceUsageRow
+		if err := tx.Model(&providerModel.Instance{}).
+			Where("provider_id = ? AND status NOT IN (?)", providerID, []string{"deleted", "deleting", "failed"}).
+			Select("instance_type, COUNT(*) as count, COALESCE(SUM(cpu), 0) as cpu, COALESCE(SUM(memory), 0) as memory, COALESCE(SUM(disk), 0) as disk").
+			Group("instance_type").
+			Scan(&rows).Error; err != nil {
+			return fmt.Errorf("统计实例资源失败: %v", err)
 		}
 
-		vmCPU := stats.UsedCPUCores
-		vmMemory := stats.UsedMemory
-		vmDisk := stats.UsedDisk
-		vmCount := stats.VMCount
-
-		// 统计容器资源
-		err = tx.Model(&providerModel.Instance{}).
-			Where("provider_id = ? AND instance_type = ? AND status NOT IN (?)",
-				providerID, "container", []string{"deleted", "deleting"}).
-			Select("COUNT(*) as container_count, COALESCE(SUM(memory), 0) as used_memory, COALESCE(SUM(disk), 0) as used_disk").
-			Scan(&stats).Error
-		if err != nil {
-			return fmt.Errorf("统计容器资源失败: %v", err)
-		}
-
-		containerMemory := stats.UsedMemory
-		containerDisk := stats.UsedDisk
-		containerCount := stats.ContainerCount
+		result = calculateProviderUsage(&provider, rows)
 
 		// 设置缓存过期时间（5分钟后）
 		cacheExpiry := time.Now().Add(5 * time.Minute)
 
-		// 更新Provider资源统计
-		totalInstances := int(vmCount + containerCount)
-		availableCPU := provider.NodeCPUCores - int(vmCPU)
-		if availableCPU < 0 {
-			availableCPU = 0
-		}
-		availableMemory := provider.NodeMemoryTotal - (vmMemory + containerMemory)
-		if availableMemory < 0 {
-			availableMemory = 0
-		}
-
-		// 计算最大实例数限制（基于容器和虚拟机的单独限制）
-		// 0 表示无限制，不应该被处理成有限制的情况
-		maxInstances := 0
-		hasLimit := false
-		if provider.MaxContainerInstances > 0 {
-			maxInstances += provider.MaxContainerInstances
-			hasLimit = true
-		}
-		if provider.MaxVMInstances > 0 {
-			maxInstances += provider.MaxVMInstances
-			hasLimit = true
-		}
-		// 如果没有设置任何限制（都为0，表示无限制），使用默认值仅用于显示
-		if !hasLimit {
-			maxInstances = 999999 // 使用一个很大的数字表示无限制，
-		}
-
 		now := time.Now()
 		updates := map[string]interface{}{
-			"used_cpu_cores":      int(vmCPU), // 只有虚拟机占用CPU核心
-			"used_memory":         vmMemory + containerMemory,
-			"used_disk":           vmDisk + containerDisk,
-			"vm_count":            int(vmCount),
-			"container_count":     int(containerCount),
-			"available_cpu_cores": availableCPU,
-			"available_memory":    availableMemory,
-			"used_instances":      totalInstances,
+			"used_cpu_cores":      result.UsedCPU,
+			"used_memory":         result.UsedMemory,
+			"used_disk":           result.UsedDisk,
+			"vm_count":            result.VMCount,
+			"container_count":     result.ContainerCount,
+			"available_cpu_cores": result.AvailableCPU,
+			"available_memory":    result.AvailableMemory,
+			"available_disk":      result.AvailableDisk,
+			"used_instances":      result.VMCount + result.ContainerCount,
 			"resource_synced":     true,
 			"resource_synced_at":  &now,
 			"count_cache_expiry":  &cacheExpiry, // 设置缓存过期时间
 		}
-
 		return tx.Model(&provider).Updates(updates).Error
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	global.APP_LOG.Debug("Provider资源同步完成",
+		zap.Uint("providerId", providerID),
+		zap.Int("usedCpu", result.UsedCPU),
+		zap.Int64("usedMemory", result.UsedMemory),
+		zap.Int64("usedDisk", result.UsedDisk),
+		zap.Int("availableCpu", result.AvailableCPU),
+		zap.Int64("availableMemory", result.AvailableMemory),
+		zap.Int64("availableDisk", result.AvailableDisk))
+
+	return result, nil
+}
+
+// instanceUsageRow 按实例类型汇总的资源占用
+type instanceUsageRow struct {
+	InstanceType string
+	Count        int
+	CPU          int
+	Memory       int64
+	Disk         int64
+}
+
+// calculateProviderUsage 根据实例汇总数据、Provider资源限制配置和超分配比例计算已用和可用资源
+func calculateProviderUsage(provider *providerModel.Provider, rows []instanceUsageRow) *resource.ProviderBudgetResult {
+	result := &resource.ProviderBudgetResult{
+		ProviderID:        provider.ID,
+		TotalCPU:          provider.NodeCPUCores,
+		TotalMemory:       provider.NodeMemoryTotal,
+		TotalDisk:         provider.NodeDiskTotal,
+		AllocatableCPU:    provider.AllocatableCPUCores(),
+		AllocatableMemory: provider.AllocatableMemory(),
+	}
+
+	for _, row := range rows {
+		limitCPU, limitMemory, limitDisk := provider.ContainerLimitCPU, provider.ContainerLimitMemory, provider.ContainerLimitDisk
+		if row.InstanceType == "vm" {
+			limitCPU, limitMemory, limitDisk = provider.VMLimitCPU, provider.VMLimitMemory, provider.VMLimitDisk
+			result.VMCount += row.Count
+		} else {
+			result.ContainerCount += row.Count
+		}
+
+		if limitCPU {
+			result.UsedCPU += row.CPU
+		}
+		if limitMemory {
+			result.UsedMemory += row.Memory
+		}
+		if limitDisk {
+			result.UsedDisk += row.Disk
+		}
+	}
+
+	// CPU和内存按超分配比例计算可用量，磁盘不允许超分配
+	result.AvailableCPU = max(result.AllocatableCPU-result.UsedCPU, 0)
+	result.AvailableMemory = max(result.AllocatableMemory-result.UsedMemory, 0)
+	result.AvailableDisk = max(result.TotalDisk-result.UsedDisk, 0)
+
+	return result
 }
 
 // GetProviderResourceStatus 获取Provider资源状态
@@ -547,14 +564,18 @@ func (s *ResourceService) GetProviderResourceStatus(providerID uint) (map[string
 		"maxVMInstances":        provider.MaxVMInstances,
 		"resources": map[string]interface{}{
 			"cpu": map[string]interface{}{
-				"total":     provider.NodeCPUCores,
-				"used":      provider.UsedCPUCores,
-				"available": provider.NodeCPUCores - provider.UsedCPUCores,
+				"total":           provider.NodeCPUCores,
+				"used":            provider.UsedCPUCores,
+				"overcommitRatio": provider.CPUOvercommitRatio,
+				"allocatable":     provider.AllocatableCPUCores(),
+				"available":       provider.AllocatableCPUCores() - provider.UsedCPUCores,
 			},
 			"memory": map[string]interface{}{
-				"total":     provider.NodeMemoryTotal,
-				"used":      provider.UsedMemory,
-				"available": provider.NodeMemoryTotal - provider.UsedMemory,
+				"total":           provider.NodeMemoryTotal,
+				"used":            provider.UsedMemory,
+				"overcommitRatio": provider.MemoryOvercommitRatio,
+				"allocatable":     provider.AllocatableMemory(),
+				"available":       provider.AllocatableMemory() - provider.UsedMemory,
 			},
 			"disk": map[string]interface{}{
 				"total":     provider.NodeDiskTotal,
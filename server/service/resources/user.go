@@ -230,6 +230,16 @@ func (s *UserDashboardService) GetUserLimits(userID uint) (*userModel.UserLimits
 		UsedBandwidth: usedBandwidth,
 		MaxTraffic:    levelLimits.MaxTraffic, // 使用等级配置的流量限制
 		UsedTraffic:   usedTrafficMB,          // 使用实时查询的流量数据
+
+		TrafficLimited: user.TrafficLimited,
+	}
+
+	// 用户单独设置了流量配额时以用户配额为准
+	if user.TotalTraffic > 0 {
+		response.MaxTraffic = user.TotalTraffic
+	}
+	if response.MaxTraffic > 0 {
+		response.TrafficUsagePercent = float64(usedTrafficMB) / float64(response.MaxTraffic) * 100
 	}
 
 	return response, nil
@@ -39,6 +39,9 @@ func (s *SchedulerService) performMaintenance() {
 	// 清理过期实例
 	s.cleanupExpiredInstances()
 
+	// 删除等待期已结束的实例
+	s.cleanupScheduledDeletions()
+
 	// 清理旧的任务记录（可选）
 	s.cleanupOldTasks()
 }
@@ -51,6 +54,14 @@ func (s *SchedulerService) cleanupExpiredInstances() {
 	}
 }
 
+// cleanupScheduledDeletions 删除等待期已结束的实例
+func (s *SchedulerService) cleanupScheduledDeletions() {
+	cleanupService := system.GetInstanceCleanupService()
+	if err := cleanupService.CleanupScheduledDeletions(); err != nil {
+		global.APP_LOG.Error("删除等待期结束的实例时发生错误", zap.Error(err))
+	}
+}
+
 // cleanupExpiredProviders 清理过期的Provider配置
 func (s *SchedulerService) cleanupExpiredProviders() {
 	// 检查数据库是否已初始化
@@ -10,6 +10,8 @@ import (
 	adminModel "oneclickvirt/model/admin"
 	monitoringModel "oneclickvirt/model/monitoring"
 	providerModel "oneclickvirt/model/provider"
+	"oneclickvirt/service/traffic"
+	"oneclickvirt/service/webhook"
 
 	"go.uber.org/zap"
 	"gorm.io/gorm"
@@ -152,12 +154,16 @@ func (s *MonitoringSchedulerService) startPmacctCollection(ctx context.Context)
 	}
 
 	// 主循环：周期性检查哪些provider需要采集
-	checkInterval := 30 * time.Second
+	// 检查周期需明显小于随机延迟上限，否则各Provider仍会集中在同一次检查中开始采集
+	checkInterval := 10 * time.Second
 	checkTicker = time.NewTicker(checkInterval)
 
 	// 定期清理已删除provider的状态（3分钟）
 	cleanupTicker = time.NewTicker(3 * time.Minute)
 
+	// 采集槽位限制同时采集的Provider数量，槽位已满时本轮跳过，等待下一次检查
+	collectSlots := make(chan struct{}, trafficCollectConcurrency())
+
 	for {
 		select {
 		case <-s.stopChan:
@@ -216,8 +222,11 @@ func (s *MonitoringSchedulerService) startPmacctCollection(ctx context.Context)
 					continue
 				}
 
-				// 判断是否到达采集间隔
-				collectInterval := time.Duration(p.TrafficCollectInterval) * time.Second
+				// 判断是否到达采集时间（采集间隔叠加随机延迟，错开各Provider的SSH连接）
+				collectInterval := trafficCollectBaseInterval()
+				if p.TrafficCollectInterval > 0 {
+					collectInterval = time.Duration(p.TrafficCollectInterval) * time.Second
+				}
 				if collectInterval < 60*time.Second {
 					collectInterval = 60 * time.Second
 				}
@@ -227,13 +236,22 @@ func (s *MonitoringSchedulerService) startPmacctCollection(ctx context.Context)
 					batchSize = 10
 				}
 
-				lastCollect := state.GetLastCollect()
-				if !lastCollect.IsZero() && now.Sub(lastCollect) < collectInterval {
+				if now.Before(state.NextCollectAt(collectInterval, trafficCollectMaxJitter(collectInterval))) {
+					continue
+				}
+
+				select {
+				case collectSlots <- struct{}{}:
+				default:
+					global.APP_LOG.Debug("流量采集并发已满，Provider延后采集",
+						zap.Uint("providerID", p.ID),
+						zap.String("providerName", p.Name))
 					continue
 				}
 
 				// 尝试获取采集锁
 				if !state.StartCollecting() {
+					<-collectSlots
 					continue // 其他goroutine已经开始采集
 				}
 
@@ -251,6 +269,7 @@ func (s *MonitoringSchedulerService) startPmacctCollection(ctx context.Context)
 				go func(providerID uint, providerName string, roundID int64, batchSize int) {
 					// 多层 defer 确保状态一定会被释放
 					defer s.wg.Done()
+					defer func() { <-collectSlots }()
 
 					// 第一层：确保状态解锁（最外层，一定会执行）
 					defer func() {
@@ -272,8 +291,8 @@ func (s *MonitoringSchedulerService) startPmacctCollection(ctx context.Context)
 						}
 					}()
 
-					// 第三层：超时保护（防止goroutine永久阻塞）
-					ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+					// 第三层：超时保护（防止单个不可达的Provider长期占用采集槽位）
+					ctx, cancel := context.WithTimeout(context.Background(), trafficCollectTimeout())
 					defer cancel()
 
 					// 使用带超时的channel确保goroutine能退出
@@ -290,7 +309,7 @@ func (s *MonitoringSchedulerService) startPmacctCollection(ctx context.Context)
 						default:
 						}
 
-						err := s.collectProviderTrafficInBatches(providerID, batchSize, roundID)
+						err := s.collectProviderTrafficInBatches(ctx, providerID, batchSize, roundID)
 						done <- err
 					}()
 
@@ -363,6 +382,12 @@ func (s *MonitoringSchedulerService) startCleanupTask(ctx context.Context) {
 		}
 	}
 
+	// 补齐最近30天缺失的实例日度流量，供长周期流量图表查询
+	historyService := traffic.NewHistoryService()
+	if err := historyService.BackfillDailyInstanceTraffic(30); err != nil {
+		global.APP_LOG.Warn("补齐实例日度流量失败", zap.Error(err))
+	}
+
 	// 每天凌晨3点执行清理任务
 	ticker = time.NewTicker(1 * time.Hour)
 
@@ -374,6 +399,11 @@ func (s *MonitoringSchedulerService) startCleanupTask(ctx context.Context) {
 			now := time.Now()
 			// 只在凌晨3点执行
 			if now.Hour() == 3 {
+				// 先聚合前一天的日度流量，再清理过期数据
+				if err := historyService.AggregateDailyInstanceTraffic(now.AddDate(0, 0, -1)); err != nil {
+					global.APP_LOG.Error("聚合实例日度流量失败", zap.Error(err))
+				}
+
 				global.APP_LOG.Info("开始清理过期的pmacct数据")
 				if err := s.pmacctService.CleanupOldPmacctData(90); err != nil {
 					global.APP_LOG.Error("清理过期pmacct数据失败", zap.Error(err))
@@ -600,8 +630,47 @@ func (s *MonitoringSchedulerService) cleanupDeletedInstanceResetTime() {
 	}
 }
 
+// trafficCollectConcurrency 读取同时采集流量的Provider数量上限，默认8
+func trafficCollectConcurrency() int {
+	if n := global.APP_CONFIG.System.TrafficCollectConcurrency; n > 0 {
+		return n
+	}
+	return 8
+}
+
+// trafficCollectTimeout 读取单个Provider一轮流量采集的超时时间，默认30分钟
+func trafficCollectTimeout() time.Duration {
+	if seconds := global.APP_CONFIG.System.TrafficCollectTimeout; seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return 30 * time.Minute
+}
+
+// trafficCollectBaseInterval 读取Provider未设置采集间隔时使用的基础采集间隔，默认5分钟
+func trafficCollectBaseInterval() time.Duration {
+	if seconds := global.APP_CONFIG.System.TrafficCollectInterval; seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return 5 * time.Minute
+}
+
+// trafficCollectMaxJitter 读取每轮采集的随机延迟上限，默认60秒，不超过采集间隔本身
+// 配置为负数时关闭随机延迟
+func trafficCollectMaxJitter(collectInterval time.Duration) time.Duration {
+	seconds := global.APP_CONFIG.System.TrafficCollectMaxJitter
+	if seconds < 0 {
+		return 0
+	}
+	maxJitter := 60 * time.Second
+	if seconds > 0 {
+		maxJitter = time.Duration(seconds) * time.Second
+	}
+	return min(maxJitter, collectInterval)
+}
+
 // collectProviderTrafficInBatches 分批采集Provider的流量数据，确保一轮内不重复采集
-func (s *MonitoringSchedulerService) collectProviderTrafficInBatches(providerID uint, batchSize int, roundID int64) error {
+// ctx超时或取消后不再处理剩余的监控实例，避免超时的采集在后台继续占用SSH连接
+func (s *MonitoringSchedulerService) collectProviderTrafficInBatches(ctx context.Context, providerID uint, batchSize int, roundID int64) error {
 	// 获取该Provider下所有启用的监控实例（只查询需要的字段，避免加载所有数据）
 	var totalCount int64
 	err := global.APP_DB.Model(&monitoringModel.PmacctMonitor{}).
@@ -664,12 +733,20 @@ func (s *MonitoringSchedulerService) collectProviderTrafficInBatches(providerID
 
 		// 为本批次的每个监控实例采集数据（从SQLite同步到MySQL）
 		for _, monitor := range monitors {
+			if err := ctx.Err(); err != nil {
+				return fmt.Errorf("采集中断，已处理 %d/%d 个实例: %w", processedCount, totalCount, err)
+			}
+
 			instance := instanceMap[monitor.InstanceID]
 			if instance == nil {
 				global.APP_LOG.Warn("实例不存在",
 					zap.Uint("instanceID", monitor.InstanceID))
 				continue
 			}
+			// 关闭监控时会清理监控记录，这里兜底跳过清理失败残留的记录
+			if !instance.EnableMonitoring {
+				continue
+			}
 
 			// 使用 CollectTrafficFromSQLite 从 Provider 的 SQLite 数据库采集数据
 			// 传入预加载的数据，避免函数内部重复查询
@@ -696,7 +773,11 @@ func (s *MonitoringSchedulerService) collectProviderTrafficInBatches(providerID
 
 		// 批次间短暂延迟，避免过载
 		if offset+batchSize < int(totalCount) {
-			time.Sleep(2 * time.Second)
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("采集中断，已处理 %d/%d 个实例: %w", processedCount, totalCount, ctx.Err())
+			case <-time.After(2 * time.Second):
+			}
 		}
 	}
 
@@ -792,6 +873,9 @@ func (s *MonitoringSchedulerService) checkProviderTrafficLimits(ctx context.Cont
 					global.APP_LOG.Error("标记实例流量超限失败",
 						zap.Uint("instanceID", instance.ID),
 						zap.Error(err))
+				} else {
+					webhook.NotifyInstanceEvent(webhook.EventTrafficQuotaExceed, instance.ID,
+						fmt.Sprintf("实例流量超限: %dMB/%dMB", usedTraffic, instance.MaxTraffic))
 				}
 
 				// 实例流量超限处理：创建停止任务
@@ -0,0 +1,204 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"oneclickvirt/global"
+	adminModel "oneclickvirt/model/admin"
+	providerModel "oneclickvirt/model/provider"
+	providerService "oneclickvirt/service/provider"
+	"oneclickvirt/service/webhook"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// powerStateSyncInterval 实例电源状态同步周期
+	powerStateSyncInterval = 5 * time.Minute
+	// powerStateListTimeout 单个Provider获取实例列表的超时时间
+	powerStateListTimeout = 90 * time.Second
+	// powerStateMaxConcurrency 同时同步的Provider数量上限
+	powerStateMaxConcurrency = 4
+)
+
+// PowerStateSchedulerService 实例电源状态同步调度服务
+// 定期从宿主机获取实例列表，将数据库中的running/stopped状态校正为实际状态（实例崩溃或在宿主机上被手动启停时会产生偏差）
+type PowerStateSchedulerService struct {
+	stopChan chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewPowerStateSchedulerService 创建实例电源状态同步调度服务
+func NewPowerStateSchedulerService() *PowerStateSchedulerService {
+	return &PowerStateSchedulerService{
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Start 启动实例电源状态同步
+func (s *PowerStateSchedulerService) Start(ctx context.Context) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer func() {
+			if r := recover(); r != nil {
+				global.APP_LOG.Error("实例电源状态同步任务panic", zap.Any("panic", r), zap.Stack("stack"))
+			}
+		}()
+
+		ticker := time.NewTicker(powerStateSyncInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-s.stopChan:
+				return
+			case <-ticker.C:
+				if global.APP_DB == nil {
+					continue
+				}
+				s.syncAllInstances(ctx)
+			}
+		}
+	}()
+}
+
+// Stop 停止实例电源状态同步
+func (s *PowerStateSchedulerService) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.stopChan)
+	})
+	s.wg.Wait()
+}
+
+// syncAllInstances 同步所有在线Provider上处于running/stopped状态的实例
+// 有进行中任务的实例状态由任务维护，不参与同步
+func (s *PowerStateSchedulerService) syncAllInstances(ctx context.Context) {
+	var instances []providerModel.Instance
+	if err := global.APP_DB.
+		Select("instances.id, instances.name, instances.provider_id, instances.status").
+		Joins("JOIN providers ON providers.id = instances.provider_id").
+		Where("instances.status IN ?", []string{"running", "stopped"}).
+		Where("providers.is_frozen = ? AND (providers.ssh_status = ? OR providers.api_status = ?)", false, "online", "online").
+		Find(&instances).Error; err != nil {
+		global.APP_LOG.Warn("查询需要同步电源状态的实例失败", zap.Error(err))
+		return
+	}
+	if len(instances) == 0 {
+		return
+	}
+
+	ids := make([]uint, 0, len(instances))
+	for _, inst := range instances {
+		ids = append(ids, inst.ID)
+	}
+	var busyIDs []uint
+	if err := global.APP_DB.Model(&adminModel.Task{}).
+		Where("instance_id IN ? AND status IN ?", ids, []string{"pending", "running"}).
+		Distinct().Pluck("instance_id", &busyIDs).Error; err != nil {
+		global.APP_LOG.Warn("查询实例进行中任务失败", zap.Error(err))
+		return
+	}
+	busy := make(map[uint]bool, len(busyIDs))
+	for _, id := range busyIDs {
+		busy[id] = true
+	}
+
+	byProvider := make(map[uint][]providerModel.Instance)
+	for _, inst := range instances {
+		if !busy[inst.ID] {
+			byProvider[inst.ProviderID] = append(byProvider[inst.ProviderID], inst)
+		}
+	}
+
+	sem := make(chan struct{}, powerStateMaxConcurrency)
+	var wg sync.WaitGroup
+	for providerID, list := range byProvider {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(providerID uint, list []providerModel.Instance) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			s.syncProvider(ctx, providerID, list)
+		}(providerID, list)
+	}
+	wg.Wait()
+}
+
+// syncProvider 对比单个Provider上实例的实际状态并更新数据库
+func (s *PowerStateSchedulerService) syncProvider(ctx context.Context, providerID uint, instances []providerModel.Instance) {
+	prov, err := providerService.GetProviderInstanceByID(providerID)
+	if err != nil {
+		global.APP_LOG.Debug("获取Provider实例失败，跳过电源状态同步",
+			zap.Uint("providerID", providerID),
+			zap.Error(err))
+		return
+	}
+
+	listCtx, cancel := context.WithTimeout(ctx, powerStateListTimeout)
+	hostInstances, err := prov.ListInstances(listCtx)
+	cancel()
+	if err != nil {
+		global.APP_LOG.Warn("获取宿主机实例列表失败，跳过电源状态同步",
+			zap.Uint("providerID", providerID),
+			zap.Error(err))
+		return
+	}
+
+	actualStates := make(map[string]string, len(hostInstances))
+	for _, hostInstance := range hostInstances {
+		if state := normalizePowerState(hostInstance.Status); state != "" {
+			actualStates[hostInstance.Name] = state
+		}
+	}
+
+	for _, inst := range instances {
+		// 宿主机上找不到的实例或无法识别的状态不做处理，避免列表不完整时误改状态
+		actual, ok := actualStates[inst.Name]
+		if !ok || actual == inst.Status {
+			continue
+		}
+
+		// 仅在状态未被其他流程修改时更新
+		result := global.APP_DB.Model(&providerModel.Instance{}).
+			Where("id = ? AND status = ?", inst.ID, inst.Status).
+			Update("status", actual)
+		if result.Error != nil {
+			global.APP_LOG.Warn("更新实例电源状态失败",
+				zap.Uint("instanceID", inst.ID),
+				zap.Error(result.Error))
+			continue
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+
+		global.APP_LOG.Warn("实例状态与宿主机不一致，已按实际状态更新",
+			zap.Uint("providerID", providerID),
+			zap.Uint("instanceID", inst.ID),
+			zap.String("instanceName", inst.Name),
+			zap.String("from", inst.Status),
+			zap.String("to", actual))
+		webhook.NotifyInstanceEvent(webhook.EventInstanceStatusDrift, inst.ID,
+			fmt.Sprintf("实例状态由 %s 变为 %s（宿主机实际状态）", inst.Status, actual))
+	}
+}
+
+// normalizePowerState 将各类Provider返回的实例状态归一化为running/stopped，其他状态返回空字符串
+func normalizePowerState(status string) string {
+	switch strings.ToLower(strings.TrimSpace(status)) {
+	case "running":
+		return "running"
+	case "stopped", "exited", "shutoff", "shut off":
+		return "stopped"
+	default:
+		return ""
+	}
+}
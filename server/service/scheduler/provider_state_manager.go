@@ -1,6 +1,7 @@
 package scheduler
 
 import (
+	"math/rand/v2"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -18,6 +19,8 @@ type ProviderState struct {
 	isCollecting     atomic.Bool // 使用atomic避免锁
 	collectStartTime time.Time
 	lastAccess       time.Time
+	jitter           time.Duration // 本轮采集叠加的随机延迟，用于错开各Provider的采集时间
+	jitterReady      bool          // 本轮随机延迟是否已生成
 	mu               sync.RWMutex
 }
 
@@ -216,6 +219,7 @@ func (s *ProviderState) UpdateLastCollect() int64 {
 	s.lastCollect = time.Now()
 	s.currentRoundID++
 	s.collectStartTime = time.Now()
+	s.jitterReady = false // 下一轮重新生成随机延迟
 
 	return s.currentRoundID
 }
@@ -227,6 +231,31 @@ func (s *ProviderState) GetLastCollect() time.Time {
 	return s.lastCollect
 }
 
+// NextCollectAt 返回下一次采集的时间：上次采集时间 + 采集间隔 + 随机延迟
+// 随机延迟在每轮采集后重新生成，首次采集从状态创建时间起算，
+// 避免所有Provider在同一个检查周期内同时建立SSH连接
+func (s *ProviderState) NextCollectAt(interval, maxJitter time.Duration) time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.jitterReady {
+		s.jitter = randomJitter(maxJitter)
+		s.jitterReady = true
+	}
+	if s.lastCollect.IsZero() {
+		return s.createdAt.Add(s.jitter)
+	}
+	return s.lastCollect.Add(interval + s.jitter)
+}
+
+// randomJitter 返回 [0, maxJitter] 范围内的随机时长
+func randomJitter(maxJitter time.Duration) time.Duration {
+	if maxJitter <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int64N(int64(maxJitter) + 1))
+}
+
 // GetCurrentRoundID 获取当前轮次ID
 func (s *ProviderState) GetCurrentRoundID() int64 {
 	s.mu.RLock()
@@ -154,10 +154,10 @@ func (s *SchedulerService) processPendingTasks() {
 		return
 	}
 
-	// 获取所有待处理任务，按创建时间排序
+	// 获取所有待处理任务，按优先级从高到低、同优先级按创建时间排序
 	var pendingTasks []adminModel.Task
 	err := global.APP_DB.Where("status = ?", "pending").
-		Order("created_at ASC").
+		Order("priority DESC, created_at ASC").
 		Find(&pendingTasks).Error
 
 	if err != nil {
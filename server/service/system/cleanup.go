@@ -1,14 +1,20 @@
 package system
 
 import (
-	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
 	"time"
 
 	"oneclickvirt/global"
+	adminModel "oneclickvirt/model/admin"
 	providerModel "oneclickvirt/model/provider"
+	userModel "oneclickvirt/model/user"
 
-	traffic_monitor "oneclickvirt/service/admin/traffic_monitor"
 	"oneclickvirt/service/resources"
+	"oneclickvirt/service/task"
+	"oneclickvirt/service/user/notification"
+	"oneclickvirt/utils"
 
 	"go.uber.org/zap"
 	"gorm.io/gorm"
@@ -160,128 +166,182 @@ func (s *InstanceCleanupService) cleanupSingleFailedInstance(instance *providerM
 	})
 }
 
-// CleanupExpiredInstances 清理过期实例
+// CleanupExpiredInstances 回收到期实例
+// 先通知即将到期的实例所属用户，再为已到期的实例创建"先停止后删除"的删除任务，
+// 删除任务复用Provider删除流程，负责释放资源预留、配额以及pmacct流量监控
 func (s *InstanceCleanupService) CleanupExpiredInstances() error {
-	now := time.Now()
+	s.notifyExpiringInstances()
 
 	var expiredInstances []providerModel.Instance
-	if err := global.APP_DB.Where("expired_at < ? AND status NOT IN ?",
-		now, []string{"deleted", "deleting"}).Find(&expiredInstances).Error; err != nil {
+	if err := global.APP_DB.Where("expired_at > ? AND expired_at < ? AND status NOT IN ?",
+		time.Unix(0, 0), time.Now(), []string{"deleted", "deleting", "creating"}).
+		Limit(100).Find(&expiredInstances).Error; err != nil {
 		global.APP_LOG.Error("查询过期实例失败", zap.Error(err))
 		return err
 	}
 
 	if len(expiredInstances) == 0 {
-		global.APP_LOG.Debug("没有需要清理的过期实例")
+		global.APP_LOG.Debug("没有需要回收的过期实例")
 		return nil
 	}
 
-	global.APP_LOG.Info("开始清理过期实例", zap.Int("count", len(expiredInstances)))
+	global.APP_LOG.Info("开始回收过期实例", zap.Int("count", len(expiredInstances)))
 
-	// 批量预加载provider信息
-	var providerIDs []uint
-	providerIDSet := make(map[uint]bool)
 	for _, instance := range expiredInstances {
-		if instance.ProviderID > 0 && !providerIDSet[instance.ProviderID] {
-			providerIDs = append(providerIDs, instance.ProviderID)
-			providerIDSet[instance.ProviderID] = true
+		if err := s.reclaimExpiredInstance(&instance); err != nil {
+			global.APP_LOG.Error("回收过期实例时发生错误",
+				zap.Uint("instanceId", instance.ID),
+				zap.String("instanceName", instance.Name),
+				zap.Error(err))
+			// 继续处理其他实例
 		}
 	}
 
-	providerMap := make(map[uint]providerModel.Provider)
-	if len(providerIDs) > 0 {
-		var providers []providerModel.Provider
-		if err := global.APP_DB.Where("id IN ?", providerIDs).Find(&providers).Error; err == nil {
-			for _, provider := range providers {
-				providerMap[provider.ID] = provider
-			}
-		}
+	return nil
+}
+
+// reclaimExpiredInstance 为过期实例创建删除任务，删除前先停止实例
+func (s *InstanceCleanupService) reclaimExpiredInstance(instance *providerModel.Instance) error {
+	deleteTask, err := s.createSystemDeleteTask(instance)
+	if err != nil || deleteTask == nil {
+		return err
 	}
 
-	// 逐个清理过期实例
-	for _, instance := range expiredInstances {
-		if err := s.cleanupSingleExpiredInstance(&instance, providerMap); err != nil {
-			global.APP_LOG.Error("清理过期实例时发生错误",
+	global.APP_LOG.Info("已为过期实例创建删除任务",
+		zap.Uint("instanceId", instance.ID),
+		zap.String("instanceName", instance.Name),
+		zap.Time("expiredAt", instance.ExpiredAt),
+		zap.Uint("taskId", deleteTask.ID))
+	return nil
+}
+
+// CleanupScheduledDeletions 删除等待期已结束的实例
+func (s *InstanceCleanupService) CleanupScheduledDeletions() error {
+	var instances []providerModel.Instance
+	if err := global.APP_DB.Where("delete_scheduled_at IS NOT NULL AND delete_scheduled_at <= ? AND status NOT IN ?",
+		time.Now(), []string{"deleted", "deleting"}).
+		Limit(100).Find(&instances).Error; err != nil {
+		global.APP_LOG.Error("查询待删除实例失败", zap.Error(err))
+		return err
+	}
+
+	for _, instance := range instances {
+		// 先以条件更新占用实例，避免与用户撤销删除并发时误删
+		result := global.APP_DB.Model(&providerModel.Instance{}).
+			Where("id = ? AND delete_scheduled_at IS NOT NULL AND status = ?", instance.ID, instance.Status).
+			Update("status", "deleting")
+		if result.Error != nil || result.RowsAffected == 0 {
+			continue
+		}
+
+		deleteTask, err := s.createSystemDeleteTask(&instance)
+		if err != nil {
+			global.APP_DB.Model(&instance).Update("status", instance.Status)
+			global.APP_LOG.Error("删除等待期结束的实例时发生错误",
 				zap.Uint("instanceId", instance.ID),
 				zap.String("instanceName", instance.Name),
 				zap.Error(err))
-			// 继续清理其他实例
+			continue
+		}
+		if deleteTask != nil {
+			global.APP_LOG.Info("实例删除等待期结束，已创建删除任务",
+				zap.Uint("instanceId", instance.ID),
+				zap.String("instanceName", instance.Name),
+				zap.Time("deleteScheduledAt", *instance.DeleteScheduledAt),
+				zap.Uint("taskId", deleteTask.ID))
 		}
 	}
-
-	global.APP_LOG.Info("过期实例清理完成", zap.Int("processedCount", len(expiredInstances)))
 	return nil
 }
 
-// cleanupSingleExpiredInstance 清理单个过期实例
-func (s *InstanceCleanupService) cleanupSingleExpiredInstance(instance *providerModel.Instance, providerMap map[uint]providerModel.Provider) error {
-	// 第一步：在事务外清理 pmacct 数据（可能包含SSH命令，不应在事务内）
-	trafficMonitorManager := traffic_monitor.GetManager()
-	deleteCtx, deleteCancel := context.WithTimeout(context.Background(), 2*time.Minute)
-	defer deleteCancel()
-	if err := trafficMonitorManager.DetachMonitor(deleteCtx, instance.ID); err != nil {
-		global.APP_LOG.Warn("清理过期实例pmacct数据失败",
-			zap.Uint("instanceId", instance.ID),
-			zap.Error(err))
-		// 不返回错误，继续清理数据库记录
+// createSystemDeleteTask 以系统身份为实例创建删除任务，删除前先停止实例；已有进行中的删除任务时返回nil
+func (s *InstanceCleanupService) createSystemDeleteTask(instance *providerModel.Instance) (*adminModel.Task, error) {
+	var existingCount int64
+	global.APP_DB.Model(&adminModel.Task{}).
+		Where("instance_id = ? AND task_type = ? AND status IN ?", instance.ID, "delete", []string{"pending", "running"}).
+		Count(&existingCount)
+	if existingCount > 0 {
+		return nil, nil
 	}
 
-	// 第二步：在短事务内完成数据库操作
-	return global.APP_DB.Transaction(func(tx *gorm.DB) error {
-		// 1. 标记实例为删除中
-		if err := tx.Model(instance).Updates(map[string]interface{}{
-			"status":     "deleting",
-			"updated_at": time.Now(),
-		}).Error; err != nil {
-			return err
-		}
+	taskData, err := json.Marshal(adminModel.DeleteInstanceTaskRequest{
+		InstanceId:       instance.ID,
+		ProviderId:       instance.ProviderID,
+		AdminOperation:   true,
+		StopBeforeDelete: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("序列化任务数据失败: %v", err)
+	}
 
-		// 2. 清理实例相关资源
-		global.APP_LOG.Debug("清理过期实例资源",
-			zap.Uint("instanceId", instance.ID))
+	deleteTask, err := task.GetTaskService().CreateTask(instance.UserID, &instance.ProviderID, &instance.ID, "delete", string(taskData), 1800)
+	if err != nil {
+		return nil, fmt.Errorf("创建删除任务失败: %v", err)
+	}
 
-		// 删除实例的端口映射（在事务内）
-		portMappingService := resources.PortMappingService{}
-		if err := portMappingService.DeleteInstancePortMappingsInTx(tx, instance.ID); err != nil {
-			global.APP_LOG.Warn("删除过期实例端口映射失败",
-				zap.Uint("instanceId", instance.ID),
-				zap.Error(err))
-			// 不返回错误，继续其他清理操作
-		} else {
-			global.APP_LOG.Info("成功删除过期实例端口映射",
-				zap.Uint("instanceId", instance.ID))
-		}
+	// 系统发起的删除不允许用户取消，并优先于用户的常规任务执行
+	if err := global.APP_DB.Model(deleteTask).Updates(map[string]interface{}{
+		"is_force_stoppable": false,
+		"priority":           adminModel.TaskPrioritySystem,
+	}).Error; err != nil {
+		global.APP_LOG.Warn("更新任务可取消状态失败", zap.Uint("taskId", deleteTask.ID), zap.Error(err))
+	}
+	if err := global.APP_DB.Model(instance).Update("status", "deleting").Error; err != nil {
+		global.APP_LOG.Warn("更新实例状态失败", zap.Uint("instanceId", instance.ID), zap.Error(err))
+	}
+	return deleteTask, nil
+}
 
-		// 保存需要用于日志的字段
-		instanceID := instance.ID
-		instanceName := instance.Name
-		instanceExpiredAt := instance.ExpiredAt
-		instanceProviderID := instance.ProviderID
+// notifyExpiringInstances 通知即将到期的实例所属用户，每个实例在一次有效期内只通知一次
+func (s *InstanceCleanupService) notifyExpiringInstances() {
+	policy := utils.GetInstanceTTLPolicy()
+	now := time.Now()
 
-		// 从预加载的map获取Provider信息并更新使用配额
-		if provider, ok := providerMap[instanceProviderID]; ok {
-			if provider.UsedQuota > 0 {
-				newUsedQuota := provider.UsedQuota - 1
-				if err := tx.Model(&providerModel.Provider{}).
-					Where("id = ?", instanceProviderID).
-					Update("used_quota", newUsedQuota).Error; err != nil {
-					global.APP_LOG.Error("更新Provider配额失败", zap.Error(err))
-				}
-			}
-		}
+	var instances []providerModel.Instance
+	if err := global.APP_DB.Select("id, name, user_id, expired_at").
+		Where("expired_at > ? AND expired_at <= ? AND expire_notified_at IS NULL AND status NOT IN ?",
+			now, now.Add(time.Duration(policy.NotifyHours)*time.Hour), []string{"deleted", "deleting", "creating", "failed"}).
+		Limit(500).Find(&instances).Error; err != nil {
+		global.APP_LOG.Warn("查询即将到期实例失败", zap.Error(err))
+		return
+	}
+	if len(instances) == 0 {
+		return
+	}
 
-		// 3. 软删除实例记录（使用GORM的软删除）
-		if err := tx.Delete(instance).Error; err != nil {
-			return err
+	byUser := make(map[uint][]providerModel.Instance)
+	for _, instance := range instances {
+		byUser[instance.UserID] = append(byUser[instance.UserID], instance)
+	}
+
+	notifier := notification.NewService()
+	for userID, items := range byUser {
+		ids := make([]uint, 0, len(items))
+		lines := make([]string, 0, len(items))
+		for _, instance := range items {
+			ids = append(ids, instance.ID)
+			lines = append(lines, fmt.Sprintf("%s（到期时间 %s）", instance.Name, instance.ExpiredAt.Format("2006-01-02 15:04")))
 		}
 
-		global.APP_LOG.Info("成功清理过期实例",
-			zap.Uint("instanceId", instanceID),
-			zap.String("instanceName", instanceName),
-			zap.Time("expiredAt", instanceExpiredAt))
+		// 先记录通知时间，避免通知渠道异常时每轮维护重复发送
+		if err := global.APP_DB.Model(&providerModel.Instance{}).Where("id IN ?", ids).
+			Update("expire_notified_at", now).Error; err != nil {
+			global.APP_LOG.Warn("更新实例到期提醒时间失败", zap.Uint("userID", userID), zap.Error(err))
+			continue
+		}
 
-		return nil
-	})
+		var u userModel.User
+		if err := global.APP_DB.First(&u, userID).Error; err != nil {
+			continue
+		}
+		message := fmt.Sprintf("以下实例即将到期，到期后将被自动停止并删除，如需继续使用请及时续期：\n%s", strings.Join(lines, "\n"))
+		if err := notifier.NotifyUser(&u, "实例即将到期", message); err != nil {
+			global.APP_LOG.Warn("发送实例到期提醒失败",
+				zap.Uint("userID", userID),
+				zap.Int("instanceCount", len(items)),
+				zap.Error(err))
+		}
+	}
 }
 
 // GetInstanceCleanupService 获取实例清理服务实例
@@ -10,6 +10,7 @@ import (
 	adminModel "oneclickvirt/model/admin"
 	"oneclickvirt/model/auth"
 	"oneclickvirt/model/config"
+	monitoringModel "oneclickvirt/model/monitoring"
 	permissionModel "oneclickvirt/model/permission"
 	"oneclickvirt/model/provider"
 	"oneclickvirt/model/resource"
@@ -137,6 +138,21 @@ func (s *InitService) AutoMigrateTables() error {
 		&provider.Port{},     // 端口映射表
 		&adminModel.Task{},   // 用户任务表
 
+		// 实例防火墙规则表
+		&provider.InstanceFirewallRule{}, // 实例防火墙规则表
+
+		// 实例备份表
+		&provider.Backup{}, // 实例备份记录表
+
+		// 实例定时开关机计划表
+		&provider.InstanceSchedule{}, // 实例定时开关机计划表
+
+		// 实例模板表
+		&provider.InstanceTemplate{}, // 实例模板表
+
+		// 实例共享授权表
+		&provider.InstanceShare{}, // 实例共享授权表
+
 		// 资源管理表
 		&resource.ResourceReservation{}, // 资源预留表
 
@@ -161,8 +177,15 @@ func (s *InitService) AutoMigrateTables() error {
 		&adminModel.AuditLog{},      // 操作审计日志表
 		&provider.PendingDeletion{}, // 待删除资源表
 
+		// Webhook通知表
+		&adminModel.Webhook{}, // Webhook通知配置表
+
 		// 管理员配置任务表
 		&adminModel.ConfigurationTask{}, // 管理员配置任务表
+
+		// 资源告警表
+		&monitoringModel.ResourceAlert{},        // 实例资源告警记录表
+		&monitoringModel.ResourceAlertSetting{}, // 用户资源告警设置表
 	)
 
 	if err != nil {
@@ -0,0 +1,166 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"oneclickvirt/global"
+	adminModel "oneclickvirt/model/admin"
+	providerModel "oneclickvirt/model/provider"
+	"oneclickvirt/provider"
+	"oneclickvirt/service/backup"
+	provider2 "oneclickvirt/service/provider"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+)
+
+// executeBackupInstanceTask 执行实例备份任务
+// Provider导出的归档通过管道边读边上传到对象存储，不在面板服务器上落盘或整体缓存
+func (s *TaskService) executeBackupInstanceTask(ctx context.Context, task *adminModel.Task) error {
+	var taskReq adminModel.BackupTaskRequest
+	if err := json.Unmarshal([]byte(task.TaskData), &taskReq); err != nil {
+		return fmt.Errorf("解析任务数据失败: %v", err)
+	}
+
+	var record providerModel.Backup
+	if err := global.APP_DB.First(&record, taskReq.BackupId).Error; err != nil {
+		return fmt.Errorf("备份记录不存在: %v", err)
+	}
+
+	err := s.runBackup(ctx, task, &record)
+	if err != nil {
+		s.markBackupFailed(record.ID, err)
+		return err
+	}
+
+	s.updateTaskProgress(task.ID, 95, "正在清理过期备份...")
+	backup.ApplyRetention(ctx, record.InstanceID)
+	return nil
+}
+
+func (s *TaskService) runBackup(ctx context.Context, task *adminModel.Task, record *providerModel.Backup) error {
+	storage, err := backup.NewObjectStorage()
+	if err != nil {
+		return err
+	}
+
+	global.APP_DB.Model(record).Update("status", backup.StatusRunning)
+	s.updateTaskProgress(task.ID, 10, "正在导出实例并上传到对象存储...")
+
+	pipeReader, pipeWriter := io.Pipe()
+	type exportResult struct {
+		archive *provider.BackupArchive
+		err     error
+	}
+	exportDone := make(chan exportResult, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				pipeWriter.CloseWithError(fmt.Errorf("导出实例时发生panic: %v", r))
+				exportDone <- exportResult{err: fmt.Errorf("导出实例时发生panic: %v", r)}
+			}
+		}()
+		providerApiService := &provider2.ProviderApiService{}
+		archive, err := providerApiService.BackupInstanceByProviderID(ctx, record.ProviderID, record.InstanceName, pipeWriter)
+		pipeWriter.CloseWithError(err)
+		exportDone <- exportResult{archive: archive, err: err}
+	}()
+
+	reader := backup.NewChecksumReader(pipeReader, "")
+	uploaded, uploadErr := storage.UploadStream(ctx, record.ObjectKey, reader)
+	// 上传失败时关闭管道，让导出端不再阻塞在写入上
+	pipeReader.CloseWithError(uploadErr)
+	result := <-exportDone
+
+	if result.err != nil {
+		return fmt.Errorf("导出实例失败: %v", result.err)
+	}
+	if uploadErr != nil {
+		return fmt.Errorf("上传备份失败: %v", uploadErr)
+	}
+
+	now := time.Now()
+	if err := global.APP_DB.Model(record).Updates(map[string]interface{}{
+		"status":        backup.StatusCompleted,
+		"file_name":     result.archive.FileName,
+		"size":          uploaded,
+		"checksum":      reader.Sum(),
+		"error_message": "",
+		"completed_at":  &now,
+	}).Error; err != nil {
+		return fmt.Errorf("更新备份记录失败: %v", err)
+	}
+
+	global.APP_LOG.Info("实例备份完成",
+		zap.Uint("taskId", task.ID),
+		zap.Uint("backupId", record.ID),
+		zap.String("instanceName", record.InstanceName),
+		zap.Int64("size", uploaded))
+	return nil
+}
+
+func (s *TaskService) markBackupFailed(backupID uint, cause error) {
+	global.APP_DB.Model(&providerModel.Backup{}).Where("id = ?", backupID).Updates(map[string]interface{}{
+		"status":        backup.StatusFailed,
+		"error_message": utils.TruncateString(cause.Error(), 1000),
+	})
+}
+
+// executeRestoreBackupTask 执行从备份恢复实例的任务
+// 备份内容边下载边校验SHA256，校验失败时在导入前中止，不会用损坏的备份覆盖实例
+func (s *TaskService) executeRestoreBackupTask(ctx context.Context, task *adminModel.Task) error {
+	var taskReq adminModel.BackupTaskRequest
+	if err := json.Unmarshal([]byte(task.TaskData), &taskReq); err != nil {
+		return fmt.Errorf("解析任务数据失败: %v", err)
+	}
+
+	var record providerModel.Backup
+	if err := global.APP_DB.First(&record, taskReq.BackupId).Error; err != nil {
+		return fmt.Errorf("备份记录不存在: %v", err)
+	}
+	if record.Status != backup.StatusCompleted {
+		return fmt.Errorf("备份未完成，无法恢复")
+	}
+
+	var instance providerModel.Instance
+	if err := global.APP_DB.First(&instance, taskReq.InstanceId).Error; err != nil {
+		return fmt.Errorf("实例不存在: %v", err)
+	}
+
+	storage, err := backup.NewObjectStorage()
+	if err != nil {
+		return err
+	}
+
+	s.updateTaskProgress(task.ID, 10, "正在从对象存储下载备份...")
+	body, err := storage.GetObject(ctx, record.ObjectKey)
+	if err != nil {
+		return fmt.Errorf("下载备份失败: %v", err)
+	}
+	defer body.Close()
+
+	s.updateTaskProgress(task.ID, 20, "正在恢复实例...")
+	reader := backup.NewChecksumReader(body, record.Checksum)
+	providerApiService := &provider2.ProviderApiService{}
+	if err := providerApiService.RestoreFromBackupByProviderID(ctx, instance.ProviderID, instance.Name, record.FileName, reader); err != nil {
+		return fmt.Errorf("恢复实例失败: %v", err)
+	}
+
+	// 恢复会重建宿主机侧接口，重新应用限速和防火墙规则
+	if instance.Status == "running" {
+		s.updateTaskProgress(task.ID, 90, "正在重新应用网络配置...")
+		s.reapplyBandwidthLimit(ctx, &instance)
+		s.reapplyFirewallRules(ctx, &instance)
+	}
+
+	global.APP_LOG.Info("实例备份恢复完成",
+		zap.Uint("taskId", task.ID),
+		zap.Uint("backupId", record.ID),
+		zap.Uint("instanceId", instance.ID),
+		zap.String("instanceName", instance.Name))
+	return nil
+}
@@ -0,0 +1,237 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"oneclickvirt/global"
+	adminModel "oneclickvirt/model/admin"
+	providerModel "oneclickvirt/model/provider"
+	"oneclickvirt/provider/portmapping"
+	traffic_monitor "oneclickvirt/service/admin/traffic_monitor"
+	provider2 "oneclickvirt/service/provider"
+	"oneclickvirt/service/resources"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// executeCloneInstanceTask 执行克隆实例任务
+// 克隆实例记录、配额和Provider资源在提交任务时已预先占用，失败时在此回滚
+func (s *TaskService) executeCloneInstanceTask(ctx context.Context, task *adminModel.Task) error {
+	s.updateTaskProgress(task.ID, 5, "正在解析任务数据...")
+
+	var taskReq adminModel.CloneInstanceTaskRequest
+	if err := json.Unmarshal([]byte(task.TaskData), &taskReq); err != nil {
+		return fmt.Errorf("解析任务数据失败: %v", err)
+	}
+
+	s.updateTaskProgress(task.ID, 10, "正在获取实例信息...")
+
+	var source providerModel.Instance
+	if err := global.APP_DB.First(&source, taskReq.SourceInstanceId).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			s.rollbackClonedInstance(ctx, taskReq.InstanceId)
+			return fmt.Errorf("源实例不存在")
+		}
+		return fmt.Errorf("获取源实例信息失败: %v", err)
+	}
+
+	var clone providerModel.Instance
+	if err := global.APP_DB.First(&clone, taskReq.InstanceId).Error; err != nil {
+		return fmt.Errorf("获取克隆实例信息失败: %v", err)
+	}
+
+	if source.UserID != task.UserID || clone.UserID != task.UserID {
+		s.rollbackClonedInstance(ctx, clone.ID)
+		return fmt.Errorf("无权限克隆此实例")
+	}
+
+	var provider providerModel.Provider
+	if err := global.APP_DB.First(&provider, clone.ProviderID).Error; err != nil {
+		s.rollbackClonedInstance(ctx, clone.ID)
+		return fmt.Errorf("获取Provider配置失败: %v", err)
+	}
+
+	s.updateTaskProgress(task.ID, 20, "正在克隆实例...")
+
+	prov, err := provider2.GetProviderInstanceByID(provider.ID)
+	if err != nil {
+		s.rollbackClonedInstance(ctx, clone.ID)
+		return fmt.Errorf("获取Provider实例失败: %v", err)
+	}
+
+	cloner, ok := prov.(instanceCloner)
+	if !ok {
+		s.rollbackClonedInstance(ctx, clone.ID)
+		return fmt.Errorf("Provider类型 %s 不支持克隆实例", provider.Type)
+	}
+
+	if err := cloner.CloneInstance(ctx, source.Name, clone.Name); err != nil {
+		global.APP_LOG.Error("克隆实例失败",
+			zap.Uint("taskId", task.ID),
+			zap.String("source", source.Name),
+			zap.String("instance", clone.Name),
+			zap.Error(err))
+		s.rollbackClonedInstance(ctx, clone.ID)
+		return fmt.Errorf("克隆实例失败: %v", err)
+	}
+
+	// 复制成功后失败需同时删除节点上的实例
+	abort := func(err error) error {
+		if delErr := prov.DeleteInstance(ctx, clone.Name); delErr != nil {
+			global.APP_LOG.Error("删除克隆失败的实例失败",
+				zap.Uint("instanceId", clone.ID),
+				zap.String("instanceName", clone.Name),
+				zap.Error(delErr))
+		}
+		s.rollbackClonedInstance(ctx, clone.ID)
+		return err
+	}
+
+	// 克隆实例继承了源实例的密码，重新生成独立的SSH密码
+	s.updateTaskProgress(task.ID, 60, "正在设置新密码...")
+
+	newPassword := utils.GenerateInstancePassword()
+	if err := s.setClonedInstancePassword(ctx, provider.ID, &clone, newPassword); err != nil {
+		// 不沿用源实例密码，避免两个实例共用同一个root密码
+		return abort(fmt.Errorf("设置克隆实例密码失败: %v", err))
+	}
+
+	s.updateTaskProgress(task.ID, 70, "正在获取实例网络信息...")
+
+	updates := map[string]interface{}{
+		"status":   "running",
+		"username": "root",
+		"password": newPassword,
+	}
+	if info, err := prov.GetInstance(ctx, clone.Name); err == nil && info != nil {
+		if info.PrivateIP != "" {
+			updates["private_ip"] = info.PrivateIP
+		} else if info.IP != "" {
+			updates["private_ip"] = info.IP
+		}
+	}
+
+	if err := s.dbService.ExecuteTransaction(ctx, func(tx *gorm.DB) error {
+		return tx.Model(&providerModel.Instance{}).Where("id = ?", clone.ID).Updates(updates).Error
+	}); err != nil {
+		return abort(fmt.Errorf("更新实例信息失败: %v", err))
+	}
+
+	s.updateTaskProgress(task.ID, 80, "正在配置SSH端口映射...")
+	s.createCloneSSHPortMapping(ctx, &clone, &provider)
+
+	s.updateTaskProgress(task.ID, 90, "正在初始化监控...")
+	if provider.EnableTrafficControl {
+		if err := traffic_monitor.GetManager().AttachMonitor(ctx, clone.ID); err != nil {
+			global.APP_LOG.Warn("初始化克隆实例pmacct监控失败",
+				zap.Uint("instanceId", clone.ID),
+				zap.Error(err))
+		}
+	}
+
+	global.APP_LOG.Info("实例克隆成功",
+		zap.Uint("taskId", task.ID),
+		zap.Uint("sourceInstanceId", source.ID),
+		zap.Uint("instanceId", clone.ID),
+		zap.String("instanceName", clone.Name))
+
+	return nil
+}
+
+// setClonedInstancePassword 为克隆出的实例设置密码，刚启动的实例可能尚未就绪，失败时重试
+func (s *TaskService) setClonedInstancePassword(ctx context.Context, providerID uint, instance *providerModel.Instance, password string) error {
+	var lastErr error
+	for attempt := 1; attempt <= 3; attempt++ {
+		if attempt > 1 {
+			time.Sleep(time.Duration(attempt*3) * time.Second)
+		}
+		if lastErr = provider2.GetProviderService().SetInstancePassword(ctx, providerID, instance.Name, password); lastErr == nil {
+			return nil
+		}
+		global.APP_LOG.Warn("设置克隆实例密码失败",
+			zap.Uint("instanceId", instance.ID),
+			zap.Int("attempt", attempt),
+			zap.Error(lastErr))
+	}
+	return lastErr
+}
+
+// createCloneSSHPortMapping 为克隆实例分配新的SSH端口映射，源实例的映射不会被复制
+func (s *TaskService) createCloneSSHPortMapping(ctx context.Context, instance *providerModel.Instance, provider *providerModel.Provider) {
+	if provider.NetworkType == "dedicated_ipv4" || provider.NetworkType == "dedicated_ipv4_ipv6" || provider.NetworkType == "ipv6_only" {
+		return
+	}
+
+	manager := portmapping.NewManager(&portmapping.ManagerConfig{
+		DefaultMappingMethod: provider.IPv4PortMappingMethod,
+	})
+
+	portMappingType := provider.Type
+	if portMappingType == "proxmox" {
+		portMappingType = "iptables"
+	} else if portMappingType == "podman" {
+		// Podman使用与Docker相同的原生端口映射，命令按节点类型使用podman执行
+		portMappingType = "docker"
+	}
+
+	isSSH := true
+	result, err := manager.CreatePortMapping(ctx, portMappingType, &portmapping.PortMappingRequest{
+		InstanceID:    fmt.Sprintf("%d", instance.ID),
+		ProviderID:    provider.ID,
+		Protocol:      "both",
+		HostPort:      0,
+		GuestPort:     22,
+		Description:   "SSH",
+		MappingMethod: provider.IPv4PortMappingMethod,
+		IsSSH:         &isSSH,
+	})
+	if err != nil {
+		global.APP_LOG.Warn("创建克隆实例SSH端口映射失败",
+			zap.Uint("instanceId", instance.ID),
+			zap.Error(err))
+		return
+	}
+
+	global.APP_DB.Model(&providerModel.Instance{}).Where("id = ?", instance.ID).Update("ssh_port", result.HostPort)
+}
+
+// rollbackClonedInstance 克隆失败时释放预占的资源和配额并删除克隆实例记录
+// 记录直接物理删除，软删除的记录仍占用实例名称的唯一索引
+func (s *TaskService) rollbackClonedInstance(ctx context.Context, instanceID uint) {
+	var instance providerModel.Instance
+	if err := global.APP_DB.First(&instance, instanceID).Error; err != nil {
+		return
+	}
+
+	err := s.dbService.ExecuteTransaction(ctx, func(tx *gorm.DB) error {
+		resourceService := &resources.ResourceService{}
+		if err := resourceService.ReleaseResourcesInTx(tx, instance.ProviderID, instance.InstanceType,
+			instance.CPU, instance.Memory, instance.Disk); err != nil {
+			global.APP_LOG.Error("释放Provider资源失败",
+				zap.Uint("instanceId", instance.ID),
+				zap.Error(err))
+		}
+
+		if err := tx.Unscoped().Delete(&instance).Error; err != nil {
+			return fmt.Errorf("删除克隆实例记录失败: %v", err)
+		}
+
+		return resources.NewQuotaService().UpdateUserQuotaAfterDeletionWithTx(tx, instance.UserID, resources.ResourceUsage{
+			CPU:       instance.CPU,
+			Memory:    instance.Memory,
+			Disk:      instance.Disk,
+			Bandwidth: instance.Bandwidth,
+		})
+	})
+	if err != nil {
+		global.APP_LOG.Error("回滚克隆实例失败",
+			zap.Uint("instanceId", instance.ID),
+			zap.Error(err))
+	}
+}
@@ -9,12 +9,25 @@ import (
 	adminModel "oneclickvirt/model/admin"
 	providerModel "oneclickvirt/model/provider"
 	"oneclickvirt/service/resources"
+	"oneclickvirt/service/webhook"
 	"time"
 
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
+// lifecycleWebhookEvents 任务类型与Webhook事件的对应关系，按顺序推送
+// 迁移保留实例记录但在源节点删除、在目标节点重新创建，因此推送删除和创建两个事件
+var lifecycleWebhookEvents = map[string][]string{
+	"create":               {webhook.EventInstanceCreate},
+	"clone":                {webhook.EventInstanceCreate},
+	"create-from-template": {webhook.EventInstanceCreate},
+	"start":                {webhook.EventInstanceStart},
+	"stop":                 {webhook.EventInstanceStop},
+	"delete":               {webhook.EventInstanceDelete},
+	"migrate":              {webhook.EventInstanceDelete, webhook.EventInstanceCreate},
+}
+
 // CompleteTask 完成任务
 func (s *TaskService) CompleteTask(taskID uint, success bool, errorMessage string, resultData map[string]interface{}) error {
 	// 首先获取任务信息
@@ -77,6 +90,13 @@ func (s *TaskService) CompleteTask(taskID uint, success bool, errorMessage strin
 		zap.Bool("success", success),
 		zap.String("errorMessage", errorMessage))
 
+	// 实例生命周期任务成功后推送Webhook
+	if success && task.InstanceID != nil {
+		for _, event := range lifecycleWebhookEvents[task.TaskType] {
+			webhook.NotifyInstanceEvent(event, *task.InstanceID, "")
+		}
+	}
+
 	// 任务完成后，立即触发调度器检查pending任务
 	if global.APP_SCHEDULER != nil {
 		global.APP_SCHEDULER.TriggerTaskProcessing()
@@ -152,6 +172,40 @@ func (s *TaskService) CancelTaskByAdmin(taskID uint, reason string) error {
 	return err
 }
 
+// SetTaskPriority 调整排队中任务的优先级，只允许修改pending状态的任务
+// 优先级不影响任务的取消权限，调整后用户和管理员仍可按原规则取消任务
+func (s *TaskService) SetTaskPriority(taskID uint, priority int) error {
+	if priority < adminModel.TaskPriorityNormal || priority > adminModel.TaskPriorityMax {
+		return fmt.Errorf("优先级必须在%d到%d之间", adminModel.TaskPriorityNormal, adminModel.TaskPriorityMax)
+	}
+
+	err := s.dbService.ExecuteTransaction(context.Background(), func(tx *gorm.DB) error {
+		var task adminModel.Task
+		if err := tx.Select("id", "status").First(&task, taskID).Error; err != nil {
+			return fmt.Errorf("任务不存在")
+		}
+		if task.Status != "pending" {
+			return fmt.Errorf("任务状态[%s]不允许调整优先级", task.Status)
+		}
+
+		result := tx.Model(&adminModel.Task{}).
+			Where("id = ? AND status = ?", taskID, "pending").
+			Update("priority", priority)
+		if result.Error != nil {
+			return result.Error
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	global.APP_LOG.Info("任务优先级已调整",
+		zap.Uint("taskId", taskID),
+		zap.Int("priority", priority))
+	return nil
+}
+
 // cancelPendingTask 取消pending状态的任务
 func (s *TaskService) cancelPendingTask(tx *gorm.DB, taskID uint, reason string) error {
 	now := time.Now()
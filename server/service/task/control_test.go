@@ -0,0 +1,29 @@
+package task
+
+import (
+	"strings"
+	"testing"
+
+	"oneclickvirt/service/webhook"
+)
+
+// TestLifecycleWebhookEvents 测试会创建或删除实例的任务类型都推送对应的Webhook事件
+func TestLifecycleWebhookEvents(t *testing.T) {
+	tests := map[string][]string{
+		"create":               {webhook.EventInstanceCreate},
+		"clone":                {webhook.EventInstanceCreate},
+		"create-from-template": {webhook.EventInstanceCreate},
+		"start":                {webhook.EventInstanceStart},
+		"stop":                 {webhook.EventInstanceStop},
+		"delete":               {webhook.EventInstanceDelete},
+		"migrate":              {webhook.EventInstanceDelete, webhook.EventInstanceCreate},
+		"create-template":      nil,
+		"reset-password":       nil,
+	}
+	for taskType, want := range tests {
+		got := lifecycleWebhookEvents[taskType]
+		if strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("任务类型 %q 的Webhook事件 = %v, 期望 %v", taskType, got, want)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package task
+
+import (
+	"context"
+	"fmt"
+
+	"oneclickvirt/global"
+	adminModel "oneclickvirt/model/admin"
+	providerModel "oneclickvirt/model/provider"
+	"oneclickvirt/service/resources"
+
+	"go.uber.org/zap"
+)
+
+// acquireCreationSlot 为实例创建任务获取Provider的创建名额，其他类型任务直接放行
+// 排队期间任务已处于running状态，取消任务会结束taskCtx，等待随之退出
+func (s *TaskService) acquireCreationSlot(ctx context.Context, task *adminModel.Task) (func(), error) {
+	noop := func() {}
+	if task.TaskType != "create" || task.ProviderID == nil {
+		return noop, nil
+	}
+
+	var provider providerModel.Provider
+	if err := global.APP_DB.Select("id, max_concurrent_creations").First(&provider, *task.ProviderID).Error; err != nil {
+		global.APP_LOG.Warn("查询Provider创建并发限制失败，不限制本次创建",
+			zap.Uint("taskId", task.ID),
+			zap.Error(err))
+		return noop, nil
+	}
+	if provider.MaxConcurrentCreations <= 0 {
+		return noop, nil
+	}
+
+	release, err := resources.GetCreationLimiter().Acquire(ctx, provider.ID, provider.MaxConcurrentCreations, func(active int) {
+		global.APP_LOG.Info("Provider创建名额已满，任务排队等待",
+			zap.Uint("taskId", task.ID),
+			zap.Uint("providerId", provider.ID),
+			zap.Int("active", active),
+			zap.Int("limit", provider.MaxConcurrentCreations))
+		s.updateTaskProgress(task.ID, 0, fmt.Sprintf("节点创建任务已满（%d/%d），排队等待中...", active, provider.MaxConcurrentCreations))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("等待创建名额时任务已结束: %v", err)
+	}
+	return release, nil
+}
@@ -82,11 +82,45 @@ func (s *TaskService) executeDeleteInstanceTask(ctx context.Context, task *admin
 		return fmt.Errorf("任务已取消")
 	}
 
+	providerApiService := &provider2.ProviderApiService{}
+
+	// 清理实例级带宽限速规则，失败不影响后续删除
+	if instance.IngressMbps > 0 || instance.EgressMbps > 0 {
+		if err := providerApiService.SetBandwidthLimitByProviderID(ctx, localProviderID, instance.Name, 0, 0); err != nil {
+			global.APP_LOG.Warn("删除前清理带宽限速失败，继续删除",
+				zap.Uint("taskId", task.ID),
+				zap.String("instanceName", instance.Name),
+				zap.Error(err))
+		}
+	}
+
+	// 清理实例防火墙规则链，失败不影响后续删除
+	var firewallRuleCount int64
+	global.APP_DB.Model(&providerModel.InstanceFirewallRule{}).Where("instance_id = ?", instance.ID).Count(&firewallRuleCount)
+	if firewallRuleCount > 0 {
+		if err := providerApiService.ClearFirewallRulesByProviderID(ctx, localProviderID, instance.Name); err != nil {
+			global.APP_LOG.Warn("删除前清理防火墙规则失败，继续删除",
+				zap.Uint("taskId", task.ID),
+				zap.String("instanceName", instance.Name),
+				zap.Error(err))
+		}
+	}
+
+	// 到期回收时先停止实例，停止失败不影响后续删除
+	if taskReq.StopBeforeDelete {
+		s.updateTaskProgress(task.ID, 22, "正在停止实例...")
+		if err := providerApiService.StopInstanceByProviderID(ctx, localProviderID, instance.Name); err != nil {
+			global.APP_LOG.Warn("删除前停止实例失败，继续删除",
+				zap.Uint("taskId", task.ID),
+				zap.String("instanceName", instance.Name),
+				zap.Error(err))
+		}
+	}
+
 	// 更新进度 (25%)
 	s.updateTaskProgress(task.ID, 25, "正在删除实例...")
 
 	// 调用Provider删除实例，重试机制
-	providerApiService := &provider2.ProviderApiService{}
 	maxRetries := global.APP_CONFIG.Task.DeleteRetryCount
 	if maxRetries <= 0 {
 		maxRetries = 3
@@ -188,6 +222,21 @@ func (s *TaskService) executeDeleteInstanceTask(ctx context.Context, task *admin
 				zap.Error(err))
 		}
 
+		// 删除防火墙规则
+		if err := tx.Where("instance_id = ?", instanceID).Delete(&providerModel.InstanceFirewallRule{}).Error; err != nil {
+			return fmt.Errorf("删除防火墙规则失败: %v", err)
+		}
+
+		// 删除定时开关机计划
+		if err := tx.Where("instance_id = ?", instanceID).Delete(&providerModel.InstanceSchedule{}).Error; err != nil {
+			return fmt.Errorf("删除定时开关机计划失败: %v", err)
+		}
+
+		// 删除共享授权
+		if err := tx.Where("instance_id = ?", instanceID).Delete(&providerModel.InstanceShare{}).Error; err != nil {
+			return fmt.Errorf("删除实例共享授权失败: %v", err)
+		}
+
 		// 释放Provider资源
 		resourceService := &resources.ResourceService{}
 		if err := resourceService.ReleaseResourcesInTx(tx, instanceProviderID, instanceType,
@@ -222,6 +271,9 @@ func (s *TaskService) executeDeleteInstanceTask(ctx context.Context, task *admin
 		return err
 	}
 
+	// 实例已删除，重新同步Provider资源占用
+	(&resources.ResourceService{}).SyncProviderResourcesAsync(instanceProviderID)
+
 	// 标记任务完成
 	operationType := "用户"
 	if taskReq.AdminOperation {
@@ -0,0 +1,35 @@
+package task
+
+import (
+	"errors"
+	"fmt"
+
+	"oneclickvirt/global"
+	adminModel "oneclickvirt/model/admin"
+	"oneclickvirt/model/common"
+
+	"gorm.io/gorm"
+)
+
+// IsTaskFinished 任务是否已结束（完成、失败、取消或超时）
+func IsTaskFinished(status string) bool {
+	switch status {
+	case adminModel.TaskStatusCompleted, adminModel.TaskStatusFailed, adminModel.TaskStatusCancelled, "timeout":
+		return true
+	}
+	return false
+}
+
+// GetUserTaskProgress 获取用户任务当前的状态和进度，用于进度事件流的初始状态和结束检测
+func (s *TaskService) GetUserTaskProgress(taskID, userID uint) (*adminModel.Task, error) {
+	var task adminModel.Task
+	err := global.APP_DB.Select("id", "user_id", "status", "progress", "status_message", "error_message", "cancel_reason").
+		Where("id = ? AND user_id = ?", taskID, userID).First(&task).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, common.NewError(common.CodeNotFound, "任务不存在或无权限")
+		}
+		return nil, fmt.Errorf("查询任务失败: %v", err)
+	}
+	return &task, nil
+}
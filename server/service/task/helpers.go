@@ -77,6 +77,20 @@ func (s *TaskService) executeTaskLogic(ctx context.Context, task *adminModel.Tas
 		return s.executeResetInstanceTask(ctx, task)
 	case "reset-password":
 		return s.executeResetPasswordTask(ctx, task)
+	case "recreate":
+		return s.executeRecreateInstanceTask(ctx, task)
+	case "clone":
+		return s.executeCloneInstanceTask(ctx, task)
+	case "create-template":
+		return s.executeCreateTemplateTask(ctx, task)
+	case "create-from-template":
+		return s.executeTemplateInstanceTask(ctx, task)
+	case "backup":
+		return s.executeBackupInstanceTask(ctx, task)
+	case "restore-backup":
+		return s.executeRestoreBackupTask(ctx, task)
+	case "migrate":
+		return s.executeMigrateTask(ctx, task)
 	case "create-port-mapping":
 		return s.executeCreatePortMappingTask(ctx, task)
 	case "delete-port-mapping":
@@ -95,6 +95,10 @@ func (s *TaskService) executeStartInstanceTask(ctx context.Context, task *adminM
 		return fmt.Errorf("更新实例状态失败: %v", err)
 	}
 
+	// 宿主机侧接口在启动后重建，需要重新应用实例级带宽限速
+	s.reapplyBandwidthLimit(ctx, &instance)
+	s.reapplyFirewallRules(ctx, &instance)
+
 	// 更新进度 (90%)
 	s.updateTaskProgress(task.ID, 90, "正在初始化监控服务...")
 
@@ -391,6 +395,10 @@ func (s *TaskService) executeRestartInstanceTask(ctx context.Context, task *admi
 		return fmt.Errorf("更新实例状态失败: %v", err)
 	}
 
+	// 宿主机侧接口在重启后重建，需要重新应用实例级带宽限速
+	s.reapplyBandwidthLimit(ctx, &instance)
+	s.reapplyFirewallRules(ctx, &instance)
+
 	// 更新进度 (80%)
 	s.updateTaskProgress(task.ID, 80, "正在重新初始化监控服务...")
 
@@ -535,7 +543,7 @@ func (s *TaskService) executeResetPasswordTask(ctx context.Context, task *adminM
 	s.updateTaskProgress(task.ID, 35, "正在生成新密码...")
 
 	// 生成新密码
-	newPassword := utils.GenerateStrongPassword(12)
+	newPassword := utils.GenerateInstancePassword()
 
 	global.APP_LOG.Info("开始重置实例密码",
 		zap.Uint("taskId", task.ID),
@@ -628,3 +636,41 @@ func (s *TaskService) executeResetPasswordTask(ctx context.Context, task *adminM
 
 	return nil
 }
+
+// reapplyBandwidthLimit 重新应用实例级带宽限速，失败仅记录日志
+func (s *TaskService) reapplyBandwidthLimit(ctx context.Context, instance *providerModel.Instance) {
+	if instance.IngressMbps <= 0 && instance.EgressMbps <= 0 {
+		return
+	}
+	providerApiService := &provider2.ProviderApiService{}
+	if err := providerApiService.SetBandwidthLimitByProviderID(ctx, instance.ProviderID, instance.Name, instance.EgressMbps, instance.IngressMbps); err != nil {
+		global.APP_LOG.Warn("重新应用带宽限速失败",
+			zap.Uint("instanceId", instance.ID),
+			zap.String("instanceName", instance.Name),
+			zap.Error(err))
+	}
+}
+
+// reapplyFirewallRules 重新应用实例防火墙规则，实例IP可能在启动后变化，先清理再逐条下发，失败仅记录日志
+func (s *TaskService) reapplyFirewallRules(ctx context.Context, instance *providerModel.Instance) {
+	var rules []providerModel.InstanceFirewallRule
+	if err := global.APP_DB.Where("instance_id = ?", instance.ID).Order("id ASC").Find(&rules).Error; err != nil || len(rules) == 0 {
+		return
+	}
+
+	providerApiService := &provider2.ProviderApiService{}
+	if err := providerApiService.ClearFirewallRulesByProviderID(ctx, instance.ProviderID, instance.Name); err != nil {
+		global.APP_LOG.Warn("清理实例防火墙规则失败",
+			zap.Uint("instanceId", instance.ID),
+			zap.Error(err))
+		return
+	}
+	for _, rule := range rules {
+		if err := providerApiService.ManageFirewallRuleByProviderID(ctx, instance.ProviderID, instance.Name, rule.ToProviderRule()); err != nil {
+			global.APP_LOG.Warn("重新应用防火墙规则失败",
+				zap.Uint("instanceId", instance.ID),
+				zap.Uint("ruleId", rule.ID),
+				zap.Error(err))
+		}
+	}
+}
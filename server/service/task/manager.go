@@ -56,6 +56,20 @@ func (s *TaskService) calculateEstimatedDuration(taskType string, instanceType s
 		return 60 // 1分钟 - 删除操作通常较快
 	case "reset-password":
 		return 30 // 30秒 - 密码重置操作快
+	case "recreate":
+		return 120 // 2分钟 - 重建容器（镜像通常已在节点上）
+	case "clone", "create-template", "create-from-template":
+		if instanceType == "vm" {
+			return 300 // 5分钟 - VM克隆需要完整复制磁盘
+		}
+		return 180 // 3分钟 - 容器克隆
+	case "backup", "restore-backup":
+		if instanceType == "vm" {
+			return 1200 // 20分钟 - 虚拟机磁盘较大，实际耗时取决于数据量
+		}
+		return 600 // 10分钟 - 容器备份/恢复
+	case "migrate":
+		return 900 // 15分钟 - 导出、传输、导入并重建端口映射
 	default:
 		return 60 // 默认1分钟 - 保守估计
 	}
@@ -228,9 +242,9 @@ func (s *TaskService) GetUserTasks(userID uint, req userModel.UserTasksRequest)
 
 		// 一次性查询所有 provider 的 pending 和 running 任务
 		var allProviderTasks []adminModel.Task
-		if err := global.APP_DB.Select("id", "provider_id", "status", "created_at", "estimated_duration", "started_at").
+		if err := global.APP_DB.Select("id", "provider_id", "status", "priority", "created_at", "estimated_duration", "started_at").
 			Where("provider_id IN ? AND status IN (?, ?)", providerIDList, "pending", "running").
-			Order("provider_id ASC, created_at ASC").
+			Order("provider_id ASC, priority DESC, created_at ASC").
 			Find(&allProviderTasks).Error; err == nil {
 			// 按 provider_id 分组
 			for _, pt := range allProviderTasks {
@@ -274,6 +288,7 @@ func (s *TaskService) GetUserTasks(userID uint, req userModel.UserTasksRequest)
 			TaskType:              task.TaskType,
 			Status:                task.Status,
 			Progress:              task.Progress,
+			Priority:              task.Priority,
 			ErrorMessage:          task.ErrorMessage,
 			CancelReason:          task.CancelReason,
 			CreatedAt:             task.CreatedAt,
@@ -520,6 +535,7 @@ func (s *TaskService) GetAdminTasks(req adminModel.AdminTaskListRequest) ([]admi
 			TaskType:              task.TaskType,
 			Status:                task.Status,
 			Progress:              task.Progress,
+			Priority:              task.Priority,
 			ErrorMessage:          task.ErrorMessage,
 			CancelReason:          task.CancelReason,
 			CreatedAt:             task.CreatedAt,
@@ -668,6 +684,7 @@ func (s *TaskService) GetTaskDetail(taskID uint) (*adminModel.AdminTaskDetailRes
 			TaskType:              task.TaskType,
 			Status:                task.Status,
 			Progress:              task.Progress,
+			Priority:              task.Priority,
 			ErrorMessage:          task.ErrorMessage,
 			CancelReason:          task.CancelReason,
 			CreatedAt:             task.CreatedAt,
@@ -0,0 +1,455 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"oneclickvirt/global"
+	adminModel "oneclickvirt/model/admin"
+	providerModel "oneclickvirt/model/provider"
+	systemModel "oneclickvirt/model/system"
+	"oneclickvirt/provider"
+	traffic_monitor "oneclickvirt/service/admin/traffic_monitor"
+	provider2 "oneclickvirt/service/provider"
+	"oneclickvirt/service/resources"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// migrationContext 迁移任务上下文，记录回滚所需的状态
+type migrationContext struct {
+	Instance    providerModel.Instance
+	Source      providerModel.Provider
+	Target      providerModel.Provider
+	SystemImage *systemModel.SystemImage
+
+	WasRunning       bool
+	TargetCreated    bool
+	MonitorDetached  bool
+	Committed        bool
+	OriginalSSHPort  int
+	OriginalStatus   string
+	TargetPrivateIP  string
+	TargetSSHPort    int
+	TargetPortsReady bool
+}
+
+// importedInstancePreparer 导入的实例在启动前需要清理源节点配置的Provider实现的可选接口（Incus）
+type importedInstancePreparer interface {
+	PrepareImportedInstance(ctx context.Context, instanceName string) error
+}
+
+// portMappingRebinder 需要在实例启动后按本节点分配重新配置端口映射的Provider实现的可选接口（Incus）
+type portMappingRebinder interface {
+	RebindPortMappings(ctx context.Context, instanceName string) error
+}
+
+// executeMigrateTask 执行迁移实例任务
+func (s *TaskService) executeMigrateTask(ctx context.Context, task *adminModel.Task) error {
+	var taskReq adminModel.MigrateInstanceTaskRequest
+	if err := json.Unmarshal([]byte(task.TaskData), &taskReq); err != nil {
+		return fmt.Errorf("解析任务数据失败: %v", err)
+	}
+
+	return s.MigrateInstance(ctx, taskReq.InstanceId, taskReq.TargetProviderId, func(percentage int, message string) {
+		s.updateTaskProgress(task.ID, percentage, message)
+	})
+}
+
+// MigrateInstance 将实例迁移到同类型的其他节点
+// 流程：停止源实例 → 在目标节点创建同配置的实例 → 源实例导出后流式导入目标实例 → 切换数据库记录 → 重新初始化监控 → 删除源实例
+// 切换数据库记录之前的任何失败（包括任务被取消）都会删除目标节点上的实例并恢复源实例，源实例只在切换成功后才删除
+func (s *TaskService) MigrateInstance(ctx context.Context, instanceID, targetProviderID uint, progress provider.ProgressCallback) (err error) {
+	if progress == nil {
+		progress = func(int, string) {}
+	}
+
+	progress(5, "正在校验迁移条件...")
+	mc, err := s.prepareMigration(ctx, instanceID, targetProviderID)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil && !mc.Committed {
+			s.rollbackMigration(mc, err)
+		}
+	}()
+
+	providerApiService := &provider2.ProviderApiService{}
+	name := mc.Instance.Name
+
+	if mc.WasRunning {
+		progress(10, "正在停止源实例...")
+		if err := providerApiService.StopInstanceByProviderID(ctx, mc.Source.ID, name); err != nil {
+			return fmt.Errorf("停止源实例失败: %v", err)
+		}
+	}
+
+	progress(15, "正在为目标节点分配端口...")
+	ports, err := s.allocateMigrationPorts(mc)
+	if err != nil {
+		return err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("迁移已取消: %v", err)
+	}
+
+	progress(20, "正在目标节点创建实例...")
+	createReq := provider2.CreateInstanceRequest{
+		InstanceConfig: providerModel.ProviderInstanceConfig{
+			Name:         name,
+			Image:        mc.Instance.Image,
+			InstanceType: mc.Instance.InstanceType,
+			StoragePool:  mc.Target.InstanceStoragePool(),
+			CPU:          fmt.Sprintf("%d", mc.Instance.CPU),
+			Memory:       fmt.Sprintf("%dMB", mc.Instance.Memory),
+			Disk:         fmt.Sprintf("%dMB", mc.Instance.Disk),
+			Metadata:     make(map[string]string),
+			Ports:        ports,
+			IngressMbps:  mc.Instance.IngressMbps,
+			EgressMbps:   mc.Instance.EgressMbps,
+
+			Network:           mc.Instance.Network,
+			NetworkInterfaces: mc.Instance.NetworkInterfaces,
+			DNSServers:        mc.Instance.DNSServers,
+			Hostname:          mc.Instance.Hostname,
+			MemorySwapMB:      mc.Instance.MemorySwapMB,
+			Labels:            mc.Instance.Tags,
+		},
+		SystemImageID: mc.SystemImage.ID,
+	}
+	// 创建失败时Provider上可能残留部分资源，同样需要清理
+	mc.TargetCreated = true
+	if err := providerApiService.CreateInstanceByProviderID(ctx, mc.Target.ID, createReq); err != nil {
+		return fmt.Errorf("在目标节点创建实例失败: %v", err)
+	}
+
+	progress(45, "正在停止目标实例...")
+	if err := providerApiService.StopInstanceByProviderID(ctx, mc.Target.ID, name); err != nil {
+		return fmt.Errorf("停止目标实例失败: %v", err)
+	}
+
+	progress(50, "正在传输实例数据...")
+	if err := s.transferInstanceData(ctx, mc); err != nil {
+		return err
+	}
+
+	prov, err := provider2.GetProviderInstanceByID(mc.Target.ID)
+	if err != nil {
+		return fmt.Errorf("获取目标节点失败: %v", err)
+	}
+	if preparer, ok := prov.(importedInstancePreparer); ok {
+		if err := preparer.PrepareImportedInstance(ctx, name); err != nil {
+			return fmt.Errorf("清理导入实例的源节点配置失败: %v", err)
+		}
+	}
+
+	if mc.WasRunning {
+		progress(80, "正在启动目标实例...")
+		if err := providerApiService.StartInstanceByProviderID(ctx, mc.Target.ID, name); err != nil {
+			return fmt.Errorf("启动目标实例失败: %v", err)
+		}
+		if info, err := prov.GetInstance(ctx, name); err == nil && info != nil {
+			mc.TargetPrivateIP = info.PrivateIP
+			if mc.TargetPrivateIP == "" {
+				mc.TargetPrivateIP = info.IP
+			}
+		}
+	}
+
+	// 切换数据库记录前最后一次响应取消，之后不再回滚
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("迁移已取消: %v", err)
+	}
+
+	progress(85, "正在切换实例记录...")
+	if err := s.commitMigration(ctx, mc); err != nil {
+		return err
+	}
+
+	s.finishMigration(ctx, mc, progress)
+
+	global.APP_LOG.Info("实例迁移完成",
+		zap.Uint("instanceId", mc.Instance.ID),
+		zap.String("instanceName", name),
+		zap.Uint("sourceProviderId", mc.Source.ID),
+		zap.Uint("targetProviderId", mc.Target.ID))
+	progress(100, "迁移完成")
+	return nil
+}
+
+// prepareMigration 查询并校验迁移所需的数据，校验通过后将实例标记为迁移中
+func (s *TaskService) prepareMigration(ctx context.Context, instanceID, targetProviderID uint) (*migrationContext, error) {
+	mc := &migrationContext{}
+	if err := global.APP_DB.First(&mc.Instance, instanceID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("实例不存在")
+		}
+		return nil, fmt.Errorf("获取实例信息失败: %v", err)
+	}
+	if err := global.APP_DB.First(&mc.Source, mc.Instance.ProviderID).Error; err != nil {
+		return nil, fmt.Errorf("获取源节点失败: %v", err)
+	}
+	if err := global.APP_DB.First(&mc.Target, targetProviderID).Error; err != nil {
+		return nil, fmt.Errorf("目标节点不存在")
+	}
+
+	systemImage, err := provider2.ValidateMigration(&mc.Instance, &mc.Source, &mc.Target)
+	if err != nil {
+		return nil, err
+	}
+	mc.SystemImage = systemImage
+
+	for _, providerID := range []uint{mc.Source.ID, mc.Target.ID} {
+		prov, err := provider2.GetProviderInstanceByID(providerID)
+		if err != nil {
+			return nil, fmt.Errorf("获取Provider失败: %v", err)
+		}
+		if err := provider2.CheckProviderConnection(prov); err != nil {
+			return nil, fmt.Errorf("Provider %d 不可用: %v", providerID, err)
+		}
+	}
+
+	mc.WasRunning = mc.Instance.Status == "running"
+	mc.OriginalStatus = mc.Instance.Status
+	mc.OriginalSSHPort = mc.Instance.SSHPort
+	if err := global.APP_DB.Model(&providerModel.Instance{}).Where("id = ?", mc.Instance.ID).
+		Update("status", "migrating").Error; err != nil {
+		return nil, fmt.Errorf("更新实例状态失败: %v", err)
+	}
+	return mc, nil
+}
+
+// allocateMigrationPorts 在目标节点上为实例分配默认端口映射，返回Docker创建容器时使用的端口参数
+// 源节点上的端口映射在切换记录时释放；用户手动添加的端口映射不会迁移
+func (s *TaskService) allocateMigrationPorts(mc *migrationContext) ([]string, error) {
+	portMappingService := &resources.PortMappingService{}
+	mc.TargetPortsReady = true
+	if err := portMappingService.CreateDefaultPortMappings(mc.Instance.ID, mc.Target.ID); err != nil {
+		return nil, fmt.Errorf("为目标节点分配端口失败: %v", err)
+	}
+
+	var targetPorts []providerModel.Port
+	if err := global.APP_DB.Where("instance_id = ? AND provider_id = ?", mc.Instance.ID, mc.Target.ID).
+		Find(&targetPorts).Error; err != nil {
+		return nil, fmt.Errorf("获取目标节点端口映射失败: %v", err)
+	}
+
+	var ports []string
+	for _, port := range targetPorts {
+		if port.IsSSH {
+			mc.TargetSSHPort = port.HostPort
+		}
+		if mc.Target.Type != "docker" {
+			continue
+		}
+		if port.Protocol == "both" {
+			ports = append(ports,
+				fmt.Sprintf("0.0.0.0:%d:%d/tcp", port.HostPort, port.GuestPort),
+				fmt.Sprintf("0.0.0.0:%d:%d/udp", port.HostPort, port.GuestPort))
+		} else {
+			ports = append(ports, fmt.Sprintf("0.0.0.0:%d:%d/%s", port.HostPort, port.GuestPort, port.Protocol))
+		}
+	}
+	return ports, nil
+}
+
+// transferInstanceData 将源实例导出的归档通过管道边读边导入目标实例，不在面板服务器上落盘
+func (s *TaskService) transferInstanceData(ctx context.Context, mc *migrationContext) error {
+	providerApiService := &provider2.ProviderApiService{}
+	pipeReader, pipeWriter := io.Pipe()
+	exportDone := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				err := fmt.Errorf("导出实例时发生panic: %v", r)
+				pipeWriter.CloseWithError(err)
+				exportDone <- err
+			}
+		}()
+		_, err := providerApiService.BackupInstanceByProviderID(ctx, mc.Source.ID, mc.Instance.Name, pipeWriter)
+		pipeWriter.CloseWithError(err)
+		exportDone <- err
+	}()
+
+	restoreErr := providerApiService.RestoreFromBackupByProviderID(ctx, mc.Target.ID, mc.Instance.Name,
+		migrationArchiveName(mc.Target.Type, mc.Instance.Name), pipeReader)
+	// 导入失败时关闭管道，让导出端不再阻塞在写入上
+	pipeReader.CloseWithError(restoreErr)
+	exportErr := <-exportDone
+
+	if exportErr != nil {
+		return fmt.Errorf("导出源实例失败: %v", exportErr)
+	}
+	if restoreErr != nil {
+		return fmt.Errorf("导入目标实例失败: %v", restoreErr)
+	}
+	return nil
+}
+
+// migrationArchiveName 目标节点上接收归档使用的文件名，与各Provider导出时的格式一致
+func migrationArchiveName(providerType, instanceName string) string {
+	if providerType == "incus" {
+		return instanceName + ".tar.gz"
+	}
+	return instanceName + ".tar"
+}
+
+// commitMigration 将实例记录切换到目标节点，释放源节点的端口和资源占用
+func (s *TaskService) commitMigration(ctx context.Context, mc *migrationContext) error {
+	// 源节点的监控依赖实例当前所在的Provider，需在切换记录前清理
+	if err := traffic_monitor.GetManager().DetachMonitor(ctx, mc.Instance.ID); err != nil {
+		global.APP_LOG.Warn("清理源节点流量监控失败",
+			zap.Uint("instanceId", mc.Instance.ID),
+			zap.Error(err))
+	}
+	mc.MonitorDetached = true
+
+	publicIP := mc.Target.PortIP
+	if publicIP == "" {
+		publicIP = mc.Target.Endpoint
+	}
+	updates := map[string]interface{}{
+		"provider_id":   mc.Target.ID,
+		"provider":      mc.Target.Name,
+		"public_ip":     publicIP,
+		"status":        mc.OriginalStatus,
+		"reserved_ipv4": "",
+		"reserved_ipv6": "",
+	}
+	if mc.TargetSSHPort > 0 {
+		updates["ssh_port"] = mc.TargetSSHPort
+	}
+	if mc.TargetPrivateIP != "" {
+		updates["private_ip"] = mc.TargetPrivateIP
+	}
+
+	err := s.dbService.ExecuteTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Model(&providerModel.Instance{}).Where("id = ?", mc.Instance.ID).Updates(updates).Error; err != nil {
+			return fmt.Errorf("更新实例记录失败: %v", err)
+		}
+		if err := tx.Where("instance_id = ? AND provider_id = ?", mc.Instance.ID, mc.Source.ID).
+			Delete(&providerModel.Port{}).Error; err != nil {
+			return fmt.Errorf("释放源节点端口映射失败: %v", err)
+		}
+
+		resourceService := &resources.ResourceService{}
+		if err := resourceService.AllocateResourcesInTx(tx, mc.Target.ID, mc.Instance.InstanceType,
+			mc.Instance.CPU, mc.Instance.Memory, mc.Instance.Disk); err != nil {
+			return fmt.Errorf("占用目标节点资源失败: %v", err)
+		}
+		if err := resourceService.ReleaseResourcesInTx(tx, mc.Source.ID, mc.Instance.InstanceType,
+			mc.Instance.CPU, mc.Instance.Memory, mc.Instance.Disk); err != nil {
+			global.APP_LOG.Warn("释放源节点资源失败",
+				zap.Uint("providerId", mc.Source.ID),
+				zap.Error(err))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	mc.Committed = true
+	mc.Instance.ProviderID = mc.Target.ID
+	return nil
+}
+
+// finishMigration 切换记录后在目标节点上恢复端口映射、限速、防火墙和监控，并删除源节点上的实例
+// 此阶段的失败只记录日志，实例已在目标节点上可用
+func (s *TaskService) finishMigration(ctx context.Context, mc *migrationContext, progress provider.ProgressCallback) {
+	name := mc.Instance.Name
+
+	if mc.WasRunning {
+		progress(88, "正在配置目标节点端口映射...")
+		if prov, err := provider2.GetProviderInstanceByID(mc.Target.ID); err == nil {
+			if rebinder, ok := prov.(portMappingRebinder); ok {
+				if err := rebinder.RebindPortMappings(ctx, name); err != nil {
+					global.APP_LOG.Warn("配置目标节点端口映射失败",
+						zap.Uint("instanceId", mc.Instance.ID),
+						zap.Error(err))
+				}
+			}
+		}
+		s.reapplyBandwidthLimit(ctx, &mc.Instance)
+		s.reapplyFirewallRules(ctx, &mc.Instance)
+	}
+
+	progress(92, "正在初始化流量监控...")
+	if err := traffic_monitor.GetManager().AttachMonitor(ctx, mc.Instance.ID); err != nil {
+		global.APP_LOG.Warn("初始化目标节点流量监控失败",
+			zap.Uint("instanceId", mc.Instance.ID),
+			zap.Error(err))
+	}
+
+	progress(96, "正在删除源节点上的实例...")
+	providerApiService := &provider2.ProviderApiService{}
+	if err := providerApiService.DeleteInstanceByProviderID(ctx, mc.Source.ID, name); err != nil {
+		global.APP_LOG.Error("迁移完成但删除源节点上的实例失败，需要手动清理",
+			zap.Uint("instanceId", mc.Instance.ID),
+			zap.String("instanceName", name),
+			zap.Uint("sourceProviderId", mc.Source.ID),
+			zap.Error(err))
+	}
+}
+
+// rollbackMigration 切换记录前迁移失败时删除目标节点上的实例、释放目标节点端口并恢复源实例
+// 任务可能因取消而失败，回滚使用独立的context
+func (s *TaskService) rollbackMigration(mc *migrationContext, cause error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	global.APP_LOG.Warn("实例迁移失败，开始回滚",
+		zap.Uint("instanceId", mc.Instance.ID),
+		zap.Uint("targetProviderId", mc.Target.ID),
+		zap.Error(cause))
+
+	providerApiService := &provider2.ProviderApiService{}
+	name := mc.Instance.Name
+
+	if mc.TargetCreated {
+		if err := providerApiService.DeleteInstanceByProviderID(ctx, mc.Target.ID, name); err != nil {
+			global.APP_LOG.Warn("回滚时删除目标节点上的实例失败",
+				zap.Uint("targetProviderId", mc.Target.ID),
+				zap.String("instanceName", name),
+				zap.Error(err))
+		}
+	}
+
+	if mc.TargetPortsReady {
+		if err := global.APP_DB.Where("instance_id = ? AND provider_id = ?", mc.Instance.ID, mc.Target.ID).
+			Delete(&providerModel.Port{}).Error; err != nil {
+			global.APP_LOG.Warn("回滚时释放目标节点端口映射失败", zap.Error(err))
+		}
+	}
+
+	if mc.MonitorDetached {
+		if err := traffic_monitor.GetManager().AttachMonitor(ctx, mc.Instance.ID); err != nil {
+			global.APP_LOG.Warn("回滚时恢复源节点流量监控失败", zap.Error(err))
+		}
+	}
+
+	if mc.WasRunning {
+		if err := providerApiService.StartInstanceByProviderID(ctx, mc.Source.ID, name); err != nil {
+			global.APP_LOG.Error("回滚时启动源实例失败",
+				zap.Uint("instanceId", mc.Instance.ID),
+				zap.Error(err))
+		}
+	}
+
+	if err := global.APP_DB.Model(&providerModel.Instance{}).Where("id = ?", mc.Instance.ID).
+		Updates(map[string]interface{}{
+			"status":   mc.OriginalStatus,
+			"ssh_port": mc.OriginalSSHPort,
+		}).Error; err != nil {
+		global.APP_LOG.Error("回滚时恢复实例状态失败",
+			zap.Uint("instanceId", mc.Instance.ID),
+			zap.Error(err))
+	}
+}
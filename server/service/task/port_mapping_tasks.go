@@ -128,8 +128,8 @@ func (s *TaskService) executeCreatePortMappingTask(ctx context.Context, task *ad
 				currentPrivateIP = instance.PrivateIP
 			}
 		}
-	case "docker":
-		// Docker通常不需要内网IP映射
+	case "docker", "podman":
+		// Docker/Podman通常不需要内网IP映射
 		currentPrivateIP = instance.PrivateIP
 	default:
 		currentPrivateIP = instance.PrivateIP
@@ -164,6 +164,9 @@ func (s *TaskService) executeCreatePortMappingTask(ctx context.Context, task *ad
 	portMappingType := localProviderType
 	if portMappingType == "proxmox" {
 		portMappingType = "iptables"
+	} else if portMappingType == "podman" {
+		// Podman使用与Docker相同的原生端口映射，命令按节点类型使用podman执行
+		portMappingType = "docker"
 	}
 
 	portReq := &portmapping.PortMappingRequest{
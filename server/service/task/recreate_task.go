@@ -0,0 +1,94 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"oneclickvirt/global"
+	adminModel "oneclickvirt/model/admin"
+	providerModel "oneclickvirt/model/provider"
+	provider2 "oneclickvirt/service/provider"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// executeRecreateInstanceTask 执行重建实例任务
+// 按实例创建时保存的配置重建容器并保留数据卷，与删除后重新创建不同，实例记录、端口和密码保持不变
+func (s *TaskService) executeRecreateInstanceTask(ctx context.Context, task *adminModel.Task) error {
+	s.updateTaskProgress(task.ID, 5, "正在解析任务数据...")
+
+	var taskReq adminModel.InstanceOperationTaskRequest
+	if err := json.Unmarshal([]byte(task.TaskData), &taskReq); err != nil {
+		return fmt.Errorf("解析任务数据失败: %v", err)
+	}
+
+	var instance providerModel.Instance
+	if err := global.APP_DB.First(&instance, taskReq.InstanceId).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("实例不存在")
+		}
+		return fmt.Errorf("获取实例信息失败: %v", err)
+	}
+	if instance.UserID != task.UserID {
+		return fmt.Errorf("无权限操作此实例")
+	}
+	if instance.CreateConfig == nil {
+		global.APP_DB.Model(&instance).Update("status", "stopped")
+		return fmt.Errorf("实例没有保存创建配置，无法重建")
+	}
+
+	var provider providerModel.Provider
+	if err := global.APP_DB.First(&provider, instance.ProviderID).Error; err != nil {
+		return fmt.Errorf("获取Provider配置失败: %v", err)
+	}
+
+	config := *instance.CreateConfig
+	// 端口映射在创建后可能变化，按当前的端口映射重建
+	if provider.Type == "docker" || provider.Type == "podman" {
+		var ports []providerModel.Port
+		if err := global.APP_DB.Where("instance_id = ?", instance.ID).Find(&ports).Error; err == nil {
+			config.Ports = make([]string, 0, len(ports))
+			for _, port := range ports {
+				config.Ports = append(config.Ports, fmt.Sprintf("0.0.0.0:%d:%d/%s", port.HostPort, port.GuestPort, port.Protocol))
+			}
+		}
+	}
+
+	s.updateTaskProgress(task.ID, 30, "正在按原配置重建实例...")
+
+	providerApiService := &provider2.ProviderApiService{}
+	if err := providerApiService.RecreateInstanceByProviderID(ctx, provider.ID, config); err != nil {
+		global.APP_DB.Model(&instance).Update("status", "stopped")
+		return err
+	}
+
+	// 新容器的系统盘为全新镜像，重新设置为实例当前的登录密码
+	s.updateTaskProgress(task.ID, 75, "正在恢复登录密码...")
+	if instance.Password != "" {
+		if err := provider2.GetProviderService().SetInstancePassword(ctx, provider.ID, instance.Name, instance.Password); err != nil {
+			global.APP_LOG.Warn("重建后恢复实例密码失败",
+				zap.Uint("instanceId", instance.ID),
+				zap.Error(err))
+		}
+	}
+
+	if err := global.APP_DB.Model(&instance).Update("status", "running").Error; err != nil {
+		return fmt.Errorf("更新实例状态失败: %v", err)
+	}
+
+	s.updateTaskProgress(task.ID, 90, "正在重新应用网络配置...")
+	s.reapplyBandwidthLimit(ctx, &instance)
+	s.reapplyFirewallRules(ctx, &instance)
+
+	s.updateTaskProgress(task.ID, 100, "实例重建完成")
+
+	global.APP_LOG.Info("实例重建成功",
+		zap.Uint("taskId", task.ID),
+		zap.Uint("instanceId", instance.ID),
+		zap.String("instanceName", instance.Name))
+
+	return nil
+}
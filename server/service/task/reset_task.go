@@ -84,6 +84,13 @@ func (s *TaskService) executeResetTask(ctx context.Context, task *adminModel.Tas
 		return err
 	}
 
+	// 重新应用防火墙规则，失败不影响重置结果
+	s.reapplyFirewallRules(ctx, &providerModel.Instance{
+		ID:         resetCtx.NewInstanceID,
+		Name:       resetCtx.OldInstanceName,
+		ProviderID: resetCtx.Provider.ID,
+	})
+
 	// 阶段8: 重新初始化监控（短事务）
 	if err := s.resetTask_ReinitializeMonitoring(ctx, task, &resetCtx); err != nil {
 		return err
@@ -192,6 +199,16 @@ func (s *TaskService) resetTask_RenameAndCreateNew(ctx context.Context, task *ad
 			ExpiredAt:    resetCtx.Instance.ExpiredAt,
 			PublicIP:     resetCtx.Provider.Endpoint,
 			MaxTraffic:   resetCtx.Instance.MaxTraffic,
+			IngressMbps:  resetCtx.Instance.IngressMbps,
+			EgressMbps:   resetCtx.Instance.EgressMbps,
+
+			Network:           resetCtx.Instance.Network,
+			NetworkInterfaces: resetCtx.Instance.NetworkInterfaces,
+			GPUDevices:        resetCtx.Instance.GPUDevices,
+			DNSServers:        resetCtx.Instance.DNSServers,
+			Hostname:          resetCtx.Instance.Hostname,
+			MemorySwapMB:      resetCtx.Instance.MemorySwapMB,
+			Volumes:           resetCtx.Instance.Volumes,
 		}
 
 		if err := tx.Create(&newInstance).Error; err != nil {
@@ -199,6 +216,20 @@ func (s *TaskService) resetTask_RenameAndCreateNew(ctx context.Context, task *ad
 		}
 
 		resetCtx.NewInstanceID = newInstance.ID
+
+		// 4. 防火墙规则转移到新实例，实例创建完成后重新应用
+		if err := tx.Model(&providerModel.InstanceFirewallRule{}).
+			Where("instance_id = ?", resetCtx.OldInstanceID).
+			Update("instance_id", newInstance.ID).Error; err != nil {
+			return fmt.Errorf("转移防火墙规则失败: %v", err)
+		}
+
+		// 5. 定时开关机计划转移到新实例
+		if err := tx.Model(&providerModel.InstanceSchedule{}).
+			Where("instance_id = ?", resetCtx.OldInstanceID).
+			Update("instance_id", newInstance.ID).Error; err != nil {
+			return fmt.Errorf("转移定时开关机计划失败: %v", err)
+		}
 		return nil
 	})
 
@@ -257,17 +288,28 @@ func (s *TaskService) resetTask_CreateNewInstance(ctx context.Context, task *adm
 			Name:         resetCtx.OldInstanceName,
 			Image:        resetCtx.Instance.Image,
 			InstanceType: resetCtx.Instance.InstanceType,
+			StoragePool:  resetCtx.Provider.InstanceStoragePool(),
 			CPU:          fmt.Sprintf("%d", resetCtx.Instance.CPU),
 			Memory:       fmt.Sprintf("%dMB", resetCtx.Instance.Memory),
 			Disk:         fmt.Sprintf("%dMB", resetCtx.Instance.Disk),
 			Env:          map[string]string{"RESET_OPERATION": "true"},
 			Metadata:     make(map[string]string),
+			IngressMbps:  resetCtx.Instance.IngressMbps,
+			EgressMbps:   resetCtx.Instance.EgressMbps,
+
+			Network:           resetCtx.Instance.Network,
+			NetworkInterfaces: resetCtx.Instance.NetworkInterfaces,
+			GPUDevices:        resetCtx.Instance.GPUDevices,
+			DNSServers:        resetCtx.Instance.DNSServers,
+			Hostname:          resetCtx.Instance.Hostname,
+			MemorySwapMB:      resetCtx.Instance.MemorySwapMB,
+			Volumes:           resetCtx.Instance.Volumes,
 		},
 		SystemImageID: resetCtx.SystemImage.ID,
 	}
 
-	// Docker特殊处理：端口映射
-	if resetCtx.Provider.Type == "docker" && len(resetCtx.OldPortMappings) > 0 {
+	// Docker/Podman特殊处理：端口映射
+	if (resetCtx.Provider.Type == "docker" || resetCtx.Provider.Type == "podman") && len(resetCtx.OldPortMappings) > 0 {
 		var ports []string
 		for _, oldPort := range resetCtx.OldPortMappings {
 			portMapping := fmt.Sprintf("0.0.0.0:%d:%d/%s", oldPort.HostPort, oldPort.GuestPort, oldPort.Protocol)
@@ -285,6 +327,12 @@ func (s *TaskService) resetTask_CreateNewInstance(ctx context.Context, task *adm
 		return fmt.Errorf("重置实例失败（重建阶段）: %v", err)
 	}
 
+	// 保存创建配置，重建实例时按相同配置重新应用
+	if err := global.APP_DB.Model(&providerModel.Instance{ID: resetCtx.NewInstanceID}).Select("create_config").
+		Updates(&providerModel.Instance{CreateConfig: &createReq.InstanceConfig}).Error; err != nil {
+		global.APP_LOG.Warn("保存实例创建配置失败", zap.Uint("instanceId", resetCtx.NewInstanceID), zap.Error(err))
+	}
+
 	// 等待实例启动
 	time.Sleep(15 * time.Second)
 
@@ -300,7 +348,7 @@ func (s *TaskService) resetTask_SetPassword(ctx context.Context, task *adminMode
 	s.updateTaskProgress(task.ID, 70, "正在设置新密码...")
 
 	// 生成新密码
-	resetCtx.NewPassword = utils.GenerateStrongPassword(12)
+	resetCtx.NewPassword = utils.GenerateInstancePassword()
 
 	// 获取内网IP（如果需要）
 	s.resetTask_GetPrivateIP(ctx, resetCtx)
@@ -409,8 +457,8 @@ func (s *TaskService) resetTask_RestorePortMappings(ctx context.Context, task *a
 	successCount := 0
 	failCount := 0
 
-	if resetCtx.Provider.Type == "docker" {
-		// Docker: 只需恢复数据库记录
+	if resetCtx.Provider.Type == "docker" || resetCtx.Provider.Type == "podman" {
+		// Docker/Podman: 只需恢复数据库记录
 		for _, oldPort := range resetCtx.OldPortMappings {
 			err := s.dbService.ExecuteTransaction(ctx, func(tx *gorm.DB) error {
 				newPort := providerModel.Port{
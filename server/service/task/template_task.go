@@ -0,0 +1,261 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"oneclickvirt/global"
+	adminModel "oneclickvirt/model/admin"
+	providerModel "oneclickvirt/model/provider"
+	providerPkg "oneclickvirt/provider"
+	traffic_monitor "oneclickvirt/service/admin/traffic_monitor"
+	provider2 "oneclickvirt/service/provider"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// instanceCloner 支持克隆实例的Provider
+type instanceCloner interface {
+	CloneInstance(ctx context.Context, sourceName, newName string) error
+}
+
+// executeCreateTemplateTask 执行创建实例模板任务
+// 在源实例所在节点复制出模板副本并停止，复制失败时模板标记为failed
+func (s *TaskService) executeCreateTemplateTask(ctx context.Context, task *adminModel.Task) error {
+	s.updateTaskProgress(task.ID, 5, "正在解析任务数据...")
+
+	var taskReq adminModel.CreateTemplateTaskRequest
+	if err := json.Unmarshal([]byte(task.TaskData), &taskReq); err != nil {
+		return fmt.Errorf("解析任务数据失败: %v", err)
+	}
+
+	var template providerModel.InstanceTemplate
+	if err := global.APP_DB.First(&template, taskReq.TemplateId).Error; err != nil {
+		return fmt.Errorf("获取模板信息失败: %v", err)
+	}
+	fail := func(err error) error {
+		global.APP_DB.Model(&template).Updates(map[string]interface{}{
+			"status":        providerModel.TemplateStatusFailed,
+			"error_message": err.Error(),
+		})
+		return err
+	}
+
+	var source providerModel.Instance
+	if err := global.APP_DB.First(&source, taskReq.SourceInstanceId).Error; err != nil {
+		return fail(fmt.Errorf("获取源实例信息失败: %v", err))
+	}
+
+	prov, err := provider2.GetProviderInstanceByID(template.ProviderID)
+	if err != nil {
+		return fail(fmt.Errorf("获取Provider实例失败: %v", err))
+	}
+	cloner, ok := prov.(instanceCloner)
+	if !ok {
+		return fail(fmt.Errorf("Provider类型 %s 不支持克隆实例", prov.GetType()))
+	}
+
+	s.updateTaskProgress(task.ID, 20, "正在复制模板副本...")
+	if err := cloner.CloneInstance(ctx, source.Name, template.InstanceName); err != nil {
+		global.APP_LOG.Error("复制模板副本失败",
+			zap.Uint("taskId", task.ID),
+			zap.Uint("templateId", template.ID),
+			zap.String("source", source.Name),
+			zap.Error(err))
+		return fail(fmt.Errorf("复制模板副本失败: %v", err))
+	}
+
+	// 克隆会启动新实例，模板副本只作为复制来源，停止以免占用节点资源
+	s.updateTaskProgress(task.ID, 80, "正在停止模板副本...")
+	if err := prov.StopInstance(ctx, template.InstanceName); err != nil {
+		global.APP_LOG.Warn("停止模板副本失败",
+			zap.Uint("templateId", template.ID),
+			zap.String("instanceName", template.InstanceName),
+			zap.Error(err))
+	}
+
+	if err := global.APP_DB.Model(&template).Updates(map[string]interface{}{
+		"status":        providerModel.TemplateStatusReady,
+		"error_message": "",
+	}).Error; err != nil {
+		return fmt.Errorf("更新模板状态失败: %v", err)
+	}
+
+	global.APP_LOG.Info("实例模板创建成功",
+		zap.Uint("taskId", task.ID),
+		zap.Uint("templateId", template.ID),
+		zap.String("templateName", template.Name),
+		zap.Uint("sourceInstanceId", source.ID))
+	return nil
+}
+
+// executeTemplateInstanceTask 执行从模板创建实例任务
+// 实例记录、配额和Provider资源在提交任务时已预先占用，克隆模板副本后按用户选择的规格调整资源并设置密码，
+// 任一步骤失败都删除已创建的实例并回滚
+func (s *TaskService) executeTemplateInstanceTask(ctx context.Context, task *adminModel.Task) error {
+	s.updateTaskProgress(task.ID, 5, "正在解析任务数据...")
+
+	var taskReq adminModel.TemplateInstanceTaskRequest
+	if err := json.Unmarshal([]byte(task.TaskData), &taskReq); err != nil {
+		return fmt.Errorf("解析任务数据失败: %v", err)
+	}
+
+	var instance providerModel.Instance
+	if err := global.APP_DB.First(&instance, taskReq.InstanceId).Error; err != nil {
+		return fmt.Errorf("获取实例信息失败: %v", err)
+	}
+	if instance.UserID != task.UserID {
+		s.rollbackClonedInstance(ctx, instance.ID)
+		return fmt.Errorf("无权限操作此实例")
+	}
+
+	var template providerModel.InstanceTemplate
+	if err := global.APP_DB.First(&template, taskReq.TemplateId).Error; err != nil {
+		s.rollbackClonedInstance(ctx, instance.ID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("模板不存在")
+		}
+		return fmt.Errorf("获取模板信息失败: %v", err)
+	}
+	if template.Status != providerModel.TemplateStatusReady {
+		s.rollbackClonedInstance(ctx, instance.ID)
+		return fmt.Errorf("模板当前状态为 %s，无法创建实例", template.Status)
+	}
+
+	var provider providerModel.Provider
+	if err := global.APP_DB.First(&provider, instance.ProviderID).Error; err != nil {
+		s.rollbackClonedInstance(ctx, instance.ID)
+		return fmt.Errorf("获取Provider配置失败: %v", err)
+	}
+
+	prov, err := provider2.GetProviderInstanceByID(provider.ID)
+	if err != nil {
+		s.rollbackClonedInstance(ctx, instance.ID)
+		return fmt.Errorf("获取Provider实例失败: %v", err)
+	}
+	cloner, ok := prov.(instanceCloner)
+	if !ok {
+		s.rollbackClonedInstance(ctx, instance.ID)
+		return fmt.Errorf("Provider类型 %s 不支持克隆实例", provider.Type)
+	}
+
+	s.updateTaskProgress(task.ID, 20, "正在从模板复制实例...")
+	if err := cloner.CloneInstance(ctx, template.InstanceName, instance.Name); err != nil {
+		global.APP_LOG.Error("从模板复制实例失败",
+			zap.Uint("taskId", task.ID),
+			zap.Uint("templateId", template.ID),
+			zap.String("instance", instance.Name),
+			zap.Error(err))
+		s.rollbackClonedInstance(ctx, instance.ID)
+		return fmt.Errorf("从模板复制实例失败: %v", err)
+	}
+
+	// 复制成功后失败需同时删除节点上的实例
+	abort := func(err error) error {
+		if delErr := prov.DeleteInstance(ctx, instance.Name); delErr != nil {
+			global.APP_LOG.Error("删除从模板创建失败的实例失败",
+				zap.Uint("instanceId", instance.ID),
+				zap.String("instanceName", instance.Name),
+				zap.Error(delErr))
+		}
+		s.rollbackClonedInstance(ctx, instance.ID)
+		return err
+	}
+
+	s.updateTaskProgress(task.ID, 45, "正在调整实例规格...")
+	if err := s.applyTemplateInstanceLimits(ctx, prov, &template, &instance); err != nil {
+		return abort(err)
+	}
+
+	s.updateTaskProgress(task.ID, 60, "正在设置实例密码...")
+	if err := s.setClonedInstancePassword(ctx, provider.ID, &instance, instance.Password); err != nil {
+		return abort(fmt.Errorf("设置实例密码失败: %v", err))
+	}
+
+	s.updateTaskProgress(task.ID, 70, "正在获取实例网络信息...")
+	updates := map[string]interface{}{
+		"status":   "running",
+		"username": "root",
+	}
+	if info, err := prov.GetInstance(ctx, instance.Name); err == nil && info != nil {
+		if info.PrivateIP != "" {
+			updates["private_ip"] = info.PrivateIP
+		} else if info.IP != "" {
+			updates["private_ip"] = info.IP
+		}
+	}
+	if err := s.dbService.ExecuteTransaction(ctx, func(tx *gorm.DB) error {
+		return tx.Model(&providerModel.Instance{}).Where("id = ?", instance.ID).Updates(updates).Error
+	}); err != nil {
+		return fmt.Errorf("更新实例信息失败: %v", err)
+	}
+
+	s.updateTaskProgress(task.ID, 80, "正在配置SSH端口映射...")
+	s.createCloneSSHPortMapping(ctx, &instance, &provider)
+
+	s.updateTaskProgress(task.ID, 90, "正在初始化监控...")
+	if provider.EnableTrafficControl {
+		if err := traffic_monitor.GetManager().AttachMonitor(ctx, instance.ID); err != nil {
+			global.APP_LOG.Warn("初始化模板实例pmacct监控失败",
+				zap.Uint("instanceId", instance.ID),
+				zap.Error(err))
+		}
+	}
+
+	global.APP_LOG.Info("从模板创建实例成功",
+		zap.Uint("taskId", task.ID),
+		zap.Uint("templateId", template.ID),
+		zap.Uint("instanceId", instance.ID),
+		zap.String("instanceName", instance.Name))
+	return nil
+}
+
+// applyTemplateInstanceLimits 将复制出的实例调整为用户选择的规格
+// CPU和内存需要重启才能生效时直接重启实例，磁盘只在大于模板时扩容，带宽限速失败只记录日志
+func (s *TaskService) applyTemplateInstanceLimits(ctx context.Context, prov providerPkg.Provider, template *providerModel.InstanceTemplate, instance *providerModel.Instance) error {
+	if instance.CPU != template.CPU || instance.Memory != template.Memory {
+		updater, ok := prov.(interface {
+			UpdateInstanceLimits(ctx context.Context, instanceName string, cpu int, memoryMB int64) error
+		})
+		if !ok {
+			return fmt.Errorf("Provider类型 %s 不支持调整资源", prov.GetType())
+		}
+		if err := updater.UpdateInstanceLimits(ctx, instance.Name, instance.CPU, instance.Memory); err != nil {
+			if !errors.Is(err, providerPkg.ErrLimitsRequireRestart) {
+				return fmt.Errorf("调整CPU和内存失败: %v", err)
+			}
+			if err := prov.RestartInstance(ctx, instance.Name); err != nil {
+				return fmt.Errorf("重启实例使资源限制生效失败: %v", err)
+			}
+		}
+	}
+
+	if instance.Disk > template.Disk {
+		resizer, ok := prov.(interface {
+			ResizeInstanceDisk(ctx context.Context, instanceName string, newSizeGB int) error
+		})
+		if !ok {
+			return fmt.Errorf("Provider类型 %s 不支持调整磁盘大小", prov.GetType())
+		}
+		if err := resizer.ResizeInstanceDisk(ctx, instance.Name, int((instance.Disk+1023)/1024)); err != nil {
+			return fmt.Errorf("扩容磁盘失败: %v", err)
+		}
+	}
+
+	if instance.Bandwidth != template.Bandwidth {
+		if limiter, ok := prov.(interface {
+			SetBandwidthLimit(ctx context.Context, instanceName string, uploadMbps, downloadMbps int) error
+		}); ok {
+			if err := limiter.SetBandwidthLimit(ctx, instance.Name, instance.Bandwidth, instance.Bandwidth); err != nil {
+				global.APP_LOG.Warn("设置模板实例带宽限制失败",
+					zap.Uint("instanceId", instance.ID),
+					zap.Int("bandwidth", instance.Bandwidth),
+					zap.Error(err))
+			}
+		}
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package task
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -14,6 +15,9 @@ import (
 	"gorm.io/gorm/clause"
 )
 
+// errYieldToHigherPriority 同一Provider上有更高优先级的任务在排队，当前任务暂不执行
+var errYieldToHigherPriority = errors.New("存在更高优先级的排队任务")
+
 // getOrCreateProviderPool 获取或创建Provider工作池
 func (s *TaskService) getOrCreateProviderPool(providerID uint, concurrency int) *ProviderWorkerPool {
 	return s.poolManager.GetOrCreate(providerID, concurrency, s)
@@ -113,6 +117,18 @@ func (pool *ProviderWorkerPool) executeTask(taskReq TaskRequest) {
 			return fmt.Errorf("任务状态已变更，当前状态: %s", currentTask.Status)
 		}
 
+		// 工作池队列是先进先出的，同一Provider上存在更高优先级的排队任务时让出worker，
+		// 当前任务保持pending，由调度器按优先级重新派发
+		var higherPriority int64
+		if err := tx.Model(&adminModel.Task{}).
+			Where("provider_id = ? AND status = ? AND priority > ?", currentTask.ProviderID, "pending", currentTask.Priority).
+			Count(&higherPriority).Error; err != nil {
+			return fmt.Errorf("查询排队任务失败: %v", err)
+		}
+		if higherPriority > 0 {
+			return errYieldToHigherPriority
+		}
+
 		// 使用WHERE条件确保只有pending状态才会被更新
 		result := tx.Model(&adminModel.Task{}).
 			Where("id = ? AND status = ?", task.ID, "pending").
@@ -133,6 +149,12 @@ func (pool *ProviderWorkerPool) executeTask(taskReq TaskRequest) {
 		return nil
 	})
 
+	if errors.Is(updateErr, errYieldToHigherPriority) {
+		global.APP_LOG.Debug("存在更高优先级的排队任务，任务让出worker",
+			zap.Uint("taskId", task.ID),
+			zap.Uint("providerId", pool.ProviderID))
+		return
+	}
 	if updateErr != nil {
 		result.Error = fmt.Errorf("更新任务状态失败: %v", updateErr)
 		global.APP_LOG.Warn("任务状态更新失败，可能被其他worker处理",
@@ -142,8 +164,14 @@ func (pool *ProviderWorkerPool) executeTask(taskReq TaskRequest) {
 		return
 	}
 
-	// 执行具体任务逻辑
-	taskError := pool.TaskService.executeTaskLogic(taskCtx, &task)
+	// 执行具体任务逻辑，创建任务需先取得Provider的创建名额
+	release, taskError := pool.TaskService.acquireCreationSlot(taskCtx, &task)
+	if taskError == nil {
+		func() {
+			defer release()
+			taskError = pool.TaskService.executeTaskLogic(taskCtx, &task)
+		}()
+	}
 	if taskError != nil {
 		result.Error = taskError
 	} else {
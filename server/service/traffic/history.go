@@ -63,44 +63,140 @@ func (h *HistoryService) RecordInstanceTrafficHistory(tx *gorm.DB, instanceID, p
 		history.RecordTime, now, now).Error
 }
 
-// AggregateDailyInstanceTraffic 聚合实例每日流量（从小时数据）
-// 通常在每日凌晨或定时任务中调用
+// dailyHistoryBatchSize 按天聚合实例流量时每批处理的实例数量，避免一次加载过多原始记录
+const dailyHistoryBatchSize = 100
+
+// AggregateDailyInstanceTraffic 聚合实例每日流量，写入hour=0、record_time为当天零点的日度记录
+// pmacct_traffic_records存储累积值，按实例计算当天相邻数据点的增量之和（单位：字节），供7天、30天等长周期图表查询
+// 重复执行时先删除当天已有的hour=0记录再写入，结果幂等
 func (h *HistoryService) AggregateDailyInstanceTraffic(date time.Time) error {
-	year := date.Year()
-	month := int(date.Month())
-	day := date.Day()
+	dayStart := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	dayEnd := dayStart.AddDate(0, 0, 1)
+
+	var instanceIDs []uint
+	if err := global.APP_DB.Model(&monitoringModel.PmacctTrafficRecord{}).
+		Where("timestamp >= ? AND timestamp < ?", dayStart, dayEnd).
+		Distinct("instance_id").
+		Pluck("instance_id", &instanceIDs).Error; err != nil {
+		return fmt.Errorf("查询当日有流量记录的实例失败: %w", err)
+	}
 
-	// 从小时级数据聚合到日级
-	// hour=0表示日级聚合数据
-	return global.APP_DB.Exec(`
-		INSERT INTO instance_traffic_histories 
-			(instance_id, provider_id, user_id, traffic_in, traffic_out, total_used, year, month, day, hour, record_time, created_at, updated_at)
-		SELECT 
-			instance_id,
-			provider_id,
-			user_id,
-			SUM(traffic_in) as traffic_in,
-			SUM(traffic_out) as traffic_out,
-			SUM(total_used) as total_used,
-			year,
-			month,
-			day,
-			0 as hour,
-			? as record_time,
-			? as created_at,
-			? as updated_at
-		FROM instance_traffic_histories
-		WHERE year = ? AND month = ? AND day = ? AND hour > 0 AND deleted_at IS NULL
-		GROUP BY instance_id, provider_id, user_id, year, month, day
-		ON CONFLICT (instance_id, year, month, day, hour)
-		WHERE deleted_at IS NULL
-		DO UPDATE SET
-			traffic_in = EXCLUDED.traffic_in,
-			traffic_out = EXCLUDED.traffic_out,
-			total_used = EXCLUDED.total_used,
-			record_time = EXCLUDED.record_time,
-			updated_at = EXCLUDED.updated_at
-	`, date, time.Now(), time.Now(), year, month, day).Error
+	now := time.Now()
+	for i := 0; i < len(instanceIDs); i += dailyHistoryBatchSize {
+		end := i + dailyHistoryBatchSize
+		if end > len(instanceIDs) {
+			end = len(instanceIDs)
+		}
+		batch := instanceIDs[i:end]
+
+		usage, err := instanceTrafficUsage(batch, dayStart, dayEnd)
+		if err != nil {
+			return err
+		}
+		rows := make([]monitoringModel.InstanceTrafficHistory, 0, len(usage))
+		for _, point := range usage {
+			point.Year = dayStart.Year()
+			point.Month = int(dayStart.Month())
+			point.Day = dayStart.Day()
+			point.Hour = 0
+			point.RecordTime = dayStart
+			point.CreatedAt = now
+			point.UpdatedAt = now
+			rows = append(rows, *point)
+		}
+
+		err = global.APP_DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Unscoped().
+				Where("instance_id IN ? AND year = ? AND month = ? AND day = ? AND hour = 0",
+					batch, dayStart.Year(), int(dayStart.Month()), dayStart.Day()).
+				Delete(&monitoringModel.InstanceTrafficHistory{}).Error; err != nil {
+				return err
+			}
+			if len(rows) == 0 {
+				return nil
+			}
+			return tx.CreateInBatches(rows, dailyHistoryBatchSize).Error
+		})
+		if err != nil {
+			return fmt.Errorf("写入实例日度流量失败: %w", err)
+		}
+	}
+
+	global.APP_LOG.Debug("实例日度流量聚合完成",
+		zap.String("date", dayStart.Format("2006-01-02")),
+		zap.Int("instances", len(instanceIDs)))
+	return nil
+}
+
+// BackfillDailyInstanceTraffic 补齐最近days天（不含今天）缺失的实例日度流量记录
+// 已聚合的日期（存在record_time为当天零点的hour=0记录）会被跳过
+func (h *HistoryService) BackfillDailyInstanceTraffic(days int) error {
+	today := time.Now()
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, today.Location())
+	for i := days; i >= 1; i-- {
+		dayStart := today.AddDate(0, 0, -i)
+		var count int64
+		if err := global.APP_DB.Model(&monitoringModel.InstanceTrafficHistory{}).
+			Where("hour = 0 AND record_time = ?", dayStart).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			continue
+		}
+		if err := h.AggregateDailyInstanceTraffic(dayStart); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// instanceTrafficUsage 计算实例在[start, end)内的流量增量（字节），返回以实例ID为键的结果
+// 以start之前一天内的最后一个数据点为基准，没有基准点时第一个数据点按完整累积值计入，与图表查询规则一致
+func instanceTrafficUsage(instanceIDs []uint, start, end time.Time) (map[uint]*monitoringModel.InstanceTrafficHistory, error) {
+	usage := make(map[uint]*monitoringModel.InstanceTrafficHistory, len(instanceIDs))
+	if len(instanceIDs) == 0 {
+		return usage, nil
+	}
+
+	var records []monitoringModel.PmacctTrafficRecord
+	if err := global.APP_DB.Model(&monitoringModel.PmacctTrafficRecord{}).
+		Select("instance_id", "provider_id", "user_id", "timestamp", "rx_bytes", "tx_bytes", "total_bytes").
+		Where("instance_id IN ? AND timestamp >= ? AND timestamp < ?", instanceIDs, start.AddDate(0, 0, -1), end).
+		Order("instance_id ASC, timestamp ASC").
+		Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("查询实例流量记录失败: %w", err)
+	}
+
+	var prev *monitoringModel.PmacctTrafficRecord
+	for i := range records {
+		record := &records[i]
+		if prev != nil && prev.InstanceID != record.InstanceID {
+			prev = nil
+		}
+		if !record.Timestamp.Before(start) {
+			point, ok := usage[record.InstanceID]
+			if !ok {
+				point = &monitoringModel.InstanceTrafficHistory{
+					InstanceID: record.InstanceID,
+					ProviderID: record.ProviderID,
+					UserID:     record.UserID,
+				}
+				usage[record.InstanceID] = point
+			}
+			if prev != nil {
+				point.TrafficIn += counterDelta(record.RxBytes, prev.RxBytes)
+				point.TrafficOut += counterDelta(record.TxBytes, prev.TxBytes)
+				point.TotalUsed += counterDelta(record.TotalBytes, prev.TotalBytes)
+			} else {
+				point.TrafficIn += record.RxBytes
+				point.TrafficOut += record.TxBytes
+				point.TotalUsed += record.TotalBytes
+			}
+		}
+		prev = record
+	}
+	return usage, nil
 }
 
 // AggregateProviderTrafficHistory 聚合Provider流量历史（小时级）
@@ -224,48 +320,19 @@ func (h *HistoryService) AggregateUserTrafficHistory(userID uint) error {
 }
 
 // GetInstanceTrafficHistory 获取实例流量历史（用于图表展示）
-// period: 时间范围，支持 "5m", "10m", "15m", "30m", "45m", "1h", "6h", "12h", "24h"
-// interval: 数据点间隔（分钟），0表示自动选择最佳间隔
+// period: 时间范围，支持 "5m", "10m", "15m", "30m", "45m", "1h", "6h", "12h", "24h", "7d", "30d"
+// interval: 数据点间隔（分钟），0表示自动选择最佳间隔；7d、30d固定每天一个点，忽略该参数
 // includeArchived: 是否包含已归档的数据（重置前的历史数据），默认false
 func (h *HistoryService) GetInstanceTrafficHistory(instanceID uint, period string, interval int, includeArchived bool) ([]monitoringModel.InstanceTrafficHistory, error) {
+	// 7天、30天等长周期查询日度聚合记录，避免对原始记录做大范围自连接
+	if days, ok := dailyHistoryDays(period); ok {
+		return h.getDailyInstanceTrafficHistory(instanceID, days)
+	}
+
 	now := time.Now()
 
 	// 解析时间范围并计算起始时间
-	var startTime time.Time
-	var autoInterval int // 自动选择的间隔（分钟）
-
-	switch period {
-	case "5m":
-		startTime = now.Add(-5 * time.Minute)
-		autoInterval = 5 // 5分钟查看，每5分钟一个点
-	case "10m":
-		startTime = now.Add(-10 * time.Minute)
-		autoInterval = 5
-	case "15m":
-		startTime = now.Add(-15 * time.Minute)
-		autoInterval = 5
-	case "30m":
-		startTime = now.Add(-30 * time.Minute)
-		autoInterval = 5
-	case "45m":
-		startTime = now.Add(-45 * time.Minute)
-		autoInterval = 5
-	case "1h":
-		startTime = now.Add(-1 * time.Hour)
-		autoInterval = 5
-	case "6h":
-		startTime = now.Add(-6 * time.Hour)
-		autoInterval = 15 // 6小时查看，每15分钟一个点
-	case "12h":
-		startTime = now.Add(-12 * time.Hour)
-		autoInterval = 30 // 12小时查看，每30分钟一个点
-	case "24h":
-		startTime = now.Add(-24 * time.Hour)
-		autoInterval = 60 // 24小时查看，每60分钟一个点
-	default:
-		startTime = now.Add(-24 * time.Hour)
-		autoInterval = 60
-	}
+	startTime, autoInterval := historyTimeRange(period, now)
 
 	// 如果没有指定interval，使用自动选择的间隔
 	if interval == 0 {
@@ -330,6 +397,169 @@ func (h *HistoryService) GetInstanceTrafficHistory(instanceID uint, period strin
 	return histories, nil
 }
 
+// dailyHistoryDays 返回长周期时间范围对应的天数，非长周期返回false
+func dailyHistoryDays(period string) (int, bool) {
+	switch period {
+	case "7d":
+		return 7, true
+	case "30d":
+		return 30, true
+	default:
+		return 0, false
+	}
+}
+
+// getDailyInstanceTrafficHistory 获取最近days天（含今天）每天一个点的实例流量
+// 已结束的日期读取 AggregateDailyInstanceTraffic 生成的日度记录，今天的用量由当天原始记录实时计算，没有数据的日期填充0值
+func (h *HistoryService) getDailyInstanceTrafficHistory(instanceID uint, days int) ([]monitoringModel.InstanceTrafficHistory, error) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	dayStarts := make([]time.Time, 0, days)
+	for i := days - 1; i >= 0; i-- {
+		dayStarts = append(dayStarts, today.AddDate(0, 0, -i))
+	}
+
+	// 日度记录的record_time为当天零点，按精确时间匹配以排除同为hour=0的小时级记录
+	var rows []monitoringModel.InstanceTrafficHistory
+	if err := global.APP_DB.Where("instance_id = ? AND hour = 0 AND record_time IN ?", instanceID, dayStarts[:len(dayStarts)-1]).
+		Order("record_time ASC").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	byDate := make(map[string]monitoringModel.InstanceTrafficHistory, len(rows))
+	for _, row := range rows {
+		byDate[row.RecordTime.In(now.Location()).Format("2006-01-02")] = row
+	}
+
+	todayUsage, err := instanceTrafficUsage([]uint{instanceID}, today, today.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	if point, ok := todayUsage[instanceID]; ok {
+		point.Year, point.Month, point.Day, point.Hour = today.Year(), int(today.Month()), today.Day(), 0
+		point.RecordTime = today
+		byDate[today.Format("2006-01-02")] = *point
+	}
+
+	histories := make([]monitoringModel.InstanceTrafficHistory, 0, days)
+	for _, dayStart := range dayStarts {
+		if row, ok := byDate[dayStart.Format("2006-01-02")]; ok {
+			histories = append(histories, row)
+			continue
+		}
+		histories = append(histories, monitoringModel.InstanceTrafficHistory{
+			InstanceID: instanceID,
+			Year:       dayStart.Year(),
+			Month:      int(dayStart.Month()),
+			Day:        dayStart.Day(),
+			RecordTime: dayStart,
+		})
+	}
+	return histories, nil
+}
+
+// maxMultiInstanceHistoryPoints 批量查询时每个实例最多返回的原始数据点数量，与单实例查询保持一致
+const maxMultiInstanceHistoryPoints = 500
+
+// GetMultiInstanceTrafficHistory 批量获取多个实例的流量历史，返回以实例ID为键的数据序列
+// 一次查询取出所有实例在时间范围内的累积值，在内存中按实例计算相邻数据点的增量，
+// 避免逐个实例执行自连接查询。增量和重启处理规则与 GetInstanceTrafficHistory 一致，调用方需先校验实例归属
+func (h *HistoryService) GetMultiInstanceTrafficHistory(instanceIDs []uint, period string, interval int) (map[uint][]monitoringModel.InstanceTrafficHistory, error) {
+	result := make(map[uint][]monitoringModel.InstanceTrafficHistory, len(instanceIDs))
+	if len(instanceIDs) == 0 {
+		return result, nil
+	}
+
+	now := time.Now()
+	startTime, autoInterval := historyTimeRange(period, now)
+	if interval == 0 {
+		interval = autoInterval
+	}
+
+	var records []monitoringModel.PmacctTrafficRecord
+	err := global.APP_DB.Model(&monitoringModel.PmacctTrafficRecord{}).
+		Select("instance_id", "provider_id", "user_id", "timestamp", "year", "month", "day", "hour", "minute", "rx_bytes", "tx_bytes", "total_bytes").
+		Where("instance_id IN ? AND timestamp >= ?", instanceIDs, startTime).
+		Order("instance_id ASC, timestamp ASC").
+		Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// 记录按实例、时间排序，prev为同一实例的上一个数据点（不受间隔过滤影响）
+	var prev *monitoringModel.PmacctTrafficRecord
+	for i := range records {
+		record := &records[i]
+		if prev != nil && prev.InstanceID != record.InstanceID {
+			prev = nil
+		}
+
+		keep := interval <= 5 || record.Minute%interval == 0
+		if keep && len(result[record.InstanceID]) < maxMultiInstanceHistoryPoints {
+			point := monitoringModel.InstanceTrafficHistory{
+				InstanceID: record.InstanceID,
+				ProviderID: record.ProviderID,
+				UserID:     record.UserID,
+				TrafficIn:  record.RxBytes,
+				TrafficOut: record.TxBytes,
+				TotalUsed:  record.TotalBytes,
+				Year:       record.Year,
+				Month:      record.Month,
+				Day:        record.Day,
+				Hour:       record.Hour,
+				RecordTime: record.Timestamp,
+			}
+			if prev != nil {
+				point.TrafficIn = counterDelta(record.RxBytes, prev.RxBytes)
+				point.TrafficOut = counterDelta(record.TxBytes, prev.TxBytes)
+				point.TotalUsed = counterDelta(record.TotalBytes, prev.TotalBytes)
+			}
+			result[record.InstanceID] = append(result[record.InstanceID], point)
+		}
+		prev = record
+	}
+
+	// 填充缺失的时间点，没有数据的实例也返回完整的0值序列
+	for _, instanceID := range instanceIDs {
+		result[instanceID] = fillMissingInstanceTimePoints(result[instanceID], startTime, now, interval, instanceID, 0, 0)
+	}
+
+	return result, nil
+}
+
+// counterDelta 计算累积计数器相邻两点的增量，当前值小于上一个值时视为pmacct重启，直接使用当前值
+func counterDelta(current, previous int64) int64 {
+	if current < previous {
+		return current
+	}
+	return current - previous
+}
+
+// historyTimeRange 根据时间范围参数计算查询起始时间和自动选择的数据点间隔（分钟）
+func historyTimeRange(period string, now time.Time) (time.Time, int) {
+	switch period {
+	case "5m":
+		return now.Add(-5 * time.Minute), 5 // 5分钟查看，每5分钟一个点
+	case "10m":
+		return now.Add(-10 * time.Minute), 5
+	case "15m":
+		return now.Add(-15 * time.Minute), 5
+	case "30m":
+		return now.Add(-30 * time.Minute), 5
+	case "45m":
+		return now.Add(-45 * time.Minute), 5
+	case "1h":
+		return now.Add(-1 * time.Hour), 5
+	case "6h":
+		return now.Add(-6 * time.Hour), 15 // 6小时查看，每15分钟一个点
+	case "12h":
+		return now.Add(-12 * time.Hour), 30 // 12小时查看，每30分钟一个点
+	default:
+		return now.Add(-24 * time.Hour), 60 // 24小时查看，每60分钟一个点
+	}
+}
+
 // GetProviderTrafficHistory 获取Provider流量历史
 // period: "5m", "10m", "15m", "30m", "45m", "1h", "6h", "12h", "24h"
 // interval: 数据点间隔（分钟），0表示自动选择
@@ -781,34 +1011,59 @@ func (h *HistoryService) GetUserTrafficHistory(userID uint, period string, inter
 }
 
 // CleanupOldHistory 清理过期的历史数据
-// 默认保留72小时数据，自动清理更早的数据
+// 小时级数据和日级汇总数据分别按配置的保留时长清理
 func (h *HistoryService) CleanupOldHistory() error {
-	// 固定保留72小时
-	cutoffTime := time.Now().Add(-72 * time.Hour)
-
-	// 清理实例历史
-	if err := global.APP_DB.Where("record_time < ?", cutoffTime).
-		Delete(&monitoringModel.InstanceTrafficHistory{}).Error; err != nil {
-		global.APP_LOG.Error("清理实例流量历史失败", zap.Error(err))
-		return err
+	hourlyRetention, dailyRetention := trafficHistoryRetention()
+	now := time.Now()
+	hourlyCutoff := now.Add(-hourlyRetention)
+	dailyCutoff := now.Add(-dailyRetention)
+
+	// hour=0为日级汇总数据，单独按日级保留时长清理
+	targets := []struct {
+		name  string
+		model interface{}
+	}{
+		{"实例", &monitoringModel.InstanceTrafficHistory{}},
+		{"Provider", &monitoringModel.ProviderTrafficHistory{}},
+		{"用户", &monitoringModel.UserTrafficHistory{}},
 	}
-
-	// 清理Provider历史
-	if err := global.APP_DB.Where("record_time < ?", cutoffTime).
-		Delete(&monitoringModel.ProviderTrafficHistory{}).Error; err != nil {
-		global.APP_LOG.Error("清理Provider流量历史失败", zap.Error(err))
-		return err
+	for _, target := range targets {
+		if err := global.APP_DB.Where("hour <> 0 AND record_time < ?", hourlyCutoff).
+			Delete(target.model).Error; err != nil {
+			global.APP_LOG.Error("清理"+target.name+"流量历史失败", zap.Error(err))
+			return err
+		}
+		if err := global.APP_DB.Where("hour = 0 AND record_time < ?", dailyCutoff).
+			Delete(target.model).Error; err != nil {
+			global.APP_LOG.Error("清理"+target.name+"日级流量历史失败", zap.Error(err))
+			return err
+		}
 	}
 
-	// 清理用户历史
-	if err := global.APP_DB.Where("record_time < ?", cutoffTime).
-		Delete(&monitoringModel.UserTrafficHistory{}).Error; err != nil {
-		global.APP_LOG.Error("清理用户流量历史失败", zap.Error(err))
-		return err
+	global.APP_LOG.Info("清理历史流量数据完成",
+		zap.Duration("小时级保留时长", hourlyRetention),
+		zap.Duration("日级保留时长", dailyRetention))
+	return nil
+}
+
+// trafficHistoryRetention 读取流量历史保留时长配置
+// 小时级默认72小时，最少1小时；日级默认90天，且不短于小时级保留时长
+func trafficHistoryRetention() (time.Duration, time.Duration) {
+	hours := global.APP_CONFIG.System.TrafficHistoryRetentionHours
+	if hours <= 0 {
+		hours = 72
 	}
+	hourly := time.Duration(hours) * time.Hour
 
-	global.APP_LOG.Info("清理历史流量数据完成", zap.String("保留时长", "72小时"))
-	return nil
+	days := global.APP_CONFIG.System.TrafficDailyRetentionDays
+	if days <= 0 {
+		days = 90
+	}
+	daily := time.Duration(days) * 24 * time.Hour
+	if daily < hourly {
+		daily = hourly
+	}
+	return hourly, daily
 }
 
 // BatchRecordInstanceHistory 批量记录实例流量历史
@@ -872,9 +1127,10 @@ func (h *HistoryService) BatchRecordInstanceHistory(instances []providerModel.In
 }
 
 // fillMissingInstanceTimePoints 填充缺失的实例流量时间点
-// 在展示层自动构造缺失的时间点，流量值设为0，确保折线图连续显示
+// 在展示层自动构造缺失的时间点，流量值设为0，确保折线图连续显示；
+// 没有任何数据时（如新建实例）同样返回整个时间窗口的0值序列
 func fillMissingInstanceTimePoints(histories []monitoringModel.InstanceTrafficHistory, startTime, endTime time.Time, intervalMinutes int, instanceID, providerID, userID uint) []monitoringModel.InstanceTrafficHistory {
-	if len(histories) == 0 {
+	if intervalMinutes <= 0 {
 		return histories
 	}
 
@@ -921,7 +1177,7 @@ func fillMissingInstanceTimePoints(histories []monitoringModel.InstanceTrafficHi
 
 // fillMissingProviderTimePoints 填充缺失的Provider流量时间点
 func fillMissingProviderTimePoints(histories []monitoringModel.ProviderTrafficHistory, startTime, endTime time.Time, intervalMinutes int, providerID uint) []monitoringModel.ProviderTrafficHistory {
-	if len(histories) == 0 {
+	if intervalMinutes <= 0 {
 		return histories
 	}
 
@@ -962,7 +1218,7 @@ func fillMissingProviderTimePoints(histories []monitoringModel.ProviderTrafficHi
 
 // fillMissingUserTimePoints 填充缺失的用户流量时间点
 func fillMissingUserTimePoints(histories []monitoringModel.UserTrafficHistory, startTime, endTime time.Time, intervalMinutes int, userID uint) []monitoringModel.UserTrafficHistory {
-	if len(histories) == 0 {
+	if intervalMinutes <= 0 {
 		return histories
 	}
 
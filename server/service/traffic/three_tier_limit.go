@@ -9,6 +9,8 @@ import (
 	adminModel "oneclickvirt/model/admin"
 	"oneclickvirt/model/provider"
 	"oneclickvirt/model/user"
+	"oneclickvirt/service/user/notification"
+	"oneclickvirt/service/webhook"
 
 	"go.uber.org/zap"
 )
@@ -189,6 +191,7 @@ func (s *ThreeTierLimitService) limitInstance(instanceID uint, reason string, me
 	// 保存需要使用的字段
 	userID := instance.UserID
 	providerID := instance.ProviderID
+	alreadyLimited := instance.TrafficLimited
 
 	// 标记实例为受限状态
 	updates := map[string]interface{}{
@@ -200,6 +203,9 @@ func (s *ThreeTierLimitService) limitInstance(instanceID uint, reason string, me
 	if err := global.APP_DB.Model(&instance).Updates(updates).Error; err != nil {
 		return false, fmt.Errorf("标记实例为受限状态失败: %w", err)
 	}
+	if !alreadyLimited {
+		webhook.NotifyInstanceEvent(webhook.EventTrafficQuotaExceed, instanceID, message)
+	}
 
 	// 创建停止任务
 	if err := s.createStopTask(userID, instanceID, providerID, message); err != nil {
@@ -351,6 +357,9 @@ func (s *ThreeTierLimitService) CheckUserTrafficLimit(userID uint) (bool, error)
 		return s.limitUserInstances(userID, fmt.Sprintf("用户流量超限: %dMB/%dMB", totalUsedMB, u.TotalTraffic))
 	}
 
+	// 未超限，检查是否达到预警阈值
+	s.checkUserTrafficWarning(&u, totalUsedMB)
+
 	// 未超限，解除用户级限制
 	if u.TrafficLimited {
 		return s.unlimitUserInstances(userID, "用户流量恢复正常")
@@ -359,6 +368,43 @@ func (s *ThreeTierLimitService) CheckUserTrafficLimit(userID uint) (bool, error)
 	return false, nil
 }
 
+// checkUserTrafficWarning 用户流量达到预警阈值时通过通信渠道发送通知，每月最多发送一次
+func (s *ThreeTierLimitService) checkUserTrafficWarning(u *user.User, usedMB int64) {
+	warningPercent := global.APP_CONFIG.Quota.TrafficWarningPercent
+	if warningPercent <= 0 {
+		warningPercent = 90
+	}
+	if warningPercent >= 100 || usedMB*100 < u.TotalTraffic*int64(warningPercent) {
+		return
+	}
+
+	now := time.Now()
+	if u.TrafficWarnAt != nil && u.TrafficWarnAt.Year() == now.Year() && u.TrafficWarnAt.Month() == now.Month() {
+		return
+	}
+
+	// 先记录预警时间，避免通知渠道异常时每轮检查重复发送
+	if err := global.APP_DB.Model(u).Update("traffic_warn_at", &now).Error; err != nil {
+		global.APP_LOG.Warn("更新用户流量预警时间失败", zap.Uint("userID", u.ID), zap.Error(err))
+		return
+	}
+
+	message := fmt.Sprintf("用户 %s 本月流量已使用 %dMB，达到配额 %dMB 的 %d%%，超出配额后所有实例将被自动停止。",
+		u.Username, usedMB, u.TotalTraffic, usedMB*100/u.TotalTraffic)
+	if err := notification.NewService().NotifyUser(u, "流量使用预警", message); err != nil {
+		global.APP_LOG.Warn("发送用户流量预警通知失败",
+			zap.Uint("userID", u.ID),
+			zap.String("username", u.Username),
+			zap.Error(err))
+		return
+	}
+
+	global.APP_LOG.Info("已发送用户流量预警通知",
+		zap.Uint("userID", u.ID),
+		zap.Int64("usedTraffic", usedMB),
+		zap.Int64("totalTraffic", u.TotalTraffic))
+}
+
 // limitUserInstances 限制用户的所有实例
 func (s *ThreeTierLimitService) limitUserInstances(userID uint, message string) (bool, error) {
 	// 标记用户为受限状态
@@ -373,6 +419,12 @@ func (s *ThreeTierLimitService) limitUserInstances(userID uint, message string)
 		"status":               "stopped",
 	}
 
+	// 记录本次将被限制的运行中实例，用于推送超限事件
+	var breachedIDs []uint
+	global.APP_DB.Model(&provider.Instance{}).
+		Where("user_id = ? AND status = ?", userID, "running").
+		Pluck("id", &breachedIDs)
+
 	result := global.APP_DB.Model(&provider.Instance{}).
 		Where("user_id = ? AND status = ?", userID, "running").
 		Updates(updates)
@@ -380,6 +432,9 @@ func (s *ThreeTierLimitService) limitUserInstances(userID uint, message string)
 	if result.Error != nil {
 		return false, fmt.Errorf("批量标记实例为受限状态失败: %w", result.Error)
 	}
+	for _, id := range breachedIDs {
+		webhook.NotifyInstanceEvent(webhook.EventTrafficQuotaExceed, id, message)
+	}
 
 	// 获取被停止的实例ID列表用于创建任务
 	var instances []provider.Instance
@@ -558,6 +613,12 @@ func (s *ThreeTierLimitService) limitProviderInstances(providerID uint, message
 		"status":               "stopped",
 	}
 
+	// 记录本次将被限制的运行中实例，用于推送超限事件
+	var breachedIDs []uint
+	global.APP_DB.Model(&provider.Instance{}).
+		Where("provider_id = ? AND status = ?", providerID, "running").
+		Pluck("id", &breachedIDs)
+
 	result := global.APP_DB.Model(&provider.Instance{}).
 		Where("provider_id = ? AND status = ?", providerID, "running").
 		Updates(updates)
@@ -565,6 +626,9 @@ func (s *ThreeTierLimitService) limitProviderInstances(providerID uint, message
 	if result.Error != nil {
 		return false, fmt.Errorf("批量标记实例为受限状态失败: %w", result.Error)
 	}
+	for _, id := range breachedIDs {
+		webhook.NotifyInstanceEvent(webhook.EventTrafficQuotaExceed, id, message)
+	}
 
 	// 获取被停止的实例ID列表用于创建任务
 	var instances []provider.Instance
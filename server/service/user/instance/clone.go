@@ -0,0 +1,205 @@
+package instance
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"oneclickvirt/global"
+	adminModel "oneclickvirt/model/admin"
+	providerModel "oneclickvirt/model/provider"
+	userModel "oneclickvirt/model/user"
+	"oneclickvirt/service/resources"
+	"oneclickvirt/service/task"
+	userProviderService "oneclickvirt/service/user/provider"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// cloneNamePattern 克隆实例名称需同时满足容器与虚拟机的命名规则
+var cloneNamePattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9-]{0,62}$`)
+
+// CloneInstance 克隆用户实例
+// 克隆实例按源实例规格计入用户配额并占用Provider资源，实际克隆由异步任务完成
+func (s *Service) CloneInstance(userID uint, instanceID uint, req userModel.CloneInstanceRequest) (*userModel.CloneInstanceResponse, error) {
+	if !s.HasInstanceAccess(userID, instanceID) {
+		return nil, errors.New("无权限访问此实例")
+	}
+
+	var source providerModel.Instance
+	if err := global.APP_DB.First(&source, instanceID).Error; err != nil {
+		return nil, fmt.Errorf("实例不存在: %w", err)
+	}
+
+	if source.Status != "running" && source.Status != "stopped" {
+		return nil, errors.New("只有运行中或已停止的实例才能克隆")
+	}
+
+	if req.Name != "" && !cloneNamePattern.MatchString(req.Name) {
+		return nil, errors.New("实例名称只能包含字母、数字和连字符，且必须以字母开头")
+	}
+
+	// 克隆实例落在源实例所在节点上，按申领新实例的条件校验节点
+	var sourceProvider providerModel.Provider
+	if err := global.APP_DB.First(&sourceProvider, source.ProviderID).Error; err != nil {
+		return nil, fmt.Errorf("获取实例所在节点失败: %w", err)
+	}
+	if err := userProviderService.ValidateProviderAcceptsNewInstance(&sourceProvider); err != nil {
+		return nil, err
+	}
+
+	var clone providerModel.Instance
+	err := global.APP_DB.Transaction(func(tx *gorm.DB) error {
+		quotaResult, err := resources.NewQuotaService().ValidateInTransaction(tx, resources.ResourceRequest{
+			UserID:       userID,
+			CPU:          source.CPU,
+			Memory:       source.Memory,
+			Disk:         source.Disk,
+			Bandwidth:    source.Bandwidth,
+			InstanceType: source.InstanceType,
+			ProviderID:   source.ProviderID,
+		})
+		if err != nil {
+			return fmt.Errorf("配额验证失败: %w", err)
+		}
+		if !quotaResult.Allowed {
+			return errors.New(quotaResult.Reason)
+		}
+
+		name, err := s.resolveCloneName(tx, source, req.Name)
+		if err != nil {
+			return err
+		}
+
+		clone = providerModel.Instance{
+			Name:         name,
+			Provider:     source.Provider,
+			ProviderID:   source.ProviderID,
+			Status:       "creating",
+			Image:        source.Image,
+			InstanceType: source.InstanceType,
+			CPU:          source.CPU,
+			Memory:       source.Memory,
+			Disk:         source.Disk,
+			Bandwidth:    source.Bandwidth,
+			Network:      source.Network,
+			SSHPort:      22,
+			Username:     "root",
+			OSType:       source.OSType,
+			Region:       source.Region,
+			MaxTraffic:   source.MaxTraffic,
+			ExpiredAt:    source.ExpiredAt,
+			UserID:       userID,
+		}
+		if err := tx.Create(&clone).Error; err != nil {
+			return fmt.Errorf("创建实例记录失败: %w", err)
+		}
+
+		if err := resources.NewQuotaService().UpdateUserQuotaAfterCreationWithTx(tx, userID, resources.ResourceUsage{
+			CPU:       source.CPU,
+			Memory:    source.Memory,
+			Disk:      source.Disk,
+			Bandwidth: source.Bandwidth,
+		}); err != nil {
+			return fmt.Errorf("更新用户配额失败: %w", err)
+		}
+
+		resourceService := &resources.ResourceService{}
+		if err := resourceService.AllocateResourcesInTx(tx, source.ProviderID, source.InstanceType,
+			source.CPU, source.Memory, source.Disk); err != nil {
+			return fmt.Errorf("分配Provider资源失败: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	taskData, _ := json.Marshal(adminModel.CloneInstanceTaskRequest{
+		SourceInstanceId: source.ID,
+		InstanceId:       clone.ID,
+		ProviderId:       source.ProviderID,
+	})
+	taskModel, err := task.GetTaskService().CreateTask(userID, &clone.ProviderID, &clone.ID, "clone", string(taskData), 1800)
+	if err != nil {
+		s.discardPendingClone(clone)
+		return nil, fmt.Errorf("创建克隆任务失败: %w", err)
+	}
+
+	global.APP_LOG.Info("用户创建实例克隆任务",
+		zap.Uint("userID", userID),
+		zap.Uint("sourceInstanceID", source.ID),
+		zap.Uint("instanceID", clone.ID),
+		zap.String("name", clone.Name),
+		zap.Uint("taskID", taskModel.ID))
+
+	return &userModel.CloneInstanceResponse{
+		TaskID:     taskModel.ID,
+		InstanceID: clone.ID,
+		Name:       clone.Name,
+	}, nil
+}
+
+// resolveCloneName 确定克隆实例名称
+// 唯一索引包含软删除记录，因此需要使用Unscoped检查名称冲突
+func (s *Service) resolveCloneName(tx *gorm.DB, source providerModel.Instance, requested string) (string, error) {
+	exists := func(name string) (bool, error) {
+		var count int64
+		err := tx.Unscoped().Model(&providerModel.Instance{}).
+			Where("name = ? AND provider_id = ?", name, source.ProviderID).
+			Count(&count).Error
+		return count > 0, err
+	}
+
+	if requested != "" {
+		taken, err := exists(requested)
+		if err != nil {
+			return "", fmt.Errorf("检查实例名称失败: %w", err)
+		}
+		if taken {
+			return "", errors.New("实例名称已存在")
+		}
+		return requested, nil
+	}
+
+	for attempt := 0; attempt < 10; attempt++ {
+		name := utils.GenerateInstanceName(source.Provider)
+		taken, err := exists(name)
+		if err != nil {
+			return "", fmt.Errorf("检查实例名称失败: %w", err)
+		}
+		if !taken {
+			return name, nil
+		}
+	}
+	return "", errors.New("生成实例名称失败，请稍后重试")
+}
+
+// discardPendingClone 任务创建失败时回滚预创建的克隆实例
+func (s *Service) discardPendingClone(clone providerModel.Instance) {
+	err := global.APP_DB.Transaction(func(tx *gorm.DB) error {
+		resourceService := &resources.ResourceService{}
+		if err := resourceService.ReleaseResourcesInTx(tx, clone.ProviderID, clone.InstanceType,
+			clone.CPU, clone.Memory, clone.Disk); err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Delete(&clone).Error; err != nil {
+			return err
+		}
+		return resources.NewQuotaService().UpdateUserQuotaAfterDeletionWithTx(tx, clone.UserID, resources.ResourceUsage{
+			CPU:       clone.CPU,
+			Memory:    clone.Memory,
+			Disk:      clone.Disk,
+			Bandwidth: clone.Bandwidth,
+		})
+	})
+	if err != nil {
+		global.APP_LOG.Error("回滚克隆实例记录失败",
+			zap.Uint("instanceID", clone.ID),
+			zap.Error(err))
+	}
+}
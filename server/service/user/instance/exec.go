@@ -0,0 +1,157 @@
+package instance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"oneclickvirt/global"
+	"oneclickvirt/model/common"
+	providerModel "oneclickvirt/model/provider"
+	userModel "oneclickvirt/model/user"
+	providerService "oneclickvirt/service/provider"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+)
+
+const (
+	defaultExecTimeout   = 30 * time.Second
+	defaultExecMaxOutput = 64 * 1024
+)
+
+var (
+	// execSeparatorPattern shell命令分隔符，用于拆分出每段命令
+	execSeparatorPattern = regexp.MustCompile("[;&|\n]|\\$\\(|`")
+	// execOperatorPattern 白名单模式下禁止出现的shell控制字符，避免拼接其他命令绕过白名单
+	execOperatorPattern = regexp.MustCompile("[;&|<>\n`]|\\$\\(")
+)
+
+// ExecInstanceCommand 在用户实例内执行一条命令
+// 需要管理员为用户开启执行权限，命令受白名单/黑名单、超时和输出大小限制
+func (s *Service) ExecInstanceCommand(userID uint, instanceID uint, command string) (*userModel.ExecInstanceCommandResponse, error) {
+	var user userModel.User
+	if err := global.APP_DB.Select("id, allow_instance_exec").First(&user, userID).Error; err != nil {
+		return nil, fmt.Errorf("获取用户信息失败: %w", err)
+	}
+	if !user.AllowInstanceExec {
+		return nil, common.NewError(common.CodeForbidden, "未开通实例命令执行权限，请联系管理员")
+	}
+
+	if !s.HasInstanceAccess(userID, instanceID) {
+		return nil, common.NewError(common.CodeForbidden, "无权限访问此实例")
+	}
+
+	command = strings.TrimSpace(command)
+	if command == "" {
+		return nil, common.NewError(common.CodeValidationError, "命令不能为空")
+	}
+	quota := global.APP_CONFIG.Quota
+	if err := checkExecCommand(command, quota.InstanceExecAllowlist, quota.InstanceExecDenylist); err != nil {
+		return nil, common.NewError(common.CodeValidationError, err.Error())
+	}
+
+	var instance providerModel.Instance
+	if err := global.APP_DB.First(&instance, instanceID).Error; err != nil {
+		return nil, fmt.Errorf("实例不存在: %w", err)
+	}
+	if instance.Status != "running" {
+		return nil, common.NewError(common.CodeValidationError, "只能在运行中的实例内执行命令")
+	}
+
+	prov, err := providerService.GetProviderInstanceByID(instance.ProviderID)
+	if err != nil {
+		return nil, fmt.Errorf("获取Provider实例失败: %w", err)
+	}
+	executor, ok := prov.(interface {
+		ExecInInstance(ctx context.Context, instanceName, command string, timeout time.Duration, maxOutput int) (*utils.CommandResult, error)
+	})
+	if !ok {
+		return nil, common.NewError(common.CodeValidationError, "该节点类型不支持在实例内执行命令")
+	}
+
+	timeout := defaultExecTimeout
+	if quota.InstanceExecTimeout > 0 {
+		timeout = time.Duration(quota.InstanceExecTimeout) * time.Second
+	}
+	maxOutput := defaultExecMaxOutput
+	if quota.InstanceExecMaxOutput > 0 {
+		maxOutput = quota.InstanceExecMaxOutput
+	}
+
+	global.APP_LOG.Info("用户在实例内执行命令",
+		zap.Uint("userID", userID),
+		zap.Uint("instanceID", instanceID),
+		zap.String("instanceName", instance.Name),
+		zap.String("command", utils.TruncateString(command, 256)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout+10*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	result, err := executor.ExecInInstance(ctx, instance.Name, command, timeout, maxOutput)
+	elapsed := time.Since(start)
+	if err != nil {
+		if strings.Contains(err.Error(), "timeout") {
+			return &userModel.ExecInstanceCommandResponse{
+				ExitCode: -1,
+				TimedOut: true,
+				Duration: elapsed.Milliseconds(),
+			}, nil
+		}
+		global.APP_LOG.Warn("实例内命令执行失败",
+			zap.Uint("instanceID", instanceID),
+			zap.Error(err))
+		return nil, fmt.Errorf("命令执行失败: %w", err)
+	}
+
+	return &userModel.ExecInstanceCommandResponse{
+		Stdout:    result.Stdout,
+		Stderr:    result.Stderr,
+		ExitCode:  result.ExitCode,
+		Truncated: result.Truncated,
+		// 宿主机timeout以SIGKILL终止命令时退出码为137
+		TimedOut: result.ExitCode == 137 && elapsed >= timeout,
+		Duration: elapsed.Milliseconds(),
+	}, nil
+}
+
+// checkExecCommand 按管理员配置的白名单和黑名单校验命令
+// 白名单非空时只允许单条命令且程序名必须在白名单内；黑名单对命令中出现的任意程序名生效
+func checkExecCommand(command string, allowlist, denylist []string) error {
+	if len(allowlist) > 0 {
+		if execOperatorPattern.MatchString(command) {
+			return errors.New("当前仅允许执行白名单内的单条命令，不支持管道、重定向或命令拼接")
+		}
+		program := path.Base(strings.Fields(command)[0])
+		if !containsProgram(allowlist, program) {
+			return fmt.Errorf("命令 %s 不在允许执行的列表中", program)
+		}
+	}
+
+	if len(denylist) > 0 {
+		for _, segment := range execSeparatorPattern.Split(command, -1) {
+			for _, token := range strings.Fields(segment) {
+				program := path.Base(strings.Trim(token, `"'()`))
+				if containsProgram(denylist, program) {
+					return fmt.Errorf("禁止执行命令 %s", program)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func containsProgram(list []string, program string) bool {
+	for _, item := range list {
+		if strings.TrimSpace(item) == program {
+			return true
+		}
+	}
+	return false
+}
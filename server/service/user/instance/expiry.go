@@ -0,0 +1,75 @@
+package instance
+
+import (
+	"fmt"
+	"time"
+
+	"oneclickvirt/global"
+	"oneclickvirt/model/common"
+	providerModel "oneclickvirt/model/provider"
+	userModel "oneclickvirt/model/user"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+)
+
+// ExtendInstance 延长实例有效期
+// 单次续期不超过策略上限，续期后的到期时间不超过当前时间加最长有效期，也不超过Provider的到期时间
+func (s *Service) ExtendInstance(userID uint, instanceID uint, hours int) (*userModel.ExtendInstanceResponse, error) {
+	if !s.HasInstanceAccess(userID, instanceID) {
+		return nil, common.NewError(common.CodeForbidden, "无权限访问此实例")
+	}
+
+	policy := utils.GetInstanceTTLPolicy()
+	if hours <= 0 || hours > policy.ExtendMaxHours {
+		return nil, common.NewError(common.CodeValidationError, fmt.Sprintf("单次续期时长需在1到%d小时之间", policy.ExtendMaxHours))
+	}
+
+	var instance providerModel.Instance
+	if err := global.APP_DB.First(&instance, instanceID).Error; err != nil {
+		return nil, common.NewError(common.CodeNotFound, "实例不存在")
+	}
+	switch instance.Status {
+	case "deleting", "deleted", "creating", "failed":
+		return nil, common.NewError(common.CodeValidationError, "当前实例状态不允许续期")
+	}
+
+	now := time.Now()
+	maxExpiredAt := now.Add(time.Duration(policy.MaxTTLHours) * time.Hour)
+	var prov providerModel.Provider
+	if err := global.APP_DB.Select("id, expires_at").First(&prov, instance.ProviderID).Error; err == nil &&
+		prov.ExpiresAt != nil && prov.ExpiresAt.Before(maxExpiredAt) {
+		maxExpiredAt = *prov.ExpiresAt
+	}
+
+	base := instance.ExpiredAt
+	if base.Before(now) {
+		base = now
+	}
+	newExpiredAt := base.Add(time.Duration(hours) * time.Hour)
+	if newExpiredAt.After(maxExpiredAt) {
+		newExpiredAt = maxExpiredAt
+	}
+	if !newExpiredAt.After(instance.ExpiredAt) {
+		return nil, common.NewError(common.CodeValidationError, "实例已达到最长有效期，无法继续续期")
+	}
+
+	if err := global.APP_DB.Model(&instance).Updates(map[string]interface{}{
+		"expired_at":         newExpiredAt,
+		"expire_notified_at": nil,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("更新实例有效期失败: %w", err)
+	}
+
+	global.APP_LOG.Info("用户续期实例",
+		zap.Uint("userID", userID),
+		zap.Uint("instanceID", instanceID),
+		zap.Int("hours", hours),
+		zap.Time("expiredAt", newExpiredAt))
+
+	return &userModel.ExtendInstanceResponse{
+		ExpiredAt:      newExpiredAt,
+		MaxExpiredAt:   maxExpiredAt,
+		ExtendMaxHours: policy.ExtendMaxHours,
+	}, nil
+}
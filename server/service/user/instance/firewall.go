@@ -0,0 +1,144 @@
+package instance
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"oneclickvirt/global"
+	"oneclickvirt/model/common"
+	providerModel "oneclickvirt/model/provider"
+	userModel "oneclickvirt/model/user"
+	"oneclickvirt/provider"
+	providerService "oneclickvirt/service/provider"
+
+	"go.uber.org/zap"
+)
+
+// firewallApplyTimeout 下发单条防火墙规则的超时时间
+const firewallApplyTimeout = 60 * time.Second
+
+// ListFirewallRules 获取实例的防火墙规则
+func (s *Service) ListFirewallRules(userID uint, instanceID uint) ([]providerModel.InstanceFirewallRule, error) {
+	if !s.HasInstanceAccess(userID, instanceID) {
+		return nil, common.NewError(common.CodeForbidden, "无权限访问此实例")
+	}
+
+	rules := []providerModel.InstanceFirewallRule{}
+	if err := global.APP_DB.Where("instance_id = ?", instanceID).Order("id ASC").Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("获取防火墙规则失败: %w", err)
+	}
+	return rules, nil
+}
+
+// AddFirewallRule 为实例添加防火墙规则
+// 实例运行中时立即下发到宿主机，下发失败不保存；未运行时只保存，实例启动后统一应用
+func (s *Service) AddFirewallRule(userID uint, instanceID uint, req userModel.AddFirewallRuleRequest) (*providerModel.InstanceFirewallRule, error) {
+	if !s.HasInstanceAccess(userID, instanceID) {
+		return nil, common.NewError(common.CodeForbidden, "无权限访问此实例")
+	}
+
+	rule := providerModel.InstanceFirewallRule{
+		InstanceID:  instanceID,
+		Protocol:    req.Protocol,
+		Port:        req.Port,
+		EndPort:     req.EndPort,
+		Source:      req.Source,
+		Action:      req.Action,
+		Description: req.Description,
+	}
+	if err := provider.ValidateFirewallRule(rule.ToProviderRule()); err != nil {
+		return nil, common.NewError(common.CodeValidationError, err.Error())
+	}
+
+	var instance providerModel.Instance
+	if err := global.APP_DB.First(&instance, instanceID).Error; err != nil {
+		return nil, common.NewError(common.CodeNotFound, "实例不存在")
+	}
+
+	var count int64
+	if err := global.APP_DB.Model(&providerModel.InstanceFirewallRule{}).Where("instance_id = ?", instanceID).Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("统计防火墙规则失败: %w", err)
+	}
+	if count >= provider.MaxFirewallRulesPerInstance {
+		return nil, common.NewError(common.CodeValidationError,
+			fmt.Sprintf("每个实例最多添加%d条防火墙规则", provider.MaxFirewallRulesPerInstance))
+	}
+
+	var duplicate int64
+	global.APP_DB.Model(&providerModel.InstanceFirewallRule{}).
+		Where("instance_id = ? AND protocol = ? AND port = ? AND end_port = ? AND source = ? AND action = ?",
+			instanceID, rule.Protocol, rule.Port, rule.EndPort, rule.Source, rule.Action).
+		Count(&duplicate)
+	if duplicate > 0 {
+		return nil, common.NewError(common.CodeConflict, "相同的防火墙规则已存在")
+	}
+
+	if instance.Status == "running" {
+		if err := s.applyFirewallRule(instance, rule.ToProviderRule()); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := global.APP_DB.Create(&rule).Error; err != nil {
+		// 规则已下发但未保存时回滚宿主机上的规则，避免残留无法管理的规则
+		if instance.Status == "running" {
+			removal := rule.ToProviderRule()
+			removal.Remove = true
+			s.applyFirewallRule(instance, removal)
+		}
+		return nil, fmt.Errorf("保存防火墙规则失败: %w", err)
+	}
+
+	global.APP_LOG.Info("用户添加实例防火墙规则",
+		zap.Uint("userID", userID),
+		zap.Uint("instanceID", instanceID),
+		zap.Uint("ruleID", rule.ID))
+	return &rule, nil
+}
+
+// DeleteFirewallRule 删除实例的防火墙规则
+func (s *Service) DeleteFirewallRule(userID uint, instanceID uint, ruleID uint) error {
+	if !s.HasInstanceAccess(userID, instanceID) {
+		return common.NewError(common.CodeForbidden, "无权限访问此实例")
+	}
+
+	var rule providerModel.InstanceFirewallRule
+	if err := global.APP_DB.Where("id = ? AND instance_id = ?", ruleID, instanceID).First(&rule).Error; err != nil {
+		return common.NewError(common.CodeNotFound, "防火墙规则不存在")
+	}
+
+	var instance providerModel.Instance
+	if err := global.APP_DB.First(&instance, instanceID).Error; err != nil {
+		return common.NewError(common.CodeNotFound, "实例不存在")
+	}
+
+	if instance.Status == "running" {
+		removal := rule.ToProviderRule()
+		removal.Remove = true
+		if err := s.applyFirewallRule(instance, removal); err != nil {
+			return err
+		}
+	}
+
+	if err := global.APP_DB.Delete(&rule).Error; err != nil {
+		return fmt.Errorf("删除防火墙规则失败: %w", err)
+	}
+
+	global.APP_LOG.Info("用户删除实例防火墙规则",
+		zap.Uint("userID", userID),
+		zap.Uint("instanceID", instanceID),
+		zap.Uint("ruleID", ruleID))
+	return nil
+}
+
+func (s *Service) applyFirewallRule(instance providerModel.Instance, rule provider.FirewallRule) error {
+	ctx, cancel := context.WithTimeout(context.Background(), firewallApplyTimeout)
+	defer cancel()
+
+	providerApiService := &providerService.ProviderApiService{}
+	if err := providerApiService.ManageFirewallRuleByProviderID(ctx, instance.ProviderID, instance.Name, rule); err != nil {
+		return common.NewError(common.CodeInternalError, err.Error())
+	}
+	return nil
+}
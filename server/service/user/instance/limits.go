@@ -0,0 +1,146 @@
+package instance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"oneclickvirt/constant"
+	"oneclickvirt/global"
+	"oneclickvirt/model/common"
+	providerModel "oneclickvirt/model/provider"
+	userModel "oneclickvirt/model/user"
+	"oneclickvirt/provider"
+	"oneclickvirt/service/cache"
+	providerService "oneclickvirt/service/provider"
+	"oneclickvirt/service/resources"
+
+	"go.uber.org/zap"
+)
+
+// UpdateInstanceLimits 在不重建实例的情况下调整CPU和内存
+// 调整前按用户等级限制和节点剩余资源重新校验，成功后更新数据库并重算用户配额和节点预算；
+// 需要重启才能生效的实例（如运行中的虚拟机）在用户选择自动重启时提交重启任务
+func (s *Service) UpdateInstanceLimits(userID uint, instanceID uint, req userModel.UpdateInstanceLimitsRequest) (*userModel.UpdateInstanceLimitsResponse, error) {
+	if !s.HasInstanceAccess(userID, instanceID) {
+		return nil, common.NewError(common.CodeForbidden, "无权限访问此实例")
+	}
+
+	cpuSpec, err := constant.GetCPUSpecByID(req.CPUId)
+	if err != nil {
+		return nil, common.NewError(common.CodeValidationError, fmt.Sprintf("无效的CPU规格ID: %v", err))
+	}
+	memorySpec, err := constant.GetMemorySpecByID(req.MemoryId)
+	if err != nil {
+		return nil, common.NewError(common.CodeValidationError, fmt.Sprintf("无效的内存规格ID: %v", err))
+	}
+	newCPU, newMemory := cpuSpec.Cores, int64(memorySpec.SizeMB)
+
+	var instance providerModel.Instance
+	if err := global.APP_DB.First(&instance, instanceID).Error; err != nil {
+		return nil, fmt.Errorf("实例不存在: %w", err)
+	}
+	if instance.Status != "running" && instance.Status != "stopped" {
+		return nil, common.NewError(common.CodeValidationError, fmt.Sprintf("实例当前状态为 %s，无法调整资源", instance.Status))
+	}
+	if instance.DeleteScheduledAt != nil {
+		return nil, common.NewError(common.CodeValidationError, "实例已计划删除，请先撤销删除")
+	}
+	if newCPU == instance.CPU && newMemory == instance.Memory {
+		return nil, common.NewError(common.CodeValidationError, "资源配置未发生变化")
+	}
+
+	if err := resources.NewQuotaService().ValidateInstanceResize(userID, instance, newCPU, newMemory); err != nil {
+		return nil, common.NewError(common.CodeValidationError, err.Error())
+	}
+	if err := checkProviderResizeCapacity(instance, newCPU, newMemory); err != nil {
+		return nil, common.NewError(common.CodeValidationError, err.Error())
+	}
+
+	prov, err := providerService.GetProviderInstanceByID(instance.ProviderID)
+	if err != nil {
+		return nil, fmt.Errorf("获取Provider实例失败: %w", err)
+	}
+	updater, ok := prov.(interface {
+		UpdateInstanceLimits(ctx context.Context, instanceName string, cpu int, memoryMB int64) error
+	})
+	if !ok {
+		return nil, common.NewError(common.CodeValidationError, fmt.Sprintf("Provider类型 %s 不支持调整资源", prov.GetType()))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	resp := &userModel.UpdateInstanceLimitsResponse{CPU: newCPU, Memory: newMemory}
+	if err := updater.UpdateInstanceLimits(ctx, instance.Name, newCPU, newMemory); err != nil {
+		if !errors.Is(err, provider.ErrLimitsRequireRestart) {
+			return nil, fmt.Errorf("调整实例资源失败: %w", err)
+		}
+		resp.RestartRequired = true
+	}
+
+	if err := global.APP_DB.Model(&instance).Updates(map[string]interface{}{
+		"cpu":    newCPU,
+		"memory": newMemory,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("更新实例资源配置失败: %w", err)
+	}
+
+	if err := resources.NewQuotaService().RecalculateUserQuota(userID); err != nil {
+		global.APP_LOG.Warn("调整实例资源后重算用户配额失败",
+			zap.Uint("userID", userID),
+			zap.Error(err))
+	}
+	(&resources.ResourceService{}).SyncProviderResourcesAsync(instance.ProviderID)
+	cacheService := cache.GetUserCacheService()
+	cacheService.InvalidateUserCache(userID)
+	cacheService.InvalidateInstanceCache(instanceID)
+
+	if resp.RestartRequired && req.Restart {
+		if err := s.InstanceAction(userID, userModel.InstanceActionRequest{InstanceID: instanceID, Action: "restart"}); err != nil {
+			global.APP_LOG.Warn("调整实例资源后提交重启任务失败",
+				zap.Uint("instanceID", instanceID),
+				zap.Error(err))
+		} else {
+			resp.Restarted = true
+		}
+	}
+
+	global.APP_LOG.Info("用户调整实例资源",
+		zap.Uint("userID", userID),
+		zap.Uint("instanceID", instanceID),
+		zap.String("instanceName", instance.Name),
+		zap.Int("oldCPU", instance.CPU),
+		zap.Int("newCPU", newCPU),
+		zap.Int64("oldMemoryMB", instance.Memory),
+		zap.Int64("newMemoryMB", newMemory),
+		zap.Bool("restartRequired", resp.RestartRequired),
+		zap.Bool("restarted", resp.Restarted))
+
+	return resp, nil
+}
+
+// checkProviderResizeCapacity 校验节点剩余可分配资源能容纳调整带来的增量，只检查计入节点预算的资源
+func checkProviderResizeCapacity(instance providerModel.Instance, cpu int, memory int64) error {
+	var p providerModel.Provider
+	if err := global.APP_DB.First(&p, instance.ProviderID).Error; err != nil {
+		return fmt.Errorf("获取节点信息失败: %v", err)
+	}
+
+	limitCPU, limitMemory := p.ContainerLimitCPU, p.ContainerLimitMemory
+	if instance.InstanceType == "vm" {
+		limitCPU, limitMemory = p.VMLimitCPU, p.VMLimitMemory
+	}
+	if deltaCPU := cpu - instance.CPU; limitCPU && deltaCPU > 0 && p.NodeCPUCores > 0 {
+		if available := p.AllocatableCPUCores() - p.UsedCPUCores; deltaCPU > available {
+			return fmt.Errorf("节点CPU资源不足：需要增加 %d 核，剩余 %d 核", deltaCPU, available)
+		}
+	}
+	if deltaMemory := memory - instance.Memory; limitMemory && deltaMemory > 0 && p.NodeMemoryTotal > 0 {
+		if available := p.AllocatableMemory() - p.UsedMemory; deltaMemory > available {
+			return fmt.Errorf("节点内存资源不足：需要增加 %dMB，剩余 %dMB", deltaMemory, available)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package instance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"oneclickvirt/global"
+	"oneclickvirt/model/common"
+	providerModel "oneclickvirt/model/provider"
+	userModel "oneclickvirt/model/user"
+	"oneclickvirt/provider"
+	providerService "oneclickvirt/service/provider"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+)
+
+// GetInstanceLogs 获取用户实例的日志
+// Docker返回容器日志，Incus/LXD返回控制台日志，Proxmox返回实例内的系统日志
+func (s *Service) GetInstanceLogs(userID uint, instanceID uint, req userModel.InstanceLogsRequest) (*userModel.InstanceLogsResponse, error) {
+	if !s.HasInstanceAccess(userID, instanceID) {
+		return nil, common.NewError(common.CodeForbidden, "无权限访问此实例")
+	}
+
+	var instance providerModel.Instance
+	if err := global.APP_DB.First(&instance, instanceID).Error; err != nil {
+		return nil, fmt.Errorf("实例不存在: %w", err)
+	}
+	if instance.Status == "creating" || instance.Status == "deleting" {
+		return nil, common.NewError(common.CodeValidationError, "实例正在创建或删除中，暂时无法获取日志")
+	}
+
+	prov, err := providerService.GetProviderInstanceByID(instance.ProviderID)
+	if err != nil {
+		return nil, fmt.Errorf("获取Provider实例失败: %w", err)
+	}
+	reader, ok := prov.(interface {
+		GetInstanceLogs(ctx context.Context, instanceName string, lines int, since time.Time) (string, error)
+	})
+	if !ok {
+		return nil, common.NewError(common.CodeValidationError, "该节点类型不支持获取实例日志")
+	}
+
+	lines := provider.ClampLogLines(req.Lines)
+	ctx, cancel := context.WithTimeout(context.Background(), provider.InstanceLogTimeout+provider.ExecTimeoutGrace)
+	defer cancel()
+
+	logs, err := reader.GetInstanceLogs(ctx, instance.Name, lines, req.Since)
+	truncated := errors.Is(err, utils.ErrOutputTruncated)
+	if err != nil && !truncated {
+		global.APP_LOG.Warn("获取实例日志失败",
+			zap.Uint("instanceID", instanceID),
+			zap.String("instanceName", instance.Name),
+			zap.Error(err))
+		return nil, common.NewError(common.CodeExternalAPIError, fmt.Sprintf("获取实例日志失败: %v", err))
+	}
+
+	return &userModel.InstanceLogsResponse{
+		Logs:      logs,
+		Lines:     lines,
+		Truncated: truncated,
+	}, nil
+}
@@ -0,0 +1,255 @@
+package instance
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"oneclickvirt/global"
+	"oneclickvirt/model/common"
+	providerModel "oneclickvirt/model/provider"
+	userModel "oneclickvirt/model/user"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// MinScheduleInterval 同一cron表达式相邻两次触发的最小间隔，避免频繁开关机
+const MinScheduleInterval = time.Hour
+
+const (
+	scheduleStatusSubmitted = "submitted"
+	scheduleStatusSkipped   = "skipped"
+	scheduleStatusFailed    = "failed"
+)
+
+// GetInstanceSchedule 获取实例定时开关机计划，未设置时返回nil
+func (s *Service) GetInstanceSchedule(userID, instanceID uint) (*userModel.InstanceScheduleResponse, error) {
+	if !s.HasInstanceAccess(userID, instanceID) {
+		return nil, common.NewError(common.CodeForbidden, "无权限访问此实例")
+	}
+
+	var schedule providerModel.InstanceSchedule
+	if err := global.APP_DB.Where("instance_id = ?", instanceID).First(&schedule).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("获取定时开关机计划失败: %w", err)
+	}
+	return buildScheduleResponse(schedule), nil
+}
+
+// SetInstanceSchedule 设置实例定时开关机计划，已存在时覆盖
+func (s *Service) SetInstanceSchedule(userID, instanceID uint, req userModel.SetInstanceScheduleRequest) (*userModel.InstanceScheduleResponse, error) {
+	if !s.HasInstanceAccess(userID, instanceID) {
+		return nil, common.NewError(common.CodeForbidden, "无权限访问此实例")
+	}
+
+	startCron := strings.Join(strings.Fields(req.StartCron), " ")
+	stopCron := strings.Join(strings.Fields(req.StopCron), " ")
+	if err := validateScheduleRequest(startCron, stopCron, req.Timezone); err != nil {
+		return nil, common.NewError(common.CodeValidationError, err.Error())
+	}
+
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+
+	schedule := providerModel.InstanceSchedule{InstanceID: instanceID}
+	err := global.APP_DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("instance_id = ?", instanceID).FirstOrInit(&schedule).Error; err != nil {
+			return err
+		}
+		schedule.UserID = userID
+		schedule.StartCron = startCron
+		schedule.StopCron = stopCron
+		schedule.Timezone = req.Timezone
+		schedule.Enabled = enabled
+		return tx.Save(&schedule).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("保存定时开关机计划失败: %w", err)
+	}
+
+	global.APP_LOG.Info("用户设置实例定时开关机计划",
+		zap.Uint("userID", userID),
+		zap.Uint("instanceID", instanceID),
+		zap.String("startCron", startCron),
+		zap.String("stopCron", stopCron),
+		zap.String("timezone", req.Timezone),
+		zap.Bool("enabled", enabled))
+
+	return buildScheduleResponse(schedule), nil
+}
+
+// ClearInstanceSchedule 删除实例定时开关机计划
+func (s *Service) ClearInstanceSchedule(userID, instanceID uint) error {
+	if !s.HasInstanceAccess(userID, instanceID) {
+		return common.NewError(common.CodeForbidden, "无权限访问此实例")
+	}
+	if err := global.APP_DB.Where("instance_id = ?", instanceID).Delete(&providerModel.InstanceSchedule{}).Error; err != nil {
+		return fmt.Errorf("删除定时开关机计划失败: %w", err)
+	}
+	global.APP_LOG.Info("用户删除实例定时开关机计划",
+		zap.Uint("userID", userID),
+		zap.Uint("instanceID", instanceID))
+	return nil
+}
+
+// validateScheduleRequest 校验时区、cron语法和触发频率
+func validateScheduleRequest(startCron, stopCron, timezone string) error {
+	if startCron == "" && stopCron == "" {
+		return errors.New("开机和关机计划至少需要设置一项")
+	}
+	if startCron != "" && startCron == stopCron {
+		return errors.New("开机和关机计划不能相同")
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil || timezone == "" || strings.EqualFold(timezone, "Local") {
+		return fmt.Errorf("时区无效: %s", timezone)
+	}
+
+	now := time.Now().In(loc)
+	for _, item := range []struct {
+		label string
+		expr  string
+	}{{"开机", startCron}, {"关机", stopCron}} {
+		if item.expr == "" {
+			continue
+		}
+		cron, err := utils.ParseCronExpression(item.expr)
+		if err != nil {
+			return fmt.Errorf("%s计划无效: %v", item.label, err)
+		}
+		if cron.Next(now).IsZero() {
+			return fmt.Errorf("%s计划不会被触发", item.label)
+		}
+		if cron.MinInterval(now) < MinScheduleInterval {
+			return fmt.Errorf("%s计划触发过于频繁，相邻两次触发需间隔至少%d分钟", item.label, int(MinScheduleInterval.Minutes()))
+		}
+	}
+	return nil
+}
+
+// buildScheduleResponse 计算下次开机、关机时间
+func buildScheduleResponse(schedule providerModel.InstanceSchedule) *userModel.InstanceScheduleResponse {
+	resp := &userModel.InstanceScheduleResponse{InstanceSchedule: schedule}
+	if !schedule.Enabled {
+		return resp
+	}
+	loc, err := time.LoadLocation(schedule.Timezone)
+	if err != nil {
+		return resp
+	}
+	now := time.Now().In(loc)
+	resp.NextStartAt = nextScheduleTime(schedule.StartCron, now)
+	resp.NextStopAt = nextScheduleTime(schedule.StopCron, now)
+	return resp
+}
+
+func nextScheduleTime(expr string, now time.Time) *time.Time {
+	if expr == "" {
+		return nil
+	}
+	cron, err := utils.ParseCronExpression(expr)
+	if err != nil {
+		return nil
+	}
+	next := cron.Next(now)
+	if next.IsZero() {
+		return nil
+	}
+	return &next
+}
+
+// RunDueInstanceSchedules 执行在 (from, to] 区间内到期的定时开关机操作
+// 操作通过与用户手动开关机相同的路径创建start/stop任务，任务记录在任务历史中；
+// 实例已处于目标状态或有进行中的任务时跳过，结果记录在计划的最近执行信息中
+func (s *Service) RunDueInstanceSchedules(from, to time.Time) {
+	var schedules []providerModel.InstanceSchedule
+	if err := global.APP_DB.Where("enabled = ?", true).Find(&schedules).Error; err != nil {
+		global.APP_LOG.Warn("查询定时开关机计划失败", zap.Error(err))
+		return
+	}
+
+	for _, schedule := range schedules {
+		loc, err := time.LoadLocation(schedule.Timezone)
+		if err != nil {
+			continue
+		}
+		action, firedAt := dueScheduleAction(schedule, from.In(loc), to.In(loc))
+		if action == "" {
+			continue
+		}
+		if schedule.LastRunAt != nil && !schedule.LastRunAt.Before(firedAt) {
+			continue
+		}
+		s.runScheduledAction(schedule, action, firedAt)
+	}
+}
+
+// dueScheduleAction 返回区间内最后一次到期的操作，开机和关机在同一分钟触发时以关机为准
+func dueScheduleAction(schedule providerModel.InstanceSchedule, from, to time.Time) (string, time.Time) {
+	startCron, _ := parseOptionalCron(schedule.StartCron)
+	stopCron, _ := parseOptionalCron(schedule.StopCron)
+
+	var action string
+	var firedAt time.Time
+	for t := from.Truncate(time.Minute).Add(time.Minute); !t.After(to); t = t.Add(time.Minute) {
+		if stopCron != nil && stopCron.Matches(t) {
+			action, firedAt = "stop", t
+		} else if startCron != nil && startCron.Matches(t) {
+			action, firedAt = "start", t
+		}
+	}
+	return action, firedAt
+}
+
+func parseOptionalCron(expr string) (*utils.CronExpression, error) {
+	if expr == "" {
+		return nil, nil
+	}
+	return utils.ParseCronExpression(expr)
+}
+
+// runScheduledAction 以计划所属用户身份执行开关机，并记录执行结果
+func (s *Service) runScheduledAction(schedule providerModel.InstanceSchedule, action string, firedAt time.Time) {
+	status := scheduleStatusSubmitted
+	message := ""
+
+	var instance providerModel.Instance
+	if err := global.APP_DB.Select("id, status").First(&instance, schedule.InstanceID).Error; err != nil {
+		status, message = scheduleStatusFailed, "实例不存在"
+	} else if (action == "start" && instance.Status == "running") || (action == "stop" && instance.Status == "stopped") {
+		status, message = scheduleStatusSkipped, fmt.Sprintf("实例已处于%s状态", instance.Status)
+	} else if err := s.InstanceAction(schedule.UserID, userModel.InstanceActionRequest{
+		InstanceID: schedule.InstanceID,
+		Action:     action,
+	}); err != nil {
+		status, message = scheduleStatusFailed, err.Error()
+	}
+
+	if err := global.APP_DB.Model(&providerModel.InstanceSchedule{}).
+		Where("id = ?", schedule.ID).
+		Updates(map[string]interface{}{
+			"last_action": action,
+			"last_status": status,
+			"last_error":  strings.ToValidUTF8(utils.TruncateString(message, 255), ""),
+			"last_run_at": firedAt,
+		}).Error; err != nil {
+		global.APP_LOG.Warn("更新定时开关机计划执行结果失败",
+			zap.Uint("scheduleID", schedule.ID),
+			zap.Error(err))
+	}
+
+	global.APP_LOG.Info("执行实例定时开关机",
+		zap.Uint("instanceID", schedule.InstanceID),
+		zap.Uint("userID", schedule.UserID),
+		zap.String("action", action),
+		zap.String("status", status),
+		zap.String("message", message),
+		zap.Time("firedAt", firedAt))
+}
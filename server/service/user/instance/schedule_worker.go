@@ -0,0 +1,87 @@
+package instance
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"oneclickvirt/global"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// scheduleCheckInterval 定时开关机计划的检查周期，cron精度为分钟
+	scheduleCheckInterval = 30 * time.Second
+	// scheduleMaxCatchUp 检查中断（如服务重启）后最多补执行的时间范围
+	scheduleMaxCatchUp = 5 * time.Minute
+)
+
+// ScheduleWorker 定期检查并执行到期的实例定时开关机计划
+type ScheduleWorker struct {
+	service  *Service
+	stopChan chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+var (
+	scheduleWorker     *ScheduleWorker
+	scheduleWorkerOnce sync.Once
+)
+
+// GetScheduleWorker 获取定时开关机执行器单例
+func GetScheduleWorker() *ScheduleWorker {
+	scheduleWorkerOnce.Do(func() {
+		scheduleWorker = &ScheduleWorker{
+			service:  NewService(),
+			stopChan: make(chan struct{}),
+		}
+	})
+	return scheduleWorker
+}
+
+// Start 启动定时开关机执行器
+func (w *ScheduleWorker) Start(ctx context.Context) {
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		defer func() {
+			if r := recover(); r != nil {
+				global.APP_LOG.Error("定时开关机任务panic", zap.Any("panic", r), zap.Stack("stack"))
+			}
+		}()
+
+		ticker := time.NewTicker(scheduleCheckInterval)
+		defer ticker.Stop()
+
+		// 从当前分钟开始检查，启动前错过的触发点不补执行
+		lastCheck := time.Now().Truncate(time.Minute)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-w.stopChan:
+				return
+			case <-ticker.C:
+				if global.APP_DB == nil {
+					continue
+				}
+				now := time.Now()
+				if now.Sub(lastCheck) > scheduleMaxCatchUp {
+					lastCheck = now.Add(-scheduleMaxCatchUp)
+				}
+				w.service.RunDueInstanceSchedules(lastCheck, now)
+				lastCheck = now
+			}
+		}
+	}()
+}
+
+// Stop 停止定时开关机执行器
+func (w *ScheduleWorker) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stopChan)
+	})
+	w.wg.Wait()
+}
@@ -8,6 +8,7 @@ import (
 	"oneclickvirt/service/auth"
 	"oneclickvirt/service/cache"
 	"oneclickvirt/service/database"
+	providerService "oneclickvirt/service/provider"
 	"oneclickvirt/service/task"
 	trafficService "oneclickvirt/service/traffic"
 	"oneclickvirt/utils"
@@ -58,6 +59,18 @@ func (s *Service) GetUserInstances(userID uint, req userModel.UserInstanceListRe
 	if req.ProviderName != "" {
 		query = query.Where("provider LIKE ?", "%"+req.ProviderName+"%")
 	}
+	// 支持按标签筛选，tags列以JSON存储
+	if req.TagKey != "" {
+		if err := utils.ValidateInstanceTagKey(req.TagKey); err != nil {
+			return nil, 0, err
+		}
+		tagPath := fmt.Sprintf(`$."%s"`, req.TagKey)
+		if req.TagValue != "" {
+			query = query.Where("JSON_UNQUOTE(JSON_EXTRACT(tags, ?)) = ?", tagPath, req.TagValue)
+		} else {
+			query = query.Where("JSON_EXTRACT(tags, ?) IS NOT NULL", tagPath)
+		}
+	}
 
 	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
@@ -166,9 +179,9 @@ func (s *Service) GetUserInstances(userID uint, req userModel.UserInstanceListRe
 
 		userInstance := userModel.UserInstanceResponse{
 			Instance:       modifiedInstance,
-			CanStart:       instance.Status == "stopped" && !instance.TrafficLimited, // 流量受限时不能启动
+			CanStart:       instance.Status == "stopped" && !instance.TrafficLimited && instance.DeleteScheduledAt == nil, // 流量受限或计划删除时不能启动
 			CanStop:        instance.Status == "running" || instance.Status == "unavailable",
-			CanRestart:     instance.Status == "running" && !instance.TrafficLimited, // 流量受限时不能重启
+			CanRestart:     instance.Status == "running" && !instance.TrafficLimited && instance.DeleteScheduledAt == nil, // 流量受限或计划删除时不能重启
 			CanDelete:      instance.Status != "deleting",
 			PortMappings:   portMappings,
 			PublicIP:       instance.PublicIP, // 直接使用实例的PublicIP字段
@@ -182,22 +195,33 @@ func (s *Service) GetUserInstances(userID uint, req userModel.UserInstanceListRe
 }
 
 // InstanceAction 执行实例操作
+// 所有者可执行全部操作，共享角色为operator的用户只能开机、关机和重启，任务以所有者身份创建
 func (s *Service) InstanceAction(userID uint, req userModel.InstanceActionRequest) error {
-	var instance providerModel.Instance
-	if err := global.APP_DB.Where("id = ? AND user_id = ?", req.InstanceID, userID).First(&instance).Error; err != nil {
+	found, sharedRole, err := getAccessibleInstance(userID, req.InstanceID, providerModel.ShareRoleOperator)
+	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return errors.New("实例不存在或无权限")
 		}
 		return err
 	}
+	instance := *found
+	if sharedRole != "" && req.Action != "start" && req.Action != "stop" && req.Action != "restart" {
+		return errors.New("只有实例所有者可以执行此操作")
+	}
+	ownerID := instance.UserID
 
 	// 操作完成后使缓存失效
 	defer func() {
 		cacheService := cache.GetUserCacheService()
-		cacheService.InvalidateUserCache(userID)
+		cacheService.InvalidateUserCache(ownerID)
 		cacheService.InvalidateInstanceCache(req.InstanceID)
 	}()
 
+	// 计划删除的实例只允许撤销删除或再次删除（立即删除）
+	if instance.DeleteScheduledAt != nil && req.Action != "delete" {
+		return errors.New("实例已计划删除，请先撤销删除")
+	}
+
 	switch req.Action {
 	case "start":
 		if instance.Status != "stopped" {
@@ -213,7 +237,7 @@ func (s *Service) InstanceAction(userID uint, req userModel.InstanceActionReques
 		// 创建启动任务
 		taskService := getTaskService()
 		taskData := fmt.Sprintf(`{"instanceId":%d,"providerId":%d}`, instance.ID, instance.ProviderID)
-		_, err := taskService.CreateTask(userID, &instance.ProviderID, &instance.ID, "start", taskData, 1800)
+		_, err := taskService.CreateTask(ownerID, &instance.ProviderID, &instance.ID, "start", taskData, 1800)
 		if err != nil {
 			return fmt.Errorf("创建启动任务失败: %v", err)
 		}
@@ -233,7 +257,7 @@ func (s *Service) InstanceAction(userID uint, req userModel.InstanceActionReques
 		// 创建停止任务
 		taskService := getTaskService()
 		taskData := fmt.Sprintf(`{"instanceId":%d,"providerId":%d}`, instance.ID, instance.ProviderID)
-		_, err := taskService.CreateTask(userID, &instance.ProviderID, &instance.ID, "stop", taskData, 1800)
+		_, err := taskService.CreateTask(ownerID, &instance.ProviderID, &instance.ID, "stop", taskData, 1800)
 		if err != nil {
 			return fmt.Errorf("创建停止任务失败: %v", err)
 		}
@@ -253,7 +277,7 @@ func (s *Service) InstanceAction(userID uint, req userModel.InstanceActionReques
 		// 创建重启任务
 		taskService := getTaskService()
 		taskData := fmt.Sprintf(`{"instanceId":%d,"providerId":%d}`, instance.ID, instance.ProviderID)
-		_, err := taskService.CreateTask(userID, &instance.ProviderID, &instance.ID, "restart", taskData, 1800)
+		_, err := taskService.CreateTask(ownerID, &instance.ProviderID, &instance.ID, "restart", taskData, 1800)
 		if err != nil {
 			return fmt.Errorf("创建重启任务失败: %v", err)
 		}
@@ -302,6 +326,11 @@ func (s *Service) InstanceAction(userID uint, req userModel.InstanceActionReques
 			return errors.New("实例已有删除任务正在进行")
 		}
 
+		// 配置了删除等待期时先停机保留，等待期内再次删除则立即删除
+		if graceHours := global.APP_CONFIG.Quota.InstanceDeleteGraceHours; graceHours > 0 && instance.DeleteScheduledAt == nil {
+			return s.scheduleInstanceDeletion(userID, &instance, graceHours)
+		}
+
 		// 创建删除任务
 		taskService := getTaskService()
 		taskData := fmt.Sprintf(`{"instanceId":%d,"providerId":%d}`, instance.ID, instance.ProviderID)
@@ -323,16 +352,16 @@ func (s *Service) InstanceAction(userID uint, req userModel.InstanceActionReques
 }
 
 // GetInstanceDetail 获取实例详情
+// 通过共享访问的用户可以查看详情，但不返回实例登录密码
 func (s *Service) GetInstanceDetail(userID, instanceID uint) (*userModel.UserInstanceDetailResponse, error) {
-	var instance providerModel.Instance
-	err := global.APP_DB.Where("id = ? AND user_id = ?", instanceID, userID).
-		First(&instance).Error
+	found, sharedRole, err := getAccessibleInstance(userID, instanceID, providerModel.ShareRoleViewer)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.New("实例不存在")
 		}
 		return nil, err
 	}
+	instance := *found
 
 	// 获取SSH端口映射的公网端口
 	var sshPort int
@@ -344,24 +373,32 @@ func (s *Service) GetInstanceDetail(userID, instanceID uint) (*userModel.UserIns
 	}
 
 	detail := &userModel.UserInstanceDetailResponse{
-		ID:          instance.ID,
-		Name:        instance.Name,
-		Type:        instance.InstanceType,
-		Status:      instance.Status,
-		CPU:         instance.CPU,
-		Memory:      int(instance.Memory),
-		Disk:        int(instance.Disk),
-		Bandwidth:   instance.Bandwidth,
-		OsType:      instance.OSType,
-		PrivateIP:   instance.PrivateIP,   // 使用实例的内网IP
-		PublicIP:    instance.PublicIP,    // 使用实例的公网IP
-		IPv6Address: instance.IPv6Address, // 内网IPv6地址
-		PublicIPv6:  instance.PublicIPv6,  // 公网IPv6地址
-		SSHPort:     sshPort,              // 使用映射的公网端口
-		Username:    instance.Username,
-		Password:    instance.Password,
-		CreatedAt:   instance.CreatedAt,
-		ExpiredAt:   instance.ExpiredAt,
+		ID:                instance.ID,
+		Name:              instance.Name,
+		Type:              instance.InstanceType,
+		Status:            instance.Status,
+		CPU:               instance.CPU,
+		Memory:            int(instance.Memory),
+		Disk:              int(instance.Disk),
+		Bandwidth:         instance.Bandwidth,
+		OsType:            instance.OSType,
+		PrivateIP:         instance.PrivateIP,   // 使用实例的内网IP
+		PublicIP:          instance.PublicIP,    // 使用实例的公网IP
+		IPv6Address:       instance.IPv6Address, // 内网IPv6地址
+		PublicIPv6:        instance.PublicIPv6,  // 公网IPv6地址
+		ReservedIPv4:      instance.ReservedIPv4,
+		ReservedIPv6:      instance.ReservedIPv6,
+		SSHPort:           sshPort, // 使用映射的公网端口
+		Username:          instance.Username,
+		Password:          instance.Password,
+		Tags:              instance.Tags,
+		CreatedAt:         instance.CreatedAt,
+		ExpiredAt:         instance.ExpiredAt,
+		DeleteScheduledAt: instance.DeleteScheduledAt,
+		SharedRole:        sharedRole,
+	}
+	if sharedRole != "" {
+		detail.Password = ""
 	}
 
 	// 查询关联的 Provider 信息
@@ -391,6 +428,7 @@ func (s *Service) GetInstanceDetail(userID, instanceID uint) (*userModel.UserIns
 			TaskType:      task.TaskType,
 			Status:        task.Status,
 			Progress:      task.Progress,
+			Priority:      task.Priority,
 			StatusMessage: task.StatusMessage,
 			CreatedAt:     task.CreatedAt,
 			UpdatedAt:     task.UpdatedAt,
@@ -515,6 +553,16 @@ func (s *Service) GetInstanceMonitoring(userID, instanceID uint) (*userModel.Ins
 		},
 	}
 
+	if instance.Status == "running" {
+		if diskUsage, err := providerService.GetInstanceDiskUsage(instance); err != nil {
+			global.APP_LOG.Debug("获取实例磁盘使用量失败",
+				zap.Uint("instanceID", instanceID),
+				zap.Error(err))
+		} else {
+			monitoring.DiskUsage = diskUsage
+		}
+	}
+
 	return monitoring, nil
 }
 
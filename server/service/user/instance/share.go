@@ -0,0 +1,206 @@
+package instance
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"oneclickvirt/global"
+	"oneclickvirt/model/common"
+	providerModel "oneclickvirt/model/provider"
+	userModel "oneclickvirt/model/user"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// getAccessibleInstance 获取用户可访问的实例及访问角色
+// 所有者返回空角色；其他用户需要有未过期且满足所需角色的共享授权，否则返回gorm.ErrRecordNotFound
+func getAccessibleInstance(userID, instanceID uint, required string) (*providerModel.Instance, string, error) {
+	var instance providerModel.Instance
+	if err := global.APP_DB.First(&instance, instanceID).Error; err != nil {
+		return nil, "", err
+	}
+	if instance.UserID == userID {
+		return &instance, "", nil
+	}
+
+	var share providerModel.InstanceShare
+	if err := global.APP_DB.
+		Where("instance_id = ? AND shared_user_id = ?", instanceID, userID).
+		Where("expires_at IS NULL OR expires_at > ?", time.Now()).
+		First(&share).Error; err != nil {
+		return nil, "", err
+	}
+	// 实例转移给其他用户后，原所有者授予的共享失效
+	if share.OwnerID != instance.UserID || !providerModel.ShareRoleAllows(share.Role, required) {
+		return nil, "", gorm.ErrRecordNotFound
+	}
+	return &instance, share.Role, nil
+}
+
+// GrantInstanceShare 将实例共享给其他用户，同一用户重复授权时更新角色和过期时间
+func (s *Service) GrantInstanceShare(userID, instanceID uint, req userModel.GrantInstanceShareRequest) (*userModel.InstanceShareResponse, error) {
+	if !s.HasInstanceAccess(userID, instanceID) {
+		return nil, common.NewError(common.CodeForbidden, "实例不存在或无权限")
+	}
+	if !providerModel.IsValidShareRole(req.Role) {
+		return nil, common.NewError(common.CodeValidationError, "无效的共享角色")
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, common.NewError(common.CodeValidationError, "过期时间必须晚于当前时间")
+	}
+
+	var target userModel.User
+	if err := global.APP_DB.Where("username = ?", req.Username).First(&target).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, common.NewError(common.CodeNotFound, "用户不存在")
+		}
+		return nil, fmt.Errorf("查询用户失败: %w", err)
+	}
+	if target.ID == userID {
+		return nil, common.NewError(common.CodeValidationError, "不能将实例共享给自己")
+	}
+	if target.Status != 1 {
+		return nil, common.NewError(common.CodeValidationError, "该用户已被禁用")
+	}
+
+	share := providerModel.InstanceShare{
+		InstanceID:   instanceID,
+		OwnerID:      userID,
+		SharedUserID: target.ID,
+		Role:         req.Role,
+		ExpiresAt:    req.ExpiresAt,
+	}
+	if err := global.APP_DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "instance_id"}, {Name: "shared_user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"owner_id", "role", "expires_at", "updated_at"}),
+	}).Create(&share).Error; err != nil {
+		return nil, fmt.Errorf("保存共享授权失败: %w", err)
+	}
+	if err := global.APP_DB.
+		Where("instance_id = ? AND shared_user_id = ?", instanceID, target.ID).
+		First(&share).Error; err != nil {
+		return nil, fmt.Errorf("获取共享授权失败: %w", err)
+	}
+
+	global.APP_LOG.Info("用户共享实例",
+		zap.Uint("userID", userID),
+		zap.Uint("instanceID", instanceID),
+		zap.Uint("sharedUserID", target.ID),
+		zap.String("role", req.Role))
+
+	return &userModel.InstanceShareResponse{InstanceShare: share, Username: target.Username}, nil
+}
+
+// GetInstanceShares 获取实例的共享授权列表，仅所有者可查看
+func (s *Service) GetInstanceShares(userID, instanceID uint) ([]userModel.InstanceShareResponse, error) {
+	if !s.HasInstanceAccess(userID, instanceID) {
+		return nil, common.NewError(common.CodeForbidden, "实例不存在或无权限")
+	}
+
+	var shares []providerModel.InstanceShare
+	if err := global.APP_DB.Where("instance_id = ? AND owner_id = ?", instanceID, userID).
+		Order("created_at ASC").Find(&shares).Error; err != nil {
+		return nil, fmt.Errorf("获取共享授权失败: %w", err)
+	}
+
+	userIDs := make([]uint, 0, len(shares))
+	for _, share := range shares {
+		userIDs = append(userIDs, share.SharedUserID)
+	}
+	usernames := make(map[uint]string, len(userIDs))
+	if len(userIDs) > 0 {
+		var users []userModel.User
+		global.APP_DB.Select("id, username").Where("id IN ?", userIDs).Find(&users)
+		for _, u := range users {
+			usernames[u.ID] = u.Username
+		}
+	}
+
+	result := make([]userModel.InstanceShareResponse, 0, len(shares))
+	for _, share := range shares {
+		result = append(result, userModel.InstanceShareResponse{
+			InstanceShare: share,
+			Username:      usernames[share.SharedUserID],
+		})
+	}
+	return result, nil
+}
+
+// RevokeInstanceShare 撤销实例共享授权，仅所有者可撤销
+func (s *Service) RevokeInstanceShare(userID, instanceID, shareID uint) error {
+	if !s.HasInstanceAccess(userID, instanceID) {
+		return common.NewError(common.CodeForbidden, "实例不存在或无权限")
+	}
+
+	result := global.APP_DB.Where("id = ? AND instance_id = ?", shareID, instanceID).
+		Delete(&providerModel.InstanceShare{})
+	if result.Error != nil {
+		return fmt.Errorf("撤销共享授权失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return common.NewError(common.CodeNotFound, "共享授权不存在")
+	}
+
+	global.APP_LOG.Info("用户撤销实例共享",
+		zap.Uint("userID", userID),
+		zap.Uint("instanceID", instanceID),
+		zap.Uint("shareID", shareID))
+	return nil
+}
+
+// GetSharedInstances 获取其他用户共享给当前用户且仍有效的实例
+func (s *Service) GetSharedInstances(userID uint) ([]userModel.SharedInstanceResponse, error) {
+	var shares []providerModel.InstanceShare
+	if err := global.APP_DB.Where("shared_user_id = ?", userID).
+		Where("expires_at IS NULL OR expires_at > ?", time.Now()).
+		Order("created_at DESC").Find(&shares).Error; err != nil {
+		return nil, fmt.Errorf("获取共享实例失败: %w", err)
+	}
+	if len(shares) == 0 {
+		return []userModel.SharedInstanceResponse{}, nil
+	}
+
+	instanceIDs := make([]uint, 0, len(shares))
+	for _, share := range shares {
+		instanceIDs = append(instanceIDs, share.InstanceID)
+	}
+	var instances []providerModel.Instance
+	if err := global.APP_DB.Where("id IN ?", instanceIDs).Find(&instances).Error; err != nil {
+		return nil, fmt.Errorf("获取共享实例失败: %w", err)
+	}
+	instanceMap := make(map[uint]providerModel.Instance, len(instances))
+	ownerIDs := make([]uint, 0, len(instances))
+	for _, instance := range instances {
+		instanceMap[instance.ID] = instance
+		ownerIDs = append(ownerIDs, instance.UserID)
+	}
+	ownerNames := make(map[uint]string, len(ownerIDs))
+	var owners []userModel.User
+	global.APP_DB.Select("id, username").Where("id IN ?", ownerIDs).Find(&owners)
+	for _, owner := range owners {
+		ownerNames[owner.ID] = owner.Username
+	}
+
+	result := make([]userModel.SharedInstanceResponse, 0, len(shares))
+	for _, share := range shares {
+		instance, ok := instanceMap[share.InstanceID]
+		// 实例已删除或已转移给其他用户时不再展示
+		if !ok || instance.UserID != share.OwnerID {
+			continue
+		}
+		result = append(result, userModel.SharedInstanceResponse{
+			ID:           instance.ID,
+			Name:         instance.Name,
+			Type:         instance.InstanceType,
+			Status:       instance.Status,
+			ProviderName: instance.Provider,
+			OwnerName:    ownerNames[instance.UserID],
+			Role:         share.Role,
+			ExpiresAt:    share.ExpiresAt,
+		})
+	}
+	return result, nil
+}
@@ -0,0 +1,73 @@
+package instance
+
+import (
+	"fmt"
+	"time"
+
+	"oneclickvirt/global"
+	"oneclickvirt/model/common"
+	providerModel "oneclickvirt/model/provider"
+	userModel "oneclickvirt/model/user"
+	"oneclickvirt/service/cache"
+
+	"go.uber.org/zap"
+)
+
+// scheduleInstanceDeletion 将实例标记为在等待期结束后删除，运行中的实例先提交停机任务
+// 等待期内实例保留并继续占用配额，用户可撤销删除；到期后由系统维护任务执行删除
+func (s *Service) scheduleInstanceDeletion(userID uint, instance *providerModel.Instance, graceHours int) error {
+	scheduledAt := time.Now().Add(time.Duration(graceHours) * time.Hour)
+	updates := map[string]interface{}{"delete_scheduled_at": scheduledAt}
+
+	if instance.Status == "running" {
+		taskService := getTaskService()
+		taskData := fmt.Sprintf(`{"instanceId":%d,"providerId":%d}`, instance.ID, instance.ProviderID)
+		if _, err := taskService.CreateTask(userID, &instance.ProviderID, &instance.ID, "stop", taskData, 1800); err != nil {
+			return fmt.Errorf("创建停止任务失败: %v", err)
+		}
+		updates["status"] = "stopping"
+	}
+
+	if err := global.APP_DB.Model(instance).Updates(updates).Error; err != nil {
+		return fmt.Errorf("标记实例删除失败: %v", err)
+	}
+
+	global.APP_LOG.Info("实例已计划删除",
+		zap.Uint("userID", userID),
+		zap.Uint("instanceID", instance.ID),
+		zap.String("instanceName", instance.Name),
+		zap.Time("deleteScheduledAt", scheduledAt))
+	return nil
+}
+
+// UndeleteInstance 在删除等待期内撤销删除，实例保持停机状态，需要用户手动启动
+func (s *Service) UndeleteInstance(userID, instanceID uint) (*userModel.UndeleteInstanceResponse, error) {
+	var instance providerModel.Instance
+	if err := global.APP_DB.Where("id = ? AND user_id = ?", instanceID, userID).First(&instance).Error; err != nil {
+		return nil, common.NewError(common.CodeForbidden, "实例不存在或无权限")
+	}
+	if instance.DeleteScheduledAt == nil {
+		return nil, common.NewError(common.CodeValidationError, "实例未计划删除")
+	}
+
+	// 条件更新，避免与到期删除并发
+	result := global.APP_DB.Model(&providerModel.Instance{}).
+		Where("id = ? AND delete_scheduled_at IS NOT NULL AND status <> ?", instanceID, "deleting").
+		Update("delete_scheduled_at", nil)
+	if result.Error != nil {
+		return nil, fmt.Errorf("撤销删除失败: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, common.NewError(common.CodeValidationError, "删除等待期已结束，实例正在删除")
+	}
+
+	cacheService := cache.GetUserCacheService()
+	cacheService.InvalidateUserCache(userID)
+	cacheService.InvalidateInstanceCache(instanceID)
+
+	global.APP_LOG.Info("用户撤销实例删除",
+		zap.Uint("userID", userID),
+		zap.Uint("instanceID", instanceID),
+		zap.String("instanceName", instance.Name))
+	return &userModel.UndeleteInstanceResponse{ID: instanceID, Status: instance.Status}, nil
+}
@@ -0,0 +1,80 @@
+package instance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"oneclickvirt/global"
+	providerModel "oneclickvirt/model/provider"
+	providerService "oneclickvirt/service/provider"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+)
+
+// UpdateInstanceTags 整体替换实例标签
+// 数据库为标签的唯一来源，Provider原生标签仅做尽力同步，同步失败不影响结果
+func (s *Service) UpdateInstanceTags(userID uint, instanceID uint, tags map[string]string) (map[string]string, error) {
+	if !s.HasInstanceAccess(userID, instanceID) {
+		return nil, errors.New("无权限访问此实例")
+	}
+
+	if err := utils.ValidateInstanceTags(tags); err != nil {
+		return nil, err
+	}
+	if tags == nil {
+		tags = map[string]string{}
+	}
+
+	var instance providerModel.Instance
+	if err := global.APP_DB.First(&instance, instanceID).Error; err != nil {
+		return nil, fmt.Errorf("实例不存在: %w", err)
+	}
+
+	previous := instance.Tags
+	if err := global.APP_DB.Model(&instance).Update("tags", tags).Error; err != nil {
+		return nil, fmt.Errorf("更新实例标签失败: %w", err)
+	}
+
+	s.syncInstanceTags(instance, previous, tags)
+
+	return tags, nil
+}
+
+// syncInstanceTags 将标签变更同步到Provider原生标签
+// Docker容器label创建后不可修改，仅LXD/Incus支持同步
+func (s *Service) syncInstanceTags(instance providerModel.Instance, previous, current map[string]string) {
+	prov, err := providerService.GetProviderInstanceByID(instance.ProviderID)
+	if err != nil {
+		global.APP_LOG.Warn("获取Provider实例失败，跳过标签同步",
+			zap.Uint("instanceID", instance.ID),
+			zap.Error(err))
+		return
+	}
+
+	tagger, ok := prov.(interface {
+		SetInstanceTags(ctx context.Context, instanceName string, set map[string]string, unset []string) error
+	})
+	if !ok {
+		return
+	}
+
+	var unset []string
+	for k := range previous {
+		if _, exists := current[k]; !exists {
+			unset = append(unset, k)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := tagger.SetInstanceTags(ctx, instance.Name, current, unset); err != nil {
+		global.APP_LOG.Warn("同步实例标签到Provider失败",
+			zap.Uint("instanceID", instance.ID),
+			zap.String("instanceName", instance.Name),
+			zap.Error(err))
+	}
+}
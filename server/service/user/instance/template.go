@@ -0,0 +1,194 @@
+package instance
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"oneclickvirt/constant"
+	"oneclickvirt/global"
+	adminModel "oneclickvirt/model/admin"
+	"oneclickvirt/model/common"
+	providerModel "oneclickvirt/model/provider"
+	userModel "oneclickvirt/model/user"
+	"oneclickvirt/service/resources"
+	"oneclickvirt/service/task"
+	userProviderService "oneclickvirt/service/user/provider"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// CreateInstanceFromTemplate 从模板创建实例
+// 实例落在模板所在节点，按用户选择的规格计入配额并占用节点资源，实际复制由异步任务完成
+func (s *Service) CreateInstanceFromTemplate(userID uint, templateID uint, req userModel.CreateInstanceFromTemplateRequest) (*userModel.CreateInstanceFromTemplateResponse, error) {
+	var template providerModel.InstanceTemplate
+	if err := global.APP_DB.First(&template, templateID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, common.NewError(common.CodeNotFound, "模板不存在")
+		}
+		return nil, fmt.Errorf("获取模板失败: %w", err)
+	}
+	if !template.Enabled || template.Status != providerModel.TemplateStatusReady {
+		return nil, common.NewError(common.CodeValidationError, "模板暂不可用")
+	}
+
+	if req.Name != "" && !cloneNamePattern.MatchString(req.Name) {
+		return nil, common.NewError(common.CodeValidationError, "实例名称只能包含字母、数字和连字符，且必须以字母开头")
+	}
+	password := req.Password
+	if password == "" {
+		password = utils.GenerateInstancePassword()
+	} else if err := utils.ValidatePasswordPolicy(password, utils.CurrentPasswordPolicy()); err != nil {
+		// 用户指定的密码与系统生成的密码遵循同一策略
+		return nil, common.NewError(common.CodeValidationError, err.Error())
+	}
+
+	cpu, memory, disk, bandwidth, err := templateInstanceSpecs(template, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var provider providerModel.Provider
+	if err := global.APP_DB.First(&provider, template.ProviderID).Error; err != nil {
+		return nil, fmt.Errorf("获取模板所在节点失败: %w", err)
+	}
+	if err := userProviderService.ValidateProviderAcceptsNewInstance(&provider); err != nil {
+		return nil, common.NewError(common.CodeValidationError, err.Error())
+	}
+
+	// 到期时间与节点同步，节点未设置到期时间时默认为1年后
+	expiredAt := time.Now().AddDate(1, 0, 0)
+	if provider.ExpiresAt != nil {
+		expiredAt = *provider.ExpiresAt
+	}
+
+	var instance providerModel.Instance
+	err = global.APP_DB.Transaction(func(tx *gorm.DB) error {
+		quotaResult, err := resources.NewQuotaService().ValidateInTransaction(tx, resources.ResourceRequest{
+			UserID:       userID,
+			CPU:          cpu,
+			Memory:       memory,
+			Disk:         disk,
+			Bandwidth:    bandwidth,
+			InstanceType: template.InstanceType,
+			ProviderID:   template.ProviderID,
+		})
+		if err != nil {
+			return fmt.Errorf("配额验证失败: %w", err)
+		}
+		if !quotaResult.Allowed {
+			return errors.New(quotaResult.Reason)
+		}
+
+		name, err := s.resolveCloneName(tx, providerModel.Instance{Provider: provider.Name, ProviderID: provider.ID}, req.Name)
+		if err != nil {
+			return err
+		}
+
+		instance = providerModel.Instance{
+			Name:         name,
+			Provider:     provider.Name,
+			ProviderID:   provider.ID,
+			Status:       "creating",
+			Image:        template.Image,
+			InstanceType: template.InstanceType,
+			CPU:          cpu,
+			Memory:       memory,
+			Disk:         disk,
+			Bandwidth:    bandwidth,
+			SSHPort:      22,
+			Username:     "root",
+			Password:     password,
+			OSType:       template.OSType,
+			Region:       provider.Region,
+			ExpiredAt:    expiredAt,
+			TemplateID:   &template.ID,
+			UserID:       userID,
+		}
+		if err := tx.Create(&instance).Error; err != nil {
+			return fmt.Errorf("创建实例记录失败: %w", err)
+		}
+
+		if err := resources.NewQuotaService().UpdateUserQuotaAfterCreationWithTx(tx, userID, resources.ResourceUsage{
+			CPU:       cpu,
+			Memory:    memory,
+			Disk:      disk,
+			Bandwidth: bandwidth,
+		}); err != nil {
+			return fmt.Errorf("更新用户配额失败: %w", err)
+		}
+
+		resourceService := &resources.ResourceService{}
+		if err := resourceService.AllocateResourcesInTx(tx, provider.ID, template.InstanceType, cpu, memory, disk); err != nil {
+			return fmt.Errorf("分配Provider资源失败: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	taskData, _ := json.Marshal(adminModel.TemplateInstanceTaskRequest{
+		TemplateId: template.ID,
+		InstanceId: instance.ID,
+		ProviderId: provider.ID,
+	})
+	taskModel, err := task.GetTaskService().CreateTask(userID, &instance.ProviderID, &instance.ID, "create-from-template", string(taskData), utils.GetDefaultTaskTimeout("create-from-template"))
+	if err != nil {
+		s.discardPendingClone(instance)
+		return nil, fmt.Errorf("创建任务失败: %w", err)
+	}
+
+	global.APP_LOG.Info("用户从模板创建实例",
+		zap.Uint("userID", userID),
+		zap.Uint("templateID", template.ID),
+		zap.Uint("instanceID", instance.ID),
+		zap.String("name", instance.Name),
+		zap.Uint("taskID", taskModel.ID))
+
+	return &userModel.CreateInstanceFromTemplateResponse{
+		TaskID:     taskModel.ID,
+		InstanceID: instance.ID,
+		Name:       instance.Name,
+	}, nil
+}
+
+// templateInstanceSpecs 解析用户选择的规格，未选择的使用模板默认值；磁盘不能小于模板磁盘
+func templateInstanceSpecs(template providerModel.InstanceTemplate, req userModel.CreateInstanceFromTemplateRequest) (int, int64, int64, int, error) {
+	cpu, memory, disk, bandwidth := template.CPU, template.Memory, template.Disk, template.Bandwidth
+	if req.CPUId != "" {
+		spec, err := constant.GetCPUSpecByID(req.CPUId)
+		if err != nil {
+			return 0, 0, 0, 0, common.NewError(common.CodeValidationError, fmt.Sprintf("无效的CPU规格ID: %v", err))
+		}
+		cpu = spec.Cores
+	}
+	if req.MemoryId != "" {
+		spec, err := constant.GetMemorySpecByID(req.MemoryId)
+		if err != nil {
+			return 0, 0, 0, 0, common.NewError(common.CodeValidationError, fmt.Sprintf("无效的内存规格ID: %v", err))
+		}
+		memory = int64(spec.SizeMB)
+	}
+	if req.DiskId != "" {
+		spec, err := constant.GetDiskSpecByID(req.DiskId)
+		if err != nil {
+			return 0, 0, 0, 0, common.NewError(common.CodeValidationError, fmt.Sprintf("无效的磁盘规格ID: %v", err))
+		}
+		if int64(spec.SizeMB) < template.Disk {
+			return 0, 0, 0, 0, common.NewError(common.CodeValidationError, fmt.Sprintf("磁盘不能小于模板磁盘大小 %d MB", template.Disk))
+		}
+		disk = int64(spec.SizeMB)
+	}
+	if req.BandwidthId != "" {
+		spec, err := constant.GetBandwidthSpecByID(req.BandwidthId)
+		if err != nil {
+			return 0, 0, 0, 0, common.NewError(common.CodeValidationError, fmt.Sprintf("无效的带宽规格ID: %v", err))
+		}
+		bandwidth = spec.SpeedMbps
+	}
+	return cpu, memory, disk, bandwidth, nil
+}
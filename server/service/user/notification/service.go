@@ -27,13 +27,8 @@ func (s *Service) ResetPassword(userID uint) (string, error) {
 		return "", errors.New("用户不存在")
 	}
 
-	// 生成强密码（12位）
-	newPassword := utils.GenerateStrongPassword(12)
-
-	// 密码强度验证（确保生成的密码符合策略）
-	if err := utils.ValidatePasswordStrength(newPassword, utils.DefaultPasswordPolicy, user.Username); err != nil {
-		return "", err
-	}
+	// 按配置的生成密码策略生成新密码
+	newPassword := utils.GeneratePassword(utils.CurrentPasswordPolicy())
 
 	// 加密新密码
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
@@ -56,13 +51,8 @@ func (s *Service) ResetPasswordAndNotify(userID uint) (string, error) {
 		return "", errors.New("用户不存在")
 	}
 
-	// 生成强密码（12位）
-	newPassword := utils.GenerateStrongPassword(12)
-
-	// 密码强度验证（确保生成的密码符合策略）
-	if err := utils.ValidatePasswordStrength(newPassword, utils.DefaultPasswordPolicy, user.Username); err != nil {
-		return "", err
-	}
+	// 按配置的生成密码策略生成新密码
+	newPassword := utils.GeneratePassword(utils.CurrentPasswordPolicy())
 
 	// 加密新密码
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
@@ -91,29 +81,40 @@ func (s *Service) ResetPasswordAndNotify(userID uint) (string, error) {
 
 // sendPasswordToUser 发送新密码到用户绑定的通信渠道
 func (s *Service) sendPasswordToUser(user *userModel.User, newPassword string) error {
+	message := fmt.Sprintf("用户 %s 的新密码：%s\n请及时登录并修改密码。", user.Username, newPassword)
+	return s.sendToUser(user, "密码重置通知", message, "password_reset")
+}
+
+// NotifyUser 通过用户绑定的通信渠道发送通知
+func (s *Service) NotifyUser(user *userModel.User, subject, message string) error {
+	return s.sendToUser(user, subject, message, "notification")
+}
+
+// sendToUser 发送消息到用户绑定的通信渠道
+func (s *Service) sendToUser(user *userModel.User, subject, message, operation string) error {
 	// 优先级：邮箱 > Telegram > QQ > 手机号
 
 	if user.Email != "" {
-		return s.sendPasswordByEmail(user.Email, user.Username, newPassword)
+		return s.sendByEmail(user.Email, user.Username, subject, message, operation)
 	}
 
 	if user.Telegram != "" {
-		return s.sendPasswordByTelegram(user.Telegram, user.Username, newPassword)
+		return s.sendByTelegram(user.Telegram, user.Username, message, operation)
 	}
 
 	if user.QQ != "" {
-		return s.sendPasswordByQQ(user.QQ, user.Username, newPassword)
+		return s.sendByQQ(user.QQ, user.Username, message, operation)
 	}
 
 	if user.Phone != "" {
-		return s.sendPasswordBySMS(user.Phone, user.Username, newPassword)
+		return s.sendBySMS(user.Phone, user.Username, message, operation)
 	}
 
 	return errors.New("用户未绑定任何通信渠道")
 }
 
-// sendPasswordByEmail 通过邮箱发送新密码
-func (s *Service) sendPasswordByEmail(email, username, newPassword string) error {
+// sendByEmail 通过邮箱发送消息
+func (s *Service) sendByEmail(email, username, subject, body, operation string) error {
 	config := global.APP_CONFIG.Auth
 
 	// 检查邮箱是否启用
@@ -126,10 +127,10 @@ func (s *Service) sendPasswordByEmail(email, username, newPassword string) error
 		return errors.New("邮箱SMTP配置不完整")
 	}
 
-	global.APP_LOG.Info("发送新密码到邮箱",
+	global.APP_LOG.Info("发送消息到邮箱",
 		zap.String("email", email),
 		zap.String("username", username),
-		zap.String("operation", "password_reset"))
+		zap.String("operation", operation))
 
 	// 在开发环境下直接返回成功
 	if global.APP_CONFIG.System.Env == "development" {
@@ -137,10 +138,6 @@ func (s *Service) sendPasswordByEmail(email, username, newPassword string) error
 		return nil
 	}
 
-	// 构造邮件内容
-	subject := "密码重置通知"
-	body := fmt.Sprintf("用户 %s 的新密码：%s\n请及时登录并修改密码。", username, newPassword)
-
 	// 这里应该直接调用邮件发送功能
 	// 可以使用 gomail 或其他邮件库
 	// 示例实现：
@@ -160,8 +157,8 @@ func (s *Service) sendPasswordByEmail(email, username, newPassword string) error
 	return errors.New("邮件发送功能待实现，请安装并配置邮件发送库（如 gomail）")
 }
 
-// sendPasswordByTelegram 通过Telegram发送新密码
-func (s *Service) sendPasswordByTelegram(telegram, username, newPassword string) error {
+// sendByTelegram 通过Telegram发送消息
+func (s *Service) sendByTelegram(telegram, username, message, operation string) error {
 	config := global.APP_CONFIG.Auth
 
 	// 检查Telegram是否启用
@@ -174,10 +171,10 @@ func (s *Service) sendPasswordByTelegram(telegram, username, newPassword string)
 		return errors.New("Telegram Bot Token未配置")
 	}
 
-	global.APP_LOG.Info("发送新密码到Telegram",
+	global.APP_LOG.Info("发送消息到Telegram",
 		zap.String("telegram", telegram),
 		zap.String("username", username),
-		zap.String("operation", "password_reset"))
+		zap.String("operation", operation))
 
 	// 在开发环境下直接返回成功
 	if global.APP_CONFIG.System.Env == "development" {
@@ -185,9 +182,6 @@ func (s *Service) sendPasswordByTelegram(telegram, username, newPassword string)
 		return nil
 	}
 
-	// 构造消息内容
-	message := fmt.Sprintf("用户 %s 的新密码：%s\n请及时登录并修改密码。", username, newPassword)
-
 	// 这里应该调用Telegram Bot API发送消息
 	global.APP_LOG.Warn("Telegram Bot API集成待实现",
 		zap.String("message", message),
@@ -195,8 +189,8 @@ func (s *Service) sendPasswordByTelegram(telegram, username, newPassword string)
 	return errors.New("Telegram Bot API集成待实现")
 }
 
-// sendPasswordByQQ 通过QQ发送新密码
-func (s *Service) sendPasswordByQQ(qq, username, newPassword string) error {
+// sendByQQ 通过QQ发送消息
+func (s *Service) sendByQQ(qq, username, message, operation string) error {
 	config := global.APP_CONFIG.Auth
 
 	// 检查QQ是否启用
@@ -209,10 +203,10 @@ func (s *Service) sendPasswordByQQ(qq, username, newPassword string) error {
 		return errors.New("QQ应用配置不完整")
 	}
 
-	global.APP_LOG.Info("发送新密码到QQ",
+	global.APP_LOG.Info("发送消息到QQ",
 		zap.String("qq", qq),
 		zap.String("username", username),
-		zap.String("operation", "password_reset"))
+		zap.String("operation", operation))
 
 	// 在开发环境下直接返回成功
 	if global.APP_CONFIG.System.Env == "development" {
@@ -220,9 +214,6 @@ func (s *Service) sendPasswordByQQ(qq, username, newPassword string) error {
 		return nil
 	}
 
-	// 构造消息内容
-	message := fmt.Sprintf("用户 %s 的新密码：%s\n请及时登录并修改密码。", username, newPassword)
-
 	// 这里应该调用QQ机器人API发送消息
 	global.APP_LOG.Warn("QQ机器人API集成待实现",
 		zap.String("message", message),
@@ -230,12 +221,12 @@ func (s *Service) sendPasswordByQQ(qq, username, newPassword string) error {
 	return errors.New("QQ机器人API集成待实现")
 }
 
-// sendPasswordBySMS 通过短信发送新密码
-func (s *Service) sendPasswordBySMS(phone, username, newPassword string) error {
-	global.APP_LOG.Info("发送新密码到手机",
+// sendBySMS 通过短信发送消息
+func (s *Service) sendBySMS(phone, username, message, operation string) error {
+	global.APP_LOG.Info("发送消息到手机",
 		zap.String("phone", phone),
 		zap.String("username", username),
-		zap.String("operation", "password_reset"))
+		zap.String("operation", operation))
 
 	// 在开发环境下直接返回成功
 	if global.APP_CONFIG.System.Env == "development" {
@@ -243,9 +234,6 @@ func (s *Service) sendPasswordBySMS(phone, username, newPassword string) error {
 		return nil
 	}
 
-	// 构造短信内容
-	message := fmt.Sprintf("用户 %s 的新密码：%s，请及时登录并修改密码。", username, newPassword)
-
 	// 这里应该调用短信服务商API
 	global.APP_LOG.Warn("短信服务API集成待实现",
 		zap.String("message", message),
@@ -297,9 +297,9 @@ func (s *Service) GetUserTasks(userID uint, req userModel.UserTasksRequest) ([]u
 	providerTasksMap := make(map[uint][]adminModel.Task)
 	if len(providerIDs) > 0 {
 		var allProviderTasks []adminModel.Task
-		if err := global.APP_DB.Select("id", "provider_id", "status", "created_at", "estimated_duration", "started_at").
+		if err := global.APP_DB.Select("id", "provider_id", "status", "priority", "created_at", "estimated_duration", "started_at").
 			Where("provider_id IN ? AND status IN (?, ?)", providerIDs, "pending", "running").
-			Order("provider_id ASC, created_at ASC").
+			Order("provider_id ASC, priority DESC, created_at ASC").
 			Find(&allProviderTasks).Error; err == nil {
 			// 按 provider_id 分组
 			for _, pt := range allProviderTasks {
@@ -345,6 +345,7 @@ func (s *Service) GetUserTasks(userID uint, req userModel.UserTasksRequest) ([]u
 			TaskType:              task.TaskType,
 			Status:                task.Status,
 			Progress:              task.Progress,
+			Priority:              task.Priority,
 			ErrorMessage:          task.ErrorMessage,
 			CancelReason:          task.CancelReason,
 			StatusMessage:         task.StatusMessage,
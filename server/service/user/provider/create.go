@@ -2,6 +2,7 @@ package provider
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"oneclickvirt/constant"
@@ -10,9 +11,12 @@ import (
 	providerModel "oneclickvirt/model/provider"
 	systemModel "oneclickvirt/model/system"
 	userModel "oneclickvirt/model/user"
+	providerPkg "oneclickvirt/provider"
 	"oneclickvirt/service/cache"
 	"oneclickvirt/service/database"
+	providerService "oneclickvirt/service/provider"
 	"oneclickvirt/service/resources"
+	"oneclickvirt/utils"
 	"time"
 
 	"go.uber.org/zap"
@@ -35,6 +39,19 @@ func (s *Service) CreateUserInstance(userID uint, req userModel.CreateInstanceRe
 		zap.String("bandwidthId", req.BandwidthId),
 		zap.String("description", req.Description))
 
+	// 未指定节点时按配置的策略自动选择，之后与手动选择节点走相同的校验流程
+	if req.ProviderId == 0 {
+		var image systemModel.SystemImage
+		if err := global.APP_DB.Where("id = ? AND status = ?", req.ImageId, "active").First(&image).Error; err != nil {
+			return nil, errors.New("无效的镜像ID")
+		}
+		chosen, err := s.selectPlacementProvider(&req, &image)
+		if err != nil {
+			return nil, err
+		}
+		req.ProviderId = chosen.ID
+	}
+
 	// 快速验证基本参数
 	var provider providerModel.Provider
 	if err := global.APP_DB.First(&provider, req.ProviderId).Error; err != nil {
@@ -42,21 +59,41 @@ func (s *Service) CreateUserInstance(userID uint, req userModel.CreateInstanceRe
 		return nil, errors.New("节点不存在")
 	}
 
-	if !provider.AllowClaim || provider.IsFrozen {
-		global.APP_LOG.Error("服务器不可用",
-			zap.Uint("providerId", req.ProviderId),
-			zap.Bool("allowClaim", provider.AllowClaim),
-			zap.Bool("isFrozen", provider.IsFrozen))
-		return nil, errors.New("服务器不可用")
+	if err := ValidateProviderAcceptsNewInstance(&provider); err != nil {
+		return nil, err
 	}
 
-	// 检查Provider是否因流量超限被限制
-	if provider.TrafficLimited {
-		global.APP_LOG.Error("Provider因流量超限被限制，禁止申请新实例",
-			zap.Uint("providerId", req.ProviderId),
-			zap.String("providerName", provider.Name),
-			zap.Bool("trafficLimited", provider.TrafficLimited))
-		return nil, errors.New("该服务器因流量超限暂时不可用，请选择其他服务器或联系管理员")
+	if err := utils.ValidateInstanceTags(req.Tags); err != nil {
+		return nil, err
+	}
+
+	if err := validateRequestedIPs(global.APP_DB, &provider, req.RequestedIPv4, req.RequestedIPv6); err != nil {
+		return nil, err
+	}
+
+	if err := providerPkg.ValidateNetworkInterfaces(req.NetworkInterfaces); err != nil {
+		return nil, err
+	}
+	if err := providerPkg.CheckNetworksAllowed(req.NetworkInterfaces, provider.ExtraNetworks); err != nil {
+		return nil, err
+	}
+	if req.Network != "" {
+		if provider.Type != "docker" && provider.Type != "podman" {
+			return nil, errors.New("该节点类型不支持指定实例网络")
+		}
+		if err := providerPkg.CheckPrimaryNetworkAllowed(req.Network, provider.ExtraNetworks); err != nil {
+			return nil, err
+		}
+	}
+
+	sshPublicKeys, err := utils.NormalizeSSHPublicKeys(req.SSHPublicKeys)
+	if err != nil {
+		return nil, err
+	}
+	req.SSHPublicKeys = sshPublicKeys
+
+	if err := utils.ValidateInstanceTTLHours(req.TTLHours); err != nil {
+		return nil, err
 	}
 
 	var systemImage systemModel.SystemImage
@@ -85,7 +122,75 @@ func (s *Service) CreateUserInstance(userID uint, req userModel.CreateInstanceRe
 		return nil, err
 	}
 
+	gpuDevices, err := validateGPUDevices(global.APP_DB, &provider, systemImage.InstanceType, req.GPUDevices)
+	if err != nil {
+		return nil, err
+	}
+	req.GPUDevices = gpuDevices
+
+	dnsServers, err := providerPkg.ValidateDNSServers(req.DNSServers)
+	if err != nil {
+		return nil, err
+	}
+	req.DNSServers = dnsServers
+
+	hostname, err := providerPkg.ValidateHostname(req.Hostname)
+	if err != nil {
+		return nil, err
+	}
+	req.Hostname = hostname
+
+	if err := providerPkg.ValidateMemorySwap(req.MemorySwapMB); err != nil {
+		return nil, err
+	}
+	if req.MemorySwapMB != nil && systemImage.InstanceType == "vm" {
+		return nil, errors.New("虚拟机不支持设置swap大小，请在实例系统内配置swap")
+	}
+
+	volumes, err := providerPkg.ValidateVolumeMounts(req.Volumes)
+	if err != nil {
+		return nil, err
+	}
+	if err := providerPkg.CheckVolumesAllowed(volumes, provider.VolumeAllowlist); err != nil {
+		return nil, err
+	}
+	if len(volumes) > 0 && provider.Type == "proxmox" && systemImage.InstanceType == "vm" {
+		return nil, errors.New("Proxmox虚拟机不支持挂载宿主机目录")
+	}
+	req.Volumes = volumes
+
+	// 按节点探测到的能力提前拒绝不支持的组合（如无KVM时创建虚拟机），Provider未连接时跳过
+	// 能力信息按Provider缓存，缓存有效期内申领不会重复SSH探测
+	needIPv6 := providerPkg.NetworkTypeRequiresIPv6(provider.NetworkType) || req.RequestedIPv6 != ""
+	if caps := providerService.GetProviderService().GetProviderCapabilities(context.Background(), provider.ID); caps != nil {
+		if err := providerPkg.CheckInstanceCapabilities(caps, systemImage.InstanceType, systemImage.Architecture, needIPv6); err != nil {
+			global.APP_LOG.Warn("实例配置与节点能力不匹配",
+				zap.Uint("providerId", req.ProviderId),
+				zap.String("instanceType", systemImage.InstanceType),
+				zap.String("imageArch", systemImage.Architecture),
+				zap.String("networkType", provider.NetworkType),
+				zap.Bool("needIPv6", needIPv6),
+				zap.Error(err))
+			if errors.Is(err, providerPkg.ErrIPv6Unavailable) {
+				return nil, errors.New(providerPkg.IPv6UnavailableMessage(provider.NetworkType, s.findIPv6CompatibleProviders(provider.ID, systemImage.InstanceType)))
+			}
+			return nil, err
+		}
+	}
+
 	// 验证规格ID并获取规格信息，同时验证用户权限
+	// 未选择的CPU、内存、磁盘规格使用节点默认限制对应的规格，节点未设置默认值时仍需用户选择
+	defaultCPUID, defaultMemoryID, defaultDiskID := defaultSpecIDs(provider)
+	if req.CPUId == "" {
+		req.CPUId = defaultCPUID
+	}
+	if req.MemoryId == "" {
+		req.MemoryId = defaultMemoryID
+	}
+	if req.DiskId == "" {
+		req.DiskId = defaultDiskID
+	}
+
 	global.APP_LOG.Info("开始验证规格ID",
 		zap.String("cpuId", req.CPUId),
 		zap.String("memoryId", req.MemoryId),
@@ -158,7 +263,15 @@ func (s *Service) CreateUserInstance(userID uint, req userModel.CreateInstanceRe
 	sessionID := resources.GenerateSessionID()
 
 	// 使用原子化创建流程（最小化事务范围）
-	return s.createInstanceWithMinimalTransaction(userID, &req, sessionID, &systemImage, cpuSpec, memorySpec, diskSpec, bandwidthSpec)
+	task, err := s.createInstanceWithMinimalTransaction(userID, &req, sessionID, &systemImage, cpuSpec, memorySpec, diskSpec, bandwidthSpec)
+	if err != nil {
+		return nil, err
+	}
+	// 返回实际使用的节点，自动选择节点时调用方据此告知用户
+	if task.Provider == nil {
+		task.Provider = &provider
+	}
+	return task, nil
 }
 
 // createInstanceWithMinimalTransaction 原子化实例创建流程
@@ -282,8 +395,35 @@ func (s *Service) createInstanceWithMinimalTransaction(userID uint, req *userMod
 		}
 
 		// 2. 创建任务
-		taskData := fmt.Sprintf(`{"providerId":%d,"imageId":%d,"cpuId":"%s","memoryId":"%s","diskId":"%s","bandwidthId":"%s","description":"%s","sessionId":"%s"}`,
-			req.ProviderId, req.ImageId, req.CPUId, req.MemoryId, req.DiskId, req.BandwidthId, req.Description, sessionID)
+		taskDataJSON, err := json.Marshal(adminModel.CreateInstanceTaskRequest{
+			ProviderId:    req.ProviderId,
+			ImageId:       req.ImageId,
+			CPUId:         req.CPUId,
+			MemoryId:      req.MemoryId,
+			DiskId:        req.DiskId,
+			BandwidthId:   req.BandwidthId,
+			Description:   req.Description,
+			SessionId:     sessionID,
+			Tags:          req.Tags,
+			RequestedIPv4: req.RequestedIPv4,
+			RequestedIPv6: req.RequestedIPv6,
+			SSHPublicKeys: req.SSHPublicKeys,
+			TTLHours:      req.TTLHours,
+
+			Network:           req.Network,
+			NetworkInterfaces: req.NetworkInterfaces,
+
+			GPUDevices: req.GPUDevices,
+			DNSServers: req.DNSServers,
+			Hostname:   req.Hostname,
+
+			MemorySwapMB: req.MemorySwapMB,
+			Volumes:      req.Volumes,
+		})
+		if err != nil {
+			return fmt.Errorf("序列化任务数据失败: %v", err)
+		}
+		taskData := string(taskDataJSON)
 
 		// 计算预计执行时长
 		estimatedDuration := 300 // 默认5分钟
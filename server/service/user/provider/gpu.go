@@ -0,0 +1,45 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+
+	providerModel "oneclickvirt/model/provider"
+	providerPkg "oneclickvirt/provider"
+
+	"gorm.io/gorm"
+)
+
+// validateGPUDevices 校验节点是否开放GPU直通，并检查GPU是否已被该节点上的其他实例占用
+// 设备是否存在、IOMMU是否开启由Provider在创建时根据宿主机实际情况校验
+func validateGPUDevices(db *gorm.DB, provider *providerModel.Provider, instanceType string, devices []string) ([]string, error) {
+	if len(devices) == 0 {
+		return nil, nil
+	}
+	if provider.Type != "proxmox" && provider.Type != "incus" {
+		return nil, fmt.Errorf("%s 节点不支持GPU直通", provider.Type)
+	}
+	if !provider.GPUPassthroughEnabled {
+		return nil, errors.New("该节点未开放GPU直通")
+	}
+	if instanceType != "vm" {
+		return nil, errors.New("GPU直通仅支持虚拟机")
+	}
+
+	normalized, err := providerPkg.ValidateGPUDevices(devices)
+	if err != nil {
+		return nil, err
+	}
+	for _, addr := range normalized {
+		var count int64
+		if err := db.Model(&providerModel.Instance{}).
+			Where("provider_id = ? AND status <> ? AND gpu_devices LIKE ?", provider.ID, "failed", "%\""+addr+"\"%").
+			Count(&count).Error; err != nil {
+			return nil, fmt.Errorf("检查GPU占用失败: %v", err)
+		}
+		if count > 0 {
+			return nil, fmt.Errorf("GPU %s 已被其他实例占用", addr)
+		}
+	}
+	return normalized, nil
+}
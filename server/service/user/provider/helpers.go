@@ -7,9 +7,11 @@ import (
 	"fmt"
 	"time"
 
+	"oneclickvirt/constant"
 	"oneclickvirt/global"
 	adminModel "oneclickvirt/model/admin"
 	providerModel "oneclickvirt/model/provider"
+	providerPkg "oneclickvirt/provider"
 	"oneclickvirt/service/interfaces"
 	providerService "oneclickvirt/service/provider"
 	"oneclickvirt/utils"
@@ -33,9 +35,13 @@ func (s *Service) markTaskFailed(taskID uint, errorMessage string) {
 	utils.MarkTaskFailed(taskID, errorMessage)
 }
 
-// generateInstanceName 生成实例名称（使用全局工具函数）
-func (s *Service) generateInstanceName(providerName string) string {
-	return utils.GenerateInstanceName(providerName)
+// generateInstanceName 生成实例名称（使用全局工具函数），并在写入数据库前校验名称可安全拼接到Provider命令中
+func (s *Service) generateInstanceName(providerName string) (string, error) {
+	name := utils.GenerateInstanceName(providerName)
+	if err := providerPkg.ValidateInstanceName(name); err != nil {
+		return "", err
+	}
+	return name, nil
 }
 
 // generatePassword 生成随机密码（使用全局工具函数）
@@ -132,8 +138,11 @@ func (s *Service) executeAdminDeleteInstance(instanceID uint, taskService interf
 		return fmt.Errorf("创建删除任务失败: %v", err)
 	}
 
-	// 标记任务为管理员操作，不允许用户取消
-	if err := global.APP_DB.Model(task).Update("is_force_stoppable", false).Error; err != nil {
+	// 标记任务为管理员操作，不允许用户取消，并优先于用户的常规任务执行
+	if err := global.APP_DB.Model(task).Updates(map[string]interface{}{
+		"is_force_stoppable": false,
+		"priority":           adminModel.TaskPriorityAdmin,
+	}).Error; err != nil {
 		global.APP_LOG.Warn("更新任务可取消状态失败", zap.Uint("taskId", task.ID), zap.Error(err))
 	}
 
@@ -149,3 +158,23 @@ func (s *Service) executeAdminDeleteInstance(instanceID uint, taskService interf
 
 	return nil
 }
+
+// defaultSpecIDs 将节点的默认资源限制换算为不超过该限制的最大预定义规格ID，未设置的项返回空字符串
+func defaultSpecIDs(provider providerModel.Provider) (cpuID, memoryID, diskID string) {
+	if provider.DefaultCPU > 0 {
+		if spec := constant.CPUSpecForCores(provider.DefaultCPU); spec != nil {
+			cpuID = spec.ID
+		}
+	}
+	if provider.DefaultMemory > 0 {
+		if spec := constant.MemorySpecForSize(provider.DefaultMemory); spec != nil {
+			memoryID = spec.ID
+		}
+	}
+	if provider.DefaultDisk > 0 {
+		if spec := constant.DiskSpecForSize(provider.DefaultDisk); spec != nil {
+			diskID = spec.ID
+		}
+	}
+	return cpuID, memoryID, diskID
+}
@@ -0,0 +1,263 @@
+package provider
+
+import (
+	"errors"
+	"math/rand"
+	"time"
+
+	"oneclickvirt/constant"
+	"oneclickvirt/global"
+	providerModel "oneclickvirt/model/provider"
+	resourceModel "oneclickvirt/model/resource"
+	systemModel "oneclickvirt/model/system"
+	userModel "oneclickvirt/model/user"
+	providerPkg "oneclickvirt/provider"
+	providerService "oneclickvirt/service/provider"
+
+	"go.uber.org/zap"
+)
+
+// defaultPlacementStrategy 未配置策略时使用的节点选择策略
+const defaultPlacementStrategy = "most-free"
+
+// placementCandidate 满足条件的候选节点及其评分依据
+type placementCandidate struct {
+	provider  providerModel.Provider
+	freeRatio float64 // CPU（仅虚拟机）、内存、磁盘剩余比例中的最小值
+	instances int     // 节点上的实例数量（含预留中的实例）
+	weight    float64 // 管理员配置的权重，健康状态未完全在线时减半
+}
+
+// placementStrategy 从候选节点中选出一个，候选列表保证非空
+type placementStrategy interface {
+	pick(candidates []placementCandidate) placementCandidate
+}
+
+// placementStrategies 可通过 quota.placement-strategy 配置的选择策略
+var placementStrategies = map[string]placementStrategy{
+	"most-free":       mostFreeStrategy{},
+	"least-loaded":    leastLoadedStrategy{},
+	"weighted-random": weightedRandomStrategy{},
+}
+
+// mostFreeStrategy 选择按权重加权后剩余资源比例最高的节点
+type mostFreeStrategy struct{}
+
+func (mostFreeStrategy) pick(candidates []placementCandidate) placementCandidate {
+	best := candidates[0]
+	for _, c := range candidates[1:] {
+		if c.freeRatio*c.weight > best.freeRatio*best.weight {
+			best = c
+		}
+	}
+	return best
+}
+
+// leastLoadedStrategy 选择每单位权重承载实例数最少的节点
+type leastLoadedStrategy struct{}
+
+func (leastLoadedStrategy) pick(candidates []placementCandidate) placementCandidate {
+	best := candidates[0]
+	for _, c := range candidates[1:] {
+		if float64(c.instances)/c.weight < float64(best.instances)/best.weight {
+			best = c
+		}
+	}
+	return best
+}
+
+// weightedRandomStrategy 按权重与剩余资源比例的乘积随机选择，使负载分散到多个节点
+type weightedRandomStrategy struct{}
+
+func (weightedRandomStrategy) pick(candidates []placementCandidate) placementCandidate {
+	total := 0.0
+	for _, c := range candidates {
+		total += c.weight * c.freeRatio
+	}
+	if total <= 0 {
+		return candidates[rand.Intn(len(candidates))]
+	}
+	r := rand.Float64() * total
+	for _, c := range candidates {
+		r -= c.weight * c.freeRatio
+		if r < 0 {
+			return c
+		}
+	}
+	return candidates[len(candidates)-1]
+}
+
+// resolvePlacementStrategy 按配置名称返回选择策略，未配置或名称未知时使用默认策略
+func resolvePlacementStrategy(name string) (string, placementStrategy) {
+	if strategy, ok := placementStrategies[name]; ok {
+		return name, strategy
+	}
+	return defaultPlacementStrategy, placementStrategies[defaultPlacementStrategy]
+}
+
+// selectPlacementProvider 为未指定节点的申领请求自动选择节点
+// 只考虑允许申领、未冻结、不在维护、未被流量限制且健康检查未离线的节点，
+// 并要求节点支持所选镜像、剩余资源和实例槽位足够，按配置的策略在候选节点中选择
+func (s *Service) selectPlacementProvider(req *userModel.CreateInstanceRequest, image *systemModel.SystemImage) (*providerModel.Provider, error) {
+	if req.RequestedIPv4 != "" || req.RequestedIPv6 != "" || req.Network != "" ||
+		len(req.NetworkInterfaces) > 0 || len(req.GPUDevices) > 0 || len(req.Volumes) > 0 {
+		return nil, errors.New("指定内网IP、网络、GPU或挂载目录时需要选择节点")
+	}
+
+	var providers []providerModel.Provider
+	if err := global.APP_DB.Where("(status = ? OR status = ?) AND allow_claim = ? AND is_frozen = ? AND maintenance_mode = ? AND traffic_limited = ?",
+		"active", "partial", true, false, false, false).
+		Limit(1000).
+		Find(&providers).Error; err != nil {
+		return nil, errors.New("查询可用节点失败")
+	}
+
+	reserved := activeReservationsByProvider(providers)
+	var candidates []placementCandidate
+	for _, p := range providers {
+		if c, ok := s.placementCandidateFor(p, req, image, reserved[p.ID]); ok {
+			candidates = append(candidates, c)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, errors.New("当前没有满足条件的可用节点，请稍后重试或手动选择节点")
+	}
+
+	strategyName, strategy := resolvePlacementStrategy(global.APP_CONFIG.Quota.PlacementStrategy)
+	chosen := strategy.pick(candidates)
+
+	global.APP_LOG.Info("自动选择节点",
+		zap.String("strategy", strategyName),
+		zap.Int("candidates", len(candidates)),
+		zap.Uint("providerId", chosen.provider.ID),
+		zap.String("providerName", chosen.provider.Name),
+		zap.Float64("freeRatio", chosen.freeRatio),
+		zap.Int("instances", chosen.instances))
+	return &chosen.provider, nil
+}
+
+// placementCandidateFor 判断节点能否承载该申领请求并计算评分依据
+func (s *Service) placementCandidateFor(p providerModel.Provider, req *userModel.CreateInstanceRequest, image *systemModel.SystemImage, reservations []resourceModel.ResourceReservation) (placementCandidate, bool) {
+	if p.ExpiresAt != nil && p.ExpiresAt.Before(time.Now()) {
+		return placementCandidate{}, false
+	}
+	health := p.Health()
+	if health == providerModel.ProviderHealthOffline {
+		return placementCandidate{}, false
+	}
+	if p.NodeCPUCores == 0 || p.NodeMemoryTotal == 0 || p.NodeDiskTotal == 0 {
+		return placementCandidate{}, false
+	}
+	if err := s.validateProviderImageCompatibility(&p, image); err != nil {
+		return placementCandidate{}, false
+	}
+	if caps := providerService.GetProviderService().GetCachedProviderCapabilities(p.ID); caps != nil {
+		if err := providerPkg.CheckInstanceCapabilities(caps, image.InstanceType, image.Architecture, providerPkg.NetworkTypeRequiresIPv6(p.NetworkType)); err != nil {
+			return placementCandidate{}, false
+		}
+	}
+
+	// 未选择的规格按该节点的默认限制换算，节点没有默认值时无法确定资源需求
+	cpuID, memoryID, diskID := defaultSpecIDs(p)
+	if req.CPUId != "" {
+		cpuID = req.CPUId
+	}
+	if req.MemoryId != "" {
+		memoryID = req.MemoryId
+	}
+	if req.DiskId != "" {
+		diskID = req.DiskId
+	}
+	cpuSpec, err := constant.GetCPUSpecByID(cpuID)
+	if err != nil {
+		return placementCandidate{}, false
+	}
+	memorySpec, err := constant.GetMemorySpecByID(memoryID)
+	if err != nil {
+		return placementCandidate{}, false
+	}
+	diskSpec, err := constant.GetDiskSpecByID(diskID)
+	if err != nil {
+		return placementCandidate{}, false
+	}
+
+	// 与可用节点列表一致：CPU只对虚拟机预留，内存和磁盘对所有实例预留
+	usedCPU, usedMemory, usedDisk := p.UsedCPUCores, p.UsedMemory, p.UsedDisk
+	containers, vms := p.ContainerCount, p.VMCount
+	for _, r := range reservations {
+		if r.InstanceType == "vm" {
+			usedCPU += r.CPU
+			vms++
+		} else {
+			containers++
+		}
+		usedMemory += r.Memory
+		usedDisk += r.Disk
+	}
+
+	if image.InstanceType == "vm" && p.MaxVMInstances > 0 && vms >= p.MaxVMInstances {
+		return placementCandidate{}, false
+	}
+	if image.InstanceType != "vm" && p.MaxContainerInstances > 0 && containers >= p.MaxContainerInstances {
+		return placementCandidate{}, false
+	}
+
+	allocatableCPU, allocatableMemory := p.AllocatableCPUCores(), p.AllocatableMemory()
+	freeMemory := allocatableMemory - usedMemory - int64(memorySpec.SizeMB)
+	freeDisk := p.NodeDiskTotal - usedDisk - int64(diskSpec.SizeMB)
+	if freeMemory < 0 || freeDisk < 0 {
+		return placementCandidate{}, false
+	}
+	freeRatio := minFloat(float64(freeMemory)/float64(allocatableMemory), float64(freeDisk)/float64(p.NodeDiskTotal))
+	if image.InstanceType == "vm" {
+		freeCPU := allocatableCPU - usedCPU - cpuSpec.Cores
+		if freeCPU < 0 {
+			return placementCandidate{}, false
+		}
+		freeRatio = minFloat(freeRatio, float64(freeCPU)/float64(allocatableCPU))
+	}
+
+	weight := float64(p.PlacementWeight)
+	if weight <= 0 {
+		weight = 1
+	}
+	if health != providerModel.ProviderHealthOnline {
+		weight /= 2
+	}
+
+	return placementCandidate{
+		provider:  p,
+		freeRatio: freeRatio,
+		instances: containers + vms,
+		weight:    weight,
+	}, true
+}
+
+// activeReservationsByProvider 批量查询节点上尚未过期的资源预留
+func activeReservationsByProvider(providers []providerModel.Provider) map[uint][]resourceModel.ResourceReservation {
+	result := make(map[uint][]resourceModel.ResourceReservation)
+	if len(providers) == 0 {
+		return result
+	}
+	ids := make([]uint, 0, len(providers))
+	for _, p := range providers {
+		ids = append(ids, p.ID)
+	}
+	var reservations []resourceModel.ResourceReservation
+	if err := global.APP_DB.Where("provider_id IN ? AND expires_at > ?", ids, time.Now()).
+		Find(&reservations).Error; err != nil {
+		global.APP_LOG.Warn("查询节点资源预留失败", zap.Error(err))
+		return result
+	}
+	for _, r := range reservations {
+		result[r.ProviderID] = append(result[r.ProviderID], r)
+	}
+	return result
+}
+
+func minFloat(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,333 @@
+package provider
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	providerModel "oneclickvirt/model/provider"
+	resourceModel "oneclickvirt/model/resource"
+	systemModel "oneclickvirt/model/system"
+	userModel "oneclickvirt/model/user"
+)
+
+// testPlacementProvider 4核、4GB内存、10GB磁盘的在线LXD节点
+func testPlacementProvider() providerModel.Provider {
+	p := providerModel.Provider{
+		Name:             "node",
+		Type:             "lxd",
+		ContainerEnabled: true,
+		NodeCPUCores:     4,
+		NodeMemoryTotal:  4096,
+		NodeDiskTotal:    10240,
+		APIStatus:        "online",
+		PlacementWeight:  1,
+	}
+	p.ID = 1
+	return p
+}
+
+// TestPlacementCandidateFor 测试候选节点的过滤条件和剩余资源比例计算
+func TestPlacementCandidateFor(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	containerImage := &systemModel.SystemImage{ProviderType: "lxd,incus", InstanceType: "container"}
+	vmImage := &systemModel.SystemImage{ProviderType: "lxd", InstanceType: "vm"}
+	request := &userModel.CreateInstanceRequest{CPUId: "cpu-1", MemoryId: "mem-1024mb", DiskId: "disk-1024mb"}
+
+	tests := []struct {
+		name         string
+		modify       func(p *providerModel.Provider)
+		image        *systemModel.SystemImage
+		req          *userModel.CreateInstanceRequest
+		reservations []resourceModel.ResourceReservation
+		wantOK       bool
+		freeRatio    float64
+		instances    int
+		weight       float64
+	}{
+		{
+			name:      "内存剩余比例最低",
+			wantOK:    true,
+			freeRatio: 0.75, // 内存 (4096-1024)/4096，磁盘 (10240-1024)/10240
+			weight:    1,
+		},
+		{
+			name:      "计入已用资源和实例数",
+			modify:    func(p *providerModel.Provider) { p.UsedDisk = 8192; p.ContainerCount = 3; p.VMCount = 1 },
+			wantOK:    true,
+			freeRatio: 0.1, // 磁盘 (10240-8192-1024)/10240
+			instances: 4,
+			weight:    1,
+		},
+		{
+			name: "计入未过期的资源预留",
+			reservations: []resourceModel.ResourceReservation{
+				{InstanceType: "container", CPU: 2, Memory: 1024, Disk: 1024},
+			},
+			wantOK:    true,
+			freeRatio: 0.5,
+			instances: 1,
+			weight:    1,
+		},
+		{
+			name:      "按内存超分配比例计算",
+			modify:    func(p *providerModel.Provider) { p.MemoryOvercommitRatio = 2 },
+			wantOK:    true,
+			freeRatio: 0.875,
+			weight:    1,
+		},
+		{
+			name:      "部分在线权重减半",
+			modify:    func(p *providerModel.Provider) { p.SSHStatus = "offline"; p.PlacementWeight = 3 },
+			wantOK:    true,
+			freeRatio: 0.75,
+			weight:    1.5,
+		},
+		{
+			name:      "未配置权重按1计算",
+			modify:    func(p *providerModel.Provider) { p.PlacementWeight = 0 },
+			wantOK:    true,
+			freeRatio: 0.75,
+			weight:    1,
+		},
+		{
+			name:      "未选规格时使用节点默认值",
+			modify:    func(p *providerModel.Provider) { p.DefaultCPU = 1; p.DefaultMemory = 2048; p.DefaultDisk = 1024 },
+			req:       &userModel.CreateInstanceRequest{},
+			wantOK:    true,
+			freeRatio: 0.5,
+			weight:    1,
+		},
+		{
+			name:      "虚拟机计入CPU剩余比例",
+			modify:    func(p *providerModel.Provider) { p.VirtualMachineEnabled = true; p.UsedCPUCores = 2 },
+			image:     vmImage,
+			wantOK:    true,
+			freeRatio: 0.25, // CPU (4-2-1)/4
+			weight:    1,
+		},
+		{
+			name:   "虚拟机预留计入CPU",
+			modify: func(p *providerModel.Provider) { p.VirtualMachineEnabled = true; p.UsedCPUCores = 2 },
+			image:  vmImage,
+			reservations: []resourceModel.ResourceReservation{
+				{InstanceType: "vm", CPU: 2},
+			},
+			wantOK: false,
+		},
+		{
+			name:   "节点已过期",
+			modify: func(p *providerModel.Provider) { p.ExpiresAt = &past },
+		},
+		{
+			name:   "健康检查离线",
+			modify: func(p *providerModel.Provider) { p.APIStatus = "offline" },
+		},
+		{
+			name:   "节点资源未采集",
+			modify: func(p *providerModel.Provider) { p.NodeDiskTotal = 0 },
+		},
+		{
+			name:  "镜像不支持节点类型",
+			image: &systemModel.SystemImage{ProviderType: "docker", InstanceType: "container"},
+		},
+		{
+			name:  "节点不支持虚拟机",
+			image: vmImage,
+		},
+		{
+			name:   "容器数量已满",
+			modify: func(p *providerModel.Provider) { p.MaxContainerInstances = 2; p.ContainerCount = 2 },
+		},
+		{
+			name:         "预留后容器数量已满",
+			modify:       func(p *providerModel.Provider) { p.MaxContainerInstances = 1 },
+			reservations: []resourceModel.ResourceReservation{{InstanceType: "container"}},
+		},
+		{
+			name:   "内存不足",
+			modify: func(p *providerModel.Provider) { p.UsedMemory = 3500 },
+		},
+		{
+			name:   "磁盘不足",
+			modify: func(p *providerModel.Provider) { p.UsedDisk = 9500 },
+		},
+		{
+			name: "节点没有默认规格",
+			req:  &userModel.CreateInstanceRequest{},
+		},
+	}
+
+	s := &Service{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := testPlacementProvider()
+			if tt.modify != nil {
+				tt.modify(&p)
+			}
+			image, req := tt.image, tt.req
+			if image == nil {
+				image = containerImage
+			}
+			if req == nil {
+				req = request
+			}
+
+			c, ok := s.placementCandidateFor(p, req, image, tt.reservations)
+			if ok != tt.wantOK {
+				t.Fatalf("placementCandidateFor() ok = %v, 期望 %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if math.Abs(c.freeRatio-tt.freeRatio) > 1e-9 {
+				t.Errorf("freeRatio = %v, 期望 %v", c.freeRatio, tt.freeRatio)
+			}
+			if c.instances != tt.instances {
+				t.Errorf("instances = %d, 期望 %d", c.instances, tt.instances)
+			}
+			if c.weight != tt.weight {
+				t.Errorf("weight = %v, 期望 %v", c.weight, tt.weight)
+			}
+		})
+	}
+}
+
+func testCandidate(id uint, freeRatio float64, instances int, weight float64) placementCandidate {
+	c := placementCandidate{freeRatio: freeRatio, instances: instances, weight: weight}
+	c.provider.ID = id
+	return c
+}
+
+// TestPlacementStrategies 测试各选择策略在候选节点中的选择结果
+func TestPlacementStrategies(t *testing.T) {
+	tests := []struct {
+		name       string
+		strategy   string
+		candidates []placementCandidate
+		want       uint
+	}{
+		{
+			name:     "most-free选择剩余比例最高",
+			strategy: "most-free",
+			candidates: []placementCandidate{
+				testCandidate(1, 0.3, 0, 1),
+				testCandidate(2, 0.6, 10, 1),
+				testCandidate(3, 0.5, 0, 1),
+			},
+			want: 2,
+		},
+		{
+			name:     "most-free按权重加权",
+			strategy: "most-free",
+			candidates: []placementCandidate{
+				testCandidate(1, 0.6, 0, 1),
+				testCandidate(2, 0.4, 0, 2),
+			},
+			want: 2,
+		},
+		{
+			name:     "most-free相同时保留第一个",
+			strategy: "most-free",
+			candidates: []placementCandidate{
+				testCandidate(1, 0.5, 0, 1),
+				testCandidate(2, 0.5, 0, 1),
+			},
+			want: 1,
+		},
+		{
+			name:     "least-loaded选择实例最少",
+			strategy: "least-loaded",
+			candidates: []placementCandidate{
+				testCandidate(1, 0.9, 5, 1),
+				testCandidate(2, 0.1, 2, 1),
+				testCandidate(3, 0.5, 3, 1),
+			},
+			want: 2,
+		},
+		{
+			name:     "least-loaded按权重折算",
+			strategy: "least-loaded",
+			candidates: []placementCandidate{
+				testCandidate(1, 0.5, 3, 1),
+				testCandidate(2, 0.5, 4, 2),
+			},
+			want: 2,
+		},
+		{
+			name:     "weighted-random不选择评分为0的节点",
+			strategy: "weighted-random",
+			candidates: []placementCandidate{
+				testCandidate(1, 0, 0, 1),
+				testCandidate(2, 0.5, 0, 1),
+				testCandidate(3, 0, 0, 1),
+			},
+			want: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, strategy := resolvePlacementStrategy(tt.strategy)
+			for i := 0; i < 50; i++ {
+				if got := strategy.pick(tt.candidates); got.provider.ID != tt.want {
+					t.Fatalf("pick() = 节点%d, 期望节点%d", got.provider.ID, tt.want)
+				}
+			}
+		})
+	}
+
+	t.Run("weighted-random按评分比例分布", func(t *testing.T) {
+		candidates := []placementCandidate{
+			testCandidate(1, 0.25, 0, 1),
+			testCandidate(2, 0.75, 0, 1),
+		}
+		counts := map[uint]int{}
+		for i := 0; i < 4000; i++ {
+			counts[weightedRandomStrategy{}.pick(candidates).provider.ID]++
+		}
+		// 期望约1000:3000，允许较大误差避免偶发失败
+		if counts[1] < 700 || counts[1] > 1300 {
+			t.Errorf("节点1被选中 %d 次, 期望约1000次", counts[1])
+		}
+	})
+
+	t.Run("weighted-random评分全为0时仍能选择", func(t *testing.T) {
+		candidates := []placementCandidate{testCandidate(1, 0, 0, 1), testCandidate(2, 0, 0, 1)}
+		if got := (weightedRandomStrategy{}).pick(candidates); got.provider.ID != 1 && got.provider.ID != 2 {
+			t.Errorf("pick() = 节点%d, 期望从候选节点中选择", got.provider.ID)
+		}
+	})
+}
+
+// TestResolvePlacementStrategy 测试未配置或未知的策略回退到默认策略
+func TestResolvePlacementStrategy(t *testing.T) {
+	tests := map[string]string{
+		"":                defaultPlacementStrategy,
+		"unknown":         defaultPlacementStrategy,
+		"least-loaded":    "least-loaded",
+		"weighted-random": "weighted-random",
+	}
+	for configured, expect := range tests {
+		if got, _ := resolvePlacementStrategy(configured); got != expect {
+			t.Errorf("resolvePlacementStrategy(%q) = %q, 期望 %q", configured, got, expect)
+		}
+	}
+}
+
+// TestSelectPlacementProviderRequiresProvider 测试需要节点特定资源的请求不自动选择节点
+func TestSelectPlacementProviderRequiresProvider(t *testing.T) {
+	requests := map[string]*userModel.CreateInstanceRequest{
+		"指定内网IPv4": {RequestedIPv4: "10.0.0.10"},
+		"指定内网IPv6": {RequestedIPv6: "fd00::10"},
+		"指定网络":     {Network: "br1"},
+		"指定GPU":    {GPUDevices: []string{"0000:01:00.0"}},
+		"挂载目录":     {Volumes: make([]providerModel.ProviderVolumeMount, 1)},
+	}
+	s := &Service{}
+	for name, req := range requests {
+		if _, err := s.selectPlacementProvider(req, &systemModel.SystemImage{}); err == nil {
+			t.Errorf("%s: 期望返回错误", name)
+		}
+	}
+}
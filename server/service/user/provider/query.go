@@ -1,6 +1,7 @@
 package provider
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -12,7 +13,9 @@ import (
 	resourceModel "oneclickvirt/model/resource"
 	systemModel "oneclickvirt/model/system"
 	userModel "oneclickvirt/model/user"
+	providerPkg "oneclickvirt/provider"
 	"oneclickvirt/service/images"
+	providerService "oneclickvirt/service/provider"
 	"oneclickvirt/service/resources"
 
 	"go.uber.org/zap"
@@ -22,9 +25,9 @@ import (
 func (s *Service) GetAvailableProviders(userID uint) ([]userModel.AvailableProviderResponse, error) {
 	var dbProviders []providerModel.Provider
 
-	// 获取允许申领且未冻结的Provider，包括部分在线的服务器
-	err := global.APP_DB.Where("(status = ? OR status = ?) AND allow_claim = ? AND is_frozen = ?",
-		"active", "partial", true, false).
+	// 获取允许申领、未冻结且不在维护中的Provider，包括部分在线的服务器
+	err := global.APP_DB.Where("(status = ? OR status = ?) AND allow_claim = ? AND is_frozen = ? AND maintenance_mode = ?",
+		"active", "partial", true, false, false).
 		Limit(1000). // 限制最多1000条，防止单次查询过大
 		Find(&dbProviders).Error
 	if err != nil {
@@ -104,10 +107,12 @@ func (s *Service) GetAvailableProviders(userID uint) ([]userModel.AvailableProvi
 				reservedDisk += reservation.Disk
 			}
 
-			// 使用真实的资源数据
+			// 使用真实的资源数据，CPU和内存按超分配比例计算可分配量
 			nodeCPU := provider.NodeCPUCores
 			nodeMemory := provider.NodeMemoryTotal
 			nodeDisk := provider.NodeDiskTotal
+			allocatableCPU := provider.AllocatableCPUCores()
+			allocatableMemory := provider.AllocatableMemory()
 
 			// 计算实际使用的资源 = 已分配的 + 预留的
 			actualUsedCPU := provider.UsedCPUCores + reservedCPU
@@ -117,8 +122,8 @@ func (s *Service) GetAvailableProviders(userID uint) ([]userModel.AvailableProvi
 			actualUsedVMs := provider.VMCount + reservedVMs
 
 			// 计算可用资源
-			availableCPU := nodeCPU - actualUsedCPU
-			availableMemory := nodeMemory - actualUsedMemory
+			availableCPU := allocatableCPU - actualUsedCPU
+			availableMemory := allocatableMemory - actualUsedMemory
 			availableDisk := nodeDisk - actualUsedDisk
 
 			// 确保不出现负数
@@ -132,14 +137,14 @@ func (s *Service) GetAvailableProviders(userID uint) ([]userModel.AvailableProvi
 				availableDisk = 0
 			}
 
-			// 计算资源使用率
+			// 计算资源使用率（相对于按超分配比例计算的可分配量）
 			cpuUsage := float64(0)
 			memoryUsage := float64(0)
-			if nodeCPU > 0 {
-				cpuUsage = float64(actualUsedCPU) / float64(nodeCPU) * 100
+			if allocatableCPU > 0 {
+				cpuUsage = float64(actualUsedCPU) / float64(allocatableCPU) * 100
 			}
-			if nodeMemory > 0 {
-				memoryUsage = float64(actualUsedMemory) / float64(nodeMemory) * 100
+			if allocatableMemory > 0 {
+				memoryUsage = float64(actualUsedMemory) / float64(allocatableMemory) * 100
 			}
 
 			// 计算可用实例槽位 - 基于容器和虚拟机的单独限制
@@ -245,8 +250,8 @@ func (s *Service) GetInstanceConfig(userID uint, providerID uint) (*userModel.In
 
 	// 获取节点的等级限制（如果指定了 providerID）
 	var providerLevelLimits map[string]interface{}
+	var provider providerModel.Provider
 	if providerID > 0 {
-		var provider providerModel.Provider
 		if err := global.APP_DB.First(&provider, providerID).Error; err == nil && provider.LevelLimits != "" {
 			// 解析节点的 levelLimits JSON
 			var allLevelLimits map[string]map[string]interface{}
@@ -368,15 +373,70 @@ func (s *Service) GetInstanceConfig(userID uint, providerID uint) (*userModel.In
 		}
 	}
 
+	// 节点默认规格只在用户可选范围内时返回，供前端预填表单
+	defaults := userModel.InstanceConfigDefaults{
+		CPU:      provider.DefaultCPU,
+		MemoryMB: provider.DefaultMemory,
+		DiskMB:   provider.DefaultDisk,
+	}
+	cpuID, memoryID, diskID := defaultSpecIDs(provider)
+	for _, option := range cpuOptions {
+		if option.ID == cpuID {
+			defaults.CPUId = cpuID
+		}
+	}
+	for _, option := range memoryOptions {
+		if option.ID == memoryID {
+			defaults.MemoryId = memoryID
+		}
+	}
+	for _, option := range diskOptions {
+		if option.ID == diskID {
+			defaults.DiskId = diskID
+		}
+	}
+
 	return &userModel.InstanceConfigResponse{
 		Images:         images,
 		CPUSpecs:       cpuOptions,
 		MemorySpecs:    memoryOptions,
 		DiskSpecs:      diskOptions,
 		BandwidthSpecs: bandwidthOptions,
+		Defaults:       defaults,
+		Templates:      availableTemplates(providerID),
 	}, nil
 }
 
+// availableTemplates 返回已就绪且启用的实例模板，providerID为0时返回全部节点上的模板
+func availableTemplates(providerID uint) []userModel.InstanceTemplateResponse {
+	query := global.APP_DB.Where("status = ? AND enabled = ?", providerModel.TemplateStatusReady, true)
+	if providerID > 0 {
+		query = query.Where("provider_id = ?", providerID)
+	}
+	var templates []providerModel.InstanceTemplate
+	if err := query.Order("name ASC").Find(&templates).Error; err != nil {
+		global.APP_LOG.Warn("获取实例模板列表失败", zap.Error(err))
+	}
+
+	result := make([]userModel.InstanceTemplateResponse, 0, len(templates))
+	for _, t := range templates {
+		result = append(result, userModel.InstanceTemplateResponse{
+			ID:           t.ID,
+			Name:         t.Name,
+			Description:  t.Description,
+			ProviderID:   t.ProviderID,
+			InstanceType: t.InstanceType,
+			Image:        t.Image,
+			OSType:       t.OSType,
+			CPU:          t.CPU,
+			Memory:       t.Memory,
+			MinDisk:      t.Disk,
+			Bandwidth:    t.Bandwidth,
+		})
+	}
+	return result
+}
+
 // GetFilteredSystemImages 根据Provider和实例类型获取过滤后的系统镜像列表
 func (s *Service) GetFilteredSystemImages(userID uint, providerID uint, instanceType string) ([]userModel.SystemImageResponse, error) {
 	// 验证Provider是否存在
@@ -428,12 +488,13 @@ func (s *Service) GetProviderCapabilities(userID uint, providerID uint) (map[str
 		return nil, errors.New("Provider不存在")
 	}
 
-	// 构建支持的实例类型列表
+	// 构建支持的实例类型列表，Provider已连接时再按探测到的能力过滤（如宿主机不支持KVM时不提供虚拟机）
+	caps := providerService.GetProviderService().GetProviderCapabilities(context.Background(), provider.ID)
 	var supportedTypes []string
-	if provider.ContainerEnabled {
+	if provider.ContainerEnabled && (caps == nil || caps.SupportsContainer) {
 		supportedTypes = append(supportedTypes, "container")
 	}
-	if provider.VirtualMachineEnabled {
+	if provider.VirtualMachineEnabled && (caps == nil || caps.SupportsVM) {
 		supportedTypes = append(supportedTypes, "vm")
 	}
 
@@ -441,12 +502,16 @@ func (s *Service) GetProviderCapabilities(userID uint, providerID uint) (map[str
 		"containerEnabled": provider.ContainerEnabled,
 		"vmEnabled":        provider.VirtualMachineEnabled,
 		"supportedTypes":   supportedTypes,
-		"maxCpu":           provider.NodeCPUCores,
-		"maxMemory":        provider.NodeMemoryTotal,
-		"maxDisk":          provider.NodeDiskTotal,
-		"region":           provider.Region,
-		"country":          provider.Country,
-		"city":             provider.City,
+		// 节点无法创建虚拟机的原因（未开放或宿主机不支持KVM），可以创建时为空
+		"vmUnavailableReason": providerPkg.VMUnavailableReason(provider.VirtualMachineEnabled, caps),
+		"maxCpu":              provider.NodeCPUCores,
+		"maxMemory":           provider.NodeMemoryTotal,
+		"maxDisk":             provider.NodeDiskTotal,
+		"region":              provider.Region,
+		"country":             provider.Country,
+		"city":                provider.City,
+		// 探测到的节点能力，供创建表单隐藏不支持的选项，Provider未连接时为null
+		"capabilities": caps,
 	}
 
 	return capabilities, nil
@@ -0,0 +1,184 @@
+package provider
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"oneclickvirt/global"
+	providerModel "oneclickvirt/model/provider"
+	providerService "oneclickvirt/service/provider"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+)
+
+const (
+	defaultReadyProbe        = "tcp"
+	defaultReadyProbeTimeout = 120 * time.Second
+	readyProbeInterval       = 5 * time.Second
+	readyProbeAttemptTimeout = 5 * time.Second
+)
+
+// instanceSSHAddress 用户连接实例SSH使用的地址：优先使用SSH端口映射的公网端口，其次为实例SSH端口
+func instanceSSHAddress(instance *providerModel.Instance, provider *providerModel.Provider) (string, int) {
+	sshPort := instance.SSHPort
+	var sshPortMapping providerModel.Port
+	if err := global.APP_DB.Where("instance_id = ? AND is_ssh = true AND status = 'active'", instance.ID).First(&sshPortMapping).Error; err == nil {
+		sshPort = sshPortMapping.HostPort
+	}
+	if sshPort == 0 {
+		sshPort = 22 // 默认端口
+	}
+
+	// 优先使用端口映射专用IP，否则使用SSH连接地址
+	sshHost := provider.PortIP
+	if sshHost == "" {
+		sshHost = provider.Endpoint
+	}
+
+	// 如果sshHost包含端口，去掉端口部分
+	if colonIndex := strings.LastIndex(sshHost, ":"); colonIndex > 0 {
+		if strings.Count(sshHost, ":") == 1 || strings.HasPrefix(sshHost, "[") {
+			sshHost = sshHost[:colonIndex]
+		}
+	}
+	return sshHost, sshPort
+}
+
+// readyProbeFunc 单次就绪探测，返回nil表示实例已就绪
+type readyProbeFunc func(ctx context.Context) error
+
+// probeInstanceReady 在标记创建任务完成前按配置探测实例是否可用，避免用户立即连接时被拒绝
+// 返回使用的探测方式和是否就绪，探测方式为none或探测器无法构建时视为就绪
+func (s *Service) probeInstanceReady(instanceID, taskID uint, progress int) (string, bool) {
+	probe := global.APP_CONFIG.Quota.InstanceReadyProbe
+	if probe == "" {
+		probe = defaultReadyProbe
+	}
+	if probe == "none" {
+		return probe, true
+	}
+	timeout := defaultReadyProbeTimeout
+	if global.APP_CONFIG.Quota.InstanceReadyTimeout > 0 {
+		timeout = time.Duration(global.APP_CONFIG.Quota.InstanceReadyTimeout) * time.Second
+	}
+
+	var instance providerModel.Instance
+	if err := global.APP_DB.First(&instance, instanceID).Error; err != nil {
+		global.APP_LOG.Warn("获取实例信息失败，跳过就绪探测", zap.Uint("instanceId", instanceID), zap.Error(err))
+		return probe, true
+	}
+
+	check, err := s.buildReadyProbe(probe, &instance)
+	if err != nil {
+		global.APP_LOG.Warn("无法构建实例就绪探测，跳过",
+			zap.Uint("instanceId", instanceID),
+			zap.String("probe", probe),
+			zap.Error(err))
+		return probe, true
+	}
+
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), readyProbeAttemptTimeout+5*time.Second)
+		err := check(ctx)
+		cancel()
+		elapsed := time.Since(start)
+		if err == nil {
+			global.APP_LOG.Info("实例就绪探测通过",
+				zap.Uint("instanceId", instanceID),
+				zap.String("probe", probe),
+				zap.Int("attempts", attempt),
+				zap.Duration("elapsed", elapsed))
+			s.updateTaskProgress(taskID, progress, fmt.Sprintf("实例已就绪（%s探测，%ds）", probe, int(elapsed.Seconds())))
+			return probe, true
+		}
+		if elapsed+readyProbeInterval >= timeout {
+			global.APP_LOG.Warn("实例就绪探测超时",
+				zap.Uint("instanceId", instanceID),
+				zap.String("probe", probe),
+				zap.Int("attempts", attempt),
+				zap.Duration("timeout", timeout),
+				zap.Error(err))
+			s.updateTaskProgress(taskID, progress, fmt.Sprintf("实例就绪探测超时（%s探测，%ds）", probe, int(timeout.Seconds())))
+			return probe, false
+		}
+		s.updateTaskProgress(taskID, progress, fmt.Sprintf("等待实例就绪（%s探测，第%d次，已等待%ds）...", probe, attempt, int(elapsed.Seconds())))
+		time.Sleep(readyProbeInterval)
+	}
+}
+
+// buildReadyProbe 根据探测方式构建探测函数
+func (s *Service) buildReadyProbe(probe string, instance *providerModel.Instance) (readyProbeFunc, error) {
+	switch probe {
+	case "tcp":
+		var provider providerModel.Provider
+		if err := global.APP_DB.First(&provider, instance.ProviderID).Error; err != nil {
+			return nil, fmt.Errorf("获取Provider信息失败: %w", err)
+		}
+		host, port := instanceSSHAddress(instance, &provider)
+		if host == "" {
+			return nil, fmt.Errorf("无法确定实例SSH地址")
+		}
+		address := net.JoinHostPort(strings.Trim(host, "[]"), strconv.Itoa(port))
+		return func(ctx context.Context) error {
+			return probeSSHBanner(ctx, address)
+		}, nil
+	case "exec":
+		prov, err := providerService.GetProviderInstanceByID(instance.ProviderID)
+		if err != nil {
+			return nil, fmt.Errorf("获取Provider实例失败: %w", err)
+		}
+		executor, ok := prov.(interface {
+			ExecInInstance(ctx context.Context, instanceName, command string, timeout time.Duration, maxOutput int) (*utils.CommandResult, error)
+		})
+		if !ok {
+			return nil, fmt.Errorf("该节点类型不支持在实例内执行命令")
+		}
+		name := instance.Name
+		return func(ctx context.Context) error {
+			result, err := executor.ExecInInstance(ctx, name, "true", readyProbeAttemptTimeout, 1024)
+			if err != nil {
+				return err
+			}
+			if result.ExitCode != 0 {
+				return fmt.Errorf("exit code %d", result.ExitCode)
+			}
+			return nil
+		}, nil
+	default:
+		return nil, fmt.Errorf("未知的探测方式: %s", probe)
+	}
+}
+
+// probeSSHBanner 连接SSH端口并读取服务端banner，只有sshd真正开始服务时才会返回 SSH- 开头的版本标识
+func probeSSHBanner(ctx context.Context, address string) error {
+	dialer := net.Dialer{Timeout: readyProbeAttemptTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if err := conn.SetReadDeadline(time.Now().Add(readyProbeAttemptTimeout)); err != nil {
+		return err
+	}
+	// 服务端可以在版本标识前发送其他文本行（RFC 4253 4.2）
+	reader := bufio.NewReader(conn)
+	var line string
+	for i := 0; i < 5; i++ {
+		line, err = reader.ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("读取SSH banner失败: %w", err)
+		}
+		if strings.HasPrefix(line, "SSH-") {
+			return nil
+		}
+	}
+	return fmt.Errorf("非SSH服务响应: %s", utils.TruncateString(strings.TrimSpace(line), 64))
+}
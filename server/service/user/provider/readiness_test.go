@@ -0,0 +1,100 @@
+package provider
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"oneclickvirt/global"
+	providerModel "oneclickvirt/model/provider"
+)
+
+// serveOnce 在本地监听端口，对第一个连接执行handler，返回监听地址
+func serveOnce(t *testing.T, handler func(conn net.Conn)) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("监听端口失败: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		handler(conn)
+	}()
+	return listener.Addr().String()
+}
+
+// TestProbeSSHBanner 测试只有返回SSH版本标识的服务才视为就绪
+func TestProbeSSHBanner(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler func(conn net.Conn)
+		wantErr bool
+	}{
+		{
+			name:    "sshd就绪",
+			handler: func(conn net.Conn) { conn.Write([]byte("SSH-2.0-OpenSSH_9.6\r\n")) },
+		},
+		{
+			name:    "版本标识前有其他文本行",
+			handler: func(conn net.Conn) { conn.Write([]byte("Welcome\r\nSSH-2.0-dropbear\r\n")) },
+		},
+		{
+			name:    "非SSH服务",
+			handler: func(conn net.Conn) { conn.Write([]byte("HTTP/1.1 400 Bad Request\r\n\r\n\r\n\r\n\r\n")) },
+			wantErr: true,
+		},
+		{
+			name:    "端口转发已建立但后端未响应即断开",
+			handler: func(conn net.Conn) {},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			address := serveOnce(t, tt.handler)
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			if err := probeSSHBanner(ctx, address); (err != nil) != tt.wantErr {
+				t.Errorf("probeSSHBanner() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("端口未监听", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("监听端口失败: %v", err)
+		}
+		address := listener.Addr().String()
+		listener.Close()
+		if err := probeSSHBanner(context.Background(), address); err == nil {
+			t.Error("probeSSHBanner() 期望返回错误")
+		}
+	})
+}
+
+// TestProbeInstanceReadyDisabled 测试探测方式为none时不访问实例直接视为就绪
+func TestProbeInstanceReadyDisabled(t *testing.T) {
+	original := global.APP_CONFIG.Quota.InstanceReadyProbe
+	defer func() { global.APP_CONFIG.Quota.InstanceReadyProbe = original }()
+	global.APP_CONFIG.Quota.InstanceReadyProbe = "none"
+
+	probe, ready := (&Service{}).probeInstanceReady(1, 1, 90)
+	if probe != "none" || !ready {
+		t.Errorf("probeInstanceReady() = (%q, %v), 期望 (\"none\", true)", probe, ready)
+	}
+}
+
+// TestBuildReadyProbeUnknown 测试未知探测方式无法构建探测函数
+func TestBuildReadyProbeUnknown(t *testing.T) {
+	if _, err := (&Service{}).buildReadyProbe("ssh", &providerModel.Instance{}); err == nil {
+		t.Error("buildReadyProbe() 期望返回错误")
+	}
+}
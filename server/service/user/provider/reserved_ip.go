@@ -0,0 +1,54 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+
+	providerModel "oneclickvirt/model/provider"
+
+	"gorm.io/gorm"
+)
+
+// validateRequestedIPs 校验节点类型是否支持指定内网IP，并检查地址是否已被占用
+// 子网范围由Provider在创建时根据节点实际网络配置校验
+func validateRequestedIPs(db *gorm.DB, provider *providerModel.Provider, ipv4, ipv6 string) error {
+	if ipv4 == "" && ipv6 == "" {
+		return nil
+	}
+
+	switch provider.Type {
+	case "lxd", "incus":
+	case "proxmox":
+		if ipv6 != "" {
+			return errors.New("Proxmox节点不支持指定IPv6地址")
+		}
+	default:
+		return fmt.Errorf("%s 节点不支持指定IP地址", provider.Type)
+	}
+
+	if ipv4 != "" {
+		var count int64
+		if err := db.Model(&providerModel.Instance{}).
+			Where("provider_id = ? AND (reserved_ipv4 = ? OR private_ip = ?)", provider.ID, ipv4, ipv4).
+			Count(&count).Error; err != nil {
+			return fmt.Errorf("检查IP地址占用失败: %v", err)
+		}
+		if count > 0 {
+			return fmt.Errorf("IP地址 %s 已被其他实例占用", ipv4)
+		}
+	}
+
+	if ipv6 != "" {
+		var count int64
+		if err := db.Model(&providerModel.Instance{}).
+			Where("provider_id = ? AND (reserved_ipv6 = ? OR ipv6_address = ?)", provider.ID, ipv6, ipv6).
+			Count(&count).Error; err != nil {
+			return fmt.Errorf("检查IP地址占用失败: %v", err)
+		}
+		if count > 0 {
+			return fmt.Errorf("IP地址 %s 已被其他实例占用", ipv6)
+		}
+	}
+
+	return nil
+}
@@ -45,6 +45,14 @@ func (tsa *taskServiceAdapter) CreateTask(userID uint, providerID *uint, instanc
 	return globalTaskService.CreateTask(userID, providerID, instanceID, taskType, taskData, timeoutDuration)
 }
 
+// SetTaskPriority 调整任务优先级的适配器方法
+func (tsa *taskServiceAdapter) SetTaskPriority(taskID uint, priority int) error {
+	if globalTaskService == nil {
+		return fmt.Errorf("任务服务未初始化")
+	}
+	return globalTaskService.SetTaskPriority(taskID, priority)
+}
+
 // GetStateManager 获取状态管理器的适配器方法
 func (tsa *taskServiceAdapter) GetStateManager() interfaces.TaskStateManagerInterface {
 	if globalTaskService == nil {
@@ -172,13 +180,25 @@ func (s *Service) prepareInstanceCreation(ctx context.Context, task *adminModel.
 			return fmt.Errorf("服务器已被冻结")
 		}
 
+		if provider.MaintenanceMode {
+			return fmt.Errorf("服务器正在维护，暂不接受新实例")
+		}
+
 		// 验证Provider是否过期
 		if provider.ExpiresAt != nil && provider.ExpiresAt.Before(time.Now()) {
 			return fmt.Errorf("服务器已过期")
 		}
 
+		// 指定IP在事务内复查，避免并发申请同一地址
+		if err := validateRequestedIPs(tx, &provider, taskReq.RequestedIPv4, taskReq.RequestedIPv6); err != nil {
+			return err
+		}
+
 		// 生成实例名称
-		instanceName := s.generateInstanceName(provider.Name)
+		instanceName, err := s.generateInstanceName(provider.Name)
+		if err != nil {
+			return err
+		}
 
 		// 设置实例到期时间，与Provider的到期时间同步
 		var expiredAt time.Time
@@ -189,6 +209,12 @@ func (s *Service) prepareInstanceCreation(ctx context.Context, task *adminModel.
 			// 如果Provider没有到期时间，默认为1年后
 			expiredAt = time.Now().AddDate(1, 0, 0)
 		}
+		// 用户指定了有效期时以较早者为准，到期后自动回收
+		if taskReq.TTLHours > 0 {
+			if ttlExpiredAt := time.Now().Add(time.Duration(taskReq.TTLHours) * time.Hour); ttlExpiredAt.Before(expiredAt) {
+				expiredAt = ttlExpiredAt
+			}
+		}
 
 		// 创建实例记录
 		instance = providerModel.Instance{
@@ -208,6 +234,16 @@ func (s *Service) prepareInstanceCreation(ctx context.Context, task *adminModel.
 			MaxTraffic:         0,     // 默认为0，表示继承用户等级限制，不单独限制实例
 			TrafficLimited:     false, // 显式设置为false，确保不会因流量误判为超限
 			TrafficLimitReason: "",    // 初始无限制原因
+			Tags:               taskReq.Tags,
+			ReservedIPv4:       taskReq.RequestedIPv4,
+			ReservedIPv6:       taskReq.RequestedIPv6,
+			Network:            taskReq.Network,
+			NetworkInterfaces:  taskReq.NetworkInterfaces,
+			GPUDevices:         taskReq.GPUDevices,
+			DNSServers:         taskReq.DNSServers,
+			Hostname:           taskReq.Hostname,
+			MemorySwapMB:       taskReq.MemorySwapMB,
+			Volumes:            taskReq.Volumes,
 		}
 
 		// 创建实例
@@ -255,6 +291,9 @@ func (s *Service) prepareInstanceCreation(ctx context.Context, task *adminModel.
 		zap.String("sessionId", taskReq.SessionId),
 		zap.Uint("instanceId", instance.ID))
 
+	// 实例记录已落库，重新同步Provider资源占用
+	(&resources.ResourceService{}).SyncProviderResourcesAsync(instance.ProviderID)
+
 	// 更新进度到25% (数据库预处理完成)
 	s.updateTaskProgress(task.ID, 25, "数据库预处理完成")
 
@@ -390,13 +429,25 @@ func (s *Service) executeProviderCreation(ctx context.Context, task *adminModel.
 
 	// 构建实例配置，使用实际数值而非ID
 	instanceConfig := provider.InstanceConfig{
-		Name:         instance.Name,
-		Image:        systemImage.Name,
-		CPU:          fmt.Sprintf("%d", cpuSpec.Cores),      // 使用实际核心数
-		Memory:       fmt.Sprintf("%dm", memorySpec.SizeMB), // 使用实际内存大小（MB格式）
-		Disk:         fmt.Sprintf("%dm", diskSpec.SizeMB),   // 使用实际磁盘大小（MB格式）
-		InstanceType: instance.InstanceType,
-		ImageURL:     systemImage.URL, // 镜像URL用于下载
+		Name:              instance.Name,
+		Image:             systemImage.Name,
+		CPU:               fmt.Sprintf("%d", cpuSpec.Cores),      // 使用实际核心数
+		Memory:            fmt.Sprintf("%dm", memorySpec.SizeMB), // 使用实际内存大小（MB格式）
+		Disk:              fmt.Sprintf("%dm", diskSpec.SizeMB),   // 使用实际磁盘大小（MB格式）
+		InstanceType:      instance.InstanceType,
+		ImageURL:          systemImage.URL, // 镜像URL用于下载
+		ImageSHA256:       provider.NormalizeSHA256(systemImage.Checksum),
+		StoragePool:       dbProvider.InstanceStoragePool(),
+		Labels:            instance.Tags, // 实例标签，同步为Provider原生标签
+		RequestedIPv4:     instance.ReservedIPv4,
+		RequestedIPv6:     instance.ReservedIPv6,
+		SSHPublicKeys:     taskReq.SSHPublicKeys,
+		Network:           instance.Network,
+		NetworkInterfaces: instance.NetworkInterfaces,
+		GPUDevices:        instance.GPUDevices,
+		DNSServers:        instance.DNSServers,
+		Hostname:          instance.Hostname,
+		Volumes:           instance.Volumes,
 		Metadata: map[string]string{
 			"user_level":               fmt.Sprintf("%d", user.Level),              // 用户等级，用于带宽限制配置
 			"bandwidth_spec":           fmt.Sprintf("%d", bandwidthSpec.SpeedMbps), // 用户选择的带宽规格
@@ -412,6 +463,7 @@ func (s *Service) executeProviderCreation(ctx context.Context, task *adminModel.
 		EnableLXCFS:  boolPtr(dbProvider.ContainerEnableLXCFS),
 		CPUAllowance: stringPtr(dbProvider.ContainerCPUAllowance),
 		MemorySwap:   boolPtr(dbProvider.ContainerMemorySwap),
+		MemorySwapMB: instance.MemorySwapMB,
 		MaxProcesses: intPtr(dbProvider.ContainerMaxProcesses),
 		DiskIOLimit:  stringPtr(dbProvider.ContainerDiskIOLimit),
 	}
@@ -434,8 +486,8 @@ func (s *Service) executeProviderCreation(ctx context.Context, task *adminModel.
 				zap.Uint("instanceId", instance.ID),
 				zap.Error(err))
 		} else {
-			// 对于Docker容器，将端口映射信息添加到实例配置中
-			if localProviderType == "docker" {
+			// 对于Docker/Podman容器，将端口映射信息添加到实例配置中
+			if localProviderType == "docker" || localProviderType == "podman" {
 				// 将端口映射信息添加到实例配置中
 				var ports []string
 				for _, port := range portMappings {
@@ -496,6 +548,11 @@ func (s *Service) executeProviderCreation(ctx context.Context, task *adminModel.
 
 	global.APP_LOG.Info("Provider API调用成功", zap.Uint("taskId", task.ID), zap.String("instanceName", instance.Name))
 
+	// 保存创建配置，重建实例时按相同配置重新应用
+	if err := global.APP_DB.Model(instance).Select("create_config").Updates(&providerModel.Instance{CreateConfig: &instanceConfig}).Error; err != nil {
+		global.APP_LOG.Warn("保存实例创建配置失败", zap.Uint("instanceId", instance.ID), zap.Error(err))
+	}
+
 	// 更新进度到70%
 	s.updateTaskProgress(task.ID, 70, "Provider API调用成功")
 
@@ -625,6 +682,13 @@ func (s *Service) finalizeInstanceCreation(ctx context.Context, task *adminModel
 			if actualInstance.IPv6Address != "" {
 				instanceUpdates["ipv6_address"] = actualInstance.IPv6Address
 			}
+			// 缓存veth接口和网络信息同步时间，列表和详情无需再通过SSH查询
+			if veth := actualInstance.Metadata["network_interface"]; veth != "" {
+				instanceUpdates["veth_interface"] = veth
+			}
+			if actualInstance.PrivateIP != "" || actualInstance.IP != "" {
+				instanceUpdates["network_synced_at"] = time.Now()
+			}
 			// SSH端口使用默认值22
 			instanceUpdates["ssh_port"] = 22
 			// 标准化实例状态：将Provider返回的各种运行状态统一为"running"
@@ -1074,6 +1138,10 @@ func (s *Service) finalizeInstanceCreation(ctx context.Context, task *adminModel
 				}
 			}
 
+			// 6. 就绪探测：端口映射和密码配置完成后确认实例可连接，再标记任务完成
+			s.updateTaskProgress(taskID, 99, "正在检查实例就绪状态...")
+			readyProbe, ready := s.probeInstanceReady(instanceID, taskID, 99)
+
 			// 最终完成状态判断
 			completionMessage := "实例创建成功"
 			if !passwordSetSuccess && currentInstance.Password != "" {
@@ -1081,6 +1149,8 @@ func (s *Service) finalizeInstanceCreation(ctx context.Context, task *adminModel
 				global.APP_LOG.Warn("实例创建完成但SSH密码设置失败",
 					zap.Uint("instanceId", instanceID),
 					zap.String("instanceName", currentInstance.Name))
+			} else if !ready {
+				completionMessage = fmt.Sprintf("实例创建成功，但就绪探测（%s）超时，实例可能仍在启动，请稍后再连接", readyProbe)
 			}
 
 			// 标记任务最终完成
@@ -1118,32 +1188,7 @@ func (s *Service) waitForInstanceSSHReady(instanceID, providerID, taskID uint, m
 		return fmt.Errorf("获取Provider信息失败: %w", err)
 	}
 
-	// 获取SSH端口映射
-	var sshPort int
-	var sshPortMapping providerModel.Port
-	if err := global.APP_DB.Where("instance_id = ? AND is_ssh = true AND status = 'active'", instanceID).First(&sshPortMapping).Error; err == nil {
-		sshPort = sshPortMapping.HostPort
-	} else {
-		sshPort = instance.SSHPort
-		if sshPort == 0 {
-			sshPort = 22 // 默认端口
-		}
-	}
-
-	// 确定SSH连接地址
-	var sshHost string
-	if provider.PortIP != "" {
-		sshHost = provider.PortIP
-	} else {
-		sshHost = provider.Endpoint
-	}
-
-	// 如果sshHost包含端口，去掉端口部分
-	if colonIndex := strings.LastIndex(sshHost, ":"); colonIndex > 0 {
-		if strings.Count(sshHost, ":") == 1 || strings.HasPrefix(sshHost, "[") {
-			sshHost = sshHost[:colonIndex]
-		}
-	}
+	sshHost, sshPort := instanceSSHAddress(&instance, &provider)
 
 	global.APP_LOG.Info("开始等待实例SSH服务就绪",
 		zap.Uint("instanceId", instanceID),
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"oneclickvirt/constant"
 	"oneclickvirt/global"
@@ -13,12 +14,43 @@ import (
 	resourceModel "oneclickvirt/model/resource"
 	systemModel "oneclickvirt/model/system"
 	"oneclickvirt/service/auth"
+	providerService "oneclickvirt/service/provider"
 	"oneclickvirt/service/resources"
 
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
+// ValidateProviderAcceptsNewInstance 校验节点当前是否接受新实例：允许申领、未冻结、未维护、未因流量超限被限制且未过期
+// 申领实例、克隆实例和从模板创建实例共用此校验
+func ValidateProviderAcceptsNewInstance(provider *providerModel.Provider) error {
+	if !provider.AllowClaim || provider.IsFrozen {
+		global.APP_LOG.Error("服务器不可用",
+			zap.Uint("providerId", provider.ID),
+			zap.Bool("allowClaim", provider.AllowClaim),
+			zap.Bool("isFrozen", provider.IsFrozen))
+		return errors.New("服务器不可用")
+	}
+
+	if provider.MaintenanceMode {
+		return errors.New("该服务器正在维护，暂不接受新实例，请选择其他服务器")
+	}
+
+	// 检查Provider是否因流量超限被限制
+	if provider.TrafficLimited {
+		global.APP_LOG.Error("Provider因流量超限被限制，禁止申请新实例",
+			zap.Uint("providerId", provider.ID),
+			zap.String("providerName", provider.Name),
+			zap.Bool("trafficLimited", provider.TrafficLimited))
+		return errors.New("该服务器因流量超限暂时不可用，请选择其他服务器或联系管理员")
+	}
+
+	if provider.ExpiresAt != nil && provider.ExpiresAt.Before(time.Now()) {
+		return errors.New("服务器已过期")
+	}
+	return nil
+}
+
 // validateProviderImageCompatibility 验证Provider和Image的兼容性
 func (s *Service) validateProviderImageCompatibility(provider *providerModel.Provider, image *systemModel.SystemImage) error {
 	// 验证Provider类型是否支持该镜像
@@ -461,3 +493,39 @@ func (s *Service) validateProviderConcurrencyLimit(providerID uint, maxConcurren
 
 	return nil
 }
+
+// maxIPv6ProviderSuggestions 节点缺少IPv6时最多推荐的其他节点数量
+const maxIPv6ProviderSuggestions = 5
+
+// findIPv6CompatibleProviders 查找可申领且配置了IPv6网络的其他节点名称，用于在当前节点缺少IPv6时给出推荐
+// 只使用已缓存的能力信息排除确认不支持IPv6的节点，不会对候选节点发起SSH探测
+func (s *Service) findIPv6CompatibleProviders(excludeID uint, instanceType string) []string {
+	query := global.APP_DB.Model(&providerModel.Provider{}).
+		Select("id", "name").
+		Where("id <> ? AND (status = ? OR status = ?) AND allow_claim = ? AND is_frozen = ? AND maintenance_mode = ?",
+			excludeID, "active", "partial", true, false, false).
+		Where("network_type LIKE ?", "%ipv6%")
+	if instanceType == "vm" {
+		query = query.Where("virtual_machine_enabled = ?", true)
+	} else {
+		query = query.Where("container_enabled = ?", true)
+	}
+
+	var candidates []providerModel.Provider
+	if err := query.Order("id ASC").Limit(50).Find(&candidates).Error; err != nil {
+		global.APP_LOG.Warn("查询支持IPv6的节点失败", zap.Error(err))
+		return nil
+	}
+
+	names := make([]string, 0, maxIPv6ProviderSuggestions)
+	for _, candidate := range candidates {
+		if caps := providerService.GetProviderService().GetCachedProviderCapabilities(candidate.ID); caps != nil && !caps.SupportsIPv6 {
+			continue
+		}
+		names = append(names, candidate.Name)
+		if len(names) >= maxIPv6ProviderSuggestions {
+			break
+		}
+	}
+	return names
+}
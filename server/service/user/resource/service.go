@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	providerPkg "oneclickvirt/provider"
 	"oneclickvirt/service/database"
+	providerService "oneclickvirt/service/provider"
 	"oneclickvirt/service/resources"
 	"time"
 
@@ -93,6 +95,10 @@ func (s *Service) GetAvailableResources(req userModel.AvailableResourcesRequest)
 			availableQuota = 0
 		}
 
+		// 列表只使用已缓存的能力信息，不逐个节点触发SSH探测
+		vmUnavailableReason := providerPkg.VMUnavailableReason(provider.VirtualMachineEnabled,
+			providerService.GetProviderService().GetCachedProviderCapabilities(provider.ID))
+
 		resourceResponse := userModel.AvailableResourceResponse{
 			ID:                    provider.ID,
 			Name:                  provider.Name,
@@ -102,6 +108,8 @@ func (s *Service) GetAvailableResources(req userModel.AvailableResourcesRequest)
 			CountryCode:           provider.CountryCode,
 			ContainerEnabled:      provider.ContainerEnabled,
 			VirtualMachineEnabled: provider.VirtualMachineEnabled,
+			SupportsVM:            vmUnavailableReason == "",
+			VMUnavailableReason:   vmUnavailableReason,
 			AvailableQuota:        availableQuota, // 减去预留的配额
 			Status:                provider.Status,
 		}
@@ -119,6 +127,11 @@ func (s *Service) ClaimResource(userID uint, req userModel.ClaimResourceRequest)
 	quotaService := resources.NewQuotaService()
 	reservationService := resources.GetResourceReservationService()
 
+	// 在加锁预留资源前校验节点是否支持该实例类型（如宿主机无KVM时申领虚拟机），避免在创建流程中才失败
+	if err := checkInstanceTypeSupported(req.ProviderID, req.InstanceType); err != nil {
+		return nil, err
+	}
+
 	// 生成会话ID用于资源预留
 	sessionID := resources.GenerateSessionID()
 
@@ -152,6 +165,10 @@ func (s *Service) ClaimResource(userID uint, req userModel.ClaimResourceRequest)
 			return errors.New("提供商已被冻结")
 		}
 
+		if provider.MaintenanceMode {
+			return errors.New("提供商正在维护，暂不接受新实例")
+		}
+
 		// 检查提供商是否过期
 		if provider.ExpiresAt != nil && provider.ExpiresAt.Before(time.Now()) {
 			return errors.New("提供商已过期")
@@ -319,3 +336,21 @@ func (s *Service) ClaimResource(userID uint, req userModel.ClaimResourceRequest)
 
 	return &instance, nil
 }
+
+// checkInstanceTypeSupported 校验节点已开放该实例类型，且探测到的节点能力支持该类型
+// Provider未连接时无法探测，只校验开放状态，由创建流程兜底
+func checkInstanceTypeSupported(providerID uint, instanceType string) error {
+	resourceService := &resources.ResourceService{}
+	if err := resourceService.ValidateInstanceTypeSupport(providerID, instanceType); err != nil {
+		return err
+	}
+	caps := providerService.GetProviderService().GetProviderCapabilities(context.Background(), providerID)
+	if err := providerPkg.CheckInstanceCapabilities(caps, instanceType, "", false); err != nil {
+		global.APP_LOG.Warn("申领的实例类型与节点能力不匹配",
+			zap.Uint("providerId", providerID),
+			zap.String("instanceType", instanceType),
+			zap.Error(err))
+		return err
+	}
+	return nil
+}
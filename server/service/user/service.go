@@ -191,7 +191,97 @@ func (s *Service) ResetInstancePassword(userID uint, instanceID uint) (uint, err
 	return s.instance.ResetInstancePassword(userID, instanceID)
 }
 
+// CloneInstance 克隆实例
+func (s *Service) CloneInstance(userID uint, instanceID uint, req userModel.CloneInstanceRequest) (*userModel.CloneInstanceResponse, error) {
+	return s.instance.CloneInstance(userID, instanceID, req)
+}
+
+// CreateInstanceFromTemplate 从模板创建实例
+func (s *Service) CreateInstanceFromTemplate(userID uint, templateID uint, req userModel.CreateInstanceFromTemplateRequest) (*userModel.CreateInstanceFromTemplateResponse, error) {
+	return s.instance.CreateInstanceFromTemplate(userID, templateID, req)
+}
+
+// UpdateInstanceTags 更新实例标签
+func (s *Service) UpdateInstanceTags(userID uint, instanceID uint, tags map[string]string) (map[string]string, error) {
+	return s.instance.UpdateInstanceTags(userID, instanceID, tags)
+}
+
+// ExecInstanceCommand 在实例内执行命令
+func (s *Service) ExecInstanceCommand(userID uint, instanceID uint, command string) (*userModel.ExecInstanceCommandResponse, error) {
+	return s.instance.ExecInstanceCommand(userID, instanceID, command)
+}
+
+// GetInstanceLogs 获取实例日志
+func (s *Service) GetInstanceLogs(userID uint, instanceID uint, req userModel.InstanceLogsRequest) (*userModel.InstanceLogsResponse, error) {
+	return s.instance.GetInstanceLogs(userID, instanceID, req)
+}
+
+// ExtendInstance 延长实例有效期
+func (s *Service) ExtendInstance(userID uint, instanceID uint, hours int) (*userModel.ExtendInstanceResponse, error) {
+	return s.instance.ExtendInstance(userID, instanceID, hours)
+}
+
+// UndeleteInstance 在删除等待期内撤销实例删除
+func (s *Service) UndeleteInstance(userID uint, instanceID uint) (*userModel.UndeleteInstanceResponse, error) {
+	return s.instance.UndeleteInstance(userID, instanceID)
+}
+
+// UpdateInstanceLimits 调整实例CPU和内存限制
+func (s *Service) UpdateInstanceLimits(userID uint, instanceID uint, req userModel.UpdateInstanceLimitsRequest) (*userModel.UpdateInstanceLimitsResponse, error) {
+	return s.instance.UpdateInstanceLimits(userID, instanceID, req)
+}
+
+// GetInstanceSchedule 获取实例定时开关机计划
+func (s *Service) GetInstanceSchedule(userID uint, instanceID uint) (*userModel.InstanceScheduleResponse, error) {
+	return s.instance.GetInstanceSchedule(userID, instanceID)
+}
+
+// SetInstanceSchedule 设置实例定时开关机计划
+func (s *Service) SetInstanceSchedule(userID uint, instanceID uint, req userModel.SetInstanceScheduleRequest) (*userModel.InstanceScheduleResponse, error) {
+	return s.instance.SetInstanceSchedule(userID, instanceID, req)
+}
+
+// ClearInstanceSchedule 删除实例定时开关机计划
+func (s *Service) ClearInstanceSchedule(userID uint, instanceID uint) error {
+	return s.instance.ClearInstanceSchedule(userID, instanceID)
+}
+
+// GetInstanceShares 获取实例共享授权列表
+func (s *Service) GetInstanceShares(userID uint, instanceID uint) ([]userModel.InstanceShareResponse, error) {
+	return s.instance.GetInstanceShares(userID, instanceID)
+}
+
+// GrantInstanceShare 共享实例给其他用户
+func (s *Service) GrantInstanceShare(userID uint, instanceID uint, req userModel.GrantInstanceShareRequest) (*userModel.InstanceShareResponse, error) {
+	return s.instance.GrantInstanceShare(userID, instanceID, req)
+}
+
+// RevokeInstanceShare 撤销实例共享授权
+func (s *Service) RevokeInstanceShare(userID uint, instanceID uint, shareID uint) error {
+	return s.instance.RevokeInstanceShare(userID, instanceID, shareID)
+}
+
+// GetSharedInstances 获取共享给当前用户的实例
+func (s *Service) GetSharedInstances(userID uint) ([]userModel.SharedInstanceResponse, error) {
+	return s.instance.GetSharedInstances(userID)
+}
+
 // GetInstanceNewPassword 获取实例新密码
 func (s *Service) GetInstanceNewPassword(userID uint, instanceID uint, taskID uint) (string, int64, error) {
 	return s.instance.GetInstanceNewPassword(userID, instanceID, taskID)
 }
+
+// ListFirewallRules 获取实例防火墙规则
+func (s *Service) ListFirewallRules(userID uint, instanceID uint) ([]providerModel.InstanceFirewallRule, error) {
+	return s.instance.ListFirewallRules(userID, instanceID)
+}
+
+// AddFirewallRule 添加实例防火墙规则
+func (s *Service) AddFirewallRule(userID uint, instanceID uint, req userModel.AddFirewallRuleRequest) (*providerModel.InstanceFirewallRule, error) {
+	return s.instance.AddFirewallRule(userID, instanceID, req)
+}
+
+// DeleteFirewallRule 删除实例防火墙规则
+func (s *Service) DeleteFirewallRule(userID uint, instanceID uint, ruleID uint) error {
+	return s.instance.DeleteFirewallRule(userID, instanceID, ruleID)
+}
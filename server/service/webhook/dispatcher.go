@@ -0,0 +1,186 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"oneclickvirt/global"
+	adminModel "oneclickvirt/model/admin"
+	providerModel "oneclickvirt/model/provider"
+	userModel "oneclickvirt/model/user"
+	"oneclickvirt/utils"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// deliveryTimeout 单次投递的HTTP超时
+	deliveryTimeout = 10 * time.Second
+	// initialBackoff 首次重试前的等待时间，之后每次翻倍
+	initialBackoff = 2 * time.Second
+	// maxBackoff 重试等待时间上限
+	maxBackoff = 60 * time.Second
+
+	// SignatureHeader 请求体HMAC-SHA256签名，格式为 sha256=<hex>
+	SignatureHeader = "X-Webhook-Signature"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+)
+
+// Payload Webhook推送内容
+type Payload struct {
+	Event        string    `json:"event"`
+	InstanceID   uint      `json:"instanceId"`
+	InstanceName string    `json:"instanceName"`
+	ProviderID   uint      `json:"providerId"`
+	Provider     string    `json:"provider"`
+	UserID       uint      `json:"userId"`
+	Username     string    `json:"username"`
+	Message      string    `json:"message,omitempty"`
+	Timestamp    time.Time `json:"timestamp"`
+}
+
+// NotifyInstanceEvent 异步推送实例事件到所有订阅了该事件的Webhook
+// 实例可能已被软删除，因此按Unscoped查询
+func NotifyInstanceEvent(event string, instanceID uint, message string) {
+	if global.APP_DB == nil || instanceID == 0 {
+		return
+	}
+	timestamp := time.Now()
+
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				global.APP_LOG.Error("Webhook推送发生panic", zap.Any("panic", r))
+			}
+		}()
+
+		var hooks []adminModel.Webhook
+		if err := global.APP_DB.Where("enabled = ?", true).Find(&hooks).Error; err != nil {
+			global.APP_LOG.Warn("查询Webhook配置失败", zap.Error(err))
+			return
+		}
+		var targets []adminModel.Webhook
+		for _, hook := range hooks {
+			if subscribes(hook, event) {
+				targets = append(targets, hook)
+			}
+		}
+		if len(targets) == 0 {
+			return
+		}
+
+		var instance providerModel.Instance
+		if err := global.APP_DB.Unscoped().First(&instance, instanceID).Error; err != nil {
+			global.APP_LOG.Warn("Webhook推送查询实例失败",
+				zap.String("event", event),
+				zap.Uint("instanceID", instanceID),
+				zap.Error(err))
+			return
+		}
+		var user userModel.User
+		global.APP_DB.Select("id, username").First(&user, instance.UserID)
+
+		payload := Payload{
+			Event:        event,
+			InstanceID:   instance.ID,
+			InstanceName: instance.Name,
+			ProviderID:   instance.ProviderID,
+			Provider:     instance.Provider,
+			UserID:       instance.UserID,
+			Username:     user.Username,
+			Message:      message,
+			Timestamp:    timestamp,
+		}
+		body, err := json.Marshal(payload)
+		if err != nil {
+			global.APP_LOG.Error("序列化Webhook内容失败", zap.Error(err))
+			return
+		}
+
+		for _, hook := range targets {
+			go deliver(hook, event, body)
+		}
+	}()
+}
+
+// Sign 计算请求体的HMAC-SHA256签名
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// deliver 投递单个Webhook，非2xx响应或请求失败时按指数退避重试
+func deliver(hook adminModel.Webhook, event string, body []byte) {
+	client := utils.GetHTTPClientWithTimeout(deliveryTimeout)
+	deliveryID := uuid.New().String()
+	backoff := initialBackoff
+
+	var statusCode int
+	var lastErr error
+	for attempt := 0; attempt <= hook.MaxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+			if backoff > maxBackoff {
+				backoff = maxBackoff
+			}
+		}
+
+		statusCode, lastErr = post(client, hook, event, deliveryID, body)
+		if lastErr == nil {
+			break
+		}
+		global.APP_LOG.Warn("Webhook投递失败",
+			zap.Uint("webhookID", hook.ID),
+			zap.String("event", event),
+			zap.Int("attempt", attempt+1),
+			zap.Error(lastErr))
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{
+		"last_status_code":  statusCode,
+		"last_error":        "",
+		"last_triggered_at": &now,
+	}
+	if lastErr != nil {
+		updates["last_error"] = utils.TruncateString(lastErr.Error(), 500)
+	}
+	if err := global.APP_DB.Model(&adminModel.Webhook{}).Where("id = ?", hook.ID).Updates(updates).Error; err != nil {
+		global.APP_LOG.Warn("更新Webhook投递状态失败", zap.Uint("webhookID", hook.ID), zap.Error(err))
+	}
+}
+
+func post(client *http.Client, hook adminModel.Webhook, event, deliveryID string, body []byte) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "OneClickVirt-Webhook")
+	req.Header.Set(EventHeader, event)
+	req.Header.Set(DeliveryHeader, deliveryID)
+	req.Header.Set(SignatureHeader, Sign(hook.Secret, body))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("接收端返回状态码 %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,204 @@
+package webhook
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"oneclickvirt/global"
+	adminModel "oneclickvirt/model/admin"
+	"oneclickvirt/model/common"
+
+	"gorm.io/gorm"
+)
+
+// 支持的事件类型
+const (
+	EventInstanceCreate     = "instance.create"
+	EventInstanceStart      = "instance.start"
+	EventInstanceStop       = "instance.stop"
+	EventInstanceDelete     = "instance.delete"
+	EventTrafficQuotaExceed = "traffic.quota_exceeded"
+	// EventInstanceStatusDrift 宿主机上的实例状态与记录不一致（如实例崩溃或被手动启停），已按实际状态更新
+	EventInstanceStatusDrift = "instance.status_drift"
+)
+
+const (
+	defaultMaxRetries        = 3
+	generatedSecretByteCount = 32
+)
+
+// SupportedEvents 全部可订阅的事件
+var SupportedEvents = []string{
+	EventInstanceCreate,
+	EventInstanceStart,
+	EventInstanceStop,
+	EventInstanceDelete,
+	EventTrafficQuotaExceed,
+	EventInstanceStatusDrift,
+}
+
+// Service Webhook配置管理
+type Service struct{}
+
+// NewService 创建Webhook服务
+func NewService() *Service {
+	return &Service{}
+}
+
+// List 获取全部Webhook配置
+func (s *Service) List() ([]adminModel.Webhook, error) {
+	var hooks []adminModel.Webhook
+	if err := global.APP_DB.Order("id ASC").Find(&hooks).Error; err != nil {
+		return nil, fmt.Errorf("查询Webhook失败: %w", err)
+	}
+	return hooks, nil
+}
+
+// Create 创建Webhook，未指定密钥时自动生成
+func (s *Service) Create(req adminModel.CreateWebhookRequest) (*adminModel.CreateWebhookResponse, error) {
+	events, err := normalizeEvents(req.Events)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		if secret, err = generateSecret(); err != nil {
+			return nil, fmt.Errorf("生成签名密钥失败: %w", err)
+		}
+	}
+
+	hook := adminModel.Webhook{
+		Name:       req.Name,
+		URL:        req.URL,
+		Secret:     secret,
+		Events:     events,
+		Enabled:    true,
+		MaxRetries: defaultMaxRetries,
+	}
+	if req.Enabled != nil {
+		hook.Enabled = *req.Enabled
+	}
+	if req.MaxRetries != nil {
+		hook.MaxRetries = *req.MaxRetries
+	}
+
+	if err := global.APP_DB.Create(&hook).Error; err != nil {
+		return nil, fmt.Errorf("创建Webhook失败: %w", err)
+	}
+	// enabled 字段带有 default:true，零值不会写入，需要单独更新
+	if !hook.Enabled {
+		global.APP_DB.Model(&hook).Update("enabled", false)
+	}
+
+	return &adminModel.CreateWebhookResponse{Webhook: hook, Secret: secret}, nil
+}
+
+// Update 更新Webhook配置
+func (s *Service) Update(id uint, req adminModel.UpdateWebhookRequest) (*adminModel.Webhook, error) {
+	var hook adminModel.Webhook
+	if err := global.APP_DB.First(&hook, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, common.NewError(common.CodeNotFound, "Webhook不存在")
+		}
+		return nil, err
+	}
+
+	updates := map[string]interface{}{}
+	if req.Name != nil {
+		updates["name"] = *req.Name
+	}
+	if req.URL != nil {
+		updates["url"] = *req.URL
+	}
+	if req.Secret != nil {
+		updates["secret"] = *req.Secret
+	}
+	if req.Events != nil {
+		events, err := normalizeEvents(req.Events)
+		if err != nil {
+			return nil, err
+		}
+		updates["events"] = events
+	}
+	if req.Enabled != nil {
+		updates["enabled"] = *req.Enabled
+	}
+	if req.MaxRetries != nil {
+		updates["max_retries"] = *req.MaxRetries
+	}
+
+	if len(updates) > 0 {
+		if err := global.APP_DB.Model(&hook).Updates(updates).Error; err != nil {
+			return nil, fmt.Errorf("更新Webhook失败: %w", err)
+		}
+	}
+	if err := global.APP_DB.First(&hook, id).Error; err != nil {
+		return nil, err
+	}
+	return &hook, nil
+}
+
+// Delete 删除Webhook
+func (s *Service) Delete(id uint) error {
+	result := global.APP_DB.Delete(&adminModel.Webhook{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("删除Webhook失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return common.NewError(common.CodeNotFound, "Webhook不存在")
+	}
+	return nil
+}
+
+// normalizeEvents 校验并去重事件列表，返回逗号分隔的存储格式
+func normalizeEvents(events []string) (string, error) {
+	seen := make(map[string]bool, len(events))
+	var result []string
+	for _, event := range events {
+		event = strings.TrimSpace(event)
+		if event == "" || seen[event] {
+			continue
+		}
+		if !isSupportedEvent(event) {
+			return "", common.NewError(common.CodeValidationError,
+				fmt.Sprintf("不支持的事件类型: %s，可选: %s", event, strings.Join(SupportedEvents, ", ")))
+		}
+		seen[event] = true
+		result = append(result, event)
+	}
+	return strings.Join(result, ","), nil
+}
+
+func isSupportedEvent(event string) bool {
+	for _, e := range SupportedEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// subscribes 判断Webhook是否订阅了指定事件
+func subscribes(hook adminModel.Webhook, event string) bool {
+	if strings.TrimSpace(hook.Events) == "" {
+		return true
+	}
+	for _, e := range strings.Split(hook.Events, ",") {
+		if strings.TrimSpace(e) == event {
+			return true
+		}
+	}
+	return false
+}
+
+func generateSecret() (string, error) {
+	buf := make([]byte, generatedSecretByteCount)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
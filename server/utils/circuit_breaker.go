@@ -0,0 +1,186 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"oneclickvirt/global"
+
+	"go.uber.org/zap"
+)
+
+// 熔断器状态
+const (
+	CircuitClosed   = "closed"    // 正常放行
+	CircuitOpen     = "open"      // 熔断中，直接失败
+	CircuitHalfOpen = "half_open" // 冷却结束，放行一条探测命令
+)
+
+const (
+	circuitTimeoutThreshold = 5                // 窗口内连续超时次数达到该值时熔断
+	circuitTimeoutWindow    = 10 * time.Minute // 连续超时的统计窗口
+	circuitCooldown         = 60 * time.Second // 熔断后的冷却时间
+)
+
+// ErrCircuitOpen 节点SSH熔断期间执行命令返回的错误，可通过 errors.Is 判断
+var ErrCircuitOpen = errors.New("SSH circuit breaker open")
+
+// CircuitOpenError 熔断期间快速失败的错误，包含节点地址和预计恢复探测的时间
+type CircuitOpenError struct {
+	Address string
+	RetryAt time.Time
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("节点 %s 的SSH命令连续超时，已熔断，%s 后重试",
+		e.Address, time.Until(e.RetryAt).Round(time.Second))
+}
+
+func (e *CircuitOpenError) Unwrap() error {
+	return ErrCircuitOpen
+}
+
+// CircuitBreakerStatus 熔断器状态快照
+type CircuitBreakerStatus struct {
+	State               string     `json:"state"`
+	ConsecutiveTimeouts int        `json:"consecutiveTimeouts"`
+	OpenedAt            *time.Time `json:"openedAt,omitempty"`
+	RetryAt             *time.Time `json:"retryAt,omitempty"`
+	TotalTrips          int        `json:"totalTrips"`
+}
+
+// CircuitBreaker 单个节点的SSH命令熔断器
+// 窗口内连续超时达到阈值后熔断，冷却期内所有命令直接失败；冷却结束后放行一条探测命令，
+// 探测成功则恢复，再次超时则重新熔断。命令非零退出说明节点仍有响应，视为成功
+type CircuitBreaker struct {
+	mu                  sync.Mutex
+	address             string
+	state               string
+	consecutiveTimeouts int
+	firstTimeoutAt      time.Time
+	openedAt            time.Time
+	probeStartedAt      time.Time
+	totalTrips          int
+}
+
+var (
+	circuitBreakers   = make(map[string]*CircuitBreaker)
+	circuitBreakersMu sync.Mutex
+)
+
+// GetCircuitBreaker 返回节点地址对应的熔断器，同一节点的所有SSH连接共享
+func GetCircuitBreaker(address string) *CircuitBreaker {
+	circuitBreakersMu.Lock()
+	defer circuitBreakersMu.Unlock()
+	breaker, ok := circuitBreakers[address]
+	if !ok {
+		breaker = &CircuitBreaker{address: address, state: CircuitClosed}
+		circuitBreakers[address] = breaker
+	}
+	return breaker
+}
+
+// GetSSHCircuitBreakerStatus 返回指定节点的熔断器状态，节点尚未执行过命令时为closed
+func GetSSHCircuitBreakerStatus(host string, port int) CircuitBreakerStatus {
+	return GetCircuitBreaker(sshAddress(SSHConfig{Host: host, Port: port})).Status()
+}
+
+// Allow 判断是否放行命令，熔断中返回 *CircuitOpenError
+func (b *CircuitBreaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	switch b.state {
+	case CircuitOpen:
+		retryAt := b.openedAt.Add(circuitCooldown)
+		if now.Before(retryAt) {
+			return &CircuitOpenError{Address: b.address, RetryAt: retryAt}
+		}
+		b.state = CircuitHalfOpen
+		b.probeStartedAt = now
+		global.APP_LOG.Info("SSH熔断冷却结束，放行探测命令", zap.String("address", b.address))
+		return nil
+	case CircuitHalfOpen:
+		// 只放行一条探测命令；探测命令长时间无结果时允许重新探测
+		if now.Sub(b.probeStartedAt) < circuitCooldown {
+			return &CircuitOpenError{Address: b.address, RetryAt: b.probeStartedAt.Add(circuitCooldown)}
+		}
+		b.probeStartedAt = now
+	}
+	return nil
+}
+
+// Record 记录命令执行结果，只有超时和连接失败计入熔断
+// 被管理员取消的命令无法说明节点是否有响应，不改变熔断状态
+func (b *CircuitBreaker) Record(err error) {
+	if errors.Is(err, ErrSSHCommandCancelled) {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !isUnresponsiveError(err) {
+		if b.state != CircuitClosed {
+			global.APP_LOG.Info("SSH探测命令成功，熔断恢复", zap.String("address", b.address))
+		}
+		b.state = CircuitClosed
+		b.consecutiveTimeouts = 0
+		return
+	}
+
+	now := time.Now()
+	if b.state == CircuitHalfOpen {
+		b.trip(now)
+		return
+	}
+	if b.consecutiveTimeouts == 0 || now.Sub(b.firstTimeoutAt) > circuitTimeoutWindow {
+		b.consecutiveTimeouts = 0
+		b.firstTimeoutAt = now
+	}
+	b.consecutiveTimeouts++
+	if b.state == CircuitClosed && b.consecutiveTimeouts >= circuitTimeoutThreshold {
+		b.trip(now)
+	}
+}
+
+func (b *CircuitBreaker) trip(now time.Time) {
+	b.state = CircuitOpen
+	b.openedAt = now
+	b.totalTrips++
+	global.APP_LOG.Warn("SSH命令连续超时，节点熔断",
+		zap.String("address", b.address),
+		zap.Int("consecutiveTimeouts", b.consecutiveTimeouts),
+		zap.Duration("cooldown", circuitCooldown))
+}
+
+// Status 返回熔断器状态快照
+func (b *CircuitBreaker) Status() CircuitBreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	status := CircuitBreakerStatus{
+		State:               b.state,
+		ConsecutiveTimeouts: b.consecutiveTimeouts,
+		TotalTrips:          b.totalTrips,
+	}
+	if b.state != CircuitClosed {
+		openedAt := b.openedAt
+		retryAt := b.openedAt.Add(circuitCooldown)
+		status.OpenedAt = &openedAt
+		status.RetryAt = &retryAt
+	}
+	return status
+}
+
+// isUnresponsiveError 判断错误是否说明节点无响应（执行超时或无法重新连接）
+func isUnresponsiveError(err error) bool {
+	if err == nil || errors.Is(err, ErrCircuitOpen) {
+		return false
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "timeout") || strings.Contains(msg, "failed to reconnect")
+}
@@ -0,0 +1,124 @@
+package utils
+
+import (
+	"errors"
+	"regexp"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// 每个Provider保留的最近命令数量及记录中命令和输出的最大长度
+const (
+	commandLogCapacity  = 200
+	commandLogMaxLength = 1024
+	commandLogMaxOutput = 2048
+)
+
+// CommandLogEntry 一条SSH命令执行记录，命令中的密码已脱敏，命令和输出均已截断
+type CommandLogEntry struct {
+	Time       time.Time `json:"time"`
+	Tag        string    `json:"tag,omitempty"` // ExecuteWithLogging传入的日志前缀，如DOCKER_LIST
+	Command    string    `json:"command"`
+	ExitCode   int       `json:"exitCode"` // 命令退出码，超时、连接失败等未取得退出码时为-1
+	Error      string    `json:"error,omitempty"`
+	Output     string    `json:"output,omitempty"`
+	DurationMs int64     `json:"durationMs"`
+}
+
+// commandLogRing 固定容量的环形缓冲区，写满后覆盖最早的记录
+type commandLogRing struct {
+	mu      sync.Mutex
+	entries []CommandLogEntry
+	next    int
+}
+
+// commandLogs Provider ID到命令记录的映射，仅保存在内存中，服务重启后清空
+var commandLogs sync.Map
+
+// 匹配常见的密码设置方式：echo 'root:xxx' | chpasswd、--cipassword xxx、--password xxx、PASSWORD=xxx
+var commandSecretPatterns = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile(`([\w.-]+:)[^\s'"|]+(['"]?\s*\|\s*chpasswd)`), "${1}******${2}"},
+	{regexp.MustCompile(`(?i)(--(?:ci)?password[= ]\s*)('[^']*'|"[^"]*"|\S+)`), "${1}******"},
+	{regexp.MustCompile(`(?i)(\b\w*(?:password|passwd|secret|token)=)('[^']*'|"[^"]*"|\S+)`), "${1}******"},
+}
+
+// RedactCommand 隐去命令中的密码等敏感参数
+func RedactCommand(command string) string {
+	for _, secret := range commandSecretPatterns {
+		command = secret.pattern.ReplaceAllString(command, secret.replacement)
+	}
+	return command
+}
+
+// RecordCommand 记录一条命令执行结果，providerID为0时不记录
+func RecordCommand(providerID uint, tag, command, output string, exitCode int, err error, duration time.Duration) {
+	if providerID == 0 {
+		return
+	}
+	entry := CommandLogEntry{
+		Time:       time.Now(),
+		Tag:        tag,
+		Command:    TruncateString(RedactCommand(command), commandLogMaxLength),
+		ExitCode:   exitCode,
+		Output:     TruncateString(output, commandLogMaxOutput),
+		DurationMs: duration.Milliseconds(),
+	}
+	if err != nil {
+		entry.Error = TruncateString(err.Error(), commandLogMaxLength)
+	}
+
+	value, _ := commandLogs.LoadOrStore(providerID, &commandLogRing{})
+	ring := value.(*commandLogRing)
+	ring.mu.Lock()
+	defer ring.mu.Unlock()
+	if len(ring.entries) < commandLogCapacity {
+		ring.entries = append(ring.entries, entry)
+	} else {
+		ring.entries[ring.next] = entry
+	}
+	ring.next = (ring.next + 1) % commandLogCapacity
+}
+
+// GetCommandLog 返回Provider最近执行的命令，按时间倒序，limit不大于0时返回全部
+func GetCommandLog(providerID uint, limit int) []CommandLogEntry {
+	result := []CommandLogEntry{}
+	value, ok := commandLogs.Load(providerID)
+	if !ok {
+		return result
+	}
+	ring := value.(*commandLogRing)
+	ring.mu.Lock()
+	defer ring.mu.Unlock()
+
+	count := len(ring.entries)
+	if limit > 0 && limit < count {
+		count = limit
+	}
+	for i := 1; i <= count; i++ {
+		idx := (ring.next - i + len(ring.entries)) % len(ring.entries)
+		result = append(result, ring.entries[idx])
+	}
+	return result
+}
+
+// ClearCommandLog 清空Provider的命令记录
+func ClearCommandLog(providerID uint) {
+	commandLogs.Delete(providerID)
+}
+
+// commandExitCode 从执行错误中取得退出码：成功为0，远端以非零状态退出时为其状态码，其他错误为-1
+func commandExitCode(err error) int {
+	if err == nil || errors.Is(err, ErrOutputTruncated) {
+		return 0
+	}
+	var exitErr *ssh.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitStatus()
+	}
+	return -1
+}
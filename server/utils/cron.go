@@ -0,0 +1,187 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" // 保证精简镜像中也能按IANA时区名加载时区
+)
+
+// CronExpression 标准5字段cron表达式：分 时 日 月 周
+// 支持 *、数值、范围（1-5）、步长（*/15、0-30/10）和逗号列表，周字段0和7均表示周日
+type CronExpression struct {
+	minutes  [60]bool
+	hours    [24]bool
+	days     [32]bool
+	months   [13]bool
+	weekdays [7]bool
+
+	// 日和周字段都被限制时按标准cron语义取并集，任一字段为 * 时只看另一个字段
+	dayRestricted     bool
+	weekdayRestricted bool
+}
+
+type cronField struct {
+	name     string
+	min, max int
+}
+
+var cronFields = [5]cronField{
+	{"分钟", 0, 59},
+	{"小时", 0, 23},
+	{"日期", 1, 31},
+	{"月份", 1, 12},
+	{"星期", 0, 7},
+}
+
+// ParseCronExpression 解析5字段cron表达式
+func ParseCronExpression(expr string) (*CronExpression, error) {
+	parts := strings.Fields(expr)
+	if len(parts) != 5 {
+		return nil, fmt.Errorf("cron表达式必须包含5个字段（分 时 日 月 周）: %q", expr)
+	}
+
+	c := &CronExpression{}
+	for i, part := range parts {
+		values, err := parseCronField(part, cronFields[i])
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range values {
+			switch i {
+			case 0:
+				c.minutes[v] = true
+			case 1:
+				c.hours[v] = true
+			case 2:
+				c.days[v] = true
+			case 3:
+				c.months[v] = true
+			case 4:
+				c.weekdays[v%7] = true
+			}
+		}
+	}
+	c.dayRestricted = parts[2] != "*"
+	c.weekdayRestricted = parts[4] != "*"
+	if c.dayRestricted && !c.weekdayRestricted && !c.hasValidDate() {
+		return nil, fmt.Errorf("日期与月份组合永远不会出现: %q", expr)
+	}
+	return c, nil
+}
+
+// cronMaxDays 每月最大天数，2月按闰年计
+var cronMaxDays = [13]int{0, 31, 29, 31, 30, 31, 30, 31, 31, 30, 31, 30, 31}
+
+// hasValidDate 判断日期和月份字段是否至少组合出一个真实存在的日期，如 31 2 永远不会触发
+func (c *CronExpression) hasValidDate() bool {
+	for month := 1; month <= 12; month++ {
+		if !c.months[month] {
+			continue
+		}
+		for day := 1; day <= cronMaxDays[month]; day++ {
+			if c.days[day] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func parseCronField(part string, field cronField) ([]int, error) {
+	var values []int
+	for _, item := range strings.Split(part, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("%s字段步长无效: %q", field.name, item)
+			}
+			step = n
+		}
+
+		start, end := field.min, field.max
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			from, to, _ := strings.Cut(rangePart, "-")
+			var err1, err2 error
+			start, err1 = strconv.Atoi(from)
+			end, err2 = strconv.Atoi(to)
+			if err1 != nil || err2 != nil || start > end {
+				return nil, fmt.Errorf("%s字段范围无效: %q", field.name, item)
+			}
+		default:
+			n, err := strconv.Atoi(rangePart)
+			if err != nil {
+				return nil, fmt.Errorf("%s字段值无效: %q", field.name, item)
+			}
+			start = n
+			end = n
+			if hasStep {
+				end = field.max
+			}
+		}
+		if start < field.min || end > field.max {
+			return nil, fmt.Errorf("%s字段超出范围 %d-%d: %q", field.name, field.min, field.max, item)
+		}
+		for v := start; v <= end; v += step {
+			values = append(values, v)
+		}
+	}
+	return values, nil
+}
+
+// Matches 判断时间（按其自身时区）是否命中表达式，精确到分钟
+func (c *CronExpression) Matches(t time.Time) bool {
+	if !c.minutes[t.Minute()] || !c.hours[t.Hour()] || !c.months[int(t.Month())] {
+		return false
+	}
+	dayMatch := c.days[t.Day()]
+	weekdayMatch := c.weekdays[int(t.Weekday())]
+	switch {
+	case c.dayRestricted && c.weekdayRestricted:
+		return dayMatch || weekdayMatch
+	case c.dayRestricted:
+		return dayMatch
+	case c.weekdayRestricted:
+		return weekdayMatch
+	default:
+		return true
+	}
+}
+
+// cronSearchLimit 查找下次触发时间的最大范围，覆盖闰年2月29日等稀疏表达式
+const cronSearchLimit = 366 * 24 * 60 * 5
+
+// Next 返回 after 之后（不含）的下一次触发时间，使用 after 的时区；一定范围内无触发时返回零值
+func (c *CronExpression) Next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	for i := 0; i < cronSearchLimit; i++ {
+		if c.Matches(t) {
+			return t
+		}
+		t = t.Add(time.Minute)
+	}
+	return time.Time{}
+}
+
+// MinInterval 返回从 from 开始一周内相邻两次触发的最小间隔，一周内触发不足两次时返回一周
+func (c *CronExpression) MinInterval(from time.Time) time.Duration {
+	week := 7 * 24 * time.Hour
+	minGap := week
+	end := from.Add(week)
+	var last time.Time
+	for t := from.Truncate(time.Minute); t.Before(end); t = t.Add(time.Minute) {
+		if !c.Matches(t) {
+			continue
+		}
+		if !last.IsZero() && t.Sub(last) < minGap {
+			minGap = t.Sub(last)
+		}
+		last = t
+	}
+	return minGap
+}
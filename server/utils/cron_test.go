@@ -0,0 +1,176 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+// TestParseCronExpression 测试cron语法解析，包括永远不会出现的日期月份组合
+func TestParseCronExpression(t *testing.T) {
+	tests := []struct {
+		name    string
+		expr    string
+		wantErr bool
+	}{
+		{name: "全部通配", expr: "* * * * *"},
+		{name: "工作日早上9点", expr: "0 9 * * 1-5"},
+		{name: "步长与列表", expr: "*/15 0-12/4 1,15 * 0,7"},
+		{name: "单值步长", expr: "5/20 * * * *"},
+		{name: "多余空白", expr: "  0   9 * *   *  "},
+		{name: "闰年2月29日", expr: "0 0 29 2 *"},
+		{name: "2月31日但限制星期", expr: "0 0 31 2 1"},
+		{name: "字段不足", expr: "0 9 * *", wantErr: true},
+		{name: "字段过多", expr: "0 9 * * * *", wantErr: true},
+		{name: "分钟超出范围", expr: "60 * * * *", wantErr: true},
+		{name: "小时超出范围", expr: "0 24 * * *", wantErr: true},
+		{name: "日期为0", expr: "0 0 0 * *", wantErr: true},
+		{name: "月份超出范围", expr: "0 0 1 13 *", wantErr: true},
+		{name: "星期超出范围", expr: "0 0 * * 8", wantErr: true},
+		{name: "反向范围", expr: "0 10-5 * * *", wantErr: true},
+		{name: "步长为0", expr: "*/0 * * * *", wantErr: true},
+		{name: "非数字", expr: "a * * * *", wantErr: true},
+		{name: "2月31日", expr: "0 0 31 2 *", wantErr: true},
+		{name: "小月31日", expr: "0 0 31 4,6,9,11 *", wantErr: true},
+		{name: "2月30日和31日", expr: "0 0 30-31 2 *", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseCronExpression(tt.expr)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseCronExpression(%q) error = %v, wantErr %v", tt.expr, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestCronExpressionMatches 测试按分钟匹配，日期和星期同时限制时取并集
+func TestCronExpressionMatches(t *testing.T) {
+	// 2024-01-01 是周一
+	tests := []struct {
+		name string
+		expr string
+		time time.Time
+		want bool
+	}{
+		{name: "通配命中", expr: "* * * * *", time: time.Date(2024, 1, 1, 13, 37, 0, 0, time.UTC), want: true},
+		{name: "工作日命中", expr: "0 9 * * 1-5", time: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC), want: true},
+		{name: "周末不命中", expr: "0 9 * * 1-5", time: time.Date(2024, 1, 6, 9, 0, 0, 0, time.UTC), want: false},
+		{name: "分钟不命中", expr: "0 9 * * 1-5", time: time.Date(2024, 1, 1, 9, 1, 0, 0, time.UTC), want: false},
+		{name: "步长命中", expr: "*/15 * * * *", time: time.Date(2024, 1, 1, 0, 45, 0, 0, time.UTC), want: true},
+		{name: "步长不命中", expr: "*/15 * * * *", time: time.Date(2024, 1, 1, 0, 50, 0, 0, time.UTC), want: false},
+		{name: "7表示周日", expr: "0 0 * * 7", time: time.Date(2024, 1, 7, 0, 0, 0, 0, time.UTC), want: true},
+		{name: "0表示周日", expr: "0 0 * * 0", time: time.Date(2024, 1, 7, 0, 0, 0, 0, time.UTC), want: true},
+		{name: "日期与星期取并集-日期命中", expr: "0 0 15 * 1", time: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), want: true},
+		{name: "日期与星期取并集-星期命中", expr: "0 0 15 * 1", time: time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC), want: true},
+		{name: "日期与星期取并集-都不命中", expr: "0 0 15 * 1", time: time.Date(2024, 1, 9, 0, 0, 0, 0, time.UTC), want: false},
+		{name: "仅限制日期", expr: "0 0 15 * *", time: time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC), want: false},
+		{name: "月份不命中", expr: "0 0 1 2 *", time: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), want: false},
+		{name: "按时间自身时区判断", expr: "0 9 * * *", time: time.Date(2024, 1, 1, 9, 0, 0, 0, time.FixedZone("UTC+8", 8*3600)), want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := ParseCronExpression(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseCronExpression(%q) 失败: %v", tt.expr, err)
+			}
+			if got := c.Matches(tt.time); got != tt.want {
+				t.Errorf("Matches(%v) = %v, 期望 %v", tt.time, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestCronExpressionNext 测试查找下次触发时间
+func TestCronExpressionNext(t *testing.T) {
+	tests := []struct {
+		name  string
+		expr  string
+		after time.Time
+		want  time.Time
+	}{
+		{
+			name:  "下一分钟",
+			expr:  "* * * * *",
+			after: time.Date(2024, 1, 1, 10, 0, 30, 0, time.UTC),
+			want:  time.Date(2024, 1, 1, 10, 1, 0, 0, time.UTC),
+		},
+		{
+			name:  "不包含当前时间",
+			expr:  "0 9 * * *",
+			after: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC),
+			want:  time.Date(2024, 1, 2, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "跳过周末",
+			expr:  "0 9 * * 1-5",
+			after: time.Date(2024, 1, 5, 10, 0, 0, 0, time.UTC),
+			want:  time.Date(2024, 1, 8, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "跨年",
+			expr:  "0 0 1 1 *",
+			after: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+			want:  time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "跳过没有31日的月份",
+			expr:  "0 0 31 * *",
+			after: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+			want:  time.Date(2024, 5, 31, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "闰年2月29日",
+			expr:  "0 0 29 2 *",
+			after: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			want:  time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "使用after的时区",
+			expr:  "30 8 * * *",
+			after: time.Date(2024, 1, 1, 12, 0, 0, 0, time.FixedZone("UTC+8", 8*3600)),
+			want:  time.Date(2024, 1, 2, 8, 30, 0, 0, time.FixedZone("UTC+8", 8*3600)),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := ParseCronExpression(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseCronExpression(%q) 失败: %v", tt.expr, err)
+			}
+			if got := c.Next(tt.after); !got.Equal(tt.want) {
+				t.Errorf("Next(%v) = %v, 期望 %v", tt.after, got, tt.want)
+			}
+		})
+	}
+
+	t.Run("永不触发返回零值", func(t *testing.T) {
+		if got := (&CronExpression{}).Next(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)); !got.IsZero() {
+			t.Errorf("Next() = %v, 期望零值", got)
+		}
+	})
+}
+
+// TestCronExpressionMinInterval 测试一周内相邻两次触发的最小间隔
+func TestCronExpressionMinInterval(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		expr string
+		want time.Duration
+	}{
+		{expr: "*/15 * * * *", want: 15 * time.Minute},
+		{expr: "0 9,12 * * *", want: 3 * time.Hour},
+		{expr: "0 9 * * 1", want: 7 * 24 * time.Hour},
+	}
+	for _, tt := range tests {
+		c, err := ParseCronExpression(tt.expr)
+		if err != nil {
+			t.Fatalf("ParseCronExpression(%q) 失败: %v", tt.expr, err)
+		}
+		if got := c.MinInterval(from); got != tt.want {
+			t.Errorf("MinInterval(%q) = %v, 期望 %v", tt.expr, got, tt.want)
+		}
+	}
+}
@@ -3,7 +3,14 @@ package utils
 import (
 	"fmt"
 	"math/rand"
+	"regexp"
 	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"oneclickvirt/global"
+
+	"golang.org/x/crypto/ssh"
 )
 
 // GenerateInstanceName 生成实例名称（全局统一函数）
@@ -11,9 +18,126 @@ import (
 func GenerateInstanceName(providerName string) string {
 	randomStr := fmt.Sprintf("%04x", rand.Intn(65536)) // 生成4位16进制随机字符
 
-	// 清理provider名称，移除特殊字符
-	cleanName := strings.ReplaceAll(strings.ToLower(providerName), " ", "-")
-	cleanName = strings.ReplaceAll(cleanName, "_", "-")
+	return fmt.Sprintf("%s-%s", sanitizeInstanceNamePrefix(providerName), randomStr)
+}
+
+// sanitizeInstanceNamePrefix 清理provider名称，实例名称会拼接到远程命令中，
+// 只保留小写字母和数字，其余字符（空格、下划线、shell元字符、非ASCII字符等）替换为连字符并合并
+func sanitizeInstanceNamePrefix(providerName string) string {
+	var b strings.Builder
+	lastDash := true
+	for _, r := range strings.ToLower(providerName) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			lastDash = false
+		} else if !lastDash {
+			b.WriteByte('-')
+			lastDash = true
+		}
+	}
+	if cleanName := strings.TrimSuffix(b.String(), "-"); cleanName != "" {
+		return cleanName
+	}
+	return "instance"
+}
+
+const (
+	MaxInstanceTags        = 20
+	MaxInstanceTagValueLen = 255
+)
+
+// instanceTagKeyPattern 标签键需同时可用作Docker label键、LXD/Incus配置键和MySQL JSON路径
+var instanceTagKeyPattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]{0,62}$`)
+
+// ValidateInstanceTagKey 校验实例标签键
+func ValidateInstanceTagKey(key string) error {
+	if !instanceTagKeyPattern.MatchString(key) {
+		return fmt.Errorf("标签键 %q 无效：只能包含字母、数字、下划线、点和连字符，且不超过63个字符", key)
+	}
+	return nil
+}
+
+// ValidateInstanceTags 校验实例标签
+// 标签值会以单引号包裹写入远程命令，因此不允许包含单引号和控制字符
+func ValidateInstanceTags(tags map[string]string) error {
+	if len(tags) > MaxInstanceTags {
+		return fmt.Errorf("标签数量不能超过%d个", MaxInstanceTags)
+	}
+	for key, value := range tags {
+		if err := ValidateInstanceTagKey(key); err != nil {
+			return err
+		}
+		if utf8.RuneCountInString(value) > MaxInstanceTagValueLen {
+			return fmt.Errorf("标签 %s 的值不能超过%d个字符", key, MaxInstanceTagValueLen)
+		}
+		if strings.ContainsRune(value, '\'') || strings.IndexFunc(value, unicode.IsControl) >= 0 {
+			return fmt.Errorf("标签 %s 的值不能包含单引号或控制字符", key)
+		}
+	}
+	return nil
+}
+
+// MaxInstanceSSHKeys 创建实例时可指定的SSH公钥数量上限
+const MaxInstanceSSHKeys = 10
+
+// NormalizeSSHPublicKeys 校验SSH公钥并规范化为authorized_keys格式，忽略空行
+func NormalizeSSHPublicKeys(keys []string) ([]string, error) {
+	var result []string
+	for _, key := range keys {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+		parsed, comment, _, rest, err := ssh.ParseAuthorizedKey([]byte(key))
+		if err != nil || len(strings.TrimSpace(string(rest))) > 0 {
+			return nil, fmt.Errorf("无效的SSH公钥: %s", TruncateString(key, 32))
+		}
+		line := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(parsed)))
+		if comment != "" && strings.IndexFunc(comment, unicode.IsControl) < 0 {
+			line += " " + comment
+		}
+		result = append(result, line)
+	}
+	if len(result) > MaxInstanceSSHKeys {
+		return nil, fmt.Errorf("SSH公钥数量不能超过%d个", MaxInstanceSSHKeys)
+	}
+	return result, nil
+}
+
+// InstanceTTLPolicy 实例有效期策略（单位：小时）
+type InstanceTTLPolicy struct {
+	MaxTTLHours    int // 申请时可设置的最长有效期，以及续期后距当前的最长有效期
+	ExtendMaxHours int // 单次续期的最长时长
+	NotifyHours    int // 到期前多少小时通知用户
+}
+
+// GetInstanceTTLPolicy 读取实例有效期策略，未配置的项使用默认值
+func GetInstanceTTLPolicy() InstanceTTLPolicy {
+	quota := global.APP_CONFIG.Quota
+	policy := InstanceTTLPolicy{
+		MaxTTLHours:    quota.InstanceMaxTTLHours,
+		ExtendMaxHours: quota.InstanceExtendMaxHours,
+		NotifyHours:    quota.InstanceExpireNotifyHours,
+	}
+	if policy.MaxTTLHours <= 0 {
+		policy.MaxTTLHours = 720
+	}
+	if policy.ExtendMaxHours <= 0 {
+		policy.ExtendMaxHours = 168
+	}
+	if policy.NotifyHours <= 0 {
+		policy.NotifyHours = 24
+	}
+	return policy
+}
 
-	return fmt.Sprintf("%s-%s", cleanName, randomStr)
+// ValidateInstanceTTLHours 校验申请实例时指定的有效期，0表示不指定
+func ValidateInstanceTTLHours(hours int) error {
+	if hours < 0 {
+		return fmt.Errorf("实例有效期不能为负数")
+	}
+	if maxHours := GetInstanceTTLPolicy().MaxTTLHours; hours > maxHours {
+		return fmt.Errorf("实例有效期不能超过%d小时", maxHours)
+	}
+	return nil
 }
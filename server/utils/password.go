@@ -3,11 +3,14 @@ package utils
 import (
 	"crypto/rand"
 	"fmt"
+	"math"
 	"math/big"
 	"regexp"
 	"strings"
 	"time"
 	"unicode"
+
+	"oneclickvirt/global"
 )
 
 // PasswordStrengthConfig 密码强度配置
@@ -194,37 +197,135 @@ func GenerateStrongPassword(length int) string {
 	return string(password)
 }
 
-// GenerateInstancePassword 为容器/虚拟机生成随机密码（小写英文开头，后面随机小写英文和数字混合，长度不低于8位）
-func GenerateInstancePassword() string {
-	const (
-		lowercase = "abcdefghijklmnopqrstuvwxyz"
-		digits    = "0123456789"
-	)
+// 生成密码使用的字符集，特殊字符只包含写入chpasswd等命令时无需转义的字符
+const (
+	passwordLowercase = "abcdefghijklmnopqrstuvwxyz"
+	passwordUppercase = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	passwordDigits    = "0123456789"
+	passwordSpecial   = "@#%^*_+=,.:-"
+	passwordAmbiguous = "0Oo1lI"
+
+	// DefaultGeneratedPasswordLength 未配置长度时生成密码的默认长度
+	DefaultGeneratedPasswordLength = 12
+	// MinGeneratedPasswordLength 生成密码的最小长度
+	MinGeneratedPasswordLength = 8
+	// MaxGeneratedPasswordLength 生成密码的最大长度
+	MaxGeneratedPasswordLength = 64
+)
 
-	all := lowercase + digits
-	length := 12 // 默认12位长度
-	password := make([]byte, length)
+// passwordCharsetPattern 用户指定的密码会写入节点上的chpasswd等命令，只允许不需要转义的字符
+var passwordCharsetPattern = regexp.MustCompile(`^[A-Za-z0-9@#%^*_+=,.:-]+$`)
 
-	// 确保首字符是小写英文字母
-	password[0] = lowercase[secureRandInt(len(lowercase))]
+// GeneratedPasswordPolicy 系统生成密码的策略，用于实例密码和重置后的账户密码
+// 小写字母和数字始终包含，首字符固定为小写字母
+type GeneratedPasswordPolicy struct {
+	Length           int  // 密码长度
+	UpperCase        bool // 包含大写字母
+	Special          bool // 包含特殊字符
+	ExcludeAmbiguous bool // 排除易混淆字符（0 O o 1 l I）
+}
 
-	// 确保至少包含一个数字（从第二个位置开始）
-	password[1] = digits[secureRandInt(len(digits))]
+// CurrentPasswordPolicy 读取当前配置的生成密码策略
+func CurrentPasswordPolicy() GeneratedPasswordPolicy {
+	quota := global.APP_CONFIG.Quota
+	return GeneratedPasswordPolicy{
+		Length:           quota.GeneratedPasswordLength,
+		UpperCase:        quota.GeneratedPasswordUpperCase,
+		Special:          quota.GeneratedPasswordSpecial,
+		ExcludeAmbiguous: quota.GeneratedPasswordNoAmbiguous,
+	}.Normalize()
+}
+
+// Normalize 将长度限制在允许范围内，未配置时使用默认长度
+func (p GeneratedPasswordPolicy) Normalize() GeneratedPasswordPolicy {
+	switch {
+	case p.Length == 0:
+		p.Length = DefaultGeneratedPasswordLength
+	case p.Length < MinGeneratedPasswordLength:
+		p.Length = MinGeneratedPasswordLength
+	case p.Length > MaxGeneratedPasswordLength:
+		p.Length = MaxGeneratedPasswordLength
+	}
+	return p
+}
 
-	// 填充其余位置（从第三个位置开始）
-	for i := 2; i < length; i++ {
+// charClasses 返回策略要求的字符类别，每类至少出现一次；第一个类别为小写字母
+func (p GeneratedPasswordPolicy) charClasses() []string {
+	classes := []string{passwordLowercase, passwordDigits}
+	if p.UpperCase {
+		classes = append(classes, passwordUppercase)
+	}
+	if p.Special {
+		classes = append(classes, passwordSpecial)
+	}
+	if p.ExcludeAmbiguous {
+		for i, class := range classes {
+			classes[i] = strings.Map(func(r rune) rune {
+				if strings.ContainsRune(passwordAmbiguous, r) {
+					return -1
+				}
+				return r
+			}, class)
+		}
+	}
+	return classes
+}
+
+// Alphabet 返回生成密码使用的全部字符
+func (p GeneratedPasswordPolicy) Alphabet() string {
+	return strings.Join(p.charClasses(), "")
+}
+
+// EntropyBits 估算按策略生成的密码熵（位），即 长度*log2(字符集大小)
+func (p GeneratedPasswordPolicy) EntropyBits() float64 {
+	p = p.Normalize()
+	return float64(p.Length) * math.Log2(float64(len(p.Alphabet())))
+}
+
+// GeneratePassword 按策略生成随机密码：首字符为小写字母，每类字符至少出现一次
+func GeneratePassword(policy GeneratedPasswordPolicy) string {
+	policy = policy.Normalize()
+	classes := policy.charClasses()
+	all := strings.Join(classes, "")
+
+	password := make([]byte, policy.Length)
+	for i, class := range classes {
+		password[i] = class[secureRandInt(len(class))]
+	}
+	for i := len(classes); i < len(password); i++ {
 		password[i] = all[secureRandInt(len(all))]
 	}
 
 	// 打乱第二位开始的字符（保持首字符为小写英文）
 	for i := len(password) - 1; i > 1; i-- {
-		j := secureRandInt(i-1) + 1 // 确保j不会是0，保护首字符
+		j := secureRandInt(i) + 1
 		password[i], password[j] = password[j], password[i]
 	}
-
 	return string(password)
 }
 
+// ValidatePasswordPolicy 校验用户指定的密码是否满足生成密码策略的长度和字符类别要求
+// 不要求排除易混淆字符，但只允许生成密码字符集中的字符，并拒绝常见弱密码
+func ValidatePasswordPolicy(password string, policy GeneratedPasswordPolicy) error {
+	policy = policy.Normalize()
+	if !passwordCharsetPattern.MatchString(password) {
+		return fmt.Errorf("密码只能包含字母、数字和 %s 字符", passwordSpecial)
+	}
+	return ValidatePasswordStrength(password, PasswordStrengthConfig{
+		MinLength:        policy.Length,
+		RequireUpperCase: policy.UpperCase,
+		RequireLowerCase: true,
+		RequireDigit:     true,
+		RequireSpecial:   policy.Special,
+		ForbidCommon:     true,
+	})
+}
+
+// GenerateInstancePassword 按当前配置的密码策略为容器/虚拟机生成随机密码
+func GenerateInstancePassword() string {
+	return GeneratePassword(CurrentPasswordPolicy())
+}
+
 // secureRandInt 安全随机数生成
 func secureRandInt(max int) int {
 	if max <= 0 {
@@ -0,0 +1,148 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"oneclickvirt/global"
+)
+
+// TestGeneratePassword 测试生成的密码满足策略的长度和字符类别要求
+func TestGeneratePassword(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   GeneratedPasswordPolicy
+		expected int
+	}{
+		{name: "默认策略", policy: GeneratedPasswordPolicy{Length: 12, UpperCase: true}, expected: 12},
+		{name: "未配置长度", policy: GeneratedPasswordPolicy{}, expected: DefaultGeneratedPasswordLength},
+		{name: "长度低于下限", policy: GeneratedPasswordPolicy{Length: 4, UpperCase: true, Special: true}, expected: MinGeneratedPasswordLength},
+		{name: "最小长度全部字符类别", policy: GeneratedPasswordPolicy{Length: 8, UpperCase: true, Special: true, ExcludeAmbiguous: true}, expected: 8},
+		{name: "排除易混淆字符", policy: GeneratedPasswordPolicy{Length: 16, UpperCase: true, ExcludeAmbiguous: true}, expected: 16},
+		{name: "长度超过上限", policy: GeneratedPasswordPolicy{Length: 128, Special: true}, expected: MaxGeneratedPasswordLength},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alphabet := tt.policy.Alphabet()
+			for i := 0; i < 200; i++ {
+				password := GeneratePassword(tt.policy)
+				if len(password) != tt.expected {
+					t.Fatalf("GeneratePassword() 长度 = %d, 期望 %d", len(password), tt.expected)
+				}
+				if !strings.ContainsRune(passwordLowercase, rune(password[0])) {
+					t.Fatalf("GeneratePassword() = %q, 期望首字符为小写字母", password)
+				}
+				for _, class := range tt.policy.charClasses() {
+					if !strings.ContainsAny(password, class) {
+						t.Fatalf("GeneratePassword() = %q, 期望包含 %q 中的字符", password, class)
+					}
+				}
+				for _, r := range password {
+					if !strings.ContainsRune(alphabet, r) {
+						t.Fatalf("GeneratePassword() = %q, 包含字符集之外的字符 %q", password, r)
+					}
+				}
+				if tt.policy.ExcludeAmbiguous && strings.ContainsAny(password, passwordAmbiguous) {
+					t.Fatalf("GeneratePassword() = %q, 期望不包含易混淆字符", password)
+				}
+			}
+		})
+	}
+}
+
+// TestGeneratedPasswordEntropy 测试各策略生成的密码熵足够
+func TestGeneratedPasswordEntropy(t *testing.T) {
+	policies := []GeneratedPasswordPolicy{
+		{Length: 12, UpperCase: true},
+		{Length: 12, ExcludeAmbiguous: true},
+		{Length: 8, UpperCase: true, Special: true},
+	}
+	for _, policy := range policies {
+		if bits := policy.EntropyBits(); bits < 45 {
+			t.Errorf("EntropyBits(%+v) = %.1f, 期望不低于45", policy, bits)
+		}
+	}
+
+	if bits := (GeneratedPasswordPolicy{Length: 12, UpperCase: true}).EntropyBits(); bits < 70 {
+		t.Errorf("默认策略 EntropyBits() = %.1f, 期望不低于70", bits)
+	}
+}
+
+// TestValidatePasswordPolicy 测试用户指定的密码按生成密码策略校验
+func TestValidatePasswordPolicy(t *testing.T) {
+	policy := GeneratedPasswordPolicy{Length: 10, UpperCase: true}
+	tests := []struct {
+		name     string
+		password string
+		valid    bool
+	}{
+		{name: "符合策略", password: "pXq7Wm3Rk9", valid: true},
+		{name: "允许的特殊字符", password: "pXq7Wm3R@#", valid: true},
+		{name: "长度不足", password: "pXq7Wm3Rk", valid: false},
+		{name: "缺少大写字母", password: "pxq7wm3rk9", valid: false},
+		{name: "缺少数字", password: "pXqTWmVRkZ", valid: false},
+		{name: "需要转义的字符", password: "pXq7Wm3Rk9'", valid: false},
+		{name: "包含空格", password: "pXq7W m3Rk9", valid: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePasswordPolicy(tt.password, policy)
+			if got := err == nil; got != tt.valid {
+				t.Errorf("ValidatePasswordPolicy(%q) = %v, 期望通过 = %v", tt.password, err, tt.valid)
+			}
+		})
+	}
+
+	special := GeneratedPasswordPolicy{Length: 8, Special: true}
+	if err := ValidatePasswordPolicy("pxq7wm3r", special); err == nil {
+		t.Errorf("ValidatePasswordPolicy() 缺少特殊字符时期望返回错误")
+	}
+}
+
+// TestCurrentPasswordPolicy 测试生成密码策略读取配置，长度超出范围时按上下限处理
+func TestCurrentPasswordPolicy(t *testing.T) {
+	original := global.APP_CONFIG.Quota
+	defer func() { global.APP_CONFIG.Quota = original }()
+
+	tests := []struct {
+		name     string
+		length   int
+		upper    bool
+		special  bool
+		noAmbig  bool
+		expected GeneratedPasswordPolicy
+	}{
+		{name: "未配置", expected: GeneratedPasswordPolicy{Length: DefaultGeneratedPasswordLength}},
+		{name: "全部开启", length: 20, upper: true, special: true, noAmbig: true,
+			expected: GeneratedPasswordPolicy{Length: 20, UpperCase: true, Special: true, ExcludeAmbiguous: true}},
+		{name: "长度低于下限", length: 4, expected: GeneratedPasswordPolicy{Length: MinGeneratedPasswordLength}},
+		{name: "长度超过上限", length: 100, upper: true, expected: GeneratedPasswordPolicy{Length: MaxGeneratedPasswordLength, UpperCase: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			global.APP_CONFIG.Quota.GeneratedPasswordLength = tt.length
+			global.APP_CONFIG.Quota.GeneratedPasswordUpperCase = tt.upper
+			global.APP_CONFIG.Quota.GeneratedPasswordSpecial = tt.special
+			global.APP_CONFIG.Quota.GeneratedPasswordNoAmbiguous = tt.noAmbig
+
+			policy := CurrentPasswordPolicy()
+			if policy != tt.expected {
+				t.Fatalf("CurrentPasswordPolicy() = %+v, 期望 %+v", policy, tt.expected)
+			}
+			password := GenerateInstancePassword()
+			if len(password) != tt.expected.Length {
+				t.Errorf("GenerateInstancePassword() 长度 = %d, 期望 %d", len(password), tt.expected.Length)
+			}
+			for _, class := range policy.charClasses() {
+				if !strings.ContainsAny(password, class) {
+					t.Errorf("GenerateInstancePassword() = %q, 期望包含 %q 中的字符", password, class)
+				}
+			}
+			if tt.noAmbig && strings.ContainsAny(password, passwordAmbiguous) {
+				t.Errorf("GenerateInstancePassword() = %q, 期望不包含易混淆字符", password)
+			}
+		})
+	}
+}
@@ -0,0 +1,320 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	s3MinPartSize     = 5 << 20
+	s3DefaultPartSize = 16 << 20
+	s3MaxParts        = 10000
+	s3DefaultRegion   = "us-east-1"
+)
+
+// S3Config S3兼容对象存储的连接配置
+type S3Config struct {
+	Endpoint  string // 含协议的服务地址，如 https://s3.example.com
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	PartSize  int64 // 分片上传的分片大小（字节）
+}
+
+// S3Client 最小化的S3兼容对象存储客户端，使用path-style地址和AWS Signature V4签名
+type S3Client struct {
+	config     S3Config
+	endpoint   *url.URL
+	httpClient *http.Client
+}
+
+// NewS3Client 创建对象存储客户端
+func NewS3Client(config S3Config) (*S3Client, error) {
+	endpoint, err := url.Parse(strings.TrimSpace(config.Endpoint))
+	if err != nil || endpoint.Host == "" || (endpoint.Scheme != "http" && endpoint.Scheme != "https") {
+		return nil, fmt.Errorf("对象存储地址无效: %s", config.Endpoint)
+	}
+	if config.Bucket == "" {
+		return nil, errors.New("未配置对象存储桶")
+	}
+	if config.AccessKey == "" || config.SecretKey == "" {
+		return nil, errors.New("未配置对象存储访问密钥")
+	}
+	if config.Region == "" {
+		config.Region = s3DefaultRegion
+	}
+	if config.PartSize <= 0 {
+		config.PartSize = s3DefaultPartSize
+	}
+	if config.PartSize < s3MinPartSize {
+		config.PartSize = s3MinPartSize
+	}
+	endpoint.Path = strings.TrimRight(endpoint.Path, "/")
+
+	return &S3Client{
+		config:   config,
+		endpoint: endpoint,
+		// 大文件传输耗时不可预估，由调用方的context控制超时
+		httpClient: &http.Client{},
+	}, nil
+}
+
+// UploadStream 以分片上传的方式把r中的内容写入对象，内存中最多缓冲一个分片，返回上传的字节数
+// 任一分片失败都会中止本次分片上传，避免在存储桶中留下未完成的分片
+func (c *S3Client) UploadStream(ctx context.Context, key string, r io.Reader) (int64, error) {
+	uploadID, err := c.createMultipartUpload(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		parts []s3CompletedPart
+		total int64
+	)
+	buf := make([]byte, c.config.PartSize)
+	for partNumber := 1; ; partNumber++ {
+		n, readErr := io.ReadFull(r, buf)
+		if readErr != nil && readErr != io.EOF && readErr != io.ErrUnexpectedEOF {
+			c.abortMultipartUpload(key, uploadID)
+			return total, fmt.Errorf("读取备份数据失败: %w", readErr)
+		}
+		// 空对象也需要上传一个分片才能完成分片上传
+		if n > 0 || partNumber == 1 {
+			if partNumber > s3MaxParts {
+				c.abortMultipartUpload(key, uploadID)
+				return total, fmt.Errorf("对象超过%d个分片，请调大分片大小", s3MaxParts)
+			}
+			etag, err := c.uploadPart(ctx, key, uploadID, partNumber, buf[:n])
+			if err != nil {
+				c.abortMultipartUpload(key, uploadID)
+				return total, err
+			}
+			parts = append(parts, s3CompletedPart{PartNumber: partNumber, ETag: etag})
+			total += int64(n)
+		}
+		if readErr != nil {
+			break
+		}
+	}
+
+	if err := c.completeMultipartUpload(ctx, key, uploadID, parts); err != nil {
+		c.abortMultipartUpload(key, uploadID)
+		return total, err
+	}
+	return total, nil
+}
+
+// GetObject 获取对象内容，调用方负责关闭返回的Reader
+func (c *S3Client) GetObject(ctx context.Context, key string) (io.ReadCloser, error) {
+	resp, err := c.do(ctx, http.MethodGet, key, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// DeleteObject 删除对象，对象不存在时也返回成功
+func (c *S3Client) DeleteObject(ctx context.Context, key string) error {
+	resp, err := c.do(ctx, http.MethodDelete, key, nil, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+type s3CompletedPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+func (c *S3Client) createMultipartUpload(ctx context.Context, key string) (string, error) {
+	resp, err := c.do(ctx, http.MethodPost, key, url.Values{"uploads": {""}}, nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		UploadID string `xml:"UploadId"`
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err != nil || result.UploadID == "" {
+		return "", fmt.Errorf("初始化分片上传失败: %v", err)
+	}
+	return result.UploadID, nil
+}
+
+func (c *S3Client) uploadPart(ctx context.Context, key, uploadID string, partNumber int, data []byte) (string, error) {
+	query := url.Values{
+		"partNumber": {fmt.Sprintf("%d", partNumber)},
+		"uploadId":   {uploadID},
+	}
+	resp, err := c.do(ctx, http.MethodPut, key, query, data)
+	if err != nil {
+		return "", fmt.Errorf("上传第%d个分片失败: %w", partNumber, err)
+	}
+	resp.Body.Close()
+	return resp.Header.Get("ETag"), nil
+}
+
+func (c *S3Client) completeMultipartUpload(ctx context.Context, key, uploadID string, parts []s3CompletedPart) error {
+	body, err := xml.Marshal(struct {
+		XMLName xml.Name          `xml:"CompleteMultipartUpload"`
+		Parts   []s3CompletedPart `xml:"Part"`
+	}{Parts: parts})
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.do(ctx, http.MethodPost, key, url.Values{"uploadId": {uploadID}}, body)
+	if err != nil {
+		return fmt.Errorf("完成分片上传失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// 完成分片上传可能在返回200后才在响应体中报告错误
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if bytes.Contains(respBody, []byte("<Error>")) {
+		return fmt.Errorf("完成分片上传失败: %s", TruncateString(string(respBody), 512))
+	}
+	return nil
+}
+
+// abortMultipartUpload 中止分片上传，使用独立的context保证调用方取消后仍能清理
+func (c *S3Client) abortMultipartUpload(key, uploadID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if resp, err := c.do(ctx, http.MethodDelete, key, url.Values{"uploadId": {uploadID}}, nil); err == nil {
+		resp.Body.Close()
+	}
+}
+
+// do 发送签名请求，非2xx响应作为错误返回
+func (c *S3Client) do(ctx context.Context, method, key string, query url.Values, body []byte) (*http.Response, error) {
+	req, err := c.newSignedRequest(ctx, method, key, query, body, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求对象存储失败: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("对象存储返回 %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return resp, nil
+}
+
+func (c *S3Client) newSignedRequest(ctx context.Context, method, key string, query url.Values, body []byte, now time.Time) (*http.Request, error) {
+	canonicalURI := c.endpoint.Path + "/" + s3Escape(c.config.Bucket, true) + "/" + s3Escape(key, false)
+	canonicalQuery := s3CanonicalQuery(query)
+
+	rawURL := c.endpoint.Scheme + "://" + c.endpoint.Host + canonicalURI
+	if canonicalQuery != "" {
+		rawURL += "?" + canonicalQuery
+	}
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if body == nil {
+		req.Body = http.NoBody
+		req.ContentLength = 0
+	}
+
+	c.signRequest(req, canonicalURI, canonicalQuery, sha256Hex(body), now)
+	return req, nil
+}
+
+// signRequest 按AWS Signature V4为请求签名，签名头部固定为host、x-amz-content-sha256和x-amz-date
+func (c *S3Client) signRequest(req *http.Request, canonicalURI, canonicalQuery, payloadHash string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("x-amz-date", amzDate)
+	req.Header.Set("x-amz-content-sha256", payloadHash)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalURI,
+		canonicalQuery,
+		"host:" + req.URL.Host + "\n" +
+			"x-amz-content-sha256:" + payloadHash + "\n" +
+			"x-amz-date:" + amzDate + "\n",
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + c.config.Region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	signingKey := hmacSHA256([]byte("AWS4"+c.config.SecretKey), date)
+	signingKey = hmacSHA256(signingKey, c.config.Region)
+	signingKey = hmacSHA256(signingKey, "s3")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		c.config.AccessKey, scope, signedHeaders, signature))
+}
+
+// s3CanonicalQuery 按键排序并编码查询参数，签名和实际请求使用同一字符串
+func s3CanonicalQuery(query url.Values) string {
+	if len(query) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var pairs []string
+	for _, k := range keys {
+		for _, v := range query[k] {
+			pairs = append(pairs, s3Escape(k, true)+"="+s3Escape(v, true))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// s3Escape 按SigV4要求编码，只保留非保留字符，encodeSlash为false时保留路径分隔符
+func s3Escape(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if (ch >= 'A' && ch <= 'Z') || (ch >= 'a' && ch <= 'z') || (ch >= '0' && ch <= '9') ||
+			ch == '-' || ch == '_' || ch == '.' || ch == '~' || (ch == '/' && !encodeSlash) {
+			b.WriteByte(ch)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", ch)
+	}
+	return b.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
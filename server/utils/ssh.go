@@ -2,6 +2,7 @@ package utils
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -12,19 +13,53 @@ import (
 
 	"oneclickvirt/global"
 
-	"github.com/pkg/sftp"
 	"go.uber.org/zap"
 	"golang.org/x/crypto/ssh"
 )
 
 type SSHConfig struct {
-	Host           string
-	Port           int
-	Username       string
-	Password       string
-	PrivateKey     string // SSH私钥内容，优先于密码使用
-	ConnectTimeout time.Duration
-	ExecuteTimeout time.Duration
+	Host                 string
+	Port                 int
+	Username             string
+	Password             string
+	PrivateKey           string // SSH私钥内容，优先于密码使用
+	PrivateKeyPassphrase string // 加密私钥的密码短语
+	UseAgent             bool   // 是否尝试ssh-agent认证（位于私钥之后、密码之前）
+	ConnectTimeout       time.Duration
+	ExecuteTimeout       time.Duration
+
+	// 主机密钥校验
+	VerifyHostKey      bool                     // 是否校验主机密钥，未启用时接受任意密钥
+	HostKeyFingerprint string                   // 已固定的主机密钥指纹（SHA256格式）
+	OnHostKeyPinned    func(fingerprint string) // 启用校验且首次连接固定指纹时回调，用于持久化
+
+	// 输出上限
+	MaxOutputBytes int // Execute返回输出的最大字节数，0表示使用全局配置或默认值
+
+	// 命令记录
+	CommandLogID uint // 非0时将执行的命令记录到该ID（Provider ID）的最近命令记录中，供管理员排查问题
+}
+
+// DefaultSSHMaxOutputBytes Execute默认的最大输出字节数
+const DefaultSSHMaxOutputBytes = 16 << 20
+
+// ErrOutputTruncated 命令输出超过上限被截断，此时仍返回截断后的输出
+var ErrOutputTruncated = errors.New("command output truncated")
+
+type sshOutputLimitKey struct{}
+
+// WithSSHOutputLimit 为ctx设置SSH命令的输出上限，供需要完整大输出的调用方（如流量数据查询）放宽限制
+func WithSSHOutputLimit(ctx context.Context, maxOutput int) context.Context {
+	return context.WithValue(ctx, sshOutputLimitKey{}, maxOutput)
+}
+
+// SSHOutputLimitFromContext 返回ctx中设置的输出上限，未设置时返回0
+func SSHOutputLimitFromContext(ctx context.Context) int {
+	if ctx == nil {
+		return 0
+	}
+	limit, _ := ctx.Value(sshOutputLimitKey{}).(int)
+	return limit
 }
 
 type SSHClient struct {
@@ -35,6 +70,9 @@ type SSHClient struct {
 	keepaliveWg     *sync.WaitGroup    // keepalive goroutine同步（指针避免拷贝）
 	mu              sync.RWMutex       // 保护并发访问
 	closed          bool               // 标记是否已关闭
+	authMethod      string             // 本次连接实际认证成功的方式
+	hostKey         string             // 服务端主机密钥指纹
+	sessions        sshSessionPool     // 并发会话调度和按需建立的额外连接
 }
 
 func NewSSHClient(config SSHConfig) (*SSHClient, error) {
@@ -51,10 +89,11 @@ func NewSSHClient(config SSHConfig) (*SSHClient, error) {
 		zap.Duration("connectTimeout", config.ConnectTimeout),
 		zap.Duration("executeTimeout", config.ExecuteTimeout))
 
-	client, keepaliveCancel, keepaliveWg, err := dialSSH(config)
+	client, keepaliveCancel, keepaliveWg, authMethod, hostKey, err := dialSSH(config)
 	if err != nil {
 		return nil, err
 	}
+	pinHostKey(&config, hostKey)
 
 	return &SSHClient{
 		client:          client,
@@ -63,62 +102,61 @@ func NewSSHClient(config SSHConfig) (*SSHClient, error) {
 		keepaliveCancel: keepaliveCancel,
 		keepaliveWg:     keepaliveWg,
 		closed:          false,
+		authMethod:      authMethod,
+		hostKey:         hostKey,
 	}, nil
 }
 
-// dialSSH 建立SSH连接的内部方法
-func dialSSH(config SSHConfig) (*ssh.Client, context.CancelFunc, *sync.WaitGroup, error) {
-	// 构建认证方法：支持密钥和密码，SSH客户端会按顺序尝试
-	var authMethods []ssh.AuthMethod
+// HostKeyFingerprint 返回本次连接服务端主机密钥的SHA256指纹
+func (c *SSHClient) HostKeyFingerprint() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.hostKey
+}
 
-	// 如果提供了SSH私钥，添加密钥认证
-	if config.PrivateKey != "" {
-		signer, err := ssh.ParsePrivateKey([]byte(config.PrivateKey))
-		if err != nil {
-			global.APP_LOG.Warn("SSH私钥解析失败，将尝试使用密码认证",
-				zap.String("host", config.Host),
-				zap.Error(err))
-		} else {
-			authMethods = append(authMethods, ssh.PublicKeys(signer))
-			global.APP_LOG.Debug("已添加SSH密钥认证方法",
-				zap.String("host", config.Host))
-		}
-	}
+// AuthMethod 返回本次连接实际认证成功的方式：privateKey、agent 或 password
+func (c *SSHClient) AuthMethod() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.authMethod
+}
 
-	// 如果提供了密码，添加密码认证（无论是否有密钥，都添加作为备用方案）
-	if config.Password != "" {
-		authMethods = append(authMethods, ssh.Password(config.Password))
-		global.APP_LOG.Debug("已添加SSH密码认证方法",
-			zap.String("host", config.Host))
+// sshAddress 构建连接地址，如果Host已经包含端口则直接使用，否则拼接端口
+func sshAddress(config SSHConfig) string {
+	if strings.Contains(config.Host, ":") {
+		// Host已经包含端口（如 "192.168.1.1:22"），直接使用
+		return config.Host
 	}
+	return fmt.Sprintf("%s:%d", config.Host, config.Port)
+}
 
-	// 如果既没有密钥也没有密码，返回错误
-	if len(authMethods) == 0 {
-		return nil, nil, nil, fmt.Errorf("no authentication method available: neither SSH key nor password provided")
+// dialSSH 建立SSH连接的内部方法
+// 返回值依次为：SSH客户端、keepalive取消函数、keepalive同步组、认证方式、主机密钥指纹
+func dialSSH(config SSHConfig) (*ssh.Client, context.CancelFunc, *sync.WaitGroup, string, string, error) {
+	// 构建认证链：按私钥、ssh-agent、密码的顺序尝试
+	chain, err := buildSSHAuthChain(config)
+	if err != nil {
+		return nil, nil, nil, "", "", err
 	}
+	defer chain.close()
 
+	var hostKey string
 	sshConfig := &ssh.ClientConfig{
 		User:            config.Username,
-		Auth:            authMethods,
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		Auth:            chain.methods,
+		HostKeyCallback: hostKeyCallback(config, &hostKey),
 		Timeout:         config.ConnectTimeout,
 	}
 
-	// 构建连接地址，如果Host已经包含端口则直接使用，否则拼接端口
-	var addr string
-	if strings.Contains(config.Host, ":") {
-		// Host已经包含端口（如 "192.168.1.1:22"），直接使用
-		addr = config.Host
-	} else {
-		// Host不包含端口，拼接端口号
-		addr = fmt.Sprintf("%s:%d", config.Host, config.Port)
-	}
-
-	client, err := ssh.Dial("tcp", addr, sshConfig)
+	client, err := ssh.Dial("tcp", sshAddress(config), sshConfig)
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("failed to connect to SSH server: %w", err)
+		return nil, nil, nil, "", "", fmt.Errorf("failed to connect to SSH server: %w", err)
 	}
 
+	global.APP_LOG.Debug("SSH认证成功",
+		zap.String("host", config.Host),
+		zap.String("authMethod", chain.accepted))
+
 	// 启用 KeepAlive，保持连接活跃，使用context控制生命周期
 	ctx, cancel := context.WithCancel(context.Background())
 	wg := &sync.WaitGroup{}
@@ -183,7 +221,7 @@ func dialSSH(config SSHConfig) (*ssh.Client, context.CancelFunc, *sync.WaitGroup
 		}
 	}()
 
-	return client, cancel, wg, nil
+	return client, cancel, wg, chain.accepted, hostKey, nil
 }
 
 // IsHealthy 检查SSH连接是否健康
@@ -227,6 +265,9 @@ func (c *SSHClient) Close() error {
 	c.closed = true
 	c.mu.Unlock()
 
+	// 关闭按需建立的额外连接
+	c.closeSessions()
+
 	// 取消keepalive goroutine
 	if c.keepaliveCancel != nil {
 		c.keepaliveCancel()
@@ -294,16 +335,22 @@ func (c *SSHClient) Reconnect() error {
 	}
 
 	// 建立新连接
-	client, keepaliveCancel, keepaliveWg, err := dialSSH(c.config)
+	client, keepaliveCancel, keepaliveWg, authMethod, hostKey, err := dialSSH(c.config)
 	if err != nil {
 		return fmt.Errorf("failed to reconnect SSH: %w", err)
 	}
+	pinHostKey(&c.config, hostKey)
 
+	c.mu.Lock()
 	c.client = client
+	c.authMethod = authMethod
+	c.hostKey = hostKey
 	c.keepaliveCancel = keepaliveCancel
 	c.keepaliveWg = keepaliveWg
 	c.lastHealthTime = time.Now()
 	c.closed = false
+	c.mu.Unlock()
+	c.reopenSessions()
 
 	global.APP_LOG.Info("SSH连接重建成功",
 		zap.String("host", c.config.Host),
@@ -312,7 +359,68 @@ func (c *SSHClient) Reconnect() error {
 	return nil
 }
 
+// Execute 执行命令，节点熔断期间直接返回 *CircuitOpenError
+// 输出超过上限时返回截断后的输出和 ErrOutputTruncated
 func (c *SSHClient) Execute(command string) (string, error) {
+	return c.ExecuteWithLimit(command, 0)
+}
+
+// ExecuteWithLimit 以指定的输出上限执行命令，maxOutput为0时使用默认上限
+func (c *SSHClient) ExecuteWithLimit(command string, maxOutput int) (string, error) {
+	return c.ExecuteWithTimeout(command, 0, maxOutput)
+}
+
+// ExecuteOperation 按操作类型的超时执行命令，用于下载、安装等耗时明显不同于普通命令的操作
+func (c *SSHClient) ExecuteOperation(op SSHOperation, command string) (string, error) {
+	return c.ExecuteWithTimeout(command, SSHOperationTimeout(op), 0)
+}
+
+// ExecuteWithTimeout 以指定的超时和输出上限执行命令，timeout为0时使用连接配置的执行超时
+func (c *SSHClient) ExecuteWithTimeout(command string, timeout time.Duration, maxOutput int) (string, error) {
+	start := time.Now()
+	breaker := GetCircuitBreaker(sshAddress(c.config))
+	if err := breaker.Allow(); err != nil {
+		RecordCommand(c.config.CommandLogID, "", command, "", -1, err, 0)
+		return "", err
+	}
+	if timeout <= 0 {
+		timeout = c.config.ExecuteTimeout
+	}
+	output, err := c.execute(command, timeout, c.outputLimit(maxOutput))
+	breaker.Record(err)
+	RecordCommand(c.config.CommandLogID, "", command, output, commandExitCode(err), err, time.Since(start))
+	return output, err
+}
+
+// outputLimit 确定本次执行的输出上限：调用方指定 > 连接配置 > 全局配置 > 默认值
+func (c *SSHClient) outputLimit(maxOutput int) int {
+	if maxOutput > 0 {
+		return maxOutput
+	}
+	if c.config.MaxOutputBytes > 0 {
+		return c.config.MaxOutputBytes
+	}
+	if global.APP_CONFIG.System.SSHMaxOutputBytes > 0 {
+		return global.APP_CONFIG.System.SSHMaxOutputBytes
+	}
+	return DefaultSSHMaxOutputBytes
+}
+
+// truncatedOutput 截断超限的输出并记录日志，未超限时返回nil错误
+func (c *SSHClient) truncatedOutput(command string, buf *cappedBuffer, maxOutput int) (string, error) {
+	if !buf.exceeded(maxOutput) {
+		return string(buf.buf), nil
+	}
+	if global.APP_LOG != nil {
+		global.APP_LOG.Warn("SSH命令输出超过上限，已截断",
+			zap.String("host", c.config.Host),
+			zap.String("command", TruncateString(command, 200)),
+			zap.Int("maxOutput", maxOutput))
+	}
+	return string(buf.buf[:maxOutput]), fmt.Errorf("%w: exceeded %d bytes", ErrOutputTruncated, maxOutput)
+}
+
+func (c *SSHClient) execute(command string, timeout time.Duration, maxOutput int) (string, error) {
 	// 检查连接健康状态，如果不健康则尝试重连
 	if !c.IsHealthy() {
 		global.APP_LOG.Warn("SSH连接不健康，尝试重连",
@@ -323,7 +431,7 @@ func (c *SSHClient) Execute(command string) (string, error) {
 	}
 
 	// 尝试执行命令，如果失败则重试一次（可能是连接刚断开）
-	output, err := c.executeCommand(command)
+	output, err := c.executeCommand(command, timeout, maxOutput)
 	if err != nil && strings.Contains(err.Error(), "failed to create SSH session") {
 		global.APP_LOG.Warn("SSH session创建失败，尝试重连后重试",
 			zap.String("host", c.config.Host),
@@ -335,7 +443,7 @@ func (c *SSHClient) Execute(command string) (string, error) {
 		}
 
 		// 重试执行
-		output, err = c.executeCommand(command)
+		output, err = c.executeCommand(command, timeout, maxOutput)
 		if err != nil {
 			return output, fmt.Errorf("command failed after reconnection: %w", err)
 		}
@@ -344,12 +452,13 @@ func (c *SSHClient) Execute(command string) (string, error) {
 	return output, err
 }
 
-// executeCommand 执行SSH命令的内部方法
-func (c *SSHClient) executeCommand(command string) (string, error) {
-	session, err := c.client.NewSession()
+// executeCommand 执行SSH命令的内部方法，超过timeout时终止会话，只保留前maxOutput字节的输出
+func (c *SSHClient) executeCommand(command string, timeout time.Duration, maxOutput int) (string, error) {
+	session, release, err := c.acquireSession()
 	if err != nil {
-		return "", fmt.Errorf("failed to create SSH session: %w", err)
+		return "", err
 	}
+	defer release()
 	defer session.Close()
 
 	// 请求PTY以模拟交互式登录shell，确保加载完整的环境变量
@@ -366,22 +475,28 @@ func (c *SSHClient) executeCommand(command string) (string, error) {
 	// 这种方式更安全，不需要处理复杂的命令转义
 	envCommand := fmt.Sprintf("source /etc/profile 2>/dev/null || true; source ~/.bashrc 2>/dev/null || true; source ~/.bash_profile 2>/dev/null || true; export PATH=$PATH:/usr/local/bin:/snap/bin:/usr/sbin:/sbin; %s", command)
 
-	// 创建一个通道来处理命令执行的超时
+	// 创建一个通道来处理命令执行的超时，多保留1字节用于判断输出是否超限
 	done := make(chan struct{})
-	var output []byte
+	output := &cappedBuffer{limit: maxOutput + 1}
+	session.Stdout = output
+	session.Stderr = output
 	var execErr error
 
+	cancelled, untrack := c.trackCommand("", command, timeout)
+	defer untrack()
+
 	go func() {
-		output, execErr = session.CombinedOutput(envCommand)
+		execErr = session.Run(envCommand)
 		close(done)
 	}()
 
-	// 等待命令完成或超时
-	timeoutTimer := time.NewTimer(c.config.ExecuteTimeout)
+	// 等待命令完成、超时或被取消
+	timeoutTimer := time.NewTimer(timeout)
 	defer timeoutTimer.Stop()
 
 	select {
 	case <-done:
+		result, truncErr := c.truncatedOutput(command, output, maxOutput)
 		if execErr != nil {
 			// 记录执行失败的详细信息，包括原始命令和转换后的命令
 			if global.APP_LOG != nil {
@@ -389,15 +504,140 @@ func (c *SSHClient) executeCommand(command string) (string, error) {
 					zap.String("original_command", command),
 					zap.String("env_wrapped_command", envCommand),
 					zap.Error(execErr),
-					zap.String("output", string(output)))
+					zap.String("output", TruncateString(result, 2000)))
 			}
-			return string(output), fmt.Errorf("command execution failed: %w", execErr)
+			return result, fmt.Errorf("command execution failed: %w", execErr)
 		}
-		return string(output), nil
+		return result, truncErr
 	case <-timeoutTimer.C:
 		session.Signal(ssh.SIGKILL) // 强制终止会话
-		return "", fmt.Errorf("command execution timeout after %v", c.config.ExecuteTimeout)
+		return "", fmt.Errorf("command execution timeout after %v", timeout)
+	case <-cancelled.Done():
+		session.Signal(ssh.SIGKILL)
+		return "", ErrSSHCommandCancelled
+	}
+}
+
+// CommandResult 分别捕获标准输出和标准错误的命令执行结果
+type CommandResult struct {
+	Stdout    string
+	Stderr    string
+	ExitCode  int
+	Truncated bool // 输出是否因超过上限被截断
+}
+
+// cappedBuffer 只保留前limit字节的写入缓冲区，超出部分直接丢弃
+// stdout和stderr可能写入同一个缓冲区，写入需要加锁
+type cappedBuffer struct {
+	mu    sync.Mutex
+	buf   []byte
+	limit int
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if remain := b.limit - len(b.buf); remain > 0 {
+		if len(p) > remain {
+			b.buf = append(b.buf, p[:remain]...)
+		} else {
+			b.buf = append(b.buf, p...)
+		}
+	}
+	return len(p), nil
+}
+
+// exceeded 写入的数据是否超过了maxOutput字节
+func (b *cappedBuffer) exceeded(maxOutput int) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.buf) > maxOutput
+}
+
+// ExecuteCapture 不分配PTY执行命令，分别返回stdout、stderr和退出码
+// 命令以非零状态退出不视为错误；timeout为0时使用连接的默认执行超时，maxOutput限制每路输出的字节数
+func (c *SSHClient) ExecuteCapture(command string, timeout time.Duration, maxOutput int) (*CommandResult, error) {
+	start := time.Now()
+	breaker := GetCircuitBreaker(sshAddress(c.config))
+	if err := breaker.Allow(); err != nil {
+		RecordCommand(c.config.CommandLogID, "", command, "", -1, err, 0)
+		return nil, err
+	}
+	result, err := c.executeCapture(command, timeout, maxOutput)
+	breaker.Record(err)
+	if result != nil {
+		RecordCommand(c.config.CommandLogID, "", command, result.Stdout+result.Stderr, result.ExitCode, err, time.Since(start))
+	} else {
+		RecordCommand(c.config.CommandLogID, "", command, "", -1, err, time.Since(start))
+	}
+	return result, err
+}
+
+func (c *SSHClient) executeCapture(command string, timeout time.Duration, maxOutput int) (*CommandResult, error) {
+	if !c.IsHealthy() {
+		global.APP_LOG.Warn("SSH连接不健康，尝试重连",
+			zap.String("host", c.config.Host))
+		if err := c.Reconnect(); err != nil {
+			return nil, fmt.Errorf("failed to reconnect SSH before execution: %w", err)
+		}
 	}
+	if timeout <= 0 {
+		timeout = c.config.ExecuteTimeout
+	}
+
+	session, release, err := c.acquireSession()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	defer session.Close()
+
+	// 多保留1字节用于判断输出是否超限
+	stdout := &cappedBuffer{limit: maxOutput + 1}
+	stderr := &cappedBuffer{limit: maxOutput + 1}
+	session.Stdout = stdout
+	session.Stderr = stderr
+
+	envCommand := fmt.Sprintf("export PATH=$PATH:/usr/local/bin:/snap/bin:/usr/sbin:/sbin; %s", command)
+
+	cancelled, untrack := c.trackCommand("", command, timeout)
+	defer untrack()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- session.Run(envCommand)
+	}()
+
+	timeoutTimer := time.NewTimer(timeout)
+	defer timeoutTimer.Stop()
+
+	select {
+	case runErr := <-done:
+		result := &CommandResult{
+			Stdout:    TruncateString(string(stdout.buf), maxOutput),
+			Stderr:    TruncateString(string(stderr.buf), maxOutput),
+			Truncated: len(stdout.buf) > maxOutput || len(stderr.buf) > maxOutput,
+		}
+		if runErr != nil {
+			var exitErr *ssh.ExitError
+			if !errors.As(runErr, &exitErr) {
+				return nil, fmt.Errorf("command execution failed: %w", runErr)
+			}
+			result.ExitCode = exitErr.ExitStatus()
+		}
+		return result, nil
+	case <-timeoutTimer.C:
+		session.Signal(ssh.SIGKILL) // 强制终止会话
+		return nil, fmt.Errorf("command execution timeout after %v", timeout)
+	case <-cancelled.Done():
+		session.Signal(ssh.SIGKILL)
+		return nil, ErrSSHCommandCancelled
+	}
+}
+
+// ShellQuote 使用单引号包裹字符串，使其可以安全地拼接到远程shell命令中
+func ShellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
 }
 
 // TestSSHConnectionLatency 测试SSH连接延迟，执行指定次数测试并返回结果
@@ -510,6 +750,19 @@ func TestSSHConnectionLatency(config SSHConfig, testCount int) (minLatency, maxL
 
 // ExecuteWithLogging 执行命令并记录详细的调试信息，用于排查复杂命令的执行问题
 func (c *SSHClient) ExecuteWithLogging(command string, logPrefix string) (string, error) {
+	start := time.Now()
+	breaker := GetCircuitBreaker(sshAddress(c.config))
+	if err := breaker.Allow(); err != nil {
+		RecordCommand(c.config.CommandLogID, logPrefix, command, "", -1, err, 0)
+		return "", err
+	}
+	output, err := c.executeWithLogging(command, logPrefix, c.outputLimit(0))
+	breaker.Record(err)
+	RecordCommand(c.config.CommandLogID, logPrefix, command, output, commandExitCode(err), err, time.Since(start))
+	return output, err
+}
+
+func (c *SSHClient) executeWithLogging(command string, logPrefix string, maxOutput int) (string, error) {
 	// 检查连接健康状态，如果不健康则尝试重连
 	if !c.IsHealthy() {
 		global.APP_LOG.Warn("SSH连接不健康，尝试重连",
@@ -521,7 +774,7 @@ func (c *SSHClient) ExecuteWithLogging(command string, logPrefix string) (string
 	}
 
 	// 尝试执行命令，如果失败则重试一次
-	output, err := c.executeCommandWithLogging(command, logPrefix)
+	output, err := c.executeCommandWithLogging(command, logPrefix, maxOutput)
 	if err != nil && strings.Contains(err.Error(), "failed to create SSH session") {
 		global.APP_LOG.Warn("SSH session创建失败，尝试重连后重试",
 			zap.String("host", c.config.Host),
@@ -534,7 +787,7 @@ func (c *SSHClient) ExecuteWithLogging(command string, logPrefix string) (string
 		}
 
 		// 重试执行
-		output, err = c.executeCommandWithLogging(command, logPrefix)
+		output, err = c.executeCommandWithLogging(command, logPrefix, maxOutput)
 		if err != nil {
 			return output, fmt.Errorf("command failed after reconnection: %w", err)
 		}
@@ -544,11 +797,12 @@ func (c *SSHClient) ExecuteWithLogging(command string, logPrefix string) (string
 }
 
 // executeCommandWithLogging 执行SSH命令并记录日志的内部方法
-func (c *SSHClient) executeCommandWithLogging(command string, logPrefix string) (string, error) {
-	session, err := c.client.NewSession()
+func (c *SSHClient) executeCommandWithLogging(command string, logPrefix string, maxOutput int) (string, error) {
+	session, release, err := c.acquireSession()
 	if err != nil {
-		return "", fmt.Errorf("failed to create SSH session: %w", err)
+		return "", err
 	}
+	defer release()
 	defer session.Close()
 
 	// 请求PTY以模拟交互式登录shell，确保加载完整的环境变量
@@ -572,22 +826,28 @@ func (c *SSHClient) executeCommandWithLogging(command string, logPrefix string)
 			zap.String("wrapped_command", envCommand))
 	}
 
-	// 创建一个通道来处理命令执行的超时
+	// 创建一个通道来处理命令执行的超时，多保留1字节用于判断输出是否超限
 	done := make(chan struct{})
-	var output []byte
+	output := &cappedBuffer{limit: maxOutput + 1}
+	session.Stdout = output
+	session.Stderr = output
 	var execErr error
 
+	cancelled, untrack := c.trackCommand(logPrefix, command, c.config.ExecuteTimeout)
+	defer untrack()
+
 	go func() {
-		output, execErr = session.CombinedOutput(envCommand)
+		execErr = session.Run(envCommand)
 		close(done)
 	}()
 
-	// 等待命令完成或超时
+	// 等待命令完成、超时或被取消
 	timeoutTimer := time.NewTimer(c.config.ExecuteTimeout)
 	defer timeoutTimer.Stop()
 
 	select {
 	case <-done:
+		result, truncErr := c.truncatedOutput(command, output, maxOutput)
 		if execErr != nil {
 			// 记录执行失败的详细信息
 			if global.APP_LOG != nil {
@@ -596,17 +856,17 @@ func (c *SSHClient) executeCommandWithLogging(command string, logPrefix string)
 					zap.String("original_command", command),
 					zap.String("wrapped_command", envCommand),
 					zap.Error(execErr),
-					zap.String("output", string(output)))
+					zap.String("output", TruncateString(result, 2000)))
 			}
-			return string(output), fmt.Errorf("command execution failed: %w", execErr)
+			return result, fmt.Errorf("command execution failed: %w", execErr)
 		}
 		if global.APP_LOG != nil {
 			global.APP_LOG.Debug("SSH命令执行成功",
 				zap.String("log_prefix", logPrefix),
 				zap.String("original_command", command),
-				zap.Int("output_length", len(output)))
+				zap.Int("output_length", len(result)))
 		}
-		return string(output), nil
+		return result, truncErr
 	case <-timeoutTimer.C:
 		session.Signal(ssh.SIGKILL) // 强制终止会话
 		if global.APP_LOG != nil {
@@ -616,16 +876,25 @@ func (c *SSHClient) executeCommandWithLogging(command string, logPrefix string)
 				zap.Duration("timeout", c.config.ExecuteTimeout))
 		}
 		return "", fmt.Errorf("command execution timeout after %v", c.config.ExecuteTimeout)
+	case <-cancelled.Done():
+		session.Signal(ssh.SIGKILL)
+		if global.APP_LOG != nil {
+			global.APP_LOG.Warn("SSH命令已被取消",
+				zap.String("log_prefix", logPrefix),
+				zap.String("original_command", TruncateString(RedactCommand(command), 200)))
+		}
+		return "", ErrSSHCommandCancelled
 	}
 }
 
 // UploadContent 上传内容到远程服务器指定路径
 func (c *SSHClient) UploadContent(content, remotePath string, perm os.FileMode) error {
 	// 创建SFTP客户端
-	sftpClient, err := sftp.NewClient(c.client)
+	sftpClient, release, err := c.newSFTPClient()
 	if err != nil {
-		return fmt.Errorf("failed to create SFTP client: %w", err)
+		return err
 	}
+	defer release()
 	defer sftpClient.Close()
 
 	// 创建远程文件的目录（如果不存在）
@@ -663,6 +932,50 @@ func (c *SSHClient) UploadContent(content, remotePath string, perm os.FileMode)
 	return nil
 }
 
+// DownloadFile 通过SFTP将远程文件流式写入w，不在内存中缓冲整个文件，返回写入的字节数
+func (c *SSHClient) DownloadFile(remotePath string, w io.Writer) (int64, error) {
+	sftpClient, release, err := c.newSFTPClient()
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+	defer sftpClient.Close()
+
+	remoteFile, err := sftpClient.Open(remotePath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open remote file %s: %w", remotePath, err)
+	}
+	defer remoteFile.Close()
+
+	written, err := io.Copy(w, remoteFile)
+	if err != nil {
+		return written, fmt.Errorf("failed to download remote file %s: %w", remotePath, err)
+	}
+	return written, nil
+}
+
+// UploadFile 通过SFTP将r中的内容流式写入远程文件，返回写入的字节数
+func (c *SSHClient) UploadFile(r io.Reader, remotePath string) (int64, error) {
+	sftpClient, release, err := c.newSFTPClient()
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+	defer sftpClient.Close()
+
+	remoteFile, err := sftpClient.Create(remotePath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create remote file %s: %w", remotePath, err)
+	}
+	defer remoteFile.Close()
+
+	written, err := io.Copy(remoteFile, r)
+	if err != nil {
+		return written, fmt.Errorf("failed to upload remote file %s: %w", remotePath, err)
+	}
+	return written, nil
+}
+
 // ResolveHostToIP 解析主机名到IP地址
 // 如果host已经是IP地址，直接返回；如果是域名，解析为IP地址
 func ResolveHostToIP(host string) ([]string, error) {
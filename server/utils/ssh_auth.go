@@ -0,0 +1,235 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"time"
+
+	"oneclickvirt/global"
+
+	"go.uber.org/zap"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// SSH认证方式
+const (
+	SSHAuthMethodPrivateKey = "privateKey"
+	SSHAuthMethodAgent      = "agent"
+	SSHAuthMethodPassword   = "password"
+)
+
+// sshAuthChain 按固定顺序构建的SSH认证链：私钥 -> ssh-agent -> 密码
+// 私钥和ssh-agent同属publickey方式，SSH握手中同名方式失败后不会再次尝试，因此两者放在同一个回调中按顺序提供
+type sshAuthChain struct {
+	methods   []ssh.AuthMethod
+	names     []string
+	accepted  string // 最后一次实际用于认证的方式，握手成功时即为服务端接受的方式
+	agentConn net.Conn
+}
+
+// buildSSHAuthChain 根据配置构建认证链
+func buildSSHAuthChain(config SSHConfig) (*sshAuthChain, error) {
+	chain := &sshAuthChain{}
+	var keyErr error
+
+	// 1. 私钥认证（支持带密码短语的加密私钥）
+	var keySigner ssh.Signer
+	if config.PrivateKey != "" {
+		signer, err := parseSSHPrivateKey(config.PrivateKey, config.PrivateKeyPassphrase)
+		if err != nil {
+			keyErr = err
+			global.APP_LOG.Warn("SSH私钥解析失败，将尝试其他认证方式",
+				zap.String("host", config.Host),
+				zap.Error(err))
+		} else {
+			keySigner = signer
+			chain.names = append(chain.names, SSHAuthMethodPrivateKey)
+		}
+	}
+
+	// 2. ssh-agent认证
+	var agentClient agent.ExtendedAgent
+	if config.UseAgent {
+		if socket := os.Getenv("SSH_AUTH_SOCK"); socket == "" {
+			global.APP_LOG.Warn("已启用ssh-agent认证但未设置SSH_AUTH_SOCK，跳过",
+				zap.String("host", config.Host))
+		} else if conn, err := net.Dial("unix", socket); err != nil {
+			global.APP_LOG.Warn("连接ssh-agent失败，跳过",
+				zap.String("host", config.Host),
+				zap.Error(err))
+		} else {
+			chain.agentConn = conn
+			agentClient = agent.NewClient(conn)
+			chain.names = append(chain.names, SSHAuthMethodAgent)
+		}
+	}
+
+	if keySigner != nil || agentClient != nil {
+		chain.methods = append(chain.methods, ssh.PublicKeysCallback(func() ([]ssh.Signer, error) {
+			var signers []ssh.Signer
+			if keySigner != nil {
+				signers = append(signers, recordingSigner(keySigner, func() { chain.accepted = SSHAuthMethodPrivateKey }))
+			}
+			if agentClient != nil {
+				agentSigners, err := agentClient.Signers()
+				if err != nil {
+					global.APP_LOG.Warn("获取ssh-agent密钥失败，跳过",
+						zap.String("host", config.Host),
+						zap.Error(err))
+				}
+				for _, signer := range agentSigners {
+					signers = append(signers, recordingSigner(signer, func() { chain.accepted = SSHAuthMethodAgent }))
+				}
+			}
+			return signers, nil
+		}))
+	}
+
+	// 3. 密码认证，作为最后的备用方案
+	if config.Password != "" {
+		password := config.Password
+		chain.names = append(chain.names, SSHAuthMethodPassword)
+		chain.methods = append(chain.methods, ssh.PasswordCallback(func() (string, error) {
+			chain.accepted = SSHAuthMethodPassword
+			return password, nil
+		}))
+	}
+
+	if len(chain.methods) == 0 {
+		if keyErr != nil {
+			return nil, fmt.Errorf("no authentication method available: %w", keyErr)
+		}
+		return nil, fmt.Errorf("no authentication method available: neither SSH key nor password provided")
+	}
+
+	global.APP_LOG.Debug("SSH认证链",
+		zap.String("host", config.Host),
+		zap.Strings("methods", chain.names))
+
+	return chain, nil
+}
+
+// recordingSigner 包装签名器，在签名时回调onSign
+// 客户端只会对服务端确认可接受的公钥签名，因此签名即表示该密钥被用于认证；包装后保留原签名器支持的签名算法
+func recordingSigner(signer ssh.Signer, onSign func()) ssh.Signer {
+	switch s := signer.(type) {
+	case ssh.MultiAlgorithmSigner:
+		return &recordingMultiAlgorithmSigner{recordingAlgorithmSigner{s, onSign}, s}
+	case ssh.AlgorithmSigner:
+		return &recordingAlgorithmSigner{s, onSign}
+	default:
+		return &recordingPlainSigner{s, onSign}
+	}
+}
+
+type recordingPlainSigner struct {
+	ssh.Signer
+	onSign func()
+}
+
+func (s *recordingPlainSigner) Sign(rand io.Reader, data []byte) (*ssh.Signature, error) {
+	s.onSign()
+	return s.Signer.Sign(rand, data)
+}
+
+type recordingAlgorithmSigner struct {
+	ssh.AlgorithmSigner
+	onSign func()
+}
+
+func (s *recordingAlgorithmSigner) Sign(rand io.Reader, data []byte) (*ssh.Signature, error) {
+	s.onSign()
+	return s.AlgorithmSigner.Sign(rand, data)
+}
+
+func (s *recordingAlgorithmSigner) SignWithAlgorithm(rand io.Reader, data []byte, algorithm string) (*ssh.Signature, error) {
+	s.onSign()
+	return s.AlgorithmSigner.SignWithAlgorithm(rand, data, algorithm)
+}
+
+type recordingMultiAlgorithmSigner struct {
+	recordingAlgorithmSigner
+	multi ssh.MultiAlgorithmSigner
+}
+
+func (s *recordingMultiAlgorithmSigner) Algorithms() []string {
+	return s.multi.Algorithms()
+}
+
+// close 释放认证过程中使用的ssh-agent连接，握手完成后即可关闭
+func (c *sshAuthChain) close() {
+	if c.agentConn != nil {
+		c.agentConn.Close()
+		c.agentConn = nil
+	}
+}
+
+// parseSSHPrivateKey 解析私钥，提供密码短语时按加密私钥解析
+func parseSSHPrivateKey(privateKey, passphrase string) (ssh.Signer, error) {
+	if passphrase != "" {
+		signer, err := ssh.ParsePrivateKeyWithPassphrase([]byte(privateKey), []byte(passphrase))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse encrypted private key: %w", err)
+		}
+		return signer, nil
+	}
+
+	signer, err := ssh.ParsePrivateKey([]byte(privateKey))
+	if err != nil {
+		var missing *ssh.PassphraseMissingError
+		if errors.As(err, &missing) {
+			return nil, fmt.Errorf("private key is encrypted but no passphrase provided")
+		}
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+	return signer, nil
+}
+
+// ValidateSSHAuth 仅完成SSH握手和认证，不打开会话也不执行任何命令
+// 返回实际认证成功的方式以及尝试过的认证方式列表
+func ValidateSSHAuth(config SSHConfig) (string, []string, error) {
+	if config.ConnectTimeout == 0 {
+		config.ConnectTimeout = 30 * time.Second
+	}
+
+	chain, err := buildSSHAuthChain(config)
+	if err != nil {
+		return "", nil, err
+	}
+	defer chain.close()
+
+	var hostKey string
+	client, err := ssh.Dial("tcp", sshAddress(config), &ssh.ClientConfig{
+		User:            config.Username,
+		Auth:            chain.methods,
+		HostKeyCallback: hostKeyCallback(config, &hostKey),
+		Timeout:         config.ConnectTimeout,
+	})
+	if err != nil {
+		return "", chain.names, fmt.Errorf("SSH认证失败: %w", err)
+	}
+	client.Close()
+
+	return chain.accepted, chain.names, nil
+}
+
+// NewSSHClientConfig 构造与NewSSHClient一致的ssh.ClientConfig（认证链和主机密钥校验），供需要自行ssh.Dial的调用方使用
+// 返回的清理函数用于释放ssh-agent连接，应在ssh.Dial返回后调用
+func NewSSHClientConfig(config SSHConfig) (*ssh.ClientConfig, func(), error) {
+	chain, err := buildSSHAuthChain(config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var hostKey string
+	return &ssh.ClientConfig{
+		User:            config.Username,
+		Auth:            chain.methods,
+		HostKeyCallback: hostKeyCallback(config, &hostKey),
+		Timeout:         config.ConnectTimeout,
+	}, chain.close, nil
+}
@@ -0,0 +1,165 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"oneclickvirt/global"
+
+	"go.uber.org/zap"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+func newTestSigner(t *testing.T) (ssh.Signer, ed25519.PrivateKey) {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("生成密钥失败: %v", err)
+	}
+	signer, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatalf("创建签名器失败: %v", err)
+	}
+	return signer, priv
+}
+
+// startTestSSHServer 启动只接受指定公钥和密码的SSH服务端，返回监听端口
+func startTestSSHServer(t *testing.T, acceptedKeys []ssh.PublicKey, acceptedPassword string) int {
+	t.Helper()
+	hostSigner, _ := newTestSigner(t)
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			for _, accepted := range acceptedKeys {
+				if bytes.Equal(accepted.Marshal(), key.Marshal()) {
+					return nil, nil
+				}
+			}
+			return nil, errors.New("unknown key")
+		},
+		PasswordCallback: func(conn ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if acceptedPassword != "" && string(password) == acceptedPassword {
+				return nil, nil
+			}
+			return nil, errors.New("wrong password")
+		},
+	}
+	config.AddHostKey(hostSigner)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("监听端口失败: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_, channels, requests, err := ssh.NewServerConn(conn, config)
+				if err != nil {
+					return
+				}
+				go ssh.DiscardRequests(requests)
+				for ch := range channels {
+					ch.Reject(ssh.Prohibited, "no sessions")
+				}
+			}()
+		}
+	}()
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+// startTestAgent 启动持有指定私钥的ssh-agent并设置SSH_AUTH_SOCK
+func startTestAgent(t *testing.T, key ed25519.PrivateKey) {
+	t.Helper()
+	keyring := agent.NewKeyring()
+	if err := keyring.Add(agent.AddedKey{PrivateKey: key}); err != nil {
+		t.Fatalf("添加agent密钥失败: %v", err)
+	}
+	socket := filepath.Join(t.TempDir(), "agent.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("监听agent套接字失败: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				agent.ServeAgent(keyring, conn)
+			}()
+		}
+	}()
+	t.Setenv("SSH_AUTH_SOCK", socket)
+}
+
+// TestValidateSSHAuthFallback 测试私钥被拒绝后依次回退到ssh-agent和密码，并返回服务端实际接受的方式
+func TestValidateSSHAuthFallback(t *testing.T) {
+	originalLog := global.APP_LOG
+	global.APP_LOG = zap.NewNop()
+	defer func() { global.APP_LOG = originalLog }()
+
+	keySigner, keyPriv := newTestSigner(t)
+	agentSigner, agentPriv := newTestSigner(t)
+	startTestAgent(t, agentPriv)
+
+	block, err := ssh.MarshalPrivateKey(keyPriv, "")
+	if err != nil {
+		t.Fatalf("编码私钥失败: %v", err)
+	}
+	privateKey := string(pem.EncodeToMemory(block))
+
+	tests := []struct {
+		name             string
+		acceptedKeys     []ssh.PublicKey
+		acceptedPassword string
+		want             string
+		wantErr          bool
+	}{
+		{name: "私钥被接受", acceptedKeys: []ssh.PublicKey{keySigner.PublicKey(), agentSigner.PublicKey()}, want: SSHAuthMethodPrivateKey},
+		{name: "私钥被拒绝时使用agent", acceptedKeys: []ssh.PublicKey{agentSigner.PublicKey()}, want: SSHAuthMethodAgent},
+		{name: "私钥和agent都被拒绝时使用密码", acceptedPassword: "secret", want: SSHAuthMethodPassword},
+		{name: "全部被拒绝", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			port := startTestSSHServer(t, tt.acceptedKeys, tt.acceptedPassword)
+			method, tried, err := ValidateSSHAuth(SSHConfig{
+				Host:           "127.0.0.1",
+				Port:           port,
+				Username:       "root",
+				Password:       "secret",
+				PrivateKey:     privateKey,
+				UseAgent:       true,
+				ConnectTimeout: 5 * time.Second,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateSSHAuth() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			want := []string{SSHAuthMethodPrivateKey, SSHAuthMethodAgent, SSHAuthMethodPassword}
+			if strings.Join(tried, ",") != strings.Join(want, ",") {
+				t.Errorf("尝试的认证方式 = %v, 期望 %v", tried, want)
+			}
+			if !tt.wantErr && method != tt.want {
+				t.Errorf("ValidateSSHAuth() 认证方式 = %q, 期望 %q", method, tt.want)
+			}
+		})
+	}
+}
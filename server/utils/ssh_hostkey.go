@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"oneclickvirt/global"
+
+	"go.uber.org/zap"
+	"golang.org/x/crypto/ssh"
+)
+
+// ErrHostKeyMismatch 服务端主机密钥与已固定的指纹不一致
+var ErrHostKeyMismatch = errors.New("SSH主机密钥与已固定的指纹不一致，可能遭受中间人攻击")
+
+// errHostKeyCaptured 获取指纹后主动中止握手
+var errHostKeyCaptured = errors.New("host key captured")
+
+// 未启用主机密钥校验的地址只提示一次，避免重连时刷屏
+var unverifiedHostWarned sync.Map
+
+// hostKeyCallback 按配置校验服务端主机密钥，seen记录服务端实际提供的密钥指纹
+// 启用校验且已固定指纹时拒绝不一致的密钥；启用校验但尚未固定时接受（TOFU），由调用方固定；
+// 未启用校验时保持原有的宽松行为，只记录警告
+func hostKeyCallback(config SSHConfig, seen *string) ssh.HostKeyCallback {
+	address := sshAddress(config)
+	expected := strings.TrimSpace(config.HostKeyFingerprint)
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		fingerprint := ssh.FingerprintSHA256(key)
+		*seen = fingerprint
+
+		if expected != "" && fingerprint != expected {
+			if config.VerifyHostKey {
+				global.APP_LOG.Error("SSH主机密钥校验失败，拒绝连接",
+					zap.String("address", address),
+					zap.String("expected", expected),
+					zap.String("actual", fingerprint))
+				return fmt.Errorf("%w（期望 %s，实际 %s）", ErrHostKeyMismatch, expected, fingerprint)
+			}
+			global.APP_LOG.Warn("SSH主机密钥与记录的指纹不一致，未启用校验，继续连接",
+				zap.String("address", address),
+				zap.String("expected", expected),
+				zap.String("actual", fingerprint))
+			return nil
+		}
+
+		if !config.VerifyHostKey {
+			if _, warned := unverifiedHostWarned.LoadOrStore(address, true); !warned {
+				global.APP_LOG.Warn("SSH连接未启用主机密钥校验，存在中间人攻击风险，可在Provider设置中开启",
+					zap.String("address", address),
+					zap.String("fingerprint", fingerprint))
+			}
+		}
+		return nil
+	}
+}
+
+// pinHostKey 启用校验且尚未固定指纹时，固定本次连接看到的指纹并通知调用方保存
+func pinHostKey(config *SSHConfig, fingerprint string) {
+	if !config.VerifyHostKey || config.HostKeyFingerprint != "" || fingerprint == "" {
+		return
+	}
+	config.HostKeyFingerprint = fingerprint
+	global.APP_LOG.Info("首次连接，已固定SSH主机密钥指纹",
+		zap.String("address", sshAddress(*config)),
+		zap.String("fingerprint", fingerprint))
+	if config.OnHostKeyPinned != nil {
+		config.OnHostKeyPinned(fingerprint)
+	}
+}
+
+// FetchHostKeyFingerprint 获取SSH服务端当前的主机密钥指纹（SHA256格式）
+// 在密钥交换完成后即中止握手，不进行认证
+func FetchHostKeyFingerprint(host string, port int, timeout time.Duration) (string, error) {
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	var fingerprint string
+	client, err := ssh.Dial("tcp", sshAddress(SSHConfig{Host: host, Port: port}), &ssh.ClientConfig{
+		User: "oneclickvirt",
+		HostKeyCallback: func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+			fingerprint = ssh.FingerprintSHA256(key)
+			return errHostKeyCaptured
+		},
+		Timeout: timeout,
+	})
+	if client != nil {
+		client.Close()
+	}
+	if fingerprint != "" {
+		return fingerprint, nil
+	}
+	return "", fmt.Errorf("获取SSH主机密钥失败: %w", err)
+}
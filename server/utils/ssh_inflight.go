@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrSSHCommandCancelled 正在执行的SSH命令被管理员取消，可通过 errors.Is 判断
+var ErrSSHCommandCancelled = errors.New("SSH command cancelled")
+
+// InflightSSHCommand 一条正在执行的SSH命令，命令中的密码已脱敏并截断
+type InflightSSHCommand struct {
+	ID         string    `json:"id"`
+	ProviderID uint      `json:"providerId"` // 连接配置的CommandLogID，未关联Provider时为0
+	Address    string    `json:"address"`    // 节点SSH地址，与熔断器的键一致
+	Tag        string    `json:"tag,omitempty"`
+	Command    string    `json:"command"`
+	StartedAt  time.Time `json:"startedAt"`
+	TimeoutSec int64     `json:"timeoutSec"` // 本次执行的超时时间
+	RunningSec int64     `json:"runningSec"` // 查询时已执行的时间
+	Cancelled  bool      `json:"cancelled"`  // 已请求取消，等待会话退出
+}
+
+type inflightCommand struct {
+	info   InflightSSHCommand
+	cancel context.CancelFunc
+}
+
+var (
+	inflightCommands   = make(map[string]*inflightCommand)
+	inflightCommandsMu sync.Mutex
+	inflightCommandSeq atomic.Uint64
+)
+
+// trackCommand 登记一条开始执行的命令，返回取消时关闭的ctx和执行结束后调用的注销函数
+func (c *SSHClient) trackCommand(tag, command string, timeout time.Duration) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	id := strconv.FormatUint(inflightCommandSeq.Add(1), 10)
+	entry := &inflightCommand{
+		info: InflightSSHCommand{
+			ID:         id,
+			ProviderID: c.config.CommandLogID,
+			Address:    sshAddress(c.config),
+			Tag:        tag,
+			Command:    TruncateString(RedactCommand(command), commandLogMaxLength),
+			StartedAt:  time.Now(),
+			TimeoutSec: int64(timeout / time.Second),
+		},
+		cancel: cancel,
+	}
+
+	inflightCommandsMu.Lock()
+	inflightCommands[id] = entry
+	inflightCommandsMu.Unlock()
+
+	return ctx, func() {
+		inflightCommandsMu.Lock()
+		delete(inflightCommands, id)
+		inflightCommandsMu.Unlock()
+		cancel()
+	}
+}
+
+// ListInflightSSHCommands 返回正在执行的SSH命令，按开始时间升序（执行最久的在前），providerID为0时返回全部
+func ListInflightSSHCommands(providerID uint) []InflightSSHCommand {
+	now := time.Now()
+	result := []InflightSSHCommand{}
+
+	inflightCommandsMu.Lock()
+	for _, entry := range inflightCommands {
+		if providerID != 0 && entry.info.ProviderID != providerID {
+			continue
+		}
+		info := entry.info
+		info.RunningSec = int64(now.Sub(info.StartedAt) / time.Second)
+		result = append(result, info)
+	}
+	inflightCommandsMu.Unlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].StartedAt.Before(result[j].StartedAt)
+	})
+	return result
+}
+
+// CancelInflightSSHCommand 取消正在执行的SSH命令，命令所在会话会被强制终止
+// 命令不存在（已结束）时返回false
+func CancelInflightSSHCommand(id string) (InflightSSHCommand, bool) {
+	inflightCommandsMu.Lock()
+	defer inflightCommandsMu.Unlock()
+
+	entry, ok := inflightCommands[id]
+	if !ok {
+		return InflightSSHCommand{}, false
+	}
+	entry.info.Cancelled = true
+	entry.cancel()
+
+	info := entry.info
+	info.RunningSec = int64(time.Since(info.StartedAt) / time.Second)
+	return info, true
+}
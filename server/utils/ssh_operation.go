@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"context"
+	"time"
+
+	"oneclickvirt/global"
+)
+
+// SSHOperation SSH命令的操作类型，不同类型使用不同的执行超时
+type SSHOperation string
+
+const (
+	SSHOperationQuick    SSHOperation = "quick"    // 状态查询等快速命令
+	SSHOperationNormal   SSHOperation = "normal"   // 普通命令，使用节点配置的执行超时
+	SSHOperationInstall  SSHOperation = "install"  // 软件包安装
+	SSHOperationDownload SSHOperation = "download" // 镜像和文件下载
+	SSHOperationBackup   SSHOperation = "backup"   // 备份导出与恢复导入
+)
+
+// 各操作类型的默认超时，普通命令默认使用节点配置的执行超时
+const (
+	DefaultSSHQuickTimeout    = 30 * time.Second
+	DefaultSSHInstallTimeout  = 15 * time.Minute
+	DefaultSSHDownloadTimeout = 60 * time.Minute
+	DefaultSSHBackupTimeout   = 2 * time.Hour
+)
+
+type sshOperationKey struct{}
+
+// WithSSHOperation 为ctx设置SSH命令的操作类型，Provider执行命令时按类型确定超时
+func WithSSHOperation(ctx context.Context, op SSHOperation) context.Context {
+	return context.WithValue(ctx, sshOperationKey{}, op)
+}
+
+// SSHOperationFromContext 返回ctx中设置的操作类型，未设置时为普通命令
+func SSHOperationFromContext(ctx context.Context) SSHOperation {
+	if ctx == nil {
+		return SSHOperationNormal
+	}
+	if op, ok := ctx.Value(sshOperationKey{}).(SSHOperation); ok {
+		return op
+	}
+	return SSHOperationNormal
+}
+
+// SSHOperationTimeout 返回操作类型的执行超时：task配置（秒，大于0时生效） > 默认值
+// 返回0表示使用连接配置的执行超时
+func SSHOperationTimeout(op SSHOperation) time.Duration {
+	cfg := global.APP_CONFIG.Task
+	var configured int
+	var fallback time.Duration
+	switch op {
+	case SSHOperationQuick:
+		configured, fallback = cfg.CommandTimeoutQuick, DefaultSSHQuickTimeout
+	case SSHOperationInstall:
+		configured, fallback = cfg.CommandTimeoutInstall, DefaultSSHInstallTimeout
+	case SSHOperationDownload:
+		configured, fallback = cfg.CommandTimeoutDownload, DefaultSSHDownloadTimeout
+	case SSHOperationBackup:
+		configured, fallback = cfg.CommandTimeoutBackup, DefaultSSHBackupTimeout
+	default:
+		configured = cfg.CommandTimeoutNormal
+	}
+	if configured > 0 {
+		return time.Duration(configured) * time.Second
+	}
+	return fallback
+}
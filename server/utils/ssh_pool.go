@@ -383,7 +383,9 @@ func (p *SSHConnectionPool) isSameConfig(a, b SSHConfig) bool {
 		a.Port == b.Port &&
 		a.Username == b.Username &&
 		a.Password == b.Password &&
-		a.PrivateKey == b.PrivateKey
+		a.PrivateKey == b.PrivateKey &&
+		a.PrivateKeyPassphrase == b.PrivateKeyPassphrase &&
+		a.UseAgent == b.UseAgent
 }
 
 // cleanupIdleConnections 自适应清理空闲、不健康和过期的连接
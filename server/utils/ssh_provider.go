@@ -0,0 +1,30 @@
+package utils
+
+import (
+	"time"
+
+	providerModel "oneclickvirt/model/provider"
+)
+
+// ProviderSSHConfig 根据Provider记录构造SSH连接配置，包含全部认证方式（私钥及密码短语、ssh-agent、密码）和主机密钥校验设置
+// 直接使用Provider记录建立SSH连接的地方都应通过此函数构造配置，避免遗漏认证字段
+func ProviderSSHConfig(p *providerModel.Provider, connectTimeout, executeTimeout time.Duration) SSHConfig {
+	sshPort := p.SSHPort
+	if sshPort == 0 {
+		sshPort = 22 // 未设置SSH端口时使用默认值22
+	}
+	host, port := ParseEndpoint(p.Endpoint, sshPort)
+	return SSHConfig{
+		Host:                 host,
+		Port:                 port,
+		Username:             p.Username,
+		Password:             p.Password,
+		PrivateKey:           p.SSHKey,
+		PrivateKeyPassphrase: p.SSHKeyPassphrase,
+		UseAgent:             p.SSHUseAgent,
+		VerifyHostKey:        p.SSHVerifyHostKey,
+		HostKeyFingerprint:   p.SSHHostKeyFingerprint,
+		ConnectTimeout:       connectTimeout,
+		ExecuteTimeout:       executeTimeout,
+	}
+}
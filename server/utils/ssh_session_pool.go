@@ -0,0 +1,352 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"oneclickvirt/global"
+
+	"github.com/pkg/sftp"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	defaultSSHMaxConnections   = 4                // 每个节点默认最多同时保持的SSH连接数（含主连接）
+	defaultSSHMaxSessions      = 8                // 每个连接默认最多同时打开的会话数，低于sshd默认的MaxSessions 10
+	sshExtraConnIdleTimeout    = 5 * time.Minute  // 额外连接空闲超过该时间后关闭
+	sshExtraConnDialBackoff    = 30 * time.Second // 额外连接建立失败后暂停扩容的时间
+	defaultSSHSessionWaitLimit = 5 * time.Minute  // 未配置执行超时时等待空闲会话的上限
+)
+
+// errSSHClientClosed SSH客户端已关闭
+var errSSHClientClosed = errors.New("ssh client closed")
+
+// sshExtraConn 主连接会话数已满时按需建立的额外连接，与主连接使用相同配置和主机密钥
+type sshExtraConn struct {
+	client    *ssh.Client
+	cancel    context.CancelFunc
+	wg        *sync.WaitGroup
+	sessions  int
+	idleTimer *time.Timer
+}
+
+// sshSessionPool 单个节点的SSH会话调度状态
+// 命令执行时从主连接或额外连接中选择会话数未满的连接，全部满且连接数未达上限时建立新连接，
+// 否则等待其他命令结束，避免并发命令超过sshd的MaxSessions导致会话创建失败
+type sshSessionPool struct {
+	mu              sync.Mutex
+	primarySessions int
+	extra           []*sshExtraConn
+	dialing         int
+	lastDialFailure time.Time
+	slotFreed       chan struct{}
+	closed          bool
+}
+
+// sshMaxConnections 每个节点最多同时保持的SSH连接数
+func sshMaxConnections() int {
+	if global.APP_CONFIG.System.SSHMaxConnections > 0 {
+		return global.APP_CONFIG.System.SSHMaxConnections
+	}
+	return defaultSSHMaxConnections
+}
+
+// sshMaxSessions 每个SSH连接最多同时打开的会话数
+func sshMaxSessions() int {
+	if global.APP_CONFIG.System.SSHMaxSessions > 0 {
+		return global.APP_CONFIG.System.SSHMaxSessions
+	}
+	return defaultSSHMaxSessions
+}
+
+// primaryClient 并发安全地读取主连接
+func (c *SSHClient) primaryClient() *ssh.Client {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.client
+}
+
+// sshSessionSlot 已占用的一个会话名额及其所在连接，extra为nil表示主连接
+type sshSessionSlot struct {
+	client *ssh.Client
+	extra  *sshExtraConn
+}
+
+// acquireSession 获取一个可用的SSH会话，返回的release必须在会话关闭后调用
+func (c *SSHClient) acquireSession() (*ssh.Session, func(), error) {
+	deadline := c.sessionWaitDeadline()
+	for {
+		slot, err := c.acquireSlot(deadline)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create SSH session: %w", err)
+		}
+		session, err := slot.client.NewSession()
+		if err == nil {
+			return session, func() { c.releaseSlot(slot) }, nil
+		}
+		if slot.extra == nil {
+			c.releaseSlot(slot)
+			return nil, nil, fmt.Errorf("failed to create SSH session: %w", err)
+		}
+		// 额外连接失效时丢弃并重新选择，不影响主连接上正在执行的命令
+		global.APP_LOG.Warn("SSH额外连接创建会话失败，关闭该连接",
+			zap.String("host", c.config.Host),
+			zap.Error(err))
+		c.discardExtra(slot.extra)
+	}
+}
+
+// newSFTPClient 占用一个会话名额打开SFTP客户端，SFTP子系统同样占用一个sshd会话
+// 返回的release必须在SFTP客户端关闭后调用
+func (c *SSHClient) newSFTPClient() (*sftp.Client, func(), error) {
+	slot, err := c.acquireSlot(c.sessionWaitDeadline())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create SFTP client: %w", err)
+	}
+	sftpClient, err := sftp.NewClient(slot.client)
+	if err != nil {
+		c.releaseSlot(slot)
+		return nil, nil, fmt.Errorf("failed to create SFTP client: %w", err)
+	}
+	return sftpClient, func() { c.releaseSlot(slot) }, nil
+}
+
+// sessionWaitDeadline 等待空闲会话的截止时间，未配置执行超时时使用默认上限
+func (c *SSHClient) sessionWaitDeadline() time.Time {
+	wait := c.config.ExecuteTimeout
+	if wait <= 0 {
+		wait = defaultSSHSessionWaitLimit
+	}
+	return time.Now().Add(wait)
+}
+
+// acquireSlot 从主连接或额外连接中占用一个会话名额，全部满且连接数未达上限时建立新连接，否则等待至deadline
+func (c *SSHClient) acquireSlot(deadline time.Time) (*sshSessionSlot, error) {
+	p := &c.sessions
+	p.mu.Lock()
+	for {
+		if p.closed {
+			p.mu.Unlock()
+			return nil, errSSHClientClosed
+		}
+		maxSessions := sshMaxSessions()
+
+		if p.primarySessions < maxSessions {
+			p.primarySessions++
+			p.mu.Unlock()
+			// 通过primaryClient读取主连接，避免与Reconnect替换连接竞争
+			client := c.primaryClient()
+			if client == nil {
+				c.releasePrimary()
+				return nil, errSSHClientClosed
+			}
+			return &sshSessionSlot{client: client}, nil
+		}
+
+		if conn := p.availableExtra(maxSessions); conn != nil {
+			conn.sessions++
+			if conn.idleTimer != nil {
+				conn.idleTimer.Stop()
+				conn.idleTimer = nil
+			}
+			p.mu.Unlock()
+			return &sshSessionSlot{client: conn.client, extra: conn}, nil
+		}
+
+		if 1+len(p.extra)+p.dialing < sshMaxConnections() && time.Since(p.lastDialFailure) > sshExtraConnDialBackoff {
+			p.dialing++
+			p.mu.Unlock()
+			c.dialExtra()
+			p.mu.Lock()
+			continue
+		}
+
+		if time.Until(deadline) <= 0 {
+			p.mu.Unlock()
+			return nil, errors.New("等待空闲SSH会话超时")
+		}
+		if p.slotFreed == nil {
+			p.slotFreed = make(chan struct{})
+		}
+		freed := p.slotFreed
+		p.mu.Unlock()
+
+		timer := time.NewTimer(time.Until(deadline))
+		select {
+		case <-freed:
+		case <-timer.C:
+		}
+		timer.Stop()
+		p.mu.Lock()
+	}
+}
+
+// releaseSlot 释放会话名额
+func (c *SSHClient) releaseSlot(slot *sshSessionSlot) {
+	if slot.extra != nil {
+		c.releaseExtra(slot.extra)
+		return
+	}
+	c.releasePrimary()
+}
+
+// availableExtra 返回会话数未满的额外连接，需持有锁
+func (p *sshSessionPool) availableExtra(maxSessions int) *sshExtraConn {
+	for _, conn := range p.extra {
+		if conn.sessions < maxSessions {
+			return conn
+		}
+	}
+	return nil
+}
+
+// notifyLocked 唤醒等待会话的调用方，需持有锁
+func (p *sshSessionPool) notifyLocked() {
+	if p.slotFreed != nil {
+		close(p.slotFreed)
+		p.slotFreed = nil
+	}
+}
+
+// dialExtra 建立一个额外连接并加入连接池，调用前需已占用dialing计数
+func (c *SSHClient) dialExtra() {
+	client, cancel, wg, _, _, err := dialSSH(c.config)
+
+	p := &c.sessions
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.dialing--
+	if err != nil {
+		p.lastDialFailure = time.Now()
+		global.APP_LOG.Warn("建立SSH额外连接失败，继续使用现有连接",
+			zap.String("host", c.config.Host),
+			zap.Error(err))
+		return
+	}
+	if p.closed {
+		cancel()
+		client.Close()
+		return
+	}
+	p.extra = append(p.extra, &sshExtraConn{client: client, cancel: cancel, wg: wg})
+	global.APP_LOG.Debug("建立SSH额外连接",
+		zap.String("host", c.config.Host),
+		zap.Int("connections", 1+len(p.extra)))
+}
+
+// releasePrimary 释放主连接上的会话占用
+func (c *SSHClient) releasePrimary() {
+	p := &c.sessions
+	p.mu.Lock()
+	p.primarySessions--
+	p.notifyLocked()
+	p.mu.Unlock()
+}
+
+// releaseExtra 释放额外连接上的会话占用，连接空闲后开始计时关闭
+func (c *SSHClient) releaseExtra(conn *sshExtraConn) {
+	p := &c.sessions
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	conn.sessions--
+	p.notifyLocked()
+	if conn.sessions == 0 && !p.closed && p.containsLocked(conn) {
+		conn.idleTimer = time.AfterFunc(sshExtraConnIdleTimeout, func() { c.closeIdleExtra(conn) })
+	}
+}
+
+// closeIdleExtra 关闭空闲超时的额外连接
+func (c *SSHClient) closeIdleExtra(conn *sshExtraConn) {
+	p := &c.sessions
+	p.mu.Lock()
+	if conn.sessions > 0 || !p.removeExtraLocked(conn) {
+		p.mu.Unlock()
+		return
+	}
+	p.mu.Unlock()
+
+	conn.close()
+	global.APP_LOG.Debug("关闭空闲SSH额外连接",
+		zap.String("host", c.config.Host))
+}
+
+// discardExtra 丢弃失效的额外连接
+func (c *SSHClient) discardExtra(conn *sshExtraConn) {
+	p := &c.sessions
+	p.mu.Lock()
+	removed := p.removeExtraLocked(conn)
+	p.notifyLocked()
+	p.mu.Unlock()
+	if removed {
+		conn.close()
+	}
+}
+
+// containsLocked 额外连接是否仍在连接池中，需持有锁
+func (p *sshSessionPool) containsLocked(conn *sshExtraConn) bool {
+	for _, existing := range p.extra {
+		if existing == conn {
+			return true
+		}
+	}
+	return false
+}
+
+// removeExtraLocked 从连接池中移除额外连接并停止其空闲计时，需持有锁
+func (p *sshSessionPool) removeExtraLocked(conn *sshExtraConn) bool {
+	for idx, existing := range p.extra {
+		if existing == conn {
+			p.extra = append(p.extra[:idx], p.extra[idx+1:]...)
+			if conn.idleTimer != nil {
+				conn.idleTimer.Stop()
+				conn.idleTimer = nil
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// closeSessions 关闭全部额外连接并唤醒等待中的调用方，由SSHClient.Close调用
+func (c *SSHClient) closeSessions() {
+	p := &c.sessions
+	p.mu.Lock()
+	p.closed = true
+	extra := p.extra
+	for _, conn := range extra {
+		if conn.idleTimer != nil {
+			conn.idleTimer.Stop()
+			conn.idleTimer = nil
+		}
+	}
+	p.extra = nil
+	p.notifyLocked()
+	p.mu.Unlock()
+
+	for _, conn := range extra {
+		conn.close()
+	}
+}
+
+// reopenSessions Reconnect后允许重新获取会话
+func (c *SSHClient) reopenSessions() {
+	p := &c.sessions
+	p.mu.Lock()
+	p.closed = false
+	p.mu.Unlock()
+}
+
+// close 关闭额外连接，调用前需已从连接池中移除
+func (e *sshExtraConn) close() {
+	if e.cancel != nil {
+		e.cancel()
+	}
+	e.client.Close()
+	if e.wg != nil {
+		e.wg.Wait()
+	}
+}
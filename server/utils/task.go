@@ -28,6 +28,7 @@ func UpdateTaskProgress(taskID uint, progress int, message string) {
 			zap.Uint("taskId", taskID),
 			zap.Int("progress", progress),
 			zap.String("message", message))
+		publishTaskEvent(taskID, TaskEventProgress, progress, message)
 	}
 }
 
@@ -59,6 +60,8 @@ func MarkTaskCompleted(taskID uint, message string) {
 			zap.Uint("taskId", taskID),
 			zap.String("message", message))
 
+		publishTaskEvent(taskID, TaskEventCompleted, 100, message)
+
 		// 释放并发控制锁
 		if global.APP_TASK_LOCK_RELEASER != nil {
 			global.APP_TASK_LOCK_RELEASER.ReleaseTaskLocks(taskID)
@@ -74,6 +77,8 @@ func MarkTaskFailed(taskID uint, errorMessage string) {
 		"error_message": errorMessage,
 	}).Error; err != nil {
 		global.APP_LOG.Error("标记任务失败时出错", zap.Uint("taskId", taskID), zap.Error(err))
+	} else {
+		publishTaskEvent(taskID, TaskEventFailed, 0, errorMessage)
 	}
 
 	// 释放并发控制锁
@@ -85,15 +90,22 @@ func MarkTaskFailed(taskID uint, errorMessage string) {
 // GetDefaultTaskTimeout 获取默认任务超时时间（秒）
 func GetDefaultTaskTimeout(taskType string) int {
 	timeouts := map[string]int{
-		"create":              1800, // 30分钟
-		"start":               300,  // 5分钟
-		"stop":                300,  // 5分钟
-		"restart":             600,  // 10分钟
-		"reset":               1200, // 20分钟
-		"delete":              600,  // 10分钟
-		"create-port-mapping": 600,  // 10分钟
-		"delete-port-mapping": 300,  // 5分钟
-		"reset-password":      600,  // 10分钟
+		"create":               1800, // 30分钟
+		"start":                300,  // 5分钟
+		"stop":                 300,  // 5分钟
+		"restart":              600,  // 10分钟
+		"reset":                1200, // 20分钟
+		"delete":               600,  // 10分钟
+		"create-port-mapping":  600,  // 10分钟
+		"delete-port-mapping":  300,  // 5分钟
+		"reset-password":       600,  // 10分钟
+		"recreate":             1200, // 20分钟
+		"clone":                1800, // 30分钟
+		"create-template":      1800, // 30分钟
+		"create-from-template": 1800, // 30分钟
+		"backup":               7200, // 2小时
+		"restore-backup":       7200, // 2小时
+		"migrate":              7200, // 2小时
 	}
 
 	if timeout, exists := timeouts[taskType]; exists {
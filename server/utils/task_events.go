@@ -0,0 +1,90 @@
+package utils
+
+import (
+	"sync"
+	"time"
+)
+
+// 任务事件类型
+const (
+	TaskEventProgress  = "progress"  // 进度更新
+	TaskEventCompleted = "completed" // 任务完成
+	TaskEventFailed    = "failed"    // 任务失败
+)
+
+// taskEventBuffer 每个订阅者的事件缓冲，读取过慢时丢弃进度事件，不阻塞任务执行
+const taskEventBuffer = 32
+
+// TaskEvent 任务进度事件
+type TaskEvent struct {
+	TaskID   uint      `json:"taskId"`
+	Type     string    `json:"type"`
+	Progress int       `json:"progress"`
+	Message  string    `json:"message,omitempty"`
+	Time     time.Time `json:"time"`
+}
+
+// taskEventBus 按任务ID分发进度事件
+type taskEventBus struct {
+	mu          sync.Mutex
+	subscribers map[uint]map[chan TaskEvent]struct{}
+}
+
+var taskEvents = &taskEventBus{subscribers: make(map[uint]map[chan TaskEvent]struct{})}
+
+// SubscribeTaskEvents 订阅任务的进度事件，返回事件通道和取消订阅函数
+// 取消订阅后通道会被关闭，调用方必须在不再读取时取消订阅
+func SubscribeTaskEvents(taskID uint) (<-chan TaskEvent, func()) {
+	ch := make(chan TaskEvent, taskEventBuffer)
+
+	taskEvents.mu.Lock()
+	if taskEvents.subscribers[taskID] == nil {
+		taskEvents.subscribers[taskID] = make(map[chan TaskEvent]struct{})
+	}
+	taskEvents.subscribers[taskID][ch] = struct{}{}
+	taskEvents.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			taskEvents.mu.Lock()
+			delete(taskEvents.subscribers[taskID], ch)
+			if len(taskEvents.subscribers[taskID]) == 0 {
+				delete(taskEvents.subscribers, taskID)
+			}
+			taskEvents.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// publishTaskEvent 向任务的所有订阅者发送事件，没有订阅者时直接返回
+// 完成和失败事件是流的结束标志，缓冲已满时丢弃最旧的事件以保证送达
+func publishTaskEvent(taskID uint, eventType string, progress int, message string) {
+	taskEvents.mu.Lock()
+	defer taskEvents.mu.Unlock()
+
+	subscribers := taskEvents.subscribers[taskID]
+	if len(subscribers) == 0 {
+		return
+	}
+	event := TaskEvent{TaskID: taskID, Type: eventType, Progress: progress, Message: message, Time: time.Now()}
+	for ch := range subscribers {
+		select {
+		case ch <- event:
+			continue
+		default:
+		}
+		if eventType == TaskEventProgress {
+			continue
+		}
+		select {
+		case <-ch:
+		default:
+		}
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}